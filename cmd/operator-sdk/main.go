@@ -22,10 +22,12 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/cli"
+	"github.com/operator-framework/operator-sdk/internal/util/exitcode"
 )
 
 func main() {
 	if err := cli.Run(); err != nil {
-		log.Fatal(err)
+		log.Error(err)
+		log.Exit(exitcode.For(err))
 	}
 }
@@ -3,18 +3,26 @@ module github.com/operator-framework/operator-sdk
 go 1.13
 
 require (
+	github.com/Masterminds/sprig/v3 v3.1.0
 	github.com/blang/semver v3.5.1+incompatible
+	github.com/containerd/containerd v1.3.2
+	github.com/docker/distribution v2.7.1+incompatible
 	github.com/fatih/structtag v1.1.0
 	github.com/go-logr/logr v0.1.0
+	github.com/golang/protobuf v1.4.2
+	github.com/google/cel-go v0.5.1
 	github.com/iancoleman/strcase v0.0.0-20191112232945-16388991a334
 	github.com/kr/text v0.1.0
 	github.com/markbates/inflect v1.0.4
 	github.com/onsi/ginkgo v1.12.1
 	github.com/onsi/gomega v1.10.1
+	github.com/opencontainers/go-digest v1.0.0-rc1
+	github.com/opencontainers/image-spec v1.0.2-0.20190823105129-775207bd45b6
 	github.com/operator-framework/api v0.3.13
 	github.com/operator-framework/operator-lib v0.1.0
 	github.com/operator-framework/operator-registry v1.13.4
 	github.com/prometheus/client_golang v1.5.1
+	github.com/prometheus/common v0.9.1
 	github.com/sergi/go-diff v1.0.0
 	github.com/sirupsen/logrus v1.5.0
 	github.com/spf13/afero v1.2.2
@@ -22,8 +30,10 @@ require (
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.4.0
 	github.com/stretchr/testify v1.5.1
+	golang.org/x/text v0.3.3
 	golang.org/x/tools v0.0.0-20200403190813-44a64ad78b9b
 	gomodules.xyz/jsonpatch/v3 v3.0.1
+	google.golang.org/genproto v0.0.0-20200701001935-0939c5918c31
 	helm.sh/helm/v3 v3.2.4
 	k8s.io/api v0.18.6
 	k8s.io/apiextensions-apiserver v0.18.6
@@ -35,6 +45,7 @@ require (
 	sigs.k8s.io/controller-runtime v0.6.2
 	sigs.k8s.io/controller-tools v0.3.0
 	sigs.k8s.io/kubebuilder v1.0.9-0.20200805184228-f7a3b65dd250
+	sigs.k8s.io/kustomize v2.0.3+incompatible
 	sigs.k8s.io/yaml v1.2.0
 )
 
@@ -0,0 +1,41 @@
+# Copyright 2020 The Operator-SDK Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from __future__ import (absolute_import, division, print_function)
+__metaclass__ = type
+
+from ansible.plugins.action import ActionBase
+
+# Module options defaulted from the ansible_operator_meta variable, which the
+# Ansible operator sets to identify the custom resource being reconciled.
+META_OPTIONS = ('api_version', 'kind', 'name', 'namespace')
+
+
+class ActionModule(ActionBase):
+    ''' Run a module against the custom resource being reconciled by default '''
+
+    def run(self, tmp=None, task_vars=None):
+        if task_vars is None:
+            task_vars = dict()
+        result = super(ActionModule, self).run(tmp, task_vars)
+        del tmp
+
+        module_args = self._task.args.copy()
+        meta = task_vars.get('ansible_operator_meta') or {}
+        for option in META_OPTIONS:
+            if module_args.get(option) is None and meta.get(option):
+                module_args[option] = meta[option]
+
+        result.update(self._execute_module(module_args=module_args, task_vars=task_vars))
+        return result
@@ -0,0 +1,56 @@
+# Copyright 2020 The Operator-SDK Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from __future__ import (absolute_import, division, print_function)
+__metaclass__ = type
+
+import os
+import traceback
+from datetime import datetime
+
+try:
+    from kubernetes import config
+    from openshift.dynamic import DynamicClient
+    HAS_K8S = True
+    K8S_IMPORT_ERROR = None
+except ImportError:
+    HAS_K8S = False
+    K8S_IMPORT_ERROR = traceback.format_exc()
+
+# Options identifying the custom resource a module acts on. The operator_sdk.runtime
+# action plugins default them to the custom resource being reconciled.
+RESOURCE_ARG_SPEC = dict(
+    api_version=dict(type='str', required=True),
+    kind=dict(type='str', required=True),
+    name=dict(type='str', required=True),
+    namespace=dict(type='str'),
+)
+
+
+def now():
+    ''' Returns the current time as an RFC 3339 timestamp '''
+    return datetime.utcnow().strftime('%Y-%m-%dT%H:%M:%SZ')
+
+
+def get_client():
+    ''' Returns a dynamic client for the kubeconfig the Ansible operator passes to ansible-runner '''
+    kubeconfig = os.environ.get('K8S_AUTH_KUBECONFIG') or os.environ.get('KUBECONFIG')
+    return DynamicClient(config.new_client_from_config(config_file=kubeconfig))
+
+
+def get_resource(client, params):
+    ''' Returns the API resource and object of the custom resource identified by params '''
+    resource = client.resources.get(api_version=params['api_version'], kind=params['kind'])
+    obj = resource.get(name=params['name'], namespace=params['namespace'])
+    return resource, obj.to_dict()
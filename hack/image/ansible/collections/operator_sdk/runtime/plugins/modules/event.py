@@ -0,0 +1,153 @@
+# Copyright 2020 The Operator-SDK Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from __future__ import (absolute_import, division, print_function)
+__metaclass__ = type
+DOCUMENTATION = '''
+module: event
+short_description: Emit a Kubernetes Event for a custom resource
+description:
+  - Create a Kubernetes Event involving a custom resource, shown by C(kubectl describe).
+  - The resource options default to the custom resource being reconciled by the Ansible operator.
+  - Events of cluster-scoped custom resources are created in the C(default) namespace.
+options:
+  api_version:
+    description: API version of the custom resource.
+    type: str
+  kind:
+    description: Kind of the custom resource.
+    type: str
+  name:
+    description: Name of the custom resource.
+    type: str
+  namespace:
+    description: Namespace of the custom resource. Omit for cluster-scoped resources.
+    type: str
+  type:
+    description: Type of the event.
+    type: str
+    choices: ['Normal', 'Warning']
+    default: Normal
+  reason:
+    description: Short, CamelCase reason for the event.
+    type: str
+    required: true
+  message:
+    description: Human readable description of the event.
+    type: str
+    required: true
+  component:
+    description: Component reported as the source of the event.
+    type: str
+    default: ansible-operator
+requirements:
+  - openshift >= 0.10
+'''
+
+EXAMPLES = '''
+- name: Report that the database is being upgraded
+  operator_sdk.runtime.event:
+    reason: UpgradingDatabase
+    message: Upgrading the database from {{ current_version }} to {{ version }}
+
+- name: Warn about a deprecated field
+  operator_sdk.runtime.event:
+    type: Warning
+    reason: DeprecatedField
+    message: spec.size is deprecated, use spec.replicas instead
+  when: size is defined
+'''
+
+RETURN = '''
+result:
+  description: The created event.
+  returned: success
+  type: dict
+'''
+
+import time
+
+from ansible.module_utils.basic import AnsibleModule, missing_required_lib
+
+from ansible_collections.operator_sdk.runtime.plugins.module_utils.client import (
+    HAS_K8S, K8S_IMPORT_ERROR, RESOURCE_ARG_SPEC, get_client, get_resource, now)
+
+try:
+    from openshift.dynamic.exceptions import DynamicApiError
+except ImportError:
+    pass
+
+
+def build_event(obj, params, timestamp):
+    ''' Returns an Event for obj described by the module's parameters '''
+    metadata = obj['metadata']
+    involved_object = dict(
+        apiVersion=obj['apiVersion'],
+        kind=obj['kind'],
+        name=metadata['name'],
+        uid=metadata['uid'],
+        resourceVersion=metadata['resourceVersion'],
+    )
+    if metadata.get('namespace'):
+        involved_object['namespace'] = metadata['namespace']
+    return dict(
+        apiVersion='v1',
+        kind='Event',
+        metadata=dict(
+            # Events are named like client-go's event recorder names them.
+            name='{0}.{1:x}'.format(metadata['name'], int(time.time() * 1e9)),
+            namespace=metadata.get('namespace') or 'default',
+        ),
+        involvedObject=involved_object,
+        type=params['type'],
+        reason=params['reason'],
+        message=params['message'],
+        source=dict(component=params['component']),
+        firstTimestamp=timestamp,
+        lastTimestamp=timestamp,
+        count=1,
+    )
+
+
+def main():
+    argument_spec = dict(
+        type=dict(type='str', choices=['Normal', 'Warning'], default='Normal'),
+        reason=dict(type='str', required=True),
+        message=dict(type='str', required=True),
+        component=dict(type='str', default='ansible-operator'),
+    )
+    argument_spec.update(RESOURCE_ARG_SPEC)
+    module = AnsibleModule(argument_spec=argument_spec, supports_check_mode=True)
+    if not HAS_K8S:
+        module.fail_json(msg=missing_required_lib('openshift'), exception=K8S_IMPORT_ERROR)
+
+    params = module.params
+    client = get_client()
+    try:
+        _, obj = get_resource(client, params)
+    except DynamicApiError as e:
+        module.fail_json(msg='Failed to get {0} {1}: {2}'.format(params['kind'], params['name'], e))
+    event = build_event(obj, params, now())
+    if module.check_mode:
+        module.exit_json(changed=True, result=event)
+    try:
+        events = client.resources.get(api_version='v1', kind='Event')
+        result = events.create(body=event, namespace=event['metadata']['namespace'])
+    except DynamicApiError as e:
+        module.fail_json(msg='Failed to create event for {0} {1}: {2}'.format(params['kind'], params['name'], e))
+    module.exit_json(changed=True, result=result.to_dict())
+
+
+if __name__ == '__main__':
+    main()
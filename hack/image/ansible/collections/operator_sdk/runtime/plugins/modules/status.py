@@ -0,0 +1,209 @@
+# Copyright 2020 The Operator-SDK Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from __future__ import (absolute_import, division, print_function)
+__metaclass__ = type
+DOCUMENTATION = '''
+module: status
+short_description: Set the status of a custom resource
+description:
+  - Set typed status conditions, C(observedGeneration), and custom status fields of a custom
+    resource, merging them into its existing status through the status subresource.
+  - The resource options default to the custom resource being reconciled by the Ansible operator.
+options:
+  api_version:
+    description: API version of the custom resource.
+    type: str
+  kind:
+    description: Kind of the custom resource.
+    type: str
+  name:
+    description: Name of the custom resource.
+    type: str
+  namespace:
+    description: Namespace of the custom resource. Omit for cluster-scoped resources.
+    type: str
+  conditions:
+    description:
+      - Conditions to set, merged by C(type) into the existing conditions.
+      - C(lastTransitionTime) is updated only when a condition's C(status) changes.
+    type: list
+    elements: dict
+    suboptions:
+      type:
+        description: Type of the condition.
+        type: str
+        required: true
+      status:
+        description: Status of the condition.
+        type: str
+        required: true
+        choices: ['True', 'False', 'Unknown']
+      reason:
+        description: CamelCase reason for the condition's last transition.
+        type: str
+      message:
+        description: Human readable message about the condition's last transition.
+        type: str
+  observed_generation:
+    description: Set C(observedGeneration) to the custom resource's C(metadata.generation).
+    type: bool
+    default: false
+  status:
+    description:
+      - Custom status fields, merged recursively into the existing status.
+      - A field set to C(null) is removed from the status.
+      - Use I(conditions) and I(observed_generation) to set those fields.
+    type: dict
+requirements:
+  - openshift >= 0.10
+'''
+
+EXAMPLES = '''
+- name: Mark the custom resource ready
+  operator_sdk.runtime.status:
+    observed_generation: true
+    conditions:
+      - type: Ready
+        status: 'True'
+        reason: DeploymentAvailable
+        message: All replicas are available
+
+- name: Record the deployed version and clear a stale field
+  operator_sdk.runtime.status:
+    status:
+      version: '{{ version }}'
+      upgradeFrom: null
+'''
+
+RETURN = '''
+result:
+  description: The custom resource after its status was set.
+  returned: success
+  type: dict
+'''
+
+import copy
+import time
+
+from ansible.module_utils.basic import AnsibleModule, missing_required_lib
+
+from ansible_collections.operator_sdk.runtime.plugins.module_utils.client import (
+    HAS_K8S, K8S_IMPORT_ERROR, RESOURCE_ARG_SPEC, get_client, get_resource, now)
+
+try:
+    from openshift.dynamic.exceptions import ConflictError, DynamicApiError
+except ImportError:
+    pass
+
+# Status fields that can only be set through their typed options.
+TYPED_FIELDS = ('conditions', 'observedGeneration')
+
+# Number of times to retry setting the status when the custom resource was modified concurrently.
+CONFLICT_RETRIES = 5
+
+
+def merge_conditions(existing, desired, timestamp):
+    ''' Returns existing with the desired conditions set, keyed by type '''
+    conditions = [dict(c) for c in existing or []]
+    for d in desired:
+        condition = dict(type=d['type'], status=d['status'])
+        for key in ('reason', 'message'):
+            if d.get(key) is not None:
+                condition[key] = d[key]
+        current = next((c for c in conditions if c.get('type') == d['type']), None)
+        if current is None:
+            condition['lastTransitionTime'] = timestamp
+            conditions.append(condition)
+            continue
+        if current.get('status') != condition['status'] or 'lastTransitionTime' not in current:
+            condition['lastTransitionTime'] = timestamp
+        current.update(condition)
+    return conditions
+
+
+def merge_fields(existing, desired):
+    ''' Returns existing with desired merged in recursively, removing fields set to None '''
+    merged = dict(existing)
+    for key, value in desired.items():
+        if value is None:
+            merged.pop(key, None)
+        elif isinstance(value, dict) and isinstance(merged.get(key), dict):
+            merged[key] = merge_fields(merged[key], value)
+        else:
+            merged[key] = value
+    return merged
+
+
+def desired_status(obj, params, timestamp):
+    ''' Returns the status of obj with the module's parameters applied '''
+    status = copy.deepcopy(obj.get('status') or {})
+    if params['status']:
+        status = merge_fields(status, params['status'])
+    if params['conditions']:
+        status['conditions'] = merge_conditions(status.get('conditions'), params['conditions'], timestamp)
+    if params['observed_generation']:
+        status['observedGeneration'] = obj['metadata'].get('generation')
+    return status
+
+
+def main():
+    argument_spec = dict(
+        conditions=dict(type='list', elements='dict', options=dict(
+            type=dict(type='str', required=True),
+            status=dict(type='str', required=True, choices=['True', 'False', 'Unknown']),
+            reason=dict(type='str'),
+            message=dict(type='str'),
+        )),
+        observed_generation=dict(type='bool', default=False),
+        status=dict(type='dict'),
+    )
+    argument_spec.update(RESOURCE_ARG_SPEC)
+    module = AnsibleModule(argument_spec=argument_spec, supports_check_mode=True)
+    if not HAS_K8S:
+        module.fail_json(msg=missing_required_lib('openshift'), exception=K8S_IMPORT_ERROR)
+
+    params = module.params
+    for field in TYPED_FIELDS:
+        if field in (params['status'] or {}):
+            module.fail_json(msg='status.{0} cannot be set directly, use the {1} option'.format(
+                field, 'conditions' if field == 'conditions' else 'observed_generation'))
+
+    client = get_client()
+    for attempt in range(CONFLICT_RETRIES):
+        try:
+            resource, obj = get_resource(client, params)
+        except DynamicApiError as e:
+            module.fail_json(msg='Failed to get {0} {1}: {2}'.format(params['kind'], params['name'], e))
+        status = desired_status(obj, params, now())
+        if status == (obj.get('status') or {}):
+            module.exit_json(changed=False, result=obj)
+        obj['status'] = status
+        if module.check_mode:
+            module.exit_json(changed=True, result=obj)
+        try:
+            result = resource.status.replace(body=obj, namespace=params['namespace'])
+        except ConflictError:
+            time.sleep(0.2 * (attempt + 1))
+            continue
+        except DynamicApiError as e:
+            module.fail_json(msg='Failed to set the status of {0} {1}: {2}'.format(
+                params['kind'], params['name'], e))
+        module.exit_json(changed=True, result=result.to_dict())
+    module.fail_json(msg='Failed to set the status of {0} {1}: the resource was modified concurrently {2} times'.format(
+        params['kind'], params['name'], CONFLICT_RETRIES))
+
+
+if __name__ == '__main__':
+    main()
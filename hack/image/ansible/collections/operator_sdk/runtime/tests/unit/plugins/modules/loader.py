@@ -0,0 +1,65 @@
+# Copyright 2020 The Operator-SDK Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from __future__ import (absolute_import, division, print_function)
+__metaclass__ = type
+
+import importlib
+import os
+import sys
+import types
+
+# The directory containing this collection's namespace, which is the ansible_collections package
+# when the collection is installed.
+COLLECTIONS_DIR = os.path.abspath(os.path.join(os.path.dirname(__file__), *[os.pardir] * 6))
+
+
+class ExitJson(Exception):
+    ''' Raised by FakeModule.exit_json with its arguments '''
+
+
+class FailJson(Exception):
+    ''' Raised by FakeModule.fail_json with its arguments '''
+
+
+class FakeModule(object):
+    ''' Stands in for AnsibleModule, with the given params, raising on exit '''
+
+    def __init__(self, params, check_mode=False):
+        self.params = params
+        self.check_mode = check_mode
+
+    def exit_json(self, **kwargs):
+        raise ExitJson(kwargs)
+
+    def fail_json(self, **kwargs):
+        raise FailJson(kwargs)
+
+
+def import_module(name):
+    ''' Imports the module plugin name of this collection, stubbing Ansible if it is not installed '''
+    try:
+        import ansible.module_utils.basic  # noqa: F401
+    except ImportError:
+        basic = types.ModuleType('ansible.module_utils.basic')
+        basic.AnsibleModule = FakeModule
+        basic.missing_required_lib = lambda lib: 'missing ' + lib
+        for pkg in ('ansible', 'ansible.module_utils'):
+            sys.modules.setdefault(pkg, types.ModuleType(pkg))
+        sys.modules['ansible.module_utils.basic'] = basic
+    if 'ansible_collections' not in sys.modules:
+        pkg = types.ModuleType('ansible_collections')
+        pkg.__path__ = [COLLECTIONS_DIR]
+        sys.modules['ansible_collections'] = pkg
+    return importlib.import_module('ansible_collections.operator_sdk.runtime.plugins.modules.' + name)
@@ -0,0 +1,124 @@
+# Copyright 2020 The Operator-SDK Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from __future__ import (absolute_import, division, print_function)
+__metaclass__ = type
+
+import unittest
+
+try:
+    from unittest import mock
+except ImportError:
+    import mock
+
+from loader import ExitJson, FailJson, FakeModule, import_module
+
+event = import_module('event')
+
+
+class FakeApiError(Exception):
+    ''' Stands in for openshift's DynamicApiError '''
+
+
+def memcached(namespace='default'):
+    metadata = dict(name='example', uid='1234', resourceVersion='5')
+    if namespace:
+        metadata['namespace'] = namespace
+    return dict(apiVersion='cache.example.com/v1alpha1', kind='Memcached', metadata=metadata)
+
+
+def params(**kwargs):
+    p = dict(api_version='cache.example.com/v1alpha1', kind='Memcached', name='example', namespace='default',
+             type='Normal', reason='Upgrading', message='Upgrading to 1.2.3', component='ansible-operator')
+    p.update(kwargs)
+    return p
+
+
+class TestBuildEvent(unittest.TestCase):
+
+    def test_namespaced(self):
+        e = event.build_event(memcached(), params(), '2020-10-16T00:00:00Z')
+        self.assertEqual(e['metadata']['namespace'], 'default')
+        self.assertTrue(e['metadata']['name'].startswith('example.'))
+        self.assertEqual(e['involvedObject'], dict(
+            apiVersion='cache.example.com/v1alpha1', kind='Memcached', name='example', namespace='default',
+            uid='1234', resourceVersion='5'))
+        self.assertEqual(e['type'], 'Normal')
+        self.assertEqual(e['reason'], 'Upgrading')
+        self.assertEqual(e['message'], 'Upgrading to 1.2.3')
+        self.assertEqual(e['source'], dict(component='ansible-operator'))
+        self.assertEqual(e['firstTimestamp'], '2020-10-16T00:00:00Z')
+        self.assertEqual(e['lastTimestamp'], '2020-10-16T00:00:00Z')
+        self.assertEqual(e['count'], 1)
+
+    def test_cluster_scoped(self):
+        e = event.build_event(memcached(namespace=None), params(namespace=None, type='Warning'), '')
+        self.assertEqual(e['metadata']['namespace'], 'default')
+        self.assertNotIn('namespace', e['involvedObject'])
+        self.assertEqual(e['type'], 'Warning')
+
+
+class TestMain(unittest.TestCase):
+
+    def setUp(self):
+        self.client = mock.MagicMock()
+        self.module = FakeModule(params())
+        self.get_resource = mock.MagicMock(return_value=(mock.MagicMock(), memcached()))
+        self.events = self.client.resources.get.return_value
+        self.events.create.return_value.to_dict.return_value = dict(kind='Event')
+        for name, value in dict(HAS_K8S=True, DynamicApiError=FakeApiError, get_client=lambda: self.client,
+                                get_resource=self.get_resource,
+                                AnsibleModule=lambda **kwargs: self.module).items():
+            patcher = mock.patch.object(event, name, value, create=True)
+            patcher.start()
+            self.addCleanup(patcher.stop)
+
+    def test_creates_event(self):
+        with self.assertRaises(ExitJson) as ctx:
+            event.main()
+        self.assertEqual(ctx.exception.args[0], dict(changed=True, result=dict(kind='Event')))
+        self.client.resources.get.assert_called_with(api_version='v1', kind='Event')
+        body = self.events.create.call_args[1]['body']
+        self.assertEqual(body['reason'], 'Upgrading')
+        self.assertEqual(self.events.create.call_args[1]['namespace'], 'default')
+
+    def test_check_mode(self):
+        self.module.check_mode = True
+        with self.assertRaises(ExitJson) as ctx:
+            event.main()
+        self.assertTrue(ctx.exception.args[0]['changed'])
+        self.assertEqual(ctx.exception.args[0]['result']['reason'], 'Upgrading')
+        self.events.create.assert_not_called()
+
+    def test_missing_resource(self):
+        self.get_resource.side_effect = FakeApiError('not found')
+        with self.assertRaises(FailJson) as ctx:
+            event.main()
+        self.assertEqual(ctx.exception.args[0]['msg'], 'Failed to get Memcached example: not found')
+
+    def test_create_failure(self):
+        self.events.create.side_effect = FakeApiError('forbidden')
+        with self.assertRaises(FailJson) as ctx:
+            event.main()
+        self.assertEqual(ctx.exception.args[0]['msg'], 'Failed to create event for Memcached example: forbidden')
+
+    def test_missing_libraries(self):
+        with mock.patch.object(event, 'HAS_K8S', False), mock.patch.object(event, 'K8S_IMPORT_ERROR', '', create=True):
+            with self.assertRaises(FailJson) as ctx:
+                event.main()
+        self.assertIn('openshift', ctx.exception.args[0]['msg'])
+
+
+if __name__ == '__main__':
+    unittest.main()
@@ -0,0 +1,151 @@
+# Copyright 2020 The Operator-SDK Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from __future__ import (absolute_import, division, print_function)
+__metaclass__ = type
+
+import unittest
+
+try:
+    from unittest import mock
+except ImportError:
+    import mock
+
+from loader import ExitJson, FailJson, FakeModule, import_module
+
+status = import_module('status')
+
+NOW = '2020-10-16T00:00:00Z'
+EARLIER = '2020-10-15T00:00:00Z'
+
+
+class FakeApiError(Exception):
+    ''' Stands in for openshift's DynamicApiError '''
+
+
+class FakeConflictError(FakeApiError):
+    ''' Stands in for openshift's ConflictError '''
+
+
+def memcached(**status_fields):
+    obj = dict(apiVersion='cache.example.com/v1alpha1', kind='Memcached',
+               metadata=dict(name='example', namespace='default', generation=3))
+    if status_fields:
+        obj['status'] = status_fields
+    return obj
+
+
+def params(**kwargs):
+    p = dict(api_version='cache.example.com/v1alpha1', kind='Memcached', name='example', namespace='default',
+             conditions=None, observed_generation=False, status=None)
+    p.update(kwargs)
+    return p
+
+
+class TestMergeConditions(unittest.TestCase):
+
+    def test_adds_condition(self):
+        conditions = status.merge_conditions(None, [dict(type='Ready', status='True', reason='Running')], NOW)
+        self.assertEqual(conditions, [dict(type='Ready', status='True', reason='Running', lastTransitionTime=NOW)])
+
+    def test_keeps_transition_time_when_status_is_unchanged(self):
+        existing = [dict(type='Ready', status='True', reason='Running', lastTransitionTime=EARLIER)]
+        conditions = status.merge_conditions(existing, [dict(type='Ready', status='True', reason='Scaled')], NOW)
+        self.assertEqual(conditions, [dict(type='Ready', status='True', reason='Scaled', lastTransitionTime=EARLIER)])
+
+    def test_sets_transition_time_when_status_changes(self):
+        existing = [dict(type='Ready', status='True', lastTransitionTime=EARLIER)]
+        conditions = status.merge_conditions(existing, [dict(type='Ready', status='False')], NOW)
+        self.assertEqual(conditions, [dict(type='Ready', status='False', lastTransitionTime=NOW)])
+
+    def test_keeps_other_conditions(self):
+        existing = [dict(type='Degraded', status='False', lastTransitionTime=EARLIER)]
+        conditions = status.merge_conditions(existing, [dict(type='Ready', status='True')], NOW)
+        self.assertEqual([c['type'] for c in conditions], ['Degraded', 'Ready'])
+        self.assertEqual(existing, [dict(type='Degraded', status='False', lastTransitionTime=EARLIER)])
+
+
+class TestDesiredStatus(unittest.TestCase):
+
+    def test_merges_fields(self):
+        obj = memcached(nodes=['a'], replicas=dict(ready=1, desired=2), phase='Pending')
+        got = status.desired_status(obj, params(status=dict(replicas=dict(ready=2), phase=None)), NOW)
+        self.assertEqual(got, dict(nodes=['a'], replicas=dict(ready=2, desired=2)))
+        self.assertEqual(obj['status']['phase'], 'Pending')
+
+    def test_sets_observed_generation(self):
+        got = status.desired_status(memcached(), params(observed_generation=True), NOW)
+        self.assertEqual(got, dict(observedGeneration=3))
+
+
+class TestMain(unittest.TestCase):
+
+    def setUp(self):
+        self.module = FakeModule(params(conditions=[dict(type='Ready', status='True', reason=None, message=None)]))
+        self.resource = mock.MagicMock()
+        self.resource.status.replace.return_value.to_dict.return_value = dict(kind='Memcached')
+        self.get_resource = mock.MagicMock(side_effect=lambda client, p: (self.resource, memcached()))
+        for name, value in dict(HAS_K8S=True, DynamicApiError=FakeApiError, ConflictError=FakeConflictError,
+                                get_client=mock.MagicMock, get_resource=self.get_resource,
+                                now=lambda: NOW, AnsibleModule=lambda **kwargs: self.module).items():
+            patcher = mock.patch.object(status, name, value, create=True)
+            patcher.start()
+            self.addCleanup(patcher.stop)
+        patcher = mock.patch.object(status.time, 'sleep')
+        patcher.start()
+        self.addCleanup(patcher.stop)
+
+    def test_replaces_status(self):
+        with self.assertRaises(ExitJson) as ctx:
+            status.main()
+        self.assertEqual(ctx.exception.args[0], dict(changed=True, result=dict(kind='Memcached')))
+        body = self.resource.status.replace.call_args[1]['body']
+        self.assertEqual(body['status'], dict(conditions=[dict(type='Ready', status='True', lastTransitionTime=NOW)]))
+
+    def test_unchanged(self):
+        ready = dict(type='Ready', status='True', lastTransitionTime=EARLIER)
+        self.get_resource.side_effect = lambda client, p: (self.resource, memcached(conditions=[ready]))
+        with self.assertRaises(ExitJson) as ctx:
+            status.main()
+        self.assertFalse(ctx.exception.args[0]['changed'])
+        self.resource.status.replace.assert_not_called()
+
+    def test_check_mode(self):
+        self.module.check_mode = True
+        with self.assertRaises(ExitJson) as ctx:
+            status.main()
+        self.assertTrue(ctx.exception.args[0]['changed'])
+        self.resource.status.replace.assert_not_called()
+
+    def test_typed_fields(self):
+        self.module.params['status'] = dict(conditions=[])
+        with self.assertRaises(FailJson) as ctx:
+            status.main()
+        self.assertEqual(ctx.exception.args[0]['msg'], 'status.conditions cannot be set directly, use the conditions option')
+
+    def test_retries_conflicts(self):
+        self.resource.status.replace.side_effect = [FakeConflictError('conflict'), mock.DEFAULT]
+        with self.assertRaises(ExitJson):
+            status.main()
+        self.assertEqual(self.get_resource.call_count, 2)
+
+    def test_too_many_conflicts(self):
+        self.resource.status.replace.side_effect = FakeConflictError('conflict')
+        with self.assertRaises(FailJson) as ctx:
+            status.main()
+        self.assertIn('modified concurrently 5 times', ctx.exception.args[0]['msg'])
+
+
+if __name__ == '__main__':
+    unittest.main()
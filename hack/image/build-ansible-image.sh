@@ -12,6 +12,7 @@ trap_add 'rm -rf $TMPDIR' EXIT
 # build the base image
 pushd $TMPDIR
 cp $ROOTDIR/build/ansible-operator-dev-linux-gnu .
+cp -r $ROOTDIR/hack/image/ansible/collections .
 docker build -f $ROOTDIR/hack/image/ansible/Dockerfile -t $1 .
 
 # If using a kind cluster, load the image into all nodes.
@@ -0,0 +1,30 @@
+#!/usr/bin/env bash
+
+# Usage: build-scorecard-test-adapter-image.sh <python|shell> <image>
+
+set -eux
+
+source hack/lib/test_lib.sh
+source hack/lib/image_lib.sh
+
+ROOTDIR="$(pwd)"
+TMPDIR="$(mktemp -d)"
+trap_add 'rm -rf $TMPDIR' EXIT
+
+# build scorecard test adapter
+WD="$(dirname "$(pwd)")"
+GOOS=linux CGO_ENABLED=0 \
+  go build \
+  -gcflags "all=-trimpath=${WD}" \
+  -asmflags "all=-trimpath=${WD}" \
+  -o $TMPDIR/scorecard-test-adapter \
+  images/scorecard-test-adapter/cmd/adapter/main.go
+
+# Build base image
+pushd $TMPDIR
+cp -r $ROOTDIR/images/scorecard-test-adapter/bin .
+
+docker build -f $ROOTDIR/images/scorecard-test-adapter/$1/Dockerfile --build-arg ARCH=$(go env GOARCH) -t $2 .
+# If using a kind cluster, load the image into all nodes.
+load_image_if_kind "$2"
+popd
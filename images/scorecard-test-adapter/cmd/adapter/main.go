@@ -0,0 +1,36 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/operator-framework/operator-sdk/pkg/scorecard"
+)
+
+// The scorecard test adapter runs a test written as a script, ex. in Python or shell, in the
+// scorecard-test-python and scorecard-test-shell images. The path of the script is its first
+// argument, followed by the script's arguments:
+//
+//	entrypoint:
+//	- scorecard-test-adapter
+//	- /tests/has_examples.py
+//
+// The script passes by exiting with status 0 and fails by exiting with status 1. It can inspect the
+// bundle under test in $SCORECARD_BUNDLE_ROOT, and report errors and suggestions by writing JSON
+// to the file in $SCORECARD_RESULT:
+//
+//	{"errors": ["the CSV has no example CRs"], "suggestions": ["add an alm-examples annotation"]}
+func main() {
+	scorecard.ScriptMain()
+}
@@ -15,17 +15,24 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
 	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 	"github.com/operator-framework/operator-sdk/internal/scorecard"
 	"github.com/operator-framework/operator-sdk/internal/scorecard/tests"
+	pkgscorecard "github.com/operator-framework/operator-sdk/pkg/scorecard"
 )
 
 // this is the scorecard test binary that ultimately executes the
@@ -37,6 +44,8 @@ import (
 // this binary to run various tests all from within a single
 // test image.
 
+const serviceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 func main() {
 	entrypoint := os.Args[1:]
 	if len(entrypoint) == 0 {
@@ -69,16 +78,19 @@ func main() {
 		result = tests.StatusDescriptorsTest(bundle)
 	case tests.BasicCheckSpecTest:
 		result = tests.CheckSpecTest(bundle)
+	case tests.BasicCheckMetricsTest:
+		result = runMetricsTest(bundle, entrypoint[1:])
+	case tests.BasicCheckResourcesTest:
+		result = runResourcesTest(bundle, entrypoint[1:])
+	case tests.BasicCheckResilienceTest:
+		result = runResilienceTest(bundle, entrypoint[1:])
+	case tests.ClusterAssertionsTest:
+		result = runAssertionsTest(entrypoint[1:])
 	default:
 		result = printValidTests()
 	}
 
-	prettyJSON, err := json.MarshalIndent(result, "", "    ")
-	if err != nil {
-		log.Fatal("failed to generate json", err)
-	}
-	fmt.Printf("%s\n", string(prettyJSON))
-
+	pkgscorecard.WriteStatus(result)
 }
 
 // printValidTests will print out full list of test names to give a hint to the end user on what the valid tests are
@@ -88,15 +100,104 @@ func printValidTests() scapiv1alpha3.TestStatus {
 	result.Errors = make([]string, 0)
 	result.Suggestions = make([]string, 0)
 
-	str := fmt.Sprintf("Valid tests for this image include: %s, %s, %s, %s, %s, %s",
+	str := fmt.Sprintf("Valid tests for this image include: %s, %s, %s, %s, %s, %s, %s, %s, %s, %s",
 		tests.OLMBundleValidationTest,
 		tests.OLMCRDsHaveValidationTest,
 		tests.OLMCRDsHaveResourcesTest,
 		tests.OLMSpecDescriptorsTest,
 		tests.OLMStatusDescriptorsTest,
-		tests.BasicCheckSpecTest)
+		tests.BasicCheckSpecTest,
+		tests.BasicCheckMetricsTest,
+		tests.BasicCheckResourcesTest,
+		tests.BasicCheckResilienceTest,
+		tests.ClusterAssertionsTest)
 	result.Errors = append(result.Errors, str)
 	return scapiv1alpha3.TestStatus{
 		Results: []scapiv1alpha3.TestResult{result},
 	}
 }
+
+// runMetricsTest runs the metrics test against the operator in the test pod's namespace.
+// The operator's metrics URL may be passed as the first argument.
+func runMetricsTest(bundle *apimanifests.Bundle, args []string) scapiv1alpha3.TestStatus {
+	namespace := readNamespace()
+	url := ""
+	if len(args) != 0 {
+		url = args[0]
+	} else {
+		var err error
+		if url, err = tests.DefaultMetricsURL(bundle, namespace); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	return tests.MetricsTest(context.Background(), bundle, newClient(), namespace, tests.NewMetricsScraper(url))
+}
+
+// runResourcesTest runs the resources test against the operator in the test pod's namespace.
+// The number of reconcile cycles may be passed as the first argument.
+func runResourcesTest(bundle *apimanifests.Bundle, args []string) scapiv1alpha3.TestStatus {
+	namespace := readNamespace()
+	opts := tests.ResourcesTestOptions{Cycles: tests.DefaultReconcileCycles}
+	if len(args) != 0 {
+		cycles, err := strconv.Atoi(args[0])
+		if err != nil || cycles <= 0 {
+			log.Fatalf("invalid number of reconcile cycles %q", args[0])
+		}
+		opts.Cycles = cycles
+	}
+
+	c := newClient()
+	sample, err := tests.NewPodMetricsSampler(bundle, c, namespace)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	return tests.ResourcesTest(context.Background(), bundle, c, namespace, sample, opts)
+}
+
+// runResilienceTest runs the resilience test against the operator in the test pod's namespace.
+// The time to wait for the operator and CRs to recover, ex. "5m", may be passed as the first argument.
+func runResilienceTest(bundle *apimanifests.Bundle, args []string) scapiv1alpha3.TestStatus {
+	opts := tests.ResilienceTestOptions{}
+	if len(args) != 0 {
+		timeout, err := time.ParseDuration(args[0])
+		if err != nil {
+			log.Fatalf("invalid timeout %q: %v", args[0], err)
+		}
+		opts.Timeout = timeout
+	}
+	return tests.ResilienceTest(context.Background(), bundle, newClient(), readNamespace(), opts)
+}
+
+// runAssertionsTest evaluates the assertions file in the bundle against the test pod's namespace.
+// The file's path in the bundle may be passed as the first argument.
+func runAssertionsTest(args []string) scapiv1alpha3.TestStatus {
+	path := tests.DefaultAssertionsFile
+	if len(args) != 0 {
+		path = args[0]
+	}
+	f, err := tests.ReadAssertionsFile(filepath.Join(scorecard.PodBundleRoot, filepath.FromSlash(path)))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	return tests.AssertionsTest(context.Background(), newClient(), readNamespace(), f)
+}
+
+func readNamespace() string {
+	namespace, err := ioutil.ReadFile(serviceAccountNamespacePath)
+	if err != nil {
+		log.Fatalf("error reading namespace: %v", err)
+	}
+	return string(namespace)
+}
+
+func newClient() client.Client {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Fatalf("error getting kubeconfig: %v", err)
+	}
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		log.Fatalf("error creating client: %v", err)
+	}
+	return c
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collections
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// AddDownloadsToDockerfile adds a COPY instruction for the collections downloaded to dir to the
+// Dockerfile at path, which installs them instead of resolving collections from Galaxy.
+func AddDownloadsToDockerfile(path, dir string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := string(b)
+	if !strings.Contains(content, ChecksumsFile) {
+		return fmt.Errorf("%s does not install downloaded collections; re-scaffold it or install "+
+			"${HOME}/collections/*.tar.gz after verifying them with 'sha256sum -c %s'", path, ChecksumsFile)
+	}
+	copyLine := fmt.Sprintf("COPY %s/ ${HOME}/collections/", filepath.ToSlash(filepath.Clean(dir)))
+	if strings.Contains(content, copyLine) {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "COPY requirements") {
+			lines = append(lines[:i+1], append([]string{copyLine + "\n"}, lines[i+1:]...)...)
+			return ioutil.WriteFile(path, []byte(strings.Join(lines, "")), 0644)
+		}
+	}
+	return fmt.Errorf("%s does not copy requirements files; add %q before collections are installed",
+		path, copyLine)
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collections
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultServer is the Galaxy server collections are resolved from if a requirement does not set a source.
+const DefaultServer = "https://galaxy.ansible.com"
+
+// galaxyClient queries the collection versions of Galaxy API v2 servers.
+type galaxyClient struct {
+	client *http.Client
+}
+
+func newGalaxyClient() *galaxyClient {
+	return &galaxyClient{client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// collectionVersion is the Galaxy API v2 description of a collection version.
+type collectionVersion struct {
+	Version     string `json:"version"`
+	DownloadURL string `json:"download_url"`
+	Artifact    struct {
+		SHA256 string `json:"sha256"`
+	} `json:"artifact"`
+	Metadata struct {
+		Dependencies map[string]string `json:"dependencies"`
+	} `json:"metadata"`
+}
+
+// versions returns all versions of collection name on server.
+func (c *galaxyClient) versions(server, name string) ([]string, error) {
+	u, err := collectionURL(server, name, "versions/?page_size=100")
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for u != "" {
+		var page struct {
+			Next    string `json:"next"`
+			Results []struct {
+				Version string `json:"version"`
+			} `json:"results"`
+		}
+		if err := c.get(u, &page); err != nil {
+			return nil, err
+		}
+		for _, r := range page.Results {
+			versions = append(versions, r.Version)
+		}
+		// Some servers return a path relative to the server for the next page.
+		u = page.Next
+		if strings.HasPrefix(u, "/") {
+			u = strings.TrimSuffix(server, "/") + u
+		}
+	}
+	return versions, nil
+}
+
+// version returns version of collection name on server.
+func (c *galaxyClient) version(server, name, version string) (*collectionVersion, error) {
+	u, err := collectionURL(server, name, fmt.Sprintf("versions/%s/", url.PathEscape(version)))
+	if err != nil {
+		return nil, err
+	}
+	cv := &collectionVersion{}
+	if err := c.get(u, cv); err != nil {
+		return nil, err
+	}
+	return cv, nil
+}
+
+// download writes the artifact at u to w.
+func (c *galaxyClient) download(u string, w io.Writer) error {
+	resp, err := c.client.Get(u)
+	if err != nil {
+		return fmt.Errorf("error downloading %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error downloading %s: %s", u, resp.Status)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("error downloading %s: %w", u, err)
+	}
+	return nil
+}
+
+func (c *galaxyClient) get(u string, v interface{}) error {
+	resp, err := c.client.Get(u)
+	if err != nil {
+		return fmt.Errorf("error querying Galaxy: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error querying Galaxy: GET %s: %s", u, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error decoding Galaxy response from %s: %w", u, err)
+	}
+	return nil
+}
+
+// collectionURL returns the Galaxy API v2 URL of path under collection name on server.
+func collectionURL(server, name, path string) (string, error) {
+	namespace, collection, err := splitName(name)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/api/v2/collections/%s/%s/%s", strings.TrimSuffix(server, "/"),
+		url.PathEscape(namespace), url.PathEscape(collection), path), nil
+}
+
+// splitName splits a collection name of the form <namespace>.<collection>.
+func splitName(name string) (namespace, collection string, err error) {
+	parts := strings.Split(name, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid collection name %q: must be of the form <namespace>.<collection>", name)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,335 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package collections locks the Ansible collections an Ansible operator requires to exact versions
+// and checksums, and downloads them for builds without network access.
+package collections
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// RequirementsFile is the requirements file scaffolded for Ansible operators.
+	RequirementsFile = "requirements.yml"
+	// LockFile is the lock file written for RequirementsFile.
+	LockFile = "requirements.lock.yml"
+	// ChecksumsFile is written to the directory collections are downloaded to,
+	// in the format read by 'sha256sum -c'.
+	ChecksumsFile = "SHA256SUMS"
+
+	lockHeader = "# Code generated by operator-sdk collections lock. DO NOT EDIT.\n"
+
+	// maxResolveRounds bounds re-resolving dependencies after a collection's version changes.
+	maxResolveRounds = 100
+)
+
+// Requirement is a collection required by a requirements file.
+type Requirement struct {
+	Name    string
+	Version string
+	Source  string
+}
+
+// LockedCollection is a collection locked to an exact version and checksum.
+type LockedCollection struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Source  string `json:"source"`
+	URL     string `json:"url"`
+	SHA256  string `json:"sha256"`
+}
+
+// Lock is a lock file. It is also a requirements file that 'ansible-galaxy collection install -r'
+// installs the locked versions from.
+type Lock struct {
+	Collections []LockedCollection `json:"collections"`
+}
+
+// FileName returns the name of the artifact of c.
+func (c LockedCollection) FileName() string {
+	return fmt.Sprintf("%s-%s.tar.gz", strings.Replace(c.Name, ".", "-", 1), c.Version)
+}
+
+// ReadRequirements reads the collections required by the requirements file at path.
+func ReadRequirements(path string) ([]Requirement, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Collections []interface{} `json:"collections"`
+	}
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	var reqs []Requirement
+	for _, c := range file.Collections {
+		var req Requirement
+		switch c := c.(type) {
+		case string:
+			req.Name = c
+		case map[string]interface{}:
+			req.Name, _ = c["name"].(string)
+			if v, ok := c["version"]; ok && v != nil {
+				req.Version = fmt.Sprint(v)
+			}
+			req.Source, _ = c["source"].(string)
+		default:
+			return nil, fmt.Errorf("error parsing %s: invalid collection %v", path, c)
+		}
+		if _, _, err := splitName(req.Name); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", path, err)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// Resolve locks reqs and their transitive dependencies to the highest versions matching all
+// requirements on them.
+func Resolve(reqs []Requirement) (*Lock, error) {
+	return resolve(newGalaxyClient(), reqs)
+}
+
+func resolve(c *galaxyClient, reqs []Requirement) (*Lock, error) {
+	versions := map[string][]string{}
+	details := map[string]*collectionVersion{}
+	chosen := map[string]string{}
+	for round := 0; round < maxResolveRounds; round++ {
+		// Collect the requirements of the roots and of the dependencies of the chosen versions.
+		constraints := map[string][]string{}
+		sources := map[string]string{}
+		for _, req := range reqs {
+			constraints[req.Name] = append(constraints[req.Name], req.Version)
+			if _, ok := sources[req.Name]; !ok || req.Source != "" {
+				sources[req.Name] = sourceOr(req.Source)
+			}
+		}
+		for name, version := range chosen {
+			for dep, constraint := range details[name+"@"+version].Metadata.Dependencies {
+				constraints[dep] = append(constraints[dep], constraint)
+				if _, ok := sources[dep]; !ok {
+					sources[dep] = sources[name]
+				}
+			}
+		}
+
+		changed := false
+		for name := range chosen {
+			if _, ok := constraints[name]; !ok {
+				delete(chosen, name)
+				changed = true
+			}
+		}
+		for _, name := range sortedKeys(constraints) {
+			if _, ok := versions[name]; !ok {
+				vs, err := c.versions(sources[name], name)
+				if err != nil {
+					return nil, err
+				}
+				versions[name] = vs
+			}
+			version, err := highestMatching(versions[name], constraints[name])
+			if err != nil {
+				return nil, fmt.Errorf("error resolving collection %s: %w", name, err)
+			}
+			if chosen[name] == version {
+				continue
+			}
+			if _, ok := details[name+"@"+version]; !ok {
+				cv, err := c.version(sources[name], name, version)
+				if err != nil {
+					return nil, err
+				}
+				details[name+"@"+version] = cv
+			}
+			chosen[name] = version
+			changed = true
+		}
+
+		if !changed {
+			lock := &Lock{}
+			for _, name := range sortedKeys(constraints) {
+				cv := details[name+"@"+chosen[name]]
+				lock.Collections = append(lock.Collections, LockedCollection{
+					Name:    name,
+					Version: chosen[name],
+					Source:  sources[name],
+					URL:     cv.DownloadURL,
+					SHA256:  cv.Artifact.SHA256,
+				})
+			}
+			return lock, nil
+		}
+	}
+	return nil, fmt.Errorf("error resolving collections: versions did not converge after %d rounds",
+		maxResolveRounds)
+}
+
+func sourceOr(source string) string {
+	if source == "" {
+		return DefaultServer
+	}
+	return source
+}
+
+func sortedKeys(m map[string][]string) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// highestMatching returns the highest of versions matching all constraints.
+func highestMatching(versions []string, constraints []string) (string, error) {
+	var best *semver.Version
+	for _, v := range versions {
+		sv, err := semver.Parse(v)
+		if err != nil {
+			continue
+		}
+		ok := true
+		for _, constraint := range constraints {
+			if ok, err = matches(constraint, sv); err != nil {
+				return "", err
+			} else if !ok {
+				break
+			}
+		}
+		if ok && (best == nil || sv.GT(*best)) {
+			best = &sv
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("no version matches %s", strings.Join(constraints, " and "))
+	}
+	return best.String(), nil
+}
+
+// matches returns true if v matches an ansible-galaxy version constraint: "*", an exact version,
+// or comma-separated comparisons such as ">=1.0.0,<2.0.0". Pre-releases only match exact versions.
+func matches(constraint string, v semver.Version) (bool, error) {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" || constraint == "*" {
+		return len(v.Pre) == 0, nil
+	}
+	if exact, err := semver.Parse(strings.TrimPrefix(constraint, "==")); err == nil {
+		return v.Equals(exact), nil
+	}
+	if len(v.Pre) != 0 {
+		return false, nil
+	}
+	var parts []string
+	for _, part := range strings.Split(constraint, ",") {
+		parts = append(parts, strings.Replace(strings.TrimSpace(part), " ", "", -1))
+	}
+	r, err := semver.ParseRange(strings.Join(parts, " "))
+	if err != nil {
+		return false, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+	return r(v), nil
+}
+
+// WriteLock writes lock to path.
+func WriteLock(path string, lock *Lock) error {
+	b, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append([]byte(lockHeader), b...), 0644)
+}
+
+// ReadLock reads the lock file at path.
+func ReadLock(path string) (*Lock, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lock := &Lock{}
+	if err := yaml.Unmarshal(b, lock); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return lock, nil
+}
+
+// Download downloads the artifacts of the collections in lock to dir, verifying their checksums,
+// and writes their checksums to ChecksumsFile in dir. Artifacts already in dir are not downloaded again.
+func Download(lock *Lock, dir string) error {
+	return download(newGalaxyClient(), lock, dir)
+}
+
+func download(c *galaxyClient, lock *Lock, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	sums := &bytes.Buffer{}
+	for _, lc := range lock.Collections {
+		path := filepath.Join(dir, lc.FileName())
+		if sum, err := fileSHA256(path); err == nil && sum == lc.SHA256 {
+			fmt.Fprintf(sums, "%s  %s\n", lc.SHA256, lc.FileName())
+			continue
+		}
+		tmp, err := ioutil.TempFile(dir, ".download-")
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		err = c.download(lc.URL, io.MultiWriter(tmp, h))
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			if sum := hex.EncodeToString(h.Sum(nil)); sum != lc.SHA256 {
+				err = fmt.Errorf("checksum of %s is %s, locked checksum is %s", lc.URL, sum, lc.SHA256)
+			}
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), path)
+		}
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+			return err
+		}
+		fmt.Fprintf(sums, "%s  %s\n", lc.SHA256, lc.FileName())
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ChecksumsFile), sums.Bytes(), 0644)
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, bufio.NewReader(f)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
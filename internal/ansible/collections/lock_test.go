@@ -0,0 +1,264 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collections
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGalaxy serves the versions and artifacts of collections, keyed by name and version.
+type fakeGalaxy struct {
+	deps      map[string]map[string]map[string]string
+	artifacts map[string][]byte
+}
+
+func (g fakeGalaxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/download/") {
+		b, ok := g.artifacts[strings.TrimPrefix(r.URL.Path, "/download/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(b)
+		return
+	}
+	var namespace, collection, version string
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v2/collections/"), "/"), "/")
+	if len(parts) < 3 || parts[2] != "versions" {
+		http.NotFound(w, r)
+		return
+	}
+	namespace, collection = parts[0], parts[1]
+	name := namespace + "." + collection
+	if len(parts) == 4 {
+		version = parts[3]
+	}
+	versions, ok := g.deps[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if version == "" {
+		var page struct {
+			Next    *string             `json:"next"`
+			Results []map[string]string `json:"results"`
+		}
+		for v := range versions {
+			page.Results = append(page.Results, map[string]string{"version": v})
+		}
+		_ = json.NewEncoder(w).Encode(page)
+		return
+	}
+	deps, ok := versions[version]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	file := fmt.Sprintf("%s-%s-%s.tar.gz", namespace, collection, version)
+	sum := sha256.Sum256(g.artifacts[file])
+	cv := collectionVersion{Version: version, DownloadURL: "http://" + r.Host + "/download/" + file}
+	cv.Artifact.SHA256 = hex.EncodeToString(sum[:])
+	cv.Metadata.Dependencies = deps
+	_ = json.NewEncoder(w).Encode(cv)
+}
+
+func newFakeGalaxy() fakeGalaxy {
+	return fakeGalaxy{
+		deps: map[string]map[string]map[string]string{
+			"operator_sdk.util": {
+				"0.1.0": {"community.kubernetes": ">=0.9.0"},
+				"0.2.0": {"community.kubernetes": ">=1.0.0,<2.0.0"},
+			},
+			"community.kubernetes": {
+				"0.9.0":       nil,
+				"1.0.0":       nil,
+				"1.1.0":       nil,
+				"2.0.0":       nil,
+				"3.0.0-beta1": nil,
+			},
+		},
+		artifacts: map[string][]byte{
+			"operator_sdk-util-0.2.0.tar.gz":          []byte("util"),
+			"community-kubernetes-1.1.0.tar.gz":       []byte("kubernetes"),
+			"community-kubernetes-0.9.0.tar.gz":       []byte("old kubernetes"),
+			"community-kubernetes-3.0.0-beta1.tar.gz": []byte("beta"),
+		},
+	}
+}
+
+func TestReadRequirements(t *testing.T) {
+	dir, err := ioutil.TempDir("", "collections-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, RequirementsFile)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`---
+collections:
+  - name: community.kubernetes
+    version: "<1.0.0"
+  - operator_sdk.util
+  - name: example.private
+    source: https://galaxy.example.com
+roles:
+  - name: geerlingguy.java
+`), 0644))
+	reqs, err := ReadRequirements(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []Requirement{
+		{Name: "community.kubernetes", Version: "<1.0.0"},
+		{Name: "operator_sdk.util"},
+		{Name: "example.private", Source: "https://galaxy.example.com"},
+	}, reqs)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("collections:\n  - kubernetes\n"), 0644))
+	_, err = ReadRequirements(path)
+	assert.Error(t, err)
+}
+
+func TestMatches(t *testing.T) {
+	testCases := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{"*", "1.2.3", true},
+		{"", "1.2.3", true},
+		{"*", "1.2.3-beta1", false},
+		{"1.2.3", "1.2.3", true},
+		{"==1.2.3", "1.2.4", false},
+		{"1.2.3-beta1", "1.2.3-beta1", true},
+		{">=1.0.0,<2.0.0", "1.5.0", true},
+		{">=1.0.0, <2.0.0", "2.0.0", false},
+		{"!=1.0.0", "1.0.0", false},
+		{">1.0.0", "2.0.0-beta1", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.constraint+" "+tc.version, func(t *testing.T) {
+			ok, err := matches(tc.constraint, semver.MustParse(tc.version))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+	_, err := matches(">=x", semver.MustParse("1.0.0"))
+	assert.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	srv := httptest.NewServer(newFakeGalaxy())
+	defer srv.Close()
+	c := newGalaxyClient()
+
+	lock, err := resolve(c, []Requirement{{Name: "operator_sdk.util", Source: srv.URL}})
+	assert.NoError(t, err)
+	if assert.Len(t, lock.Collections, 2) {
+		assert.Equal(t, "community.kubernetes", lock.Collections[0].Name)
+		assert.Equal(t, "1.1.0", lock.Collections[0].Version)
+		assert.Equal(t, srv.URL, lock.Collections[0].Source)
+		assert.Equal(t, "operator_sdk.util", lock.Collections[1].Name)
+		assert.Equal(t, "0.2.0", lock.Collections[1].Version)
+		assert.Equal(t, srv.URL+"/download/operator_sdk-util-0.2.0.tar.gz", lock.Collections[1].URL)
+	}
+
+	// Pinning the dependency to an old version selects the version of the root allowing it.
+	lock, err = resolve(c, []Requirement{
+		{Name: "operator_sdk.util", Version: "<0.2.0", Source: srv.URL},
+		{Name: "community.kubernetes", Version: "<1.0.0", Source: srv.URL},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, lock.Collections, 2) {
+		assert.Equal(t, "0.9.0", lock.Collections[0].Version)
+		assert.Equal(t, "0.1.0", lock.Collections[1].Version)
+	}
+
+	_, err = resolve(c, []Requirement{
+		{Name: "operator_sdk.util", Version: "0.2.0", Source: srv.URL},
+		{Name: "community.kubernetes", Version: "<1.0.0", Source: srv.URL},
+	})
+	assert.Error(t, err)
+}
+
+func TestDownload(t *testing.T) {
+	g := newFakeGalaxy()
+	srv := httptest.NewServer(g)
+	defer srv.Close()
+	c := newGalaxyClient()
+	dir, err := ioutil.TempDir("", "collections-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	lock, err := resolve(c, []Requirement{{Name: "operator_sdk.util", Source: srv.URL}})
+	assert.NoError(t, err)
+	path := filepath.Join(dir, LockFile)
+	assert.NoError(t, WriteLock(path, lock))
+	lock, err = ReadLock(path)
+	assert.NoError(t, err)
+
+	downloads := filepath.Join(dir, "collections")
+	assert.NoError(t, download(c, lock, downloads))
+	b, err := ioutil.ReadFile(filepath.Join(downloads, "community-kubernetes-1.1.0.tar.gz"))
+	assert.NoError(t, err)
+	assert.Equal(t, "kubernetes", string(b))
+	sums, err := ioutil.ReadFile(filepath.Join(downloads, ChecksumsFile))
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%s  community-kubernetes-1.1.0.tar.gz\n%s  operator_sdk-util-0.2.0.tar.gz\n",
+		lock.Collections[0].SHA256, lock.Collections[1].SHA256), string(sums))
+
+	// An artifact that changed since it was locked is rejected.
+	g.artifacts["community-kubernetes-1.1.0.tar.gz"] = []byte("tampered")
+	assert.NoError(t, os.Remove(filepath.Join(downloads, "community-kubernetes-1.1.0.tar.gz")))
+	assert.Error(t, download(c, lock, downloads))
+	_, err = os.Stat(filepath.Join(downloads, "community-kubernetes-1.1.0.tar.gz"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAddDownloadsToDockerfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "collections-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "Dockerfile")
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`FROM ansible-operator
+COPY requirements*.yml ${HOME}/
+RUN if [ -f ${HOME}/collections/SHA256SUMS ]; then true; fi
+`), 0644))
+	assert.NoError(t, AddDownloadsToDockerfile(path, "collections"))
+	assert.NoError(t, AddDownloadsToDockerfile(path, "collections/"))
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, `FROM ansible-operator
+COPY requirements*.yml ${HOME}/
+COPY collections/ ${HOME}/collections/
+RUN if [ -f ${HOME}/collections/SHA256SUMS ]; then true; fi
+`, string(b))
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`FROM ansible-operator
+COPY requirements.yml ${HOME}/requirements.yml
+RUN ansible-galaxy collection install -r ${HOME}/requirements.yml
+`), 0644))
+	assert.Error(t, AddDownloadsToDockerfile(path, "collections"))
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	ansiblestatus "github.com/operator-framework/operator-sdk/internal/ansible/controller/status"
+)
+
+// lastSuccessfulRunKey is the status key under which the spec hash and completion time
+// of the last successful run of a custom resource are recorded.
+const lastSuccessfulRunKey = "lastSuccessfulRun"
+
+// hashSpec returns the sha256 hash of spec's JSON encoding.
+func hashSpec(spec interface{}) (string, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling spec: %v", err)
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(b)), nil
+}
+
+// setLastSuccessfulRun records specHash and now as the last successful run in crStatus.
+func setLastSuccessfulRun(crStatus *ansiblestatus.Status, specHash string, now time.Time) {
+	crStatus.CustomStatus[lastSuccessfulRunKey] = map[string]interface{}{
+		"specHash":       specHash,
+		"completionTime": now.UTC().Format(time.RFC3339),
+	}
+}
+
+// ranSuccessfullyWithin returns true if u's last run succeeded less than window ago with specHash.
+func ranSuccessfullyWithin(u *unstructured.Unstructured, specHash string, window time.Duration, now time.Time) bool {
+	crStatus := getStatus(u)
+	c := ansiblestatus.GetCondition(crStatus, ansiblestatus.RunningConditionType)
+	if c == nil || c.Status != v1.ConditionTrue || c.Reason != ansiblestatus.SuccessfulReason {
+		return false
+	}
+	run, ok := crStatus.CustomStatus[lastSuccessfulRunKey].(map[string]interface{})
+	if !ok || run["specHash"] != specHash {
+		return false
+	}
+	s, _ := run["completionTime"].(string)
+	completionTime, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return false
+	}
+	return now.Sub(completionTime) < window
+}
+
+// changedDependents records the custom resources whose dependent resources changed since they last ran.
+type changedDependents struct {
+	mu   sync.Mutex
+	keys map[types.NamespacedName]struct{}
+}
+
+func newChangedDependents() *changedDependents {
+	return &changedDependents{keys: map[types.NamespacedName]struct{}{}}
+}
+
+func (c *changedDependents) mark(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys[key] = struct{}{}
+}
+
+// take returns true if key's dependents changed, and forgets the change.
+func (c *changedDependents) take(key types.NamespacedName) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.keys[key]
+	delete(c.keys, key)
+	return ok
+}
+
+// dependentsController marks the custom resources enqueued by every watch added to it, which
+// the proxy adds for dependent resources, as having changed dependents.
+type dependentsController struct {
+	controller.Controller
+	changed *changedDependents
+}
+
+func (c dependentsController) Watch(src source.Source, h handler.EventHandler, predicates ...predicate.Predicate) error {
+	return c.Controller.Watch(src, markingHandler{EventHandler: h, changed: c.changed}, predicates...)
+}
+
+// markingHandler marks the requests h enqueues before they are added to the queue.
+type markingHandler struct {
+	handler.EventHandler
+	changed *changedDependents
+}
+
+func (h markingHandler) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Create(e, markingQueue{q, h.changed})
+}
+
+func (h markingHandler) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Update(e, markingQueue{q, h.changed})
+}
+
+func (h markingHandler) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Delete(e, markingQueue{q, h.changed})
+}
+
+func (h markingHandler) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Generic(e, markingQueue{q, h.changed})
+}
+
+type markingQueue struct {
+	workqueue.RateLimitingInterface
+	changed *changedDependents
+}
+
+func (q markingQueue) mark(item interface{}) {
+	if req, ok := item.(reconcile.Request); ok {
+		q.changed.mark(req.NamespacedName)
+	}
+}
+
+func (q markingQueue) Add(item interface{}) {
+	q.mark(item)
+	q.RateLimitingInterface.Add(item)
+}
+
+func (q markingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.mark(item)
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+func (q markingQueue) AddRateLimited(item interface{}) {
+	q.mark(item)
+	q.RateLimitingInterface.AddRateLimited(item)
+}
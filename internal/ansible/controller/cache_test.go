@@ -0,0 +1,107 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	ansiblestatus "github.com/operator-framework/operator-sdk/internal/ansible/controller/status"
+)
+
+func TestHashSpec(t *testing.T) {
+	a, err := hashSpec(map[string]interface{}{"size": 1, "name": "a"})
+	assert.NoError(t, err)
+	b, err := hashSpec(map[string]interface{}{"name": "a", "size": 1})
+	assert.NoError(t, err)
+	c, err := hashSpec(map[string]interface{}{"name": "a", "size": 2})
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
+
+func TestRanSuccessfullyWithin(t *testing.T) {
+	now := time.Now()
+	newObject := func(reason string, hash string, completed time.Time) *unstructured.Unstructured {
+		crStatus := ansiblestatus.CreateFromMap(map[string]interface{}{})
+		c := ansiblestatus.NewCondition(ansiblestatus.RunningConditionType, v1.ConditionTrue, nil, reason, "")
+		ansiblestatus.SetCondition(&crStatus, *c)
+		setLastSuccessfulRun(&crStatus, hash, completed)
+		return &unstructured.Unstructured{Object: map[string]interface{}{"status": crStatus.GetJSONMap()}}
+	}
+	testCases := []struct {
+		name     string
+		u        *unstructured.Unstructured
+		expected bool
+	}{
+		{
+			name:     "no status",
+			u:        &unstructured.Unstructured{Object: map[string]interface{}{}},
+			expected: false,
+		},
+		{
+			name:     "recent successful run",
+			u:        newObject(ansiblestatus.SuccessfulReason, "sha256:a", now.Add(-time.Minute)),
+			expected: true,
+		},
+		{
+			name:     "spec changed",
+			u:        newObject(ansiblestatus.SuccessfulReason, "sha256:b", now.Add(-time.Minute)),
+			expected: false,
+		},
+		{
+			name:     "window expired",
+			u:        newObject(ansiblestatus.SuccessfulReason, "sha256:a", now.Add(-time.Hour)),
+			expected: false,
+		},
+		{
+			name:     "run in progress",
+			u:        newObject(ansiblestatus.RunningReason, "sha256:a", now.Add(-time.Minute)),
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ranSuccessfullyWithin(tc.u, "sha256:a", 10*time.Minute, now))
+		})
+	}
+}
+
+func TestSkipRun(t *testing.T) {
+	crStatus := ansiblestatus.CreateFromMap(map[string]interface{}{})
+	c := ansiblestatus.NewCondition(ansiblestatus.RunningConditionType, v1.ConditionTrue, nil,
+		ansiblestatus.SuccessfulReason, "")
+	ansiblestatus.SetCondition(&crStatus, *c)
+	setLastSuccessfulRun(&crStatus, "sha256:a", time.Now())
+	u := &unstructured.Unstructured{Object: map[string]interface{}{"status": crStatus.GetJSONMap()}}
+	key := types.NamespacedName{Namespace: "default", Name: "test"}
+
+	r := &AnsibleOperatorReconciler{ManageStatus: true}
+	assert.False(t, r.skipRun(u, key, "sha256:a", false), "cache disabled")
+
+	r.ReconcileCacheWindow = 10 * time.Minute
+	r.changedDependents = newChangedDependents()
+	assert.True(t, r.skipRun(u, key, "sha256:a", false))
+	assert.False(t, r.skipRun(u, key, "sha256:a", true), "resource deleted")
+
+	r.changedDependents.mark(key)
+	assert.False(t, r.skipRun(u, key, "sha256:a", false), "dependent changed")
+	assert.True(t, r.skipRun(u, key, "sha256:a", false), "dependent change consumed")
+}
@@ -30,11 +30,13 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	ctrlpredicate "sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/operator-framework/operator-sdk/internal/ansible/events"
 	"github.com/operator-framework/operator-sdk/internal/ansible/predicate"
 	"github.com/operator-framework/operator-sdk/internal/ansible/runner"
+	"github.com/operator-framework/operator-sdk/internal/util/health"
 )
 
 var log = logf.Log.WithName("ansible-controller")
@@ -52,6 +54,8 @@ type Options struct {
 	WatchClusterScopedResources bool
 	MaxConcurrentReconciles     int
 	Selector                    metav1.LabelSelector
+	ReconcileCacheWindow        time.Duration
+	ReconcileTracker            *health.Tracker
 }
 
 // Add - Creates a new ansible operator controller and adds it to the manager
@@ -72,6 +76,11 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 		ManageStatus:     options.ManageStatus,
 		AnsibleDebugLogs: options.AnsibleDebugLogs,
 		APIReader:        mgr.GetAPIReader(),
+
+		ReconcileCacheWindow: options.ReconcileCacheWindow,
+	}
+	if options.ReconcileCacheWindow > 0 {
+		aor.changedDependents = newChangedDependents()
 	}
 
 	scheme := mgr.GetScheme()
@@ -88,10 +97,15 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 		os.Exit(1)
 	}
 
+	var r reconcile.Reconciler = aor
+	if options.ReconcileTracker != nil {
+		r = options.ReconcileTracker.Reconciler(aor)
+	}
+
 	//Create new controller runtime controller and set the controller to watch GVK.
 	c, err := controller.New(fmt.Sprintf("%v-controller", strings.ToLower(options.GVK.Kind)), mgr,
 		controller.Options{
-			Reconciler:              aor,
+			Reconciler:              r,
 			MaxConcurrentReconciles: options.MaxConcurrentReconciles,
 		})
 	if err != nil {
@@ -118,5 +132,10 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 		os.Exit(1)
 	}
 
+	// Dependent watches are added to the returned controller, so wrap it to track
+	// dependent changes that must bypass the reconcile cache.
+	if aor.changedDependents != nil {
+		c = dependentsController{Controller: c, changed: aor.changedDependents}
+	}
 	return &c
 }
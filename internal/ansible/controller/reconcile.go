@@ -60,6 +60,11 @@ type AnsibleOperatorReconciler struct {
 	ReconcilePeriod  time.Duration
 	ManageStatus     bool
 	AnsibleDebugLogs bool
+	// ReconcileCacheWindow, if non-zero, is how long after a successful run the playbook is
+	// not run again for an unchanged spec unless a dependent resource changed.
+	ReconcileCacheWindow time.Duration
+
+	changedDependents *changedDependents
 }
 
 // Reconcile - handle the event.
@@ -128,6 +133,17 @@ func (r *AnsibleOperatorReconciler) Reconcile(request reconcile.Request) (reconc
 		u.Object["spec"] = map[string]interface{}{}
 	}
 
+	specHash, err := hashSpec(u.Object["spec"])
+	if err != nil {
+		logger.Error(err, "Unable to hash spec")
+		return reconcileResult, err
+	}
+	if r.skipRun(u, request.NamespacedName, specHash, deleted) {
+		logger.V(1).Info("Spec and dependent resources unchanged since last successful run, skipping reconciliation",
+			"specHash", specHash)
+		return reconcileResult, nil
+	}
+
 	if r.ManageStatus {
 		errmark := r.markRunning(u, request.NamespacedName)
 		if errmark != nil {
@@ -263,7 +279,7 @@ func (r *AnsibleOperatorReconciler) Reconcile(request reconcile.Request) (reconc
 		}
 	}
 	if r.ManageStatus {
-		errmark := r.markDone(u, request.NamespacedName, statusEvent, failureMessages)
+		errmark := r.markDone(u, request.NamespacedName, specHash, statusEvent, failureMessages)
 		if errmark != nil {
 			logger.Error(errmark, "Failed to mark status done")
 		}
@@ -368,8 +384,23 @@ func (r *AnsibleOperatorReconciler) markError(u *unstructured.Unstructured, name
 	return r.Client.Status().Update(context.TODO(), u)
 }
 
+// skipRun returns true if the playbook does not need to run for u because its spec, hashed as specHash,
+// and dependent resources have not changed since a successful run within the reconcile cache window.
+func (r *AnsibleOperatorReconciler) skipRun(u *unstructured.Unstructured, key types.NamespacedName,
+	specHash string, deleted bool) bool {
+	if r.ReconcileCacheWindow <= 0 || !r.ManageStatus || deleted {
+		return false
+	}
+	// Always consume a dependent change, so that changes made while this run is in progress
+	// trigger the next one.
+	if r.changedDependents != nil && r.changedDependents.take(key) {
+		return false
+	}
+	return ranSuccessfullyWithin(u, specHash, r.ReconcileCacheWindow, time.Now())
+}
+
 func (r *AnsibleOperatorReconciler) markDone(u *unstructured.Unstructured, namespacedName types.NamespacedName,
-	statusEvent eventapi.StatusJobEvent, failureMessages eventapi.FailureMessages) error {
+	specHash string, statusEvent eventapi.StatusJobEvent, failureMessages eventapi.FailureMessages) error {
 	logger := logf.Log.WithName("markDone")
 	// Get the latest resource to prevent updating a stale status.
 	if err := r.APIReader.Get(context.TODO(), namespacedName, u); err != nil {
@@ -399,6 +430,7 @@ func (r *AnsibleOperatorReconciler) markDone(u *unstructured.Unstructured, names
 			strings.Join(failureMessages, "\n"),
 		)
 		ansiblestatus.SetCondition(&crStatus, *c)
+		delete(crStatus.CustomStatus, lastSuccessfulRunKey)
 	} else {
 		metrics.ReconcileSucceeded(r.GVK.String())
 		c := ansiblestatus.NewCondition(
@@ -411,6 +443,9 @@ func (r *AnsibleOperatorReconciler) markDone(u *unstructured.Unstructured, names
 		// Remove the failure condition if set, because this completed successfully.
 		ansiblestatus.RemoveCondition(&crStatus, ansiblestatus.FailureConditionType)
 		ansiblestatus.SetCondition(&crStatus, *c)
+		if r.ReconcileCacheWindow > 0 {
+			setLastSuccessfulRun(&crStatus, specHash, time.Now())
+		}
 	}
 	// This needs the status subresource to be enabled by default.
 	u.Object["status"] = crStatus.GetJSONMap()
@@ -35,6 +35,11 @@ type Flags struct {
 	LeaderElectionID        string
 	LeaderElectionNamespace string
 	AnsibleArgs             string
+	ReconcileStallTimeout   time.Duration
+	ProxyCacheGVKs          []string
+	ProxyCacheBypass        []string
+	ProxyCacheSelector      string
+	ProxyCacheMaxObjects    int
 }
 
 const AnsibleRolesPathEnvVar = "ANSIBLE_ROLES_PATH"
@@ -105,4 +110,32 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 		"",
 		"Ansible args. Allows user to specify arbitrary arguments for ansible-based operators.",
 	)
+	flagSet.DurationVar(&f.ReconcileStallTimeout,
+		"reconcile-stall-timeout",
+		0,
+		"Fail the liveness probe when all reconcile workers of a controller have been busy without any "+
+			"reconcile finishing for longer than this duration. Zero disables the check.",
+	)
+	flagSet.StringSliceVar(&f.ProxyCacheGVKs,
+		"proxy-cache-gvks",
+		nil,
+		"Kinds the proxy serves from its cache, formatted as <group>/<version>/<kind> or <version>/<kind> "+
+			"for core kinds. If unset, all kinds are cached.",
+	)
+	flagSet.StringSliceVar(&f.ProxyCacheBypass,
+		"proxy-cache-bypass",
+		nil,
+		"Kinds the proxy never serves from its cache, formatted like --proxy-cache-gvks",
+	)
+	flagSet.StringVar(&f.ProxyCacheSelector,
+		"proxy-cache-selector",
+		"",
+		"Label selector restricting the proxy cache to matching objects. Requests for other objects "+
+			"are sent to the API server.",
+	)
+	flagSet.IntVar(&f.ProxyCacheMaxObjects,
+		"proxy-cache-max-objects",
+		0,
+		"Number of objects of a kind above which the proxy stops caching the kind. Zero disables the limit.",
+	)
 }
@@ -32,7 +32,6 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/controllermap"
@@ -45,13 +44,14 @@ type marshaler interface {
 
 type cacheResponseHandler struct {
 	next              http.Handler
-	informerCache     cache.Cache
+	informerCache     client.Reader
 	restMapper        meta.RESTMapper
 	watchedNamespaces map[string]interface{}
 	cMap              *controllermap.ControllerMap
 	injectOwnerRef    bool
 	apiResources      *apiResources
 	skipPathRegexp    []*regexp.Regexp
+	cacheOptions      CacheOptions
 }
 
 func (c *cacheResponseHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
@@ -156,6 +156,10 @@ func (c *cacheResponseHandler) skipCacheLookup(r *k8sRequest.RequestInfo, gvk sc
 	if skip {
 		return true
 	}
+	if !c.cacheOptions.cacheable(gvk) {
+		log.V(1).Info("Skipping, because gvk is not cached", "GVK", gvk)
+		return true
+	}
 
 	owner, err := getRequestOwnerRef(req)
 	if err != nil {
@@ -0,0 +1,221 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// filteredCache is a read-only cache of the objects matching a label selector, with informers
+// started on first use like the manager's cache. Kinds with more objects than a limit are not
+// cached. Requests the cache cannot answer completely return an error, so they are sent to the
+// API server instead.
+type filteredCache struct {
+	client     dynamic.Interface
+	restMapper meta.RESTMapper
+	selector   labels.Selector
+	maxObjects int
+	// namespace is the namespace informers of namespaced kinds are restricted to, if only one
+	// namespace is watched.
+	namespace string
+
+	mu        sync.Mutex
+	informers map[informerKey]*filteredInformer
+}
+
+type informerKey struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+type filteredInformer struct {
+	informer toolscache.SharedIndexInformer
+	stop     chan struct{}
+	// tooLarge is set if the informer was stopped because it held more than the limit of objects.
+	tooLarge bool
+}
+
+var _ client.Reader = &filteredCache{}
+
+func newFilteredCache(c dynamic.Interface, restMapper meta.RESTMapper, selector labels.Selector,
+	maxObjects int, watchedNamespaces []string) *filteredCache {
+	fc := &filteredCache{
+		client:     c,
+		restMapper: restMapper,
+		selector:   selector,
+		maxObjects: maxObjects,
+		informers:  map[informerKey]*filteredInformer{},
+	}
+	if len(watchedNamespaces) == 1 {
+		fc.namespace = watchedNamespaces[0]
+	}
+	return fc
+}
+
+// Get gets the object at key from the cache.
+func (c *filteredCache) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("filtered cache only supports unstructured objects, got %T", obj)
+	}
+	informer, mapping, err := c.informerFor(ctx, u.GroupVersionKind())
+	if err != nil {
+		return err
+	}
+	storeKey := key.Name
+	if key.Namespace != "" {
+		storeKey = key.Namespace + "/" + key.Name
+	}
+	item, exists, err := informer.GetIndexer().GetByKey(storeKey)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// The object may exist without matching the selector.
+		return apierrors.NewNotFound(mapping.Resource.GroupResource(), key.Name)
+	}
+	u.Object = item.(*unstructured.Unstructured).DeepCopy().Object
+	return nil
+}
+
+// List lists the objects matching opts from the cache. Only label selectors requiring everything
+// the cache's selector requires are supported, since the cache holds no other objects.
+func (c *filteredCache) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	ul, ok := list.(*unstructured.UnstructuredList)
+	if !ok {
+		return fmt.Errorf("filtered cache only supports unstructured lists, got %T", list)
+	}
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.FieldSelector != nil && !listOpts.FieldSelector.Empty() {
+		return fmt.Errorf("filtered cache does not support field selectors")
+	}
+	if !selectsSubset(listOpts.LabelSelector, c.selector) {
+		return fmt.Errorf("label selector %v may select objects not matching the cache's selector %v",
+			listOpts.LabelSelector, c.selector)
+	}
+
+	gvk := ul.GroupVersionKind()
+	gvk.Kind = gvk.Kind[:len(gvk.Kind)-len("List")]
+	informer, _, err := c.informerFor(ctx, gvk)
+	if err != nil {
+		return err
+	}
+	var items []interface{}
+	if listOpts.Namespace != "" {
+		items, err = informer.GetIndexer().ByIndex(toolscache.NamespaceIndex, listOpts.Namespace)
+		if err != nil {
+			return err
+		}
+	} else {
+		items = informer.GetIndexer().List()
+	}
+	ul.Items = nil
+	for _, item := range items {
+		u := item.(*unstructured.Unstructured)
+		if listOpts.LabelSelector == nil || listOpts.LabelSelector.Matches(labels.Set(u.GetLabels())) {
+			ul.Items = append(ul.Items, *u.DeepCopy())
+		}
+	}
+	return nil
+}
+
+// selectsSubset returns true if every object selected by s is also selected by cacheSelector,
+// because s has all of cacheSelector's requirements.
+func selectsSubset(s, cacheSelector labels.Selector) bool {
+	required, _ := cacheSelector.Requirements()
+	if len(required) == 0 {
+		return true
+	}
+	if s == nil {
+		return false
+	}
+	have, _ := s.Requirements()
+	for _, r := range required {
+		found := false
+		for _, h := range have {
+			if h.String() == r.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// informerFor returns the synced informer of gvk, starting it if needed.
+func (c *filteredCache) informerFor(ctx context.Context,
+	gvk schema.GroupVersionKind) (toolscache.SharedIndexInformer, *meta.RESTMapping, error) {
+	mapping, err := c.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := informerKey{gvr: mapping.Resource}
+	if mapping.Scope.Name() != meta.RESTScopeNameRoot && c.namespace != "" {
+		key.namespace = c.namespace
+	}
+
+	c.mu.Lock()
+	fi, ok := c.informers[key]
+	if !ok {
+		fi = &filteredInformer{stop: make(chan struct{})}
+		fi.informer = dynamicinformer.NewFilteredDynamicInformer(c.client, key.gvr, key.namespace, 0,
+			toolscache.Indexers{toolscache.NamespaceIndex: toolscache.MetaNamespaceIndexFunc},
+			func(o *metav1.ListOptions) { o.LabelSelector = c.selector.String() }).Informer()
+		c.informers[key] = fi
+		go fi.informer.Run(fi.stop)
+	}
+	informer, tooLarge := fi.informer, fi.tooLarge
+	c.mu.Unlock()
+	if tooLarge {
+		return nil, nil, fmt.Errorf("%v has more than %d objects and is not cached", gvk, c.maxObjects)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cacheEstablishmentTimeout)
+	defer cancel()
+	if !toolscache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return nil, nil, fmt.Errorf("timed out waiting for the cache of %v to sync", gvk)
+	}
+	if c.maxObjects > 0 && len(informer.GetStore().ListKeys()) > c.maxObjects {
+		c.mu.Lock()
+		if !fi.tooLarge {
+			// Release the informer's objects; the kind is served by the API server from now on.
+			fi.tooLarge = true
+			fi.informer = nil
+			close(fi.stop)
+			log.Info("Not caching kind with too many objects", "GVK", gvk, "maxObjects", c.maxObjects)
+		}
+		c.mu.Unlock()
+		return nil, nil, fmt.Errorf("%v has more than %d objects and is not cached", gvk, c.maxObjects)
+	}
+	return informer, mapping, nil
+}
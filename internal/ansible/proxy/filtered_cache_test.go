@@ -0,0 +1,135 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var configMapGVK = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+func newConfigMap(name string, lbls map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(configMapGVK)
+	u.SetNamespace("default")
+	u.SetName(name)
+	u.SetLabels(lbls)
+	return u
+}
+
+func newTestFilteredCache(selector string, maxObjects int, objs ...runtime.Object) *filteredCache {
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(configMapGVK, meta.RESTScopeNamespace)
+	sel, _ := labels.Parse(selector)
+	c := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), objs...)
+	return newFilteredCache(c, restMapper, sel, maxObjects, []string{"default"})
+}
+
+func TestParseCacheOptions(t *testing.T) {
+	o, err := ParseCacheOptions([]string{"v1/Secret", "apps/v1/Deployment"}, []string{"v1/ConfigMap"},
+		"app=memcached", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []schema.GroupVersionKind{
+		{Version: "v1", Kind: "Secret"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}, o.GVKs)
+	assert.Equal(t, []schema.GroupVersionKind{configMapGVK}, o.Bypass)
+	assert.Equal(t, "app=memcached", o.Selector.String())
+	assert.True(t, o.filtered())
+	assert.True(t, o.cacheable(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}))
+	assert.False(t, o.cacheable(configMapGVK))
+	assert.False(t, o.cacheable(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}))
+
+	o, err = ParseCacheOptions(nil, []string{"v1/ConfigMap"}, "", 0)
+	assert.NoError(t, err)
+	assert.False(t, o.filtered())
+	assert.True(t, o.cacheable(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}))
+
+	for _, invalid := range []string{"Secret", "v1/", "apps/v1/v2/Deployment"} {
+		_, err = ParseCacheOptions([]string{invalid}, nil, "", 0)
+		assert.Error(t, err, invalid)
+	}
+	_, err = ParseCacheOptions(nil, nil, "app in (", 0)
+	assert.Error(t, err)
+	_, err = ParseCacheOptions(nil, nil, "", -1)
+	assert.Error(t, err)
+}
+
+func TestFilteredCacheGet(t *testing.T) {
+	c := newTestFilteredCache("app=memcached", 0,
+		newConfigMap("labeled", map[string]string{"app": "memcached"}),
+		newConfigMap("unlabeled", nil))
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(configMapGVK)
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "labeled"}, u))
+	assert.Equal(t, "labeled", u.GetName())
+
+	err := c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "unlabeled"}, u)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestFilteredCacheList(t *testing.T) {
+	c := newTestFilteredCache("app=memcached", 0,
+		newConfigMap("a", map[string]string{"app": "memcached", "tier": "cache"}),
+		newConfigMap("b", map[string]string{"app": "memcached"}),
+		newConfigMap("c", nil))
+
+	list := func(sel string) (*unstructured.UnstructuredList, error) {
+		ul := &unstructured.UnstructuredList{}
+		ul.SetGroupVersionKind(configMapGVK.GroupVersion().WithKind("ConfigMapList"))
+		s, err := labels.Parse(sel)
+		assert.NoError(t, err)
+		return ul, c.List(context.TODO(), ul, client.InNamespace("default"), client.MatchingLabelsSelector{Selector: s})
+	}
+
+	ul, err := list("app=memcached,tier=cache")
+	assert.NoError(t, err)
+	if assert.Len(t, ul.Items, 1) {
+		assert.Equal(t, "a", ul.Items[0].GetName())
+	}
+	ul, err = list("app=memcached")
+	assert.NoError(t, err)
+	assert.Len(t, ul.Items, 2)
+
+	// The cache cannot answer lists that may select objects outside of its selector.
+	_, err = list("tier=cache")
+	assert.Error(t, err)
+	_, err = list("")
+	assert.Error(t, err)
+}
+
+func TestFilteredCacheMaxObjects(t *testing.T) {
+	c := newTestFilteredCache("", 1, newConfigMap("a", nil), newConfigMap("b", nil))
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(configMapGVK)
+	err := c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "a"}, u)
+	assert.Error(t, err)
+	assert.False(t, apierrors.IsNotFound(err))
+	err = c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "a"}, u)
+	assert.Error(t, err)
+}
@@ -63,6 +63,11 @@ func (i *injectOwnerReferenceHandler) ServeHTTP(w http.ResponseWriter, req *http
 			// Don't inject owner ref if we are POSTing to a subresource
 			break
 		}
+		if isEvent(r) {
+			// Events refer to the owner through their involvedObject. Owning and watching them
+			// would requeue the owner for every event it emits.
+			break
+		}
 
 		if i.restMapper == nil {
 			i.restMapper = meta.NewDefaultRESTMapper([]schema.GroupVersion{schema.GroupVersion{
@@ -186,3 +191,8 @@ func (i *injectOwnerReferenceHandler) ServeHTTP(w http.ResponseWriter, req *http
 	}
 	i.next.ServeHTTP(w, req)
 }
+
+// isEvent returns true if r is a request for core or events.k8s.io Events.
+func isEvent(r *k8sRequest.RequestInfo) bool {
+	return r.Resource == "events" && (r.APIGroup == "" || r.APIGroup == "events.k8s.io")
+}
@@ -31,10 +31,13 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -82,10 +85,89 @@ type Options struct {
 	ControllerMap     *controllermap.ControllerMap
 	WatchedNamespaces []string
 	DisableCache      bool
+	CacheOptions      CacheOptions
 	OwnerInjection    bool
 	LogRequests       bool
 }
 
+// CacheOptions configures which requests the proxy serves from its cache, to bound the memory
+// the cache uses in large clusters.
+type CacheOptions struct {
+	// GVKs, if non-empty, are the only kinds served from the cache.
+	GVKs []schema.GroupVersionKind
+	// Bypass are kinds never served from the cache.
+	Bypass []schema.GroupVersionKind
+	// Selector, if non-nil, restricts the cache to objects matching it. Requests for other
+	// objects are sent to the API server.
+	Selector labels.Selector
+	// MaxObjects, if non-zero, is the number of objects of a kind above which the kind is no
+	// longer cached.
+	MaxObjects int
+}
+
+// ParseCacheOptions returns the CacheOptions for kinds to cache and bypass formatted as
+// "<group>/<version>/<kind>" or "<version>/<kind>" for core kinds, a label selector, and an
+// object limit.
+func ParseCacheOptions(gvks, bypass []string, selector string, maxObjects int) (CacheOptions, error) {
+	o := CacheOptions{MaxObjects: maxObjects}
+	var err error
+	if o.GVKs, err = parseGVKs(gvks); err != nil {
+		return o, err
+	}
+	if o.Bypass, err = parseGVKs(bypass); err != nil {
+		return o, err
+	}
+	if selector != "" {
+		if o.Selector, err = labels.Parse(selector); err != nil {
+			return o, fmt.Errorf("invalid cache selector %q: %w", selector, err)
+		}
+	}
+	if maxObjects < 0 {
+		return o, fmt.Errorf("invalid cache object limit %d: must not be negative", maxObjects)
+	}
+	return o, nil
+}
+
+func parseGVKs(ss []string) ([]schema.GroupVersionKind, error) {
+	var gvks []schema.GroupVersionKind
+	for _, s := range ss {
+		i := strings.LastIndex(s, "/")
+		if i < 0 || i == len(s)-1 {
+			return nil, fmt.Errorf("invalid kind %q: must be <group>/<version>/<kind> or <version>/<kind>", s)
+		}
+		gv, err := schema.ParseGroupVersion(s[:i])
+		if err != nil || gv.Version == "" {
+			return nil, fmt.Errorf("invalid kind %q: must be <group>/<version>/<kind> or <version>/<kind>", s)
+		}
+		gvks = append(gvks, gv.WithKind(s[i+1:]))
+	}
+	return gvks, nil
+}
+
+// cacheable returns true if objects of gvk may be served from the cache.
+func (o CacheOptions) cacheable(gvk schema.GroupVersionKind) bool {
+	for _, bypass := range o.Bypass {
+		if bypass == gvk {
+			return false
+		}
+	}
+	if len(o.GVKs) == 0 {
+		return true
+	}
+	for _, cached := range o.GVKs {
+		if cached == gvk {
+			return true
+		}
+	}
+	return false
+}
+
+// filtered returns true if the cache is restricted by a selector or an object limit, which the
+// manager's cache does not support.
+func (o CacheOptions) filtered() bool {
+	return (o.Selector != nil && !o.Selector.Empty()) || o.MaxObjects > 0
+}
+
 // Run will start a proxy server in a go routine that returns on the error
 // channel if something is not correct on startup. Run will not return until
 // the network socket is listening.
@@ -121,7 +203,7 @@ func Run(done chan error, o Options) error {
 		discoveryClient:  discoveryClient,
 	}
 
-	if o.Cache == nil && !o.DisableCache {
+	if o.Cache == nil && !o.DisableCache && !o.CacheOptions.filtered() {
 		// Need to initialize cache since we don't have one
 		log.Info("Initializing and starting informer cache...")
 		informerCache, err := cache.New(o.KubeConfig, cache.Options{})
@@ -144,6 +226,22 @@ func Run(done chan error, o Options) error {
 		o.Cache = informerCache
 	}
 
+	var informerCache client.Reader = o.Cache
+	if !o.DisableCache && o.CacheOptions.filtered() {
+		log.Info("Using a filtered cache", "selector", o.CacheOptions.Selector,
+			"maxObjects", o.CacheOptions.MaxObjects)
+		dynamicClient, err := dynamic.NewForConfig(o.KubeConfig)
+		if err != nil {
+			return err
+		}
+		selector := o.CacheOptions.Selector
+		if selector == nil {
+			selector = labels.Everything()
+		}
+		informerCache = newFilteredCache(dynamicClient, o.RESTMapper, selector, o.CacheOptions.MaxObjects,
+			o.WatchedNamespaces)
+	}
+
 	// Remove the authorization header so the proxy can correctly inject the header.
 	server.Handler = removeAuthorizationHeader(server.Handler)
 
@@ -168,13 +266,14 @@ func Run(done chan error, o Options) error {
 		}
 		server.Handler = &cacheResponseHandler{
 			next:              server.Handler,
-			informerCache:     o.Cache,
+			informerCache:     informerCache,
 			restMapper:        o.RESTMapper,
 			watchedNamespaces: watchedNamespaceMap,
 			cMap:              o.ControllerMap,
 			injectOwnerRef:    o.OwnerInjection,
 			apiResources:      resources,
 			skipPathRegexp:    autoSkipCacheRegexp,
+			cacheOptions:      o.CacheOptions,
 		}
 	}
 
@@ -292,6 +292,8 @@ func (r *runner) isFinalizerRun(u *unstructured.Unstructured) bool {
 // { "ansible_operator_meta": {
 //      "name": <object_name>,
 //      "namespace": <object_namespace>,
+//      "api_version": <object_api_version>,
+//      "kind": <object_kind>,
 //   },
 //   <cr_spec_fields_as_snake_case>,
 //   <watch vars>,
@@ -322,7 +324,12 @@ func (r *runner) makeParameters(u *unstructured.Unstructured) map[string]interfa
 		}
 	}
 
-	parameters["ansible_operator_meta"] = map[string]string{"namespace": u.GetNamespace(), "name": u.GetName()}
+	parameters["ansible_operator_meta"] = map[string]string{
+		"namespace":   u.GetNamespace(),
+		"name":        u.GetName(),
+		"api_version": r.GVK.GroupVersion().String(),
+		"kind":        r.GVK.Kind,
+	}
 
 	objKey := escapeAnsibleKey(fmt.Sprintf("_%v_%v", r.GVK.Group, strings.ToLower(r.GVK.Kind)))
 	parameters[objKey] = u.Object
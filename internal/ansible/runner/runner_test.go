@@ -185,6 +185,11 @@ func TestNew(t *testing.T) {
 				if _, ok := parameters[tc.desiredObjectKey]; !ok {
 					t.Fatalf("Did not find expected objKey %v in parameters %+v", tc.desiredObjectKey, parameters)
 				}
+				meta := parameters["ansible_operator_meta"].(map[string]string)
+				if meta["api_version"] != testWatch.GroupVersionKind.GroupVersion().String() ||
+					meta["kind"] != testWatch.GroupVersionKind.Kind {
+					t.Fatalf("Unexpected ansible_operator_meta %+v for GVK %v", meta, testWatch.GroupVersionKind)
+				}
 
 			}
 
@@ -53,6 +53,9 @@ type Watch struct {
 	WatchClusterScopedResources bool                      `yaml:"watchClusterScopedResources"`
 	SnakeCaseParameters         bool                      `yaml:"snakeCaseParameters"`
 	Selector                    metav1.LabelSelector      `yaml:"selector"`
+	// ReconcileCacheWindow is how long after a successful run reconciles of a custom resource skip
+	// running Ansible if its spec and dependent resources have not changed. Zero disables skipping.
+	ReconcileCacheWindow time.Duration `yaml:"reconcileCacheWindow"`
 
 	// Not configurable via watches.yaml
 	MaxConcurrentReconciles int `yaml:"-"`
@@ -72,6 +75,7 @@ var (
 	blacklistDefault                   = []schema.GroupVersionKind{}
 	maxRunnerArtifactsDefault          = 20
 	reconcilePeriodDefault             = metav1.Duration{Duration: time.Duration(0)}
+	reconcileCacheWindowDefault        = metav1.Duration{Duration: time.Duration(0)}
 	manageStatusDefault                = true
 	watchDependentResourcesDefault     = true
 	watchClusterScopedResourcesDefault = false
@@ -130,6 +134,7 @@ type alias struct {
 	Blacklist                   []schema.GroupVersionKind `yaml:"blacklist,omitempty"`
 	Finalizer                   *Finalizer                `yaml:"finalizer"`
 	Selector                    tempLabelSelector         `yaml:"selector"`
+	ReconcileCacheWindow        *metav1.Duration          `yaml:"reconcileCacheWindow,omitempty"`
 }
 
 // buildWatch will build Watch based on the values parsed from alias
@@ -150,6 +155,10 @@ func (w *Watch) setValuesFromAlias(tmp alias) error {
 		tmp.ReconcilePeriod = &reconcilePeriodDefault
 	}
 
+	if tmp.ReconcileCacheWindow == nil {
+		tmp.ReconcileCacheWindow = &reconcileCacheWindowDefault
+	}
+
 	if tmp.WatchClusterScopedResources == nil {
 		tmp.WatchClusterScopedResources = &watchClusterScopedResourcesDefault
 	}
@@ -180,6 +189,7 @@ func (w *Watch) setValuesFromAlias(tmp alias) error {
 	w.MaxRunnerArtifacts = tmp.MaxRunnerArtifacts
 	w.MaxConcurrentReconciles = getMaxConcurrentReconciles(gvk, maxConcurrentReconcilesDefault)
 	w.ReconcilePeriod = tmp.ReconcilePeriod.Duration
+	w.ReconcileCacheWindow = tmp.ReconcileCacheWindow.Duration
 	w.ManageStatus = *tmp.ManageStatus
 	w.WatchDependentResources = *tmp.WatchDependentResources
 	w.SnakeCaseParameters = *tmp.SnakeCaseParameters
@@ -276,7 +286,15 @@ func getPossibleRolePaths(rootDir, path string) []string {
 // A Watch is considered valid if it:
 // - Specifies a valid path to a Role||Playbook
 // - If a Finalizer is non-nil, it must have a name + valid path to a Role||Playbook or Vars
+// - Manages status if it sets a ReconcileCacheWindow
 func (w *Watch) Validate() error {
+	// The spec hash and time of the last successful run are read from the status.
+	if w.ReconcileCacheWindow != 0 && !w.ManageStatus {
+		err := fmt.Errorf("reconcileCacheWindow requires manageStatus to be true")
+		log.Error(err, fmt.Sprintf("Invalid reconcile cache window for GVK: %v", w.GroupVersionKind.String()))
+		return err
+	}
+
 	err := verifyAnsiblePath(w.Playbook, w.Role)
 	if err != nil {
 		log.Error(err, fmt.Sprintf("Invalid ansible path for GVK: %v", w.GroupVersionKind.String()))
@@ -312,6 +330,7 @@ func New(gvk schema.GroupVersionKind, role, playbook string, vars map[string]int
 		MaxRunnerArtifacts:          maxRunnerArtifactsDefault,
 		MaxConcurrentReconciles:     maxConcurrentReconcilesDefault,
 		ReconcilePeriod:             reconcilePeriodDefault.Duration,
+		ReconcileCacheWindow:        reconcileCacheWindowDefault.Duration,
 		ManageStatus:                manageStatusDefault,
 		WatchDependentResources:     watchDependentResourcesDefault,
 		WatchClusterScopedResources: watchClusterScopedResourcesDefault,
@@ -187,6 +187,16 @@ func TestLoad(t *testing.T) { //nolint:gocyclo
 			ReconcilePeriod: zeroSeconds,
 			ManageStatus:    true,
 		},
+		Watch{
+			GroupVersionKind: schema.GroupVersionKind{
+				Version: "v1alpha1",
+				Group:   "app.example.com",
+				Kind:    "ReconcileCache",
+			},
+			Playbook:             validTemplate.ValidPlaybook,
+			ManageStatus:         true,
+			ReconcileCacheWindow: 10 * time.Minute,
+		},
 		Watch{
 			GroupVersionKind: schema.GroupVersionKind{
 				Version: "v1alpha1",
@@ -439,6 +449,11 @@ func TestLoad(t *testing.T) { //nolint:gocyclo
 			path:        "testdata/invalid_duration.yaml",
 			shouldError: true,
 		},
+		{
+			name:        "error reconcile cache window without managed status",
+			path:        "testdata/invalid_reconcile_cache_window.yaml",
+			shouldError: true,
+		},
 		{
 			name:        "error invalid status",
 			path:        "testdata/invalid_status.yaml",
@@ -536,6 +551,10 @@ func TestLoad(t *testing.T) { //nolint:gocyclo
 					t.Fatalf("The GVK: %v unexpected reconcile period: %v expected reconcile period: %v", gvk,
 						gotWatch.ReconcilePeriod, expectedWatch.ReconcilePeriod)
 				}
+				if gotWatch.ReconcileCacheWindow != expectedWatch.ReconcileCacheWindow {
+					t.Fatalf("The GVK: %v unexpected reconcile cache window: %v expected reconcile cache window: %v",
+						gvk, gotWatch.ReconcileCacheWindow, expectedWatch.ReconcileCacheWindow)
+				}
 
 				for i, val := range expectedWatch.Blacklist {
 					if val != gotWatch.Blacklist[i] {
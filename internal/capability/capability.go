@@ -0,0 +1,437 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package capability estimates the Operator Capability Level of a project from its
+// manifests and source, and reports what is missing to reach higher levels.
+package capability
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+	"github.com/operator-framework/operator-sdk/internal/util/operatorhub"
+)
+
+// Project is the on-disk layout of a project to assess.
+type Project struct {
+	// Dir is the project's root directory.
+	Dir string
+	// CRDsDir is the directory containing the project's CRDs, relative to Dir.
+	CRDsDir string
+	// ManifestsDirs are directories searched for the project's CSV, relative to Dir.
+	ManifestsDirs []string
+}
+
+// DefaultProject returns the layout of a project scaffolded in dir.
+func DefaultProject(dir string) Project {
+	return Project{
+		Dir:           dir,
+		CRDsDir:       filepath.Join("config", "crd", "bases"),
+		ManifestsDirs: []string{filepath.Join("config", "manifests", "bases"), filepath.Join("bundle", "manifests")},
+	}
+}
+
+// Check is the result of looking for one feature expected of operators at some capability level.
+type Check struct {
+	// Level is the capability level, from 1 to 5, this check counts towards.
+	Level int `json:"level"`
+	// Name is a short name for the check, ex. "status-conditions".
+	Name string `json:"name"`
+	// Passed is true if the feature was found.
+	Passed bool `json:"passed"`
+	// Gap describes how to add the feature if it was not found.
+	Gap string `json:"gap,omitempty"`
+}
+
+// Report is the estimated capability level of a project.
+type Report struct {
+	// Level is the highest level whose checks, and those of all lower levels, passed.
+	Level int `json:"level"`
+	// LevelName is the name of Level, ex. "Basic Install", or empty if Level is 0.
+	LevelName string `json:"levelName,omitempty"`
+	// Declared is the level in the CSV's capabilities annotation, if any.
+	Declared string `json:"declared,omitempty"`
+	// Checks are the results of every check, ordered by level.
+	Checks []Check `json:"checks"`
+}
+
+// Gaps returns the checks that did not pass.
+func (r Report) Gaps() (gaps []Check) {
+	for _, c := range r.Checks {
+		if !c.Passed {
+			gaps = append(gaps, c)
+		}
+	}
+	return gaps
+}
+
+// DeclaredExceedsEstimate returns true if the CSV claims a higher level than was estimated.
+func (r Report) DeclaredExceedsEstimate() bool {
+	for i, name := range operatorhub.Capabilities {
+		if name == r.Declared {
+			return i+1 > r.Level
+		}
+	}
+	return false
+}
+
+// project is everything checks inspect, read once up front.
+type project struct {
+	crds []apiextv1.CustomResourceDefinition
+	csv  *v1alpha1.ClusterServiceVersion
+	// sources are the contents of the project's Go, Ansible, and Helm files, keyed by path.
+	sources map[string][]byte
+	// kustomizations are the contents of the project's kustomization.yaml files, keyed by path.
+	kustomizations map[string][]byte
+}
+
+// check is a single feature expected of operators at level.
+type check struct {
+	level int
+	name  string
+	gap   string
+	found func(project) bool
+}
+
+var (
+	finalizerRe      = regexp.MustCompile(`AddFinalizer|SetFinalizers|ContainsFinalizer|(?m)^\s*finalizer:`)
+	backupRe         = regexp.MustCompile(`(?i)backup|restore|snapshot`)
+	metricsRe        = regexp.MustCompile(`metrics\.Registry\.(Must)?Register|prometheus\.(Must)?Register`)
+	autoscalerRe     = regexp.MustCompile(`HorizontalPodAutoscaler|VerticalPodAutoscaler`)
+	prometheusBaseRe = regexp.MustCompile(`(?m)^\s*-\s*\.\./prometheus\s*$`)
+)
+
+// checks are ordered by level.
+var checks = []check{
+	{
+		level: 1, name: "crds",
+		gap: "Define the operator's APIs as CRDs, ex. with 'operator-sdk create api'.",
+		found: func(p project) bool {
+			return len(p.crds) != 0
+		},
+	},
+	{
+		level: 1, name: "csv",
+		gap: "Generate a ClusterServiceVersion so OLM can install the operator, ex. with 'make bundle'.",
+		found: func(p project) bool {
+			return p.csv != nil
+		},
+	},
+	{
+		level: 2, name: "upgrade-graph",
+		gap: "Set spec.replaces or the olm.skipRange annotation in the CSV so OLM can upgrade " +
+			"from previous versions.",
+		found: func(p project) bool {
+			if p.csv == nil {
+				return false
+			}
+			_, hasSkipRange := p.csv.GetAnnotations()["olm.skipRange"]
+			return p.csv.Spec.Replaces != "" || hasSkipRange
+		},
+	},
+	{
+		level: 2, name: "api-versioning",
+		gap: "Serve every stored version of each CRD and mark exactly one as the storage version, " +
+			"adding a conversion webhook when a new version changes the schema.",
+		found: func(p project) bool {
+			if len(p.crds) == 0 {
+				return false
+			}
+			for _, crd := range p.crds {
+				if !versionsConvertible(crd) {
+					return false
+				}
+			}
+			return true
+		},
+	},
+	{
+		level: 3, name: "finalizers",
+		gap: "Add a finalizer to custom resources so the operator can clean up external state on deletion.",
+		found: func(p project) bool {
+			return sourcesMatch(p, finalizerRe)
+		},
+	},
+	{
+		level: 3, name: "backup",
+		gap: "Support backing up and restoring operand data, ex. with a Backup API or a backup field in a spec.",
+		found: func(p project) bool {
+			for _, crd := range p.crds {
+				if backupRe.MatchString(crd.Spec.Names.Kind) {
+					return true
+				}
+				for _, v := range crd.Spec.Versions {
+					if v.Schema != nil && v.Schema.OpenAPIV3Schema != nil && hasBackupField(v.Schema.OpenAPIV3Schema.Properties["spec"]) {
+						return true
+					}
+				}
+			}
+			return false
+		},
+	},
+	{
+		level: 4, name: "status-conditions",
+		gap: "Add a status.conditions array to every CRD so users and tools can observe operand health.",
+		found: func(p project) bool {
+			if len(p.crds) == 0 {
+				return false
+			}
+			for _, crd := range p.crds {
+				if !hasStatusConditions(crd) {
+					return false
+				}
+			}
+			return true
+		},
+	},
+	{
+		level: 4, name: "metrics",
+		gap: "Expose metrics to Prometheus: uncomment '../prometheus' in config/default/kustomization.yaml " +
+			"and register operand metrics with controller-runtime's metrics.Registry.",
+		found: func(p project) bool {
+			if sourcesMatch(p, metricsRe) {
+				return true
+			}
+			for _, b := range p.kustomizations {
+				if prometheusBaseRe.Match(b) {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	{
+		level: 5, name: "autoscaling",
+		gap: "Scale operands automatically, ex. by adding a scale subresource to CRDs or managing a HorizontalPodAutoscaler.",
+		found: func(p project) bool {
+			for _, crd := range p.crds {
+				for _, v := range crd.Spec.Versions {
+					if v.Subresources != nil && v.Subresources.Scale != nil {
+						return true
+					}
+				}
+			}
+			return sourcesMatch(p, autoscalerRe)
+		},
+	},
+}
+
+// Assess estimates the capability level of p.
+func Assess(p Project) (*Report, error) {
+	proj, err := readProject(p)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	if proj.csv != nil {
+		report.Declared = proj.csv.GetAnnotations()[operatorhub.CapabilitiesAnnotation]
+	}
+	// The estimated level stops increasing at the first level with a failed check.
+	reached := true
+	for level := 1; level <= len(operatorhub.Capabilities); level++ {
+		for _, c := range checks {
+			if c.level != level {
+				continue
+			}
+			result := Check{Level: c.level, Name: c.name, Passed: c.found(proj)}
+			if !result.Passed {
+				result.Gap = c.gap
+				reached = false
+			}
+			report.Checks = append(report.Checks, result)
+		}
+		if reached {
+			report.Level = level
+			report.LevelName = operatorhub.Capabilities[level-1]
+		}
+	}
+	return report, nil
+}
+
+func readProject(p Project) (proj project, err error) {
+	crdsDir := filepath.Join(p.Dir, p.CRDsDir)
+	v1crds, v1beta1crds, err := k8sutil.GetCustomResourceDefinitions(crdsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return proj, fmt.Errorf("error reading CRDs from %s: %v", crdsDir, err)
+	}
+	for _, crd := range v1beta1crds {
+		converted, err := k8sutil.Convertv1beta1Tov1CustomResourceDefinition(&crd)
+		if err != nil {
+			return proj, fmt.Errorf("error converting CRD %s to v1: %v", crd.GetName(), err)
+		}
+		v1crds = append(v1crds, *converted)
+	}
+	proj.crds = v1crds
+
+	for _, dir := range p.ManifestsDirs {
+		if proj.csv, err = findCSV(filepath.Join(p.Dir, dir)); err != nil {
+			return proj, err
+		}
+		if proj.csv != nil {
+			break
+		}
+	}
+
+	proj.sources = make(map[string][]byte)
+	proj.kustomizations = make(map[string][]byte)
+	err = filepath.Walk(p.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != p.Dir && skipDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case info.Name() == "kustomization.yaml":
+			proj.kustomizations[path], err = ioutil.ReadFile(path)
+		case isSource(path, p):
+			proj.sources[path], err = ioutil.ReadFile(path)
+		}
+		return err
+	})
+	if err != nil {
+		return proj, fmt.Errorf("error reading project files: %v", err)
+	}
+	return proj, nil
+}
+
+// skipDir returns true for directories that do not contain the project's own source.
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "vendor" || name == "bin" || name == "testdata"
+}
+
+// isSource returns true for Go files and the Ansible and Helm files outside of generated manifests.
+func isSource(path string, p Project) bool {
+	rel, err := filepath.Rel(p.Dir, path)
+	if err != nil {
+		return false
+	}
+	for _, dir := range []string{"config", "bundle"} {
+		if strings.HasPrefix(rel, dir+string(filepath.Separator)) {
+			return false
+		}
+	}
+	switch filepath.Ext(path) {
+	case ".go", ".yaml", ".yml", ".tpl":
+		return true
+	}
+	return false
+}
+
+// findCSV returns the first CSV in the manifests in dir, or nil if there is none.
+func findCSV(dir string) (*v1alpha1.ClusterServiceVersion, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading manifests from %s: %v", dir, err)
+	}
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, info.Name())
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading manifest %s: %v", path, err)
+		}
+		scanner := k8sutil.NewYAMLScanner(bytes.NewBuffer(b))
+		for scanner.Scan() {
+			typeMeta, err := k8sutil.GetTypeMetaFromBytes(scanner.Bytes())
+			if err != nil {
+				log.Debugf("Skipping manifest in %s: %v", path, err)
+				continue
+			}
+			if typeMeta.Kind != v1alpha1.ClusterServiceVersionKind {
+				continue
+			}
+			csv := &v1alpha1.ClusterServiceVersion{}
+			if err := yaml.Unmarshal(scanner.Bytes(), csv); err != nil {
+				return nil, fmt.Errorf("error unmarshalling CSV in %s: %v", path, err)
+			}
+			return csv, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error scanning %s: %v", path, err)
+		}
+	}
+	return nil, nil
+}
+
+func sourcesMatch(p project, re *regexp.Regexp) bool {
+	for _, b := range p.sources {
+		if re.Match(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// versionsConvertible returns true if crd has one storage version and, when it serves more than
+// one version, a conversion strategy that does not rely on the versions' schemas being identical.
+func versionsConvertible(crd apiextv1.CustomResourceDefinition) bool {
+	storage, served := 0, 0
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			storage++
+		}
+		if v.Served {
+			served++
+		}
+	}
+	if storage != 1 {
+		return false
+	}
+	if served <= 1 {
+		return true
+	}
+	return crd.Spec.Conversion != nil && crd.Spec.Conversion.Strategy == apiextv1.WebhookConverter
+}
+
+func hasStatusConditions(crd apiextv1.CustomResourceDefinition) bool {
+	for _, v := range crd.Spec.Versions {
+		if !v.Storage || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		conditions, ok := v.Schema.OpenAPIV3Schema.Properties["status"].Properties["conditions"]
+		return ok && conditions.Type == "array"
+	}
+	return false
+}
+
+// hasBackupField returns true if any property in props, at any depth, is named like a backup setting.
+func hasBackupField(props apiextv1.JSONSchemaProps) bool {
+	for name, prop := range props.Properties {
+		if backupRe.MatchString(name) || hasBackupField(prop) {
+			return true
+		}
+	}
+	return false
+}
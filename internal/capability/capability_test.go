@@ -0,0 +1,146 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capability
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	crdWithConditions = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Memcached
+    plural: memcacheds
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              backupSchedule:
+                type: string
+          status:
+            type: object
+            properties:
+              conditions:
+                type: array
+    subresources:
+      scale:
+        specReplicasPath: .spec.size
+        statusReplicasPath: .status.size
+`
+
+	csvWithReplaces = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.2
+  annotations:
+    capabilities: Auto Pilot
+spec:
+  replaces: memcached-operator.v0.0.1
+`
+
+	controllerWithFinalizer = `package controllers
+
+func (r *MemcachedReconciler) finalize() {
+	controllerutil.AddFinalizer(memcached, memcachedFinalizer)
+}
+`
+
+	defaultKustomization = `bases:
+- ../crd
+- ../rbac
+- ../manager
+- ../prometheus
+`
+)
+
+var _ = Describe("Assess", func() {
+	var dir string
+
+	write := func(path, content string) {
+		path = filepath.Join(dir, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "capability-")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("estimates no level for an empty project", func() {
+		report, err := Assess(DefaultProject(dir))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Level).To(Equal(0))
+		Expect(report.Gaps()).To(HaveLen(len(checks)))
+	})
+	It("estimates Basic Install for a project with only a CRD and CSV", func() {
+		write("config/crd/bases/cache.example.com_memcacheds.yaml", crdWithConditions)
+		write("config/manifests/bases/memcached-operator.clusterserviceversion.yaml",
+			"apiVersion: operators.coreos.com/v1alpha1\nkind: ClusterServiceVersion\nmetadata:\n  name: memcached-operator.v0.0.1\n")
+
+		report, err := Assess(DefaultProject(dir))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Level).To(Equal(1))
+		Expect(report.LevelName).To(Equal("Basic Install"))
+		Expect(report.Gaps()[0].Name).To(Equal("upgrade-graph"))
+	})
+	It("estimates Auto Pilot for a project with every feature", func() {
+		write("config/crd/bases/cache.example.com_memcacheds.yaml", crdWithConditions)
+		write("bundle/manifests/memcached-operator.clusterserviceversion.yaml", csvWithReplaces)
+		write("controllers/memcached_controller.go", controllerWithFinalizer)
+		write("config/default/kustomization.yaml", defaultKustomization)
+
+		report, err := Assess(DefaultProject(dir))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Gaps()).To(BeEmpty())
+		Expect(report.Level).To(Equal(5))
+		Expect(report.Declared).To(Equal("Auto Pilot"))
+		Expect(report.DeclaredExceedsEstimate()).To(BeFalse())
+	})
+	It("does not count features above a level with a gap", func() {
+		write("config/crd/bases/cache.example.com_memcacheds.yaml", crdWithConditions)
+		write("bundle/manifests/memcached-operator.clusterserviceversion.yaml", csvWithReplaces)
+		write("config/default/kustomization.yaml", defaultKustomization)
+
+		report, err := Assess(DefaultProject(dir))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Level).To(Equal(2))
+		Expect(report.Gaps()).To(HaveLen(1))
+		Expect(report.Gaps()[0].Name).To(Equal("finalizers"))
+		Expect(report.DeclaredExceedsEstimate()).To(BeTrue())
+	})
+})
@@ -40,6 +40,7 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/controllermap"
 	"github.com/operator-framework/operator-sdk/internal/ansible/runner"
 	"github.com/operator-framework/operator-sdk/internal/ansible/watches"
+	"github.com/operator-framework/operator-sdk/internal/util/health"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	sdkVersion "github.com/operator-framework/operator-sdk/internal/version"
 )
@@ -48,6 +49,7 @@ var (
 	metricsHost           = "0.0.0.0"
 	log                   = logf.Log.WithName("cmd")
 	healthProbePort int32 = 6789
+	proxyPort             = 8888
 )
 
 func printVersion() {
@@ -161,7 +163,19 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		log.Error(err, "Failed to load watches.")
 		os.Exit(1)
 	}
+	var trackers []*health.Tracker
+	var watchPaths []string
 	for _, w := range watches {
+		watchPaths = append(watchPaths, w.Playbook, w.Role)
+		if w.Finalizer != nil {
+			watchPaths = append(watchPaths, w.Finalizer.Playbook, w.Finalizer.Role)
+		}
+		var tracker *health.Tracker
+		if f.ReconcileStallTimeout > 0 {
+			tracker = health.NewTracker(w.GroupVersionKind.String(), w.MaxConcurrentReconciles)
+			trackers = append(trackers, tracker)
+		}
+
 		runner, err := runner.New(w, f.AnsibleArgs)
 		if err != nil {
 			log.Error(err, "Failed to create runner")
@@ -176,6 +190,8 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 			MaxConcurrentReconciles: w.MaxConcurrentReconciles,
 			ReconcilePeriod:         w.ReconcilePeriod,
 			Selector:                w.Selector,
+			ReconcileCacheWindow:    w.ReconcileCacheWindow,
+			ReconcileTracker:        tracker,
 		})
 		if ctr == nil {
 			log.Error(fmt.Errorf("failed to add controller for GVK %v", w.GroupVersionKind.String()), "")
@@ -194,17 +210,41 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 	if err != nil {
 		log.Error(err, "Failed to add Healthz check.")
 	}
+	proxyAddress := fmt.Sprintf("localhost:%d", proxyPort)
+	if err := mgr.AddHealthzCheck("proxy", health.DialChecker(proxyAddress)); err != nil {
+		log.Error(err, "Failed to add Healthz check.")
+	}
+	if len(trackers) != 0 {
+		err := mgr.AddHealthzCheck("reconciles", health.StallChecker(f.ReconcileStallTimeout, trackers...))
+		if err != nil {
+			log.Error(err, "Failed to add Healthz check.")
+		}
+	}
+	if err := mgr.AddReadyzCheck("proxy", health.DialChecker(proxyAddress)); err != nil {
+		log.Error(err, "Failed to add Readyz check.")
+	}
+	if err := mgr.AddReadyzCheck("watches", health.PathsChecker(nonEmpty(watchPaths)...)); err != nil {
+		log.Error(err, "Failed to add Readyz check.")
+	}
 
 	done := make(chan error)
 
+	cacheOptions, err := proxy.ParseCacheOptions(f.ProxyCacheGVKs, f.ProxyCacheBypass, f.ProxyCacheSelector,
+		f.ProxyCacheMaxObjects)
+	if err != nil {
+		log.Error(err, "Invalid proxy cache options.")
+		os.Exit(1)
+	}
+
 	// start the proxy
 	err = proxy.Run(done, proxy.Options{
 		Address:           "localhost",
-		Port:              8888,
+		Port:              proxyPort,
 		KubeConfig:        mgr.GetConfig(),
 		Cache:             mgr.GetCache(),
 		RESTMapper:        mgr.GetRESTMapper(),
 		ControllerMap:     cMap,
+		CacheOptions:      cacheOptions,
 		OwnerInjection:    f.InjectOwnerRef,
 		WatchedNamespaces: []string{namespace},
 	})
@@ -227,6 +267,16 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 	log.Info("Exiting.")
 }
 
+// nonEmpty returns the non-empty strings in ss.
+func nonEmpty(ss []string) (out []string) {
+	for _, s := range ss {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
 // getAnsibleDebugLog return the value from the ANSIBLE_DEBUG_LOGS it order to
 // print the full Ansible logs
 func getAnsibleDebugLog() bool {
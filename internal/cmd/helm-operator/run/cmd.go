@@ -27,6 +27,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	zapf "sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -36,11 +37,16 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/helm/flags"
 	"github.com/operator-framework/operator-sdk/internal/helm/release"
 	"github.com/operator-framework/operator-sdk/internal/helm/watches"
+	"github.com/operator-framework/operator-sdk/internal/util/health"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	sdkVersion "github.com/operator-framework/operator-sdk/internal/version"
 )
 
-var log = logf.Log.WithName("cmd")
+var (
+	metricsHost           = "0.0.0.0"
+	log                   = logf.Log.WithName("cmd")
+	healthProbePort int32 = 6789
+)
 
 func printVersion() {
 	log.Info("Version",
@@ -93,6 +99,7 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 
 	// Set default manager options
 	options := manager.Options{
+		HealthProbeBindAddress:  fmt.Sprintf("%s:%d", metricsHost, healthProbePort),
 		MetricsBindAddress:      f.MetricsAddress,
 		LeaderElection:          f.EnableLeaderElection,
 		LeaderElectionID:        f.LeaderElectionID,
@@ -142,21 +149,69 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		log.Error(err, "Failed to create new manager factories.")
 		os.Exit(1)
 	}
+	var trackers []*health.Tracker
+	var chartDirs []string
 	for _, w := range ws {
+		chartDirs = append(chartDirs, w.ChartDir)
+		var tracker *health.Tracker
+		if f.ReconcileStallTimeout > 0 {
+			tracker = health.NewTracker(w.GroupVersionKind.String(), f.MaxConcurrentReconciles)
+			trackers = append(trackers, tracker)
+		}
+
+		var factoryOpts []release.ManagerFactoryOption
+		if w.ReleaseName != "" {
+			tmpl, err := watches.ParseReleaseName(w.ReleaseName)
+			if err != nil {
+				log.Error(err, "Failed to parse release name template.")
+				os.Exit(1)
+			}
+			factoryOpts = append(factoryOpts, release.WithReleaseNameTemplate(tmpl))
+		}
+		if pr := w.PostRenderer; pr != nil {
+			postRenderer, err := release.NewPostRenderer(pr.Exec, pr.Kustomize)
+			if err != nil {
+				log.Error(err, "Failed to create post-renderer.")
+				os.Exit(1)
+			}
+			factoryOpts = append(factoryOpts, release.WithPostRenderer(postRenderer))
+		}
+
 		// Register the controller with the factory.
 		err := controller.Add(mgr, controller.WatchOptions{
 			Namespace:               namespace,
 			GVK:                     w.GroupVersionKind,
-			ManagerFactory:          release.NewManagerFactory(mgr, w.ChartDir),
+			ManagerFactory:          release.NewManagerFactory(mgr, w.ChartDir, factoryOpts...),
 			ReconcilePeriod:         f.ReconcilePeriod,
 			WatchDependentResources: *w.WatchDependentResources,
 			OverrideValues:          w.OverrideValues,
+			AdoptReleases:           w.AdoptReleases,
+			ValuePolicy:             w.ValuePolicy,
 			MaxConcurrentReconciles: f.MaxConcurrentReconciles,
+			ReconcileTracker:        tracker,
 		})
 		if err != nil {
 			log.Error(err, "Failed to add manager factory to controller.")
 			os.Exit(1)
 		}
+		if f.EnableValuesWebhook && w.ValuePolicy != nil {
+			path := controller.ValuesWebhookPath(w.GroupVersionKind)
+			mgr.GetWebhookServer().Register(path, controller.NewValuesWebhook(w.ValuePolicy))
+			log.Info("Serving values webhook", "path", path)
+		}
+	}
+
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		log.Error(err, "Failed to add Healthz check.")
+	}
+	if len(trackers) != 0 {
+		err := mgr.AddHealthzCheck("reconciles", health.StallChecker(f.ReconcileStallTimeout, trackers...))
+		if err != nil {
+			log.Error(err, "Failed to add Healthz check.")
+		}
+	}
+	if err := mgr.AddReadyzCheck("watches", health.PathsChecker(chartDirs...)); err != nil {
+		log.Error(err, "Failed to add Readyz check.")
 	}
 
 	// Start the Cmd
@@ -0,0 +1,123 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/operator-framework/operator-sdk/internal/flags"
+	internalregistry "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+const (
+	analyzeLongHelp = `The 'operator-sdk bundle analyze' command reports the size and content of an operator bundle
+image, bundle OCI artifact prefixed with "oci://", or bundle directory on-disk: the compressed image size,
+the size of each manifest, and content that makes a bundle slow to resolve or too large to install.
+
+OLM unpacks a bundle's manifests into a ConfigMap, so bundles whose manifests are larger than 1 MiB cannot
+be installed, and catalogs containing large bundles slow down resolution. This command reports oversized
+CSVs and CRDs, large CSV fields like alm-examples, and duplicated RBAC rules, with suggestions to fix them.
+It exits with an exit code of 1 if the bundle cannot be installed, and 0 otherwise.
+`
+
+	analyzeExamples = `  # Analyze a bundle directory.
+  $ operator-sdk bundle analyze ./bundle
+
+  # Analyze a bundle image in a remote registry.
+  $ operator-sdk bundle analyze quay.io/example/memcached-operator-bundle:v0.0.1
+`
+)
+
+type bundleAnalyzeCmd struct {
+	outputFormat string
+}
+
+// newAnalyzeCmd returns a command that will analyze the size and content of an operator bundle.
+func newAnalyzeCmd() *cobra.Command {
+	c := bundleAnalyzeCmd{}
+	cmd := &cobra.Command{
+		Use:     "analyze",
+		Short:   "Analyze the size and content of an operator bundle",
+		Long:    analyzeLongHelp,
+		Example: analyzeExamples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("an image tag or directory is a required argument")
+			}
+			if c.outputFormat != "text" && c.outputFormat != "json" {
+				return fmt.Errorf("invalid value for output flag: %v", c.outputFormat)
+			}
+
+			logger := createLogger(viper.GetBool(flags.VerboseOpt))
+			analysis, err := internalregistry.AnalyzeBundle(context.TODO(), logger, args[0])
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if c.outputFormat == "json" {
+				b, err := json.MarshalIndent(analysis, "", "    ")
+				if err != nil {
+					logger.Fatalf("error marshaling JSON output: %v", err)
+				}
+				fmt.Printf("%s\n", b)
+			} else {
+				printAnalysis(os.Stdout, analysis)
+			}
+			if analysis.HasErrors() {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&c.outputFormat, "output", "o", "text", "Result format. One of: [text, json]")
+	return cmd
+}
+
+// printAnalysis writes a human-readable report of a to w.
+func printAnalysis(w io.Writer, a *internalregistry.BundleAnalysis) {
+	if a.ImageSize != 0 {
+		fmt.Fprintf(w, "Image size (compressed): %s\n", internalregistry.FormatSize(a.ImageSize))
+	}
+	fmt.Fprintf(w, "Manifests size: %s of %s\n\n", internalregistry.FormatSize(a.ManifestsSize),
+		internalregistry.FormatSize(internalregistry.MaxUnpackedBundleSize))
+
+	fmt.Fprintf(w, "Manifests (%d):\n", len(a.Manifests))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, m := range a.Manifests {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", internalregistry.FormatSize(m.Size), m.Kind, m.Path)
+	}
+	_ = tw.Flush()
+
+	if len(a.Findings) == 0 {
+		fmt.Fprintln(w, "\nNo size or content issues found")
+		return
+	}
+	fmt.Fprintf(w, "\nFindings (%d):\n", len(a.Findings))
+	for _, f := range a.Findings {
+		fmt.Fprintf(w, "  %s: %s\n", strings.ToUpper(f.Level), f.Message)
+		if f.Suggestion != "" {
+			fmt.Fprintf(w, "    Suggestion: %s\n", f.Suggestion)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"fmt"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+
+	"github.com/operator-framework/operator-sdk/internal/imagebuild"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+const (
+	buildLongHelp = `The 'operator-sdk bundle build' command builds a bundle image from a bundle.Dockerfile, as generated
+by 'operator-sdk generate bundle'.
+
+The image builder is set with --image-builder, or with the OPERATOR_SDK_IMAGE_BUILDER environment variable,
+and defaults to docker, or podman if docker is not installed. buildah builds locally too. kaniko builds in a pod
+in --namespace, to which the build context is uploaded, and pushes the image, so no local container tool
+is needed. Bundle images contain only manifests, so they run on any platform; --platform only sets the
+platform recorded in the image.
+`
+
+	buildExamples = `  # Build a bundle image from bundle.Dockerfile in the current directory.
+  $ operator-sdk bundle build -t quay.io/example/memcached-operator-bundle:v0.0.1
+
+  # Build the bundle image with podman, ex. on a Windows or arm64 host without docker.
+  $ operator-sdk bundle build -t quay.io/example/memcached-operator-bundle:v0.0.1 --image-builder podman
+
+  # Build and push the bundle image in the cluster with kaniko.
+  $ operator-sdk bundle build -t quay.io/example/memcached-operator-bundle:v0.0.1 --image-builder kaniko \
+      --namespace builds --kaniko-push-secret quay-credentials
+`
+)
+
+type bundleBuildCmd struct {
+	bundleCmd
+	imagebuild.Flags
+	cfg        *operator.Configuration
+	dockerfile string
+	tag        string
+}
+
+func newBuildCmd() *cobra.Command {
+	c := bundleBuildCmd{cfg: &operator.Configuration{}}
+	cmd := &cobra.Command{
+		Use:     "build [<context-dir>]",
+		Short:   "Build a bundle image",
+		Long:    buildLongHelp,
+		Example: buildExamples,
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.directory = "."
+			if len(args) != 0 {
+				c.directory = args[0]
+			}
+			if c.dockerfile == "" {
+				c.dockerfile = filepath.Join(c.directory, "bundle.Dockerfile")
+			}
+			if err := c.Validate(); err != nil {
+				return err
+			}
+			if err := c.run(cmd); err != nil {
+				log.Fatalf("Error building bundle image: %v", err)
+			}
+			return nil
+		},
+	}
+	c.addToFlagSet(cmd.Flags())
+	return cmd
+}
+
+func (c *bundleBuildCmd) addToFlagSet(fs *pflag.FlagSet) {
+	fs.StringVarP(&c.tag, "tag", "t", "", "Tag of the bundle image")
+	_ = cobra.MarkFlagRequired(fs, "tag")
+	fs.StringVarP(&c.dockerfile, "dockerfile", "f", "", "Path to the bundle Dockerfile. "+
+		"Defaults to bundle.Dockerfile in the context directory")
+	c.Flags.BindFlags(fs)
+	c.cfg.BindFlags(fs)
+}
+
+func (c bundleBuildCmd) run(cmd *cobra.Command) error {
+	builder, err := c.Builder(func() (*rest.Config, string, error) {
+		err := c.cfg.Load()
+		return c.cfg.RESTConfig, c.cfg.Namespace, err
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := c.cfg.Close(); err != nil {
+			log.Warn(err)
+		}
+	}()
+	log.Infof("Building bundle image %s with %s", c.tag, c.Tool)
+	err = builder.Build(cmd.Context(), imagebuild.BuildOptions{
+		Dockerfile: c.dockerfile,
+		Context:    c.directory,
+		Tag:        c.tag,
+		Platform:   c.Platform,
+	})
+	if err != nil {
+		return fmt.Errorf("error building %s: %v", c.tag, err)
+	}
+	log.Infof("Built bundle image %s", c.tag)
+	return nil
+}
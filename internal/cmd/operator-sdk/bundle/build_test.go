@@ -0,0 +1,40 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Running a bundle build command", func() {
+	Describe("newBuildCmd", func() {
+		It("builds and returns a cobra command", func() {
+			cmd := newBuildCmd()
+			Expect(cmd).NotTo(BeNil())
+
+			for _, name := range []string{"tag", "dockerfile", "image-builder", "platform"} {
+				Expect(cmd.Flags().Lookup(name)).NotTo(BeNil())
+			}
+			Expect(cmd.Flags().Lookup("image-builder").Shorthand).To(Equal("b"))
+			Expect(cmd.Flags().Lookup("image-builder").DefValue).To(BeEmpty())
+		})
+		It("rejects invalid platforms", func() {
+			cmd := newBuildCmd()
+			cmd.SetArgs([]string{"-t", "quay.io/example/bundle:v0.0.1", "--platform", "amd64"})
+			Expect(cmd.Execute()).To(MatchError(ContainSubstring("invalid platform")))
+		})
+	})
+})
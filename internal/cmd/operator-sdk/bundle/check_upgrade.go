@@ -0,0 +1,136 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/operator-framework/operator-sdk/internal/flags"
+	internalregistry "github.com/operator-framework/operator-sdk/internal/registry"
+	"github.com/operator-framework/operator-sdk/internal/util/exitcode"
+)
+
+const (
+	checkUpgradeLongHelp = `The 'operator-sdk bundle check-upgrade' command statically compares an operator bundle with the
+previously released bundle it upgrades from, and reports the risks of the upgrade for review before the bundle
+is published. Each bundle is a bundle image, a bundle OCI artifact prefixed with "oci://", or a bundle
+directory on-disk.
+
+Risks are reported, with a level of high, medium, or low, for:
+- CRD changes. Changes that break custom resources or clients once OLM upgrades the operator are high risks:
+  removed CRDs, which OLM deletes along with their custom resources; removed versions that custom resources
+  may be stored in, or that are served; removed fields, which are pruned from existing custom resources;
+  and type changes and tightened validation, ex. new required fields, raised minimums, lowered maximums, new
+  or changed patterns, and removed enum values, which make existing custom resources invalid. Versions that
+  are no longer served and storage version changes, which require stored objects to be migrated before the
+  old version is removed, are medium risks. Added CRDs are low risks.
+- permission additions: verbs the CSV's permissions and clusterPermissions grant each service account
+  that the previous CSV did not. Access to secrets, wildcards, and escalating verbs are high risks.
+- webhook changes: added and removed webhooks, and changed rules, selectors, failure policies,
+  and services.
+- install mode changes: install modes no longer supported, which break upgrades of existing installs,
+  and newly supported ones.
+
+The command exits with an exit code of 1 if any high risks are reported, 2 if its arguments are invalid,
+and 0 otherwise.
+`
+
+	checkUpgradeExamples = `  # Compare a bundle directory with the previously released bundle image.
+  $ operator-sdk bundle check-upgrade quay.io/example/memcached-operator-bundle:v0.0.1 ./bundle
+
+  # Write the report as JSON, for review tooling.
+  $ operator-sdk bundle check-upgrade quay.io/example/memcached-operator-bundle:v0.0.1 \
+      quay.io/example/memcached-operator-bundle:v0.0.2 --output json
+`
+)
+
+type bundleCheckUpgradeCmd struct {
+	outputFormat string
+}
+
+// newCheckUpgradeCmd returns a command that reports the risks of upgrading between two bundles.
+func newCheckUpgradeCmd() *cobra.Command {
+	c := bundleCheckUpgradeCmd{}
+	cmd := &cobra.Command{
+		Use:     "check-upgrade <previous-bundle> <bundle>",
+		Short:   "Report the risks of upgrading an operator from a previous bundle",
+		Long:    checkUpgradeLongHelp,
+		Example: checkUpgradeExamples,
+		Args:    exitcode.Args(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.outputFormat != "text" && c.outputFormat != "json" {
+				return exitcode.Invalid(fmt.Errorf("invalid value for output flag: %v", c.outputFormat))
+			}
+
+			logger := createLogger(viper.GetBool(flags.VerboseOpt))
+			_, previous, err := internalregistry.LoadBundle(context.TODO(), logger, args[0])
+			if err != nil {
+				logger.Fatalf("error loading previous bundle: %v", err)
+			}
+			_, bundle, err := internalregistry.LoadBundle(context.TODO(), logger, args[1])
+			if err != nil {
+				logger.Fatalf("error loading bundle: %v", err)
+			}
+			report, err := internalregistry.CheckUpgrade(previous, bundle)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if c.outputFormat == "json" {
+				b, err := json.MarshalIndent(report, "", "    ")
+				if err != nil {
+					logger.Fatalf("error marshaling JSON output: %v", err)
+				}
+				fmt.Printf("%s\n", b)
+			} else {
+				printUpgradeReport(os.Stdout, report)
+			}
+			if report.HasRisks(internalregistry.RiskHigh) {
+				logger.Logger.Exit(exitcode.Failure)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&c.outputFormat, "output", "o", "text", "Result format. One of: [text, json]")
+	return cmd
+}
+
+// printUpgradeReport writes a human-readable report of r to w.
+func printUpgradeReport(w io.Writer, r *internalregistry.UpgradeReport) {
+	if len(r.Risks) == 0 {
+		fmt.Fprintf(w, "No risks found upgrading from %s to %s\n", r.From, r.To)
+		return
+	}
+	fmt.Fprintf(w, "Risks upgrading from %s to %s (%d):\n", r.From, r.To, len(r.Risks))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  LEVEL\tCATEGORY\tRISK")
+	for _, risk := range r.Risks {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", strings.ToUpper(risk.Level), risk.Category, risk.Message)
+	}
+	_ = tw.Flush()
+	for _, risk := range r.Risks {
+		if risk.Suggestion != "" {
+			fmt.Fprintf(w, "Suggestion: %s: %s\n", risk.Message, risk.Suggestion)
+		}
+	}
+}
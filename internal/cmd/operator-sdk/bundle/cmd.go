@@ -28,7 +28,8 @@ func NewCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "bundle",
 		Short: "Manage operator bundle metadata",
-		Long: `Manage bundle builds, bundle metadata generation, and bundle validation.
+		Long: `Manage bundle builds, bundle metadata generation, bundle validation, bundle analysis,
+checks of the risks of upgrading between bundles, and checks of bundle drift from a project.
 An operator bundle is a portable operator packaging format understood by Kubernetes
 native software, like the Operator Lifecycle Manager.
 
@@ -42,6 +43,10 @@ https://sdk.operatorframework.io/docs/olm-integration
 
 	cmd.AddCommand(
 		newValidateCmd(),
+		newAnalyzeCmd(),
+		newBuildCmd(),
+		newCheckUpgradeCmd(),
+		newVerifyCmd(),
 	)
 	return cmd
 }
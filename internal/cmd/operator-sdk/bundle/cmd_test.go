@@ -26,8 +26,12 @@ var _ = Describe("Running a bundle command", func() {
 			Expect(cmd).NotTo(BeNil())
 
 			subcommands := cmd.Commands()
-			Expect(len(subcommands)).To(Equal(1))
-			Expect(subcommands[0].Use).To(Equal("validate"))
+			Expect(len(subcommands)).To(Equal(5))
+			Expect(subcommands[0].Use).To(Equal("analyze"))
+			Expect(subcommands[1].Use).To(Equal("build [<context-dir>]"))
+			Expect(subcommands[2].Use).To(Equal("check-upgrade <previous-bundle> <bundle>"))
+			Expect(subcommands[3].Use).To(Equal("validate"))
+			Expect(subcommands[4].Use).To(Equal("verify [<bundle-dir>]"))
 		})
 	})
 })
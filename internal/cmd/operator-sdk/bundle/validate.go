@@ -32,10 +32,14 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/bundle/internal"
 	"github.com/operator-framework/operator-sdk/internal/flags"
+	"github.com/operator-framework/operator-sdk/internal/imagebuild"
+	"github.com/operator-framework/operator-sdk/internal/migrate"
 	internalregistry "github.com/operator-framework/operator-sdk/internal/registry"
+	"github.com/operator-framework/operator-sdk/internal/util/operatorhub"
 )
 
 const (
@@ -46,6 +50,17 @@ with an exit code of 1 if any validation errors arise, and 0 if only warnings ar
 More information about operator bundles and metadata:
 https://github.com/operator-framework/operator-registry/blob/master/docs/design/operator-bundle.md
 
+Optional validators that are not run by default, such as those checking content rendered by the OpenShift
+console, can be selected by label with '--select-optional'. Run with '--list-optional' to list them.
+The OperatorHub.io validator, selected with '--select-optional name=operatorhub', allows the categories listed
+by OperatorHub.io unless '--operatorhub-categories-file' names a file of other allowed categories.
+The image validator, selected with '--select-optional name=images', checks that every image referenced by the
+CSV exists in its registry, using the credentials in the Docker config.json of '--images-registry-config-dir',
+and with '--images-require-digest' that each image is pinned by digest.
+The deprecated API validator, selected with '--select-optional name=deprecated-apis', fails on manifests using
+API versions removed in the Kubernetes release '--deprecated-apis-k8s-version', and warns of deprecated ones.
+Run 'operator-sdk migrate scan' to check the project's code and other manifests as well.
+
 NOTE: if validating an image, the image must exist in a remote registry, not just locally.
 `
 
@@ -75,7 +90,16 @@ To build and validate an image built with the above manifests and metadata:
 type bundleValidateCmd struct {
 	bundleCmd
 
-	outputFormat string
+	outputFormat   string
+	selectOptional string
+	listOptional   bool
+	// categoriesFile overrides the categories allowed by the OperatorHub.io validator.
+	categoriesFile string
+	// imagesRequireDigest and imagesRegistryConfigDir configure the image validator.
+	imagesRequireDigest     bool
+	imagesRegistryConfigDir string
+	// deprecatedAPIsK8sVersion configures the deprecated API validator.
+	deprecatedAPIsK8sVersion string
 }
 
 // newValidateCmd returns a command that will validate an operator bundle.
@@ -98,6 +122,10 @@ func makeValidateCmd() *cobra.Command {
 			// if we run the command with `| jq . > result.json` the command will print just the logs
 			// and the file will have only the JSON result.
 			logger := createLogger(viper.GetBool(flags.VerboseOpt))
+			if c.listOptional {
+				fmt.Print(internalregistry.DefaultOptionalValidators)
+				return nil
+			}
 			if err = c.validate(args); err != nil {
 				return fmt.Errorf("invalid command args: %v", err)
 			}
@@ -121,7 +149,8 @@ func makeValidateCmd() *cobra.Command {
 	return cmd
 }
 
-// createLogger creates a new logrus Entry that is optionally verbose.
+// createLogger creates a new logrus Entry that is optionally verbose. It logs to stderr,
+// so output printed to stdout, ex. JSON, can be redirected without logs.
 func createLogger(verbose bool) *log.Entry {
 	logger := log.NewEntry(internal.NewLoggerTo(os.Stderr))
 	if verbose {
@@ -139,16 +168,46 @@ func (c bundleValidateCmd) validate(args []string) error {
 		return fmt.Errorf("invalid value for output flag: %v", c.outputFormat)
 
 	}
+	if _, err := c.selectOptionalValidators(); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// selectOptionalValidators returns all optional validators selected by the select-optional flag.
+func (c bundleValidateCmd) selectOptionalValidators() (internalregistry.OptionalValidators, error) {
+	if c.selectOptional == "" {
+		return nil, nil
+	}
+	selector, err := labels.Parse(c.selectOptional)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for select-optional flag: %v", err)
+	}
+	selected := internalregistry.DefaultOptionalValidators.Select(selector)
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no optional validators selected by %q", c.selectOptional)
+	}
+	if c.categoriesFile != "" {
+		categories, err := operatorhub.ReadCategoriesFile(c.categoriesFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for operatorhub-categories-file flag: %v", err)
+		}
+		selected = selected.WithOperatorHubCategories(categories)
+	}
+	return selected.WithImageValidatorOptions(internalregistry.ImageValidatorOptions{
+		RequireDigest:     c.imagesRequireDigest,
+		RegistryConfigDir: c.imagesRegistryConfigDir,
+	}).WithDeprecatedAPIsK8sVersion(c.deprecatedAPIsK8sVersion), nil
+}
+
 // TODO: add a "permissive" flag to toggle whether warnings also cause a non-zero
 // exit code to be returned (true by default).
 func (c *bundleValidateCmd) addToFlagSet(fs *pflag.FlagSet) {
-	fs.StringVarP(&c.imageBuilder, "image-builder", "b", "docker",
+	fs.StringVarP(&c.imageBuilder, "image-builder", "b", "",
 		"Tool to pull and unpack bundle images. Only used when validating a bundle image. "+
-			"One of: [docker, podman, none]")
+			"One of: [docker, podman, none]. Defaults to $"+imagebuild.ImageBuilderEnv+", or the first of "+
+			"docker or podman installed, or none if neither is")
 
 	fs.StringVarP(&c.outputFormat, "output", "o", internal.Text,
 		"Result format for results. One of: [text, json-alpha1]")
@@ -157,9 +216,31 @@ func (c *bundleValidateCmd) addToFlagSet(fs *pflag.FlagSet) {
 	if err := fs.MarkHidden("output"); err != nil {
 		panic(err)
 	}
+
+	fs.StringVar(&c.selectOptional, "select-optional", "",
+		"Label selector to select optional validators to run, ex. 'suite=console'. "+
+			"Run this command with '--list-optional' to list available optional validators")
+	fs.BoolVar(&c.listOptional, "list-optional", false,
+		"List all optional validators available. When set, no validators will be run")
+	fs.StringVar(&c.categoriesFile, "operatorhub-categories-file", "",
+		"Path to a YAML file of the form 'categories: [<category>, ...]' listing the categories allowed by "+
+			"the OperatorHub.io optional validator, instead of those listed by OperatorHub.io")
+	fs.BoolVar(&c.imagesRequireDigest, "images-require-digest", false,
+		"Make images not pinned by digest errors of the images optional validator")
+	fs.StringVar(&c.imagesRegistryConfigDir, "images-registry-config-dir", "",
+		"Directory of the Docker config.json the images optional validator reads registry credentials from. "+
+			"Defaults to $DOCKER_CONFIG, then ~/.docker")
+	fs.StringVar(&c.deprecatedAPIsK8sVersion, "deprecated-apis-k8s-version", migrate.LatestRemovalRelease,
+		"Kubernetes release the deprecated-apis optional validator checks for deprecated and removed APIs")
 }
 
 func (c bundleValidateCmd) run(logger *log.Entry, bundle string) (res internal.Result, err error) {
+	if c.imageBuilder == "" {
+		if c.imageBuilder, err = imagebuild.DetectTool(true); err != nil {
+			return res, err
+		}
+	}
+
 	// Create a registry to validate bundle files and optionally unpack the image with.
 	reg, err := newImageRegistryForTool(logger, c.imageBuilder)
 	if err != nil {
@@ -212,7 +293,11 @@ func (c bundleValidateCmd) run(logger *log.Entry, bundle string) (res internal.R
 	// TODO(estroz): instead of using hard-coded 'manifests', look up bundle
 	// dir name in metadata labels.
 	manifestsDir := filepath.Join(c.directory, registrybundle.ManifestsDir)
-	results, err := validateBundleContent(logger, manifestsDir)
+	optional, err := c.selectOptionalValidators()
+	if err != nil {
+		return res, err
+	}
+	results, err := validateBundleContent(logger, manifestsDir, optional)
 	if err != nil {
 		res.AddError(fmt.Errorf("error validating content in %s: %v", manifestsDir, err))
 	}
@@ -255,8 +340,9 @@ func (c bundleValidateCmd) unpackImageIntoDir(reg registryimage.Registry, imageT
 	return val.PullBundleImage(imageTag, dir)
 }
 
-// validateBundleContent validates a bundle in manifestsDir.
-func validateBundleContent(logger *log.Entry, manifestsDir string) ([]apierrors.ManifestResult, error) {
+// validateBundleContent validates a bundle in manifestsDir with default validators and optional.
+func validateBundleContent(logger *log.Entry, manifestsDir string,
+	optional internalregistry.OptionalValidators) ([]apierrors.ManifestResult, error) {
 	// Detect mediaType.
 	mediaType, err := registrybundle.GetMediaType(manifestsDir)
 	if err != nil {
@@ -268,7 +354,8 @@ func validateBundleContent(logger *log.Entry, manifestsDir string) ([]apierrors.
 		return nil, err
 	}
 
-	return internalregistry.ValidateBundleContent(logger, bundle, mediaType), nil
+	results := internalregistry.ValidateBundleContent(logger, bundle, mediaType)
+	return append(results, optional.Validate(bundle)...), nil
 }
 
 // checkResults logs warnings and errors in results, and returns true if at
@@ -30,7 +30,7 @@ var _ = Describe("Running a bundle validate command", func() {
 			flag := cmd.Flags().Lookup("image-builder")
 			Expect(flag).NotTo(BeNil())
 			Expect(flag.Shorthand).To(Equal("b"))
-			Expect(flag.DefValue).To(Equal("docker"))
+			Expect(flag.DefValue).To(BeEmpty())
 
 			flag = cmd.Flags().Lookup("output")
 			Expect(flag).NotTo(BeNil())
@@ -86,5 +86,20 @@ var _ = Describe("Running a bundle validate command", func() {
 			err = cmd.validate([]string{"quay.io/person/example"})
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("succeeds if select-optional selects an optional validator", func() {
+			cmd.outputFormat = "text"
+			cmd.selectOptional = "suite=console"
+			err := cmd.validate([]string{"quay.io/person/example"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails if select-optional selects no optional validators", func() {
+			cmd.outputFormat = "text"
+			cmd.selectOptional = "suite=foo"
+			err := cmd.validate([]string{"quay.io/person/example"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`no optional validators selected by "suite=foo"`))
+		})
 	})
 })
@@ -0,0 +1,229 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/operator-framework/operator-sdk/internal/flags"
+	internalregistry "github.com/operator-framework/operator-sdk/internal/registry"
+	"github.com/operator-framework/operator-sdk/internal/release"
+	"github.com/operator-framework/operator-sdk/internal/scorecard"
+)
+
+const (
+	verifyLongHelp = `The 'operator-sdk bundle verify' command checks a bundle directory committed to an operator project for
+drift from the project, ex. manifests edited by hand, or RBAC generated before the project's permissions
+changed. It is intended to run in CI, and must be run from the project root.
+
+With '--against-project', the bundle's manifests and scorecard config are generated again, at the version of
+the bundle's ClusterServiceVersion, into a temporary directory: 'kustomize build' is run on '--kustomize-dir',
+and its output is piped to 'operator-sdk generate bundle --manifests'. The project's files are not modified,
+unless '--make-manifests' is set to regenerate the CRDs and RBAC of Go projects with 'make manifests' first,
+which catches RBAC markers changed without regenerating config/.
+
+Files are compared field by field, ignoring formatting, and the ClusterServiceVersion's spec.replaces and
+createdAt annotation, which are set outside of bundle generation. Each file that is missing from the bundle,
+extra in the bundle, or modified is reported, with the modified fields. With '--output json', the report is
+machine-readable. The command exits with an exit code of 1 if the bundle has drifted, and 0 otherwise.
+`
+
+	verifyExamples = `  # Check that the committed bundle matches the project.
+  $ operator-sdk bundle verify --against-project
+
+  # Regenerate CRDs and RBAC first, and write a machine-readable report.
+  $ operator-sdk bundle verify ./bundle --against-project --make-manifests -o json > drift.json
+`
+)
+
+type bundleVerifyCmd struct {
+	againstProject bool
+	kustomizeDir   string
+	kustomize      string
+	makeManifests  bool
+	outputFormat   string
+}
+
+// newVerifyCmd returns a command that checks a bundle for drift from its project.
+func newVerifyCmd() *cobra.Command {
+	c := bundleVerifyCmd{}
+	cmd := &cobra.Command{
+		Use:     "verify [<bundle-dir>]",
+		Short:   "Check an operator bundle for drift from its project",
+		Long:    verifyLongHelp,
+		Example: verifyExamples,
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !c.againstProject {
+				return errors.New("nothing to verify the bundle against: set --against-project")
+			}
+			if c.outputFormat != "text" && c.outputFormat != "json" {
+				return fmt.Errorf("invalid value for output flag: %v", c.outputFormat)
+			}
+			bundleDir := defaultBundleDir
+			if len(args) != 0 {
+				bundleDir = args[0]
+			}
+
+			logger := createLogger(viper.GetBool(flags.VerboseOpt))
+			drift, err := c.run(cmd.Context(), bundleDir)
+			if err != nil {
+				logger.Fatalf("error verifying bundle: %v", err)
+			}
+			if c.outputFormat == "json" {
+				b, err := json.MarshalIndent(drift, "", "    ")
+				if err != nil {
+					logger.Fatalf("error marshaling JSON output: %v", err)
+				}
+				fmt.Printf("%s\n", b)
+			} else {
+				printBundleDrift(os.Stdout, bundleDir, drift)
+			}
+			if drift.Drifted {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+	fs := cmd.Flags()
+	fs.BoolVar(&c.againstProject, "against-project", false, "Compare the bundle with the bundle generated "+
+		"from the project")
+	fs.StringVar(&c.kustomizeDir, "kustomize-dir", filepath.Join("config", "manifests"), "Directory containing "+
+		"kustomize bases and a kustomization.yaml for operator-framework manifests")
+	fs.StringVar(&c.kustomize, "kustomize", "", "Path of the kustomize binary. Defaults to bin/kustomize, "+
+		"or kustomize in PATH")
+	fs.BoolVar(&c.makeManifests, "make-manifests", false, "Run 'make manifests' before generating the bundle, "+
+		"to regenerate the CRDs and RBAC of Go projects")
+	fs.StringVarP(&c.outputFormat, "output", "o", "text", "Result format. One of: [text, json]")
+	return cmd
+}
+
+// defaultBundleDir is the directory project Makefiles generate bundles in.
+const defaultBundleDir = "bundle"
+
+// run generates the bundle of the project in the working directory, and compares it with bundleDir.
+func (c bundleVerifyCmd) run(ctx context.Context, bundleDir string) (*internalregistry.BundleDrift, error) {
+	csv, err := release.ReadBundleCSV(bundleDir)
+	if err != nil {
+		return nil, err
+	}
+	if csv == nil {
+		return nil, fmt.Errorf("no ClusterServiceVersion found in bundle %s", bundleDir)
+	}
+
+	if c.makeManifests {
+		if err := runVerifyCommand(ctx, nil, nil, "make", "manifests"); err != nil {
+			return nil, err
+		}
+	}
+	kustomize := c.kustomize
+	if kustomize == "" {
+		kustomize = "kustomize"
+		if isExist(filepath.Join("bin", "kustomize")) {
+			kustomize = filepath.Join("bin", "kustomize")
+		}
+	}
+	manifests := &bytes.Buffer{}
+	if err := runVerifyCommand(ctx, nil, manifests, kustomize, "build", c.kustomizeDir); err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "bundle-verify-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Errorf("Error removing generated bundle %s: %v", dir, err)
+		}
+	}()
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"generate", "bundle", "--manifests", "-q", "--version", csv.Version,
+		"--kustomize-dir", c.kustomizeDir, "--output-dir", dir}
+	if err := runVerifyCommand(ctx, manifests, nil, self, args...); err != nil {
+		return nil, err
+	}
+
+	return internalregistry.CompareBundleFiles(bundleDir, dir, registrybundle.ManifestsDir,
+		filepath.Join(filepath.FromSlash(scorecard.DefaultConfigDir), scorecard.ConfigFileName))
+}
+
+// runVerifyCommand runs name with args, stdin, and stdout, which defaults to stderr like the command's stderr.
+func runVerifyCommand(ctx context.Context, stdin io.Reader, stdout io.Writer, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, os.Stderr
+	if stdout == nil {
+		cmd.Stdout = os.Stderr
+	}
+	log.Debugf("Running %s %s", name, strings.Join(args, " "))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running %s %s: %v", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// printBundleDrift writes a human-readable report of d, the drift of the bundle in bundleDir, to w.
+func printBundleDrift(w io.Writer, bundleDir string, d *internalregistry.BundleDrift) {
+	if !d.Drifted {
+		fmt.Fprintf(w, "Bundle %s matches the project\n", bundleDir)
+		return
+	}
+	fmt.Fprintf(w, "Bundle %s has drifted from the project (%d files):\n", bundleDir, len(d.Files))
+	for _, f := range d.Files {
+		switch f.Change {
+		case internalregistry.DriftMissing:
+			fmt.Fprintf(w, "  missing: %s is generated, but not in the bundle\n", f.Path)
+		case internalregistry.DriftExtra:
+			fmt.Fprintf(w, "  extra: %s is in the bundle, but not generated\n", f.Path)
+		default:
+			fmt.Fprintf(w, "  modified: %s\n", f.Path)
+		}
+		for _, field := range f.Fields {
+			fmt.Fprintf(w, "    %s:\n", field.Path)
+			fmt.Fprintf(w, "      bundle:    %s\n", driftValue(field.Bundle))
+			fmt.Fprintf(w, "      generated: %s\n", driftValue(field.Generated))
+		}
+	}
+	fmt.Fprintln(w, "Regenerate the bundle with 'make bundle', and commit it")
+}
+
+func driftValue(v interface{}) string {
+	if v == nil {
+		return "<unset>"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	internalregistry "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+var _ = Describe("printBundleDrift", func() {
+	It("reports a bundle without drift", func() {
+		buf := &bytes.Buffer{}
+		printBundleDrift(buf, "bundle", &internalregistry.BundleDrift{})
+		Expect(buf.String()).To(Equal("Bundle bundle matches the project\n"))
+	})
+	It("reports each drifted file and field", func() {
+		buf := &bytes.Buffer{}
+		printBundleDrift(buf, "bundle", &internalregistry.BundleDrift{
+			Drifted: true,
+			Files: []internalregistry.FileDrift{
+				{Path: "manifests/csv.yaml", Change: internalregistry.DriftModified, Fields: []internalregistry.FieldDrift{
+					{Path: "spec.install.spec.clusterPermissions[0].rules[0].verbs",
+						Bundle: []interface{}{"get"}, Generated: []interface{}{"get", "list"}},
+					{Path: "metadata.labels", Generated: map[string]interface{}{"app": "memcached"}},
+				}},
+				{Path: "manifests/service.yaml", Change: internalregistry.DriftMissing},
+				{Path: "manifests/old.yaml", Change: internalregistry.DriftExtra},
+			},
+		})
+		Expect(buf.String()).To(Equal(`Bundle bundle has drifted from the project (3 files):
+  modified: manifests/csv.yaml
+    spec.install.spec.clusterPermissions[0].rules[0].verbs:
+      bundle:    ["get"]
+      generated: ["get","list"]
+    metadata.labels:
+      bundle:    <unset>
+      generated: {"app":"memcached"}
+  missing: manifests/service.yaml is generated, but not in the bundle
+  extra: manifests/old.yaml is in the bundle, but not generated
+Regenerate the bundle with 'make bundle', and commit it
+`))
+	})
+})
@@ -0,0 +1,126 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capabilitylevel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/capability"
+)
+
+const (
+	longHelp = `'capability-level' estimates the Operator Capability Level (1-5) of the project in the current
+directory from its CRDs, CSV, kustomize configuration, and source, and lists the gaps to reach higher levels:
+
+  1. Basic Install: the project defines CRDs and a ClusterServiceVersion.
+  2. Seamless Upgrades: the CSV declares an upgrade graph and CRD versions are convertible.
+  3. Full Lifecycle: custom resources have finalizers and operand data can be backed up and restored.
+  4. Deep Insights: every CRD has status conditions and metrics are exposed to Prometheus.
+  5. Auto Pilot: operands scale automatically.
+
+The estimate is based on heuristics, so a project can reach a level with features this command does not find.
+A warning is logged if the CSV's 'capabilities' annotation declares a higher level than estimated.
+`
+
+	examples = `  # Estimate the capability level of the project in the current directory.
+  $ operator-sdk capability-level
+
+  # Write the report as JSON, ex. to track the level in CI.
+  $ operator-sdk capability-level --output json
+`
+)
+
+type capabilityLevelCmd struct {
+	project      capability.Project
+	outputFormat string
+}
+
+func NewCmd() *cobra.Command {
+	c := capabilityLevelCmd{project: capability.DefaultProject(".")}
+	cmd := &cobra.Command{
+		Use:     "capability-level",
+		Short:   "Estimate the Operator Capability Level of a project",
+		Long:    longHelp,
+		Example: examples,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if c.outputFormat != "text" && c.outputFormat != "json" {
+				return fmt.Errorf("invalid value for output flag: %v", c.outputFormat)
+			}
+			report, err := capability.Assess(c.project)
+			if err != nil {
+				log.Fatalf("Failed to assess capability level: %v", err)
+			}
+			if report.DeclaredExceedsEstimate() {
+				log.Warnf("The CSV's capabilities annotation %q is higher than the estimated level", report.Declared)
+			}
+			if c.outputFormat == "json" {
+				b, err := json.MarshalIndent(report, "", "    ")
+				if err != nil {
+					log.Fatalf("Failed to marshal report: %v", err)
+				}
+				fmt.Println(string(b))
+				return nil
+			}
+			printReport(os.Stdout, report)
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.project.CRDsDir, "crds-dir", c.project.CRDsDir, "Directory containing the project's CRDs")
+	fs.StringSliceVar(&c.project.ManifestsDirs, "manifests-dirs", c.project.ManifestsDirs,
+		"Directories searched in order for the project's ClusterServiceVersion")
+	fs.StringVarP(&c.outputFormat, "output", "o", "text", "Output format for the report. One of: [text, json]")
+
+	return cmd
+}
+
+func printReport(out io.Writer, report *capability.Report) {
+	if report.Level == 0 {
+		fmt.Fprintln(out, "Estimated capability level: none")
+	} else {
+		fmt.Fprintf(out, "Estimated capability level: %d (%s)\n", report.Level, report.LevelName)
+	}
+	if report.Declared != "" {
+		fmt.Fprintf(out, "Declared capability level: %s\n", report.Declared)
+	}
+	fmt.Fprintln(out)
+
+	w := tabwriter.NewWriter(out, 8, 4, 4, ' ', 0)
+	fmt.Fprintln(w, "LEVEL\tCHECK\tRESULT")
+	for _, check := range report.Checks {
+		result := "pass"
+		if !check.Passed {
+			result = "gap"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", check.Level, check.Name, result)
+	}
+	_ = w.Flush()
+
+	if gaps := report.Gaps(); len(gaps) != 0 {
+		fmt.Fprintln(out, "\nGaps:")
+		for _, gap := range gaps {
+			fmt.Fprintf(out, "  - [%s] %s\n", gap.Name, gap.Gap)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/rest"
+
+	"github.com/operator-framework/operator-sdk/internal/imagebuild"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/registry/catalog"
+)
+
+const (
+	buildLongHelp = `'catalog build' builds an index image serving the given bundle images, which can be served
+to OLM by a CatalogSource. Bundle images are pulled without a container tool.
+
+The image builder is set with --image-builder, or with the OPERATOR_SDK_IMAGE_BUILDER environment variable,
+and defaults to docker, or podman if docker is not installed. buildah builds locally too. kaniko builds in a pod
+in --namespace, to which the build context is uploaded, and pushes the image. Index images run the opm binary of --binary-image,
+so set --platform to a platform that image supports, ex. linux/amd64 when building on an arm64 host.
+
+With --output-dir, the index's build context is written to the directory instead of being built, so it can be
+built by other tools.
+`
+
+	buildExamples = `  # Build an index image of two bundles for amd64 nodes, ex. on an arm64 host.
+  $ operator-sdk catalog build -t quay.io/example/memcached-operator-index:v0.0.2 --platform linux/amd64 \
+      quay.io/example/memcached-operator-bundle:v0.0.1 quay.io/example/memcached-operator-bundle:v0.0.2
+
+  # Write the index's build context to index/ instead.
+  $ operator-sdk catalog build --output-dir index quay.io/example/memcached-operator-bundle:v0.0.1
+`
+)
+
+type buildCmd struct {
+	catalog.IndexOptions
+	imagebuild.Flags
+	cfg       *operator.Configuration
+	tag       string
+	outputDir string
+}
+
+func newBuildCmd() *cobra.Command {
+	c := buildCmd{cfg: &operator.Configuration{}}
+	cmd := &cobra.Command{
+		Use:     "build <bundle-image>...",
+		Short:   "Build an index image of bundle images",
+		Long:    buildLongHelp,
+		Example: buildExamples,
+		Args:    cobra.MinimumNArgs(1),
+		PreRunE: func(*cobra.Command, []string) error {
+			if (c.tag == "") == (c.outputDir == "") {
+				return errors.New("exactly one of --tag or --output-dir must be set")
+			}
+			if c.outputDir != "" {
+				return nil
+			}
+			return c.Validate()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			c.Bundles = args
+			if err := c.run(cmd); err != nil {
+				log.Fatalf("Failed to build index image: %v", err)
+			}
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVarP(&c.tag, "tag", "t", "", "Tag of the index image")
+	fs.StringVar(&c.outputDir, "output-dir", "", "Directory to write the index's build context to, "+
+		"instead of building it")
+	c.Flags.BindFlags(fs)
+	fs.StringVar(&c.BinaryImage, "binary-image", "", "Image containing the opm binary serving the index. "+
+		"Defaults to the operator-registry upstream image")
+	fs.StringVar(&c.Mode, "mode", "replaces", "Channel update mode of bundles added to the index. "+
+		"One of: [replaces, semver, semver-skippatch]")
+	fs.BoolVar(&c.SkipTLS, "skip-tls", false, "Pull bundle images without verifying registry TLS certificates")
+	c.cfg.BindFlags(fs)
+
+	return cmd
+}
+
+func (c buildCmd) run(cmd *cobra.Command) (err error) {
+	logger := log.NewEntry(log.StandardLogger())
+	dir := c.outputDir
+	if dir == "" {
+		if dir, err = ioutil.TempDir("", "index-"); err != nil {
+			return err
+		}
+		defer func() {
+			if err := os.RemoveAll(dir); err != nil {
+				log.Errorf("Error removing index build context %s: %v", dir, err)
+			}
+		}()
+	}
+	if err := catalog.GenerateIndex(logger, dir, c.IndexOptions); err != nil {
+		return err
+	}
+	if c.outputDir != "" {
+		log.Infof("Wrote index build context to %s", c.outputDir)
+		return nil
+	}
+
+	builder, err := c.Builder(func() (*rest.Config, string, error) {
+		err := c.cfg.Load()
+		return c.cfg.RESTConfig, c.cfg.Namespace, err
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := c.cfg.Close(); err != nil {
+			log.Warn(err)
+		}
+	}()
+	if c.Tool != imagebuild.Kaniko {
+		imagebuild.WarnHostPlatform(c.Platform)
+	}
+	log.Infof("Building index image %s with %s", c.tag, c.Tool)
+	err = builder.Build(cmd.Context(), imagebuild.BuildOptions{
+		Dockerfile: filepath.Join(dir, catalog.IndexDockerfileName),
+		Context:    dir,
+		Tag:        c.tag,
+		Platform:   c.Platform,
+	})
+	if err != nil {
+		return fmt.Errorf("error building %s: %v", c.tag, err)
+	}
+	log.Infof("Built index image %s", c.tag)
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/registry/catalog"
+)
+
+const (
+	deprecateLongHelp = `'catalog deprecate' deprecates a package, or a channel or bundle in it, in a file-based catalog (FBC)
+directory by editing the package's olm.deprecations blob. If the package has no deprecations yet, they are
+written to 'deprecations.yaml' next to the file containing its olm.package blob. Deprecating a package, channel,
+or bundle again replaces its message; '--remove' removes its deprecation.
+
+OLM sets PackageDeprecated, ChannelDeprecated, and BundleDeprecated conditions on Subscriptions installing from
+a catalog with deprecations, which 'run bundle' reports as warnings during install.
+`
+
+	deprecateExamples = `  # Deprecate the alpha channel of the memcached-operator package.
+  $ operator-sdk catalog deprecate catalog/ --package memcached-operator --channel alpha \
+      --message "The alpha channel is no longer updated, subscribe to stable instead."
+
+  # Deprecate a bundle.
+  $ operator-sdk catalog deprecate catalog/ --package memcached-operator --bundle memcached-operator.v0.0.1 \
+      --message "memcached-operator.v0.0.1 has a known data loss issue, upgrade to v0.0.2."
+
+  # Undeprecate the package.
+  $ operator-sdk catalog deprecate catalog/ --package memcached-operator --remove
+`
+)
+
+type deprecateCmd struct {
+	pkg     string
+	channel string
+	bundle  string
+	message string
+	remove  bool
+}
+
+func newDeprecateCmd() *cobra.Command {
+	c := deprecateCmd{}
+	cmd := &cobra.Command{
+		Use:     "deprecate <fbc-dir>",
+		Short:   "Deprecate a package, channel, or bundle in a file-based catalog",
+		Long:    deprecateLongHelp,
+		Example: deprecateExamples,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(*cobra.Command, []string) error {
+			return c.validate()
+		},
+		Run: func(_ *cobra.Command, args []string) {
+			fbc, err := catalog.LoadFBC(args[0])
+			if err != nil {
+				log.Fatalf("Failed to read catalog: %v", err)
+			}
+			ref := c.reference()
+			if c.remove {
+				err = fbc.Undeprecate(c.pkg, ref)
+			} else {
+				err = fbc.Deprecate(c.pkg, ref, c.message)
+			}
+			if err != nil {
+				log.Fatalf("Failed to update deprecations: %v", err)
+			}
+			if c.remove {
+				log.Infof("Removed deprecation of %s from package %q", ref, c.pkg)
+			} else {
+				log.Infof("Deprecated %s in package %q", ref, c.pkg)
+			}
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.pkg, "package", "", "Name of the package to deprecate, or containing the channel or bundle to deprecate")
+	fs.StringVar(&c.channel, "channel", "", "Name of a channel to deprecate")
+	fs.StringVar(&c.bundle, "bundle", "", "Name of a bundle to deprecate")
+	fs.StringVar(&c.message, "message", "", "Message shown to users of the deprecated package, channel, or bundle")
+	fs.BoolVar(&c.remove, "remove", false, "Remove the deprecation instead of adding one")
+
+	return cmd
+}
+
+func (c deprecateCmd) validate() error {
+	if c.pkg == "" {
+		return errors.New("--package must be set")
+	}
+	if c.channel != "" && c.bundle != "" {
+		return errors.New("at most one of --channel or --bundle may be set")
+	}
+	if c.remove && c.message != "" {
+		return errors.New("--message cannot be set with --remove")
+	}
+	if !c.remove && c.message == "" {
+		return errors.New("--message must be set")
+	}
+	return nil
+}
+
+// reference returns the reference to the deprecated package, channel, or bundle.
+func (c deprecateCmd) reference() catalog.PackageScopedReference {
+	switch {
+	case c.channel != "":
+		return catalog.PackageScopedReference{Schema: catalog.SchemaChannel, Name: c.channel}
+	case c.bundle != "":
+		return catalog.PackageScopedReference{Schema: catalog.SchemaBundle, Name: c.bundle}
+	}
+	return catalog.PackageScopedReference{Schema: catalog.SchemaPackage}
+}
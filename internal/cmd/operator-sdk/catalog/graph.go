@@ -0,0 +1,110 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/registry/catalog"
+)
+
+const (
+	graphLongHelp = `'catalog graph' reads a catalog from an index image, or from the registry pod of a CatalogSource
+in the cluster, and writes a graph of its packages to stdout. Each package is drawn as a cluster of its bundles,
+labeled with their versions and channels, with edges for the 'replaces' and 'skips' of each bundle and for
+the package and API dependencies declared in bundle metadata. Bundles that are replaced or skipped but are
+not in the catalog are drawn too, so broken upgrade paths stand out.
+
+The graph is written in the Graphviz DOT language by default, or as a Mermaid flowchart with '--output mermaid'.
+`
+
+	graphExamples = `  # Render the upgrade graphs of an index image with Graphviz.
+  $ operator-sdk catalog graph quay.io/example/memcached-operator-index:v0.0.1 | dot -Tsvg > catalog.svg
+
+  # Write the graph of an in-cluster CatalogSource as a Mermaid flowchart, ex. for a Markdown document.
+  $ operator-sdk catalog graph --catalog-source operatorhubio-catalog --namespace olm --output mermaid
+`
+)
+
+type graphCmd struct {
+	cfg           *operator.Configuration
+	catalogSource string
+	outputFormat  string
+}
+
+func newGraphCmd() *cobra.Command {
+	c := graphCmd{cfg: &operator.Configuration{}}
+	cmd := &cobra.Command{
+		Use:     "graph [<index-image>]",
+		Short:   "Write a graph of the packages, upgrade edges, and dependencies in a catalog",
+		Long:    graphLongHelp,
+		Example: graphExamples,
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: func(_ *cobra.Command, args []string) error {
+			if (len(args) == 0) == (c.catalogSource == "") {
+				return errors.New("exactly one of an index image argument or --catalog-source must be set")
+			}
+			if c.outputFormat != "dot" && c.outputFormat != "mermaid" {
+				return fmt.Errorf("invalid value for output flag: %v", c.outputFormat)
+			}
+			if c.catalogSource != "" {
+				return c.cfg.Load()
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var cat *catalog.Catalog
+			var err error
+			if c.catalogSource != "" {
+				key := types.NamespacedName{Namespace: c.cfg.Namespace, Name: c.catalogSource}
+				cat, err = catalog.LoadCatalogSource(cmd.Context(), c.cfg.RESTConfig, c.cfg.Client, key)
+			} else {
+				cat, err = catalog.LoadIndexImage(cmd.Context(), log.NewEntry(log.StandardLogger()), args[0])
+			}
+			if err != nil {
+				log.Fatalf("Failed to read catalog: %v", err)
+			}
+
+			g := catalog.NewGraph(*cat)
+			write := catalog.WriteDOT
+			if c.outputFormat == "mermaid" {
+				write = catalog.WriteMermaid
+			}
+			if err := write(os.Stdout, g); err != nil {
+				log.Fatalf("Failed to write graph: %v", err)
+			}
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := c.cfg.Close(); err != nil {
+				log.Warn(err)
+			}
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.catalogSource, "catalog-source", "", "Name of a CatalogSource in --namespace to read "+
+		"the catalog from, instead of an index image")
+	fs.StringVarP(&c.outputFormat, "output", "o", "dot", "Output format of the graph. One of: [dot, mermaid]")
+	c.cfg.BindFlags(fs)
+
+	return cmd
+}
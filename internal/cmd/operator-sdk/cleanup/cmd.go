@@ -16,42 +16,141 @@ package cleanup
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/bundle"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/interactive"
+	"github.com/operator-framework/operator-sdk/internal/util/exitcode"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
 )
 
 func NewCmd() *cobra.Command {
-	var timeout time.Duration
+	var timeout, waitForLock time.Duration
+	var interactiveMode, parallel, expiredNamespaces, force bool
+	var forceGracePeriod time.Duration
+	var file string
 	cfg := &operator.Configuration{}
 	cmd := &cobra.Command{
 		Use:   "cleanup <operatorPackageName>",
 		Short: "Clean up an Operator deployed with the 'run' subcommand",
-		Long:  "This command has subcommands that will destroy an Operator deployed with OLM.",
-		Args:  cobra.ExactArgs(1),
+		Long: `This command has subcommands that will destroy an Operator deployed with OLM.
+
+Along with the resources created by the Operator's install plan, every component OLM references in the
+Operator's cluster-scoped Operator resource, ex. resources created by later upgrades, is destroyed, and then
+the Operator resource itself. Operator resources are skipped if the cluster's OLM does not support them.
+
+With --file, every Operator installed by 'run bundles' with the same installs file is destroyed instead,
+along with the namespaces that install created.
+
+With --expired-namespaces, every Operator in namespaces created by 'run --ephemeral-namespace' whose
+time-to-live has passed is destroyed instead, along with the namespaces. Run it periodically, ex. from a
+CronJob, to clean up abandoned installs on shared clusters.
+
+Each resource is logged once deleted, and the finalizers of resources still being deleted are logged every 10s.
+Resources whose operator cannot finalize them, ex. because it is broken or already deleted, block cleanup until
+--timeout. With --force, finalizers known to the SDK are removed from resources not deleted within
+--force-grace-period, and from the CRs of CRDs not deleted within it. Other finalizers are logged but not removed.
+The finalizer the API server adds to CRDs is never removed, since it is removed once the CRD's CRs are deleted,
+and CRs left behind without it would reappear if the CRD were recreated.
+
+` + exitcode.Help,
+		Args: exitcode.Args(func(cmd *cobra.Command, args []string) error {
+			if file != "" || expiredNamespaces {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		}),
 		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
-			return cfg.Load()
+			if interactiveMode && !interactive.IsTerminal() {
+				return exitcode.Invalid(errors.New("--interactive requires a terminal"))
+			}
+			if interactiveMode && file != "" {
+				return exitcode.Invalid(errors.New("--interactive cannot be set with --file"))
+			}
+			if expiredNamespaces && (interactiveMode || file != "") {
+				return exitcode.Invalid(errors.New("--expired-namespaces cannot be set with --interactive or --file"))
+			}
+			if err := cfg.Load(); err != nil {
+				return err
+			}
+			if !expiredNamespaces {
+				log.Infof("Using namespace %q (%s)", cfg.Namespace, cfg.NamespaceSource)
+			}
+			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			if expiredNamespaces {
+				ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+				defer cancel()
+				if err := operator.DeleteExpiredNamespaces(ctx, cfg, time.Now()); err != nil {
+					exitcode.Fatal("Clean up expired namespaces", err)
+				}
+				return
+			}
+			if file != "" {
+				i := bundle.NewInstalls(cfg)
+				i.Parallel, i.Timeout, i.WaitForLock = parallel, timeout, waitForLock
+				i.Force, i.ForceGracePeriod = force, forceGracePeriod
+				var err error
+				if i.File, err = bundle.ReadInstallsFile(file, cfg.Namespace); err != nil {
+					exitcode.Fatal("Failed to read installs file", exitcode.Invalid(err))
+				}
+				if err := i.Cleanup(cmd.Context()); err != nil {
+					exitcode.Fatal("Uninstall operators", err)
+				}
+				return
+			}
+
 			u := operator.NewUninstall(cfg)
 			u.Package = args[0]
 			u.DeleteAll = true
 			u.DeleteOperatorGroupNames = []string{operator.SDKOperatorGroupName}
-			u.Logf = log.Infof
+			u.Force, u.ForceGracePeriod = force, forceGracePeriod
 
-			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
-			defer cancel()
-
-			if err := u.Run(ctx); err != nil {
-				log.Fatalf("Uninstall operator: %v\n", err)
+			uninstall := func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				return u.Run(ctx)
+			}
+			ctx, span := tracing.Start(cmd.Context(), "cleanup", tracing.String("package", u.Package),
+				tracing.String("namespace", cfg.Namespace))
+			err := operator.RunWithNamespaceLock(ctx, cfg, waitForLock, func(ctx context.Context) error {
+				if !interactiveMode {
+					return uninstall(ctx)
+				}
+				return interactive.New(cfg, fmt.Sprintf("Cleaning up %s", u.Package)).Run(ctx, uninstall)
+			})
+			span.End(err)
+			if err != nil {
+				exitcode.Fatal("Uninstall operator", err)
+			}
+			log.WithField("package", u.Package).Info("Operator uninstalled")
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := cfg.Close(); err != nil {
+				log.Warn(err)
 			}
-			log.Infof("Operator %q uninstalled\n", u.Package)
 		},
 	}
 	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "Time to wait for the command to complete before failing")
+	cmd.Flags().DurationVar(&waitForLock, "wait-for-lock", 0, "Time to wait for installs or other cleanups "+
+		"in the namespace to release its lock. If unset, fail if the lock is held")
+	cmd.Flags().BoolVar(&interactiveMode, "interactive", false, "Show cleanup phases and resource statuses "+
+		"in a terminal UI, and offer to retry the cleanup if it fails")
+	cmd.Flags().BoolVar(&force, "force", false, "Remove finalizers known to the SDK from resources, and the CRs "+
+		"of CRDs, that are not deleted within --force-grace-period")
+	cmd.Flags().DurationVar(&forceGracePeriod, "force-grace-period", 30*time.Second, "Time to wait for each "+
+		"resource to be deleted before --force removes its finalizers")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Installs file passed to 'run bundles' whose Operators to clean up")
+	cmd.Flags().BoolVar(&parallel, "parallel", false, "With --file, clean up Operators concurrently")
+	cmd.Flags().BoolVar(&expiredNamespaces, "expired-namespaces", false, "Clean up the Operators and "+
+		"namespaces of installs made with --ephemeral-namespace whose time-to-live has passed")
 	cfg.BindFlags(cmd.PersistentFlags())
 
 	return cmd
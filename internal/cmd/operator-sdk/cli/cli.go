@@ -15,19 +15,41 @@
 package cli
 
 import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/bundle"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/capabilitylevel"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/catalog"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/cleanup"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/collections"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/completion"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/compliance"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/doctor"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/migrate"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/olm"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/plugin"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/preflight"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/release"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/resolve"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/scorecard"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/smoke"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/status"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/version"
 	"github.com/operator-framework/operator-sdk/internal/flags"
 	ansiblev1 "github.com/operator-framework/operator-sdk/internal/plugins/ansible/v1"
 	golangv2 "github.com/operator-framework/operator-sdk/internal/plugins/golang/v2"
 	helmv1 "github.com/operator-framework/operator-sdk/internal/plugins/helm/v1"
+	"github.com/operator-framework/operator-sdk/internal/util/cliconfig"
+	"github.com/operator-framework/operator-sdk/internal/util/exitcode"
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -37,18 +59,39 @@ import (
 
 var commands = []*cobra.Command{
 	bundle.NewCmd(),
+	capabilitylevel.NewCmd(),
+	catalog.NewCmd(),
 	cleanup.NewCmd(),
+	collections.NewCmd(),
 	completion.NewCmd(),
+	compliance.NewCmd(),
+	doctor.NewCmd(),
 	generate.NewCmd(),
+	migrate.NewCmd(),
 	olm.NewCmd(),
+	plugin.NewCmd(),
+	preflight.NewCmd(),
+	release.NewCmd(),
+	resolve.NewCmd(),
 	run.NewCmd(),
 	scorecard.NewCmd(),
+	smoke.NewCmd(),
+	status.NewCmd(),
 	version.NewCmd(),
 }
 
+// registerInitializers registers cobra's initializers, which are global, once however many times
+// GetPluginsCLIAndRoot is called. They configure the latest root, the one being run.
+var registerInitializers sync.Once
+
 func Run() error {
-	cli, _ := GetPluginsCLIAndRoot()
-	return cli.Run()
+	cli, root := GetPluginsCLIAndRoot()
+	// Plugins are added after built-in commands, which they cannot override, and only when run,
+	// so generated docs do not list the plugins installed where they are generated.
+	plugin.AddCommands(root, os.Args[1:])
+	err := cli.Run()
+	flushTraces()
+	return err
 }
 
 // GetPluginsCLIAndRoot returns the plugins based CLI configured to use operator-sdk as the root command
@@ -77,21 +120,76 @@ func GetPluginsCLIAndRoot() (cli.CLI, *cobra.Command) {
 
 	// Configure --verbose globally.
 	// TODO(estroz): upstream PR for global --verbose.
-	root.PersistentFlags().Bool(flags.VerboseOpt, false, "Enable verbose logging")
+	root.PersistentFlags().Bool(flags.VerboseOpt, false, "Enable verbose logging, equivalent to --v=1")
+	root.PersistentFlags().Int(flags.VerbosityOpt, 0, "Log verbosity: 0 logs info and above, "+
+		"1 adds debug logs, and 2 or more adds trace logs")
+	root.PersistentFlags().String(flags.LogFormatOpt, logutil.FormatText, "Log format, one of [text, json]")
+	root.PersistentFlags().String(flags.TraceOpt, "", "URL of an OTLP/HTTP collector to export spans of "+
+		"installs, cleanups, and scorecard runs to. Defaults to $"+tracing.TracesEndpointEnv+", then $"+
+		tracing.EndpointEnv)
 	if err := viper.BindPFlags(root.PersistentFlags()); err != nil {
 		log.Fatalf("Failed to bind %s flags: %v", root.Name(), err)
 	}
 	root.PersistentPreRun = rootPersistentPreRun
+	// Flag errors are validation errors, exiting with their exit code.
+	root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return exitcode.Invalid(err)
+	})
+	// Subcommands with their own PersistentPreRun do not run the root's, so configure logging
+	// for every command on initialization.
+	registerInitializers.Do(func() {
+		cobra.OnInitialize(configureLogging, configureTracing, func() { applyConfig(commands[0].Root()) })
+	})
+	root.Long = strings.TrimRight(root.Long, "\n") + "\n\n" + cliconfig.Help
 
 	return c, root
 }
 
+func configureLogging() {
+	verbosity := viper.GetInt(flags.VerbosityOpt)
+	if viper.GetBool(flags.VerboseOpt) && verbosity < 1 {
+		verbosity = 1
+	}
+	if err := logutil.Configure(viper.GetString(flags.LogFormatOpt), verbosity); err != nil {
+		log.Fatalf("Invalid logging flags: %v", err)
+	}
+}
+
+func configureTracing() {
+	tracing.Configure(viper.GetString(flags.TraceOpt))
+	// Commands exit with log.Fatal on failure, so export the spans of failed commands before exiting.
+	log.RegisterExitHandler(flushTraces)
+}
+
+// applyConfig sets the flags of the command being run not set on the command line from the config files.
+// Initializers run after flags are parsed, and before arguments are validated.
+func applyConfig(root *cobra.Command) {
+	cmd, _, err := root.Find(os.Args[1:])
+	if err != nil || !cliconfig.Uses(cmd) {
+		return
+	}
+	cfg, err := cliconfig.LoadDefault()
+	if err == nil {
+		err = cfg.Apply(cmd)
+	}
+	if err != nil {
+		exitcode.Fatal("Invalid config file", exitcode.Invalid(err))
+	}
+}
+
+func flushTraces() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := tracing.Flush(ctx); err != nil {
+		log.Warnf("Failed to export traces: %v", err)
+	}
+}
+
 func rootPersistentPreRun(cmd *cobra.Command, args []string) {
 	if viper.GetBool(flags.VerboseOpt) {
 		if err := projutil.SetGoVerbose(); err != nil {
 			log.Fatalf("Could not set GOFLAGS: (%v)", err)
 		}
-		log.SetLevel(log.DebugLevel)
 		log.Debug("Debug logging is set")
 	}
 }
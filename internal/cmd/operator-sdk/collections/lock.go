@@ -0,0 +1,95 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collections
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/ansible/collections"
+)
+
+const (
+	lockLongHelp = `'collections lock' resolves the collections in requirements.yml, and their dependencies, to exact
+versions and writes them with their download URLs and checksums to requirements.lock.yml. Scaffolded
+Ansible operator Dockerfiles install the locked versions when requirements.lock.yml exists.
+
+With '--offline', the locked collections are also downloaded to the '--dir' directory and their
+checksums verified and written to SHA256SUMS in it. A COPY instruction for the directory is added to
+the Dockerfile, which then installs the downloaded collections after verifying them again without
+accessing Galaxy, for air-gapped builds. Collections already downloaded are not downloaded again.
+`
+
+	lockExamples = `  # Lock the collections of the Ansible operator in the current directory.
+  $ operator-sdk collections lock
+
+  # Lock and download the collections, and build the operator image without accessing Galaxy.
+  $ operator-sdk collections lock --offline
+  $ make docker-build
+`
+)
+
+func newLockCmd() *cobra.Command {
+	var (
+		requirementsFile, lockFile, dir, dockerfile string
+		offline                                     bool
+	)
+	cmd := &cobra.Command{
+		Use:     "lock",
+		Short:   "Lock the collections in requirements.yml to exact versions and checksums",
+		Long:    lockLongHelp,
+		Example: lockExamples,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			reqs, err := collections.ReadRequirements(requirementsFile)
+			if err != nil {
+				return fmt.Errorf("error reading requirements: %v", err)
+			}
+			lock, err := collections.Resolve(reqs)
+			if err != nil {
+				log.Fatalf("Failed to resolve collections: %v", err)
+			}
+			if err := collections.WriteLock(lockFile, lock); err != nil {
+				log.Fatalf("Failed to write lock file: %v", err)
+			}
+			for _, c := range lock.Collections {
+				log.Infof("Locked %s %s", c.Name, c.Version)
+			}
+			if !offline {
+				return nil
+			}
+
+			if err := collections.Download(lock, dir); err != nil {
+				log.Fatalf("Failed to download collections: %v", err)
+			}
+			if err := collections.AddDownloadsToDockerfile(dockerfile, dir); err != nil {
+				log.Fatalf("Failed to update Dockerfile: %v", err)
+			}
+			log.Infof("Downloaded collections to %s", dir)
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&requirementsFile, "requirements", collections.RequirementsFile, "Requirements file to lock")
+	fs.StringVar(&lockFile, "lockfile", collections.LockFile, "Lock file to write")
+	fs.BoolVar(&offline, "offline", false, "Download the locked collections for builds without network access")
+	fs.StringVar(&dir, "dir", "collections", "Directory to download collections to with --offline")
+	fs.StringVar(&dockerfile, "dockerfile", "Dockerfile", "Dockerfile to add downloaded collections to with --offline")
+
+	return cmd
+}
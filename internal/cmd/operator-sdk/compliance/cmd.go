@@ -0,0 +1,103 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/compliance"
+	golangv2 "github.com/operator-framework/operator-sdk/internal/plugins/golang/v2"
+)
+
+const (
+	longHelp = `'compliance' checks the Go source of the project in the current directory, or in the given
+directory, for usages that violate a compliance standard. Test files, vendored code, and hidden
+directories are not checked. This command exits with an exit code of 1 if any usage is found.
+
+With '--standard fips', the following are reported:
+
+  - imports of crypto/md5, crypto/des, and crypto/rc4, whose algorithms are not FIPS-approved.
+  - imports of golang.org/x/crypto packages, which are not implemented by BoringCrypto.
+  - TLS configs with InsecureSkipVerify set to true.
+  - TLS versions below 1.2 and RC4 or 3DES cipher suites.
+
+Projects scaffolded with 'operator-sdk init --compliance=fips' build their manager with BoringCrypto,
+which only replaces standard library crypto; this command finds code that bypasses it.
+`
+
+	examples = `  # Check the project in the current directory for non-FIPS-compliant crypto usages.
+  $ operator-sdk compliance
+
+  # Write the findings as JSON, ex. to annotate them in CI.
+  $ operator-sdk compliance --output json
+`
+)
+
+func NewCmd() *cobra.Command {
+	var standard, outputFormat string
+	cmd := &cobra.Command{
+		Use:     "compliance [dir]",
+		Short:   "Check a project's source for usages that violate a compliance standard",
+		Long:    longHelp,
+		Example: examples,
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if standard != golangv2.ComplianceFIPS {
+				return fmt.Errorf("invalid value for standard flag: %v", standard)
+			}
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid value for output flag: %v", outputFormat)
+			}
+			dir := "."
+			if len(args) != 0 {
+				dir = args[0]
+			}
+
+			findings, err := compliance.ScanFIPS(dir)
+			if err != nil {
+				log.Fatalf("Failed to check compliance: %v", err)
+			}
+			if outputFormat == "json" {
+				if findings == nil {
+					findings = []compliance.Finding{}
+				}
+				b, err := json.MarshalIndent(findings, "", "    ")
+				if err != nil {
+					log.Fatalf("Failed to marshal findings: %v", err)
+				}
+				fmt.Println(string(b))
+			} else {
+				for _, f := range findings {
+					fmt.Println(f)
+				}
+			}
+			if len(findings) != 0 {
+				log.Fatalf("Found %d usages that are not %s compliant", len(findings), standard)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&standard, "standard", golangv2.ComplianceFIPS, "Compliance standard to check against. "+
+		"One of: [fips]")
+	fs.StringVarP(&outputFormat, "output", "o", "text", "Output format for findings. One of: [text, json]")
+
+	return cmd
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/doctor"
+	"github.com/operator-framework/operator-sdk/internal/olm/installer"
+)
+
+const examples = `  # Check the local container tools, opm, the current kubeconfig context's cluster, and OLM.
+  $ operator-sdk doctor
+
+  # Also check that images can be pushed to a repository.
+  $ operator-sdk doctor --repository quay.io/example/memcached-operator
+`
+
+func NewCmd() *cobra.Command {
+	var timeout time.Duration
+	d := doctor.Doctor{}
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the local environment for problems running operator-sdk commands",
+		Long: `'doctor' checks the environment operator-sdk commands run in, and suggests how to fix the problems it
+finds: that docker or podman is installed and working, opm's version, that the kubeconfig is valid and its
+cluster is reachable, which Kubernetes and OLM versions the cluster runs, and, with --repository, that images
+can be pushed to a repository with the Docker config.json's credentials. This command exits with an exit code
+of 1 if any check fails.`,
+		Example: examples,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			report := d.Run(ctx)
+			fmt.Print(report)
+			if report.HasFailures() {
+				log.Fatal("One or more checks failed")
+			}
+		},
+	}
+	cmd.Flags().StringVar(&d.KubeconfigPath, "kubeconfig", "", "Path to the kubeconfig file to check")
+	cmd.Flags().StringVar(&d.Context, "context", "", "Kubeconfig context to check. Defaults to the current context")
+	cmd.Flags().StringVar(&d.OLMNamespace, "olm-namespace", installer.DefaultOLMNamespace,
+		"namespace where OLM is installed")
+	cmd.Flags().StringVar(&d.Repository, "repository", "", "Image repository to check push access to, "+
+		"ex. quay.io/example/memcached-operator")
+	cmd.Flags().StringVar(&d.RegistryConfigDir, "registry-config-dir", "", "Directory of the Docker config.json "+
+		"registry credentials are read from. Defaults to $DOCKER_CONFIG, then ~/.docker")
+	cmd.Flags().BoolVar(&d.RegistryInsecure, "registry-insecure", false, "Use plain HTTP to check push access, "+
+		"ex. to a local registry")
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "Time to wait for the command to complete before failing")
+	return cmd
+}
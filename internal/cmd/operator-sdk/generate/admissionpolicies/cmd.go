@@ -0,0 +1,123 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admissionpolicies
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	genutil "github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/internal"
+	"github.com/operator-framework/operator-sdk/internal/generate/admissionpolicy"
+)
+
+const longHelp = `
+Running 'generate admission-policies' generates a ValidatingAdmissionPolicy and binding for each CRD in
+--crds-dir, which enforce the simple validation rules of its spec with CEL expressions in clusters where
+running the operator's validating webhook is undesirable. Rules are derived from the storage version's
+OpenAPI schema, so from kubebuilder validation markers: minimum and maximum values, string lengths and
+patterns, enums, array sizes, and required fields. Rules of array items are not enforced, and CRDs without
+rules are skipped.
+
+Policies and bindings are written to --output-dir, which defaults to the bundle's manifests so they ship
+with the bundle. They require the admissionregistration.k8s.io/v1 ValidatingAdmissionPolicy API,
+available in Kubernetes 1.30+, and an OLM version able to install them.
+`
+
+const examples = `
+  $ make bundle
+  $ operator-sdk generate admission-policies
+  $ tree bundle/manifests
+  bundle/manifests
+  ├── cache.example.com_memcacheds.yaml
+  ├── memcached-operator.clusterserviceversion.yaml
+  ├── memcacheds.cache.example.com_admissionregistration.k8s.io_v1_validatingadmissionpolicy.yaml
+  └── memcacheds.cache.example.com_admissionregistration.k8s.io_v1_validatingadmissionpolicybinding.yaml
+`
+
+type admissionPoliciesCmd struct {
+	crdsDir   string
+	outputDir string
+}
+
+// NewCmd returns the 'admission-policies' command.
+func NewCmd() *cobra.Command {
+	c := &admissionPoliciesCmd{}
+	cmd := &cobra.Command{
+		Use:     "admission-policies",
+		Short:   "Generates ValidatingAdmissionPolicies enforcing the validation rules of CRDs",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+			if err := c.run(); err != nil {
+				log.Fatalf("Error generating admission policies: %v", err)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.crdsDir, "crds-dir", filepath.Join("config", "crd", "bases"), "Directory containing CRDs")
+	fs.StringVar(&c.outputDir, "output-dir", filepath.Join("bundle", "manifests"),
+		"Directory to write policies and bindings to")
+
+	return cmd
+}
+
+func (c admissionPoliciesCmd) run() error {
+	policies, err := admissionpolicy.PoliciesFromCRDs(c.crdsDir)
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return fmt.Errorf("no CRDs with validation rules found in %s", c.crdsDir)
+	}
+
+	var objs []controllerutil.Object
+	for _, p := range policies {
+		for _, v := range []interface{}{p.Policy, p.Binding} {
+			obj, err := toUnstructured(v)
+			if err != nil {
+				return err
+			}
+			objs = append(objs, obj)
+		}
+	}
+	if err := genutil.WriteObjectsToFiles(c.outputDir, objs...); err != nil {
+		return err
+	}
+	log.Infof("Wrote %d admission policies to %s", len(policies), c.outputDir)
+	return nil
+}
+
+func toUnstructured(v interface{}) (*unstructured.Unstructured, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
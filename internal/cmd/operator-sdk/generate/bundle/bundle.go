@@ -33,6 +33,11 @@ import (
 	genutil "github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/internal"
 	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
 	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+	"github.com/operator-framework/operator-sdk/internal/generate/features"
+	"github.com/operator-framework/operator-sdk/internal/generate/i18n"
+	"github.com/operator-framework/operator-sdk/internal/generate/profile"
+	"github.com/operator-framework/operator-sdk/internal/generate/properties"
+	golangv2 "github.com/operator-framework/operator-sdk/internal/plugins/golang/v2"
 	"github.com/operator-framework/operator-sdk/internal/registry"
 	"github.com/operator-framework/operator-sdk/internal/scorecard"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
@@ -50,6 +55,60 @@ for the first time or upgrading an existing one.
 If '--output-dir' is set and you wish to build bundle images from that directory,
 either manually update your bundle.Dockerfile or set '--overwrite'.
 
+The bundle is generated in a temporary copy of '--output-dir', which replaces it only if generation succeeds,
+so a failed generation never leaves a half-written bundle. Set '--check' to exit with an error listing the
+files generation would change instead of writing them, ex. to check that a committed bundle is up-to-date in CI.
+
+Edits made to the bundle's CSV are kept when it is regenerated: the CSV last generated is recorded in the
+bundle's '.last-generated' directory, ex. bundle/.last-generated/, and edits made since are three-way merged
+with the newly generated CSV. If an edited field was also changed by generation, the command fails and lists
+the conflicting fields; make the edits in the CSV base in '--kustomize-dir' instead, or set '--merge-conflicts'
+to 'keep-edits' or 'overwrite'. The '.last-generated' directory must be committed with the bundle, or edits
+made to the CSV cannot be merged, and may be lost, when it is regenerated from a fresh checkout. A warning is
+logged if the bundle's CSV differs from the generated one but no last generated CSV exists.
+
+Set '--profiles' to generate a bundle for each of several Kubernetes distributions, in a subdirectory of
+'--output-dir' named after the profile, with a bundle.<profile>.Dockerfile. Profiles are:
+
+  vanilla    upstream Kubernetes
+  openshift  Red Hat OpenShift
+  eks        Amazon Elastic Kubernetes Service
+  gke        Google Kubernetes Engine
+
+Each profile sets the security contexts of the operator's pods and containers to satisfy the "restricted"
+Pod Security Standard where unset. The openshift profile also removes fixed user, group, and fsGroup IDs,
+which OpenShift's restricted SecurityContextConstraints reject, and sets the bundle's
+'com.redhat.openshift.versions' annotation to '--openshift-versions'. Routes and OpenShift console
+resources are only included in openshift bundles, and Ingresses in all other bundles. Other resources are
+included in the bundles of the profiles listed in their 'operator-sdk.operatorframework.io/profiles'
+annotation, ex. 'eks,gke'.
+
+Bundle properties, such as olm.constraint properties declaring the cluster and package constraints OLM must
+satisfy to install the bundle, are read from 'properties.yaml' in '--kustomize-dir' and from '--property' flags,
+then written to the bundle's 'metadata/properties.yaml'. Properties are validated first: the rules of CEL
+constraints must be valid CEL expressions evaluating to a bool, and constraints must set exactly one of
+'cel', 'gvk', 'package', 'all', 'any', or 'not'.
+
+ConfigMaps mounted or referenced by env vars in the operator's Deployments, ex. the manager's config file, are
+written to the bundle so OLM installs them with the operator. If the project declares feature gates in
+'features/features.go', their names, defaults, stages, and descriptions are documented as a JSON list in
+the CSV's 'operators.operatorframework.io/feature-gates' annotation.
+
+The CSV's display name, descriptions, and owned CRD descriptions are translated by files named after their
+locale in the 'i18n' directory of '--kustomize-dir', ex. 'i18n/de.yaml' or 'i18n/pt-BR.yaml'. Set '--locale'
+to write the CSV in that locale, ex. for a marketplace in one language. Otherwise, every translation is
+written to the CSV's 'operators.operatorframework.io/i18n' annotation as a JSON object keyed by locale.
+A translation file may set:
+
+  displayName: Memcached-Operator
+  shortDescription: Verwaltet Memcached-Cluster
+  description: |
+    Der Memcached-Operator ...
+  crds:
+    memcacheds.cache.example.com:
+      displayName: Memcached
+      description: Ein Memcached-Cluster
+
 More information on bundles:
 https://github.com/operator-framework/operator-registry/#manifest-format
 `
@@ -98,6 +157,23 @@ https://github.com/operator-framework/operator-registry/#manifest-format
 
   # You can then push your bundle image:
   $ make docker-push IMG=$BUNDLE_IMG
+
+  # Generate bundles for upstream Kubernetes and OpenShift, and build the OpenShift bundle image:
+  $ kustomize build config/manifests | operator-sdk generate bundle --version 0.0.1 --profiles vanilla,openshift
+  $ tree bundle
+  bundle
+  ├── openshift
+  │   ├── manifests
+  │   └── metadata
+  └── vanilla
+      ├── manifests
+      └── metadata
+  $ docker build -f bundle.openshift.Dockerfile -t $BUNDLE_IMG-openshift .
+
+  # Generate a bundle that requires OpenShift 4.8 or older, and a cluster serving the EtcdCluster API:
+  $ kustomize build config/manifests | operator-sdk generate bundle --version 0.0.1 \
+      --property olm.maxOpenShiftVersion=4.8 \
+      --property 'olm.constraint={"failureMessage":"requires etcd","gvk":{"group":"etcd.database.coreos.com","version":"v1beta2","kind":"EtcdCluster"}}'
 `
 )
 
@@ -124,6 +200,20 @@ func (c bundleCmd) validateManifests(*config.Config) (err error) {
 		return errors.New("--kustomize-dir must be set")
 	}
 
+	if c.locale != "" {
+		if _, err := i18n.ParseLocale(c.locale); err != nil {
+			return err
+		}
+	}
+
+	validConflicts := false
+	for _, s := range gencsv.ConflictStrategies {
+		validConflicts = validConflicts || c.mergeConflicts == string(s)
+	}
+	if !validConflicts {
+		return fmt.Errorf("--merge-conflicts must be one of %v", gencsv.ConflictStrategies)
+	}
+
 	if !genutil.IsPipeReader() {
 		if c.deployDir == "" {
 			return errors.New("--deploy-dir must be set if not reading from stdin")
@@ -137,6 +227,9 @@ func (c bundleCmd) validateManifests(*config.Config) (err error) {
 		if c.outputDir != "" {
 			return errors.New("--output-dir cannot be set if writing to stdout")
 		}
+		if c.check {
+			return errors.New("--check cannot be set if writing to stdout")
+		}
 	}
 
 	return nil
@@ -162,16 +255,14 @@ func (c bundleCmd) runManifests(cfg *config.Config) (err error) {
 		}
 	}
 
-	col := &collector.Manifests{}
-	if genutil.IsPipeReader() {
-		if err := col.UpdateFromReader(os.Stdin); err != nil {
+	col := c.col
+	if col == nil {
+		if col, err = c.collectManifests(); err != nil {
 			return err
 		}
 	}
-	if c.deployDir != "" {
-		if err := col.UpdateFromDirs(c.deployDir, c.crdsDir); err != nil {
-			return err
-		}
+	if c.profile != "" {
+		col = c.profile.Apply(col)
 	}
 
 	csvGen := gencsv.Generator{
@@ -180,6 +271,18 @@ func (c bundleCmd) runManifests(cfg *config.Config) (err error) {
 		Version:      c.version,
 		Collector:    col,
 	}
+	// Namespace-scoped managers are bound to a Role, so place rules by the scope of their resources.
+	if csvGen.ScopePermissions, err = golangv2.IsNamespaceScoped(cfg); err != nil {
+		return err
+	}
+	if csvGen.FeatureGates, err = features.ParseFile(golangv2.FeaturesFile); err != nil {
+		return err
+	}
+	if csvGen.Translations, err = i18n.ReadDir(filepath.Join(c.kustomizeDir, i18n.DirName)); err != nil {
+		return err
+	}
+	csvGen.Locale = c.locale
+	csvGen.OnConflict = gencsv.ConflictStrategy(c.mergeConflicts)
 
 	stdout := genutil.NewMultiManifestWriter(os.Stdout)
 	opts := []gencsv.Option{
@@ -198,6 +301,14 @@ func (c bundleCmd) runManifests(cfg *config.Config) (err error) {
 	}
 
 	objs := genutil.GetManifestObjects(col)
+	// Other objects are only written as optional resources of profiles.
+	if c.profile != "" {
+		resources := c.profile.Resources(col.Others)
+		for i := range resources {
+			resources[i].SetNamespace("")
+			objs = append(objs, &resources[i])
+		}
+	}
 	if c.stdout {
 		if err := genutil.WriteObjects(stdout, objs...); err != nil {
 			return err
@@ -214,13 +325,29 @@ func (c bundleCmd) runManifests(cfg *config.Config) (err error) {
 		return fmt.Errorf("error writing bundle scorecard config: %v", err)
 	}
 
-	if !c.quiet && !c.stdout {
-		fmt.Println("Bundle manifests generated successfully in", c.outputDir)
+	if !c.quiet && !c.stdout && !c.check {
+		fmt.Println("Bundle manifests generated successfully in", c.bundleDir())
 	}
 
 	return nil
 }
 
+// collectManifests collects manifests from stdin, if piped, and from c's deploy and CRD directories.
+func (c bundleCmd) collectManifests() (*collector.Manifests, error) {
+	col := &collector.Manifests{}
+	if genutil.IsPipeReader() {
+		if err := col.UpdateFromReader(os.Stdin); err != nil {
+			return nil, err
+		}
+	}
+	if c.deployDir != "" {
+		if err := col.UpdateFromDirs(c.deployDir, c.crdsDir); err != nil {
+			return nil, err
+		}
+	}
+	return col, nil
+}
+
 // writeScorecardConfig writes cfg to dir at the hard-coded config path 'config.yaml'.
 func writeScorecardConfig(dir string, cfg v1alpha3.Configuration) error {
 	if cfg.Metadata.Name == "" {
@@ -240,9 +367,25 @@ func writeScorecardConfig(dir string, cfg v1alpha3.Configuration) error {
 	return ioutil.WriteFile(scorecardConfigPath, b, 0666)
 }
 
-// validateMetadata validates c for bundle metadata generation.
-func (c bundleCmd) validateMetadata(*config.Config) (err error) {
-	return nil
+// bundleDir returns the directory the bundle is generated in.
+func (c bundleCmd) bundleDir() string {
+	if c.targetDir != "" {
+		return c.targetDir
+	}
+	return c.outputDir
+}
+
+// validateMetadata validates c for bundle metadata generation, and the bundle's properties.
+func (c *bundleCmd) validateMetadata(*config.Config) (err error) {
+	if c.props, err = properties.ReadFile(filepath.Join(c.kustomizeDir, properties.FileName)); err != nil {
+		return err
+	}
+	props, err := properties.Parse(c.properties)
+	if err != nil {
+		return err
+	}
+	c.props = append(c.props, props...)
+	return properties.Validate(c.props)
 }
 
 // runMetadata generates a bundle.Dockerfile and bundle metadata.
@@ -262,7 +405,9 @@ func (c bundleCmd) runMetadata(cfg *config.Config) error {
 		directory = filepath.Join(directory, bundle.ManifestsDir)
 	}
 	outputDir := c.outputDir
-	if filepath.Clean(outputDir) == filepath.Clean(directory) {
+	// Manifests already in the output bundle must not be copied onto themselves.
+	if filepath.Clean(outputDir) == filepath.Clean(directory) ||
+		filepath.Clean(outputDir) == filepath.Dir(filepath.Clean(directory)) {
 		outputDir = ""
 	}
 
@@ -272,9 +417,12 @@ func (c bundleCmd) runMetadata(cfg *config.Config) error {
 // generateMetadata wraps the operator-registry bundle Dockerfile/metadata generator.
 func (c bundleCmd) generateMetadata(cfg *config.Config, manifestsDir, outputDir string) error {
 
-	metadataExists := isMetatdataExist(outputDir, manifestsDir)
-	err := bundle.GenerateFunc(manifestsDir, outputDir, c.projectName, c.channels, c.defaultChannel, c.overwrite)
-	if err != nil {
+	dockerfile := c.dockerfile
+	if dockerfile == "" {
+		dockerfile = bundle.DockerFile
+	}
+	metadataExists := isMetatdataExist(outputDir, manifestsDir, dockerfile)
+	if err := c.generateBundleFiles(manifestsDir, outputDir, dockerfile); err != nil {
 		return fmt.Errorf("error generating bundle metadata: %v", err)
 	}
 
@@ -285,26 +433,101 @@ func (c bundleCmd) generateMetadata(cfg *config.Config, manifestsDir, outputDir
 			bundleRoot = filepath.Dir(manifestsDir)
 		}
 
-		if err = updateMetadata(cfg, bundleRoot); err != nil {
+		var annotations map[string]string
+		if c.profile != "" {
+			annotations = c.profile.BundleAnnotations(c.openShiftVersions)
+		}
+		if err := updateMetadata(cfg, bundleRoot, dockerfile, annotations); err != nil {
 			return err
 		}
+		if err := properties.WriteBundle(bundleRoot, c.props); err != nil {
+			return fmt.Errorf("error writing bundle properties: %v", err)
+		}
 	}
 	return nil
 }
 
+// generateBundleFiles generates the bundle metadata of the manifests in manifestsDir into outputDir, copying
+// the manifests if outputDir is set, and the bundle Dockerfile at dockerfile, like bundle.GenerateFunc.
+// GenerateFunc always writes the Dockerfile to the working directory, so it could not be staged.
+func (c bundleCmd) generateBundleFiles(manifestsDir, outputDir, dockerfile string) error {
+	manifestsDir, err := filepath.Abs(manifestsDir)
+	if err != nil {
+		return err
+	}
+	if outputDir != "" {
+		if outputDir, err = filepath.Abs(outputDir); err != nil {
+			return err
+		}
+	}
+	mediaType, err := bundle.GetMediaType(manifestsDir)
+	if err != nil {
+		return err
+	}
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	packageName, channels, defaultChannel := c.projectName, c.channels, c.defaultChannel
+	if packageName == "" || channels == "" {
+		i, err := bundle.NewBundleDirInterperter(manifestsDir)
+		if err != nil {
+			return fmt.Errorf("error inferring the bundle's package name and channels from %s; "+
+				"set them with flags instead: %v", manifestsDir, err)
+		}
+		if packageName == "" {
+			packageName = i.GetPackageName()
+		}
+		if channels == "" {
+			channels = strings.Join(i.GetBundleChannels(), ",")
+		}
+		if defaultChannel == "" {
+			defaultChannel = i.GetDefaultChannel()
+		}
+	}
+
+	annotations, err := bundle.GenerateAnnotations(mediaType, bundle.ManifestsDir, bundle.MetadataDir,
+		packageName, channels, defaultChannel)
+	if err != nil {
+		return err
+	}
+	outManifestsDir, outMetadataDir, err := bundle.CopyYamlOutput(annotations, manifestsDir, outputDir,
+		workingDir, c.overwrite)
+	if err != nil {
+		return err
+	}
+	// COPY paths are relative to the working directory, which bundle images are built from.
+	content, err := bundle.GenerateDockerfile(mediaType, bundle.ManifestsDir, bundle.MetadataDir,
+		outManifestsDir, outMetadataDir, workingDir, packageName, channels, defaultChannel)
+	if err != nil {
+		return err
+	}
+	if isExist(dockerfile) && !c.overwrite {
+		return nil
+	}
+	return ioutil.WriteFile(dockerfile, content, bundle.DefaultPermission)
+}
+
 // TODO(estroz): these updates need to be atomic because the bundle's Dockerfile and annotations.yaml
 // cannot be out-of-sync.
-func updateMetadata(cfg *config.Config, bundleRoot string) error {
+// annotations are added to the SDK's annotations.
+func updateMetadata(cfg *config.Config, bundleRoot, dockerfile string, annotations map[string]string) error {
 	bundleLabels := metricsannotations.MakeBundleMetadataLabels(cfg)
-	for key, value := range scorecardannotations.MakeBundleMetadataLabels(scorecard.DefaultConfigDir) {
-		if _, hasKey := bundleLabels[key]; hasKey {
-			return fmt.Errorf("internal error: duplicate bundle annotation key %s", key)
+	for _, labels := range []map[string]string{
+		scorecardannotations.MakeBundleMetadataLabels(scorecard.DefaultConfigDir),
+		annotations,
+	} {
+		for key, value := range labels {
+			if _, hasKey := bundleLabels[key]; hasKey {
+				return fmt.Errorf("internal error: duplicate bundle annotation key %s", key)
+			}
+			bundleLabels[key] = value
 		}
-		bundleLabels[key] = value
 	}
 
 	// Write labels to bundle Dockerfile.
-	if err := rewriteDockerfileLabels(bundle.DockerFile, bundleLabels); err != nil {
+	if err := rewriteDockerfileLabels(dockerfile, bundleLabels); err != nil {
 		return fmt.Errorf("error writing LABEL's in %s: %v", bundle.DockerFile, err)
 	}
 	if err := rewriteAnnotations(bundleRoot, bundleLabels); err != nil {
@@ -314,7 +537,7 @@ func updateMetadata(cfg *config.Config, bundleRoot string) error {
 	// Add a COPY for the scorecard config to bundle Dockerfile.
 	// TODO: change input config path to be a flag-based value.
 	localScorecardConfigPath := filepath.Join(bundleRoot, filepath.FromSlash(scorecard.DefaultConfigDir))
-	err := writeDockerfileCOPYScorecardConfig(bundle.DockerFile, localScorecardConfigPath)
+	err := writeDockerfileCOPYScorecardConfig(dockerfile, localScorecardConfigPath)
 	if err != nil {
 		return fmt.Errorf("error writing scorecard config COPY in %s: %v", bundle.DockerFile, err)
 	}
@@ -325,16 +548,16 @@ func updateMetadata(cfg *config.Config, bundleRoot string) error {
 // writeDockerfileCOPYScorecardConfig checks if bundle.Dockerfile and scorecard config exists in
 // the operator project. If it does, it injects the scorecard configuration into bundle image.
 func writeDockerfileCOPYScorecardConfig(dockerfileName, localConfigDir string) error {
-	if isExist(bundle.DockerFile) && isExist(localConfigDir) {
+	if isExist(dockerfileName) && isExist(localConfigDir) {
 		scorecardFileContent := fmt.Sprintf("COPY %s %s\n", localConfigDir, "/"+scorecard.DefaultConfigDir)
 		return projutil.RewriteFileContents(dockerfileName, "COPY", scorecardFileContent)
 	}
 	return nil
 }
 
-// isMetatdataExist returns true if bundle.Dockerfile and metadataDir exist, if not
+// isMetatdataExist returns true if dockerfile and metadataDir exist, if not
 // it returns false.
-func isMetatdataExist(outputDir, manifestsDir string) bool {
+func isMetatdataExist(outputDir, manifestsDir, dockerfile string) bool {
 	var annotationsDir string
 	if outputDir == "" {
 		annotationsDir = filepath.Dir(manifestsDir) + bundle.MetadataDir
@@ -342,7 +565,7 @@ func isMetatdataExist(outputDir, manifestsDir string) bool {
 		annotationsDir = outputDir + bundle.MetadataDir
 	}
 
-	if genutil.IsNotExist(bundle.DockerFile) || genutil.IsNotExist(annotationsDir) {
+	if genutil.IsNotExist(dockerfile) || genutil.IsNotExist(annotationsDir) {
 		return false
 	}
 	return true
@@ -386,6 +609,11 @@ func rewriteAnnotations(bundleRoot string, kvs map[string]string) error {
 	return ioutil.WriteFile(annotationsPath, b, mode)
 }
 
+// profileDockerfile returns the name of the bundle Dockerfile of p.
+func profileDockerfile(p profile.Profile) string {
+	return fmt.Sprintf("bundle.%s.Dockerfile", p)
+}
+
 // isExist returns true if path exists.
 func isExist(path string) bool {
 	_, err := os.Stat(path)
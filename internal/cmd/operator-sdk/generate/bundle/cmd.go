@@ -18,10 +18,18 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
 
+	genutil "github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/internal"
+	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+	"github.com/operator-framework/operator-sdk/internal/generate/i18n"
+	"github.com/operator-framework/operator-sdk/internal/generate/profile"
+	"github.com/operator-framework/operator-sdk/internal/generate/properties"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 )
 
@@ -41,11 +49,32 @@ type bundleCmd struct {
 	crdsDir      string
 	stdout       bool
 	quiet        bool
+	locale       string
+	// check, if set, fails generation if it would change the bundle, instead of writing it.
+	check bool
+	// targetDir, if set, is the bundle directory generation is staged for in outputDir.
+	targetDir string
+	// dockerfile, if set, is the path bundle Dockerfile is written to, instead of bundle.Dockerfile.
+	dockerfile string
+	// mergeConflicts resolves conflicts between edits made to the bundled CSV and generated changes.
+	mergeConflicts string
 
 	// Metadata options.
 	channels       string
 	defaultChannel string
 	overwrite      bool
+	properties     []string
+	// props are the bundle's properties, from the kustomize directory's properties file and properties.
+	props []properties.Property
+
+	// Profile options.
+	profiles          []string
+	openShiftVersions string
+	// profile is the profile a bundle is being generated for, if any.
+	profile profile.Profile
+	// col, if set, are the manifests to generate bundles from, collected once for all profiles
+	// since stdin can only be read once.
+	col *collector.Manifests
 }
 
 // NewCmd returns the 'bundle' command configured for the new project layout.
@@ -90,18 +119,64 @@ func NewCmd() *cobra.Command {
 				}
 			}
 
-			// Run command logic.
-			if c.manifests {
-				if err = c.runManifests(cfg); err != nil {
-					log.Fatalf("Error generating bundle manifests: %v", err)
+			profiles, err := profile.Parse(c.profiles)
+			if err != nil {
+				return fmt.Errorf("invalid command options: %v", err)
+			}
+			if len(profiles) != 0 && c.stdout {
+				return fmt.Errorf("invalid command options: --profiles cannot be set with --stdout")
+			}
+
+			// Generate into a staged copy of the bundle, which replaces the bundle only if generation succeeds.
+			var stage *genutil.Stage
+			fatalf := func(format string, args ...interface{}) {
+				if stage != nil {
+					if err := stage.Discard(); err != nil {
+						log.Errorf("Error discarding staged bundle: %v", err)
+					}
 				}
+				log.Fatalf(format, args...)
 			}
-			if c.metadata {
-				if err = c.runMetadata(cfg); err != nil {
-					log.Fatalf("Error generating bundle metadata: %v", err)
+			if !c.stdout {
+				if stage, err = c.stage(profiles); err != nil {
+					log.Fatalf("Error staging bundle: %v", err)
 				}
 			}
 
+			// Run command logic.
+			if len(profiles) != 0 {
+				if c.manifests {
+					if c.col, err = c.collectManifests(); err != nil {
+						fatalf("Error collecting manifests: %v", err)
+					}
+				}
+				for _, p := range profiles {
+					pc := c.forProfile(p)
+					if stage != nil {
+						pc.dockerfile = stage.File(pc.dockerfile)
+					}
+					if err := pc.run(cfg); err != nil {
+						fatalf("Error generating %s bundle: %v", p, err)
+					}
+				}
+			} else {
+				if c.manifests {
+					if err = c.runManifests(cfg); err != nil {
+						fatalf("Error generating bundle manifests: %v", err)
+					}
+				}
+				if c.metadata {
+					if err = c.runMetadata(cfg); err != nil {
+						fatalf("Error generating bundle metadata: %v", err)
+					}
+				}
+			}
+
+			if stage != nil {
+				if err := stage.Finish(c.check); err != nil {
+					log.Fatal(err)
+				}
+			}
 			return nil
 		},
 	}
@@ -130,5 +205,81 @@ func (c *bundleCmd) addFlagsTo(fs *pflag.FlagSet) {
 	fs.StringVar(&c.channels, "channels", "alpha", "A comma-separated list of channels the bundle belongs to")
 	fs.StringVar(&c.defaultChannel, "default-channel", "", "The default channel for the bundle")
 	fs.BoolVar(&c.overwrite, "overwrite", true, "Overwrite the bundle's metadata and Dockerfile if they exist")
+	fs.StringArrayVar(&c.properties, "property", nil, "A property of the bundle, of the form <type>=<value>, "+
+		"added to the properties in --kustomize-dir's "+properties.FileName+" and written to the bundle's "+
+		"metadata. Values that are not JSON objects or arrays are strings. May be set more than once")
 	fs.BoolVarP(&c.quiet, "quiet", "q", false, "Run in quiet mode")
+	fs.StringVar(&c.locale, "locale", "", "Locale to localize the CSV's UI metadata to, from the translation "+
+		"files in --kustomize-dir's "+i18n.DirName+" directory. If unset, all translations are written to the CSV's "+
+		i18n.Annotation+" annotation")
+	fs.BoolVar(&c.check, "check", false, "Exit with an error, listing the files generation would change, "+
+		"if the bundle is not up-to-date, instead of writing it")
+	fs.StringVar(&c.mergeConflicts, "merge-conflicts", string(gencsv.ConflictFail), fmt.Sprintf("How to "+
+		"resolve conflicts between edits made to the bundle's CSV since it was last generated and generated "+
+		"changes to the same fields. One of %v", gencsv.ConflictStrategies))
+	fs.StringSliceVar(&c.profiles, "profiles", nil, fmt.Sprintf("Comma-separated list of profiles to generate "+
+		"a bundle for each of, in a subdirectory of --output-dir named after the profile. One of %v",
+		profile.Profiles))
+	fs.StringVar(&c.openShiftVersions, "openshift-versions", profile.DefaultOpenShiftVersions,
+		"OpenShift versions the bundle of the openshift profile supports, set as its "+
+			profile.OpenShiftVersionsAnnotation+" annotation")
+}
+
+// run generates the bundle manifests and metadata c is configured to.
+func (c bundleCmd) run(cfg *config.Config) error {
+	if c.manifests {
+		if err := c.runManifests(cfg); err != nil {
+			return fmt.Errorf("error generating bundle manifests: %v", err)
+		}
+	}
+	if c.metadata {
+		if err := c.runMetadata(cfg); err != nil {
+			return fmt.Errorf("error generating bundle metadata: %v", err)
+		}
+	}
+	return nil
+}
+
+// forProfile returns c configured to generate the bundle of p, in the subdirectory of c's
+// output directory named after p.
+func (c bundleCmd) forProfile(p profile.Profile) bundleCmd {
+	outputDir := c.outputDir
+	if outputDir == "" {
+		outputDir = defaultRootDir
+	}
+	c.profile = p
+	c.dockerfile = profileDockerfile(p)
+	c.outputDir = filepath.Join(outputDir, string(p))
+	c.inputDir = c.outputDir
+	if c.targetDir != "" {
+		c.targetDir = filepath.Join(c.targetDir, string(p))
+	}
+	return c
+}
+
+// stage stages the output directory, and the bundle Dockerfiles of profiles, for generation,
+// pointing the output directory and bundle Dockerfile at their staged copies.
+func (c *bundleCmd) stage(profiles []profile.Profile) (*genutil.Stage, error) {
+	target := c.outputDir
+	if target == "" {
+		target = defaultRootDir
+	}
+	var dockerfiles []string
+	if c.metadata {
+		dockerfiles = append(dockerfiles, bundle.DockerFile)
+		for _, p := range profiles {
+			dockerfiles = append(dockerfiles, profileDockerfile(p))
+		}
+	}
+	stage, err := genutil.NewStage(target, dockerfiles...)
+	if err != nil {
+		return nil, err
+	}
+	// Read the existing bundle from the staged copy, which generation updates.
+	if filepath.Clean(c.inputDir) == stage.Target || (c.inputDir == "" && stage.Target == defaultRootDir) {
+		c.inputDir = stage.Dir
+	}
+	c.outputDir, c.targetDir = stage.Dir, stage.Target
+	c.dockerfile = stage.File(bundle.DockerFile)
+	return stage, nil
 }
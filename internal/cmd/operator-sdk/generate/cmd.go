@@ -17,9 +17,16 @@ package generate
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/admissionpolicies"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/bundle"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/csvdescription"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/docs"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/healthchecks"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/helmchart"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/kustomize"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/manifests"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/packagemanifests"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/rbac"
 )
 
 // NewCmd returns the 'generate' command configured for the new project layout.
@@ -35,6 +42,13 @@ code or manifests.`,
 		kustomize.NewCmd(),
 		bundle.NewCmd(),
 		packagemanifests.NewCmd(),
+		manifests.NewCmd(),
+		helmchart.NewCmd(),
+		rbac.NewCmd(),
+		healthchecks.NewCmd(),
+		docs.NewCmd(),
+		csvdescription.NewCmd(),
+		admissionpolicies.NewCmd(),
 	)
 	return cmd
 }
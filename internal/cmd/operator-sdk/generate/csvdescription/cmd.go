@@ -0,0 +1,137 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csvdescription
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/apidocs"
+	"github.com/operator-framework/operator-sdk/internal/generate/csvdescription"
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const longHelp = `
+Running 'generate csv-description' generates a ClusterServiceVersion's description from the project's
+README, so marketplace listings like OperatorHub do not drift from the project's docs.
+
+The README's leading title is removed, since OperatorHub titles the description with the CSV's display
+name, and so are sections titled one of --exclude-sections, like contributor docs that are irrelevant to
+users installing the operator.
+
+The description is rendered with a Go text/template, set with --template. The template is passed:
+  .Readme        the filtered README
+  .InstallNotes  the contents of --install-notes, if set
+  .Examples      the first custom resource in --samples-dir of each kind, each with .APIVersion,
+                 .Kind, and .YAML fields
+
+The default template renders the README, followed by "## Installation" and "## Examples" sections if
+there are install notes or examples.
+
+If --csv is set, the CSV's description is replaced; an "## API Reference" section written by
+'generate docs' is kept. Set --csv to the base CSV in config/manifests/bases so the description is
+included in generated bundles. Otherwise the description is printed to stdout.
+`
+
+const examples = `
+  $ operator-sdk generate csv-description \
+      --csv config/manifests/bases/memcached-operator.clusterserviceversion.yaml
+  INFO[0000] Updated description of config/manifests/bases/memcached-operator.clusterserviceversion.yaml
+
+  # Preview a description rendered with a custom template and install notes.
+  $ cat hack/description.tmpl
+  {{ .Readme }}
+
+  ## Before you install
+
+  {{ .InstallNotes }}
+  $ operator-sdk generate csv-description --template hack/description.tmpl --install-notes docs/install.md
+`
+
+type descriptionCmd struct {
+	csvdescription.Generator
+	csvPath string
+}
+
+// NewCmd returns the 'csv-description' command.
+func NewCmd() *cobra.Command {
+	c := &descriptionCmd{}
+	cmd := &cobra.Command{
+		Use:     "csv-description",
+		Short:   "Generates a ClusterServiceVersion's description from the project README",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+			if err := c.run(); err != nil {
+				log.Fatalf("Error generating CSV description: %v", err)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.ReadmePath, "readme", "README.md", "Path to the README")
+	fs.StringVar(&c.TemplatePath, "template", "", "Path to a Go text/template the description is rendered "+
+		"with. The README is followed by install notes and examples by default")
+	fs.StringVar(&c.InstallNotesPath, "install-notes", "", "Path to a Markdown file with install notes")
+	fs.StringVar(&c.SamplesDir, "samples-dir", filepath.Join("config", "samples"),
+		"Directory containing custom resource samples used as examples")
+	fs.StringSliceVar(&c.ExcludeSections, "exclude-sections", []string{"Contributing", "License"},
+		"Titles of README sections to leave out of the description")
+	fs.StringVar(&c.csvPath, "csv", "", "Path to a ClusterServiceVersion whose description is replaced. "+
+		"If unset, the description is printed to stdout")
+
+	return cmd
+}
+
+func (c descriptionCmd) run() error {
+	description, err := c.Generate()
+	if err != nil {
+		return err
+	}
+	if c.csvPath == "" {
+		fmt.Print(description)
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(c.csvPath)
+	if err != nil {
+		return fmt.Errorf("error reading CSV: %v", err)
+	}
+	csv := &v1alpha1.ClusterServiceVersion{}
+	if err := yaml.Unmarshal(b, csv); err != nil {
+		return fmt.Errorf("error unmarshalling CSV %s: %v", c.csvPath, err)
+	}
+	csv.Spec.Description = csvdescription.KeepSection(description, csv.Spec.Description,
+		apidocs.CSVSectionHeading)
+	if b, err = k8sutil.GetObjectBytes(csv, yaml.Marshal); err != nil {
+		return fmt.Errorf("error marshalling CSV %s: %v", c.csvPath, err)
+	}
+	if err := ioutil.WriteFile(c.csvPath, b, projutil.FileMode); err != nil {
+		return fmt.Errorf("error writing CSV %s: %v", c.csvPath, err)
+	}
+	log.Infof("Updated description of %s", c.csvPath)
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docs
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/apidocs"
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const longHelp = `
+Running 'generate docs' generates an API reference for each kind defined by the CRDs in --crds-dir,
+from the descriptions, types, validations, and defaults in their OpenAPI schemas. Each served version
+of a kind is documented with a table of its fields, and with the first custom resource of the version
+found in --samples-dir as an example. One file per kind is written to --output-dir in Markdown or HTML.
+
+API descriptions come from the doc comments of API types, so regenerate docs after 'make manifests'
+to keep them in sync with the APIs.
+
+If --csv is set, the "## API Reference" section of the ClusterServiceVersion's description is set to
+links to each kind's file under --docs-url, so users can find the reference from OperatorHub. Set --csv
+to the base CSV in config/manifests/bases so the links are included in generated bundles.
+`
+
+const examples = `
+  $ operator-sdk generate docs
+  INFO[0000] Wrote docs/api/cache.example.com_memcacheds.md
+
+  # Write HTML docs, and link to them from the base CSV's description.
+  $ operator-sdk generate docs --format html \
+      --csv config/manifests/bases/memcached-operator.clusterserviceversion.yaml \
+      --docs-url https://example.com/memcached-operator/api
+`
+
+type docsCmd struct {
+	crdsDir    string
+	samplesDir string
+	outputDir  string
+	format     string
+	csvPath    string
+	docsURL    string
+}
+
+// NewCmd returns the 'docs' command.
+func NewCmd() *cobra.Command {
+	c := &docsCmd{}
+	cmd := &cobra.Command{
+		Use:     "docs",
+		Short:   "Generates API reference docs from CRD schemas",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+			if c.format != apidocs.FormatMarkdown && c.format != apidocs.FormatHTML {
+				return fmt.Errorf("invalid value for format flag: %v", c.format)
+			}
+			if c.csvPath != "" && c.docsURL == "" {
+				return errors.New("--docs-url must be set with --csv, since CSV descriptions are not " +
+					"rendered alongside the docs")
+			}
+			if err := c.run(); err != nil {
+				log.Fatalf("Error generating docs: %v", err)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.crdsDir, "crds-dir", filepath.Join("config", "crd", "bases"), "Directory containing CRDs")
+	fs.StringVar(&c.samplesDir, "samples-dir", filepath.Join("config", "samples"),
+		"Directory containing custom resource samples used as examples")
+	fs.StringVar(&c.outputDir, "output-dir", filepath.Join("docs", "api"), "Directory to write docs to")
+	fs.StringVar(&c.format, "format", apidocs.FormatMarkdown, "Format of the docs. One of: [markdown, html]")
+	fs.StringVar(&c.csvPath, "csv", "", "Path to a ClusterServiceVersion whose description is updated "+
+		"with links to the docs")
+	fs.StringVar(&c.docsURL, "docs-url", "", "URL the contents of --output-dir are published at, used "+
+		"to link to the docs from the CSV")
+
+	return cmd
+}
+
+func (c docsCmd) run() error {
+	docs, err := apidocs.DocsFromCRDs(c.crdsDir, c.samplesDir)
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return fmt.Errorf("no CRDs found in %s", c.crdsDir)
+	}
+	if err := os.MkdirAll(c.outputDir, projutil.DirMode); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		b, err := apidocs.Render(doc, c.format)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(c.outputDir, doc.FileName(c.format))
+		if err := ioutil.WriteFile(path, b, projutil.FileMode); err != nil {
+			return fmt.Errorf("error writing %s: %v", path, err)
+		}
+		log.Infof("Wrote %s", path)
+	}
+
+	if c.csvPath == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(c.csvPath)
+	if err != nil {
+		return fmt.Errorf("error reading CSV: %v", err)
+	}
+	csv := &v1alpha1.ClusterServiceVersion{}
+	if err := yaml.Unmarshal(b, csv); err != nil {
+		return fmt.Errorf("error unmarshalling CSV %s: %v", c.csvPath, err)
+	}
+	apidocs.EmbedLinks(csv, docs, c.docsURL, c.format)
+	if b, err = k8sutil.GetObjectBytes(csv, yaml.Marshal); err != nil {
+		return fmt.Errorf("error marshalling CSV %s: %v", c.csvPath, err)
+	}
+	if err := ioutil.WriteFile(c.csvPath, b, projutil.FileMode); err != nil {
+		return fmt.Errorf("error writing CSV %s: %v", c.csvPath, err)
+	}
+	log.Infof("Updated API reference links in %s", c.csvPath)
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthchecks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/healthcheck"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const longHelp = `
+Running 'generate health-checks' generates health checks for the custom resources defined by the
+CRDs in --crds-dir, so GitOps tools know when custom resources managed by the operator are healthy.
+A custom resource is healthy when its status condition of type --condition-type is True, degraded
+when it is False, and progressing otherwise. CRDs whose schema has no status.conditions are skipped.
+
+Two files are written to --output-dir, alongside the bundle:
+
+- argocd-resource-customizations.yaml: a patch of ArgoCD's argocd-cm ConfigMap that adds a Lua
+  health check for each kind.
+- flux-health-checks.yaml: entries of a Flux Kustomization's spec.healthChecks for each custom
+  resource sample in --samples-dir. Flux determines health from the Ready condition.
+`
+
+const examples = `
+  $ operator-sdk generate health-checks
+  $ tree bundle/health
+  bundle/health
+  ├── argocd-resource-customizations.yaml
+  └── flux-health-checks.yaml
+`
+
+const (
+	argoCDFileName = "argocd-resource-customizations.yaml"
+	fluxFileName   = "flux-health-checks.yaml"
+)
+
+type healthChecksCmd struct {
+	crdsDir       string
+	samplesDir    string
+	outputDir     string
+	conditionType string
+}
+
+// NewCmd returns the 'health-checks' command.
+func NewCmd() *cobra.Command {
+	c := &healthChecksCmd{}
+	cmd := &cobra.Command{
+		Use:     "health-checks",
+		Short:   "Generates ArgoCD and Flux health checks for custom resources",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+			if err := c.run(); err != nil {
+				log.Fatalf("Error generating health checks: %v", err)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.crdsDir, "crds-dir", filepath.Join("bundle", "manifests"), "Directory containing CRDs")
+	fs.StringVar(&c.samplesDir, "samples-dir", filepath.Join("config", "samples"),
+		"Directory containing custom resource samples checked by Flux")
+	fs.StringVar(&c.outputDir, "output-dir", filepath.Join("bundle", "health"), "Directory to write health checks to")
+	fs.StringVar(&c.conditionType, "condition-type", healthcheck.DefaultConditionType,
+		"Type of the status condition that determines whether a custom resource is healthy")
+
+	return cmd
+}
+
+func (c healthChecksCmd) run() error {
+	resources, err := healthcheck.ResourcesFromCRDs(c.crdsDir)
+	if err != nil {
+		return err
+	}
+	if len(resources) == 0 {
+		return fmt.Errorf("no CRDs with status conditions found in %s", c.crdsDir)
+	}
+	if err := os.MkdirAll(c.outputDir, projutil.DirMode); err != nil {
+		return err
+	}
+
+	cm, err := healthcheck.ArgoCDResourceCustomizations(resources, c.conditionType)
+	if err != nil {
+		return err
+	}
+	if err := writeYAML(filepath.Join(c.outputDir, argoCDFileName), cm); err != nil {
+		return err
+	}
+
+	if c.conditionType != healthcheck.DefaultConditionType {
+		log.Warnf("Flux determines health from the %s condition, not %s", healthcheck.DefaultConditionType,
+			c.conditionType)
+	}
+	checks, err := healthcheck.FluxHealthChecks(c.samplesDir, resources)
+	if err != nil {
+		return err
+	}
+	if len(checks) == 0 {
+		log.Warnf("No custom resource samples found in %s, not writing Flux health checks", c.samplesDir)
+		return nil
+	}
+	return writeYAML(filepath.Join(c.outputDir, fluxFileName), checks)
+}
+
+func writeYAML(path string, v interface{}) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshaling %s: %v", path, err)
+	}
+	if err := ioutil.WriteFile(path, b, projutil.FileMode); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	log.Infof("Wrote %s", path)
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmchart
+
+import (
+	"errors"
+	"fmt"
+
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	genutil "github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/internal"
+	"github.com/operator-framework/operator-sdk/internal/generate/standalone"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+const longHelp = `
+Running 'generate helm-chart --from-bundle' converts an existing bundle to a Helm chart that installs
+the operator without OLM. The bundle's CRDs are written to the chart's 'crds' directory, and all other
+resources, converted from the CSV as in 'generate manifests', are templated into the release namespace.
+
+Webhook serving certificates, including those of CRD conversion webhooks, are normally provisioned
+by OLM. Set '--cert-manager' to add cert-manager resources that issue and inject them; cert-manager must
+be installed in the cluster. Otherwise, set '--webhook-cert-secret' to the name of a kubernetes.io/tls
+Secret you create in the release namespace and '--webhook-ca-file' to the CA that signed its certificate.
+The chart's templates begin with comments describing how to rotate these certificates.
+`
+
+const examples = `
+  $ operator-sdk generate helm-chart --from-bundle quay.io/example/memcached-operator-bundle:v0.0.1
+  $ tree memcached-operator
+  memcached-operator
+  ├── Chart.yaml
+  ├── crds
+  │   └── crds.yaml
+  └── templates
+      └── manifests.yaml
+
+  $ helm install memcached-operator ./memcached-operator --namespace memcached --create-namespace
+`
+
+type helmChartCmd struct {
+	fromBundle string
+	outputDir  string
+	chartName  string
+	certs      genutil.CertFlags
+}
+
+// NewCmd returns the 'helm-chart' command.
+func NewCmd() *cobra.Command {
+	c := &helmChartCmd{}
+	cmd := &cobra.Command{
+		Use:     "helm-chart",
+		Short:   "Generates a Helm chart that installs an operator bundle without OLM",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+			if c.fromBundle == "" {
+				return errors.New("--from-bundle must be set")
+			}
+			if err := c.certs.Validate(); err != nil {
+				return err
+			}
+
+			if err := c.run(cmd); err != nil {
+				log.Fatalf("Error generating Helm chart: %v", err)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.fromBundle, "from-bundle", "", "Bundle image or directory to generate a chart from")
+	fs.StringVar(&c.chartName, "chart-name", "", "Name of the chart. Defaults to the bundle's package name")
+	fs.StringVar(&c.outputDir, "output-dir", "", "Directory to write the chart to. Defaults to the chart name")
+	c.certs.BindFlags(fs)
+
+	return cmd
+}
+
+func (c helmChartCmd) run(cmd *cobra.Command) error {
+	labels, bundle, err := registryutil.LoadBundle(cmd.Context(), nil, c.fromBundle)
+	if err != nil {
+		return err
+	}
+	if bundle.CSV == nil {
+		return fmt.Errorf("no ClusterServiceVersion found in bundle %s", c.fromBundle)
+	}
+	if c.chartName == "" {
+		c.chartName = labels[registrybundle.PackageLabel]
+	}
+	if c.outputDir == "" {
+		c.outputDir = c.chartName
+	}
+
+	g := standalone.Generator{Bundle: bundle}
+	if err := c.certs.Apply(&g.Options); err != nil {
+		return err
+	}
+	if err := g.WriteHelmChart(c.outputDir, c.chartName); err != nil {
+		return err
+	}
+	log.Infof("Helm chart written to %s", c.outputDir)
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genutil
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/pflag"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/standalone"
+)
+
+// CertFlags are the flags of commands generating manifests that install a bundle without OLM,
+// which choose how webhook serving certificates are provisioned.
+type CertFlags struct {
+	CertManager bool
+	CASecret    string
+	CAFile      string
+}
+
+// BindFlags binds f to fs.
+func (f *CertFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&f.CertManager, "cert-manager", false, "Add cert-manager resources that provision webhook serving certificates")
+	fs.StringVar(&f.CASecret, "webhook-cert-secret", "", "Name of a kubernetes.io/tls Secret in the operator's namespace "+
+		"holding the webhook serving certificate. Requires --webhook-ca-file")
+	fs.StringVar(&f.CAFile, "webhook-ca-file", "", "PEM-encoded CA that signed the certificate in --webhook-cert-secret, "+
+		"injected into webhook configurations and conversion CRDs")
+}
+
+// Validate checks that at most one way of provisioning certificates is set.
+func (f CertFlags) Validate() error {
+	if f.CertManager && (f.CASecret != "" || f.CAFile != "") {
+		return errors.New("--cert-manager cannot be set with --webhook-cert-secret or --webhook-ca-file")
+	}
+	if (f.CASecret == "") != (f.CAFile == "") {
+		return errors.New("--webhook-cert-secret and --webhook-ca-file must be set together")
+	}
+	return nil
+}
+
+// Apply sets the certificate options of opts from f, reading the CA file if set.
+func (f CertFlags) Apply(opts *standalone.Options) error {
+	opts.CertManager, opts.CASecret = f.CertManager, f.CASecret
+	if f.CAFile == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(f.CAFile)
+	if err != nil {
+		return fmt.Errorf("error reading webhook CA file: %v", err)
+	}
+	opts.CABundle = b
+	return nil
+}
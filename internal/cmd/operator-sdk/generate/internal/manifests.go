@@ -15,6 +15,7 @@
 package genutil
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/operator-framework/operator-sdk/internal/generate/collector"
@@ -41,10 +42,55 @@ func GetManifestObjects(c *collector.Manifests) (objs []controllerutil.Object) {
 	_, clusterRoleObjs := c.SplitCSVClusterPermissionsObjects()
 	objs = append(objs, clusterRoleObjs...)
 
+	// ConfigMaps referenced by Deployments, ex. the manager's config file, must be installed with them.
+	objs = append(objs, referencedConfigMaps(c)...)
+
 	removeNamespace(objs)
 	return objs
 }
 
+// referencedConfigMaps returns the ConfigMaps in c.Others that are mounted as volumes or set env vars
+// in the pods of c.Deployments.
+func referencedConfigMaps(c *collector.Manifests) (objs []controllerutil.Object) {
+	names := make(map[string]struct{})
+	for _, dep := range c.Deployments {
+		podSpec := dep.Spec.Template.Spec
+		for _, volume := range podSpec.Volumes {
+			if volume.ConfigMap != nil {
+				names[volume.ConfigMap.Name] = struct{}{}
+			}
+			if volume.Projected != nil {
+				for _, source := range volume.Projected.Sources {
+					if source.ConfigMap != nil {
+						names[source.ConfigMap.Name] = struct{}{}
+					}
+				}
+			}
+		}
+		for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+			for _, container := range containers {
+				for _, env := range container.Env {
+					if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
+						names[env.ValueFrom.ConfigMapKeyRef.Name] = struct{}{}
+					}
+				}
+				for _, envFrom := range container.EnvFrom {
+					if envFrom.ConfigMapRef != nil {
+						names[envFrom.ConfigMapRef.Name] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+	configMapGVK := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+	for i := range c.Others {
+		if _, mounted := names[c.Others[i].GetName()]; mounted && c.Others[i].GroupVersionKind() == configMapGVK {
+			objs = append(objs, &c.Others[i])
+		}
+	}
+	return objs
+}
+
 // removeNamespace removes the namespace field of resources intended to be inserted into
 // an OLM manifests directory.
 //
@@ -17,11 +17,13 @@ package genutil
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/operator-framework/operator-sdk/internal/generate/collector"
 )
@@ -53,4 +55,51 @@ var _ = Describe("GetManifestObjects", func() {
 			Expect(obj.GetNamespace()).To(BeEmpty())
 		}
 	})
+	It("should include ConfigMaps referenced by Deployments", func() {
+		m := collector.Manifests{
+			Deployments: []appsv1.Deployment{{
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{{
+						Name: "config",
+						VolumeSource: corev1.VolumeSource{
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "manager-config"},
+							},
+						},
+					}},
+					Containers: []corev1.Container{{
+						Env: []corev1.EnvVar{{
+							Name: "FEATURE_GATES",
+							ValueFrom: &corev1.EnvVarSource{
+								ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "feature-gates"},
+									Key:                  "FEATURE_GATES",
+								},
+							},
+						}},
+					}},
+				}}},
+			}},
+			Others: []unstructured.Unstructured{
+				newConfigMap("manager-config"),
+				newConfigMap("feature-gates"),
+				newConfigMap("other-config"),
+			},
+		}
+		objs := GetManifestObjects(&m)
+		Expect(objs).To(HaveLen(2))
+		Expect(objs[0].GetName()).To(Equal("manager-config"))
+		Expect(objs[1].GetName()).To(Equal("feature-gates"))
+		for _, obj := range objs {
+			Expect(obj.GetNamespace()).To(BeEmpty())
+		}
+	})
 })
+
+func newConfigMap(name string) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	u.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	u.SetNamespace("foo")
+	u.SetName(name)
+	return u
+}
@@ -0,0 +1,326 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genutil
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Stage is a temporary copy of a directory that generation writes to, which replaces the directory on Commit,
+// so a failed generation never leaves the directory half-written. Files outside the directory that generation
+// writes, ex. bundle.Dockerfile, are staged as copies generation writes to instead, at File's paths.
+// Nothing outside of Dir and FilesDir is written until Commit.
+type Stage struct {
+	// Target is the staged directory.
+	Target string
+	// Dir is the copy of Target generation writes to, a hidden sibling of Target.
+	Dir string
+	// FilesDir holds the copies of staged files, a hidden sibling of Target.
+	FilesDir string
+
+	// files maps staged files to their copies in FilesDir.
+	files map[string]string
+}
+
+// NewStage copies target, if it exists, to a temporary sibling directory, and files, if they exist,
+// to another.
+func NewStage(target string, files ...string) (*Stage, error) {
+	target = filepath.Clean(target)
+	parent := filepath.Dir(target)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir(parent, "."+filepath.Base(target)+"-")
+	if err != nil {
+		return nil, err
+	}
+	filesDir, err := ioutil.TempDir(parent, "."+filepath.Base(target)+"-files-")
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	s := &Stage{Target: target, Dir: filepath.Clean(dir), FilesDir: filepath.Clean(filesDir), files: map[string]string{}}
+	fail := func(err error) (*Stage, error) {
+		_ = os.RemoveAll(s.Dir)
+		_ = os.RemoveAll(s.FilesDir)
+		return nil, err
+	}
+	// Temporary directories are only accessible by their owner, unlike generated ones.
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(target); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(dir, mode); err != nil {
+		return fail(err)
+	}
+	if err := copyDir(target, dir); err != nil && !os.IsNotExist(err) {
+		return fail(fmt.Errorf("error staging %s: %v", target, err))
+	}
+	staged := map[string]bool{}
+	for _, f := range files {
+		copyPath := filepath.Join(s.FilesDir, filepath.Base(f))
+		if staged[copyPath] {
+			return fail(fmt.Errorf("error staging %s: another staged file has the same name", f))
+		}
+		staged[copyPath] = true
+		s.files[f] = copyPath
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fail(err)
+		}
+		if err := ioutil.WriteFile(copyPath, b, 0644); err != nil {
+			return fail(err)
+		}
+	}
+	return s, nil
+}
+
+// File returns the path of the copy of the staged file f that generation writes to, or f if it is not staged.
+func (s *Stage) File(f string) string {
+	if copyPath, ok := s.files[f]; ok {
+		return copyPath
+	}
+	return f
+}
+
+// Diff returns the paths of files in Target and staged files that generation changed, added, or removed.
+func (s *Stage) Diff() ([]string, error) {
+	staged, err := readDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	current, err := readDir(s.Target)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var changed []string
+	for path, b := range staged {
+		if old, ok := current[path]; !ok || !bytes.Equal(old, b) {
+			changed = append(changed, filepath.Join(s.Target, path))
+		}
+	}
+	for path := range current {
+		if _, ok := staged[path]; !ok {
+			changed = append(changed, filepath.Join(s.Target, path))
+		}
+	}
+	for f, copyPath := range s.files {
+		old, err := readFileIfExists(f)
+		if err != nil {
+			return nil, err
+		}
+		b, err := s.readFile(copyPath)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(old, b) {
+			changed = append(changed, f)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// Commit replaces Target with Dir, and staged files with their copies, with references to Dir rewritten
+// to Target. Staged files are written to temporary files first, so a failure to write one changes nothing.
+func (s *Stage) Commit() error {
+	// written maps the temporary files of staged files to the files they replace.
+	written := map[string]string{}
+	defer func() {
+		for tmp := range written {
+			_ = os.Remove(tmp)
+		}
+	}()
+	for f, copyPath := range s.files {
+		b, err := s.readFile(copyPath)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			continue
+		}
+		tmp, err := writeTempFile(f, b)
+		if err != nil {
+			return err
+		}
+		written[tmp] = f
+	}
+
+	old := s.Dir + ".old"
+	if err := os.Rename(s.Target, old); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Rename(s.Dir, s.Target); err != nil {
+			return err
+		}
+	} else {
+		if err := os.Rename(s.Dir, s.Target); err != nil {
+			// Put the original back so Target is never missing.
+			_ = os.Rename(old, s.Target)
+			return err
+		}
+		if err := os.RemoveAll(old); err != nil {
+			return err
+		}
+	}
+	for tmp, f := range written {
+		if err := os.Rename(tmp, f); err != nil {
+			return err
+		}
+		delete(written, tmp)
+	}
+	return os.RemoveAll(s.FilesDir)
+}
+
+// Discard removes Dir and the copies of staged files, leaving Target and staged files as they were.
+func (s *Stage) Discard() error {
+	if err := os.RemoveAll(s.FilesDir); err != nil {
+		return err
+	}
+	return os.RemoveAll(s.Dir)
+}
+
+// readFile returns the contents of the staged copy copyPath, with references to Dir replaced by Target,
+// or nil if it does not exist.
+func (s *Stage) readFile(copyPath string) ([]byte, error) {
+	b, err := readFileIfExists(copyPath)
+	if b == nil || err != nil {
+		return nil, err
+	}
+	absDir, err := filepath.Abs(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	absTarget, err := filepath.Abs(s.Target)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.ReplaceAll(b, []byte(absDir), []byte(absTarget))
+	return bytes.ReplaceAll(b, []byte(s.Dir), []byte(s.Target)), nil
+}
+
+// readFileIfExists returns the contents of f, or nil if f does not exist.
+func readFileIfExists(f string) ([]byte, error) {
+	b, err := ioutil.ReadFile(f)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// copyDir copies the files of src into dst, which must exist.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		out := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(out, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, out)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(out, b, info.Mode().Perm())
+	})
+}
+
+// readDir returns the contents of the files in dir by their path relative to dir.
+func readDir(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			files[rel] = []byte(link)
+			return err
+		}
+		files[rel], err = ioutil.ReadFile(path)
+		return err
+	})
+	return files, err
+}
+
+// writeTempFile writes b to a temporary file beside path with path's mode, to be renamed over path,
+// and returns its name.
+func writeTempFile(path string, b []byte) (string, error) {
+	mode := os.FileMode(0666)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode()
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), mode)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Finish commits s, unless check is true, in which case s is discarded, and an error listing the files
+// generation changed is returned if it changed any.
+func (s *Stage) Finish(check bool) error {
+	if !check {
+		return s.Commit()
+	}
+	changed, err := s.Diff()
+	if derr := s.Discard(); err == nil {
+		err = derr
+	}
+	if err != nil {
+		return err
+	}
+	if len(changed) != 0 {
+		return fmt.Errorf("generation would change files:\n  %s", strings.Join(changed, "\n  "))
+	}
+	return nil
+}
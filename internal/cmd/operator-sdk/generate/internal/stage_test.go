@@ -0,0 +1,144 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stage", func() {
+	var (
+		tmp, target, dockerfile string
+		stage                   *Stage
+	)
+
+	read := func(path string) string {
+		b, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		return string(b)
+	}
+	write := func(path, content string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+	siblings := func() []string {
+		infos, err := ioutil.ReadDir(tmp)
+		Expect(err).NotTo(HaveOccurred())
+		names := make([]string, len(infos))
+		for i, info := range infos {
+			names[i] = info.Name()
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = ioutil.TempDir("", "stage")
+		Expect(err).NotTo(HaveOccurred())
+		target, dockerfile = filepath.Join(tmp, "bundle"), filepath.Join(tmp, "bundle.Dockerfile")
+		write(filepath.Join(target, "manifests", "csv.yaml"), "old")
+		write(filepath.Join(target, "manifests", "removed.yaml"), "removed")
+		write(dockerfile, "COPY bundle/manifests /manifests/\n")
+
+		stage, err = NewStage(target, dockerfile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Dir(stage.Dir)).To(Equal(tmp))
+		Expect(read(filepath.Join(stage.Dir, "manifests", "csv.yaml"))).To(Equal("old"))
+
+		// Generate into the stage.
+		write(filepath.Join(stage.Dir, "manifests", "csv.yaml"), "new")
+		write(filepath.Join(stage.Dir, "metadata", "annotations.yaml"), "added")
+		Expect(os.Remove(filepath.Join(stage.Dir, "manifests", "removed.yaml"))).To(Succeed())
+		Expect(read(stage.File(dockerfile))).To(Equal("COPY bundle/manifests /manifests/\n"))
+		write(stage.File(dockerfile), "COPY "+stage.Dir+"/manifests /manifests/\nCOPY "+stage.Dir+"/metadata /metadata/\n")
+		Expect(read(dockerfile)).To(Equal("COPY bundle/manifests /manifests/\n"))
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmp)).To(Succeed())
+	})
+
+	It("lists changed files", func() {
+		Expect(stage.Diff()).To(Equal([]string{
+			dockerfile,
+			filepath.Join(target, "manifests", "csv.yaml"),
+			filepath.Join(target, "manifests", "removed.yaml"),
+			filepath.Join(target, "metadata", "annotations.yaml"),
+		}))
+	})
+	It("replaces the target on commit", func() {
+		Expect(stage.Commit()).To(Succeed())
+		Expect(read(filepath.Join(target, "manifests", "csv.yaml"))).To(Equal("new"))
+		Expect(read(filepath.Join(target, "metadata", "annotations.yaml"))).To(Equal("added"))
+		Expect(filepath.Join(target, "manifests", "removed.yaml")).NotTo(BeAnExistingFile())
+		Expect(read(dockerfile)).To(Equal("COPY " + target + "/manifests /manifests/\nCOPY " + target + "/metadata /metadata/\n"))
+		Expect(siblings()).To(ConsistOf("bundle", "bundle.Dockerfile"))
+	})
+	It("leaves the target and files unchanged on discard", func() {
+		Expect(stage.Discard()).To(Succeed())
+		Expect(read(filepath.Join(target, "manifests", "csv.yaml"))).To(Equal("old"))
+		Expect(read(filepath.Join(target, "manifests", "removed.yaml"))).To(Equal("removed"))
+		Expect(read(dockerfile)).To(Equal("COPY bundle/manifests /manifests/\n"))
+		Expect(siblings()).To(ConsistOf("bundle", "bundle.Dockerfile"))
+	})
+	It("fails a check without writing if generation changed files", func() {
+		Expect(stage.Finish(true)).To(MatchError(ContainSubstring(filepath.Join(target, "manifests", "csv.yaml"))))
+		Expect(read(filepath.Join(target, "manifests", "csv.yaml"))).To(Equal("old"))
+		Expect(read(dockerfile)).To(Equal("COPY bundle/manifests /manifests/\n"))
+		Expect(siblings()).To(ConsistOf("bundle", "bundle.Dockerfile"))
+	})
+	It("passes a check if generation changed nothing", func() {
+		Expect(stage.Discard()).To(Succeed())
+		var err error
+		stage, err = NewStage(target, dockerfile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stage.Finish(true)).To(Succeed())
+	})
+	It("changes nothing on commit if a staged file cannot be written", func() {
+		Expect(stage.Discard()).To(Succeed())
+		// The staged file's directory does not exist, so it cannot be written.
+		missing := filepath.Join(tmp, "missing", "bundle.Dockerfile")
+		var err error
+		stage, err = NewStage(target, missing)
+		Expect(err).NotTo(HaveOccurred())
+		write(filepath.Join(stage.Dir, "manifests", "csv.yaml"), "new")
+		write(stage.File(missing), "COPY "+stage.Dir+"/manifests /manifests/\n")
+		Expect(stage.Commit()).NotTo(Succeed())
+		Expect(read(filepath.Join(target, "manifests", "csv.yaml"))).To(Equal("old"))
+		Expect(stage.Discard()).To(Succeed())
+		Expect(siblings()).To(ConsistOf("bundle", "bundle.Dockerfile"))
+	})
+	It("creates the target on commit if it did not exist", func() {
+		Expect(stage.Discard()).To(Succeed())
+		Expect(os.RemoveAll(target)).To(Succeed())
+		Expect(os.Remove(dockerfile)).To(Succeed())
+		var err error
+		stage, err = NewStage(target, dockerfile)
+		Expect(err).NotTo(HaveOccurred())
+		write(filepath.Join(stage.Dir, "manifests", "csv.yaml"), "new")
+		Expect(stage.Diff()).To(Equal([]string{filepath.Join(target, "manifests", "csv.yaml")}))
+		Expect(stage.Commit()).To(Succeed())
+		Expect(read(filepath.Join(target, "manifests", "csv.yaml"))).To(Equal("new"))
+		Expect(siblings()).To(ConsistOf("bundle"))
+		info, err := os.Stat(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+		Expect(dockerfile).NotTo(BeAnExistingFile())
+	})
+})
@@ -16,8 +16,11 @@ package kustomize
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -25,6 +28,8 @@ import (
 
 	genutil "github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/internal"
 	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+	"github.com/operator-framework/operator-sdk/internal/generate/properties"
+	golangv2 "github.com/operator-framework/operator-sdk/internal/plugins/golang/v2"
 	"github.com/operator-framework/operator-sdk/internal/plugins/util/kustomize"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 )
@@ -33,7 +38,8 @@ const longHelp = `
 Running 'generate kustomize manifests' will (re)generate kustomize bases and a kustomization.yaml in
 'config/manifests', which are used to build operator-framework manifests by other operator-sdk commands.
 This command will interactively ask for UI metadata, an important component of manifest bases,
-by default unless a base already exists or you set '--interactive=false'.
+by default unless a base already exists or you set '--interactive=false'. A 'properties.yaml' is also written
+if one does not exist, in which bundle properties such as olm.constraint properties can be declared.
 `
 
 const examples = `
@@ -65,6 +71,9 @@ type manifestsCmd struct {
 	outputDir   string
 	apisDir     string
 	quiet       bool
+	check       bool
+	// targetDir is the directory generation is staged for in outputDir.
+	targetDir string
 
 	// Interactive options.
 	interactiveLevel projutil.InteractiveLevel
@@ -104,9 +113,24 @@ func newManifestsCmd() *cobra.Command {
 			}
 
 			// Run command logic.
+			// Generate into a staged copy of the output directory, which replaces it only if generation succeeds.
+			stage, err := genutil.NewStage(c.outputDir)
+			if err != nil {
+				log.Fatalf("Error staging kustomize files: %v", err)
+			}
+			if filepath.Clean(c.inputDir) == stage.Target {
+				c.inputDir = stage.Dir
+			}
+			c.outputDir, c.targetDir = stage.Dir, stage.Target
 			if err = c.run(cfg); err != nil {
+				if err := stage.Discard(); err != nil {
+					log.Errorf("Error discarding staged kustomize files: %v", err)
+				}
 				log.Fatalf("Error generating kustomize files: %v", err)
 			}
+			if err := stage.Finish(c.check); err != nil {
+				log.Fatal(err)
+			}
 
 			return nil
 		},
@@ -122,6 +146,8 @@ func (c *manifestsCmd) addFlagsTo(fs *pflag.FlagSet) {
 	fs.StringVar(&c.outputDir, "output-dir", "", "Directory to write kustomize files")
 	fs.StringVar(&c.apisDir, "apis-dir", "", "Root directory for API type defintions")
 	fs.BoolVarP(&c.quiet, "quiet", "q", false, "Run in quiet mode")
+	fs.BoolVar(&c.check, "check", false, "Exit with an error, listing the files generation would change, "+
+		"if the kustomize files are not up-to-date, instead of writing them")
 	fs.BoolVar(&c.interactive, "interactive", false, "When set or no kustomize base exists, an interactive "+
 		"command prompt will be presented to accept non-inferrable metadata")
 }
@@ -159,17 +185,56 @@ const manifestsKustomization = `resources:
 - ../scorecard
 `
 
+// properties.yaml file contents for manifests, which 'generate bundle' writes to bundle metadata.
+const manifestsProperties = `# Properties of the operator's bundles, such as constraints OLM must satisfy to install them.
+# 'operator-sdk generate bundle' validates these and writes them to the bundle's metadata/properties.yaml.
+# For example:
+#
+# properties:
+# - type: olm.maxOpenShiftVersion
+#   value: "4.8"
+# - type: olm.constraint
+#   value:
+#     failureMessage: requires a cluster serving the EtcdCluster API
+#     gvk:
+#       group: etcd.database.coreos.com
+#       version: v1beta2
+#       kind: EtcdCluster
+# - type: olm.constraint
+#   value:
+#     failureMessage: requires etcd-operator v0.9.0 or later
+#     cel:
+#       rule: properties.exists(p, p.type == "olm.package" && p.value.packageName == "etcd" && semverCompare(p.value.version, "0.9.0") >= 0)
+properties: []
+`
+
+// namespaceScopedInstallModes are supported by operators whose manager only watches namespaces in WATCH_NAMESPACE,
+// which OLM sets to an OperatorGroup's target namespaces, and whose permissions are namespaced.
+var namespaceScopedInstallModes = []v1alpha1.InstallMode{
+	{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+	{Type: v1alpha1.InstallModeTypeSingleNamespace, Supported: true},
+	{Type: v1alpha1.InstallModeTypeMultiNamespace, Supported: true},
+	{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: false},
+}
+
 // run generates kustomize bundle bases and a kustomization.yaml if one does not exist.
 func (c manifestsCmd) run(cfg *config.Config) error {
 
 	if !c.quiet {
-		fmt.Println("Generating kustomize files in", c.outputDir)
+		fmt.Println("Generating kustomize files in", c.targetDir)
 	}
 
 	csvGen := gencsv.Generator{
 		OperatorName: c.projectName,
 		OperatorType: projutil.PluginKeyToOperatorType(cfg.Layout),
 	}
+	namespaceScoped, err := golangv2.IsNamespaceScoped(cfg)
+	if err != nil {
+		return err
+	}
+	if namespaceScoped {
+		csvGen.InstallModes = namespaceScopedInstallModes
+	}
 	opts := []gencsv.Option{
 		gencsv.WithBase(c.inputDir, c.apisDir, c.interactiveLevel),
 		gencsv.WithBaseWriter(c.outputDir),
@@ -182,10 +247,21 @@ func (c manifestsCmd) run(cfg *config.Config) error {
 	if err := kustomize.WriteIfNotExist(c.outputDir, manifestsKustomization); err != nil {
 		return fmt.Errorf("error writing kustomization.yaml: %v", err)
 	}
+	if err := writeIfNotExist(filepath.Join(c.outputDir, properties.FileName), manifestsProperties); err != nil {
+		return fmt.Errorf("error writing %s: %v", properties.FileName, err)
+	}
 
-	if !c.quiet {
+	if !c.quiet && !c.check {
 		fmt.Println("Kustomize files generated successfully")
 	}
 
 	return nil
 }
+
+// writeIfNotExist writes content to path if it does not exist.
+func writeIfNotExist(path, content string) error {
+	if _, err := os.Stat(path); err == nil || !os.IsNotExist(err) {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(content), 0666)
+}
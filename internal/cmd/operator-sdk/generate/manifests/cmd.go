@@ -0,0 +1,162 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifests
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	genutil "github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/internal"
+	"github.com/operator-framework/operator-sdk/internal/generate/standalone"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+const longHelp = `
+Running 'generate manifests --from-bundle' converts an existing bundle to a set of plain manifests
+that install the operator with 'kubectl apply' instead of OLM: a Namespace, the bundle's CRDs,
+a ServiceAccount, Role, and ClusterRole (with bindings) per CSV permission, the CSV's Deployments,
+and a Service and webhook configuration for each CSV webhook.
+
+Webhook serving certificates, including those of CRD conversion webhooks, are normally provisioned
+by OLM. Set '--cert-manager' to add cert-manager resources that issue and inject them; cert-manager must
+be installed in the cluster. Otherwise, set '--webhook-cert-secret' to the name of a kubernetes.io/tls
+Secret you create in the operator's namespace and '--webhook-ca-file' to the CA that signed its certificate:
+webhook servers mount the Secret, and the CA is injected into webhook configurations and conversion CRDs.
+The generated manifests begin with comments describing how to rotate these certificates.
+`
+
+const examples = `
+  # Write manifests for a bundle image to stdout, then apply them:
+  $ operator-sdk generate manifests --from-bundle quay.io/example/memcached-operator-bundle:v0.0.1 \
+      --namespace memcached | kubectl apply -f -
+
+  # Write manifests for a bundle directory to a file:
+  $ operator-sdk generate manifests --from-bundle ./bundle --cert-manager --output-dir deploy
+  $ tree deploy
+  deploy
+  └── memcached-operator.yaml
+
+  # Write manifests whose webhooks serve a certificate from a Secret signed by ca.crt:
+  $ kubectl create secret tls memcached-webhook-cert -n memcached --cert tls.crt --key tls.key
+  $ operator-sdk generate manifests --from-bundle ./bundle --namespace memcached \
+      --webhook-cert-secret memcached-webhook-cert --webhook-ca-file ca.crt | kubectl apply -f -
+`
+
+type manifestsCmd struct {
+	fromBundle string
+	outputDir  string
+	check      bool
+	certs      genutil.CertFlags
+	standalone.Options
+}
+
+// NewCmd returns the 'manifests' command.
+func NewCmd() *cobra.Command {
+	c := &manifestsCmd{}
+	cmd := &cobra.Command{
+		Use:     "manifests",
+		Short:   "Generates plain manifests that install an operator bundle without OLM",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+			if c.fromBundle == "" {
+				return errors.New("--from-bundle must be set")
+			}
+			if c.check && c.outputDir == "" {
+				return errors.New("--output-dir must be set with --check")
+			}
+			if err := c.certs.Validate(); err != nil {
+				return err
+			}
+
+			if err := c.run(cmd); err != nil {
+				log.Fatalf("Error generating manifests: %v", err)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.fromBundle, "from-bundle", "", "Bundle image or directory to generate manifests from")
+	fs.StringVar(&c.outputDir, "output-dir", "", "Directory to write manifests to. Manifests are written to stdout if unset")
+	fs.BoolVar(&c.check, "check", false, "Exit with an error if the manifests in --output-dir are not up-to-date, "+
+		"instead of writing them")
+	fs.StringVarP(&c.Namespace, "namespace", "n", "", "Namespace to install the operator in. "+
+		"Defaults to '<package name>-system'")
+	c.certs.BindFlags(fs)
+
+	return cmd
+}
+
+func (c manifestsCmd) run(cmd *cobra.Command) error {
+	labels, bundle, err := registryutil.LoadBundle(cmd.Context(), nil, c.fromBundle)
+	if err != nil {
+		return err
+	}
+	if bundle.CSV == nil {
+		return fmt.Errorf("no ClusterServiceVersion found in bundle %s", c.fromBundle)
+	}
+	pkgName := labels[registrybundle.PackageLabel]
+	if c.Namespace == "" {
+		c.Namespace = pkgName + "-system"
+	}
+
+	if err := c.certs.Apply(&c.Options); err != nil {
+		return err
+	}
+
+	g := standalone.Generator{Bundle: bundle, Options: c.Options}
+	if c.outputDir == "" {
+		return g.WriteManifests(os.Stdout)
+	}
+	// Write into a staged copy of the output directory, which replaces it only if writing succeeds.
+	stage, err := genutil.NewStage(c.outputDir)
+	if err != nil {
+		return err
+	}
+	if err := writeManifests(filepath.Join(stage.Dir, pkgName+".yaml"), g); err != nil {
+		_ = stage.Discard()
+		return err
+	}
+	if err := stage.Finish(c.check); err != nil {
+		return err
+	}
+	if !c.check {
+		log.Infof("Manifests written to %s", filepath.Join(c.outputDir, pkgName+".yaml"))
+	}
+	return nil
+}
+
+// writeManifests writes the manifests generated by g to a file at path.
+func writeManifests(path string, g standalone.Generator) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := g.WriteManifests(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
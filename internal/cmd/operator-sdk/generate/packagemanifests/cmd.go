@@ -22,6 +22,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	"github.com/operator-framework/operator-sdk/internal/generate/i18n"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 )
 
@@ -39,6 +40,7 @@ type packagemanifestsCmd struct {
 	updateObjects bool
 	stdout        bool
 	quiet         bool
+	locale        string
 
 	// Package manifest options.
 	channelName      string
@@ -100,5 +102,8 @@ func (c *packagemanifestsCmd) addFlagsTo(fs *pflag.FlagSet) {
 	fs.BoolVar(&c.updateObjects, "update-objects", true, "Update non-CSV objects in this package, "+
 		"ex. CustomResoureDefinitions, Roles")
 	fs.BoolVarP(&c.quiet, "quiet", "q", false, "Run in quiet mode")
+	fs.StringVar(&c.locale, "locale", "", "Locale to localize the CSV's UI metadata to, from the translation "+
+		"files in --kustomize-dir's "+i18n.DirName+" directory. If unset, all translations are written to the CSV's "+
+		i18n.Annotation+" annotation")
 	fs.BoolVar(&c.stdout, "stdout", false, "Write package to stdout")
 }
@@ -25,7 +25,10 @@ import (
 	genutil "github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/internal"
 	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
 	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+	"github.com/operator-framework/operator-sdk/internal/generate/features"
+	"github.com/operator-framework/operator-sdk/internal/generate/i18n"
 	genpkg "github.com/operator-framework/operator-sdk/internal/generate/packagemanifest"
+	golangv2 "github.com/operator-framework/operator-sdk/internal/plugins/golang/v2"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 )
 
@@ -41,6 +44,9 @@ consumed by this command.
 
 Set '--version' to supply a semantic version for your new package.
 
+Set '--locale' to write the CSV's UI metadata in a locale translated by the files in the 'i18n' directory of
+'--kustomize-dir'. Run 'operator-sdk generate bundle -h' for the format of translation files.
+
 More information on the package manifests format:
 https://github.com/operator-framework/operator-registry/#manifest-format
 `
@@ -118,6 +124,12 @@ func (c packagemanifestsCmd) validate() error {
 		return errors.New("--kustomize-dir must be set")
 	}
 
+	if c.locale != "" {
+		if _, err := i18n.ParseLocale(c.locale); err != nil {
+			return err
+		}
+	}
+
 	if !genutil.IsPipeReader() {
 		if c.deployDir == "" {
 			return errors.New("--deploy-dir must be set if not reading from stdin")
@@ -170,6 +182,18 @@ func (c packagemanifestsCmd) run(cfg *config.Config) error {
 		FromVersion:  c.fromVersion,
 		Collector:    col,
 	}
+	// Namespace-scoped managers are bound to a Role, so place rules by the scope of their resources.
+	var err error
+	if csvGen.ScopePermissions, err = golangv2.IsNamespaceScoped(cfg); err != nil {
+		return err
+	}
+	if csvGen.FeatureGates, err = features.ParseFile(golangv2.FeaturesFile); err != nil {
+		return err
+	}
+	if csvGen.Translations, err = i18n.ReadDir(filepath.Join(c.kustomizeDir, i18n.DirName)); err != nil {
+		return err
+	}
+	csvGen.Locale = c.locale
 
 	stdout := genutil.NewMultiManifestWriter(os.Stdout)
 	opts := []gencsv.Option{
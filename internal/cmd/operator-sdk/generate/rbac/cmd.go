@@ -0,0 +1,130 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	genrbac "github.com/operator-framework/operator-sdk/internal/generate/rbac"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const longHelp = `
+Running 'generate rbac --from-code' analyzes controller code for controller-runtime client calls
+(Get, List, Create, Update, Patch, Delete, DeleteAllOf, and their status equivalents), builder watches
+(For, Owns, and source.Kind), and event recorders, then prints the minimal kubebuilder RBAC markers
+those calls require. Reads and watches require get, list, and watch, since they are served by the
+manager's cache.
+
+Rules in the manager's role (config/rbac/role.yaml) that no analyzed call requires are reported
+so they can be removed. Calls on unstructured objects or interfaces cannot be analyzed and are
+reported as warnings; add markers for them by hand.
+`
+
+const examples = `
+  $ operator-sdk generate rbac --from-code
+  // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update
+  // +kubebuilder:rbac:groups=cache.example.com,resources=memcacheds,verbs=get;list;watch;update
+  // +kubebuilder:rbac:groups=cache.example.com,resources=memcacheds/status,verbs=update
+  // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+  WARN[0001] Rule in config/rbac/role.yaml not required by code: groups=cache.example.com resources=memcacheds verbs=delete
+`
+
+type rbacCmd struct {
+	fromCode bool
+	packages []string
+	rolePath string
+}
+
+// NewCmd returns the 'rbac' command.
+func NewCmd() *cobra.Command {
+	c := &rbacCmd{}
+	cmd := &cobra.Command{
+		Use:     "rbac",
+		Short:   "Generates minimal RBAC markers from controller code",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+			if !c.fromCode {
+				return errors.New("--from-code must be set")
+			}
+
+			if err := c.run(); err != nil {
+				log.Fatalf("Error generating RBAC: %v", err)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.BoolVar(&c.fromCode, "from-code", false, "Analyze controller code to find the RBAC rules it requires")
+	fs.StringSliceVar(&c.packages, "packages", []string{"./controllers/..."}, "Go package patterns to analyze")
+	fs.StringVar(&c.rolePath, "role", "config/rbac/role.yaml", "Path to the manager's role, whose rules "+
+		"are compared with those required by code. Ignored if the file does not exist")
+
+	return cmd
+}
+
+func (c rbacCmd) run() error {
+	a := &genrbac.Analyzer{}
+	if projutil.HasProjectFile() {
+		cfg, err := projutil.ReadConfig()
+		if err != nil {
+			return fmt.Errorf("error reading configuration: %v", err)
+		}
+		for _, r := range cfg.Resources {
+			a.GVKs = append(a.GVKs, schema.GroupVersionKind{
+				Group:   fmt.Sprintf("%s.%s", r.Group, cfg.Domain),
+				Version: r.Version,
+				Kind:    r.Kind,
+			})
+		}
+	}
+
+	rules, err := a.Analyze(c.packages...)
+	if err != nil {
+		return err
+	}
+	for _, marker := range genrbac.Markers(rules) {
+		fmt.Println(marker)
+	}
+
+	b, err := ioutil.ReadFile(c.rolePath)
+	if err != nil {
+		log.Debugf("Not comparing rules with role %s: %v", c.rolePath, err)
+		return nil
+	}
+	role := rbacv1.ClusterRole{}
+	if err := yaml.Unmarshal(b, &role); err != nil {
+		return fmt.Errorf("error unmarshalling role %s: %v", c.rolePath, err)
+	}
+	for _, rule := range genrbac.Unused(rules, role.Rules) {
+		log.Warnf("Rule in %s not required by code: groups=%s resources=%s verbs=%s", c.rolePath,
+			rule.APIGroups[0], rule.Resources[0], strings.Join(rule.Verbs, ";"))
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/migrate"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const apisLongHelp = `
+Running 'migrate apis' rewrites the manifests in --dirs from the deprecated apiextensions.k8s.io/v1beta1
+and admissionregistration.k8s.io/v1beta1 API versions to v1, which is required by Kubernetes 1.22:
+
+- CRDs are converted to v1, moving their schemas, subresources, and printer columns into each version.
+  Structural schema problems that can be inferred are fixed, ex. a missing 'type: object' on schemas
+  with properties. Others, ex. schemas without a type, are reported.
+- CRD patches, ex. the conversion webhook patches in config/crd/patches, are converted to v1.
+- Webhook configurations are converted to v1. Fields whose defaults changed in v1 are set to their
+  v1beta1 defaults so webhooks behave the same, and sideEffects values v1 does not allow are reported.
+
+The CRD_OPTIONS variable in --makefile is updated so 'make manifests' generates v1 CRDs.
+
+Migrated manifests are re-serialized, so comments in them are not preserved. Review every fix, and
+address every reported issue, before committing the result. With --dry-run, fixes and issues are
+reported without writing files.
+`
+
+const apisExamples = `
+  $ operator-sdk migrate apis
+  INFO[0000] Migrated config/crd/bases/cache.example.com_memcacheds.yaml
+  WARN[0000] config/crd/bases/cache.example.com_memcacheds.yaml: CRD memcacheds.cache.example.com: v1 CRDs prune unknown fields, ...
+  INFO[0000] Migrated config/webhook/manifests.yaml
+  INFO[0000]   Fix: MutatingWebhookConfiguration mutating-webhook-configuration webhook mmemcached.kb.io: set admissionReviewVersions to [v1beta1], the v1beta1 default
+  INFO[0000] Migrated Makefile
+`
+
+// webhookGeneratorIssue is reported when webhook configurations are migrated in a project with a Makefile,
+// since controller-gen regenerates them as v1beta1 unless configured otherwise.
+const webhookGeneratorIssue = "controller-gen generates v1beta1 webhook configurations from " +
+	"+kubebuilder:webhook markers; upgrade controller-gen to v0.4.1 or later, add 'webhook:webhookVersions=v1' " +
+	"to its arguments, and set sideEffects and admissionReviewVersions on each marker"
+
+type apisCmd struct {
+	dirs     []string
+	makefile string
+	dryRun   bool
+}
+
+func newAPIsCmd() *cobra.Command {
+	c := &apisCmd{}
+	cmd := &cobra.Command{
+		Use:     "apis",
+		Short:   "Migrate CRD and webhook manifests from v1beta1 to v1 APIs",
+		Long:    apisLongHelp,
+		Example: apisExamples,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := c.run(); err != nil {
+				log.Fatalf("Error migrating APIs: %v", err)
+			}
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringSliceVar(&c.dirs, "dirs", []string{filepath.Join("config", "crd"), filepath.Join("config", "webhook"),
+		filepath.Join("config", "default")}, "Directories containing manifests to migrate")
+	fs.StringVar(&c.makefile, "makefile", "Makefile", "Path to the project's Makefile, whose CRD_OPTIONS "+
+		"are updated. Ignored if the file does not exist")
+	fs.BoolVar(&c.dryRun, "dry-run", false, "Report fixes and issues without writing files")
+
+	return cmd
+}
+
+func (c apisCmd) run() error {
+	results, err := migrate.Dirs(c.dirs...)
+	if err != nil {
+		return err
+	}
+	migratedWebhooks := false
+	for _, r := range results {
+		if filepath.Base(filepath.Dir(r.Path)) == "webhook" && r.Changed() {
+			migratedWebhooks = true
+		}
+	}
+
+	if b, err := ioutil.ReadFile(c.makefile); err == nil {
+		r := migrate.Makefile(b)
+		r.Path = c.makefile
+		if migratedWebhooks {
+			r.Issues = append(r.Issues, webhookGeneratorIssue)
+		}
+		if r.Changed() || len(r.Issues) != 0 {
+			results = append(results, r)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("error reading %s: %v", c.makefile, err)
+	}
+
+	if len(results) == 0 {
+		log.Info("No deprecated API versions found")
+		return nil
+	}
+	issues := 0
+	for _, r := range results {
+		if r.Changed() {
+			if !c.dryRun {
+				if err := ioutil.WriteFile(r.Path, r.Content, projutil.FileMode); err != nil {
+					return fmt.Errorf("error writing %s: %v", r.Path, err)
+				}
+			}
+			log.Infof("Migrated %s", r.Path)
+		}
+		for _, fix := range r.Fixes {
+			log.Infof("  Fix: %s", fix)
+		}
+		for _, issue := range r.Issues {
+			log.Warnf("%s: %s", r.Path, issue)
+		}
+		issues += len(r.Issues)
+	}
+	if issues != 0 {
+		log.Warnf("%d issues must be fixed by hand", issues)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/operator-framework/operator-sdk/internal/migrate"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const scanLongHelp = `
+Running 'scan' reports uses of Kubernetes API versions that are deprecated or removed in --k8s-version,
+so they can be migrated before the operator is installed on a cluster that no longer serves them.
+
+The project's type, read from its PROJECT file, determines what is scanned. In Go projects, API types used
+by Go code outside of vendor and bin directories are reported. In every project, apiVersions of the YAML
+manifests in config and bundle are reported, along with those in helm-charts in Helm projects and in roles,
+playbooks, and molecule in Ansible projects. Manifests are scanned line by line, so templated manifests
+are scanned as well. Outside of a project, every Go file and manifest is scanned.
+
+The command fails if any API removed in --k8s-version is used. Deprecated APIs are reported as warnings.
+`
+
+const scanExamples = `
+  $ operator-sdk migrate scan --k8s-version v1.22
+  WARN[0000] controllers/memcached_controller.go:52: batch/v1beta1 CronJob is deprecated in v1.21 and removed in v1.25, use batch/v1
+  ERRO[0000] config/crd/bases/cache.example.com_memcacheds.yaml:2: apiextensions.k8s.io/v1beta1 CustomResourceDefinition is deprecated in v1.16 and removed in v1.22, use apiextensions.k8s.io/v1
+  FATA[0000] Error scanning APIs: 1 APIs removed in v1.22 are used
+`
+
+type scanCmd struct {
+	k8sVersion string
+}
+
+func newScanCmd() *cobra.Command {
+	c := &scanCmd{}
+	cmd := &cobra.Command{
+		Use:     "scan",
+		Short:   "Report deprecated and removed Kubernetes APIs used by the project",
+		Long:    scanLongHelp,
+		Example: scanExamples,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := c.run(); err != nil {
+				log.Fatalf("Error scanning APIs: %v", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&c.k8sVersion, "k8s-version", migrate.LatestRemovalRelease,
+		"Kubernetes release to report APIs deprecated or removed in")
+
+	return cmd
+}
+
+func (c scanCmd) run() error {
+	target, err := version.ParseGeneric(c.k8sVersion)
+	if err != nil {
+		return fmt.Errorf("invalid --k8s-version: %v", err)
+	}
+
+	operatorType := projutil.OperatorTypeUnknown
+	if projutil.HasProjectFile() {
+		cfg, err := projutil.ReadConfig()
+		if err != nil {
+			return fmt.Errorf("error reading PROJECT file: %v", err)
+		}
+		operatorType = projutil.PluginKeyToOperatorType(cfg.Layout)
+	} else if _, err := os.Stat("go.mod"); err == nil {
+		operatorType = projutil.OperatorTypeGo
+	}
+	uses, err := migrate.ScanDeprecatedAPIs(migrate.ScanDirs(operatorType))
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, use := range uses {
+		switch use.Status(target) {
+		case migrate.StatusRemoved:
+			log.Error(use)
+			removed++
+		case migrate.StatusDeprecated:
+			log.Warn(use)
+		}
+	}
+	if removed != 0 {
+		return fmt.Errorf("%d APIs removed in %s are used", removed, c.k8sVersion)
+	}
+	log.Infof("No APIs removed in %s are used", c.k8sVersion)
+	return nil
+}
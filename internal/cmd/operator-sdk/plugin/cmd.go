@@ -0,0 +1,75 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Manage plugins extending operator-sdk with subcommands",
+		Long: `Executables named operator-sdk-<name> in PATH are plugins, run by 'operator-sdk <name>' with the
+remaining arguments and flags, so vendors can add subcommands, ex. internal certification checks, without
+forking operator-sdk. A plugin's exit code is operator-sdk's exit code.
+
+A plugin may have a manifest next to its executable, named operator-sdk-<name>.yaml, whose 'short', 'long',
+and 'example' fields are shown in 'operator-sdk --help' and 'operator-sdk help <name>'. Plugins cannot
+override built-in commands, and the first executable of a name in PATH shadows later ones. PATH is only
+searched for the plugin being run, except by 'operator-sdk --help' and 'plugin list', which list every plugin.`,
+	}
+	cmd.AddCommand(newListCmd())
+	return cmd
+}
+
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the plugins in PATH",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if err := writeList(os.Stdout, cmd.Root(), Discover(os.Getenv("PATH"))); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+}
+
+// writeList writes a table of plugins to w, and warns of plugins that are not run or are missing help.
+func writeList(w io.Writer, root *cobra.Command, plugins []Plugin) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPATH\tDESCRIPTION")
+	for _, p := range plugins {
+		description := p.Manifest.Short
+		if isBuiltin(root, p.Name) {
+			log.Warnf("Plugin %s is not run, since it is named like a built-in command", p.Path)
+		}
+		for _, path := range p.Shadowed {
+			log.Warnf("Plugin %s is shadowed by %s, which is earlier in PATH", path, p.Path)
+		}
+		if p.Error != nil {
+			log.Warnf("Plugin %s has an invalid manifest: %v", p.Path, p.Error)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Name, p.Path, description)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,253 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// Prefix is the prefix of the names of plugin executables. An executable named operator-sdk-<name> in PATH
+// is run by 'operator-sdk <name>'.
+const Prefix = "operator-sdk-"
+
+// ManifestSuffix is the suffix of a plugin's manifest, found next to its executable, ex. operator-sdk-foo.yaml.
+const ManifestSuffix = ".yaml"
+
+// Manifest describes a plugin for help output.
+type Manifest struct {
+	// Short is the one-line description shown in 'operator-sdk --help'.
+	Short string `json:"short,omitempty"`
+	// Long is the description shown in 'operator-sdk help <name>'.
+	Long string `json:"long,omitempty"`
+	// Example is the plugin's usage examples.
+	Example string `json:"example,omitempty"`
+}
+
+// Plugin is an executable in PATH extending operator-sdk with a subcommand.
+type Plugin struct {
+	// Name is the subcommand the plugin is run by.
+	Name string
+	// Path is the path of the plugin's executable.
+	Path string
+	// Manifest is the plugin's manifest, empty if it has none.
+	Manifest Manifest
+	// Shadowed lists the paths of executables of the same name later in PATH, which are not run.
+	Shadowed []string
+	// Error is why the plugin's manifest could not be read, if it could not.
+	Error error
+}
+
+// Discover returns the plugins in the directories of path, a list like $PATH, sorted by name.
+// Executables earlier in path shadow those of the same name later in path.
+func Discover(path string) []Plugin {
+	plugins := map[string]*Plugin{}
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			continue
+		}
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, info := range infos {
+			name, ok := pluginName(info)
+			if !ok {
+				continue
+			}
+			exe := filepath.Join(dir, info.Name())
+			if p, found := plugins[name]; found {
+				p.Shadowed = append(p.Shadowed, exe)
+				continue
+			}
+			p := &Plugin{Name: name, Path: exe}
+			p.Manifest, p.Error = readManifest(exe)
+			plugins[name] = p
+		}
+	}
+
+	list := make([]Plugin, 0, len(plugins))
+	for _, p := range plugins {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Find returns the plugin named name in the directories of path, a list like $PATH, and whether it exists.
+// Unlike Discover, only the plugin's own executable is looked up in each directory.
+func Find(path, name string) (Plugin, bool) {
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			continue
+		}
+		for _, file := range pluginFileNames(name) {
+			exe := filepath.Join(dir, file)
+			info, err := os.Stat(exe)
+			if err != nil {
+				continue
+			}
+			if n, ok := pluginName(info); ok && n == name {
+				p := Plugin{Name: name, Path: exe}
+				p.Manifest, p.Error = readManifest(exe)
+				return p, true
+			}
+		}
+	}
+	return Plugin{}, false
+}
+
+// pluginFileNames returns the names the executable of the plugin named name may have.
+func pluginFileNames(name string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{Prefix + name + ".exe", Prefix + name + ".bat", Prefix + name + ".cmd"}
+	}
+	return []string{Prefix + name}
+}
+
+// pluginName returns the name of the plugin info is the executable of, and whether it is one.
+func pluginName(info os.FileInfo) (string, bool) {
+	if info.IsDir() || !strings.HasPrefix(info.Name(), Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(info.Name(), Prefix)
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	} else if info.Mode()&0111 == 0 {
+		return "", false
+	}
+	return name, name != "" && !strings.HasSuffix(name, ManifestSuffix)
+}
+
+// readManifest reads the manifest of the plugin executable exe, if it has one.
+func readManifest(exe string) (Manifest, error) {
+	m := Manifest{}
+	path := exe
+	if runtime.GOOS == "windows" {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	path += ManifestSuffix
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return m, err
+	}
+	if err := yaml.UnmarshalStrict(b, &m); err != nil {
+		return m, fmt.Errorf("error parsing manifest %s: %v", path, err)
+	}
+	return m, nil
+}
+
+// AddCommands adds the plugin commands args, the CLI's arguments without the program name, may run to root.
+// Like kubectl, $PATH is only searched for the plugin named by the first argument, if it is not one of root's
+// commands, which cannot be overridden. Every plugin in $PATH is added only if args asks for root's help,
+// so it lists them.
+func AddCommands(root *cobra.Command, args []string) {
+	if wantsHelp(args) {
+		for _, p := range Discover(os.Getenv("PATH")) {
+			if !isBuiltin(root, p.Name) {
+				root.AddCommand(newPluginCmd(p))
+			}
+		}
+		return
+	}
+	name := args[0]
+	if strings.HasPrefix(name, "-") || isBuiltin(root, name) {
+		return
+	}
+	if p, ok := Find(os.Getenv("PATH"), name); ok {
+		root.AddCommand(newPluginCmd(p))
+	}
+}
+
+// wantsHelp returns whether args asks for the root command's help, or the help of a command.
+func wantsHelp(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	switch args[0] {
+	case "help", "-h", "--help":
+		return true
+	}
+	return false
+}
+
+// isBuiltin returns whether name is the name or an alias of a command of root that does not run a plugin.
+func isBuiltin(root *cobra.Command, name string) bool {
+	for _, cmd := range root.Commands() {
+		if _, ok := cmd.Annotations[pluginAnnotation]; ok {
+			continue
+		}
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return name == "help"
+}
+
+// newPluginCmd returns a command running p with its arguments. Flags, including --help, are passed to p.
+func newPluginCmd(p Plugin) *cobra.Command {
+	short := p.Manifest.Short
+	if short == "" {
+		short = fmt.Sprintf("Run the %s%s plugin", Prefix, p.Name)
+	}
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              short,
+		Long:               p.Manifest.Long,
+		Example:            p.Manifest.Example,
+		DisableFlagParsing: true,
+		Annotations:        map[string]string{pluginAnnotation: p.Path},
+		Run: func(cmd *cobra.Command, args []string) {
+			// Exit through logrus so its exit handlers, ex. flushing traces, run.
+			log.Exit(run(p.Path, args))
+		},
+	}
+}
+
+// pluginAnnotation is the annotation of plugin commands holding the plugin's path.
+const pluginAnnotation = "operator-sdk.io/plugin"
+
+// run runs the executable at path with args and the CLI's standard streams, and returns its exit code.
+func run(path string, args []string) int {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "Failed to run plugin %s: %v\n", path, err)
+		return 1
+	}
+	return 0
+}
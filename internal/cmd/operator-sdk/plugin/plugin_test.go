@@ -0,0 +1,186 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+var _ = Describe("Plugins", func() {
+	var dirs [2]string
+
+	writeFile := func(dir, name, content string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(content), mode)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("plugins are shell scripts")
+		}
+		for i := range dirs {
+			var err error
+			dirs[i], err = ioutil.TempDir("", "plugins-")
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+	AfterEach(func() {
+		for _, dir := range dirs {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		}
+	})
+
+	Describe("Discover", func() {
+		It("finds executables named with the plugin prefix", func() {
+			hello := writeFile(dirs[0], "operator-sdk-hello", "#!/bin/sh\n", 0755)
+			writeFile(dirs[0], "operator-sdk-not-executable", "#!/bin/sh\n", 0644)
+			writeFile(dirs[0], "kubectl-hello", "#!/bin/sh\n", 0755)
+			Expect(os.Mkdir(filepath.Join(dirs[0], "operator-sdk-dir"), 0755)).To(Succeed())
+
+			plugins := Discover(strings.Join(dirs[:], string(os.PathListSeparator)))
+			Expect(plugins).To(Equal([]Plugin{{Name: "hello", Path: hello}}))
+		})
+		It("reads manifests next to executables", func() {
+			hello := writeFile(dirs[0], "operator-sdk-hello", "#!/bin/sh\n", 0755)
+			writeFile(dirs[0], "operator-sdk-hello.yaml", "short: Say hello\nlong: Says hello.\n", 0644)
+			bad := writeFile(dirs[0], "operator-sdk-bad", "#!/bin/sh\n", 0755)
+			writeFile(dirs[0], "operator-sdk-bad.yaml", "description: unknown field\n", 0644)
+
+			plugins := Discover(dirs[0])
+			Expect(plugins).To(HaveLen(2))
+			Expect(plugins[0].Path).To(Equal(bad))
+			Expect(plugins[0].Error).To(MatchError(ContainSubstring("error parsing manifest")))
+			Expect(plugins[1]).To(Equal(Plugin{Name: "hello", Path: hello,
+				Manifest: Manifest{Short: "Say hello", Long: "Says hello."}}))
+		})
+		It("shadows executables later in PATH", func() {
+			first := writeFile(dirs[0], "operator-sdk-hello", "#!/bin/sh\n", 0755)
+			second := writeFile(dirs[1], "operator-sdk-hello", "#!/bin/sh\n", 0755)
+
+			plugins := Discover(strings.Join(dirs[:], string(os.PathListSeparator)))
+			Expect(plugins).To(Equal([]Plugin{{Name: "hello", Path: first, Shadowed: []string{second}}}))
+		})
+	})
+
+	Describe("Find", func() {
+		It("finds the first executable of a plugin in PATH", func() {
+			writeFile(dirs[0], "operator-sdk-hello", "#!/bin/sh\n", 0644)
+			hello := writeFile(dirs[1], "operator-sdk-hello", "#!/bin/sh\n", 0755)
+			writeFile(dirs[1], "operator-sdk-hello.yaml", "short: Say hello\n", 0644)
+
+			p, ok := Find(strings.Join(dirs[:], string(os.PathListSeparator)), "hello")
+			Expect(ok).To(BeTrue())
+			Expect(p).To(Equal(Plugin{Name: "hello", Path: hello, Manifest: Manifest{Short: "Say hello"}}))
+			_, ok = Find(strings.Join(dirs[:], string(os.PathListSeparator)), "missing")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("AddCommands", func() {
+		var (
+			root *cobra.Command
+			path string
+		)
+
+		BeforeEach(func() {
+			root = &cobra.Command{Use: "operator-sdk"}
+			root.AddCommand(&cobra.Command{Use: "run"})
+			writeFile(dirs[0], "operator-sdk-hello", "#!/bin/sh\n", 0755)
+			writeFile(dirs[0], "operator-sdk-bye", "#!/bin/sh\n", 0755)
+			writeFile(dirs[0], "operator-sdk-run", "#!/bin/sh\n", 0755)
+			path = os.Getenv("PATH")
+			Expect(os.Setenv("PATH", dirs[0])).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.Setenv("PATH", path)).To(Succeed())
+		})
+
+		names := func() (names []string) {
+			for _, cmd := range root.Commands() {
+				names = append(names, cmd.Name())
+			}
+			return names
+		}
+
+		It("adds only the plugin being run", func() {
+			AddCommands(root, []string{"hello", "world"})
+			Expect(names()).To(Equal([]string{"hello", "run"}))
+		})
+		It("does not search PATH for built-in commands or flags", func() {
+			AddCommands(root, []string{"run", "bundle"})
+			Expect(names()).To(Equal([]string{"run"}))
+			AddCommands(root, []string{"--verbose", "hello"})
+			Expect(names()).To(Equal([]string{"run"}))
+		})
+		It("adds every plugin for help", func() {
+			AddCommands(root, []string{"--help"})
+			Expect(names()).To(Equal([]string{"bye", "hello", "run"}))
+		})
+	})
+
+	Describe("commands", func() {
+		var root *cobra.Command
+
+		BeforeEach(func() {
+			root = &cobra.Command{Use: "operator-sdk"}
+			root.AddCommand(&cobra.Command{Use: "run"}, &cobra.Command{Use: "status", Aliases: []string{"st"}})
+		})
+
+		It("does not override built-in commands", func() {
+			Expect(isBuiltin(root, "run")).To(BeTrue())
+			Expect(isBuiltin(root, "st")).To(BeTrue())
+			Expect(isBuiltin(root, "help")).To(BeTrue())
+			Expect(isBuiltin(root, "hello")).To(BeFalse())
+			root.AddCommand(newPluginCmd(Plugin{Name: "hello", Path: "/bin/operator-sdk-hello"}))
+			Expect(isBuiltin(root, "hello")).To(BeFalse())
+		})
+		It("describes plugins with their manifest", func() {
+			cmd := newPluginCmd(Plugin{Name: "hello", Manifest: Manifest{Short: "Say hello"}})
+			Expect(cmd.Short).To(Equal("Say hello"))
+			Expect(cmd.DisableFlagParsing).To(BeTrue())
+			cmd = newPluginCmd(Plugin{Name: "hello"})
+			Expect(cmd.Short).To(Equal("Run the operator-sdk-hello plugin"))
+		})
+		It("returns the exit code of plugins", func() {
+			out := filepath.Join(dirs[1], "out")
+			hello := writeFile(dirs[0], "operator-sdk-hello", "#!/bin/sh\necho \"$@\" > "+out+"\nexit 3\n", 0755)
+			Expect(run(hello, []string{"world", "--help"})).To(Equal(3))
+			b, err := ioutil.ReadFile(out)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal("world --help\n"))
+			Expect(run(filepath.Join(dirs[0], "missing"), nil)).To(Equal(1))
+		})
+		It("lists plugins", func() {
+			buf := &bytes.Buffer{}
+			Expect(writeList(buf, root, []Plugin{
+				{Name: "hello", Path: "/bin/operator-sdk-hello", Manifest: Manifest{Short: "Say hello"}},
+				{Name: "run", Path: "/bin/operator-sdk-run"},
+			})).To(Succeed())
+			Expect(buf.String()).To(Equal("NAME   PATH                     DESCRIPTION\n" +
+				"hello  /bin/operator-sdk-hello  Say hello\n" +
+				"run    /bin/operator-sdk-run    \n"))
+		})
+	})
+})
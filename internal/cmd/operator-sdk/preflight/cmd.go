@@ -0,0 +1,78 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/preflight"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+func NewCmd() *cobra.Command {
+	var timeout time.Duration
+	cfg := &operator.Configuration{}
+	c := preflight.NewChecker(cfg)
+	cmd := &cobra.Command{
+		Use:   "preflight <bundle-image|bundle-dir>",
+		Short: "Check that a cluster meets an Operator bundle's requirements before installing it",
+		Long: `'preflight' checks a cluster for everything an Operator bundle needs before it is installed with
+'run bundle': OLM presence, the Kubernetes version against the CSV's minKubeVersion, APIs the CSV requires,
+support for the install mode the Operator will be installed with, the current user's permissions,
+a default StorageClass, and whether the resources requested by the CSV's deployments fit the
+namespace's LimitRanges and ResourceQuotas and the cluster's free capacity. This command exits with an exit code of 1 if any check fails.`,
+		Args: cobra.ExactArgs(1),
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return cfg.Load()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			_, bundle, err := registryutil.LoadBundle(ctx, nil, args[0])
+			if err != nil {
+				log.Fatalf("Failed to load bundle: %v", err)
+			}
+			if bundle.CSV == nil {
+				log.Fatalf("No ClusterServiceVersion found in bundle %s", args[0])
+			}
+
+			report, err := c.Run(ctx, bundle.CSV)
+			if err != nil {
+				log.Fatalf("Failed to run preflight checks: %v", err)
+			}
+			fmt.Print(report)
+			if report.HasFailures() {
+				log.Fatal("One or more preflight checks failed")
+			}
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := cfg.Close(); err != nil {
+				log.Warn(err)
+			}
+		},
+	}
+	cfg.BindFlags(cmd.PersistentFlags())
+	cmd.Flags().Var(&c.InstallMode, "install-mode", "install mode the Operator will be installed with")
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "Time to wait for the command to complete before failing")
+
+	return cmd
+}
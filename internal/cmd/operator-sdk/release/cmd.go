@@ -0,0 +1,306 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/blang/semver"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/rest"
+
+	"github.com/operator-framework/operator-sdk/internal/imagebuild"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/registry/catalog"
+	"github.com/operator-framework/operator-sdk/internal/release"
+)
+
+const (
+	longHelp = `'release' releases a new version of an operator project in one step, replacing the manual sequence of
+bumping the project's version, regenerating and validating its bundle, and publishing it:
+
+  1. VERSION in the Makefile is set to '--version', or bumped by '--bump' (a patch version by default).
+  2. The bundle is regenerated with 'make bundle'. Its ClusterServiceVersion replaces the ClusterServiceVersion
+     of the bundle it regenerated, read before regeneration.
+  3. The bundle is validated with 'operator-sdk bundle validate'.
+  4. With '--catalog-dir', the bundle is added to each of its channels in the package of the file-based
+     catalog (FBC) in that directory, upgrading from the previous bundle if the channel contains it.
+     Channels that do not exist are created. The bundle's olm.bundle blob must be added separately,
+     ex. with 'opm render', since it is rendered from the bundle image.
+  5. With '--git-tag', the changed project files are committed and the commit is tagged 'v<version>'.
+  6. With '--bundle-image', the bundle image is built, and with '--push' pushed.
+
+This command must be run from the project root, and stops at the first step that fails.
+`
+
+	examples = `  # Release the next patch version, adding it to the channels of the FBC in catalog/.
+  $ operator-sdk release --catalog-dir catalog
+
+  # Release 0.2.0, then tag it, and build and push its bundle image.
+  $ operator-sdk release --version 0.2.0 --git-tag \
+      --bundle-image quay.io/example/memcached-operator-bundle:v0.2.0 --push
+`
+)
+
+// bundleDir is the directory project Makefiles generate bundles in.
+const bundleDir = "bundle"
+
+type releaseCmd struct {
+	imagebuild.Flags
+	cfg *operator.Configuration
+
+	version     string
+	bump        string
+	channels    string
+	catalogDir  string
+	gitTag      bool
+	bundleImage string
+	push        bool
+}
+
+func NewCmd() *cobra.Command {
+	c := releaseCmd{cfg: &operator.Configuration{}}
+	cmd := &cobra.Command{
+		Use:     "release",
+		Short:   "Release a new version of an operator: bump its version, then regenerate and publish its bundle",
+		Long:    longHelp,
+		Example: examples,
+		Args:    cobra.NoArgs,
+		PreRunE: func(*cobra.Command, []string) error {
+			return c.validate()
+		},
+		Run: func(cmd *cobra.Command, _ []string) {
+			if err := c.run(cmd.Context()); err != nil {
+				log.Fatalf("Failed to release: %v", err)
+			}
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&c.version, "version", "", "Semantic version to release. Defaults to the Makefile's VERSION "+
+		"bumped by --bump")
+	fs.StringVar(&c.bump, "bump", release.Patch, fmt.Sprintf("Part of the Makefile's VERSION to bump if "+
+		"--version is not set. One of: [%s]", strings.Join(release.Parts, ", ")))
+	fs.StringVar(&c.channels, "channels", "", "A comma-separated list of channels of the released bundle. "+
+		"Defaults to the Makefile's CHANNELS, if set")
+	fs.StringVar(&c.catalogDir, "catalog-dir", "", "Directory of a file-based catalog to add the released "+
+		"bundle to the channels of")
+	fs.BoolVar(&c.gitTag, "git-tag", false, "Commit the release's changes, and tag the commit 'v<version>'")
+	fs.StringVar(&c.bundleImage, "bundle-image", "", "Tag of the bundle image to build")
+	fs.BoolVar(&c.push, "push", false, "Push the bundle image after building it")
+	c.Flags.BindFlags(fs)
+	c.cfg.BindFlags(fs)
+
+	return cmd
+}
+
+func (c *releaseCmd) validate() error {
+	if c.version != "" {
+		if _, err := semver.Parse(c.version); err != nil {
+			return fmt.Errorf("invalid --version %q: %v", c.version, err)
+		}
+	}
+	if c.push && c.bundleImage == "" {
+		return errors.New("--bundle-image must be set with --push")
+	}
+	if c.bundleImage != "" {
+		return c.Flags.Validate()
+	}
+	return nil
+}
+
+func (c releaseCmd) run(ctx context.Context) error {
+	// Bump the project's version.
+	version, err := c.nextVersion()
+	if err != nil {
+		return err
+	}
+	previous, err := release.ReadBundleCSV(bundleDir)
+	if err != nil {
+		return fmt.Errorf("error reading the current bundle: %v", err)
+	}
+	if previous != nil {
+		if err := checkNewer(version, previous.Version); err != nil {
+			return err
+		}
+	}
+	if err := release.WriteMakefileVersion("Makefile", version); err != nil {
+		return err
+	}
+	log.Infof("Releasing version %s", version)
+
+	// Regenerate and validate the bundle.
+	makeArgs := []string{"bundle", "VERSION=" + version}
+	if c.channels != "" {
+		makeArgs = append(makeArgs, "CHANNELS="+c.channels)
+	}
+	if err := runCommand(ctx, "make", makeArgs...); err != nil {
+		return err
+	}
+	csv, err := release.ReadBundleCSV(bundleDir)
+	if err != nil {
+		return fmt.Errorf("error reading the regenerated bundle: %v", err)
+	}
+	if csv == nil {
+		return fmt.Errorf("no ClusterServiceVersion was generated in %s", bundleDir)
+	}
+	var replaces string
+	if previous != nil && previous.Name != csv.Name {
+		replaces = previous.Name
+		if err := csv.SetReplaces(replaces); err != nil {
+			return fmt.Errorf("error setting replaces: %v", err)
+		}
+		log.Infof("Bundle %s replaces %s", csv.Name, replaces)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := runCommand(ctx, self, "bundle", "validate", "./"+bundleDir); err != nil {
+		return err
+	}
+
+	if c.catalogDir != "" {
+		if err := c.addToCatalog(csv.Name, replaces); err != nil {
+			return fmt.Errorf("error adding bundle to catalog: %v", err)
+		}
+	}
+
+	if c.gitTag {
+		paths := []string{"Makefile", bundleDir, registrybundle.DockerFile, "config"}
+		if c.catalogDir != "" {
+			paths = append(paths, c.catalogDir)
+		}
+		tag := "v" + version
+		if err := runCommand(ctx, "git", append([]string{"add", "--"}, paths...)...); err != nil {
+			return err
+		}
+		if err := runCommand(ctx, "git", "commit", "-m", "Release "+tag); err != nil {
+			return err
+		}
+		if err := runCommand(ctx, "git", "tag", tag); err != nil {
+			return err
+		}
+		log.Infof("Tagged release %s", tag)
+	}
+
+	if c.bundleImage != "" {
+		if err := c.buildBundle(ctx); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Released version %s", version)
+	return nil
+}
+
+// nextVersion returns the version to release.
+func (c releaseCmd) nextVersion() (string, error) {
+	if c.version != "" {
+		return c.version, nil
+	}
+	current, err := release.ReadMakefileVersion("Makefile")
+	if err != nil {
+		return "", err
+	}
+	return release.Bump(current, c.bump)
+}
+
+// checkNewer returns an error if version is not newer than the bundle version previous.
+func checkNewer(version, previous string) error {
+	if previous == "" {
+		return nil
+	}
+	pv, err := semver.Parse(previous)
+	if err != nil {
+		return fmt.Errorf("invalid version of the current bundle %q: %v", previous, err)
+	}
+	if v := semver.MustParse(version); !v.GT(pv) {
+		return fmt.Errorf("version %s must be newer than the current bundle's version %s", version, previous)
+	}
+	return nil
+}
+
+// addToCatalog adds the bundle name to its channels in the file-based catalog in c.catalogDir.
+func (c releaseCmd) addToCatalog(name, replaces string) error {
+	pkg, channels, err := release.ReadBundlePackage(bundleDir)
+	if err != nil {
+		return err
+	}
+	fbc, err := catalog.LoadFBC(c.catalogDir)
+	if err != nil {
+		return err
+	}
+	for _, channel := range channels {
+		if err := fbc.AddChannelEntry(pkg, channel, catalog.ChannelEntry{Name: name, Replaces: replaces}); err != nil {
+			return err
+		}
+		log.Infof("Added %s to channel %q of package %q", name, channel, pkg)
+	}
+	if !fbc.HasBundle(pkg, name) {
+		log.Warnf("Catalog %s has no %s blob of %s; add it with 'opm render <bundle-image>'",
+			c.catalogDir, catalog.SchemaBundle, name)
+	}
+	return nil
+}
+
+// buildBundle builds the bundle image, and pushes it if c.push is set.
+func (c releaseCmd) buildBundle(ctx context.Context) error {
+	builder, err := c.Builder(func() (*rest.Config, string, error) {
+		err := c.cfg.Load()
+		return c.cfg.RESTConfig, c.cfg.Namespace, err
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := c.cfg.Close(); err != nil {
+			log.Warn(err)
+		}
+	}()
+	log.Infof("Building bundle image %s with %s", c.bundleImage, c.Tool)
+	err = builder.Build(ctx, imagebuild.BuildOptions{
+		Dockerfile: registrybundle.DockerFile,
+		Context:    ".",
+		Tag:        c.bundleImage,
+		Platform:   c.Platform,
+	})
+	if err != nil {
+		return err
+	}
+	if c.push {
+		log.Infof("Pushing bundle image %s", c.bundleImage)
+		return imagebuild.Push(ctx, c.Tool, c.bundleImage)
+	}
+	return nil
+}
+
+// runCommand runs name with args, writing their output to stderr.
+func runCommand(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	log.Debugf("Running %s %s", name, strings.Join(args, " "))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running %s %s: %v", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/registry/catalog"
+)
+
+const (
+	longHelp = `'resolve' simulates how OLM would resolve a Subscription to a package, without a cluster. Each
+'--catalog' is a directory containing a file-based catalog, or an index image, and is searched in the order
+given, as OLM prefers CatalogSources by priority. The bundles that would be installed are written with the
+reason each was chosen: as the head of the subscription's channel, as its starting CSV, or as the provider of
+an API or package another selected bundle depends on.
+
+If the subscription cannot be resolved, the reasons are written to stderr and the command exits non-zero.
+Dependencies that cannot be evaluated offline, such as CEL constraints on cluster properties, are reported
+as warnings. OLM considers every combination of candidates, while 'resolve' installs the most preferred
+candidate of each dependency, so a failure here may still resolve in a cluster.
+`
+
+	examples = `  # Resolve the default channel of memcached-operator against a file-based catalog.
+  $ operator-sdk resolve memcached-operator --catalog ./catalog
+
+  # Resolve a starting CSV from an index image, falling back to a second catalog for dependencies.
+  $ operator-sdk resolve memcached-operator --channel alpha --starting-csv memcached-operator.v0.0.1 \
+      --catalog quay.io/example/memcached-operator-index:v0.0.1 --catalog ./catalog
+`
+)
+
+type resolveCmd struct {
+	catalogs     []string
+	sub          catalog.Subscription
+	outputFormat string
+}
+
+func NewCmd() *cobra.Command {
+	c := resolveCmd{}
+	cmd := &cobra.Command{
+		Use:     "resolve <package>",
+		Short:   "Simulate OLM's resolution of a Subscription against local catalogs",
+		Long:    longHelp,
+		Example: examples,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(*cobra.Command, []string) error {
+			if len(c.catalogs) == 0 {
+				return errors.New("at least one --catalog must be set")
+			}
+			if c.outputFormat != "text" && c.outputFormat != "json" {
+				return fmt.Errorf("invalid value for output flag: %v", c.outputFormat)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			c.sub.Package = args[0]
+			sources, err := loadSources(cmd.Context(), c.catalogs)
+			if err != nil {
+				log.Fatal(err)
+			}
+			res, err := catalog.Resolve(sources, c.sub)
+			var resErr *catalog.ResolutionError
+			if errors.As(err, &resErr) {
+				fmt.Fprintf(os.Stderr, "Package %s cannot be resolved:\n", c.sub.Package)
+				for _, reason := range resErr.Reasons {
+					fmt.Fprintf(os.Stderr, "  %s\n", reason)
+				}
+				os.Exit(1)
+			} else if err != nil {
+				log.Fatalf("Failed to resolve package %s: %v", c.sub.Package, err)
+			}
+			if err := c.write(os.Stdout, res); err != nil {
+				log.Fatalf("Failed to write resolution: %v", err)
+			}
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringArrayVar(&c.catalogs, "catalog", nil, "File-based catalog directory or index image to resolve "+
+		"against. May be set multiple times, in order of preference")
+	fs.StringVar(&c.sub.Channel, "channel", "", "Channel to subscribe to. Defaults to the package's default channel")
+	fs.StringVar(&c.sub.StartingCSV, "starting-csv", "", "Bundle in the channel to install instead of "+
+		"the channel's head")
+	fs.StringVar(&c.sub.Source, "source", "", "The --catalog to install the package from. "+
+		"Dependencies may be resolved from any catalog")
+	fs.StringVarP(&c.outputFormat, "output", "o", "text", "Output format. One of: [text, json]")
+
+	return cmd
+}
+
+// loadSources reads each catalog, from a file-based catalog if it is a directory or else from an index image.
+func loadSources(ctx context.Context, catalogs []string) ([]catalog.Source, error) {
+	sources := make([]catalog.Source, 0, len(catalogs))
+	for _, ref := range catalogs {
+		var cat *catalog.Catalog
+		var err error
+		if info, statErr := os.Stat(ref); statErr == nil && info.IsDir() {
+			cat, err = catalog.LoadFBCCatalog(ref)
+		} else {
+			cat, err = catalog.LoadIndexImage(ctx, log.NewEntry(log.StandardLogger()), ref)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalog %s: %v", ref, err)
+		}
+		sources = append(sources, catalog.Source{Name: ref, Catalog: cat})
+	}
+	return sources, nil
+}
+
+func (c resolveCmd) write(w io.Writer, res *catalog.Resolution) error {
+	if c.outputFormat == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+	for _, b := range res.Bundles {
+		if _, err := fmt.Fprintf(w, "%s (package %s, channel %s, version %s) from %s\n  %s %s\n",
+			b.Name, b.Package, b.Channel, b.Version, b.Source, b.Name, b.Reason); err != nil {
+			return err
+		}
+	}
+	for _, warning := range res.Warnings {
+		if _, err := fmt.Fprintf(w, "Warning: %s\n", warning); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/operator-framework/operator-sdk/internal/registry/catalog"
+)
+
+var _ = Describe("Running a resolve command", func() {
+	res := &catalog.Resolution{
+		Bundles: []catalog.ResolvedBundle{{
+			Name: "memcached.v0.2.0", Package: "memcached", Channel: "stable", Version: "0.2.0", Source: "./catalog",
+			Reason: "is the head of channel stable of package memcached, the subscription's channel",
+		}},
+		Warnings: []string{"olm.constraint dependency {} of bundle memcached.v0.2.0 was not simulated"},
+	}
+
+	It("requires a catalog", func() {
+		cmd := NewCmd()
+		Expect(cmd.PreRunE(cmd, []string{"memcached"})).To(MatchError("at least one --catalog must be set"))
+	})
+	It("writes the selected bundles and their reasons", func() {
+		out := &bytes.Buffer{}
+		Expect(resolveCmd{outputFormat: "text"}.write(out, res)).To(Succeed())
+		Expect(out.String()).To(Equal(`memcached.v0.2.0 (package memcached, channel stable, version 0.2.0) from ./catalog
+  memcached.v0.2.0 is the head of channel stable of package memcached, the subscription's channel
+Warning: olm.constraint dependency {} of bundle memcached.v0.2.0 was not simulated
+`))
+	})
+	It("writes the resolution as JSON", func() {
+		out := &bytes.Buffer{}
+		Expect(resolveCmd{outputFormat: "json"}.write(out, res)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring(`"reason": "is the head of channel stable`))
+		Expect(out.String()).To(ContainSubstring(`"warnings": [`))
+	})
+})
@@ -16,43 +16,287 @@ package bundle
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/operator-sdk/internal/olm/installer"
+	"github.com/operator-framework/operator-sdk/internal/olm/metrics"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator/bundle"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/interactive"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+	"github.com/operator-framework/operator-sdk/internal/util/exitcode"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
 )
 
 func NewCmd(cfg *operator.Configuration) *cobra.Command {
-	var timeout time.Duration
+	var timeout, waitForLock time.Duration
+	var interactiveMode, generateJob, followLogs, detach bool
+	var jobImage, statusConfigMap string
+	var ephemeralNamespace operator.EphemeralNamespace
+	var pusher metrics.Pusher
+	var bootstrap installer.Bootstrap
 
 	i := bundle.NewInstall(cfg)
 	cmd := &cobra.Command{
 		Use:   "bundle <bundle-image>",
 		Short: "Deploy an Operator in the bundle format with OLM",
-		Args:  cobra.ExactArgs(1),
-		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
-			return cfg.Load()
+		Long: `Deploy an Operator in the bundle format with OLM.
+
+The bundle is either a bundle image or, if prefixed with "oci://", a bundle pushed to a registry
+as an OCI artifact, ex. with ORAS. Artifact layers are written to the file named by their title
+annotation, and directory layers are unpacked. Artifacts are always unpacked on the client.
+
+With --generate-job, the manifests of a Job that runs this command in-cluster, and of the
+ServiceAccount and RBAC it runs with, are written to stdout instead, so they can be committed to a
+GitOps repository. The Job writes its progress to a ConfigMap with the Job's name, which the
+status command reports.
+
+With --ephemeral-namespace, the Operator is installed into a new, uniquely named namespace annotated
+with an expiry time. 'cleanup --expired-namespaces', ex. run periodically on a shared development
+cluster, uninstalls the Operators in expired namespaces and deletes them.
+
+With --follow-logs, once the install succeeds, the logs of the operator's Deployment pods are streamed
+to stdout, each line prefixed with its pod and container name, until the command is interrupted.
+
+With --install-olm, OLM is installed into the "olm" namespace first if the cluster does not serve its
+APIs, ex. in a throwaway CI cluster. Clusters that already run OLM are left as they are.
+
+With --node-selector and --toleration, the Operator's pods are constrained through the Subscription's
+config, ex. to run only on infra nodes. Before installing, the pods' node selectors, tolerations, and
+required node affinity from the CSV are checked against the cluster's nodes, unless --skip-resource-check
+is set.
+
+With --detach, the Operator's catalog, OperatorGroup, and Subscription are created, and the command returns
+without waiting for OLM to install the Operator, so CI can run other setup while OLM converges. The ID of
+the run, the name of the ConfigMap its status is written to, is printed to stdout; 'run wait <run-id>'
+resumes waiting for the install.
+
+` + exitcode.Help,
+		Args: exitcode.Args(cobra.ExactArgs(1)),
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if interactiveMode && !interactive.IsTerminal() {
+				return exitcode.Invalid(errors.New("--interactive requires a terminal"))
+			}
+			if generateJob && followLogs {
+				return exitcode.Invalid(errors.New("--follow-logs cannot be set with --generate-job"))
+			}
+			if detach && (generateJob || followLogs || interactiveMode) {
+				return exitcode.Invalid(errors.New("--generate-job, --follow-logs, and --interactive cannot be set with --detach"))
+			}
+			if detach && len(i.ImageOverrides) != 0 {
+				return exitcode.Invalid(errors.New("--set-image cannot be set with --detach"))
+			}
+			if generateJob {
+				if cfg.NamespaceFlag() == "" {
+					return exitcode.Invalid(errors.New("--namespace is required with --generate-job"))
+				}
+				if ephemeralNamespace.Enabled() {
+					return exitcode.Invalid(errors.New("--ephemeral-namespace cannot be set with --generate-job"))
+				}
+				if bootstrap.Enabled() {
+					return exitcode.Invalid(errors.New("--install-olm cannot be set with --generate-job"))
+				}
+				return nil
+			}
+			if err := cfg.Load(); err != nil {
+				return err
+			}
+			if bootstrap.Enabled() {
+				ctx, cancel := context.WithTimeout(cmd.Context(), installer.DefaultTimeout)
+				defer cancel()
+				installed, err := bootstrap.Run(ctx, cfg.RESTConfig)
+				if err != nil {
+					return err
+				}
+				// Reload so the client maps OLM's newly installed APIs.
+				if installed {
+					if err := cfg.Load(); err != nil {
+						return err
+					}
+				}
+			}
+			if ephemeralNamespace.Enabled() {
+				return ephemeralNamespace.Create(cmd.Context(), cfg)
+			}
+			logrus.Infof("Using namespace %q (%s)", cfg.Namespace, cfg.NamespaceSource)
+			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
-			defer cancel()
-
 			i.BundleImage = args[0]
 
+			if generateJob {
+				name := statusConfigMap
+				if name == "" {
+					name = "operator-sdk-install"
+				}
+				if err := writeInstallJob(os.Stdout, cmd, name, cfg.NamespaceFlag(), jobImage, i.BundleImage); err != nil {
+					logrus.Fatalf("Failed to generate install job: %v", err)
+				}
+				return
+			}
+			writeStatus := func(phase operator.InstallPhase, csv, message string) {
+				if statusConfigMap == "" {
+					return
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				defer cancel()
+				if err := cfg.WriteInstallStatus(ctx, operator.InstallStatus{
+					ConfigMap: statusConfigMap,
+					Phase:     phase,
+					Bundle:    i.BundleImage,
+					Package:   i.OperatorInstaller.PackageName,
+					CSV:       csv,
+					Message:   message,
+				}); err != nil {
+					logrus.Warn(err)
+				}
+			}
+			if detach {
+				runID := statusConfigMap
+				if runID == "" {
+					runID = operator.NewRunID()
+				}
+				err := operator.RunWithNamespaceLock(cmd.Context(), cfg, waitForLock, func(ctx context.Context) error {
+					ctx, cancel := context.WithTimeout(ctx, timeout)
+					defer cancel()
+					sub, err := i.Detach(ctx)
+					if err != nil {
+						return err
+					}
+					return cfg.WriteInstallStatus(ctx, operator.InstallStatus{
+						ConfigMap:    runID,
+						Phase:        operator.InstallPhaseDetached,
+						Bundle:       i.BundleImage,
+						Package:      i.OperatorInstaller.PackageName,
+						CSV:          i.OperatorInstaller.StartingCSV,
+						Subscription: sub.GetName(),
+					})
+				})
+				if err != nil {
+					exitcode.Fatal("Failed to run bundle", err)
+				}
+				logrus.Infof("Detached run %s, run 'operator-sdk run wait %s -n %s' to wait for the install",
+					runID, runID, cfg.Namespace)
+				fmt.Println(runID)
+				return
+			}
+
+			writeStatus(operator.InstallPhaseRunning, "", "")
+			ctx, span := tracing.Start(cmd.Context(), "run bundle", tracing.String("bundle", i.BundleImage),
+				tracing.String("namespace", cfg.Namespace))
+
+			var csvName string
+			install := func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				timings := registry.InstallTimings{}
+				i.OperatorInstaller.Timings = &timings
+				csv, err := i.Run(ctx)
+				pushTimings(cmd.Context(), pusher, i, timings)
+				if err == nil {
+					csvName = csv.GetName()
+					writeStatus(operator.InstallPhaseSucceeded, csvName, "")
+				}
+				return err
+			}
 			// TODO(joelanford): Add cleanup logic if this fails?
-			_, err := i.Run(ctx)
+			err := operator.RunWithNamespaceLock(ctx, cfg, waitForLock, func(ctx context.Context) error {
+				if !interactiveMode {
+					return install(ctx)
+				}
+				ui := interactive.New(cfg, fmt.Sprintf("Running bundle %s", i.BundleImage))
+				ui.BeforeRetry = func(ctx context.Context) error {
+					ctx, cancel := context.WithTimeout(ctx, timeout)
+					defer cancel()
+					return i.Cleanup(ctx)
+				}
+				return ui.Run(ctx, install)
+			})
+			span.End(err)
 			if err != nil {
-				logrus.Fatalf("Failed to run bundle: %v\n", err)
+				writeStatus(operator.InstallPhaseFailed, "", err.Error())
+				exitcode.Fatal("Failed to run bundle", err)
+			}
+			// Logs are followed after the namespace lock is released, so other installs are not blocked.
+			if followLogs {
+				followOperatorLogs(cmd.Context(), cfg, csvName)
+			}
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := cfg.Close(); err != nil {
+				logrus.Warn(err)
 			}
 		},
 	}
 	cmd.Flags().SortFlags = false
 	cfg.BindFlags(cmd.PersistentFlags())
 	i.BindFlags(cmd.Flags())
+	pusher.BindFlags(cmd.Flags())
 
 	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "install timeout")
+	cmd.Flags().DurationVar(&waitForLock, "wait-for-lock", 0, "time to wait for other installs or cleanups "+
+		"in the namespace to release its lock. If unset, fail if the lock is held")
+	cmd.Flags().BoolVar(&interactiveMode, "interactive", false, "show install phases, resource statuses, "+
+		"and operator logs in a terminal UI, and offer to clean up and retry the install if it fails")
+	ephemeralNamespace.BindFlags(cmd.Flags())
+	bootstrap.BindFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&followLogs, "follow-logs", false, "once the install succeeds, stream the logs of "+
+		"the operator's pods until interrupted")
+	cmd.Flags().StringVar(&statusConfigMap, statusConfigMapFlag, "", "name of a ConfigMap in the namespace "+
+		"to write the install's phase and result to. With --generate-job, the name of the Job and its resources")
+	cmd.Flags().BoolVar(&generateJob, generateJobFlag, false, "write the manifests of a Job that runs this "+
+		"install in-cluster to stdout instead of installing")
+	cmd.Flags().StringVar(&jobImage, jobImageFlag, defaultJobImage(), "operator-sdk image run by the install Job")
+	cmd.Flags().BoolVar(&detach, "detach", false, "create the install's resources and return without waiting "+
+		"for OLM to install the Operator, printing a run ID to wait for the install with 'run wait'. "+
+		"With --status-configmap, the ConfigMap's name is the run ID")
 	return cmd
 }
+
+// pushTimings pushes the phase timings of an install attempt by i, if configured and the attempt
+// reached the installer. Push failures are logged, since they do not affect the install.
+func pushTimings(ctx context.Context, pusher metrics.Pusher, i bundle.Install, timings registry.InstallTimings) {
+	if !pusher.Enabled() || timings.Total == 0 {
+		return
+	}
+	pusher.Labels = map[string]string{"package": i.OperatorInstaller.PackageName}
+	if err := pusher.Push(ctx, timings); err != nil {
+		logrus.Warnf("Failed to push install timings: %v", err)
+	}
+}
+
+// followOperatorLogs writes the logs of the pods of csvName's Deployments to stdout until interrupted.
+func followOperatorLogs(ctx context.Context, cfg *operator.Configuration, csvName string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	logrus.Infof("Following logs of %s, press Ctrl+C to stop", csvName)
+	mu := sync.Mutex{}
+	f := operator.NewLogFollower(cfg)
+	f.CSVName = csvName
+	f.Output = func(line string) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Println(line)
+	}
+	f.Run(ctx)
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/version"
+)
+
+const (
+	generateJobFlag     = "generate-job"
+	jobImageFlag        = "job-image"
+	statusConfigMapFlag = "status-configmap"
+
+	operatorSDKImage = "quay.io/operator-framework/operator-sdk"
+)
+
+// jobExcludedFlags are not passed to the command run by an install Job, either because they only
+// apply to the local command or because the Job sets them itself.
+var jobExcludedFlags = map[string]bool{
+	generateJobFlag:     true,
+	jobImageFlag:        true,
+	statusConfigMapFlag: true,
+	"namespace":         true,
+	"kubeconfig":        true,
+	"interactive":       true,
+}
+
+// defaultJobImage returns the operator-sdk image of this binary's release.
+func defaultJobImage() string {
+	tag := strings.SplitN(version.Version, "+", 2)[0]
+	if tag == "" || tag == "unknown" {
+		tag = "latest"
+	}
+	return operatorSDKImage + ":" + tag
+}
+
+// writeInstallJob writes the manifests of a Job that runs cmd with bundleImage and the flags set on cmd to w.
+func writeInstallJob(w io.Writer, cmd *cobra.Command, name, namespace, image, bundleImage string) error {
+	args := []string{"run", "bundle", bundleImage}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if jobExcludedFlags[f.Name] {
+			return
+		}
+		if sv, isSlice := f.Value.(pflag.SliceValue); isSlice {
+			for _, v := range sv.GetSlice() {
+				args = append(args, fmt.Sprintf("--%s=%s", f.Name, v))
+			}
+			return
+		}
+		args = append(args, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+	})
+	// Wait on the namespace lock by default, since GitOps tools may apply installs concurrently.
+	if !cmd.Flags().Changed("wait-for-lock") {
+		args = append(args, "--wait-for-lock=5m")
+	}
+
+	job := operator.InstallJob{Name: name, Namespace: namespace, Image: image, Args: args}
+	for _, obj := range job.Manifests() {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("error marshaling %T: %v", obj, err)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundlematrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/installer"
+	"github.com/operator-framework/operator-sdk/internal/olm/matrix"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/bundle"
+)
+
+const examples = `  # Install a bundle into Kubernetes 1.18 and 1.19 clusters, and report the result of each.
+  $ operator-sdk run bundle-matrix quay.io/example/memcached-operator-bundle:v0.0.1 \
+      --node-images kindest/node:v1.18.8,kindest/node:v1.19.1
+
+  # Write the report as JSON, ex. to archive it with release artifacts.
+  $ operator-sdk run bundle-matrix quay.io/example/memcached-operator-bundle:v0.0.1 \
+      --node-images kindest/node:v1.18.8,kindest/node:v1.19.1 --output json > matrix.json
+`
+
+func NewCmd(cfg *operator.Configuration) *cobra.Command {
+	var outputFormat string
+	m := matrix.Matrix{Config: cfg}
+	i := bundle.NewInstall(cfg)
+	cmd := &cobra.Command{
+		Use:   "bundle-matrix <bundle-image>",
+		Short: "Test installing an Operator bundle into clusters of several Kubernetes versions",
+		Long: `Install an Operator bundle into a new kind cluster for each node image in --node-images, in turn,
+and report which installs passed and which deprecated APIs each install requested, for release qualification.
+
+Each cluster is created with kind, which must be in PATH, then OLM and the bundle are installed into it
+as by 'run bundle', and the cluster is deleted unless --keep-clusters is set. Deprecated API requests are
+read from the API server's metrics, so are only reported by clusters of Kubernetes 1.19 and later.
+
+This command exits with an exit code of 1 if any install failed.`,
+		Example: examples,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(*cobra.Command, []string) error {
+			if len(m.NodeImages) == 0 {
+				return fmt.Errorf("--node-images must be set")
+			}
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid value for output flag: %v", outputFormat)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			i.BundleImage = args[0]
+			dir, err := ioutil.TempDir("", "operator-sdk-matrix-")
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			defer func() {
+				// Kubeconfigs of kept clusters are needed to debug them.
+				if !m.KeepClusters {
+					_ = os.RemoveAll(dir)
+				}
+			}()
+			m.Provisioner = matrix.KindProvisioner{Dir: dir}
+			m.Install = func(ctx context.Context) error {
+				_, err := i.Run(ctx)
+				return err
+			}
+
+			report := m.Run(cmd.Context())
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					logrus.Fatalf("Failed to marshal report: %v", err)
+				}
+				fmt.Printf("%s\n", b)
+			} else if err := matrix.WriteText(os.Stdout, report); err != nil {
+				logrus.Fatalf("Failed to write report: %v", err)
+			}
+			if !report.Passed() {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().SortFlags = false
+	cfg.BindFlags(cmd.PersistentFlags())
+	_ = cmd.PersistentFlags().MarkHidden("kubeconfig")
+	i.BindFlags(cmd.Flags())
+
+	cmd.Flags().StringSliceVar(&m.NodeImages, "node-images", nil, "kind node images of the clusters to install "+
+		"into, ex. kindest/node:v1.19.1. One cluster is created per image")
+	cmd.Flags().StringVar(&m.OLMVersion, "olm-version", installer.DefaultVersion, "version of OLM to install "+
+		"into each cluster")
+	cmd.Flags().StringVar(&m.ClusterPrefix, "cluster-prefix", matrix.DefaultClusterPrefix, "prefix of the names "+
+		"of the created clusters")
+	cmd.Flags().BoolVar(&m.KeepClusters, "keep-clusters", false, "do not delete clusters once their install "+
+		"completes, ex. to debug failed installs")
+	cmd.Flags().DurationVar(&m.Timeout, "timeout", 5*time.Minute, "timeout of installing OLM and the bundle "+
+		"into each cluster")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Report format. One of: [text, json]")
+	return cmd
+}
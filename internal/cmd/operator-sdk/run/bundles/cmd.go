@@ -0,0 +1,87 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundles
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/bundle"
+)
+
+const examples = `  # Install a stack of operators listed in installs.yaml, in parallel.
+  $ cat installs.yaml
+  installs:
+  - bundleImage: quay.io/example/memcached-operator-bundle:v0.0.1
+    namespace: memcached
+    installMode: SingleNamespace=memcached
+  - name: etcd
+    bundleImage: quay.io/example/etcd-operator-bundle:v0.9.4
+    namespace: storage
+    channel: alpha
+  $ operator-sdk run bundles -f installs.yaml --parallel
+
+  # Uninstall them, and delete the namespaces the install created.
+  $ operator-sdk cleanup -f installs.yaml
+`
+
+func NewCmd(cfg *operator.Configuration) *cobra.Command {
+	var file string
+	i := bundle.NewInstalls(cfg)
+	cmd := &cobra.Command{
+		Use:   "bundles",
+		Short: "Deploy several Operators in the bundle format with OLM",
+		Long: `Deploy every Operator listed in an installs file with OLM, each into its own namespace.
+
+Each entry of the file's 'installs' list has a bundleImage, and optionally a namespace, which defaults to
+--namespace and is created if it does not exist, an installMode in the format of 'run bundle --install-mode',
+a channel, an indexImage, and a name, which defaults to the namespace. Every object created for an entry is
+labeled with its name, so the entries can be uninstalled with 'cleanup -f' and the same file.
+
+Failed entries do not stop other entries from being installed. This command exits with an exit code of 1
+if any entry failed.`,
+		Example: examples,
+		Args:    cobra.NoArgs,
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return cfg.Load()
+		},
+		Run: func(cmd *cobra.Command, _ []string) {
+			var err error
+			if i.File, err = bundle.ReadInstallsFile(file, cfg.Namespace); err != nil {
+				logrus.Fatalf("Failed to read installs file: %v", err)
+			}
+			if err := i.Run(cmd.Context()); err != nil {
+				logrus.Fatalf("Failed to run bundles: %v", err)
+			}
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := cfg.Close(); err != nil {
+				logrus.Warn(err)
+			}
+		},
+	}
+	cfg.BindFlags(cmd.PersistentFlags())
+	cmd.Flags().StringVarP(&file, "file", "f", "", "installs file listing the bundles to install")
+	_ = cmd.MarkFlagRequired("file")
+	cmd.Flags().BoolVar(&i.Parallel, "parallel", false, "install entries concurrently. Entries installing into "+
+		"the same namespace must set --wait-for-lock to wait for each other")
+	cmd.Flags().DurationVar(&i.Timeout, "timeout", 2*time.Minute, "install timeout of each entry")
+	cmd.Flags().DurationVar(&i.WaitForLock, "wait-for-lock", 0, "time to wait for other installs or cleanups "+
+		"in an entry's namespace to release its lock. If unset, fail the entry if the lock is held")
+	return cmd
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundleupgrade
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/bundle"
+	"github.com/operator-framework/operator-sdk/internal/util/exitcode"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
+)
+
+const examples = `  # Install a bundle, then upgrade the Operator to the next version of its bundle.
+  $ operator-sdk run bundle quay.io/example/memcached-operator-bundle:v0.0.1 -n memcached
+  $ operator-sdk run bundle-upgrade quay.io/example/memcached-operator-bundle:v0.0.2 -n memcached
+`
+
+func NewCmd(cfg *operator.Configuration) *cobra.Command {
+	var timeout, waitForLock time.Duration
+	u := bundle.NewUpgrade(cfg)
+	cmd := &cobra.Command{
+		Use:   "bundle-upgrade <bundle-image>",
+		Short: "Upgrade an Operator deployed with 'run bundle' to a newer bundle",
+		Long: `Upgrade an Operator deployed with 'run bundle' to the Operator in a newer bundle image of the same package.
+
+The bundle is added to the catalog of the installed Operator, which is served by a new registry pod, and the
+install plan OLM creates for the upgrade is approved. The bundle's CSV must replace, or have a greater version
+than, the installed CSV, depending on how the catalog was built.
+
+Before upgrading, the OperatorCondition OLM creates for the installed CSV is read. If its Upgradeable condition
+is "False", the Operator has reported it cannot be upgraded, ex. while it migrates data, and the upgrade fails
+with the condition's reason and message. With --ignore-operator-condition, the upgrade proceeds anyway,
+although versions of OLM that enforce OperatorConditions may still block it. Operators without an
+OperatorCondition, ex. on clusters whose OLM does not create them, are always upgraded.
+
+` + exitcode.Help,
+		Example: examples,
+		Args:    exitcode.Args(cobra.ExactArgs(1)),
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			if err := cfg.Load(); err != nil {
+				return err
+			}
+			log.Infof("Using namespace %q (%s)", cfg.Namespace, cfg.NamespaceSource)
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			u.BundleImage = args[0]
+			ctx, span := tracing.Start(cmd.Context(), "run bundle-upgrade", tracing.String("bundle", u.BundleImage),
+				tracing.String("namespace", cfg.Namespace))
+			err := operator.RunWithNamespaceLock(ctx, cfg, waitForLock, func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				_, err := u.Run(ctx)
+				return err
+			})
+			span.End(err)
+			if err != nil {
+				exitcode.Fatal("Failed to upgrade bundle", err)
+			}
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := cfg.Close(); err != nil {
+				log.Warn(err)
+			}
+		},
+	}
+	cmd.Flags().SortFlags = false
+	cfg.BindFlags(cmd.PersistentFlags())
+	u.BindFlags(cmd.Flags())
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "upgrade timeout")
+	cmd.Flags().DurationVar(&waitForLock, "wait-for-lock", 0, "time to wait for installs or cleanups "+
+		"in the namespace to release its lock. If unset, fail if the lock is held")
+	return cmd
+}
@@ -17,7 +17,13 @@ package run
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/bundle"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/bundlematrix"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/bundles"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/bundleupgrade"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/local"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/packagemanifests"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/wait"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 )
 
@@ -25,17 +31,26 @@ func NewCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run an Operator in a variety of environments",
-		// TODO(joelanford): remove the second sentence when `run bundle` implementation is complete
 		Long: `This command has subcommands that will deploy your Operator with OLM.
-Currently only the package manifests format is supported via the 'packagemanifests' subcommand.`,
+Operators in the bundle format are deployed via the 'bundle' subcommand, those in the package
+manifests format via the 'packagemanifests' subcommand, sets of bundles listed in a file via the
+'bundles' subcommand, test installs of a bundle into clusters of several Kubernetes versions via the
+'bundle-matrix' subcommand, upgrades of a bundle's Operator to a newer bundle via the
+'bundle-upgrade' subcommand, and local runs of the Operator's manager, optionally with its bundle
+installed by OLM, via the 'local' subcommand.
+Installs started with --detach are waited for via the 'wait' subcommand.`,
 	}
 
 	cfg := &operator.Configuration{}
 
 	cmd.AddCommand(
-		// TODO(joelanford): enable bundle command when implementation is complete
-		// bundle.NewCmd(cfg),
+		bundle.NewCmd(cfg),
+		bundlematrix.NewCmd(cfg),
+		bundleupgrade.NewCmd(cfg),
+		bundles.NewCmd(cfg),
+		local.NewCmd(cfg),
 		packagemanifests.NewCmd(cfg),
+		wait.NewCmd(cfg),
 	)
 
 	return cmd
@@ -29,8 +29,14 @@ var _ = Describe("Running a run command", func() {
 			Expect(cmd.Long).NotTo(BeNil())
 
 			subcommands := cmd.Commands()
-			Expect(len(subcommands)).To(Equal(1))
-			Expect(subcommands[0].Use).To(Equal("packagemanifests [packagemanifests-root-dir]"))
+			Expect(len(subcommands)).To(Equal(7))
+			Expect(subcommands[0].Use).To(Equal("bundle <bundle-image>"))
+			Expect(subcommands[1].Use).To(Equal("bundle-matrix <bundle-image>"))
+			Expect(subcommands[2].Use).To(Equal("bundle-upgrade <bundle-image>"))
+			Expect(subcommands[3].Use).To(Equal("bundles"))
+			Expect(subcommands[4].Use).To(Equal("local [bundle-image]"))
+			Expect(subcommands[5].Use).To(Equal("packagemanifests [packagemanifests-root-dir]"))
+			Expect(subcommands[6].Use).To(Equal("wait <run-id>"))
 		})
 	})
 })
@@ -0,0 +1,212 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/bundle"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const longHelp = `Run the Operator's manager locally against the cluster of the current kubeconfig context.
+
+The manager is run by --command, which defaults to 'go run ./main.go' in Go projects, 'helm-operator run'
+in Helm projects, and 'ansible-operator run' in Ansible projects, with WATCH_NAMESPACE set to the namespace.
+Arguments after '--' are passed to the manager.
+
+With --with-olm, the bundle image is installed with OLM first, as by 'run bundle', so its CRDs, RBAC, and
+other resources are created and owned by OLM. The manager's Deployment is then scaled to zero through its
+CSV, which OLM reconciles, and the manager is run locally with the environment variables and arguments of
+its container instead, ex. so it can be debugged. Environment variables referencing the pod's namespace,
+labels, and annotations, ConfigMaps, and Secrets are resolved from the cluster. Webhooks served by the
+manager are not reachable by the API server while it runs locally.
+
+The Deployment stays scaled to zero once the manager exits. Run 'operator-sdk cleanup <package>' to
+uninstall the Operator.`
+
+const examples = `
+  # Run the manager of a Go project locally.
+  $ operator-sdk run local
+
+  # Install a bundle with OLM and run its manager locally instead of in-cluster.
+  $ operator-sdk run local --with-olm quay.io/example/memcached-operator-bundle:v0.0.1
+
+  # Pass arguments to the manager.
+  $ operator-sdk run local --with-olm quay.io/example/memcached-operator-bundle:v0.0.1 -- --zap-devel
+`
+
+type localCmd struct {
+	withOLM bool
+	command string
+	timeout time.Duration
+
+	cfg     *operator.Configuration
+	install bundle.Install
+	manager *operator.LocalManager
+}
+
+func NewCmd(cfg *operator.Configuration) *cobra.Command {
+	c := &localCmd{
+		cfg:     cfg,
+		install: bundle.NewInstall(cfg),
+		manager: operator.NewLocalManager(cfg),
+	}
+	cmd := &cobra.Command{
+		Use:     "local [bundle-image]",
+		Short:   "Run an Operator's manager locally, optionally with its bundle installed by OLM",
+		Long:    longHelp,
+		Example: examples,
+		Args: func(cmd *cobra.Command, args []string) error {
+			n := len(args)
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				n = dash
+			}
+			if c.withOLM && n != 1 {
+				return errors.New("--with-olm requires exactly one bundle image argument")
+			}
+			if !c.withOLM && n != 0 {
+				return errors.New("a bundle image may only be set with --with-olm")
+			}
+			return nil
+		},
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			return cfg.Load()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var managerArgs []string
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				args, managerArgs = args[:dash], args[dash:]
+			}
+			if err := c.run(cmd.Context(), args, managerArgs); err != nil {
+				log.Fatalf("Failed to run locally: %v", err)
+			}
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := cfg.Close(); err != nil {
+				log.Warn(err)
+			}
+		},
+	}
+	cmd.Flags().SortFlags = false
+	cfg.BindFlags(cmd.PersistentFlags())
+	cmd.Flags().BoolVar(&c.withOLM, "with-olm", false, "install the bundle image with OLM, then scale its "+
+		"manager's Deployment to zero and run the manager locally")
+	cmd.Flags().StringVar(&c.command, "command", "", "command that runs the manager locally. Defaults to "+
+		"the command of the project's type")
+	cmd.Flags().StringVar(&c.manager.Deployment, "deployment", "", "name of the CSV deployment to run locally, "+
+		"if the CSV has more than one")
+	cmd.Flags().StringVar(&c.manager.Container, "container", "", "name of the manager's container, if its "+
+		"deployment has more than one. Defaults to \"manager\"")
+	cmd.Flags().DurationVar(&c.timeout, "timeout", 2*time.Minute, "install timeout")
+	c.install.BindFlags(cmd.Flags())
+	return cmd
+}
+
+func (c *localCmd) run(ctx context.Context, args, managerArgs []string) error {
+	command := strings.Fields(c.command)
+	if len(command) == 0 {
+		var err error
+		if command, err = defaultCommand(); err != nil {
+			return err
+		}
+	}
+	env := []string{"WATCH_NAMESPACE=" + c.cfg.Namespace}
+	if c.cfg.KubeconfigPath != "" {
+		env = append(env, "KUBECONFIG="+c.cfg.KubeconfigPath)
+	}
+
+	if c.withOLM {
+		lc, err := c.installWithOLM(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		// The container's environment overrides the defaults, ex. WATCH_NAMESPACE set from the
+		// OperatorGroup's target namespaces.
+		env = append(env, lc.Env...)
+		command = append(command, lc.Args...)
+	}
+	command = append(command, managerArgs...)
+	return runManager(ctx, command, env)
+}
+
+// installWithOLM installs bundleImage, scales its manager's Deployment down, and returns the command
+// its manager's container runs with.
+func (c *localCmd) installWithOLM(ctx context.Context, bundleImage string) (*operator.LocalCommand, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	c.install.BundleImage = bundleImage
+	csv, err := c.install.Run(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error installing bundle: %v", err)
+	}
+	dep, err := c.manager.ScaleDown(ctx, csv)
+	if err != nil {
+		return nil, err
+	}
+	return c.manager.Command(ctx, dep)
+}
+
+// defaultCommand returns the command that runs the manager of the project in the current directory.
+func defaultCommand() ([]string, error) {
+	operatorType := projutil.OperatorTypeUnknown
+	if projutil.HasProjectFile() {
+		cfg, err := projutil.ReadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("error reading PROJECT file: %v", err)
+		}
+		operatorType = projutil.PluginKeyToOperatorType(cfg.Layout)
+	} else if _, err := os.Stat("go.mod"); err == nil {
+		operatorType = projutil.OperatorTypeGo
+	}
+	switch operatorType {
+	case projutil.OperatorTypeGo:
+		return []string{"go", "run", "./main.go"}, nil
+	case projutil.OperatorTypeHelm:
+		return []string{"helm-operator", "run"}, nil
+	case projutil.OperatorTypeAnsible:
+		return []string{"ansible-operator", "run"}, nil
+	}
+	return nil, errors.New("unknown project type, set --command")
+}
+
+// runManager runs command with env added to the environment until it exits. Interrupts are
+// delivered to the manager, which shuts down gracefully, so they are ignored until it exits.
+func runManager(ctx context.Context, command, env []string) error {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	log.Infof("Running %s", strings.Join(command, " "))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running manager: %v", err)
+	}
+	return nil
+}
@@ -16,6 +16,8 @@ package packagemanifests
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -23,10 +25,13 @@ import (
 
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator/packagemanifests"
+	"github.com/operator-framework/operator-sdk/internal/util/exitcode"
 )
 
 func NewCmd(cfg *operator.Configuration) *cobra.Command {
-	var timeout time.Duration
+	var timeout, waitForLock time.Duration
+	var ephemeralNamespace operator.EphemeralNamespace
+	var detach bool
 
 	i := packagemanifests.NewInstall(cfg)
 	cmd := &cobra.Command{
@@ -34,24 +39,62 @@ func NewCmd(cfg *operator.Configuration) *cobra.Command {
 		Short: "Deploy an Operator in the package manifests format with OLM",
 		Long: `'run packagemanifests' deploys an Operator's package manifests with OLM. The command's argument
 will default to './packagemanifests' if unset; if set, the argument must be a package manifests root directory,
-ex. '<project-root>/packagemanifests'.`,
-		Aliases:           []string{"pm"},
-		Args:              cobra.MaximumNArgs(1),
-		PersistentPreRunE: func(_ *cobra.Command, _ []string) error { return cfg.Load() },
-		Run: func(cmd *cobra.Command, args []string) {
-			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
-			defer cancel()
+ex. '<project-root>/packagemanifests'.
+
+With --ephemeral-namespace, the Operator is installed into a new, uniquely named namespace annotated
+with an expiry time, which 'cleanup --expired-namespaces' deletes once expired.
 
+With --detach, the Operator's catalog, OperatorGroup, and Subscription are created, and the command returns
+without waiting for OLM to install the Operator. The ID of the run is printed to stdout; 'run wait <run-id>'
+resumes waiting for the install.
+
+` + exitcode.Help,
+		Aliases: []string{"pm"},
+		Args:    exitcode.Args(cobra.MaximumNArgs(1)),
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if detach && len(i.ImageOverrides) != 0 {
+				return exitcode.Invalid(errors.New("--set-image cannot be set with --detach"))
+			}
+			if err := cfg.Load(); err != nil {
+				return err
+			}
+			if ephemeralNamespace.Enabled() {
+				return ephemeralNamespace.Create(cmd.Context(), cfg)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
 			if len(args) == 0 {
 				i.PackageManifestsDirectory = "packagemanifests"
 			} else {
 				i.PackageManifestsDirectory = args[0]
 			}
 
+			if detach {
+				runID, err := detachInstall(cmd.Context(), cfg, i, timeout, waitForLock)
+				if err != nil {
+					exitcode.Fatal("Failed to run packagemanifests", err)
+				}
+				log.Infof("Detached run %s, run 'operator-sdk run wait %s -n %s' to wait for the install",
+					runID, runID, cfg.Namespace)
+				fmt.Println(runID)
+				return
+			}
+
 			// TODO(joelanford): Add cleanup logic if this fails?
-			_, err := i.Run(ctx)
+			err := operator.RunWithNamespaceLock(cmd.Context(), cfg, waitForLock, func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				_, err := i.Run(ctx)
+				return err
+			})
 			if err != nil {
-				log.Fatalf("Failed to run packagemanifests: %v\n", err)
+				exitcode.Fatal("Failed to run packagemanifests", err)
+			}
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := cfg.Close(); err != nil {
+				log.Warn(err)
 			}
 		},
 	}
@@ -60,5 +103,33 @@ ex. '<project-root>/packagemanifests'.`,
 	i.BindFlags(cmd.Flags())
 
 	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "install timeout")
+	ephemeralNamespace.BindFlags(cmd.Flags())
+	cmd.Flags().DurationVar(&waitForLock, "wait-for-lock", 0, "time to wait for other installs or cleanups "+
+		"in the namespace to release its lock. If unset, fail if the lock is held")
+	cmd.Flags().BoolVar(&detach, "detach", false, "create the install's resources and return without waiting "+
+		"for OLM to install the Operator, printing a run ID to wait for the install with 'run wait'")
 	return cmd
 }
+
+// detachInstall creates the resources of i, and writes the install's status to a new ConfigMap whose name,
+// the run's ID, is returned.
+func detachInstall(ctx context.Context, cfg *operator.Configuration, i packagemanifests.Install,
+	timeout, waitForLock time.Duration) (runID string, err error) {
+	runID = operator.NewRunID()
+	err = operator.RunWithNamespaceLock(ctx, cfg, waitForLock, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		sub, err := i.Detach(ctx)
+		if err != nil {
+			return err
+		}
+		return cfg.WriteInstallStatus(ctx, operator.InstallStatus{
+			ConfigMap:    runID,
+			Phase:        operator.InstallPhaseDetached,
+			Package:      i.OperatorInstaller.PackageName,
+			CSV:          i.OperatorInstaller.StartingCSV,
+			Subscription: sub.GetName(),
+		})
+	})
+	return runID, err
+}
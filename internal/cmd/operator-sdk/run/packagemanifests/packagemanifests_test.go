@@ -34,5 +34,11 @@ var _ = Describe("Running a run packagemanifests command", func() {
 			Expect(len(aliases)).To(Equal(1))
 			Expect(aliases[0]).To(Equal("pm"))
 		})
+		It("fails if --set-image is set with --detach", func() {
+			cmd := NewCmd(&operator.Configuration{})
+			Expect(cmd.Flags().Set("detach", "true")).To(Succeed())
+			Expect(cmd.Flags().Set("set-image", "manager=quay.io/example/memcached-operator:dev")).To(Succeed())
+			Expect(cmd.PersistentPreRunE(cmd, nil)).To(MatchError("--set-image cannot be set with --detach"))
+		})
 	})
 })
@@ -0,0 +1,119 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wait
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+	"github.com/operator-framework/operator-sdk/internal/util/exitcode"
+)
+
+const examples = `  # Create the resources of an install without waiting for OLM, do other setup, then wait for the install.
+  $ RUN_ID=$(operator-sdk run packagemanifests --detach -n memcached)
+  $ kubectl apply -f config/samples/prerequisites.yaml
+  $ operator-sdk run wait "$RUN_ID" -n memcached --timeout 5m
+`
+
+func NewCmd(cfg *operator.Configuration) *cobra.Command {
+	var (
+		timeout time.Duration
+		health  olmclient.CSVHealth
+	)
+	cmd := &cobra.Command{
+		Use:   "wait <run-id>",
+		Short: "Wait for a detached install to complete",
+		Long: `Wait for an install started with --detach to complete. The run ID is the name of the ConfigMap the
+detached install wrote its status to, printed to stdout by the detached command.
+
+Once OLM creates the install plan of the install's Subscription, it is approved, and the install's CSV is
+waited for. The install's status ConfigMap is updated with the result, which 'status' reports. Waiting for
+an install that already succeeded returns immediately.
+
+` + exitcode.Help,
+		Example: examples,
+		Args:    exitcode.Args(cobra.ExactArgs(1)),
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			return cfg.Load()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+			if err := waitForRun(ctx, cfg, args[0], health); err != nil {
+				exitcode.Fatal(fmt.Sprintf("Failed to wait for run %s", args[0]), err)
+			}
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := cfg.Close(); err != nil {
+				log.Warn(err)
+			}
+		},
+	}
+	cfg.BindFlags(cmd.PersistentFlags())
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "time to wait for the install to complete")
+	cmd.Flags().Var(&health, "csv-health", "criterion for the CSV being installed, one of [phase, conditions, "+
+		"deployments-ready]")
+	return cmd
+}
+
+// waitForRun resumes the detached install whose status is in the ConfigMap runID until its CSV meets health,
+// recording its result there.
+func waitForRun(ctx context.Context, cfg *operator.Configuration, runID string, health olmclient.CSVHealth) error {
+	status, err := cfg.ReadInstallStatus(ctx, runID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case status.Phase == operator.InstallPhaseSucceeded:
+		log.Infof("Run %s already installed %s", runID, status.CSV)
+		return nil
+	case status.Subscription == "" && status.Phase == operator.InstallPhaseFailed:
+		return fmt.Errorf("install failed: %s", status.Message)
+	case status.Subscription == "":
+		return fmt.Errorf("run %s was not detached, its status is %s", runID, status.Phase)
+	}
+
+	o := registry.NewOperatorInstaller(cfg)
+	o.PackageName = status.Package
+	o.StartingCSV = status.CSV
+	o.CSVHealth = health
+	csv, err := o.ResumeInstall(ctx, status.Subscription)
+	switch {
+	case err == nil:
+		status.Phase, status.CSV, status.Message = operator.InstallPhaseSucceeded, csv.GetName(), ""
+	case ctx.Err() != nil:
+		// The install may still complete, so it can be waited for again.
+		return err
+	default:
+		status.Phase, status.Message = operator.InstallPhaseFailed, err.Error()
+	}
+	// Record the result with a fresh context, in case ctx is about to expire.
+	wctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if werr := cfg.WriteInstallStatus(wctx, *status); werr != nil {
+		log.Warn(werr)
+	}
+	if err == nil {
+		log.Infof("Run %s installed %s", runID, status.CSV)
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wait
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("wait", func() {
+	It("has a timeout flag", func() {
+		cmd := NewCmd(&operator.Configuration{})
+		Expect(cmd.Use).To(Equal("wait <run-id>"))
+		flag := cmd.Flags().Lookup("timeout")
+		Expect(flag).NotTo(BeNil())
+		Expect(flag.DefValue).To(Equal("2m0s"))
+	})
+
+	Describe("waitForRun", func() {
+		var (
+			cfg *operator.Configuration
+			ctx = context.TODO()
+		)
+		BeforeEach(func() {
+			sch := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+			Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+			cfg = &operator.Configuration{Namespace: "default", Scheme: sch, Client: fake.NewFakeClientWithScheme(sch)}
+		})
+
+		It("returns immediately if the install succeeded", func() {
+			Expect(cfg.WriteInstallStatus(ctx, operator.InstallStatus{
+				ConfigMap: "operator-sdk-run-abcde",
+				Phase:     operator.InstallPhaseSucceeded,
+				CSV:       "memcached-operator.v0.0.1",
+			})).To(Succeed())
+			Expect(waitForRun(ctx, cfg, "operator-sdk-run-abcde", olmclient.CSVHealthPhase)).To(Succeed())
+		})
+		It("fails if the install was not detached", func() {
+			Expect(cfg.WriteInstallStatus(ctx, operator.InstallStatus{
+				ConfigMap: "memcached-install",
+				Phase:     operator.InstallPhaseRunning,
+			})).To(Succeed())
+			Expect(waitForRun(ctx, cfg, "memcached-install", olmclient.CSVHealthPhase)).To(MatchError("run memcached-install was not detached, its status is Running"))
+		})
+		It("fails if the run does not exist", func() {
+			Expect(waitForRun(ctx, cfg, "operator-sdk-run-none", olmclient.CSVHealthPhase)).To(MatchError(ContainSubstring("error reading install status")))
+		})
+		It("records a failed install in the run's status", func() {
+			Expect(cfg.WriteInstallStatus(ctx, operator.InstallStatus{
+				ConfigMap:    "operator-sdk-run-abcde",
+				Phase:        operator.InstallPhaseDetached,
+				Package:      "memcached-operator",
+				CSV:          "memcached-operator.v0.0.1",
+				Subscription: "memcached-operator-v0-0-1-sub",
+			})).To(Succeed())
+			Expect(waitForRun(ctx, cfg, "operator-sdk-run-abcde", olmclient.CSVHealthPhase)).To(MatchError(ContainSubstring("error getting subscription")))
+			status, err := cfg.ReadInstallStatus(ctx, "operator-sdk-run-abcde")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Phase).To(Equal(operator.InstallPhaseFailed))
+			Expect(status.Message).To(ContainSubstring("error getting subscription"))
+		})
+	})
+})
@@ -21,30 +21,42 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
 	scorecardannotations "github.com/operator-framework/operator-sdk/internal/annotations/scorecard"
 	"github.com/operator-framework/operator-sdk/internal/flags"
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 	"github.com/operator-framework/operator-sdk/internal/scorecard"
+	"github.com/operator-framework/operator-sdk/internal/util/exitcode"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
 )
 
 type scorecardCmd struct {
 	bundle         string
+	baseline       string
 	config         string
 	kubeconfig     string
 	namespace      string
 	outputFormat   string
 	selector       string
 	serviceAccount string
+	resultSource   string
+	dnsPolicy      string
+	imageMirrors   []string
+	dnsNameservers []string
+	dnsSearches    []string
 	list           bool
+	listImages     bool
 	skipCleanup    bool
+	updateBaseline bool
 	waitTime       time.Duration
 }
 
@@ -58,9 +70,24 @@ func NewCmd() *cobra.Command {
 		// to run it, etc.
 		Long: `Has flags to configure dsl, bundle, and selector. This command takes
 one argument, either a bundle image or directory containing manifests and metadata.
-If the argument holds an image tag, it must be present remotely.`,
+If the argument holds an image tag, it must be present remotely.
+
+Set --baseline to the results of a previous run, as written by --update-baseline or '--output json',
+to only fail on regressions: results that passed in the baseline, or new results, that do not pass.
+Results failing in both runs, ex. noisy suggestion-level checks, are reported as known failures, and
+results passing now but not in the baseline as improvements. Set --update-baseline to write the results
+of this run to --baseline instead of comparing them.
+
+On clusters with restrictive egress, mirror the images listed by --list-images to a reachable registry
+and set --image-mirror to pull them from it. Tests run by images built with the scorecard test library
+can write their results to a ConfigMap, read with --result-source=configmap, if scorecard cannot read
+pod logs. Set --dns-policy, --dns-nameserver, and --dns-search to configure DNS resolution in test pods.
+
+Exit code 1 is also returned if a test does not pass, or with --baseline, if a result regressed.
+
+` + exitcode.Help,
 		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
-			return c.validate(args)
+			return exitcode.Invalid(c.validate(args))
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			c.bundle = args[0]
@@ -80,9 +107,26 @@ If the argument holds an image tag, it must be present remotely.`,
 		"Option to enable listing which tests are run")
 	scorecardCmd.Flags().BoolVarP(&c.skipCleanup, "skip-cleanup", "x", false,
 		"Disable resource cleanup after tests are run")
+	scorecardCmd.Flags().StringVar(&c.baseline, "baseline", "",
+		"Path to the results of a previous run to compare results against, failing only on regressions")
+	scorecardCmd.Flags().BoolVar(&c.updateBaseline, "update-baseline", false,
+		"Write the results to --baseline instead of comparing them")
+	scorecardCmd.Flags().BoolVar(&c.listImages, "list-images", false,
+		"List the images the selected tests are run with, to mirror them for clusters that cannot pull them")
+	scorecardCmd.Flags().StringArrayVar(&c.imageMirrors, "image-mirror", nil,
+		"Pull images in a repository from a mirror, of the form <repository>=<mirror>. "+
+			"Ex. quay.io/operator-framework=registry.example.com/operator-framework. May be set more than once")
+	scorecardCmd.Flags().StringVar(&c.resultSource, "result-source", scorecard.ResultSourceLog,
+		fmt.Sprintf("Where to read test results from. Valid values: %s", strings.Join(scorecard.ResultSources, ", ")))
+	scorecardCmd.Flags().StringVar(&c.dnsPolicy, "dns-policy", "",
+		fmt.Sprintf("DNS policy of test pods. Valid values: %s", strings.Join(dnsPolicies, ", ")))
+	scorecardCmd.Flags().StringSliceVar(&c.dnsNameservers, "dns-nameserver", nil,
+		"DNS nameserver IPs of test pods, required if --dns-policy=None")
+	scorecardCmd.Flags().StringSliceVar(&c.dnsSearches, "dns-search", nil, "DNS search domains of test pods")
 	scorecardCmd.Flags().DurationVarP(&c.waitTime, "wait-time", "w", 30*time.Second,
 		"seconds to wait for tests to complete. Example: 35s")
 
+	scorecardCmd.AddCommand(newInitCmd(), newCreateCmd())
 	return scorecardCmd
 }
 
@@ -109,6 +153,7 @@ func (c *scorecardCmd) printOutput(output v1alpha3.TestList) error {
 }
 
 func (c *scorecardCmd) run() (err error) {
+	bundle := c.bundle
 	// Extract bundle image contents if bundle is inferred to be an image.
 	if _, err = os.Stat(c.bundle); err != nil && errors.Is(err, os.ErrNotExist) {
 		if c.bundle, err = extractBundleImage(c.bundle); err != nil {
@@ -148,6 +193,13 @@ func (c *scorecardCmd) run() (err error) {
 		return fmt.Errorf("could not parse selector %w", err)
 	}
 
+	if c.listImages {
+		for _, image := range o.Images() {
+			fmt.Println(image)
+		}
+		return nil
+	}
+
 	var scorecardTests v1alpha3.TestList
 	if c.list {
 		scorecardTests = o.List()
@@ -157,6 +209,14 @@ func (c *scorecardCmd) run() (err error) {
 			Namespace:      scorecard.GetKubeNamespace(c.kubeconfig, c.namespace),
 			BundlePath:     c.bundle,
 			BundleMetadata: metadata,
+			ResultSource:   c.resultSource,
+			DNSPolicy:      corev1.DNSPolicy(c.dnsPolicy),
+		}
+		if runner.ImageMirrors, err = scorecard.ParseImageMirrors(c.imageMirrors); err != nil {
+			return err
+		}
+		if len(c.dnsNameservers) != 0 || len(c.dnsSearches) != 0 {
+			runner.DNSConfig = &corev1.PodDNSConfig{Nameservers: c.dnsNameservers, Searches: c.dnsSearches}
 		}
 
 		// Only get the client if running tests.
@@ -169,7 +229,10 @@ func (c *scorecardCmd) run() (err error) {
 		ctx, cancel := context.WithTimeout(context.Background(), c.waitTime)
 		defer cancel()
 
+		ctx, span := tracing.Start(ctx, "scorecard", tracing.String("bundle", bundle),
+			tracing.String("namespace", runner.Namespace))
 		scorecardTests, err = o.Run(ctx)
+		span.End(err)
 		if err != nil {
 			return fmt.Errorf("error running tests %w", err)
 		}
@@ -179,8 +242,29 @@ func (c *scorecardCmd) run() (err error) {
 		log.Fatal(err)
 	}
 
+	if c.baseline != "" {
+		if c.updateBaseline {
+			return scorecard.WriteBaseline(c.baseline, scorecardTests)
+		}
+		baseline, err := scorecard.ReadBaseline(c.baseline)
+		if err != nil {
+			return err
+		}
+		comparison := scorecard.CompareBaseline(baseline, scorecardTests)
+		// Keep stdout valid JSON.
+		out := os.Stdout
+		if c.outputFormat == "json" {
+			out = os.Stderr
+		}
+		fmt.Fprint(out, comparison.MarshalText())
+		if comparison.HasRegressions() {
+			log.Exit(exitcode.Failure)
+		}
+		return nil
+	}
+
 	if hasFailingTest(scorecardTests) {
-		os.Exit(1)
+		log.Exit(exitcode.Failure)
 	}
 	return nil
 }
@@ -200,9 +284,48 @@ func (c *scorecardCmd) validate(args []string) error {
 	if len(args) != 1 {
 		return fmt.Errorf("a bundle image or directory argument is required")
 	}
+	if c.updateBaseline && c.baseline == "" {
+		return errors.New("--baseline must be set if --update-baseline is set")
+	}
+	if c.list && c.baseline != "" {
+		return errors.New("--baseline cannot be set if --list is set")
+	}
+	if c.listImages && (c.list || c.baseline != "") {
+		return errors.New("--list and --baseline cannot be set if --list-images is set")
+	}
+	if c.resultSource != "" && !containsString(scorecard.ResultSources, c.resultSource) {
+		return fmt.Errorf("invalid --result-source %q, valid values: %s", c.resultSource,
+			strings.Join(scorecard.ResultSources, ", "))
+	}
+	if _, err := scorecard.ParseImageMirrors(c.imageMirrors); err != nil {
+		return err
+	}
+	if c.dnsPolicy != "" && !containsString(dnsPolicies, c.dnsPolicy) {
+		return fmt.Errorf("invalid --dns-policy %q, valid values: %s", c.dnsPolicy, strings.Join(dnsPolicies, ", "))
+	}
+	if c.dnsPolicy == string(corev1.DNSNone) && len(c.dnsNameservers) == 0 {
+		return fmt.Errorf("--dns-nameserver must be set if --dns-policy=%s", corev1.DNSNone)
+	}
 	return nil
 }
 
+// dnsPolicies are the valid DNS policies of test pods.
+var dnsPolicies = []string{
+	string(corev1.DNSClusterFirst),
+	string(corev1.DNSClusterFirstWithHostNet),
+	string(corev1.DNSDefault),
+	string(corev1.DNSNone),
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
 // extractBundleImage returns bundleImage's path on disk post-extraction.
 func extractBundleImage(bundleImage string) (string, error) {
 	// Discard bundle extraction logs unless user sets verbose mode.
@@ -61,6 +61,38 @@ var _ = Describe("Running the scorecard command", func() {
 			Expect(flag.Shorthand).To(Equal("x"))
 			Expect(flag.DefValue).To(Equal("false"))
 
+			flag = cmd.Flags().Lookup("baseline")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(""))
+
+			flag = cmd.Flags().Lookup("update-baseline")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("false"))
+
+			flag = cmd.Flags().Lookup("list-images")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("false"))
+
+			flag = cmd.Flags().Lookup("image-mirror")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.Value.Type()).To(Equal("stringArray"))
+
+			flag = cmd.Flags().Lookup("result-source")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("log"))
+
+			flag = cmd.Flags().Lookup("dns-policy")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(""))
+
+			flag = cmd.Flags().Lookup("dns-nameserver")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.Value.Type()).To(Equal("stringSlice"))
+
+			flag = cmd.Flags().Lookup("dns-search")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.Value.Type()).To(Equal("stringSlice"))
+
 			flag = cmd.Flags().Lookup("wait-time")
 			Expect(flag).NotTo(BeNil())
 			Expect(flag.Shorthand).To(Equal("w"))
@@ -68,6 +100,42 @@ var _ = Describe("Running the scorecard command", func() {
 		})
 	})
 
+	Describe("newInitCmd", func() {
+		It("is a subcommand with flags to generate or validate a config", func() {
+			cmd, _, err := NewCmd().Find([]string{"init"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmd.Name()).To(Equal("init"))
+
+			flag := cmd.Flags().Lookup("config")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(""))
+
+			flag = cmd.Flags().Lookup("validate")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("false"))
+
+			flag = cmd.Flags().Lookup("cluster-tests")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("false"))
+		})
+	})
+
+	Describe("newCreateCmd", func() {
+		It("has a test subcommand with flags to scaffold a custom test", func() {
+			cmd, _, err := NewCmd().Find([]string{"create", "test"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmd.Name()).To(Equal("test"))
+
+			flag := cmd.Flags().Lookup("dir")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("scorecard-tests"))
+
+			for _, name := range []string{"language", "module", "image", "force"} {
+				Expect(cmd.Flags().Lookup(name)).NotTo(BeNil())
+			}
+		})
+	})
+
 	Describe("validate", func() {
 		var cmd scorecardCmd
 		BeforeEach(func() {
@@ -86,5 +154,44 @@ var _ = Describe("Running the scorecard command", func() {
 			err := cmd.validate([]string{input})
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("fails if --update-baseline is set without --baseline", func() {
+			cmd.updateBaseline = true
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError("--baseline must be set if --update-baseline is set"))
+		})
+
+		It("fails if --baseline is set with --list", func() {
+			cmd.baseline, cmd.list = "results.json", true
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError("--baseline cannot be set if --list is set"))
+		})
+
+		It("fails if --list-images is set with --list", func() {
+			cmd.listImages, cmd.list = true, true
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError("--list and --baseline cannot be set if --list-images is set"))
+		})
+
+		It("fails if --result-source is invalid", func() {
+			cmd.resultSource = "termination-message"
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError(`invalid --result-source "termination-message", valid values: log, configmap`))
+			cmd.resultSource = "configmap"
+			Expect(cmd.validate([]string{"cherry"})).To(Succeed())
+		})
+
+		It("fails if an --image-mirror is malformed", func() {
+			cmd.imageMirrors = []string{"quay.io=mirror.example.com/quay", "busybox"}
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError(`invalid image mirror "busybox", must be of the form <repository>=<mirror>`))
+		})
+
+		It("fails if --dns-policy is invalid", func() {
+			cmd.dnsPolicy = "ClusterLast"
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError(`invalid --dns-policy "ClusterLast", valid values: ClusterFirst, ClusterFirstWithHostNet, Default, None`))
+		})
+
+		It("fails if --dns-policy=None is set without --dns-nameserver", func() {
+			cmd.dnsPolicy = "None"
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError("--dns-nameserver must be set if --dns-policy=None"))
+			cmd.dnsNameservers = []string{"10.0.0.10"}
+			Expect(cmd.validate([]string{"cherry"})).To(Succeed())
+		})
 	})
 })
@@ -0,0 +1,108 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/scorecard"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const defaultCustomTestDir = "scorecard-tests"
+
+func newCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Scaffold custom scorecard tests",
+	}
+	cmd.AddCommand(newCreateTestCmd())
+	return cmd
+}
+
+func newCreateTestCmd() *cobra.Command {
+	s := scorecard.TestScaffold{}
+	cmd := &cobra.Command{
+		Use:   "test <name>",
+		Short: "Scaffold a custom scorecard test written in Go, Python, or shell",
+		Long: `Scaffold a custom scorecard test named <name> in the test image in --dir, which is scaffolded
+first if it does not exist, in the language set by --language.
+
+Go tests are in a Go module with a Dockerfile building the image, and a main.go running the test named by
+the image's first argument. They are written with the github.com/operator-framework/operator-sdk/pkg/scorecard
+library, which reads the bundle under test and records the test's errors, suggestions, log, and artifacts in
+the result scorecard expects.
+
+Python and shell tests are scripts in the image's tests directory, and the image is built from the
+scorecard-test-python or scorecard-test-shell base image. A script passes by exiting with status 0 and fails
+by exiting with status 1. Its output is its log, and it may write errors and suggestions as JSON to the file in
+$SCORECARD_RESULT. The bundle under test is in $SCORECARD_BUNDLE_ROOT, and the test's namespace is
+$SCORECARD_NAMESPACE. The Python image includes the kubernetes and PyYAML packages, and the shell image
+includes kubectl and jq.
+
+Each test is in its own file. The scaffolded test checks that the CSV has example CRs. The entry to add to
+the stages of the scorecard config is written to stdout.`,
+		Example: `  # Scaffold a test, then build and push its image.
+  $ operator-sdk scorecard create test has-examples --image quay.io/example/custom-scorecard-tests:v0.0.1
+  $ cd scorecard-tests && go mod tidy
+  $ docker build -t quay.io/example/custom-scorecard-tests:v0.0.1 . && docker push quay.io/example/custom-scorecard-tests:v0.0.1
+
+  # Scaffold a test written in Python.
+  $ operator-sdk scorecard create test has-examples --language python --dir scorecard-tests-python
+
+  # Add the test's entry to bundle/tests/scorecard/config.yaml, then run it.
+  $ operator-sdk scorecard bundle --selector=suite=custom
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s.Name = args[0]
+			if s.Module == "" {
+				s.Module = defaultTestModule(s.Dir)
+			}
+			entry, err := scorecard.ScaffoldTest(s)
+			if err != nil {
+				return err
+			}
+			log.Infof("Scaffolded test %s in %s", s.Name, s.Dir)
+			fmt.Printf("Add the test to a stage of the scorecard config:\n%s", entry)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&s.Dir, "dir", defaultCustomTestDir, "directory of the test image")
+	cmd.Flags().StringVar(&s.Language, "language", scorecard.LanguageGo, fmt.Sprintf("language of the test, "+
+		"one of %v", scorecard.Languages))
+	cmd.Flags().StringVar(&s.Module, "module", "", "path of the Go module of a Go test image if it is scaffolded. "+
+		"Defaults to --dir under the project's repo, if any")
+	cmd.Flags().StringVar(&s.Image, "image", scorecard.CustomTestBinary+":latest", "test image, "+
+		"written in the test's config entry")
+	cmd.Flags().BoolVar(&s.Force, "force", false, "overwrite the test's file if it exists")
+	return cmd
+}
+
+// defaultTestModule returns the path of the project's repo joined with dir, or dir's base name
+// outside of projects.
+func defaultTestModule(dir string) string {
+	if projutil.HasProjectFile() {
+		if cfg, err := projutil.ReadConfig(); err == nil && cfg.Repo != "" {
+			return path.Join(cfg.Repo, filepath.ToSlash(filepath.Clean(dir)))
+		}
+	}
+	return filepath.Base(dir)
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/scorecard"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const defaultBundleDir = "bundle"
+
+type initCmd struct {
+	bundleDir string
+	config    string
+	validate  bool
+	force     bool
+	opts      scorecard.ConfigOptions
+}
+
+func newInitCmd() *cobra.Command {
+	c := initCmd{}
+	cmd := &cobra.Command{
+		Use:   "init [<bundle-dir>]",
+		Short: "Generate or validate a scorecard config for a bundle",
+		Long: `Generate a scorecard config for the bundle in <bundle-dir>, which defaults to 'bundle'.
+The config includes the built-in tests that apply to the bundle, ex. tests of example CRs
+are only included if the CSV has an alm-examples annotation, and explains each test and
+how to select tests in comments. With --cluster-tests, a second stage runs the tests that
+create the example CRs and restart pods in the test namespace.
+
+The config is written to --config, which defaults to the bundle's tests/scorecard/config.yaml.
+An existing config is not overwritten unless --force is set.
+
+With --validate, the config at --config is validated instead, and each problem found is
+reported with the path of the field it is in.`,
+		Example: `  # Generate a config with the static and cluster tests.
+  $ operator-sdk scorecard init bundle --cluster-tests --service-account=scorecard
+
+  # Validate a config.
+  $ operator-sdk scorecard init --validate --config bundle/tests/scorecard/config.yaml
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c.bundleDir = defaultBundleDir
+			if len(args) != 0 {
+				c.bundleDir = args[0]
+			}
+			if c.config == "" {
+				c.config = filepath.Join(c.bundleDir, filepath.FromSlash(scorecard.DefaultConfigDir), scorecard.ConfigFileName)
+			}
+			if c.validate {
+				return c.runValidate()
+			}
+			return c.run()
+		},
+	}
+	cmd.Flags().StringVarP(&c.config, "config", "c", "", "path of the scorecard config file to generate or validate")
+	cmd.Flags().BoolVar(&c.validate, "validate", false, "validate the config at --config instead of generating one")
+	cmd.Flags().BoolVar(&c.force, "force", false, "overwrite an existing config")
+	cmd.Flags().StringVar(&c.opts.TestImage, "image", scorecard.DefaultTestImage, "image of the built-in tests")
+	cmd.Flags().StringVarP(&c.opts.ServiceAccount, "service-account", "s", "default",
+		"service account the tests are run with, which is documented in the config")
+	cmd.Flags().BoolVar(&c.opts.ClusterTests, "cluster-tests", false, "add a stage of tests that create the "+
+		"bundle's example CRs and restart pods in the test namespace")
+	return cmd
+}
+
+func (c initCmd) run() error {
+	if _, err := os.Stat(c.config); err == nil && !c.force {
+		return fmt.Errorf("config %s exists, set --force to overwrite it", c.config)
+	}
+	bundle, err := apimanifests.GetBundleFromDir(c.bundleDir)
+	if err != nil {
+		return fmt.Errorf("error reading bundle %s: %v", c.bundleDir, err)
+	}
+	b, err := scorecard.GenerateConfig(bundle, c.opts)
+	if err != nil {
+		return fmt.Errorf("error generating config: %v", err)
+	}
+	if err := scorecard.ValidateConfig(b); err != nil {
+		return fmt.Errorf("generated an invalid config: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.config), projutil.DirMode); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(c.config, b, projutil.FileMode); err != nil {
+		return fmt.Errorf("error writing config: %v", err)
+	}
+	log.Infof("Generated scorecard config %s", c.config)
+	return nil
+}
+
+func (c initCmd) runValidate() error {
+	b, err := ioutil.ReadFile(c.config)
+	if err != nil {
+		return fmt.Errorf("error reading config: %v", err)
+	}
+	if err := scorecard.ValidateConfig(b); err != nil {
+		return fmt.Errorf("%s: %v", c.config, err)
+	}
+	log.Infof("Config %s is valid", c.config)
+	return nil
+}
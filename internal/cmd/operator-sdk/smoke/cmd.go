@@ -0,0 +1,118 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smoke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/bundle"
+	"github.com/operator-framework/operator-sdk/internal/olm/smoke"
+)
+
+const examples = `  # Install a bundle, create and delete the CRs in its CSV's alm-examples, then uninstall it.
+  $ operator-sdk smoke quay.io/example/memcached-operator-bundle:v0.0.1 --namespace smoke-test
+
+  # Wait for each CR's "Reconciled" condition instead, and write the report as JSON.
+  $ operator-sdk smoke quay.io/example/memcached-operator-bundle:v0.0.1 --namespace smoke-test \
+      --ready-condition Reconciled --output json > smoke.json
+`
+
+func NewCmd() *cobra.Command {
+	var (
+		timeout      time.Duration
+		outputFormat string
+	)
+	cfg := &operator.Configuration{}
+	i := bundle.NewInstall(cfg)
+	s := smoke.Smoke{}
+	cmd := &cobra.Command{
+		Use:   "smoke <bundle-image>",
+		Short: "Test installing an Operator bundle and reconciling its example CRs",
+		Long: `Install an Operator bundle with OLM as by 'run bundle', create each CR in its CSV's alm-examples
+annotation, wait for each CR to become ready, delete the CRs and wait for them to be deleted, then uninstall
+the Operator, as an acceptance test of the bundle, ex. in release pipelines.
+
+A CR is ready once its --ready-condition condition has status "True". If --ready-condition is not set, a CR
+is ready once it has a status, its status.observedGeneration, if set, is its generation, and its Ready,
+Available, or Successful conditions, if any, have status "True".
+
+The Operator is uninstalled even if a step fails. This command exits with an exit code of 1 if any step failed.`,
+		Example: examples,
+		Args:    cobra.ExactArgs(1),
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid value for output flag: %v", outputFormat)
+			}
+			return cfg.Load()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			i.BundleImage = args[0]
+			s.Client = cfg.Client
+			s.Namespace = cfg.Namespace
+			s.Install = func(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				return i.Run(ctx)
+			}
+			s.Uninstall = func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				return i.Cleanup(ctx)
+			}
+
+			report := s.Run(cmd.Context())
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					log.Fatalf("Failed to marshal report: %v", err)
+				}
+				fmt.Printf("%s\n", b)
+			} else if err := smoke.WriteText(os.Stdout, report); err != nil {
+				log.Fatalf("Failed to write report: %v", err)
+			}
+			if !report.Passed() {
+				os.Exit(1)
+			}
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := cfg.Close(); err != nil {
+				log.Warn(err)
+			}
+		},
+	}
+	cmd.Flags().SortFlags = false
+	cfg.BindFlags(cmd.PersistentFlags())
+	i.BindFlags(cmd.Flags())
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "timeout of installing, and of uninstalling, "+
+		"the bundle")
+	cmd.Flags().StringVar(&s.ReadyCondition, "ready-condition", "", "type of the condition each CR must have "+
+		"with status \"True\" to be ready. If unset, well-known conditions are checked")
+	cmd.Flags().DurationVar(&s.CRTimeout, "cr-timeout", 2*time.Minute, "time to wait for each CR to become "+
+		"ready, and to be deleted")
+	cmd.Flags().BoolVar(&s.KeepCRs, "keep-crs", false, "do not delete CRs before uninstalling, in which case "+
+		"they are deleted with their CRDs")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Report format. One of: [text, json]")
+	return cmd
+}
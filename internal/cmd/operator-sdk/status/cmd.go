@@ -0,0 +1,164 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+func NewCmd() *cobra.Command {
+	var (
+		timeout      time.Duration
+		outputFormat string
+	)
+	cfg := &operator.Configuration{}
+	cmd := &cobra.Command{
+		Use:   "status <operatorPackageName>",
+		Short: "Summarize the status of an Operator installed with OLM",
+		Long: `Summarize the status of an Operator installed with OLM in a namespace: its Subscription's state,
+its installed and current CSVs, its install plans, the health of its catalog, the readiness of its
+Deployments, the components OLM references in its Operator resource, and the status of any in-cluster
+installs run by Jobs generated by 'run bundle --generate-job'. This command exits with an exit code of 1 if the Operator is not ready.`,
+		Args: cobra.ExactArgs(1),
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			if outputFormat != "text" && outputFormat != "json" {
+				return fmt.Errorf("invalid value for output flag: %v", outputFormat)
+			}
+			return cfg.Load()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			s := operator.NewStatus(cfg)
+			s.Package = args[0]
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+			status, err := s.Run(ctx)
+			if err != nil {
+				log.Fatalf("Get operator status: %v", err)
+			}
+			if outputFormat == "json" {
+				b, err := json.MarshalIndent(status, "", "    ")
+				if err != nil {
+					log.Fatalf("Error marshaling JSON output: %v", err)
+				}
+				fmt.Printf("%s\n", b)
+			} else {
+				printStatus(os.Stdout, status)
+			}
+			if !status.Ready() {
+				os.Exit(1)
+			}
+		},
+		PostRun: func(*cobra.Command, []string) {
+			if err := cfg.Close(); err != nil {
+				log.Warn(err)
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "Time to wait for the command to complete before failing")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format. One of: [text, json]")
+	cfg.BindFlags(cmd.PersistentFlags())
+
+	return cmd
+}
+
+// printStatus writes s as tables to w.
+func printStatus(w io.Writer, s *operator.OperatorStatus) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer func() {
+		_ = tw.Flush()
+	}()
+
+	fmt.Fprintf(tw, "PACKAGE\tNAMESPACE\tREADY\n%s\t%s\t%t\n\n", s.Package, s.Namespace, s.Ready())
+
+	if len(s.Installs) != 0 {
+		fmt.Fprintf(tw, "INSTALL\tPHASE\tBUNDLE\tCSV\tUPDATED\tMESSAGE\n")
+		for _, in := range s.Installs {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", in.ConfigMap, orNone(string(in.Phase)), orNone(in.Bundle),
+				orNone(in.CSV), orNone(in.LastUpdateTime), in.Message)
+		}
+		fmt.Fprintln(tw)
+	}
+	// The operator is not subscribed to if an in-cluster install failed early.
+	if s.Subscription.Name == "" {
+		return
+	}
+
+	sub := s.Subscription
+	fmt.Fprintf(tw, "SUBSCRIPTION\tCHANNEL\tSTATE\tINSTALLED CSV\tCURRENT CSV\n")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n\n", sub.Name, orNone(sub.Channel), orNone(sub.State),
+		orNone(sub.InstalledCSV), orNone(sub.CurrentCSV))
+
+	cs := s.CatalogSource
+	fmt.Fprintf(tw, "CATALOG SOURCE\tNAMESPACE\tCONNECTION\tHEALTHY\n")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%t\n\n", cs.Name, cs.Namespace, orNone(cs.ConnectionState), cs.Healthy)
+
+	if len(s.InstallPlans) != 0 {
+		fmt.Fprintf(tw, "INSTALL PLAN\tPHASE\tAPPROVED\tPENDING\tCSVS\n")
+		for _, ip := range s.InstallPlans {
+			fmt.Fprintf(tw, "%s\t%s\t%t\t%t\t%s\n", ip.Name, orNone(ip.Phase), ip.Approved, ip.Pending,
+				orNone(strings.Join(ip.CSVNames, ",")))
+		}
+		fmt.Fprintln(tw)
+	}
+
+	if s.CSV != nil {
+		fmt.Fprintf(tw, "CSV\tVERSION\tPHASE\tMESSAGE\n")
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n\n", s.CSV.Name, orNone(s.CSV.Version), orNone(s.CSV.Phase), s.CSV.Message)
+	}
+
+	if len(s.Deployments) != 0 {
+		fmt.Fprintf(tw, "DEPLOYMENT\tREADY\n")
+		for _, dep := range s.Deployments {
+			ready := "not found"
+			if dep.Found {
+				ready = fmt.Sprintf("%d/%d", dep.ReadyReplicas, dep.Replicas)
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", dep.Name, ready)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	if len(s.Components) != 0 {
+		fmt.Fprintf(tw, "COMPONENT\tNAMESPACE\tCONDITIONS\n")
+		for _, c := range s.Components {
+			conds := make([]string, len(c.Conditions))
+			for i, cond := range c.Conditions {
+				conds[i] = cond.Type + "=" + cond.Status
+			}
+			fmt.Fprintf(tw, "%s/%s\t%s\t%s\n", strings.ToLower(c.Kind), c.Name, orNone(c.Namespace),
+				orNone(strings.Join(conds, ",")))
+		}
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
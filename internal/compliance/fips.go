@@ -0,0 +1,157 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compliance checks a project's source for usages that violate a compliance standard.
+package compliance
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Finding is a single non-compliant usage in a source file.
+type Finding struct {
+	// Position is the file, line, and column of the usage, ex. "controllers/foo.go:12:3".
+	Position string `json:"position"`
+	// Rule is a short name for the violated rule, ex. "weak-tls-version".
+	Rule string `json:"rule"`
+	// Message describes the violation and how to fix it.
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: [%s] %s", f.Position, f.Rule, f.Message)
+}
+
+// nonFIPSPackages are imports whose algorithms are not FIPS-approved or not implemented by BoringCrypto.
+var nonFIPSPackages = map[string]string{
+	"crypto/md5": "MD5 is not a FIPS-approved hash, use crypto/sha256",
+	"crypto/des": "DES and 3DES are not FIPS-approved ciphers, use crypto/aes",
+	"crypto/rc4": "RC4 is not a FIPS-approved cipher, use crypto/aes",
+}
+
+// xCryptoPrefix is the prefix of golang.org/x/crypto packages, none of which use BoringCrypto.
+const xCryptoPrefix = "golang.org/x/crypto/"
+
+// weakTLSVersions are crypto/tls version constants below TLS 1.2.
+var weakTLSVersions = map[string]bool{
+	"VersionSSL30": true,
+	"VersionTLS10": true,
+	"VersionTLS11": true,
+}
+
+// ScanFIPS parses the Go files under dir, excluding tests, vendored code, and hidden directories,
+// and returns crypto usages that are not FIPS compliant in file and source order.
+func ScanFIPS(dir string) ([]Finding, error) {
+	var findings []Finding
+	fset := token.NewFileSet()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %v", path, err)
+		}
+		findings = append(findings, scanFile(fset, dir, f)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
+
+// scanFile returns the non-compliant usages in f, with positions relative to dir.
+func scanFile(fset *token.FileSet, dir string, f *ast.File) (findings []Finding) {
+	add := func(pos token.Pos, rule, msg string) {
+		p := fset.Position(pos)
+		if rel, err := filepath.Rel(dir, p.Filename); err == nil {
+			p.Filename = rel
+		}
+		findings = append(findings, Finding{Position: p.String(), Rule: rule, Message: msg})
+	}
+
+	// tlsName is the name crypto/tls is imported as in f, if imported. Imports precede
+	// every other declaration, so it is set before any selector is inspected.
+	tlsName := ""
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			path, err := strconv.Unquote(n.Path.Value)
+			if err != nil {
+				return false
+			}
+			if msg, ok := nonFIPSPackages[path]; ok {
+				add(n.Pos(), "weak-algorithm", msg)
+			} else if strings.HasPrefix(path, xCryptoPrefix) {
+				add(n.Pos(), "non-boringcrypto", fmt.Sprintf("%s is not implemented by BoringCrypto, "+
+					"use an equivalent from the standard library's crypto packages", path))
+			}
+			if path == "crypto/tls" {
+				tlsName = "tls"
+				if n.Name != nil {
+					tlsName = n.Name.Name
+				}
+			}
+		case *ast.KeyValueExpr:
+			if isIdent(n.Key, "InsecureSkipVerify") && isIdent(n.Value, "true") {
+				add(n.Pos(), "insecure-skip-verify", "TLS certificate verification is disabled")
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if sel, ok := lhs.(*ast.SelectorExpr); ok && sel.Sel.Name == "InsecureSkipVerify" &&
+					i < len(n.Rhs) && isIdent(n.Rhs[i], "true") {
+					add(n.Pos(), "insecure-skip-verify", "TLS certificate verification is disabled")
+				}
+			}
+		case *ast.SelectorExpr:
+			if tlsName == "" || !isIdent(n.X, tlsName) {
+				return true
+			}
+			name := n.Sel.Name
+			if weakTLSVersions[name] {
+				add(n.Pos(), "weak-tls-version", fmt.Sprintf("%s is not a FIPS-approved TLS version, "+
+					"use tls.VersionTLS12 or higher", name))
+			} else if strings.HasPrefix(name, "TLS_") && (strings.Contains(name, "RC4") || strings.Contains(name, "3DES")) {
+				add(n.Pos(), "weak-cipher-suite", fmt.Sprintf("%s is not a FIPS-approved cipher suite, "+
+					"use an AES-GCM cipher suite", name))
+			}
+		}
+		return true
+	})
+	return findings
+}
+
+// isIdent returns true if e is an identifier named name.
+func isIdent(e ast.Expr, name string) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == name
+}
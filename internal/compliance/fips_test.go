@@ -0,0 +1,128 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const nonCompliantSource = `package controllers
+
+import (
+	"crypto/md5"
+	stdtls "crypto/tls"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func insecure() {
+	_ = md5.New()
+	_, _ = bcrypt.GenerateFromPassword(nil, 0)
+	cfg := &stdtls.Config{
+		InsecureSkipVerify: true,
+		MinVersion:         stdtls.VersionTLS10,
+		CipherSuites:       []uint16{stdtls.TLS_RSA_WITH_3DES_EDE_CBC_SHA},
+	}
+	cfg.InsecureSkipVerify = true
+}
+`
+
+const compliantSource = `package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+)
+
+func secure() {
+	_ = sha256.New()
+	_ = &tls.Config{
+		InsecureSkipVerify: false,
+		MinVersion:         tls.VersionTLS12,
+		CipherSuites:       []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+	}
+}
+`
+
+var _ = Describe("ScanFIPS", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "compliance-")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	writeFile := func(path, contents string) {
+		path = filepath.Join(dir, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+	}
+
+	rules := func(findings []Finding) (rs []string) {
+		for _, f := range findings {
+			rs = append(rs, f.Rule)
+		}
+		return rs
+	}
+
+	It("returns no findings for compliant source", func() {
+		writeFile("main.go", compliantSource)
+		findings, err := ScanFIPS(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(BeEmpty())
+	})
+
+	It("flags non-compliant crypto usages in order", func() {
+		writeFile("main.go", compliantSource)
+		writeFile(filepath.Join("controllers", "foo.go"), nonCompliantSource)
+		findings, err := ScanFIPS(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules(findings)).To(Equal([]string{
+			"weak-algorithm",
+			"non-boringcrypto",
+			"insecure-skip-verify",
+			"weak-tls-version",
+			"weak-cipher-suite",
+			"insecure-skip-verify",
+		}))
+		Expect(findings[0].Position).To(Equal(filepath.Join("controllers", "foo.go") + ":4:2"))
+		Expect(findings[3].Message).To(ContainSubstring("VersionTLS10"))
+	})
+
+	It("skips tests, vendored code, and hidden directories", func() {
+		writeFile("foo_test.go", nonCompliantSource)
+		writeFile(filepath.Join("vendor", "foo.go"), nonCompliantSource)
+		writeFile(filepath.Join(".cache", "foo.go"), nonCompliantSource)
+		findings, err := ScanFIPS(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(BeEmpty())
+	})
+
+	It("returns an error for unparseable source", func() {
+		writeFile("main.go", "package main\nfunc {")
+		_, err := ScanFIPS(dir)
+		Expect(err).To(MatchError(ContainSubstring("error parsing")))
+	})
+})
@@ -0,0 +1,105 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doctor
+
+import (
+	"context"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+)
+
+// checkCluster checks that the kubeconfig is valid, its cluster is reachable, and OLM is installed there.
+func (d Doctor) checkCluster(ctx context.Context, report *Report) {
+	cfg, err := d.checkKubeconfig(report)
+	if err != nil {
+		report.skip(clusterCheck, "no valid kubeconfig")
+		report.skip(olmCheck, "no valid kubeconfig")
+		return
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		report.fail(clusterCheck, "check the kubeconfig's cluster settings", "cannot create a client: %v", err)
+		report.skip(olmCheck, "cluster is not reachable")
+		return
+	}
+	info, err := dc.ServerVersion()
+	if err != nil {
+		report.fail(clusterCheck, "check that the cluster is running and reachable, ex. with 'kubectl cluster-info'",
+			"cannot reach %s: %v", cfg.Host, err)
+		report.skip(olmCheck, "cluster is not reachable")
+		return
+	}
+	report.pass(clusterCheck, "Kubernetes %s at %s", info.GitVersion, cfg.Host)
+	d.checkOLM(ctx, report, cfg, dc)
+}
+
+// checkKubeconfig checks that the kubeconfig can be loaded, and returns its client config.
+func (d Doctor) checkKubeconfig(report *Report) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = d.KubeconfigPath
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: d.Context}
+	cc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+	cfg, err := cc.ClientConfig()
+	if err != nil {
+		report.fail(kubeconfigCheck, "set --kubeconfig or $KUBECONFIG to a valid kubeconfig, ex. one written by "+
+			"'kind export kubeconfig'", "%v", err)
+		return nil, err
+	}
+	raw, err := cc.RawConfig()
+	if err != nil || raw.CurrentContext == "" {
+		// The in-cluster config is used if no kubeconfig is found.
+		report.pass(kubeconfigCheck, "using the in-cluster config")
+		return cfg, nil
+	}
+	name := raw.CurrentContext
+	if d.Context != "" {
+		name = d.Context
+	}
+	report.pass(kubeconfigCheck, "context %q", name)
+	return cfg, nil
+}
+
+// checkOLM checks that the cluster serves OLM's APIs, and reads OLM's version.
+func (d Doctor) checkOLM(ctx context.Context, report *Report, cfg *rest.Config, dc discovery.DiscoveryInterface) {
+	fix := "run 'operator-sdk olm install', or set --install-olm when running 'run bundle'"
+	if _, err := dc.ServerResourcesForGroupVersion(v1alpha1.SchemeGroupVersion.String()); err != nil {
+		if apierrors.IsNotFound(err) {
+			report.fail(olmCheck, fix, "OLM is not installed")
+			return
+		}
+		report.warn(olmCheck, "", "unable to discover OLM's APIs: %v", err)
+		return
+	}
+	versionFix := "set --olm-namespace to the namespace OLM is installed in"
+	c, err := client.New(cfg, client.Options{Scheme: olmclient.Scheme})
+	if err != nil {
+		report.warn(olmCheck, versionFix, "OLM is installed, but its version could not be read: %v", err)
+		return
+	}
+	version, err := olmclient.Client{KubeClient: c}.GetInstalledVersion(ctx, d.OLMNamespace)
+	if err != nil {
+		report.warn(olmCheck, versionFix, "OLM is installed, but its version could not be read from namespace %q: %v",
+			d.OLMNamespace, err)
+		return
+	}
+	report.pass(olmCheck, "OLM version %s in namespace %q", version, d.OLMNamespace)
+}
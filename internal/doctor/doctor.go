@@ -0,0 +1,73 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package doctor checks the local environment operator-sdk commands run in: container tools, opm,
+// the kubeconfig, the cluster it points to, OLM, and push access to an image registry.
+package doctor
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Names of checks.
+const (
+	containerToolCheck = "container-tool"
+	opmCheck           = "opm"
+	kubeconfigCheck    = "kubeconfig"
+	clusterCheck       = "cluster"
+	olmCheck           = "olm"
+	registryCheck      = "registry-push"
+)
+
+// Doctor checks the local environment.
+type Doctor struct {
+	// KubeconfigPath is the path of the kubeconfig to check. If empty, the default loading rules are used.
+	KubeconfigPath string
+	// Context is the kubeconfig context to check. If empty, the current context is used.
+	Context string
+	// OLMNamespace is the namespace OLM is installed in.
+	OLMNamespace string
+	// Repository is an image repository to check push access to, ex. quay.io/example/memcached-operator.
+	// The check is skipped if it is empty.
+	Repository string
+	// RegistryConfigDir is the directory of the Docker config.json registry credentials are read from.
+	// If empty, $DOCKER_CONFIG, then ~/.docker, is used.
+	RegistryConfigDir string
+	// RegistryInsecure makes the push check use plain HTTP, ex. for a local registry.
+	RegistryInsecure bool
+
+	// lookPath and output are exec.LookPath and running a command for its output. They are replaced in tests.
+	lookPath func(string) (string, error)
+	output   func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+func runOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+// Run runs all checks and returns a report of their results.
+func (d Doctor) Run(ctx context.Context) (report Report) {
+	if d.lookPath == nil {
+		d.lookPath = exec.LookPath
+	}
+	if d.output == nil {
+		d.output = runOutput
+	}
+	d.checkContainerTools(ctx, &report)
+	d.checkOPM(ctx, &report)
+	d.checkCluster(ctx, &report)
+	d.checkRegistry(ctx, &report)
+	return report
+}
@@ -0,0 +1,236 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Doctor", func() {
+	var (
+		d      Doctor
+		report Report
+		ctx    context.Context
+		paths  map[string]bool
+		// outputs maps commands to their output, and errors to fail them with.
+		outputs map[string]string
+		errs    map[string]error
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		report = Report{}
+		paths = map[string]bool{}
+		outputs = map[string]string{}
+		errs = map[string]error{}
+		d = Doctor{
+			lookPath: func(name string) (string, error) {
+				if paths[name] {
+					return "/usr/bin/" + name, nil
+				}
+				return "", errors.New("not found")
+			},
+			output: func(_ context.Context, name string, args ...string) ([]byte, error) {
+				cmd := strings.Join(append([]string{name}, args...), " ")
+				return []byte(outputs[cmd]), errs[cmd]
+			},
+		}
+	})
+
+	Describe("checkContainerTools", func() {
+		const dockerVersion = "docker version --format {{.Server.Version}}"
+
+		It("passes for a working tool", func() {
+			paths["docker"] = true
+			outputs[dockerVersion] = "19.03.12\n"
+			d.checkContainerTools(ctx, &report)
+			Expect(report.Results).To(Equal([]Result{{Name: "container-tool/docker", State: PassState,
+				Message: "docker 19.03.12"}}))
+		})
+		It("fails if docker's daemon is not running", func() {
+			paths["docker"] = true
+			outputs[dockerVersion] = "Cannot connect to the Docker daemon at unix:///var/run/docker.sock.\n"
+			errs[dockerVersion] = errors.New("exit status 1")
+			d.checkContainerTools(ctx, &report)
+			Expect(report.Results).To(HaveLen(1))
+			Expect(report.Results[0].State).To(Equal(FailState))
+			Expect(report.Results[0].Message).To(Equal("docker is installed but not working: " +
+				"Cannot connect to the Docker daemon at unix:///var/run/docker.sock."))
+			Expect(report.Results[0].Fix).To(ContainSubstring("start the Docker daemon"))
+		})
+		It("fails if no tool is installed", func() {
+			d.checkContainerTools(ctx, &report)
+			Expect(report.HasFailures()).To(BeTrue())
+			Expect(report.Results[0].Message).To(Equal("neither docker nor podman was found in PATH"))
+		})
+	})
+
+	Describe("checkOPM", func() {
+		It("passes for a recent opm", func() {
+			paths["opm"] = true
+			outputs["opm version"] = `Version: version.Version{OpmVersion:"v1.17.2", GitCommit:"abc", GoOs:"linux"}`
+			d.checkOPM(ctx, &report)
+			Expect(report.Results).To(Equal([]Result{{Name: "opm", State: PassState, Message: "opm v1.17.2"}}))
+		})
+		It("warns for an old opm", func() {
+			paths["opm"] = true
+			outputs["opm version"] = `Version: version.Version{OpmVersion:"v1.13.4", GitCommit:"abc", GoOs:"linux"}`
+			d.checkOPM(ctx, &report)
+			Expect(report.Results[0].State).To(Equal(WarnState))
+			Expect(report.Results[0].Message).To(ContainSubstring("opm v1.13.4 is older than v1.17.0"))
+		})
+		It("warns if opm is not installed", func() {
+			d.checkOPM(ctx, &report)
+			Expect(report.Results[0].State).To(Equal(WarnState))
+			Expect(report.Results[0].Fix).To(ContainSubstring(opmReleasesURL))
+		})
+	})
+
+	Describe("checkCluster", func() {
+		var (
+			server *httptest.Server
+			dir    string
+		)
+
+		writeKubeconfig := func(host string) string {
+			path := filepath.Join(dir, "kubeconfig")
+			Expect(ioutil.WriteFile(path, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+current-context: test
+`, host)), 0600)).To(Succeed())
+			return path
+		}
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "doctor-")
+			Expect(err).NotTo(HaveOccurred())
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/version" {
+					fmt.Fprint(w, `{"major":"1","minor":"18","gitVersion":"v1.18.6"}`)
+					return
+				}
+				http.NotFound(w, r)
+			}))
+		})
+		AfterEach(func() {
+			server.Close()
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("fails and skips cluster checks for an invalid kubeconfig", func() {
+			d.KubeconfigPath = filepath.Join(dir, "missing")
+			d.checkCluster(ctx, &report)
+			Expect(report.Results).To(HaveLen(3))
+			Expect(report.Results[0].State).To(Equal(FailState))
+			Expect(report.Results[1].State).To(Equal(SkipState))
+			Expect(report.Results[2].State).To(Equal(SkipState))
+		})
+		It("reports the cluster's version, and fails if OLM is not installed", func() {
+			d.KubeconfigPath = writeKubeconfig(server.URL)
+			d.checkCluster(ctx, &report)
+			Expect(report.Results).To(HaveLen(3))
+			Expect(report.Results[0]).To(Equal(Result{Name: "kubeconfig", State: PassState, Message: `context "test"`}))
+			Expect(report.Results[1]).To(Equal(Result{Name: "cluster", State: PassState,
+				Message: "Kubernetes v1.18.6 at " + server.URL}))
+			Expect(report.Results[2].State).To(Equal(FailState))
+			Expect(report.Results[2].Message).To(Equal("OLM is not installed"))
+		})
+		It("fails if the cluster is not reachable", func() {
+			d.KubeconfigPath = writeKubeconfig(server.URL)
+			server.Close()
+			d.checkCluster(ctx, &report)
+			Expect(report.Results[1].State).To(Equal(FailState))
+			Expect(report.Results[2].State).To(Equal(SkipState))
+		})
+	})
+
+	Describe("checkRegistry", func() {
+		var (
+			server *httptest.Server
+			allow  bool
+		)
+
+		BeforeEach(func() {
+			allow = true
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == "/v2/example/memcached-operator/blobs/uploads/":
+					if !allow {
+						w.WriteHeader(http.StatusForbidden)
+						return
+					}
+					w.Header().Set("Location", "/v2/example/memcached-operator/blobs/uploads/1")
+					w.WriteHeader(http.StatusAccepted)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			d.RegistryInsecure = true
+			d.RegistryConfigDir = "testdata"
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("skips the check if no repository is set", func() {
+			d.checkRegistry(ctx, &report)
+			Expect(report.Results).To(Equal([]Result{{Name: "registry-push", State: SkipState,
+				Message: "no repository set"}}))
+		})
+		It("passes if a push can be started", func() {
+			d.Repository = strings.TrimPrefix(server.URL, "http://") + "/example/memcached-operator"
+			d.checkRegistry(ctx, &report)
+			Expect(report.Results).To(Equal([]Result{{Name: "registry-push", State: PassState,
+				Message: "can push to " + d.Repository}}))
+		})
+		It("fails if pushes are denied", func() {
+			allow = false
+			d.Repository = strings.TrimPrefix(server.URL, "http://") + "/example/memcached-operator"
+			d.checkRegistry(ctx, &report)
+			Expect(report.Results[0].State).To(Equal(FailState))
+			Expect(report.Results[0].Message).To(ContainSubstring("403 Forbidden"))
+			Expect(report.Results[0].Fix).To(ContainSubstring("docker login"))
+		})
+	})
+
+	Describe("Report", func() {
+		It("lists the fixes of results that did not pass", func() {
+			report.pass("a", "fine")
+			report.fail("b", "do something", "broken")
+			Expect(report.HasFailures()).To(BeTrue())
+			Expect(report.String()).To(HaveSuffix("\nTo fix:\n  b: do something\n"))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+)
+
+// checkRegistry checks that d.Repository can be pushed to with the configured credentials, by starting
+// the upload of a blob that does not exist, then abandoning it.
+func (d Doctor) checkRegistry(ctx context.Context, report *Report) {
+	if d.Repository == "" {
+		report.skip(registryCheck, "no repository set")
+		return
+	}
+	named, err := reference.ParseNormalizedNamed(d.Repository)
+	if err != nil {
+		report.fail(registryCheck, "set --repository to a repository, ex. quay.io/example/memcached-operator",
+			"invalid repository %q: %v", d.Repository, err)
+		return
+	}
+	host := reference.Domain(named)
+	resolver, err := containerdregistry.NewResolver(d.RegistryConfigDir, d.RegistryInsecure, nil)
+	if err != nil {
+		report.fail(registryCheck, "check that the Docker config.json registry credentials are read from is valid",
+			"cannot read registry credentials: %v", err)
+		return
+	}
+	pusher, err := resolver.Pusher(ctx, reference.TagNameOnly(named).String())
+	if err != nil {
+		report.fail(registryCheck, "", "cannot push to %s: %v", named.Name(), err)
+		return
+	}
+
+	// The upload is abandoned by cancelling its context before the writer is closed, so no blob is created.
+	pushCtx, cancel := context.WithCancel(ctx)
+	blob := digest.FromString(fmt.Sprintf("operator-sdk doctor %d", time.Now().UnixNano()))
+	w, err := pusher.Push(pushCtx, ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer, Digest: blob, Size: 1})
+	cancel()
+	if w != nil {
+		_ = w.Close()
+	}
+	if err != nil && !errdefs.IsAlreadyExists(err) {
+		report.fail(registryCheck, fmt.Sprintf("log in with 'docker login %s', or set --registry-config-dir to "+
+			"the directory of a config.json with credentials for it, and check that the account may push to %s",
+			host, reference.Path(named)),
+			"cannot push to %s: %v", named.Name(), err)
+		return
+	}
+	report.pass(registryCheck, "can push to %s", named.Name())
+}
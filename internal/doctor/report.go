@@ -0,0 +1,108 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doctor
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+)
+
+// State is the outcome of a single check.
+type State string
+
+const (
+	// PassState means the environment satisfies the check.
+	PassState State = "pass"
+	// WarnState means some commands may not work until the check passes.
+	WarnState State = "warn"
+	// FailState means commands that need the checked tool or cluster will fail until the check passes.
+	FailState State = "fail"
+	// SkipState means the check was not run, since a check it depends on did not pass or it was not configured.
+	SkipState State = "skip"
+)
+
+// Result is the result of a single check.
+type Result struct {
+	Name    string
+	State   State
+	Message string
+	// Fix is guidance on making the check pass, if it did not.
+	Fix string
+}
+
+// Report is a set of check results.
+type Report struct {
+	Results []Result
+}
+
+func (r *Report) pass(name, format string, args ...interface{}) {
+	r.add(name, PassState, "", format, args...)
+}
+
+func (r *Report) skip(name, format string, args ...interface{}) {
+	r.add(name, SkipState, "", format, args...)
+}
+
+func (r *Report) warn(name, fix, format string, args ...interface{}) {
+	r.add(name, WarnState, fix, format, args...)
+}
+
+func (r *Report) fail(name, fix, format string, args ...interface{}) {
+	r.add(name, FailState, fix, format, args...)
+}
+
+func (r *Report) add(name string, state State, fix, format string, args ...interface{}) {
+	r.Results = append(r.Results, Result{
+		Name:    name,
+		State:   state,
+		Message: fmt.Sprintf(format, args...),
+		Fix:     fix,
+	})
+}
+
+// HasFailures returns true if at least one result in r has failed.
+func (r Report) HasFailures() bool {
+	for _, result := range r.Results {
+		if result.State == FailState {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a table of r's results, followed by the fixes of results that did not pass.
+func (r Report) String() string {
+	out := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(out, 8, 4, 4, ' ', 0)
+	fmt.Fprintf(tw, "CHECK\tSTATE\tMESSAGE\n")
+	for _, result := range r.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Name, result.State, result.Message)
+	}
+	tw.Flush()
+
+	header := false
+	for _, result := range r.Results {
+		if result.Fix == "" {
+			continue
+		}
+		if !header {
+			fmt.Fprintf(out, "\nTo fix:\n")
+			header = true
+		}
+		fmt.Fprintf(out, "  %s: %s\n", result.Name, result.Fix)
+	}
+	return out.String()
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+
+	"github.com/operator-framework/operator-sdk/internal/imagebuild"
+)
+
+// minOPMVersion is the earliest opm version with 'opm render', which renders bundles into the
+// file-based catalogs 'release' expects.
+var minOPMVersion = semver.MustParse("1.17.0")
+
+const opmReleasesURL = "https://github.com/operator-framework/operator-registry/releases"
+
+// opmVersionRegexp matches the version in the output of 'opm version'.
+var opmVersionRegexp = regexp.MustCompile(`OpmVersion:"([^"]+)"`)
+
+// checkContainerTools checks that docker or podman is installed and working, and that the tool set in
+// $OPERATOR_SDK_IMAGE_BUILDER, if any, is installed.
+func (d Doctor) checkContainerTools(ctx context.Context, report *Report) {
+	envTool := os.Getenv(imagebuild.ImageBuilderEnv)
+	found := false
+	for _, tool := range []string{imagebuild.Docker, imagebuild.Podman} {
+		if _, err := d.lookPath(tool); err != nil {
+			continue
+		}
+		found = true
+		name := containerToolCheck + "/" + tool
+		// docker's server version needs its daemon, so a stopped daemon fails the check.
+		format := "{{.Server.Version}}"
+		if tool == imagebuild.Podman {
+			format = "{{.Client.Version}}"
+		}
+		out, err := d.output(ctx, tool, "version", "--format", format)
+		if err != nil {
+			fix := fmt.Sprintf("check that '%s version' succeeds", tool)
+			if tool == imagebuild.Docker {
+				fix = "start the Docker daemon, or set $" + imagebuild.ImageBuilderEnv + "=podman to use podman"
+			}
+			report.fail(name, fix, "%s is installed but not working: %s", tool, firstLine(out, err))
+			continue
+		}
+		report.pass(name, "%s %s", tool, strings.TrimSpace(string(out)))
+	}
+	if !found {
+		report.fail(containerToolCheck, "install docker or podman to build and push images",
+			"neither %s nor %s was found in PATH", imagebuild.Docker, imagebuild.Podman)
+		return
+	}
+	switch envTool {
+	case "", imagebuild.Kaniko, imagebuild.None:
+	default:
+		if _, err := d.lookPath(envTool); err != nil {
+			report.fail(containerToolCheck, fmt.Sprintf("install %s, or unset $%s", envTool, imagebuild.ImageBuilderEnv),
+				"$%s is %s, which was not found in PATH", imagebuild.ImageBuilderEnv, envTool)
+		}
+	}
+}
+
+// checkOPM checks that opm is installed, and recent enough to render bundles.
+func (d Doctor) checkOPM(ctx context.Context, report *Report) {
+	fix := fmt.Sprintf("install opm v%s or later from %s", minOPMVersion, opmReleasesURL)
+	if _, err := d.lookPath("opm"); err != nil {
+		report.warn(opmCheck, fix, "opm was not found in PATH; it is needed to render bundles into catalogs")
+		return
+	}
+	out, err := d.output(ctx, "opm", "version")
+	if err != nil {
+		report.fail(opmCheck, fix, "opm is installed but not working: %s", firstLine(out, err))
+		return
+	}
+	version, err := parseOPMVersion(out)
+	if err != nil {
+		report.warn(opmCheck, fix, "%v", err)
+		return
+	}
+	if version.LT(minOPMVersion) {
+		report.warn(opmCheck, fix, "opm v%s is older than v%s, so cannot render bundles with 'opm render'",
+			version, minOPMVersion)
+		return
+	}
+	report.pass(opmCheck, "opm v%s", version)
+}
+
+// parseOPMVersion returns the version in the output of 'opm version'.
+func parseOPMVersion(out []byte) (semver.Version, error) {
+	m := opmVersionRegexp.FindSubmatch(out)
+	if m == nil {
+		return semver.Version{}, fmt.Errorf("unable to find the version in 'opm version' output %q",
+			strings.TrimSpace(string(out)))
+	}
+	version, err := semver.ParseTolerant(string(m[1]))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("unable to parse opm version %q: %v", m[1], err)
+	}
+	return version, nil
+}
+
+// firstLine returns the first line of a failed command's output, or err if it had no output.
+func firstLine(out []byte, err error) string {
+	if line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(string(out)), "\n", 2)[0]); line != "" {
+		return line
+	}
+	return err.Error()
+}
@@ -16,5 +16,8 @@ package flags
 
 // global command-line flags
 const (
-	VerboseOpt = "verbose"
+	VerboseOpt   = "verbose"
+	VerbosityOpt = "v"
+	LogFormatOpt = "log-format"
+	TraceOpt     = "trace-endpoint"
 )
@@ -0,0 +1,303 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admissionpolicy generates CEL-based ValidatingAdmissionPolicies from the OpenAPI schemas
+// of CRDs, which enforce an operator's simple validation rules, i.e. those set by kubebuilder
+// validation markers, in clusters where running its validating webhook is undesirable.
+package admissionpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// APIVersion is the API version of generated policies and bindings. The types are not defined by the
+// vendored Kubernetes API version, so are defined here.
+const APIVersion = "admissionregistration.k8s.io/v1"
+
+// ValidatingAdmissionPolicy validates objects with CEL expressions.
+type ValidatingAdmissionPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        ObjectMeta `json:"metadata"`
+	Spec            PolicySpec `json:"spec"`
+}
+
+// ValidatingAdmissionPolicyBinding enforces a ValidatingAdmissionPolicy in the cluster.
+type ValidatingAdmissionPolicyBinding struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        ObjectMeta  `json:"metadata"`
+	Spec            BindingSpec `json:"spec"`
+}
+
+// ObjectMeta is the metadata of a generated object.
+type ObjectMeta struct {
+	Name string `json:"name"`
+}
+
+type PolicySpec struct {
+	FailurePolicy    string           `json:"failurePolicy"`
+	MatchConstraints MatchConstraints `json:"matchConstraints"`
+	Validations      []Validation     `json:"validations"`
+}
+
+type MatchConstraints struct {
+	MatchPolicy   string         `json:"matchPolicy"`
+	ResourceRules []ResourceRule `json:"resourceRules"`
+}
+
+type ResourceRule struct {
+	APIGroups   []string `json:"apiGroups"`
+	APIVersions []string `json:"apiVersions"`
+	Operations  []string `json:"operations"`
+	Resources   []string `json:"resources"`
+}
+
+// Validation is a CEL expression an object must satisfy, and the message returned if it does not.
+type Validation struct {
+	Expression string `json:"expression"`
+	Message    string `json:"message"`
+	Reason     string `json:"reason"`
+}
+
+type BindingSpec struct {
+	PolicyName        string   `json:"policyName"`
+	ValidationActions []string `json:"validationActions"`
+}
+
+// Policy is the ValidatingAdmissionPolicy of a CRD and the binding that enforces it.
+type Policy struct {
+	Policy  ValidatingAdmissionPolicy
+	Binding ValidatingAdmissionPolicyBinding
+}
+
+// PoliciesFromCRDs returns a Policy for each v1 and v1beta1 CRD in dir with validation rules in the spec
+// of its storage version's schema. Requests for other versions are converted to the storage version
+// before they are validated. CRDs without rules are logged and skipped.
+func PoliciesFromCRDs(dir string) (policies []Policy, err error) {
+	v1crds, v1beta1crds, err := k8sutil.GetCustomResourceDefinitions(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CRDs from %s: %v", dir, err)
+	}
+	for _, crd := range v1beta1crds {
+		converted, err := k8sutil.Convertv1beta1Tov1CustomResourceDefinition(&crd)
+		if err != nil {
+			return nil, fmt.Errorf("error converting CRD %s to v1: %v", crd.GetName(), err)
+		}
+		v1crds = append(v1crds, *converted)
+	}
+
+	for _, crd := range v1crds {
+		for _, version := range crd.Spec.Versions {
+			if !version.Storage {
+				continue
+			}
+			var validations []Validation
+			if version.Schema != nil && version.Schema.OpenAPIV3Schema != nil {
+				if spec, hasSpec := version.Schema.OpenAPIV3Schema.Properties["spec"]; hasSpec {
+					validations = fieldValidations(&spec, field{cel: "object.spec", path: "spec"}, nil)
+				}
+			}
+			if len(validations) == 0 {
+				log.Warnf("Skipping CRD %s: its spec has no validation rules", crd.GetName())
+				continue
+			}
+			policies = append(policies, newPolicy(crd, version.Name, validations))
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Policy.Metadata.Name < policies[j].Policy.Metadata.Name
+	})
+	return policies, nil
+}
+
+func newPolicy(crd apiextv1.CustomResourceDefinition, version string, validations []Validation) Policy {
+	name := crd.GetName()
+	return Policy{
+		Policy: ValidatingAdmissionPolicy{
+			TypeMeta: metav1.TypeMeta{APIVersion: APIVersion, Kind: "ValidatingAdmissionPolicy"},
+			Metadata: ObjectMeta{Name: name},
+			Spec: PolicySpec{
+				FailurePolicy: "Fail",
+				MatchConstraints: MatchConstraints{
+					MatchPolicy: "Equivalent",
+					ResourceRules: []ResourceRule{{
+						APIGroups:   []string{crd.Spec.Group},
+						APIVersions: []string{version},
+						Operations:  []string{"CREATE", "UPDATE"},
+						Resources:   []string{crd.Spec.Names.Plural},
+					}},
+				},
+				Validations: validations,
+			},
+		},
+		Binding: ValidatingAdmissionPolicyBinding{
+			TypeMeta: metav1.TypeMeta{APIVersion: APIVersion, Kind: "ValidatingAdmissionPolicyBinding"},
+			Metadata: ObjectMeta{Name: name},
+			Spec:     BindingSpec{PolicyName: name, ValidationActions: []string{"Deny"}},
+		},
+	}
+}
+
+// field is a field of an object, accessed in CEL by cel and reported by path.
+type field struct {
+	cel, path string
+}
+
+func (f field) child(name string) (field, bool) {
+	escaped, ok := escapeProperty(name)
+	return field{cel: f.cel + "." + escaped, path: f.path + "." + name}, ok
+}
+
+// fieldValidations returns the validations of the rules of s, the schema of f, and of its properties.
+// Validations of optional fields pass if any of the fields checked by guards are unset.
+// Rules of array items are not validated.
+func fieldValidations(s *apiextv1.JSONSchemaProps, f field, guards []string) (validations []Validation) {
+	if s.Nullable || s.XIntOrString {
+		return nil
+	}
+	guards = append(guards[:len(guards):len(guards)], fmt.Sprintf("has(%s)", f.cel))
+	add := func(check, message string, args ...interface{}) {
+		expression := check
+		if len(guards) != 0 {
+			expression = "!" + strings.Join(guards, " || !") + " || " + check
+		}
+		validations = append(validations, Validation{
+			Expression: expression,
+			Message:    fmt.Sprintf("%s %s", f.path, fmt.Sprintf(message, args...)),
+			Reason:     "Invalid",
+		})
+	}
+
+	switch s.Type {
+	case "integer", "number":
+		if s.Minimum != nil {
+			if s.ExclusiveMinimum {
+				add(fmt.Sprintf("%s > %s", f.cel, number(s.Type, *s.Minimum)), "must be greater than %v", *s.Minimum)
+			} else {
+				add(fmt.Sprintf("%s >= %s", f.cel, number(s.Type, *s.Minimum)), "must be at least %v", *s.Minimum)
+			}
+		}
+		if s.Maximum != nil {
+			if s.ExclusiveMaximum {
+				add(fmt.Sprintf("%s < %s", f.cel, number(s.Type, *s.Maximum)), "must be less than %v", *s.Maximum)
+			} else {
+				add(fmt.Sprintf("%s <= %s", f.cel, number(s.Type, *s.Maximum)), "must be at most %v", *s.Maximum)
+			}
+		}
+	case "string":
+		if s.MinLength != nil {
+			add(fmt.Sprintf("size(%s) >= %d", f.cel, *s.MinLength), "must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil {
+			add(fmt.Sprintf("size(%s) <= %d", f.cel, *s.MaxLength), "must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			add(fmt.Sprintf("%s.matches(%s)", f.cel, strconv.Quote(s.Pattern)), "must match %q", s.Pattern)
+		}
+	case "array":
+		if s.MinItems != nil {
+			add(fmt.Sprintf("size(%s) >= %d", f.cel, *s.MinItems), "must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil {
+			add(fmt.Sprintf("size(%s) <= %d", f.cel, *s.MaxItems), "must have at most %d items", *s.MaxItems)
+		}
+	case "object":
+		for _, name := range s.Required {
+			if child, ok := f.child(name); ok {
+				add(fmt.Sprintf("has(%s)", child.cel), "must set %s", name)
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child, ok := f.child(name)
+			if !ok {
+				log.Debugf("Skipping %s.%s: its name cannot be accessed in CEL", f.path, name)
+				continue
+			}
+			prop := s.Properties[name]
+			validations = append(validations, fieldValidations(&prop, child, guards)...)
+		}
+	}
+	if enum := enumValues(s.Enum); len(enum) != 0 {
+		add(fmt.Sprintf("%s in [%s]", f.cel, strings.Join(enum, ", ")), "must be one of [%s]", strings.Join(enum, ", "))
+	}
+	return validations
+}
+
+// number formats v as a CEL literal of the schema type typ.
+func number(typ string, v float64) string {
+	if typ == "integer" {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+// enumValues returns enum's values as CEL literals, or nil if any is not a string, number, or bool.
+func enumValues(enum []apiextv1.JSON) []string {
+	values := make([]string, 0, len(enum))
+	for _, v := range enum {
+		var value interface{}
+		if err := json.Unmarshal(v.Raw, &value); err != nil {
+			return nil
+		}
+		switch value.(type) {
+		case string, float64, bool:
+		default:
+			return nil
+		}
+		values = append(values, string(v.Raw))
+	}
+	return values
+}
+
+var (
+	propertyNameRegexp = regexp.MustCompile(`^[a-zA-Z_.\-/][a-zA-Z0-9_.\-/]*$`)
+	celReservedWords   = map[string]bool{
+		"true": true, "false": true, "null": true, "in": true, "as": true, "break": true, "const": true,
+		"continue": true, "else": true, "for": true, "function": true, "if": true, "import": true, "let": true,
+		"loop": true, "package": true, "namespace": true, "return": true, "var": true, "void": true, "while": true,
+	}
+)
+
+// escapeProperty escapes name the way Kubernetes does to access it in CEL, and returns false if it cannot
+// be accessed.
+func escapeProperty(name string) (string, bool) {
+	if celReservedWords[name] {
+		return "__" + name + "__", true
+	}
+	if !propertyNameRegexp.MatchString(name) {
+		return "", false
+	}
+	escaped := strings.NewReplacer("__", "__underscores__", ".", "__dot__", "-", "__dash__", "/", "__slash__").
+		Replace(name)
+	return escaped, true
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admissionpolicy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/parser"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const crds = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Memcached
+    plural: memcacheds
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required:
+            - size
+            properties:
+              size:
+                type: integer
+                minimum: 1
+                maximum: 10
+              ratio:
+                type: number
+                maximum: 1
+                exclusiveMaximum: true
+              image-tag:
+                type: string
+                minLength: 1
+                pattern: '^v\d+$'
+              mode:
+                type: string
+                enum: ["fast", "safe"]
+              nodes:
+                type: array
+                maxItems: 3
+                items:
+                  type: object
+                  properties:
+                    name:
+                      type: string
+                      maxLength: 10
+              namespace:
+                type: string
+                maxLength: 63
+              resources:
+                x-kubernetes-int-or-string: true
+                maxLength: 4
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: backups.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Backup
+    plural: backups
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+`
+
+var _ = Describe("Admission policies", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "admissionpolicy")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "crds.yaml"), []byte(crds), 0644)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("generates a policy and binding for the storage version of CRDs with validation rules", func() {
+		policies, err := PoliciesFromCRDs(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policies).To(HaveLen(1))
+
+		policy, binding := policies[0].Policy, policies[0].Binding
+		Expect(policy.Kind).To(Equal("ValidatingAdmissionPolicy"))
+		Expect(policy.Metadata.Name).To(Equal("memcacheds.cache.example.com"))
+		Expect(policy.Spec.MatchConstraints.ResourceRules).To(Equal([]ResourceRule{{
+			APIGroups:   []string{"cache.example.com"},
+			APIVersions: []string{"v1"},
+			Operations:  []string{"CREATE", "UPDATE"},
+			Resources:   []string{"memcacheds"},
+		}}))
+		Expect(binding.Kind).To(Equal("ValidatingAdmissionPolicyBinding"))
+		Expect(binding.Spec.PolicyName).To(Equal(policy.Metadata.Name))
+		Expect(binding.Spec.ValidationActions).To(Equal([]string{"Deny"}))
+	})
+	It("derives CEL expressions from the spec's validation rules", func() {
+		policies, err := PoliciesFromCRDs(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policies).To(HaveLen(1))
+
+		expressions := map[string]string{}
+		for _, v := range policies[0].Policy.Spec.Validations {
+			expressions[v.Message] = v.Expression
+			_, errs := parser.Parse(common.NewTextSource(v.Expression))
+			Expect(errs.GetErrors()).To(BeEmpty(), v.Expression)
+		}
+		Expect(expressions).To(Equal(map[string]string{
+			"spec must set size": `!has(object.spec) || has(object.spec.size)`,
+			"spec.size must be at least 1": `!has(object.spec) || !has(object.spec.size) || ` +
+				`object.spec.size >= 1`,
+			"spec.size must be at most 10": `!has(object.spec) || !has(object.spec.size) || ` +
+				`object.spec.size <= 10`,
+			"spec.ratio must be less than 1": `!has(object.spec) || !has(object.spec.ratio) || ` +
+				`object.spec.ratio < 1.0`,
+			"spec.image-tag must be at least 1 characters": `!has(object.spec) || ` +
+				`!has(object.spec.image__dash__tag) || size(object.spec.image__dash__tag) >= 1`,
+			`spec.image-tag must match "^v\\d+$"`: `!has(object.spec) || !has(object.spec.image__dash__tag) || ` +
+				`object.spec.image__dash__tag.matches("^v\\d+$")`,
+			`spec.mode must be one of ["fast", "safe"]`: `!has(object.spec) || !has(object.spec.mode) || ` +
+				`object.spec.mode in ["fast", "safe"]`,
+			"spec.nodes must have at most 3 items": `!has(object.spec) || !has(object.spec.nodes) || ` +
+				`size(object.spec.nodes) <= 3`,
+			"spec.namespace must be at most 63 characters": `!has(object.spec) || ` +
+				`!has(object.spec.__namespace__) || size(object.spec.__namespace__) <= 63`,
+		}))
+	})
+})
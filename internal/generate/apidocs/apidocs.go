@@ -0,0 +1,384 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apidocs generates API reference documentation for custom resources from the OpenAPI
+// schemas of their CRDs, so user docs stay in sync with an operator's APIs.
+package apidocs
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// Output formats.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// CSVSectionHeading is the heading of the section of a CSV description that links to API docs.
+const CSVSectionHeading = "## API Reference"
+
+// Doc is the reference documentation of a custom resource kind.
+type Doc struct {
+	Kind   string
+	Group  string
+	Plural string
+	Scope  string
+	// Versions are sorted by Kubernetes version priority, ex. v1 before v1beta1.
+	Versions []Version
+}
+
+// Version documents a served version of a kind.
+type Version struct {
+	Name        string
+	Storage     bool
+	Description string
+	Fields      []Field
+	// Example is a sample custom resource of the version in YAML, if one was found.
+	Example string
+}
+
+// Field is a schema property of a custom resource.
+type Field struct {
+	// Path is the dot-separated path of the field, with "[]" after array fields, ex. "spec.ports[].name".
+	Path        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// FileName returns the name of d's documentation file in format, which is named like the CRD
+// files generated by controller-gen, ex. "cache.example.com_memcacheds.md".
+func (d Doc) FileName(format string) string {
+	ext := ".md"
+	if format == FormatHTML {
+		ext = ".html"
+	}
+	return d.Group + "_" + d.Plural + ext
+}
+
+// DocsFromCRDs returns a Doc for each kind defined by the v1 and v1beta1 CRDs in crdsDir, sorted
+// by group and kind. Custom resources in samplesDir are added as examples of their versions.
+func DocsFromCRDs(crdsDir, samplesDir string) (docs []Doc, err error) {
+	v1crds, v1beta1crds, err := k8sutil.GetCustomResourceDefinitions(crdsDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CRDs from %s: %v", crdsDir, err)
+	}
+	for _, crd := range v1beta1crds {
+		converted, err := k8sutil.Convertv1beta1Tov1CustomResourceDefinition(&crd)
+		if err != nil {
+			return nil, fmt.Errorf("error converting CRD %s to v1: %v", crd.GetName(), err)
+		}
+		v1crds = append(v1crds, *converted)
+	}
+	examples, err := readExamples(samplesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, crd := range v1crds {
+		doc := Doc{
+			Kind:   crd.Spec.Names.Kind,
+			Group:  crd.Spec.Group,
+			Plural: crd.Spec.Names.Plural,
+			Scope:  string(crd.Spec.Scope),
+		}
+		for _, v := range crd.Spec.Versions {
+			if !v.Served {
+				continue
+			}
+			ver := Version{Name: v.Name, Storage: v.Storage}
+			if v.Schema != nil && v.Schema.OpenAPIV3Schema != nil {
+				ver.Description = v.Schema.OpenAPIV3Schema.Description
+				ver.Fields = fields(*v.Schema.OpenAPIV3Schema)
+			}
+			ver.Example = examples[schema.GroupVersionKind{Group: doc.Group, Version: v.Name, Kind: doc.Kind}]
+			doc.Versions = append(doc.Versions, ver)
+		}
+		sort.Slice(doc.Versions, func(i, j int) bool {
+			return version.CompareKubeAwareVersionStrings(doc.Versions[i].Name, doc.Versions[j].Name) > 0
+		})
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].Group != docs[j].Group {
+			return docs[i].Group < docs[j].Group
+		}
+		return docs[i].Kind < docs[j].Kind
+	})
+	return docs, nil
+}
+
+// readExamples returns the first custom resource in samplesDir of each GVK, marshaled to YAML.
+// Samples are optional, so a missing samplesDir yields no examples.
+func readExamples(samplesDir string) (map[schema.GroupVersionKind]string, error) {
+	examples := map[schema.GroupVersionKind]string{}
+	paths, err := filepath.Glob(filepath.Join(samplesDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading sample %s: %v", path, err)
+		}
+		scanner := k8sutil.NewYAMLScanner(bytes.NewBuffer(b))
+		for scanner.Scan() {
+			u := unstructured.Unstructured{}
+			if err := yaml.Unmarshal(scanner.Bytes(), &u.Object); err != nil {
+				return nil, fmt.Errorf("error unmarshaling sample %s: %v", path, err)
+			}
+			gvk := u.GroupVersionKind()
+			if _, ok := examples[gvk]; ok || gvk.Kind == "" {
+				continue
+			}
+			examples[gvk] = strings.TrimSpace(string(scanner.Bytes()))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error scanning sample %s: %v", path, err)
+		}
+	}
+	return examples, nil
+}
+
+// fields returns the fields of a custom resource's root schema, depth-first in property name order.
+// apiVersion, kind, and metadata are common to all objects and omitted.
+func fields(root apiextv1.JSONSchemaProps) (fs []Field) {
+	for _, name := range propertyNames(root) {
+		switch name {
+		case "apiVersion", "kind", "metadata":
+			continue
+		}
+		fs = appendFields(fs, name, root.Properties[name], contains(root.Required, name))
+	}
+	return fs
+}
+
+func appendFields(fs []Field, path string, s apiextv1.JSONSchemaProps, required bool) []Field {
+	fs = append(fs, Field{
+		Path:        path,
+		Type:        typeOf(s),
+		Required:    required,
+		Description: describe(s),
+	})
+	for _, name := range propertyNames(s) {
+		fs = appendFields(fs, path+"."+name, s.Properties[name], contains(s.Required, name))
+	}
+	if s.Items != nil && s.Items.Schema != nil {
+		items := *s.Items.Schema
+		for _, name := range propertyNames(items) {
+			fs = appendFields(fs, path+"[]."+name, items.Properties[name], contains(items.Required, name))
+		}
+	}
+	return fs
+}
+
+// typeOf returns a Go-like description of s's type, ex. "[]string" or "map[string]integer".
+func typeOf(s apiextv1.JSONSchemaProps) string {
+	t := s.Type
+	switch {
+	case s.XIntOrString:
+		t = "integer or string"
+	case s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields && len(s.Properties) == 0:
+		t = "object (arbitrary)"
+	case t == "array" && s.Items != nil && s.Items.Schema != nil:
+		t = "[]" + typeOf(*s.Items.Schema)
+	case t == "object" && s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+		t = "map[string]" + typeOf(*s.AdditionalProperties.Schema)
+	case t == "":
+		t = "any"
+	}
+	if s.Format != "" {
+		t += " (" + s.Format + ")"
+	}
+	return t
+}
+
+// describe returns s's description followed by its enum values, default, and bounds.
+func describe(s apiextv1.JSONSchemaProps) string {
+	parts := []string{strings.TrimSpace(s.Description)}
+	if len(s.Enum) != 0 {
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = string(v.Raw)
+		}
+		parts = append(parts, "Allowed values: "+strings.Join(values, ", ")+".")
+	}
+	if s.Default != nil {
+		parts = append(parts, "Default: "+string(s.Default.Raw)+".")
+	}
+	if s.Minimum != nil {
+		parts = append(parts, fmt.Sprintf("Minimum: %v.", *s.Minimum))
+	}
+	if s.Maximum != nil {
+		parts = append(parts, fmt.Sprintf("Maximum: %v.", *s.Maximum))
+	}
+	if s.Pattern != "" {
+		parts = append(parts, "Pattern: `"+s.Pattern+"`.")
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+func propertyNames(s apiextv1.JSONSchemaProps) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+var funcs = map[string]interface{}{
+	// cell makes a string safe to use in a Markdown table cell.
+	"cell": func(s string) string {
+		s = strings.ReplaceAll(s, "|", `\|`)
+		return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+	},
+}
+
+var markdownTemplate = template.Must(template.New("").Funcs(funcs).Parse(`# {{ .Kind }}
+
+Group: ` + "`{{ .Group }}`" + `, scope: {{ .Scope }}, versions: {{ range $i, $v := .Versions }}{{ if $i }}, {{ end }}` +
+	"`{{ $v.Name }}`" + `{{ end }}
+{{ range .Versions }}
+## {{ $.Kind }} {{ .Name }}{{ if .Storage }} (storage version){{ end }}
+{{ with .Description }}
+{{ . }}
+{{ end }}{{ if .Fields }}
+| Field | Type | Required | Description |
+| ----- | ---- | -------- | ----------- |
+{{ range .Fields }}| ` + "`{{ .Path }}`" + ` | {{ cell .Type }} | {{ if .Required }}yes{{ else }}no{{ end }} | {{ cell .Description }} |
+{{ end }}{{ end }}{{ with .Example }}
+### Example
+
+` + "```yaml" + `
+{{ . }}
+` + "```" + `
+{{ end }}{{ end }}`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Kind }} API Reference</title>
+</head>
+<body>
+<h1>{{ .Kind }}</h1>
+<p>Group: <code>{{ .Group }}</code>, scope: {{ .Scope }}</p>
+{{- range .Versions }}
+<h2 id="{{ .Name }}">{{ $.Kind }} {{ .Name }}{{ if .Storage }} (storage version){{ end }}</h2>
+{{- with .Description }}
+<p>{{ . }}</p>
+{{- end }}
+{{- if .Fields }}
+<table>
+<thead><tr><th>Field</th><th>Type</th><th>Required</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .Fields }}
+<tr><td><code>{{ .Path }}</code></td><td>{{ .Type }}</td><td>{{ if .Required }}yes{{ else }}no{{ end }}</td><td>{{ .Description }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- end }}
+{{- with .Example }}
+<h3>Example</h3>
+<pre><code>{{ . }}</code></pre>
+{{- end }}
+{{- end }}
+</body>
+</html>
+`))
+
+// Render returns d's documentation in format.
+func Render(d Doc, format string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	var err error
+	switch format {
+	case FormatMarkdown:
+		err = markdownTemplate.Execute(buf, d)
+	case FormatHTML:
+		err = htmlTemplate.Execute(buf, d)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error rendering docs for %s: %v", d.Kind, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// EmbedLinks sets the CSVSectionHeading section of csv's description to a list of links to the docs
+// of each kind, with file names relative to baseURL. An existing section is replaced, so links stay
+// in sync when kinds are added or removed.
+func EmbedLinks(csv *v1alpha1.ClusterServiceVersion, docs []Doc, baseURL, format string) {
+	section := &strings.Builder{}
+	section.WriteString(CSVSectionHeading + "\n\n")
+	for _, d := range docs {
+		fmt.Fprintf(section, "* [%s](%s/%s)\n", d.Kind, strings.TrimSuffix(baseURL, "/"), d.FileName(format))
+	}
+
+	lines := strings.Split(csv.Spec.Description, "\n")
+	start, end := -1, len(lines)
+	for i, line := range lines {
+		if start < 0 && strings.TrimSpace(line) == CSVSectionHeading {
+			start = i
+		} else if start >= 0 && isH1OrH2(line) {
+			end = i
+			break
+		}
+	}
+	if start < 0 {
+		description := strings.TrimRight(csv.Spec.Description, "\n")
+		if description != "" {
+			description += "\n\n"
+		}
+		csv.Spec.Description = description + section.String()
+		return
+	}
+	rest := strings.Join(lines[end:], "\n")
+	if rest != "" {
+		rest = "\n" + rest
+	}
+	csv.Spec.Description = strings.Join(lines[:start], "\n") + "\n" + section.String() + rest
+	csv.Spec.Description = strings.TrimPrefix(csv.Spec.Description, "\n")
+}
+
+func isH1OrH2(line string) bool {
+	return strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "## ")
+}
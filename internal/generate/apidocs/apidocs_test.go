@@ -0,0 +1,167 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apidocs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+const crd = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Memcached
+    plural: memcacheds
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        description: Memcached is a Memcached cluster.
+        type: object
+        properties:
+          apiVersion:
+            type: string
+          spec:
+            type: object
+            required:
+            - size
+            properties:
+              size:
+                description: Size is the number of | separated replicas.
+                type: integer
+                format: int32
+                minimum: 1
+              ports:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    name:
+                      type: string
+                      enum: ["http", "metrics"]
+`
+
+const sample = `apiVersion: cache.example.com/v1
+kind: Memcached
+metadata:
+  name: memcached-sample
+spec:
+  size: 3
+`
+
+var _ = Describe("Docs", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "apidocs-")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(dir, "crds"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(dir, "samples"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "crds", "memcacheds.yaml"), []byte(crd), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "samples", "memcached.yaml"), []byte(sample), 0644)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	Describe("DocsFromCRDs", func() {
+		It("documents the fields and examples of each served version", func() {
+			docs, err := DocsFromCRDs(filepath.Join(dir, "crds"), filepath.Join(dir, "samples"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(docs).To(HaveLen(1))
+			Expect(docs[0].FileName(FormatMarkdown)).To(Equal("cache.example.com_memcacheds.md"))
+			Expect(docs[0].Versions).To(HaveLen(2))
+
+			v1 := docs[0].Versions[0]
+			Expect(v1.Name).To(Equal("v1"))
+			Expect(v1.Description).To(Equal("Memcached is a Memcached cluster."))
+			Expect(v1.Example).To(Equal(sample[:len(sample)-1]))
+			Expect(v1.Fields).To(Equal([]Field{
+				{Path: "spec", Type: "object"},
+				{Path: "spec.ports", Type: "[]object"},
+				{Path: "spec.ports[].name", Type: "string", Description: `Allowed values: "http", "metrics".`},
+				{Path: "spec.size", Type: "integer (int32)", Required: true,
+					Description: "Size is the number of | separated replicas. Minimum: 1."},
+			}))
+			Expect(docs[0].Versions[1].Example).To(BeEmpty())
+		})
+		It("returns an error if the CRDs directory does not exist", func() {
+			_, err := DocsFromCRDs(filepath.Join(dir, "missing"), filepath.Join(dir, "samples"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Render", func() {
+		It("renders Markdown tables with escaped cells", func() {
+			docs, err := DocsFromCRDs(filepath.Join(dir, "crds"), filepath.Join(dir, "samples"))
+			Expect(err).NotTo(HaveOccurred())
+			b, err := Render(docs[0], FormatMarkdown)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(HavePrefix("# Memcached\n\nGroup: `cache.example.com`, scope: Namespaced, " +
+				"versions: `v1`, `v1alpha1`\n\n## Memcached v1 (storage version)\n"))
+			Expect(string(b)).To(ContainSubstring(
+				"| `spec.size` | integer (int32) | yes | Size is the number of \\| separated replicas. Minimum: 1. |\n"))
+			Expect(string(b)).To(ContainSubstring("### Example\n\n```yaml\napiVersion: cache.example.com/v1\n"))
+		})
+		It("renders HTML", func() {
+			docs, err := DocsFromCRDs(filepath.Join(dir, "crds"), filepath.Join(dir, "samples"))
+			Expect(err).NotTo(HaveOccurred())
+			b, err := Render(docs[0], FormatHTML)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(ContainSubstring("<h2 id=\"v1\">Memcached v1 (storage version)</h2>"))
+			Expect(string(b)).To(ContainSubstring("<td>Allowed values: &#34;http&#34;, &#34;metrics&#34;.</td>"))
+		})
+	})
+
+	Describe("EmbedLinks", func() {
+		docs := []Doc{{Kind: "Memcached", Group: "cache.example.com", Plural: "memcacheds"}}
+		links := "## API Reference\n\n* [Memcached](https://example.com/api/cache.example.com_memcacheds.md)\n"
+
+		It("appends a section to the description", func() {
+			csv := &v1alpha1.ClusterServiceVersion{}
+			csv.Spec.Description = "An operator.\n"
+			EmbedLinks(csv, docs, "https://example.com/api/", FormatMarkdown)
+			Expect(csv.Spec.Description).To(Equal("An operator.\n\n" + links))
+		})
+		It("replaces an existing section", func() {
+			csv := &v1alpha1.ClusterServiceVersion{}
+			csv.Spec.Description = "An operator.\n\n## API Reference\n\n* [Old](old.md)\n\n## Support\nFile issues."
+			EmbedLinks(csv, docs, "https://example.com/api", FormatMarkdown)
+			Expect(csv.Spec.Description).To(Equal("An operator.\n\n" + links + "\n## Support\nFile issues."))
+
+			EmbedLinks(csv, docs, "https://example.com/api", FormatMarkdown)
+			Expect(csv.Spec.Description).To(Equal("An operator.\n\n" + links + "\n## Support\nFile issues."))
+		})
+	})
+})
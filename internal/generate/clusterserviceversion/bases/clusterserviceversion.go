@@ -46,6 +46,8 @@ type ClusterServiceVersion struct {
 	GVKs []schema.GroupVersionKind
 	// Interactive turns on an interactive prompt.
 	Interactive bool
+	// InstallModes are set in a new base. If empty, only AllNamespaces is supported.
+	InstallModes []v1alpha1.InstallMode
 
 	// Fields for input to the base.
 	DisplayName  string
@@ -126,6 +128,14 @@ func (b *ClusterServiceVersion) setDefaults() {
 			URL:  "https://your.domain",
 		}
 	}
+	if len(b.InstallModes) == 0 {
+		b.InstallModes = []v1alpha1.InstallMode{
+			{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: false},
+			{Type: v1alpha1.InstallModeTypeSingleNamespace, Supported: false},
+			{Type: v1alpha1.InstallModeTypeMultiNamespace, Supported: false},
+			{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+		}
+	}
 	if len(b.Maintainers) == 0 || b.Maintainers[0] == (v1alpha1.Maintainer{}) {
 		b.Maintainers = []v1alpha1.Maintainer{
 			{
@@ -152,20 +162,15 @@ func (b ClusterServiceVersion) makeNewBase() *v1alpha1.ClusterServiceVersion {
 			},
 		},
 		Spec: v1alpha1.ClusterServiceVersionSpec{
-			DisplayName: b.DisplayName,
-			Description: b.Description,
-			Provider:    b.Provider,
-			Maintainers: b.Maintainers,
-			Links:       b.Links,
-			Maturity:    b.Maturity,
-			Keywords:    b.Keywords,
-			Icon:        b.Icon,
-			InstallModes: []v1alpha1.InstallMode{
-				{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: false},
-				{Type: v1alpha1.InstallModeTypeSingleNamespace, Supported: false},
-				{Type: v1alpha1.InstallModeTypeMultiNamespace, Supported: false},
-				{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
-			},
+			DisplayName:  b.DisplayName,
+			Description:  b.Description,
+			Provider:     b.Provider,
+			Maintainers:  b.Maintainers,
+			Links:        b.Links,
+			Maturity:     b.Maturity,
+			Keywords:     b.Keywords,
+			Icon:         b.Icon,
+			InstallModes: b.InstallModes,
 		},
 	}
 }
@@ -0,0 +1,46 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bases
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+var _ = Describe("ClusterServiceVersion", func() {
+	newBase := func(installModes []v1alpha1.InstallMode) *v1alpha1.ClusterServiceVersion {
+		b := ClusterServiceVersion{OperatorName: "memcached-operator", InstallModes: installModes}
+		b.setDefaults()
+		return b.makeNewBase()
+	}
+
+	It("supports only AllNamespaces in new bases of cluster-scoped projects", func() {
+		Expect(newBase(nil).Spec.InstallModes).To(Equal([]v1alpha1.InstallMode{
+			{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: false},
+			{Type: v1alpha1.InstallModeTypeSingleNamespace, Supported: false},
+			{Type: v1alpha1.InstallModeTypeMultiNamespace, Supported: false},
+			{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+		}))
+	})
+	It("sets the install modes of new bases of namespace-scoped projects", func() {
+		installModes := []v1alpha1.InstallMode{
+			{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+			{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: false},
+		}
+		Expect(newBase(installModes).Spec.InstallModes).To(Equal(installModes))
+	})
+})
@@ -16,6 +16,7 @@ package definitions
 
 import (
 	"fmt"
+	"go/ast"
 	"reflect"
 	"sort"
 	"strings"
@@ -130,12 +131,58 @@ func (g generator) buildCRDDescriptionFromType(gvk schema.GroupVersionKind, kind
 			}
 		}
 	}
+	if descriptor, include := g.conditionsStatusDescriptor(status, statusDescriptors); include {
+		statusDescriptors = append(statusDescriptors, descriptor)
+	}
 	sortDescriptors(statusDescriptors)
 	description.StatusDescriptors = statusDescriptors
 
 	return description, nil
 }
 
+const (
+	// conditionsPath is the conventional path of a list of conditions in status.
+	conditionsPath = "conditions"
+	// conditionsXDescriptor is the UI path displaying a list of conditions.
+	conditionsXDescriptor = "urn:alm:descriptor:io.kubernetes.conditions"
+)
+
+// conditionsStatusDescriptor returns a statusDescriptor displaying the conditions of status, since conditions
+// are conventionally displayed whether or not their field is marked. It returns false if status has no
+// top-level conditions list, or it is already described by statusDescriptors.
+func (g generator) conditionsStatusDescriptor(status markers.FieldInfo,
+	statusDescriptors []v1alpha1.StatusDescriptor) (descriptor v1alpha1.StatusDescriptor, include bool) {
+
+	for _, d := range statusDescriptors {
+		if d.Path == conditionsPath {
+			return descriptor, false
+		}
+	}
+	typeName, isIdent := status.RawField.Type.(*ast.Ident)
+	if !isIdent {
+		return descriptor, false
+	}
+	info, hasInfo := g.types[typeName.Name]
+	if !hasInfo {
+		return descriptor, false
+	}
+	for _, field := range info.Fields {
+		if _, isArray := field.RawField.Type.(*ast.ArrayType); !isArray {
+			continue
+		}
+		if segment, err := getPathSegmentForField(field); err != nil || segment != conditionsPath {
+			continue
+		}
+		return v1alpha1.StatusDescriptor{
+			Description:  field.Doc,
+			Path:         conditionsPath,
+			DisplayName:  k8sutil.GetDisplayName(field.Name),
+			XDescriptors: []string{conditionsXDescriptor},
+		}, true
+	}
+	return descriptor, false
+}
+
 // findChildForDescType returns a field with a tag matching string(typ) by searching all top-level fields in info.
 // If no field is found, an error is returned.
 func findChildForDescType(info *markers.TypeInfo, typ descType) (markers.FieldInfo, error) {
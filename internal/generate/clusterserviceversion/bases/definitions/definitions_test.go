@@ -134,6 +134,70 @@ func TestApplyDefinitionsForKeysGo(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "Populate CRDDescription with unmarked status conditions successfully",
+			apisDir:     "api",
+			csv:         &v1alpha1.ClusterServiceVersion{},
+			gvks: []schema.GroupVersionKind{
+				{Group: "cache.example.com", Version: "v1alpha2", Kind: "ConditionedDummy"},
+			},
+			expectedCRDs: v1alpha1.CustomResourceDefinitions{
+				Owned: []v1alpha1.CRDDescription{
+					{
+						Name:        "conditioneddummies.cache.example.com",
+						Kind:        "ConditionedDummy",
+						Version:     "v1alpha2",
+						DisplayName: "Conditioned Dummy",
+						Description: "ConditionedDummy is the Schema for the conditioned dummy API",
+						SpecDescriptors: []v1alpha1.SpecDescriptor{
+							{Path: "size", DisplayName: "dummy-size", Description: "Should be in spec",
+								XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:podCount"}},
+							{Path: "wheels", DisplayName: "Wheels",
+								Description:  "Should be in spec, but should not have array index in path",
+								XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:text"}},
+							{Path: "wheels[0].type", DisplayName: "Wheel Type",
+								Description: "Type should be in spec with path equal to wheels[0].type",
+								XDescriptors: []string{
+									"urn:alm:descriptor:com.tectonic.ui:arrayFieldGroup:wheels",
+									"urn:alm:descriptor:com.tectonic.ui:text",
+								}},
+						},
+						StatusDescriptors: []v1alpha1.StatusDescriptor{
+							{Path: "conditions", DisplayName: "Conditions",
+								Description:  "Should be in status, since conditions are displayed whether or not they are marked",
+								XDescriptors: []string{"urn:alm:descriptor:io.kubernetes.conditions"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "Populate CRDDescription with marked status conditions successfully",
+			apisDir:     "api",
+			csv:         &v1alpha1.ClusterServiceVersion{},
+			gvks: []schema.GroupVersionKind{
+				{Group: "cache.example.com", Version: "v1alpha2", Kind: "MarkedConditionsDummy"},
+			},
+			expectedCRDs: v1alpha1.CustomResourceDefinitions{
+				Owned: []v1alpha1.CRDDescription{
+					{
+						Name:        "markedconditionsdummies.cache.example.com",
+						Kind:        "MarkedConditionsDummy",
+						Version:     "v1alpha2",
+						DisplayName: "Marked Conditions Dummy",
+						Description: "MarkedConditionsDummy is the Schema for the marked conditions dummy API",
+						SpecDescriptors: []v1alpha1.SpecDescriptor{
+							{Path: "nothing", DisplayName: "Nothing", XDescriptors: []string{},
+								Description: "Should be in status but not spec, since this isn't a spec type"},
+						},
+						StatusDescriptors: []v1alpha1.StatusDescriptor{
+							{Path: "conditions", DisplayName: "Dummy Conditions", XDescriptors: []string{},
+								Description: "Should be in status once, with its own descriptors"},
+						},
+					},
+				},
+			},
+		},
 		{
 			description: "Do not change definitions with non-existent package dir",
 			apisDir:     filepath.Join("pkg", "notexist"),
@@ -15,10 +15,13 @@
 package bases
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
 
+	"github.com/operator-framework/operator-sdk/internal/util/operatorhub"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 )
 
@@ -38,6 +41,12 @@ type uiMetadata struct {
 	Keywords []string
 	// Maintainers is the list of organizational entities maintaining the operator.
 	Maintainers []string
+	// Categories are the OperatorHub.io categories the operator is listed under.
+	Categories []string
+	// Capabilities is the operator's capability level, ex. "Basic Install".
+	Capabilities string
+	// Support is the name of the entity supporting the operator.
+	Support string
 	// FEAT: read icon bytes from files.
 }
 
@@ -50,6 +59,27 @@ func (s *uiMetadata) runInteractivePrompt() {
 	s.Keywords = projutil.GetStringArray("Comma-separated list of keywords for your operator")
 	s.Maintainers = projutil.GetStringArray("Comma-separated list of maintainers and their emails" +
 		" (e.g. 'name1:email1, name2:email2')")
+	s.Categories = validCategories(operatorhub.SplitCategories(projutil.GetOptionalInput(
+		fmt.Sprintf("Comma-separated list of categories, any of: %s", strings.Join(operatorhub.DefaultCategories, ", ")))))
+	s.Capabilities = projutil.GetOptionalInput(fmt.Sprintf("Capability level, one of: %s",
+		strings.Join(operatorhub.Capabilities, ", ")))
+	if s.Capabilities != "" && !operatorhub.Contains(operatorhub.Capabilities, s.Capabilities) {
+		log.Warnf("Ignoring unknown capability level %q", s.Capabilities)
+		s.Capabilities = ""
+	}
+	s.Support = projutil.GetOptionalInput("Name of the entity supporting the operator")
+}
+
+// validCategories returns the OperatorHub.io categories in categories, warning about others.
+func validCategories(categories []string) (valid []string) {
+	for _, c := range categories {
+		if operatorhub.Contains(operatorhub.DefaultCategories, c) {
+			valid = append(valid, c)
+		} else {
+			log.Warnf("Ignoring category %q not listed by OperatorHub.io", c)
+		}
+	}
+	return valid
 }
 
 // apply populates the CSV with the data in s.
@@ -79,6 +109,21 @@ func (s uiMetadata) apply(csv *v1alpha1.ClusterServiceVersion) {
 		csv.Spec.Maintainers = maintainers
 	}
 
+	annotations := csv.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if len(s.Categories) != 0 {
+		annotations[operatorhub.CategoriesAnnotation] = operatorhub.JoinCategories(s.Categories)
+	}
+	if s.Capabilities != "" {
+		annotations[operatorhub.CapabilitiesAnnotation] = s.Capabilities
+	}
+	if s.Support != "" {
+		annotations[operatorhub.SupportAnnotation] = s.Support
+	}
+	csv.SetAnnotations(annotations)
+
 	if s.ProviderName != "" {
 		provider := v1alpha1.AppLink{}
 		provider.Name = s.ProviderName
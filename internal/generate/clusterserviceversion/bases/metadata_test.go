@@ -38,6 +38,9 @@ var _ = Describe("Metadata", func() {
 		ProviderName: "Example",
 		ProviderURL:  "www.example.com",
 		Maintainers:  []string{"Some Corp:corp@example.com"},
+		Categories:   []string{"Database", "Monitoring"},
+		Capabilities: "Seamless Upgrades",
+		Support:      "Example",
 	}
 
 	It("populates an empty CSV", func() {
@@ -50,6 +53,11 @@ var _ = Describe("Metadata", func() {
 		Expect(csv.Spec.Description).To(Equal(meta.Description))
 		Expect(csv.Spec.Maintainers).To(Equal([]v1alpha1.Maintainer{{Name: "Some Corp", Email: "corp@example.com"}}))
 		Expect(csv.Spec.Provider).To(Equal(v1alpha1.AppLink{Name: meta.ProviderName, URL: meta.ProviderURL}))
+		Expect(csv.GetAnnotations()).To(Equal(map[string]string{
+			"categories":   "Database,Monitoring",
+			"capabilities": "Seamless Upgrades",
+			"support":      "Example",
+		}))
 	})
 
 	It("populates a CSV with existing values", func() {
@@ -64,5 +72,10 @@ var _ = Describe("Metadata", func() {
 		Expect(csv.Spec.Description).To(Equal(meta.Description))
 		Expect(csv.Spec.Maintainers).To(Equal([]v1alpha1.Maintainer{{Name: "Some Corp", Email: "corp@example.com"}}))
 		Expect(csv.Spec.Provider).To(Equal(v1alpha1.AppLink{Name: meta.ProviderName, URL: meta.ProviderURL}))
+		Expect(csv.GetAnnotations()).To(HaveKeyWithValue("capabilities", "Seamless Upgrades"))
+	})
+
+	It("keeps only OperatorHub.io categories", func() {
+		Expect(validCategories([]string{"Database", "Games"})).To(Equal([]string{"Database"}))
 	})
 })
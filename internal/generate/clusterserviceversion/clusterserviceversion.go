@@ -30,6 +30,8 @@ import (
 	metricsannotations "github.com/operator-framework/operator-sdk/internal/annotations/metrics"
 	"github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion/bases"
 	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+	"github.com/operator-framework/operator-sdk/internal/generate/features"
+	"github.com/operator-framework/operator-sdk/internal/generate/i18n"
 	genutil "github.com/operator-framework/operator-sdk/internal/generate/internal"
 	"github.com/operator-framework/operator-sdk/internal/util/projutil"
 )
@@ -59,6 +61,20 @@ type Generator struct {
 	FromVersion string
 	// Collector holds all manifests relevant to the Generator.
 	Collector *collector.Manifests
+	// InstallModes are set in a newly generated base.
+	InstallModes []operatorsv1alpha1.InstallMode
+	// ScopePermissions places rules in the CSV's permissions or clusterPermissions by the scope of their
+	// resources, instead of by the kind of role they are in, for operators installed in their own namespace.
+	ScopePermissions bool
+	// FeatureGates are documented in the CSV's feature gates annotation.
+	FeatureGates []features.Gate
+	// Translations are the CSV's UI metadata by locale. The CSV is localized to Locale if set,
+	// otherwise all translations are written to the CSV's translations annotation.
+	Translations map[string]i18n.Translation
+	Locale       string
+	// OnConflict resolves conflicts between edits made to the bundled CSV since it was last generated
+	// and generated changes to the same fields. Defaults to failing.
+	OnConflict ConflictStrategy
 
 	// Project configuration.
 	config *config.Config
@@ -70,6 +86,9 @@ type Generator struct {
 	// CSV. Used to bring over data from an existing CSV that is not captured
 	// in a base. Not set if a non-file or base writer is returned by getWriter.
 	bundledPath string
+	// If set, the path the generated CSV is recorded at, which is the base of the three-way merge
+	// of edits made to the CSV at bundledPath with the next generated CSV.
+	lastGeneratedPath string
 }
 
 // Type of Generator.getBase.
@@ -121,6 +140,7 @@ func WithBundleWriter(dir string) Option {
 	return func(g *Generator) error {
 		fileName := makeCSVFileName(g.OperatorName)
 		g.bundledPath = filepath.Join(dir, bundle.ManifestsDir, fileName)
+		g.lastGeneratedPath = filepath.Join(dir, lastGeneratedDir, fileName)
 		g.getWriter = func() (io.Writer, error) {
 			return genutil.Open(filepath.Join(dir, bundle.ManifestsDir), fileName)
 		}
@@ -164,11 +184,29 @@ func (g *Generator) Generate(cfg *config.Config, opts ...Option) (err error) {
 	// Add sdk labels to csv
 	g.setSDKAnnotations(csv)
 
+	// Keep edits made to the bundled CSV since it was last generated.
+	merged := csv
+	if g.lastGeneratedPath != "" {
+		if merged, err = g.mergeEdits(csv); err != nil {
+			return err
+		}
+	}
+
 	w, err := g.getWriter()
 	if err != nil {
 		return err
 	}
-	return genutil.WriteObject(w, csv)
+	if err := genutil.WriteObject(w, merged); err != nil {
+		return err
+	}
+	if g.lastGeneratedPath == "" {
+		return nil
+	}
+	f, err := genutil.Open(filepath.Dir(g.lastGeneratedPath), filepath.Base(g.lastGeneratedPath))
+	if err != nil {
+		return err
+	}
+	return genutil.WriteObject(f, csv)
 }
 
 // setSDKAnnotations adds SDK metric labels to the base if they do not exist.
@@ -203,6 +241,23 @@ func (g *Generator) generate() (*operatorsv1alpha1.ClusterServiceVersion, error)
 		if err := ApplyTo(g.Collector, base); err != nil {
 			return nil, err
 		}
+		if g.ScopePermissions {
+			scopePermissions(g.Collector, base)
+		}
+		if err := features.SetAnnotation(base, g.FeatureGates); err != nil {
+			return nil, err
+		}
+		if g.Locale != "" {
+			if err := i18n.Localize(base, g.Translations, g.Locale); err != nil {
+				return nil, err
+			}
+			err = i18n.SetAnnotation(base, nil)
+		} else {
+			err = i18n.SetAnnotation(base, g.Translations)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return base, nil
@@ -241,6 +296,7 @@ func (g Generator) makeBaseGetter(basePath, apisDir string, interactive bool) ge
 			APIsDir:      apisDir,
 			GVKs:         gvks,
 			Interactive:  interactive,
+			InstallModes: g.InstallModes,
 		}
 		return b.GetBase()
 	}
@@ -67,8 +67,7 @@ func apply(c *collector.Manifests, csv *operatorsv1alpha1.ClusterServiceVersion)
 	if err := applyCustomResources(c, csv); err != nil {
 		return fmt.Errorf("error applying Custom Resource examples to CSV %s: %v", csv.GetName(), err)
 	}
-	applyWebhooks(c, csv)
-	return nil
+	return applyWebhooks(c, csv)
 }
 
 // Get install strategy from csv.
@@ -266,8 +265,9 @@ func applyCustomResourceDefinitions(c *collector.Manifests, csv *operatorsv1alph
 	csv.Spec.CustomResourceDefinitions.Owned = ownedDescs
 }
 
-// applyWebhooks updates csv's webhookDefinitions with any mutating and validating webhooks in the collector.
-func applyWebhooks(c *collector.Manifests, csv *operatorsv1alpha1.ClusterServiceVersion) {
+// applyWebhooks updates csv's webhookDefinitions with any mutating, validating, and conversion webhooks
+// in the collector.
+func applyWebhooks(c *collector.Manifests, csv *operatorsv1alpha1.ClusterServiceVersion) error {
 	webhookDescriptions := []operatorsv1alpha1.WebhookDescription{}
 	for _, webhook := range c.ValidatingWebhooks {
 		depName, serviceName := findMatchingDeploymentAndServiceForWebhook(c, webhook.ClientConfig)
@@ -287,7 +287,149 @@ func applyWebhooks(c *collector.Manifests, csv *operatorsv1alpha1.ClusterService
 		}
 		webhookDescriptions = append(webhookDescriptions, mutatingToWebhookDescription(webhook, depName))
 	}
+	conversionDescriptions, err := conversionWebhookDescriptions(c, csv)
+	if err != nil {
+		return err
+	}
+	webhookDescriptions = append(webhookDescriptions, conversionDescriptions...)
 	csv.Spec.WebhookDefinitions = webhookDescriptions
+	return nil
+}
+
+// ConversionWebhook is the webhook type of CRD conversion webhooks, which OLM supports in newer versions
+// of the CSV API than this one.
+const ConversionWebhook operatorsv1alpha1.WebhookAdmissionType = "ConversionWebhook"
+
+// certManagerInjectAnnotation tells the cert-manager CA injector which Certificate's CA to inject.
+const certManagerInjectAnnotation = "cert-manager.io/inject-ca-from"
+
+// conversionWebhookDescriptions returns a ConversionWebhook description per webhook Service endpoint
+// converting CRDs in the collector. OLM provisions the serving certificate of each description,
+// mounts it in the serving deployment, injects its CA into the conversionCRDs, and rotates the
+// certificate before it expires, so these CRDs must not also have their CA injected by cert-manager.
+// OLM only installs conversion webhooks of operators supporting only the AllNamespaces install mode,
+// so an error is returned if csv supports other install modes.
+func conversionWebhookDescriptions(c *collector.Manifests, csv *operatorsv1alpha1.ClusterServiceVersion) (descriptions []operatorsv1alpha1.WebhookDescription, err error) {
+	byEndpoint := make(map[string]int)
+	for _, crd := range conversionCRDs(c) {
+		if _, hasAnnotation := crd.annotations[certManagerInjectAnnotation]; hasAnnotation {
+			log.Warnf("CRD %q has a cert-manager CA injection annotation, but OLM injects the CA of its "+
+				"conversion webhook; remove the annotation from the CRD", crd.name)
+		}
+
+		wcc := admissionregv1.WebhookClientConfig{Service: crd.service}
+		depName, serviceName := findMatchingDeploymentAndServiceForWebhook(c, wcc)
+		if serviceName == "" && depName == "" {
+			log.Infof("No service found for conversion webhook of CRD %q", crd.name)
+		} else if depName == "" {
+			log.Infof("No deployment is selected by service %q for conversion webhook of CRD %q", serviceName, crd.name)
+		}
+		description := conversionToWebhookDescription(crd.group, crd.singular, wcc, depName)
+		description.AdmissionReviewVersions = crd.reviewVersions
+
+		endpoint := fmt.Sprintf("%s:%d%s", description.DeploymentName, description.ContainerPort, stringValue(description.WebhookPath))
+		if i, seen := byEndpoint[endpoint]; seen {
+			descriptions[i].ConversionCRDs = append(descriptions[i].ConversionCRDs, crd.name)
+			continue
+		}
+		byEndpoint[endpoint] = len(descriptions)
+		description.ConversionCRDs = []string{crd.name}
+		descriptions = append(descriptions, description)
+	}
+
+	if len(descriptions) != 0 && !supportsOnlyAllNamespaces(csv) {
+		return nil, fmt.Errorf("CSV %q has conversion webhooks, which OLM only installs for operators "+
+			"supporting only the %s install mode; set supported to false for the other install modes "+
+			"in the CSV base", csv.GetName(), operatorsv1alpha1.InstallModeTypeAllNamespaces)
+	}
+	return descriptions, nil
+}
+
+// supportsOnlyAllNamespaces returns true if AllNamespaces is the only install mode csv supports.
+func supportsOnlyAllNamespaces(csv *operatorsv1alpha1.ClusterServiceVersion) bool {
+	supportsAll := false
+	for _, mode := range csv.Spec.InstallModes {
+		if mode.Type == operatorsv1alpha1.InstallModeTypeAllNamespaces {
+			supportsAll = mode.Supported
+		} else if mode.Supported {
+			return false
+		}
+	}
+	return supportsAll
+}
+
+// conversionCRD is a CRD converted by a webhook Service.
+type conversionCRD struct {
+	name, group, singular string
+	annotations           map[string]string
+	service               *admissionregv1.ServiceReference
+	reviewVersions        []string
+}
+
+// conversionCRDs returns the CRDs in the collector converted by a webhook Service.
+func conversionCRDs(c *collector.Manifests) (crds []conversionCRD) {
+	for _, crd := range c.V1CustomResourceDefinitions {
+		conversion := crd.Spec.Conversion
+		if conversion == nil || conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil ||
+			conversion.Webhook.ClientConfig.Service == nil {
+			continue
+		}
+		ref := conversion.Webhook.ClientConfig.Service
+		crds = append(crds, conversionCRD{
+			name:           crd.GetName(),
+			group:          crd.Spec.Group,
+			singular:       crd.Spec.Names.Singular,
+			annotations:    crd.GetAnnotations(),
+			service:        &admissionregv1.ServiceReference{Namespace: ref.Namespace, Name: ref.Name, Path: ref.Path, Port: ref.Port},
+			reviewVersions: conversion.Webhook.ConversionReviewVersions,
+		})
+	}
+	for _, crd := range c.V1beta1CustomResourceDefinitions {
+		conversion := crd.Spec.Conversion
+		if conversion == nil || conversion.WebhookClientConfig == nil || conversion.WebhookClientConfig.Service == nil {
+			continue
+		}
+		ref := conversion.WebhookClientConfig.Service
+		crds = append(crds, conversionCRD{
+			name:           crd.GetName(),
+			group:          crd.Spec.Group,
+			singular:       crd.Spec.Names.Singular,
+			annotations:    crd.GetAnnotations(),
+			service:        &admissionregv1.ServiceReference{Namespace: ref.Namespace, Name: ref.Name, Path: ref.Path, Port: ref.Port},
+			reviewVersions: conversion.ConversionReviewVersions,
+		})
+	}
+	return crds
+}
+
+// conversionToWebhookDescription transforms the client config of the conversion webhook of a CRD
+// in group with singular name into a WebhookDescription.
+func conversionToWebhookDescription(group, singular string, wcc admissionregv1.WebhookClientConfig, depName string) operatorsv1alpha1.WebhookDescription {
+	sideEffects := admissionregv1.SideEffectClassNone
+	description := operatorsv1alpha1.WebhookDescription{
+		Type:         ConversionWebhook,
+		GenerateName: fmt.Sprintf("c%s.%s", singular, group),
+		SideEffects:  &sideEffects,
+	}
+
+	if serviceRef := wcc.Service; serviceRef != nil {
+		if serviceRef.Port != nil {
+			description.ContainerPort = *serviceRef.Port
+		}
+		description.DeploymentName = depName
+		if description.DeploymentName == "" {
+			description.DeploymentName = strings.TrimSuffix(serviceRef.Name, "-service")
+		}
+		description.WebhookPath = serviceRef.Path
+	}
+	return description
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
 
 // validatingToWebhookDescription transforms webhook into a WebhookDescription.
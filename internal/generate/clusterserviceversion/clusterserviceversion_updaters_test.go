@@ -15,11 +15,16 @@
 package clusterserviceversion
 
 import (
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/api/pkg/validation"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
 	"github.com/operator-framework/operator-sdk/internal/generate/collector"
 )
@@ -128,6 +133,91 @@ var _ = Describe("findMatchingDeploymentAndServiceForWebhook", func() {
 	})
 })
 
+var _ = Describe("conversionWebhookDescriptions", func() {
+	var (
+		c   *collector.Manifests
+		csv *operatorsv1alpha1.ClusterServiceVersion
+	)
+
+	newCRD := func(singular, servicePath string) apiextv1.CustomResourceDefinition {
+		port := int32(9443)
+		crd := apiextv1.CustomResourceDefinition{}
+		crd.SetName(singular + "s.cache.example.com")
+		crd.Spec.Group = "cache.example.com"
+		crd.Spec.Names.Singular = singular
+		crd.Spec.Conversion = &apiextv1.CustomResourceConversion{
+			Strategy: apiextv1.WebhookConverter,
+			Webhook: &apiextv1.WebhookConversion{
+				ClientConfig: &apiextv1.WebhookClientConfig{
+					Service: &apiextv1.ServiceReference{Name: "service-name-1", Path: &servicePath, Port: &port},
+				},
+				ConversionReviewVersions: []string{"v1", "v1beta1"},
+			},
+		}
+		return crd
+	}
+
+	BeforeEach(func() {
+		labels := map[string]string{"operator-name": "test-operator"}
+		c = &collector.Manifests{
+			Deployments: []appsv1.Deployment{newDeployment("dep-name-1", labels)},
+			Services:    []corev1.Service{newService("service-name-1", labels)},
+		}
+		csv = &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.Spec.InstallModes = []operatorsv1alpha1.InstallMode{
+			{Type: operatorsv1alpha1.InstallModeTypeOwnNamespace, Supported: false},
+			{Type: operatorsv1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+		}
+	})
+
+	It("describes CRDs converted by the same endpoint in one description", func() {
+		noConversion := apiextv1.CustomResourceDefinition{}
+		noConversion.SetName("baz.cache.example.com")
+		c.V1CustomResourceDefinitions = []apiextv1.CustomResourceDefinition{
+			newCRD("foo", "/convert"), newCRD("bar", "/convert"), noConversion,
+		}
+		descriptions, err := conversionWebhookDescriptions(c, csv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(descriptions).To(HaveLen(1))
+		Expect(descriptions[0].Type).To(Equal(ConversionWebhook))
+		Expect(descriptions[0].GenerateName).To(Equal("cfoo.cache.example.com"))
+		Expect(descriptions[0].DeploymentName).To(Equal("dep-name-1"))
+		Expect(descriptions[0].ContainerPort).To(BeEquivalentTo(9443))
+		Expect(descriptions[0].AdmissionReviewVersions).To(Equal([]string{"v1", "v1beta1"}))
+		Expect(descriptions[0].ConversionCRDs).To(Equal([]string{"foos.cache.example.com", "bars.cache.example.com"}))
+	})
+	It("describes CRDs converted by different paths separately", func() {
+		c.V1CustomResourceDefinitions = []apiextv1.CustomResourceDefinition{
+			newCRD("foo", "/convert-foo"), newCRD("bar", "/convert-bar"),
+		}
+		descriptions, err := conversionWebhookDescriptions(c, csv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(descriptions).To(HaveLen(2))
+	})
+	It("fails if the CSV supports install modes other than AllNamespaces", func() {
+		csv.Spec.InstallModes[0].Supported = true
+		c.V1CustomResourceDefinitions = []apiextv1.CustomResourceDefinition{newCRD("foo", "/convert")}
+		_, err := conversionWebhookDescriptions(c, csv)
+		Expect(err).To(MatchError(ContainSubstring("only installs for operators supporting only the AllNamespaces")))
+	})
+	It("does not fail without conversion webhooks", func() {
+		csv.Spec.InstallModes[0].Supported = true
+		Expect(conversionWebhookDescriptions(c, csv)).To(BeEmpty())
+	})
+	It("generates a CSV accepted by the CSV validator", func() {
+		base, _, err := getCSVFromFile(filepath.Join(testDataDir, "clusterserviceversions", "bases",
+			"memcached-operator.clusterserviceversion.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		base.SetName("memcached-operator.v0.0.1")
+		c.V1CustomResourceDefinitions = []apiextv1.CustomResourceDefinition{newCRD("foo", "/convert")}
+		Expect(ApplyTo(c, base)).To(Succeed())
+		Expect(base.Spec.WebhookDefinitions).To(HaveLen(1))
+		for _, result := range validation.ClusterServiceVersionValidator.Validate(base) {
+			Expect(result.Errors).To(BeEmpty())
+		}
+	})
+})
+
 func newDeployment(name string, labels map[string]string) appsv1.Deployment {
 	dep := appsv1.Deployment{}
 	dep.SetName(name)
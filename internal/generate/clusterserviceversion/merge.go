@@ -0,0 +1,364 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// lastGeneratedDir is the directory of a bundle the generator records the CSV it last generated in.
+// That CSV is the base of the three-way merge of edits made to the bundle's CSV with a newly generated one.
+const lastGeneratedDir = ".last-generated"
+
+// ConflictStrategy resolves conflicts between edits made to a bundled CSV and generated changes
+// to the same fields.
+type ConflictStrategy string
+
+const (
+	// ConflictFail fails generation, leaving the bundled CSV unchanged.
+	ConflictFail ConflictStrategy = "fail"
+	// ConflictKeepEdits keeps the edited values.
+	ConflictKeepEdits ConflictStrategy = "keep-edits"
+	// ConflictOverwrite overwrites the edited values with the generated ones.
+	ConflictOverwrite ConflictStrategy = "overwrite"
+)
+
+// ConflictStrategies are the valid ConflictStrategy values.
+var ConflictStrategies = []ConflictStrategy{ConflictFail, ConflictKeepEdits, ConflictOverwrite}
+
+// MergeConflictError is returned by Generate when edits made to a bundled CSV conflict with generated changes.
+type MergeConflictError struct {
+	// Path is the path of the bundled CSV in its bundle, ex. manifests/memcached-operator.clusterserviceversion.yaml.
+	Path string
+	// Fields are the paths of the conflicting fields, ex. spec.description.
+	Fields []string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("edits to %s conflict with generated changes to fields [%s]; make the edits in the "+
+		"CSV base instead, or keep the edits or overwrite them with --merge-conflicts",
+		e.Path, strings.Join(e.Fields, ", "))
+}
+
+// mergeEdits three-way merges the edits made to the bundled CSV since it was last generated with generated,
+// returning the merged CSV. generated is returned if the bundled CSV or the last generated CSV do not exist.
+func (g Generator) mergeEdits(generated *operatorsv1alpha1.ClusterServiceVersion) (*operatorsv1alpha1.ClusterServiceVersion, error) {
+	baseBytes, err := ioutil.ReadFile(g.lastGeneratedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return generated, g.warnUnmergedEdits(generated)
+		}
+		return nil, err
+	}
+	editedBytes, err := ioutil.ReadFile(g.bundledPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return generated, nil
+		}
+		return nil, err
+	}
+	if bytes.Equal(baseBytes, editedBytes) {
+		return generated, nil
+	}
+	generatedBytes, err := k8sutil.GetObjectBytes(generated, yaml.Marshal)
+	if err != nil {
+		return nil, err
+	}
+
+	var base, edited, gen map[string]interface{}
+	for _, f := range []struct {
+		path string
+		b    []byte
+		v    *map[string]interface{}
+	}{
+		{g.lastGeneratedPath, baseBytes, &base},
+		{g.bundledPath, editedBytes, &edited},
+		{"generated ClusterServiceVersion", generatedBytes, &gen},
+	} {
+		if err := yaml.Unmarshal(f.b, f.v); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %v", f.path, err)
+		}
+	}
+
+	// The bundle may be generated in a temporary copy, so refer to the CSV by its path in the bundle.
+	path := filepath.Join(bundle.ManifestsDir, filepath.Base(g.bundledPath))
+	m := merger{}
+	merged := m.merge("", mergeValue{base, true}, mergeValue{edited, true}, mergeValue{gen, true})
+	if len(m.conflicts) != 0 {
+		switch g.OnConflict {
+		case ConflictKeepEdits, ConflictOverwrite:
+			for _, c := range m.conflicts {
+				log.Warnf("Edits to %s field %s conflict with generated changes, %s", path, c.path, g.OnConflict)
+				if c.resolve != nil {
+					c.resolve(g.OnConflict == ConflictKeepEdits)
+				}
+			}
+		default:
+			fields := make([]string, len(m.conflicts))
+			for i, c := range m.conflicts {
+				fields[i] = c.path
+			}
+			return nil, &MergeConflictError{Path: path, Fields: fields}
+		}
+	}
+
+	b, err := json.Marshal(merged.v)
+	if err != nil {
+		return nil, err
+	}
+	csv := &operatorsv1alpha1.ClusterServiceVersion{}
+	if err := json.Unmarshal(b, csv); err != nil {
+		return nil, fmt.Errorf("error merging edits to %s: %v", path, err)
+	}
+	return csv, nil
+}
+
+// mergeValue is a value of a field, which is absent unless ok is true.
+type mergeValue struct {
+	v  interface{}
+	ok bool
+}
+
+func (v mergeValue) equal(o mergeValue) bool {
+	return v.ok == o.ok && reflect.DeepEqual(v.v, o.v)
+}
+
+func (v mergeValue) object() (map[string]interface{}, bool) {
+	obj, isObj := v.v.(map[string]interface{})
+	return obj, v.ok && isObj
+}
+
+// conflict is a field edited and generated with different values. resolve sets the field's merged value.
+type conflict struct {
+	path    string
+	resolve func(keepEdits bool)
+}
+
+// warnUnmergedEdits warns if the bundled CSV differs from generated while no last generated CSV exists to
+// merge its edits with, ex. because the bundle's last generated directory was not committed.
+func (g Generator) warnUnmergedEdits(generated *operatorsv1alpha1.ClusterServiceVersion) error {
+	editedBytes, err := ioutil.ReadFile(g.bundledPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	generatedBytes, err := k8sutil.GetObjectBytes(generated, yaml.Marshal)
+	if err != nil {
+		return err
+	}
+	var edited, gen map[string]interface{}
+	if err := yaml.Unmarshal(editedBytes, &edited); err != nil {
+		return fmt.Errorf("error parsing %s: %v", g.bundledPath, err)
+	}
+	if err := yaml.Unmarshal(generatedBytes, &gen); err != nil {
+		return fmt.Errorf("error parsing generated ClusterServiceVersion: %v", err)
+	}
+	if reflect.DeepEqual(edited, gen) {
+		return nil
+	}
+	path := filepath.Join(bundle.ManifestsDir, filepath.Base(g.bundledPath))
+	log.Warnf("%s differs from the generated CSV, but %s does not exist to merge its edits with, so edits "+
+		"not made in the CSV base may be lost. Commit the bundle's %s directory so edits are merged the next "+
+		"time the CSV is generated", path, filepath.Join(lastGeneratedDir, filepath.Base(g.lastGeneratedPath)),
+		lastGeneratedDir)
+	return nil
+}
+
+// merger three-way merges JSON values, recording conflicts.
+type merger struct {
+	conflicts []conflict
+}
+
+// merge returns the merge of the changes from base to edited with those from base to generated.
+// Objects are merged by field, and lists of objects with a key, ex. deployments by name, are merged
+// by key. Other values, including other lists, are replaced whole. Conflicting fields take generated
+// values until resolved.
+func (m *merger) merge(path string, base, edited, generated mergeValue) mergeValue {
+	switch {
+	case edited.equal(base):
+		return generated
+	case generated.equal(base), generated.equal(edited):
+		return edited
+	}
+	editedObj, editedIsObj := edited.object()
+	generatedObj, generatedIsObj := generated.object()
+	if editedIsObj && generatedIsObj {
+		baseObj, _ := base.object()
+		return mergeValue{m.mergeObjects(baseObj, editedObj, generatedObj, func(k string) string {
+			if path == "" {
+				return k
+			}
+			return path + "." + k
+		}), true}
+	}
+	if l, ok := m.mergeKeyedLists(path, base, edited, generated); ok {
+		return mergeValue{l, true}
+	}
+	// The parent object sets the conflict's resolver, since resolving it replaces the parent's field.
+	m.conflicts = append(m.conflicts, conflict{path: path})
+	return generated
+}
+
+// listKeys are the fields identifying the elements of keyed lists, in order of preference.
+// Owned CRDs are identified by name and version, since a CRD may be owned at several versions.
+var listKeys = [][]string{{"name", "version"}, {"name"}, {"serviceAccountName"}, {"type"}, {"kind"}}
+
+// keyedList is a list merged by key. It is marshaled as its items in order of their keys, skipping keys
+// whose items were removed, so conflicts in its items can be resolved after merging.
+type keyedList struct {
+	keys  []string
+	items map[string]interface{}
+}
+
+func (l *keyedList) MarshalJSON() ([]byte, error) {
+	list := make([]interface{}, 0, len(l.keys))
+	for _, k := range l.keys {
+		if v, ok := l.items[k]; ok {
+			list = append(list, v)
+		}
+	}
+	return json.Marshal(list)
+}
+
+// mergeKeyedLists merges edited and generated lists by key if every element of base, edited, and generated
+// is an object with a unique key, and returns false otherwise. Merged elements are ordered as generated,
+// followed by elements added by edits.
+func (m *merger) mergeKeyedLists(path string, base, edited, generated mergeValue) (*keyedList, bool) {
+	lists := make([][]interface{}, 3)
+	for i, v := range []mergeValue{base, edited, generated} {
+		// Absent and null lists have no elements.
+		if !v.ok || v.v == nil {
+			continue
+		}
+		l, isList := v.v.([]interface{})
+		if !isList {
+			return nil, false
+		}
+		lists[i] = l
+	}
+	for _, fields := range listKeys {
+		objs, keys, ok := make([]map[string]interface{}, 3), make([][]string, 3), true
+		for i := 0; i < len(lists) && ok; i++ {
+			objs[i] = map[string]interface{}{}
+			keys[i], ok = keyElements(lists[i], fields, objs[i])
+		}
+		if !ok {
+			continue
+		}
+		merged := &keyedList{}
+		merged.items = m.mergeObjects(objs[0], objs[1], objs[2], func(k string) string {
+			return path + "[" + k + "]"
+		})
+		seen := map[string]bool{}
+		for _, k := range append(keys[2], keys[1]...) {
+			if !seen[k] {
+				seen[k] = true
+				merged.keys = append(merged.keys, k)
+			}
+		}
+		return merged, true
+	}
+	return nil, false
+}
+
+// keyElements adds the elements of list to objs by the values of fields, joined by "/", returning the
+// keys in order. It returns false if an element is not an object with string fields, or keys are not unique.
+func keyElements(list []interface{}, fields []string, objs map[string]interface{}) ([]string, bool) {
+	keys := make([]string, 0, len(list))
+	for _, e := range list {
+		obj, isObj := e.(map[string]interface{})
+		if !isObj {
+			return nil, false
+		}
+		values := make([]string, len(fields))
+		for i, f := range fields {
+			v, isString := obj[f].(string)
+			if !isString || v == "" {
+				return nil, false
+			}
+			values[i] = v
+		}
+		k := strings.Join(values, "/")
+		if _, dup := objs[k]; dup {
+			return nil, false
+		}
+		objs[k] = obj
+		keys = append(keys, k)
+	}
+	return keys, true
+}
+
+// mergeObjects merges the fields of base, edited, and generated, named in conflicts by fieldPath.
+func (m *merger) mergeObjects(base, edited, generated map[string]interface{},
+	fieldPath func(string) string) map[string]interface{} {
+	keys := map[string]bool{}
+	for _, obj := range []map[string]interface{}{base, edited, generated} {
+		for k := range obj {
+			keys[k] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	merged := map[string]interface{}{}
+	for _, k := range sorted {
+		get := func(obj map[string]interface{}) mergeValue {
+			v, ok := obj[k]
+			return mergeValue{v, ok}
+		}
+		e, g := get(edited), get(generated)
+		n := len(m.conflicts)
+		v := m.merge(fieldPath(k), get(base), e, g)
+		if len(m.conflicts) > n && m.conflicts[n].resolve == nil {
+			// The field itself conflicts, so resolving it sets the field in merged.
+			k := k
+			m.conflicts[n].resolve = func(keepEdits bool) {
+				v := g
+				if keepEdits {
+					v = e
+				}
+				if v.ok {
+					merged[k] = v.v
+				} else {
+					delete(merged, k)
+				}
+			}
+		}
+		if v.ok {
+			merged[k] = v.v
+		}
+	}
+	return merged
+}
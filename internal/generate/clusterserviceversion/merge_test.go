@@ -0,0 +1,249 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	genutil "github.com/operator-framework/operator-sdk/internal/generate/internal"
+)
+
+var _ = Describe("Merging edits", func() {
+	var (
+		g         Generator
+		tmp       string
+		base      *v1alpha1.ClusterServiceVersion
+		generated *v1alpha1.ClusterServiceVersion
+	)
+
+	newCSV := func() *v1alpha1.ClusterServiceVersion {
+		csv := &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.SetAnnotations(map[string]string{"capabilities": "Basic Install"})
+		csv.Spec.DisplayName = "Memcached Operator"
+		csv.Spec.Description = "Runs memcached"
+		csv.Spec.Keywords = []string{"cache"}
+		return csv
+	}
+	write := func(path string, csv *v1alpha1.ClusterServiceVersion) {
+		f, err := genutil.Open(filepath.Dir(path), filepath.Base(path))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(genutil.WriteObject(f, csv)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = ioutil.TempDir("", "csv-merge")
+		Expect(err).NotTo(HaveOccurred())
+		g = Generator{OperatorName: "memcached-operator"}
+		Expect(WithBundleWriter(tmp)(&g)).To(Succeed())
+		base, generated = newCSV(), newCSV()
+		write(g.lastGeneratedPath, base)
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(tmp)).To(Succeed())
+	})
+
+	Context("without a last generated CSV", func() {
+		var out *bytes.Buffer
+		BeforeEach(func() {
+			Expect(os.Remove(g.lastGeneratedPath)).To(Succeed())
+			out = &bytes.Buffer{}
+			log.SetOutput(out)
+		})
+		AfterEach(func() {
+			log.SetOutput(os.Stderr)
+		})
+
+		It("returns the generated CSV, warning that edits cannot be merged", func() {
+			edited := newCSV()
+			edited.Spec.Description = "edited"
+			write(g.bundledPath, edited)
+			Expect(g.mergeEdits(generated)).To(BeIdenticalTo(generated))
+			Expect(out.String()).To(ContainSubstring("does not exist to merge its edits with"))
+			Expect(out.String()).To(ContainSubstring("Commit the bundle's .last-generated directory"))
+		})
+		It("does not warn if the bundled CSV was not edited", func() {
+			write(g.bundledPath, base)
+			Expect(g.mergeEdits(generated)).To(BeIdenticalTo(generated))
+			Expect(out.String()).To(BeEmpty())
+		})
+	})
+	It("returns the generated CSV without edits", func() {
+		write(g.bundledPath, base)
+		generated.Spec.Description = "generated"
+		Expect(g.mergeEdits(generated)).To(BeIdenticalTo(generated))
+	})
+	It("keeps edits to fields that were not regenerated", func() {
+		edited := newCSV()
+		edited.Spec.Description = "edited"
+		edited.Spec.Maturity = "stable"
+		edited.GetAnnotations()["repository"] = "https://github.com/example/memcached-operator"
+		write(g.bundledPath, edited)
+		generated.Spec.DisplayName = "Memcached"
+		generated.Spec.Keywords = []string{"cache", "memcached"}
+		generated.GetAnnotations()["capabilities"] = "Seamless Upgrades"
+
+		merged, err := g.mergeEdits(generated)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.Spec.Description).To(Equal("edited"))
+		Expect(merged.Spec.Maturity).To(Equal("stable"))
+		Expect(merged.Spec.DisplayName).To(Equal("Memcached"))
+		Expect(merged.Spec.Keywords).To(Equal([]string{"cache", "memcached"}))
+		Expect(merged.GetAnnotations()).To(Equal(map[string]string{
+			"capabilities": "Seamless Upgrades",
+			"repository":   "https://github.com/example/memcached-operator",
+		}))
+	})
+	It("keeps fields removed by edits", func() {
+		edited := newCSV()
+		edited.Spec.Keywords = nil
+		write(g.bundledPath, edited)
+		merged, err := g.mergeEdits(generated)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.Spec.Keywords).To(BeEmpty())
+	})
+
+	Context("with keyed lists", func() {
+		deployment := func(name, image string) v1alpha1.StrategyDeploymentSpec {
+			ds := v1alpha1.StrategyDeploymentSpec{Name: name}
+			ds.Spec.Template.Spec.Containers = []corev1.Container{{Name: "manager", Image: image}}
+			return ds
+		}
+		withInstall := func(csv *v1alpha1.ClusterServiceVersion, deployments ...v1alpha1.StrategyDeploymentSpec) {
+			csv.Spec.InstallStrategy = v1alpha1.NamedInstallStrategy{
+				StrategyName: v1alpha1.InstallStrategyNameDeployment,
+				StrategySpec: v1alpha1.StrategyDetailsDeployment{
+					DeploymentSpecs: deployments,
+					Permissions: []v1alpha1.StrategyDeploymentPermissions{
+						{ServiceAccountName: "default", Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}}}},
+					},
+				},
+			}
+			csv.Spec.InstallModes = []v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+				{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+			}
+		}
+
+		BeforeEach(func() {
+			withInstall(base, deployment("controller-manager", "controller:v0.0.1"))
+			write(g.lastGeneratedPath, base)
+		})
+
+		It("merges elements by key", func() {
+			edited := newCSV()
+			withInstall(edited, deployment("controller-manager", "controller:v0.0.1"), deployment("proxy", "proxy:v0.0.1"))
+			replicas := int32(2)
+			edited.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Replicas = &replicas
+			edited.Spec.InstallStrategy.StrategySpec.Permissions[0].Rules[0].Verbs = []string{"get", "list"}
+			edited.Spec.InstallModes[1].Supported = false
+			write(g.bundledPath, edited)
+			withInstall(generated, deployment("webhook", "webhook:v0.0.2"),
+				deployment("controller-manager", "controller:v0.0.2"))
+			generated.Spec.InstallModes[0].Supported = false
+
+			merged, err := g.mergeEdits(generated)
+			Expect(err).NotTo(HaveOccurred())
+			deployments := merged.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+			Expect(deployments).To(HaveLen(3))
+			Expect(deployments[0].Name).To(Equal("webhook"))
+			Expect(deployments[1].Name).To(Equal("controller-manager"))
+			Expect(*deployments[1].Spec.Replicas).To(Equal(int32(2)))
+			Expect(deployments[1].Spec.Template.Spec.Containers[0].Image).To(Equal("controller:v0.0.2"))
+			Expect(deployments[2].Name).To(Equal("proxy"))
+			Expect(merged.Spec.InstallStrategy.StrategySpec.Permissions[0].Rules[0].Verbs).To(Equal([]string{"get", "list"}))
+			Expect(merged.Spec.InstallModes).To(Equal([]v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: false},
+				{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: false},
+			}))
+		})
+		It("removes elements removed by edits", func() {
+			edited := newCSV()
+			withInstall(edited)
+			write(g.bundledPath, edited)
+			withInstall(generated, deployment("controller-manager", "controller:v0.0.1"),
+				deployment("webhook", "webhook:v0.0.2"))
+
+			merged, err := g.mergeEdits(generated)
+			Expect(err).NotTo(HaveOccurred())
+			deployments := merged.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+			Expect(deployments).To(HaveLen(1))
+			Expect(deployments[0].Name).To(Equal("webhook"))
+		})
+		It("reports and resolves conflicts in elements", func() {
+			edited := newCSV()
+			withInstall(edited, deployment("controller-manager", "controller:edited"))
+			write(g.bundledPath, edited)
+			withInstall(generated, deployment("controller-manager", "controller:v0.0.2"))
+
+			_, err := g.mergeEdits(generated)
+			Expect(err).To(MatchError(ContainSubstring(
+				"spec.install.spec.deployments[controller-manager].spec.template.spec.containers[manager].image")))
+
+			g.OnConflict = ConflictKeepEdits
+			merged, err := g.mergeEdits(generated)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec.Containers[0].Image).
+				To(Equal("controller:edited"))
+		})
+	})
+
+	Context("with conflicts", func() {
+		BeforeEach(func() {
+			edited := newCSV()
+			edited.Spec.Description = "edited"
+			edited.Spec.Keywords = []string{"edited"}
+			edited.Spec.Maturity = "stable"
+			write(g.bundledPath, edited)
+			generated.Spec.Description = "generated"
+			generated.Spec.Keywords = []string{"generated"}
+		})
+
+		It("fails by default", func() {
+			_, err := g.mergeEdits(generated)
+			Expect(err).To(Equal(&MergeConflictError{
+				Path:   filepath.Join("manifests", "memcached-operator.clusterserviceversion.yaml"),
+				Fields: []string{"spec.description", "spec.keywords"},
+			}))
+		})
+		It("keeps edits", func() {
+			g.OnConflict = ConflictKeepEdits
+			merged, err := g.mergeEdits(generated)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged.Spec.Description).To(Equal("edited"))
+			Expect(merged.Spec.Keywords).To(Equal([]string{"edited"}))
+			Expect(merged.Spec.Maturity).To(Equal("stable"))
+		})
+		It("overwrites edits", func() {
+			g.OnConflict = ConflictOverwrite
+			merged, err := g.mergeEdits(generated)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged.Spec.Description).To(Equal("generated"))
+			Expect(merged.Spec.Keywords).To(Equal([]string{"generated"}))
+			Expect(merged.Spec.Maturity).To(Equal("stable"))
+		})
+	})
+})
@@ -0,0 +1,237 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	"sort"
+	"strings"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+// builtinResourceScopes are the scopes of the resources of Kubernetes, OpenShift, OLM, and Prometheus
+// operator APIs, keyed by "<group>/<resource>": true if namespaced, false if cluster-scoped.
+// The scopes of other resources are read from CRDs, or are unknown.
+var builtinResourceScopes = map[string]bool{
+	"/bindings":                                      true,
+	"/configmaps":                                    true,
+	"/endpoints":                                     true,
+	"/events":                                        true,
+	"/limitranges":                                   true,
+	"/persistentvolumeclaims":                        true,
+	"/pods":                                          true,
+	"/podtemplates":                                  true,
+	"/replicationcontrollers":                        true,
+	"/resourcequotas":                                true,
+	"/secrets":                                       true,
+	"/serviceaccounts":                               true,
+	"/services":                                      true,
+	"/componentstatuses":                             false,
+	"/namespaces":                                    false,
+	"/nodes":                                         false,
+	"/persistentvolumes":                             false,
+	"apps/controllerrevisions":                       true,
+	"apps/daemonsets":                                true,
+	"apps/deployments":                               true,
+	"apps/replicasets":                               true,
+	"apps/statefulsets":                              true,
+	"autoscaling/horizontalpodautoscalers":           true,
+	"batch/cronjobs":                                 true,
+	"batch/jobs":                                     true,
+	"coordination.k8s.io/leases":                     true,
+	"discovery.k8s.io/endpointslices":                true,
+	"events.k8s.io/events":                           true,
+	"extensions/deployments":                         true,
+	"extensions/ingresses":                           true,
+	"networking.k8s.io/ingresses":                    true,
+	"networking.k8s.io/networkpolicies":              true,
+	"networking.k8s.io/ingressclasses":               false,
+	"policy/poddisruptionbudgets":                    true,
+	"policy/podsecuritypolicies":                     false,
+	"rbac.authorization.k8s.io/rolebindings":         true,
+	"rbac.authorization.k8s.io/roles":                true,
+	"rbac.authorization.k8s.io/clusterrolebindings":  false,
+	"rbac.authorization.k8s.io/clusterroles":         false,
+	"authorization.k8s.io/localsubjectaccessreviews": true,
+	"authorization.k8s.io/selfsubjectaccessreviews":  false,
+	"authorization.k8s.io/selfsubjectrulesreviews":   false,
+	"authorization.k8s.io/subjectaccessreviews":      false,
+	"authentication.k8s.io/tokenreviews":             false,
+	"admissionregistration.k8s.io/mutatingwebhookconfigurations":   false,
+	"admissionregistration.k8s.io/validatingwebhookconfigurations": false,
+	"apiextensions.k8s.io/customresourcedefinitions":               false,
+	"apiregistration.k8s.io/apiservices":                           false,
+	"certificates.k8s.io/certificatesigningrequests":               false,
+	"node.k8s.io/runtimeclasses":                                   false,
+	"scheduling.k8s.io/priorityclasses":                            false,
+	"storage.k8s.io/csidrivers":                                    false,
+	"storage.k8s.io/csinodes":                                      false,
+	"storage.k8s.io/storageclasses":                                false,
+	"storage.k8s.io/volumeattachments":                             false,
+	"config.openshift.io/clusteroperators":                         false,
+	"config.openshift.io/clusterversions":                          false,
+	"console.openshift.io/consolelinks":                            false,
+	"console.openshift.io/consoleyamlsamples":                      false,
+	"route.openshift.io/routes":                                    true,
+	"security.openshift.io/securitycontextconstraints":             false,
+	"monitoring.coreos.com/podmonitors":                            true,
+	"monitoring.coreos.com/prometheusrules":                        true,
+	"monitoring.coreos.com/servicemonitors":                        true,
+	"operators.coreos.com/catalogsources":                          true,
+	"operators.coreos.com/clusterserviceversions":                  true,
+	"operators.coreos.com/installplans":                            true,
+	"operators.coreos.com/operatorgroups":                          true,
+	"operators.coreos.com/subscriptions":                           true,
+	"operators.coreos.com/operators":                               false,
+}
+
+// resourceScopes are the known scopes of resources.
+type resourceScopes map[string]bool
+
+// newResourceScopes returns the scopes of built-in resources, and of the resources of the CRDs in c.
+func newResourceScopes(c *collector.Manifests) resourceScopes {
+	scopes := resourceScopes{}
+	for k, v := range builtinResourceScopes {
+		scopes[k] = v
+	}
+	for _, crd := range c.V1CustomResourceDefinitions {
+		scopes[crd.Spec.Group+"/"+crd.Spec.Names.Plural] = crd.Spec.Scope == apiextv1.NamespaceScoped
+	}
+	for _, crd := range c.V1beta1CustomResourceDefinitions {
+		scopes[crd.Spec.Group+"/"+crd.Spec.Names.Plural] = crd.Spec.Scope == apiextv1beta1.NamespaceScoped
+	}
+	return scopes
+}
+
+// Scopes of rules.
+const (
+	scopeUnknown = iota
+	scopeNamespaced
+	scopeCluster
+)
+
+// scopeOf returns the scope of the resource of group, ignoring subresources.
+func (s resourceScopes) scopeOf(group, resource string) int {
+	resource = strings.SplitN(resource, "/", 2)[0]
+	namespaced, known := s[group+"/"+resource]
+	switch {
+	case !known:
+		return scopeUnknown
+	case namespaced:
+		return scopeNamespaced
+	}
+	return scopeCluster
+}
+
+// split returns rules split by the scope of their resources. Rules of non-resource URLs are cluster-scoped.
+// Rules whose resources have several scopes are split if they have one API group, and are otherwise unknown.
+func (s resourceScopes) split(rules []rbacv1.PolicyRule) (scoped map[int][]rbacv1.PolicyRule) {
+	scoped = map[int][]rbacv1.PolicyRule{}
+	for _, rule := range rules {
+		if len(rule.NonResourceURLs) != 0 {
+			scoped[scopeCluster] = append(scoped[scopeCluster], rule)
+			continue
+		}
+		byScope := map[int][]string{}
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				scope := s.scopeOf(group, resource)
+				byScope[scope] = appendUnique(byScope[scope], resource)
+			}
+		}
+		switch {
+		case len(byScope) == 1:
+			for scope := range byScope {
+				scoped[scope] = append(scoped[scope], rule)
+			}
+		case len(rule.APIGroups) == 1:
+			for scope, resources := range byScope {
+				r := *rule.DeepCopy()
+				r.Resources = resources
+				scoped[scope] = append(scoped[scope], r)
+			}
+		default:
+			scoped[scopeUnknown] = append(scoped[scopeUnknown], rule)
+		}
+	}
+	return scoped
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, e := range list {
+		if e == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// scopePermissions moves the rules of csv's permissions for cluster-scoped resources to its clusterPermissions,
+// since the Roles OLM creates for permissions cannot grant them. If csv does not support the AllNamespaces install
+// mode, the rules of its clusterPermissions for namespaced resources are moved to its permissions, since OLM only
+// needs to grant them in the namespaces the operator watches. The scope of resources is that of built-in APIs,
+// or of CRDs in c; rules of resources of unknown scope are not moved.
+func scopePermissions(c *collector.Manifests, csv *operatorsv1alpha1.ClusterServiceVersion) {
+	scopes := newResourceScopes(c)
+	namespaced := supportsOnlyNamespaces(csv)
+	strategy := &csv.Spec.InstallStrategy.StrategySpec
+
+	perms, clusterPerms := map[string][]rbacv1.PolicyRule{}, map[string][]rbacv1.PolicyRule{}
+	for _, p := range strategy.Permissions {
+		scoped := scopes.split(p.Rules)
+		perms[p.ServiceAccountName] = append(perms[p.ServiceAccountName], scoped[scopeNamespaced]...)
+		perms[p.ServiceAccountName] = append(perms[p.ServiceAccountName], scoped[scopeUnknown]...)
+		clusterPerms[p.ServiceAccountName] = append(clusterPerms[p.ServiceAccountName], scoped[scopeCluster]...)
+	}
+	for _, p := range strategy.ClusterPermissions {
+		if !namespaced {
+			clusterPerms[p.ServiceAccountName] = append(clusterPerms[p.ServiceAccountName], p.Rules...)
+			continue
+		}
+		scoped := scopes.split(p.Rules)
+		perms[p.ServiceAccountName] = append(perms[p.ServiceAccountName], scoped[scopeNamespaced]...)
+		clusterPerms[p.ServiceAccountName] = append(clusterPerms[p.ServiceAccountName], scoped[scopeCluster]...)
+		clusterPerms[p.ServiceAccountName] = append(clusterPerms[p.ServiceAccountName], scoped[scopeUnknown]...)
+	}
+	strategy.Permissions = toPermissions(perms)
+	strategy.ClusterPermissions = toPermissions(clusterPerms)
+}
+
+// supportsOnlyNamespaces returns true if csv does not support the AllNamespaces install mode.
+func supportsOnlyNamespaces(csv *operatorsv1alpha1.ClusterServiceVersion) bool {
+	for _, mode := range csv.Spec.InstallModes {
+		if mode.Type == operatorsv1alpha1.InstallModeTypeAllNamespaces {
+			return !mode.Supported
+		}
+	}
+	return false
+}
+
+// toPermissions returns the permissions of each service account in rules that has rules, sorted by name.
+func toPermissions(rules map[string][]rbacv1.PolicyRule) []operatorsv1alpha1.StrategyDeploymentPermissions {
+	perms := []operatorsv1alpha1.StrategyDeploymentPermissions{}
+	for saName, saRules := range rules {
+		if len(saRules) != 0 {
+			perms = append(perms, operatorsv1alpha1.StrategyDeploymentPermissions{ServiceAccountName: saName, Rules: saRules})
+		}
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i].ServiceAccountName < perms[j].ServiceAccountName })
+	return perms
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+var _ = Describe("scopePermissions", func() {
+	var (
+		c   *collector.Manifests
+		csv *operatorsv1alpha1.ClusterServiceVersion
+	)
+
+	rule := func(group string, resources ...string) rbacv1.PolicyRule {
+		return rbacv1.PolicyRule{APIGroups: []string{group}, Resources: resources, Verbs: []string{"get"}}
+	}
+	perms := func(rules ...rbacv1.PolicyRule) []operatorsv1alpha1.StrategyDeploymentPermissions {
+		return []operatorsv1alpha1.StrategyDeploymentPermissions{{ServiceAccountName: "controller-manager", Rules: rules}}
+	}
+	crdRule := rule("cache.example.com", "memcacheds", "memcacheds/status")
+
+	BeforeEach(func() {
+		c = &collector.Manifests{}
+		crd := apiextv1.CustomResourceDefinition{}
+		crd.Spec.Group, crd.Spec.Names.Plural, crd.Spec.Scope = "cache.example.com", "memcacheds", apiextv1.NamespaceScoped
+		c.V1CustomResourceDefinitions = append(c.V1CustomResourceDefinitions, crd)
+		csv = &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.Spec.InstallModes = []operatorsv1alpha1.InstallMode{
+			{Type: operatorsv1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+			{Type: operatorsv1alpha1.InstallModeTypeAllNamespaces, Supported: false},
+		}
+	})
+
+	It("moves namespaced rules of clusterPermissions to permissions", func() {
+		nodes := rule("", "nodes")
+		metrics := rbacv1.PolicyRule{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}}
+		unknown := rule("example.com", "widgets")
+		csv.Spec.InstallStrategy.StrategySpec.ClusterPermissions = perms(crdRule, rule("", "pods", "nodes"), nodes, metrics, unknown)
+		scopePermissions(c, csv)
+		Expect(csv.Spec.InstallStrategy.StrategySpec.Permissions).To(Equal(perms(crdRule, rule("", "pods"))))
+		Expect(csv.Spec.InstallStrategy.StrategySpec.ClusterPermissions).To(Equal(perms(rule("", "nodes"), nodes, metrics, unknown)))
+	})
+	It("moves cluster-scoped rules of permissions to clusterPermissions", func() {
+		unknown := rule("example.com", "widgets")
+		mixed := rbacv1.PolicyRule{APIGroups: []string{"", "apps"}, Resources: []string{"namespaces", "deployments"}, Verbs: []string{"get"}}
+		csv.Spec.InstallStrategy.StrategySpec.Permissions = perms(crdRule, unknown, mixed,
+			rule("apiextensions.k8s.io", "customresourcedefinitions"))
+		scopePermissions(c, csv)
+		Expect(csv.Spec.InstallStrategy.StrategySpec.Permissions).To(Equal(perms(crdRule, unknown, mixed)))
+		Expect(csv.Spec.InstallStrategy.StrategySpec.ClusterPermissions).
+			To(Equal(perms(rule("apiextensions.k8s.io", "customresourcedefinitions"))))
+	})
+	It("keeps clusterPermissions of operators supporting AllNamespaces installs", func() {
+		csv.Spec.InstallModes[1].Supported = true
+		csv.Spec.InstallStrategy.StrategySpec.ClusterPermissions = perms(crdRule, rule("", "namespaces"))
+		scopePermissions(c, csv)
+		Expect(csv.Spec.InstallStrategy.StrategySpec.Permissions).To(BeEmpty())
+		Expect(csv.Spec.InstallStrategy.StrategySpec.ClusterPermissions).To(Equal(perms(crdRule, rule("", "namespaces"))))
+	})
+})
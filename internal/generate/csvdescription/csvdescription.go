@@ -0,0 +1,252 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package csvdescription generates the description of a ClusterServiceVersion from a project's README,
+// so marketplace listings like OperatorHub stay in sync with the project's docs.
+package csvdescription
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// DefaultTemplate renders the README, followed by install notes and custom resource examples if any.
+const DefaultTemplate = `{{ .Readme }}
+{{- if .InstallNotes }}
+
+## Installation
+
+{{ .InstallNotes }}
+{{- end }}
+{{- if .Examples }}
+
+## Examples
+{{- range .Examples }}
+
+### {{ .Kind }}
+
+` + "```yaml" + `
+{{ .YAML }}
+` + "```" + `
+{{- end }}
+{{- end }}
+`
+
+// Data is passed to description templates.
+type Data struct {
+	// Readme is the README without its title and excluded sections.
+	Readme string
+	// InstallNotes are the contents of the install notes file, if one was set.
+	InstallNotes string
+	// Examples are the first custom resource sample of each kind, sorted by kind.
+	Examples []Example
+}
+
+// Example is a custom resource sample.
+type Example struct {
+	APIVersion string
+	Kind       string
+	// YAML is the sample's manifest.
+	YAML string
+}
+
+// Generator generates a CSV description.
+type Generator struct {
+	// ReadmePath is the path of the README, which is required.
+	ReadmePath string
+	// TemplatePath is the path of a text/template rendered with Data. DefaultTemplate is used if unset.
+	TemplatePath string
+	// InstallNotesPath is the path of a Markdown file with install notes, which are optional.
+	InstallNotesPath string
+	// SamplesDir contains custom resource samples used as examples. A missing directory yields no examples.
+	SamplesDir string
+	// ExcludeSections are the titles of README sections, ex. "Contributing", left out of the description.
+	// Sections are H1 or H2 headings, and include subsections.
+	ExcludeSections []string
+}
+
+// Generate renders the description.
+func (g Generator) Generate() (string, error) {
+	b, err := ioutil.ReadFile(g.ReadmePath)
+	if err != nil {
+		return "", fmt.Errorf("error reading README: %v", err)
+	}
+	data := Data{Readme: FilterReadme(string(b), g.ExcludeSections)}
+	if g.InstallNotesPath != "" {
+		if b, err = ioutil.ReadFile(g.InstallNotesPath); err != nil {
+			return "", fmt.Errorf("error reading install notes: %v", err)
+		}
+		data.InstallNotes = strings.TrimSpace(string(b))
+	}
+	if g.SamplesDir != "" {
+		if data.Examples, err = readExamples(g.SamplesDir); err != nil {
+			return "", err
+		}
+	}
+
+	text := DefaultTemplate
+	if g.TemplatePath != "" {
+		if b, err = ioutil.ReadFile(g.TemplatePath); err != nil {
+			return "", fmt.Errorf("error reading template: %v", err)
+		}
+		text = string(b)
+	}
+	return Render(text, data)
+}
+
+// Render renders the description template text with data.
+func Render(text string, data Data) (string, error) {
+	t, err := template.New("description").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("error executing template: %v", err)
+	}
+	return strings.TrimSpace(buf.String()) + "\n", nil
+}
+
+// FilterReadme returns readme without its leading H1 title, since OperatorHub titles descriptions with
+// the CSV's display name, and without sections titled one of excludeSections, compared case-insensitively.
+// Headings in fenced code blocks are ignored.
+func FilterReadme(readme string, excludeSections []string) string {
+	excluded := map[string]bool{}
+	for _, s := range excludeSections {
+		excluded[strings.ToLower(strings.TrimSpace(s))] = true
+	}
+
+	var kept []string
+	inFence, excluding, hasContent := false, false, false
+	for _, line := range strings.Split(readme, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if !inFence {
+			level, title := heading(line)
+			if level == 1 && !hasContent {
+				hasContent = true
+				continue
+			}
+			if level == 1 || level == 2 {
+				excluding = excluded[strings.ToLower(title)]
+			}
+		}
+		if strings.TrimSpace(line) != "" {
+			hasContent = true
+		}
+		if !excluding {
+			kept = append(kept, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// heading returns the level and title of an ATX heading line, ex. 2 and "Usage" for "## Usage",
+// or 0 if line is not a heading.
+func heading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(strings.TrimRight(line[level:], "# "))
+}
+
+// readExamples returns the first custom resource in samplesDir of each kind.
+func readExamples(samplesDir string) ([]Example, error) {
+	paths, err := filepath.Glob(filepath.Join(samplesDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var examples []Example
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading sample %s: %v", path, err)
+		}
+		scanner := k8sutil.NewYAMLScanner(bytes.NewBuffer(b))
+		for scanner.Scan() {
+			u := unstructured.Unstructured{}
+			if err := yaml.Unmarshal(scanner.Bytes(), &u.Object); err != nil {
+				return nil, fmt.Errorf("error unmarshaling sample %s: %v", path, err)
+			}
+			// Kustomization files are not samples.
+			kind := u.GetKind()
+			if kind == "" || kind == "Kustomization" || seen[kind] {
+				continue
+			}
+			seen[kind] = true
+			examples = append(examples, Example{
+				APIVersion: u.GetAPIVersion(),
+				Kind:       kind,
+				YAML:       strings.TrimSpace(string(scanner.Bytes())),
+			})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error scanning sample %s: %v", path, err)
+		}
+	}
+	sort.Slice(examples, func(i, j int) bool {
+		return examples[i].Kind < examples[j].Kind
+	})
+	return examples, nil
+}
+
+// KeepSection returns description with the section of old starting at the line sectionHeading,
+// ex. API reference links written by 'generate docs', appended if description has no such section.
+func KeepSection(description, old, sectionHeading string) string {
+	if section := findSection(description, sectionHeading); section != "" {
+		return description
+	}
+	section := findSection(old, sectionHeading)
+	if section == "" {
+		return description
+	}
+	return strings.TrimRight(description, "\n") + "\n\n" + section + "\n"
+}
+
+// findSection returns the section of description starting at the line sectionHeading,
+// up to the next H1 or H2.
+func findSection(description, sectionHeading string) string {
+	lines := strings.Split(description, "\n")
+	start, end := -1, len(lines)
+	for i, line := range lines {
+		if start < 0 && strings.TrimSpace(line) == sectionHeading {
+			start = i
+		} else if start >= 0 {
+			if level, _ := heading(line); level == 1 || level == 2 {
+				end = i
+				break
+			}
+		}
+	}
+	if start < 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(lines[start:end], "\n"))
+}
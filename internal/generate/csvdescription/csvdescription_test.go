@@ -0,0 +1,159 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csvdescription
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const readme = `# memcached-operator
+
+Manages memcached clusters.
+
+## Usage
+
+Create a Memcached:
+
+` + "```sh" + `
+# Not a heading.
+kubectl apply -f config/samples
+` + "```" + `
+
+### Sizing
+
+Set spec.size.
+
+## Contributing
+
+Open a pull request.
+
+### Testing
+
+Run make test.
+
+## License
+
+Apache 2.0
+`
+
+var _ = Describe("FilterReadme", func() {
+	It("removes the title and excluded sections with their subsections", func() {
+		Expect(FilterReadme(readme, []string{"contributing", "License"})).To(Equal(`Manages memcached clusters.
+
+## Usage
+
+Create a Memcached:
+
+` + "```sh" + `
+# Not a heading.
+kubectl apply -f config/samples
+` + "```" + `
+
+### Sizing
+
+Set spec.size.`))
+	})
+	It("keeps an H1 that is not the title", func() {
+		Expect(FilterReadme("Intro.\n\n# Usage\n\nRun it.\n", nil)).To(Equal("Intro.\n\n# Usage\n\nRun it."))
+	})
+})
+
+var _ = Describe("Generator", func() {
+	var (
+		dir string
+		g   Generator
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "csvdescription")
+		Expect(err).NotTo(HaveOccurred())
+		write := func(name, content string) string {
+			path := filepath.Join(dir, name)
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+			return path
+		}
+		sample := write("samples/cache_v1_memcached.yaml",
+			"apiVersion: cache.example.com/v1\nkind: Memcached\nmetadata:\n  name: memcached-sample\n")
+		g = Generator{
+			ReadmePath:       write("README.md", "# memcached-operator\n\nManages memcached clusters.\n"),
+			InstallNotesPath: write("install.md", "Install cert-manager first.\n"),
+			SamplesDir:       filepath.Dir(sample),
+		}
+		write("samples/kustomization.yaml", "resources:\n- cache_v1_memcached.yaml\n")
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("renders the default template", func() {
+		Expect(g.Generate()).To(Equal(`Manages memcached clusters.
+
+## Installation
+
+Install cert-manager first.
+
+## Examples
+
+### Memcached
+
+` + "```yaml" + `
+apiVersion: cache.example.com/v1
+kind: Memcached
+metadata:
+  name: memcached-sample
+` + "```" + `
+`))
+	})
+	It("renders a custom template", func() {
+		g.TemplatePath = filepath.Join(dir, "description.tmpl")
+		Expect(ioutil.WriteFile(g.TemplatePath, []byte("{{ .Readme }}\n\nKinds:{{ range .Examples }} {{ .Kind }}{{ end }}"),
+			0644)).To(Succeed())
+		Expect(g.Generate()).To(Equal("Manages memcached clusters.\n\nKinds: Memcached\n"))
+	})
+	It("fails on unknown template fields", func() {
+		g.TemplatePath = filepath.Join(dir, "description.tmpl")
+		Expect(ioutil.WriteFile(g.TemplatePath, []byte("{{ .Unknown }}"), 0644)).To(Succeed())
+		_, err := g.Generate()
+		Expect(err).To(HaveOccurred())
+	})
+	It("renders the README alone without install notes or samples", func() {
+		g.InstallNotesPath = ""
+		g.SamplesDir = filepath.Join(dir, "missing")
+		Expect(g.Generate()).To(Equal("Manages memcached clusters.\n"))
+	})
+})
+
+var _ = Describe("KeepSection", func() {
+	const heading = "## API Reference"
+
+	It("appends the old section", func() {
+		old := "Old.\n\n## API Reference\n\n* [Memcached](https://example.com)\n\n## Other\n\nOther.\n"
+		Expect(KeepSection("New.\n", old, heading)).To(Equal("New.\n\n## API Reference\n\n* [Memcached](https://example.com)\n"))
+	})
+	It("keeps the new section", func() {
+		Expect(KeepSection("New.\n\n## API Reference\n\nNone.\n", "## API Reference\n\nOld.\n", heading)).
+			To(Equal("New.\n\n## API Reference\n\nNone.\n"))
+	})
+	It("does nothing without an old section", func() {
+		Expect(KeepSection("New.\n", "Old.\n", heading)).To(Equal("New.\n"))
+	})
+})
@@ -0,0 +1,224 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package features documents the feature gates of projects scaffolded with 'init --feature-gates'
+// in their ClusterServiceVersion.
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotation is the CSV annotation documenting an operator's feature gates as a JSON list of Gates.
+const Annotation = "operators.operatorframework.io/feature-gates"
+
+// Names of declarations in a project's features package.
+const (
+	featureType     = "Feature"
+	defaultGatesVar = "defaultFeatureGates"
+	defaultField    = "Default"
+	preReleaseField = "PreRelease"
+	stageGA         = "GA"
+)
+
+// Gate documents a feature gate.
+type Gate struct {
+	// Name is the name the gate is toggled by.
+	Name string `json:"name"`
+	// Default is true if the feature is enabled unless disabled explicitly.
+	Default bool `json:"default"`
+	// Stage is the feature's maturity: ALPHA, BETA, or GA.
+	Stage string `json:"stage"`
+	// Description is the comment of the gate's Feature constant.
+	Description string `json:"description,omitempty"`
+}
+
+// ParseFile returns the gates declared in defaultFeatureGates of the features package file at path,
+// sorted by name, or nil if the file does not exist.
+func ParseFile(path string) ([]Gate, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing feature gates: %v", err)
+	}
+
+	// Feature constants by identifier.
+	features := map[string]Gate{}
+	var defaults *ast.CompositeLit
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			switch gen.Tok {
+			case token.CONST:
+				if typ, ok := vs.Type.(*ast.Ident); !ok || typ.Name != featureType {
+					continue
+				}
+				for i, name := range vs.Names {
+					if i >= len(vs.Values) {
+						break
+					}
+					if lit, ok := vs.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						value, err := strconv.Unquote(lit.Value)
+						if err != nil {
+							return nil, err
+						}
+						features[name.Name] = Gate{Name: value, Description: docText(vs, gen)}
+					}
+				}
+			case token.VAR:
+				for i, name := range vs.Names {
+					if name.Name == defaultGatesVar && i < len(vs.Values) {
+						defaults, _ = vs.Values[i].(*ast.CompositeLit)
+					}
+				}
+			}
+		}
+	}
+	if defaults == nil {
+		return nil, fmt.Errorf("no %s map in %s", defaultGatesVar, path)
+	}
+
+	var gates []Gate
+	for _, elt := range defaults.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		gate, err := keyGate(kv.Key, features)
+		if err != nil {
+			return nil, err
+		}
+		spec, ok := kv.Value.(*ast.CompositeLit)
+		if !ok {
+			return nil, fmt.Errorf("feature gate %s must have a FeatureSpec literal", gate.Name)
+		}
+		if err := setSpec(&gate, spec); err != nil {
+			return nil, err
+		}
+		gates = append(gates, gate)
+	}
+	sort.Slice(gates, func(i, j int) bool { return gates[i].Name < gates[j].Name })
+	return gates, nil
+}
+
+// keyGate returns the gate named by a key of defaultFeatureGates, a Feature constant or a string.
+func keyGate(key ast.Expr, features map[string]Gate) (Gate, error) {
+	switch k := key.(type) {
+	case *ast.Ident:
+		if gate, ok := features[k.Name]; ok {
+			return gate, nil
+		}
+		return Gate{}, fmt.Errorf("feature gate %s is not a %s constant declared in the features package", k.Name, featureType)
+	case *ast.BasicLit:
+		name, err := strconv.Unquote(k.Value)
+		if err != nil {
+			return Gate{}, err
+		}
+		return Gate{Name: name}, nil
+	}
+	return Gate{}, fmt.Errorf("unsupported feature gate key at offset %d", key.Pos())
+}
+
+// setSpec sets gate's default and stage from the fields of its FeatureSpec literal.
+func setSpec(gate *Gate, spec *ast.CompositeLit) error {
+	gate.Stage = stageGA
+	for _, elt := range spec.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		field, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		value := identName(kv.Value)
+		switch field.Name {
+		case defaultField:
+			gate.Default = value == "true"
+		case preReleaseField:
+			switch value {
+			case "Alpha", "Beta":
+				gate.Stage = strings.ToUpper(value)
+			case stageGA:
+			default:
+				return fmt.Errorf("feature gate %s has a PreRelease that is not one of Alpha, Beta, or GA", gate.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// identName returns the name of an identifier, qualified or not, or the empty string.
+func identName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+	return ""
+}
+
+// docText returns the comment of a constant spec, or of its declaration if it has one spec.
+func docText(vs *ast.ValueSpec, gen *ast.GenDecl) string {
+	doc := vs.Doc
+	if doc == nil && len(gen.Specs) == 1 {
+		doc = gen.Doc
+	}
+	if doc == nil {
+		doc = vs.Comment
+	}
+	return strings.TrimSpace(doc.Text())
+}
+
+// SetAnnotation documents gates in obj's Annotation, or removes it if there are no gates.
+func SetAnnotation(obj metav1.Object, gates []Gate) error {
+	annotations := obj.GetAnnotations()
+	if len(gates) == 0 {
+		if _, ok := annotations[Annotation]; ok {
+			delete(annotations, Annotation)
+			obj.SetAnnotations(annotations)
+		}
+		return nil
+	}
+	b, err := json.Marshal(gates)
+	if err != nil {
+		return fmt.Errorf("error marshaling feature gates: %v", err)
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[Annotation] = string(b)
+	obj.SetAnnotations(annotations)
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package features
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const featuresFile = `package features
+
+const (
+	// Backups enables reconciling MemcachedBackups.
+	Backups Feature = "Backups"
+	// Sharding spreads a Memcached's keys
+	// across its replicas.
+	Sharding Feature = "Sharding"
+	Legacy   Feature = "Legacy"
+)
+
+var defaultFeatureGates = map[Feature]FeatureSpec{
+	Sharding: {Default: true, PreRelease: Beta},
+	Backups:  {Default: false, PreRelease: Alpha},
+	Legacy:   {Default: true, PreRelease: GA},
+	"Other":  {},
+}
+`
+
+var _ = Describe("ParseFile", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "features-")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	write := func(content string) string {
+		path := filepath.Join(dir, "features.go")
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	It("returns the default feature gates sorted by name", func() {
+		gates, err := ParseFile(write(featuresFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gates).To(Equal([]Gate{
+			{Name: "Backups", Stage: "ALPHA", Description: "Backups enables reconciling MemcachedBackups."},
+			{Name: "Legacy", Default: true, Stage: "GA"},
+			{Name: "Other", Stage: "GA"},
+			{Name: "Sharding", Default: true, Stage: "BETA",
+				Description: "Sharding spreads a Memcached's keys\nacross its replicas."},
+		}))
+	})
+	It("returns no gates if the file does not exist", func() {
+		gates, err := ParseFile(filepath.Join(dir, "features.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gates).To(BeNil())
+	})
+	It("returns no gates if none are declared", func() {
+		gates, err := ParseFile(write("package features\n\nvar defaultFeatureGates = map[Feature]FeatureSpec{}\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gates).To(BeEmpty())
+	})
+	It("fails if a gate is not a declared constant", func() {
+		_, err := ParseFile(write("package features\n\nvar defaultFeatureGates = map[Feature]FeatureSpec{\n\tFoo: {},\n}\n"))
+		Expect(err).To(MatchError(ContainSubstring("feature gate Foo is not a Feature constant")))
+	})
+	It("fails if a gate has an unknown stage", func() {
+		_, err := ParseFile(write(`package features
+
+var defaultFeatureGates = map[Feature]FeatureSpec{
+	"Foo": {PreRelease: "Experimental"},
+}
+`))
+		Expect(err).To(MatchError(ContainSubstring("feature gate Foo has a PreRelease")))
+	})
+	It("fails without defaultFeatureGates", func() {
+		_, err := ParseFile(write("package features\n"))
+		Expect(err).To(MatchError(ContainSubstring("no defaultFeatureGates map")))
+	})
+})
+
+var _ = Describe("SetAnnotation", func() {
+	It("sets and removes the annotation", func() {
+		obj := &metav1.ObjectMeta{}
+		Expect(SetAnnotation(obj, []Gate{{Name: "Backups", Stage: "ALPHA"}})).To(Succeed())
+		Expect(obj.GetAnnotations()).To(HaveKeyWithValue(Annotation,
+			`[{"name":"Backups","default":false,"stage":"ALPHA"}]`))
+		Expect(SetAnnotation(obj, nil)).To(Succeed())
+		Expect(obj.GetAnnotations()).NotTo(HaveKey(Annotation))
+	})
+})
@@ -0,0 +1,186 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package healthcheck generates GitOps health checks for custom resources, so tools like ArgoCD
+// and Flux know when custom resources managed by an operator are healthy.
+package healthcheck
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+const (
+	// DefaultConditionType is the condition type whose status determines health by default,
+	// which is also the condition Flux's kstatus evaluates.
+	DefaultConditionType = "Ready"
+
+	argoCDConfigMapName      = "argocd-cm"
+	argoCDNamespace          = "argocd"
+	argoCDHealthKeyPrefix    = "resource.customizations.health."
+	argoCDHealthKeySeparator = "_"
+)
+
+// Resource is a custom resource kind whose health is determined by a status condition.
+type Resource struct {
+	schema.GroupVersionKind
+}
+
+// ResourcesFromCRDs returns the storage version of each kind defined in the v1 and v1beta1 CRDs
+// in dir whose schema has a status.conditions array. Kinds without conditions are logged and skipped,
+// since their health cannot be determined.
+func ResourcesFromCRDs(dir string) (resources []Resource, err error) {
+	v1crds, v1beta1crds, err := k8sutil.GetCustomResourceDefinitions(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CRDs from %s: %v", dir, err)
+	}
+	for _, crd := range v1beta1crds {
+		converted, err := k8sutil.Convertv1beta1Tov1CustomResourceDefinition(&crd)
+		if err != nil {
+			return nil, fmt.Errorf("error converting CRD %s to v1: %v", crd.GetName(), err)
+		}
+		v1crds = append(v1crds, *converted)
+	}
+
+	for _, crd := range v1crds {
+		for _, version := range crd.Spec.Versions {
+			if !version.Storage {
+				continue
+			}
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+			if !hasStatusConditions(version.Schema) {
+				log.Warnf("Skipping %s: its schema has no status.conditions", gvk)
+				continue
+			}
+			resources = append(resources, Resource{gvk})
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].String() < resources[j].String()
+	})
+	return resources, nil
+}
+
+func hasStatusConditions(v *apiextv1.CustomResourceValidation) bool {
+	if v == nil || v.OpenAPIV3Schema == nil {
+		return false
+	}
+	status, hasStatus := v.OpenAPIV3Schema.Properties["status"]
+	if !hasStatus {
+		return false
+	}
+	conditions, hasConditions := status.Properties["conditions"]
+	return hasConditions && conditions.Type == "array"
+}
+
+// argoCDHealthLua is an ArgoCD health check, which sets the health of an object from the status
+// of its condition of type ConditionType and reports the condition's message.
+var argoCDHealthLua = template.Must(template.New("").Parse(`hs = {}
+if obj.status ~= nil and obj.status.conditions ~= nil then
+  for i, condition in ipairs(obj.status.conditions) do
+    if condition.type == "{{ .ConditionType }}" then
+      if condition.status == "True" then
+        hs.status = "Healthy"
+      elseif condition.status == "False" then
+        hs.status = "Degraded"
+      else
+        hs.status = "Progressing"
+      end
+      hs.message = condition.message
+      return hs
+    end
+  end
+end
+hs.status = "Progressing"
+hs.message = "Waiting for condition {{ .ConditionType }}"
+return hs
+`))
+
+// ArgoCDResourceCustomizations returns a patch of ArgoCD's argocd-cm ConfigMap that adds a Lua
+// health check for each resource, keyed by "resource.customizations.health.<group>_<kind>".
+func ArgoCDResourceCustomizations(resources []Resource, conditionType string) (*corev1.ConfigMap, error) {
+	buf := &bytes.Buffer{}
+	if err := argoCDHealthLua.Execute(buf, struct{ ConditionType string }{conditionType}); err != nil {
+		return nil, err
+	}
+	cm := &corev1.ConfigMap{Data: map[string]string{}}
+	cm.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	cm.SetName(argoCDConfigMapName)
+	cm.SetNamespace(argoCDNamespace)
+	for _, r := range resources {
+		cm.Data[argoCDHealthKeyPrefix+r.Group+argoCDHealthKeySeparator+r.Kind] = buf.String()
+	}
+	return cm, nil
+}
+
+// FluxHealthCheck is an entry of a Flux Kustomization's spec.healthChecks.
+type FluxHealthCheck struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// FluxHealthChecks returns a health check for each custom resource sample in samplesDir of a kind
+// in resources. Flux health checks name objects, so kinds without samples are not checked.
+// Flux determines the health of custom resources from their Ready condition.
+func FluxHealthChecks(samplesDir string, resources []Resource) (checks []FluxHealthCheck, err error) {
+	kinds := make(map[schema.GroupVersionKind]bool, len(resources))
+	for _, r := range resources {
+		kinds[r.GroupVersionKind] = true
+	}
+	paths, err := filepath.Glob(filepath.Join(samplesDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading sample %s: %v", path, err)
+		}
+		scanner := k8sutil.NewYAMLScanner(bytes.NewBuffer(b))
+		for scanner.Scan() {
+			u := unstructured.Unstructured{}
+			if err := yaml.Unmarshal(scanner.Bytes(), &u.Object); err != nil {
+				return nil, fmt.Errorf("error unmarshaling sample %s: %v", path, err)
+			}
+			if !kinds[u.GroupVersionKind()] {
+				continue
+			}
+			checks = append(checks, FluxHealthCheck{
+				APIVersion: u.GetAPIVersion(),
+				Kind:       u.GetKind(),
+				Name:       u.GetName(),
+				Namespace:  u.GetNamespace(),
+			})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error scanning sample %s: %v", path, err)
+		}
+	}
+	return checks, nil
+}
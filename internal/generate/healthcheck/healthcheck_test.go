@@ -0,0 +1,126 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const crds = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Memcached
+    plural: memcacheds
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          status:
+            type: object
+            properties:
+              conditions:
+                type: array
+                items:
+                  type: object
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: backups.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Backup
+    plural: backups
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`
+
+const samples = `apiVersion: cache.example.com/v1
+kind: Memcached
+metadata:
+  name: memcached-sample
+---
+apiVersion: cache.example.com/v1
+kind: Backup
+metadata:
+  name: backup-sample
+`
+
+var _ = Describe("Health checks", func() {
+	var (
+		dir       string
+		memcached = Resource{schema.GroupVersionKind{Group: "cache.example.com", Version: "v1", Kind: "Memcached"}}
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "healthcheck")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("finds the storage versions of kinds with status conditions", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "crds.yaml"), []byte(crds), 0644)).To(Succeed())
+		resources, err := ResourcesFromCRDs(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(Equal([]Resource{memcached}))
+	})
+	It("generates an ArgoCD health check for each kind", func() {
+		cm, err := ArgoCDResourceCustomizations([]Resource{memcached}, "Available")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.GetName()).To(Equal("argocd-cm"))
+		Expect(cm.Data).To(HaveKey("resource.customizations.health.cache.example.com_Memcached"))
+		Expect(cm.Data["resource.customizations.health.cache.example.com_Memcached"]).
+			To(ContainSubstring(`condition.type == "Available"`))
+	})
+	It("generates a Flux health check for each sample of a kind", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "samples.yaml"), []byte(samples), 0644)).To(Succeed())
+		checks, err := FluxHealthChecks(dir, []Resource{memcached})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(checks).To(Equal([]FluxHealthCheck{
+			{APIVersion: "cache.example.com/v1", Kind: "Memcached", Name: "memcached-sample"},
+		}))
+	})
+})
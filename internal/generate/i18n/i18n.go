@@ -0,0 +1,181 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package i18n localizes the UI metadata of a ClusterServiceVersion with translation files,
+// one per locale, kept next to the project's CSV base.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"golang.org/x/text/language"
+	"sigs.k8s.io/yaml"
+)
+
+// DirName is the name of the directory of translation files in a project's manifests directory.
+const DirName = "i18n"
+
+// Annotation is the CSV annotation containing the translations of every locale, as a JSON object of
+// Translations by locale, for marketplaces that choose a locale when the CSV is displayed.
+const Annotation = "operators.operatorframework.io/i18n"
+
+// shortDescriptionAnnotation is the CSV annotation with the one-line description shown in catalog tiles.
+const shortDescriptionAnnotation = "description"
+
+// Translation is the localized UI metadata of a CSV, read from the file '<locale>.yaml'.
+// Fields that are not set are left in the CSV's default language.
+type Translation struct {
+	DisplayName string `json:"displayName,omitempty"`
+	// ShortDescription is the CSV's 'description' annotation.
+	ShortDescription string `json:"shortDescription,omitempty"`
+	Description      string `json:"description,omitempty"`
+	// CRDs are translations of the owned CRD descriptions of the CSV by CRD name, ex. memcacheds.cache.example.com.
+	CRDs map[string]CRDTranslation `json:"crds,omitempty"`
+}
+
+// CRDTranslation is the localized UI metadata of an owned CRD description.
+type CRDTranslation struct {
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ReadDir returns the translations in the '<locale>.yaml' files of dir by locale, or nil if dir
+// does not exist. Locales are BCP 47 language tags, ex. 'de' or 'pt-BR'.
+func ReadDir(dir string) (map[string]Translation, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading translations: %v", err)
+	}
+	translations := map[string]Translation{}
+	for _, info := range infos {
+		ext := filepath.Ext(info.Name())
+		if info.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		locale, err := ParseLocale(strings.TrimSuffix(info.Name(), ext))
+		if err != nil {
+			return nil, fmt.Errorf("translation file %s: %v", info.Name(), err)
+		}
+		if _, ok := translations[locale]; ok {
+			return nil, fmt.Errorf("more than one translation file for locale %s", locale)
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading translation file %s: %v", info.Name(), err)
+		}
+		var t Translation
+		if err := yaml.UnmarshalStrict(b, &t); err != nil {
+			return nil, fmt.Errorf("error parsing translation file %s: %v", info.Name(), err)
+		}
+		translations[locale] = t
+	}
+	return translations, nil
+}
+
+// ParseLocale returns locale in its canonical form, ex. 'pt-BR' for 'pt_br'.
+func ParseLocale(locale string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", fmt.Errorf("invalid locale %q: %v", locale, err)
+	}
+	return tag.String(), nil
+}
+
+// Localize replaces the UI metadata of csv with its translation for locale, which must be in translations.
+func Localize(csv *v1alpha1.ClusterServiceVersion, translations map[string]Translation, locale string) error {
+	locale, err := ParseLocale(locale)
+	if err != nil {
+		return err
+	}
+	t, ok := translations[locale]
+	if !ok {
+		return fmt.Errorf("no translation for locale %s, locales are: %v", locale, Locales(translations))
+	}
+	if t.DisplayName != "" {
+		csv.Spec.DisplayName = t.DisplayName
+	}
+	if t.Description != "" {
+		csv.Spec.Description = t.Description
+	}
+	if t.ShortDescription != "" {
+		annotations := csv.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[shortDescriptionAnnotation] = t.ShortDescription
+		csv.SetAnnotations(annotations)
+	}
+	owned := csv.Spec.CustomResourceDefinitions.Owned
+	for name, crdT := range t.CRDs {
+		found := false
+		for i := range owned {
+			if owned[i].Name != name {
+				continue
+			}
+			found = true
+			if crdT.DisplayName != "" {
+				owned[i].DisplayName = crdT.DisplayName
+			}
+			if crdT.Description != "" {
+				owned[i].Description = crdT.Description
+			}
+		}
+		if !found {
+			return fmt.Errorf("translation for locale %s has CRD %s, which the CSV does not own", locale, name)
+		}
+	}
+	return nil
+}
+
+// Locales returns the sorted locales of translations.
+func Locales(translations map[string]Translation) []string {
+	locales := make([]string, 0, len(translations))
+	for locale := range translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// SetAnnotation writes translations to csv's Annotation, or removes it if there are none.
+func SetAnnotation(csv *v1alpha1.ClusterServiceVersion, translations map[string]Translation) error {
+	annotations := csv.GetAnnotations()
+	if len(translations) == 0 {
+		if _, ok := annotations[Annotation]; ok {
+			delete(annotations, Annotation)
+			csv.SetAnnotations(annotations)
+		}
+		return nil
+	}
+	b, err := json.Marshal(translations)
+	if err != nil {
+		return fmt.Errorf("error marshaling translations: %v", err)
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[Annotation] = string(b)
+	csv.SetAnnotations(annotations)
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+const deTranslation = `displayName: Memcached-Operator
+shortDescription: Verwaltet Memcached-Cluster
+crds:
+  memcacheds.cache.example.com:
+    description: Ein Memcached-Cluster
+`
+
+var _ = Describe("Translations", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "i18n")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	write := func(name, content string) {
+		ExpectWithOffset(1, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).To(Succeed())
+	}
+	newCSV := func() *v1alpha1.ClusterServiceVersion {
+		csv := &v1alpha1.ClusterServiceVersion{}
+		csv.SetAnnotations(map[string]string{"description": "Manages Memcached clusters"})
+		csv.Spec.DisplayName = "Memcached Operator"
+		csv.Spec.Description = "The Memcached Operator ..."
+		csv.Spec.CustomResourceDefinitions.Owned = []v1alpha1.CRDDescription{{
+			Name: "memcacheds.cache.example.com", DisplayName: "Memcached", Description: "A Memcached cluster",
+		}}
+		return csv
+	}
+
+	Describe("ReadDir", func() {
+		It("returns nil if the directory does not exist", func() {
+			Expect(ReadDir(filepath.Join(dir, "missing"))).To(BeNil())
+		})
+		It("reads translation files by canonical locale", func() {
+			write("de.yaml", deTranslation)
+			write("pt_br.yml", "displayName: Operador Memcached\n")
+			write("README.md", "Translations of the CSV")
+			translations, err := ReadDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(Locales(translations)).To(Equal([]string{"de", "pt-BR"}))
+			Expect(translations["de"].CRDs["memcacheds.cache.example.com"].Description).To(Equal("Ein Memcached-Cluster"))
+		})
+		It("rejects invalid locales, duplicate locales, and unknown fields", func() {
+			write("not a locale.yaml", "")
+			_, err := ReadDir(dir)
+			Expect(err).To(MatchError(ContainSubstring(`translation file not a locale.yaml: invalid locale "not a locale"`)))
+			Expect(os.Remove(filepath.Join(dir, "not a locale.yaml"))).To(Succeed())
+
+			write("pt-BR.yaml", "")
+			write("pt_BR.yaml", "")
+			_, err = ReadDir(dir)
+			Expect(err).To(MatchError("more than one translation file for locale pt-BR"))
+			Expect(os.Remove(filepath.Join(dir, "pt_BR.yaml"))).To(Succeed())
+
+			write("de.yaml", "name: Memcached-Operator\n")
+			_, err = ReadDir(dir)
+			Expect(err).To(MatchError(ContainSubstring("error parsing translation file de.yaml")))
+		})
+	})
+
+	Describe("Localize", func() {
+		var translations map[string]Translation
+
+		BeforeEach(func() {
+			write("de.yaml", deTranslation)
+			var err error
+			translations, err = ReadDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("replaces the fields a translation sets", func() {
+			csv := newCSV()
+			Expect(Localize(csv, translations, "DE")).To(Succeed())
+			Expect(csv.Spec.DisplayName).To(Equal("Memcached-Operator"))
+			Expect(csv.Spec.Description).To(Equal("The Memcached Operator ..."))
+			Expect(csv.GetAnnotations()).To(HaveKeyWithValue("description", "Verwaltet Memcached-Cluster"))
+			Expect(csv.Spec.CustomResourceDefinitions.Owned[0].DisplayName).To(Equal("Memcached"))
+			Expect(csv.Spec.CustomResourceDefinitions.Owned[0].Description).To(Equal("Ein Memcached-Cluster"))
+		})
+		It("returns an error for missing locales and CRDs the CSV does not own", func() {
+			Expect(Localize(newCSV(), translations, "fr")).To(MatchError("no translation for locale fr, locales are: [de]"))
+			csv := newCSV()
+			csv.Spec.CustomResourceDefinitions.Owned = nil
+			Expect(Localize(csv, translations, "de")).To(MatchError(
+				"translation for locale de has CRD memcacheds.cache.example.com, which the CSV does not own"))
+		})
+	})
+
+	Describe("SetAnnotation", func() {
+		It("writes translations by locale, and removes the annotation if there are none", func() {
+			csv := newCSV()
+			Expect(SetAnnotation(csv, map[string]Translation{"de": {DisplayName: "Memcached-Operator"}})).To(Succeed())
+			Expect(csv.GetAnnotations()).To(HaveKeyWithValue(Annotation, `{"de":{"displayName":"Memcached-Operator"}}`))
+			Expect(SetAnnotation(csv, nil)).To(Succeed())
+			Expect(csv.GetAnnotations()).NotTo(HaveKey(Annotation))
+		})
+	})
+})
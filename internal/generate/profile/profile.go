@@ -0,0 +1,178 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package profile adjusts the manifests of a bundle for the Kubernetes distribution it is installed on,
+// so bundles for several distributions can be generated from one project.
+package profile
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+// Profile is a Kubernetes distribution bundles are generated for.
+type Profile string
+
+const (
+	// Vanilla is upstream Kubernetes.
+	Vanilla Profile = "vanilla"
+	// OpenShift is Red Hat OpenShift, which assigns pod UIDs and serves Routes.
+	OpenShift Profile = "openshift"
+	// EKS is Amazon Elastic Kubernetes Service.
+	EKS Profile = "eks"
+	// GKE is Google Kubernetes Engine.
+	GKE Profile = "gke"
+)
+
+// Profiles are all known profiles.
+var Profiles = []Profile{Vanilla, OpenShift, EKS, GKE}
+
+const (
+	// ProfilesAnnotation is set on an object to a comma-separated list of the profiles whose bundles
+	// include it. The annotation is removed from bundle manifests.
+	ProfilesAnnotation = "operator-sdk.operatorframework.io/profiles"
+	// OpenShiftVersionsAnnotation is the bundle annotation declaring the OpenShift versions a bundle supports.
+	OpenShiftVersionsAnnotation = "com.redhat.openshift.versions"
+	// DefaultOpenShiftVersions are the OpenShift versions of OpenShift bundles by default.
+	DefaultOpenShiftVersions = "v4.6"
+)
+
+// Parse returns the profiles named in names, or an error if a name is unknown or repeated.
+func Parse(names []string) (profiles []Profile, err error) {
+	seen := map[Profile]bool{}
+	for _, name := range names {
+		p := Profile(strings.TrimSpace(name))
+		if !p.known() {
+			return nil, fmt.Errorf("unknown profile %q, must be one of %v", name, Profiles)
+		}
+		if seen[p] {
+			return nil, fmt.Errorf("profile %q is set more than once", name)
+		}
+		seen[p] = true
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+func (p Profile) known() bool {
+	for _, known := range Profiles {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns a copy of col with p's changes: the security contexts of Deployments' pods are set
+// to satisfy the "restricted" Pod Security Standard where unset, and on OpenShift, fixed user, group,
+// and fsGroup IDs, which OpenShift's restricted SecurityContextConstraints reject, are removed.
+// col is not modified.
+func (p Profile) Apply(col *collector.Manifests) *collector.Manifests {
+	out := *col
+	out.Deployments = make([]appsv1.Deployment, len(col.Deployments))
+	for i := range col.Deployments {
+		dep := col.Deployments[i].DeepCopy()
+		p.setSecurityContexts(&dep.Spec.Template.Spec)
+		out.Deployments[i] = *dep
+	}
+	return &out
+}
+
+// Resources returns copies of the objects in objs that are optional resources of p's bundles, without
+// ProfilesAnnotation: objects with ProfilesAnnotation listing p, Routes and OpenShift console
+// resources on OpenShift, and Ingresses elsewhere. Other objects are not profile resources, and
+// are not returned.
+func (p Profile) Resources(objs []unstructured.Unstructured) (resources []unstructured.Unstructured) {
+	for i := range objs {
+		if !p.includes(&objs[i]) {
+			continue
+		}
+		obj := objs[i].DeepCopy()
+		annotations := obj.GetAnnotations()
+		if _, ok := annotations[ProfilesAnnotation]; ok {
+			delete(annotations, ProfilesAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+		resources = append(resources, *obj)
+	}
+	return resources
+}
+
+func (p Profile) includes(obj *unstructured.Unstructured) bool {
+	if names, ok := obj.GetAnnotations()[ProfilesAnnotation]; ok {
+		for _, name := range strings.Split(names, ",") {
+			if Profile(strings.TrimSpace(name)) == p {
+				return true
+			}
+		}
+		return false
+	}
+	gvk := obj.GroupVersionKind()
+	switch {
+	case gvk.Group == "route.openshift.io" && gvk.Kind == "Route", gvk.Group == "console.openshift.io":
+		return p == OpenShift
+	case (gvk.Group == "networking.k8s.io" || gvk.Group == "extensions") && gvk.Kind == "Ingress":
+		return p != OpenShift
+	}
+	return false
+}
+
+// BundleAnnotations returns the bundle metadata annotations of p's bundles. openShiftVersions is
+// the value of OpenShiftVersionsAnnotation on OpenShift.
+func (p Profile) BundleAnnotations(openShiftVersions string) map[string]string {
+	if p == OpenShift {
+		return map[string]string{OpenShiftVersionsAnnotation: openShiftVersions}
+	}
+	return map[string]string{}
+}
+
+func (p Profile) setSecurityContexts(spec *corev1.PodSpec) {
+	if spec.SecurityContext == nil {
+		spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	psc := spec.SecurityContext
+	if psc.RunAsNonRoot == nil {
+		psc.RunAsNonRoot = boolPtr(true)
+	}
+	if p == OpenShift {
+		psc.RunAsUser, psc.RunAsGroup, psc.FSGroup = nil, nil, nil
+	}
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			if containers[i].SecurityContext == nil {
+				containers[i].SecurityContext = &corev1.SecurityContext{}
+			}
+			sc := containers[i].SecurityContext
+			if sc.AllowPrivilegeEscalation == nil {
+				sc.AllowPrivilegeEscalation = boolPtr(false)
+			}
+			if sc.Capabilities == nil {
+				sc.Capabilities = &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}}
+			}
+			if p == OpenShift {
+				sc.RunAsUser, sc.RunAsGroup = nil, nil
+			}
+		}
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
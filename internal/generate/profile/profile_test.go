@@ -0,0 +1,127 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+var _ = Describe("Profile", func() {
+	Describe("Parse", func() {
+		It("parses known profiles", func() {
+			profiles, err := Parse([]string{"vanilla", " openshift"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(profiles).To(Equal([]Profile{Vanilla, OpenShift}))
+		})
+		It("fails on unknown or repeated profiles", func() {
+			_, err := Parse([]string{"aks"})
+			Expect(err).To(MatchError(ContainSubstring(`unknown profile "aks"`)))
+			_, err = Parse([]string{"eks", "eks"})
+			Expect(err).To(MatchError(`profile "eks" is set more than once`))
+		})
+	})
+
+	Describe("Apply", func() {
+		var col *collector.Manifests
+
+		BeforeEach(func() {
+			uid := int64(65532)
+			dep := appsv1.Deployment{}
+			dep.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsUser: &uid, FSGroup: &uid}
+			dep.Spec.Template.Spec.Containers = []corev1.Container{
+				{Name: "manager", SecurityContext: &corev1.SecurityContext{RunAsUser: &uid}},
+				{Name: "proxy", SecurityContext: &corev1.SecurityContext{
+					Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_BIND_SERVICE"}},
+				}},
+			}
+			col = &collector.Manifests{Deployments: []appsv1.Deployment{dep}}
+		})
+
+		It("sets unset restricted security contexts", func() {
+			spec := Vanilla.Apply(col).Deployments[0].Spec.Template.Spec
+			Expect(*spec.SecurityContext.RunAsNonRoot).To(BeTrue())
+			Expect(*spec.SecurityContext.RunAsUser).To(Equal(int64(65532)))
+			manager, proxy := spec.Containers[0].SecurityContext, spec.Containers[1].SecurityContext
+			Expect(*manager.AllowPrivilegeEscalation).To(BeFalse())
+			Expect(manager.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+			Expect(*manager.RunAsUser).To(Equal(int64(65532)))
+			Expect(proxy.Capabilities.Add).To(Equal([]corev1.Capability{"NET_BIND_SERVICE"}))
+			Expect(proxy.Capabilities.Drop).To(BeEmpty())
+
+			// The collected manifests are not modified.
+			Expect(col.Deployments[0].Spec.Template.Spec.SecurityContext.RunAsNonRoot).To(BeNil())
+		})
+		It("removes fixed IDs on OpenShift", func() {
+			spec := OpenShift.Apply(col).Deployments[0].Spec.Template.Spec
+			Expect(*spec.SecurityContext.RunAsNonRoot).To(BeTrue())
+			Expect(spec.SecurityContext.RunAsUser).To(BeNil())
+			Expect(spec.SecurityContext.FSGroup).To(BeNil())
+			Expect(spec.Containers[0].SecurityContext.RunAsUser).To(BeNil())
+		})
+	})
+
+	Describe("Resources", func() {
+		newObject := func(apiVersion, kind, name, profiles string) unstructured.Unstructured {
+			u := unstructured.Unstructured{}
+			u.SetAPIVersion(apiVersion)
+			u.SetKind(kind)
+			u.SetName(name)
+			if profiles != "" {
+				u.SetAnnotations(map[string]string{ProfilesAnnotation: profiles})
+			}
+			return u
+		}
+		names := func(objs []unstructured.Unstructured) (names []string) {
+			for _, obj := range objs {
+				names = append(names, obj.GetName())
+			}
+			return names
+		}
+		objs := []unstructured.Unstructured{
+			newObject("route.openshift.io/v1", "Route", "route", ""),
+			newObject("networking.k8s.io/v1", "Ingress", "ingress", ""),
+			newObject("console.openshift.io/v1", "ConsoleYAMLSample", "sample", ""),
+			newObject("v1", "ConfigMap", "cloud-config", "eks, gke"),
+			newObject("v1", "ConfigMap", "other", ""),
+		}
+
+		It("selects the optional resources of each profile", func() {
+			Expect(names(OpenShift.Resources(objs))).To(Equal([]string{"route", "sample"}))
+			Expect(names(Vanilla.Resources(objs))).To(Equal([]string{"ingress"}))
+			Expect(names(GKE.Resources(objs))).To(Equal([]string{"ingress", "cloud-config"}))
+		})
+		It("removes the profiles annotation", func() {
+			resources := EKS.Resources(objs)
+			Expect(resources).To(HaveLen(2))
+			Expect(resources[1].GetAnnotations()).To(BeEmpty())
+			Expect(objs[3].GetAnnotations()).To(HaveKey(ProfilesAnnotation))
+		})
+	})
+
+	Describe("BundleAnnotations", func() {
+		It("sets the OpenShift versions of OpenShift bundles", func() {
+			Expect(OpenShift.BundleAnnotations("v4.8-v4.12")).To(Equal(map[string]string{
+				OpenShiftVersionsAnnotation: "v4.8-v4.12",
+			}))
+			Expect(Vanilla.BundleAnnotations("v4.8")).To(BeEmpty())
+		})
+	})
+})
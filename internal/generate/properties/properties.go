@@ -0,0 +1,288 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package properties reads, validates, and writes the properties a bundle declares in its
+// metadata/properties.yaml, such as the constraints OLM must satisfy to install it.
+package properties
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// FileName is the name of a bundle's properties file in its metadata directory, and of the
+// properties file in a project's manifests directory.
+const FileName = "properties.yaml"
+
+// Types of properties validated by this package. Other properties are only required to have a type.
+const (
+	TypeConstraint = "olm.constraint"
+	TypePackage    = "olm.package"
+	TypeGVK        = "olm.gvk"
+)
+
+// File is a properties file.
+type File struct {
+	Properties []Property `json:"properties"`
+}
+
+// Property is a typed property of a bundle.
+type Property struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Constraint is the value of an olm.constraint property, which must be satisfied for its bundle to be installed.
+// Exactly one of its constraint fields must be set.
+type Constraint struct {
+	FailureMessage string `json:"failureMessage,omitempty"`
+
+	CEL     *CELConstraint      `json:"cel,omitempty"`
+	GVK     *GVKConstraint      `json:"gvk,omitempty"`
+	Package *PackageConstraint  `json:"package,omitempty"`
+	All     *CompoundConstraint `json:"all,omitempty"`
+	Any     *CompoundConstraint `json:"any,omitempty"`
+	Not     *CompoundConstraint `json:"not,omitempty"`
+}
+
+// CELConstraint is satisfied by a bundle whose properties its CEL rule evaluates to true for.
+type CELConstraint struct {
+	Rule string `json:"rule"`
+}
+
+// GVKConstraint is satisfied by a bundle providing an API.
+type GVKConstraint struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// PackageConstraint is satisfied by a bundle of a package in a version range.
+type PackageConstraint struct {
+	PackageName  string `json:"packageName"`
+	VersionRange string `json:"versionRange"`
+}
+
+// CompoundConstraint combines constraints.
+type CompoundConstraint struct {
+	Constraints []Constraint `json:"constraints"`
+}
+
+// Parse returns the properties in kvs, which have the form '<type>=<value>'. Values that are JSON objects
+// or arrays are kept as-is, and others are strings, so ex. 'olm.maxOpenShiftVersion=4.10' is not a number.
+func Parse(kvs []string) ([]Property, error) {
+	props := make([]Property, 0, len(kvs))
+	for _, kv := range kvs {
+		split := strings.SplitN(kv, "=", 2)
+		if len(split) != 2 || split[0] == "" {
+			return nil, fmt.Errorf("property %q must have the form <type>=<value>", kv)
+		}
+		typ, value := split[0], strings.TrimSpace(split[1])
+		var raw json.RawMessage
+		if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+			if !json.Valid([]byte(value)) {
+				return nil, fmt.Errorf("property %q has an invalid JSON value", typ)
+			}
+			raw = json.RawMessage(value)
+		} else {
+			b, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			raw = b
+		}
+		props = append(props, Property{Type: typ, Value: raw})
+	}
+	return props, nil
+}
+
+// ReadFile returns the properties in the file at path, or none if it does not exist.
+func ReadFile(path string) ([]Property, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	f := File{}
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return f.Properties, nil
+}
+
+// WriteBundle writes props to the properties file in the metadata directory of the bundle in bundleRoot.
+// An existing file is removed if props is empty.
+func WriteBundle(bundleRoot string, props []Property) error {
+	path := filepath.Join(bundleRoot, bundle.MetadataDir, FileName)
+	if len(props) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	b, err := yaml.Marshal(File{Properties: props})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0666)
+}
+
+// Validate returns an error describing each invalid property in props.
+func Validate(props []Property) error {
+	var errs []string
+	for i, p := range props {
+		if err := validateProperty(p); err != nil {
+			errs = append(errs, fmt.Sprintf("properties[%d]: %v", i, err))
+		}
+	}
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func validateProperty(p Property) error {
+	if p.Type == "" {
+		return errors.New("type is required")
+	}
+	if len(p.Value) == 0 || string(p.Value) == "null" {
+		return fmt.Errorf("%s: value is required", p.Type)
+	}
+	var err error
+	switch p.Type {
+	case TypeConstraint:
+		c := Constraint{}
+		if err = unmarshalStrict(p.Value, &c); err == nil {
+			err = validateConstraint(c)
+		}
+	case TypePackage:
+		v := struct {
+			PackageName string `json:"packageName"`
+			Version     string `json:"version"`
+		}{}
+		if err = unmarshalStrict(p.Value, &v); err == nil && (v.PackageName == "" || v.Version == "") {
+			err = errors.New("packageName and version are required")
+		}
+	case TypeGVK:
+		v := GVKConstraint{}
+		if err = unmarshalStrict(p.Value, &v); err == nil {
+			err = validateGVK(v)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", p.Type, err)
+	}
+	return nil
+}
+
+func unmarshalStrict(raw json.RawMessage, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// validateConstraint validates c and the constraints it combines.
+func validateConstraint(c Constraint) error {
+	set := 0
+	for _, isSet := range []bool{c.CEL != nil, c.GVK != nil, c.Package != nil, c.All != nil, c.Any != nil, c.Not != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of cel, gvk, package, all, any, or not must be set")
+	}
+
+	switch {
+	case c.CEL != nil:
+		if err := validateRule(c.CEL.Rule); err != nil {
+			return fmt.Errorf("cel: %v", err)
+		}
+	case c.GVK != nil:
+		if err := validateGVK(*c.GVK); err != nil {
+			return fmt.Errorf("gvk: %v", err)
+		}
+	case c.Package != nil:
+		if c.Package.PackageName == "" || c.Package.VersionRange == "" {
+			return errors.New("package: packageName and versionRange are required")
+		}
+	}
+	for name, cc := range map[string]*CompoundConstraint{"all": c.All, "any": c.Any, "not": c.Not} {
+		if cc == nil {
+			continue
+		}
+		if len(cc.Constraints) == 0 {
+			return fmt.Errorf("%s: constraints are required", name)
+		}
+		for i, sub := range cc.Constraints {
+			if err := validateConstraint(sub); err != nil {
+				return fmt.Errorf("%s.constraints[%d]: %v", name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateGVK(gvk GVKConstraint) error {
+	if gvk.Version == "" || gvk.Kind == "" {
+		return errors.New("version and kind are required")
+	}
+	return nil
+}
+
+// celEnv declares the variables and functions OLM evaluates constraint rules with: the properties of a
+// candidate bundle, and semverCompare, which compares two semantic versions like strings.Compare.
+var celEnv = func() *cel.Env {
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar("properties", decls.NewListType(decls.NewMapType(decls.String, decls.Dyn))),
+		decls.NewFunction("semverCompare",
+			decls.NewOverload("semver_compare_dyn_string", []*exprpb.Type{decls.Dyn, decls.String}, decls.Int)),
+	))
+	if err != nil {
+		panic(err)
+	}
+	return env
+}()
+
+// validateRule checks that rule is a valid CEL expression evaluating to a bool.
+func validateRule(rule string) error {
+	if strings.TrimSpace(rule) == "" {
+		return errors.New("rule is required")
+	}
+	ast, issues := celEnv.Compile(rule)
+	if issues != nil && issues.Err() != nil {
+		return fmt.Errorf("invalid rule %q: %v", rule, issues.Err())
+	}
+	if t := ast.ResultType(); t.GetPrimitive() != exprpb.Type_BOOL && t.GetDyn() == nil {
+		return fmt.Errorf("rule %q must evaluate to a bool", rule)
+	}
+	return nil
+}
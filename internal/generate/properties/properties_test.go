@@ -0,0 +1,122 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package properties
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Properties", func() {
+	constraint := func(value string) Property {
+		return Property{Type: TypeConstraint, Value: json.RawMessage(value)}
+	}
+
+	Describe("Parse", func() {
+		It("keeps JSON values and quotes others", func() {
+			props, err := Parse([]string{
+				"olm.maxOpenShiftVersion=4.10",
+				`olm.constraint={"cel":{"rule":"true"}}`,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(props).To(Equal([]Property{
+				{Type: "olm.maxOpenShiftVersion", Value: json.RawMessage(`"4.10"`)},
+				constraint(`{"cel":{"rule":"true"}}`),
+			}))
+		})
+		It("fails on malformed properties", func() {
+			_, err := Parse([]string{"olm.maxOpenShiftVersion"})
+			Expect(err).To(MatchError(`property "olm.maxOpenShiftVersion" must have the form <type>=<value>`))
+			_, err = Parse([]string{`olm.constraint={"cel":`})
+			Expect(err).To(MatchError(`property "olm.constraint" has an invalid JSON value`))
+		})
+	})
+
+	Describe("Validate", func() {
+		It("passes valid properties", func() {
+			Expect(Validate([]Property{
+				{Type: "olm.maxOpenShiftVersion", Value: json.RawMessage(`"4.10"`)},
+				{Type: TypePackage, Value: json.RawMessage(`{"packageName":"memcached-operator","version":"0.0.1"}`)},
+				constraint(`{"failureMessage":"requires etcd","package":{"packageName":"etcd","versionRange":">=0.9.0"}}`),
+				constraint(`{"cel":{"rule":"properties.exists(p, p.type == 'olm.package' && semverCompare(p.value.version, '1.0.0') >= 0)"}}`),
+				constraint(`{"any":{"constraints":[{"gvk":{"group":"etcd.database.coreos.com","version":"v1beta2","kind":"EtcdCluster"}},` +
+					`{"not":{"constraints":[{"cel":{"rule":"false"}}]}}]}}`),
+			})).To(Succeed())
+		})
+		It("fails on invalid CEL rules", func() {
+			err := Validate([]Property{constraint(`{"cel":{"rule":"properties.exists(p, p.type == "}}`)})
+			Expect(err).To(MatchError(ContainSubstring("properties[0]: olm.constraint: cel: invalid rule")))
+			err = Validate([]Property{constraint(`{"cel":{"rule":"semverCompare('1.0.0', '0.9.0')"}}`)})
+			Expect(err).To(MatchError(ContainSubstring("must evaluate to a bool")))
+			err = Validate([]Property{constraint(`{"cel":{"rule":"unknown(properties)"}}`)})
+			Expect(err).To(MatchError(ContainSubstring("undeclared reference to 'unknown'")))
+		})
+		It("fails on malformed constraints", func() {
+			Expect(Validate([]Property{constraint(`{"failureMessage":"nothing"}`)})).
+				To(MatchError("properties[0]: olm.constraint: exactly one of cel, gvk, package, all, any, or not must be set"))
+			Expect(Validate([]Property{constraint(`{"cel":{"rule":"true"},"gvk":{"version":"v1","kind":"Pod"}}`)})).
+				To(MatchError(ContainSubstring("exactly one of")))
+			Expect(Validate([]Property{constraint(`{"all":{"constraints":[{"gvk":{"kind":"Pod"}}]}}`)})).
+				To(MatchError("properties[0]: olm.constraint: all.constraints[0]: gvk: version and kind are required"))
+			Expect(Validate([]Property{constraint(`{"not":{"constraints":[]}}`)})).
+				To(MatchError("properties[0]: olm.constraint: not: constraints are required"))
+			Expect(Validate([]Property{constraint(`{"cel":{"rules":"true"}}`)})).
+				To(MatchError(ContainSubstring(`unknown field "rules"`)))
+		})
+		It("reports each invalid property", func() {
+			Expect(Validate([]Property{
+				{Value: json.RawMessage(`"x"`)},
+				{Type: TypePackage, Value: json.RawMessage(`{"packageName":"etcd"}`)},
+				{Type: TypeGVK},
+			})).To(MatchError("properties[0]: type is required; " +
+				"properties[1]: olm.package: packageName and version are required; " +
+				"properties[2]: olm.gvk: value is required"))
+		})
+	})
+
+	Describe("ReadFile and WriteBundle", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "properties")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("writes bundle properties that read back the same", func() {
+			props := []Property{constraint(`{"cel":{"rule":"true"}}`)}
+			Expect(WriteBundle(dir, props)).To(Succeed())
+			path := filepath.Join(dir, "metadata", FileName)
+			b, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal("properties:\n- type: olm.constraint\n  value:\n    cel:\n      rule: \"true\"\n"))
+			Expect(ReadFile(path)).To(Equal([]Property{constraint(`{"cel":{"rule":"true"}}`)}))
+
+			Expect(WriteBundle(dir, nil)).To(Succeed())
+			Expect(path).NotTo(BeAnExistingFile())
+		})
+		It("reads no properties from a missing file", func() {
+			Expect(ReadFile(filepath.Join(dir, FileName))).To(BeEmpty())
+		})
+	})
+})
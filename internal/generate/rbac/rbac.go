@@ -0,0 +1,339 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rbac finds the minimal RBAC rules a controller requires by analyzing
+// its controller-runtime client calls and watches.
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/markbates/inflect"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/tools/go/packages"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	clientPkgPath       = "sigs.k8s.io/controller-runtime/pkg/client"
+	builderPkgPath      = "sigs.k8s.io/controller-runtime/pkg/builder"
+	sourcePkgPath       = "sigs.k8s.io/controller-runtime/pkg/source"
+	recordPkgPath       = "k8s.io/client-go/tools/record"
+	unstructuredPkgPath = "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	statusSubresource = "status"
+)
+
+var (
+	// clientVerbs maps controller-runtime client methods to the verb they require.
+	clientVerbs = map[string]string{
+		"Get":         "get",
+		"List":        "list",
+		"Create":      "create",
+		"Update":      "update",
+		"Patch":       "patch",
+		"Delete":      "delete",
+		"DeleteAllOf": "deletecollection",
+	}
+	// cacheVerbs are required to read an object from the manager's cache, which backs
+	// both the default client's reads and watches.
+	cacheVerbs = []string{"get", "list", "watch"}
+	// eventVerbs are required to record events.
+	eventVerbs = []string{"create", "patch"}
+	// verbOrder is the order in which verbs are written, matching kubebuilder's scaffolded markers.
+	verbOrder = []string{"get", "list", "watch", "create", "update", "patch", "delete", "deletecollection"}
+)
+
+// groupResource is an API group and resource, ex. "apps" and "deployments" or "deployments/status".
+type groupResource struct {
+	group, resource string
+}
+
+// Analyzer finds the RBAC rules required by controller code.
+type Analyzer struct {
+	// GVKs are the project's API types, used to find the group of types defined in packages
+	// without a GroupName constant, like those scaffolded by kubebuilder.
+	GVKs []schema.GroupVersionKind
+
+	verbs map[groupResource]map[string]struct{}
+}
+
+// Analyze loads the Go packages matching patterns and returns the rules required
+// by the client calls, watches, and event recorders in them.
+func (a *Analyzer) Analyze(patterns ...string) ([]rbacv1.PolicyRule, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("error loading packages: %v", err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, errors.New("one or more packages had errors")
+	}
+
+	a.verbs = make(map[groupResource]map[string]struct{})
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				switch t := n.(type) {
+				case *ast.CallExpr:
+					a.inspectCall(pkg, t)
+				case *ast.CompositeLit:
+					a.inspectCompositeLit(pkg, t)
+				}
+				return true
+			})
+		}
+	}
+	return a.rules(), nil
+}
+
+// inspectCall adds verbs for client calls, builder watches, and event recorder calls.
+func (a *Analyzer) inspectCall(pkg *packages.Package, call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	fn, ok := pkg.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return
+	}
+
+	switch fn.Pkg().Path() {
+	case clientPkgPath:
+		verb, isClientVerb := clientVerbs[fn.Name()]
+		if !isClientVerb {
+			return
+		}
+		// Get is the only method whose object argument follows a key.
+		objIdx := 1
+		if fn.Name() == "Get" {
+			objIdx = 2
+		}
+		if len(call.Args) <= objIdx {
+			return
+		}
+		subresource := ""
+		if recvName(fn) == "StatusWriter" {
+			subresource = statusSubresource
+		}
+		verbs := []string{verb}
+		if verb == "get" || verb == "list" {
+			verbs = cacheVerbs
+		}
+		a.addForExpr(pkg, call.Args[objIdx], subresource, verbs...)
+	case builderPkgPath:
+		// Watches arguments are source.Kinds, which are handled as composite literals.
+		if (fn.Name() == "For" || fn.Name() == "Owns") && len(call.Args) > 0 {
+			a.addForExpr(pkg, call.Args[0], "", cacheVerbs...)
+		}
+	case recordPkgPath:
+		if strings.Contains(fn.Name(), "Event") {
+			a.add(groupResource{group: "", resource: "events"}, eventVerbs...)
+		}
+	}
+}
+
+// inspectCompositeLit adds verbs for the type watched by a source.Kind.
+func (a *Analyzer) inspectCompositeLit(pkg *packages.Package, lit *ast.CompositeLit) {
+	named, ok := pkg.TypesInfo.TypeOf(lit).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != sourcePkgPath || named.Obj().Name() != "Kind" {
+		return
+	}
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Type" {
+				a.addForExpr(pkg, kv.Value, "", cacheVerbs...)
+			}
+		}
+	}
+}
+
+// addForExpr adds verbs for the resource of the type of expr, logging a warning if that resource
+// cannot be determined statically, ex. expr is an unstructured object or an interface.
+func (a *Analyzer) addForExpr(pkg *packages.Package, expr ast.Expr, subresource string, verbs ...string) {
+	gr, err := a.resourceFor(pkg.TypesInfo.TypeOf(expr))
+	if err != nil {
+		log.Warnf("%s: skipping %s: %v", pkg.Fset.Position(expr.Pos()), strings.Join(verbs, ";"), err)
+		return
+	}
+	if subresource != "" {
+		gr.resource += "/" + subresource
+	}
+	a.add(gr, verbs...)
+}
+
+func (a *Analyzer) add(gr groupResource, verbs ...string) {
+	if _, ok := a.verbs[gr]; !ok {
+		a.verbs[gr] = make(map[string]struct{})
+	}
+	for _, verb := range verbs {
+		a.verbs[gr][verb] = struct{}{}
+	}
+}
+
+// resourceFor returns the group and resource of an API type t or list of t.
+func (a *Analyzer) resourceFor(t types.Type) (gr groupResource, err error) {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return gr, fmt.Errorf("type %v is not a named API type", t)
+	}
+	pkg := named.Obj().Pkg()
+	if pkg.Path() == unstructuredPkgPath {
+		return gr, fmt.Errorf("type of unstructured object cannot be determined")
+	}
+	if _, isInterface := named.Underlying().(*types.Interface); isInterface {
+		return gr, fmt.Errorf("type of %v object cannot be determined", t)
+	}
+
+	kind := strings.TrimSuffix(named.Obj().Name(), "List")
+	if gr.group, err = a.groupFor(pkg, kind); err != nil {
+		return gr, err
+	}
+	gr.resource = inflect.Pluralize(strings.ToLower(kind))
+	return gr, nil
+}
+
+// groupFor returns the API group of kind defined in pkg, either from pkg's GroupName constant
+// or the group of a project GVK with the same kind and version as pkg.
+func (a *Analyzer) groupFor(pkg *types.Package, kind string) (string, error) {
+	if c, ok := pkg.Scope().Lookup("GroupName").(*types.Const); ok && c.Val().Kind() == constant.String {
+		return constant.StringVal(c.Val()), nil
+	}
+	version := pkg.Path()[strings.LastIndex(pkg.Path(), "/")+1:]
+	for _, gvk := range a.GVKs {
+		if gvk.Kind == kind && gvk.Version == version {
+			return gvk.Group, nil
+		}
+	}
+	return "", fmt.Errorf("API group of %s.%s not found", pkg.Path(), kind)
+}
+
+// rules returns one rule per group and resource found by the Analyzer.
+func (a *Analyzer) rules() (rules []rbacv1.PolicyRule) {
+	for gr, verbSet := range a.verbs {
+		rule := rbacv1.PolicyRule{APIGroups: []string{gr.group}, Resources: []string{gr.resource}}
+		for _, verb := range verbOrder {
+			if _, ok := verbSet[verb]; ok {
+				rule.Verbs = append(rule.Verbs, verb)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].APIGroups[0] != rules[j].APIGroups[0] {
+			return rules[i].APIGroups[0] < rules[j].APIGroups[0]
+		}
+		return rules[i].Resources[0] < rules[j].Resources[0]
+	})
+	return rules
+}
+
+// recvName returns the name of fn's receiver type, or an empty string if fn is not a method.
+func recvName(fn *types.Func) string {
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return ""
+	}
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// Markers returns a kubebuilder RBAC marker for each rule.
+func Markers(rules []rbacv1.PolicyRule) (markers []string) {
+	for _, rule := range rules {
+		groups := make([]string, len(rule.APIGroups))
+		for i, group := range rule.APIGroups {
+			if group == "" {
+				group = "core"
+			}
+			groups[i] = group
+		}
+		markers = append(markers, fmt.Sprintf("// +kubebuilder:rbac:groups=%s,resources=%s,verbs=%s",
+			strings.Join(groups, ";"), strings.Join(rule.Resources, ";"), strings.Join(rule.Verbs, ";")))
+	}
+	return markers
+}
+
+// Unused returns the parts of existing rules not covered by required, as one rule per group and resource.
+// Rules with resource names, non-resource URLs, or wildcards are not compared.
+func Unused(required, existing []rbacv1.PolicyRule) (unused []rbacv1.PolicyRule) {
+	covered := make(map[groupResource]map[string]struct{})
+	for _, rule := range required {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				gr := groupResource{group, resource}
+				if _, ok := covered[gr]; !ok {
+					covered[gr] = make(map[string]struct{})
+				}
+				for _, verb := range rule.Verbs {
+					covered[gr][verb] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for _, rule := range existing {
+		if len(rule.ResourceNames) != 0 || len(rule.NonResourceURLs) != 0 || hasWildcard(rule) {
+			continue
+		}
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				var verbs []string
+				for _, verb := range rule.Verbs {
+					if _, ok := covered[groupResource{group, resource}][verb]; !ok {
+						verbs = append(verbs, verb)
+					}
+				}
+				if len(verbs) != 0 {
+					unused = append(unused, rbacv1.PolicyRule{
+						APIGroups: []string{group},
+						Resources: []string{resource},
+						Verbs:     verbs,
+					})
+				}
+			}
+		}
+	}
+	return unused
+}
+
+func hasWildcard(rule rbacv1.PolicyRule) bool {
+	for _, values := range [][]string{rule.APIGroups, rule.Resources, rule.Verbs} {
+		for _, v := range values {
+			if v == rbacv1.APIGroupAll {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+var _ = Describe("Analyzer", func() {
+	It("finds rules required by client calls, watches, and event recorders", func() {
+		a := &Analyzer{}
+		rules, err := a.Analyze("./" + filepath.Join("testdata", "controllers"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(Equal([]rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list", "watch", "create"}},
+			{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create", "patch"}},
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}},
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list", "watch"}},
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments/status"}, Verbs: []string{"update"}},
+		}))
+	})
+})
+
+var _ = Describe("Markers", func() {
+	It("writes the core group as core", func() {
+		rules := []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"create"}},
+		}
+		Expect(Markers(rules)).To(Equal([]string{
+			"// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list",
+			"// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=create",
+		}))
+	})
+})
+
+var _ = Describe("Unused", func() {
+	required := []rbacv1.PolicyRule{
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list", "watch"}},
+	}
+
+	It("returns verbs and resources no required rule covers", func() {
+		existing := []rbacv1.PolicyRule{
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments", "replicasets"}, Verbs: []string{"get", "delete"}},
+		}
+		Expect(Unused(required, existing)).To(Equal([]rbacv1.PolicyRule{
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"delete"}},
+			{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: []string{"get", "delete"}},
+		}))
+	})
+	It("ignores wildcard and resource name rules", func() {
+		existing := []rbacv1.PolicyRule{
+			{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"lock"}, Verbs: []string{"get"}},
+		}
+		Expect(Unused(required, existing)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,259 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standalone
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	csvlib "github.com/operator-framework/operator-sdk/pkg/clusterserviceversion"
+)
+
+const (
+	certManagerAPIVersion = "cert-manager.io/v1alpha2"
+	// injectCAAnnotation tells the cert-manager CA injector which Certificate's CA
+	// to inject into a webhook configuration.
+	injectCAAnnotation = "cert-manager.io/inject-ca-from"
+	// webhookCertDir is where controller-runtime webhook servers read certificates from by default.
+	webhookCertDir       = "/tmp/k8s-webhook-server/serving-certs"
+	webhookCertVolume    = "webhook-cert"
+	webhookCertSecretKey = "cert"
+)
+
+// addCertManager adds a self-signed cert-manager Issuer and one Certificate per webhook Service in m,
+// mounts each Certificate's secret in the Deployment serving it, and annotates webhook configurations
+// and conversion CRDs for CA injection. The Issuer and Certificates are returned.
+func addCertManager(m *csvlib.Manifests, name, namespace string) (objs []controllerutil.Object) {
+	if len(m.Services) == 0 {
+		return nil
+	}
+
+	issuerName := name + "-selfsigned-issuer"
+	issuer := newCertManagerObject("Issuer", issuerName, namespace)
+	_ = unstructured.SetNestedField(issuer.Object, map[string]interface{}{}, "spec", "selfSigned")
+	objs = append(objs, issuer)
+
+	certRefs := make(map[string]string, len(m.Services))
+	for _, svc := range m.Services {
+		certName := svc.GetName() + "-" + webhookCertSecretKey
+		cert := newCertManagerObject("Certificate", certName, namespace)
+		_ = unstructured.SetNestedField(cert.Object, map[string]interface{}{
+			"dnsNames": []interface{}{
+				fmt.Sprintf("%s.%s.svc", svc.GetName(), namespace),
+				fmt.Sprintf("%s.%s.svc.cluster.local", svc.GetName(), namespace),
+			},
+			"issuerRef": map[string]interface{}{
+				"kind": "Issuer",
+				"name": issuerName,
+			},
+			"secretName": certName,
+		}, "spec")
+		objs = append(objs, cert)
+		certRefs[svc.GetName()] = namespace + "/" + certName
+
+		selector := labels.SelectorFromSet(svc.Spec.Selector)
+		for i := range m.Deployments {
+			dep := &m.Deployments[i]
+			if selector.Matches(labels.Set(dep.Spec.Template.GetLabels())) {
+				mountWebhookCert(dep, certName, svc.Spec.Ports[0].TargetPort.IntVal)
+			}
+		}
+	}
+
+	for _, cc := range clientConfigs(m) {
+		ref, hasRef := certRefs[cc.service]
+		if _, annotated := cc.obj.GetAnnotations()[injectCAAnnotation]; hasRef && !annotated {
+			setAnnotation(cc.obj, injectCAAnnotation, ref)
+		}
+	}
+
+	return objs
+}
+
+// addStaticCA mounts secretName in each Deployment serving a webhook Service in m, and sets caBundle
+// in webhook configurations and conversion CRDs.
+func addStaticCA(m *csvlib.Manifests, secretName string, caBundle []byte) {
+	mounted := make(map[string]bool)
+	for _, svc := range m.Services {
+		selector := labels.SelectorFromSet(svc.Spec.Selector)
+		for i := range m.Deployments {
+			dep := &m.Deployments[i]
+			if !mounted[dep.GetName()] && selector.Matches(labels.Set(dep.Spec.Template.GetLabels())) {
+				mountWebhookCert(dep, secretName, svc.Spec.Ports[0].TargetPort.IntVal)
+				mounted[dep.GetName()] = true
+			}
+		}
+	}
+	for _, cc := range clientConfigs(m) {
+		*cc.caBundle = caBundle
+	}
+}
+
+// clientConfig is a webhook client config in obj that references a Service.
+type clientConfig struct {
+	obj      controllerutil.Object
+	service  string
+	caBundle *[]byte
+}
+
+// clientConfigs returns the client configs of webhook configurations and conversion CRDs in m
+// that reference a Service.
+func clientConfigs(m *csvlib.Manifests) (configs []clientConfig) {
+	for i := range m.ValidatingWebhookConfigurations {
+		cfg := &m.ValidatingWebhookConfigurations[i]
+		for j := range cfg.Webhooks {
+			if cc := &cfg.Webhooks[j].ClientConfig; cc.Service != nil {
+				configs = append(configs, clientConfig{cfg, cc.Service.Name, &cc.CABundle})
+			}
+		}
+	}
+	for i := range m.MutatingWebhookConfigurations {
+		cfg := &m.MutatingWebhookConfigurations[i]
+		for j := range cfg.Webhooks {
+			if cc := &cfg.Webhooks[j].ClientConfig; cc.Service != nil {
+				configs = append(configs, clientConfig{cfg, cc.Service.Name, &cc.CABundle})
+			}
+		}
+	}
+	for i := range m.V1CustomResourceDefinitions {
+		crd := &m.V1CustomResourceDefinitions[i]
+		if conv := crd.Spec.Conversion; conv != nil && conv.Webhook != nil && conv.Webhook.ClientConfig != nil &&
+			conv.Webhook.ClientConfig.Service != nil {
+			cc := conv.Webhook.ClientConfig
+			configs = append(configs, clientConfig{crd, cc.Service.Name, &cc.CABundle})
+		}
+	}
+	for i := range m.V1beta1CustomResourceDefinitions {
+		crd := &m.V1beta1CustomResourceDefinitions[i]
+		if conv := crd.Spec.Conversion; conv != nil && conv.WebhookClientConfig != nil &&
+			conv.WebhookClientConfig.Service != nil {
+			cc := conv.WebhookClientConfig
+			configs = append(configs, clientConfig{crd, cc.Service.Name, &cc.CABundle})
+		}
+	}
+	return configs
+}
+
+// rotationNotes returns comments describing how the webhook serving certificates of m are provisioned
+// and rotated, or the empty string if m has no webhooks.
+func (g Generator) rotationNotes(m *csvlib.Manifests) string {
+	if len(m.Services) == 0 {
+		return ""
+	}
+	var dnsNames []string
+	for _, svc := range m.Services {
+		dnsNames = append(dnsNames, fmt.Sprintf("#   %s.%s.svc", svc.GetName(), g.Namespace))
+	}
+
+	var notes []string
+	switch {
+	case g.CertManager:
+		notes = []string{
+			"# cert-manager issues the serving certificate of each webhook Service, renews it before it expires,",
+			"# and its CA injector updates the caBundle of webhook configurations and conversion CRDs annotated",
+			"# with " + injectCAAnnotation + ". Webhook servers reload renewed certificates once the kubelet",
+			"# updates their mounted Secret, so rotation requires no action.",
+		}
+	case g.CASecret != "":
+		notes = []string{
+			fmt.Sprintf("# Webhook servers read their serving certificate from Secret %s/%s, which must exist", g.Namespace, g.CASecret),
+			"# before these manifests are applied, and contain a tls.crt and tls.key valid for DNS names:",
+		}
+		notes = append(notes, dnsNames...)
+		notes = append(notes,
+			"# The caBundle of webhook configurations and conversion CRDs is the CA given when these manifests",
+			"# were generated. To rotate:",
+			"#   - the serving certificate: update the Secret with a certificate signed by the same CA. Webhook",
+			"#     servers reload it once the kubelet updates their mounted Secret.",
+			"#   - the CA: regenerate these manifests with a CA bundle containing both the old and new CA and apply",
+			"#     them, update the Secret with a certificate signed by the new CA, then regenerate these manifests",
+			"#     with only the new CA and apply them again.",
+		)
+	default:
+		notes = []string{
+			"# These manifests do not provision webhook serving certificates. Mount a Secret containing a tls.crt",
+			"# and tls.key valid for DNS names:",
+		}
+		notes = append(notes, dnsNames...)
+		notes = append(notes,
+			"# at "+webhookCertDir+" in webhook servers, and set the caBundle of webhook",
+			"# configurations and conversion CRDs to its CA, or regenerate these manifests with cert-manager or",
+			"# a CA secret. Repeat these steps to rotate certificates before they expire.",
+		)
+	}
+	return "# Webhook certificate rotation:\n" + strings.Join(notes, "\n") + "\n"
+}
+
+// mountWebhookCert mounts secretName in the container of dep serving port,
+// or the first container if none declares that port. A volume the container
+// already mounts at the certificate directory is replaced by secretName.
+func mountWebhookCert(dep *appsv1.Deployment, secretName string, port int32) {
+	podSpec := &dep.Spec.Template.Spec
+	if len(podSpec.Containers) == 0 {
+		return
+	}
+	container := &podSpec.Containers[0]
+	for i, c := range podSpec.Containers {
+		for _, p := range c.Ports {
+			if p.ContainerPort == port {
+				container = &podSpec.Containers[i]
+			}
+		}
+	}
+	secret := corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secretName}}
+
+	for _, mount := range container.VolumeMounts {
+		if mount.MountPath != webhookCertDir {
+			continue
+		}
+		for i := range podSpec.Volumes {
+			if podSpec.Volumes[i].Name == mount.Name {
+				podSpec.Volumes[i].VolumeSource = secret
+				return
+			}
+		}
+	}
+
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{Name: webhookCertVolume, VolumeSource: secret})
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      webhookCertVolume,
+		MountPath: webhookCertDir,
+		ReadOnly:  true,
+	})
+}
+
+func newCertManagerObject(kind, name, namespace string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(certManagerAPIVersion)
+	u.SetKind(kind)
+	u.SetName(name)
+	u.SetNamespace(namespace)
+	return u
+}
+
+func setAnnotation(obj controllerutil.Object, key, value string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = value
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standalone
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// namespacePlaceholder is set as the namespace of all chart objects, then replaced
+	// by releaseNamespace once they are serialized.
+	namespacePlaceholder = "operator-sdk-release-namespace"
+	releaseNamespace     = "{{ .Release.Namespace }}"
+)
+
+// chartMetadata is the subset of Chart.yaml fields set by the generator.
+type chartMetadata struct {
+	APIVersion  string `json:"apiVersion"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Version     string `json:"version"`
+	AppVersion  string `json:"appVersion,omitempty"`
+}
+
+// WriteHelmChart writes a Helm chart named name to dir. CustomResourceDefinitions are
+// written to the chart's crds directory, and all other objects to a template installed
+// in the release namespace, preceded by webhook certificate rotation notes. g.Namespace is ignored.
+func (g Generator) WriteHelmChart(dir, name string) error {
+	g.Namespace = namespacePlaceholder
+	m, extra, err := g.manifests()
+	if err != nil {
+		return err
+	}
+
+	var crds []controllerutil.Object
+	for i := range m.V1CustomResourceDefinitions {
+		crds = append(crds, &m.V1CustomResourceDefinitions[i])
+	}
+	for i := range m.V1beta1CustomResourceDefinitions {
+		crds = append(crds, &m.V1beta1CustomResourceDefinitions[i])
+	}
+	m.V1CustomResourceDefinitions, m.V1beta1CustomResourceDefinitions = nil, nil
+	templates := append(m.Objects(), extra...)
+
+	csv := g.Bundle.CSV
+	chart := chartMetadata{
+		APIVersion:  "v2",
+		Name:        name,
+		Description: csv.Spec.DisplayName,
+		Type:        "application",
+		Version:     csv.Spec.Version.String(),
+		AppVersion:  csv.Spec.Version.String(),
+	}
+	chartBytes, err := yaml.Marshal(chart)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "templates"), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "Chart.yaml"), chartBytes, 0644); err != nil {
+		return err
+	}
+
+	if len(crds) != 0 {
+		if err := os.MkdirAll(filepath.Join(dir, "crds"), 0755); err != nil {
+			return err
+		}
+		buf := &bytes.Buffer{}
+		if err := WriteObjects(buf, crds...); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "crds", "crds.yaml"), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+
+	buf := bytes.NewBufferString(g.rotationNotes(m))
+	if err := WriteObjects(buf, templates...); err != nil {
+		return err
+	}
+	content := strings.ReplaceAll(buf.String(), namespacePlaceholder, releaseNamespace)
+	return ioutil.WriteFile(filepath.Join(dir, "templates", "manifests.yaml"), []byte(content), 0644)
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package standalone generates manifests and Helm charts that install
+// an operator bundle without OLM.
+package standalone
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+	csvlib "github.com/operator-framework/operator-sdk/pkg/clusterserviceversion"
+)
+
+// Options configure generated manifests.
+type Options struct {
+	// Namespace the operator is installed in.
+	Namespace string
+	// CertManager adds cert-manager resources that issue and inject webhook serving certificates.
+	// Without it or CASecret, webhook certificates must be provisioned separately.
+	CertManager bool
+	// CASecret is the name of a kubernetes.io/tls Secret in Namespace holding a webhook serving
+	// certificate signed by the CA in CABundle. The Secret is mounted by webhook servers, and
+	// CABundle is injected into webhook configurations and conversion CRDs.
+	CASecret string
+	// CABundle is the PEM-encoded CA bundle injected with CASecret.
+	CABundle []byte
+}
+
+// Generator generates standalone install manifests for a bundle.
+type Generator struct {
+	Bundle *apimanifests.Bundle
+	Options
+}
+
+// manifests converts g.Bundle to manifests and applies all options.
+func (g Generator) manifests() (*csvlib.Manifests, []controllerutil.Object, error) {
+	m, err := csvlib.BundleToManifests(g.Bundle, g.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var extra []controllerutil.Object
+	switch {
+	case g.CertManager && g.CASecret != "":
+		return nil, nil, errors.New("cert-manager and a CA secret cannot both provision webhook certificates")
+	case g.CertManager:
+		extra = addCertManager(m, g.Bundle.CSV.GetName(), g.Namespace)
+	case g.CASecret != "":
+		if len(g.CABundle) == 0 {
+			return nil, nil, errors.New("a CA bundle must be set with a CA secret")
+		}
+		addStaticCA(m, g.CASecret, g.CABundle)
+	case len(m.Services) != 0:
+		log.Warn("Bundle contains webhooks; their serving certificates must be provisioned separately " +
+			"unless cert-manager or a CA secret is set")
+	}
+	return m, extra, nil
+}
+
+// Objects returns all objects required to install g.Bundle, preceded by a Namespace.
+func (g Generator) Objects() ([]controllerutil.Object, error) {
+	objs, _, err := g.objects()
+	return objs, err
+}
+
+// WriteManifests writes all objects required to install g.Bundle to w, preceded by
+// comments describing how to rotate webhook certificates if g.Bundle has webhooks.
+func (g Generator) WriteManifests(w io.Writer) error {
+	objs, m, err := g.objects()
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, g.rotationNotes(m)); err != nil {
+		return err
+	}
+	return WriteObjects(w, objs...)
+}
+
+func (g Generator) objects() ([]controllerutil.Object, *csvlib.Manifests, error) {
+	m, extra, err := g.manifests()
+	if err != nil {
+		return nil, nil, err
+	}
+	ns := &corev1.Namespace{}
+	ns.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Namespace"))
+	ns.SetName(g.Namespace)
+
+	objs := []controllerutil.Object{ns}
+	objs = append(objs, m.Objects()...)
+	return append(objs, extra...), m, nil
+}
+
+// WriteObjects writes objs to w as a multi-document manifest.
+func WriteObjects(w io.Writer, objs ...controllerutil.Object) error {
+	for i, obj := range objs {
+		b, err := k8sutil.GetObjectBytes(obj, yaml.Marshal)
+		if err != nil {
+			return fmt.Errorf("error marshalling %s %q: %v", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+		}
+		if i != 0 {
+			if _, err := w.Write([]byte("---\n")); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(bytes.TrimSpace(b)); err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte("\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standalone
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+)
+
+var _ = Describe("Generator", func() {
+	var g Generator
+
+	BeforeEach(func() {
+		labels := map[string]string{"control-plane": "controller-manager"}
+		sideEffects := admissionregv1.SideEffectClassNone
+
+		csv := &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.Spec.DisplayName = "Memcached Operator"
+		csv.Spec.InstallStrategy.StrategyName = v1alpha1.InstallStrategyNameDeployment
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{{
+			Name: "memcached-operator-controller-manager",
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "manager", Image: "quay.io/example/memcached-operator:v0.0.1"}},
+					},
+				},
+			},
+		}}
+		csv.Spec.WebhookDefinitions = []v1alpha1.WebhookDescription{{
+			GenerateName:            "vmemcached.kb.io",
+			Type:                    v1alpha1.ValidatingAdmissionWebhook,
+			DeploymentName:          "memcached-operator-controller-manager",
+			ContainerPort:           9443,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1beta1"},
+		}}
+
+		crd := &apiextv1.CustomResourceDefinition{}
+		crd.SetName("memcacheds.cache.example.com")
+		g = Generator{
+			Bundle:  &apimanifests.Bundle{CSV: csv, V1CRDs: []*apiextv1.CustomResourceDefinition{crd}},
+			Options: Options{Namespace: "memcached-system"},
+		}
+	})
+
+	Describe("Objects", func() {
+		It("returns a Namespace followed by CRDs", func() {
+			objs, err := g.Objects()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objs[0]).To(BeAssignableToTypeOf(&corev1.Namespace{}))
+			Expect(objs[0].GetName()).To(Equal("memcached-system"))
+			Expect(objs[1].GetName()).To(Equal("memcacheds.cache.example.com"))
+		})
+		It("adds cert-manager resources for webhooks", func() {
+			g.CertManager = true
+			objs, err := g.Objects()
+			Expect(err).NotTo(HaveOccurred())
+
+			var kinds []string
+			for _, obj := range objs {
+				kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+				switch t := obj.(type) {
+				case *appsv1.Deployment:
+					Expect(t.Spec.Template.Spec.Volumes).To(HaveLen(1))
+					Expect(t.Spec.Template.Spec.Containers[0].VolumeMounts[0].MountPath).To(Equal(webhookCertDir))
+				case *admissionregv1.ValidatingWebhookConfiguration:
+					Expect(t.GetAnnotations()).To(HaveKeyWithValue(injectCAAnnotation,
+						"memcached-system/memcached-operator-controller-manager-service-cert"))
+				}
+			}
+			Expect(kinds).To(ContainElement("Issuer"))
+			Expect(kinds).To(ContainElement("Certificate"))
+		})
+		It("injects the CA of cert-manager Certificates into conversion CRDs", func() {
+			addConversionWebhook(&g)
+			g.CertManager = true
+			objs, err := g.Objects()
+			Expect(err).NotTo(HaveOccurred())
+
+			crd := objs[1].(*apiextv1.CustomResourceDefinition)
+			Expect(crd.Spec.Conversion.Webhook.ClientConfig.Service.Name).To(Equal("memcached-operator-controller-manager-service"))
+			Expect(crd.GetAnnotations()).To(HaveKeyWithValue(injectCAAnnotation,
+				"memcached-system/memcached-operator-controller-manager-service-cert"))
+		})
+		It("mounts a CA secret and injects its CA bundle", func() {
+			addConversionWebhook(&g)
+			g.CASecret, g.CABundle = "webhook-cert", []byte("ca")
+			objs, err := g.Objects()
+			Expect(err).NotTo(HaveOccurred())
+
+			var kinds []string
+			for _, obj := range objs {
+				kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+				switch t := obj.(type) {
+				case *appsv1.Deployment:
+					Expect(t.Spec.Template.Spec.Volumes).To(HaveLen(1))
+					Expect(t.Spec.Template.Spec.Volumes[0].Secret.SecretName).To(Equal("webhook-cert"))
+				case *admissionregv1.ValidatingWebhookConfiguration:
+					Expect(t.Webhooks[0].ClientConfig.CABundle).To(Equal([]byte("ca")))
+				case *apiextv1.CustomResourceDefinition:
+					Expect(t.Spec.Conversion.Webhook.ClientConfig.CABundle).To(Equal([]byte("ca")))
+				}
+			}
+			Expect(kinds).NotTo(ContainElement("Certificate"))
+		})
+		It("replaces a volume already mounted at the certificate directory", func() {
+			podSpec := &g.Bundle.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec
+			podSpec.Volumes = []corev1.Volume{{Name: "cert", VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "webhook-server-cert"},
+			}}}
+			podSpec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "cert", MountPath: webhookCertDir}}
+			g.CASecret, g.CABundle = "webhook-cert", []byte("ca")
+			objs, err := g.Objects()
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, obj := range objs {
+				if dep, isDep := obj.(*appsv1.Deployment); isDep {
+					Expect(dep.Spec.Template.Spec.Volumes).To(HaveLen(1))
+					Expect(dep.Spec.Template.Spec.Volumes[0].Secret.SecretName).To(Equal("webhook-cert"))
+					Expect(dep.Spec.Template.Spec.Containers[0].VolumeMounts).To(HaveLen(1))
+				}
+			}
+		})
+		It("fails for a CA secret without a CA bundle", func() {
+			g.CASecret = "webhook-cert"
+			_, err := g.Objects()
+			Expect(err).To(HaveOccurred())
+		})
+		It("fails for both cert-manager and a CA secret", func() {
+			g.CertManager, g.CASecret, g.CABundle = true, "webhook-cert", []byte("ca")
+			_, err := g.Objects()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("WriteManifests", func() {
+		It("writes rotation notes for a CA secret", func() {
+			g.CASecret, g.CABundle = "webhook-cert", []byte("ca")
+			buf := &bytes.Buffer{}
+			Expect(g.WriteManifests(buf)).To(Succeed())
+			Expect(buf.String()).To(HavePrefix("# Webhook certificate rotation:\n"))
+			Expect(buf.String()).To(ContainSubstring("Secret memcached-system/webhook-cert"))
+			Expect(buf.String()).To(ContainSubstring("memcached-operator-controller-manager-service.memcached-system.svc"))
+		})
+		It("writes no rotation notes without webhooks", func() {
+			g.Bundle.CSV.Spec.WebhookDefinitions = nil
+			buf := &bytes.Buffer{}
+			Expect(g.WriteManifests(buf)).To(Succeed())
+			Expect(buf.String()).To(HavePrefix("apiVersion: v1\nkind: Namespace"))
+		})
+	})
+
+	Describe("WriteHelmChart", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "helm-chart-")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("writes CRDs and templates in the release namespace", func() {
+			Expect(g.WriteHelmChart(dir, "memcached-operator")).To(Succeed())
+			Expect(filepath.Join(dir, "Chart.yaml")).To(BeAnExistingFile())
+			Expect(filepath.Join(dir, "crds", "crds.yaml")).To(BeAnExistingFile())
+
+			b, err := ioutil.ReadFile(filepath.Join(dir, "templates", "manifests.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(ContainSubstring("namespace: " + releaseNamespace))
+			Expect(string(b)).NotTo(ContainSubstring(namespacePlaceholder))
+			Expect(string(b)).NotTo(ContainSubstring("CustomResourceDefinition"))
+		})
+	})
+})
+
+// addConversionWebhook adds a conversion webhook for the CRD of g.Bundle to its CSV.
+func addConversionWebhook(g *Generator) {
+	sideEffects := admissionregv1.SideEffectClassNone
+	g.Bundle.CSV.Spec.WebhookDefinitions = append(g.Bundle.CSV.Spec.WebhookDefinitions, v1alpha1.WebhookDescription{
+		GenerateName:            "cmemcached.cache.example.com",
+		Type:                    gencsv.ConversionWebhook,
+		DeploymentName:          "memcached-operator-controller-manager",
+		ContainerPort:           9443,
+		SideEffects:             &sideEffects,
+		AdmissionReviewVersions: []string{"v1beta1"},
+		ConversionCRDs:          []string{"memcacheds.cache.example.com"},
+	})
+}
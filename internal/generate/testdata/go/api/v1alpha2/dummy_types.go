@@ -135,6 +135,30 @@ type OtherDummyStatus struct {
 	Nothing string `json:"nothing"`
 }
 
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type ConditionedDummyStatus struct {
+	// Should not be in status, since it isn't marked
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Should be in status, since conditions are displayed whether or not they are marked
+	Conditions []DummyCondition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type DummyCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type MarkedConditionsDummyStatus struct {
+	// Should be in status once, with its own descriptors
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Dummy Conditions"
+	Conditions []DummyCondition `json:"conditions,omitempty"`
+}
+
 // Dummy is the Schema for the dummy API
 // +k8s:deepcopy-gen=false
 // +k8s:openapi-gen=false
@@ -163,6 +187,28 @@ type OtherDummy struct {
 	Status OtherDummyStatus `json:"status,omitempty"`
 }
 
+// ConditionedDummy is the Schema for the conditioned dummy API
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type ConditionedDummy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DummySpec              `json:"spec,omitempty"`
+	Status ConditionedDummyStatus `json:"status,omitempty"`
+}
+
+// MarkedConditionsDummy is the Schema for the marked conditions dummy API
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type MarkedConditionsDummy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OtherDummyStatus            `json:"spec,omitempty"`
+	Status MarkedConditionsDummyStatus `json:"status,omitempty"`
+}
+
 // DummyList contains a list of Dummy
 // +k8s:deepcopy-gen=false
 // +k8s:openapi-gen=false
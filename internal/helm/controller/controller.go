@@ -30,12 +30,15 @@ import (
 	crthandler "sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sigs.k8s.io/yaml"
 
 	libhandler "github.com/operator-framework/operator-lib/handler"
 	"github.com/operator-framework/operator-lib/predicate"
 	"github.com/operator-framework/operator-sdk/internal/helm/release"
+	"github.com/operator-framework/operator-sdk/internal/helm/watches"
+	"github.com/operator-framework/operator-sdk/internal/util/health"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 )
 
@@ -50,7 +53,10 @@ type WatchOptions struct {
 	ReconcilePeriod         time.Duration
 	WatchDependentResources bool
 	OverrideValues          map[string]string
+	AdoptReleases           bool
+	ValuePolicy             *watches.ValuePolicy
 	MaxConcurrentReconciles int
+	ReconcileTracker        *health.Tracker
 }
 
 // Add creates a new helm operator controller and adds it to the manager
@@ -64,14 +70,21 @@ func Add(mgr manager.Manager, options WatchOptions) error {
 		ManagerFactory:  options.ManagerFactory,
 		ReconcilePeriod: options.ReconcilePeriod,
 		OverrideValues:  options.OverrideValues,
+		AdoptReleases:   options.AdoptReleases,
+		ValuePolicy:     options.ValuePolicy,
 	}
 
 	// Register the GVK with the schema
 	mgr.GetScheme().AddKnownTypeWithName(options.GVK, &unstructured.Unstructured{})
 	metav1.AddToGroupVersion(mgr.GetScheme(), options.GVK.GroupVersion())
 
+	var reconciler reconcile.Reconciler = r
+	if options.ReconcileTracker != nil {
+		reconciler = options.ReconcileTracker.Reconciler(r)
+	}
+
 	c, err := controller.New(controllerName, mgr, controller.Options{
-		Reconciler:              r,
+		Reconciler:              reconciler,
 		MaxConcurrentReconciles: options.MaxConcurrentReconciles,
 	})
 	if err != nil {
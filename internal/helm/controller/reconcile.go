@@ -37,6 +37,7 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/helm/internal/diff"
 	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
 	"github.com/operator-framework/operator-sdk/internal/helm/release"
+	"github.com/operator-framework/operator-sdk/internal/helm/watches"
 )
 
 // blank assignment to verify that HelmOperatorReconciler implements reconcile.Reconciler
@@ -53,6 +54,8 @@ type HelmOperatorReconciler struct {
 	ManagerFactory  release.ManagerFactory
 	ReconcilePeriod time.Duration
 	OverrideValues  map[string]string
+	AdoptReleases   bool
+	ValuePolicy     *watches.ValuePolicy
 	releaseHook     ReleaseHookFunc
 }
 
@@ -153,6 +156,39 @@ func (r HelmOperatorReconciler) Reconcile(request reconcile.Request) (reconcile.
 		return reconcile.Result{}, nil
 	}
 
+	if err := validateValues(r.ValuePolicy, o); err != nil {
+		// The release is not changed until the resource is updated, so don't requeue.
+		log.Error(err, "Release failed")
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionReleaseFailed,
+			Status:  types.StatusTrue,
+			Reason:  types.ReasonValidationError,
+			Message: err.Error(),
+		})
+		return reconcile.Result{}, r.updateResourceStatus(o, status)
+	}
+
+	if manager.ReleaseExists() && !ownsRelease(o, status, manager.ReleaseName()) {
+		if !r.AdoptReleases {
+			err := fmt.Errorf("release %q was not installed by this resource, "+
+				"set adoptReleases to true for %s in watches.yaml to adopt it", manager.ReleaseName(), r.GVK.Kind)
+			log.Error(err, "Release failed")
+			status.SetCondition(types.HelmAppCondition{
+				Type:    types.ConditionReleaseFailed,
+				Status:  types.StatusTrue,
+				Reason:  types.ReasonInstallError,
+				Message: err.Error(),
+			})
+			_ = r.updateResourceStatus(o, status)
+			return reconcile.Result{}, err
+		}
+		// The release is upgraded or reconciled below with this resource's values, which
+		// sets this resource as the owner of the release's resources.
+		log.Info("Adopting existing release")
+		r.EventRecorder.Eventf(o, "Normal", "AdoptedRelease",
+			"Adopted existing Helm release %q", manager.ReleaseName())
+	}
+
 	status.SetCondition(types.HelmAppCondition{
 		Type:   types.ConditionInitialized,
 		Status: types.StatusTrue,
@@ -389,6 +425,16 @@ func (r HelmOperatorReconciler) waitForDeletion(o runtime.Object) error {
 	}, ctx.Done())
 }
 
+// ownsRelease returns true if o installed or previously adopted the release named releaseName.
+func ownsRelease(o *unstructured.Unstructured, status *types.HelmAppStatus, releaseName string) bool {
+	if status.DeployedRelease != nil && status.DeployedRelease.Name == releaseName {
+		return true
+	}
+	// The finalizer is added right after o's release is installed, so it marks releases installed
+	// by o even if o's status could not be updated.
+	return contains(o.GetFinalizers(), finalizer)
+}
+
 func contains(l []string, s string) bool {
 	for _, elem := range l {
 		if elem == s {
@@ -19,6 +19,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
 )
 
 func TestHasHelmUpgradeForceAnnotation(t *testing.T) {
@@ -86,3 +88,40 @@ func annotations(m map[string]interface{}) *unstructured.Unstructured {
 		},
 	}
 }
+
+func TestOwnsRelease(t *testing.T) {
+	tests := []struct {
+		name        string
+		finalizers  []string
+		status      *types.HelmAppStatus
+		expectedVal bool
+	}{
+		{
+			name:        "no finalizer or deployed release",
+			status:      &types.HelmAppStatus{},
+			expectedVal: false,
+		},
+		{
+			name:        "finalizer",
+			finalizers:  []string{finalizer},
+			status:      &types.HelmAppStatus{},
+			expectedVal: true,
+		},
+		{
+			name:        "deployed release",
+			status:      &types.HelmAppStatus{DeployedRelease: &types.HelmAppRelease{Name: "foo"}},
+			expectedVal: true,
+		},
+		{
+			name:        "other deployed release",
+			status:      &types.HelmAppStatus{DeployedRelease: &types.HelmAppRelease{Name: "bar"}},
+			expectedVal: false,
+		},
+	}
+
+	for _, test := range tests {
+		o := &unstructured.Unstructured{}
+		o.SetFinalizers(test.finalizers)
+		assert.Equal(t, test.expectedVal, ownsRelease(o, test.status, "foo"), test.name)
+	}
+}
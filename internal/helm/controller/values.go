@@ -0,0 +1,74 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/watches"
+)
+
+// validateValues returns an error listing the values set in o's spec that policy does not allow.
+func validateValues(policy *watches.ValuePolicy, o *unstructured.Unstructured) error {
+	if policy == nil {
+		return nil
+	}
+	spec, _ := o.Object["spec"].(map[string]interface{})
+	if violations := policy.Violations(spec); len(violations) != 0 {
+		return fmt.Errorf("spec sets values that cannot be set by %s resources: %s",
+			o.GetKind(), strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// ValuesWebhookPath returns the path the values validating webhook for gvk is served at.
+func ValuesWebhookPath(gvk schema.GroupVersionKind) string {
+	return fmt.Sprintf("/validate-%s-%s-%s", strings.ReplaceAll(gvk.Group, ".", "-"), gvk.Version,
+		strings.ToLower(gvk.Kind))
+}
+
+// NewValuesWebhook returns a validating admission webhook that rejects custom resources
+// whose spec sets values policy does not allow.
+func NewValuesWebhook(policy *watches.ValuePolicy) *admission.Webhook {
+	return &admission.Webhook{Handler: &valuesValidator{policy: policy}}
+}
+
+type valuesValidator struct {
+	policy *watches.ValuePolicy
+}
+
+var _ admission.Handler = &valuesValidator{}
+
+func (v *valuesValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	// Deletes carry no object, and must be allowed so releases can be uninstalled.
+	if len(req.Object.Raw) == 0 {
+		return admission.Allowed("")
+	}
+	o := &unstructured.Unstructured{}
+	if err := o.UnmarshalJSON(req.Object.Raw); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := validateValues(v.policy, o); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
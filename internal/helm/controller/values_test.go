@@ -0,0 +1,75 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/watches"
+)
+
+func TestValuesWebhookPath(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "cache.example.com", Version: "v1alpha1", Kind: "Memcached"}
+	assert.Equal(t, "/validate-cache-example-com-v1alpha1-memcached", ValuesWebhookPath(gvk))
+}
+
+func TestValuesValidator(t *testing.T) {
+	v := &valuesValidator{policy: &watches.ValuePolicy{Deny: []string{"image"}}}
+	tests := []struct {
+		name          string
+		raw           string
+		expectAllowed bool
+	}{
+		{
+			name:          "allowed values",
+			raw:           `{"apiVersion":"example.com/v1","kind":"App","metadata":{"name":"a"},"spec":{"replicaCount":2}}`,
+			expectAllowed: true,
+		},
+		{
+			name:          "denied values",
+			raw:           `{"apiVersion":"example.com/v1","kind":"App","metadata":{"name":"a"},"spec":{"image":"evil"}}`,
+			expectAllowed: false,
+		},
+		{
+			name:          "no spec",
+			raw:           `{"apiVersion":"example.com/v1","kind":"App","metadata":{"name":"a"}}`,
+			expectAllowed: true,
+		},
+		{
+			name:          "delete",
+			expectAllowed: true,
+		},
+		{
+			name:          "invalid object",
+			raw:           `{`,
+			expectAllowed: false,
+		},
+	}
+
+	for _, test := range tests {
+		req := admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Object: runtime.RawExtension{Raw: []byte(test.raw)},
+		}}
+		resp := v.Handle(context.TODO(), req)
+		assert.Equal(t, test.expectAllowed, resp.Allowed, test.name)
+	}
+}
@@ -30,6 +30,8 @@ type Flags struct {
 	LeaderElectionID        string
 	LeaderElectionNamespace string
 	MaxConcurrentReconciles int
+	EnableValuesWebhook     bool
+	ReconcileStallTimeout   time.Duration
 }
 
 // AddTo - Add the helm operator flags to the the flagset
@@ -69,4 +71,17 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 		runtime.NumCPU(),
 		"Maximum number of concurrent reconciles for controllers.",
 	)
+	flagSet.BoolVar(&f.EnableValuesWebhook,
+		"enable-values-webhook",
+		false,
+		"Serve validating admission webhooks at /validate-<group>-<version>-<kind> that reject custom resources "+
+			"setting values their watch's valuePolicy does not allow. Requires serving certificates in "+
+			"/tmp/k8s-webhook-server/serving-certs and a ValidatingWebhookConfiguration for the webhooks.",
+	)
+	flagSet.DurationVar(&f.ReconcileStallTimeout,
+		"reconcile-stall-timeout",
+		0,
+		"Fail the liveness probe when all reconcile workers of a controller have been busy without any "+
+			"reconcile finishing for longer than this duration. Zero disables the check.",
+	)
 }
@@ -72,6 +72,7 @@ const (
 	ReasonUpgradeError        HelmAppConditionReason = "UpgradeError"
 	ReasonReconcileError      HelmAppConditionReason = "ReconcileError"
 	ReasonUninstallError      HelmAppConditionReason = "UninstallError"
+	ReasonValidationError     HelmAppConditionReason = "ValidationError"
 )
 
 type HelmAppStatus struct {
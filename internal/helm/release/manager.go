@@ -27,6 +27,7 @@ import (
 	cpb "helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/kube"
 	helmkube "helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/postrender"
 	rpb "helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
@@ -46,6 +47,7 @@ import (
 // and uninstall a release.
 type Manager interface {
 	ReleaseName() string
+	ReleaseExists() bool
 	IsInstalled() bool
 	IsUpgradeRequired() bool
 	Sync(context.Context) error
@@ -60,8 +62,9 @@ type manager struct {
 	storageBackend *storage.Storage
 	kubeClient     kube.Interface
 
-	releaseName string
-	namespace   string
+	releaseName   string
+	releaseExists bool
+	namespace     string
 
 	values map[string]interface{}
 	status *types.HelmAppStatus
@@ -70,6 +73,7 @@ type manager struct {
 	isUpgradeRequired bool
 	deployedRelease   *rpb.Release
 	chart             *cpb.Chart
+	postRenderer      postrender.PostRenderer
 }
 
 type InstallOption func(*action.Install) error
@@ -81,6 +85,12 @@ func (m manager) ReleaseName() string {
 	return m.releaseName
 }
 
+// ReleaseExists returns true if any version of the release, deployed or not,
+// was stored when the manager was created.
+func (m manager) ReleaseExists() bool {
+	return m.releaseExists
+}
+
 func (m manager) IsInstalled() bool {
 	return m.isInstalled
 }
@@ -153,6 +163,7 @@ func (m manager) getCandidateRelease(namespace, name string, chart *cpb.Chart,
 	upgrade := action.NewUpgrade(m.actionConfig)
 	upgrade.Namespace = namespace
 	upgrade.DryRun = true
+	upgrade.PostRenderer = m.postRenderer
 	return upgrade.Run(name, chart, values)
 }
 
@@ -161,6 +172,7 @@ func (m manager) InstallRelease(ctx context.Context, opts ...InstallOption) (*rp
 	install := action.NewInstall(m.actionConfig)
 	install.ReleaseName = m.releaseName
 	install.Namespace = m.namespace
+	install.PostRenderer = m.postRenderer
 	for _, o := range opts {
 		if err := o(install); err != nil {
 			return nil, fmt.Errorf("failed to apply install option: %w", err)
@@ -202,6 +214,7 @@ func ForceUpgrade(force bool) UpgradeOption {
 func (m manager) UpgradeRelease(ctx context.Context, opts ...UpgradeOption) (*rpb.Release, *rpb.Release, error) {
 	upgrade := action.NewUpgrade(m.actionConfig)
 	upgrade.Namespace = m.namespace
+	upgrade.PostRenderer = m.postRenderer
 	for _, o := range opts {
 		if err := o(upgrade); err != nil {
 			return nil, nil, fmt.Errorf("failed to apply upgrade option: %w", err)
@@ -15,16 +15,21 @@
 package release
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
+	"text/template"
 
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/postrender"
 	helmrelease "helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	"helm.sh/helm/v3/pkg/strvals"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	crmanager "sigs.k8s.io/controller-runtime/pkg/manager"
 
@@ -41,13 +46,37 @@ type ManagerFactory interface {
 }
 
 type managerFactory struct {
-	mgr      crmanager.Manager
-	chartDir string
+	mgr                 crmanager.Manager
+	chartDir            string
+	releaseNameTemplate *template.Template
+	postRenderer        postrender.PostRenderer
+}
+
+// ManagerFactoryOption configures a ManagerFactory.
+type ManagerFactoryOption func(*managerFactory)
+
+// WithReleaseNameTemplate names the release of a custom resource by executing tmpl with the
+// resource's Name, Namespace, Kind, Labels, and Annotations, instead of using the resource's name.
+func WithReleaseNameTemplate(tmpl *template.Template) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.releaseNameTemplate = tmpl
+	}
+}
+
+// WithPostRenderer modifies every release's rendered manifests with pr before they are applied.
+func WithPostRenderer(pr postrender.PostRenderer) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.postRenderer = pr
+	}
 }
 
 // NewManagerFactory returns a new Helm manager factory capable of installing and uninstalling releases.
-func NewManagerFactory(mgr crmanager.Manager, chartDir string) ManagerFactory {
-	return &managerFactory{mgr, chartDir}
+func NewManagerFactory(mgr crmanager.Manager, chartDir string, opts ...ManagerFactoryOption) ManagerFactory {
+	f := &managerFactory{mgr: mgr, chartDir: chartDir}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 func (f managerFactory) NewManager(cr *unstructured.Unstructured, overrideValues map[string]string) (Manager, error) {
@@ -77,7 +106,12 @@ func (f managerFactory) NewManager(cr *unstructured.Unstructured, overrideValues
 		return nil, fmt.Errorf("failed to load chart dir: %w", err)
 	}
 
-	releaseName, err := getReleaseName(storageBackend, crChart.Name(), cr)
+	status := types.StatusFor(cr)
+	releaseName, err := f.releaseNameFor(cr, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get helm release name: %w", err)
+	}
+	releaseExists, err := checkReleaseName(storageBackend, crChart.Name(), releaseName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get helm release name: %w", err)
 	}
@@ -105,56 +139,93 @@ func (f managerFactory) NewManager(cr *unstructured.Unstructured, overrideValues
 		storageBackend: storageBackend,
 		kubeClient:     ownerRefClient,
 
-		releaseName: releaseName,
-		namespace:   cr.GetNamespace(),
+		releaseName:   releaseName,
+		releaseExists: releaseExists,
+		namespace:     cr.GetNamespace(),
 
-		chart:  crChart,
-		values: values,
-		status: types.StatusFor(cr),
+		chart:        crChart,
+		values:       values,
+		status:       status,
+		postRenderer: f.postRenderer,
 	}, nil
 }
 
-// getReleaseName returns a release name for the CR.
-//
-// getReleaseName searches for a release using the CR name. If a release
-// cannot be found, or if it is found and was created by the chart managed
-// by this manager, the CR name is returned.
+// releaseNameFor returns the name of cr's release. Once a release is deployed, its name is kept
+// even if the release name template would render a different name, ex. after cr's labels change.
+func (f managerFactory) releaseNameFor(cr *unstructured.Unstructured, status *types.HelmAppStatus) (string, error) {
+	if status.DeployedRelease != nil && status.DeployedRelease.Name != "" {
+		return status.DeployedRelease.Name, nil
+	}
+	if f.releaseNameTemplate == nil {
+		return cr.GetName(), nil
+	}
+	return renderReleaseName(f.releaseNameTemplate, cr)
+}
+
+// maxReleaseNameLen is the longest release name Helm allows.
+const maxReleaseNameLen = 53
+
+// renderReleaseName executes tmpl with cr's metadata and validates the result as a release name.
+func renderReleaseName(tmpl *template.Template, cr *unstructured.Unstructured) (string, error) {
+	data := map[string]interface{}{
+		"Name":        cr.GetName(),
+		"Namespace":   cr.GetNamespace(),
+		"Kind":        cr.GetKind(),
+		"Labels":      cr.GetLabels(),
+		"Annotations": cr.GetAnnotations(),
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("error executing release name template: %w", err)
+	}
+	name := strings.TrimSpace(buf.String())
+	if len(name) > maxReleaseNameLen {
+		return "", fmt.Errorf("release name %q is longer than %d characters", name, maxReleaseNameLen)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) != 0 {
+		return "", fmt.Errorf("invalid release name %q: %s", name, strings.Join(errs, ", "))
+	}
+	return name, nil
+}
+
+// checkReleaseName returns true if a release named releaseName exists.
 //
 // If a release is found but it was created by another chart, that means we
 // have a release name collision, so return an error. This case is possible
 // because Kubernetes allows instances of different types to have the same name
-// in the same namespace.
+// in the same namespace, and because release name templates can render the
+// same name for different custom resources.
+//
+// A release created by the chart managed by this manager is not necessarily
+// owned by the CR, ex. if it was installed with the helm CLI. The reconciler
+// decides whether the CR can adopt it.
 //
-// TODO(jlanford): As noted above, using the CR name as the release name raises
-//   the possibility of collision. We should move this logic to a validating
-//   admission webhook so that the CR owner receives immediate feedback of the
-//   collision. As is, the only indication of collision will be in the CR status
-//   and operator logs.
-func getReleaseName(storageBackend *storage.Storage, crChartName string,
-	cr *unstructured.Unstructured) (string, error) {
-	// If a release with the CR name does not exist, return the CR name.
-	releaseName := cr.GetName()
+// TODO(jlanford): As noted above, release names can collide. We should move
+//   this logic to a validating admission webhook so that the CR owner receives
+//   immediate feedback of the collision. As is, the only indication of
+//   collision will be in the CR status and operator logs.
+func checkReleaseName(storageBackend *storage.Storage, crChartName, releaseName string) (bool, error) {
 	history, exists, err := releaseHistory(storageBackend, releaseName)
 	if err != nil {
-		return "", err
+		return false, err
 	}
 	if !exists {
-		return releaseName, nil
+		return false, nil
 	}
 
-	// If a release name with the CR name exists, but the release's chart is
+	// If a release with the release name exists, but the release's chart is
 	// different than the chart managed by this operator, return an error
 	// because something else created the existing release.
 	if history[0].Chart == nil {
-		return "", fmt.Errorf("could not find chart metadata in release with name %q", releaseName)
+		return false, fmt.Errorf("could not find chart metadata in release with name %q", releaseName)
 	}
 	existingChartName := history[0].Chart.Name()
 	if existingChartName != crChartName {
-		return "", fmt.Errorf("duplicate release name: found existing release with name %q for chart %q",
+		return false, fmt.Errorf("duplicate release name: found existing release with name %q for chart %q",
 			releaseName, existingChartName)
 	}
 
-	return releaseName, nil
+	return true, nil
 }
 
 func releaseHistory(storageBackend *storage.Storage, releaseName string) ([]*helmrelease.Release, bool, error) {
@@ -0,0 +1,121 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/watches"
+)
+
+func newTestCR(name, namespace string, labels map[string]string) *unstructured.Unstructured {
+	cr := &unstructured.Unstructured{}
+	cr.SetKind("MyKind")
+	cr.SetName(name)
+	cr.SetNamespace(namespace)
+	cr.SetLabels(labels)
+	return cr
+}
+
+func mustParseReleaseName(t *testing.T, text string) *template.Template {
+	tmpl, err := watches.ParseReleaseName(text)
+	if err != nil {
+		t.Fatalf("Failed to parse release name template %q: %v", text, err)
+	}
+	return tmpl
+}
+
+func TestReleaseNameFor(t *testing.T) {
+	testCases := []struct {
+		name       string
+		template   string
+		cr         *unstructured.Unstructured
+		status     *types.HelmAppStatus
+		expectName string
+		expectErr  bool
+	}{
+		{
+			name:       "no template uses CR name",
+			cr:         newTestCR("foo", "ns", nil),
+			status:     &types.HelmAppStatus{},
+			expectName: "foo",
+		},
+		{
+			name:       "template",
+			template:   `{{ .Namespace }}-{{ .Kind | lower }}-{{ .Name }}`,
+			cr:         newTestCR("foo", "ns", nil),
+			status:     &types.HelmAppStatus{},
+			expectName: "ns-mykind-foo",
+		},
+		{
+			name:       "template with labels",
+			template:   `{{ index .Labels "app" | default .Name }}`,
+			cr:         newTestCR("foo", "ns", map[string]string{"app": "bar"}),
+			status:     &types.HelmAppStatus{},
+			expectName: "bar",
+		},
+		{
+			name:     "deployed release name is kept",
+			template: `{{ .Namespace }}-{{ .Name }}`,
+			cr:       newTestCR("foo", "ns", nil),
+			status: &types.HelmAppStatus{
+				DeployedRelease: &types.HelmAppRelease{Name: "foo"},
+			},
+			expectName: "foo",
+		},
+		{
+			name:      "invalid release name",
+			template:  `{{ .Name | upper }}`,
+			cr:        newTestCR("foo", "ns", nil),
+			status:    &types.HelmAppStatus{},
+			expectErr: true,
+		},
+		{
+			name:      "release name too long",
+			template:  `{{ .Name | repeat 10 }}`,
+			cr:        newTestCR("foobar", "ns", nil),
+			status:    &types.HelmAppStatus{},
+			expectErr: true,
+		},
+		{
+			name:      "missing key",
+			template:  `{{ .Spec }}`,
+			cr:        newTestCR("foo", "ns", nil),
+			status:    &types.HelmAppStatus{},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := managerFactory{}
+			if tc.template != "" {
+				WithReleaseNameTemplate(mustParseReleaseName(t, tc.template))(&f)
+			}
+			name, err := f.releaseNameFor(tc.cr, tc.status)
+			if !tc.expectErr && err != nil {
+				t.Fatalf("Expected no error; got error: %v", err)
+			} else if tc.expectErr && err == nil {
+				t.Fatalf("Expected error; got no error")
+			}
+			assert.Equal(t, tc.expectName, name)
+		})
+	}
+}
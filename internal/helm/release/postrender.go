@@ -0,0 +1,141 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"helm.sh/helm/v3/pkg/postrender"
+	"k8s.io/cli-runtime/pkg/kustomize"
+	"sigs.k8s.io/kustomize/pkg/fs"
+	"sigs.k8s.io/yaml"
+)
+
+// NewPostRenderer returns a post-renderer that modifies a release's rendered manifests before they
+// are applied. Exactly one of execPath, a binary that reads manifests on stdin and writes modified
+// manifests to stdout like the helm CLI's --post-renderer, or kustomizeDir, a kustomize overlay, must be set.
+func NewPostRenderer(execPath, kustomizeDir string) (postrender.PostRenderer, error) {
+	switch {
+	case execPath != "" && kustomizeDir != "":
+		return nil, fmt.Errorf("only one of exec and kustomize can be set")
+	case execPath != "":
+		return postrender.NewExec(execPath)
+	case kustomizeDir != "":
+		return newKustomizePostRenderer(kustomizeDir)
+	}
+	return nil, fmt.Errorf("one of exec or kustomize must be set")
+}
+
+const (
+	// kustomizeRoot is the directory an overlay is copied to in a kustomize post-renderer's in-memory filesystem.
+	kustomizeRoot = "/overlay"
+	// renderedManifestsFile is the file a release's rendered manifests are written to in an overlay.
+	renderedManifestsFile = "helm-rendered-manifests.yaml"
+)
+
+var kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// kustomizePostRenderer applies a kustomize overlay to a release's rendered manifests.
+type kustomizePostRenderer struct {
+	// files are the overlay's file contents keyed by path relative to the overlay.
+	files map[string][]byte
+	// kustomizationFile is the name of the overlay's kustomization file.
+	kustomizationFile string
+}
+
+// newKustomizePostRenderer reads the overlay in dir, which must be self-contained,
+// i.e. not reference files outside of dir.
+func newKustomizePostRenderer(dir string) (*kustomizePostRenderer, error) {
+	r := &kustomizePostRenderer{files: map[string][]byte{}}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		r.files[filepath.ToSlash(rel)] = b
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading kustomize overlay %s: %w", dir, err)
+	}
+	for _, name := range kustomizationFiles {
+		if _, ok := r.files[name]; ok {
+			r.kustomizationFile = name
+			break
+		}
+	}
+	if r.kustomizationFile == "" {
+		return nil, fmt.Errorf("no kustomization file found in kustomize overlay %s", dir)
+	}
+	return r, nil
+}
+
+// Run builds the overlay with renderedManifests added to its kustomization's resources.
+func (r *kustomizePostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	kustomization, err := withRenderedManifests(r.files[r.kustomizationFile])
+	if err != nil {
+		return nil, err
+	}
+
+	fSys := fs.MakeFakeFS()
+	for name, b := range r.files {
+		if name == r.kustomizationFile {
+			b = kustomization
+		}
+		path := filepath.Join(kustomizeRoot, filepath.FromSlash(name))
+		if err := fSys.MkdirAll(filepath.Dir(path)); err != nil {
+			return nil, err
+		}
+		if err := fSys.WriteFile(path, b); err != nil {
+			return nil, err
+		}
+	}
+	if err := fSys.WriteFile(filepath.Join(kustomizeRoot, renderedManifestsFile), renderedManifests.Bytes()); err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	if err := kustomize.RunKustomizeBuild(out, fSys, kustomizeRoot); err != nil {
+		return nil, fmt.Errorf("error building kustomize overlay: %w", err)
+	}
+	return out, nil
+}
+
+// withRenderedManifests returns kustomization with renderedManifestsFile appended to its resources.
+func withRenderedManifests(kustomization []byte) ([]byte, error) {
+	k := map[string]interface{}{}
+	if err := yaml.Unmarshal(kustomization, &k); err != nil {
+		return nil, fmt.Errorf("error parsing kustomization: %w", err)
+	}
+	resources, _ := k["resources"].([]interface{})
+	for _, res := range resources {
+		if res == renderedManifestsFile {
+			return kustomization, nil
+		}
+	}
+	k["resources"] = append(resources, renderedManifestsFile)
+	return yaml.Marshal(k)
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRenderedManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+data:
+  key: value
+`
+
+func writeOverlay(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "osdk-test-overlay")
+	if err != nil {
+		t.Fatalf("Failed to create overlay dir: %v", err)
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create overlay dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to write overlay file: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestKustomizePostRenderer(t *testing.T) {
+	testCases := []struct {
+		name         string
+		files        map[string]string
+		expectOutput string
+		expectErr    bool
+	}{
+		{
+			name: "labels and patches",
+			files: map[string]string{
+				"kustomization.yaml": `commonLabels:
+  team: foo
+patchesStrategicMerge:
+- patches/configmap.yaml
+`,
+				"patches/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+data:
+  other: value
+`,
+			},
+			expectOutput: `apiVersion: v1
+data:
+  key: value
+  other: value
+kind: ConfigMap
+metadata:
+  labels:
+    team: foo
+  name: foo
+`,
+		},
+		{
+			name: "rendered manifests already in resources",
+			files: map[string]string{
+				"kustomization.yml": `resources:
+- helm-rendered-manifests.yaml
+namePrefix: bar-
+`,
+			},
+			expectOutput: `apiVersion: v1
+data:
+  key: value
+kind: ConfigMap
+metadata:
+  name: bar-foo
+`,
+		},
+		{
+			name: "no kustomization",
+			files: map[string]string{
+				"configmap.yaml": testRenderedManifests,
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeOverlay(t, tc.files)
+			defer os.RemoveAll(dir)
+
+			pr, err := NewPostRenderer("", dir)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("Expected error; got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error; got error: %v", err)
+			}
+			out, err := pr.Run(bytes.NewBufferString(testRenderedManifests))
+			if err != nil {
+				t.Fatalf("Failed to run post-renderer: %v", err)
+			}
+			assert.Equal(t, tc.expectOutput, out.String())
+		})
+	}
+}
+
+func TestNewPostRenderer(t *testing.T) {
+	if _, err := NewPostRenderer("", ""); err == nil {
+		t.Errorf("Expected error for no post-renderer; got no error")
+	}
+	if _, err := NewPostRenderer("foo", "bar"); err == nil {
+		t.Errorf("Expected error for both exec and kustomize; got no error")
+	}
+	if _, err := NewPostRenderer("nonexistent-post-renderer", ""); err == nil {
+		t.Errorf("Expected error for nonexistent exec; got no error")
+	}
+}
@@ -20,7 +20,11 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"sort"
+	"strings"
+	"text/template"
 
+	"github.com/Masterminds/sprig/v3"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
@@ -35,6 +39,26 @@ type Watch struct {
 	ChartDir                string            `json:"chart"`
 	WatchDependentResources *bool             `json:"watchDependentResources,omitempty"`
 	OverrideValues          map[string]string `json:"overrideValues,omitempty"`
+	// ReleaseName is a template for the names of releases of custom resources, ex.
+	// "{{ .Namespace }}-{{ .Name }}". If empty, a custom resource's name is its release's name.
+	ReleaseName string `json:"releaseName,omitempty"`
+	// AdoptReleases allows a custom resource to take over an existing release with its release name,
+	// ex. one installed with the helm CLI, instead of failing to reconcile.
+	AdoptReleases bool `json:"adoptReleases,omitempty"`
+	// PostRenderer modifies the rendered manifests of releases before they are applied.
+	PostRenderer *PostRenderer `json:"postRenderer,omitempty"`
+	// ValuePolicy restricts the chart values custom resources can set in their spec.
+	ValuePolicy *ValuePolicy `json:"valuePolicy,omitempty"`
+}
+
+// PostRenderer configures a post-renderer for a watch. Exactly one field must be set.
+type PostRenderer struct {
+	// Exec is the path to a binary that reads rendered manifests on stdin and writes
+	// modified manifests to stdout, like the helm CLI's --post-renderer.
+	Exec string `json:"exec,omitempty"`
+	// Kustomize is the path to a kustomize overlay directory. Rendered manifests are added to
+	// the resources of its kustomization, so it can patch them and add labels to them.
+	Kustomize string `json:"kustomize,omitempty"`
 }
 
 // UnmarshalYAML unmarshals an individual watch from the Helm watches.yaml file
@@ -102,6 +126,18 @@ func LoadReader(reader io.Reader) ([]Watch, error) {
 			return nil, fmt.Errorf("invalid chart directory %s: %w", w.ChartDir, err)
 		}
 
+		if _, err := ParseReleaseName(w.ReleaseName); err != nil {
+			return nil, fmt.Errorf("invalid release name template for %s: %w", gvk, err)
+		}
+
+		if err := verifyPostRenderer(w.PostRenderer); err != nil {
+			return nil, fmt.Errorf("invalid post-renderer for %s: %w", gvk, err)
+		}
+
+		if err := verifyValuePolicy(w.ValuePolicy); err != nil {
+			return nil, fmt.Errorf("invalid value policy for %s: %w", gvk, err)
+		}
+
 		if _, ok := watchesMap[gvk]; ok {
 			return nil, fmt.Errorf("duplicate GVK: %s", gvk)
 		}
@@ -116,6 +152,12 @@ func LoadReader(reader io.Reader) ([]Watch, error) {
 	return watches, nil
 }
 
+// ParseReleaseName parses a watch's release name template. Templates can use sprig functions,
+// and are executed with a custom resource's Name, Namespace, Kind, Labels, and Annotations.
+func ParseReleaseName(text string) (*template.Template, error) {
+	return template.New("releaseName").Funcs(sprig.TxtFuncMap()).Option("missingkey=error").Parse(text)
+}
+
 func expandOverrideEnvs(in map[string]string) map[string]string {
 	if in == nil {
 		return nil
@@ -127,6 +169,39 @@ func expandOverrideEnvs(in map[string]string) map[string]string {
 	return out
 }
 
+func verifyPostRenderer(pr *PostRenderer) error {
+	if pr == nil {
+		return nil
+	}
+	if (pr.Exec == "") == (pr.Kustomize == "") {
+		return errors.New("exactly one of exec or kustomize must be set")
+	}
+	if pr.Kustomize != "" {
+		info, err := os.Stat(pr.Kustomize)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("kustomize overlay %s is not a directory", pr.Kustomize)
+		}
+	}
+	return nil
+}
+
+func verifyValuePolicy(p *ValuePolicy) error {
+	if p == nil {
+		return nil
+	}
+	for _, path := range append(append([]string{}, p.Allow...), p.Deny...) {
+		for _, seg := range strings.Split(path, ".") {
+			if seg == "" {
+				return fmt.Errorf("value path %q has an empty segment", path)
+			}
+		}
+	}
+	return nil
+}
+
 func verifyGVK(gvk schema.GroupVersionKind) error {
 	// A GVK without a group is valid. Certain scenarios may cause a GVK
 	// without a group to fail in other ways later in the initialization
@@ -139,3 +214,80 @@ func verifyGVK(gvk schema.GroupVersionKind) error {
 	}
 	return nil
 }
+
+// ValuePolicy restricts the chart values custom resources can set in their spec, ex. to stop
+// cluster users from overriding security-sensitive values like images. Values that cannot be set
+// keep the chart's defaults or overrideValues.
+//
+// Paths are dot-separated value keys, and a "*" segment matches any key, ex. "ingress.*.host".
+// A path matches the value at the path and every value nested under it.
+type ValuePolicy struct {
+	// Allow lists the paths of values custom resources can set. If empty, every value not denied can be set.
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists the paths of values custom resources cannot set. Deny takes precedence over Allow.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Violations returns the sorted paths of values set in spec that p does not allow.
+func (p ValuePolicy) Violations(spec map[string]interface{}) []string {
+	var violations []string
+	walkValues(spec, nil, func(path []string, v interface{}) {
+		if !p.allows(path, v) {
+			violations = append(violations, strings.Join(path, "."))
+		}
+	})
+	sort.Strings(violations)
+	return violations
+}
+
+// allows returns true if the value v at path can be set.
+func (p ValuePolicy) allows(path []string, v interface{}) bool {
+	// An empty map only sets keys in its parents, but any other value, including null, replaces the
+	// chart's values under it, so it is not allowed if it could replace a denied value.
+	m, isMap := v.(map[string]interface{})
+	isEmptyMap := isMap && len(m) == 0
+	for _, pattern := range p.Deny {
+		prefix, under := matchPath(strings.Split(pattern, "."), path)
+		if prefix || (under && !isEmptyMap) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.Allow {
+		prefix, under := matchPath(strings.Split(pattern, "."), path)
+		if prefix || (under && isEmptyMap) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath returns whether pattern matches path or a prefix of it, and whether path matches
+// a proper prefix of pattern, i.e. pattern matches values under path.
+func matchPath(pattern, path []string) (prefix, under bool) {
+	n := len(pattern)
+	if len(path) < n {
+		n = len(path)
+	}
+	for i := 0; i < n; i++ {
+		if pattern[i] != "*" && pattern[i] != path[i] {
+			return false, false
+		}
+	}
+	return len(pattern) <= len(path), len(pattern) > len(path)
+}
+
+// walkValues calls f with the path and value of every leaf in values. Empty maps, lists,
+// and scalars are leaves.
+func walkValues(values map[string]interface{}, parent []string, f func([]string, interface{})) {
+	for k, v := range values {
+		path := append(append([]string{}, parent...), k)
+		if m, ok := v.(map[string]interface{}); ok && len(m) != 0 {
+			walkValues(m, path, f)
+			continue
+		}
+		f(path, v)
+	}
+}
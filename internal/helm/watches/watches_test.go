@@ -76,6 +76,72 @@ func TestLoadReader(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "valid with release name template and adoption",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseName: "{{ .Namespace }}-{{ .Name | trunc 20 }}"
+  adoptReleases: true
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					ReleaseName:             "{{ .Namespace }}-{{ .Name | trunc 20 }}",
+					AdoptReleases:           true,
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid with post-renderer",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  postRenderer:
+    kustomize: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart/templates
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					PostRenderer: &PostRenderer{
+						Kustomize: "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart/templates",
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid with value policy",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  valuePolicy:
+    allow:
+    - replicaCount
+    deny:
+    - image
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					ValuePolicy:             &ValuePolicy{Allow: []string{"replicaCount"}, Deny: []string{"image"}},
+				},
+			},
+			expectErr: false,
+		},
 		{
 			name: "multiple gvk",
 			data: `---
@@ -154,6 +220,55 @@ func TestLoadReader(t *testing.T) {
   overrideValues:
     key1:
 		key2: value
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid release name template",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseName: "{{ .Name"
+`,
+			expectErr: true,
+		},
+		{
+			name: "post-renderer with exec and kustomize",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  postRenderer:
+    exec: /bin/cat
+    kustomize: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart/templates
+`,
+			expectErr: true,
+		},
+		{
+			name: "post-renderer with nonexistent kustomize overlay",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  postRenderer:
+    kustomize: nonexistent/path/to/overlay
+`,
+			expectErr: true,
+		},
+		{
+			name: "value policy with empty path segment",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  valuePolicy:
+    deny:
+    - securityContext..runAsUser
 `,
 			expectErr: true,
 		},
@@ -257,6 +372,77 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestValuePolicyViolations(t *testing.T) {
+	spec := map[string]interface{}{
+		"replicaCount": 3,
+		"image": map[string]interface{}{
+			"repository": "example.com/app",
+			"tag":        "v1",
+		},
+		"securityContext": map[string]interface{}{
+			"runAsUser":    0,
+			"runAsNonRoot": true,
+		},
+		"ingress": map[string]interface{}{
+			"hosts": map[string]interface{}{
+				"web": map[string]interface{}{"host": "example.com", "tls": true},
+			},
+		},
+		"podAnnotations": map[string]interface{}{},
+	}
+
+	testCases := []struct {
+		name             string
+		policy           ValuePolicy
+		spec             map[string]interface{}
+		expectViolations []string
+	}{
+		{
+			name:   "empty policy",
+			policy: ValuePolicy{},
+			spec:   spec,
+		},
+		{
+			name:             "deny",
+			policy:           ValuePolicy{Deny: []string{"image", "securityContext.runAsUser"}},
+			spec:             spec,
+			expectViolations: []string{"image.repository", "image.tag", "securityContext.runAsUser"},
+		},
+		{
+			name:   "allow",
+			policy: ValuePolicy{Allow: []string{"replicaCount", "ingress.*.*.host", "podAnnotations.foo"}},
+			spec:   spec,
+			expectViolations: []string{
+				"image.repository", "image.tag", "ingress.hosts.web.tls",
+				"securityContext.runAsNonRoot", "securityContext.runAsUser",
+			},
+		},
+		{
+			name:             "deny takes precedence over allow",
+			policy:           ValuePolicy{Allow: []string{"securityContext"}, Deny: []string{"securityContext.runAsUser"}},
+			spec:             map[string]interface{}{"securityContext": spec["securityContext"]},
+			expectViolations: []string{"securityContext.runAsUser"},
+		},
+		{
+			name:             "replacing a parent of a denied value",
+			policy:           ValuePolicy{Deny: []string{"securityContext.runAsUser"}},
+			spec:             map[string]interface{}{"securityContext": nil},
+			expectViolations: []string{"securityContext"},
+		},
+		{
+			name:   "empty parent of a denied value",
+			policy: ValuePolicy{Deny: []string{"securityContext.runAsUser"}},
+			spec:   map[string]interface{}{"securityContext": map[string]interface{}{}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectViolations, tc.policy.Violations(tc.spec))
+		})
+	}
+}
+
 // remove removes path from disk. Used in defer statements.
 func removeFile(t *testing.T, f *os.File) {
 	if err := f.Close(); err != nil {
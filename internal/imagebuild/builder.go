@@ -0,0 +1,111 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuild
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Builder builds images.
+type Builder interface {
+	// Build builds the image opts.Tag. Builders that build remotely also push the image.
+	Build(ctx context.Context, opts BuildOptions) error
+}
+
+// NewBuilder returns the Builder of a local container tool, one of docker, podman, or buildah.
+// Kaniko builders are created with NewKanikoBuilder, since they need a cluster.
+func NewBuilder(tool string) (Builder, error) {
+	switch tool {
+	case Docker, Podman:
+		return execBuilder{tool: tool, args: buildArgs}, nil
+	case Buildah:
+		return execBuilder{tool: tool, args: buildahArgs}, nil
+	case Kaniko:
+		return nil, fmt.Errorf("%s builds require a cluster", Kaniko)
+	case None:
+		return nil, fmt.Errorf("container tool %q cannot build images", None)
+	}
+	return nil, fmt.Errorf("unsupported image builder %q, must be one of [%s]", tool, strings.Join(Builders, ", "))
+}
+
+// Builders are the supported image builders.
+var Builders = []string{Docker, Podman, Buildah, Kaniko}
+
+// execBuilder builds images by running a local container tool.
+type execBuilder struct {
+	tool string
+	// args returns the tool's arguments to build an image with validated opts.
+	args func(opts BuildOptions) []string
+}
+
+func (b execBuilder) Build(ctx context.Context, opts BuildOptions) error {
+	opts, err := opts.validate()
+	if err != nil {
+		return err
+	}
+	args := b.args(opts)
+	cmd := exec.CommandContext(ctx, b.tool, args...)
+	cmd.Stdout, cmd.Stderr = opts.Stdout, opts.Stderr
+	log.Debugf("Running %s %s", b.tool, strings.Join(args, " "))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error building image %s with %s: %v", opts.Tag, b.tool, err)
+	}
+	return nil
+}
+
+// buildArgs are the build arguments of docker and podman. Native path separators are kept,
+// which both tools accept on Windows.
+func buildArgs(opts BuildOptions) []string {
+	args := []string{"build", "-f", opts.Dockerfile, "-t", opts.Tag}
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+	return append(args, opts.Context)
+}
+
+// buildahArgs are the build arguments of buildah. Images are built in the docker format, like docker
+// and podman builds, so bundle and index image labels are kept.
+func buildahArgs(opts BuildOptions) []string {
+	args := []string{"bud", "--format", "docker", "-f", opts.Dockerfile, "-t", opts.Tag}
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+	return append(args, opts.Context)
+}
+
+// Push pushes image to its registry with tool, one of docker, podman, or buildah. Images built by kaniko
+// were pushed by their build, so are not pushed again.
+func Push(ctx context.Context, tool, image string) error {
+	switch tool {
+	case Docker, Podman, Buildah:
+	case Kaniko:
+		return nil
+	default:
+		return fmt.Errorf("container tool %q cannot push images", tool)
+	}
+	cmd := exec.CommandContext(ctx, tool, "push", image)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	log.Debugf("Running %s push %s", tool, image)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error pushing image %s with %s: %v", image, tool, err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuild
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewBuilder", func() {
+	opts := BuildOptions{Dockerfile: "/bundle.Dockerfile", Context: "/project", Tag: "image", Platform: "linux/amd64"}
+
+	It("returns builders of local tools", func() {
+		for tool, args := range map[string][]string{
+			Docker:  {"build", "-f", "/bundle.Dockerfile", "-t", "image", "--platform", "linux/amd64", "/project"},
+			Podman:  {"build", "-f", "/bundle.Dockerfile", "-t", "image", "--platform", "linux/amd64", "/project"},
+			Buildah: {"bud", "--format", "docker", "-f", "/bundle.Dockerfile", "-t", "image", "--platform", "linux/amd64", "/project"},
+		} {
+			b, err := NewBuilder(tool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b).To(BeAssignableToTypeOf(execBuilder{}))
+			Expect(b.(execBuilder).tool).To(Equal(tool))
+			Expect(b.(execBuilder).args(opts)).To(Equal(args))
+		}
+	})
+	It("does not return builders that cannot build locally", func() {
+		for _, tool := range []string{Kaniko, None, "img"} {
+			_, err := NewBuilder(tool)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
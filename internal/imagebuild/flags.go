@@ -0,0 +1,68 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuild
+
+import (
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+)
+
+// Flags are the flags of commands building images.
+type Flags struct {
+	Tool             string
+	Platform         string
+	KanikoImage      string
+	KanikoPushSecret string
+}
+
+// BindFlags binds f to fs.
+func (f *Flags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&f.Tool, "image-builder", "b", "", "Tool to build the image with. One of: [docker, podman, "+
+		"buildah, kaniko]. Defaults to $"+ImageBuilderEnv+", or the first of docker or podman installed. "+
+		"kaniko builds run in a pod in --namespace, and push the image")
+	fs.StringVar(&f.Platform, "platform", "", "Platform of the image, ex. linux/amd64")
+	fs.StringVar(&f.KanikoImage, "kaniko-image", DefaultKanikoImage, "kaniko executor image of kaniko builds")
+	fs.StringVar(&f.KanikoPushSecret, "kaniko-push-secret", "", "Name of a kubernetes.io/dockerconfigjson "+
+		"Secret in --namespace with the registry credentials of kaniko builds")
+}
+
+// Validate checks f's values, and detects the tool if unset.
+func (f *Flags) Validate() (err error) {
+	if err := ValidatePlatform(f.Platform); err != nil {
+		return err
+	}
+	if f.Tool == "" {
+		f.Tool, err = DetectTool(false)
+	}
+	return err
+}
+
+// Builder returns the Builder of f.Tool. cluster returns the config and namespace of the cluster
+// kaniko builds run in, and is only called for kaniko builds.
+func (f Flags) Builder(cluster func() (*rest.Config, string, error)) (Builder, error) {
+	if f.Tool != Kaniko {
+		return NewBuilder(f.Tool)
+	}
+	cfg, namespace, err := cluster()
+	if err != nil {
+		return nil, err
+	}
+	b, err := NewKanikoBuilder(cfg, namespace)
+	if err != nil {
+		return nil, err
+	}
+	b.Image, b.PushSecret = f.KanikoImage, f.KanikoPushSecret
+	return b, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package imagebuild selects container tools and builds images with them, consistently across
+// commands and developer hosts, including arm64 and Windows hosts. Images are built by a Builder,
+// which runs docker, podman, or buildah locally, or kaniko in a cluster.
+package imagebuild
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Container tools.
+const (
+	Docker  = "docker"
+	Podman  = "podman"
+	Buildah = "buildah"
+	// Kaniko builds images in a cluster, and pushes them to their registry.
+	Kaniko = "kaniko"
+	// None pulls and unpacks images without a container tool. It cannot build images.
+	None = "none"
+)
+
+// ImageBuilderEnv is the environment variable setting the default container tool of all commands.
+const ImageBuilderEnv = "OPERATOR_SDK_IMAGE_BUILDER"
+
+// lookPath is exec.LookPath, which finds '.exe' files on Windows. It is replaced in tests.
+var lookPath = exec.LookPath
+
+// DetectTool returns the container tool set in ImageBuilderEnv, or the first of docker or podman found
+// in PATH. If neither is found and allowNone is true, None is returned; otherwise an error is returned.
+func DetectTool(allowNone bool) (string, error) {
+	if tool := os.Getenv(ImageBuilderEnv); tool != "" {
+		return tool, nil
+	}
+	for _, tool := range []string{Docker, Podman} {
+		if _, err := lookPath(tool); err == nil {
+			return tool, nil
+		}
+	}
+	if allowNone {
+		return None, nil
+	}
+	return "", fmt.Errorf("neither %s nor %s was found in PATH; install one or set --image-builder", Docker, Podman)
+}
+
+// ValidatePlatform returns an error if platform is not empty and is not of the form "<os>/<arch>[/<variant>]",
+// ex. "linux/amd64" or "linux/arm64/v8".
+func ValidatePlatform(platform string) error {
+	if platform == "" {
+		return nil
+	}
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fmt.Errorf("invalid platform %q: must be of the form <os>/<arch>[/<variant>]", platform)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return fmt.Errorf("invalid platform %q: must be of the form <os>/<arch>[/<variant>]", platform)
+		}
+	}
+	return nil
+}
+
+// WarnHostPlatform logs a warning if platform is unset on a host that is not amd64, since images built
+// there target the host's architecture, while clusters commonly run amd64 nodes.
+func WarnHostPlatform(platform string) {
+	if platform == "" && runtime.GOARCH != "amd64" {
+		log.Warnf("Building for this host's architecture %s; set --platform, ex. linux/amd64, "+
+			"if the image will run on nodes of another architecture", runtime.GOARCH)
+	}
+}
+
+// BuildOptions configure an image build.
+type BuildOptions struct {
+	// Dockerfile is the path of the Dockerfile.
+	Dockerfile string
+	// Context is the build context directory.
+	Context string
+	// Tag is the image's tag.
+	Tag string
+	// Platform is the platform to build the image for, ex. linux/amd64. Defaults to the host's platform.
+	Platform string
+	// Stdout and Stderr receive the output of the build. Both default to os.Stderr.
+	Stdout, Stderr io.Writer
+}
+
+// validate checks opts, and returns them with absolute paths, so builds do not depend on a tool's
+// working directory, and with os.Stderr set as default outputs.
+func (o BuildOptions) validate() (BuildOptions, error) {
+	if o.Tag == "" {
+		return o, errors.New("image tag must be set")
+	}
+	if err := ValidatePlatform(o.Platform); err != nil {
+		return o, err
+	}
+	var err error
+	if o.Dockerfile, err = filepath.Abs(o.Dockerfile); err != nil {
+		return o, err
+	}
+	if o.Context, err = filepath.Abs(o.Context); err != nil {
+		return o, err
+	}
+	if _, err := os.Stat(o.Dockerfile); err != nil {
+		return o, fmt.Errorf("error reading Dockerfile: %v", err)
+	}
+	if o.Stdout == nil {
+		o.Stdout = os.Stderr
+	}
+	if o.Stderr == nil {
+		o.Stderr = os.Stderr
+	}
+	return o, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuild
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DetectTool", func() {
+	var installed map[string]bool
+
+	BeforeEach(func() {
+		installed = map[string]bool{}
+		lookPath = func(file string) (string, error) {
+			if installed[file] {
+				return "/usr/bin/" + file, nil
+			}
+			return "", exec.ErrNotFound
+		}
+		Expect(os.Unsetenv(ImageBuilderEnv)).To(Succeed())
+	})
+	AfterEach(func() {
+		lookPath = exec.LookPath
+		Expect(os.Unsetenv(ImageBuilderEnv)).To(Succeed())
+	})
+
+	It("prefers the environment variable", func() {
+		installed[Docker] = true
+		Expect(os.Setenv(ImageBuilderEnv, Podman)).To(Succeed())
+		Expect(DetectTool(false)).To(Equal(Podman))
+	})
+	It("prefers docker over podman", func() {
+		installed[Docker], installed[Podman] = true, true
+		Expect(DetectTool(false)).To(Equal(Docker))
+	})
+	It("falls back to podman", func() {
+		installed[Podman] = true
+		Expect(DetectTool(false)).To(Equal(Podman))
+	})
+	It("returns none if allowed and no tool is installed", func() {
+		Expect(DetectTool(true)).To(Equal(None))
+		_, err := DetectTool(false)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ValidatePlatform", func() {
+	It("accepts empty and well-formed platforms", func() {
+		for _, p := range []string{"", "linux/amd64", "linux/arm64/v8", "windows/amd64"} {
+			Expect(ValidatePlatform(p)).To(Succeed())
+		}
+	})
+	It("rejects malformed platforms", func() {
+		for _, p := range []string{"amd64", "linux/", "/amd64", "linux/arm64/v8/x"} {
+			Expect(ValidatePlatform(p)).NotTo(Succeed())
+		}
+	})
+})
+
+var _ = Describe("BuildOptions", func() {
+	It("makes paths absolute", func() {
+		dir, err := ioutil.TempDir("", "imagebuild")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Expect(ioutil.WriteFile(filepath.Join(dir, "bundle.Dockerfile"), []byte("FROM scratch\n"), 0644)).To(Succeed())
+		wd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(dir)).To(Succeed())
+		defer func() { Expect(os.Chdir(wd)).To(Succeed()) }()
+
+		opts, err := BuildOptions{Dockerfile: "bundle.Dockerfile", Context: ".", Tag: "image"}.validate()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.IsAbs(opts.Dockerfile)).To(BeTrue())
+		Expect(filepath.IsAbs(opts.Context)).To(BeTrue())
+		Expect(opts.Stdout).To(Equal(os.Stderr))
+	})
+	It("requires a tag", func() {
+		_, err := BuildOptions{Dockerfile: "Dockerfile", Context: "."}.validate()
+		Expect(err).To(HaveOccurred())
+	})
+	It("requires an existing Dockerfile", func() {
+		_, err := BuildOptions{Dockerfile: "missing.Dockerfile", Context: ".", Tag: "image"}.validate()
+		Expect(err).To(MatchError(ContainSubstring("error reading Dockerfile")))
+	})
+})
@@ -0,0 +1,234 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuild
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// DefaultKanikoImage is the kaniko executor image run by kaniko builds.
+const DefaultKanikoImage = "gcr.io/kaniko-project/executor:v1.9.1"
+
+const (
+	kanikoContainerName = "kaniko"
+	kanikoDockerConfig  = "/kaniko/.docker"
+)
+
+// KanikoBuilder builds images with kaniko in a pod, streaming the build context to the pod,
+// and pushes them to their registry.
+type KanikoBuilder struct {
+	// Image is the kaniko executor image. Defaults to DefaultKanikoImage.
+	Image string
+	// Namespace is the namespace of build pods.
+	Namespace string
+	// PushSecret is the name of a kubernetes.io/dockerconfigjson Secret in Namespace with the credentials
+	// kaniko pushes images with. Images are pushed anonymously if unset.
+	PushSecret string
+
+	cfg    *rest.Config
+	client kubernetes.Interface
+}
+
+// NewKanikoBuilder returns a KanikoBuilder running build pods in namespace of the cluster of cfg.
+func NewKanikoBuilder(cfg *rest.Config, namespace string) (*KanikoBuilder, error) {
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &KanikoBuilder{Image: DefaultKanikoImage, Namespace: namespace, cfg: cfg, client: cs}, nil
+}
+
+func (b KanikoBuilder) Build(ctx context.Context, opts BuildOptions) error {
+	opts, err := opts.validate()
+	if err != nil {
+		return err
+	}
+	dockerfile, err := filepath.Rel(opts.Context, opts.Dockerfile)
+	if err != nil || strings.HasPrefix(dockerfile, "..") {
+		return fmt.Errorf("dockerfile %s must be in the build context %s for %s builds", opts.Dockerfile,
+			opts.Context, Kaniko)
+	}
+
+	pod, err := b.client.CoreV1().Pods(b.Namespace).Create(ctx, b.newPod(opts, filepath.ToSlash(dockerfile)),
+		metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("error creating build pod: %v", err)
+	}
+	log.Infof("Building image %s in pod %s/%s", opts.Tag, pod.GetNamespace(), pod.GetName())
+	defer func() {
+		// The build context may be cancelled, so delete the pod with a fresh one.
+		delCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := b.client.CoreV1().Pods(b.Namespace).Delete(delCtx, pod.GetName(), metav1.DeleteOptions{}); err != nil {
+			log.Errorf("Error deleting build pod %s: %v", pod.GetName(), err)
+		}
+	}()
+
+	if err := b.waitForPod(ctx, pod.GetName(), func(p *corev1.Pod) bool {
+		return p.Status.Phase != corev1.PodPending
+	}); err != nil {
+		return fmt.Errorf("error waiting for build pod to start: %v", err)
+	}
+	if err := b.streamContext(ctx, pod.GetName(), opts); err != nil {
+		return fmt.Errorf("error streaming build context: %v", err)
+	}
+	if err := b.waitForPod(ctx, pod.GetName(), func(p *corev1.Pod) bool {
+		return p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed
+	}); err != nil {
+		return fmt.Errorf("error waiting for build pod to complete: %v", err)
+	}
+	pod, err = b.client.CoreV1().Pods(b.Namespace).Get(ctx, pod.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if pod.Status.Phase != corev1.PodSucceeded {
+		return fmt.Errorf("error building image %s with %s: build pod %s failed", opts.Tag, Kaniko, pod.GetName())
+	}
+	return nil
+}
+
+// newPod returns a build pod reading a gzipped tar of the build context from stdin.
+func (b KanikoBuilder) newPod(opts BuildOptions, dockerfile string) *corev1.Pod {
+	image := b.Image
+	if image == "" {
+		image = DefaultKanikoImage
+	}
+	args := []string{"--context=tar://stdin", "--dockerfile=" + dockerfile, "--destination=" + opts.Tag}
+	if opts.Platform != "" {
+		args = append(args, "--custom-platform="+opts.Platform)
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kaniko-build-",
+			Namespace:    b.Namespace,
+			Labels:       map[string]string{"app.kubernetes.io/created-by": "operator-sdk"},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:      kanikoContainerName,
+				Image:     image,
+				Args:      args,
+				Stdin:     true,
+				StdinOnce: true,
+			}},
+		},
+	}
+	if b.PushSecret != "" {
+		pod.Spec.Volumes = []corev1.Volume{{
+			Name: "docker-config",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: b.PushSecret,
+				Items:      []corev1.KeyToPath{{Key: corev1.DockerConfigJsonKey, Path: "config.json"}},
+			}},
+		}}
+		pod.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "docker-config", MountPath: kanikoDockerConfig}}
+	}
+	return pod
+}
+
+// streamContext attaches to the build pod, and writes the build context to its stdin.
+func (b KanikoBuilder) streamContext(ctx context.Context, name string, opts BuildOptions) error {
+	req := b.client.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(b.Namespace).Name(name).SubResource("attach").
+		VersionedParams(&corev1.PodAttachOptions{
+			Container: kanikoContainerName,
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(b.cfg, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeContextTar(pw, opts.Context))
+	}()
+	defer pr.Close()
+	return executor.Stream(remotecommand.StreamOptions{Stdin: pr, Stdout: opts.Stdout, Stderr: opts.Stderr})
+}
+
+func (b KanikoBuilder) waitForPod(ctx context.Context, name string, done func(*corev1.Pod) bool) error {
+	return wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		pod, err := b.client.CoreV1().Pods(b.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return done(pod), nil
+	}, ctx.Done())
+}
+
+// writeContextTar writes the files in dir to w as a gzipped tar, with slash-separated paths
+// relative to dir, as kaniko expects on any host.
+func writeContextTar(w io.Writer, dir string) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
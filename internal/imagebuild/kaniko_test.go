@@ -0,0 +1,90 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagebuild
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("KanikoBuilder", func() {
+	Describe("newPod", func() {
+		opts := BuildOptions{Tag: "quay.io/example/index:v0.0.1", Platform: "linux/arm64"}
+
+		It("returns a pod building from stdin", func() {
+			pod := KanikoBuilder{Namespace: "builds"}.newPod(opts, "index.Dockerfile")
+			Expect(pod.GetNamespace()).To(Equal("builds"))
+			Expect(pod.Spec.Containers).To(HaveLen(1))
+			c := pod.Spec.Containers[0]
+			Expect(c.Image).To(Equal(DefaultKanikoImage))
+			Expect(c.Stdin).To(BeTrue())
+			Expect(c.Args).To(Equal([]string{"--context=tar://stdin", "--dockerfile=index.Dockerfile",
+				"--destination=quay.io/example/index:v0.0.1", "--custom-platform=linux/arm64"}))
+			Expect(pod.Spec.Volumes).To(BeEmpty())
+		})
+		It("mounts the push secret", func() {
+			pod := KanikoBuilder{Namespace: "builds", PushSecret: "quay"}.newPod(opts, "index.Dockerfile")
+			Expect(pod.Spec.Volumes).To(HaveLen(1))
+			Expect(pod.Spec.Volumes[0].Secret.SecretName).To(Equal("quay"))
+			Expect(pod.Spec.Volumes[0].Secret.Items).To(Equal([]corev1.KeyToPath{
+				{Key: corev1.DockerConfigJsonKey, Path: "config.json"},
+			}))
+			Expect(pod.Spec.Containers[0].VolumeMounts[0].MountPath).To(Equal(kanikoDockerConfig))
+		})
+	})
+
+	Describe("writeContextTar", func() {
+		It("writes the directory with slash-separated paths", func() {
+			dir, err := ioutil.TempDir("", "kaniko")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			Expect(os.MkdirAll(filepath.Join(dir, "database"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "index.Dockerfile"), []byte("FROM scratch\n"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "database", "index.db"), []byte("db"), 0644)).To(Succeed())
+
+			buf := &bytes.Buffer{}
+			Expect(writeContextTar(buf, dir)).To(Succeed())
+
+			gr, err := gzip.NewReader(buf)
+			Expect(err).NotTo(HaveOccurred())
+			tr := tar.NewReader(gr)
+			files := map[string]string{}
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).NotTo(HaveOccurred())
+				b, err := ioutil.ReadAll(tr)
+				Expect(err).NotTo(HaveOccurred())
+				files[hdr.Name] = string(b)
+			}
+			Expect(files).To(Equal(map[string]string{
+				"database":          "",
+				"database/index.db": "db",
+				"index.Dockerfile":  "FROM scratch\n",
+			}))
+		})
+	})
+})
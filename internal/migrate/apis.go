@@ -0,0 +1,408 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migrate rewrites the manifests of existing projects to replace deprecated API versions.
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+const (
+	crdKind               = "CustomResourceDefinition"
+	mutatingWebhookKind   = "MutatingWebhookConfiguration"
+	validatingWebhookKind = "ValidatingWebhookConfiguration"
+	admissionV1beta1      = "admissionregistration.k8s.io/v1beta1"
+	admissionV1           = "admissionregistration.k8s.io/v1"
+
+	// defaultReviewVersion is the version of review objects sent to v1beta1 webhooks.
+	defaultReviewVersion = "v1beta1"
+	// Defaults of v1beta1 webhook fields that changed in v1.
+	v1beta1FailurePolicy  = "Ignore"
+	v1beta1MatchPolicy    = "Exact"
+	v1beta1TimeoutSeconds = int64(30)
+
+	preserveUnknownFieldsKey = "x-kubernetes-preserve-unknown-fields"
+)
+
+// Result is the outcome of migrating a file.
+type Result struct {
+	Path string
+	// Content is the migrated content of the file, which is only set if the file changed.
+	Content []byte
+	// Fixes describe changes made besides updating API versions, which should be reviewed.
+	Fixes []string
+	// Issues describe problems that could not be fixed, and must be fixed by hand.
+	Issues []string
+}
+
+// Changed returns true if the file's content changed.
+func (r Result) Changed() bool {
+	return r.Content != nil
+}
+
+func (r *Result) fix(format string, args ...interface{}) {
+	r.Fixes = append(r.Fixes, fmt.Sprintf(format, args...))
+}
+
+func (r *Result) issue(format string, args ...interface{}) {
+	r.Issues = append(r.Issues, fmt.Sprintf(format, args...))
+}
+
+// Dirs migrates the v1beta1 CRDs, CRD patches, and webhook configurations in YAML files under dirs
+// to v1. Files without such manifests are omitted from results. Missing dirs are skipped.
+func Dirs(dirs ...string) (results []Result, err error) {
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+				return nil
+			}
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			r, err := Manifests(b)
+			if err != nil {
+				return fmt.Errorf("error migrating %s: %v", path, err)
+			}
+			if r.Changed() || len(r.Issues) != 0 {
+				r.Path = path
+				results = append(results, r)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// Manifests migrates each manifest in the multi-document YAML b. Other manifests are kept as-is.
+func Manifests(b []byte) (r Result, err error) {
+	var docs [][]byte
+	changed := false
+	scanner := k8sutil.NewYAMLScanner(bytes.NewBuffer(b))
+	for scanner.Scan() {
+		doc := scanner.Bytes()
+		migrated, err := manifest(doc, &r)
+		if err != nil {
+			return Result{}, err
+		}
+		if migrated != nil {
+			changed = true
+			doc = migrated
+		}
+		docs = append(docs, bytes.TrimSpace(doc))
+	}
+	if err := scanner.Err(); err != nil {
+		return Result{}, err
+	}
+	if changed {
+		r.Content = append(bytes.Join(docs, []byte("\n---\n")), '\n')
+	}
+	return r, nil
+}
+
+// manifest returns the migrated manifest, or nil if it does not need to be migrated.
+func manifest(doc []byte, r *Result) ([]byte, error) {
+	typeMeta, err := k8sutil.GetTypeMetaFromBytes(doc)
+	if err != nil {
+		// Not an object, ex. a kustomization.yaml without apiVersion.
+		return nil, nil
+	}
+	switch {
+	case typeMeta.APIVersion == apiextv1beta1.SchemeGroupVersion.String() && typeMeta.Kind == crdKind:
+		u := map[string]interface{}{}
+		if err := yaml.Unmarshal(doc, &u); err != nil {
+			return nil, err
+		}
+		if _, hasNames, _ := unstructured.NestedMap(u, "spec", "names"); hasNames {
+			return crd(doc, r)
+		}
+		return crdPatch(u, r)
+	case typeMeta.APIVersion == admissionV1beta1 &&
+		(typeMeta.Kind == mutatingWebhookKind || typeMeta.Kind == validatingWebhookKind):
+		u := map[string]interface{}{}
+		if err := yaml.Unmarshal(doc, &u); err != nil {
+			return nil, err
+		}
+		return webhookConfiguration(u, r)
+	}
+	return nil, nil
+}
+
+// crd converts a complete v1beta1 CRD to v1, and fixes the structural schema problems it can infer.
+func crd(doc []byte, r *Result) ([]byte, error) {
+	in := &apiextv1beta1.CustomResourceDefinition{}
+	if err := yaml.Unmarshal(doc, in); err != nil {
+		return nil, err
+	}
+	out, err := k8sutil.Convertv1beta1Tov1CustomResourceDefinition(in)
+	if err != nil {
+		return nil, fmt.Errorf("error converting CRD %s: %v", in.GetName(), err)
+	}
+	name := in.GetName()
+
+	if in.Spec.PreserveUnknownFields == nil || *in.Spec.PreserveUnknownFields {
+		out.Spec.PreserveUnknownFields = false
+		r.issue("CRD %s: v1 CRDs prune unknown fields, so fields missing from the schema are now dropped; "+
+			"set %s: true on schemas of fields that accept arbitrary content", name, preserveUnknownFieldsKey)
+	}
+	if c := out.Spec.Conversion; c != nil && c.Strategy == apiextv1.WebhookConverter {
+		if c.Webhook == nil {
+			c.Webhook = &apiextv1.WebhookConversion{}
+		}
+		if len(c.Webhook.ConversionReviewVersions) == 0 {
+			c.Webhook.ConversionReviewVersions = []string{defaultReviewVersion}
+			r.fix("CRD %s: set conversionReviewVersions to [%s], the version v1beta1 CRDs used",
+				name, defaultReviewVersion)
+		}
+	}
+	for i := range out.Spec.Versions {
+		v := &out.Spec.Versions[i]
+		field := fmt.Sprintf("CRD %s version %s", name, v.Name)
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			preserve := true
+			v.Schema = &apiextv1.CustomResourceValidation{OpenAPIV3Schema: &apiextv1.JSONSchemaProps{
+				Type:                   "object",
+				XPreserveUnknownFields: &preserve,
+			}}
+			r.fix("%s: v1 CRDs require a schema, so added one accepting any object; consider generating "+
+				"a complete schema", field)
+			continue
+		}
+		// Versions share the schema converted from spec.validation, so each is copied before being fixed.
+		v.Schema = v.Schema.DeepCopy()
+		fixRootSchema(field, v.Schema.OpenAPIV3Schema, r)
+	}
+	return marshalCRD(out)
+}
+
+// fixRootSchema fixes structural problems in a CRD version's schema, which must be an object whose
+// metadata schema only restricts name and generateName.
+func fixRootSchema(field string, s *apiextv1.JSONSchemaProps, r *Result) {
+	if s.Type != "object" {
+		s.Type = "object"
+		r.fix("%s: set the root schema's type to object", field)
+	}
+	if metadata, ok := s.Properties["metadata"]; ok {
+		for name := range metadata.Properties {
+			if name != "name" && name != "generateName" {
+				s.Properties["metadata"] = apiextv1.JSONSchemaProps{Type: "object"}
+				r.fix("%s: removed metadata fields other than name and generateName from the schema, "+
+					"which v1 CRDs do not allow", field)
+				break
+			}
+		}
+	}
+	fixSchema(field, "", s, r)
+}
+
+// fixSchema infers missing types from properties and items, and reports other structural problems
+// of s and its children.
+func fixSchema(field, path string, s *apiextv1.JSONSchemaProps, r *Result) {
+	at := path
+	if at == "" {
+		at = "<root>"
+	}
+	if s.Type == "" && !s.XIntOrString && (s.XPreserveUnknownFields == nil || !*s.XPreserveUnknownFields) {
+		switch {
+		case len(s.Properties) != 0 || s.AdditionalProperties != nil:
+			s.Type = "object"
+			r.fix("%s: set the type of %s to object", field, at)
+		case s.Items != nil:
+			s.Type = "array"
+			r.fix("%s: set the type of %s to array", field, at)
+		default:
+			r.issue("%s: %s has no type; set one, or set %s: true for arbitrary content",
+				field, at, preserveUnknownFieldsKey)
+		}
+	}
+	if len(s.Properties) != 0 && s.AdditionalProperties != nil {
+		r.issue("%s: %s sets both properties and additionalProperties, which v1 CRDs do not allow", field, at)
+	}
+	if s.Items != nil && len(s.Items.JSONSchemas) != 0 {
+		r.issue("%s: %s sets items to a list of schemas, which v1 CRDs do not allow", field, at)
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop := s.Properties[name]
+		fixSchema(field, joinPath(path, name), &prop, r)
+		s.Properties[name] = prop
+	}
+	if s.Items != nil && s.Items.Schema != nil {
+		fixSchema(field, path+"[]", s.Items.Schema, r)
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+		fixSchema(field, path+"{}", s.AdditionalProperties.Schema, r)
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// marshalCRD marshals crd without its status and creation timestamp, which are set by the API server.
+func marshalCRD(crd *apiextv1.CustomResourceDefinition) ([]byte, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+	if err != nil {
+		return nil, err
+	}
+	delete(u, "status")
+	unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+	return yaml.Marshal(u)
+}
+
+// crdPatch migrates a partial v1beta1 CRD, ex. a kustomize patch, by moving its conversion webhook config.
+// Other version-specific fields must be moved to spec.versions, which cannot be inferred from a patch.
+func crdPatch(u map[string]interface{}, r *Result) ([]byte, error) {
+	name, _, _ := unstructured.NestedString(u, "metadata", "name")
+	u["apiVersion"] = apiextv1.SchemeGroupVersion.String()
+	if cfg, ok, _ := unstructured.NestedFieldNoCopy(u, "spec", "conversion", "webhookClientConfig"); ok {
+		unstructured.RemoveNestedField(u, "spec", "conversion", "webhookClientConfig")
+		if err := unstructured.SetNestedField(u, cfg, "spec", "conversion", "webhook", "clientConfig"); err != nil {
+			return nil, err
+		}
+		if _, ok, _ := unstructured.NestedFieldNoCopy(u, "spec", "conversion", "conversionReviewVersions"); ok {
+			versions, _, _ := unstructured.NestedFieldNoCopy(u, "spec", "conversion", "conversionReviewVersions")
+			unstructured.RemoveNestedField(u, "spec", "conversion", "conversionReviewVersions")
+			if err := unstructured.SetNestedField(u, versions, "spec", "conversion", "webhook",
+				"conversionReviewVersions"); err != nil {
+				return nil, err
+			}
+		} else if err := unstructured.SetNestedStringSlice(u, []string{defaultReviewVersion}, "spec",
+			"conversion", "webhook", "conversionReviewVersions"); err != nil {
+			return nil, err
+		}
+		r.fix("CRD patch %s: moved spec.conversion.webhookClientConfig to spec.conversion.webhook.clientConfig", name)
+	}
+	for _, f := range []string{"version", "validation", "subresources", "additionalPrinterColumns"} {
+		if _, ok, _ := unstructured.NestedFieldNoCopy(u, "spec", f); ok {
+			r.issue("CRD patch %s: spec.%s must be moved into each entry of spec.versions", name, f)
+		}
+	}
+	return yaml.Marshal(u)
+}
+
+// webhookConfiguration migrates a v1beta1 webhook configuration, setting fields whose defaults
+// changed in v1 to their v1beta1 defaults so the webhooks behave the same.
+func webhookConfiguration(u map[string]interface{}, r *Result) ([]byte, error) {
+	name, _, _ := unstructured.NestedString(u, "metadata", "name")
+	u["apiVersion"] = admissionV1
+	webhooks, ok, err := unstructured.NestedSlice(u, "webhooks")
+	if err != nil || !ok {
+		// A patch of only the configuration's metadata, ex. for CA injection.
+		return yaml.Marshal(u)
+	}
+	for i, w := range webhooks {
+		webhook, ok := w.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		field := fmt.Sprintf("%s %s webhook %v", u["kind"], name, webhook["name"])
+		if _, ok := webhook["admissionReviewVersions"]; !ok {
+			webhook["admissionReviewVersions"] = []interface{}{defaultReviewVersion}
+			r.fix("%s: set admissionReviewVersions to [%s], the v1beta1 default", field, defaultReviewVersion)
+		}
+		if _, ok := webhook["failurePolicy"]; !ok {
+			webhook["failurePolicy"] = v1beta1FailurePolicy
+			r.fix("%s: set failurePolicy to %s, the v1beta1 default", field, v1beta1FailurePolicy)
+		}
+		if _, ok := webhook["matchPolicy"]; !ok {
+			webhook["matchPolicy"] = v1beta1MatchPolicy
+			r.fix("%s: set matchPolicy to %s, the v1beta1 default", field, v1beta1MatchPolicy)
+		}
+		if _, ok := webhook["timeoutSeconds"]; !ok {
+			webhook["timeoutSeconds"] = v1beta1TimeoutSeconds
+			r.fix("%s: set timeoutSeconds to %d, the v1beta1 default", field, v1beta1TimeoutSeconds)
+		}
+		switch sideEffects, _ := webhook["sideEffects"].(string); sideEffects {
+		case "None", "NoneOnDryRun":
+		case "":
+			r.issue("%s: v1 requires sideEffects to be None or NoneOnDryRun; set it if the webhook "+
+				"has no side effects, or make it skip dry-run requests", field)
+		default:
+			r.issue("%s: sideEffects %s is not allowed in v1; make the webhook skip dry-run requests "+
+				"and set sideEffects to NoneOnDryRun", field, sideEffects)
+		}
+		webhooks[i] = webhook
+	}
+	if err := unstructured.SetNestedSlice(u, webhooks, "webhooks"); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(u)
+}
+
+var crdOptionsRe = regexp.MustCompile(`(?m)^(CRD_OPTIONS\s*\??=\s*)"?(crd[^"\n]*)"?[ \t]*$`)
+
+// Makefile updates the CRD_OPTIONS variable of a kubebuilder Makefile to generate v1 CRDs,
+// so 'make manifests' does not regenerate v1beta1 CRDs. trivialVersions and preserveUnknownFields
+// only apply to v1beta1 CRDs, and are removed.
+func Makefile(b []byte) (r Result) {
+	m := crdOptionsRe.FindSubmatchIndex(b)
+	if m == nil {
+		return r
+	}
+	value := string(b[m[4]:m[5]])
+	var opts []string
+	if i := strings.Index(value, ":"); i >= 0 {
+		opts = strings.Split(value[i+1:], ",")
+	}
+	kept := []string{"crdVersions=v1"}
+	for _, opt := range opts {
+		switch {
+		case opt == "", strings.HasPrefix(opt, "crdVersions="),
+			strings.HasPrefix(opt, "trivialVersions="), strings.HasPrefix(opt, "preserveUnknownFields="):
+			continue
+		}
+		kept = append(kept, opt)
+	}
+	updated := fmt.Sprintf("%s\"crd:%s\"", b[m[2]:m[3]], strings.Join(kept, ","))
+	if updated == string(b[m[0]:m[1]]) {
+		return r
+	}
+	r.Content = append(append(append([]byte{}, b[:m[0]]...), updated...), b[m[1]:]...)
+	r.fix("set CRD_OPTIONS to generate v1 CRDs with 'make manifests'")
+	return r
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const v1beta1CRD = `apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Memcached
+    plural: memcacheds
+  scope: Namespaced
+  subresources:
+    status: {}
+  validation:
+    openAPIV3Schema:
+      properties:
+        metadata:
+          properties:
+            labels:
+              type: object
+        spec:
+          properties:
+            size:
+              type: integer
+            config: {}
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+`
+
+const conversionPatch = `# The following patch enables conversion webhook for CRD
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  conversion:
+    strategy: Webhook
+    webhookClientConfig:
+      caBundle: Cg==
+      service:
+        namespace: system
+        name: webhook-service
+        path: /convert
+`
+
+const webhookConfig = `apiVersion: admissionregistration.k8s.io/v1beta1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: mutating-webhook-configuration
+webhooks:
+- name: mmemcached.kb.io
+  failurePolicy: Fail
+  clientConfig:
+    service:
+      name: webhook-service
+      namespace: system
+      path: /mutate-cache-example-com-v1alpha1-memcached
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: webhook-service
+`
+
+var _ = Describe("Manifests", func() {
+	It("converts CRDs to v1 and fixes inferable schema problems", func() {
+		r, err := Manifests([]byte(v1beta1CRD))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Changed()).To(BeTrue())
+
+		crd := apiextv1.CustomResourceDefinition{}
+		Expect(yaml.Unmarshal(r.Content, &crd)).To(Succeed())
+		Expect(crd.APIVersion).To(Equal("apiextensions.k8s.io/v1"))
+		Expect(crd.Spec.PreserveUnknownFields).To(BeFalse())
+		Expect(crd.Spec.Versions).To(HaveLen(1))
+		v := crd.Spec.Versions[0]
+		Expect(v.Subresources).NotTo(BeNil())
+		schema := v.Schema.OpenAPIV3Schema
+		Expect(schema.Type).To(Equal("object"))
+		Expect(schema.Properties["metadata"]).To(Equal(apiextv1.JSONSchemaProps{Type: "object"}))
+		Expect(schema.Properties["spec"].Type).To(Equal("object"))
+		Expect(string(r.Content)).NotTo(ContainSubstring("creationTimestamp"))
+
+		Expect(r.Fixes).To(ConsistOf(
+			"CRD memcacheds.cache.example.com version v1alpha1: set the root schema's type to object",
+			"CRD memcacheds.cache.example.com version v1alpha1: removed metadata fields other than name "+
+				"and generateName from the schema, which v1 CRDs do not allow",
+			"CRD memcacheds.cache.example.com version v1alpha1: set the type of spec to object",
+		))
+		Expect(r.Issues).To(HaveLen(2))
+		Expect(r.Issues[0]).To(ContainSubstring("v1 CRDs prune unknown fields"))
+		Expect(r.Issues[1]).To(Equal("CRD memcacheds.cache.example.com version v1alpha1: spec.config has no " +
+			"type; set one, or set x-kubernetes-preserve-unknown-fields: true for arbitrary content"))
+	})
+
+	It("moves the conversion webhook config of CRD patches", func() {
+		r, err := Manifests([]byte(conversionPatch))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Issues).To(BeEmpty())
+		Expect(string(r.Content)).To(Equal(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  conversion:
+    strategy: Webhook
+    webhook:
+      clientConfig:
+        caBundle: Cg==
+        service:
+          name: webhook-service
+          namespace: system
+          path: /convert
+      conversionReviewVersions:
+      - v1beta1
+`))
+	})
+
+	It("converts webhook configurations and keeps other manifests", func() {
+		r, err := Manifests([]byte(webhookConfig))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(r.Content)).To(ContainSubstring("- admissionReviewVersions:\n  - v1beta1\n"))
+		Expect(string(r.Content)).To(ContainSubstring("apiVersion: admissionregistration.k8s.io/v1\n"))
+		Expect(string(r.Content)).To(ContainSubstring("  failurePolicy: Fail\n"))
+		Expect(string(r.Content)).To(ContainSubstring("  matchPolicy: Exact\n"))
+		Expect(string(r.Content)).To(ContainSubstring("  timeoutSeconds: 30\n"))
+		Expect(string(r.Content)).To(HaveSuffix("---\napiVersion: v1\nkind: Service\nmetadata:\n  name: webhook-service\n"))
+		Expect(r.Fixes).To(HaveLen(3))
+		Expect(r.Issues).To(ConsistOf(ContainSubstring("v1 requires sideEffects to be None or NoneOnDryRun")))
+	})
+
+	It("does not change manifests without deprecated versions", func() {
+		r, err := Manifests([]byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: webhook-service\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Changed()).To(BeFalse())
+	})
+})
+
+var _ = Describe("Makefile", func() {
+	It("generates v1 CRDs", func() {
+		r := Makefile([]byte("# Produce CRDs that work back to Kubernetes 1.11 (no version conversion)\n" +
+			"CRD_OPTIONS ?= \"crd:trivialVersions=true\"\n\nall: manager\n"))
+		Expect(string(r.Content)).To(Equal("# Produce CRDs that work back to Kubernetes 1.11 (no version conversion)\n" +
+			"CRD_OPTIONS ?= \"crd:crdVersions=v1\"\n\nall: manager\n"))
+		Expect(r.Fixes).To(HaveLen(1))
+	})
+	It("keeps other options and is idempotent", func() {
+		r := Makefile([]byte("CRD_OPTIONS ?= \"crd:trivialVersions=true,maxDescLen=0\"\n"))
+		Expect(string(r.Content)).To(Equal("CRD_OPTIONS ?= \"crd:crdVersions=v1,maxDescLen=0\"\n"))
+		Expect(Makefile(r.Content).Changed()).To(BeFalse())
+	})
+})
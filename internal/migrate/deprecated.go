@@ -0,0 +1,333 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+// DeprecatedAPI is a Kubernetes API version of a kind that is deprecated, and removed in a later release.
+type DeprecatedAPI struct {
+	schema.GroupVersionKind
+	// DeprecatedIn and RemovedIn are the Kubernetes releases the API was deprecated and removed in.
+	DeprecatedIn, RemovedIn string
+	// Replacement is the API version to migrate to, if any.
+	Replacement string
+}
+
+// Statuses of a DeprecatedAPI in a Kubernetes release.
+const (
+	StatusSupported  = ""
+	StatusDeprecated = "deprecated"
+	StatusRemoved    = "removed"
+)
+
+// Status returns the status of a in Kubernetes release target.
+func (a DeprecatedAPI) Status(target *version.Version) string {
+	switch {
+	case target.AtLeast(version.MustParseGeneric(a.RemovedIn)):
+		return StatusRemoved
+	case target.AtLeast(version.MustParseGeneric(a.DeprecatedIn)):
+		return StatusDeprecated
+	}
+	return StatusSupported
+}
+
+func (a DeprecatedAPI) String() string {
+	msg := fmt.Sprintf("%s %s is deprecated in %s and removed in %s", a.GroupVersion(), a.Kind,
+		a.DeprecatedIn, a.RemovedIn)
+	if a.Replacement != "" {
+		msg += fmt.Sprintf(", use %s", a.Replacement)
+	}
+	return msg
+}
+
+// deprecatedAPIs are the API versions deprecated since the operator-framework supported Kubernetes 1.16.
+var deprecatedAPIs = func() (apis []DeprecatedAPI) {
+	add := func(gv, deprecatedIn, removedIn, replacement string, kinds ...string) {
+		parsed := schema.FromAPIVersionAndKind(gv, "")
+		for _, kind := range kinds {
+			apis = append(apis, DeprecatedAPI{parsed.GroupVersion().WithKind(kind), deprecatedIn, removedIn, replacement})
+		}
+	}
+	add("extensions/v1beta1", "v1.9", "v1.16", "apps/v1", "Deployment", "DaemonSet", "ReplicaSet")
+	add("extensions/v1beta1", "v1.9", "v1.16", "networking.k8s.io/v1", "NetworkPolicy")
+	add("extensions/v1beta1", "v1.11", "v1.16", "policy/v1beta1", "PodSecurityPolicy")
+	add("extensions/v1beta1", "v1.14", "v1.22", "networking.k8s.io/v1", "Ingress")
+	add("apps/v1beta1", "v1.9", "v1.16", "apps/v1", "Deployment", "StatefulSet", "ReplicaSet")
+	add("apps/v1beta2", "v1.9", "v1.16", "apps/v1", "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet")
+	add("apiextensions.k8s.io/v1beta1", "v1.16", "v1.22", "apiextensions.k8s.io/v1", "CustomResourceDefinition")
+	add("admissionregistration.k8s.io/v1beta1", "v1.16", "v1.22", "admissionregistration.k8s.io/v1",
+		"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration")
+	add("apiregistration.k8s.io/v1beta1", "v1.19", "v1.22", "apiregistration.k8s.io/v1", "APIService")
+	add("authentication.k8s.io/v1beta1", "v1.19", "v1.22", "authentication.k8s.io/v1", "TokenReview")
+	add("authorization.k8s.io/v1beta1", "v1.19", "v1.22", "authorization.k8s.io/v1", "SubjectAccessReview",
+		"LocalSubjectAccessReview", "SelfSubjectAccessReview", "SelfSubjectRulesReview")
+	add("certificates.k8s.io/v1beta1", "v1.19", "v1.22", "certificates.k8s.io/v1", "CertificateSigningRequest")
+	add("coordination.k8s.io/v1beta1", "v1.19", "v1.22", "coordination.k8s.io/v1", "Lease")
+	add("networking.k8s.io/v1beta1", "v1.19", "v1.22", "networking.k8s.io/v1", "Ingress", "IngressClass")
+	add("rbac.authorization.k8s.io/v1beta1", "v1.17", "v1.22", "rbac.authorization.k8s.io/v1",
+		"ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding")
+	add("scheduling.k8s.io/v1beta1", "v1.14", "v1.22", "scheduling.k8s.io/v1", "PriorityClass")
+	add("storage.k8s.io/v1beta1", "v1.19", "v1.22", "storage.k8s.io/v1",
+		"CSIDriver", "CSINode", "StorageClass", "VolumeAttachment")
+	add("batch/v1beta1", "v1.21", "v1.25", "batch/v1", "CronJob")
+	add("discovery.k8s.io/v1beta1", "v1.21", "v1.25", "discovery.k8s.io/v1", "EndpointSlice")
+	add("events.k8s.io/v1beta1", "v1.19", "v1.25", "events.k8s.io/v1", "Event")
+	add("autoscaling/v2beta1", "v1.22", "v1.25", "autoscaling/v2", "HorizontalPodAutoscaler")
+	add("policy/v1beta1", "v1.21", "v1.25", "policy/v1", "PodDisruptionBudget")
+	add("policy/v1beta1", "v1.21", "v1.25", "", "PodSecurityPolicy")
+	add("node.k8s.io/v1beta1", "v1.20", "v1.25", "node.k8s.io/v1", "RuntimeClass")
+	add("autoscaling/v2beta2", "v1.23", "v1.26", "autoscaling/v2", "HorizontalPodAutoscaler")
+	add("flowcontrol.apiserver.k8s.io/v1beta1", "v1.23", "v1.26", "flowcontrol.apiserver.k8s.io/v1",
+		"FlowSchema", "PriorityLevelConfiguration")
+	add("storage.k8s.io/v1beta1", "v1.24", "v1.27", "storage.k8s.io/v1", "CSIStorageCapacity")
+	add("flowcontrol.apiserver.k8s.io/v1beta2", "v1.26", "v1.29", "flowcontrol.apiserver.k8s.io/v1",
+		"FlowSchema", "PriorityLevelConfiguration")
+	add("flowcontrol.apiserver.k8s.io/v1beta3", "v1.29", "v1.32", "flowcontrol.apiserver.k8s.io/v1",
+		"FlowSchema", "PriorityLevelConfiguration")
+	return apis
+}()
+
+// LatestRemovalRelease is the latest Kubernetes release known to remove an API version.
+const LatestRemovalRelease = "v1.32"
+
+// LookupDeprecatedAPI returns the deprecated API of gvk, if it is deprecated.
+func LookupDeprecatedAPI(gvk schema.GroupVersionKind) (DeprecatedAPI, bool) {
+	for _, api := range deprecatedAPIs {
+		if api.GroupVersionKind == gvk {
+			return api, true
+		}
+	}
+	return DeprecatedAPI{}, false
+}
+
+// DeprecatedAPIUse is a use of a deprecated API in a project's source or manifests.
+type DeprecatedAPIUse struct {
+	Path string
+	Line int
+	DeprecatedAPI
+}
+
+func (u DeprecatedAPIUse) String() string {
+	return fmt.Sprintf("%s:%d: %s", u.Path, u.Line, u.DeprecatedAPI)
+}
+
+// ScanDirs returns the project directories ScanDeprecatedAPIs scans in projects of operatorType:
+// every directory for Go code, and the directories containing manifests for each project type.
+func ScanDirs(operatorType projutil.OperatorType) (goDirs, manifestDirs []string) {
+	manifestDirs = []string{"config", "bundle"}
+	switch operatorType {
+	case projutil.OperatorTypeGo:
+		return []string{"."}, manifestDirs
+	case projutil.OperatorTypeHelm:
+		return nil, append(manifestDirs, "helm-charts")
+	case projutil.OperatorTypeAnsible:
+		return nil, append(manifestDirs, "roles", "playbooks", "molecule")
+	}
+	return []string{"."}, []string{"."}
+}
+
+// skippedDirs are not scanned for Go code, since they contain tools and dependencies.
+var skippedDirs = map[string]bool{"vendor": true, "bin": true, "testbin": true}
+
+// ScanDeprecatedAPIs returns the uses of deprecated API types in Go files under goDirs, and of deprecated
+// API versions in YAML manifests under manifestDirs. Missing dirs are skipped.
+func ScanDeprecatedAPIs(goDirs, manifestDirs []string) (uses []DeprecatedAPIUse, err error) {
+	scan := func(dirs []string, exts, skip map[string]bool, f func(string, []byte) ([]DeprecatedAPIUse, error)) error {
+		for _, dir := range dirs {
+			err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if info.IsDir() {
+					if path != dir && (skip[info.Name()] || strings.HasPrefix(info.Name(), ".")) {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if !exts[filepath.Ext(path)] {
+					return nil
+				}
+				b, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				fileUses, err := f(path, b)
+				if err != nil {
+					return fmt.Errorf("error scanning %s: %v", path, err)
+				}
+				uses = append(uses, fileUses...)
+				return nil
+			})
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := scan(goDirs, map[string]bool{".go": true}, skippedDirs, scanGoFile); err != nil {
+		return nil, err
+	}
+	if err := scan(manifestDirs, map[string]bool{".yaml": true, ".yml": true}, nil, scanManifest); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(uses, func(i, j int) bool {
+		if uses[i].Path == uses[j].Path {
+			return uses[i].Line < uses[j].Line
+		}
+		return uses[i].Path < uses[j].Path
+	})
+	return uses, nil
+}
+
+// apiImportPathRegexp matches the import paths of Kubernetes API type packages.
+var apiImportPathRegexp = regexp.MustCompile(`^k8s\.io/(?:api/([a-z]+)|apiextensions-apiserver/pkg/apis/(apiextensions)|` +
+	`kube-aggregator/pkg/apis/(apiregistration))/(v[0-9a-z]+)$`)
+
+// importPathGroups are the API groups of API type packages whose name is not the group's first segment.
+var importPathGroups = map[string]string{
+	"apps":        "apps",
+	"autoscaling": "autoscaling",
+	"batch":       "batch",
+	"extensions":  "extensions",
+	"policy":      "policy",
+	"rbac":        "rbac.authorization.k8s.io",
+	"flowcontrol": "flowcontrol.apiserver.k8s.io",
+}
+
+// importPathGroupVersion returns the API group version of the API type package imported by path.
+func importPathGroupVersion(path string) (schema.GroupVersion, bool) {
+	m := apiImportPathRegexp.FindStringSubmatch(path)
+	if m == nil {
+		return schema.GroupVersion{}, false
+	}
+	pkg := m[1] + m[2] + m[3]
+	group, ok := importPathGroups[pkg]
+	if !ok {
+		group = pkg + ".k8s.io"
+	}
+	return schema.GroupVersion{Group: group, Version: m[4]}, true
+}
+
+// scanGoFile returns the uses of deprecated API types, and their lists, in a Go file.
+func scanGoFile(path string, b []byte) (uses []DeprecatedAPIUse, err error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, b, 0)
+	if err != nil {
+		return nil, err
+	}
+	imports := map[string]schema.GroupVersion{}
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		gv, ok := importPathGroupVersion(importPath)
+		if !ok {
+			continue
+		}
+		name := filepath.Base(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = gv
+	}
+	if len(imports) == 0 {
+		return nil, nil
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		gv, ok := imports[pkg.Name]
+		if !ok {
+			return true
+		}
+		if api, ok := LookupDeprecatedAPI(gv.WithKind(strings.TrimSuffix(sel.Sel.Name, "List"))); ok {
+			uses = append(uses, DeprecatedAPIUse{Path: path, Line: fset.Position(sel.Pos()).Line, DeprecatedAPI: api})
+		}
+		return true
+	})
+	return uses, nil
+}
+
+var (
+	apiVersionLineRegexp = regexp.MustCompile(`^((?:\s*-)?\s*)apiVersion:\s*["']?([^\s"'#]+)`)
+	kindLineRegexp       = regexp.MustCompile(`^((?:\s*-)?\s*)kind:\s*["']?([^\s"'#]+)`)
+)
+
+// scanManifest returns the uses of deprecated API versions in a YAML file, which may be templated, ex. Helm
+// chart templates, so it is scanned line by line. An apiVersion and kind at the same indentation
+// of a document are paired.
+func scanManifest(path string, b []byte) (uses []DeprecatedAPIUse, err error) {
+	type value struct {
+		v    string
+		line int
+	}
+	apiVersions, kinds := map[int]value{}, map[int]value{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.HasPrefix(text, "---") {
+			apiVersions, kinds = map[int]value{}, map[int]value{}
+			continue
+		}
+		var indent int
+		if m := apiVersionLineRegexp.FindStringSubmatch(text); m != nil {
+			indent = len(m[1])
+			apiVersions[indent] = value{m[2], line}
+		} else if m := kindLineRegexp.FindStringSubmatch(text); m != nil {
+			indent = len(m[1])
+			kinds[indent] = value{m[2], line}
+		} else {
+			continue
+		}
+		apiVersion, hasAPIVersion := apiVersions[indent]
+		kind, hasKind := kinds[indent]
+		if !hasAPIVersion || !hasKind {
+			continue
+		}
+		delete(apiVersions, indent)
+		delete(kinds, indent)
+		if api, ok := LookupDeprecatedAPI(schema.FromAPIVersionAndKind(apiVersion.v, kind.v)); ok {
+			uses = append(uses, DeprecatedAPIUse{Path: path, Line: apiVersion.line, DeprecatedAPI: api})
+		}
+	}
+	return uses, scanner.Err()
+}
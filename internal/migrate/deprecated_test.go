@@ -0,0 +1,132 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+const controllerGo = `package controllers
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1beta1"
+	"k8s.io/api/networking/v1beta1"
+)
+
+func (r *Reconciler) objects() {
+	_ = &appsv1.Deployment{}
+	_ = &batch.CronJob{}
+	_ = &batch.JobTemplateSpec{}
+	_ = &v1beta1.IngressList{}
+}
+`
+
+const chartTemplate = `{{- if .Values.ingress.enabled }}
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: {{ .Release.Name }}
+---
+apiVersion: {{ .Values.apiVersion }}
+kind: Ingress
+{{- end }}
+`
+
+const roleTask = `- name: Create CRD
+  k8s:
+    definition:
+      kind: CustomResourceDefinition
+      apiVersion: "apiextensions.k8s.io/v1beta1"
+      metadata:
+        ownerReferences:
+        - apiVersion: apps/v1
+          kind: Deployment
+`
+
+var _ = Describe("Deprecated APIs", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "deprecated-apis")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	write := func(path, content string) string {
+		path = filepath.Join(dir, path)
+		ExpectWithOffset(1, os.MkdirAll(filepath.Dir(path), projutil.DirMode)).To(Succeed())
+		ExpectWithOffset(1, ioutil.WriteFile(path, []byte(content), projutil.FileMode)).To(Succeed())
+		return path
+	}
+	apiUse := func(path string, line int, gv, kind string) DeprecatedAPIUse {
+		api, ok := LookupDeprecatedAPI(schema.FromAPIVersionAndKind(gv, kind))
+		ExpectWithOffset(1, ok).To(BeTrue())
+		return DeprecatedAPIUse{Path: path, Line: line, DeprecatedAPI: api}
+	}
+
+	It("reports whether an API is deprecated or removed in a release", func() {
+		api, ok := LookupDeprecatedAPI(schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"})
+		Expect(ok).To(BeTrue())
+		Expect(api.Status(version.MustParseGeneric("v1.20"))).To(Equal(StatusSupported))
+		Expect(api.Status(version.MustParseGeneric("v1.21"))).To(Equal(StatusDeprecated))
+		Expect(api.Status(version.MustParseGeneric("1.25.3"))).To(Equal(StatusRemoved))
+
+		_, ok = LookupDeprecatedAPI(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"})
+		Expect(ok).To(BeFalse())
+	})
+	It("finds deprecated API types used by Go code", func() {
+		path := write(filepath.Join("controllers", "controller.go"), controllerGo)
+		write(filepath.Join("vendor", "k8s.io", "controller.go"), controllerGo)
+		uses, err := ScanDeprecatedAPIs([]string{dir}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(uses).To(Equal([]DeprecatedAPIUse{
+			apiUse(path, 11, "batch/v1beta1", "CronJob"),
+			apiUse(path, 13, "networking.k8s.io/v1beta1", "Ingress"),
+		}))
+	})
+	It("finds deprecated API versions used by manifests, templates, and Ansible tasks", func() {
+		crd := write(filepath.Join("config", "crd", "crd.yaml"), v1beta1CRD)
+		chart := write(filepath.Join("helm-charts", "app", "templates", "ingress.yaml"), chartTemplate)
+		task := write(filepath.Join("roles", "app", "tasks", "main.yml"), roleTask)
+		uses, err := ScanDeprecatedAPIs(nil, []string{filepath.Join(dir, "config"), filepath.Join(dir, "helm-charts"),
+			filepath.Join(dir, "roles"), filepath.Join(dir, "missing")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(uses).To(Equal([]DeprecatedAPIUse{
+			apiUse(crd, 1, "apiextensions.k8s.io/v1beta1", "CustomResourceDefinition"),
+			apiUse(chart, 2, "networking.k8s.io/v1beta1", "Ingress"),
+			apiUse(task, 5, "apiextensions.k8s.io/v1beta1", "CustomResourceDefinition"),
+		}))
+	})
+	It("scans Go code only in Go projects", func() {
+		goDirs, manifestDirs := ScanDirs(projutil.OperatorTypeHelm)
+		Expect(goDirs).To(BeEmpty())
+		Expect(manifestDirs).To(ContainElement("helm-charts"))
+		goDirs, _ = ScanDirs(projutil.OperatorTypeGo)
+		Expect(goDirs).To(Equal([]string{"."}))
+	})
+})
@@ -42,10 +42,22 @@ import (
 	deploymentutil "k8s.io/kubectl/pkg/util/deployment"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
 )
 
 var ErrOLMNotInstalled = errors.New("no existing installation found")
 
+// ErrCSVFailed is returned by CSV waits when the CSV reaches the Failed phase.
+type ErrCSVFailed struct {
+	Reason  olmapiv1alpha1.ConditionReason
+	Message string
+}
+
+func (e ErrCSVFailed) Error() string {
+	return fmt.Sprintf("csv failed: reason: %q, message: %q", e.Reason, e.Message)
+}
+
 var Scheme = scheme.Scheme
 
 func init() {
@@ -56,6 +68,10 @@ func init() {
 
 type Client struct {
 	KubeClient client.Client
+	// Notifier, if set, notifies waits of changes to the objects they wait on, so waits do not poll.
+	Notifier *Notifier
+	// CSVHealth is the criterion a CSV must meet for WaitForCSV to return, CSVHealthPhase by default.
+	CSVHealth CSVHealth
 }
 
 func NewClientForConfig(cfg *rest.Config) (*Client, error) {
@@ -80,18 +96,17 @@ func NewClientForConfig(cfg *rest.Config) (*Client, error) {
 
 func (c Client) DoCreate(ctx context.Context, objs ...runtime.Object) error {
 	for _, obj := range objs {
-		a, err := meta.Accessor(obj)
-		if err != nil {
+		if _, err := meta.Accessor(obj); err != nil {
 			return err
 		}
-		kind := obj.GetObjectKind().GroupVersionKind().Kind
-		log.Infof("  Creating %s %q", kind, getName(a.GetNamespace(), a.GetName()))
-		err = c.KubeClient.Create(ctx, obj)
+		logger := logutil.WithObject(obj)
+		logger.Info("Creating resource")
+		err := c.KubeClient.Create(ctx, obj)
 		if err != nil {
 			if !apierrors.IsAlreadyExists(err) {
 				return err
 			}
-			log.Infof("    %s %q already exists", kind, getName(a.GetNamespace(), a.GetName()))
+			logger.Info("Resource already exists")
 		}
 	}
 	return nil
@@ -99,18 +114,17 @@ func (c Client) DoCreate(ctx context.Context, objs ...runtime.Object) error {
 
 func (c Client) DoDelete(ctx context.Context, objs ...runtime.Object) error {
 	for _, obj := range objs {
-		a, err := meta.Accessor(obj)
-		if err != nil {
+		if _, err := meta.Accessor(obj); err != nil {
 			return err
 		}
-		kind := obj.GetObjectKind().GroupVersionKind().Kind
-		log.Infof("  Deleting %s %q", kind, getName(a.GetNamespace(), a.GetName()))
-		err = c.KubeClient.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		logger := logutil.WithObject(obj)
+		logger.Info("Deleting resource")
+		err := c.KubeClient.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
 		if err != nil {
 			if !apierrors.IsNotFound(err) {
 				return err
 			}
-			log.Infof("    %s %q does not exist", kind, getName(a.GetNamespace(), a.GetName()))
+			logger.Info("Resource does not exist")
 		}
 		key, err := client.ObjectKeyFromObject(obj)
 		if err != nil {
@@ -131,18 +145,12 @@ func (c Client) DoDelete(ctx context.Context, objs ...runtime.Object) error {
 	return nil
 }
 
-func getName(namespace, name string) string {
-	if namespace != "" {
-		name = fmt.Sprintf("%s/%s", namespace, name)
-	}
-	return name
-}
-
 func (c Client) DoRolloutWait(ctx context.Context, key types.NamespacedName) error {
 	onceReplicasUpdated := sync.Once{}
 	oncePendingTermination := sync.Once{}
 	onceNotAvailable := sync.Once{}
 	onceSpecUpdate := sync.Once{}
+	logger := logutil.WithResource("Deployment", key)
 
 	rolloutComplete := func() (bool, error) {
 		deployment := appsv1.Deployment{}
@@ -157,32 +165,30 @@ func (c Client) DoRolloutWait(ctx context.Context, key types.NamespacedName) err
 			}
 			if deployment.Spec.Replicas != nil && deployment.Status.UpdatedReplicas < *deployment.Spec.Replicas {
 				onceReplicasUpdated.Do(func() {
-					log.Printf(
-						"  Waiting for Deployment %q to rollout: %d out of %d new replicas have been updated",
-						key, deployment.Status.UpdatedReplicas, *deployment.Spec.Replicas)
+					logger.Infof("Waiting for Deployment to rollout: %d out of %d new replicas have been updated",
+						deployment.Status.UpdatedReplicas, *deployment.Spec.Replicas)
 				})
 				return false, nil
 			}
 			if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
 				oncePendingTermination.Do(func() {
-					log.Printf("  Waiting for Deployment %q to rollout: %d old replicas are pending termination",
-						key, deployment.Status.Replicas-deployment.Status.UpdatedReplicas)
+					logger.Infof("Waiting for Deployment to rollout: %d old replicas are pending termination",
+						deployment.Status.Replicas-deployment.Status.UpdatedReplicas)
 				})
 				return false, nil
 			}
 			if deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas {
 				onceNotAvailable.Do(func() {
-					log.Printf("  Waiting for Deployment %q to rollout: %d of %d updated replicas are available",
-						key, deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)
+					logger.Infof("Waiting for Deployment to rollout: %d of %d updated replicas are available",
+						deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)
 				})
 				return false, nil
 			}
-			log.Printf("  Deployment %q successfully rolled out", key)
+			logger.Info("Deployment successfully rolled out")
 			return true, nil
 		}
 		onceSpecUpdate.Do(func() {
-			log.Printf("Waiting for Deployment %q to rollout: waiting for deployment spec update to be observed",
-				key)
+			logger.Info("Waiting for Deployment to rollout: waiting for deployment spec update to be observed")
 		})
 		return false, nil
 	}
@@ -190,11 +196,93 @@ func (c Client) DoRolloutWait(ctx context.Context, key types.NamespacedName) err
 }
 
 func (c Client) DoCSVWait(ctx context.Context, key types.NamespacedName) error {
+	_, err := c.WaitForCSV(ctx, key)
+	return err
+}
+
+// WaitForCSV waits for the CSV named key.Name to meet c.CSVHealth, and returns the key of the CSV
+// waited for. If the CSV in key.Namespace is a copy, or it is only found in another namespace, ex. because
+// the operator was installed into the namespace of an OperatorGroup not targeting key.Namespace,
+// the original CSV is waited for instead.
+func (c Client) WaitForCSV(ctx context.Context, key types.NamespacedName) (types.NamespacedName, error) {
+	once := sync.Once{}
+	resolved := key
+	err := WaitForChange(ctx, c.Notifier, key.Namespace, time.Second, func() (bool, error) {
+		var found bool
+		var err error
+		if resolved, found, err = c.locateCSV(ctx, key); err != nil || found {
+			return found, err
+		}
+		once.Do(func() {
+			logutil.WithResource(olmapiv1alpha1.ClusterServiceVersionKind, key).
+				Info("Waiting for ClusterServiceVersion to appear")
+		})
+		return false, nil
+	}, &olmapiv1alpha1.ClusterServiceVersion{})
+	if err != nil {
+		return key, err
+	}
+	if resolved != key {
+		logutil.WithResource(olmapiv1alpha1.ClusterServiceVersionKind, resolved).
+			Infof("Found ClusterServiceVersion in namespace %q instead of %q", resolved.Namespace, key.Namespace)
+	}
+	return resolved, c.waitForCSVPhase(ctx, resolved)
+}
+
+// locateCSV returns the key of the original CSV named key.Name, and whether it was found.
+// The CSV in key.Namespace is preferred, unless it was copied from another namespace.
+// Otherwise CSVs in all namespaces are searched, which is skipped if listing them is forbidden.
+func (c Client) locateCSV(ctx context.Context, key types.NamespacedName) (types.NamespacedName, bool, error) {
+	csv := olmapiv1alpha1.ClusterServiceVersion{}
+	err := c.KubeClient.Get(ctx, key, &csv)
+	if err == nil {
+		return originalCSVKey(csv), true, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return key, false, err
+	}
+
+	csvs := olmapiv1alpha1.ClusterServiceVersionList{}
+	if err := c.KubeClient.List(ctx, &csvs); err != nil {
+		if apierrors.IsForbidden(err) {
+			return key, false, nil
+		}
+		return key, false, err
+	}
+	var copied *olmapiv1alpha1.ClusterServiceVersion
+	for i, item := range csvs.Items {
+		if item.GetName() != key.Name {
+			continue
+		}
+		if !item.IsCopied() {
+			return types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()}, true, nil
+		}
+		copied = &csvs.Items[i]
+	}
+	if copied != nil {
+		return originalCSVKey(*copied), true, nil
+	}
+	return key, false, nil
+}
+
+// originalCSVKey returns the key of the CSV csv was copied from by OLM, or csv's key if it is not a copy.
+func originalCSVKey(csv olmapiv1alpha1.ClusterServiceVersion) types.NamespacedName {
+	key := types.NamespacedName{Namespace: csv.GetNamespace(), Name: csv.GetName()}
+	if ns, ok := csv.GetLabels()[olmapiv1alpha1.CopiedLabelKey]; ok && ns != "" {
+		key.Namespace = ns
+	}
+	return key
+}
+
+// waitForCSVPhase waits for the CSV key to meet c.CSVHealth.
+func (c Client) waitForCSVPhase(ctx context.Context, key types.NamespacedName) error {
 	var (
 		curPhase olmapiv1alpha1.ClusterServiceVersionPhase
 		newPhase olmapiv1alpha1.ClusterServiceVersionPhase
+		unready  string
 	)
 	once := sync.Once{}
+	logger := logutil.WithResource(olmapiv1alpha1.ClusterServiceVersionKind, key)
 
 	csv := olmapiv1alpha1.ClusterServiceVersion{}
 	csvPhaseSucceeded := func() (bool, error) {
@@ -202,7 +290,7 @@ func (c Client) DoCSVWait(ctx context.Context, key types.NamespacedName) error {
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				once.Do(func() {
-					log.Printf("  Waiting for ClusterServiceVersion %q to appear", key)
+					logger.Info("Waiting for ClusterServiceVersion to appear")
 				})
 				return false, nil
 			}
@@ -211,20 +299,39 @@ func (c Client) DoCSVWait(ctx context.Context, key types.NamespacedName) error {
 		newPhase = csv.Status.Phase
 		if newPhase != curPhase {
 			curPhase = newPhase
-			log.Printf("  Found ClusterServiceVersion %q phase: %s", key, curPhase)
+			logger.WithField(logutil.PhaseKey, curPhase).Info("Found ClusterServiceVersion phase")
 		}
 
-		switch curPhase {
-		case olmapiv1alpha1.CSVPhaseFailed:
-			return false, fmt.Errorf("csv failed: reason: %q, message: %q", csv.Status.Reason, csv.Status.Message)
-		case olmapiv1alpha1.CSVPhaseSucceeded:
-			return true, nil
-		default:
+		if curPhase == olmapiv1alpha1.CSVPhaseFailed {
+			return false, ErrCSVFailed{Reason: csv.Status.Reason, Message: csv.Status.Message}
+		}
+		switch c.CSVHealth {
+		case CSVHealthConditions:
+			return installSucceeded(csv), nil
+		case CSVHealthDeploymentsReady:
+			if curPhase != olmapiv1alpha1.CSVPhaseSucceeded {
+				return false, nil
+			}
+			name, reason, err := c.unreadyDeployment(ctx, key.Namespace, csv)
+			if err != nil || name == "" {
+				return err == nil, err
+			}
+			if name != unready {
+				unready = name
+				logger.Infof("Waiting for Deployment %q to be ready: %s", name, reason)
+			}
 			return false, nil
+		default:
+			return curPhase == olmapiv1alpha1.CSVPhaseSucceeded, nil
 		}
 	}
 
-	err := wait.PollImmediateUntil(time.Second, csvPhaseSucceeded, ctx.Done())
+	// Deployments becoming ready do not change the CSV, so are watched too.
+	watched := []runtime.Object{&olmapiv1alpha1.ClusterServiceVersion{}}
+	if c.CSVHealth == CSVHealthDeploymentsReady {
+		watched = append(watched, &appsv1.Deployment{})
+	}
+	err := WaitForChange(ctx, c.Notifier, key.Namespace, time.Second, csvPhaseSucceeded, watched...)
 	if err != nil && errors.Is(err, context.DeadlineExceeded) {
 		if depCheckErr := c.printDeploymentErrors(ctx, key, csv); depCheckErr != nil {
 			return fmt.Errorf("error printing operator resource errors: %v %v", err, depCheckErr)
@@ -248,12 +355,12 @@ func (c Client) printDeploymentErrors(ctx context.Context, key types.NamespacedN
 		}
 		depSelectors := ds.Spec.Selector
 		if err := c.KubeClient.Get(ctx, depKey, dep); err != nil {
-			log.Printf("error getting operator deployment %q: %v", ds.Name, err)
+			logutil.WithResource("Deployment", depKey).Errorf("Error getting operator deployment: %v", err)
 			continue
 		}
 		for _, s := range dep.Status.Conditions {
 			if s.Type == appsv1.DeploymentAvailable && s.Status == corev1.ConditionFalse {
-				log.Printf("operator deployment %q not available: %s", ds.Name, s.Reason)
+				logutil.WithResource("Deployment", depKey).Errorf("Operator deployment not available: %s", s.Reason)
 				if err := c.printPodErrors(ctx, depSelectors, key); err != nil {
 					return err
 				}
@@ -289,7 +396,7 @@ func (c Client) printPodErrors(ctx context.Context, depSelectors *metav1.LabelSe
 		}
 	}
 	if len(podErrors) > 0 {
-		log.Printf("pod errors: %v\n", podErrors)
+		log.Errorf("Pod errors: %v", podErrors)
 	}
 	return nil
 }
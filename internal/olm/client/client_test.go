@@ -23,6 +23,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -193,4 +194,67 @@ var _ = Describe("Client", func() {
 			})
 		})
 	})
+
+	Describe("locateCSV", func() {
+		var (
+			sch *runtime.Scheme
+			key types.NamespacedName
+		)
+
+		newCSV := func(namespace string, labels map[string]string) *olmapiv1alpha1.ClusterServiceVersion {
+			return &olmapiv1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "memcached-operator.v0.0.1",
+					Namespace: namespace,
+					Labels:    labels,
+				},
+			}
+		}
+		copiedFrom := func(namespace string) map[string]string {
+			return map[string]string{olmapiv1alpha1.CopiedLabelKey: namespace}
+		}
+
+		BeforeEach(func() {
+			sch = runtime.NewScheme()
+			Expect(olmapiv1alpha1.AddToScheme(sch)).To(Succeed())
+			key = types.NamespacedName{Namespace: "default", Name: "memcached-operator.v0.0.1"}
+		})
+
+		It("should return the key of a CSV in the key's namespace", func() {
+			olmclient := Client{KubeClient: fake.NewFakeClientWithScheme(sch, newCSV("default", nil))}
+			nn, found, err := olmclient.locateCSV(context.TODO(), key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(nn).To(Equal(key))
+		})
+		It("should return the key of the original CSV if the CSV in the key's namespace is a copy", func() {
+			olmclient := Client{KubeClient: fake.NewFakeClientWithScheme(sch,
+				newCSV("default", copiedFrom("operators")), newCSV("operators", nil))}
+			nn, found, err := olmclient.locateCSV(context.TODO(), key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(nn).To(Equal(types.NamespacedName{Namespace: "operators", Name: key.Name}))
+		})
+		It("should find a CSV in another namespace", func() {
+			olmclient := Client{KubeClient: fake.NewFakeClientWithScheme(sch,
+				newCSV("other", copiedFrom("operators")), newCSV("operators", nil))}
+			nn, found, err := olmclient.locateCSV(context.TODO(), key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(nn).To(Equal(types.NamespacedName{Namespace: "operators", Name: key.Name}))
+		})
+		It("should find the original namespace of a copied CSV in another namespace", func() {
+			olmclient := Client{KubeClient: fake.NewFakeClientWithScheme(sch, newCSV("other", copiedFrom("operators")))}
+			nn, found, err := olmclient.locateCSV(context.TODO(), key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(nn).To(Equal(types.NamespacedName{Namespace: "operators", Name: key.Name}))
+		})
+		It("should not find a CSV that does not exist", func() {
+			olmclient := Client{KubeClient: fake.NewFakeClientWithScheme(sch)}
+			_, found, err := olmclient.locateCSV(context.TODO(), key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+	})
 })
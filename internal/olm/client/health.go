@@ -0,0 +1,139 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CSVHealth is the criterion a CSV must meet to be considered installed. It implements pflag.Value.
+type CSVHealth string
+
+const (
+	// CSVHealthPhase considers a CSV installed once it is in the Succeeded phase.
+	CSVHealthPhase CSVHealth = "phase"
+	// CSVHealthConditions considers a CSV installed once it has reached the Succeeded phase
+	// with reason InstallSucceeded, even if it has since returned to another phase, ex. because
+	// the operator briefly flaps between Installing and Succeeded.
+	CSVHealthConditions CSVHealth = "conditions"
+	// CSVHealthDeploymentsReady considers a CSV installed once it is in the Succeeded phase
+	// and all of its deployments have rolled out with every replica available.
+	CSVHealthDeploymentsReady CSVHealth = "deployments-ready"
+)
+
+// CSVHealths are all valid CSVHealth values.
+var CSVHealths = []CSVHealth{CSVHealthPhase, CSVHealthConditions, CSVHealthDeploymentsReady}
+
+func (h CSVHealth) String() string {
+	if h == "" {
+		return string(CSVHealthPhase)
+	}
+	return string(h)
+}
+
+func (h *CSVHealth) Set(s string) error {
+	for _, health := range CSVHealths {
+		if CSVHealth(s) == health {
+			*h = health
+			return nil
+		}
+	}
+	names := make([]string, len(CSVHealths))
+	for i, health := range CSVHealths {
+		names[i] = string(health)
+	}
+	return fmt.Errorf("unknown CSV health %q, must be one of [%s]", s, strings.Join(names, " "))
+}
+
+func (CSVHealth) Type() string {
+	return "string"
+}
+
+// Description returns a description of the criterion for logs.
+func (h CSVHealth) Description() string {
+	switch h {
+	case CSVHealthConditions:
+		return "reach 'Succeeded' phase with reason 'InstallSucceeded'"
+	case CSVHealthDeploymentsReady:
+		return "reach 'Succeeded' phase with all deployments ready"
+	default:
+		return "reach 'Succeeded' phase"
+	}
+}
+
+// installSucceeded returns whether csv has reached the Succeeded phase with reason InstallSucceeded,
+// whether or not it is still in that phase.
+func installSucceeded(csv olmapiv1alpha1.ClusterServiceVersion) bool {
+	if csv.Status.Phase == olmapiv1alpha1.CSVPhaseSucceeded &&
+		csv.Status.Reason == olmapiv1alpha1.CSVReasonInstallSuccessful {
+		return true
+	}
+	for _, cond := range csv.Status.Conditions {
+		if cond.Phase == olmapiv1alpha1.CSVPhaseSucceeded && cond.Reason == olmapiv1alpha1.CSVReasonInstallSuccessful {
+			return true
+		}
+	}
+	return false
+}
+
+// unreadyDeployment returns the name of the first of csv's deployments that is not ready, and why,
+// or an empty name if all are ready.
+func (c Client) unreadyDeployment(ctx context.Context, namespace string,
+	csv olmapiv1alpha1.ClusterServiceVersion) (string, string, error) {
+
+	for _, spec := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		dep := appsv1.Deployment{}
+		err := c.KubeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.Name}, &dep)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return spec.Name, "not found", nil
+			}
+			return "", "", err
+		}
+		if reason := deploymentNotReady(dep); reason != "" {
+			return spec.Name, reason, nil
+		}
+	}
+	return "", "", nil
+}
+
+// deploymentNotReady returns why dep has not rolled out with all replicas available,
+// or an empty string if it has.
+func deploymentNotReady(dep appsv1.Deployment) string {
+	if dep.Generation > dep.Status.ObservedGeneration {
+		return "spec update not observed"
+	}
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	if dep.Status.UpdatedReplicas < replicas {
+		return fmt.Sprintf("%d out of %d new replicas updated", dep.Status.UpdatedReplicas, replicas)
+	}
+	if dep.Status.Replicas > dep.Status.UpdatedReplicas {
+		return fmt.Sprintf("%d old replicas pending termination", dep.Status.Replicas-dep.Status.UpdatedReplicas)
+	}
+	if dep.Status.AvailableReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas available", dep.Status.AvailableReplicas, replicas)
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("CSVHealth", func() {
+	Describe("Set", func() {
+		It("should accept known criteria", func() {
+			var h CSVHealth
+			Expect(h.String()).To(Equal("phase"))
+			Expect(h.Set("deployments-ready")).To(Succeed())
+			Expect(h).To(Equal(CSVHealthDeploymentsReady))
+		})
+		It("should reject unknown criteria", func() {
+			var h CSVHealth
+			Expect(h.Set("ready")).To(MatchError(`unknown CSV health "ready", must be one of [phase conditions deployments-ready]`))
+		})
+	})
+
+	Describe("waitForCSVPhase", func() {
+		var (
+			sch *runtime.Scheme
+			key types.NamespacedName
+			csv *olmapiv1alpha1.ClusterServiceVersion
+			dep *appsv1.Deployment
+		)
+
+		waitFor := func(health CSVHealth, objs ...runtime.Object) error {
+			ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+			defer cancel()
+			c := Client{KubeClient: fake.NewFakeClientWithScheme(sch, objs...), CSVHealth: health}
+			return c.waitForCSVPhase(ctx, key)
+		}
+
+		BeforeEach(func() {
+			sch = runtime.NewScheme()
+			Expect(olmapiv1alpha1.AddToScheme(sch)).To(Succeed())
+			Expect(appsv1.AddToScheme(sch)).To(Succeed())
+			key = types.NamespacedName{Namespace: "default", Name: "memcached-operator.v0.0.1"}
+			csv = &olmapiv1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: olmapiv1alpha1.ClusterServiceVersionSpec{
+					InstallStrategy: olmapiv1alpha1.NamedInstallStrategy{
+						StrategySpec: olmapiv1alpha1.StrategyDetailsDeployment{
+							DeploymentSpecs: []olmapiv1alpha1.StrategyDeploymentSpec{{Name: "memcached-operator"}},
+						},
+					},
+				},
+				Status: olmapiv1alpha1.ClusterServiceVersionStatus{
+					Phase:  olmapiv1alpha1.CSVPhaseSucceeded,
+					Reason: olmapiv1alpha1.CSVReasonInstallSuccessful,
+				},
+			}
+			replicas := int32(2)
+			dep = &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "memcached-operator", Namespace: key.Namespace},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{
+					Replicas:          2,
+					UpdatedReplicas:   2,
+					AvailableReplicas: 2,
+				},
+			}
+		})
+
+		It("should wait for the Succeeded phase by default", func() {
+			Expect(waitFor("", csv)).To(Succeed())
+			csv.Status.Phase = olmapiv1alpha1.CSVPhaseInstalling
+			Expect(waitFor(CSVHealthPhase, csv)).To(MatchError(wait.ErrWaitTimeout))
+		})
+		It("should fail if the CSV failed", func() {
+			csv.Status.Phase = olmapiv1alpha1.CSVPhaseFailed
+			csv.Status.Reason = olmapiv1alpha1.CSVReasonComponentFailed
+			err := waitFor(CSVHealthConditions, csv)
+			Expect(err).To(MatchError(ContainSubstring("csv failed")))
+			failed := ErrCSVFailed{}
+			Expect(errors.As(err, &failed)).To(BeTrue())
+			Expect(failed.Reason).To(Equal(olmapiv1alpha1.CSVReasonComponentFailed))
+		})
+		It("should accept a CSV that succeeded and returned to Installing with conditions", func() {
+			csv.Status.Conditions = []olmapiv1alpha1.ClusterServiceVersionCondition{
+				{Phase: olmapiv1alpha1.CSVPhaseSucceeded, Reason: olmapiv1alpha1.CSVReasonInstallSuccessful},
+			}
+			csv.Status.Phase = olmapiv1alpha1.CSVPhaseInstalling
+			csv.Status.Reason = olmapiv1alpha1.CSVReasonWaiting
+			Expect(waitFor(CSVHealthConditions, csv)).To(Succeed())
+			Expect(waitFor(CSVHealthPhase, csv)).To(MatchError(wait.ErrWaitTimeout))
+		})
+		It("should not accept a CSV that never succeeded with conditions", func() {
+			csv.Status.Phase = olmapiv1alpha1.CSVPhaseInstalling
+			csv.Status.Reason = olmapiv1alpha1.CSVReasonWaiting
+			Expect(waitFor(CSVHealthConditions, csv)).To(MatchError(wait.ErrWaitTimeout))
+		})
+		It("should wait for deployments to be ready with deployments-ready", func() {
+			Expect(waitFor(CSVHealthDeploymentsReady, csv, dep)).To(Succeed())
+			Expect(waitFor(CSVHealthDeploymentsReady, csv)).To(MatchError(wait.ErrWaitTimeout))
+			dep.Status.AvailableReplicas = 1
+			Expect(waitFor(CSVHealthDeploymentsReady, csv, dep)).To(MatchError(wait.ErrWaitTimeout))
+		})
+	})
+
+	Describe("deploymentNotReady", func() {
+		var dep appsv1.Deployment
+
+		BeforeEach(func() {
+			dep = appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           1,
+					UpdatedReplicas:    1,
+					AvailableReplicas:  1,
+				},
+			}
+		})
+
+		It("should be ready once all replicas are updated and available", func() {
+			Expect(deploymentNotReady(dep)).To(BeEmpty())
+		})
+		It("should not be ready until its spec is observed", func() {
+			dep.Status.ObservedGeneration = 1
+			Expect(deploymentNotReady(dep)).To(Equal("spec update not observed"))
+		})
+		It("should not be ready while old replicas are terminating", func() {
+			dep.Status.Replicas = 2
+			Expect(deploymentNotReady(dep)).To(Equal("1 old replicas pending termination"))
+		})
+		It("should not be ready while replicas are unavailable", func() {
+			dep.Status.AvailableReplicas = 0
+			Expect(deploymentNotReady(dep)).To(Equal("0 of 1 replicas available"))
+		})
+	})
+})
@@ -0,0 +1,174 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+const (
+	// watchResyncInterval is how often a watched condition is checked without any events,
+	// in case an event was missed.
+	watchResyncInterval = 10 * time.Second
+	// informerSyncTimeout is how long to wait for an informer to sync before only polling.
+	informerSyncTimeout = 10 * time.Second
+)
+
+// Notifier notifies waits of changes to objects, from the informers of the namespace each wait is in.
+// Each informer is registered a single event handler, which notifies the waits subscribed to it.
+type Notifier struct {
+	// newInformers returns the informers of a namespace, which it starts.
+	newInformers func(namespace string) (cache.Informers, error)
+
+	mu          sync.Mutex
+	informers   map[string]cache.Informers
+	subscribers map[cache.Informer]map[chan<- struct{}]struct{}
+}
+
+// NewNotifier returns a Notifier using informers for objects in namespace. Informers of other namespaces
+// are created with newInformers the first time a wait needs them, or waits on them poll if it is nil.
+func NewNotifier(namespace string, informers cache.Informers,
+	newInformers func(namespace string) (cache.Informers, error)) *Notifier {
+	return &Notifier{
+		newInformers: newInformers,
+		informers:    map[string]cache.Informers{namespace: informers},
+		subscribers:  map[cache.Informer]map[chan<- struct{}]struct{}{},
+	}
+}
+
+func (n *Notifier) informersFor(namespace string) (cache.Informers, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if informers, ok := n.informers[namespace]; ok {
+		return informers, nil
+	}
+	if n.newInformers == nil {
+		return nil, errors.New("no informers for namespace " + namespace)
+	}
+	informers, err := n.newInformers(namespace)
+	if err != nil {
+		return nil, err
+	}
+	n.informers[namespace] = informers
+	return informers, nil
+}
+
+// subscribe sends to changed whenever an object of obj's type in namespace is added, updated, or deleted,
+// until the returned func is called. Sends do not block, so changed should be buffered.
+func (n *Notifier) subscribe(ctx context.Context, namespace string, obj runtime.Object,
+	changed chan<- struct{}) (func(), error) {
+	informers, err := n.informersFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	informer, err := informers.GetInformer(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	subs, ok := n.subscribers[informer]
+	if !ok {
+		subs = map[chan<- struct{}]struct{}{}
+		n.subscribers[informer] = subs
+		notify := func() {
+			n.mu.Lock()
+			defer n.mu.Unlock()
+			for ch := range subs {
+				select {
+				case ch <- struct{}{}:
+				default:
+				}
+			}
+		}
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { notify() },
+			UpdateFunc: func(interface{}, interface{}) { notify() },
+			DeleteFunc: func(interface{}) { notify() },
+		})
+	}
+	subs[changed] = struct{}{}
+	return func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		delete(subs, changed)
+	}, nil
+}
+
+// WaitForChange calls cond until it returns true or an error, or ctx is done, in which case
+// wait.ErrWaitTimeout is returned like wait.PollImmediateUntil. cond is called whenever an object
+// of one of objs' types is added, updated, or deleted in namespace, and every watchResyncInterval
+// in case an event is missed. If n is nil or an informer of objs cannot sync, ex. the user cannot
+// watch its type, cond is called every interval instead.
+func WaitForChange(ctx context.Context, n *Notifier, namespace string, interval time.Duration,
+	cond wait.ConditionFunc, objs ...runtime.Object) error {
+
+	changed := make(chan struct{}, 1)
+	watching := make(chan struct{})
+	if n != nil {
+		// Subscriptions are made in the background, so cond is checked while informers sync.
+		var unsubscribe []func()
+		subscribed := make(chan struct{})
+		syncCtx, cancel := context.WithTimeout(ctx, informerSyncTimeout)
+		go func() {
+			defer close(subscribed)
+			for _, obj := range objs {
+				unsub, err := n.subscribe(syncCtx, namespace, obj, changed)
+				if err != nil {
+					log.Debugf("Polling for %T changes: %v", obj, err)
+					return
+				}
+				unsubscribe = append(unsubscribe, unsub)
+			}
+			close(watching)
+		}()
+		defer func() {
+			cancel()
+			<-subscribed
+			for _, unsub := range unsubscribe {
+				unsub()
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer func() { ticker.Stop() }()
+	for {
+		if done, err := cond(); err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return wait.ErrWaitTimeout
+		case <-changed:
+		case <-ticker.C:
+		case <-watching:
+			// Events are now delivered, so only poll to resync.
+			watching = nil
+			ticker.Stop()
+			ticker = time.NewTicker(watchResyncInterval)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+)
+
+var _ = Describe("WaitForChange", func() {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		calls  int32
+		// cond is done on its third call.
+		cond = func() (bool, error) {
+			return atomic.AddInt32(&calls, 1) >= 3, nil
+		}
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithTimeout(context.TODO(), 10*time.Second)
+		atomic.StoreInt32(&calls, 0)
+	})
+	AfterEach(func() {
+		cancel()
+	})
+
+	// waitForAdds waits for cond while adding objects to fi, returning the wait's error.
+	waitForAdds := func(n *Notifier, namespace string, fi *controllertest.FakeInformer) error {
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- WaitForChange(ctx, n, namespace, time.Hour, cond, &olmapiv1alpha1.Subscription{})
+		}()
+		sub := &olmapiv1alpha1.Subscription{}
+		Eventually(func() int32 {
+			fi.Add(sub)
+			return atomic.LoadInt32(&calls)
+		}).Should(BeNumerically(">=", 3))
+		var err error
+		Eventually(errCh).Should(Receive(&err))
+		return err
+	}
+
+	It("checks the condition when objects of the watched type change", func() {
+		informers := &informertest.FakeInformers{Scheme: Scheme}
+		fi, err := informers.FakeInformerFor(&olmapiv1alpha1.Subscription{})
+		Expect(err).NotTo(HaveOccurred())
+		n := NewNotifier("default", informers, nil)
+		Expect(waitForAdds(n, "default", fi)).To(Succeed())
+		Expect(n.subscribers).To(HaveKeyWithValue(fi, BeEmpty()))
+	})
+	It("registers a single event handler per informer", func() {
+		informers := &informertest.FakeInformers{Scheme: Scheme}
+		fi, err := informers.FakeInformerFor(&olmapiv1alpha1.Subscription{})
+		Expect(err).NotTo(HaveOccurred())
+		counting := &countingInformers{Informers: informers}
+		n := NewNotifier("default", counting, nil)
+		for i := 0; i < 3; i++ {
+			atomic.StoreInt32(&calls, 0)
+			Expect(waitForAdds(n, "default", fi)).To(Succeed())
+		}
+		Expect(atomic.LoadInt32(&counting.handlers)).To(BeEquivalentTo(1))
+	})
+	It("watches objects in other namespaces with their own informers", func() {
+		other := &informertest.FakeInformers{Scheme: Scheme}
+		fi, err := other.FakeInformerFor(&olmapiv1alpha1.Subscription{})
+		Expect(err).NotTo(HaveOccurred())
+		n := NewNotifier("default", &informertest.FakeInformers{Scheme: Scheme}, func(namespace string) (cache.Informers, error) {
+			Expect(namespace).To(Equal("openshift-operators"))
+			return other, nil
+		})
+		Expect(waitForAdds(n, "openshift-operators", fi)).To(Succeed())
+	})
+	It("polls without a notifier", func() {
+		Expect(WaitForChange(ctx, nil, "default", time.Millisecond, cond, &olmapiv1alpha1.Subscription{})).To(Succeed())
+		Expect(atomic.LoadInt32(&calls)).To(BeEquivalentTo(3))
+	})
+	It("polls objects in namespaces without informers", func() {
+		n := NewNotifier("default", &informertest.FakeInformers{Scheme: Scheme}, nil)
+		Expect(WaitForChange(ctx, n, "other", time.Millisecond, cond, &olmapiv1alpha1.Subscription{})).To(Succeed())
+		Expect(atomic.LoadInt32(&calls)).To(BeEquivalentTo(3))
+	})
+	It("returns wait.ErrWaitTimeout when the context is done", func() {
+		cancel()
+		never := func() (bool, error) { return false, nil }
+		Expect(WaitForChange(ctx, nil, "default", time.Hour, never, &olmapiv1alpha1.Subscription{})).
+			To(Equal(wait.ErrWaitTimeout))
+	})
+})
+
+// countingInformers counts the event handlers added to its informers.
+type countingInformers struct {
+	cache.Informers
+	handlers int32
+}
+
+func (c *countingInformers) GetInformer(ctx context.Context, obj runtime.Object) (cache.Informer, error) {
+	informer, err := c.Informers.GetInformer(ctx, obj)
+	return countingInformer{Informer: informer, handlers: &c.handlers}, err
+}
+
+type countingInformer struct {
+	cache.Informer
+	handlers *int32
+}
+
+func (c countingInformer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	atomic.AddInt32(c.handlers, 1)
+	c.Informer.AddEventHandler(handler)
+}
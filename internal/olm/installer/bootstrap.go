@@ -0,0 +1,106 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"context"
+	"fmt"
+
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const installOLMFlag = "install-olm"
+
+// Bootstrap configures installing OLM into clusters it is absent from, ex. throwaway CI clusters,
+// before installing an operator. It is a flag value, so it can be set without a version to install
+// DefaultVersion.
+type Bootstrap struct {
+	Version string
+	enabled bool
+}
+
+var _ pflag.Value = &Bootstrap{}
+
+// BindFlags binds --install-olm[=version] to b.
+func (b *Bootstrap) BindFlags(fs *pflag.FlagSet) {
+	fs.Var(b, installOLMFlag, fmt.Sprintf("install OLM of the given version into namespace %q if it is "+
+		"not installed in the cluster (default %s)", DefaultOLMNamespace, DefaultVersion))
+	fs.Lookup(installOLMFlag).NoOptDefVal = DefaultVersion
+}
+
+func (b *Bootstrap) String() string {
+	if !b.enabled {
+		return ""
+	}
+	return b.Version
+}
+
+func (b *Bootstrap) Set(s string) error {
+	if s == "" {
+		return fmt.Errorf("version must not be empty")
+	}
+	b.Version, b.enabled = s, true
+	return nil
+}
+
+func (b *Bootstrap) Type() string {
+	return "version"
+}
+
+// Enabled returns true if --install-olm was set.
+func (b Bootstrap) Enabled() bool {
+	return b.enabled
+}
+
+// Run installs OLM into DefaultOLMNamespace using cfg if the cluster does not serve OLM's APIs,
+// and returns true if it was installed.
+func (b Bootstrap) Run(ctx context.Context, cfg *rest.Config) (bool, error) {
+	c, err := ClientForConfig(cfg)
+	if err != nil {
+		return false, err
+	}
+	return b.run(ctx, c)
+}
+
+func (b Bootstrap) run(ctx context.Context, c *Client) (bool, error) {
+	installed, err := IsInstalled(ctx, c.KubeClient)
+	if err != nil || installed {
+		return false, err
+	}
+	log.Infof("OLM is not installed, installing OLM version %q", b.Version)
+	if _, err := c.InstallVersion(ctx, DefaultOLMNamespace, b.Version); err != nil {
+		return false, fmt.Errorf("error installing OLM version %q: %v", b.Version, err)
+	}
+	log.Infof("Successfully installed OLM version %q", b.Version)
+	return true, nil
+}
+
+// IsInstalled returns true if the cluster serves OLM's Subscription API, regardless of the namespace
+// or distribution OLM was installed with.
+func IsInstalled(ctx context.Context, c client.Reader) (bool, error) {
+	subs := &olmapiv1alpha1.SubscriptionList{}
+	if err := c.List(ctx, subs, client.Limit(1)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking for an OLM installation: %v", err)
+	}
+	return true, nil
+}
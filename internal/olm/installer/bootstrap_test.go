@@ -0,0 +1,100 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	olmresourceclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+)
+
+// listErrorClient fails every List with err.
+type listErrorClient struct {
+	client.Client
+	err error
+}
+
+func (c listErrorClient) List(context.Context, runtime.Object, ...client.ListOption) error {
+	return c.err
+}
+
+var _ = Describe("Bootstrap", func() {
+	Describe("BindFlags", func() {
+		var (
+			b  *Bootstrap
+			fs *pflag.FlagSet
+		)
+		BeforeEach(func() {
+			b = &Bootstrap{}
+			fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+			b.BindFlags(fs)
+		})
+
+		It("is disabled by default", func() {
+			Expect(fs.Parse(nil)).To(Succeed())
+			Expect(b.Enabled()).To(BeFalse())
+		})
+		It("installs the default version without a value", func() {
+			Expect(fs.Parse([]string{"--install-olm"})).To(Succeed())
+			Expect(b.Enabled()).To(BeTrue())
+			Expect(b.Version).To(Equal(DefaultVersion))
+		})
+		It("installs the given version", func() {
+			Expect(fs.Parse([]string{"--install-olm=0.16.1"})).To(Succeed())
+			Expect(b.Enabled()).To(BeTrue())
+			Expect(b.Version).To(Equal("0.16.1"))
+		})
+		It("rejects an empty version", func() {
+			Expect(fs.Parse([]string{"--install-olm="})).NotTo(Succeed())
+		})
+	})
+
+	Describe("run", func() {
+		It("does not install OLM if it is installed", func() {
+			c := &Client{Client: &olmresourceclient.Client{
+				KubeClient: fake.NewFakeClientWithScheme(olmresourceclient.Scheme),
+			}}
+			installed, err := Bootstrap{Version: DefaultVersion}.run(context.TODO(), c)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(installed).To(BeFalse())
+		})
+		It("fails if the installation cannot be checked", func() {
+			c := &Client{Client: &olmresourceclient.Client{
+				KubeClient: listErrorClient{err: errors.New("forbidden")},
+			}}
+			_, err := Bootstrap{Version: DefaultVersion}.run(context.TODO(), c)
+			Expect(err).To(MatchError("error checking for an OLM installation: forbidden"))
+		})
+	})
+
+	Describe("IsInstalled", func() {
+		It("returns false if the cluster does not serve OLM's APIs", func() {
+			noMatch := &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "operators.coreos.com", Kind: "Subscription"}}
+			installed, err := IsInstalled(context.TODO(), listErrorClient{err: noMatch})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(installed).To(BeFalse())
+		})
+	})
+})
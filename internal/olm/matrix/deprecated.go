@@ -0,0 +1,114 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/client-go/kubernetes"
+)
+
+// deprecatedAPIsMetric is exported by API servers since Kubernetes 1.19, with a series for each deprecated
+// API requested since the API server started.
+const deprecatedAPIsMetric = "apiserver_requested_deprecated_apis"
+
+// DeprecatedAPI is a deprecated API requested from a cluster.
+type DeprecatedAPI struct {
+	Group          string `json:"group,omitempty"`
+	Version        string `json:"version"`
+	Resource       string `json:"resource"`
+	RemovedRelease string `json:"removedRelease,omitempty"`
+}
+
+func (a DeprecatedAPI) String() string {
+	gv := a.Version
+	if a.Group != "" {
+		gv = a.Group + "/" + a.Version
+	}
+	if a.RemovedRelease == "" {
+		return fmt.Sprintf("%s %s", gv, a.Resource)
+	}
+	return fmt.Sprintf("%s %s (removed in %s)", gv, a.Resource, a.RemovedRelease)
+}
+
+// requestedDeprecatedAPIs reads the deprecated APIs requested from the cluster from its API server's metrics.
+// No APIs are returned for clusters older than Kubernetes 1.19.
+func requestedDeprecatedAPIs(ctx context.Context, cs kubernetes.Interface) ([]DeprecatedAPI, error) {
+	b, err := cs.Discovery().RESTClient().Get().AbsPath("/metrics").DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting API server metrics: %v", err)
+	}
+	return parseDeprecatedAPIs(bytes.NewReader(b))
+}
+
+// parseDeprecatedAPIs parses the deprecated APIs from metrics in the Prometheus text format, sorted.
+func parseDeprecatedAPIs(r io.Reader) ([]DeprecatedAPI, error) {
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing API server metrics: %v", err)
+	}
+	family, ok := families[deprecatedAPIsMetric]
+	if !ok {
+		return nil, nil
+	}
+	var apis []DeprecatedAPI
+	for _, m := range family.GetMetric() {
+		api := DeprecatedAPI{}
+		for _, l := range m.GetLabel() {
+			switch l.GetName() {
+			case "group":
+				api.Group = l.GetValue()
+			case "version":
+				api.Version = l.GetValue()
+			case "resource":
+				api.Resource = l.GetValue()
+			case "removed_release":
+				api.RemovedRelease = l.GetValue()
+			}
+		}
+		if !containsAPI(apis, api) {
+			apis = append(apis, api)
+		}
+	}
+	sort.Slice(apis, func(i, j int) bool {
+		return apis[i].String() < apis[j].String()
+	})
+	return apis, nil
+}
+
+// subtract returns the APIs in apis that are not in other.
+func subtract(apis, other []DeprecatedAPI) (diff []DeprecatedAPI) {
+	for _, api := range apis {
+		if !containsAPI(other, api) {
+			diff = append(diff, api)
+		}
+	}
+	return diff
+}
+
+func containsAPI(apis []DeprecatedAPI, api DeprecatedAPI) bool {
+	for _, a := range apis {
+		if a == api {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// kindWait is how long kind waits for a cluster's control plane to become ready.
+const kindWait = 5 * time.Minute
+
+// KindProvisioner creates clusters with kind, which must be in PATH.
+type KindProvisioner struct {
+	// Dir is the directory kubeconfigs are written to.
+	Dir string
+}
+
+func (p KindProvisioner) Create(ctx context.Context, name, nodeImage string) (string, error) {
+	kubeconfig := filepath.Join(p.Dir, name+".kubeconfig")
+	if err := runKind(ctx, "create", "cluster", "--name", name, "--image", nodeImage,
+		"--kubeconfig", kubeconfig, "--wait", kindWait.String()); err != nil {
+		return "", err
+	}
+	return kubeconfig, nil
+}
+
+func (p KindProvisioner) Delete(ctx context.Context, name string) error {
+	return runKind(ctx, "delete", "cluster", "--name", name)
+}
+
+func runKind(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, "kind", args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running kind %s: %v", strings.Join(args, " "), err)
+	}
+	return nil
+}
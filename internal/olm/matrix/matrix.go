@@ -0,0 +1,147 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package matrix installs an operator into clusters of several Kubernetes versions, and reports
+// which installs passed and which deprecated APIs each install requested, for release qualification.
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/installer"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+// DefaultClusterPrefix prefixes the names of the clusters a Matrix creates.
+const DefaultClusterPrefix = "operator-sdk-matrix"
+
+// Provisioner creates and deletes the clusters a Matrix installs into.
+type Provisioner interface {
+	// Create creates a cluster named name with nodeImage, and returns the path of its kubeconfig.
+	Create(ctx context.Context, name, nodeImage string) (string, error)
+	// Delete deletes the cluster named name.
+	Delete(ctx context.Context, name string) error
+}
+
+// Matrix runs an install in one new cluster per node image.
+type Matrix struct {
+	// NodeImages are the node images of the clusters, one cluster per image.
+	NodeImages []string
+	// ClusterPrefix prefixes cluster names, which are suffixed with the index of their node image.
+	ClusterPrefix string
+	// OLMVersion is the version of OLM installed into each cluster.
+	OLMVersion string
+	// Timeout is the timeout of installing OLM and running Install in a cluster.
+	Timeout time.Duration
+	// KeepClusters keeps clusters once their install completes, ex. to debug failed installs.
+	KeepClusters bool
+	Provisioner  Provisioner
+
+	// Install installs the operator with Config, which is loaded for each cluster before Install is called.
+	Install func(ctx context.Context) error
+	Config  *operator.Configuration
+}
+
+// Run runs the install in every cluster in turn, and returns the result of each.
+// Failures of one cluster do not stop other clusters from being tested.
+func (m Matrix) Run(ctx context.Context) Report {
+	prefix := m.ClusterPrefix
+	if prefix == "" {
+		prefix = DefaultClusterPrefix
+	}
+	report := Report{}
+	for i, image := range m.NodeImages {
+		name := fmt.Sprintf("%s-%d", prefix, i)
+		start := time.Now()
+		r := Result{NodeImage: image, Cluster: name}
+		if err := m.runCluster(ctx, name, &r); err != nil {
+			log.Errorf("Install into cluster %s (%s) failed: %v", name, image, err)
+			r.Error = err.Error()
+		} else {
+			r.Passed = true
+		}
+		r.Duration = time.Since(start).Round(time.Second).String()
+		report.Results = append(report.Results, r)
+	}
+	return report
+}
+
+// runCluster creates cluster name, installs OLM and runs the install in it, then deletes it.
+func (m Matrix) runCluster(ctx context.Context, name string, r *Result) (err error) {
+	log.Infof("Creating cluster %s with node image %s", name, r.NodeImage)
+	kubeconfig, err := m.Provisioner.Create(ctx, name, r.NodeImage)
+	if err != nil {
+		return fmt.Errorf("error creating cluster: %v", err)
+	}
+	if m.KeepClusters {
+		log.Infof("Keeping cluster %s, with kubeconfig %s", name, kubeconfig)
+	} else {
+		defer func() {
+			if derr := m.Provisioner.Delete(ctx, name); derr != nil {
+				log.Warnf("Failed to delete cluster %s: %v", name, derr)
+			}
+		}()
+	}
+
+	m.Config.KubeconfigPath = kubeconfig
+	// Reset the namespace loaded for the previous cluster, so it is loaded from the new kubeconfig.
+	m.Config.Namespace = ""
+	if err := m.Config.Load(); err != nil {
+		return fmt.Errorf("error loading cluster config: %v", err)
+	}
+	// Stop watching the cluster before it is deleted.
+	defer func() {
+		if err := m.Config.Close(); err != nil {
+			log.Warn(err)
+		}
+	}()
+	cs, err := kubernetes.NewForConfig(m.Config.RESTConfig)
+	if err != nil {
+		return err
+	}
+	if v, err := cs.Discovery().ServerVersion(); err == nil {
+		r.KubernetesVersion = v.GitVersion
+	}
+
+	if m.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.Timeout)
+		defer cancel()
+	}
+	olmClient, err := installer.ClientForConfig(m.Config.RESTConfig)
+	if err != nil {
+		return err
+	}
+	if _, err := olmClient.InstallVersion(ctx, installer.DefaultOLMNamespace, m.OLMVersion); err != nil {
+		return fmt.Errorf("error installing OLM: %v", err)
+	}
+
+	// Deprecated API requests made before the install, ex. by OLM, are not attributed to the operator.
+	before, err := requestedDeprecatedAPIs(ctx, cs)
+	if err != nil {
+		log.Warnf("Failed to read deprecated API requests of cluster %s: %v", name, err)
+	}
+	installErr := m.Install(ctx)
+	after, err := requestedDeprecatedAPIs(ctx, cs)
+	if err != nil {
+		log.Warnf("Failed to read deprecated API requests of cluster %s: %v", name, err)
+	}
+	r.DeprecatedAPIs = subtract(after, before)
+	return installErr
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+// failingProvisioner fails to create clusters.
+type failingProvisioner struct {
+	created, deleted []string
+}
+
+func (p *failingProvisioner) Create(_ context.Context, name, _ string) (string, error) {
+	p.created = append(p.created, name)
+	return "", errors.New("no docker daemon")
+}
+
+func (p *failingProvisioner) Delete(_ context.Context, name string) error {
+	p.deleted = append(p.deleted, name)
+	return nil
+}
+
+const metrics = `# HELP apiserver_requested_deprecated_apis Gauge of deprecated APIs that have been requested.
+# TYPE apiserver_requested_deprecated_apis gauge
+apiserver_requested_deprecated_apis{group="apiextensions.k8s.io",removed_release="1.22",resource="customresourcedefinitions",subresource="",version="v1beta1"} 1
+apiserver_requested_deprecated_apis{group="apiextensions.k8s.io",removed_release="1.22",resource="customresourcedefinitions",subresource="status",version="v1beta1"} 1
+apiserver_requested_deprecated_apis{group="",removed_release="",resource="componentstatuses",subresource="",version="v1"} 1
+# HELP apiserver_request_total Counter of apiserver requests.
+# TYPE apiserver_request_total counter
+apiserver_request_total{code="200",verb="GET"} 10
+`
+
+var _ = Describe("Matrix", func() {
+	Describe("Run", func() {
+		It("reports a failure per cluster that cannot be created and continues", func() {
+			p := &failingProvisioner{}
+			m := Matrix{
+				NodeImages:  []string{"kindest/node:v1.18.8", "kindest/node:v1.19.1"},
+				Provisioner: p,
+				Config:      &operator.Configuration{},
+				Install:     func(context.Context) error { return nil },
+			}
+			report := m.Run(context.TODO())
+			Expect(report.Passed()).To(BeFalse())
+			Expect(report.Results).To(HaveLen(2))
+			Expect(report.Results[0].NodeImage).To(Equal("kindest/node:v1.18.8"))
+			Expect(report.Results[0].Cluster).To(Equal("operator-sdk-matrix-0"))
+			Expect(report.Results[0].Error).To(ContainSubstring("no docker daemon"))
+			Expect(report.Results[1].Cluster).To(Equal("operator-sdk-matrix-1"))
+			Expect(p.created).To(Equal([]string{"operator-sdk-matrix-0", "operator-sdk-matrix-1"}))
+			Expect(p.deleted).To(BeEmpty())
+		})
+	})
+
+	Describe("parseDeprecatedAPIs", func() {
+		It("returns each deprecated API once, sorted", func() {
+			apis, err := parseDeprecatedAPIs(strings.NewReader(metrics))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apis).To(Equal([]DeprecatedAPI{
+				{Group: "apiextensions.k8s.io", Version: "v1beta1", Resource: "customresourcedefinitions",
+					RemovedRelease: "1.22"},
+				{Version: "v1", Resource: "componentstatuses"},
+			}))
+			Expect(apis[0].String()).To(Equal("apiextensions.k8s.io/v1beta1 customresourcedefinitions (removed in 1.22)"))
+			Expect(apis[1].String()).To(Equal("v1 componentstatuses"))
+		})
+		It("returns no APIs if the metric is not exported", func() {
+			apis, err := parseDeprecatedAPIs(strings.NewReader("apiserver_request_total 10\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apis).To(BeEmpty())
+		})
+	})
+
+	Describe("subtract", func() {
+		It("returns the APIs not requested before", func() {
+			a := DeprecatedAPI{Version: "v1", Resource: "componentstatuses"}
+			b := DeprecatedAPI{Group: "extensions", Version: "v1beta1", Resource: "ingresses"}
+			Expect(subtract([]DeprecatedAPI{a, b}, []DeprecatedAPI{a})).To(Equal([]DeprecatedAPI{b}))
+			Expect(subtract([]DeprecatedAPI{a}, []DeprecatedAPI{a})).To(BeEmpty())
+		})
+	})
+
+	Describe("WriteText", func() {
+		It("writes a table and the details of failed installs and deprecated APIs", func() {
+			r := Report{Results: []Result{
+				{NodeImage: "kindest/node:v1.18.8", KubernetesVersion: "v1.18.8", Passed: true, Duration: "2m0s"},
+				{NodeImage: "kindest/node:v1.19.1", KubernetesVersion: "v1.19.1", Error: "CSV failed",
+					Duration: "3m0s", DeprecatedAPIs: []DeprecatedAPI{{Group: "extensions", Version: "v1beta1",
+						Resource: "ingresses", RemovedRelease: "1.22"}}},
+			}}
+			b := &bytes.Buffer{}
+			Expect(WriteText(b, r)).To(Succeed())
+			Expect(b.String()).To(Equal(`NODE IMAGE            VERSION  RESULT  DEPRECATED APIS  DURATION
+kindest/node:v1.18.8  v1.18.8  PASS    0                2m0s
+kindest/node:v1.19.1  v1.19.1  FAIL    1                3m0s
+
+kindest/node:v1.19.1:
+  error: CSV failed
+  deprecated API: extensions/v1beta1 ingresses (removed in 1.22)
+`))
+		})
+	})
+})
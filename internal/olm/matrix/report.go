@@ -0,0 +1,87 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Report is the result of a Matrix run.
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// Result is the result of installing into one cluster.
+type Result struct {
+	NodeImage         string `json:"nodeImage"`
+	Cluster           string `json:"cluster"`
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	Passed            bool   `json:"passed"`
+	Error             string `json:"error,omitempty"`
+	Duration          string `json:"duration"`
+	// DeprecatedAPIs are the deprecated APIs requested while installing, ex. by OLM to create the
+	// operator's manifests.
+	DeprecatedAPIs []DeprecatedAPI `json:"deprecatedAPIs,omitempty"`
+}
+
+// Passed returns true if every install passed.
+func (r Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteText writes a table of r's results to w, followed by the errors and deprecated APIs of each cluster.
+func WriteText(w io.Writer, r Report) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NODE IMAGE\tVERSION\tRESULT\tDEPRECATED APIS\tDURATION")
+	for _, result := range r.Results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		}
+		version := result.KubernetesVersion
+		if version == "" {
+			version = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", result.NodeImage, version, status, len(result.DeprecatedAPIs),
+			result.Duration)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	b := &strings.Builder{}
+	for _, result := range r.Results {
+		if result.Passed && len(result.DeprecatedAPIs) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "\n%s:\n", result.NodeImage)
+		if result.Error != "" {
+			fmt.Fprintf(b, "  error: %s\n", result.Error)
+		}
+		for _, api := range result.DeprecatedAPIs {
+			fmt.Fprintf(b, "  deprecated API: %s\n", api)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,168 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics pushes the phase timings of operator installs to a Prometheus Pushgateway or an
+// OpenTelemetry (OTLP) collector, so install time can be tracked across releases.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/spf13/pflag"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+	"github.com/operator-framework/operator-sdk/internal/util/otlp"
+)
+
+const (
+	// DefaultJob is the Pushgateway job, and OTLP service name, timings are pushed as.
+	DefaultJob = "operator-sdk"
+
+	phaseDurationName = "operator_sdk_install_phase_duration_seconds"
+	durationName      = "operator_sdk_install_duration_seconds"
+	succeededName     = "operator_sdk_install_succeeded"
+)
+
+// Pusher pushes install timings to each configured endpoint.
+type Pusher struct {
+	// PushgatewayURL is the URL of a Prometheus Pushgateway.
+	PushgatewayURL string
+	// OTLPEndpoint is the URL of an OTLP/HTTP collector. If it has no path, otlp.MetricsPath is used.
+	OTLPEndpoint string
+	// Job is the Pushgateway job and OTLP service name.
+	Job string
+	// Labels are added to every pushed metric, ex. the installed package.
+	Labels map[string]string
+	// Client sends requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+func (p *Pusher) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&p.PushgatewayURL, "metrics-pushgateway-url", "", "URL of a Prometheus Pushgateway to push "+
+		"install phase timings to")
+	fs.StringVar(&p.OTLPEndpoint, "metrics-otlp-endpoint", "", "URL of an OTLP/HTTP collector to push install "+
+		"phase timings to, ex. http://localhost:4318")
+	fs.StringVar(&p.Job, "metrics-job", DefaultJob, "Pushgateway job and OTLP service name of pushed timings")
+}
+
+// Enabled returns true if any endpoint is configured.
+func (p Pusher) Enabled() bool {
+	return p.PushgatewayURL != "" || p.OTLPEndpoint != ""
+}
+
+// Push pushes t to each configured endpoint.
+func (p Pusher) Push(ctx context.Context, t registry.InstallTimings) error {
+	if p.PushgatewayURL != "" {
+		if err := p.pushPrometheus(t); err != nil {
+			return fmt.Errorf("error pushing timings to Pushgateway: %v", err)
+		}
+	}
+	if p.OTLPEndpoint != "" {
+		if err := p.pushOTLP(ctx, t); err != nil {
+			return fmt.Errorf("error pushing timings to OTLP endpoint: %v", err)
+		}
+	}
+	return nil
+}
+
+func (p Pusher) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return http.DefaultClient
+}
+
+// pushPrometheus replaces the metrics of p's job and labels in the Pushgateway with t.
+func (p Pusher) pushPrometheus(t registry.InstallTimings) error {
+	phases := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: phaseDurationName,
+		Help: "Duration of each phase of the last operator install.",
+	}, []string{"phase"})
+	for _, phase := range t.Phases {
+		phases.WithLabelValues(phase.Phase).Set(phase.Duration.Seconds())
+	}
+	duration := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: durationName,
+		Help: "Duration of the last operator install.",
+	})
+	duration.Set(t.Total.Seconds())
+	succeeded := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: succeededName,
+		Help: "1 if the last operator install succeeded, 0 otherwise.",
+	})
+	succeeded.Set(boolValue(t.Succeeded))
+
+	pusher := push.New(p.PushgatewayURL, p.job()).Client(p.client()).
+		Collector(phases).Collector(duration).Collector(succeeded)
+	for _, k := range p.labelNames() {
+		pusher = pusher.Grouping(k, p.Labels[k])
+	}
+	return pusher.Push()
+}
+
+// pushOTLP posts t as OTLP gauges.
+func (p Pusher) pushOTLP(ctx context.Context, t registry.InstallTimings) error {
+	now := otlp.UnixNano(time.Now())
+	point := func(v float64, attrs ...otlp.Attribute) otlp.DataPoint {
+		for _, k := range p.labelNames() {
+			attrs = append(attrs, otlp.NewAttribute(k, p.Labels[k]))
+		}
+		return otlp.DataPoint{AsDouble: v, TimeUnixNano: now, Attributes: attrs}
+	}
+	var phasePoints []otlp.DataPoint
+	for _, phase := range t.Phases {
+		phasePoints = append(phasePoints, point(phase.Duration.Seconds(), otlp.NewAttribute("phase", phase.Phase)))
+	}
+	metrics := []otlp.Metric{
+		{Name: phaseDurationName, Unit: "s", Gauge: otlp.Gauge{DataPoints: phasePoints}},
+		{Name: durationName, Unit: "s", Gauge: otlp.Gauge{DataPoints: []otlp.DataPoint{point(t.Total.Seconds())}}},
+		{Name: succeededName, Gauge: otlp.Gauge{DataPoints: []otlp.DataPoint{point(boolValue(t.Succeeded))}}},
+	}
+	return otlp.Post(ctx, p.Client, p.OTLPEndpoint, otlp.MetricsPath, otlp.MetricsRequest{
+		ResourceMetrics: []otlp.ResourceMetrics{{
+			Resource:     otlp.NewResource(p.job()),
+			ScopeMetrics: []otlp.ScopeMetrics{{Scope: otlp.Scope{Name: DefaultJob}, Metrics: metrics}},
+		}},
+	})
+}
+
+func (p Pusher) job() string {
+	if p.Job == "" {
+		return DefaultJob
+	}
+	return p.Job
+}
+
+// labelNames returns the names of p's labels, sorted so requests are deterministic.
+func (p Pusher) labelNames() []string {
+	var names []string
+	for k := range p.Labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+	"github.com/operator-framework/operator-sdk/internal/util/otlp"
+)
+
+var _ = Describe("Pusher", func() {
+	var (
+		server   *httptest.Server
+		requests []*http.Request
+		bodies   []string
+		timings  registry.InstallTimings
+	)
+
+	BeforeEach(func() {
+		requests, bodies = nil, nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			requests = append(requests, r)
+			bodies = append(bodies, string(b))
+		}))
+		timings = registry.InstallTimings{
+			Phases: []registry.PhaseTiming{
+				{Phase: registry.PhaseCatalogReady, Duration: 3 * time.Second},
+				{Phase: registry.PhaseCSVReady, Duration: 20 * time.Second},
+			},
+			Total:     30 * time.Second,
+			Succeeded: true,
+		}
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("is disabled without endpoints", func() {
+		Expect(Pusher{}.Enabled()).To(BeFalse())
+		Expect(Pusher{}.Push(context.TODO(), timings)).To(Succeed())
+	})
+
+	It("pushes to a Pushgateway, grouped by job and labels", func() {
+		p := Pusher{PushgatewayURL: server.URL, Labels: map[string]string{"package": "memcached-operator"}}
+		Expect(p.Push(context.TODO(), timings)).To(Succeed())
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Method).To(Equal(http.MethodPut))
+		Expect(requests[0].URL.Path).To(Equal("/metrics/job/operator-sdk/package/memcached-operator"))
+	})
+
+	It("pushes OTLP gauges as JSON", func() {
+		p := Pusher{OTLPEndpoint: server.URL, Job: "release-ci", Labels: map[string]string{"package": "memcached-operator"}}
+		Expect(p.Push(context.TODO(), timings)).To(Succeed())
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Method).To(Equal(http.MethodPost))
+		Expect(requests[0].URL.Path).To(Equal("/v1/metrics"))
+		Expect(requests[0].Header.Get("Content-Type")).To(Equal("application/json"))
+
+		req := otlp.MetricsRequest{}
+		Expect(json.Unmarshal([]byte(bodies[0]), &req)).To(Succeed())
+		Expect(req.ResourceMetrics).To(HaveLen(1))
+		rm := req.ResourceMetrics[0]
+		Expect(rm.Resource.Attributes).To(ConsistOf(otlp.NewAttribute("service.name", "release-ci")))
+		metrics := rm.ScopeMetrics[0].Metrics
+		Expect(metrics).To(HaveLen(3))
+		Expect(metrics[0].Name).To(Equal(phaseDurationName))
+		Expect(metrics[0].Gauge.DataPoints).To(HaveLen(2))
+		Expect(metrics[0].Gauge.DataPoints[1].AsDouble).To(Equal(20.0))
+		Expect(metrics[0].Gauge.DataPoints[1].Attributes).To(ConsistOf(
+			otlp.NewAttribute("phase", registry.PhaseCSVReady),
+			otlp.NewAttribute("package", "memcached-operator"),
+		))
+		Expect(metrics[1].Gauge.DataPoints[0].AsDouble).To(Equal(30.0))
+		Expect(metrics[2].Gauge.DataPoints[0].AsDouble).To(Equal(1.0))
+	})
+
+	It("returns an error if the OTLP endpoint rejects the request", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad payload", http.StatusBadRequest)
+		})
+		err := Pusher{OTLPEndpoint: server.URL + "/custom"}.Push(context.TODO(), timings)
+		Expect(err).To(MatchError(ContainSubstring("400 Bad Request: bad payload")))
+	})
+})
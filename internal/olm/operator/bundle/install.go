@@ -17,21 +17,35 @@ package bundle
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+	"github.com/operator-framework/operator-sdk/internal/olm/preflight"
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 )
 
+// Modes in which a bundle's contents are unpacked.
+const (
+	// UnpackModeCluster adds the bundle image to an index served by an in-cluster registry pod,
+	// which pulls the image itself.
+	UnpackModeCluster = "cluster"
+	// UnpackModeClient pulls and unpacks the bundle image locally, then serves its contents
+	// from ConfigMaps, so no image is pulled in-cluster. ConfigMaps are reused if the bundle is unchanged.
+	UnpackModeClient = "client"
+)
+
 type Install struct {
 	BundleImage string
+	UnpackMode  string
+	// SkipResourceCheck skips checking that the CSV's resource requests fit the namespace and cluster.
+	SkipResourceCheck bool
 
 	*registry.IndexImageCatalogCreator
 	*registry.OperatorInstaller
@@ -54,6 +68,24 @@ const defaultIndexImage = "quay.io/operator-framework/upstream-opm-builder:lates
 func (i *Install) BindFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&i.IndexImage, "index-image", defaultIndexImage, "index image in which to inject bundle")
 	fs.Var(&i.InstallMode, "install-mode", "install mode")
+	fs.Var(&i.SecurityContextConfig, "security-context-config", "security context of the registry pod, one of "+
+		"[legacy, restricted]. Detected from the cluster if unset: restricted on OpenShift or in namespaces "+
+		"enforcing the restricted Pod Security Standard, legacy otherwise")
+	fs.StringVar(&i.UnpackMode, "unpack", UnpackModeCluster, "where to unpack the bundle, one of [cluster, client]. "+
+		"With client, the bundle is unpacked locally and served from ConfigMaps instead of being pulled in-cluster")
+	fs.BoolVar(&i.SkipResourceCheck, "skip-resource-check", false, "do not check that the resources requested by "+
+		"the CSV's deployments fit the namespace's LimitRanges and ResourceQuotas and the cluster's free capacity")
+	fs.BoolVar(&i.SkipOperatorGroup, "skip-operator-group", false, "do not create an OperatorGroup, for namespaces "+
+		"whose OperatorGroups are managed by platform automation. The namespace's existing OperatorGroup must "+
+		"cover --install-mode's target namespaces")
+	fs.StringToStringVar(&i.ImageOverrides, "set-image", nil, "override the image of a container in the "+
+		"CSV's deployments once installed, as <container>=<image> or <deployment>/<container>=<image>. "+
+		"A container name shared by several deployments must be qualified by its deployment. May be set more than once")
+	i.IndexImageCatalogCreator.CatalogSourceConfig.BindFlags(fs)
+	i.OperatorInstaller.SubscriptionConfig.BindFlags(fs)
+	fs.Var(&i.CSVHealth, "csv-health", "criterion for the CSV being installed, one of [phase, conditions, "+
+		"deployments-ready]. conditions also accepts a CSV that reached InstallSucceeded and then briefly "+
+		"returned to another phase; deployments-ready also waits for all of the CSV's deployments to be ready")
 	fs.StringVar(&i.InjectBundleMode, "mode", "", "mode to use for adding bundle to index")
 	_ = fs.MarkHidden("mode")
 }
@@ -65,20 +97,80 @@ func (i Install) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, erro
 	return i.InstallOperator(ctx)
 }
 
+// Detach creates the install's resources without waiting for OLM to install the operator, returning its
+// Subscription. The install is completed by OperatorInstaller.ResumeInstall.
+func (i Install) Detach(ctx context.Context) (*v1alpha1.Subscription, error) {
+	if err := i.setup(ctx); err != nil {
+		return nil, err
+	}
+	return i.Subscribe(ctx)
+}
+
+// Cleanup uninstalls the operator installed by a complete or failed run of i, and deletes its catalog source
+// in case the run failed before subscribing to it.
+func (i Install) Cleanup(ctx context.Context) error {
+	if i.OperatorInstaller.PackageName == "" {
+		return nil
+	}
+	u := operator.NewUninstall(i.cfg)
+	u.Package = i.OperatorInstaller.PackageName
+	u.DeleteAll = true
+	u.DeleteOperatorGroupNames = []string{operator.SDKOperatorGroupName}
+	if err := u.Run(ctx); err != nil {
+		log.Warnf("Failed to uninstall operator: %v", err)
+	}
+
+	cs := &v1alpha1.CatalogSource{}
+	cs.SetName(i.OperatorInstaller.CatalogSourceName)
+	cs.SetNamespace(i.cfg.Namespace)
+	if err := i.cfg.Client.Delete(ctx, cs); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting catalog source: %v", err)
+	}
+	return nil
+}
+
 func (i *Install) setup(ctx context.Context) error {
-	labels, csv, err := loadBundle(ctx, i.BundleImage)
+	// An in-cluster registry can only pull bundle images, so artifacts are always unpacked locally.
+	if registryutil.IsArtifactRef(i.BundleImage) && i.UnpackMode == UnpackModeCluster {
+		log.Infof("Bundle %s is an OCI artifact, unpacking it on the client", i.BundleImage)
+		i.UnpackMode = UnpackModeClient
+	}
+
+	switch i.UnpackMode {
+	case UnpackModeCluster:
+	case UnpackModeClient:
+		if i.IndexImageCatalogCreator.IndexImage != defaultIndexImage {
+			return fmt.Errorf("an index image cannot be set when unpacking on the client")
+		}
+	default:
+		return fmt.Errorf("unpack mode must be one of [%q, %q]", UnpackModeCluster, UnpackModeClient)
+	}
+
+	labels, bundle, err := registryutil.LoadBundle(ctx, nil, i.BundleImage)
 	if err != nil {
 		return err
 	}
+	csv := bundle.CSV
 
 	if err := i.InstallMode.CheckCompatibility(csv, i.cfg.Namespace); err != nil {
 		return err
 	}
+	if !i.SkipResourceCheck {
+		// Check the pods as the Subscription's config will configure them.
+		if err := preflight.CheckResources(ctx, i.cfg, i.OperatorInstaller.SubscriptionConfig.Apply(csv)); err != nil {
+			return fmt.Errorf("operator will not be schedulable, set --skip-resource-check to install anyway: %v", err)
+		}
+	}
 
 	i.OperatorInstaller.PackageName = labels["operators.operatorframework.io.bundle.package.v1"]
 	i.OperatorInstaller.CatalogSourceName = fmt.Sprintf("%s-catalog", i.OperatorInstaller.PackageName)
 	i.OperatorInstaller.StartingCSV = csv.Name
-	i.OperatorInstaller.Channel = strings.Split(labels["operators.operatorframework.io.bundle.channels.v1"], ",")[0]
+	channels := strings.Split(labels["operators.operatorframework.io.bundle.channels.v1"], ",")
+	if i.OperatorInstaller.Channel == "" {
+		i.OperatorInstaller.Channel = channels[0]
+	} else if !containsString(channels, i.OperatorInstaller.Channel) {
+		return fmt.Errorf("channel %q is not one of the bundle's channels %q", i.OperatorInstaller.Channel, channels)
+	}
 	i.IndexImageCatalogCreator.BundleImage = i.BundleImage
 	i.IndexImageCatalogCreator.PackageName = i.OperatorInstaller.PackageName
 	i.IndexImageCatalogCreator.InjectBundles = []string{i.BundleImage}
@@ -87,32 +179,31 @@ func (i *Install) setup(ctx context.Context) error {
 		i.IndexImageCatalogCreator.InjectBundleMode = "semver"
 	}
 
-	return nil
-}
-
-func loadBundle(ctx context.Context, bundleImage string) (registryutil.Labels, *v1alpha1.ClusterServiceVersion, error) {
-	bundlePath, err := registryutil.ExtractBundleImage(ctx, nil, bundleImage, false)
-	if err != nil {
-		return nil, nil, fmt.Errorf("pull bundle image: %v", err)
+	if i.UnpackMode == UnpackModeClient {
+		defaultChannel := labels["operators.operatorframework.io.bundle.channel.default.v1"]
+		if defaultChannel == "" {
+			defaultChannel = i.OperatorInstaller.Channel
+		}
+		cc := registry.NewConfigMapCatalogCreator(i.cfg)
+		cc.Package = &apimanifests.PackageManifest{
+			PackageName: i.OperatorInstaller.PackageName,
+			Channels: []apimanifests.PackageChannel{
+				{Name: i.OperatorInstaller.Channel, CurrentCSVName: csv.GetName()},
+			},
+			DefaultChannelName: defaultChannel,
+		}
+		cc.Bundles = []*apimanifests.Bundle{bundle}
+		i.OperatorInstaller.CatalogCreator = cc
 	}
-	defer func() {
-		_ = os.RemoveAll(bundlePath)
-	}()
 
-	labels, _, err := registryutil.FindBundleMetadata(bundlePath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("load bundle metadata: %v", err)
-	}
+	return nil
+}
 
-	relManifestsDir, ok := labels.GetManifestsDir()
-	if !ok {
-		return nil, nil, fmt.Errorf("manifests directory not defined in bundle metadata")
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
 	}
-	manifestsDir := filepath.Join(bundlePath, relManifestsDir)
-	bundle, err := apimanifests.GetBundleFromDir(manifestsDir)
-	if err != nil {
-		return nil, nil, fmt.Errorf("load bundle: %v", err)
-	}
-
-	return labels, bundle.CSV, nil
+	return false
 }
@@ -0,0 +1,287 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+// InstallsEntryLabel labels every object created for an entry of an installs file with the entry's name,
+// so the objects can be found and deleted by a cleanup of the same file.
+const InstallsEntryLabel = "operators.operatorframework.io/installs-entry"
+
+// InstallsFile lists bundles to install together, ex. a stack of operators for a test environment.
+type InstallsFile struct {
+	Installs []InstallsEntry `json:"installs"`
+}
+
+// InstallsEntry is a bundle to install into a namespace.
+type InstallsEntry struct {
+	// Name identifies the entry in logs and labels, and defaults to Namespace.
+	// Entries installing into the same namespace must be named.
+	Name string `json:"name,omitempty"`
+	// BundleImage is the bundle image or "oci://" bundle artifact to install.
+	BundleImage string `json:"bundleImage"`
+	// Namespace is the namespace to install into, which is created if it does not exist.
+	// Defaults to the namespace of the command.
+	Namespace string `json:"namespace,omitempty"`
+	// InstallMode is the install mode in the format of the --install-mode flag, ex. "SingleNamespace=ns1".
+	InstallMode string `json:"installMode,omitempty"`
+	// Channel is the channel to subscribe to, and defaults to the bundle's first channel.
+	Channel string `json:"channel,omitempty"`
+	// IndexImage is the index image the bundle is added to.
+	IndexImage string `json:"indexImage,omitempty"`
+}
+
+// ReadInstallsFile reads and validates the installs file at path, defaulting entry namespaces to namespace.
+func ReadInstallsFile(path, namespace string) (*InstallsFile, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &InstallsFile{}
+	if err := yaml.UnmarshalStrict(b, f); err != nil {
+		return nil, fmt.Errorf("error unmarshalling installs file %s: %v", path, err)
+	}
+	if len(f.Installs) == 0 {
+		return nil, fmt.Errorf("installs file %s has no installs", path)
+	}
+
+	names := map[string]struct{}{}
+	for i := range f.Installs {
+		entry := &f.Installs[i]
+		if entry.Namespace == "" {
+			entry.Namespace = namespace
+		}
+		if entry.Name == "" {
+			entry.Name = entry.Namespace
+		}
+		if entry.BundleImage == "" {
+			return nil, fmt.Errorf("installs[%d]: bundleImage is required", i)
+		}
+		if errs := validation.IsValidLabelValue(entry.Name); len(errs) != 0 {
+			return nil, fmt.Errorf("installs[%d]: invalid name %q: %s", i, entry.Name, strings.Join(errs, ", "))
+		}
+		if _, ok := names[entry.Name]; ok {
+			return nil, fmt.Errorf("installs[%d]: duplicate name %q, entries installing into the same namespace "+
+				"must have distinct names", i, entry.Name)
+		}
+		names[entry.Name] = struct{}{}
+		if entry.InstallMode != "" {
+			if err := (&operator.InstallMode{}).Set(entry.InstallMode); err != nil {
+				return nil, fmt.Errorf("installs[%d]: invalid installMode %q: %v", i, entry.InstallMode, err)
+			}
+		}
+	}
+	return f, nil
+}
+
+// Installs installs or cleans up every entry of an InstallsFile, each in its own namespace.
+type Installs struct {
+	File *InstallsFile
+	// Parallel runs entries concurrently instead of in order.
+	Parallel bool
+	// Timeout bounds the install or cleanup of each entry.
+	Timeout time.Duration
+	// WaitForLock is how long each entry waits for its namespace's lock.
+	WaitForLock time.Duration
+	// Force and ForceGracePeriod configure the Uninstall of each entry's operators on cleanup.
+	Force            bool
+	ForceGracePeriod time.Duration
+
+	cfg *operator.Configuration
+}
+
+func NewInstalls(cfg *operator.Configuration) Installs {
+	return Installs{cfg: cfg}
+}
+
+// Run installs every entry, continuing past failed entries, and returns an error naming those that failed.
+func (i Installs) Run(ctx context.Context) error {
+	return i.forEach(i.File.Installs, func(entry InstallsEntry, cfg *operator.Configuration) error {
+		if err := ensureNamespace(ctx, cfg); err != nil {
+			return err
+		}
+		inst := NewInstall(cfg)
+		inst.BundleImage = entry.BundleImage
+		inst.OperatorInstaller.Channel = entry.Channel
+		if entry.IndexImage != "" {
+			inst.IndexImage = entry.IndexImage
+		} else {
+			inst.IndexImage = defaultIndexImage
+		}
+		inst.UnpackMode = UnpackModeCluster
+		if entry.InstallMode != "" {
+			if err := inst.InstallMode.Set(entry.InstallMode); err != nil {
+				return err
+			}
+		}
+		return operator.RunWithNamespaceLock(ctx, cfg, i.WaitForLock, func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, i.Timeout)
+			defer cancel()
+			csv, err := inst.Run(ctx)
+			if err != nil {
+				return err
+			}
+			log.WithField("install", entry.Name).Infof("Installed %s", csv.GetName())
+			return nil
+		})
+	})
+}
+
+// Cleanup uninstalls every entry in reverse order, or concurrently, deleting the operator found by
+// the entry's label and the entry's namespace if it was created by Run.
+func (i Installs) Cleanup(ctx context.Context) error {
+	entries := make([]InstallsEntry, len(i.File.Installs))
+	for j, entry := range i.File.Installs {
+		entries[len(entries)-1-j] = entry
+	}
+	return i.forEach(entries, func(entry InstallsEntry, cfg *operator.Configuration) error {
+		if err := operator.RunWithNamespaceLock(ctx, cfg, i.WaitForLock, func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, i.Timeout)
+			defer cancel()
+			return i.uninstallEntry(ctx, cfg, entry)
+		}); err != nil {
+			return err
+		}
+		// The namespace lock is held in the namespace, so the namespace is deleted after releasing it.
+		return deleteEntryNamespace(ctx, cfg, entry)
+	})
+}
+
+// forEach runs f for each entry with a Configuration for the entry's namespace
+// that labels created objects with the entry's name.
+func (i Installs) forEach(entries []InstallsEntry, f func(InstallsEntry, *operator.Configuration) error) error {
+	errs := make([]error, len(entries))
+	// Configurations are loaded up front, since loading registers types with a shared scheme.
+	cfgs := make([]*operator.Configuration, len(entries))
+	for j, entry := range entries {
+		cfgs[j] = i.cfg.ForNamespace(entry.Namespace)
+		cfgs[j].Labels = map[string]string{InstallsEntryLabel: entry.Name}
+		errs[j] = cfgs[j].Load()
+	}
+	defer func() {
+		for _, cfg := range cfgs {
+			if err := cfg.Close(); err != nil {
+				log.Warn(err)
+			}
+		}
+	}()
+	run := func(j int) {
+		if errs[j] == nil {
+			errs[j] = f(entries[j], cfgs[j])
+		}
+		if errs[j] != nil {
+			log.WithField("install", entries[j].Name).Errorf("Failed: %v", errs[j])
+		}
+	}
+
+	if i.Parallel {
+		wg := sync.WaitGroup{}
+		for j := range entries {
+			wg.Add(1)
+			go func(j int) {
+				defer wg.Done()
+				run(j)
+			}(j)
+		}
+		wg.Wait()
+	} else {
+		for j := range entries {
+			run(j)
+		}
+	}
+
+	var failed []string
+	for j, err := range errs {
+		if err != nil {
+			failed = append(failed, entries[j].Name)
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("%d of %d entries failed: %s", len(failed), len(entries), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// ensureNamespace creates cfg's namespace if it does not exist. The namespace is labeled
+// by cfg's Client, so it is only deleted by a cleanup if it was created here.
+func ensureNamespace(ctx context.Context, cfg *operator.Configuration) error {
+	ns := &corev1.Namespace{}
+	err := cfg.Client.Get(ctx, types.NamespacedName{Name: cfg.Namespace}, ns)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+	ns.SetName(cfg.Namespace)
+	if err := cfg.Client.Create(ctx, ns); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating namespace %s: %v", cfg.Namespace, err)
+	}
+	return nil
+}
+
+// uninstallEntry uninstalls the operators subscribed to by entry.
+func (i Installs) uninstallEntry(ctx context.Context, cfg *operator.Configuration, entry InstallsEntry) error {
+	subs := v1alpha1.SubscriptionList{}
+	if err := cfg.Client.List(ctx, &subs, client.InNamespace(cfg.Namespace),
+		client.MatchingLabels{InstallsEntryLabel: entry.Name}); err != nil {
+		return fmt.Errorf("error listing subscriptions: %v", err)
+	}
+	for _, sub := range subs.Items {
+		u := operator.NewUninstall(cfg)
+		u.Package = sub.Spec.Package
+		u.DeleteAll = true
+		u.DeleteOperatorGroupNames = []string{operator.SDKOperatorGroupName}
+		u.Force, u.ForceGracePeriod = i.Force, i.ForceGracePeriod
+		if err := u.Run(ctx); err != nil {
+			return fmt.Errorf("error uninstalling %s: %v", sub.Spec.Package, err)
+		}
+	}
+	return nil
+}
+
+// deleteEntryNamespace deletes entry's namespace if it was created by installing entry.
+func deleteEntryNamespace(ctx context.Context, cfg *operator.Configuration, entry InstallsEntry) error {
+	ns := &corev1.Namespace{}
+	if err := cfg.Client.Get(ctx, types.NamespacedName{Name: cfg.Namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if ns.GetLabels()[InstallsEntryLabel] != entry.Name {
+		return nil
+	}
+	if err := cfg.Client.Delete(ctx, ns); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting namespace %s: %v", ns.GetName(), err)
+	}
+	log.WithField("install", entry.Name).Infof("Deleted namespace %s", ns.GetName())
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadInstallsFile", func() {
+	var dir string
+
+	read := func(content string) (*InstallsFile, error) {
+		path := filepath.Join(dir, "installs.yaml")
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return ReadInstallsFile(path, "default")
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "installs-")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("defaults namespaces and names", func() {
+		f, err := read(`installs:
+- bundleImage: quay.io/example/memcached-operator-bundle:v0.0.1
+- name: etcd
+  bundleImage: quay.io/example/etcd-operator-bundle:v0.9.4
+  namespace: storage
+  installMode: SingleNamespace=storage
+  channel: alpha
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Installs).To(Equal([]InstallsEntry{
+			{Name: "default", BundleImage: "quay.io/example/memcached-operator-bundle:v0.0.1", Namespace: "default"},
+			{Name: "etcd", BundleImage: "quay.io/example/etcd-operator-bundle:v0.9.4", Namespace: "storage",
+				InstallMode: "SingleNamespace=storage", Channel: "alpha"},
+		}))
+	})
+	It("fails when entries in the same namespace are not named", func() {
+		_, err := read(`installs:
+- bundleImage: quay.io/example/memcached-operator-bundle:v0.0.1
+- bundleImage: quay.io/example/etcd-operator-bundle:v0.9.4
+`)
+		Expect(err).To(MatchError(ContainSubstring(`duplicate name "default"`)))
+	})
+	It("fails when an entry has no bundle image", func() {
+		_, err := read("installs:\n- namespace: memcached\n")
+		Expect(err).To(MatchError(ContainSubstring("bundleImage is required")))
+	})
+	It("fails when an install mode is invalid", func() {
+		_, err := read("installs:\n- bundleImage: quay.io/example/memcached-operator-bundle:v0.0.1\n  installMode: Everywhere\n")
+		Expect(err).To(MatchError(ContainSubstring("invalid installMode")))
+	})
+	It("fails on unknown fields", func() {
+		_, err := read("installs:\n- bundle: quay.io/example/memcached-operator-bundle:v0.0.1\n")
+		Expect(err).To(HaveOccurred())
+	})
+})
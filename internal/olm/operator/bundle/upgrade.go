@@ -0,0 +1,81 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/spf13/pflag"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+// Upgrade upgrades an operator installed by Install to the operator in BundleImage, by adding BundleImage to
+// the installed operator's catalog and approving the upgrade OLM resolves from it.
+type Upgrade struct {
+	BundleImage string
+
+	*registry.IndexImageCatalogCreator
+	*registry.OperatorInstaller
+
+	cfg *operator.Configuration
+}
+
+func NewUpgrade(cfg *operator.Configuration) Upgrade {
+	u := Upgrade{
+		OperatorInstaller: registry.NewOperatorInstaller(cfg),
+		cfg:               cfg,
+	}
+	u.IndexImageCatalogCreator = registry.NewIndexImageCatalogCreator(cfg)
+	u.CatalogUpdater = u.IndexImageCatalogCreator
+	return u
+}
+
+func (u *Upgrade) BindFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&u.IgnoreOperatorCondition, "ignore-operator-condition", false, "upgrade even if the installed "+
+		"operator's OperatorCondition reports it is not upgradeable")
+	fs.Var(&u.SecurityContextConfig, "security-context-config", "security context of the registry pod, one of "+
+		"[legacy, restricted]. Detected from the cluster if unset: restricted on OpenShift or in namespaces "+
+		"enforcing the restricted Pod Security Standard, legacy otherwise")
+	fs.Var(&u.CSVHealth, "csv-health", "criterion for the CSV being installed, one of [phase, conditions, "+
+		"deployments-ready]")
+}
+
+func (u Upgrade) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+	if err := u.setup(ctx); err != nil {
+		return nil, err
+	}
+	return u.UpgradeOperator(ctx)
+}
+
+func (u *Upgrade) setup(ctx context.Context) error {
+	// The upgrade's bundle is added to a registry pod, which can only pull bundle images.
+	if registryutil.IsArtifactRef(u.BundleImage) {
+		return fmt.Errorf("bundle %s is an OCI artifact, only bundle images can be upgraded to", u.BundleImage)
+	}
+	labels, bundle, err := registryutil.LoadBundle(ctx, nil, u.BundleImage)
+	if err != nil {
+		return err
+	}
+	u.OperatorInstaller.PackageName = labels["operators.operatorframework.io.bundle.package.v1"]
+	u.OperatorInstaller.StartingCSV = bundle.CSV.GetName()
+	u.IndexImageCatalogCreator.PackageName = u.OperatorInstaller.PackageName
+	u.IndexImageCatalogCreator.BundleImage = u.BundleImage
+	return nil
+}
@@ -16,18 +16,46 @@ package operator
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
 
 	v1 "github.com/operator-framework/api/pkg/operators/v1"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/replay"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
+)
+
+const (
+	// clientQPS and clientBurst configure the rate limiter shared by all clients created from RESTConfig.
+	clientQPS   = 20
+	clientBurst = 40
+	// cacheSyncTimeout is how long a read waits for an informer to sync before falling back to the API server.
+	cacheSyncTimeout = 10 * time.Second
 )
 
+// NamespaceEnv is the environment variable of the namespace of commands run without --namespace.
+// It takes precedence over the namespace of the kubeconfig's context.
+const NamespaceEnv = "OPERATOR_SDK_NAMESPACE"
+
 type Configuration struct {
 	Namespace      string
 	KubeconfigPath string
@@ -35,7 +63,43 @@ type Configuration struct {
 	Client         client.Client
 	Scheme         *runtime.Scheme
 
+	// NamespaceSource describes where Load resolved Namespace from, for logs, ex. "set by --namespace".
+	NamespaceSource string
+
+	// Labels and Annotations are added to every object created by Client, without overwriting
+	// labels and annotations the SDK sets itself.
+	Labels      map[string]string
+	Annotations map[string]string
+	// Owner, if set, is added as an owner of every object created by Client that has no other owner,
+	// so the operator and its catalog are garbage collected when Owner is deleted. Other created objects
+	// are owned by those objects. Owner's type must be in Scheme, and Owner must be cluster-scoped
+	// or in Namespace.
+	Owner controllerutil.Object
+	// RetryPolicy configures retries of requests made by Client that fail with transient errors.
+	RetryPolicy RetryPolicy
+	// Cache is an informer cache of objects in Namespace, started by Load and stopped by Close.
+	// Informers are created the first time their type is read or watched, so only polled types are watched.
+	Cache cache.Cache
+	// Notifier notifies waits of changes to the objects they wait on, from Cache's informers, or those of
+	// another namespace's cache started the first time a wait is in that namespace. It is stopped by Close.
+	Notifier *olmclient.Notifier
+	// RecordPath, if set, is the path of a fixture Load records every request made by Client into,
+	// until Close is called, which can be replayed by a replay.Client in unit tests.
+	RecordPath string
+	// QPS and Burst, if set, configure the rate limiter shared by all clients created from RESTConfig,
+	// overriding the kubeconfig's settings and the defaults of 20 and 40.
+	QPS   float32
+	Burst int
+	// UserAgent, if set, is the User-Agent of every request, so the API server's audit logs attribute
+	// requests to the automation running the SDK.
+	UserAgent string
+
 	overrides *clientcmd.ConfigOverrides
+	// namespaceFlag is the namespace flag bound by BindFlags, if any.
+	namespaceFlag *pflag.Flag
+	// stopCache stops Cache, and recordFile is the file requests are recorded into.
+	stopCache  chan struct{}
+	recordFile *os.File
 }
 
 func (c *Configuration) BindFlags(fs *pflag.FlagSet) {
@@ -45,34 +109,123 @@ func (c *Configuration) BindFlags(fs *pflag.FlagSet) {
 	clientcmd.BindOverrideFlags(c.overrides, fs, clientcmd.ConfigOverrideFlags{
 		ContextOverrideFlags: clientcmd.ContextOverrideFlags{
 			Namespace: clientcmd.FlagInfo{
-				LongName:    "namespace",
-				ShortName:   "n",
-				Default:     "",
-				Description: "If present, namespace scope for this CLI request",
+				LongName:  "namespace",
+				ShortName: "n",
+				Default:   "",
+				Description: "Namespace scope for this CLI request. Defaults to $" + NamespaceEnv + ", then the " +
+					"namespace of the kubeconfig's context, then \"default\"",
 			},
 		},
 	})
+	c.namespaceFlag = fs.Lookup("namespace")
 	fs.StringVar(&c.KubeconfigPath, "kubeconfig", "",
 		"Path to the kubeconfig file to use for CLI requests.")
+	if c.RetryPolicy == (RetryPolicy{}) {
+		c.RetryPolicy = DefaultRetryPolicy
+	}
+	fs.DurationVar(&c.RetryPolicy.MaxElapsedTime, "api-retry-timeout", c.RetryPolicy.MaxElapsedTime,
+		"Maximum time to retry an API request that fails with a transient error, ex. throttling or a "+
+			"webhook timeout. Set to 0 to disable retries")
+	fs.Float32Var(&c.QPS, "kube-api-qps", c.QPS,
+		"Maximum queries per second sent to the API server. Defaults to the kubeconfig's setting, or 20")
+	fs.IntVar(&c.Burst, "kube-api-burst", c.Burst,
+		"Maximum burst of queries sent to the API server. Defaults to the kubeconfig's setting, or 40")
+	fs.StringVar(&c.UserAgent, "user-agent", c.UserAgent,
+		"User-Agent of requests sent to the API server, to identify them in audit logs")
+	fs.StringVar(&c.RecordPath, "record-api-interactions", c.RecordPath,
+		"Path of a file to record every API request and response to, for replay in unit tests")
+	_ = fs.MarkHidden("record-api-interactions")
+}
+
+// NamespaceFlag returns the value of the namespace flag bound by BindFlags, which may be a default
+// read from a config file.
+func (c *Configuration) NamespaceFlag() string {
+	if c.overrides == nil {
+		return ""
+	}
+	return c.overrides.Context.Namespace
+}
+
+// NamespaceFlagChanged returns true if the namespace flag was set on the command line, rather than
+// defaulted, ex. from a config file.
+func (c *Configuration) NamespaceFlagChanged() bool {
+	return c.NamespaceFlag() != "" && (c.namespaceFlag == nil || c.namespaceFlag.Changed)
+}
+
+// resolveNamespace returns the namespace of commands, and where it was set, which is in order
+// of precedence: --namespace, $OPERATOR_SDK_NAMESPACE, the default of --namespace read from a config file,
+// the kubeconfig's context, the in-cluster config, then "default". flagChanged is false if the namespace
+// of overrides is a config file default.
+func resolveNamespace(cfg clientcmd.ClientConfig, overrides *clientcmd.ConfigOverrides,
+	flagChanged bool) (string, string, error) {
+	if ns := overrides.Context.Namespace; ns != "" && flagChanged {
+		return ns, "set by --namespace", nil
+	}
+	if ns := os.Getenv(NamespaceEnv); ns != "" {
+		return ns, "set by $" + NamespaceEnv, nil
+	}
+	if ns := overrides.Context.Namespace; ns != "" {
+		return ns, "set by the --namespace default of a config file", nil
+	}
+	ns, _, err := cfg.Namespace()
+	if err != nil {
+		return "", "", err
+	}
+	if raw, err := cfg.RawConfig(); err == nil {
+		name := overrides.CurrentContext
+		if name == "" {
+			name = raw.CurrentContext
+		}
+		if ctx, ok := raw.Contexts[name]; ok && ctx.Namespace != "" {
+			return ns, fmt.Sprintf("set by kubeconfig context %q", name), nil
+		}
+	}
+	if ns != "default" {
+		return ns, "set by the in-cluster config", nil
+	}
+	return ns, "the default namespace; set --namespace, $" + NamespaceEnv +
+		", or the kubeconfig context's namespace to change it", nil
+}
+
+// ForNamespace returns an unloaded Configuration with c's client settings, labels, and annotations
+// for namespace, ex. to install operators into several namespaces in one command. Once loaded,
+// the returned Configuration must be closed.
+func (c *Configuration) ForNamespace(namespace string) *Configuration {
+	return &Configuration{
+		Namespace:      namespace,
+		KubeconfigPath: c.KubeconfigPath,
+		Labels:         c.Labels,
+		Annotations:    c.Annotations,
+		RetryPolicy:    c.RetryPolicy,
+		QPS:            c.QPS,
+		Burst:          c.Burst,
+		UserAgent:      c.UserAgent,
+		overrides:      c.overrides,
+		namespaceFlag:  c.namespaceFlag,
+	}
 }
 
+// Load creates c's clients and starts its Cache. c must be closed once it is no longer used.
+// Loading a loaded Configuration closes it first.
 func (c *Configuration) Load() error {
+	if err := c.Close(); err != nil {
+		return err
+	}
 	if c.overrides == nil {
 		c.overrides = &clientcmd.ConfigOverrides{}
 	}
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 	loadingRules.ExplicitPath = c.KubeconfigPath
-	mergedConfig, err := loadingRules.Load()
-	if err != nil {
-		return err
-	}
-	cfg := clientcmd.NewDefaultClientConfig(*mergedConfig, c.overrides)
+	// Fall back to the in-cluster config if no kubeconfig is found, ex. when run by an install Job.
+	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, c.overrides)
 	cc, err := cfg.ClientConfig()
 	if err != nil {
 		return err
 	}
+	c.configureClient(cc)
+	tracing.WrapConfig(cc)
 
-	ns, _, err := cfg.Namespace()
+	ns, source, err := resolveNamespace(cfg, c.overrides, c.NamespaceFlagChanged())
 	if err != nil {
 		return err
 	}
@@ -94,21 +247,234 @@ func (c *Configuration) Load() error {
 		return err
 	}
 
-	c.Scheme = sch
-	c.Client = &operatorClient{cl}
+	if c.RetryPolicy == (RetryPolicy{}) {
+		c.RetryPolicy = DefaultRetryPolicy
+	}
 	if c.Namespace == "" {
-		c.Namespace = ns
+		c.Namespace, c.NamespaceSource = ns, source
+	}
+	if c.Cache, err = cache.New(cc, cache.Options{Scheme: sch, Namespace: c.Namespace}); err != nil {
+		return err
+	}
+	stop := make(chan struct{})
+	c.stopCache = stop
+	go func() {
+		_ = c.Cache.Start(stop)
+	}()
+	c.Notifier = olmclient.NewNotifier(c.Namespace, c.Cache, func(namespace string) (cache.Informers, error) {
+		nc, err := cache.New(cc, cache.Options{Scheme: sch, Namespace: namespace})
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			_ = nc.Start(stop)
+		}()
+		return nc, nil
+	})
+
+	c.Scheme = sch
+	c.Client = &operatorClient{Client: cl, cfg: c}
+	if c.RecordPath != "" {
+		f, err := os.Create(c.RecordPath)
+		if err != nil {
+			return fmt.Errorf("error creating API interaction fixture: %v", err)
+		}
+		// Interactions are written as they complete, so the file is left open until Close.
+		c.recordFile = f
+		c.Client = replay.NewRecorder(c.Client, sch, f)
 	}
 	c.RESTConfig = cc
 
 	return nil
 }
 
+// Close stops c's Cache and closes the file requests are recorded into. Close is a no-op
+// if c is not loaded. Client reads from the API server once c is closed.
+func (c *Configuration) Close() error {
+	if c.stopCache != nil {
+		close(c.stopCache)
+		c.stopCache = nil
+		c.Cache = nil
+		c.Notifier = nil
+	}
+	if c.recordFile != nil {
+		f := c.recordFile
+		c.recordFile = nil
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("error closing API interaction fixture: %v", err)
+		}
+	}
+	return nil
+}
+
+// configureClient sets cc's rate limits and User-Agent from c.
+func (c *Configuration) configureClient(cc *rest.Config) {
+	if c.QPS != 0 {
+		cc.QPS = c.QPS
+	}
+	if c.Burst != 0 {
+		cc.Burst = c.Burst
+	}
+	if c.UserAgent != "" {
+		cc.UserAgent = c.UserAgent
+	}
+	// Share one rate limiter between every client, so concurrent waits do not multiply API server load.
+	if cc.RateLimiter == nil || c.QPS != 0 || c.Burst != 0 {
+		if cc.QPS == 0 {
+			cc.QPS = clientQPS
+		}
+		if cc.Burst == 0 {
+			cc.Burst = clientBurst
+		}
+		cc.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(cc.QPS, cc.Burst)
+	}
+}
+
+// operatorClient is the client shared by all operations run with a Configuration.
 type operatorClient struct {
 	client.Client
+
+	// uncached are cachedTypes whose informers failed to sync, which are read from the API server.
+	uncached sync.Map
+	cfg      *Configuration
+}
+
+// cachedTypes are polled while waiting for an operator to install, so are read from an informer cache.
+var cachedTypes = []runtime.Object{
+	&v1alpha1.Subscription{},
+	&v1alpha1.InstallPlan{},
+	&v1alpha1.ClusterServiceVersion{},
+}
+
+func (c *operatorClient) useCache(key client.ObjectKey, obj runtime.Object) bool {
+	if c.cfg.Cache == nil || key.Namespace != c.cfg.Namespace {
+		return false
+	}
+	if _, uncached := c.uncached.Load(reflect.TypeOf(obj)); uncached {
+		return false
+	}
+	for _, t := range cachedTypes {
+		if reflect.TypeOf(t) == reflect.TypeOf(obj) {
+			return true
+		}
+	}
+	return false
 }
 
+// Create is retried on transient errors, unless obj is named by the server with GenerateName.
+// An object that already exists when a create is retried was created by an earlier attempt.
 func (c *operatorClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if err := c.cfg.setCreatedObjectMetadata(obj); err != nil {
+		return err
+	}
 	opts = append(opts, client.FieldOwner("operator-sdk"))
-	return c.Client.Create(ctx, obj, opts...)
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	// A create that failed with a transient error, ex. a timeout, may have succeeded, so retrying
+	// the create of an object named by the server could create a duplicate.
+	if accessor.GetName() == "" && accessor.GetGenerateName() != "" {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	attempts := 0
+	return c.cfg.RetryPolicy.Do(ctx, func() error {
+		attempts++
+		err := c.Client.Create(ctx, obj, opts...)
+		if attempts > 1 && apierrors.IsAlreadyExists(err) {
+			// An earlier attempt created obj before failing.
+			log.Debugf("%T %s was created by a failed attempt", obj, accessor.GetName())
+			key := client.ObjectKey{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}
+			return c.Client.Get(ctx, key, obj)
+		}
+		return err
+	})
+}
+
+// Get reads cachedTypes in the Configuration's namespace from its Cache, and other objects or those
+// not yet in the cache, ex. just created objects, from the API server. Objects read from the cache
+// may be stale, so updates of them must be retried on conflict.
+func (c *operatorClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if c.useCache(key, obj) {
+		cacheCtx, cancel := context.WithTimeout(ctx, cacheSyncTimeout)
+		err := c.cfg.Cache.Get(cacheCtx, key, obj)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if !apierrors.IsNotFound(err) {
+			// The cache could not sync, ex. the user cannot watch this type, so stop reading it from the cache.
+			log.Debugf("Reading %T from the API server: %v", obj, err)
+			c.uncached.Store(reflect.TypeOf(obj), struct{}{})
+		}
+	}
+	return c.cfg.RetryPolicy.Do(ctx, func() error {
+		return c.Client.Get(ctx, key, obj)
+	})
+}
+
+func (c *operatorClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	return c.cfg.RetryPolicy.Do(ctx, func() error {
+		return c.Client.List(ctx, list, opts...)
+	})
+}
+
+// Update is retried on transient errors only. Conflicts must be retried by callers
+// with the latest version of obj, ex. with RetryPolicy.OnConflict.
+func (c *operatorClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return c.cfg.RetryPolicy.Do(ctx, func() error {
+		return c.Client.Update(ctx, obj, opts...)
+	})
+}
+
+func (c *operatorClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.cfg.RetryPolicy.Do(ctx, func() error {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	})
+}
+
+func (c *operatorClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	return c.cfg.RetryPolicy.Do(ctx, func() error {
+		return c.Client.Delete(ctx, obj, opts...)
+	})
+}
+
+// setCreatedObjectMetadata adds c's Labels, Annotations, and Owner to obj.
+func (c *Configuration) setCreatedObjectMetadata(obj runtime.Object) error {
+	if len(c.Labels) == 0 && len(c.Annotations) == 0 && c.Owner == nil {
+		return nil
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	accessor.SetLabels(mergeMissing(accessor.GetLabels(), c.Labels))
+	accessor.SetAnnotations(mergeMissing(accessor.GetAnnotations(), c.Annotations))
+	// The namespace lock is not owned, since it would be garbage collected if Owner were deleted
+	// while the lock is held, letting another process take it.
+	if _, isLease := obj.(*coordinationv1.Lease); isLease {
+		return nil
+	}
+	if c.Owner != nil && len(accessor.GetOwnerReferences()) == 0 {
+		if err := controllerutil.SetOwnerReference(c.Owner, accessor, c.Scheme); err != nil {
+			return fmt.Errorf("error setting owner of %s: %v", accessor.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// mergeMissing adds keys in src that are not in dst to dst, and returns dst.
+func mergeMissing(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+	return dst
 }
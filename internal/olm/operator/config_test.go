@@ -0,0 +1,342 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ = Describe("Configuration", func() {
+	Describe("Client.Create", func() {
+		var (
+			cfg   *Configuration
+			ctx   context.Context
+			owner *corev1.ConfigMap
+		)
+
+		BeforeEach(func() {
+			sch := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+			Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+			cfg = &Configuration{Namespace: "default", Scheme: sch}
+			cfg.Client = &operatorClient{Client: fake.NewFakeClientWithScheme(sch), cfg: cfg}
+			ctx = context.TODO()
+
+			owner = &corev1.ConfigMap{}
+			owner.SetName("parent")
+			owner.SetNamespace("default")
+			owner.SetUID("parent-uid")
+		})
+
+		It("adds labels, annotations, and an owner to created objects", func() {
+			cfg.Labels = map[string]string{"app": "memcached", "owner": "parent"}
+			cfg.Annotations = map[string]string{"example.com/managed-by": "parent"}
+			cfg.Owner = owner
+
+			sub := &v1alpha1.Subscription{}
+			sub.SetName("memcached-operator")
+			sub.SetNamespace("default")
+			sub.SetLabels(map[string]string{"owner": "operator-sdk"})
+			Expect(cfg.Client.Create(ctx, sub)).To(Succeed())
+
+			Expect(cfg.Client.Get(ctx, types.NamespacedName{Name: "memcached-operator", Namespace: "default"}, sub)).To(Succeed())
+			Expect(sub.GetLabels()).To(Equal(map[string]string{"app": "memcached", "owner": "operator-sdk"}))
+			Expect(sub.GetAnnotations()).To(Equal(map[string]string{"example.com/managed-by": "parent"}))
+			Expect(sub.GetOwnerReferences()).To(HaveLen(1))
+			Expect(sub.GetOwnerReferences()[0].Name).To(Equal("parent"))
+			Expect(sub.GetOwnerReferences()[0].Kind).To(Equal("ConfigMap"))
+		})
+		It("does not add an owner to objects with another owner", func() {
+			cfg.Owner = owner
+
+			cm := &corev1.ConfigMap{}
+			cm.SetName("registry")
+			cm.SetNamespace("default")
+			cs := &v1alpha1.CatalogSource{}
+			cs.SetName("memcached-operator-catalog")
+			cs.SetNamespace("default")
+			cs.SetUID("catalog-uid")
+			Expect(controllerutil.SetOwnerReference(cs, cm, cfg.Scheme)).To(Succeed())
+			Expect(cfg.Client.Create(ctx, cm)).To(Succeed())
+
+			Expect(cm.GetOwnerReferences()).To(HaveLen(1))
+			Expect(cm.GetOwnerReferences()[0].Name).To(Equal("memcached-operator-catalog"))
+		})
+		It("does not add an owner to the namespace lock", func() {
+			cfg.Owner = owner
+
+			Expect(NewNamespaceLock(cfg).Acquire(ctx, 0)).To(Succeed())
+
+			lease := &coordinationv1.Lease{}
+			Expect(cfg.Client.Get(ctx, types.NamespacedName{Name: NamespaceLockName, Namespace: "default"}, lease)).To(Succeed())
+			Expect(lease.GetOwnerReferences()).To(BeEmpty())
+		})
+		It("fails to create objects in another namespace than a namespaced owner", func() {
+			cfg.Owner = owner
+
+			sub := &v1alpha1.Subscription{}
+			sub.SetName("memcached-operator")
+			sub.SetNamespace("other")
+			Expect(cfg.Client.Create(ctx, sub)).NotTo(Succeed())
+		})
+		It("succeeds if a retried create finds the object created by a failed attempt", func() {
+			stub := &timeoutCreateClient{Client: fake.NewFakeClientWithScheme(cfg.Scheme)}
+			cfg.Client = &operatorClient{Client: stub, cfg: cfg}
+			cfg.RetryPolicy = RetryPolicy{InitialInterval: time.Millisecond, MaxElapsedTime: time.Second}
+
+			cm := &corev1.ConfigMap{}
+			cm.SetName("registry")
+			cm.SetNamespace("default")
+			Expect(cfg.Client.Create(ctx, cm)).To(Succeed())
+			Expect(stub.creates).To(Equal(2))
+			Expect(cm.GetResourceVersion()).NotTo(BeEmpty())
+		})
+		It("does not retry creates of objects named by the server", func() {
+			stub := &timeoutCreateClient{Client: fake.NewFakeClientWithScheme(cfg.Scheme)}
+			cfg.Client = &operatorClient{Client: stub, cfg: cfg}
+			cfg.RetryPolicy = RetryPolicy{InitialInterval: time.Millisecond, MaxElapsedTime: time.Second}
+
+			cm := &corev1.ConfigMap{}
+			cm.SetGenerateName("registry-")
+			cm.SetNamespace("default")
+			Expect(apierrors.IsTimeout(cfg.Client.Create(ctx, cm))).To(BeTrue())
+			Expect(stub.creates).To(Equal(1))
+		})
+	})
+
+	Describe("Client.Get", func() {
+		var (
+			cfg   *Configuration
+			ctx   context.Context
+			c     *operatorClient
+			stub  *stubCache
+			key   = types.NamespacedName{Name: "memcached-operator", Namespace: "default"}
+			subGR = v1alpha1.Resource("subscriptions")
+		)
+
+		BeforeEach(func() {
+			sch := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+			Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+			sub := &v1alpha1.Subscription{}
+			sub.SetName(key.Name)
+			sub.SetNamespace(key.Namespace)
+			stub = &stubCache{}
+			cfg = &Configuration{Namespace: "default", Scheme: sch, Cache: stub}
+			c = &operatorClient{Client: fake.NewFakeClientWithScheme(sch, sub), cfg: cfg}
+			ctx = context.TODO()
+		})
+
+		It("reads cached types from the cache", func() {
+			Expect(c.Get(ctx, key, &v1alpha1.Subscription{})).To(Succeed())
+			Expect(stub.gets).To(Equal(1))
+		})
+		It("reads other types and namespaces from the API server", func() {
+			Expect(c.Get(ctx, key, &corev1.ConfigMap{})).NotTo(Succeed())
+			Expect(c.Get(ctx, types.NamespacedName{Name: key.Name, Namespace: "other"}, &v1alpha1.Subscription{})).NotTo(Succeed())
+			Expect(stub.gets).To(Equal(0))
+		})
+		It("reads objects missing from the cache from the API server", func() {
+			stub.err = apierrors.NewNotFound(subGR, key.Name)
+			sub := &v1alpha1.Subscription{}
+			Expect(c.Get(ctx, key, sub)).To(Succeed())
+			Expect(sub.GetName()).To(Equal(key.Name))
+		})
+		It("stops reading a type from a cache that fails to sync", func() {
+			stub.err = errors.New("failed waiting for informer to sync")
+			Expect(c.Get(ctx, key, &v1alpha1.Subscription{})).To(Succeed())
+			Expect(c.Get(ctx, key, &v1alpha1.Subscription{})).To(Succeed())
+			Expect(stub.gets).To(Equal(1))
+		})
+	})
+
+	Describe("configureClient", func() {
+		It("uses the default rate limits if unset", func() {
+			cc := &rest.Config{}
+			(&Configuration{}).configureClient(cc)
+			Expect(cc.QPS).To(BeEquivalentTo(clientQPS))
+			Expect(cc.Burst).To(Equal(clientBurst))
+			Expect(cc.RateLimiter).NotTo(BeNil())
+			Expect(cc.UserAgent).To(BeEmpty())
+		})
+		It("keeps the kubeconfig's rate limits if unset", func() {
+			cc := &rest.Config{QPS: 5, Burst: 10}
+			(&Configuration{}).configureClient(cc)
+			Expect(cc.QPS).To(BeEquivalentTo(5))
+			Expect(cc.Burst).To(Equal(10))
+		})
+		It("overrides the kubeconfig's rate limits and User-Agent", func() {
+			limiter := flowcontrol.NewFakeAlwaysRateLimiter()
+			cc := &rest.Config{QPS: 5, Burst: 10, RateLimiter: limiter, UserAgent: "kubectl"}
+			(&Configuration{QPS: 100, Burst: 200, UserAgent: "release-pipeline"}).configureClient(cc)
+			Expect(cc.QPS).To(BeEquivalentTo(100))
+			Expect(cc.Burst).To(Equal(200))
+			Expect(cc.RateLimiter).NotTo(BeIdenticalTo(limiter))
+			Expect(cc.RateLimiter.QPS()).To(BeEquivalentTo(100))
+			Expect(cc.UserAgent).To(Equal("release-pipeline"))
+		})
+	})
+
+	Describe("Close", func() {
+		It("is a no-op if the configuration is not loaded", func() {
+			Expect((&Configuration{}).Close()).To(Succeed())
+		})
+		It("stops the cache and closes the recorded fixture", func() {
+			f, err := ioutil.TempFile("", "fixture")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Remove(f.Name())
+			stop := make(chan struct{})
+			cfg := &Configuration{Cache: &stubCache{}, stopCache: stop, recordFile: f}
+
+			Expect(cfg.Close()).To(Succeed())
+			Expect(stop).To(BeClosed())
+			Expect(cfg.Cache).To(BeNil())
+			_, err = f.Write([]byte("{}"))
+			Expect(err).To(HaveOccurred())
+			Expect(cfg.Close()).To(Succeed())
+		})
+	})
+
+	Describe("resolveNamespace", func() {
+		var (
+			raw         clientcmdapi.Config
+			overrides   *clientcmd.ConfigOverrides
+			flagChanged bool
+		)
+
+		resolve := func() (string, string) {
+			ns, source, err := resolveNamespace(clientcmd.NewDefaultClientConfig(raw, overrides), overrides,
+				flagChanged)
+			Expect(err).NotTo(HaveOccurred())
+			return ns, source
+		}
+
+		BeforeEach(func() {
+			raw = clientcmdapi.Config{
+				Clusters:       map[string]*clientcmdapi.Cluster{"kind": {Server: "https://127.0.0.1:6443"}},
+				AuthInfos:      map[string]*clientcmdapi.AuthInfo{"kind": {}},
+				Contexts:       map[string]*clientcmdapi.Context{"kind": {Cluster: "kind", AuthInfo: "kind"}},
+				CurrentContext: "kind",
+			}
+			overrides = &clientcmd.ConfigOverrides{}
+			flagChanged = true
+			Expect(os.Unsetenv(NamespaceEnv)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.Unsetenv(NamespaceEnv)).To(Succeed())
+		})
+
+		It("uses \"default\" if no namespace is set", func() {
+			ns, source := resolve()
+			Expect(ns).To(Equal("default"))
+			Expect(source).To(HavePrefix("the default namespace"))
+		})
+		It("uses the context's namespace", func() {
+			raw.Contexts["kind"].Namespace = "memcached"
+			ns, source := resolve()
+			Expect(ns).To(Equal("memcached"))
+			Expect(source).To(Equal(`set by kubeconfig context "kind"`))
+		})
+		It("uses the namespace of the context set by --context", func() {
+			raw.Contexts["other"] = &clientcmdapi.Context{Cluster: "kind", AuthInfo: "kind", Namespace: "other"}
+			overrides.CurrentContext = "other"
+			ns, source := resolve()
+			Expect(ns).To(Equal("other"))
+			Expect(source).To(Equal(`set by kubeconfig context "other"`))
+		})
+		It("prefers the environment variable to the context", func() {
+			raw.Contexts["kind"].Namespace = "memcached"
+			Expect(os.Setenv(NamespaceEnv, "env")).To(Succeed())
+			ns, source := resolve()
+			Expect(ns).To(Equal("env"))
+			Expect(source).To(Equal("set by $" + NamespaceEnv))
+		})
+		It("prefers --namespace to the environment variable", func() {
+			Expect(os.Setenv(NamespaceEnv, "env")).To(Succeed())
+			overrides.Context.Namespace = "flag"
+			ns, source := resolve()
+			Expect(ns).To(Equal("flag"))
+			Expect(source).To(Equal("set by --namespace"))
+		})
+		It("prefers the environment variable to a config file's --namespace default", func() {
+			Expect(os.Setenv(NamespaceEnv, "env")).To(Succeed())
+			overrides.Context.Namespace, flagChanged = "config", false
+			ns, source := resolve()
+			Expect(ns).To(Equal("env"))
+			Expect(source).To(Equal("set by $" + NamespaceEnv))
+		})
+		It("prefers a config file's --namespace default to the context", func() {
+			raw.Contexts["kind"].Namespace = "memcached"
+			overrides.Context.Namespace, flagChanged = "config", false
+			ns, source := resolve()
+			Expect(ns).To(Equal("config"))
+			Expect(source).To(Equal("set by the --namespace default of a config file"))
+		})
+	})
+})
+
+// timeoutCreateClient is a client whose first create succeeds, but returns a timeout error.
+type timeoutCreateClient struct {
+	client.Client
+
+	creates int
+}
+
+func (c *timeoutCreateClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	c.creates++
+	if c.creates > 1 {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	// Like a request that timed out, the object is created, but obj is not updated.
+	if err := c.Client.Create(ctx, obj.DeepCopyObject(), opts...); err != nil {
+		return err
+	}
+	return apierrors.NewTimeoutError("request timed out", 0)
+}
+
+// stubCache is a cache whose Get returns err.
+type stubCache struct {
+	cache.Cache
+
+	err  error
+	gets int
+}
+
+func (c *stubCache) Get(context.Context, client.ObjectKey, runtime.Object) error {
+	c.gets++
+	return c.err
+}
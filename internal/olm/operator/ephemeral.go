@@ -0,0 +1,171 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EphemeralNamespaceLabel labels namespaces created for installs with --ephemeral-namespace.
+	EphemeralNamespaceLabel = "operators.operatorframework.io/ephemeral"
+	// EphemeralNamespaceExpiryAnnotation is the RFC 3339 time after which an ephemeral namespace
+	// may be deleted by 'cleanup --expired-namespaces'.
+	EphemeralNamespaceExpiryAnnotation = "operators.operatorframework.io/expires-at"
+
+	ephemeralNamespaceFlag       = "ephemeral-namespace"
+	ephemeralNamespacePrefix     = "operator-sdk-sandbox-"
+	defaultEphemeralNamespaceTTL = 24 * time.Hour
+)
+
+// EphemeralNamespace configures installs into a uniquely named namespace that expires after TTL.
+// It is a flag value, so it can be set without a TTL to use the default.
+type EphemeralNamespace struct {
+	TTL     time.Duration
+	enabled bool
+}
+
+var _ pflag.Value = &EphemeralNamespace{}
+
+// BindFlags binds --ephemeral-namespace[=ttl] to e.
+func (e *EphemeralNamespace) BindFlags(fs *pflag.FlagSet) {
+	fs.Var(e, ephemeralNamespaceFlag, fmt.Sprintf("install into a new, uniquely named namespace that may "+
+		"be deleted by 'cleanup --expired-namespaces' after the given time-to-live (default %s)",
+		defaultEphemeralNamespaceTTL))
+	fs.Lookup(ephemeralNamespaceFlag).NoOptDefVal = defaultEphemeralNamespaceTTL.String()
+}
+
+func (e *EphemeralNamespace) String() string {
+	if !e.enabled {
+		return ""
+	}
+	return e.TTL.String()
+}
+
+func (e *EphemeralNamespace) Set(s string) error {
+	ttl, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return fmt.Errorf("time-to-live must be positive")
+	}
+	e.TTL, e.enabled = ttl, true
+	return nil
+}
+
+func (e *EphemeralNamespace) Type() string {
+	return "ttl"
+}
+
+// Enabled returns true if --ephemeral-namespace was set.
+func (e EphemeralNamespace) Enabled() bool {
+	return e.enabled
+}
+
+// Create creates an ephemeral namespace, and reloads cfg to use it. cfg must be loaded, and its
+// namespace must not have been set with --namespace. A namespace defaulted from a config file is ignored.
+func (e EphemeralNamespace) Create(ctx context.Context, cfg *Configuration) error {
+	if cfg.NamespaceFlagChanged() {
+		return fmt.Errorf("--%s cannot be set with --namespace", ephemeralNamespaceFlag)
+	}
+	expiry := time.Now().Add(e.TTL).UTC()
+	ns := &corev1.Namespace{}
+	ns.SetGenerateName(ephemeralNamespacePrefix)
+	ns.SetLabels(map[string]string{EphemeralNamespaceLabel: "true"})
+	ns.SetAnnotations(map[string]string{EphemeralNamespaceExpiryAnnotation: expiry.Format(time.RFC3339)})
+	if err := cfg.Client.Create(ctx, ns); err != nil {
+		return fmt.Errorf("error creating ephemeral namespace: %v", err)
+	}
+	log.Infof("Created namespace %s, which expires at %s", ns.GetName(), expiry.Format(time.RFC3339))
+
+	// Reload so the client's cache watches the new namespace.
+	cfg.Namespace, cfg.NamespaceSource = ns.GetName(), "created by --"+ephemeralNamespaceFlag
+	return cfg.Load()
+}
+
+// DeleteExpiredNamespaces uninstalls every operator in each ephemeral namespace that expired before now,
+// then deletes the namespace. Namespaces that fail to be cleaned up are logged and skipped.
+func DeleteExpiredNamespaces(ctx context.Context, cfg *Configuration, now time.Time) error {
+	namespaces := corev1.NamespaceList{}
+	if err := cfg.Client.List(ctx, &namespaces, client.HasLabels{EphemeralNamespaceLabel}); err != nil {
+		return fmt.Errorf("error listing ephemeral namespaces: %v", err)
+	}
+	failed := 0
+	for i := range namespaces.Items {
+		ns := &namespaces.Items[i]
+		logger := log.WithField("namespace", ns.GetName())
+		expiry, err := time.Parse(time.RFC3339, ns.GetAnnotations()[EphemeralNamespaceExpiryAnnotation])
+		if err != nil {
+			logger.Warnf("Skipping namespace with invalid %s annotation: %v", EphemeralNamespaceExpiryAnnotation, err)
+			continue
+		}
+		if expiry.After(now) || ns.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if err := deleteEphemeralNamespace(ctx, cfg, ns); err != nil {
+			logger.Errorf("Failed to clean up expired namespace: %v", err)
+			failed++
+			continue
+		}
+		logger.Infof("Deleted namespace, which expired at %s", expiry.Format(time.RFC3339))
+	}
+	if failed != 0 {
+		return fmt.Errorf("failed to clean up %d expired namespaces", failed)
+	}
+	return nil
+}
+
+// deleteEphemeralNamespace uninstalls the operators subscribed to in ns, so their cluster-scoped
+// objects like CRDs are deleted, then deletes ns.
+func deleteEphemeralNamespace(ctx context.Context, cfg *Configuration, ns *corev1.Namespace) error {
+	nsCfg := cfg.ForNamespace(ns.GetName())
+	if err := nsCfg.Load(); err != nil {
+		return err
+	}
+	defer func() {
+		if err := nsCfg.Close(); err != nil {
+			log.Warn(err)
+		}
+	}()
+	subs := v1alpha1.SubscriptionList{}
+	if err := nsCfg.Client.List(ctx, &subs, client.InNamespace(ns.GetName())); err != nil {
+		return fmt.Errorf("error listing subscriptions: %v", err)
+	}
+	for _, sub := range subs.Items {
+		if sub.Spec == nil {
+			continue
+		}
+		u := NewUninstall(nsCfg)
+		u.Package = sub.Spec.Package
+		u.DeleteAll = true
+		if err := u.Run(ctx); err != nil {
+			return fmt.Errorf("error uninstalling %s: %v", sub.Spec.Package, err)
+		}
+	}
+	if err := cfg.Client.Delete(ctx, ns); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting namespace: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("EphemeralNamespace", func() {
+	var (
+		e  *EphemeralNamespace
+		fs *pflag.FlagSet
+	)
+
+	BeforeEach(func() {
+		e = &EphemeralNamespace{}
+		fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+		e.BindFlags(fs)
+	})
+
+	It("is disabled by default", func() {
+		Expect(fs.Parse([]string{})).To(Succeed())
+		Expect(e.Enabled()).To(BeFalse())
+	})
+	It("uses the default time-to-live if set without a value", func() {
+		Expect(fs.Parse([]string{"--ephemeral-namespace"})).To(Succeed())
+		Expect(e.Enabled()).To(BeTrue())
+		Expect(e.TTL).To(Equal(defaultEphemeralNamespaceTTL))
+	})
+	It("parses a time-to-live", func() {
+		Expect(fs.Parse([]string{"--ephemeral-namespace=2h"})).To(Succeed())
+		Expect(e.Enabled()).To(BeTrue())
+		Expect(e.TTL).To(Equal(2 * time.Hour))
+	})
+	It("rejects invalid time-to-lives", func() {
+		Expect(fs.Parse([]string{"--ephemeral-namespace=forever"})).NotTo(Succeed())
+		Expect(fs.Parse([]string{"--ephemeral-namespace=-1h"})).NotTo(Succeed())
+	})
+	It("cannot be created with --namespace", func() {
+		cfg := &Configuration{}
+		cfg.BindFlags(fs)
+		Expect(fs.Parse([]string{"--ephemeral-namespace", "--namespace", "foo"})).To(Succeed())
+		Expect(e.Create(context.TODO(), cfg)).To(MatchError("--ephemeral-namespace cannot be set with --namespace"))
+	})
+})
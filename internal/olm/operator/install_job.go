@@ -0,0 +1,253 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// InstallStatusPackageLabel labels an install status ConfigMap with the package being installed.
+	InstallStatusPackageLabel = "operators.operatorframework.io/install-status-package"
+
+	installStatusPhaseKey      = "phase"
+	installStatusBundleKey     = "bundle"
+	installStatusPackageKey    = "package"
+	installStatusCSVKey        = "csv"
+	installStatusSubKey        = "subscription"
+	installStatusMessageKey    = "message"
+	installStatusUpdateTimeKey = "lastUpdateTime"
+
+	// installJobBackoffLimit is the number of times a failed install Job is retried.
+	installJobBackoffLimit = 2
+)
+
+type InstallPhase string
+
+const (
+	InstallPhaseRunning   InstallPhase = "Running"
+	InstallPhaseSucceeded InstallPhase = "Succeeded"
+	InstallPhaseFailed    InstallPhase = "Failed"
+	// InstallPhaseDetached is the phase of an install whose resources were created without waiting for OLM
+	// to install the operator, which 'run wait' resumes.
+	InstallPhaseDetached InstallPhase = "Detached"
+)
+
+// InstallStatus is the status of an install run in-cluster, ex. by an install Job,
+// written to a ConfigMap in the install namespace so the CLI can read it later.
+type InstallStatus struct {
+	ConfigMap string       `json:"configMap"`
+	Phase     InstallPhase `json:"phase"`
+	Bundle    string       `json:"bundle,omitempty"`
+	Package   string       `json:"package,omitempty"`
+	CSV       string       `json:"csv,omitempty"`
+	// Subscription is the name of the install's Subscription, set once the install is detached.
+	Subscription   string `json:"subscription,omitempty"`
+	Message        string `json:"message,omitempty"`
+	LastUpdateTime string `json:"lastUpdateTime,omitempty"`
+}
+
+// WriteInstallStatus creates or updates the ConfigMap named s.ConfigMap in the namespace with s.
+func (c *Configuration) WriteInstallStatus(ctx context.Context, s InstallStatus) error {
+	s.LastUpdateTime = time.Now().UTC().Format(time.RFC3339)
+	data := map[string]string{
+		installStatusPhaseKey:      string(s.Phase),
+		installStatusBundleKey:     s.Bundle,
+		installStatusPackageKey:    s.Package,
+		installStatusCSVKey:        s.CSV,
+		installStatusSubKey:        s.Subscription,
+		installStatusMessageKey:    s.Message,
+		installStatusUpdateTimeKey: s.LastUpdateTime,
+	}
+	key := types.NamespacedName{Namespace: c.Namespace, Name: s.ConfigMap}
+	err := c.RetryPolicy.OnConflict(ctx, func() error {
+		cm := &corev1.ConfigMap{}
+		if err := c.Client.Get(ctx, key, cm); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			cm.SetName(key.Name)
+			cm.SetNamespace(key.Namespace)
+			setInstallStatusData(cm, s.Package, data)
+			return c.Client.Create(ctx, cm)
+		}
+		setInstallStatusData(cm, s.Package, data)
+		return c.Client.Update(ctx, cm)
+	})
+	if err != nil {
+		return fmt.Errorf("error writing install status to config map %q: %v", s.ConfigMap, err)
+	}
+	return nil
+}
+
+func setInstallStatusData(cm *corev1.ConfigMap, pkg string, data map[string]string) {
+	cm.Data = data
+	if pkg != "" {
+		labels := cm.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[InstallStatusPackageLabel] = pkg
+		cm.SetLabels(labels)
+	}
+}
+
+// ListInstallStatuses returns the statuses of installs of pkg in the namespace.
+func (c *Configuration) ListInstallStatuses(ctx context.Context, pkg string) (statuses []InstallStatus, err error) {
+	cms := &corev1.ConfigMapList{}
+	if err := c.Client.List(ctx, cms, client.InNamespace(c.Namespace),
+		client.MatchingLabels{InstallStatusPackageLabel: pkg}); err != nil {
+		return nil, fmt.Errorf("list install status config maps: %v", err)
+	}
+	for _, cm := range cms.Items {
+		statuses = append(statuses, installStatusFromConfigMap(cm))
+	}
+	return statuses, nil
+}
+
+// ReadInstallStatus returns the status of the install written to the ConfigMap name in the namespace.
+func (c *Configuration) ReadInstallStatus(ctx context.Context, name string) (*InstallStatus, error) {
+	cm := corev1.ConfigMap{}
+	if err := c.Client.Get(ctx, types.NamespacedName{Namespace: c.Namespace, Name: name}, &cm); err != nil {
+		return nil, fmt.Errorf("error reading install status from config map %q: %v", name, err)
+	}
+	s := installStatusFromConfigMap(cm)
+	return &s, nil
+}
+
+func installStatusFromConfigMap(cm corev1.ConfigMap) InstallStatus {
+	return InstallStatus{
+		ConfigMap:      cm.GetName(),
+		Phase:          InstallPhase(cm.Data[installStatusPhaseKey]),
+		Bundle:         cm.Data[installStatusBundleKey],
+		Package:        cm.Data[installStatusPackageKey],
+		CSV:            cm.Data[installStatusCSVKey],
+		Subscription:   cm.Data[installStatusSubKey],
+		Message:        cm.Data[installStatusMessageKey],
+		LastUpdateTime: cm.Data[installStatusUpdateTimeKey],
+	}
+}
+
+// NewRunID returns a unique name for the status ConfigMap of a detached install, which identifies it.
+func NewRunID() string {
+	return "operator-sdk-run-" + rand.String(5)
+}
+
+// InstallJob configures the manifests of a Job that runs an operator-sdk install command in-cluster,
+// so installs can be driven by GitOps tools like ArgoCD and Flux.
+type InstallJob struct {
+	// Name is the name of the Job, its ServiceAccount, Role, and RoleBinding, and its status ConfigMap.
+	Name      string
+	Namespace string
+	// Image is an operator-sdk image.
+	Image string
+	// Args are the arguments of the operator-sdk command, ex. "run bundle <bundle-image>".
+	// The Job's namespace and status ConfigMap flags are appended to Args.
+	Args []string
+}
+
+// installJobRules are the permissions required to install an operator in a namespace.
+var installJobRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{v1alpha1.GroupName},
+		Resources: []string{"catalogsources", "subscriptions", "installplans", "clusterserviceversions", "operatorgroups"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods", "services", "configmaps", "serviceaccounts"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods/log"},
+		Verbs:     []string{"get"},
+	},
+	{
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{batchv1.GroupName},
+		Resources: []string{"jobs"},
+		Verbs:     []string{"get", "list"},
+	},
+	{
+		APIGroups: []string{coordinationv1.GroupName},
+		Resources: []string{"leases"},
+		Verbs:     []string{"get", "create", "update", "delete"},
+	},
+}
+
+// Manifests returns the Job and the ServiceAccount, Role, and RoleBinding it runs with.
+func (j InstallJob) Manifests() []runtime.Object {
+	meta := metav1.ObjectMeta{
+		Name:      j.Name,
+		Namespace: j.Namespace,
+		Labels:    map[string]string{"app.kubernetes.io/managed-by": "operator-sdk"},
+	}
+
+	sa := &corev1.ServiceAccount{ObjectMeta: meta}
+	sa.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ServiceAccount"))
+
+	role := &rbacv1.Role{ObjectMeta: meta, Rules: installJobRules}
+	role.SetGroupVersionKind(rbacv1.SchemeGroupVersion.WithKind("Role"))
+
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: meta,
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: j.Name},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: j.Name, Namespace: j.Namespace}},
+	}
+	binding.SetGroupVersionKind(rbacv1.SchemeGroupVersion.WithKind("RoleBinding"))
+
+	backoffLimit := int32(installJobBackoffLimit)
+	args := append(append([]string{}, j.Args...), "--namespace", j.Namespace, "--status-configmap", j.Name)
+	job := &batchv1.Job{
+		ObjectMeta: meta,
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: meta.Labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: j.Name,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:  "install",
+						Image: j.Image,
+						Args:  args,
+					}},
+				},
+			},
+		},
+	}
+	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+
+	return []runtime.Object{sa, role, binding, job}
+}
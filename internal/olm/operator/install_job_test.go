@@ -0,0 +1,111 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("InstallJob", func() {
+	It("runs the install command with a ServiceAccount bound to a namespaced Role", func() {
+		j := InstallJob{
+			Name:      "memcached-install",
+			Namespace: "operators",
+			Image:     "quay.io/operator-framework/operator-sdk:v1.0.0",
+			Args:      []string{"run", "bundle", "quay.io/example/memcached-operator-bundle:v0.0.1"},
+		}
+		objs := j.Manifests()
+		Expect(objs).To(HaveLen(4))
+
+		binding, ok := objs[2].(*rbacv1.RoleBinding)
+		Expect(ok).To(BeTrue())
+		Expect(binding.RoleRef.Kind).To(Equal("Role"))
+		Expect(binding.Subjects[0].Name).To(Equal("memcached-install"))
+
+		job, ok := objs[3].(*batchv1.Job)
+		Expect(ok).To(BeTrue())
+		Expect(job.GetNamespace()).To(Equal("operators"))
+		Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal("memcached-install"))
+		Expect(job.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{
+			"run", "bundle", "quay.io/example/memcached-operator-bundle:v0.0.1",
+			"--namespace", "operators", "--status-configmap", "memcached-install",
+		}))
+		Expect(job.GroupVersionKind()).To(Equal(batchv1.SchemeGroupVersion.WithKind("Job")))
+	})
+})
+
+var _ = Describe("InstallStatus", func() {
+	var (
+		cfg *Configuration
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		cfg = &Configuration{Namespace: "default", Scheme: sch}
+		cfg.Client = &operatorClient{Client: fake.NewFakeClientWithScheme(sch), cfg: cfg}
+		ctx = context.TODO()
+	})
+
+	It("writes statuses that can be listed by package", func() {
+		status := InstallStatus{ConfigMap: "memcached-install", Phase: InstallPhaseRunning}
+		Expect(cfg.WriteInstallStatus(ctx, status)).To(Succeed())
+		statuses, err := cfg.ListInstallStatuses(ctx, "memcached-operator")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(BeEmpty())
+
+		status.Phase = InstallPhaseSucceeded
+		status.Package = "memcached-operator"
+		status.CSV = "memcached-operator.v0.0.1"
+		Expect(cfg.WriteInstallStatus(ctx, status)).To(Succeed())
+		statuses, err = cfg.ListInstallStatuses(ctx, "memcached-operator")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(HaveLen(1))
+		Expect(statuses[0].Phase).To(Equal(InstallPhaseSucceeded))
+		Expect(statuses[0].CSV).To(Equal("memcached-operator.v0.0.1"))
+		Expect(statuses[0].LastUpdateTime).NotTo(BeEmpty())
+	})
+
+	It("reads the status of a detached install by its run ID", func() {
+		runID := NewRunID()
+		Expect(runID).To(HavePrefix("operator-sdk-run-"))
+		Expect(cfg.WriteInstallStatus(ctx, InstallStatus{
+			ConfigMap:    runID,
+			Phase:        InstallPhaseDetached,
+			Package:      "memcached-operator",
+			CSV:          "memcached-operator.v0.0.1",
+			Subscription: "memcached-operator-v0-0-1-sub",
+		})).To(Succeed())
+
+		status, err := cfg.ReadInstallStatus(ctx, runID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.ConfigMap).To(Equal(runID))
+		Expect(status.Phase).To(Equal(InstallPhaseDetached))
+		Expect(status.Subscription).To(Equal("memcached-operator-v0-0-1-sub"))
+		Expect(status.CSV).To(Equal("memcached-operator.v0.0.1"))
+
+		_, err = cfg.ReadInstallStatus(ctx, "operator-sdk-run-none")
+		Expect(err).To(MatchError(ContainSubstring(`error reading install status from config map "operator-sdk-run-none"`)))
+	})
+})
@@ -0,0 +1,124 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interactive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+type resourceStatus struct {
+	kind, name, status string
+}
+
+// updateStatuses gets the status of OLM resources and operator pods in the namespace.
+func (u *UI) updateStatuses(ctx context.Context) {
+	statuses, err := u.listStatuses(ctx)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if err != nil {
+		// Keep the last statuses so a transient error doesn't clear the screen.
+		u.statusErr = err
+		return
+	}
+	u.statuses, u.statusErr = statuses, nil
+}
+
+func (u *UI) listStatuses(ctx context.Context) (statuses []resourceStatus, err error) {
+	inNamespace := client.InNamespace(u.cfg.Namespace)
+
+	catSrcs := &v1alpha1.CatalogSourceList{}
+	if err := u.cfg.Client.List(ctx, catSrcs, inNamespace); err != nil {
+		return nil, fmt.Errorf("error listing catalog sources: %v", err)
+	}
+	for _, cs := range catSrcs.Items {
+		state := ""
+		if cs.Status.GRPCConnectionState != nil {
+			state = cs.Status.GRPCConnectionState.LastObservedState
+		}
+		statuses = append(statuses, resourceStatus{v1alpha1.CatalogSourceKind, cs.GetName(), state})
+	}
+
+	subs := &v1alpha1.SubscriptionList{}
+	if err := u.cfg.Client.List(ctx, subs, inNamespace); err != nil {
+		return nil, fmt.Errorf("error listing subscriptions: %v", err)
+	}
+	for _, sub := range subs.Items {
+		statuses = append(statuses, resourceStatus{v1alpha1.SubscriptionKind, sub.GetName(), string(sub.Status.State)})
+	}
+
+	ips := &v1alpha1.InstallPlanList{}
+	if err := u.cfg.Client.List(ctx, ips, inNamespace); err != nil {
+		return nil, fmt.Errorf("error listing install plans: %v", err)
+	}
+	for _, ip := range ips.Items {
+		statuses = append(statuses, resourceStatus{v1alpha1.InstallPlanKind, ip.GetName(), string(ip.Status.Phase)})
+	}
+
+	csvs := &v1alpha1.ClusterServiceVersionList{}
+	if err := u.cfg.Client.List(ctx, csvs, inNamespace); err != nil {
+		return nil, fmt.Errorf("error listing cluster service versions: %v", err)
+	}
+	for _, csv := range csvs.Items {
+		status := string(csv.Status.Phase)
+		if csv.Status.Message != "" {
+			status = fmt.Sprintf("%s: %s", status, csv.Status.Message)
+		}
+		statuses = append(statuses, resourceStatus{v1alpha1.ClusterServiceVersionKind, csv.GetName(), status})
+	}
+
+	deps, err := operator.OperatorDeployments(ctx, u.cfg, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, dep := range deps {
+		status := fmt.Sprintf("%d/%d ready", dep.Status.ReadyReplicas, dep.Status.Replicas)
+		statuses = append(statuses, resourceStatus{"Deployment", dep.GetName(), status})
+	}
+
+	pods, err := operator.OperatorPods(ctx, u.cfg, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		statuses = append(statuses, resourceStatus{"Pod", pod.GetName(), podStatus(pod)})
+	}
+	return statuses, nil
+}
+
+// podStatus returns pod's phase, or the reason one of its containers is waiting or terminated, ex. CrashLoopBackOff.
+func podStatus(pod corev1.Pod) string {
+	for _, cs := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			return cs.State.Waiting.Reason
+		}
+		if cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0 {
+			return cs.State.Terminated.Reason
+		}
+	}
+	return string(pod.Status.Phase)
+}
+
+func (u *UI) addLog(line string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.logs.add(line)
+}
@@ -0,0 +1,276 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package interactive provides a terminal UI for installs and cleanups run repeatedly
+// while debugging an operator.
+package interactive
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
+)
+
+const (
+	defaultRefreshInterval = time.Second
+	// maxLogLines is the number of operator pod log lines shown.
+	maxLogLines = 20
+	clearScreen = "\033[H\033[2J"
+)
+
+// UI shows the phases of an install or cleanup as they are logged, the status of OLM resources
+// and operator pods in the namespace, and streamed operator pod logs. If the operation fails,
+// the user chooses to retry or abort it.
+type UI struct {
+	// Title describes the operation, ex. "Running bundle quay.io/example/memcached-operator-bundle:v0.0.1".
+	Title string
+	// BeforeRetry, if set, is run before each retry, ex. to clean up a partial install.
+	BeforeRetry func(context.Context) error
+	// RefreshInterval is the time between screen refreshes.
+	RefreshInterval time.Duration
+
+	In  io.Reader
+	Out io.Writer
+
+	cfg *operator.Configuration
+
+	mu        sync.Mutex
+	started   time.Time
+	phases    []phase
+	statuses  []resourceStatus
+	statusErr error
+	logs      lineBuffer
+}
+
+// phase is a message logged by the operation.
+type phase struct {
+	at    time.Time
+	level log.Level
+	msg   string
+}
+
+func New(cfg *operator.Configuration, title string) *UI {
+	return &UI{
+		Title:           title,
+		RefreshInterval: defaultRefreshInterval,
+		In:              os.Stdin,
+		Out:             os.Stdout,
+		cfg:             cfg,
+	}
+}
+
+// IsTerminal returns true if stdin and stdout are terminals, which the UI requires.
+func IsTerminal() bool {
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := f.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Run runs attempt until it succeeds or, after it fails, the user chooses to abort.
+// The error of the last attempt is returned.
+func (u *UI) Run(ctx context.Context, attempt func(context.Context) error) error {
+	in := bufio.NewReader(u.In)
+	for n := 1; ; n++ {
+		retrying := n > 1
+		err := u.runAttempt(ctx, func(ctx context.Context) error {
+			if retrying && u.BeforeRetry != nil {
+				if err := u.BeforeRetry(ctx); err != nil {
+					return fmt.Errorf("error preparing retry: %v", err)
+				}
+			}
+			return attempt(ctx)
+		})
+		if err == nil || !u.promptRetry(in) {
+			return err
+		}
+	}
+}
+
+// runAttempt runs f, refreshing the screen and following operator pod logs until it returns.
+func (u *UI) runAttempt(ctx context.Context, f func(context.Context) error) error {
+	u.mu.Lock()
+	u.started = time.Now()
+	u.phases, u.statuses, u.statusErr = nil, nil, nil
+	u.logs = lineBuffer{max: maxLogLines}
+	u.mu.Unlock()
+
+	restoreLogger := u.captureLogs()
+	defer restoreLogger()
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		f := operator.NewLogFollower(u.cfg)
+		f.RefreshInterval, f.Output = u.RefreshInterval, u.addLog
+		f.Run(watchCtx)
+	}()
+	go func() {
+		defer wg.Done()
+		u.refresh(watchCtx)
+	}()
+
+	err := f(ctx)
+	cancel()
+	wg.Wait()
+
+	statusCtx, statusCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer statusCancel()
+	u.updateStatuses(statusCtx)
+	u.render(true, err)
+	return err
+}
+
+// captureLogs sends messages logged by the standard logger to u instead of its output,
+// returning a function that restores the logger.
+func (u *UI) captureLogs() (restore func()) {
+	logger := log.StandardLogger()
+	out, hooks := logger.Out, logger.ReplaceHooks(make(log.LevelHooks))
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHook(u)
+	return func() {
+		logger.ReplaceHooks(hooks)
+		logger.SetOutput(out)
+	}
+}
+
+// Levels implements logrus.Hook.
+func (u *UI) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire implements logrus.Hook by adding e as a phase.
+func (u *UI) Fire(e *log.Entry) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	msg := strings.TrimSpace(e.Message)
+	// Include the resource a structured log is about, since fields are not shown.
+	if name, ok := e.Data[logutil.NameKey]; ok {
+		msg = fmt.Sprintf("%s %v", msg, name)
+	}
+	if p, ok := e.Data[logutil.PhaseKey]; ok {
+		msg = fmt.Sprintf("%s (%v)", msg, p)
+	}
+	u.phases = append(u.phases, phase{at: e.Time, level: e.Level, msg: msg})
+	return nil
+}
+
+func (u *UI) refresh(ctx context.Context) {
+	ticker := time.NewTicker(u.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		u.updateStatuses(ctx)
+		u.render(false, nil)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// render redraws the screen. If done is true, the result of the operation, err, is shown.
+func (u *UI) render(done bool, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	b := &strings.Builder{}
+	b.WriteString(clearScreen)
+	fmt.Fprintf(b, "%s (%s)\n\nPhases:\n", u.Title, time.Since(u.started).Round(time.Second))
+	for i, p := range u.phases {
+		marker := " "
+		switch {
+		case p.level <= log.ErrorLevel:
+			marker = "x"
+		case p.level == log.WarnLevel:
+			marker = "!"
+		case i == len(u.phases)-1 && !done:
+			marker = ">"
+		}
+		fmt.Fprintf(b, "  %s %s %s\n", marker, p.at.Format("15:04:05"), p.msg)
+	}
+
+	b.WriteString("\nResources:\n")
+	if u.statusErr != nil {
+		fmt.Fprintf(b, "  error getting resource statuses: %v\n", u.statusErr)
+	}
+	tw := tabwriter.NewWriter(b, 0, 4, 2, ' ', 0)
+	for _, s := range u.statuses {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", s.kind, s.name, s.status)
+	}
+	_ = tw.Flush()
+
+	b.WriteString("\nOperator logs:\n")
+	for _, line := range u.logs.lines {
+		fmt.Fprintf(b, "  %s\n", line)
+	}
+
+	if done {
+		if err != nil {
+			fmt.Fprintf(b, "\nFailed: %v\n", err)
+		} else {
+			b.WriteString("\nSucceeded\n")
+		}
+	}
+	fmt.Fprint(u.Out, b.String())
+}
+
+// promptRetry asks the user whether to retry a failed attempt, returning false if they abort
+// or in cannot be read.
+func (u *UI) promptRetry(in *bufio.Reader) bool {
+	for {
+		fmt.Fprint(u.Out, "[r]etry or [a]bort? ")
+		line, err := in.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "r", "retry":
+			return true
+		case "a", "abort":
+			return false
+		}
+		if err != nil {
+			fmt.Fprintln(u.Out)
+			return false
+		}
+	}
+}
+
+// lineBuffer holds the last max lines added to it.
+type lineBuffer struct {
+	lines []string
+	max   int
+}
+
+func (b *lineBuffer) add(line string) {
+	b.lines = append(b.lines, line)
+	if len(b.lines) > b.max {
+		b.lines = b.lines[len(b.lines)-b.max:]
+	}
+}
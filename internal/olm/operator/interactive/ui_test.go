@@ -0,0 +1,123 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interactive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("UI", func() {
+	var (
+		u   *UI
+		out *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		cfg := &operator.Configuration{Namespace: "default", Scheme: sch, Client: fake.NewFakeClientWithScheme(sch)}
+
+		out = &bytes.Buffer{}
+		u = New(cfg, "Running bundle")
+		u.RefreshInterval = 10 * time.Millisecond
+		u.Out = out
+	})
+
+	Describe("Run", func() {
+		It("shows logged phases and the result", func() {
+			u.In = strings.NewReader("")
+			Expect(u.Run(context.TODO(), func(context.Context) error {
+				log.Info("Created CatalogSource: memcached-operator-catalog")
+				return nil
+			})).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("Created CatalogSource: memcached-operator-catalog"))
+			Expect(out.String()).To(ContainSubstring("Succeeded"))
+		})
+		It("retries a failed attempt after running BeforeRetry", func() {
+			u.In = strings.NewReader("x\nr\n")
+			attempts, cleanups := 0, 0
+			u.BeforeRetry = func(context.Context) error {
+				cleanups++
+				return nil
+			}
+			Expect(u.Run(context.TODO(), func(context.Context) error {
+				attempts++
+				if attempts == 1 {
+					return errors.New("install plan failed")
+				}
+				return nil
+			})).To(Succeed())
+			Expect(attempts).To(Equal(2))
+			Expect(cleanups).To(Equal(1))
+			Expect(out.String()).To(ContainSubstring("Failed: install plan failed"))
+		})
+		It("returns the error of an aborted attempt", func() {
+			u.In = strings.NewReader("a\n")
+			err := u.Run(context.TODO(), func(context.Context) error {
+				return errors.New("install plan failed")
+			})
+			Expect(err).To(MatchError("install plan failed"))
+		})
+		It("aborts when input ends", func() {
+			u.In = strings.NewReader("")
+			err := u.Run(context.TODO(), func(context.Context) error {
+				return errors.New("install plan failed")
+			})
+			Expect(err).To(MatchError("install plan failed"))
+		})
+	})
+
+	Describe("podStatus", func() {
+		It("returns the reason a container is waiting", func() {
+			pod := corev1.Pod{}
+			pod.Status.Phase = corev1.PodRunning
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}}
+			Expect(podStatus(pod)).To(Equal("CrashLoopBackOff"))
+		})
+		It("returns the pod's phase", func() {
+			pod := corev1.Pod{}
+			pod.Status.Phase = corev1.PodRunning
+			Expect(podStatus(pod)).To(Equal("Running"))
+		})
+	})
+
+	Describe("lineBuffer", func() {
+		It("keeps the last lines", func() {
+			b := lineBuffer{max: 2}
+			for _, line := range []string{"a", "b", "c"} {
+				b.add(line)
+			}
+			Expect(b.lines).To(Equal([]string{"b", "c"}))
+		})
+	})
+})
@@ -0,0 +1,215 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
+)
+
+// defaultLocalContainer is the name of the manager container in projects scaffolded by the SDK.
+const defaultLocalContainer = "manager"
+
+// LocalManager prepares the manager of an operator installed by OLM to run locally: it scales the
+// manager's Deployment to zero through its CSV, so OLM keeps managing everything else, and returns
+// the environment and arguments the manager's container runs with.
+type LocalManager struct {
+	// Deployment is the name of the CSV deployment whose manager runs locally. It may be unset if
+	// the CSV has one deployment.
+	Deployment string
+	// Container is the name of the manager's container. If unset, the container named "manager",
+	// or the deployment's only container, is used.
+	Container string
+
+	cfg *Configuration
+}
+
+func NewLocalManager(cfg *Configuration) *LocalManager {
+	return &LocalManager{cfg: cfg}
+}
+
+// LocalCommand is the environment and arguments of a manager container, to run the manager locally with.
+type LocalCommand struct {
+	// Env are "NAME=value" pairs, with values referenced from the pod, ConfigMaps, and Secrets resolved.
+	Env  []string
+	Args []string
+}
+
+// ScaleDown sets the replicas of the manager's deployment in csv to zero, then waits for OLM to scale
+// the Deployment down. OLM reconciles deployments from their specs in the CSV, so scaling the Deployment
+// directly would be reverted. It returns the scaled down Deployment.
+func (m LocalManager) ScaleDown(ctx context.Context, csv *v1alpha1.ClusterServiceVersion) (*appsv1.Deployment, error) {
+	key := types.NamespacedName{Namespace: csv.GetNamespace(), Name: csv.GetName()}
+	var name string
+	if err := m.cfg.RetryPolicy.OnConflict(ctx, func() (err error) {
+		if err := m.cfg.Client.Get(ctx, key, csv); err != nil {
+			return err
+		}
+		ds, err := m.deploymentSpec(csv)
+		if err != nil {
+			return err
+		}
+		name = ds.Name
+		replicas := int32(0)
+		ds.Spec.Replicas = &replicas
+		return m.cfg.Client.Update(ctx, csv)
+	}); err != nil {
+		return nil, fmt.Errorf("error scaling down CSV deployment: %v", err)
+	}
+
+	dep := &appsv1.Deployment{}
+	depKey := types.NamespacedName{Namespace: key.Namespace, Name: name}
+	scaledDown := func() (bool, error) {
+		if err := m.cfg.Client.Get(ctx, depKey, dep); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return dep.Spec.Replicas != nil && *dep.Spec.Replicas == 0 && dep.Status.Replicas == 0, nil
+	}
+	if err := wait.PollImmediateUntil(time.Second, scaledDown, ctx.Done()); err != nil {
+		return nil, fmt.Errorf("error waiting for deployment %s to scale down: %v", name, err)
+	}
+	logutil.WithObject(dep).Info("Scaled down Deployment")
+	return dep, nil
+}
+
+// deploymentSpec returns the CSV deployment spec named m.Deployment, or csv's only deployment spec.
+func (m LocalManager) deploymentSpec(csv *v1alpha1.ClusterServiceVersion) (*v1alpha1.StrategyDeploymentSpec, error) {
+	specs := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+	if m.Deployment == "" {
+		if len(specs) != 1 {
+			return nil, fmt.Errorf("CSV %s has %d deployments, set the deployment to run locally",
+				csv.GetName(), len(specs))
+		}
+		return &specs[0], nil
+	}
+	for i := range specs {
+		if specs[i].Name == m.Deployment {
+			return &specs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("CSV %s has no deployment %q", csv.GetName(), m.Deployment)
+}
+
+// Command returns the environment and arguments of the manager's container in dep.
+func (m LocalManager) Command(ctx context.Context, dep *appsv1.Deployment) (*LocalCommand, error) {
+	container, err := m.container(dep)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &LocalCommand{Args: container.Args}
+	for _, env := range container.Env {
+		value, ok, err := m.envValue(ctx, dep, env)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			cmd.Env = append(cmd.Env, env.Name+"="+value)
+		}
+	}
+	if len(container.EnvFrom) != 0 {
+		log.Warnf("Environment variables of container %q set from ConfigMaps or Secrets with envFrom are not set",
+			container.Name)
+	}
+	return cmd, nil
+}
+
+// container returns the container named m.Container, "manager", or dep's only container.
+func (m LocalManager) container(dep *appsv1.Deployment) (*corev1.Container, error) {
+	containers := dep.Spec.Template.Spec.Containers
+	name := m.Container
+	if name == "" {
+		if len(containers) == 1 {
+			return &containers[0], nil
+		}
+		name = defaultLocalContainer
+	}
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("deployment %s has no container %q, set the container to run locally", dep.GetName(), name)
+}
+
+// fieldPathRe matches the label and annotation field paths of the downward API, ex. metadata.annotations['key'].
+var fieldPathRe = regexp.MustCompile(`^metadata\.(labels|annotations)\['(.+)'\]$`)
+
+// envValue returns the value of env in dep's pods, and false if it cannot be resolved outside of a pod.
+func (m LocalManager) envValue(ctx context.Context, dep *appsv1.Deployment, env corev1.EnvVar) (string, bool, error) {
+	from := env.ValueFrom
+	switch {
+	case from == nil:
+		return env.Value, true, nil
+	case from.FieldRef != nil:
+		tmpl := dep.Spec.Template
+		switch path := from.FieldRef.FieldPath; path {
+		case "metadata.namespace":
+			return dep.GetNamespace(), true, nil
+		case "spec.serviceAccountName":
+			return tmpl.Spec.ServiceAccountName, true, nil
+		default:
+			if match := fieldPathRe.FindStringSubmatch(path); match != nil {
+				if match[1] == "labels" {
+					return tmpl.GetLabels()[match[2]], true, nil
+				}
+				return tmpl.GetAnnotations()[match[2]], true, nil
+			}
+		}
+	case from.ConfigMapKeyRef != nil:
+		ref := from.ConfigMapKeyRef
+		cm := &corev1.ConfigMap{}
+		key := types.NamespacedName{Namespace: dep.GetNamespace(), Name: ref.Name}
+		if err := m.cfg.Client.Get(ctx, key, cm); err != nil {
+			return m.optionalRef(env, ref.Optional, err)
+		}
+		value, ok := cm.Data[ref.Key]
+		return value, ok, nil
+	case from.SecretKeyRef != nil:
+		ref := from.SecretKeyRef
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: dep.GetNamespace(), Name: ref.Name}
+		if err := m.cfg.Client.Get(ctx, key, secret); err != nil {
+			return m.optionalRef(env, ref.Optional, err)
+		}
+		value, ok := secret.Data[ref.Key]
+		return string(value), ok, nil
+	}
+	log.Warnf("Environment variable %s cannot be resolved outside of a pod, so it is not set", env.Name)
+	return "", false, nil
+}
+
+// optionalRef returns an error getting the object env refers to unless the reference is optional and
+// the object does not exist.
+func (m LocalManager) optionalRef(env corev1.EnvVar, optional *bool, err error) (string, bool, error) {
+	if apierrors.IsNotFound(err) && optional != nil && *optional {
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("error getting value of environment variable %s: %v", env.Name, err)
+}
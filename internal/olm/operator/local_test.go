@@ -0,0 +1,143 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("LocalManager", func() {
+	var (
+		m   *LocalManager
+		cfg *Configuration
+		csv *v1alpha1.ClusterServiceVersion
+		dep *appsv1.Deployment
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		ctx = context.TODO()
+
+		dep = &appsv1.Deployment{}
+		dep.SetName("memcached-operator-controller-manager")
+		dep.SetNamespace("default")
+		dep.Spec.Template.SetAnnotations(map[string]string{"olm.targetNamespaces": "ns1,ns2"})
+		dep.Spec.Template.Spec.ServiceAccountName = "controller-manager"
+		dep.Spec.Template.Spec.Containers = []corev1.Container{
+			{Name: "kube-rbac-proxy"},
+			{
+				Name: "manager",
+				Args: []string{"--leader-elect"},
+				Env: []corev1.EnvVar{
+					{Name: "LOG_LEVEL", Value: "debug"},
+					{Name: "WATCH_NAMESPACE", ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations['olm.targetNamespaces']"},
+					}},
+					{Name: "POD_NAMESPACE", ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+					}},
+					{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+					}},
+					{Name: "RELATED_IMAGE", ValueFrom: &corev1.EnvVarSource{
+						ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "images"},
+							Key:                  "memcached",
+						},
+					}},
+				},
+			},
+		}
+
+		replicas := int32(1)
+		csv = &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.SetNamespace("default")
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{{
+			Name: dep.GetName(),
+			Spec: appsv1.DeploymentSpec{Replicas: &replicas, Template: dep.Spec.Template},
+		}}
+
+		images := &corev1.ConfigMap{Data: map[string]string{"memcached": "memcached:1.6"}}
+		images.SetName("images")
+		images.SetNamespace("default")
+
+		cfg = &Configuration{Namespace: "default", Scheme: sch,
+			Client: fake.NewFakeClientWithScheme(sch, csv.DeepCopy(), images)}
+		m = NewLocalManager(cfg)
+	})
+
+	Describe("ScaleDown", func() {
+		It("sets the CSV deployment's replicas to zero", func() {
+			replicas := int32(0)
+			dep.Spec.Replicas = &replicas
+			Expect(cfg.Client.Create(ctx, dep)).To(Succeed())
+
+			got, err := m.ScaleDown(ctx, csv)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got.GetName()).To(Equal(dep.GetName()))
+
+			updated := &v1alpha1.ClusterServiceVersion{}
+			Expect(cfg.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: csv.GetName()},
+				updated)).To(Succeed())
+			Expect(*updated.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Replicas).To(BeZero())
+		})
+		It("fails if the deployment is not in the CSV", func() {
+			m.Deployment = "other"
+			_, err := m.ScaleDown(ctx, csv)
+			Expect(err).To(MatchError(ContainSubstring(`CSV memcached-operator.v0.0.1 has no deployment "other"`)))
+		})
+	})
+
+	Describe("Command", func() {
+		It("resolves the manager container's environment", func() {
+			cmd, err := m.Command(ctx, dep)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmd.Args).To(Equal([]string{"--leader-elect"}))
+			Expect(cmd.Env).To(Equal([]string{
+				"LOG_LEVEL=debug",
+				"WATCH_NAMESPACE=ns1,ns2",
+				"POD_NAMESPACE=default",
+				"RELATED_IMAGE=memcached:1.6",
+			}))
+		})
+		It("fails if a referenced ConfigMap does not exist", func() {
+			cm := &corev1.ConfigMap{}
+			cm.SetName("images")
+			cm.SetNamespace("default")
+			Expect(cfg.Client.Delete(ctx, cm)).To(Succeed())
+			_, err := m.Command(ctx, dep)
+			Expect(err).To(MatchError(ContainSubstring("error getting value of environment variable RELATED_IMAGE")))
+		})
+		It("fails if the container does not exist", func() {
+			m.Container = "other"
+			_, err := m.Command(ctx, dep)
+			Expect(err).To(MatchError(ContainSubstring(`has no container "other"`)))
+		})
+	})
+})
@@ -0,0 +1,296 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
+)
+
+const (
+	// NamespaceLockName is the name of the Lease that serializes SDK installs and cleanups in a namespace.
+	NamespaceLockName = "operator-sdk-lock"
+
+	namespaceLockDuration = 60 * time.Second
+	namespaceLockRetry    = 2 * time.Second
+)
+
+// namespaceLockRenewInterval is how often a held lock is renewed.
+var namespaceLockRenewInterval = namespaceLockDuration / 3
+
+// NamespaceLock is a Lease held while an operator is installed or cleaned up, since concurrent
+// installs and cleanups in a namespace race on its OperatorGroup and CatalogSources.
+// The Lease is renewed while held, so a lock whose holder exited without releasing it expires.
+// If the Lease is lost, because it expired and was taken over or was deleted, Lost is closed.
+// Users who may not manage Leases in the namespace run without the lock.
+type NamespaceLock struct {
+	cfg      *Configuration
+	identity string
+	// held is true if l holds its Lease, and false if it was acquired without one.
+	held bool
+
+	stopRenew chan struct{}
+	renewWG   sync.WaitGroup
+	lost      chan struct{}
+}
+
+func NewNamespaceLock(cfg *Configuration) *NamespaceLock {
+	hostname, _ := os.Hostname()
+	return &NamespaceLock{
+		cfg:      cfg,
+		identity: fmt.Sprintf("%s_%s", hostname, uuid.NewUUID()),
+		lost:     make(chan struct{}),
+	}
+}
+
+// RunWithNamespaceLock runs f while holding cfg.Namespace's lock, waiting up to waitTimeout to acquire it.
+// The context passed to f is cancelled if the lock is lost while f runs, so f does not keep changing
+// the namespace while another process holds its lock.
+func RunWithNamespaceLock(ctx context.Context, cfg *Configuration, waitTimeout time.Duration,
+	f func(context.Context) error) error {
+	l := NewNamespaceLock(cfg)
+	if err := l.Acquire(ctx, waitTimeout); err != nil {
+		return err
+	}
+	defer func() {
+		if err := l.Release(context.Background()); err != nil {
+			logutil.WithResource("Lease", l.key()).Warnf("Failed to release lock: %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-l.Lost():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	err := f(ctx)
+	select {
+	case <-l.Lost():
+		return fmt.Errorf("lost the lock of namespace %q, so the operation was cancelled: %v", cfg.Namespace, err)
+	default:
+	}
+	return err
+}
+
+// Lost returns a channel that is closed once l's Lease is lost to another process, or deleted,
+// while l holds it.
+func (l *NamespaceLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Acquire blocks until l is acquired, waiting up to waitTimeout if another process holds it.
+// If the user may not manage Leases in the namespace, Acquire logs a warning and returns
+// without holding the lock.
+func (l *NamespaceLock) Acquire(ctx context.Context, waitTimeout time.Duration) error {
+	var holder string
+	tryAcquire := func() (acquired bool, err error) {
+		acquired, holder, err = l.tryAcquire(ctx)
+		if err == nil && !acquired {
+			logutil.WithResource("Lease", l.key()).WithField("holder", holder).Info("Waiting for lock")
+		}
+		return acquired, err
+	}
+
+	acquired, err := tryAcquire()
+	if err == errLockForbidden {
+		logutil.WithResource("Lease", l.key()).Warn("Running without a lock, since Leases may not be " +
+			"managed in the namespace; concurrent installs and cleanups in the namespace may conflict")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !acquired && waitTimeout > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, waitTimeout)
+		defer cancel()
+		err = wait.PollImmediateUntil(namespaceLockRetry, tryAcquire, waitCtx.Done())
+		if err != nil && err != wait.ErrWaitTimeout {
+			return err
+		}
+		acquired = err == nil
+	}
+	if !acquired {
+		return fmt.Errorf("namespace %q is locked by %s; wait for it with --wait-for-lock", l.cfg.Namespace, holder)
+	}
+
+	l.held = true
+	l.stopRenew = make(chan struct{})
+	l.renewWG.Add(1)
+	go l.renew()
+	return nil
+}
+
+// errLockForbidden is returned by tryAcquire if the user may not get, create, or update l's Lease.
+var errLockForbidden = errors.New("forbidden to manage lock")
+
+// tryAcquire creates l's Lease, or takes it over if it has expired. If another
+// process holds the Lease, tryAcquire returns its identity.
+func (l *NamespaceLock) tryAcquire(ctx context.Context) (acquired bool, holder string, err error) {
+	now := metav1.NewMicroTime(time.Now())
+	durationSeconds := int32(namespaceLockDuration.Seconds())
+
+	lease := &coordinationv1.Lease{}
+	if err := l.cfg.Client.Get(ctx, l.key(), lease); err != nil {
+		if apierrors.IsForbidden(err) {
+			return false, "", errLockForbidden
+		}
+		if !apierrors.IsNotFound(err) {
+			return false, "", fmt.Errorf("error getting lock: %v", err)
+		}
+		lease.SetName(NamespaceLockName)
+		lease.SetNamespace(l.cfg.Namespace)
+		lease.Spec = coordinationv1.LeaseSpec{
+			HolderIdentity:       &l.identity,
+			LeaseDurationSeconds: &durationSeconds,
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		}
+		if err := l.cfg.Client.Create(ctx, lease); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return false, "another process", nil
+			}
+			if apierrors.IsForbidden(err) {
+				return false, "", errLockForbidden
+			}
+			return false, "", fmt.Errorf("error creating lock: %v", err)
+		}
+		return true, "", nil
+	}
+
+	if lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != "" && !isLeaseExpired(lease, now.Time) {
+		return false, *lease.Spec.HolderIdentity, nil
+	}
+	lease.Spec.HolderIdentity = &l.identity
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+	if err := l.cfg.Client.Update(ctx, lease); err != nil {
+		if apierrors.IsConflict(err) {
+			return false, "another process", nil
+		}
+		if apierrors.IsForbidden(err) {
+			return false, "", errLockForbidden
+		}
+		return false, "", fmt.Errorf("error taking over expired lock: %v", err)
+	}
+	return true, "", nil
+}
+
+// renew periodically renews l's Lease until Release is called, or until the Lease is lost
+// to another process, closing l.lost.
+func (l *NamespaceLock) renew() {
+	defer l.renewWG.Done()
+	ticker := time.NewTicker(namespaceLockRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stopRenew:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), namespaceLockRenewInterval)
+			lost, err := l.renewOnce(ctx)
+			cancel()
+			if err != nil {
+				logutil.WithResource("Lease", l.key()).Warnf("Failed to renew lock: %v", err)
+			}
+			if lost {
+				close(l.lost)
+				return
+			}
+		}
+	}
+}
+
+// renewOnce renews l's Lease, if it is still held by l. If the Lease was deleted or taken over
+// by another process after expiring, renewOnce returns true, since l no longer holds it.
+func (l *NamespaceLock) renewOnce(ctx context.Context) (lost bool, err error) {
+	lease := &coordinationv1.Lease{}
+	if err := l.cfg.Client.Get(ctx, l.key(), lease); err != nil {
+		if apierrors.IsNotFound(err) {
+			logutil.WithResource("Lease", l.key()).Warn("Lock was deleted, no longer renewing it")
+			return true, nil
+		}
+		return false, err
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.identity {
+		holder := ""
+		if lease.Spec.HolderIdentity != nil {
+			holder = *lease.Spec.HolderIdentity
+		}
+		logutil.WithResource("Lease", l.key()).WithField("holder", holder).
+			Warn("Lock was taken over by another process, no longer renewing it")
+		return true, nil
+	}
+	now := metav1.NewMicroTime(time.Now())
+	lease.Spec.RenewTime = &now
+	return false, l.cfg.Client.Update(ctx, lease)
+}
+
+// Release stops renewing l's Lease and deletes it, if it is still held by l.
+func (l *NamespaceLock) Release(ctx context.Context) error {
+	if l.stopRenew != nil {
+		close(l.stopRenew)
+		l.renewWG.Wait()
+		l.stopRenew = nil
+	}
+	if !l.held {
+		return nil
+	}
+	l.held = false
+
+	lease := &coordinationv1.Lease{}
+	if err := l.cfg.Client.Get(ctx, l.key(), lease); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error getting lock: %v", err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.identity {
+		return nil
+	}
+	if err := l.cfg.Client.Delete(ctx, lease); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting lock: %v", err)
+	}
+	return nil
+}
+
+func (l *NamespaceLock) key() types.NamespacedName {
+	return types.NamespacedName{Namespace: l.cfg.Namespace, Name: NamespaceLockName}
+}
+
+func isLeaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	duration := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	return lease.Spec.RenewTime.Add(duration).Before(now)
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("NamespaceLock", func() {
+	var (
+		cfg *Configuration
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		cfg = &Configuration{Namespace: "default", Scheme: sch, Client: fake.NewFakeClientWithScheme(sch)}
+		ctx = context.TODO()
+	})
+
+	It("is held by one process at a time", func() {
+		first, second := NewNamespaceLock(cfg), NewNamespaceLock(cfg)
+		Expect(first.Acquire(ctx, 0)).To(Succeed())
+		Expect(second.Acquire(ctx, 0)).NotTo(Succeed())
+
+		Expect(first.Release(ctx)).To(Succeed())
+		Expect(second.Acquire(ctx, 0)).To(Succeed())
+		Expect(second.Release(ctx)).To(Succeed())
+	})
+	It("is taken over once expired", func() {
+		holder, expired := "crashed", metav1.NewMicroTime(time.Now().Add(-2*namespaceLockDuration))
+		durationSeconds := int32(namespaceLockDuration.Seconds())
+		lease := &coordinationv1.Lease{}
+		lease.SetName(NamespaceLockName)
+		lease.SetNamespace(cfg.Namespace)
+		lease.Spec = coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &durationSeconds,
+			RenewTime:            &expired,
+		}
+		Expect(cfg.Client.Create(ctx, lease)).To(Succeed())
+
+		l := NewNamespaceLock(cfg)
+		Expect(l.Acquire(ctx, 0)).To(Succeed())
+		Expect(l.Release(ctx)).To(Succeed())
+	})
+	It("runs a function while holding the lock", func() {
+		ran := false
+		Expect(RunWithNamespaceLock(ctx, cfg, 0, func(context.Context) error {
+			ran = true
+			return NewNamespaceLock(cfg).Acquire(ctx, 0)
+		})).NotTo(Succeed())
+		Expect(ran).To(BeTrue())
+
+		l := NewNamespaceLock(cfg)
+		Expect(l.Acquire(ctx, 0)).To(Succeed())
+		Expect(l.Release(ctx)).To(Succeed())
+	})
+	It("runs without the lock if Leases may not be managed", func() {
+		cfg.Client = forbiddenLeaseClient{cfg.Client}
+		ran := false
+		Expect(RunWithNamespaceLock(ctx, cfg, 0, func(context.Context) error {
+			ran = true
+			return nil
+		})).To(Succeed())
+		Expect(ran).To(BeTrue())
+	})
+	It("cancels the function if the lock is lost", func() {
+		defer func(interval time.Duration) { namespaceLockRenewInterval = interval }(namespaceLockRenewInterval)
+		namespaceLockRenewInterval = 10 * time.Millisecond
+
+		err := RunWithNamespaceLock(ctx, cfg, 0, func(ctx context.Context) error {
+			lease := &coordinationv1.Lease{}
+			lease.SetName(NamespaceLockName)
+			lease.SetNamespace(cfg.Namespace)
+			Expect(cfg.Client.Delete(ctx, lease)).To(Succeed())
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("lost the lock"))
+	})
+	It("stops renewing a lock taken over by another process", func() {
+		l := NewNamespaceLock(cfg)
+		acquired, _, err := l.tryAcquire(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeTrue())
+
+		lost, err := l.renewOnce(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lost).To(BeFalse())
+
+		lease := &coordinationv1.Lease{}
+		Expect(cfg.Client.Get(ctx, l.key(), lease)).To(Succeed())
+		other := "other"
+		lease.Spec.HolderIdentity = &other
+		Expect(cfg.Client.Update(ctx, lease)).To(Succeed())
+		renewTime := lease.Spec.RenewTime
+
+		lost, err = l.renewOnce(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lost).To(BeTrue())
+		Expect(cfg.Client.Get(ctx, l.key(), lease)).To(Succeed())
+		Expect(*lease.Spec.HolderIdentity).To(Equal(other))
+		Expect(lease.Spec.RenewTime.Equal(renewTime)).To(BeTrue())
+	})
+})
+
+// forbiddenLeaseClient is a client forbidden to get Leases.
+type forbiddenLeaseClient struct {
+	client.Client
+}
+
+func (c forbiddenLeaseClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if _, isLease := obj.(*coordinationv1.Lease); isLease {
+		return apierrors.NewForbidden(coordinationv1.Resource("leases"), key.Name, errors.New("forbidden"))
+	}
+	return c.Client.Get(ctx, key, obj)
+}
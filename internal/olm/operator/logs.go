@@ -0,0 +1,181 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Labels set by OLM on the Deployments it creates for a CSV.
+	olmOwnerKindLabel = "olm.owner.kind"
+	olmOwnerLabel     = "olm.owner"
+
+	defaultLogRefreshInterval = time.Second
+)
+
+// OperatorDeployments returns the Deployments created by OLM for the CSV csvName in cfg's namespace,
+// or for any CSV if csvName is empty.
+func OperatorDeployments(ctx context.Context, cfg *Configuration, csvName string) ([]appsv1.Deployment, error) {
+	labels := client.MatchingLabels{olmOwnerKindLabel: v1alpha1.ClusterServiceVersionKind}
+	if csvName != "" {
+		labels[olmOwnerLabel] = csvName
+	}
+	deps := &appsv1.DeploymentList{}
+	if err := cfg.Client.List(ctx, deps, client.InNamespace(cfg.Namespace), labels); err != nil {
+		return nil, fmt.Errorf("error listing deployments: %v", err)
+	}
+	return deps.Items, nil
+}
+
+// OperatorPods returns the pods of the Deployments returned by OperatorDeployments.
+func OperatorPods(ctx context.Context, cfg *Configuration, csvName string) (pods []corev1.Pod, err error) {
+	deps, err := OperatorDeployments(ctx, cfg, csvName)
+	if err != nil {
+		return nil, err
+	}
+	for _, dep := range deps {
+		selector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing deployment %s selector: %v", dep.GetName(), err)
+		}
+		podList := &corev1.PodList{}
+		if err := cfg.Client.List(ctx, podList, client.InNamespace(cfg.Namespace),
+			client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, fmt.Errorf("error listing pods of deployment %s: %v", dep.GetName(), err)
+		}
+		pods = append(pods, podList.Items...)
+	}
+	return pods, nil
+}
+
+// LogFollower streams the logs of every container of running operator pods, including pods
+// created after it starts, prefixing each line with "<pod>/<container>: ".
+type LogFollower struct {
+	// CSVName limits followed pods to those of the CSV's Deployments. If empty, pods of all CSVs are followed.
+	CSVName string
+	// RefreshInterval is the time between looking for new pods and restarted containers.
+	RefreshInterval time.Duration
+	// Output is called with each prefixed log line, and errors streaming logs. It may be called concurrently.
+	Output func(line string)
+
+	cfg *Configuration
+}
+
+func NewLogFollower(cfg *Configuration) LogFollower {
+	return LogFollower{
+		RefreshInterval: defaultLogRefreshInterval,
+		cfg:             cfg,
+	}
+}
+
+// Run follows logs until ctx is done. A container's logs are followed again from where they stopped
+// if its stream ends while the pod is running, ex. when the container restarts.
+func (f LogFollower) Run(ctx context.Context) {
+	var (
+		cs kubernetes.Interface
+		mu sync.Mutex
+		// Maps are keyed by "<pod>/<container>". since is the time a container's last stream ended,
+		// and failed is set once an error opening a container's stream is output, so retries are quiet.
+		following = make(map[string]bool)
+		since     = make(map[string]metav1.Time)
+		failed    = make(map[string]bool)
+		wg        sync.WaitGroup
+	)
+	defer wg.Wait()
+
+	ticker := time.NewTicker(f.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		pods, err := OperatorPods(ctx, f.cfg, f.CSVName)
+		if err == nil && len(pods) != 0 && cs == nil {
+			// Only create a clientset once there are logs to stream.
+			if cs, err = kubernetes.NewForConfig(f.cfg.RESTConfig); err != nil {
+				f.Output(fmt.Sprintf("error creating client to stream logs: %v", err))
+				return
+			}
+		}
+		for _, pod := range pods {
+			if pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			for _, c := range pod.Spec.Containers {
+				key := pod.GetName() + "/" + c.Name
+				mu.Lock()
+				if following[key] {
+					mu.Unlock()
+					continue
+				}
+				following[key] = true
+				opts := &corev1.PodLogOptions{Container: c.Name, Follow: true}
+				if t, ok := since[key]; ok {
+					opts.SinceTime = &t
+				}
+				quiet := failed[key]
+				mu.Unlock()
+
+				wg.Add(1)
+				go func(pod corev1.Pod, key string, opts *corev1.PodLogOptions, quiet bool) {
+					defer wg.Done()
+					err := f.stream(ctx, cs, pod, key, opts)
+					if err != nil && !quiet && ctx.Err() == nil {
+						f.Output(key + ": " + fmt.Sprintf("error streaming logs: %v", err))
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					following[key] = false
+					if err != nil {
+						failed[key] = true
+					} else {
+						since[key] = metav1.Now()
+					}
+				}(pod, key, opts, quiet)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// stream outputs the logs of a container until its stream ends, returning an error if it cannot be opened.
+func (f LogFollower) stream(ctx context.Context, cs kubernetes.Interface, pod corev1.Pod, key string,
+	opts *corev1.PodLogOptions) error {
+	stream, err := cs.CoreV1().Pods(pod.GetNamespace()).GetLogs(pod.GetName(), opts).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		f.Output(key + ": " + scanner.Text())
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("OperatorPods", func() {
+	var cfg *Configuration
+
+	newDeployment := func(name, csvName string) *appsv1.Deployment {
+		dep := &appsv1.Deployment{}
+		dep.SetName(name)
+		dep.SetNamespace("default")
+		dep.SetLabels(map[string]string{olmOwnerKindLabel: "ClusterServiceVersion", olmOwnerLabel: csvName})
+		dep.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}}
+		return dep
+	}
+	newPod := func(name, app string) *corev1.Pod {
+		pod := &corev1.Pod{}
+		pod.SetName(name)
+		pod.SetNamespace("default")
+		pod.SetLabels(map[string]string{"app": app})
+		return pod
+	}
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		cfg = &Configuration{Namespace: "default", Scheme: sch, Client: fake.NewFakeClientWithScheme(sch,
+			newDeployment("memcached-operator", "memcached-operator.v0.0.1"),
+			newDeployment("etcd-operator", "etcd-operator.v0.9.4"),
+			newPod("memcached-operator-abcde", "memcached-operator"),
+			newPod("etcd-operator-fghij", "etcd-operator"),
+			newPod("unrelated", "unrelated"),
+		)}
+	})
+
+	It("returns the pods of a CSV's deployments", func() {
+		pods, err := OperatorPods(context.TODO(), cfg, "memcached-operator.v0.0.1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods).To(HaveLen(1))
+		Expect(pods[0].GetName()).To(Equal("memcached-operator-abcde"))
+	})
+	It("returns the pods of every CSV's deployments if no CSV is named", func() {
+		pods, err := OperatorPods(context.TODO(), cfg, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,104 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OperatorResourceName returns the name of the cluster-scoped Operator resource OLM aggregates
+// the components of pkg installed in namespace under.
+func OperatorResourceName(pkg, namespace string) string {
+	return fmt.Sprintf("%s.%s", pkg, namespace)
+}
+
+// EnsureOperatorResource creates the Operator resource of pkg installed in cfg's namespace, so OLM
+// aggregates references to the operator's components in its status, or adds cfg's missing labels and
+// annotations to an existing one. Clusters running an OLM version without the Operator API are skipped,
+// returning nil.
+func EnsureOperatorResource(ctx context.Context, cfg *Configuration, pkg string) (*v1.Operator, error) {
+	op := &v1.Operator{}
+	key := types.NamespacedName{Name: OperatorResourceName(pkg, cfg.Namespace)}
+	err := cfg.Client.Get(ctx, key, op)
+	switch {
+	case meta.IsNoMatchError(err):
+		return nil, nil
+	case apierrors.IsNotFound(err):
+		op.SetName(key.Name)
+		if err := cfg.Client.Create(ctx, op); err != nil && !apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("error creating operator %q: %v", key.Name, err)
+		}
+		return op, nil
+	case err != nil:
+		return nil, fmt.Errorf("error getting operator %q: %v", key.Name, err)
+	}
+
+	base := op.DeepCopy()
+	op.SetLabels(mergeMissing(op.GetLabels(), cfg.Labels))
+	op.SetAnnotations(mergeMissing(op.GetAnnotations(), cfg.Annotations))
+	if reflect.DeepEqual(base.GetLabels(), op.GetLabels()) &&
+		reflect.DeepEqual(base.GetAnnotations(), op.GetAnnotations()) {
+		return op, nil
+	}
+	if err := cfg.Client.Patch(ctx, op, client.MergeFrom(base)); err != nil {
+		return nil, fmt.Errorf("error patching operator %q: %v", key.Name, err)
+	}
+	return op, nil
+}
+
+// getOperatorComponents returns the component references OLM aggregated in the status of the Operator
+// resource of pkg installed in cfg's namespace, or nil if it or the Operator API does not exist.
+func getOperatorComponents(ctx context.Context, cfg *Configuration, pkg string) ([]v1.RichReference, error) {
+	op := &v1.Operator{}
+	key := types.NamespacedName{Name: OperatorResourceName(pkg, cfg.Namespace)}
+	if err := cfg.Client.Get(ctx, key, op); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get operator %q: %v", key.Name, err)
+	}
+	if op.Status.Components == nil {
+		return nil, nil
+	}
+	return op.Status.Components.Refs, nil
+}
+
+// componentObject returns an object referring to the component ref, or nil if ref is incomplete.
+func componentObject(ref v1.RichReference) *unstructured.Unstructured {
+	if ref.ObjectReference == nil || ref.Kind == "" || ref.Name == "" {
+		return nil
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind))
+	obj.SetNamespace(ref.Namespace)
+	obj.SetName(ref.Name)
+	return obj
+}
+
+// componentKey uniquely identifies an object by its group, kind, namespace, and name.
+func componentKey(gvk schema.GroupVersionKind, namespace, name string) string {
+	return strings.Join([]string{gvk.Group, gvk.Kind, namespace, name}, "/")
+}
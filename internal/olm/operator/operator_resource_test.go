@@ -0,0 +1,124 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ = Describe("Operator resource", func() {
+	var (
+		cfg *Configuration
+		ctx context.Context
+		key types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		cfg = &Configuration{Namespace: "default", Scheme: sch, Client: fake.NewFakeClientWithScheme(sch)}
+		ctx = context.TODO()
+		key = types.NamespacedName{Name: "memcached-operator.default"}
+	})
+
+	Describe("EnsureOperatorResource", func() {
+		It("creates the Operator resource", func() {
+			op, err := EnsureOperatorResource(ctx, cfg, "memcached-operator")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(op.GetName()).To(Equal("memcached-operator.default"))
+			Expect(cfg.Client.Get(ctx, key, &v1.Operator{})).To(Succeed())
+		})
+		It("adds missing labels to an existing Operator resource", func() {
+			existing := &v1.Operator{}
+			existing.SetName(key.Name)
+			existing.SetLabels(map[string]string{"team": "cache"})
+			Expect(cfg.Client.Create(ctx, existing)).To(Succeed())
+
+			cfg.Labels = map[string]string{"team": "other", "ci-run": "42"}
+			_, err := EnsureOperatorResource(ctx, cfg, "memcached-operator")
+			Expect(err).NotTo(HaveOccurred())
+			op := &v1.Operator{}
+			Expect(cfg.Client.Get(ctx, key, op)).To(Succeed())
+			Expect(op.GetLabels()).To(Equal(map[string]string{"team": "cache", "ci-run": "42"}))
+		})
+	})
+
+	Describe("Uninstall", func() {
+		var u *Uninstall
+
+		BeforeEach(func() {
+			u = NewUninstall(cfg)
+			u.Package = "memcached-operator"
+			u.Logf = func(string, ...interface{}) {}
+		})
+
+		It("adds components not created by the install plan", func() {
+			op := &v1.Operator{}
+			op.SetName(key.Name)
+			op.Status.Components = &v1.Components{Refs: []v1.RichReference{
+				{ObjectReference: &corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment",
+					Namespace: "default", Name: "memcached-operator-controller-manager"}},
+				{ObjectReference: &corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap",
+					Namespace: "default", Name: "memcached-operator-config"}},
+				{ObjectReference: &corev1.ObjectReference{APIVersion: "apiextensions.k8s.io/v1",
+					Kind: "CustomResourceDefinition", Name: "memcacheds.cache.example.com"}},
+				{ObjectReference: &corev1.ObjectReference{APIVersion: "operators.coreos.com/v1alpha1",
+					Kind: v1alpha1.SubscriptionKind, Namespace: "default", Name: "memcached-operator-sub"}},
+				{ObjectReference: &corev1.ObjectReference{APIVersion: "v1", Kind: "Namespace", Name: "default"}},
+			}}
+			Expect(cfg.Client.Create(ctx, op)).To(Succeed())
+
+			dep := &unstructured.Unstructured{}
+			dep.SetAPIVersion("apps/v1")
+			dep.SetKind("Deployment")
+			dep.SetNamespace("default")
+			dep.SetName("memcached-operator-controller-manager")
+			crds, others, err := u.addOperatorComponents(ctx, nil, nil, []controllerutil.Object{dep})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(crds).To(HaveLen(1))
+			Expect(crds[0].GetName()).To(Equal("memcacheds.cache.example.com"))
+			Expect(others).To(HaveLen(2))
+			Expect(others[1].GetName()).To(Equal("memcached-operator-config"))
+		})
+		It("adds nothing without an Operator resource", func() {
+			crds, others, err := u.addOperatorComponents(ctx, nil, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(crds).To(BeEmpty())
+			Expect(others).To(BeEmpty())
+		})
+		It("deletes the Operator resource", func() {
+			_, err := EnsureOperatorResource(ctx, cfg, "memcached-operator")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.deleteOperatorResource(ctx)).To(Succeed())
+			err = cfg.Client.Get(ctx, key, &v1.Operator{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(u.deleteOperatorResource(ctx)).To(Succeed())
+		})
+	})
+})
@@ -50,6 +50,17 @@ func NewInstall(cfg *operator.Configuration) Install {
 func (i *Install) BindFlags(fs *pflag.FlagSet) {
 	fs.Var(&i.InstallMode, "install-mode", "install mode")
 	fs.StringVar(&i.Version, "version", "", "Packaged version of the operator to deploy")
+	i.ConfigMapCatalogCreator.CatalogSourceConfig.BindFlags(fs)
+	i.OperatorInstaller.SubscriptionConfig.BindFlags(fs)
+	fs.Var(&i.CSVHealth, "csv-health", "criterion for the CSV being installed, one of [phase, conditions, "+
+		"deployments-ready]. conditions also accepts a CSV that reached InstallSucceeded and then briefly "+
+		"returned to another phase; deployments-ready also waits for all of the CSV's deployments to be ready")
+	fs.BoolVar(&i.SkipOperatorGroup, "skip-operator-group", false, "do not create an OperatorGroup, for namespaces "+
+		"whose OperatorGroups are managed by platform automation. The namespace's existing OperatorGroup must "+
+		"cover --install-mode's target namespaces")
+	fs.StringToStringVar(&i.ImageOverrides, "set-image", nil, "override the image of a container in the "+
+		"CSV's deployments once installed, as <container>=<image> or <deployment>/<container>=<image>. "+
+		"A container name shared by several deployments must be qualified by its deployment. May be set more than once")
 }
 
 func (i Install) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
@@ -59,6 +70,15 @@ func (i Install) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, erro
 	return i.InstallOperator(ctx)
 }
 
+// Detach creates the install's resources without waiting for OLM to install the operator, returning its
+// Subscription. The install is completed by OperatorInstaller.ResumeInstall.
+func (i Install) Detach(ctx context.Context) (*v1alpha1.Subscription, error) {
+	if err := i.setup(); err != nil {
+		return nil, err
+	}
+	return i.Subscribe(ctx)
+}
+
 func (i *Install) setup() error {
 	pkg, bundles, err := loadPackageManifests(i.PackageManifestsDirectory)
 	if err != nil {
@@ -23,3 +23,8 @@ import (
 type CatalogCreator interface {
 	CreateCatalog(ctx context.Context, name string) (*v1alpha1.CatalogSource, error)
 }
+
+// CatalogUpdater adds an upgrade's bundle to a catalog created by a CatalogCreator.
+type CatalogUpdater interface {
+	UpdateCatalog(ctx context.Context, cs *v1alpha1.CatalogSource) error
+}
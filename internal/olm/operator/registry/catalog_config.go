@@ -0,0 +1,112 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CatalogSourceConfig configures the CatalogSources created by catalog creators, and the registry pods
+// serving them, so SDK-created catalogs comply with cluster policies and are ordered deliberately
+// against other catalogs.
+type CatalogSourceConfig struct {
+	// Priority orders the CatalogSource against others in dependency resolution. Catalogs with higher
+	// priorities are preferred; the default is 0.
+	Priority int
+	// NodeSelector and Tolerations are set on registry pods.
+	NodeSelector map[string]string
+	Tolerations  Tolerations
+}
+
+// BindFlags binds c to fs.
+func (c *CatalogSourceConfig) BindFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&c.Priority, "catalog-priority", 0, "priority of the CatalogSource in dependency resolution. "+
+		"Catalogs with higher priorities are preferred over other catalogs providing the same APIs or packages")
+	fs.StringToStringVar(&c.NodeSelector, "registry-pod-node-selector", nil, "node selector of the registry "+
+		"pod serving the catalog, as <label>=<value>. May be set more than once")
+	fs.Var(&c.Tolerations, "registry-pod-toleration", "toleration of the registry pod serving the catalog, "+
+		"as <key>[=<value>]:<effect>, ex. 'dedicated=infra:NoSchedule'. The effect may be empty to tolerate "+
+		"every effect. May be set more than once")
+}
+
+// withCatalogSourceConfig returns a function that sets the CatalogSource argument's fields configured by c.
+func withCatalogSourceConfig(c CatalogSourceConfig) func(*v1alpha1.CatalogSource) {
+	return func(cs *v1alpha1.CatalogSource) {
+		cs.Spec.Priority = c.Priority
+	}
+}
+
+// Tolerations is a pflag.Value of tolerations in the format of 'kubectl taint', '<key>[=<value>]:<effect>'.
+// Keys without values tolerate taints with any value of the key.
+type Tolerations []corev1.Toleration
+
+var _ pflag.Value = &Tolerations{}
+
+func (t Tolerations) String() string {
+	s := make([]string, len(t))
+	for i, tol := range t {
+		s[i] = tol.Key
+		if tol.Operator == corev1.TolerationOpEqual {
+			s[i] += "=" + tol.Value
+		}
+		s[i] += ":" + string(tol.Effect)
+	}
+	return strings.Join(s, ",")
+}
+
+func (t *Tolerations) Set(s string) error {
+	for _, value := range strings.Split(s, ",") {
+		tol, err := parseToleration(value)
+		if err != nil {
+			return err
+		}
+		*t = append(*t, tol)
+	}
+	return nil
+}
+
+func (t Tolerations) Type() string {
+	return "toleration"
+}
+
+func parseToleration(s string) (corev1.Toleration, error) {
+	tol := corev1.Toleration{}
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return tol, fmt.Errorf("invalid toleration %q: must be of the form <key>[=<value>]:<effect>", s)
+	}
+	keyValue, effect := s[:i], corev1.TaintEffect(s[i+1:])
+	switch effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		tol.Effect = effect
+	default:
+		return tol, fmt.Errorf("invalid toleration %q: effect must be one of [NoSchedule, PreferNoSchedule, "+
+			"NoExecute] or empty", s)
+	}
+	if kv := strings.SplitN(keyValue, "=", 2); len(kv) == 2 {
+		tol.Key, tol.Operator, tol.Value = kv[0], corev1.TolerationOpEqual, kv[1]
+	} else {
+		tol.Key, tol.Operator = keyValue, corev1.TolerationOpExists
+	}
+	if tol.Key == "" {
+		return tol, fmt.Errorf("invalid toleration %q: key must be set", s)
+	}
+	return tol, nil
+}
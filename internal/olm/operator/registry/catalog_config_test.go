@@ -0,0 +1,50 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("CatalogSourceConfig", func() {
+	It("sets the CatalogSource's priority", func() {
+		cs := newCatalogSource("test-catalog", "default", withCatalogSourceConfig(CatalogSourceConfig{Priority: -10}))
+		Expect(cs.Spec.Priority).To(Equal(-10))
+		Expect(newCatalogSource("test-catalog", "default").Spec).To(Equal(v1alpha1.CatalogSourceSpec{}))
+	})
+
+	Describe("Tolerations", func() {
+		It("parses tolerations", func() {
+			t := Tolerations{}
+			Expect(t.Set("dedicated=infra:NoSchedule,gpu:NoExecute")).To(Succeed())
+			Expect(t.Set("maintenance:")).To(Succeed())
+			Expect(t).To(Equal(Tolerations{
+				{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+				{Key: "maintenance", Operator: corev1.TolerationOpExists},
+			}))
+			Expect(t.String()).To(Equal("dedicated=infra:NoSchedule,gpu:NoExecute,maintenance:"))
+		})
+		It("rejects invalid tolerations", func() {
+			for _, s := range []string{"dedicated", "dedicated=infra:Sometimes", ":NoSchedule", "=infra:NoSchedule"} {
+				t := Tolerations{}
+				Expect(t.Set(s)).NotTo(Succeed(), s)
+			}
+		})
+	})
+})
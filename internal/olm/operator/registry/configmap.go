@@ -23,7 +23,6 @@ import (
 	log "github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/util/retry"
 
 	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
@@ -34,6 +33,8 @@ type ConfigMapCatalogCreator struct {
 	Package *apimanifests.PackageManifest
 	Bundles []*apimanifests.Bundle
 
+	CatalogSourceConfig
+
 	cfg *operator.Configuration
 }
 
@@ -44,8 +45,7 @@ func NewConfigMapCatalogCreator(cfg *operator.Configuration) *ConfigMapCatalogCr
 }
 
 func (c ConfigMapCatalogCreator) CreateCatalog(ctx context.Context, name string) (*v1alpha1.CatalogSource, error) {
-	cs := newCatalogSource(name, c.cfg.Namespace,
-		withSDKPublisher(c.Package.PackageName))
+	cs := c.catalogSource(name)
 	if err := c.cfg.Client.Create(ctx, cs); err != nil {
 		return nil, fmt.Errorf("error creating catalog source: %w", err)
 	}
@@ -61,13 +61,20 @@ func (c ConfigMapCatalogCreator) CreateCatalog(ctx context.Context, name string)
 	return cs, nil
 }
 
+func (c ConfigMapCatalogCreator) catalogSource(name string) *v1alpha1.CatalogSource {
+	return newCatalogSource(name, c.cfg.Namespace,
+		withSDKPublisher(c.Package.PackageName),
+		withCatalogSourceConfig(c.CatalogSourceConfig))
+}
+
 func (c ConfigMapCatalogCreator) registryUp(ctx context.Context, cs *v1alpha1.CatalogSource) (err error) {
 	rr := configmap.RegistryResources{
 		Pkg:     c.Package,
 		Bundles: c.Bundles,
-	}
-	if rr.Client, err = olmclient.NewClientForConfig(c.cfg.RESTConfig); err != nil {
-		return err
+		// Create registry objects with the configured client so they get its labels and annotations.
+		Client:       &olmclient.Client{KubeClient: c.cfg.Client},
+		NodeSelector: c.NodeSelector,
+		Tolerations:  c.Tolerations,
 	}
 
 	if exists, err := rr.IsRegistryExist(ctx, c.cfg.Namespace); err != nil {
@@ -75,10 +82,10 @@ func (c ConfigMapCatalogCreator) registryUp(ctx context.Context, cs *v1alpha1.Ca
 	} else if exists {
 		if isRegistryStale, err := rr.IsRegistryDataStale(ctx, c.cfg.Namespace); err == nil {
 			if !isRegistryStale {
-				log.Infof("%s registry data is current", c.Package.PackageName)
+				log.WithField("package", c.Package.PackageName).Info("Registry data is current")
 				return nil
 			}
-			log.Infof("A stale %s registry exists, deleting", c.Package.PackageName)
+			log.WithField("package", c.Package.PackageName).Info("A stale registry exists, deleting")
 			if err = rr.DeletePackageManifestsRegistry(ctx, c.cfg.Namespace); err != nil {
 				return fmt.Errorf("error deleting registered package: %w", err)
 			}
@@ -86,7 +93,7 @@ func (c ConfigMapCatalogCreator) registryUp(ctx context.Context, cs *v1alpha1.Ca
 			return fmt.Errorf("error checking registry data: %w", err)
 		}
 	}
-	log.Infof("Creating %s registry", c.Package.PackageName)
+	log.WithField("package", c.Package.PackageName).Info("Creating registry")
 	if err := rr.CreatePackageManifestsRegistry(ctx, cs, c.cfg.Namespace); err != nil {
 		return fmt.Errorf("error registering package: %w", err)
 	}
@@ -104,7 +111,7 @@ func (c *ConfigMapCatalogCreator) updateCatalogSource(ctx context.Context, cs *v
 		Namespace: c.cfg.Namespace,
 		Name:      cs.GetName(),
 	}
-	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+	if err := c.cfg.RetryPolicy.OnConflict(ctx, func() error {
 		if err := c.cfg.Client.Get(ctx, catsrcKey, cs); err != nil {
 			return err
 		}
@@ -134,6 +134,16 @@ func withRegistryGRPCContainer(pkgName string) func(*appsv1.Deployment) {
 	}
 }
 
+// withScheduling returns a function that sets the Deployment argument's pod node selector and tolerations.
+func withScheduling(nodeSelector map[string]string, tolerations []corev1.Toleration) func(*appsv1.Deployment) {
+	return func(dep *appsv1.Deployment) {
+		applyToDeploymentPodSpec(dep, func(spec *corev1.PodSpec) {
+			spec.NodeSelector = nodeSelector
+			spec.Tolerations = tolerations
+		})
+	}
+}
+
 // newRegistryDeployment creates a new Deployment with a name derived from
 // pkgName, the package manifest's packageName, in namespace. The Deployment
 // and replicas are created with labels derived from pkgName. opts will be
@@ -21,8 +21,8 @@ import (
 
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
-	log "github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -30,6 +30,7 @@ import (
 
 	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
 )
 
 const (
@@ -49,6 +50,10 @@ type RegistryResources struct {
 	Client  *olmclient.Client
 	Pkg     *apimanifests.PackageManifest
 	Bundles []*apimanifests.Bundle
+
+	// NodeSelector and Tolerations schedule registry pods, ex. onto nodes required by cluster policies.
+	NodeSelector map[string]string
+	Tolerations  []corev1.Toleration
 }
 
 // IsRegistryExist returns true if a registry Deployment exists in namespace.
@@ -134,7 +139,7 @@ func (rr *RegistryResources) CreatePackageManifestsRegistry(ctx context.Context,
 	// Options for creating a Deployment, since we need to mount all package
 	// ConfigMaps as volumes into pods.
 	opts := make([]func(*appsv1.Deployment), 0, 2*len(binaryDataByConfigMap)+1)
-	opts = append(opts, withRegistryGRPCContainer(pkgName))
+	opts = append(opts, withRegistryGRPCContainer(pkgName), withScheduling(rr.NodeSelector, rr.Tolerations))
 	// Build all package ConfigMaps.
 	for cmName, binaryData := range binaryDataByConfigMap {
 		cm := newConfigMap(cmName, namespace, withBinaryData(binaryData))
@@ -173,7 +178,7 @@ func (rr *RegistryResources) CreatePackageManifestsRegistry(ctx context.Context,
 		Name:      dep.GetName(),
 		Namespace: namespace,
 	}
-	log.Infof("Waiting for Deployment %q rollout to complete", depKey)
+	logutil.WithResource("Deployment", depKey).Info("Waiting for Deployment rollout to complete")
 	if err := rr.Client.DoRolloutWait(ctx, depKey); err != nil {
 		return fmt.Errorf("error waiting for Deployment %q to roll out: %w", depKey, err)
 	}
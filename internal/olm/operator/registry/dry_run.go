@@ -0,0 +1,76 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// catalogSourcer is implemented by CatalogCreators that can build the CatalogSource they create
+// without creating it, so it can be dry-run created.
+type catalogSourcer interface {
+	catalogSource(name string) *v1alpha1.CatalogSource
+}
+
+// DryRunError contains every error returned by server-side dry-run creates of the objects an install creates.
+type DryRunError struct {
+	Errors []error
+}
+
+func (e DryRunError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = "  - " + err.Error()
+	}
+	return fmt.Sprintf("dry run found %d issue(s) with the objects to create:\n%s",
+		len(e.Errors), strings.Join(msgs, "\n"))
+}
+
+// dryRunCreate creates the CatalogSource, OperatorGroup if none exists, and Subscription of the install
+// with a server-side dry run, so admission webhook and validation failures of all of them are returned
+// together as a DryRunError before any object is created.
+func (o OperatorInstaller) dryRunCreate(ctx context.Context) error {
+	var objs []runtime.Object
+	cs := newCatalogSource(o.CatalogSourceName, o.cfg.Namespace)
+	if c, ok := o.CatalogCreator.(catalogSourcer); ok {
+		cs = c.catalogSource(o.CatalogSourceName)
+	}
+	objs = append(objs, cs)
+
+	var errs []error
+	if _, ogFound, err := o.getOperatorGroup(ctx); err != nil {
+		errs = append(errs, err)
+	} else if !ogFound {
+		objs = append(objs, newSDKOperatorGroup(o.cfg.Namespace, withTargetNamespaces(o.InstallMode.TargetNamespaces...)))
+	}
+	objs = append(objs, o.newSubscription(cs))
+
+	for _, obj := range objs {
+		if err := o.cfg.Client.Create(ctx, obj, client.DryRunAll); err != nil {
+			kind := obj.GetObjectKind().GroupVersionKind().Kind
+			errs = append(errs, fmt.Errorf("%s: %v", kind, err))
+		}
+	}
+	if len(errs) != 0 {
+		return DryRunError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+// rejectingClient fails dry-run creates of objects of the kinds in rejected.
+type rejectingClient struct {
+	client.Client
+	rejected map[string]error
+	dryRuns  []string
+}
+
+func (c *rejectingClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	createOpts := &client.CreateOptions{}
+	createOpts.ApplyOptions(opts)
+	if len(createOpts.DryRun) != 0 {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		c.dryRuns = append(c.dryRuns, kind)
+		if err, ok := c.rejected[kind]; ok {
+			return err
+		}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("dryRunCreate", func() {
+	var (
+		o   *OperatorInstaller
+		c   *rejectingClient
+		sch *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		sch = runtime.NewScheme()
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		c = &rejectingClient{Client: fake.NewFakeClientWithScheme(sch), rejected: map[string]error{}}
+		o = &OperatorInstaller{
+			CatalogSourceName: "test-operator-catalog",
+			PackageName:       "test-operator",
+			StartingCSV:       "test-operator.v0.0.1",
+			CatalogCreator:    fakeCatalogCreator{},
+			cfg:               &operator.Configuration{Scheme: sch, Namespace: "default", Client: c},
+		}
+	})
+
+	It("dry-run creates the catalog source, operator group, and subscription", func() {
+		Expect(o.dryRunCreate(context.TODO())).To(Succeed())
+		Expect(c.dryRuns).To(Equal([]string{
+			v1alpha1.CatalogSourceKind, v1.OperatorGroupKind, v1alpha1.SubscriptionKind,
+		}))
+		subs := &v1alpha1.SubscriptionList{}
+		Expect(c.List(context.TODO(), subs)).To(Succeed())
+		Expect(subs.Items).To(BeEmpty())
+	})
+	It("does not dry-run create an operator group if one exists", func() {
+		og := &v1.OperatorGroup{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"}}
+		c.Client = fake.NewFakeClientWithScheme(sch, og)
+		Expect(o.dryRunCreate(context.TODO())).To(Succeed())
+		Expect(c.dryRuns).To(Equal([]string{v1alpha1.CatalogSourceKind, v1alpha1.SubscriptionKind}))
+	})
+	It("returns every rejection together", func() {
+		c.rejected[v1alpha1.CatalogSourceKind] = errors.New("denied by webhook")
+		c.rejected[v1alpha1.SubscriptionKind] = errors.New("invalid channel")
+		err := o.dryRunCreate(context.TODO())
+		Expect(err).To(HaveOccurred())
+		Expect(c.dryRuns).To(HaveLen(3))
+		dryRunErr := DryRunError{}
+		Expect(errors.As(err, &dryRunErr)).To(BeTrue())
+		Expect(dryRunErr.Errors).To(HaveLen(2))
+		Expect(err.Error()).To(ContainSubstring("CatalogSource: denied by webhook"))
+		Expect(err.Error()).To(ContainSubstring("Subscription: invalid channel"))
+	})
+	It("stops the install before creating any object", func() {
+		c.rejected[v1alpha1.SubscriptionKind] = errors.New("invalid channel")
+		o.CatalogCreator = fakeCatalogCreator{err: errors.New("catalog should not be created")}
+		_, err := o.InstallOperator(context.TODO())
+		Expect(err).To(MatchError(ContainSubstring("invalid channel")))
+	})
+})
@@ -0,0 +1,57 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"errors"
+
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+)
+
+var (
+	// ErrOperatorGroupConflict is returned by InstallOperator when the install's namespace has an OperatorGroup,
+	// or more than one, that the install cannot use.
+	ErrOperatorGroupConflict = errors.New("operator group conflict")
+	// ErrInstallPlanTimeout is returned by InstallOperator and ResumeInstall when OLM does not create
+	// an install plan for the install's Subscription before the install times out.
+	ErrInstallPlanTimeout = errors.New("timed out waiting for install plan")
+	// ErrCatalogFailed is returned by InstallOperator when the install's catalog cannot be created, and by
+	// UpgradeOperator when the installed operator's catalog cannot be updated.
+	ErrCatalogFailed = errors.New("catalog failed")
+	// ErrUpgradeBlocked is returned by UpgradeOperator when the installed operator's OperatorCondition
+	// reports it is not upgradeable.
+	ErrUpgradeBlocked = errors.New("upgrade blocked by operator condition")
+)
+
+// ErrCSVFailed is returned by InstallOperator and ResumeInstall when the installed CSV reaches the Failed phase.
+type ErrCSVFailed = olmclient.ErrCSVFailed
+
+// categorizedError is an error that matches category with errors.Is, without changing err's message.
+type categorizedError struct {
+	err      error
+	category error
+}
+
+func (e categorizedError) Error() string {
+	return e.err.Error()
+}
+
+func (e categorizedError) Unwrap() error {
+	return e.err
+}
+
+func (e categorizedError) Is(target error) bool {
+	return target == e.category
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+// InstallerHooks are called by an OperatorInstaller as an install progresses, so tools embedding
+// the installer can report progress without parsing its logs. Hooks are called synchronously
+// from the installing goroutine, and so should return quickly.
+type InstallerHooks interface {
+	// OnCatalogCreated is called once the CatalogSource serving the operator is created.
+	OnCatalogCreated(*v1alpha1.CatalogSource)
+	// OnSubscriptionCreated is called once the operator's Subscription is created.
+	OnSubscriptionCreated(*v1alpha1.Subscription)
+	// OnInstallPlanApproved is called once the Subscription's InstallPlan is approved.
+	OnInstallPlanApproved(*v1alpha1.InstallPlan)
+	// OnCSVSucceeded is called once the operator's CSV reaches the Succeeded phase.
+	OnCSVSucceeded(*v1alpha1.ClusterServiceVersion)
+	// OnError is called with the error an install failed with. No other hook is called after OnError.
+	OnError(error)
+}
+
+// NoopInstallerHooks implements InstallerHooks by doing nothing. Embed it to implement only some hooks.
+type NoopInstallerHooks struct{}
+
+var _ InstallerHooks = NoopInstallerHooks{}
+
+func (NoopInstallerHooks) OnCatalogCreated(*v1alpha1.CatalogSource)       {}
+func (NoopInstallerHooks) OnSubscriptionCreated(*v1alpha1.Subscription)   {}
+func (NoopInstallerHooks) OnInstallPlanApproved(*v1alpha1.InstallPlan)    {}
+func (NoopInstallerHooks) OnCSVSucceeded(*v1alpha1.ClusterServiceVersion) {}
+func (NoopInstallerHooks) OnError(error)                                  {}
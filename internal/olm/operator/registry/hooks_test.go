@@ -0,0 +1,110 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+type fakeCatalogCreator struct {
+	err error
+}
+
+func (c fakeCatalogCreator) CreateCatalog(_ context.Context, name string) (*v1alpha1.CatalogSource, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &v1alpha1.CatalogSource{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}, nil
+}
+
+// recordingHooks records the names of the hooks called.
+type recordingHooks struct {
+	NoopInstallerHooks
+	called []string
+	err    error
+}
+
+func (h *recordingHooks) OnCatalogCreated(*v1alpha1.CatalogSource) {
+	h.called = append(h.called, "OnCatalogCreated")
+}
+
+func (h *recordingHooks) OnSubscriptionCreated(*v1alpha1.Subscription) {
+	h.called = append(h.called, "OnSubscriptionCreated")
+}
+
+func (h *recordingHooks) OnError(err error) {
+	h.called = append(h.called, "OnError")
+	h.err = err
+}
+
+var _ = Describe("InstallerHooks", func() {
+	var (
+		o     *OperatorInstaller
+		hooks *recordingHooks
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		hooks = &recordingHooks{}
+		o = &OperatorInstaller{
+			CatalogSourceName: "test-operator-catalog",
+			PackageName:       "test-operator",
+			StartingCSV:       "test-operator.v0.0.1",
+			Hooks:             hooks,
+			cfg: &operator.Configuration{
+				Scheme:    sch,
+				Namespace: "default",
+				Client:    fake.NewFakeClientWithScheme(sch),
+			},
+		}
+	})
+
+	It("calls OnError if the catalog cannot be created", func() {
+		o.CatalogCreator = fakeCatalogCreator{err: errors.New("no catalog")}
+		_, err := o.InstallOperator(context.TODO())
+		Expect(err).To(HaveOccurred())
+		Expect(hooks.called).To(Equal([]string{"OnError"}))
+		Expect(hooks.err).To(Equal(err))
+	})
+	It("calls hooks in order until the install fails", func() {
+		o.CatalogCreator = fakeCatalogCreator{}
+		ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer cancel()
+		// No install plan is ever created, so waiting for one times out.
+		_, err := o.InstallOperator(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(hooks.called).To(Equal([]string{"OnCatalogCreated", "OnSubscriptionCreated", "OnError"}))
+	})
+	It("does not require hooks to be set", func() {
+		o.Hooks = nil
+		o.CatalogCreator = fakeCatalogCreator{err: errors.New("no catalog")}
+		_, err := o.InstallOperator(context.TODO())
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,198 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
+)
+
+// overrideImages sets the images of the installed CSV's deployment containers to o.ImageOverrides,
+// then waits for OLM to roll out the updated deployments and for the CSV to succeed again.
+// OLM reconciles deployments from their specs in the CSV, so patching the deployments directly
+// would be reverted.
+func (o OperatorInstaller) overrideImages(ctx context.Context,
+	csv *v1alpha1.ClusterServiceVersion) (*v1alpha1.ClusterServiceVersion, error) {
+
+	if len(o.ImageOverrides) == 0 {
+		return csv, nil
+	}
+	key := types.NamespacedName{Namespace: csv.GetNamespace(), Name: csv.GetName()}
+	var updated []string
+	if err := o.cfg.RetryPolicy.OnConflict(ctx, func() (err error) {
+		if err := o.cfg.Client.Get(ctx, key, csv); err != nil {
+			return err
+		}
+		if updated, err = applyImageOverrides(csv, o.ImageOverrides); err != nil {
+			return err
+		}
+		return o.cfg.Client.Update(ctx, csv)
+	}); err != nil {
+		return nil, fmt.Errorf("error overriding images: %v", err)
+	}
+	for _, name := range sortedKeys(o.ImageOverrides) {
+		logutil.WithObject(csv).WithField("image", o.ImageOverrides[name]).
+			Infof("Overrode image of container %q", name)
+	}
+
+	c := olmclient.Client{KubeClient: o.cfg.Client, Notifier: o.cfg.Notifier, CSVHealth: o.CSVHealth}
+	for _, name := range updated {
+		depKey := types.NamespacedName{Namespace: key.Namespace, Name: name}
+		if err := o.waitForDeploymentSpec(ctx, csv, depKey); err != nil {
+			return nil, err
+		}
+		if err := c.DoRolloutWait(ctx, depKey); err != nil {
+			return nil, fmt.Errorf("error waiting for deployment %s to roll out overridden images: %v", name, err)
+		}
+	}
+	if err := c.DoCSVWait(ctx, key); err != nil {
+		return nil, fmt.Errorf("error waiting for CSV to install with overridden images: %w", err)
+	}
+	if err := o.cfg.Client.Get(ctx, key, csv); err != nil {
+		return nil, fmt.Errorf("error getting installed CSV: %w", err)
+	}
+	return csv, nil
+}
+
+// waitForDeploymentSpec waits for OLM to update the deployment key with the CSV's deployment spec.
+func (o OperatorInstaller) waitForDeploymentSpec(ctx context.Context, csv *v1alpha1.ClusterServiceVersion,
+	key types.NamespacedName) error {
+
+	want := map[string]string{}
+	for _, ds := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		if ds.Name == key.Name {
+			want = containerImages(ds.Spec.Template.Spec)
+		}
+	}
+	dep := appsv1.Deployment{}
+	updated := func() (bool, error) {
+		if err := o.cfg.Client.Get(ctx, key, &dep); err != nil {
+			return false, err
+		}
+		got := containerImages(dep.Spec.Template.Spec)
+		for name, image := range want {
+			if got[name] != image {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	if err := wait.PollImmediateUntil(time.Second, updated, ctx.Done()); err != nil {
+		return fmt.Errorf("error waiting for deployment %s to be updated with overridden images: %v", key.Name, err)
+	}
+	return nil
+}
+
+// applyImageOverrides sets the images of containers and init containers in csv's deployment specs to
+// overrides, keyed by container name, or by "<deployment>/<container>" to override a single deployment's
+// container. A container name shared by containers of several deployments is rejected, since the override
+// is ambiguous. It returns the names of updated deployments, and an error if an override matches no container.
+func applyImageOverrides(csv *v1alpha1.ClusterServiceVersion, overrides map[string]string) ([]string, error) {
+	deploymentSpecs := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+	// Deployments of each container name are collected first, so ambiguous overrides change nothing.
+	deployments := map[string][]string{}
+	for _, ds := range deploymentSpecs {
+		for name := range containerImages(ds.Spec.Template.Spec) {
+			deployments[name] = append(deployments[name], ds.Name)
+		}
+	}
+	var unmatched, ambiguous []string
+	for _, key := range sortedKeys(overrides) {
+		if strings.Contains(key, "/") {
+			continue
+		}
+		switch names := deployments[key]; {
+		case len(names) == 0:
+			unmatched = append(unmatched, key)
+		case len(names) > 1:
+			ambiguous = append(ambiguous, fmt.Sprintf("%s (in %s)", key, strings.Join(names, ", ")))
+		}
+	}
+	if len(ambiguous) != 0 {
+		return nil, fmt.Errorf("image overrides %s match containers of several deployments in CSV %s; "+
+			"use <deployment>/<container> instead", strings.Join(ambiguous, "; "), csv.GetName())
+	}
+
+	matched := map[string]bool{}
+	var updated []string
+	for i := range deploymentSpecs {
+		ds := &deploymentSpecs[i]
+		changed := false
+		podSpec := &ds.Spec.Template.Spec
+		for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+			for j := range containers {
+				// A deployment's container override takes precedence over one by container name.
+				image, found := "", false
+				for _, key := range []string{containers[j].Name, ds.Name + "/" + containers[j].Name} {
+					if override, ok := overrides[key]; ok {
+						matched[key] = true
+						image, found = override, true
+					}
+				}
+				if found {
+					changed = changed || containers[j].Image != image
+					containers[j].Image = image
+				}
+			}
+		}
+		if changed {
+			updated = append(updated, ds.Name)
+		}
+	}
+
+	for _, key := range sortedKeys(overrides) {
+		if strings.Contains(key, "/") && !matched[key] {
+			unmatched = append(unmatched, key)
+		}
+	}
+	if len(unmatched) != 0 {
+		sort.Strings(unmatched)
+		return nil, fmt.Errorf("no container in CSV %s matches image overrides %s",
+			csv.GetName(), strings.Join(unmatched, ", "))
+	}
+	return updated, nil
+}
+
+// containerImages maps the names of a pod's containers and init containers to their images.
+func containerImages(podSpec corev1.PodSpec) map[string]string {
+	images := map[string]string{}
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for _, c := range containers {
+			images[c.Name] = c.Image
+		}
+	}
+	return images
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("applyImageOverrides", func() {
+	var csv *v1alpha1.ClusterServiceVersion
+
+	deploymentSpec := func(name string, containers ...string) v1alpha1.StrategyDeploymentSpec {
+		ds := v1alpha1.StrategyDeploymentSpec{Name: name, Spec: appsv1.DeploymentSpec{}}
+		for _, c := range containers {
+			ds.Spec.Template.Spec.Containers = append(ds.Spec.Template.Spec.Containers,
+				corev1.Container{Name: c, Image: "quay.io/example/" + c + ":v0.0.1"})
+		}
+		return ds
+	}
+	images := func() map[string]string {
+		m := map[string]string{}
+		for _, ds := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+			for _, c := range ds.Spec.Template.Spec.Containers {
+				m[ds.Name+"/"+c.Name] = c.Image
+			}
+		}
+		return m
+	}
+
+	BeforeEach(func() {
+		csv = &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "memcached-operator.v0.0.1"},
+			Spec: v1alpha1.ClusterServiceVersionSpec{
+				InstallStrategy: v1alpha1.NamedInstallStrategy{
+					StrategySpec: v1alpha1.StrategyDetailsDeployment{
+						DeploymentSpecs: []v1alpha1.StrategyDeploymentSpec{
+							deploymentSpec("controller-manager", "manager", "kube-rbac-proxy"),
+							deploymentSpec("webhook", "manager"),
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("overrides containers by name", func() {
+		updated, err := applyImageOverrides(csv, map[string]string{"kube-rbac-proxy": "localhost:5000/proxy:dev"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(Equal([]string{"controller-manager"}))
+		Expect(images()).To(Equal(map[string]string{
+			"controller-manager/manager":         "quay.io/example/manager:v0.0.1",
+			"controller-manager/kube-rbac-proxy": "localhost:5000/proxy:dev",
+			"webhook/manager":                    "quay.io/example/manager:v0.0.1",
+		}))
+	})
+	It("fails if a container name is shared by several deployments", func() {
+		_, err := applyImageOverrides(csv, map[string]string{"manager": "localhost:5000/manager:dev"})
+		Expect(err).To(MatchError(ContainSubstring("manager (in controller-manager, webhook)")))
+		Expect(images()).To(HaveKeyWithValue("controller-manager/manager", "quay.io/example/manager:v0.0.1"))
+	})
+	It("prefers a deployment's container override to one by container name", func() {
+		updated, err := applyImageOverrides(csv, map[string]string{
+			"kube-rbac-proxy":                    "localhost:5000/proxy:dev",
+			"controller-manager/kube-rbac-proxy": "quay.io/example/kube-rbac-proxy:v0.0.1",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeEmpty())
+	})
+	It("overrides a single deployment's container", func() {
+		updated, err := applyImageOverrides(csv, map[string]string{"webhook/manager": "localhost:5000/webhook:dev"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(Equal([]string{"webhook"}))
+		Expect(images()).To(HaveKeyWithValue("controller-manager/manager", "quay.io/example/manager:v0.0.1"))
+		Expect(images()).To(HaveKeyWithValue("webhook/manager", "localhost:5000/webhook:dev"))
+	})
+	It("does not report deployments whose images are unchanged", func() {
+		updated, err := applyImageOverrides(csv, map[string]string{"webhook/manager": "quay.io/example/manager:v0.0.1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeEmpty())
+	})
+	It("fails if an override matches no container", func() {
+		_, err := applyImageOverrides(csv, map[string]string{"webhook/manager": "m:dev", "proxy": "p:dev", "webhook/x": "x:dev"})
+		Expect(err).To(MatchError(ContainSubstring("proxy, webhook/x")))
+	})
+})
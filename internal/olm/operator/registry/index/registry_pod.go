@@ -25,15 +25,16 @@ import (
 	"time"
 
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
-	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
 )
 
 // BundleAddModeType - type of BundleAddMode in RegistryPod struct
@@ -78,6 +79,14 @@ type RegistryPod struct {
 	// GRPCPort is the container grpc port
 	GRPCPort int32
 
+	// SecurityContextConfig is the security context applied to the pod when created.
+	// If restricted, the pod can run in namespaces enforcing the "restricted" Pod Security Standard.
+	SecurityContextConfig operator.SecurityContextConfig
+
+	// NodeSelector and Tolerations schedule the pod, ex. onto nodes required by cluster policies.
+	NodeSelector map[string]string
+	Tolerations  []corev1.Toleration
+
 	// pod represents a kubernetes *corev1.pod that will be created on a cluster using an index image
 	pod *corev1.Pod
 
@@ -136,7 +145,14 @@ func (rp *RegistryPod) Create(ctx context.Context, cs *v1alpha1.CatalogSource) (
 		return nil, fmt.Errorf("set registry pod owner reference: %v", err)
 	}
 
-	if err := rp.cfg.Client.Create(ctx, rp.pod); err != nil {
+	var obj runtime.Object = rp.pod
+	if rp.SecurityContextConfig == operator.SecurityContextConfigRestricted {
+		var err error
+		if obj, err = restrictedPod(rp.pod, rp.DBPath, rp.IndexImage == defaultIndexImage); err != nil {
+			return nil, fmt.Errorf("set registry pod security context: %v", err)
+		}
+	}
+	if err := rp.cfg.Client.Create(ctx, obj); err != nil {
 		return nil, fmt.Errorf("create registry pod: %v", err)
 	}
 
@@ -159,7 +175,8 @@ func (rp *RegistryPod) Create(ctx context.Context, cs *v1alpha1.CatalogSource) (
 	if err := rp.checkPodStatus(ctx, podCheck); err != nil {
 		return nil, fmt.Errorf("registry pod did not become ready: %w", err)
 	}
-	log.Infof("Successfully created registry pod: %s", rp.pod.Name)
+	logutil.WithObject(rp.pod).WithField(logutil.PhaseKey, rp.pod.Status.Phase).
+		Info("Successfully created registry pod")
 	return rp.pod, nil
 }
 
@@ -237,6 +254,8 @@ func (rp *RegistryPod) podForBundleRegistry() (*corev1.Pod, error) {
 					},
 				},
 			},
+			NodeSelector: rp.NodeSelector,
+			Tolerations:  rp.Tolerations,
 		},
 	}
 
@@ -24,6 +24,8 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -102,6 +104,42 @@ var _ = Describe("RegistryPod", func() {
 				}
 			})
 
+			It("should schedule the pod with its node selector and tolerations", func() {
+				rp.NodeSelector = map[string]string{"node-role.kubernetes.io/infra": ""}
+				rp.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+				pod, err := rp.podForBundleRegistry()
+				Expect(err).To(BeNil())
+				Expect(pod.Spec.NodeSelector).To(Equal(rp.NodeSelector))
+				Expect(pod.Spec.Tolerations).To(Equal(rp.Tolerations))
+			})
+
+			It("should restrict the pod's security context", func() {
+				u, err := restrictedPod(rp.pod, rp.DBPath, true)
+				Expect(err).To(BeNil())
+
+				profile, _, err := unstructured.NestedString(u.Object, "spec", "securityContext", "seccompProfile", "type")
+				Expect(err).To(BeNil())
+				Expect(profile).To(Equal("RuntimeDefault"))
+				runAsNonRoot, _, err := unstructured.NestedBool(u.Object, "spec", "securityContext", "runAsNonRoot")
+				Expect(err).To(BeNil())
+				Expect(runAsNonRoot).To(BeTrue())
+				_, hasUser, _ := unstructured.NestedFieldNoCopy(u.Object, "spec", "securityContext", "runAsUser")
+				Expect(hasUser).To(BeFalse())
+
+				containers, _, err := unstructured.NestedSlice(u.Object, "spec", "containers")
+				Expect(err).To(BeNil())
+				container := containers[0].(map[string]interface{})
+				drop, _, err := unstructured.NestedStringSlice(container, "securityContext", "capabilities", "drop")
+				Expect(err).To(BeNil())
+				Expect(drop).To(Equal([]string{"ALL"}))
+				mounts, _, err := unstructured.NestedSlice(container, "volumeMounts")
+				Expect(err).To(BeNil())
+				Expect(mounts[0].(map[string]interface{})["mountPath"]).To(Equal("/database"))
+
+				// The original pod must not be modified.
+				Expect(rp.pod.Spec.SecurityContext).To(BeNil())
+			})
+
 			It("check pod status should return successfully when pod check is true", func() {
 				mockGoodPodCheck := wait.ConditionFunc(func() (done bool, err error) {
 					return true, nil
@@ -0,0 +1,89 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	databaseVolumeName = "database"
+	// seccompProfileRuntimeDefault is the only seccomp profile type allowed by the "restricted"
+	// Pod Security Standard besides Localhost.
+	seccompProfileRuntimeDefault = "RuntimeDefault"
+)
+
+// restrictedPod returns a copy of pod whose security context satisfies the "restricted"
+// Pod Security Standard and OpenShift's restricted-v2 SecurityContextConstraints: all
+// capabilities are dropped, privilege escalation is disallowed, the pod runs as non-root
+// with the RuntimeDefault seccomp profile, and no UID is fixed so OpenShift can assign one.
+//
+// The seccompProfile field does not exist in the vendored core/v1 API,
+// so the pod is returned as unstructured.
+func restrictedPod(pod *corev1.Pod, dbPath string, ownDatabase bool) (*unstructured.Unstructured, error) {
+	pod = pod.DeepCopy()
+	runAsNonRoot, allowPrivilegeEscalation := true, false
+	pod.Spec.SecurityContext = &corev1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot}
+
+	// An arbitrary UID cannot write to the root filesystem, so a database created
+	// by the registry server itself must be written to a volume.
+	if ownDatabase {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name:         databaseVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		c.SecurityContext = &corev1.SecurityContext{
+			AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		}
+		if ownDatabase {
+			c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+				Name:      databaseVolumeName,
+				MountPath: path.Dir(dbPath),
+			})
+		}
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: obj}
+	u.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Pod"))
+	seccompProfile := map[string]interface{}{"type": seccompProfileRuntimeDefault}
+	if err := unstructured.SetNestedField(u.Object, seccompProfile, "spec", "securityContext", "seccompProfile"); err != nil {
+		return nil, err
+	}
+	containers, _, err := unstructured.NestedSlice(u.Object, "spec", "containers")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range containers {
+		if err := unstructured.SetNestedField(c.(map[string]interface{}), seccompProfile, "securityContext", "seccompProfile"); err != nil {
+			return nil, err
+		}
+	}
+	if err := unstructured.SetNestedSlice(u.Object, containers, "spec", "containers"); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -18,15 +18,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry/index"
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
+)
+
+// Annotations of catalog sources created by IndexImageCatalogCreator, recording how their registry was built.
+const (
+	indexImageAnnotation       = "operators.operatorframework.io/index-image"
+	injectBundleModeAnnotation = "operators.operatorframework.io/inject-bundle-mode"
+	injectedBundlesAnnotation  = "operators.operatorframework.io/injected-bundles"
 )
 
 type IndexImageCatalogCreator struct {
@@ -36,6 +48,12 @@ type IndexImageCatalogCreator struct {
 	InjectBundleMode string
 	BundleImage      string
 
+	// SecurityContextConfig is the security context of the registry pod.
+	// If empty, it is detected from the cluster and namespace.
+	SecurityContextConfig operator.SecurityContextConfig
+
+	CatalogSourceConfig
+
 	cfg *operator.Configuration
 }
 
@@ -51,9 +69,12 @@ func (c IndexImageCatalogCreator) CreateCatalog(ctx context.Context, name string
 		return nil, fmt.Errorf("get database path: %v", err)
 	}
 
+	if err := c.detectSecurityContextConfig(ctx); err != nil {
+		return nil, err
+	}
+
 	// create a basic catalog source type
-	cs := newCatalogSource(name, c.cfg.Namespace,
-		withSDKPublisher(c.PackageName))
+	cs := c.catalogSource(name)
 
 	// create catalog source resource
 	if err := c.cfg.Client.Create(ctx, cs); err != nil {
@@ -61,7 +82,7 @@ func (c IndexImageCatalogCreator) CreateCatalog(ctx context.Context, name string
 	}
 
 	// create registry pod
-	pod, err := c.createRegistryPod(ctx, dbPath, cs)
+	pod, err := c.createRegistryPod(ctx, dbPath, c.BundleImage, cs)
 	if err != nil {
 		return nil, fmt.Errorf("error creating registry pod: %v", err)
 	}
@@ -74,6 +95,80 @@ func (c IndexImageCatalogCreator) CreateCatalog(ctx context.Context, name string
 	return cs, nil
 }
 
+// UpdateCatalog adds BundleImage to cs, a catalog source created by CreateCatalog, by serving it from a new
+// registry pod that adds BundleImage after the bundles already injected into cs. The old registry pods are
+// deleted once cs is served by the new one.
+func (c IndexImageCatalogCreator) UpdateCatalog(ctx context.Context, cs *v1alpha1.CatalogSource) error {
+	annotations := cs.GetAnnotations()
+	indexImage, ok := annotations[indexImageAnnotation]
+	if !ok {
+		return fmt.Errorf("catalog source %q was not created from an index image by 'run bundle'", cs.GetName())
+	}
+	var injected []string
+	if err := json.Unmarshal([]byte(annotations[injectedBundlesAnnotation]), &injected); err != nil {
+		return fmt.Errorf("error unmarshaling injected bundles of catalog source %q: %v", cs.GetName(), err)
+	}
+	for _, b := range injected {
+		if b == c.BundleImage {
+			return fmt.Errorf("bundle %s is already in catalog source %q", c.BundleImage, cs.GetName())
+		}
+	}
+	c.IndexImage = indexImage
+	c.InjectBundleMode = annotations[injectBundleModeAnnotation]
+	c.InjectBundles = append(injected, c.BundleImage)
+
+	dbPath, err := c.getDBPath(ctx)
+	if err != nil {
+		return fmt.Errorf("get database path: %v", err)
+	}
+	if err := c.detectSecurityContextConfig(ctx); err != nil {
+		return err
+	}
+
+	oldPods := corev1.PodList{}
+	if err := c.cfg.Client.List(ctx, &oldPods, client.InNamespace(cs.GetNamespace())); err != nil {
+		return fmt.Errorf("error listing registry pods: %v", err)
+	}
+	pod, err := c.createRegistryPod(ctx, dbPath, strings.Join(c.InjectBundles, ","), cs)
+	if err != nil {
+		return fmt.Errorf("error creating registry pod: %v", err)
+	}
+	if err := c.updateCatalogSource(ctx, pod.Status.PodIP, cs); err != nil {
+		return fmt.Errorf("error updating catalog source: %v", err)
+	}
+
+	for i := range oldPods.Items {
+		old := &oldPods.Items[i]
+		if old.GetName() == pod.GetName() || !metav1.IsControlledBy(old, cs) {
+			continue
+		}
+		if err := c.cfg.Client.Delete(ctx, old); err != nil && !apierrors.IsNotFound(err) {
+			log.Warnf("Failed to delete old registry pod %s: %v", old.GetName(), err)
+			continue
+		}
+		logutil.WithObject(old).Info("Deleted old registry pod")
+	}
+	return nil
+}
+
+// detectSecurityContextConfig sets the registry pod's security context config from the cluster if unset.
+func (c *IndexImageCatalogCreator) detectSecurityContextConfig(ctx context.Context) (err error) {
+	if !c.SecurityContextConfig.IsEmpty() {
+		return nil
+	}
+	if c.SecurityContextConfig, err = operator.DetectSecurityContextConfig(ctx, c.cfg, c.cfg.Namespace); err != nil {
+		return fmt.Errorf("detect security context config: %v", err)
+	}
+	log.Debugf("Using %q security context config for registry pod", c.SecurityContextConfig)
+	return nil
+}
+
+func (c IndexImageCatalogCreator) catalogSource(name string) *v1alpha1.CatalogSource {
+	return newCatalogSource(name, c.cfg.Namespace,
+		withSDKPublisher(c.PackageName),
+		withCatalogSourceConfig(c.CatalogSourceConfig))
+}
+
 const defaultDBPath = "/database/index.db"
 
 func (c IndexImageCatalogCreator) getDBPath(ctx context.Context) (string, error) {
@@ -87,12 +182,16 @@ func (c IndexImageCatalogCreator) getDBPath(ctx context.Context) (string, error)
 	return defaultDBPath, nil
 }
 
-func (c IndexImageCatalogCreator) createRegistryPod(ctx context.Context, dbPath string, cs *v1alpha1.CatalogSource) (*corev1.Pod, error) {
+func (c IndexImageCatalogCreator) createRegistryPod(ctx context.Context, dbPath, bundleImages string,
+	cs *v1alpha1.CatalogSource) (*corev1.Pod, error) {
 	// Initialize registry pod
-	registryPod, err := index.NewRegistryPod(c.cfg, dbPath, c.BundleImage)
+	registryPod, err := index.NewRegistryPod(c.cfg, dbPath, bundleImages)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing registry pod: %v", err)
 	}
+	registryPod.SecurityContextConfig = c.SecurityContextConfig
+	registryPod.NodeSelector = c.NodeSelector
+	registryPod.Tolerations = c.Tolerations
 
 	var pod *corev1.Pod
 	// Create registry pod
@@ -118,15 +217,15 @@ func (c IndexImageCatalogCreator) updateCatalogSource(ctx context.Context, podAd
 
 	// Annotations for catalog source
 	annotationMapping := map[string]string{
-		"operators.operatorframework.io/index-image":        c.IndexImage,
-		"operators.operatorframework.io/inject-bundle-mode": c.InjectBundleMode,
-		"operators.operatorframework.io/injected-bundles":   string(injectedBundlesJSON),
+		indexImageAnnotation:       c.IndexImage,
+		injectBundleModeAnnotation: c.InjectBundleMode,
+		injectedBundlesAnnotation:  string(injectedBundlesJSON),
 	}
 	// Update catalog source with source type as grpc and address as the pod IP,
 	// and annotations for index image, injected bundles, and registry bundle add mode
-	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+	if err := c.cfg.RetryPolicy.OnConflict(ctx, func() error {
 		if err := c.cfg.Client.Get(ctx, catsrcKey, cs); err != nil {
-			return fmt.Errorf("error getting catalog source: %v", err)
+			return err
 		}
 		cs.Spec.Address = index.GetRegistryPodHost(podAddr)
 		cs.Spec.SourceType = v1alpha1.SourceTypeGrpc
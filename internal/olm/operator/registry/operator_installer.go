@@ -26,11 +26,12 @@ import (
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
 )
 
 type OperatorInstaller struct {
@@ -40,6 +41,26 @@ type OperatorInstaller struct {
 	Channel           string
 	InstallMode       operator.InstallMode
 	CatalogCreator    CatalogCreator
+	// ImageOverrides maps container names, or "<deployment>/<container>", to images that replace
+	// the images of the installed CSV's deployment containers. Container names must be unique
+	// across the CSV's deployments.
+	ImageOverrides map[string]string
+	// SubscriptionConfig configures the operator's pods through the Subscription's config.
+	SubscriptionConfig SubscriptionConfig
+	// CSVHealth is the criterion the installed CSV must meet for the install to complete.
+	CSVHealth olmclient.CSVHealth
+	// SkipOperatorGroup skips creating an OperatorGroup, for namespaces whose OperatorGroups are managed
+	// by something else. An existing OperatorGroup must cover InstallMode's target namespaces instead.
+	SkipOperatorGroup bool
+	// CatalogUpdater adds the upgrade's bundle to the installed operator's catalog in UpgradeOperator.
+	CatalogUpdater CatalogUpdater
+	// IgnoreOperatorCondition upgrades operators whose OperatorCondition reports they are not upgradeable.
+	IgnoreOperatorCondition bool
+	// Hooks, if set, are called as the install progresses.
+	Hooks InstallerHooks
+	// Timings, if set, is set to the durations of the install's phases once it completes or fails.
+	// Timings are also logged once the install completes or fails.
+	Timings *InstallTimings
 
 	cfg *operator.Configuration
 }
@@ -49,11 +70,86 @@ func NewOperatorInstaller(cfg *operator.Configuration) *OperatorInstaller {
 }
 
 func (o OperatorInstaller) InstallOperator(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
-	cs, err := o.CatalogCreator.CreateCatalog(ctx, o.CatalogSourceName)
+	hooks := o.hooks()
+	ctx, span := tracing.Start(ctx, "install operator", tracing.String("package", o.PackageName),
+		tracing.String("namespace", o.cfg.Namespace))
+	timings := &InstallTimings{}
+	start := time.Now()
+	csv, err := o.installOperator(ctx, hooks, timings)
+	timings.Total = time.Since(start)
+	span.End(err)
+	timings.Succeeded = err == nil
+	log.WithFields(timings.Fields()).Info("Install phase timings")
+	if o.Timings != nil {
+		*o.Timings = *timings
+	}
 	if err != nil {
-		return nil, fmt.Errorf("create catalog: %v", err)
+		hooks.OnError(err)
+		return nil, err
 	}
-	log.Infof("Created CatalogSource: %s", cs.GetName())
+	return csv, nil
+}
+
+// Subscribe creates the install's catalog, OperatorGroup, and Subscription, returning the Subscription without
+// waiting for OLM to install the operator. ResumeInstall completes the install later.
+func (o OperatorInstaller) Subscribe(ctx context.Context) (*v1alpha1.Subscription, error) {
+	ctx, span := tracing.Start(ctx, "subscribe", tracing.String("package", o.PackageName),
+		tracing.String("namespace", o.cfg.Namespace))
+	sub, err := o.subscribe(ctx, o.hooks(), &InstallTimings{})
+	span.End(err)
+	return sub, err
+}
+
+// ResumeInstall completes an install started by Subscribe: it approves the install plan OLM creates for the
+// Subscription named subName, and waits for the CSV to be installed.
+func (o OperatorInstaller) ResumeInstall(ctx context.Context, subName string) (*v1alpha1.ClusterServiceVersion, error) {
+	ctx, span := tracing.Start(ctx, "resume install", tracing.String("package", o.PackageName),
+		tracing.String("namespace", o.cfg.Namespace))
+	sub := &v1alpha1.Subscription{}
+	err := o.cfg.Client.Get(ctx, types.NamespacedName{Namespace: o.cfg.Namespace, Name: subName}, sub)
+	if err != nil {
+		err = fmt.Errorf("error getting subscription: %w", err)
+		span.End(err)
+		return nil, err
+	}
+	csv, err := o.waitForInstall(ctx, o.hooks(), &InstallTimings{}, sub)
+	span.End(err)
+	return csv, err
+}
+
+func (o OperatorInstaller) hooks() InstallerHooks {
+	if o.Hooks == nil {
+		return NoopInstallerHooks{}
+	}
+	return o.Hooks
+}
+
+func (o OperatorInstaller) installOperator(ctx context.Context, hooks InstallerHooks,
+	timings *InstallTimings) (*v1alpha1.ClusterServiceVersion, error) {
+	subscription, err := o.subscribe(ctx, hooks, timings)
+	if err != nil {
+		return nil, err
+	}
+	return o.waitForInstall(ctx, hooks, timings, subscription)
+}
+
+// subscribe creates the install's catalog, OperatorGroup, Subscription, and Operator resource.
+func (o OperatorInstaller) subscribe(ctx context.Context, hooks InstallerHooks,
+	timings *InstallTimings) (*v1alpha1.Subscription, error) {
+	if err := o.dryRunCreate(ctx); err != nil {
+		return nil, err
+	}
+
+	var cs *v1alpha1.CatalogSource
+	err := timings.timePhase(ctx, PhaseCatalogReady, func(ctx context.Context) (err error) {
+		cs, err = o.CatalogCreator.CreateCatalog(ctx, o.CatalogSourceName)
+		return err
+	})
+	if err != nil {
+		return nil, categorizedError{fmt.Errorf("create catalog: %w", err), ErrCatalogFailed}
+	}
+	logutil.WithObject(cs).Info("Created CatalogSource")
+	hooks.OnCatalogCreated(cs)
 
 	// TODO: OLM doesn't appear to propagate the "READY" connection status to the catalogsource in a timely manner
 	// even though its catalog-operator reports a connection almost immediately. This condition either needs
@@ -64,7 +160,12 @@ func (o OperatorInstaller) InstallOperator(ctx context.Context) (*v1alpha1.Clust
 	// }
 
 	// Ensure Operator Group
-	if err = o.createOperatorGroup(ctx); err != nil {
+	if o.SkipOperatorGroup {
+		err = o.checkOperatorGroup(ctx)
+	} else {
+		err = o.createOperatorGroup(ctx)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -73,24 +174,61 @@ func (o OperatorInstaller) InstallOperator(ctx context.Context) (*v1alpha1.Clust
 	if subscription, err = o.createSubscription(ctx, cs); err != nil {
 		return nil, err
 	}
+	hooks.OnSubscriptionCreated(subscription)
+
+	// The Operator resource aggregates references to the operator's components, which status and
+	// cleanup use. It is not required to install the operator, ex. by users who cannot create it.
+	if op, err := operator.EnsureOperatorResource(ctx, o.cfg, o.PackageName); err != nil {
+		log.Warnf("Failed to create Operator resource: %v", err)
+	} else if op != nil {
+		logutil.WithObject(op).Info("Ensured Operator")
+	}
+	return subscription, nil
+}
 
+// waitForInstall approves the install plan of subscription once generated, and waits for its CSV to succeed.
+func (o OperatorInstaller) waitForInstall(ctx context.Context, hooks InstallerHooks, timings *InstallTimings,
+	subscription *v1alpha1.Subscription) (*v1alpha1.ClusterServiceVersion, error) {
 	// Wait for the Install Plan to be generated
-	if err = o.waitForInstallPlan(ctx, subscription); err != nil {
+	if err := timings.timePhase(ctx, PhaseInstallPlanGenerated, func(ctx context.Context) error {
+		return o.waitForInstallPlan(ctx, subscription)
+	}); err != nil {
 		return nil, err
 	}
+	return o.completeInstall(ctx, hooks, timings, subscription)
+}
 
+// completeInstall approves the install plan referenced by subscription, and waits for its CSV to succeed.
+func (o OperatorInstaller) completeInstall(ctx context.Context, hooks InstallerHooks, timings *InstallTimings,
+	subscription *v1alpha1.Subscription) (*v1alpha1.ClusterServiceVersion, error) {
 	// Approve Install Plan for the subscription
-	if err = o.approveInstallPlan(ctx, subscription); err != nil {
+	var ip *v1alpha1.InstallPlan
+	if err := timings.timePhase(ctx, PhaseInstallPlanApproved, func(ctx context.Context) (err error) {
+		ip, err = o.approveInstallPlan(ctx, subscription)
+		return err
+	}); err != nil {
 		return nil, err
 	}
+	hooks.OnInstallPlanApproved(ip)
 
 	// Wait for successfully installed CSV
-	csv, err := o.getInstalledCSV(ctx)
+	var csv *v1alpha1.ClusterServiceVersion
+	if err := timings.timePhase(ctx, PhaseCSVReady, func(ctx context.Context) (err error) {
+		csv, err = o.getInstalledCSV(ctx)
+		return err
+	}); err != nil {
+		// A CSV is never created if OLM fails to unpack its bundle, so surface any unpack failure.
+		o.logBundleUnpackJobs(subscription)
+		return nil, err
+	}
+	csv, err := o.overrideImages(ctx, csv)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Infof("OLM has successfully installed %q", o.StartingCSV)
+	logutil.WithObject(csv).WithField(logutil.PhaseKey, csv.Status.Phase).
+		Info("OLM has successfully installed the operator")
+	hooks.OnCSVSucceeded(csv)
 
 	return csv, nil
 }
@@ -115,7 +253,8 @@ func (o OperatorInstaller) waitForCatalogSource(ctx context.Context, cs *v1alpha
 		return false, nil
 	})
 
-	if err := wait.PollImmediateUntil(200*time.Millisecond, catSrcCheck, ctx.Done()); err != nil {
+	if err := olmclient.WaitForChange(ctx, o.cfg.Notifier, cs.GetNamespace(), 200*time.Millisecond, catSrcCheck,
+		&v1alpha1.CatalogSource{}); err != nil {
 		return fmt.Errorf("catalog source connection is not ready: %v", err)
 	}
 
@@ -147,13 +286,15 @@ func (o OperatorInstaller) createOperatorGroup(ctx context.Context) error {
 		if !reflect.DeepEqual(og.Status.Namespaces, targetNamespaces) {
 			msg := fmt.Sprintf("namespaces %+q do not match desired namespaces %+q", og.Status.Namespaces, targetNamespaces)
 			if og.GetName() == operator.SDKOperatorGroupName {
-				return fmt.Errorf("existing SDK-managed operator group's %s, "+
-					"please clean up existing operators `operator-sdk cleanup` before running package %q", msg, o.PackageName)
+				return categorizedError{fmt.Errorf("existing SDK-managed operator group's %s, "+
+					"please clean up existing operators `operator-sdk cleanup` before running package %q", msg, o.PackageName),
+					ErrOperatorGroupConflict}
 			}
-			return fmt.Errorf("existing operator group %q's %s, "+
-				"please ensure it has the exact namespace set before running package %q", og.GetName(), msg, o.PackageName)
+			return categorizedError{fmt.Errorf("existing operator group %q's %s, "+
+				"please ensure it has the exact namespace set before running package %q", og.GetName(), msg, o.PackageName),
+				ErrOperatorGroupConflict}
 		}
-		log.Infof("Using existing operator group %q", og.GetName())
+		logutil.WithObject(og).Info("Using existing OperatorGroup")
 	} else {
 		// New SDK-managed OperatorGroup.
 		og = newSDKOperatorGroup(o.cfg.Namespace,
@@ -161,12 +302,65 @@ func (o OperatorInstaller) createOperatorGroup(ctx context.Context) error {
 		if err = o.cfg.Client.Create(ctx, og); err != nil {
 			return fmt.Errorf("error creating OperatorGroup: %w", err)
 		}
-		log.Infof("Created OperatorGroup: %s", og.GetName())
+		logutil.WithObject(og).Info("Created OperatorGroup")
 
 	}
 	return nil
 }
 
+// checkOperatorGroup checks that an OperatorGroup not managed by the SDK exists and covers the target namespaces
+// of the install mode. Any OperatorGroup is accepted if no install mode is set.
+func (o OperatorInstaller) checkOperatorGroup(ctx context.Context) error {
+	og, ogFound, err := o.getOperatorGroup(ctx)
+	if err != nil {
+		return err
+	}
+	if !ogFound {
+		return fmt.Errorf("no operator group in namespace %q, please create one targeting the namespaces "+
+			"the operator should watch, or unset --skip-operator-group to have one created", o.cfg.Namespace)
+	}
+
+	namespaces := og.Status.Namespaces
+	if namespaces == nil {
+		namespaces = og.Spec.TargetNamespaces
+	}
+	allNamespaces := len(namespaces) == 0 || (len(namespaces) == 1 && namespaces[0] == "")
+	var uncovered []string
+	switch o.InstallMode.InstallModeType {
+	case "":
+	case v1alpha1.InstallModeTypeAllNamespaces:
+		if !allNamespaces {
+			uncovered = []string{""}
+		}
+	default:
+		targetNamespaces := o.InstallMode.TargetNamespaces
+		if o.InstallMode.InstallModeType == v1alpha1.InstallModeTypeOwnNamespace {
+			targetNamespaces = []string{o.cfg.Namespace}
+		}
+		for _, ns := range targetNamespaces {
+			if !allNamespaces && !containsString(namespaces, ns) {
+				uncovered = append(uncovered, ns)
+			}
+		}
+	}
+	if len(uncovered) != 0 {
+		return categorizedError{fmt.Errorf("existing operator group %q's namespaces %+q do not cover install mode "+
+			"%s's namespaces %+q, please add them to its target namespaces, or install with an install mode it covers",
+			og.GetName(), namespaces, o.InstallMode, uncovered), ErrOperatorGroupConflict}
+	}
+	logutil.WithObject(og).WithField("namespaces", namespaces).Info("Using existing OperatorGroup")
+	return nil
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
 // getOperatorGroup returns true if an OperatorGroup in the desired namespace was found.
 // If more than one operator group exists in namespace, this function will return an error
 // since CSVs in namespace will have an error status in that case.
@@ -183,53 +377,59 @@ func (o OperatorInstaller) getOperatorGroup(ctx context.Context) (*v1.OperatorGr
 		for _, og := range ogList.Items {
 			names = append(names, og.GetName())
 		}
-		return nil, true, fmt.Errorf("more than one operator group in namespace %s: %+q", o.cfg.Namespace, names)
+		return nil, true, categorizedError{fmt.Errorf("more than one operator group in namespace %s: %+q",
+			o.cfg.Namespace, names), ErrOperatorGroupConflict}
 	}
 	return &ogList.Items[0], true, nil
 }
 
 func (o OperatorInstaller) createSubscription(ctx context.Context, cs *v1alpha1.CatalogSource) (*v1alpha1.Subscription, error) {
-	sub := newSubscription(o.StartingCSV, o.cfg.Namespace,
-		withPackageChannel(o.PackageName, o.Channel, o.StartingCSV),
-		withCatalogSource(cs.GetName(), o.cfg.Namespace),
-		withInstallPlanApproval(v1alpha1.ApprovalManual))
-
+	sub := o.newSubscription(cs)
 	if err := o.cfg.Client.Create(ctx, sub); err != nil {
 		return nil, fmt.Errorf("error creating subscription: %w", err)
 	}
-	log.Infof("Created Subscription: %s", sub.Name)
+	logutil.WithObject(sub).Info("Created Subscription")
 
 	return sub, nil
 }
 
+// newSubscription returns the Subscription to the install's package in cs.
+func (o OperatorInstaller) newSubscription(cs *v1alpha1.CatalogSource) *v1alpha1.Subscription {
+	return newSubscription(o.StartingCSV, o.cfg.Namespace,
+		withPackageChannel(o.PackageName, o.Channel, o.StartingCSV),
+		withCatalogSource(cs.GetName(), o.cfg.Namespace),
+		withInstallPlanApproval(v1alpha1.ApprovalManual),
+		withSubscriptionConfig(o.SubscriptionConfig))
+}
+
 func (o OperatorInstaller) getInstalledCSV(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
-	c, err := olmclient.NewClientForConfig(o.cfg.RESTConfig)
-	if err != nil {
-		return nil, err
-	}
+	c := olmclient.Client{KubeClient: o.cfg.Client, Notifier: o.cfg.Notifier, CSVHealth: o.CSVHealth}
 
-	// BUG(estroz): if namespace is not contained in targetNamespaces,
-	// DoCSVWait will fail because the CSV is not deployed in namespace.
 	nn := types.NamespacedName{
 		Name:      o.StartingCSV,
 		Namespace: o.cfg.Namespace,
 	}
-	log.Infof("Waiting for ClusterServiceVersion %q to reach 'Succeeded' phase", nn)
-	if err = c.DoCSVWait(ctx, nn); err != nil {
+	logutil.WithResource(v1alpha1.ClusterServiceVersionKind, nn).
+		Infof("Waiting for ClusterServiceVersion to %s", o.CSVHealth.Description())
+	// The CSV may be installed in another namespace than cfg.Namespace, ex. if the operator group of
+	// the namespace it was installed into does not target cfg.Namespace.
+	nn, err := c.WaitForCSV(ctx, nn)
+	if err != nil {
 		return nil, fmt.Errorf("error waiting for CSV to install: %w", err)
 	}
 
 	// TODO: check status of all resources in the desired bundle/package.
 	csv := &v1alpha1.ClusterServiceVersion{}
-	if err = o.cfg.Client.Get(ctx, nn, csv); err != nil {
+	if err := o.cfg.Client.Get(ctx, nn, csv); err != nil {
 		return nil, fmt.Errorf("error getting installed CSV: %w", err)
 	}
 	return csv, nil
 }
 
 // approveInstallPlan approves the install plan for a subscription, which will
-// generate a CSV
-func (o OperatorInstaller) approveInstallPlan(ctx context.Context, sub *v1alpha1.Subscription) error {
+// generate a CSV, and returns the approved install plan
+func (o OperatorInstaller) approveInstallPlan(ctx context.Context,
+	sub *v1alpha1.Subscription) (*v1alpha1.InstallPlan, error) {
 	ip := v1alpha1.InstallPlan{}
 
 	ipKey := types.NamespacedName{
@@ -237,44 +437,61 @@ func (o OperatorInstaller) approveInstallPlan(ctx context.Context, sub *v1alpha1
 		Namespace: sub.Status.InstallPlanRef.Namespace,
 	}
 
-	if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+	if err := o.cfg.RetryPolicy.OnConflict(ctx, func() error {
 		if err := o.cfg.Client.Get(ctx, ipKey, &ip); err != nil {
-			return fmt.Errorf("error getting install plan: %v", err)
+			return err
 		}
 		// approve the install plan by setting Approved to true
 		ip.Spec.Approved = true
-		if err := o.cfg.Client.Update(ctx, &ip); err != nil {
-			return fmt.Errorf("error approving install plan: %v", err)
-		}
-		return nil
+		return o.cfg.Client.Update(ctx, &ip)
 	}); err != nil {
-		return err
+		return nil, fmt.Errorf("error approving install plan: %w", err)
 	}
 
-	log.Infof("Approved InstallPlan %s for the Subscription: %s", ipKey.Name, sub.Name)
+	logutil.WithResource(v1alpha1.InstallPlanKind, ipKey).WithField("subscription", sub.GetName()).
+		Info("Approved InstallPlan")
 
-	return nil
+	return &ip, nil
 }
 
-// waitForInstallPlan verifies if an Install Plan exists through subscription status
+// waitForInstallPlan verifies if an Install Plan exists through subscription status,
+// failing early if the subscription's conditions show one will not be created.
 func (o OperatorInstaller) waitForInstallPlan(ctx context.Context, sub *v1alpha1.Subscription) error {
+	return o.waitForSubscription(ctx, sub, func(sub *v1alpha1.Subscription) bool {
+		return sub.Status.InstallPlanRef != nil
+	})
+}
+
+// waitForSubscription waits for planReady to return true for sub, which it updates, failing early
+// if the subscription's conditions show an install plan will not be created.
+func (o OperatorInstaller) waitForSubscription(ctx context.Context, sub *v1alpha1.Subscription,
+	planReady func(*v1alpha1.Subscription) bool) error {
 	subKey := types.NamespacedName{
 		Namespace: sub.GetNamespace(),
 		Name:      sub.GetName(),
 	}
 
+	health := newSubscriptionHealth()
 	ipCheck := wait.ConditionFunc(func() (done bool, err error) {
 		if err := o.cfg.Client.Get(ctx, subKey, sub); err != nil {
 			return false, err
 		}
-		if sub.Status.InstallPlanRef != nil {
+		for _, msg := range health.deprecations(sub) {
+			log.Warnf("Subscription %s installs a deprecated operator: %s", sub.GetName(), msg)
+		}
+		if planReady(sub) {
 			return true, nil
 		}
-		return false, nil
+		return false, health.check(sub)
 	})
 
-	if err := wait.PollImmediateUntil(200*time.Millisecond, ipCheck, ctx.Done()); err != nil {
-		return fmt.Errorf("install plan is not available for the subscription %s: %v", sub.Name, err)
+	if err := olmclient.WaitForChange(ctx, o.cfg.Notifier, sub.GetNamespace(), 200*time.Millisecond, ipCheck,
+		&v1alpha1.Subscription{}); err != nil {
+		err = fmt.Errorf("install plan is not available for the subscription %s: %w", sub.Name, err)
+		if ctx.Err() != nil {
+			return categorizedError{err, ErrInstallPlanTimeout}
+		}
+		return err
 	}
 	return nil
 }
@@ -0,0 +1,110 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("Detached installs", func() {
+	var (
+		o   *OperatorInstaller
+		cfg *operator.Configuration
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		cfg = &operator.Configuration{
+			Scheme:    sch,
+			Namespace: "default",
+			Client:    fake.NewFakeClientWithScheme(sch),
+		}
+		o = &OperatorInstaller{
+			CatalogSourceName: "test-operator-catalog",
+			PackageName:       "test-operator",
+			StartingCSV:       "test-operator.v0.0.1",
+			Channel:           "alpha",
+			CatalogCreator:    fakeCatalogCreator{},
+			cfg:               cfg,
+		}
+		ctx = context.TODO()
+	})
+
+	It("subscribes without waiting for OLM to install the operator", func() {
+		sub, err := o.Subscribe(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sub.Spec.Package).To(Equal("test-operator"))
+		Expect(sub.Spec.CatalogSource).To(Equal("test-operator-catalog"))
+
+		created := &v1alpha1.Subscription{}
+		Expect(cfg.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: sub.GetName()}, created)).To(Succeed())
+		ogs := &v1.OperatorGroupList{}
+		Expect(cfg.Client.List(ctx, ogs)).To(Succeed())
+		Expect(ogs.Items).To(HaveLen(1))
+	})
+
+	It("resumes an install by approving its install plan and waiting for its CSV", func() {
+		sub, err := o.Subscribe(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act as OLM: create and reference an install plan, and install the CSV.
+		ip := &v1alpha1.InstallPlan{ObjectMeta: metav1.ObjectMeta{Name: "install-abcde", Namespace: "default"}}
+		Expect(cfg.Client.Create(ctx, ip)).To(Succeed())
+		sub.Status.InstallPlanRef = &corev1.ObjectReference{Name: ip.GetName(), Namespace: "default"}
+		Expect(cfg.Client.Status().Update(ctx, sub)).To(Succeed())
+		csv := &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: o.StartingCSV, Namespace: "default"}}
+		csv.Status.Phase = v1alpha1.CSVPhaseSucceeded
+		Expect(cfg.Client.Create(ctx, csv)).To(Succeed())
+
+		resumed := &OperatorInstaller{PackageName: o.PackageName, StartingCSV: o.StartingCSV, cfg: cfg}
+		installed, err := resumed.ResumeInstall(ctx, sub.GetName())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(installed.GetName()).To(Equal(o.StartingCSV))
+		Expect(cfg.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: ip.GetName()}, ip)).To(Succeed())
+		Expect(ip.Spec.Approved).To(BeTrue())
+	})
+
+	It("fails to resume an install whose subscription does not exist", func() {
+		_, err := o.ResumeInstall(ctx, "test-operator-v0-0-1-sub")
+		Expect(err).To(MatchError(ContainSubstring("error getting subscription")))
+	})
+
+	It("fails to resume an install if no install plan is created in time", func() {
+		sub, err := o.Subscribe(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err = o.ResumeInstall(ctx, sub.GetName())
+		Expect(err).To(MatchError(ContainSubstring("install plan is not available")))
+		Expect(errors.Is(err, ErrInstallPlanTimeout)).To(BeTrue())
+	})
+})
@@ -16,6 +16,7 @@ package registry
 
 import (
 	"context"
+	"errors"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -107,15 +108,72 @@ var _ = Describe("Tenancy", func() {
 				_ = createOperatorGroupHelper(ctx, o.cfg.Client, operator.SDKOperatorGroupName, namespace, "foo")
 				err = o.createOperatorGroup(ctx)
 				Expect(err.Error()).To(ContainSubstring(`existing SDK-managed operator group's namespaces ["foo"] do not match desired namespaces []`))
+				Expect(errors.Is(err, ErrOperatorGroupConflict)).To(BeTrue())
 			})
 			It("returns an error for a non-SDK OperatorGroup", func() {
 				_ = createOperatorGroupHelper(ctx, o.cfg.Client, nonSDKOperatorGroupName, namespace, "foo")
 				err = o.createOperatorGroup(ctx)
 				Expect(err.Error()).To(ContainSubstring(`existing operator group "my-og"'s namespaces ["foo"] do not match desired namespaces []`))
+				Expect(errors.Is(err, ErrOperatorGroupConflict)).To(BeTrue())
 			})
 		})
 	})
 
+	Describe("checkOperatorGroup", func() {
+		var (
+			o   *OperatorInstaller
+			ctx context.Context
+		)
+
+		BeforeEach(func() {
+			sch := runtime.NewScheme()
+			Expect(v1.AddToScheme(sch)).To(Succeed())
+			o = &OperatorInstaller{
+				PackageName:       "test-operator",
+				SkipOperatorGroup: true,
+				cfg: &operator.Configuration{
+					Scheme:    sch,
+					Namespace: "default",
+					Client:    fake.NewFakeClientWithScheme(sch),
+				},
+			}
+			ctx = context.TODO()
+		})
+
+		It("returns an error with remediation hints if no OperatorGroup exists", func() {
+			err := o.checkOperatorGroup(ctx)
+			Expect(err).To(MatchError(ContainSubstring(`no operator group in namespace "default"`)))
+			Expect(err).To(MatchError(ContainSubstring("--skip-operator-group")))
+			_, ogExists, err := o.getOperatorGroup(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ogExists).To(BeFalse())
+		})
+		It("accepts any OperatorGroup if no install mode is set", func() {
+			_ = createOperatorGroupHelper(ctx, o.cfg.Client, "platform-og", "default", "foo")
+			Expect(o.checkOperatorGroup(ctx)).To(Succeed())
+		})
+		It("accepts an OperatorGroup covering all namespaces", func() {
+			_ = createOperatorGroupHelper(ctx, o.cfg.Client, "platform-og", "default", "")
+			Expect(o.InstallMode.Set("SingleNamespace=foo")).To(Succeed())
+			Expect(o.checkOperatorGroup(ctx)).To(Succeed())
+		})
+		It("accepts an OperatorGroup covering the target namespaces", func() {
+			_ = createOperatorGroupHelper(ctx, o.cfg.Client, "platform-og", "default", "bar", "baz", "foo")
+			Expect(o.InstallMode.Set("MultiNamespace=foo,bar")).To(Succeed())
+			Expect(o.checkOperatorGroup(ctx)).To(Succeed())
+		})
+		It("returns an error for an OperatorGroup not covering the target namespaces", func() {
+			_ = createOperatorGroupHelper(ctx, o.cfg.Client, "platform-og", "default", "foo")
+			Expect(o.InstallMode.Set("MultiNamespace=foo,bar")).To(Succeed())
+			Expect(o.checkOperatorGroup(ctx)).To(MatchError(ContainSubstring(`existing operator group "platform-og"'s ` +
+				`namespaces ["foo"] do not cover install mode MultiNamespace=bar,foo's namespaces ["bar"]`)))
+		})
+		It("returns an error for an OperatorGroup not covering all namespaces", func() {
+			_ = createOperatorGroupHelper(ctx, o.cfg.Client, "platform-og", "default", "foo")
+			Expect(o.InstallMode.Set("AllNamespaces")).To(Succeed())
+			Expect(o.checkOperatorGroup(ctx)).To(HaveOccurred())
+		})
+	})
 })
 
 func createOperatorGroupHelper(ctx context.Context, c client.Client, name, namespace string, targetNamespaces ...string) (og v1.OperatorGroup) {
@@ -0,0 +1,50 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/replay"
+)
+
+var _ = Describe("Replayed installs", func() {
+	It("surfaces an operator group denied by an admission webhook", func() {
+		sch := runtime.NewScheme()
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		interactions, err := replay.LoadFixture("testdata/replay/operatorgroup-denied.jsonl")
+		Expect(err).NotTo(HaveOccurred())
+		c := replay.NewClient(sch, interactions)
+		o := &OperatorInstaller{
+			PackageName: "test-operator",
+			cfg:         &operator.Configuration{Scheme: sch, Namespace: "default", Client: c},
+		}
+
+		err = o.createOperatorGroup(context.TODO())
+		statusErr := &apierrors.StatusError{}
+		Expect(errors.As(err, &statusErr)).To(BeTrue())
+		Expect(apierrors.IsForbidden(statusErr)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("operator groups must target their own namespace")))
+		Expect(c.Unreplayed()).To(BeEmpty())
+	})
+})
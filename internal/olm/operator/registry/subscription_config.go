@@ -0,0 +1,76 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SubscriptionConfig configures the pods of the operator's deployments through the config of the Subscription
+// installing it, ex. to constrain operators that must only run on infra nodes.
+type SubscriptionConfig struct {
+	// NodeSelector and Tolerations are set on the operator's pods, replacing the node selectors of
+	// the CSV's deployments and adding to their tolerations.
+	NodeSelector map[string]string
+	Tolerations  Tolerations
+}
+
+// BindFlags binds c to fs.
+func (c *SubscriptionConfig) BindFlags(fs *pflag.FlagSet) {
+	fs.StringToStringVar(&c.NodeSelector, "node-selector", nil, "node selector of the operator's pods, set in "+
+		"the Subscription's config, as <label>=<value>. Replaces the node selectors of the CSV's deployments. "+
+		"May be set more than once")
+	fs.Var(&c.Tolerations, "toleration", "toleration of the operator's pods, set in the Subscription's config, "+
+		"as <key>[=<value>]:<effect>, ex. 'node-role.kubernetes.io/infra:NoSchedule'. The effect may be empty "+
+		"to tolerate every effect. May be set more than once")
+}
+
+// withSubscriptionConfig returns a function that sets the Subscription argument's config fields configured by c.
+func withSubscriptionConfig(c SubscriptionConfig) func(*v1alpha1.Subscription) {
+	return func(sub *v1alpha1.Subscription) {
+		sub.Spec.Config.NodeSelector = c.NodeSelector
+		sub.Spec.Config.Tolerations = c.Tolerations
+	}
+}
+
+// Apply returns a copy of csv whose deployments' pods are configured by c as OLM would configure them, so their
+// schedulability can be checked before installing.
+func (c SubscriptionConfig) Apply(csv *v1alpha1.ClusterServiceVersion) *v1alpha1.ClusterServiceVersion {
+	csv = csv.DeepCopy()
+	deploymentSpecs := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+	for i := range deploymentSpecs {
+		podSpec := &deploymentSpecs[i].Spec.Template.Spec
+		if len(c.NodeSelector) != 0 {
+			podSpec.NodeSelector = c.NodeSelector
+		}
+		for _, tol := range c.Tolerations {
+			if !containsToleration(podSpec.Tolerations, tol) {
+				podSpec.Tolerations = append(podSpec.Tolerations, tol)
+			}
+		}
+	}
+	return csv
+}
+
+func containsToleration(tolerations []corev1.Toleration, tol corev1.Toleration) bool {
+	for _, t := range tolerations {
+		if t.MatchToleration(&tol) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("SubscriptionConfig", func() {
+	infraToleration := corev1.Toleration{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists,
+		Effect: corev1.TaintEffectNoSchedule}
+	config := SubscriptionConfig{
+		NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+		Tolerations:  Tolerations{infraToleration},
+	}
+
+	It("sets the Subscription's config", func() {
+		sub := newSubscription("memcached-operator.v0.0.1", "default", withSubscriptionConfig(config))
+		Expect(sub.Spec.Config.NodeSelector).To(Equal(config.NodeSelector))
+		Expect(sub.Spec.Config.Tolerations).To(Equal([]corev1.Toleration{infraToleration}))
+
+		sub = newSubscription("memcached-operator.v0.0.1", "default", withSubscriptionConfig(SubscriptionConfig{}))
+		Expect(sub.Spec.Config).To(Equal(v1alpha1.SubscriptionConfig{}))
+	})
+
+	It("configures the CSV's deployments as OLM would", func() {
+		csv := &v1alpha1.ClusterServiceVersion{}
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{{
+			Name: "memcached-operator-controller-manager",
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+						Tolerations:  []corev1.Toleration{infraToleration, {Key: "gpu", Operator: corev1.TolerationOpExists}},
+					},
+				},
+			},
+		}}
+
+		configured := config.Apply(csv)
+		spec := configured.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec
+		Expect(spec.NodeSelector).To(Equal(config.NodeSelector))
+		Expect(spec.Tolerations).To(Equal([]corev1.Toleration{infraToleration, {Key: "gpu", Operator: corev1.TolerationOpExists}}))
+
+		original := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec
+		Expect(original.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux"}))
+		Expect(SubscriptionConfig{}.Apply(csv)).To(Equal(csv))
+	})
+})
@@ -0,0 +1,112 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SubscriptionResolutionFailed is the type of the condition OLM sets on a Subscription when its
+// dependencies cannot be resolved, ex. because no bundle satisfies its constraints.
+// The vendored API version does not define it.
+const SubscriptionResolutionFailed v1alpha1.SubscriptionConditionType = "ResolutionFailed"
+
+// Types of the conditions OLM sets on a Subscription when its package, channel, or bundle is deprecated
+// by an olm.deprecations blob in its catalog. The vendored API version does not define them.
+const (
+	SubscriptionPackageDeprecated v1alpha1.SubscriptionConditionType = "PackageDeprecated"
+	SubscriptionChannelDeprecated v1alpha1.SubscriptionConditionType = "ChannelDeprecated"
+	SubscriptionBundleDeprecated  v1alpha1.SubscriptionConditionType = "BundleDeprecated"
+)
+
+// subscriptionDeprecations are the deprecation conditions checked, from widest to narrowest scope.
+var subscriptionDeprecations = []v1alpha1.SubscriptionConditionType{
+	SubscriptionPackageDeprecated,
+	SubscriptionChannelDeprecated,
+	SubscriptionBundleDeprecated,
+}
+
+// catalogUnhealthyGracePeriod is how long a Subscription's catalogs may be reported unhealthy
+// before the install fails, since OLM reports them unhealthy while their registry pods start.
+const catalogUnhealthyGracePeriod = time.Minute
+
+// subscriptionHealth detects Subscription conditions from which OLM will not create an install plan,
+// so the install fails with the condition's message instead of timing out.
+// It also reports the deprecations of what the Subscription installs.
+type subscriptionHealth struct {
+	now            func() time.Time
+	unhealthySince time.Time
+	// warned is the deprecations already reported.
+	warned map[v1alpha1.SubscriptionConditionType]bool
+}
+
+func newSubscriptionHealth() *subscriptionHealth {
+	return &subscriptionHealth{now: time.Now}
+}
+
+// deprecations returns the messages of sub's deprecation conditions not reported by a previous call.
+func (h *subscriptionHealth) deprecations(sub *v1alpha1.Subscription) (msgs []string) {
+	if h.warned == nil {
+		h.warned = map[v1alpha1.SubscriptionConditionType]bool{}
+	}
+	for _, typ := range subscriptionDeprecations {
+		cond := sub.Status.GetCondition(typ)
+		if cond.Status != corev1.ConditionTrue || h.warned[typ] {
+			continue
+		}
+		h.warned[typ] = true
+		msg := conditionMessage(cond)
+		if msg == "" {
+			msg = string(typ)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// check returns an error describing sub's failed condition, if any.
+func (h *subscriptionHealth) check(sub *v1alpha1.Subscription) error {
+	if cond := sub.Status.GetCondition(SubscriptionResolutionFailed); cond.Status == corev1.ConditionTrue {
+		return fmt.Errorf("subscription %s cannot be resolved: %s", sub.GetName(), conditionMessage(cond))
+	}
+
+	cond := sub.Status.GetCondition(v1alpha1.SubscriptionCatalogSourcesUnhealthy)
+	if cond.Status != corev1.ConditionTrue {
+		h.unhealthySince = time.Time{}
+		return nil
+	}
+	if h.unhealthySince.IsZero() {
+		h.unhealthySince = h.now()
+	}
+	if h.now().Sub(h.unhealthySince) >= catalogUnhealthyGracePeriod {
+		return fmt.Errorf("subscription %s catalog sources have been unhealthy for %s: %s",
+			sub.GetName(), catalogUnhealthyGracePeriod, conditionMessage(cond))
+	}
+	return nil
+}
+
+func conditionMessage(cond v1alpha1.SubscriptionCondition) string {
+	switch {
+	case cond.Reason != "" && cond.Message != "":
+		return fmt.Sprintf("%s: %s", cond.Reason, cond.Message)
+	case cond.Message != "":
+		return cond.Message
+	}
+	return cond.Reason
+}
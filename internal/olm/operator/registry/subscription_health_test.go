@@ -0,0 +1,108 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("subscriptionHealth", func() {
+	var (
+		sub    *v1alpha1.Subscription
+		health *subscriptionHealth
+		now    time.Time
+	)
+
+	BeforeEach(func() {
+		sub = &v1alpha1.Subscription{}
+		sub.SetName("test-operator-sub")
+		sub.SetNamespace("default")
+		now = time.Now()
+		health = &subscriptionHealth{now: func() time.Time { return now }}
+	})
+
+	setCondition := func(typ v1alpha1.SubscriptionConditionType, reason, message string) {
+		sub.Status.SetCondition(v1alpha1.SubscriptionCondition{
+			Type:    typ,
+			Status:  corev1.ConditionTrue,
+			Reason:  reason,
+			Message: message,
+		})
+	}
+
+	It("passes a subscription without failed conditions", func() {
+		setCondition(v1alpha1.SubscriptionInstallPlanPending, "", "")
+		Expect(health.check(sub)).To(Succeed())
+	})
+	It("fails a subscription that cannot be resolved", func() {
+		setCondition(SubscriptionResolutionFailed, "ConstraintsNotSatisfiable", "no operators found in package test-operator")
+		Expect(health.check(sub)).To(MatchError("subscription test-operator-sub cannot be resolved: " +
+			"ConstraintsNotSatisfiable: no operators found in package test-operator"))
+	})
+	It("fails a subscription whose catalog sources stay unhealthy", func() {
+		setCondition(v1alpha1.SubscriptionCatalogSourcesUnhealthy, "UnhealthyCatalogSourceFound",
+			"targeted catalogsource default/test-operator-catalog unhealthy")
+		Expect(health.check(sub)).To(Succeed())
+		now = now.Add(catalogUnhealthyGracePeriod)
+		Expect(health.check(sub)).To(MatchError(ContainSubstring("targeted catalogsource default/test-operator-catalog unhealthy")))
+	})
+	It("restarts the grace period once catalog sources are healthy", func() {
+		setCondition(v1alpha1.SubscriptionCatalogSourcesUnhealthy, "", "unhealthy")
+		Expect(health.check(sub)).To(Succeed())
+		now = now.Add(catalogUnhealthyGracePeriod / 2)
+		sub.Status.RemoveConditions(v1alpha1.SubscriptionCatalogSourcesUnhealthy)
+		Expect(health.check(sub)).To(Succeed())
+		setCondition(v1alpha1.SubscriptionCatalogSourcesUnhealthy, "", "unhealthy")
+		now = now.Add(catalogUnhealthyGracePeriod / 2)
+		Expect(health.check(sub)).To(Succeed())
+	})
+	It("reports each deprecation of a subscription once", func() {
+		Expect(health.deprecations(sub)).To(BeEmpty())
+		setCondition(SubscriptionChannelDeprecated, "", "channel alpha is no longer updated, use stable")
+		Expect(health.deprecations(sub)).To(Equal([]string{"channel alpha is no longer updated, use stable"}))
+		Expect(health.deprecations(sub)).To(BeEmpty())
+		setCondition(SubscriptionBundleDeprecated, "Deprecated", "")
+		setCondition(SubscriptionPackageDeprecated, "", "")
+		Expect(health.deprecations(sub)).To(Equal([]string{"PackageDeprecated", "Deprecated"}))
+		Expect(health.check(sub)).To(Succeed())
+	})
+
+	It("fails waiting for an install plan with the subscription's condition", func() {
+		sch := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		setCondition(SubscriptionResolutionFailed, "ConstraintsNotSatisfiable", "no operators found")
+		o := &OperatorInstaller{cfg: &operator.Configuration{
+			Scheme:    sch,
+			Namespace: "default",
+			Client:    fake.NewFakeClientWithScheme(sch, sub),
+		}}
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+		defer cancel()
+		err := o.waitForInstallPlan(ctx, sub)
+		Expect(err).To(MatchError(ContainSubstring("ConstraintsNotSatisfiable: no operators found")))
+		Expect(ctx.Err()).To(BeNil())
+	})
+})
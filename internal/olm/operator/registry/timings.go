@@ -0,0 +1,75 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
+)
+
+// Install phases timed by an OperatorInstaller, in the order they run.
+const (
+	// PhaseCatalogReady is creating the CatalogSource and waiting for its registry to serve.
+	PhaseCatalogReady = "catalog_ready"
+	// PhaseInstallPlanGenerated is waiting for OLM to resolve the Subscription into an InstallPlan.
+	PhaseInstallPlanGenerated = "install_plan_generated"
+	// PhaseInstallPlanApproved is approving the InstallPlan.
+	PhaseInstallPlanApproved = "install_plan_approved"
+	// PhaseCSVReady is waiting for the installed CSV to succeed.
+	PhaseCSVReady = "csv_ready"
+)
+
+// InstallTimings are the durations of the phases of an install.
+type InstallTimings struct {
+	// Phases are the phases that ran, in order. The last phase of a failed install is the one that failed.
+	Phases []PhaseTiming `json:"phases"`
+	// Total is the duration of the whole install, including steps outside of timed phases.
+	Total time.Duration `json:"total"`
+	// Succeeded is true if the install succeeded.
+	Succeeded bool `json:"succeeded"`
+}
+
+// PhaseTiming is the duration of an install phase.
+type PhaseTiming struct {
+	Phase    string        `json:"phase"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Fields returns t as log fields, with one "<phase>_seconds" field per phase, so timings can be
+// read from structured logs.
+func (t InstallTimings) Fields() log.Fields {
+	fields := log.Fields{
+		"total_seconds": t.Total.Seconds(),
+		"succeeded":     t.Succeeded,
+	}
+	for _, p := range t.Phases {
+		fields[p.Phase+"_seconds"] = p.Duration.Seconds()
+	}
+	return fields
+}
+
+// timePhase runs f as phase, recording its duration in t and a span of it.
+func (t *InstallTimings) timePhase(ctx context.Context, phase string, f func(context.Context) error) error {
+	ctx, span := tracing.Start(ctx, phase)
+	start := time.Now()
+	err := f(ctx)
+	t.Phases = append(t.Phases, PhaseTiming{Phase: phase, Duration: time.Since(start)})
+	span.End(err)
+	return err
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("InstallTimings", func() {
+	It("records the phases that ran, ending with the failed phase", func() {
+		sch := runtime.NewScheme()
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		timings := &InstallTimings{}
+		o := &OperatorInstaller{
+			CatalogSourceName: "test-operator-catalog",
+			PackageName:       "test-operator",
+			StartingCSV:       "test-operator.v0.0.1",
+			CatalogCreator:    fakeCatalogCreator{},
+			Timings:           timings,
+			cfg: &operator.Configuration{
+				Scheme:    sch,
+				Namespace: "default",
+				Client:    fake.NewFakeClientWithScheme(sch),
+			},
+		}
+		ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer cancel()
+		// No install plan is ever created, so waiting for one times out.
+		_, err := o.InstallOperator(ctx)
+		Expect(err).To(HaveOccurred())
+
+		Expect(timings.Succeeded).To(BeFalse())
+		Expect(timings.Phases).To(HaveLen(2))
+		Expect(timings.Phases[0].Phase).To(Equal(PhaseCatalogReady))
+		Expect(timings.Phases[1].Phase).To(Equal(PhaseInstallPlanGenerated))
+		Expect(timings.Phases[1].Duration).To(BeNumerically(">=", 50*time.Millisecond))
+		Expect(timings.Total).To(BeNumerically(">=", timings.Phases[1].Duration))
+	})
+
+	It("converts to log fields in seconds", func() {
+		t := InstallTimings{
+			Phases: []PhaseTiming{
+				{Phase: PhaseCatalogReady, Duration: 1500 * time.Millisecond},
+				{Phase: PhaseCSVReady, Duration: 2 * time.Second},
+			},
+			Total:     4 * time.Second,
+			Succeeded: true,
+		}
+		Expect(t.Fields()).To(SatisfyAll(
+			HaveKeyWithValue("catalog_ready_seconds", 1.5),
+			HaveKeyWithValue("csv_ready_seconds", 2.0),
+			HaveKeyWithValue("total_seconds", 4.0),
+			HaveKeyWithValue("succeeded", true),
+		))
+	})
+})
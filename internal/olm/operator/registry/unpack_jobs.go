@@ -0,0 +1,146 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
+)
+
+// unpackJobLogTimeout bounds log collection, which runs after the install context may have expired.
+const unpackJobLogTimeout = 30 * time.Second
+
+// logBundleUnpackJobs logs the status and container logs of every OLM bundle unpack Job
+// for the install plan referenced by sub. Errors are logged rather than returned, since
+// this is only called to help debug an install that already failed.
+func (o OperatorInstaller) logBundleUnpackJobs(sub *v1alpha1.Subscription) {
+	if sub.Status.InstallPlanRef == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), unpackJobLogTimeout)
+	defer cancel()
+
+	ip := &v1alpha1.InstallPlan{}
+	ipKey := types.NamespacedName{
+		Name:      sub.Status.InstallPlanRef.Name,
+		Namespace: sub.Status.InstallPlanRef.Namespace,
+	}
+	if err := o.cfg.Client.Get(ctx, ipKey, ip); err != nil {
+		logutil.WithResource(v1alpha1.InstallPlanKind, ipKey).
+			Warnf("Failed to get install plan to collect bundle unpack logs: %v", err)
+		return
+	}
+	jobs, err := bundleUnpackJobs(ctx, o.cfg.Client, ip)
+	if err != nil {
+		log.Warnf("Failed to find bundle unpack jobs: %v", err)
+		return
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	cs, err := kubernetes.NewForConfig(o.cfg.RESTConfig)
+	if err != nil {
+		log.Warnf("Failed to create client to collect bundle unpack logs: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		jobLogger := logutil.WithObject(&job)
+		jobLogger.WithFields(log.Fields{
+			"active":    job.Status.Active,
+			"succeeded": job.Status.Succeeded,
+			"failed":    job.Status.Failed,
+		}).Info("Bundle unpack job status")
+		for _, cond := range job.Status.Conditions {
+			if cond.Status == corev1.ConditionTrue {
+				jobLogger.WithFields(log.Fields{"condition": cond.Type, "reason": cond.Reason}).Info(cond.Message)
+			}
+		}
+
+		pods := &corev1.PodList{}
+		if err := o.cfg.Client.List(ctx, pods, client.InNamespace(job.GetNamespace()),
+			client.MatchingLabels{"job-name": job.GetName()}); err != nil {
+			jobLogger.Warnf("Failed to list pods for job: %v", err)
+			continue
+		}
+		for _, pod := range pods.Items {
+			containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+			for _, c := range containers {
+				logs, err := getContainerLogs(ctx, cs, pod, c.Name)
+				if err != nil {
+					logutil.WithObject(&pod).WithField("container", c.Name).Warnf("Failed to get container logs: %v", err)
+					continue
+				}
+				logutil.WithObject(&pod).WithField("container", c.Name).Infof("Container logs:\n%s", logs)
+			}
+		}
+	}
+}
+
+// bundleUnpackJobs returns the Jobs OLM created to unpack bundles looked up by ip. These Jobs
+// are not labeled by bundle, so they are matched by the bundle image one of their containers pulls.
+func bundleUnpackJobs(ctx context.Context, c client.Client, ip *v1alpha1.InstallPlan) (jobs []batchv1.Job, err error) {
+	for _, lookup := range ip.Status.BundleLookups {
+		namespace := ip.GetNamespace()
+		if lookup.CatalogSourceRef != nil {
+			namespace = lookup.CatalogSourceRef.Namespace
+		}
+		jobList := &batchv1.JobList{}
+		if err := c.List(ctx, jobList, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("error listing jobs in namespace %s: %v", namespace, err)
+		}
+		for _, job := range jobList.Items {
+			if podSpecUsesImage(job.Spec.Template.Spec, lookup.Path) {
+				jobs = append(jobs, job)
+			}
+		}
+	}
+	return jobs, nil
+}
+
+func podSpecUsesImage(spec corev1.PodSpec, image string) bool {
+	for _, c := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+		if c.Image == image {
+			return true
+		}
+	}
+	return false
+}
+
+func getContainerLogs(ctx context.Context, cs kubernetes.Interface, pod corev1.Pod, container string) (string, error) {
+	req := cs.CoreV1().Pods(pod.GetNamespace()).GetLogs(pod.GetName(), &corev1.PodLogOptions{Container: container})
+	podLogs, err := req.Stream(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer podLogs.Close()
+
+	buf := &bytes.Buffer{}
+	_, err = io.Copy(buf, podLogs)
+	return buf.String(), err
+}
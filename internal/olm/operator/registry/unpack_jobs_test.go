@@ -0,0 +1,82 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("bundleUnpackJobs", func() {
+	const bundleImage = "quay.io/example/memcached-operator-bundle:v0.0.1"
+
+	var (
+		c   client.Client
+		ip  *v1alpha1.InstallPlan
+		ctx context.Context
+	)
+
+	newJob := func(name, namespace, image string) *batchv1.Job {
+		job := &batchv1.Job{}
+		job.SetName(name)
+		job.SetNamespace(namespace)
+		job.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "pull", Image: image}}
+		job.Spec.Template.Spec.Containers = []corev1.Container{{Name: "extract", Image: "quay.io/operator-framework/upstream-opm-builder"}}
+		return job
+	}
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		c = fake.NewFakeClientWithScheme(sch)
+		ctx = context.TODO()
+
+		ip = &v1alpha1.InstallPlan{}
+		ip.SetName("install-abcde")
+		ip.SetNamespace("default")
+		ip.Status.BundleLookups = []v1alpha1.BundleLookup{{
+			Path:             bundleImage,
+			CatalogSourceRef: &corev1.ObjectReference{Name: "memcached-operator-catalog", Namespace: "olm"},
+		}}
+	})
+
+	It("returns jobs pulling a looked up bundle in the catalog namespace", func() {
+		Expect(c.Create(ctx, newJob("unpack", "olm", bundleImage))).To(Succeed())
+		Expect(c.Create(ctx, newJob("other-bundle", "olm", "quay.io/example/other-bundle:v0.0.1"))).To(Succeed())
+		Expect(c.Create(ctx, newJob("other-namespace", "default", bundleImage))).To(Succeed())
+
+		jobs, err := bundleUnpackJobs(ctx, c, ip)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].GetName()).To(Equal("unpack"))
+	})
+	It("returns nothing when the install plan has no bundle lookups", func() {
+		Expect(c.Create(ctx, newJob("unpack", "olm", bundleImage))).To(Succeed())
+		ip.Status.BundleLookups = nil
+
+		jobs, err := bundleUnpackJobs(ctx, c, ip)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs).To(BeEmpty())
+	})
+})
@@ -0,0 +1,203 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
+)
+
+// operatorConditionGVK is the kind of the OperatorConditions OLM creates for each CSV, through which operators
+// report whether they can be upgraded. They are read as unstructured objects, since the OLM API module
+// vendored by the SDK does not define them.
+var operatorConditionGVK = schema.GroupVersionKind{Group: "operators.coreos.com", Version: "v1", Kind: "OperatorCondition"}
+
+// operatorConditionUpgradeable is the type of the condition an operator sets to "False" to block upgrades.
+const operatorConditionUpgradeable = "Upgradeable"
+
+// operatorCondition is the subset of an OperatorCondition read by UpgradeOperator.
+type operatorCondition struct {
+	Spec struct {
+		// Overrides are set by cluster admins, and take precedence over the operator's conditions.
+		Overrides  []condition `json:"overrides,omitempty"`
+		Conditions []condition `json:"conditions,omitempty"`
+	} `json:"spec,omitempty"`
+	Status struct {
+		Conditions []condition `json:"conditions,omitempty"`
+	} `json:"status,omitempty"`
+}
+
+type condition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// UpgradeOperator upgrades the operator installed from PackageName in the namespace to StartingCSV: it adds the
+// upgrade's bundle to the operator's catalog with CatalogUpdater, approves the install plan OLM creates for the
+// upgrade, and waits for StartingCSV to be installed. The upgrade fails with ErrUpgradeBlocked if the installed
+// operator's OperatorCondition reports it is not upgradeable, unless IgnoreOperatorCondition is set.
+func (o OperatorInstaller) UpgradeOperator(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+	hooks := o.hooks()
+	ctx, span := tracing.Start(ctx, "upgrade operator", tracing.String("package", o.PackageName),
+		tracing.String("namespace", o.cfg.Namespace))
+	timings := &InstallTimings{}
+	start := time.Now()
+	csv, err := o.upgradeOperator(ctx, hooks, timings)
+	timings.Total = time.Since(start)
+	span.End(err)
+	timings.Succeeded = err == nil
+	log.WithFields(timings.Fields()).Info("Upgrade phase timings")
+	if o.Timings != nil {
+		*o.Timings = *timings
+	}
+	if err != nil {
+		hooks.OnError(err)
+		return nil, err
+	}
+	return csv, nil
+}
+
+func (o OperatorInstaller) upgradeOperator(ctx context.Context, hooks InstallerHooks,
+	timings *InstallTimings) (*v1alpha1.ClusterServiceVersion, error) {
+	sub, err := o.getSubscription(ctx)
+	if err != nil {
+		return nil, err
+	}
+	installed := sub.Status.InstalledCSV
+	switch installed {
+	case "":
+		return nil, fmt.Errorf("subscription %q has not installed a CSV, wait for its install to complete "+
+			"before upgrading", sub.GetName())
+	case o.StartingCSV:
+		return nil, fmt.Errorf("%s is already installed by subscription %q", installed, sub.GetName())
+	}
+	if err := o.checkOperatorCondition(ctx, installed); err != nil {
+		return nil, err
+	}
+
+	var previousPlan string
+	if sub.Status.InstallPlanRef != nil {
+		previousPlan = sub.Status.InstallPlanRef.Name
+	}
+	cs := &v1alpha1.CatalogSource{}
+	csKey := types.NamespacedName{Namespace: sub.Spec.CatalogSourceNamespace, Name: sub.Spec.CatalogSource}
+	err = timings.timePhase(ctx, PhaseCatalogReady, func(ctx context.Context) error {
+		if err := o.cfg.Client.Get(ctx, csKey, cs); err != nil {
+			return fmt.Errorf("error getting catalog source: %w", err)
+		}
+		return o.CatalogUpdater.UpdateCatalog(ctx, cs)
+	})
+	if err != nil {
+		return nil, categorizedError{fmt.Errorf("update catalog: %w", err), ErrCatalogFailed}
+	}
+	logutil.WithObject(cs).Info("Updated CatalogSource")
+
+	// Wait for OLM to resolve the upgrade into a new install plan.
+	if err := timings.timePhase(ctx, PhaseInstallPlanGenerated, func(ctx context.Context) error {
+		return o.waitForSubscription(ctx, sub, func(sub *v1alpha1.Subscription) bool {
+			return sub.Status.CurrentCSV == o.StartingCSV && sub.Status.InstallPlanRef != nil &&
+				sub.Status.InstallPlanRef.Name != previousPlan
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return o.completeInstall(ctx, hooks, timings, sub)
+}
+
+// getSubscription returns the Subscription to PackageName in the namespace.
+func (o OperatorInstaller) getSubscription(ctx context.Context) (*v1alpha1.Subscription, error) {
+	subs := v1alpha1.SubscriptionList{}
+	if err := o.cfg.Client.List(ctx, &subs, client.InNamespace(o.cfg.Namespace)); err != nil {
+		return nil, fmt.Errorf("error listing subscriptions: %w", err)
+	}
+	for i := range subs.Items {
+		if subs.Items[i].Spec != nil && subs.Items[i].Spec.Package == o.PackageName {
+			return &subs.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no subscription to package %q in namespace %q, install it with 'run bundle' "+
+		"before upgrading", o.PackageName, o.cfg.Namespace)
+}
+
+// checkOperatorCondition returns an error if the OperatorCondition of the installed CSV csvName reports the
+// operator is not upgradeable, unless IgnoreOperatorCondition is set. Operators without an OperatorCondition,
+// ex. on clusters whose OLM does not create them, are upgradeable.
+func (o OperatorInstaller) checkOperatorCondition(ctx context.Context, csvName string) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(operatorConditionGVK)
+	key := types.NamespacedName{Namespace: o.cfg.Namespace, Name: csvName}
+	if err := o.cfg.Client.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			log.Debugf("No OperatorCondition for %s, not checking whether it is upgradeable", csvName)
+			return nil
+		}
+		return fmt.Errorf("error getting operator condition: %w", err)
+	}
+	oc := operatorCondition{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &oc); err != nil {
+		return fmt.Errorf("error reading operator condition: %w", err)
+	}
+
+	upgradeable, ok := findCondition(oc.Spec.Overrides, operatorConditionUpgradeable)
+	if !ok {
+		upgradeable, ok = findCondition(oc.Status.Conditions, operatorConditionUpgradeable)
+	}
+	if !ok {
+		upgradeable, ok = findCondition(oc.Spec.Conditions, operatorConditionUpgradeable)
+	}
+	if !ok || upgradeable.Status != "False" {
+		return nil
+	}
+
+	msg := fmt.Sprintf("operator %s reports it is not upgradeable", csvName)
+	if upgradeable.Reason != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, upgradeable.Reason)
+	}
+	if upgradeable.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, upgradeable.Message)
+	}
+	if !o.IgnoreOperatorCondition {
+		return categorizedError{fmt.Errorf("%s; set --ignore-operator-condition to upgrade anyway", msg),
+			ErrUpgradeBlocked}
+	}
+	log.Warnf("Ignoring operator condition: %s. OLM versions that enforce operator conditions may still "+
+		"block the upgrade", msg)
+	return nil
+}
+
+func findCondition(conditions []condition, conditionType string) (condition, bool) {
+	for _, c := range conditions {
+		if c.Type == conditionType {
+			return c, true
+		}
+	}
+	return condition{}, false
+}
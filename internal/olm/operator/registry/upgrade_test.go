@@ -0,0 +1,196 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+// olmCatalogUpdater acts as OLM once the catalog is updated, by resolving the upgrade into a new install plan.
+type olmCatalogUpdater struct {
+	cfg     *operator.Configuration
+	upgrade string
+	updated bool
+}
+
+func (u *olmCatalogUpdater) UpdateCatalog(ctx context.Context, _ *v1alpha1.CatalogSource) error {
+	u.updated = true
+	ip := &v1alpha1.InstallPlan{ObjectMeta: metav1.ObjectMeta{Name: "install-upgrade", Namespace: "default"}}
+	if err := u.cfg.Client.Create(ctx, ip); err != nil {
+		return err
+	}
+	sub := &v1alpha1.Subscription{}
+	if err := u.cfg.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "test-operator-sub"}, sub); err != nil {
+		return err
+	}
+	sub.Status.CurrentCSV = u.upgrade
+	sub.Status.State = v1alpha1.SubscriptionStateUpgradePending
+	sub.Status.InstallPlanRef = &corev1.ObjectReference{Name: ip.GetName(), Namespace: "default"}
+	if err := u.cfg.Client.Status().Update(ctx, sub); err != nil {
+		return err
+	}
+	csv := &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: u.upgrade, Namespace: "default"}}
+	csv.Status.Phase = v1alpha1.CSVPhaseSucceeded
+	return u.cfg.Client.Create(ctx, csv)
+}
+
+func newOperatorCondition(name string, conditions map[string][]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetGroupVersionKind(operatorConditionGVK)
+	obj.SetName(name)
+	obj.SetNamespace("default")
+	for path, c := range conditions {
+		Expect(unstructured.SetNestedSlice(obj.Object, c, path, "conditions")).To(Succeed())
+	}
+	return obj
+}
+
+func notUpgradeable(reason, message string) []interface{} {
+	return []interface{}{map[string]interface{}{
+		"type": "Upgradeable", "status": "False", "reason": reason, "message": message,
+	}}
+}
+
+var _ = Describe("Upgrades", func() {
+	var (
+		o       *OperatorInstaller
+		cfg     *operator.Configuration
+		updater *olmCatalogUpdater
+		ctx     context.Context
+	)
+
+	newConfig := func(objs ...runtime.Object) {
+		sch := runtime.NewScheme()
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		sub := &v1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-operator-sub", Namespace: "default"},
+			Spec: &v1alpha1.SubscriptionSpec{
+				Package:                "test-operator",
+				CatalogSource:          "test-operator-catalog",
+				CatalogSourceNamespace: "default",
+			},
+			Status: v1alpha1.SubscriptionStatus{
+				InstalledCSV:   "test-operator.v0.0.1",
+				CurrentCSV:     "test-operator.v0.0.1",
+				InstallPlanRef: &corev1.ObjectReference{Name: "install-initial", Namespace: "default"},
+			},
+		}
+		cs := &v1alpha1.CatalogSource{ObjectMeta: metav1.ObjectMeta{Name: "test-operator-catalog", Namespace: "default"}}
+		cfg.Scheme = sch
+		cfg.Client = fake.NewFakeClientWithScheme(sch, append(objs, sub, cs)...)
+	}
+
+	BeforeEach(func() {
+		cfg = &operator.Configuration{Namespace: "default"}
+		updater = &olmCatalogUpdater{cfg: cfg, upgrade: "test-operator.v0.0.2"}
+		o = &OperatorInstaller{
+			PackageName:    "test-operator",
+			StartingCSV:    "test-operator.v0.0.2",
+			CatalogUpdater: updater,
+			cfg:            cfg,
+		}
+		ctx = context.TODO()
+	})
+
+	It("approves the upgrade's install plan and waits for its CSV", func() {
+		newConfig()
+		csv, err := o.UpgradeOperator(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(csv.GetName()).To(Equal("test-operator.v0.0.2"))
+		ip := &v1alpha1.InstallPlan{}
+		Expect(cfg.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "install-upgrade"}, ip)).To(Succeed())
+		Expect(ip.Spec.Approved).To(BeTrue())
+	})
+
+	It("upgrades operators whose OperatorCondition reports they are upgradeable", func() {
+		newConfig(newOperatorCondition("test-operator.v0.0.1", map[string][]interface{}{
+			"status": {map[string]interface{}{"type": "Upgradeable", "status": "True"}},
+		}))
+		_, err := o.UpgradeOperator(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updater.updated).To(BeTrue())
+	})
+
+	It("blocks upgrades of operators whose OperatorCondition reports they are not upgradeable", func() {
+		newConfig(newOperatorCondition("test-operator.v0.0.1", map[string][]interface{}{
+			"status": notUpgradeable("Migrating", "data migration in progress"),
+		}))
+		_, err := o.UpgradeOperator(ctx)
+		Expect(errors.Is(err, ErrUpgradeBlocked)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("(Migrating): data migration in progress")))
+		Expect(err).To(MatchError(ContainSubstring("--ignore-operator-condition")))
+		Expect(updater.updated).To(BeFalse())
+	})
+
+	It("reads the conditions set by the operator if OLM has not copied them to the status", func() {
+		newConfig(newOperatorCondition("test-operator.v0.0.1", map[string][]interface{}{
+			"spec": notUpgradeable("Migrating", ""),
+		}))
+		_, err := o.UpgradeOperator(ctx)
+		Expect(errors.Is(err, ErrUpgradeBlocked)).To(BeTrue())
+	})
+
+	It("honors overrides of the operator's conditions", func() {
+		obj := newOperatorCondition("test-operator.v0.0.1", map[string][]interface{}{
+			"status": notUpgradeable("Migrating", ""),
+		})
+		Expect(unstructured.SetNestedSlice(obj.Object, []interface{}{
+			map[string]interface{}{"type": "Upgradeable", "status": "True"},
+		}, "spec", "overrides")).To(Succeed())
+		newConfig(obj)
+		_, err := o.UpgradeOperator(ctx)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("upgrades operators that are not upgradeable with IgnoreOperatorCondition", func() {
+		newConfig(newOperatorCondition("test-operator.v0.0.1", map[string][]interface{}{
+			"status": notUpgradeable("Migrating", ""),
+		}))
+		o.IgnoreOperatorCondition = true
+		_, err := o.UpgradeOperator(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updater.updated).To(BeTrue())
+	})
+
+	It("fails if the package is not installed", func() {
+		newConfig()
+		o.PackageName = "other-operator"
+		_, err := o.UpgradeOperator(ctx)
+		Expect(err).To(MatchError(ContainSubstring(`no subscription to package "other-operator"`)))
+	})
+
+	It("fails if the bundle's CSV is already installed", func() {
+		newConfig()
+		o.StartingCSV = "test-operator.v0.0.1"
+		_, err := o.UpgradeOperator(ctx)
+		Expect(err).To(MatchError(ContainSubstring("already installed")))
+		Expect(updater.updated).To(BeFalse())
+	})
+})
@@ -0,0 +1,141 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Client is a client replaying recorded interactions. Each request is answered by the first
+// unreplayed interaction with the same verb, type, namespace, and name, so requests of the same
+// object are answered in recorded order, while requests of different objects may be made in any order.
+// Requests without a matching interaction fail.
+type Client struct {
+	scheme       *runtime.Scheme
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+var _ client.Client = &Client{}
+
+// NewClient returns a Client replaying interactions. Types of requested objects are looked up in scheme.
+func NewClient(scheme *runtime.Scheme, interactions []Interaction) *Client {
+	return &Client{
+		scheme:       scheme,
+		interactions: interactions,
+		replayed:     make([]bool, len(interactions)),
+	}
+}
+
+// Unreplayed returns the interactions that have not been replayed, ex. to check that
+// an install made every recorded request.
+func (c *Client) Unreplayed() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var unreplayed []Interaction
+	for i, interaction := range c.interactions {
+		if !c.replayed[i] {
+			unreplayed = append(unreplayed, interaction)
+		}
+	}
+	return unreplayed
+}
+
+// replay answers a request of verb on obj with the next matching interaction, decoding its response into obj.
+func (c *Client) replay(verb string, obj runtime.Object, key client.ObjectKey) error {
+	req, err := newInteraction(c.scheme, verb, obj, key)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	var recorded *Interaction
+	for i := range c.interactions {
+		if !c.replayed[i] && c.interactions[i].matches(req) {
+			c.replayed[i] = true
+			recorded = &c.interactions[i]
+			break
+		}
+	}
+	c.mu.Unlock()
+	if recorded == nil {
+		return fmt.Errorf("no recorded interaction for request %s", req)
+	}
+
+	if recorded.Error != nil {
+		return recorded.Error.err()
+	}
+	if len(recorded.Response) != 0 {
+		if err := json.Unmarshal(recorded.Response, obj); err != nil {
+			return fmt.Errorf("error decoding recorded response of %s: %v", req, err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) Get(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return c.replay(VerbGet, obj, key)
+}
+
+func (c *Client) List(_ context.Context, list runtime.Object, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	return c.replay(VerbList, list, client.ObjectKey{Namespace: listOpts.Namespace})
+}
+
+func (c *Client) Create(_ context.Context, obj runtime.Object, _ ...client.CreateOption) error {
+	return c.replay(VerbCreate, obj, objectKey(obj))
+}
+
+func (c *Client) Update(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+	return c.replay(VerbUpdate, obj, objectKey(obj))
+}
+
+func (c *Client) Patch(_ context.Context, obj runtime.Object, _ client.Patch, _ ...client.PatchOption) error {
+	return c.replay(VerbPatch, obj, objectKey(obj))
+}
+
+func (c *Client) Delete(_ context.Context, obj runtime.Object, _ ...client.DeleteOption) error {
+	return c.replay(VerbDelete, obj, objectKey(obj))
+}
+
+func (c *Client) DeleteAllOf(_ context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	deleteOpts := &client.DeleteAllOfOptions{}
+	deleteOpts.ApplyOptions(opts)
+	return c.replay(VerbDeleteAllOf, obj, client.ObjectKey{Namespace: deleteOpts.Namespace})
+}
+
+func (c *Client) Status() client.StatusWriter {
+	return statusClient{c}
+}
+
+type statusClient struct {
+	c *Client
+}
+
+func (s statusClient) Update(_ context.Context, obj runtime.Object, _ ...client.UpdateOption) error {
+	return s.c.replay(VerbUpdateStatus, obj, objectKey(obj))
+}
+
+func (s statusClient) Patch(_ context.Context, obj runtime.Object, _ client.Patch, _ ...client.PatchOption) error {
+	return s.c.replay(VerbPatchStatus, obj, objectKey(obj))
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replay records the API requests made by a client into a fixture, and replays fixtures
+// with a client that returns the recorded responses, so install logic can be unit tested
+// deterministically against interactions recorded from a real cluster.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Verbs of recorded requests.
+const (
+	VerbGet          = "get"
+	VerbList         = "list"
+	VerbCreate       = "create"
+	VerbUpdate       = "update"
+	VerbPatch        = "patch"
+	VerbDelete       = "delete"
+	VerbDeleteAllOf  = "deleteallof"
+	VerbUpdateStatus = "update/status"
+	VerbPatchStatus  = "patch/status"
+)
+
+// Interaction is a request made by a client and its response.
+type Interaction struct {
+	Verb       string `json:"verb"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	// Request is the object sent, for requests sending one.
+	Request json.RawMessage `json:"request,omitempty"`
+	// Response is the object read or returned by the API server, if the request succeeded.
+	Response json.RawMessage `json:"response,omitempty"`
+	// Error is the error of a failed request.
+	Error *Error `json:"error,omitempty"`
+}
+
+// Error is a recorded request error. API errors are recorded with their status,
+// so replayed errors can be checked with functions like apierrors.IsNotFound.
+type Error struct {
+	Status  *metav1.Status `json:"status,omitempty"`
+	Message string         `json:"message,omitempty"`
+}
+
+func newError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		s := status.Status()
+		return &Error{Status: &s}
+	}
+	return &Error{Message: err.Error()}
+}
+
+func (e *Error) err() error {
+	if e == nil {
+		return nil
+	}
+	if e.Status != nil {
+		return &apierrors.StatusError{ErrStatus: *e.Status}
+	}
+	return errors.New(e.Message)
+}
+
+func (i Interaction) String() string {
+	key := i.Name
+	if i.Namespace != "" {
+		key = i.Namespace + "/" + i.Name
+	}
+	return fmt.Sprintf("%s %s %s %s", i.Verb, i.APIVersion, i.Kind, key)
+}
+
+// matches returns true if i is a request with the same verb, type, and key as other.
+func (i Interaction) matches(other Interaction) bool {
+	return i.Verb == other.Verb && i.APIVersion == other.APIVersion && i.Kind == other.Kind &&
+		i.Namespace == other.Namespace && i.Name == other.Name
+}
+
+// newInteraction returns an Interaction of verb on obj, with the type of obj looked up in scheme.
+func newInteraction(scheme *runtime.Scheme, verb string, obj runtime.Object, key client.ObjectKey) (Interaction, error) {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return Interaction{}, err
+	}
+	return Interaction{
+		Verb:       verb,
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Namespace:  key.Namespace,
+		Name:       key.Name,
+	}, nil
+}
+
+// ReadFixture reads the interactions of a fixture, which has one JSON-encoded Interaction per line.
+func ReadFixture(r io.Reader) ([]Interaction, error) {
+	var interactions []Interaction
+	scanner := bufio.NewScanner(r)
+	// Lists of large objects like CSVs do not fit in the default buffer.
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		i := Interaction{}
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("error decoding interaction on line %d: %v", line, err)
+		}
+		interactions = append(interactions, i)
+	}
+	return interactions, scanner.Err()
+}
+
+// LoadFixture reads the interactions of the fixture at path.
+func LoadFixture(path string) ([]Interaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadFixture(f)
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Recorder is a client recording each request made with it and the response of its underlying client.
+// Interactions are written to a fixture as they complete, so interactions of an interrupted install
+// are recorded too.
+type Recorder struct {
+	client.Client
+
+	scheme *runtime.Scheme
+	mu     sync.Mutex
+	w      io.Writer
+	err    error
+}
+
+var _ client.Client = &Recorder{}
+
+// NewRecorder returns a Recorder of requests made with c, which writes a fixture to w.
+// Types of recorded objects are looked up in scheme.
+func NewRecorder(c client.Client, scheme *runtime.Scheme, w io.Writer) *Recorder {
+	return &Recorder{Client: c, scheme: scheme, w: w}
+}
+
+// Err returns the first error recording an interaction.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// record writes an interaction of verb on obj, run by do, to the fixture.
+func (r *Recorder) record(verb string, obj, req runtime.Object, key client.ObjectKey, do func() error) error {
+	i, ierr := newInteraction(r.scheme, verb, obj, key)
+	if ierr == nil && req != nil {
+		i.Request, ierr = json.Marshal(req)
+	}
+	err := do()
+	if ierr == nil {
+		if err == nil {
+			i.Response, ierr = json.Marshal(obj)
+		}
+		i.Error = newError(err)
+	}
+	var b []byte
+	if ierr == nil {
+		b, ierr = json.Marshal(i)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ierr == nil {
+		_, ierr = r.w.Write(append(b, '\n'))
+	}
+	if ierr != nil && r.err == nil {
+		r.err = ierr
+	}
+	return err
+}
+
+func (r *Recorder) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return r.record(VerbGet, obj, nil, key, func() error {
+		return r.Client.Get(ctx, key, obj)
+	})
+}
+
+func (r *Recorder) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	return r.record(VerbList, list, nil, client.ObjectKey{Namespace: listOpts.Namespace}, func() error {
+		return r.Client.List(ctx, list, opts...)
+	})
+}
+
+func (r *Recorder) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	return r.record(VerbCreate, obj, obj.DeepCopyObject(), objectKey(obj), func() error {
+		return r.Client.Create(ctx, obj, opts...)
+	})
+}
+
+func (r *Recorder) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return r.record(VerbUpdate, obj, obj.DeepCopyObject(), objectKey(obj), func() error {
+		return r.Client.Update(ctx, obj, opts...)
+	})
+}
+
+func (r *Recorder) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return r.record(VerbPatch, obj, obj.DeepCopyObject(), objectKey(obj), func() error {
+		return r.Client.Patch(ctx, obj, patch, opts...)
+	})
+}
+
+func (r *Recorder) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	return r.record(VerbDelete, obj, nil, objectKey(obj), func() error {
+		return r.Client.Delete(ctx, obj, opts...)
+	})
+}
+
+func (r *Recorder) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	deleteOpts := &client.DeleteAllOfOptions{}
+	deleteOpts.ApplyOptions(opts)
+	return r.record(VerbDeleteAllOf, obj, nil, client.ObjectKey{Namespace: deleteOpts.Namespace}, func() error {
+		return r.Client.DeleteAllOf(ctx, obj, opts...)
+	})
+}
+
+func (r *Recorder) Status() client.StatusWriter {
+	return statusRecorder{r}
+}
+
+type statusRecorder struct {
+	r *Recorder
+}
+
+func (s statusRecorder) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return s.r.record(VerbUpdateStatus, obj, obj.DeepCopyObject(), objectKey(obj), func() error {
+		return s.r.Client.Status().Update(ctx, obj, opts...)
+	})
+}
+
+func (s statusRecorder) Patch(ctx context.Context, obj runtime.Object, patch client.Patch,
+	opts ...client.PatchOption) error {
+	return s.r.record(VerbPatchStatus, obj, obj.DeepCopyObject(), objectKey(obj), func() error {
+		return s.r.Client.Status().Patch(ctx, obj, patch, opts...)
+	})
+}
+
+// objectKey returns the key of obj, or an empty key if obj has no metadata.
+func objectKey(obj runtime.Object) client.ObjectKey {
+	key, _ := client.ObjectKeyFromObject(obj)
+	return key
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+var _ = Describe("Record and replay", func() {
+	var (
+		sch *runtime.Scheme
+		ctx = context.TODO()
+		key = types.NamespacedName{Namespace: "default", Name: "etcd"}
+	)
+
+	// interact makes requests of a subscription, returning the objects read.
+	interact := func(c client.Client) (*v1alpha1.Subscription, *v1alpha1.SubscriptionList, error) {
+		sub := &v1alpha1.Subscription{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+		if err := c.Create(ctx, sub); err != nil {
+			return nil, nil, err
+		}
+		sub.Spec = &v1alpha1.SubscriptionSpec{Package: "etcd"}
+		if err := c.Update(ctx, sub); err != nil {
+			return nil, nil, err
+		}
+		got := &v1alpha1.Subscription{}
+		if err := c.Get(ctx, key, got); err != nil {
+			return nil, nil, err
+		}
+		list := &v1alpha1.SubscriptionList{}
+		if err := c.List(ctx, list, client.InNamespace(key.Namespace)); err != nil {
+			return nil, nil, err
+		}
+		// Reading a missing object fails.
+		err := c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "missing"}, &v1alpha1.Subscription{})
+		return got, list, err
+	}
+
+	BeforeEach(func() {
+		sch = runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+	})
+
+	It("replays recorded responses and errors", func() {
+		fixture := &bytes.Buffer{}
+		r := NewRecorder(fake.NewFakeClientWithScheme(sch), sch, fixture)
+		recordedSub, recordedList, recordedErr := interact(r)
+		Expect(apierrors.IsNotFound(recordedErr)).To(BeTrue())
+		Expect(r.Err()).NotTo(HaveOccurred())
+
+		interactions, err := ReadFixture(fixture)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(interactions).To(HaveLen(5))
+		Expect(interactions[0].String()).To(Equal("create operators.coreos.com/v1alpha1 Subscription default/etcd"))
+		Expect(interactions[3].String()).To(Equal("list operators.coreos.com/v1alpha1 SubscriptionList default/"))
+
+		c := NewClient(sch, interactions)
+		sub, list, err := interact(c)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(sub).To(Equal(recordedSub))
+		Expect(list).To(Equal(recordedList))
+		Expect(c.Unreplayed()).To(BeEmpty())
+	})
+	It("fails requests that were not recorded", func() {
+		c := NewClient(sch, nil)
+		err := c.Get(ctx, key, &v1alpha1.Subscription{})
+		Expect(err).To(MatchError("no recorded interaction for request " +
+			"get operators.coreos.com/v1alpha1 Subscription default/etcd"))
+	})
+	It("replays interactions of the same object in recorded order", func() {
+		interactions := []Interaction{
+			{Verb: VerbGet, APIVersion: "operators.coreos.com/v1alpha1", Kind: "Subscription",
+				Namespace: "default", Name: "etcd", Response: []byte(`{"status":{"state":"UpgradePending"}}`)},
+			{Verb: VerbGet, APIVersion: "operators.coreos.com/v1alpha1", Kind: "Subscription",
+				Namespace: "default", Name: "etcd", Response: []byte(`{"status":{"state":"AtLatestKnown"}}`)},
+		}
+		c := NewClient(sch, interactions)
+		sub := &v1alpha1.Subscription{}
+		Expect(c.Get(ctx, key, sub)).To(Succeed())
+		Expect(sub.Status.State).To(Equal(v1alpha1.SubscriptionState(v1alpha1.SubscriptionStateUpgradePending)))
+		Expect(c.Unreplayed()).To(HaveLen(1))
+		Expect(c.Get(ctx, key, sub)).To(Succeed())
+		Expect(sub.Status.State).To(Equal(v1alpha1.SubscriptionState(v1alpha1.SubscriptionStateAtLatest)))
+		Expect(c.Get(ctx, key, sub)).NotTo(Succeed())
+	})
+	It("returns the first error writing the fixture", func() {
+		r := NewRecorder(fake.NewFakeClientWithScheme(sch), sch, failingWriter{})
+		Expect(r.Get(ctx, key, &v1alpha1.Subscription{})).NotTo(Succeed())
+		Expect(r.Err()).To(MatchError("disk full"))
+	})
+	It("fails to read malformed fixtures", func() {
+		_, err := ReadFixture(bytes.NewBufferString("{}\nnot json\n"))
+		Expect(err).To(MatchError(ContainSubstring("line 2")))
+	})
+})
@@ -0,0 +1,118 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// RetryPolicy configures retries of API requests that fail with transient errors, like
+// throttling, server and webhook timeouts, and dropped connections, which are common on busy clusters.
+type RetryPolicy struct {
+	// InitialInterval is the time to wait before the first retry. It doubles after each retry up to MaxInterval.
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	// MaxElapsedTime is the maximum time spent retrying a request. If zero, requests are not retried.
+	MaxElapsedTime time.Duration
+	// Jitter is the maximum fraction of an interval randomly added to it, so clients retrying
+	// at the same time do not keep doing so.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is the RetryPolicy of a loaded Configuration.
+var DefaultRetryPolicy = RetryPolicy{
+	InitialInterval: 200 * time.Millisecond,
+	MaxInterval:     5 * time.Second,
+	MaxElapsedTime:  30 * time.Second,
+	Jitter:          0.2,
+}
+
+// Do calls f until it returns nil or an error that is not transient, p.MaxElapsedTime passes,
+// or ctx is done, and returns f's last error.
+func (p RetryPolicy) Do(ctx context.Context, f func() error) error {
+	return p.do(ctx, IsTransientError, f)
+}
+
+// OnConflict is like Do, but also retries f if it returns a conflict error. f must get the
+// latest version of any object it updates, since conflicts are caused by updating stale objects.
+// f must return API errors unwrapped, so conflicts can be detected; wrap the error OnConflict returns.
+func (p RetryPolicy) OnConflict(ctx context.Context, f func() error) error {
+	return p.do(ctx, func(err error) bool {
+		return apierrors.IsConflict(err) || IsTransientError(err)
+	}, f)
+}
+
+func (p RetryPolicy) do(ctx context.Context, retriable func(error) bool, f func() error) error {
+	interval, maxInterval := p.InitialInterval, p.MaxInterval
+	if interval <= 0 {
+		interval = DefaultRetryPolicy.InitialInterval
+	}
+	if maxInterval <= 0 {
+		maxInterval = DefaultRetryPolicy.MaxInterval
+	}
+	deadline := time.Now().Add(p.MaxElapsedTime)
+
+	for {
+		err := f()
+		if err == nil || !retriable(err) {
+			return err
+		}
+
+		wait := interval
+		// Respect the server's requested delay, ex. the Retry-After header of a throttled request.
+		if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+			if delay := time.Duration(seconds) * time.Second; delay > wait {
+				wait = delay
+			}
+		}
+		if p.Jitter > 0 {
+			wait += time.Duration(rand.Float64() * p.Jitter * float64(wait))
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return err
+		}
+		log.Debugf("Retrying request after transient error in %s: %v", wait, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// IsTransientError returns true if err is likely to not recur if the request that caused it is retried.
+func IsTransientError(err error) bool {
+	return apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		// Webhook timeouts and etcd leader changes are internal errors.
+		apierrors.IsInternalError(err) ||
+		utilnet.IsConnectionReset(err) ||
+		utilnet.IsConnectionRefused(err) ||
+		utilnet.IsProbableEOF(err)
+}
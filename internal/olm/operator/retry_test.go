@@ -0,0 +1,97 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("RetryPolicy", func() {
+	var (
+		p   RetryPolicy
+		ctx context.Context
+		gr  = schema.GroupResource{Resource: "subscriptions"}
+	)
+
+	BeforeEach(func() {
+		p = RetryPolicy{InitialInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond, MaxElapsedTime: time.Second}
+		ctx = context.TODO()
+	})
+
+	It("retries transient errors until the call succeeds", func() {
+		calls := 0
+		err := p.Do(ctx, func() error {
+			if calls++; calls < 3 {
+				return apierrors.NewTimeoutError("webhook timed out", 0)
+			}
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal(3))
+	})
+	It("does not retry other errors", func() {
+		calls := 0
+		err := p.Do(ctx, func() error {
+			calls++
+			return apierrors.NewNotFound(gr, "foo")
+		})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(calls).To(Equal(1))
+	})
+	It("does not retry if MaxElapsedTime is zero", func() {
+		p.MaxElapsedTime = 0
+		calls := 0
+		err := p.Do(ctx, func() error {
+			calls++
+			return apierrors.NewTooManyRequests("throttled", 0)
+		})
+		Expect(apierrors.IsTooManyRequests(err)).To(BeTrue())
+		Expect(calls).To(Equal(1))
+	})
+	It("stops retrying when the context is done", func() {
+		p.MaxElapsedTime = time.Hour
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		calls := 0
+		err := p.Do(ctx, func() error {
+			if calls++; calls == 2 {
+				cancel()
+			}
+			return apierrors.NewServiceUnavailable("unavailable")
+		})
+		Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+		Expect(calls).To(Equal(2))
+	})
+	It("retries conflicts with OnConflict only", func() {
+		calls := 0
+		f := func() error {
+			if calls++; calls < 2 {
+				return apierrors.NewConflict(gr, "foo", errors.New("stale"))
+			}
+			return nil
+		}
+		Expect(apierrors.IsConflict(p.Do(ctx, f))).To(BeTrue())
+		calls = 0
+		Expect(p.OnConflict(ctx, f)).To(Succeed())
+		Expect(calls).To(Equal(2))
+	})
+})
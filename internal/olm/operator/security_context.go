@@ -0,0 +1,124 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SecurityContextConfig is the security context applied to pods created by the SDK,
+// ex. registry pods.
+type SecurityContextConfig string
+
+const (
+	// SecurityContextConfigLegacy leaves pod security contexts unset.
+	SecurityContextConfigLegacy SecurityContextConfig = "legacy"
+	// SecurityContextConfigRestricted sets pod security contexts that satisfy the "restricted"
+	// Pod Security Standard and OpenShift's restricted-v2 SecurityContextConstraints.
+	SecurityContextConfigRestricted SecurityContextConfig = "restricted"
+)
+
+const (
+	// podSecurityEnforceLabel is the namespace label that sets the enforced Pod Security Standard level.
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	// openShiftSecurityGroupVersion is only served by OpenShift clusters.
+	openShiftSecurityGroupVersion = "security.openshift.io/v1"
+)
+
+var _ flag.Value = new(SecurityContextConfig)
+
+func (s *SecurityContextConfig) Set(str string) error {
+	switch v := SecurityContextConfig(str); v {
+	case SecurityContextConfigLegacy, SecurityContextConfigRestricted:
+		*s = v
+		return nil
+	}
+	return fmt.Errorf("security context config must be one of [%q, %q]",
+		SecurityContextConfigLegacy, SecurityContextConfigRestricted)
+}
+
+func (s SecurityContextConfig) String() string {
+	return string(s)
+}
+
+func (SecurityContextConfig) Type() string {
+	return "SecurityContextConfigValue"
+}
+
+// IsEmpty returns true if s was not set by a user.
+func (s SecurityContextConfig) IsEmpty() bool {
+	return s == ""
+}
+
+// DetectSecurityContextConfig returns the security context config pods created in namespace
+// require: restricted if the cluster is OpenShift, which assigns restricted-v2 SecurityContextConstraints
+// to pods by default, or namespace enforces the "restricted" Pod Security Standard; otherwise legacy.
+// If namespace cannot be read, ex. by users with only namespaced RBAC, legacy is returned.
+func DetectSecurityContextConfig(ctx context.Context, cfg *Configuration, namespace string) (SecurityContextConfig, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg.RESTConfig)
+	if err != nil {
+		return "", fmt.Errorf("error creating discovery client: %v", err)
+	}
+	return detectSecurityContextConfig(ctx, dc, cfg.Client, namespace)
+}
+
+func detectSecurityContextConfig(ctx context.Context, dc discovery.ServerGroupsInterface, c client.Client,
+	namespace string) (SecurityContextConfig, error) {
+	isOpenShift, err := IsOpenShift(dc)
+	if err != nil {
+		return "", err
+	}
+	if isOpenShift {
+		return SecurityContextConfigRestricted, nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) {
+			log.Debugf("Using the %s security context config, since namespace %q cannot be read: %v",
+				SecurityContextConfigLegacy, namespace, err)
+			return SecurityContextConfigLegacy, nil
+		}
+		return "", fmt.Errorf("error getting namespace %q: %v", namespace, err)
+	}
+	if ns.GetLabels()[podSecurityEnforceLabel] == string(SecurityContextConfigRestricted) {
+		return SecurityContextConfigRestricted, nil
+	}
+	return SecurityContextConfigLegacy, nil
+}
+
+// IsOpenShift returns true if dc's cluster serves OpenShift's security API.
+func IsOpenShift(dc discovery.ServerGroupsInterface) (bool, error) {
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return false, fmt.Errorf("error getting server groups: %v", err)
+	}
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			if version.GroupVersion == openShiftSecurityGroupVersion {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
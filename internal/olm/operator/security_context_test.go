@@ -0,0 +1,85 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("detectSecurityContextConfig", func() {
+	var (
+		ctx context.Context
+		dc  *discoveryfake.FakeDiscovery
+		c   client.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		dc = &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{}}
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		c = fake.NewFakeClientWithScheme(sch)
+	})
+
+	It("uses restricted on OpenShift", func() {
+		dc.Resources = []*metav1.APIResourceList{{GroupVersion: openShiftSecurityGroupVersion}}
+		Expect(detectSecurityContextConfig(ctx, dc, c, "default")).To(Equal(SecurityContextConfigRestricted))
+	})
+	It("uses restricted in namespaces enforcing the restricted Pod Security Standard", func() {
+		ns := &corev1.Namespace{}
+		ns.SetName("default")
+		ns.SetLabels(map[string]string{podSecurityEnforceLabel: "restricted"})
+		Expect(c.Create(ctx, ns)).To(Succeed())
+		Expect(detectSecurityContextConfig(ctx, dc, c, "default")).To(Equal(SecurityContextConfigRestricted))
+	})
+	It("uses legacy in other namespaces", func() {
+		ns := &corev1.Namespace{}
+		ns.SetName("default")
+		Expect(c.Create(ctx, ns)).To(Succeed())
+		Expect(detectSecurityContextConfig(ctx, dc, c, "default")).To(Equal(SecurityContextConfigLegacy))
+	})
+	It("uses legacy if the namespace is not found", func() {
+		Expect(detectSecurityContextConfig(ctx, dc, c, "default")).To(Equal(SecurityContextConfigLegacy))
+	})
+	It("uses legacy if the namespace may not be read", func() {
+		c = forbiddenNamespaceClient{c}
+		Expect(detectSecurityContextConfig(ctx, dc, c, "default")).To(Equal(SecurityContextConfigLegacy))
+	})
+})
+
+// forbiddenNamespaceClient is a client forbidden to get Namespaces.
+type forbiddenNamespaceClient struct {
+	client.Client
+}
+
+func (c forbiddenNamespaceClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if _, isNamespace := obj.(*corev1.Namespace); isNamespace {
+		return apierrors.NewForbidden(corev1.Resource("namespaces"), key.Name, errors.New("forbidden"))
+	}
+	return c.Client.Get(ctx, key, obj)
+}
@@ -0,0 +1,312 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Status gets the status of an operator installed by OLM: the state of its Subscription,
+// the health of its catalog, its install plans, its CSV, its operator Deployments, and the
+// components OLM aggregates in its Operator resource.
+type Status struct {
+	config *Configuration
+
+	Package string
+}
+
+func NewStatus(cfg *Configuration) *Status {
+	return &Status{
+		config: cfg,
+	}
+}
+
+// OperatorStatus is a summary of the OLM resources of an installed operator.
+type OperatorStatus struct {
+	Package       string              `json:"package"`
+	Namespace     string              `json:"namespace"`
+	Subscription  SubscriptionStatus  `json:"subscription"`
+	CatalogSource CatalogSourceStatus `json:"catalogSource"`
+	InstallPlans  []InstallPlanStatus `json:"installPlans,omitempty"`
+	// CSV is the installed CSV, or nil if no CSV is installed.
+	CSV         *CSVStatus         `json:"csv,omitempty"`
+	Deployments []DeploymentStatus `json:"deployments,omitempty"`
+	// Installs are the statuses of in-cluster installs of the operator, ex. by install Jobs.
+	Installs []InstallStatus `json:"installs,omitempty"`
+	// Components are the resources OLM references in the operator's Operator resource, which is
+	// empty if the cluster's OLM does not support the Operator API.
+	Components []ComponentStatus `json:"components,omitempty"`
+}
+
+type SubscriptionStatus struct {
+	Name         string `json:"name"`
+	Channel      string `json:"channel,omitempty"`
+	State        string `json:"state,omitempty"`
+	InstalledCSV string `json:"installedCSV,omitempty"`
+	CurrentCSV   string `json:"currentCSV,omitempty"`
+}
+
+type CatalogSourceStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// ConnectionState is the last observed state of OLM's connection to the catalog, ex. "READY".
+	ConnectionState string `json:"connectionState,omitempty"`
+	Healthy         bool   `json:"healthy"`
+}
+
+type InstallPlanStatus struct {
+	Name     string   `json:"name"`
+	Phase    string   `json:"phase,omitempty"`
+	Approved bool     `json:"approved"`
+	CSVNames []string `json:"csvNames,omitempty"`
+	// Pending is true if the install plan has not completed or failed.
+	Pending bool `json:"pending"`
+}
+
+type CSVStatus struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Phase   string `json:"phase,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type DeploymentStatus struct {
+	Name          string `json:"name"`
+	Replicas      int32  `json:"replicas"`
+	ReadyReplicas int32  `json:"readyReplicas"`
+	// Found is false if the CSV's Deployment has not been created.
+	Found bool `json:"found"`
+}
+
+type ComponentStatus struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Conditions are the component's status conditions, ex. a Deployment's "Available" condition.
+	Conditions []ComponentCondition `json:"conditions,omitempty"`
+}
+
+type ComponentCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Ready returns true if the operator's CSV succeeded and all of its Deployments' replicas are ready.
+func (s OperatorStatus) Ready() bool {
+	if s.CSV == nil || s.CSV.Phase != string(v1alpha1.CSVPhaseSucceeded) {
+		return false
+	}
+	for _, dep := range s.Deployments {
+		if !dep.Found || dep.ReadyReplicas < dep.Replicas {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Status) Run(ctx context.Context) (*OperatorStatus, error) {
+	installs, err := s.config.ListInstallStatuses(ctx, s.Package)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.getSubscription(ctx)
+	if err != nil {
+		// An in-cluster install may have failed before subscribing, which its status explains.
+		if len(installs) != 0 {
+			return &OperatorStatus{Package: s.Package, Namespace: s.config.Namespace, Installs: installs}, nil
+		}
+		return nil, err
+	}
+	status := &OperatorStatus{
+		Package:   s.Package,
+		Namespace: s.config.Namespace,
+		Subscription: SubscriptionStatus{
+			Name:         sub.GetName(),
+			Channel:      sub.Spec.Channel,
+			State:        string(sub.Status.State),
+			InstalledCSV: sub.Status.InstalledCSV,
+			CurrentCSV:   sub.Status.CurrentCSV,
+		},
+		Installs: installs,
+	}
+
+	if status.Components, err = s.getComponentStatuses(ctx); err != nil {
+		return nil, err
+	}
+	if status.CatalogSource, err = s.getCatalogSourceStatus(ctx, sub); err != nil {
+		return nil, err
+	}
+	if status.InstallPlans, err = s.getInstallPlanStatuses(ctx, sub); err != nil {
+		return nil, err
+	}
+	if sub.Status.InstalledCSV == "" {
+		return status, nil
+	}
+
+	csv := &v1alpha1.ClusterServiceVersion{}
+	csvKey := types.NamespacedName{Namespace: s.config.Namespace, Name: sub.Status.InstalledCSV}
+	if err := s.config.Client.Get(ctx, csvKey, csv); err != nil {
+		if apierrors.IsNotFound(err) {
+			return status, nil
+		}
+		return nil, fmt.Errorf("get cluster service version: %v", err)
+	}
+	status.CSV = &CSVStatus{
+		Name:    csv.GetName(),
+		Version: csv.Spec.Version.String(),
+		Phase:   string(csv.Status.Phase),
+		Reason:  string(csv.Status.Reason),
+		Message: csv.Status.Message,
+	}
+	if status.Deployments, err = s.getDeploymentStatuses(ctx, csv); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// getSubscription returns the Subscription to s.Package in the namespace.
+func (s *Status) getSubscription(ctx context.Context) (*v1alpha1.Subscription, error) {
+	subs := v1alpha1.SubscriptionList{}
+	if err := s.config.Client.List(ctx, &subs, client.InNamespace(s.config.Namespace)); err != nil {
+		return nil, fmt.Errorf("list subscriptions: %v", err)
+	}
+	for i := range subs.Items {
+		if subs.Items[i].Spec != nil && subs.Items[i].Spec.Package == s.Package {
+			return &subs.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("operator package %q not found in namespace %q", s.Package, s.config.Namespace)
+}
+
+func (s *Status) getCatalogSourceStatus(ctx context.Context, sub *v1alpha1.Subscription) (CatalogSourceStatus, error) {
+	status := CatalogSourceStatus{Name: sub.Spec.CatalogSource, Namespace: sub.Spec.CatalogSourceNamespace}
+	catsrc := &v1alpha1.CatalogSource{}
+	catsrcKey := types.NamespacedName{Namespace: status.Namespace, Name: status.Name}
+	if err := s.config.Client.Get(ctx, catsrcKey, catsrc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return status, nil
+		}
+		return status, fmt.Errorf("get catalog source: %v", err)
+	}
+	if catsrc.Status.GRPCConnectionState != nil {
+		status.ConnectionState = catsrc.Status.GRPCConnectionState.LastObservedState
+	}
+	// OLM reports catalog health on the subscription, which is more accurate than the
+	// connection state since OLM is slow to update the latter.
+	for _, health := range sub.Status.CatalogHealth {
+		if health.CatalogSourceRef != nil && health.CatalogSourceRef.Name == status.Name &&
+			health.CatalogSourceRef.Namespace == status.Namespace {
+			status.Healthy = health.Healthy
+			return status, nil
+		}
+	}
+	status.Healthy = status.ConnectionState == "READY"
+	return status, nil
+}
+
+// getInstallPlanStatuses returns the statuses of install plans owned by sub or referenced by its status.
+func (s *Status) getInstallPlanStatuses(ctx context.Context, sub *v1alpha1.Subscription) (statuses []InstallPlanStatus, err error) {
+	ips := v1alpha1.InstallPlanList{}
+	if err := s.config.Client.List(ctx, &ips, client.InNamespace(s.config.Namespace)); err != nil {
+		return nil, fmt.Errorf("list install plans: %v", err)
+	}
+	for _, ip := range ips.Items {
+		if !isOwnedBy(ip.GetOwnerReferences(), sub) &&
+			(sub.Status.InstallPlanRef == nil || sub.Status.InstallPlanRef.Name != ip.GetName()) {
+			continue
+		}
+		statuses = append(statuses, InstallPlanStatus{
+			Name:     ip.GetName(),
+			Phase:    string(ip.Status.Phase),
+			Approved: ip.Spec.Approved,
+			CSVNames: ip.Spec.ClusterServiceVersionNames,
+			Pending: ip.Status.Phase != v1alpha1.InstallPlanPhaseComplete &&
+				ip.Status.Phase != v1alpha1.InstallPlanPhaseFailed,
+		})
+	}
+	return statuses, nil
+}
+
+// getDeploymentStatuses returns the readiness of the Deployments in csv's install strategy.
+func (s *Status) getDeploymentStatuses(ctx context.Context, csv *v1alpha1.ClusterServiceVersion) (statuses []DeploymentStatus, err error) {
+	for _, spec := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		status := DeploymentStatus{Name: spec.Name}
+		if spec.Spec.Replicas != nil {
+			status.Replicas = *spec.Spec.Replicas
+		}
+		dep := &appsv1.Deployment{}
+		depKey := types.NamespacedName{Namespace: s.config.Namespace, Name: spec.Name}
+		if err := s.config.Client.Get(ctx, depKey, dep); err == nil {
+			status.Found = true
+			status.Replicas = dep.Status.Replicas
+			status.ReadyReplicas = dep.Status.ReadyReplicas
+		} else if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("get deployment %q: %v", spec.Name, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// getComponentStatuses returns the components referenced by the operator's Operator resource.
+func (s *Status) getComponentStatuses(ctx context.Context) (statuses []ComponentStatus, err error) {
+	refs, err := getOperatorComponents(ctx, s.config, s.Package)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		if ref.ObjectReference == nil {
+			continue
+		}
+		status := ComponentStatus{
+			APIVersion: ref.APIVersion,
+			Kind:       ref.Kind,
+			Namespace:  ref.Namespace,
+			Name:       ref.Name,
+		}
+		for _, cond := range ref.Conditions {
+			status.Conditions = append(status.Conditions, ComponentCondition{
+				Type:    string(cond.Type),
+				Status:  string(cond.Status),
+				Reason:  cond.Reason,
+				Message: cond.Message,
+			})
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func isOwnedBy(refs []metav1.OwnerReference, owner *v1alpha1.Subscription) bool {
+	for _, ref := range refs {
+		if (owner.GetUID() != "" && ref.UID == owner.GetUID()) ||
+			(ref.Kind == v1alpha1.SubscriptionKind && ref.Name == owner.GetName()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Status", func() {
+	var (
+		cfg *Configuration
+		sub *v1alpha1.Subscription
+		csv *v1alpha1.ClusterServiceVersion
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		ctx = context.TODO()
+
+		sub = &v1alpha1.Subscription{}
+		sub.SetName("memcached-operator-v0-0-1-sub")
+		sub.SetNamespace("default")
+		sub.Spec = &v1alpha1.SubscriptionSpec{
+			Package:                "memcached-operator",
+			Channel:                "alpha",
+			CatalogSource:          "memcached-operator-catalog",
+			CatalogSourceNamespace: "default",
+		}
+		sub.Status.State = v1alpha1.SubscriptionStateAtLatest
+		sub.Status.InstalledCSV = "memcached-operator.v0.0.1"
+		sub.Status.CurrentCSV = "memcached-operator.v0.0.1"
+		sub.Status.InstallPlanRef = &corev1.ObjectReference{Name: "install-abcde", Namespace: "default"}
+
+		catsrc := &v1alpha1.CatalogSource{}
+		catsrc.SetName("memcached-operator-catalog")
+		catsrc.SetNamespace("default")
+		catsrc.Status.GRPCConnectionState = &v1alpha1.GRPCConnectionState{LastObservedState: "READY"}
+
+		ip := &v1alpha1.InstallPlan{}
+		ip.SetName("install-abcde")
+		ip.SetNamespace("default")
+		ip.Spec.Approved = true
+		ip.Spec.ClusterServiceVersionNames = []string{"memcached-operator.v0.0.1"}
+		ip.Status.Phase = v1alpha1.InstallPlanPhaseComplete
+		otherIP := &v1alpha1.InstallPlan{}
+		otherIP.SetName("install-fghij")
+		otherIP.SetNamespace("default")
+
+		replicas := int32(1)
+		csv = &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.SetNamespace("default")
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{{
+			Name: "memcached-operator-controller-manager",
+			Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+		}}
+		csv.Status.Phase = v1alpha1.CSVPhaseSucceeded
+
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:      "memcached-operator-controller-manager",
+			Namespace: "default",
+		}}
+		dep.Status.Replicas = 1
+		dep.Status.ReadyReplicas = 1
+
+		c := fake.NewFakeClientWithScheme(sch, sub, catsrc, ip, otherIP, csv, dep)
+		cfg = &Configuration{Namespace: "default", Scheme: sch, Client: c}
+	})
+
+	It("summarizes a ready operator", func() {
+		s := NewStatus(cfg)
+		s.Package = "memcached-operator"
+		status, err := s.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(status.Subscription).To(Equal(SubscriptionStatus{
+			Name:         "memcached-operator-v0-0-1-sub",
+			Channel:      "alpha",
+			State:        string(v1alpha1.SubscriptionStateAtLatest),
+			InstalledCSV: "memcached-operator.v0.0.1",
+			CurrentCSV:   "memcached-operator.v0.0.1",
+		}))
+		Expect(status.CatalogSource.ConnectionState).To(Equal("READY"))
+		Expect(status.CatalogSource.Healthy).To(BeTrue())
+		Expect(status.InstallPlans).To(Equal([]InstallPlanStatus{{
+			Name:     "install-abcde",
+			Phase:    string(v1alpha1.InstallPlanPhaseComplete),
+			Approved: true,
+			CSVNames: []string{"memcached-operator.v0.0.1"},
+		}}))
+		Expect(status.CSV).NotTo(BeNil())
+		Expect(status.CSV.Phase).To(Equal(string(v1alpha1.CSVPhaseSucceeded)))
+		Expect(status.Deployments).To(Equal([]DeploymentStatus{{
+			Name:          "memcached-operator-controller-manager",
+			Replicas:      1,
+			ReadyReplicas: 1,
+			Found:         true,
+		}}))
+		Expect(status.Components).To(BeEmpty())
+		Expect(status.Ready()).To(BeTrue())
+	})
+	It("lists the components of the operator's Operator resource", func() {
+		op := &v1.Operator{}
+		op.SetName("memcached-operator.default")
+		op.Status.Components = &v1.Components{Refs: []v1.RichReference{
+			{
+				ObjectReference: &corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment",
+					Namespace: "default", Name: "memcached-operator-controller-manager"},
+				Conditions: []v1.Condition{{Type: "Available", Status: corev1.ConditionTrue}},
+			},
+			{ObjectReference: &corev1.ObjectReference{APIVersion: "rbac.authorization.k8s.io/v1",
+				Kind: "ClusterRole", Name: "memcached-operator-metrics-reader"}},
+		}}
+		Expect(cfg.Client.Create(ctx, op)).To(Succeed())
+
+		s := NewStatus(cfg)
+		s.Package = "memcached-operator"
+		status, err := s.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Components).To(Equal([]ComponentStatus{
+			{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Namespace:  "default",
+				Name:       "memcached-operator-controller-manager",
+				Conditions: []ComponentCondition{{Type: "Available", Status: "True"}},
+			},
+			{
+				APIVersion: "rbac.authorization.k8s.io/v1",
+				Kind:       "ClusterRole",
+				Name:       "memcached-operator-metrics-reader",
+			},
+		}))
+	})
+	It("is not ready if an operator deployment is missing", func() {
+		Expect(cfg.Client.Delete(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:      "memcached-operator-controller-manager",
+			Namespace: "default",
+		}})).To(Succeed())
+
+		s := NewStatus(cfg)
+		s.Package = "memcached-operator"
+		status, err := s.Run(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Deployments).To(HaveLen(1))
+		Expect(status.Deployments[0].Found).To(BeFalse())
+		Expect(status.Ready()).To(BeFalse())
+	})
+	It("fails if the package is not installed", func() {
+		s := NewStatus(cfg)
+		s.Package = "other-operator"
+		_, err := s.Run(ctx)
+		Expect(err).To(MatchError(ContainSubstring(`operator package "other-operator" not found`)))
+	})
+})
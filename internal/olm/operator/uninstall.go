@@ -23,6 +23,7 @@ import (
 	v1 "github.com/operator-framework/api/pkg/operators/v1"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -31,6 +32,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/logutil"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
 )
 
 type Uninstall struct {
@@ -42,16 +46,46 @@ type Uninstall struct {
 	DeleteOperatorGroups     bool
 	DeleteOperatorGroupNames []string
 
+	// Force removes known finalizers from objects that are not deleted within ForceGracePeriod,
+	// and from the CRs of CRDs that are not, so operators that cannot finalize them do not block cleanup.
+	// Other finalizers are reported but not removed.
+	Force            bool
+	ForceGracePeriod time.Duration
+
+	// Logf logs deleted objects. If nil, they are logged with structured fields by the standard logger.
 	Logf func(string, ...interface{})
 }
 
+// KnownFinalizers are the finalizers Uninstall.Force removes, which the SDK knows are safe to remove
+// once the object's owner cannot be expected to remove them. The customresourcecleanup.apiextensions.k8s.io
+// finalizer of CRDs is not removed, since the API server deletes a CRD's CRs before removing it, and CRs
+// left behind would reappear if the CRD were recreated. Instead, known finalizers are removed from the
+// CRs blocking it.
+var KnownFinalizers = []string{
+	// Added to CRs by Helm-based operators, to uninstall their releases.
+	"uninstall-helm-release",
+}
+
+const (
+	// deletionProgressInterval is how often the finalizers of an object being deleted are logged.
+	deletionProgressInterval = 10 * time.Second
+	deletionPollInterval     = 250 * time.Millisecond
+)
+
 func NewUninstall(cfg *Configuration) *Uninstall {
 	return &Uninstall{
 		config: cfg,
 	}
 }
 
-func (u *Uninstall) Run(ctx context.Context) error {
+func (u *Uninstall) Run(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "uninstall operator", tracing.String("package", u.Package),
+		tracing.String("namespace", u.config.Namespace))
+	defer func() { span.End(err) }()
+	return u.run(ctx)
+}
+
+func (u *Uninstall) run(ctx context.Context) error {
 	if u.DeleteAll {
 		u.DeleteCRDs = true
 		u.DeleteOperatorGroups = true
@@ -100,6 +134,13 @@ func (u *Uninstall) Run(ctx context.Context) error {
 		}
 	}
 
+	// OLM references every component of the operator in its Operator resource, including ones not
+	// created by the install plan, ex. by a later upgrade.
+	crds, others, err := u.addOperatorComponents(ctx, crds, csvs, others)
+	if err != nil {
+		return err
+	}
+
 	// Delete the subscription first, so that no further installs or upgrades
 	// of the operator occur while we're cleaning up.
 	if err := u.deleteObjects(ctx, false, sub); err != nil {
@@ -127,6 +168,11 @@ func (u *Uninstall) Run(ctx context.Context) error {
 		return err
 	}
 
+	// OLM recreates the Operator resource while any labeled component remains, so delete it last.
+	if err := u.deleteOperatorResource(ctx); err != nil {
+		return err
+	}
+
 	// If this was the last subscription in the namespace and the operator group is
 	// the one we created, delete it
 	if u.DeleteOperatorGroups {
@@ -153,30 +199,219 @@ func (u *Uninstall) Run(ctx context.Context) error {
 
 func (u *Uninstall) deleteObjects(ctx context.Context, waitForDelete bool, objs ...controllerutil.Object) error {
 	for _, obj := range objs {
-		obj := obj
-		lowerKind := strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind)
-		if err := u.config.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
-			return fmt.Errorf("delete %s %q: %v", lowerKind, obj.GetName(), err)
-		} else if err == nil {
+		if err := u.deleteObject(ctx, waitForDelete, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteObject deletes obj, recording a span of its deletion.
+func (u *Uninstall) deleteObject(ctx context.Context, waitForDelete bool, obj controllerutil.Object) (err error) {
+	lowerKind := strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind)
+	ctx, span := tracing.Start(ctx, "delete "+lowerKind, tracing.String("name", obj.GetName()))
+	defer func() { span.End(err) }()
+
+	if err := u.config.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete %s %q: %v", lowerKind, obj.GetName(), err)
+	} else if err == nil {
+		if u.Logf != nil {
 			u.Logf("%s %q deleted", lowerKind, obj.GetName())
+		} else {
+			logutil.WithObject(obj).Info("Deleted resource")
+		}
+	}
+	if waitForDelete {
+		return u.waitForDeletion(ctx, obj)
+	}
+	return nil
+}
+
+// waitForDeletion waits for obj to be deleted, logging the finalizers blocking its deletion every
+// deletionProgressInterval, and removing known finalizers once ForceGracePeriod has passed if Force is set.
+func (u *Uninstall) waitForDeletion(ctx context.Context, obj controllerutil.Object) error {
+	lowerKind := strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind)
+	key, err := client.ObjectKeyFromObject(obj)
+	if err != nil {
+		return fmt.Errorf("get %s key: %v", lowerKind, err)
+	}
+	start := time.Now()
+	lastProgress, forced := start, false
+	err = wait.PollImmediateUntil(deletionPollInterval, func() (bool, error) {
+		if err := u.config.Client.Get(ctx, key, obj); apierrors.IsNotFound(err) {
+			return true, nil
+		} else if err != nil {
+			return false, err
 		}
-		if waitForDelete {
-			key, err := client.ObjectKeyFromObject(obj)
-			if err != nil {
-				return fmt.Errorf("get %s key: %v", lowerKind, err)
+		if time.Since(lastProgress) >= deletionProgressInterval {
+			lastProgress = time.Now()
+			u.logf(obj, "Waiting for resource to be deleted", "waiting for %s %q to be deleted, finalizers: %v",
+				lowerKind, obj.GetName(), obj.GetFinalizers())
+		}
+		if u.Force && !forced && time.Since(start) >= u.ForceGracePeriod {
+			forced = true
+			if err := u.forceDeletion(ctx, obj); err != nil {
+				return false, err
 			}
-			if err := wait.PollImmediateUntil(250*time.Millisecond, func() (bool, error) {
-				if err := u.config.Client.Get(ctx, key, obj); apierrors.IsNotFound(err) {
-					return true, nil
-				} else if err != nil {
-					return false, err
+		}
+		return false, nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		err = fmt.Errorf("timed out with finalizers %v", obj.GetFinalizers())
+		if !u.Force {
+			err = fmt.Errorf("%v, set --force to remove known finalizers", err)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("wait for %s %q deleted: %v", lowerKind, obj.GetName(), err)
+	}
+	return nil
+}
+
+// forceDeletion removes known finalizers from obj, and if obj is a CRD, from its CRs being deleted.
+func (u *Uninstall) forceDeletion(ctx context.Context, obj controllerutil.Object) error {
+	if obj.GetObjectKind().GroupVersionKind().Kind == "CustomResourceDefinition" {
+		crs, err := u.listCRs(ctx, obj)
+		if err != nil {
+			return err
+		}
+		for _, cr := range crs {
+			if cr.GetDeletionTimestamp() != nil {
+				if err := u.removeKnownFinalizers(ctx, cr); err != nil {
+					return err
 				}
-				return false, nil
-			}, ctx.Done()); err != nil {
-				return fmt.Errorf("wait for %s deleted: %v", lowerKind, err)
 			}
 		}
 	}
+	return u.removeKnownFinalizers(ctx, obj)
+}
+
+// removeKnownFinalizers removes KnownFinalizers from obj, logging the finalizers it does not remove.
+func (u *Uninstall) removeKnownFinalizers(ctx context.Context, obj controllerutil.Object) error {
+	lowerKind := strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind)
+	var kept, removed []string
+	for _, f := range obj.GetFinalizers() {
+		if slice.ContainsString(KnownFinalizers, f, nil) {
+			removed = append(removed, f)
+		} else {
+			kept = append(kept, f)
+		}
+	}
+	if len(removed) != 0 {
+		base := obj.DeepCopyObject()
+		obj.SetFinalizers(kept)
+		if err := u.config.Client.Patch(ctx, obj, client.MergeFrom(base)); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("remove finalizers of %s %q: %v", lowerKind, obj.GetName(), err)
+		}
+		u.logf(obj, "Removed finalizers", "removed finalizers %v of %s %q", removed, lowerKind, obj.GetName())
+	}
+	if len(kept) != 0 {
+		u.logf(obj, "Unknown finalizers not removed", "finalizers %v of %s %q are unknown, so not removed",
+			kept, lowerKind, obj.GetName())
+	}
+	return nil
+}
+
+// listCRs lists the CRs of the served version of crd.
+func (u *Uninstall) listCRs(ctx context.Context, crd controllerutil.Object) ([]*unstructured.Unstructured, error) {
+	unstr, ok := crd.(*unstructured.Unstructured)
+	if !ok {
+		return nil, nil
+	}
+	group, _, _ := unstructured.NestedString(unstr.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(unstr.Object, "spec", "names", "kind")
+	version, _, _ := unstructured.NestedString(unstr.Object, "spec", "version")
+	versions, _, _ := unstructured.NestedSlice(unstr.Object, "spec", "versions")
+	for _, v := range versions {
+		if m, ok := v.(map[string]interface{}); ok && m["served"] == true {
+			version, _ = m["name"].(string)
+			break
+		}
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: group, Version: version, Kind: kind + "List"})
+	if err := u.config.Client.List(ctx, list); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list %s: %v", strings.ToLower(kind), err)
+	}
+	crs := make([]*unstructured.Unstructured, len(list.Items))
+	for i := range list.Items {
+		crs[i] = &list.Items[i]
+	}
+	return crs, nil
+}
+
+// logf logs msg with obj's fields, or format with args if u.Logf is set.
+func (u *Uninstall) logf(obj controllerutil.Object, msg, format string, args ...interface{}) {
+	if u.Logf != nil {
+		u.Logf(format, args...)
+		return
+	}
+	logutil.WithObject(obj).WithField("finalizers", obj.GetFinalizers()).Info(msg)
+}
+
+// operatorComponentSkipKinds are the kinds of components of an Operator resource addOperatorComponents
+// skips, since they are deleted separately, garbage collected, or shared with other operators.
+var operatorComponentSkipKinds = []string{
+	v1alpha1.SubscriptionKind,
+	v1alpha1.InstallPlanKind,
+	v1alpha1.CatalogSourceKind,
+	v1.OperatorGroupKind,
+	"Namespace",
+}
+
+// addOperatorComponents adds the components of the operator's Operator resource that are not in crds,
+// csvs, or others to crds or others, so components not created by the operator's install plan are deleted.
+func (u *Uninstall) addOperatorComponents(ctx context.Context, crds, csvs, others []controllerutil.Object) (
+	[]controllerutil.Object, []controllerutil.Object, error) {
+
+	refs, err := getOperatorComponents(ctx, u.config, u.Package)
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := map[string]bool{}
+	for _, objs := range [][]controllerutil.Object{crds, csvs, others} {
+		for _, obj := range objs {
+			seen[componentKey(obj.GetObjectKind().GroupVersionKind(), obj.GetNamespace(), obj.GetName())] = true
+		}
+	}
+	for _, ref := range refs {
+		obj := componentObject(ref)
+		if obj == nil || slice.ContainsString(operatorComponentSkipKinds, obj.GetKind(), nil) {
+			continue
+		}
+		key := componentKey(obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if obj.GetKind() == "CustomResourceDefinition" {
+			crds = append(crds, obj)
+		} else {
+			others = append(others, obj)
+		}
+	}
+	return crds, others, nil
+}
+
+// deleteOperatorResource deletes the operator's Operator resource, if the cluster's OLM supports them.
+func (u *Uninstall) deleteOperatorResource(ctx context.Context) error {
+	op := &v1.Operator{}
+	op.SetGroupVersionKind(v1.GroupVersion.WithKind("Operator"))
+	op.SetName(OperatorResourceName(u.Package, u.config.Namespace))
+	if err := u.config.Client.Delete(ctx, op); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return fmt.Errorf("delete operator %q: %v", op.GetName(), err)
+	}
+	if u.Logf != nil {
+		u.Logf("operator %q deleted", op.GetName())
+	} else {
+		logutil.WithObject(op).Info("Deleted resource")
+	}
 	return nil
 }
 
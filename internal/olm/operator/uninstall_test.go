@@ -0,0 +1,145 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Uninstall", func() {
+	var (
+		u   *Uninstall
+		cfg *Configuration
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		cfg = &Configuration{Namespace: "default", Scheme: sch, Client: fake.NewFakeClientWithScheme(sch)}
+		u = NewUninstall(cfg)
+		u.Logf = func(string, ...interface{}) {}
+		ctx = context.TODO()
+	})
+
+	configMap := func(name string, finalizers ...string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{}
+		cm.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+		cm.SetName(name)
+		cm.SetNamespace("default")
+		cm.SetFinalizers(finalizers)
+		return cm
+	}
+
+	Describe("removeKnownFinalizers", func() {
+		It("removes known finalizers and keeps unknown ones", func() {
+			cm := configMap("foo", "uninstall-helm-release", "example.com/finalizer")
+			Expect(cfg.Client.Create(ctx, cm)).To(Succeed())
+
+			Expect(u.removeKnownFinalizers(ctx, cm)).To(Succeed())
+			got := &corev1.ConfigMap{}
+			Expect(cfg.Client.Get(ctx, types.NamespacedName{Name: "foo", Namespace: "default"}, got)).To(Succeed())
+			Expect(got.GetFinalizers()).To(Equal([]string{"example.com/finalizer"}))
+		})
+		It("does not update an object without known finalizers", func() {
+			cm := configMap("foo", "example.com/finalizer")
+			Expect(cfg.Client.Create(ctx, cm)).To(Succeed())
+			rv := cm.GetResourceVersion()
+
+			Expect(u.removeKnownFinalizers(ctx, cm)).To(Succeed())
+			got := &corev1.ConfigMap{}
+			Expect(cfg.Client.Get(ctx, types.NamespacedName{Name: "foo", Namespace: "default"}, got)).To(Succeed())
+			Expect(got.GetResourceVersion()).To(Equal(rv))
+		})
+	})
+
+	Describe("forceDeletion", func() {
+		It("removes known finalizers from the CRs of a CRD being deleted", func() {
+			gvk := schema.GroupVersionKind{Group: "cache.example.com", Version: "v1alpha1", Kind: "Memcached"}
+			cfg.Scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+			cfg.Scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind("MemcachedList"), &unstructured.UnstructuredList{})
+			crd := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apiextensions.k8s.io/v1",
+				"kind":       "CustomResourceDefinition",
+				"metadata": map[string]interface{}{
+					"name":       "memcacheds.cache.example.com",
+					"finalizers": []interface{}{"customresourcecleanup.apiextensions.k8s.io"},
+				},
+				"spec": map[string]interface{}{
+					"group": gvk.Group,
+					"names": map[string]interface{}{"kind": gvk.Kind},
+					"versions": []interface{}{
+						map[string]interface{}{"name": "v1alpha0", "served": false},
+						map[string]interface{}{"name": gvk.Version, "served": true},
+					},
+				},
+			}}
+			Expect(cfg.Client.Create(ctx, crd)).To(Succeed())
+
+			deleting, live := &unstructured.Unstructured{}, &unstructured.Unstructured{}
+			for _, cr := range []*unstructured.Unstructured{deleting, live} {
+				cr.SetGroupVersionKind(gvk)
+				cr.SetNamespace("default")
+				cr.SetFinalizers([]string{"uninstall-helm-release"})
+			}
+			deleting.SetName("deleting")
+			now := metav1.Now()
+			deleting.SetDeletionTimestamp(&now)
+			live.SetName("live")
+			Expect(cfg.Client.Create(ctx, deleting)).To(Succeed())
+			Expect(cfg.Client.Create(ctx, live)).To(Succeed())
+
+			Expect(u.forceDeletion(ctx, crd)).To(Succeed())
+			got := &unstructured.Unstructured{}
+			got.SetGroupVersionKind(gvk)
+			Expect(cfg.Client.Get(ctx, types.NamespacedName{Name: "deleting", Namespace: "default"}, got)).To(Succeed())
+			Expect(got.GetFinalizers()).To(BeEmpty())
+			Expect(cfg.Client.Get(ctx, types.NamespacedName{Name: "live", Namespace: "default"}, got)).To(Succeed())
+			Expect(got.GetFinalizers()).To(Equal([]string{"uninstall-helm-release"}))
+			Expect(crd.GetFinalizers()).To(Equal([]string{"customresourcecleanup.apiextensions.k8s.io"}))
+		})
+	})
+
+	Describe("waitForDeletion", func() {
+		It("reports the finalizers of an object that is not deleted in time", func() {
+			cm := configMap("foo", "example.com/finalizer")
+			Expect(cfg.Client.Create(ctx, cm)).To(Succeed())
+
+			ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer cancel()
+			err := u.waitForDeletion(ctx, cm)
+			Expect(err).To(MatchError(ContainSubstring("timed out with finalizers [example.com/finalizer]")))
+			Expect(err).To(MatchError(ContainSubstring("set --force")))
+		})
+		It("returns once the object is deleted", func() {
+			cm := configMap("foo")
+			Expect(cfg.Client.Create(ctx, cm)).To(Succeed())
+			Expect(cfg.Client.Delete(ctx, cm)).To(Succeed())
+			Expect(u.waitForDeletion(ctx, cm)).To(Succeed())
+		})
+	})
+})
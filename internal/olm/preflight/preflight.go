@@ -0,0 +1,349 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package preflight checks whether a cluster meets the requirements of an
+// Operator bundle before that bundle is installed with OLM.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+const (
+	olmCheck          = "olm"
+	kubeVersionCheck  = "kube-version"
+	requiredAPIsCheck = "required-apis"
+	installModeCheck  = "install-mode"
+	rbacCheck         = "rbac"
+	storageClassCheck = "storage-class"
+)
+
+// Annotations marking a StorageClass as the cluster default.
+var defaultStorageClassAnnotations = []string{
+	"storageclass.kubernetes.io/is-default-class",
+	"storageclass.beta.kubernetes.io/is-default-class",
+}
+
+// Checker runs preflight checks for a bundle's CSV against a cluster.
+type Checker struct {
+	// InstallMode is the install mode the operator will be installed with.
+	// If unset, the mode is inferred from an existing OperatorGroup or
+	// defaults to AllNamespaces, the mode 'run bundle' uses by default.
+	InstallMode operator.InstallMode
+
+	cfg           *operator.Configuration
+	discovery     discovery.DiscoveryInterface
+	groupVersions sets.String
+}
+
+func NewChecker(cfg *operator.Configuration) *Checker {
+	return &Checker{cfg: cfg}
+}
+
+// Run runs all preflight checks for csv and returns a report of their results.
+// An error is returned only if a check could not be run at all.
+func (c *Checker) Run(ctx context.Context, csv *v1alpha1.ClusterServiceVersion) (report Report, err error) {
+	if c.discovery == nil {
+		if c.discovery, err = discovery.NewDiscoveryClientForConfig(c.cfg.RESTConfig); err != nil {
+			return report, fmt.Errorf("create discovery client: %v", err)
+		}
+	}
+
+	checks := []func(context.Context, *Report, *v1alpha1.ClusterServiceVersion) error{
+		c.checkOLM,
+		c.checkKubeVersion,
+		c.checkRequiredAPIs,
+		c.checkInstallMode,
+		c.checkRBAC,
+		c.checkStorageClass,
+		c.checkResources,
+	}
+	for _, check := range checks {
+		if err := check(ctx, &report, csv); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// checkOLM checks that OLM's APIs are served by the cluster.
+func (c *Checker) checkOLM(_ context.Context, report *Report, _ *v1alpha1.ClusterServiceVersion) error {
+	found, err := c.hasAPI(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.ClusterServiceVersionKind))
+	if err != nil {
+		return fmt.Errorf("discover OLM APIs: %v", err)
+	}
+	if !found {
+		report.fail(olmCheck, "OLM is not installed, run 'operator-sdk olm install' to install it")
+		return nil
+	}
+	report.pass(olmCheck, "OLM is installed")
+	return nil
+}
+
+// checkKubeVersion checks that the cluster's Kubernetes version is at least the CSV's minKubeVersion.
+func (c *Checker) checkKubeVersion(_ context.Context, report *Report, csv *v1alpha1.ClusterServiceVersion) error {
+	if csv.Spec.MinKubeVersion == "" {
+		report.pass(kubeVersionCheck, "no minimum Kubernetes version required")
+		return nil
+	}
+	minVersion, err := semver.ParseTolerant(csv.Spec.MinKubeVersion)
+	if err != nil {
+		report.fail(kubeVersionCheck, "invalid minKubeVersion %q: %v", csv.Spec.MinKubeVersion, err)
+		return nil
+	}
+	info, err := c.discovery.ServerVersion()
+	if err != nil {
+		return fmt.Errorf("get server version: %v", err)
+	}
+	serverVersion, err := semver.ParseTolerant(info.GitVersion)
+	if err != nil {
+		report.warn(kubeVersionCheck, "unable to parse server version %q: %v", info.GitVersion, err)
+		return nil
+	}
+	// Distributions attach pre-release and build metadata to their versions,
+	// ex. v1.18.3+6c42de8, which should not affect the comparison.
+	serverVersion.Pre, serverVersion.Build = nil, nil
+	minVersion.Pre, minVersion.Build = nil, nil
+	if serverVersion.LT(minVersion) {
+		report.fail(kubeVersionCheck, "server version %s is less than minKubeVersion %s", serverVersion, minVersion)
+		return nil
+	}
+	report.pass(kubeVersionCheck, "server version %s satisfies minKubeVersion %s", serverVersion, minVersion)
+	return nil
+}
+
+// checkRequiredAPIs checks that APIs the CSV depends on are served by the cluster. Missing native APIs
+// fail the check, while missing required CRDs and APIServices only warn since OLM may resolve them
+// from a dependency in the catalog.
+func (c *Checker) checkRequiredAPIs(_ context.Context, report *Report, csv *v1alpha1.ClusterServiceVersion) error {
+	var missingNative, missingRequired []string
+	for _, gvk := range csv.Spec.NativeAPIs {
+		found, err := c.hasAPI(schema.GroupVersionKind(gvk))
+		if err != nil {
+			return fmt.Errorf("discover native API %s: %v", gvk, err)
+		}
+		if !found {
+			missingNative = append(missingNative, formatGVK(schema.GroupVersionKind(gvk)))
+		}
+	}
+
+	var required []schema.GroupVersionKind
+	for _, desc := range csv.Spec.CustomResourceDefinitions.Required {
+		group := ""
+		if split := strings.SplitN(desc.Name, ".", 2); len(split) == 2 {
+			group = split[1]
+		}
+		required = append(required, schema.GroupVersionKind{Group: group, Version: desc.Version, Kind: desc.Kind})
+	}
+	for _, desc := range csv.Spec.APIServiceDefinitions.Required {
+		required = append(required, schema.GroupVersionKind{Group: desc.Group, Version: desc.Version, Kind: desc.Kind})
+	}
+	for _, gvk := range required {
+		found, err := c.hasAPI(gvk)
+		if err != nil {
+			return fmt.Errorf("discover required API %s: %v", gvk, err)
+		}
+		if !found {
+			missingRequired = append(missingRequired, formatGVK(gvk))
+		}
+	}
+
+	switch {
+	case len(missingNative) != 0:
+		report.fail(requiredAPIsCheck, "native APIs not served by the cluster: %s", strings.Join(missingNative, ", "))
+	case len(missingRequired) != 0:
+		report.warn(requiredAPIsCheck, "required APIs not served by the cluster, they must be provided by a dependency: %s",
+			strings.Join(missingRequired, ", "))
+	default:
+		report.pass(requiredAPIsCheck, "all required APIs are served by the cluster")
+	}
+	return nil
+}
+
+// checkInstallMode checks that the install mode the operator will be installed with, either set
+// explicitly or determined by an existing OperatorGroup, is supported by the CSV.
+func (c *Checker) checkInstallMode(ctx context.Context, report *Report, csv *v1alpha1.ClusterServiceVersion) error {
+	ogs := v1.OperatorGroupList{}
+	if err := c.cfg.Client.List(ctx, &ogs, client.InNamespace(c.cfg.Namespace)); err != nil {
+		return fmt.Errorf("list operator groups: %v", err)
+	}
+
+	modeType := c.InstallMode.InstallModeType
+	switch len(ogs.Items) {
+	case 0:
+		if modeType == "" {
+			modeType = v1alpha1.InstallModeTypeAllNamespaces
+		}
+	case 1:
+		og := ogs.Items[0]
+		targetNamespaces := og.Status.Namespaces
+		if len(targetNamespaces) == 0 {
+			targetNamespaces = og.Spec.TargetNamespaces
+		}
+		ogModeType := installModeTypeFor(c.cfg.Namespace, targetNamespaces)
+		if modeType != "" && modeType != ogModeType {
+			report.fail(installModeCheck, "existing operator group %q has install mode %s, not %s",
+				og.GetName(), ogModeType, modeType)
+			return nil
+		}
+		modeType = ogModeType
+	default:
+		var names []string
+		for _, og := range ogs.Items {
+			names = append(names, og.GetName())
+		}
+		report.fail(installModeCheck, "more than one operator group in namespace %s: %+q", c.cfg.Namespace, names)
+		return nil
+	}
+
+	for _, mode := range csv.Spec.InstallModes {
+		if mode.Type == modeType && mode.Supported {
+			report.pass(installModeCheck, "install mode %s is supported", modeType)
+			return nil
+		}
+	}
+	report.fail(installModeCheck, "install mode %s is not supported by CSV %q", modeType, csv.GetName())
+	return nil
+}
+
+// installModeTypeFor returns the install mode type an OperatorGroup in operatorNamespace
+// targeting targetNamespaces configures.
+func installModeTypeFor(operatorNamespace string, targetNamespaces []string) v1alpha1.InstallModeType {
+	switch {
+	case len(targetNamespaces) == 0 || (len(targetNamespaces) == 1 && targetNamespaces[0] == ""):
+		return v1alpha1.InstallModeTypeAllNamespaces
+	case len(targetNamespaces) == 1 && targetNamespaces[0] == operatorNamespace:
+		return v1alpha1.InstallModeTypeOwnNamespace
+	case len(targetNamespaces) == 1:
+		return v1alpha1.InstallModeTypeSingleNamespace
+	default:
+		return v1alpha1.InstallModeTypeMultiNamespace
+	}
+}
+
+// installAttributes are the actions the current user must be allowed to
+// perform in the operator's namespace to install an operator with OLM.
+var installAttributes = []authorizationv1.ResourceAttributes{
+	{Verb: "create", Group: v1alpha1.GroupName, Resource: "catalogsources"},
+	{Verb: "create", Group: v1alpha1.GroupName, Resource: "subscriptions"},
+	{Verb: "update", Group: v1alpha1.GroupName, Resource: "installplans"},
+	{Verb: "create", Group: v1.GroupVersion.Group, Resource: "operatorgroups"},
+	{Verb: "create", Group: "", Resource: "pods"},
+}
+
+// checkRBAC checks that the current user can create the resources needed to install an operator.
+func (c *Checker) checkRBAC(ctx context.Context, report *Report, _ *v1alpha1.ClusterServiceVersion) error {
+	var denied []string
+	for _, attrs := range installAttributes {
+		attrs := attrs
+		attrs.Namespace = c.cfg.Namespace
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+		}
+		if err := c.cfg.Client.Create(ctx, review); err != nil {
+			return fmt.Errorf("review access to %s %s: %v", attrs.Verb, attrs.Resource, err)
+		}
+		if !review.Status.Allowed {
+			denied = append(denied, fmt.Sprintf("%s %s", attrs.Verb, attrs.Resource))
+		}
+	}
+	if len(denied) != 0 {
+		report.fail(rbacCheck, "current user is not allowed to %s in namespace %s",
+			strings.Join(denied, ", "), c.cfg.Namespace)
+		return nil
+	}
+	report.pass(rbacCheck, "current user can install operators in namespace %s", c.cfg.Namespace)
+	return nil
+}
+
+// checkStorageClass checks that the cluster has a default StorageClass. Operators do not always
+// need persistent storage, so a missing default only warns.
+func (c *Checker) checkStorageClass(ctx context.Context, report *Report, _ *v1alpha1.ClusterServiceVersion) error {
+	scs := storagev1.StorageClassList{}
+	if err := c.cfg.Client.List(ctx, &scs); err != nil {
+		if apierrors.IsForbidden(err) {
+			report.warn(storageClassCheck, "current user is not allowed to list storage classes")
+			return nil
+		}
+		return fmt.Errorf("list storage classes: %v", err)
+	}
+	for _, sc := range scs.Items {
+		for _, key := range defaultStorageClassAnnotations {
+			if sc.GetAnnotations()[key] == "true" {
+				report.pass(storageClassCheck, "default storage class is %q", sc.GetName())
+				return nil
+			}
+		}
+	}
+	report.warn(storageClassCheck, "no default storage class found")
+	return nil
+}
+
+// hasAPI returns true if gvk is served by the cluster.
+func (c *Checker) hasAPI(gvk schema.GroupVersionKind) (bool, error) {
+	// Check the group version is served first, since the error returned by
+	// ServerResourcesForGroupVersion for a missing group version varies by client.
+	if c.groupVersions == nil {
+		groups, err := c.discovery.ServerGroups()
+		if err != nil {
+			return false, err
+		}
+		c.groupVersions = sets.NewString()
+		for _, group := range groups.Groups {
+			for _, gv := range group.Versions {
+				c.groupVersions.Insert(gv.GroupVersion)
+			}
+		}
+	}
+	gv := gvk.GroupVersion().String()
+	if !c.groupVersions.Has(gv) {
+		return false, nil
+	}
+
+	resources, err := c.discovery.ServerResourcesForGroupVersion(gv)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Kind == gvk.Kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func formatGVK(gvk schema.GroupVersionKind) string {
+	if gvk.Group == "" {
+		return fmt.Sprintf("%s/%s", gvk.Version, gvk.Kind)
+	}
+	return fmt.Sprintf("%s/%s/%s", gvk.Group, gvk.Version, gvk.Kind)
+}
@@ -0,0 +1,324 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("Checker", func() {
+	var (
+		c      *Checker
+		csv    *v1alpha1.ClusterServiceVersion
+		report *Report
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		c = NewChecker(&operator.Configuration{
+			Scheme:    sch,
+			Namespace: "default",
+			Client:    fake.NewFakeClientWithScheme(sch),
+		})
+		c.discovery = &fakediscovery.FakeDiscovery{
+			Fake: &clienttesting.Fake{
+				Resources: []*metav1.APIResourceList{
+					{
+						GroupVersion: "v1",
+						APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod"}},
+					},
+					{
+						GroupVersion: v1alpha1.SchemeGroupVersion.String(),
+						APIResources: []metav1.APIResource{{Name: "clusterserviceversions", Kind: "ClusterServiceVersion"}},
+					},
+				},
+			},
+			FakedServerVersion: &version.Info{GitVersion: "v1.18.3+6c42de8"},
+		}
+		csv = &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		report = &Report{}
+		ctx = context.TODO()
+	})
+
+	Describe("checkOLM", func() {
+		It("passes when OLM APIs are served", func() {
+			Expect(c.checkOLM(ctx, report, csv)).To(Succeed())
+			Expect(report.Results).To(HaveLen(1))
+			Expect(report.Results[0].State).To(Equal(PassState))
+		})
+		It("fails when OLM APIs are not served", func() {
+			c.discovery.(*fakediscovery.FakeDiscovery).Resources = nil
+			Expect(c.checkOLM(ctx, report, csv)).To(Succeed())
+			Expect(report.HasFailures()).To(BeTrue())
+		})
+	})
+
+	Describe("checkKubeVersion", func() {
+		It("passes when minKubeVersion is unset", func() {
+			Expect(c.checkKubeVersion(ctx, report, csv)).To(Succeed())
+			Expect(report.Results[0].State).To(Equal(PassState))
+		})
+		It("passes when the server version satisfies minKubeVersion", func() {
+			csv.Spec.MinKubeVersion = "1.18.3"
+			Expect(c.checkKubeVersion(ctx, report, csv)).To(Succeed())
+			Expect(report.Results[0].State).To(Equal(PassState))
+		})
+		It("fails when the server version is less than minKubeVersion", func() {
+			csv.Spec.MinKubeVersion = "1.19.0"
+			Expect(c.checkKubeVersion(ctx, report, csv)).To(Succeed())
+			Expect(report.Results[0].State).To(Equal(FailState))
+			Expect(report.Results[0].Message).To(ContainSubstring("less than minKubeVersion 1.19.0"))
+		})
+	})
+
+	Describe("checkRequiredAPIs", func() {
+		It("fails when a native API is not served", func() {
+			csv.Spec.NativeAPIs = []metav1.GroupVersionKind{
+				{Version: "v1", Kind: "Pod"},
+				{Group: "route.openshift.io", Version: "v1", Kind: "Route"},
+			}
+			Expect(c.checkRequiredAPIs(ctx, report, csv)).To(Succeed())
+			Expect(report.Results[0].State).To(Equal(FailState))
+			Expect(report.Results[0].Message).To(ContainSubstring("route.openshift.io/v1/Route"))
+			Expect(report.Results[0].Message).NotTo(ContainSubstring("v1/Pod"))
+		})
+		It("warns when a required CRD is not served", func() {
+			csv.Spec.CustomResourceDefinitions.Required = []v1alpha1.CRDDescription{
+				{Name: "etcdclusters.etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"},
+			}
+			Expect(c.checkRequiredAPIs(ctx, report, csv)).To(Succeed())
+			Expect(report.Results[0].State).To(Equal(WarnState))
+			Expect(report.Results[0].Message).To(ContainSubstring("etcd.database.coreos.com/v1beta2/EtcdCluster"))
+		})
+	})
+
+	Describe("checkInstallMode", func() {
+		BeforeEach(func() {
+			csv.Spec.InstallModes = []v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+				{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: false},
+			}
+		})
+
+		It("fails for the default AllNamespaces mode when unsupported", func() {
+			Expect(c.checkInstallMode(ctx, report, csv)).To(Succeed())
+			Expect(report.Results[0].State).To(Equal(FailState))
+		})
+		It("passes for a supported install mode", func() {
+			c.InstallMode.InstallModeType = v1alpha1.InstallModeTypeOwnNamespace
+			Expect(c.checkInstallMode(ctx, report, csv)).To(Succeed())
+			Expect(report.Results[0].State).To(Equal(PassState))
+		})
+		It("infers the install mode from an existing operator group", func() {
+			og := &v1.OperatorGroup{}
+			og.SetName("my-og")
+			og.SetNamespace("default")
+			og.Spec.TargetNamespaces = []string{"default"}
+			Expect(c.cfg.Client.Create(ctx, og)).To(Succeed())
+			Expect(c.checkInstallMode(ctx, report, csv)).To(Succeed())
+			Expect(report.Results[0].State).To(Equal(PassState))
+			Expect(report.Results[0].Message).To(ContainSubstring(string(v1alpha1.InstallModeTypeOwnNamespace)))
+		})
+	})
+
+	Describe("checkStorageClass", func() {
+		It("warns when there is no default storage class", func() {
+			Expect(c.checkStorageClass(ctx, report, csv)).To(Succeed())
+			Expect(report.Results[0].State).To(Equal(WarnState))
+		})
+		It("passes when there is a default storage class", func() {
+			sc := &storagev1.StorageClass{}
+			sc.SetName("standard")
+			sc.SetAnnotations(map[string]string{defaultStorageClassAnnotations[0]: "true"})
+			Expect(c.cfg.Client.Create(ctx, sc)).To(Succeed())
+			Expect(c.checkStorageClass(ctx, report, csv)).To(Succeed())
+			Expect(report.Results[0].State).To(Equal(PassState))
+		})
+	})
+
+	Describe("checkResources", func() {
+		newNode := func(name, cpu, memory string) *corev1.Node {
+			node := &corev1.Node{}
+			node.SetName(name)
+			node.Status.Allocatable = corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}
+			node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+			return node
+		}
+
+		BeforeEach(func() {
+			replicas := int32(2)
+			csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{{
+				Name: "memcached-operator-controller-manager",
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{
+								Name: "manager",
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("500m"),
+										corev1.ResourceMemory: resource.MustParse("256Mi"),
+									},
+								},
+							}},
+						},
+					},
+				},
+			}}
+		})
+
+		It("passes when pods fit on the cluster's nodes", func() {
+			Expect(c.cfg.Client.Create(ctx, newNode("node-1", "1", "1Gi"))).To(Succeed())
+			Expect(c.checkResources(ctx, report, csv)).To(Succeed())
+			Expect(report.HasFailures()).To(BeFalse())
+			Expect(report.Results[0].Message).To(ContainSubstring("cpu=1, memory=512Mi"))
+		})
+		It("fails when a pod does not fit the free capacity of any node", func() {
+			Expect(c.cfg.Client.Create(ctx, newNode("node-1", "1", "1Gi"))).To(Succeed())
+			running := &corev1.Pod{}
+			running.SetName("busy")
+			running.SetNamespace("kube-system")
+			running.Spec.NodeName = "node-1"
+			running.Spec.Containers = []corev1.Container{{
+				Name: "busy",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("750m")},
+				},
+			}}
+			Expect(c.cfg.Client.Create(ctx, running)).To(Succeed())
+
+			Expect(c.checkResources(ctx, report, csv)).To(Succeed())
+			Expect(report.HasFailures()).To(BeTrue())
+			Expect(report.Results[0].Message).To(ContainSubstring(`node "node-1" has the most free capacity, cpu=250m`))
+		})
+		It("fails when a container limit exceeds a LimitRange maximum", func() {
+			Expect(c.cfg.Client.Create(ctx, newNode("node-1", "4", "4Gi"))).To(Succeed())
+			lr := &corev1.LimitRange{}
+			lr.SetName("limits")
+			lr.SetNamespace("default")
+			lr.Spec.Limits = []corev1.LimitRangeItem{{
+				Type:    corev1.LimitTypeContainer,
+				Max:     corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				Default: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			}}
+			Expect(c.cfg.Client.Create(ctx, lr)).To(Succeed())
+
+			Expect(c.checkResources(ctx, report, csv)).To(Succeed())
+			Expect(report.HasFailures()).To(BeTrue())
+			Expect(report.Results[0].Message).To(ContainSubstring(`more than the maximum 1 of LimitRange "limits"`))
+		})
+		It("fails when requests exceed a ResourceQuota's remaining capacity", func() {
+			Expect(c.cfg.Client.Create(ctx, newNode("node-1", "4", "4Gi"))).To(Succeed())
+			quota := &corev1.ResourceQuota{}
+			quota.SetName("quota")
+			quota.SetNamespace("default")
+			quota.Status.Hard = corev1.ResourceList{corev1.ResourceRequestsMemory: resource.MustParse("1Gi")}
+			quota.Status.Used = corev1.ResourceList{corev1.ResourceRequestsMemory: resource.MustParse("768Mi")}
+			Expect(c.cfg.Client.Create(ctx, quota)).To(Succeed())
+
+			Expect(c.checkResources(ctx, report, csv)).To(Succeed())
+			Expect(report.HasFailures()).To(BeTrue())
+			Expect(report.Results[0].Message).To(ContainSubstring(`ResourceQuota "quota" has 256Mi of requests.memory remaining`))
+		})
+		Context("with operators that must run on infra nodes", func() {
+			var infra *corev1.Node
+			BeforeEach(func() {
+				Expect(c.cfg.Client.Create(ctx, newNode("worker-1", "4", "4Gi"))).To(Succeed())
+				infra = newNode("infra-1", "4", "4Gi")
+				infra.SetLabels(map[string]string{"node-role.kubernetes.io/infra": ""})
+				infra.Spec.Taints = []corev1.Taint{{Key: "node-role.kubernetes.io/infra", Effect: corev1.TaintEffectNoSchedule}}
+			})
+			requireInfra := func() {
+				csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec.Affinity = &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+								MatchExpressions: []corev1.NodeSelectorRequirement{{
+									Key:      "node-role.kubernetes.io/infra",
+									Operator: corev1.NodeSelectorOpExists,
+								}},
+							}},
+						},
+					},
+				}
+			}
+
+			It("fails when no node matches the pods' required node affinity", func() {
+				requireInfra()
+				Expect(c.checkResources(ctx, report, csv)).To(Succeed())
+				Expect(report.HasFailures()).To(BeTrue())
+				Expect(report.Results[0].Message).To(ContainSubstring(
+					"no nodes are ready and match the pod's node selector, node affinity, and tolerations"))
+			})
+			It("fails when the pods do not tolerate the taints of the nodes matching their node affinity", func() {
+				requireInfra()
+				Expect(c.cfg.Client.Create(ctx, infra)).To(Succeed())
+				Expect(c.checkResources(ctx, report, csv)).To(Succeed())
+				Expect(report.HasFailures()).To(BeTrue())
+			})
+			It("passes when the pods match and tolerate a node", func() {
+				requireInfra()
+				spec := &csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec
+				spec.Tolerations = []corev1.Toleration{{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists}}
+				Expect(c.cfg.Client.Create(ctx, infra)).To(Succeed())
+				Expect(c.checkResources(ctx, report, csv)).To(Succeed())
+				Expect(report.HasFailures()).To(BeFalse())
+			})
+			It("matches node names in required node affinity fields", func() {
+				csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec.Affinity = &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+								MatchFields: []corev1.NodeSelectorRequirement{{
+									Key:      "metadata.name",
+									Operator: corev1.NodeSelectorOpNotIn,
+									Values:   []string{"worker-1"},
+								}},
+							}},
+						},
+					},
+				}
+				Expect(c.checkResources(ctx, report, csv)).To(Succeed())
+				Expect(report.HasFailures()).To(BeTrue())
+			})
+		})
+	})
+})
@@ -0,0 +1,88 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+)
+
+// State is the outcome of a single preflight check.
+type State string
+
+const (
+	// PassState means the cluster satisfies the check.
+	PassState State = "pass"
+	// WarnState means the check could not be fully satisfied,
+	// but installation may still succeed.
+	WarnState State = "warn"
+	// FailState means installation will not succeed until the check passes.
+	FailState State = "fail"
+)
+
+// Result is the result of a single preflight check.
+type Result struct {
+	Name    string
+	State   State
+	Message string
+}
+
+// Report is a set of preflight check results.
+type Report struct {
+	Results []Result
+}
+
+func (r *Report) pass(name, format string, args ...interface{}) {
+	r.add(name, PassState, format, args...)
+}
+
+func (r *Report) warn(name, format string, args ...interface{}) {
+	r.add(name, WarnState, format, args...)
+}
+
+func (r *Report) fail(name, format string, args ...interface{}) {
+	r.add(name, FailState, format, args...)
+}
+
+func (r *Report) add(name string, state State, format string, args ...interface{}) {
+	r.Results = append(r.Results, Result{
+		Name:    name,
+		State:   state,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// HasFailures returns true if at least one result in r has failed.
+func (r Report) HasFailures() bool {
+	for _, result := range r.Results {
+		if result.State == FailState {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Report) String() string {
+	out := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(out, 8, 4, 4, ' ', 0)
+	fmt.Fprintf(tw, "CHECK\tSTATE\tMESSAGE\n")
+	for _, result := range r.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Name, result.State, result.Message)
+	}
+	tw.Flush()
+
+	return out.String()
+}
@@ -0,0 +1,448 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+const resourcesCheck = "resources"
+
+// budgetResources are the resources whose requests are checked against the cluster.
+var budgetResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// quotaResources maps the resources checked against ResourceQuotas to the quota resource names
+// that constrain their requests.
+var quotaResources = map[corev1.ResourceName][]corev1.ResourceName{
+	corev1.ResourceCPU:    {corev1.ResourceRequestsCPU, corev1.ResourceCPU},
+	corev1.ResourceMemory: {corev1.ResourceRequestsMemory, corev1.ResourceMemory},
+}
+
+// podBudget is a single pod the CSV's deployments will create.
+type podBudget struct {
+	deployment string
+	requests   corev1.ResourceList
+	spec       corev1.PodSpec
+}
+
+// CheckResources runs only the resource budget check for csv, logging warnings and returning an error
+// describing every failure, so installs can fail early instead of leaving pods pending.
+func CheckResources(ctx context.Context, cfg *operator.Configuration, csv *v1alpha1.ClusterServiceVersion) error {
+	report := Report{}
+	if err := NewChecker(cfg).checkResources(ctx, &report, csv); err != nil {
+		return err
+	}
+	var failures []string
+	for _, result := range report.Results {
+		switch result.State {
+		case FailState:
+			failures = append(failures, result.Message)
+		case WarnState:
+			log.Warn(result.Message)
+		}
+	}
+	if len(failures) != 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// checkResources checks that the pods of the CSV's deployments satisfy the namespace's LimitRanges, fit in
+// its ResourceQuotas, and can be scheduled with the cluster's free capacity. Container requests left unset
+// are defaulted as the LimitRanger admission plugin would. Quotas with scopes are not checked, and capacity
+// is only warned about if the current user cannot list nodes and pods.
+func (c *Checker) checkResources(ctx context.Context, report *Report, csv *v1alpha1.ClusterServiceVersion) error {
+	limitRanges := corev1.LimitRangeList{}
+	if err := c.cfg.Client.List(ctx, &limitRanges, client.InNamespace(c.cfg.Namespace)); err != nil {
+		if !apierrors.IsForbidden(err) {
+			return fmt.Errorf("list limit ranges: %v", err)
+		}
+		report.warn(resourcesCheck, "current user is not allowed to list limit ranges in namespace %s", c.cfg.Namespace)
+	}
+
+	var problems []string
+	var pods []podBudget
+	total := corev1.ResourceList{}
+	for _, dep := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		spec := dep.Spec.Template.Spec
+		problems = append(problems, checkLimitRanges(dep.Name, spec, limitRanges.Items)...)
+		pod := podBudget{deployment: dep.Name, requests: podRequests(spec, limitRanges.Items), spec: spec}
+		replicas := 1
+		if dep.Spec.Replicas != nil {
+			replicas = int(*dep.Spec.Replicas)
+		}
+		for i := 0; i < replicas; i++ {
+			pods = append(pods, pod)
+			addResources(total, pod.requests)
+		}
+	}
+
+	quotaProblems, err := c.checkResourceQuotas(ctx, report, pods, total)
+	if err != nil {
+		return err
+	}
+	capacityProblems, err := c.checkCapacity(ctx, report, pods)
+	if err != nil {
+		return err
+	}
+	problems = append(append(problems, quotaProblems...), capacityProblems...)
+
+	for _, problem := range problems {
+		report.fail(resourcesCheck, "%s", problem)
+	}
+	if len(problems) == 0 {
+		report.pass(resourcesCheck, "%d pod(s) requesting %s fit in namespace %s and the cluster",
+			len(pods), formatResources(total), c.cfg.Namespace)
+	}
+	return nil
+}
+
+// checkLimitRanges returns a problem for each container in spec whose requests or limits are outside
+// the bounds of a Container LimitRange.
+func checkLimitRanges(deployment string, spec corev1.PodSpec, limitRanges []corev1.LimitRange) (problems []string) {
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, lr := range limitRanges {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			for _, ctr := range containers {
+				for _, name := range budgetResources {
+					request := containerRequest(ctr, name, limitRanges)
+					if min, ok := item.Min[name]; ok && request.Cmp(min) < 0 {
+						problems = append(problems, fmt.Sprintf("container %q of deployment %q requests %s %s, "+
+							"less than the minimum %s of LimitRange %q", ctr.Name, deployment, name, request.String(),
+							min.String(), lr.GetName()))
+					}
+					max, ok := item.Max[name]
+					if !ok {
+						continue
+					}
+					limit, hasLimit := ctr.Resources.Limits[name]
+					if !hasLimit {
+						limit, hasLimit = item.Default[name]
+					}
+					switch {
+					case !hasLimit:
+						problems = append(problems, fmt.Sprintf("container %q of deployment %q must set a %s limit, "+
+							"since LimitRange %q has a maximum of %s", ctr.Name, deployment, name, lr.GetName(), max.String()))
+					case limit.Cmp(max) > 0:
+						problems = append(problems, fmt.Sprintf("container %q of deployment %q has a %s limit of %s, "+
+							"more than the maximum %s of LimitRange %q", ctr.Name, deployment, name, limit.String(),
+							max.String(), lr.GetName()))
+					}
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// checkResourceQuotas returns a problem for each unscoped ResourceQuota in the namespace without
+// room for pods, whose total requests are total.
+func (c *Checker) checkResourceQuotas(ctx context.Context, report *Report, pods []podBudget,
+	total corev1.ResourceList) (problems []string, err error) {
+
+	quotas := corev1.ResourceQuotaList{}
+	if err := c.cfg.Client.List(ctx, &quotas, client.InNamespace(c.cfg.Namespace)); err != nil {
+		if apierrors.IsForbidden(err) {
+			report.warn(resourcesCheck, "current user is not allowed to list resource quotas in namespace %s",
+				c.cfg.Namespace)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list resource quotas: %v", err)
+	}
+
+	for _, quota := range quotas.Items {
+		if len(quota.Spec.Scopes) != 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		if hard, ok := quota.Status.Hard[corev1.ResourcePods]; ok {
+			used := quota.Status.Used[corev1.ResourcePods]
+			if remaining := hard.Value() - used.Value(); int64(len(pods)) > remaining {
+				problems = append(problems, fmt.Sprintf("ResourceQuota %q allows %d more pod(s), but %d are needed",
+					quota.GetName(), remaining, len(pods)))
+			}
+		}
+		for _, name := range budgetResources {
+			for _, quotaName := range quotaResources[name] {
+				hard, ok := quota.Status.Hard[quotaName]
+				if !ok {
+					continue
+				}
+				// Pods without a request for a resource constrained by a quota are rejected.
+				for _, pod := range pods {
+					if request := pod.requests[name]; request.IsZero() {
+						problems = append(problems, fmt.Sprintf("deployment %q must set %s requests on all containers, "+
+							"since ResourceQuota %q constrains %s", pod.deployment, name, quota.GetName(), quotaName))
+						break
+					}
+				}
+				remaining := hard.DeepCopy()
+				remaining.Sub(quota.Status.Used[quotaName])
+				if need := total[name]; need.Cmp(remaining) > 0 {
+					problems = append(problems, fmt.Sprintf("ResourceQuota %q has %s of %s remaining, "+
+						"but the operator's deployments request %s", quota.GetName(), remaining.String(), quotaName,
+						need.String()))
+				}
+			}
+		}
+	}
+	return problems, nil
+}
+
+// checkCapacity returns a problem for each deployment with a pod that does not fit in the free capacity
+// of any schedulable node, after pods are placed on nodes in order.
+func (c *Checker) checkCapacity(ctx context.Context, report *Report, pods []podBudget) (problems []string, err error) {
+	nodes := corev1.NodeList{}
+	if err := c.cfg.Client.List(ctx, &nodes); err != nil {
+		if apierrors.IsForbidden(err) {
+			report.warn(resourcesCheck, "current user is not allowed to list nodes, cluster capacity was not checked")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list nodes: %v", err)
+	}
+	running := corev1.PodList{}
+	if err := c.cfg.Client.List(ctx, &running); err != nil {
+		if apierrors.IsForbidden(err) {
+			report.warn(resourcesCheck, "current user is not allowed to list pods in all namespaces, "+
+				"cluster capacity was not checked")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list pods: %v", err)
+	}
+
+	free := map[string]corev1.ResourceList{}
+	for _, node := range nodes.Items {
+		free[node.GetName()] = node.Status.Allocatable.DeepCopy()
+	}
+	for _, pod := range running.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if nodeFree, ok := free[pod.Spec.NodeName]; ok {
+			subResources(nodeFree, podRequests(pod.Spec, nil))
+		}
+	}
+
+	unschedulable := map[string]bool{}
+	for _, pod := range pods {
+		placed := false
+		for _, node := range nodes.Items {
+			if schedulable(node, pod.spec) && fits(free[node.GetName()], pod.requests) {
+				subResources(free[node.GetName()], pod.requests)
+				placed = true
+				break
+			}
+		}
+		if !placed && !unschedulable[pod.deployment] {
+			unschedulable[pod.deployment] = true
+			problems = append(problems, fmt.Sprintf("a pod of deployment %q requesting %s does not fit on any "+
+				"schedulable node; %s", pod.deployment, formatResources(pod.requests), mostFree(nodes.Items, free, pod.spec)))
+		}
+	}
+	return problems, nil
+}
+
+// schedulable returns true if a pod with spec can be scheduled on node,
+// considering only the node's readiness, taints, labels, and name.
+func schedulable(node corev1.Node, spec corev1.PodSpec) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	ready := false
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			ready = cond.Status == corev1.ConditionTrue
+		}
+	}
+	if !ready {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		taint := taint
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for _, toleration := range spec.Tolerations {
+			if toleration.ToleratesTaint(&taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.GetLabels())) &&
+		matchesNodeAffinity(node, spec.Affinity)
+}
+
+// matchesNodeAffinity returns true if node matches a term of affinity's required node affinity, if any.
+// Terms with invalid requirements match no nodes, as they do when scheduling.
+func matchesNodeAffinity(node corev1.Node, affinity *corev1.Affinity) bool {
+	if affinity == nil || affinity.NodeAffinity == nil ||
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if matchesRequirements(labels.Set(node.GetLabels()), term.MatchExpressions) &&
+			matchesRequirements(labels.Set{"metadata.name": node.GetName()}, term.MatchFields) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesRequirements returns true if set matches every requirement.
+func matchesRequirements(set labels.Set, requirements []corev1.NodeSelectorRequirement) bool {
+	ops := map[corev1.NodeSelectorOperator]selection.Operator{
+		corev1.NodeSelectorOpIn:           selection.In,
+		corev1.NodeSelectorOpNotIn:        selection.NotIn,
+		corev1.NodeSelectorOpExists:       selection.Exists,
+		corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+		corev1.NodeSelectorOpGt:           selection.GreaterThan,
+		corev1.NodeSelectorOpLt:           selection.LessThan,
+	}
+	for _, req := range requirements {
+		op, ok := ops[req.Operator]
+		if !ok {
+			return false
+		}
+		r, err := labels.NewRequirement(req.Key, op, req.Values)
+		if err != nil || !r.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
+
+// mostFree describes the schedulable node with the most free CPU, to suggest how much to reduce requests by.
+func mostFree(nodes []corev1.Node, free map[string]corev1.ResourceList, spec corev1.PodSpec) string {
+	best := ""
+	for _, node := range nodes {
+		if !schedulable(node, spec) {
+			continue
+		}
+		if best == "" {
+			best = node.GetName()
+			continue
+		}
+		cpu, bestCPU := free[node.GetName()][corev1.ResourceCPU], free[best][corev1.ResourceCPU]
+		if cpu.Cmp(bestCPU) > 0 {
+			best = node.GetName()
+		}
+	}
+	if best == "" {
+		return "no nodes are ready and match the pod's node selector, node affinity, and tolerations"
+	}
+	return fmt.Sprintf("node %q has the most free capacity, %s", best, formatResources(free[best]))
+}
+
+// podRequests returns the requests the scheduler accounts for a pod with spec: the larger of the sum of its
+// containers' requests and the largest init container request, with requests defaulted by limitRanges.
+func podRequests(spec corev1.PodSpec, limitRanges []corev1.LimitRange) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, ctr := range spec.Containers {
+		for _, name := range budgetResources {
+			addResources(requests, corev1.ResourceList{name: containerRequest(ctr, name, limitRanges)})
+		}
+	}
+	for _, ctr := range spec.InitContainers {
+		for _, name := range budgetResources {
+			if request := containerRequest(ctr, name, limitRanges); request.Cmp(requests[name]) > 0 {
+				requests[name] = request
+			}
+		}
+	}
+	return requests
+}
+
+// containerRequest returns ctr's request for name. Like the API server, an unset request defaults to
+// the container's limit, then to the defaultRequest or default limit of a Container LimitRange.
+func containerRequest(ctr corev1.Container, name corev1.ResourceName, limitRanges []corev1.LimitRange) resource.Quantity {
+	if q, ok := ctr.Resources.Requests[name]; ok {
+		return q.DeepCopy()
+	}
+	if q, ok := ctr.Resources.Limits[name]; ok {
+		return q.DeepCopy()
+	}
+	for _, lr := range limitRanges {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			if q, ok := item.DefaultRequest[name]; ok {
+				return q.DeepCopy()
+			}
+			if q, ok := item.Default[name]; ok {
+				return q.DeepCopy()
+			}
+		}
+	}
+	return resource.Quantity{}
+}
+
+func fits(free, requests corev1.ResourceList) bool {
+	for _, name := range budgetResources {
+		if request, ok := requests[name]; ok && request.Cmp(free[name]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func addResources(list, add corev1.ResourceList) {
+	for name, q := range add {
+		sum := list[name]
+		sum.Add(q)
+		list[name] = sum
+	}
+}
+
+func subResources(list, sub corev1.ResourceList) {
+	for name, q := range sub {
+		diff := list[name]
+		diff.Sub(q)
+		list[name] = diff
+	}
+}
+
+func formatResources(list corev1.ResourceList) string {
+	var parts []string
+	for _, name := range budgetResources {
+		q := list[name]
+		parts = append(parts, fmt.Sprintf("%s=%s", name, q.String()))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smoke
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Report is the result of a Smoke run.
+type Report struct {
+	// CSV is the name of the installed CSV.
+	CSV   string `json:"csv,omitempty"`
+	Steps []Step `json:"steps"`
+}
+
+// Step is the result of a step of a Smoke run.
+type Step struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Passed returns true if every step passed.
+func (r Report) Passed() bool {
+	for _, s := range r.Steps {
+		if !s.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// run runs step name with f, adds its result to r, and returns true if it passed.
+func (r *Report) run(name string, f func() error) bool {
+	log.Infof("Running step %q", name)
+	start := time.Now()
+	err := f()
+	s := Step{Name: name, Passed: err == nil, Duration: time.Since(start).Round(time.Millisecond).String()}
+	if err != nil {
+		log.Errorf("Step %q failed: %v", name, err)
+		s.Error = err.Error()
+	}
+	r.Steps = append(r.Steps, s)
+	return s.Passed
+}
+
+// WriteText writes a table of r's steps to w.
+func WriteText(w io.Writer, r Report) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tRESULT\tDURATION\tERROR")
+	for _, s := range r.Steps {
+		result := "PASS"
+		if !s.Passed {
+			result = "FAIL"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name, result, s.Duration, s.Error)
+	}
+	return tw.Flush()
+}
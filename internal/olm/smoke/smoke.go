@@ -0,0 +1,215 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package smoke runs an operator's lifecycle end to end: it installs the operator, creates the example CRs
+// of its CSV, waits for them to become ready, deletes them, and uninstalls the operator.
+package smoke
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	almExamplesAnnotation = "alm-examples"
+	pollInterval          = 2 * time.Second
+)
+
+// readyConditionTypes are the condition types checked if no ready condition is configured.
+var readyConditionTypes = []string{"Ready", "Available", "Successful"}
+
+// Smoke runs an operator's lifecycle.
+type Smoke struct {
+	// Install installs the operator, returning its CSV.
+	Install func(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error)
+	// Uninstall uninstalls the operator. It is called even if the install failed, to clean up.
+	Uninstall func(ctx context.Context) error
+	// Client creates, reads, and deletes the CRs.
+	Client    client.Client
+	Namespace string
+
+	// ReadyCondition is the type of the condition every CR must have with status "True" to be ready.
+	// If empty, a CR is ready once it has a status, its status.observedGeneration, if set, is its
+	// generation, and its Ready, Available, or Successful conditions, if any, are "True".
+	ReadyCondition string
+	// CRTimeout is how long to wait for each CR to become ready, and to be deleted.
+	CRTimeout time.Duration
+	// KeepCRs skips deleting CRs before uninstalling, in which case they are deleted with their CRDs.
+	KeepCRs bool
+}
+
+// Run runs each step of the lifecycle in turn, stopping at the first failed step except for cleanup steps,
+// which are always run.
+func (s Smoke) Run(ctx context.Context) (report Report) {
+	r := &report
+	var crs []*unstructured.Unstructured
+	defer func() {
+		if !s.KeepCRs {
+			s.deleteCRs(ctx, r, crs)
+		}
+		r.run("uninstall operator", func() error {
+			return s.Uninstall(ctx)
+		})
+	}()
+
+	var csv *v1alpha1.ClusterServiceVersion
+	if !r.run("install operator", func() (err error) {
+		csv, err = s.Install(ctx)
+		return err
+	}) {
+		return report
+	}
+	r.CSV = csv.GetName()
+
+	examples, err := exampleCRs(csv)
+	if !r.run("read alm-examples", func() error { return err }) {
+		return report
+	}
+	for _, cr := range examples {
+		if cr.GetNamespace() == "" {
+			// The namespace is ignored for cluster-scoped CRs.
+			cr.SetNamespace(s.Namespace)
+		}
+		if !r.run("create "+crName(cr), func() error { return s.Client.Create(ctx, cr) }) {
+			return report
+		}
+		crs = append(crs, cr)
+	}
+	for _, cr := range crs {
+		if !r.run(crName(cr)+" ready", func() error { return s.waitForReady(ctx, cr) }) {
+			return report
+		}
+	}
+	return report
+}
+
+// deleteCRs deletes crs, and waits for each to be deleted, ex. once the operator removes its finalizers.
+func (s Smoke) deleteCRs(ctx context.Context, r *Report, crs []*unstructured.Unstructured) {
+	var deleted []*unstructured.Unstructured
+	for _, cr := range crs {
+		if r.run("delete "+crName(cr), func() error {
+			return client.IgnoreNotFound(s.Client.Delete(ctx, cr))
+		}) {
+			deleted = append(deleted, cr)
+		}
+	}
+	for _, cr := range deleted {
+		r.run(crName(cr)+" deleted", func() error {
+			return s.poll(ctx, func() (bool, error) {
+				err := s.Client.Get(ctx, client.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()},
+					cr.DeepCopy())
+				if apierrors.IsNotFound(err) {
+					return true, nil
+				}
+				return false, err
+			})
+		})
+	}
+}
+
+// waitForReady waits for cr to become ready.
+func (s Smoke) waitForReady(ctx context.Context, cr *unstructured.Unstructured) error {
+	var reason string
+	err := s.poll(ctx, func() (bool, error) {
+		current := cr.DeepCopy()
+		key := client.ObjectKey{Namespace: cr.GetNamespace(), Name: cr.GetName()}
+		if err := s.Client.Get(ctx, key, current); err != nil {
+			return false, err
+		}
+		reason = notReadyReason(current, s.ReadyCondition)
+		return reason == "", nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("not ready after %s: %s", s.CRTimeout, reason)
+	}
+	return err
+}
+
+func (s Smoke) poll(ctx context.Context, cond wait.ConditionFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, s.CRTimeout)
+	defer cancel()
+	return wait.PollImmediateUntil(pollInterval, cond, ctx.Done())
+}
+
+// notReadyReason returns why cr is not ready, or an empty string if it is ready.
+func notReadyReason(cr *unstructured.Unstructured, readyCondition string) string {
+	status, ok, _ := unstructured.NestedMap(cr.Object, "status")
+	if !ok || len(status) == 0 {
+		return "no status"
+	}
+	if observed, ok, _ := unstructured.NestedInt64(status, "observedGeneration"); ok && observed != cr.GetGeneration() {
+		return fmt.Sprintf("observed generation %d, expected %d", observed, cr.GetGeneration())
+	}
+	conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+	found := false
+	for _, cond := range conditions {
+		m, ok := cond.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _ := m["type"].(string)
+		condStatus, _ := m["status"].(string)
+		if readyCondition != "" && condType != readyCondition {
+			continue
+		}
+		if readyCondition == "" && !containsString(readyConditionTypes, condType) {
+			continue
+		}
+		found = true
+		if condStatus != string(corev1.ConditionTrue) {
+			return fmt.Sprintf("condition %s is %s", condType, condStatus)
+		}
+	}
+	if readyCondition != "" && !found {
+		return fmt.Sprintf("no %s condition", readyCondition)
+	}
+	return ""
+}
+
+// exampleCRs returns the CRs in the alm-examples annotation of csv.
+func exampleCRs(csv *v1alpha1.ClusterServiceVersion) ([]*unstructured.Unstructured, error) {
+	examples := csv.GetAnnotations()[almExamplesAnnotation]
+	if examples == "" {
+		log.Warnf("CSV %s has no %s annotation, so no CRs are tested", csv.GetName(), almExamplesAnnotation)
+		return nil, nil
+	}
+	var crs []*unstructured.Unstructured
+	if err := json.Unmarshal([]byte(examples), &crs); err != nil {
+		return nil, fmt.Errorf("error parsing %s annotation: %v", almExamplesAnnotation, err)
+	}
+	return crs, nil
+}
+
+func crName(cr *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s %s", cr.GetKind(), cr.GetName())
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
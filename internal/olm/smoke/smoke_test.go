@@ -0,0 +1,199 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smoke
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const examples = `[{"apiVersion":"cache.example.com/v1alpha1","kind":"Memcached","metadata":{"name":"memcached-sample"},"spec":{"size":3}}]`
+
+var memcachedGVK = schema.GroupVersionKind{Group: "cache.example.com", Version: "v1alpha1", Kind: "Memcached"}
+
+func newScheme() *runtime.Scheme {
+	sch := runtime.NewScheme()
+	sch.AddKnownTypeWithName(memcachedGVK, &unstructured.Unstructured{})
+	sch.AddKnownTypeWithName(memcachedGVK.GroupVersion().WithKind("MemcachedList"), &unstructured.UnstructuredList{})
+	return sch
+}
+
+func newCSV(almExamples string) *v1alpha1.ClusterServiceVersion {
+	csv := &v1alpha1.ClusterServiceVersion{}
+	csv.SetName("memcached-operator.v0.0.1")
+	if almExamples != "" {
+		csv.SetAnnotations(map[string]string{almExamplesAnnotation: almExamples})
+	}
+	return csv
+}
+
+// readyClient sets the status of created CRs to status, as an operator would.
+type readyClient struct {
+	client.Client
+	status map[string]interface{}
+}
+
+func (c readyClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok && c.status != nil {
+		u = u.DeepCopy()
+		u.Object["status"] = c.status
+		obj = u
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("Smoke", func() {
+	var (
+		s           Smoke
+		uninstalled bool
+	)
+
+	BeforeEach(func() {
+		uninstalled = false
+		s = Smoke{
+			Install: func(context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+				return newCSV(examples), nil
+			},
+			Uninstall: func(context.Context) error {
+				uninstalled = true
+				return nil
+			},
+			Namespace: "memcached",
+			CRTimeout: 50 * time.Millisecond,
+		}
+	})
+
+	stepNames := func(r Report) (names []string) {
+		for _, step := range r.Steps {
+			names = append(names, step.Name)
+		}
+		return names
+	}
+
+	It("creates, waits for, and deletes each example CR, then uninstalls", func() {
+		s.Client = readyClient{
+			Client: fake.NewFakeClientWithScheme(newScheme()),
+			status: map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			}},
+		}
+		r := s.Run(context.TODO())
+		Expect(r.Passed()).To(BeTrue(), "%+v", r)
+		Expect(r.CSV).To(Equal("memcached-operator.v0.0.1"))
+		Expect(stepNames(r)).To(Equal([]string{
+			"install operator",
+			"read alm-examples",
+			"create Memcached memcached-sample",
+			"Memcached memcached-sample ready",
+			"delete Memcached memcached-sample",
+			"Memcached memcached-sample deleted",
+			"uninstall operator",
+		}))
+		Expect(uninstalled).To(BeTrue())
+
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(memcachedGVK)
+		err := s.Client.Get(context.TODO(), client.ObjectKey{Namespace: "memcached", Name: "memcached-sample"}, u)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails if a CR does not become ready, and still cleans up", func() {
+		s.Client = readyClient{Client: fake.NewFakeClientWithScheme(newScheme())}
+		s.KeepCRs = true
+		r := s.Run(context.TODO())
+		Expect(r.Passed()).To(BeFalse())
+		Expect(stepNames(r)).To(Equal([]string{
+			"install operator",
+			"read alm-examples",
+			"create Memcached memcached-sample",
+			"Memcached memcached-sample ready",
+			"uninstall operator",
+		}))
+		Expect(r.Steps[3].Error).To(ContainSubstring("no status"))
+		Expect(uninstalled).To(BeTrue())
+	})
+
+	It("uninstalls if the install fails", func() {
+		s.Install = func(context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+			return nil, errors.New("csv failed")
+		}
+		r := s.Run(context.TODO())
+		Expect(r.Passed()).To(BeFalse())
+		Expect(stepNames(r)).To(Equal([]string{"install operator", "uninstall operator"}))
+		Expect(r.Steps[0].Error).To(Equal("csv failed"))
+		Expect(uninstalled).To(BeTrue())
+	})
+
+	It("fails if alm-examples is invalid", func() {
+		s.Install = func(context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+			return newCSV("{"), nil
+		}
+		r := s.Run(context.TODO())
+		Expect(r.Passed()).To(BeFalse())
+		Expect(r.Steps[1].Error).To(ContainSubstring("error parsing alm-examples annotation"))
+	})
+})
+
+var _ = Describe("notReadyReason", func() {
+	cr := func(status map[string]interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		u.SetGeneration(2)
+		if status != nil {
+			u.Object["status"] = status
+		}
+		return u
+	}
+	conditions := func(conds ...map[string]interface{}) map[string]interface{} {
+		var l []interface{}
+		for _, c := range conds {
+			l = append(l, c)
+		}
+		return map[string]interface{}{"conditions": l}
+	}
+
+	It("requires a status", func() {
+		Expect(notReadyReason(cr(nil), "")).To(Equal("no status"))
+	})
+	It("requires the observed generation to be current", func() {
+		Expect(notReadyReason(cr(map[string]interface{}{"observedGeneration": int64(1)}), "")).
+			To(Equal("observed generation 1, expected 2"))
+		Expect(notReadyReason(cr(map[string]interface{}{"observedGeneration": int64(2)}), "")).To(BeEmpty())
+	})
+	It("checks well-known conditions by default", func() {
+		status := conditions(
+			map[string]interface{}{"type": "Degraded", "status": "True"},
+			map[string]interface{}{"type": "Available", "status": "False"},
+		)
+		Expect(notReadyReason(cr(status), "")).To(Equal("condition Available is False"))
+	})
+	It("checks only the configured condition", func() {
+		status := conditions(
+			map[string]interface{}{"type": "Available", "status": "False"},
+			map[string]interface{}{"type": "Reconciled", "status": "True"},
+		)
+		Expect(notReadyReason(cr(status), "Reconciled")).To(BeEmpty())
+		Expect(notReadyReason(cr(status), "Synced")).To(Equal("no Synced condition"))
+	})
+})
@@ -26,6 +26,7 @@ import (
 
 	"github.com/operator-framework/operator-sdk/internal/kubebuilder/cmdutil"
 	"github.com/operator-framework/operator-sdk/internal/plugins/ansible/v1/scaffolds"
+	"github.com/operator-framework/operator-sdk/internal/plugins/external"
 	"github.com/operator-framework/operator-sdk/internal/plugins/manifests"
 )
 
@@ -120,7 +121,11 @@ func (p *createAPIPlugin) Run() error {
 // SDK phase 2 plugins.
 func (p *createAPIPlugin) runPhase2() error {
 	gvk := p.createOptions.GVK
-	return manifests.RunCreateAPI(p.config, config.GVK{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind})
+	cfgGVK := config.GVK{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}
+	if err := manifests.RunCreateAPI(p.config, cfgGVK); err != nil {
+		return err
+	}
+	return external.RunCreateAPI(p.config, cfgGVK)
 }
 
 func (p *createAPIPlugin) Validate() error {
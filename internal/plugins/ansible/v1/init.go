@@ -28,6 +28,7 @@ import (
 
 	"github.com/operator-framework/operator-sdk/internal/kubebuilder/cmdutil"
 	"github.com/operator-framework/operator-sdk/internal/plugins/ansible/v1/scaffolds"
+	"github.com/operator-framework/operator-sdk/internal/plugins/external"
 	"github.com/operator-framework/operator-sdk/internal/plugins/manifests"
 	"github.com/operator-framework/operator-sdk/internal/plugins/scorecard"
 )
@@ -38,6 +39,8 @@ type initPlugin struct {
 
 	// If true, run the `create api` plugin.
 	doCreateAPI bool
+	// externalPlugins are declared as 'name=command [args]'.
+	externalPlugins []string
 
 	// For help text.
 	commandName string
@@ -99,6 +102,7 @@ func (p *initPlugin) BindFlags(fs *pflag.FlagSet) {
 	fs.SortFlags = false
 	fs.StringVar(&p.config.Domain, "domain", "my.domain", "domain for groups")
 	fs.StringVar(&p.config.ProjectName, "project-name", "", "name of this project, the default being directory name")
+	fs.StringArrayVar(&p.externalPlugins, "external-plugin", nil, external.FlagUsage)
 	p.apiPlugin.BindFlags(fs)
 }
 
@@ -129,6 +133,9 @@ func (p *initPlugin) runPhase2() error {
 	if err := scorecard.RunInit(p.config); err != nil {
 		return err
 	}
+	if err := external.RunInit(p.config, p.externalPlugins); err != nil {
+		return err
+	}
 
 	if p.doCreateAPI {
 		if err := p.apiPlugin.runPhase2(); err != nil {
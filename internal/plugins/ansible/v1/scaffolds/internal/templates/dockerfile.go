@@ -49,8 +49,19 @@ func (f *Dockerfile) SetTemplateDefaults() error {
 
 const dockerfileTemplate = `FROM quay.io/operator-framework/ansible-operator:{{.ImageTag}}
 
-COPY requirements.yml ${HOME}/requirements.yml
-RUN ansible-galaxy collection install -r ${HOME}/requirements.yml \
+# Collections are installed from, in order of preference, the artifacts and checksums downloaded by
+# 'operator-sdk collections lock --offline', the lock file written by 'operator-sdk collections lock',
+# or requirements.yml.
+COPY requirements*.yml ${HOME}/
+RUN if [ -f ${HOME}/collections/SHA256SUMS ]; then \
+      cd ${HOME}/collections && sha256sum -c SHA256SUMS \
+      && ansible-galaxy collection install --no-deps ${HOME}/collections/*.tar.gz \
+      && rm -rf ${HOME}/collections; \
+    elif [ -f ${HOME}/requirements.lock.yml ]; then \
+      ansible-galaxy collection install --no-deps -r ${HOME}/requirements.lock.yml; \
+    else \
+      ansible-galaxy collection install -r ${HOME}/requirements.yml; \
+    fi \
  && chmod -R ug+rwx ${HOME}/.ansible
 
 COPY watches.yaml ${HOME}/watches.yaml
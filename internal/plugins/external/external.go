@@ -0,0 +1,310 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package external runs scaffolding plugins declared in a project's config, which are executables
+// outside of the SDK that add files to or modify files in the project after 'init' and 'create api'.
+//
+// An external plugin is run in the project root. It reads a Request from stdin, a JSON object containing
+// the command run and the universe of the scaffold: the project config, boilerplate, and the resource
+// of 'create api'. It writes a Response to stdout, a JSON object containing the file patches to apply
+// to the project. A plugin fails by exiting non-zero, and its stderr is included in the error.
+package external
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/pkg/plugin"
+
+	"github.com/operator-framework/operator-sdk/internal/plugins"
+)
+
+const (
+	pluginName = "external" + plugins.DefaultNameQualifier
+
+	// APIVersion is the version of the Request and Response formats.
+	APIVersion = "v1alpha1"
+
+	// FlagUsage is the usage of the repeatable flag declaring external plugins on 'init'.
+	FlagUsage = "declare an external scaffolding plugin as 'name=command [args]', run after 'init' and " +
+		"'create api'. A relative command is run from the project root. May be repeated"
+)
+
+// Commands external plugins are run after.
+const (
+	CommandInit      = "init"
+	CommandCreateAPI = "create api"
+)
+
+var (
+	pluginVersion   = plugin.Version{Number: 1, Stage: plugin.AlphaStage}
+	pluginConfigKey = plugin.Key(pluginName, pluginVersion.String())
+)
+
+// Config configures the external plugins of a project, and is saved in the project config file.
+type Config struct {
+	// Plugins are run in order, each seeing the patches applied by those before it.
+	Plugins []Plugin `json:"plugins,omitempty"`
+}
+
+// Plugin is an external scaffolding plugin.
+type Plugin struct {
+	// Name identifies the plugin in errors.
+	Name string `json:"name"`
+	// Command is the plugin executable. A relative command is resolved from the project root.
+	Command string `json:"command"`
+	// Args are passed to Command.
+	Args []string `json:"args,omitempty"`
+}
+
+// Request is written to an external plugin's stdin.
+type Request struct {
+	// APIVersion is the version of this format.
+	APIVersion string `json:"apiVersion"`
+	// Command is the command the plugin is run after, "init" or "create api".
+	Command string `json:"command"`
+	// Universe is the project config, boilerplate, and resource being scaffolded.
+	Universe *model.Universe `json:"universe"`
+}
+
+// Response is read from an external plugin's stdout.
+type Response struct {
+	// APIVersion is the version of this format.
+	APIVersion string `json:"apiVersion"`
+	// Patches are applied in order.
+	Patches []FilePatch `json:"patches,omitempty"`
+}
+
+// Actions if a file written by a FilePatch exists.
+const (
+	IfExistsError     = "error"
+	IfExistsSkip      = "skip"
+	IfExistsOverwrite = "overwrite"
+)
+
+// FilePatch is a change to a file in the project.
+type FilePatch struct {
+	// Path of the file, relative to the project root.
+	Path string `json:"path"`
+	// Contents are written to the file, or inserted into it if InsertAfter is set.
+	Contents string `json:"contents"`
+	// InsertAfter, if set, inserts Contents into the existing file after the first line containing it.
+	InsertAfter string `json:"insertAfter,omitempty"`
+	// IfExists is the action if the file exists and InsertAfter is not set, one of
+	// "error" (the default), "skip", or "overwrite".
+	IfExists string `json:"ifExists,omitempty"`
+}
+
+// ParsePlugins parses plugins declared as 'name=command [args]'.
+func ParsePlugins(declared []string) ([]Plugin, error) {
+	var ps []Plugin
+	seen := map[string]bool{}
+	for _, d := range declared {
+		split := strings.SplitN(d, "=", 2)
+		if len(split) != 2 || split[0] == "" || len(strings.Fields(split[1])) == 0 {
+			return nil, fmt.Errorf("external plugin %q must be declared as 'name=command [args]'", d)
+		}
+		if seen[split[0]] {
+			return nil, fmt.Errorf("external plugin %q is declared more than once", split[0])
+		}
+		seen[split[0]] = true
+		command := strings.Fields(split[1])
+		p := Plugin{Name: split[0], Command: command[0]}
+		if len(command) > 1 {
+			p.Args = command[1:]
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+// RunInit saves the external plugins declared on 'init' in cfg, then runs them.
+func RunInit(cfg *config.Config, declared []string) error {
+	ps, err := ParsePlugins(declared)
+	if err != nil || len(ps) == 0 {
+		return err
+	}
+	if !cfg.IsV3() {
+		return fmt.Errorf("external plugins require project version %s", config.Version3Alpha)
+	}
+	if err := cfg.EncodePluginConfig(pluginConfigKey, Config{Plugins: ps}); err != nil {
+		return fmt.Errorf("error writing plugin config for %s: %v", pluginConfigKey, err)
+	}
+	return run(ps, CommandInit, newUniverse(cfg, nil))
+}
+
+// RunCreateAPI runs the external plugins in cfg after 'create api' for the resource gvk.
+func RunCreateAPI(cfg *config.Config, gvk config.GVK) error {
+	ps, err := pluginsOf(cfg)
+	if err != nil || len(ps) == 0 {
+		return err
+	}
+	opts := resource.Options{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}
+	return run(ps, CommandCreateAPI, newUniverse(cfg, opts.NewResource(cfg, false)))
+}
+
+// pluginsOf returns the external plugins saved in cfg.
+func pluginsOf(cfg *config.Config) ([]Plugin, error) {
+	if !cfg.IsV3() {
+		return nil, nil
+	}
+	if _, hasKey := cfg.Plugins[pluginConfigKey]; !hasKey {
+		return nil, nil
+	}
+	c := Config{}
+	if err := cfg.DecodePluginConfig(pluginConfigKey, &c); err != nil {
+		return nil, fmt.Errorf("error reading plugin config for %s: %v", pluginConfigKey, err)
+	}
+	return c.Plugins, nil
+}
+
+// newUniverse returns the universe sent to external plugins. The boilerplate of Go projects is included.
+func newUniverse(cfg *config.Config, res *resource.Resource) *model.Universe {
+	opts := []model.UniverseOption{model.WithConfig(cfg)}
+	if bp, err := ioutil.ReadFile(filepath.Join("hack", "boilerplate.go.txt")); err == nil {
+		opts = append(opts, model.WithBoilerplate(string(bp)))
+	}
+	if res != nil {
+		opts = append(opts, model.WithResource(res))
+	}
+	return model.NewUniverse(opts...)
+}
+
+// run runs each plugin in ps after command, and applies the patches it returns.
+func run(ps []Plugin, command string, universe *model.Universe) error {
+	req, err := json.Marshal(Request{APIVersion: APIVersion, Command: command, Universe: universe})
+	if err != nil {
+		return fmt.Errorf("error encoding external plugin request: %v", err)
+	}
+	for _, p := range ps {
+		resp, err := p.exec(req)
+		if err != nil {
+			return fmt.Errorf("error running external plugin %q: %v", p.Name, err)
+		}
+		if err := apply(resp.Patches); err != nil {
+			return fmt.Errorf("error applying patches of external plugin %q: %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// exec runs p with req on stdin, and decodes its response.
+func (p Plugin) exec(req []byte) (*Response, error) {
+	command := p.Command
+	if !filepath.IsAbs(command) && strings.ContainsRune(command, filepath.Separator) {
+		// exec.Command only resolves relative paths from the working directory if they contain a separator.
+		command = "." + string(filepath.Separator) + filepath.Clean(command)
+	}
+	cmd := exec.Command(command, p.Args...)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewReader(req), stdout, stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%v: %s", err, msg)
+		}
+		return nil, err
+	}
+	resp := &Response{}
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %v", err)
+	}
+	if resp.APIVersion != APIVersion {
+		return nil, fmt.Errorf("unsupported response apiVersion %q, must be %q", resp.APIVersion, APIVersion)
+	}
+	return resp, nil
+}
+
+// apply applies patches to files in the working directory.
+func apply(patches []FilePatch) error {
+	for _, patch := range patches {
+		path := filepath.Clean(patch.Path)
+		if patch.Path == "" || filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("patch path %q must be relative to the project root, and in it", patch.Path)
+		}
+		if err := applyPatch(path, patch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyPatch(path string, patch FilePatch) error {
+	if patch.InsertAfter != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", path, err)
+		}
+		contents, err := insertAfter(string(b), patch.InsertAfter, patch.Contents)
+		if err != nil {
+			return fmt.Errorf("error patching %s: %v", path, err)
+		}
+		return writeFile(path, contents)
+	}
+
+	_, err := os.Stat(path)
+	switch {
+	case err == nil:
+		switch patch.IfExists {
+		case "", IfExistsError:
+			return fmt.Errorf("failed to create %s: file already exists", path)
+		case IfExistsSkip:
+			return nil
+		case IfExistsOverwrite:
+		default:
+			return fmt.Errorf("unknown ifExists action %q for %s, must be one of: [%s, %s, %s]",
+				patch.IfExists, path, IfExistsError, IfExistsSkip, IfExistsOverwrite)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating directory for %s: %v", path, err)
+	}
+	return writeFile(path, patch.Contents)
+}
+
+// insertAfter inserts contents after the first line of s containing marker.
+func insertAfter(s, marker, contents string) (string, error) {
+	i := strings.Index(s, marker)
+	if i == -1 {
+		return "", fmt.Errorf("no line contains %q", marker)
+	}
+	end := strings.IndexByte(s[i:], '\n')
+	if end == -1 {
+		return s + "\n" + contents, nil
+	}
+	end += i + 1
+	return s[:end] + contents + s[end:], nil
+}
+
+// writeFile writes contents to path, keeping its mode if it exists.
+func writeFile(path, contents string) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode()
+	}
+	if err := ioutil.WriteFile(path, []byte(contents), mode); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return nil
+}
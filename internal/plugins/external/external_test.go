@@ -0,0 +1,181 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+var _ = Describe("External plugins", func() {
+	var (
+		cfg    *config.Config
+		dir    string
+		oldDir string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "external-plugins-")
+		Expect(err).NotTo(HaveOccurred())
+		oldDir, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(dir)).To(Succeed())
+		cfg = &config.Config{Version: config.Version3Alpha, Domain: "example.com", Repo: "github.com/example/memcached-operator"}
+	})
+
+	AfterEach(func() {
+		Expect(os.Chdir(oldDir)).To(Succeed())
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	// writePlugin writes a plugin that saves its request to request.json, then responds with resp.
+	writePlugin := func(path string, resp Response) {
+		b, err := json.Marshal(resp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		script := "#!/bin/sh\ncat > request.json\ncat <<'EOF'\n" + string(b) + "\nEOF\n"
+		Expect(ioutil.WriteFile(path, []byte(script), 0755)).To(Succeed())
+	}
+
+	readRequest := func() Request {
+		b, err := ioutil.ReadFile("request.json")
+		Expect(err).NotTo(HaveOccurred())
+		req := Request{}
+		Expect(json.Unmarshal(b, &req)).To(Succeed())
+		return req
+	}
+
+	Describe("ParsePlugins", func() {
+		It("parses plugins declared as name=command [args]", func() {
+			Expect(ParsePlugins([]string{"logging=hack/logging.sh --json", "lib=/usr/bin/lib"})).To(Equal([]Plugin{
+				{Name: "logging", Command: "hack/logging.sh", Args: []string{"--json"}},
+				{Name: "lib", Command: "/usr/bin/lib"},
+			}))
+		})
+		It("fails on invalid or duplicate declarations", func() {
+			_, err := ParsePlugins([]string{"logging"})
+			Expect(err).To(MatchError(ContainSubstring("must be declared as 'name=command [args]'")))
+			_, err = ParsePlugins([]string{"logging= "})
+			Expect(err).To(HaveOccurred())
+			_, err = ParsePlugins([]string{"logging=a", "logging=b"})
+			Expect(err).To(MatchError(ContainSubstring("declared more than once")))
+		})
+	})
+
+	Describe("RunInit", func() {
+		It("saves declared plugins in the config and runs them", func() {
+			writePlugin(filepath.Join("hack", "logging.sh"), Response{APIVersion: APIVersion, Patches: []FilePatch{
+				{Path: filepath.Join("pkg", "log", "log.go"), Contents: "package log\n"},
+			}})
+			Expect(ioutil.WriteFile(filepath.Join("hack", "boilerplate.go.txt"), []byte("// Boilerplate"), 0644)).
+				To(Succeed())
+			Expect(RunInit(cfg, []string{"logging=hack/logging.sh"})).To(Succeed())
+
+			Expect(ioutil.ReadFile(filepath.Join("pkg", "log", "log.go"))).To(Equal([]byte("package log\n")))
+			req := readRequest()
+			Expect(req.APIVersion).To(Equal(APIVersion))
+			Expect(req.Command).To(Equal(CommandInit))
+			Expect(req.Universe.Config.Repo).To(Equal(cfg.Repo))
+			Expect(req.Universe.Boilerplate).To(Equal("// Boilerplate"))
+			Expect(req.Universe.Resource).To(BeNil())
+
+			Expect(pluginsOf(cfg)).To(Equal([]Plugin{{Name: "logging", Command: "hack/logging.sh"}}))
+		})
+		It("does nothing if no plugins are declared", func() {
+			Expect(RunInit(cfg, nil)).To(Succeed())
+			Expect(cfg.Plugins).To(BeEmpty())
+		})
+		It("fails for projects that cannot save plugins", func() {
+			cfg.Version = config.Version2
+			Expect(RunInit(cfg, []string{"logging=hack/logging.sh"})).To(MatchError(ContainSubstring("require project version")))
+		})
+	})
+
+	Describe("RunCreateAPI", func() {
+		gvk := config.GVK{Group: "cache", Version: "v1alpha1", Kind: "Memcached"}
+
+		BeforeEach(func() {
+			Expect(cfg.EncodePluginConfig(pluginConfigKey, Config{Plugins: []Plugin{
+				{Name: "first", Command: "hack/first.sh"},
+				{Name: "second", Command: "hack/second.sh"},
+			}})).To(Succeed())
+		})
+
+		It("runs the plugins in the config in order with the created resource", func() {
+			writePlugin(filepath.Join("hack", "first.sh"), Response{APIVersion: APIVersion, Patches: []FilePatch{
+				{Path: "main.go", Contents: "package main\n\n// +marker\n"},
+			}})
+			writePlugin(filepath.Join("hack", "second.sh"), Response{APIVersion: APIVersion, Patches: []FilePatch{
+				{Path: "main.go", InsertAfter: "+marker", Contents: "// inserted\n"},
+			}})
+			Expect(RunCreateAPI(cfg, gvk)).To(Succeed())
+
+			Expect(ioutil.ReadFile("main.go")).To(Equal([]byte("package main\n\n// +marker\n// inserted\n")))
+			req := readRequest()
+			Expect(req.Command).To(Equal(CommandCreateAPI))
+			Expect(req.Universe.Resource.Kind).To(Equal("Memcached"))
+			Expect(req.Universe.Resource.Package).To(Equal("github.com/example/memcached-operator/api/v1alpha1"))
+		})
+		It("does nothing if the config has no plugins", func() {
+			cfg.Plugins = nil
+			Expect(RunCreateAPI(cfg, gvk)).To(Succeed())
+		})
+		It("fails with the stderr of a failed plugin", func() {
+			Expect(os.Mkdir("hack", 0755)).To(Succeed())
+			script := "#!/bin/sh\necho 'no logging library' >&2\nexit 1\n"
+			Expect(ioutil.WriteFile(filepath.Join("hack", "first.sh"), []byte(script), 0755)).To(Succeed())
+			err := RunCreateAPI(cfg, gvk)
+			Expect(err).To(MatchError(ContainSubstring(`error running external plugin "first"`)))
+			Expect(err).To(MatchError(ContainSubstring("no logging library")))
+		})
+		It("fails on a response of an unsupported version", func() {
+			writePlugin(filepath.Join("hack", "first.sh"), Response{APIVersion: "v2"})
+			Expect(RunCreateAPI(cfg, gvk)).To(MatchError(ContainSubstring(`unsupported response apiVersion "v2"`)))
+		})
+	})
+
+	Describe("apply", func() {
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile("main.go", []byte("package main\n"), 0644)).To(Succeed())
+		})
+
+		It("fails to create existing files by default", func() {
+			Expect(apply([]FilePatch{{Path: "main.go", Contents: "new"}})).To(MatchError(ContainSubstring("file already exists")))
+		})
+		It("skips or overwrites existing files", func() {
+			Expect(apply([]FilePatch{{Path: "main.go", Contents: "skipped", IfExists: IfExistsSkip}})).To(Succeed())
+			Expect(ioutil.ReadFile("main.go")).To(Equal([]byte("package main\n")))
+			Expect(apply([]FilePatch{{Path: "main.go", Contents: "new", IfExists: IfExistsOverwrite}})).To(Succeed())
+			Expect(ioutil.ReadFile("main.go")).To(Equal([]byte("new")))
+		})
+		It("fails to insert after a missing marker", func() {
+			Expect(apply([]FilePatch{{Path: "main.go", InsertAfter: "+marker", Contents: "x"}})).
+				To(MatchError(ContainSubstring(`no line contains "+marker"`)))
+		})
+		It("fails to patch files outside of the project", func() {
+			for _, path := range []string{"", "../main.go", "/etc/passwd", "pkg/../../main.go"} {
+				Expect(apply([]FilePatch{{Path: path, Contents: "x"}})).
+					To(MatchError(ContainSubstring("must be relative to the project root")), path)
+			}
+		})
+	})
+})
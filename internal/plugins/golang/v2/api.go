@@ -19,6 +19,8 @@ import (
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/plugin"
 
+	"github.com/operator-framework/operator-sdk/internal/plugins/external"
+	"github.com/operator-framework/operator-sdk/internal/plugins/golang/v2/scaffolds"
 	"github.com/operator-framework/operator-sdk/internal/plugins/manifests"
 )
 
@@ -26,12 +28,25 @@ type createAPIPlugin struct {
 	plugin.CreateAPI
 
 	config *config.Config
+	// flags are the flags bound by the kubebuilder plugin, which stores their values in unexported fields.
+	flags *pflag.FlagSet
+
+	finalizer  bool
+	conditions bool
 }
 
 var _ plugin.CreateAPI = &createAPIPlugin{}
 
 func (p *createAPIPlugin) UpdateContext(ctx *plugin.Context) { p.CreateAPI.UpdateContext(ctx) }
-func (p *createAPIPlugin) BindFlags(fs *pflag.FlagSet)       { p.CreateAPI.BindFlags(fs) }
+
+func (p *createAPIPlugin) BindFlags(fs *pflag.FlagSet) {
+	p.CreateAPI.BindFlags(fs)
+	p.flags = fs
+	fs.BoolVar(&p.finalizer, "finalizer", false, "if set, the controller adds a finalizer to each object, "+
+		"and finalizes deleted objects before removing it")
+	fs.BoolVar(&p.conditions, "conditions", false, "if set, the resource's status has Available, Progressing, "+
+		"and Degraded conditions and an observed generation, which the controller updates")
+}
 
 func (p *createAPIPlugin) InjectConfig(c *config.Config) {
 	p.CreateAPI.InjectConfig(c)
@@ -69,5 +84,31 @@ func (p *createAPIPlugin) Run() error {
 
 // SDK phase 2 plugins.
 func (p *createAPIPlugin) runPhase2(gvk config.GVK) error {
-	return manifests.RunCreateAPI(p.config, gvk)
+	if err := manifests.RunCreateAPI(p.config, gvk); err != nil {
+		return err
+	}
+	componentConfig, err := HasComponentConfig(p.config)
+	if err != nil {
+		return err
+	}
+	opts := scaffolds.APIOptions{
+		// The finalizer is handled by the resource's controller, so it is only scaffolded with one.
+		Finalizer: p.finalizer && p.boolFlag("controller"),
+		// Conditions are added to the status of a new resource, and set by its controller.
+		Conditions: p.conditions && p.boolFlag("resource") && p.boolFlag("controller"),
+		// The manager's configuration sets the concurrency of each new controller.
+		ComponentConfig: componentConfig && p.boolFlag("controller"),
+	}
+	opts.Resource.Group = gvk.Group
+	opts.Resource.Version = gvk.Version
+	opts.Resource.Kind = gvk.Kind
+	if err := scaffolds.RunCreateAPI(p.config, opts); err != nil {
+		return err
+	}
+	return external.RunCreateAPI(p.config, gvk)
+}
+
+func (p *createAPIPlugin) boolFlag(name string) bool {
+	f := p.flags.Lookup(name)
+	return f != nil && f.Value.String() == "true"
 }
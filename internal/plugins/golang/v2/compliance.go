@@ -0,0 +1,110 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+// ComplianceFIPS is the --compliance mode that scaffolds a project built with FIPS 140-2 validated crypto.
+const ComplianceFIPS = "fips"
+
+var (
+	dockerFile         = "Dockerfile"
+	fipsFile           = "fips.go"
+	authProxyPatchFile = filepath.Join("config", "default", "manager_auth_proxy_patch.yaml")
+)
+
+// validateCompliance returns an error if mode is not a supported --compliance mode.
+func validateCompliance(mode string) error {
+	if mode != "" && mode != ComplianceFIPS {
+		return fmt.Errorf("unsupported compliance mode %q, must be one of: [%s]", mode, ComplianceFIPS)
+	}
+	return nil
+}
+
+// scaffoldFIPS modifies a project scaffolded by kubebuilder's Init plugin so its manager is built
+// against BoringCrypto, only negotiates FIPS-approved TLS settings in its webhook server, and its
+// metrics proxy rejects TLS versions and cipher suites that are not FIPS-approved.
+func scaffoldFIPS() error {
+	replacements := []struct {
+		path, old, new string
+	}{
+		{dockerFile, "FROM golang:1.13 as builder", dockerfileFIPSBuilder},
+		{dockerFile, "COPY main.go main.go\n", "COPY main.go main.go\nCOPY fips.go fips.go\n"},
+		{dockerFile, "RUN CGO_ENABLED=0 GOOS=linux GOARCH=amd64 GO111MODULE=on go build -a -o manager main.go",
+			dockerfileFIPSBuild},
+		{dockerFile, "FROM gcr.io/distroless/static:nonroot", dockerfileFIPSBase},
+	}
+	for _, r := range replacements {
+		if err := replaceFileContents(r.path, r.old, r.new); err != nil {
+			return err
+		}
+	}
+	if err := projutil.RewriteFileContents(authProxyPatchFile, `- "--upstream=http://127.0.0.1:8080/"`,
+		authProxyTLSFragment); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fipsFile, []byte(fipsOnlyFile), projutil.FileMode)
+}
+
+// replaceFileContents replaces the only occurrence of old in the file at path with new.
+func replaceFileContents(path, old, new string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", path, err)
+	}
+	if n := strings.Count(string(b), old); n != 1 {
+		return fmt.Errorf("expected one occurrence of %q in %s, found %d", old, path, n)
+	}
+	b = []byte(strings.Replace(string(b), old, new, 1))
+	if err := ioutil.WriteFile(path, b, projutil.FileMode); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return nil
+}
+
+const (
+	dockerfileFIPSBuilder = `# GOEXPERIMENT=boringcrypto requires go1.19+, which builds modules declaring older go versions.
+FROM golang:1.19 as builder`
+
+	// The boringcrypto experiment sets the boringcrypto build tag, so fips.go is compiled in.
+	dockerfileFIPSBuild = `# BoringCrypto is linked with cgo, so the manager is built against glibc.
+RUN CGO_ENABLED=1 GOOS=linux GOARCH=amd64 GO111MODULE=on GOEXPERIMENT=boringcrypto go build -a -o manager .`
+
+	dockerfileFIPSBase = `# The manager is dynamically linked against glibc, so use distroless/base instead of distroless/static.
+# Replace this image with a base image whose OpenSSL/glibc is FIPS-validated if your environment requires it.
+FROM gcr.io/distroless/base:nonroot`
+
+	authProxyTLSFragment = `        - "--tls-min-version=VersionTLS12"
+        - "--tls-cipher-suites=TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"
+`
+
+	fipsOnlyFile = `//go:build boringcrypto
+// +build boringcrypto
+
+package main
+
+// Importing fipsonly restricts every TLS client and server in the manager, including the webhook
+// server, to FIPS-approved TLS versions, cipher suites, and curves. This file is only compiled when
+// the manager is built with GOEXPERIMENT=boringcrypto, as in the Dockerfile.
+import _ "crypto/tls/fipsonly"
+`
+)
@@ -0,0 +1,309 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+var (
+	makefile                  = "Makefile"
+	managerConfigFile         = "managerconfig.go"
+	managerKustomizationFile  = filepath.Join("config", "manager", "kustomization.yaml")
+	controllerManagerConfig   = filepath.Join("config", "manager", "controller_manager_config.yaml")
+	dockerfileManagerBuild    = "go build -a -o manager main.go"
+	makefileManagerBuild      = "go build -o bin/manager main.go"
+	makefileManagerRun        = "go run ./main.go"
+	managerConfigFileArgument = "--config=/controller_manager_config.yaml"
+)
+
+// scaffoldComponentConfig modifies a project scaffolded by kubebuilder's Init plugin so its manager
+// reads a typed configuration file, passed with --config, whose values are overridden by flags set on
+// the command line. The file is generated into the manager-config ConfigMap by kustomize and mounted
+// into the manager's container, so it is also shipped in bundles.
+func scaffoldComponentConfig() error {
+	replacements := []struct {
+		path, old, new string
+	}{
+		{mainFile, mainFlagsStub, mainManagerConfigFlags},
+		{mainFile, "MetricsBindAddress: metricsAddr,", "MetricsBindAddress: managerConfig.Metrics.BindAddress,"},
+		{mainFile, "Port:               9443,", "Port:               managerConfig.Webhook.Port,"},
+		{mainFile, "LeaderElection:     enableLeaderElection,",
+			"LeaderElection:     managerConfig.LeaderElection.LeaderElect,"},
+		{dockerFile, "COPY main.go main.go\n", "COPY main.go main.go\nCOPY managerconfig.go managerconfig.go\n"},
+		{makefile, makefileManagerBuild, "go build -o bin/manager ."},
+		{makefile, makefileManagerRun, "go run ."},
+		{managerFile, "        - --enable-leader-election\n", "        - " + managerConfigFileArgument + "\n"},
+		{managerFile, "        name: manager\n", "        name: manager\n" + managerConfigVolumeMountFragment},
+		{managerFile, "      terminationGracePeriodSeconds: 10\n",
+			"      terminationGracePeriodSeconds: 10\n" + managerConfigVolumeFragment},
+		{authProxyPatchFile, `        - "--enable-leader-election"`, `        - "` + managerConfigFileArgument + `"`},
+	}
+	// The manager is built from every file in the main package, unless --compliance=fips already did so.
+	b, err := ioutil.ReadFile(dockerFile)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(string(b), dockerfileManagerBuild) {
+		replacements = append(replacements, struct{ path, old, new string }{
+			dockerFile, dockerfileManagerBuild, "go build -a -o manager .",
+		})
+	}
+	for _, r := range replacements {
+		if err := replaceFileContents(r.path, r.old, r.new); err != nil {
+			return err
+		}
+	}
+	if err := projutil.RewriteFileContents(mainFile, "ctrl.SetLogger(", mainLoadManagerConfig); err != nil {
+		return err
+	}
+	if err := projutil.RewriteFileContents(managerKustomizationFile, "- manager.yaml",
+		managerConfigMapGeneratorFragment); err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		managerConfigFile:       managerConfigGoFile,
+		controllerManagerConfig: controllerManagerConfigFile,
+	}
+	for path, content := range files {
+		if err := ioutil.WriteFile(path, []byte(content), projutil.FileMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	// mainFlagsStub are the flags of main.go scaffolded by kubebuilder.
+	mainFlagsStub = `	var metricsAddr string
+	var enableLeaderElection bool
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
+		"Enable leader election for controller manager. "+
+			"Enabling this will ensure there is only one active controller manager.")
+	flag.Parse()`
+
+	mainManagerConfigFlags = `	var configFile string
+	flag.StringVar(&configFile, "config", "",
+		"The manager's configuration file. Flags set on the command line override its values.")
+	managerConfig := newManagerConfig()
+	managerConfig.bindFlags(flag.CommandLine)
+	flag.Parse()`
+
+	mainLoadManagerConfig = `
+	if err := managerConfig.load(configFile, flag.CommandLine); err != nil {
+		setupLog.Error(err, "unable to load manager config")
+		os.Exit(1)
+	}
+`
+
+	managerConfigVolumeMountFragment = `        volumeMounts:
+        - name: manager-config
+          mountPath: /controller_manager_config.yaml
+          subPath: controller_manager_config.yaml
+`
+
+	managerConfigVolumeFragment = `      volumes:
+      - name: manager-config
+        configMap:
+          name: manager-config
+`
+
+	managerConfigMapGeneratorFragment = `
+# The manager reads its configuration from this ConfigMap, which is mounted by manager.yaml.
+configMapGenerator:
+- name: manager-config
+  files:
+  - controller_manager_config.yaml
+`
+
+	controllerManagerConfigFile = `# This file configures the manager, which reads it from the manager-config ConfigMap.
+# Flags passed to the manager, ex. in config/default/manager_auth_proxy_patch.yaml,
+# override the values set here.
+kind: ManagerConfig
+leaderElection:
+  # Only one replica of the manager is active at a time.
+  leaderElect: true
+metrics:
+  # The address the metrics endpoint binds to.
+  bindAddress: ":8080"
+webhook:
+  # The port the webhook server listens on.
+  port: 9443
+controller:
+  # The maximum number of objects each controller reconciles concurrently.
+  maxConcurrentReconciles: 1
+# Features enabled or disabled by name, ex. "MyFeature: true".
+featureGates: {}
+`
+
+	managerConfigGoFile = `package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const managerConfigKind = "ManagerConfig"
+
+// ManagerConfig configures the manager. It is read from the file passed with --config,
+// and flags set on the command line override its values.
+type ManagerConfig struct {
+	Kind string ` + "`" + `json:"kind,omitempty"` + "`" + `
+
+	// LeaderElection configures leader election between replicas of the manager.
+	LeaderElection LeaderElectionConfig ` + "`" + `json:"leaderElection,omitempty"` + "`" + `
+	// Metrics configures the metrics endpoint.
+	Metrics MetricsConfig ` + "`" + `json:"metrics,omitempty"` + "`" + `
+	// Webhook configures the webhook server.
+	Webhook WebhookConfig ` + "`" + `json:"webhook,omitempty"` + "`" + `
+	// Controller configures each controller run by the manager.
+	Controller ControllerConfig ` + "`" + `json:"controller,omitempty"` + "`" + `
+	// FeatureGates enables or disables features by name.
+	FeatureGates map[string]bool ` + "`" + `json:"featureGates,omitempty"` + "`" + `
+}
+
+// LeaderElectionConfig configures leader election.
+type LeaderElectionConfig struct {
+	// LeaderElect ensures only one replica of the manager is active at a time.
+	LeaderElect bool ` + "`" + `json:"leaderElect,omitempty"` + "`" + `
+}
+
+// MetricsConfig configures the metrics endpoint.
+type MetricsConfig struct {
+	// BindAddress is the address the metrics endpoint binds to.
+	BindAddress string ` + "`" + `json:"bindAddress,omitempty"` + "`" + `
+}
+
+// WebhookConfig configures the webhook server.
+type WebhookConfig struct {
+	// Port is the port the webhook server listens on.
+	Port int ` + "`" + `json:"port,omitempty"` + "`" + `
+}
+
+// ControllerConfig configures controllers.
+type ControllerConfig struct {
+	// MaxConcurrentReconciles is the maximum number of objects each controller reconciles concurrently.
+	MaxConcurrentReconciles int ` + "`" + `json:"maxConcurrentReconciles,omitempty"` + "`" + `
+}
+
+// newManagerConfig returns a ManagerConfig with default values.
+func newManagerConfig() *ManagerConfig {
+	return &ManagerConfig{
+		Metrics:      MetricsConfig{BindAddress: ":8080"},
+		Webhook:      WebhookConfig{Port: 9443},
+		Controller:   ControllerConfig{MaxConcurrentReconciles: 1},
+		FeatureGates: map[string]bool{},
+	}
+}
+
+// bindFlags adds the flags overriding c's values to fs.
+func (c *ManagerConfig) bindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Metrics.BindAddress, "metrics-addr", c.Metrics.BindAddress,
+		"The address the metric endpoint binds to.")
+	fs.BoolVar(&c.LeaderElection.LeaderElect, "enable-leader-election", c.LeaderElection.LeaderElect,
+		"Enable leader election for controller manager. "+
+			"Enabling this will ensure there is only one active controller manager.")
+	fs.IntVar(&c.Webhook.Port, "webhook-port", c.Webhook.Port, "The port the webhook server listens on.")
+	fs.IntVar(&c.Controller.MaxConcurrentReconciles, "max-concurrent-reconciles",
+		c.Controller.MaxConcurrentReconciles, "The maximum number of objects each controller reconciles concurrently.")
+	fs.Var(&featureGatesFlag{config: c}, "feature-gates",
+		"A comma-separated list of name=true|false pairs enabling or disabling features.")
+}
+
+// load reads c from the file at path, if set, then sets the flags set in fs again so they override
+// the values in the file.
+func (c *ManagerConfig) load(path string, fs *flag.FlagSet) error {
+	if path == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading manager config: %v", err)
+	}
+	// Flags are bound to c's fields, so save their values before the file overwrites them.
+	setFlags := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = f.Value.String()
+	})
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return fmt.Errorf("error parsing manager config %s: %v", path, err)
+	}
+	if c.Kind != "" && c.Kind != managerConfigKind {
+		return fmt.Errorf("manager config %s has kind %q, expected %q", path, c.Kind, managerConfigKind)
+	}
+	for name, value := range setFlags {
+		if err := fs.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// featureGatesFlag sets the feature gates of a ManagerConfig from name=true|false pairs.
+type featureGatesFlag struct {
+	config *ManagerConfig
+	// gates are the feature gates set by this flag, which override those set by the config file.
+	gates map[string]bool
+}
+
+func (f *featureGatesFlag) String() string {
+	pairs := []string{}
+	for name, enabled := range f.gates {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", name, enabled))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f *featureGatesFlag) Set(value string) error {
+	if f.gates == nil {
+		f.gates = map[string]bool{}
+	}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("feature gate %q must be of the form name=true|false", pair)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return fmt.Errorf("invalid value of feature gate %q: %v", kv[0], err)
+		}
+		f.gates[strings.TrimSpace(kv[0])] = enabled
+	}
+	if f.config.FeatureGates == nil {
+		f.config.FeatureGates = map[string]bool{}
+	}
+	for name, enabled := range f.gates {
+		f.config.FeatureGates[name] = enabled
+	}
+	return nil
+}
+`
+)
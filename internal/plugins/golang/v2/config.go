@@ -14,10 +14,45 @@
 
 package v2
 
-import "sigs.k8s.io/kubebuilder/pkg/model/config"
+import (
+	"fmt"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
 
 // Config configures this plugin, and is saved in the project config file.
-type Config struct{}
+type Config struct {
+	// NamespaceScoped is true if the project's manager only watches namespaces listed in WATCH_NAMESPACE.
+	NamespaceScoped bool `json:"namespaceScoped,omitempty"`
+	// Compliance is the compliance standard the project was scaffolded to meet, ex. "fips".
+	Compliance string `json:"compliance,omitempty"`
+	// ComponentConfig is true if the project's manager is configured by a typed configuration file.
+	ComponentConfig bool `json:"componentConfig,omitempty"`
+}
+
+// IsNamespaceScoped returns true if cfg's project was scaffolded with a namespace-scoped manager.
+func IsNamespaceScoped(cfg *config.Config) (bool, error) {
+	if !hasPluginConfig(cfg) {
+		return false, nil
+	}
+	c := Config{}
+	if err := cfg.DecodePluginConfig(pluginConfigKey, &c); err != nil {
+		return false, fmt.Errorf("error reading plugin config for %s: %v", pluginConfigKey, err)
+	}
+	return c.NamespaceScoped, nil
+}
+
+// HasComponentConfig returns true if cfg's project was scaffolded with a manager configuration file.
+func HasComponentConfig(cfg *config.Config) (bool, error) {
+	if !hasPluginConfig(cfg) {
+		return false, nil
+	}
+	c := Config{}
+	if err := cfg.DecodePluginConfig(pluginConfigKey, &c); err != nil {
+		return false, fmt.Errorf("error reading plugin config for %s: %v", pluginConfigKey, err)
+	}
+	return c.ComponentConfig, nil
+}
 
 // hasPluginConfig returns true if cfg.Plugins contains an exact match for this plugin's key.
 func hasPluginConfig(cfg *config.Config) bool {
@@ -0,0 +1,50 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+var _ = Describe("Config", func() {
+	var cfg *config.Config
+
+	BeforeEach(func() {
+		cfg = &config.Config{Version: config.Version3Alpha, Domain: "example.com"}
+	})
+
+	Describe("IsNamespaceScoped", func() {
+		It("returns true for namespace-scoped projects", func() {
+			Expect(cfg.EncodePluginConfig(pluginConfigKey, Config{NamespaceScoped: true})).To(Succeed())
+			Expect(IsNamespaceScoped(cfg)).To(BeTrue())
+		})
+		It("returns false for cluster-scoped projects", func() {
+			Expect(cfg.EncodePluginConfig(pluginConfigKey, Config{})).To(Succeed())
+			Expect(IsNamespaceScoped(cfg)).To(BeFalse())
+		})
+		It("returns false for projects without plugin config", func() {
+			Expect(IsNamespaceScoped(cfg)).To(BeFalse())
+			cfg.Version = config.Version2
+			Expect(IsNamespaceScoped(cfg)).To(BeFalse())
+		})
+		It("fails on invalid plugin config", func() {
+			Expect(cfg.EncodePluginConfig(pluginConfigKey, map[string]string{"namespaceScoped": "yes"})).To(Succeed())
+			_, err := IsNamespaceScoped(cfg)
+			Expect(err).To(MatchError(ContainSubstring("error reading plugin config")))
+		})
+	})
+})
@@ -0,0 +1,271 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/operator-sdk/internal/plugins/golang/v2/scaffolds"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+// FeaturesFile is the file declaring a project's feature gates, scaffolded by --feature-gates.
+var FeaturesFile = filepath.Join("features", "features.go")
+
+// scaffoldFeatureGates adds a features package to a project scaffolded by kubebuilder's Init plugin,
+// which declares the operator's feature gates and exports whether each is enabled as a metric. The
+// manager sets the gates from its configuration file if scaffolded with --component-config, otherwise
+// from the FEATURE_GATES env var, which is set by the feature-gates ConfigMap.
+func scaffoldFeatureGates(repo string, componentConfig bool) error {
+	setGates := mainFeatureGatesEnvFragment
+	if componentConfig {
+		setGates = mainFeatureGatesConfigFragment
+	}
+	replacements := []struct {
+		path, old, new string
+	}{
+		{mainFile, "\tmgr, err := ctrl.NewManager(", setGates + "\tmgr, err := ctrl.NewManager("},
+		{dockerFile, "COPY controllers/ controllers/\n", "COPY controllers/ controllers/\nCOPY features/ features/\n"},
+	}
+	for _, r := range replacements {
+		if err := replaceFileContents(r.path, r.old, r.new); err != nil {
+			return err
+		}
+	}
+	featuresImport := fmt.Sprintf("\t%q\n", repo+"/features")
+	if err := projutil.RewriteFileContents(mainFile, `ctrl "sigs.k8s.io/controller-runtime"`, featuresImport); err != nil {
+		return err
+	}
+	// Sort the features import into main.go's imports.
+	if err := scaffolds.FormatFile(mainFile); err != nil {
+		return err
+	}
+
+	if !componentConfig {
+		b, err := ioutil.ReadFile(managerFile)
+		if err != nil {
+			return err
+		}
+		// The manager may already set env vars, ex. WATCH_NAMESPACE if namespace-scoped.
+		env := "        env:\n"
+		if !strings.Contains(string(b), env) {
+			if err := replaceFileContents(managerFile, "        name: manager\n", "        name: manager\n"+env); err != nil {
+				return err
+			}
+		}
+		if err := replaceFileContents(managerFile, env, env+managerFeatureGatesEnvFragment); err != nil {
+			return err
+		}
+		if err := projutil.RewriteFileContents(managerKustomizationFile, "- manager.yaml",
+			featureGatesConfigMapGeneratorFragment); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(FeaturesFile), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(FeaturesFile, []byte(featuresGoFile), projutil.FileMode)
+}
+
+const (
+	mainFeatureGatesEnvFragment = `	// Feature gates are set by the FEATURE_GATES env var, ex. "MyFeature=true,OtherFeature=false".
+	if err := features.DefaultFeatureGate.SetFromString(os.Getenv("FEATURE_GATES")); err != nil {
+		setupLog.Error(err, "unable to set feature gates")
+		os.Exit(1)
+	}
+
+`
+
+	mainFeatureGatesConfigFragment = `	if err := features.DefaultFeatureGate.Set(managerConfig.FeatureGates); err != nil {
+		setupLog.Error(err, "unable to set feature gates")
+		os.Exit(1)
+	}
+
+`
+
+	managerFeatureGatesEnvFragment = `        - name: FEATURE_GATES
+          valueFrom:
+            configMapKeyRef:
+              name: feature-gates
+              key: FEATURE_GATES
+              optional: true
+`
+
+	featureGatesConfigMapGeneratorFragment = `
+# Features are enabled or disabled by the comma-separated name=true|false pairs in FEATURE_GATES.
+configMapGenerator:
+- name: feature-gates
+  literals:
+  - FEATURE_GATES=
+`
+
+	featuresGoFile = `// Package features declares the operator's feature gates. Each gate is enabled or disabled by default
+// according to its stage, and can be toggled when the manager starts.
+//
+// To add a gate, declare its name as a Feature constant, documented by its comment, and add it to
+// defaultFeatureGates. Then guard the feature's code with:
+//
+//	if features.DefaultFeatureGate.Enabled(features.MyFeature) {
+//		...
+//	}
+//
+// 'operator-sdk generate bundle' documents these gates in the ClusterServiceVersion.
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+// Every feature gate should add a constant here, with a comment describing the feature, ex.
+//
+// // MyFeature enables reconciling MyResource backups.
+// MyFeature Feature = "MyFeature"
+)
+
+// defaultFeatureGates are the operator's feature gates. Alpha features should be disabled by default.
+var defaultFeatureGates = map[Feature]FeatureSpec{
+	// MyFeature: {Default: false, PreRelease: Alpha},
+}
+
+// Feature is the name of a feature gate.
+type Feature string
+
+// Stage is the maturity of a feature.
+type Stage string
+
+const (
+	// Alpha features may be buggy or removed, and are disabled by default.
+	Alpha = Stage("ALPHA")
+	// Beta features are well tested, and may be enabled by default.
+	Beta = Stage("BETA")
+	// GA features are always enabled, and their gates will be removed.
+	GA = Stage("")
+)
+
+// FeatureSpec is the default value and stage of a feature gate.
+type FeatureSpec struct {
+	// Default is true if the feature is enabled unless disabled explicitly.
+	Default bool
+	// PreRelease is the feature's stage.
+	PreRelease Stage
+}
+
+// featureEnabled exports whether each feature gate is enabled.
+var featureEnabled = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "feature_enabled",
+	Help: "Whether a feature gate is enabled (1) or disabled (0).",
+}, []string{"name", "stage"})
+
+func init() {
+	metrics.Registry.MustRegister(featureEnabled)
+	DefaultFeatureGate.recordMetrics()
+}
+
+// DefaultFeatureGate is the operator's feature gate.
+var DefaultFeatureGate = NewFeatureGate(defaultFeatureGates)
+
+// FeatureGate records which features are enabled.
+type FeatureGate struct {
+	mu      sync.RWMutex
+	known   map[Feature]FeatureSpec
+	enabled map[Feature]bool
+}
+
+// NewFeatureGate returns a FeatureGate for the known features, each set to its default.
+func NewFeatureGate(known map[Feature]FeatureSpec) *FeatureGate {
+	return &FeatureGate{known: known, enabled: map[Feature]bool{}}
+}
+
+// Enabled returns true if feature is enabled.
+func (g *FeatureGate) Enabled(feature Feature) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if enabled, ok := g.enabled[feature]; ok {
+		return enabled
+	}
+	return g.known[feature].Default
+}
+
+// Set enables or disables features by name. It returns an error if a feature is unknown.
+func (g *FeatureGate) Set(gates map[string]bool) error {
+	for name := range gates {
+		if _, ok := g.known[Feature(name)]; !ok {
+			return fmt.Errorf("unknown feature gate %q, must be one of: [%s]", name, strings.Join(g.KnownFeatures(), ", "))
+		}
+	}
+	g.mu.Lock()
+	for name, enabled := range gates {
+		g.enabled[Feature(name)] = enabled
+	}
+	g.mu.Unlock()
+	g.recordMetrics()
+	return nil
+}
+
+// SetFromString enables or disables features from comma-separated name=true|false pairs.
+func (g *FeatureGate) SetFromString(value string) error {
+	gates := map[string]bool{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("feature gate %q must be of the form name=true|false", pair)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return fmt.Errorf("invalid value of feature gate %q: %v", kv[0], err)
+		}
+		gates[strings.TrimSpace(kv[0])] = enabled
+	}
+	return g.Set(gates)
+}
+
+// KnownFeatures returns the sorted names of the known features.
+func (g *FeatureGate) KnownFeatures() []string {
+	names := make([]string, 0, len(g.known))
+	for feature := range g.known {
+		names = append(names, string(feature))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// recordMetrics sets the feature_enabled metric of each known feature.
+func (g *FeatureGate) recordMetrics() {
+	for feature, spec := range g.known {
+		value := 0.0
+		if g.Enabled(feature) {
+			value = 1
+		}
+		featureEnabled.WithLabelValues(string(feature), string(spec.PreRelease)).Set(value)
+	}
+}
+`
+)
@@ -21,6 +21,7 @@ import (
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/plugin"
 
+	"github.com/operator-framework/operator-sdk/internal/plugins/external"
 	"github.com/operator-framework/operator-sdk/internal/plugins/manifests"
 	"github.com/operator-framework/operator-sdk/internal/plugins/scorecard"
 )
@@ -29,12 +30,32 @@ type initPlugin struct {
 	plugin.Init
 
 	config *config.Config
+
+	namespaceScoped bool
+	compliance      string
+	componentConfig bool
+	featureGates    bool
+	externalPlugins []string
 }
 
 var _ plugin.Init = &initPlugin{}
 
 func (p *initPlugin) UpdateContext(ctx *plugin.Context) { p.Init.UpdateContext(ctx) }
-func (p *initPlugin) BindFlags(fs *pflag.FlagSet)       { p.Init.BindFlags(fs) }
+
+func (p *initPlugin) BindFlags(fs *pflag.FlagSet) {
+	p.Init.BindFlags(fs)
+	fs.BoolVar(&p.namespaceScoped, "namespace-scoped", false, "scaffold a manager that only watches "+
+		"the comma-separated namespaces in the WATCH_NAMESPACE env var, and is granted namespaced RBAC")
+	fs.StringVar(&p.compliance, "compliance", "", "scaffold a project that meets a compliance standard. "+
+		"With 'fips', the manager is built with FIPS 140-2 validated crypto and its servers only accept "+
+		"FIPS-approved TLS settings. One of: [fips]")
+	fs.BoolVar(&p.componentConfig, "component-config", false, "scaffold a typed manager configuration file, "+
+		"mounted from a ConfigMap, that sets leader election, the metrics address, the webhook port, "+
+		"controller concurrency, and feature gates. Flags passed to the manager override its values")
+	fs.BoolVar(&p.featureGates, "feature-gates", false, "scaffold a features package declaring feature gates, "+
+		"which are disabled by default if alpha, toggled when the manager starts, and exported as metrics")
+	fs.StringArrayVar(&p.externalPlugins, "external-plugin", nil, external.FlagUsage)
+}
 
 func (p *initPlugin) InjectConfig(c *config.Config) {
 	p.Init.InjectConfig(c)
@@ -42,6 +63,9 @@ func (p *initPlugin) InjectConfig(c *config.Config) {
 }
 
 func (p *initPlugin) Run() error {
+	if err := validateCompliance(p.compliance); err != nil {
+		return err
+	}
 	if err := p.Init.Run(); err != nil {
 		return err
 	}
@@ -53,7 +77,11 @@ func (p *initPlugin) Run() error {
 
 	// Update plugin config section with this plugin's configuration for v3 projects.
 	if p.config.IsV3() {
-		cfg := Config{}
+		cfg := Config{
+			NamespaceScoped: p.namespaceScoped,
+			Compliance:      p.compliance,
+			ComponentConfig: p.componentConfig,
+		}
 		if err := p.config.EncodePluginConfig(pluginConfigKey, cfg); err != nil {
 			return fmt.Errorf("error writing plugin config for %s: %v", pluginConfigKey, err)
 		}
@@ -70,5 +98,26 @@ func (p *initPlugin) runPhase2() error {
 	if err := scorecard.RunInit(p.config); err != nil {
 		return err
 	}
-	return nil
+	if p.namespaceScoped {
+		if err := scaffoldNamespaceScoped(); err != nil {
+			return fmt.Errorf("error scaffolding namespace-scoped manager: %v", err)
+		}
+	}
+	if p.compliance == ComplianceFIPS {
+		if err := scaffoldFIPS(); err != nil {
+			return fmt.Errorf("error scaffolding FIPS compliance: %v", err)
+		}
+	}
+	if p.componentConfig {
+		if err := scaffoldComponentConfig(); err != nil {
+			return fmt.Errorf("error scaffolding manager config: %v", err)
+		}
+	}
+	if p.featureGates {
+		if err := scaffoldFeatureGates(p.config.Repo, p.componentConfig); err != nil {
+			return fmt.Errorf("error scaffolding feature gates: %v", err)
+		}
+	}
+	// External plugins run last, so they can modify anything scaffolded by the SDK.
+	return external.RunInit(p.config, p.externalPlugins)
 }
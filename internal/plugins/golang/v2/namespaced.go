@@ -0,0 +1,115 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+var (
+	mainFile              = "main.go"
+	managerFile           = filepath.Join("config", "manager", "manager.yaml")
+	rbacKustomizationFile = filepath.Join("config", "rbac", "kustomization.yaml")
+)
+
+// scaffoldNamespaceScoped modifies a project scaffolded by kubebuilder's Init plugin so its manager
+// only caches objects in the namespaces listed in WATCH_NAMESPACE, which defaults to the manager's
+// namespace, and is bound to a Role in that namespace instead of a ClusterRole.
+func scaffoldNamespaceScoped() error {
+	edits := []struct {
+		path, target, content string
+	}{
+		{mainFile, `"os"`, "\t\"strings\"\n"},
+		{mainFile, `ctrl "sigs.k8s.io/controller-runtime"`, "\t\"sigs.k8s.io/controller-runtime/pkg/cache\"\n"},
+		{mainFile, "flag.Parse()", mainWatchNamespaceFragment},
+		{mainFile, "Scheme:", mainManagerOptionsFragment},
+		{managerFile, "name: manager", managerWatchNamespaceFragment},
+		{rbacKustomizationFile, "- auth_proxy_client_clusterrole.yaml", rbacKustomizationPatchesFragment},
+	}
+	for _, e := range edits {
+		if err := projutil.RewriteFileContents(e.path, e.target, e.content); err != nil {
+			return err
+		}
+	}
+
+	patches := map[string]string{
+		"role_namespaced_patch.yaml":         roleNamespacedPatch,
+		"role_binding_namespaced_patch.yaml": roleBindingNamespacedPatch,
+	}
+	for name, patch := range patches {
+		path := filepath.Join(filepath.Dir(rbacKustomizationFile), name)
+		if err := ioutil.WriteFile(path, []byte(patch), projutil.FileMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	mainWatchNamespaceFragment = `
+	// The manager's cache only watches the comma-separated namespaces in WATCH_NAMESPACE,
+	// or all namespaces if WATCH_NAMESPACE is empty.
+	watchNamespace := os.Getenv("WATCH_NAMESPACE")
+	var newCache cache.NewCacheFunc
+	if strings.Contains(watchNamespace, ",") {
+		newCache = cache.MultiNamespacedCacheBuilder(strings.Split(watchNamespace, ","))
+		watchNamespace = ""
+	}
+`
+
+	mainManagerOptionsFragment = `		Namespace:          watchNamespace,
+		NewCache:           newCache,
+`
+
+	managerWatchNamespaceFragment = `        env:
+        - name: WATCH_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace
+`
+
+	rbacKustomizationPatchesFragment = `# The manager only watches namespaces in WATCH_NAMESPACE,
+# so only grant it permissions in its own namespace.
+patchesJson6902:
+- path: role_namespaced_patch.yaml
+  target:
+    group: rbac.authorization.k8s.io
+    version: v1
+    kind: ClusterRole
+    name: manager-role
+- path: role_binding_namespaced_patch.yaml
+  target:
+    group: rbac.authorization.k8s.io
+    version: v1
+    kind: ClusterRoleBinding
+    name: manager-rolebinding
+`
+
+	roleNamespacedPatch = `- op: replace
+  path: /kind
+  value: Role
+`
+
+	roleBindingNamespacedPatch = `- op: replace
+  path: /kind
+  value: RoleBinding
+- op: replace
+  path: /roleRef/kind
+  value: Role
+`
+)
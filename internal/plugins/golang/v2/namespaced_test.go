@@ -0,0 +1,249 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Files scaffolded by kubebuilder's Init plugin that scaffoldNamespaceScoped edits.
+const (
+	initMain = `package main
+
+import (
+	"flag"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	// +kubebuilder:scaffold:imports
+)
+
+var (
+	scheme   = runtime.NewScheme()
+	setupLog = ctrl.Log.WithName("setup")
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	// +kubebuilder:scaffold:scheme
+}
+
+func main() {
+	var metricsAddr string
+	var enableLeaderElection bool
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
+		"Enable leader election for controller manager. "+
+			"Enabling this will ensure there is only one active controller manager.")
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: metricsAddr,
+		Port:               9443,
+		LeaderElection:     enableLeaderElection,
+		LeaderElectionID:   "f1c5ece8.example.com",
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+
+	// +kubebuilder:scaffold:builder
+
+	setupLog.Info("starting manager")
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		setupLog.Error(err, "problem running manager")
+		os.Exit(1)
+	}
+}
+`
+
+	initManager = `apiVersion: v1
+kind: Namespace
+metadata:
+  labels:
+    control-plane: controller-manager
+  name: system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+  labels:
+    control-plane: controller-manager
+spec:
+  selector:
+    matchLabels:
+      control-plane: controller-manager
+  replicas: 1
+  template:
+    metadata:
+      labels:
+        control-plane: controller-manager
+    spec:
+      containers:
+      - command:
+        - /manager
+        args:
+        - --enable-leader-election
+        image: controller:latest
+        name: manager
+        resources:
+          limits:
+            cpu: 100m
+            memory: 30Mi
+          requests:
+            cpu: 100m
+            memory: 20Mi
+      terminationGracePeriodSeconds: 10
+`
+
+	initRBACKustomization = `resources:
+- role.yaml
+- role_binding.yaml
+- leader_election_role.yaml
+- leader_election_role_binding.yaml
+# Comment the following 4 lines if you want to disable
+# the auth proxy (https://github.com/brancz/kube-rbac-proxy)
+# which protects your /metrics endpoint.
+- auth_proxy_service.yaml
+- auth_proxy_role.yaml
+- auth_proxy_role_binding.yaml
+- auth_proxy_client_clusterrole.yaml
+`
+)
+
+var _ = Describe("scaffoldNamespaceScoped", func() {
+	var dir, oldDir string
+
+	readFile := func(path string) string {
+		b, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		return string(b)
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "namespaced-scaffold-")
+		Expect(err).NotTo(HaveOccurred())
+		oldDir, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(dir)).To(Succeed())
+		for path, content := range map[string]string{
+			mainFile:              initMain,
+			managerFile:           initManager,
+			rbacKustomizationFile: initRBACKustomization,
+		} {
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.Chdir(oldDir)).To(Succeed())
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("scaffolds a manager whose cache only watches WATCH_NAMESPACE", func() {
+		Expect(scaffoldNamespaceScoped()).To(Succeed())
+
+		main := readFile(mainFile)
+		f, err := parser.ParseFile(token.NewFileSet(), mainFile, main, parser.ImportsOnly)
+		Expect(err).NotTo(HaveOccurred())
+		var imports []string
+		for _, imp := range f.Imports {
+			imports = append(imports, imp.Path.Value)
+		}
+		Expect(imports).To(ContainElement(`"strings"`))
+		Expect(imports).To(ContainElement(`"sigs.k8s.io/controller-runtime/pkg/cache"`))
+		_, err = parser.ParseFile(token.NewFileSet(), mainFile, main, parser.AllErrors)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(main).To(ContainSubstring(`watchNamespace := os.Getenv("WATCH_NAMESPACE")`))
+		Expect(main).To(ContainSubstring(`cache.MultiNamespacedCacheBuilder(strings.Split(watchNamespace, ","))`))
+		// The namespace is read after flags are parsed, and passed to the manager.
+		Expect(strings.Index(main, "flag.Parse()")).To(BeNumerically("<", strings.Index(main, "WATCH_NAMESPACE")))
+		Expect(main).To(MatchRegexp(`Scheme:\s+scheme,\n\s+Namespace:\s+watchNamespace,\n\s+NewCache:\s+newCache,`))
+	})
+
+	It("sets WATCH_NAMESPACE to the manager's namespace", func() {
+		Expect(scaffoldNamespaceScoped()).To(Succeed())
+
+		docs := strings.Split(readFile(managerFile), "---\n")
+		Expect(docs).To(HaveLen(2))
+		dep := appsv1.Deployment{}
+		Expect(yaml.UnmarshalStrict([]byte(docs[1]), &dep)).To(Succeed())
+		Expect(dep.Spec.Template.Spec.Containers).To(HaveLen(1))
+		Expect(dep.Spec.Template.Spec.Containers[0].Env).To(Equal([]corev1.EnvVar{{
+			Name: "WATCH_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+			},
+		}}))
+	})
+
+	It("binds the manager to a Role instead of a ClusterRole", func() {
+		Expect(scaffoldNamespaceScoped()).To(Succeed())
+
+		kustomization := struct {
+			Resources []string `json:"resources"`
+			Patches   []struct {
+				Path   string            `json:"path"`
+				Target map[string]string `json:"target"`
+			} `json:"patchesJson6902"`
+		}{}
+		Expect(yaml.UnmarshalStrict([]byte(readFile(rbacKustomizationFile)), &kustomization)).To(Succeed())
+		Expect(kustomization.Resources).To(ContainElement("role.yaml"))
+		Expect(kustomization.Patches).To(HaveLen(2))
+		Expect(kustomization.Patches[0].Target).To(HaveKeyWithValue("name", "manager-role"))
+		Expect(kustomization.Patches[1].Target).To(HaveKeyWithValue("name", "manager-rolebinding"))
+
+		type op struct {
+			Op    string `json:"op"`
+			Path  string `json:"path"`
+			Value string `json:"value"`
+		}
+		for _, patch := range kustomization.Patches {
+			var ops []op
+			Expect(yaml.UnmarshalStrict([]byte(readFile(filepath.Join("config", "rbac", patch.Path))), &ops)).To(Succeed())
+			Expect(ops).To(ContainElement(op{Op: "replace", Path: "/kind", Value: strings.TrimPrefix(patch.Target["kind"], "Cluster")}))
+		}
+	})
+
+	It("fails if the project was not scaffolded by kubebuilder's Init plugin", func() {
+		Expect(ioutil.WriteFile(mainFile, []byte("package main\n"), 0644)).To(Succeed())
+		Expect(scaffoldNamespaceScoped()).NotTo(Succeed())
+	})
+})
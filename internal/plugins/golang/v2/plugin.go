@@ -64,5 +64,7 @@ func (p Plugin) GetCreateAPIPlugin() plugin.CreateAPI {
 }
 
 func (p Plugin) GetCreateWebhookPlugin() plugin.CreateWebhook {
-	return (kbgov2.Plugin{}).GetCreateWebhookPlugin()
+	return &createWebhookPlugin{
+		CreateWebhook: (kbgov2.Plugin{}).GetCreateWebhookPlugin(),
+	}
 }
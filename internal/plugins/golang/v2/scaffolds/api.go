@@ -0,0 +1,245 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaffolds
+
+import (
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/pkg/model/resource"
+
+	"github.com/operator-framework/operator-sdk/internal/kubebuilder/machinery"
+	"github.com/operator-framework/operator-sdk/internal/plugins/golang/v2/scaffolds/internal/templates/api"
+	"github.com/operator-framework/operator-sdk/internal/plugins/golang/v2/scaffolds/internal/templates/controllers"
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+// APIOptions are the options of a 'create api' run.
+type APIOptions struct {
+	Resource resource.Options
+	// Finalizer is true if the controller finalizes deleted objects.
+	Finalizer bool
+	// Conditions is true if the controller sets conventional status conditions.
+	Conditions bool
+	// ComponentConfig is true if the controller's concurrency is set by the manager's configuration file.
+	ComponentConfig bool
+}
+
+// RunCreateAPI scaffolds the SDK's additions to a resource's controller. If opts.Finalizer is set,
+// the controller adds a finalizer to each object and finalizes deleted objects before removing it.
+// If opts.Conditions is set, the resource's status has Available, Progressing, and Degraded conditions
+// and an observed generation, which the controller updates after each reconcile. Unit tests of the
+// helpers implementing these patterns are scaffolded alongside them. If opts.ComponentConfig is set,
+// main.go passes the maximum concurrent reconciles of the manager's configuration to the controller.
+func RunCreateAPI(cfg *config.Config, opts APIOptions) error {
+	res := opts.Resource.NewResource(cfg, false)
+	if opts.ComponentConfig {
+		if err := setMaxConcurrentReconciles(cfg, res); err != nil {
+			return err
+		}
+	}
+	if !opts.Finalizer && !opts.Conditions {
+		return nil
+	}
+	bp, err := ioutil.ReadFile(filepath.Join("hack", "boilerplate.go.txt")) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("unable to load boilerplate: %v", err)
+	}
+	universe := model.NewUniverse(
+		model.WithConfig(cfg),
+		model.WithBoilerplate(string(bp)),
+		model.WithResource(res),
+	)
+
+	var builders []file.Builder
+	if opts.Finalizer {
+		builders = append(builders, &controllers.Finalizer{}, &controllers.FinalizerTest{})
+	}
+	if opts.Conditions {
+		builders = append(builders, &api.Conditions{}, &controllers.Conditions{}, &controllers.ConditionsTest{})
+	}
+	if err := machinery.NewScaffold().Execute(universe, builders...); err != nil {
+		return fmt.Errorf("error scaffolding controller: %v", err)
+	}
+
+	if opts.Conditions {
+		if err := replaceInFile(resourcePath(cfg, res, "%[kind]_types.go"), statusStub, statusFields); err != nil {
+			return err
+		}
+	}
+	body := &strings.Builder{}
+	if err := reconcileTemplate.Execute(body, struct {
+		Resource              *resource.Resource
+		Finalizer, Conditions bool
+	}{res, opts.Finalizer, opts.Conditions}); err != nil {
+		return fmt.Errorf("error templating Reconcile: %v", err)
+	}
+	return replaceInFile(controllerPath(cfg, res), fmt.Sprintf(reconcileStub, strings.ToLower(res.Kind)), body.String())
+}
+
+// setMaxConcurrentReconciles adds a MaxConcurrentReconciles field to the controller of res, which
+// main.go sets from the manager's configuration.
+func setMaxConcurrentReconciles(cfg *config.Config, res *resource.Resource) error {
+	path := controllerPath(cfg, res)
+	edits := []struct {
+		path, old, new string
+	}{
+		{path, `ctrl "sigs.k8s.io/controller-runtime"`,
+			`ctrl "sigs.k8s.io/controller-runtime"` + "\n\t\"sigs.k8s.io/controller-runtime/pkg/controller\""},
+		{path, "\tScheme *runtime.Scheme\n}", reconcilerConcurrencyField},
+		{path, "\t\tComplete(r)\n}", setupConcurrencyOption},
+		// kubebuilder sets up the new controller after those of other resources in main.go.
+		{"main.go", fmt.Sprintf(mainReconcilerStub, res.Kind), fmt.Sprintf(mainReconcilerConcurrency, res.Kind)},
+	}
+	for _, e := range edits {
+		if err := replaceInFile(e.path, e.old, e.new); err != nil {
+			return err
+		}
+	}
+	for _, p := range []string{path, "main.go"} {
+		if err := FormatFile(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resourcePath returns the path of a file in the API package of res.
+func resourcePath(cfg *config.Config, res *resource.Resource, name string) string {
+	path := filepath.Join("api", "%[version]", name)
+	if cfg.MultiGroup {
+		path = filepath.Join("apis", "%[group]", "%[version]", name)
+	}
+	return res.Replacer().Replace(path)
+}
+
+// controllerPath returns the path of the controller of res scaffolded by kubebuilder.
+func controllerPath(cfg *config.Config, res *resource.Resource) string {
+	path := filepath.Join("controllers", "%[kind]_controller.go")
+	if cfg.MultiGroup {
+		path = filepath.Join("controllers", "%[group]", "%[kind]_controller.go")
+	}
+	return res.Replacer().Replace(path)
+}
+
+// replaceInFile replaces the only occurrence of old in the file at path with new.
+func replaceInFile(path, old, new string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", path, err)
+	}
+	if n := strings.Count(string(b), old); n != 1 {
+		return fmt.Errorf("expected one occurrence of %q in %s, found %d", old, path, n)
+	}
+	b = []byte(strings.Replace(string(b), old, new, 1))
+	if err := ioutil.WriteFile(path, b, projutil.FileMode); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return nil
+}
+
+// FormatFile formats the Go source file at path.
+func FormatFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", path, err)
+	}
+	if b, err = format.Source(b); err != nil {
+		return fmt.Errorf("error formatting %s: %v", path, err)
+	}
+	if err := ioutil.WriteFile(path, b, projutil.FileMode); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return nil
+}
+
+const (
+	reconcilerConcurrencyField = `	Scheme *runtime.Scheme
+	// MaxConcurrentReconciles is the maximum number of objects this controller reconciles concurrently.
+	MaxConcurrentReconciles int
+}`
+
+	setupConcurrencyOption = `		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		Complete(r)
+}`
+
+	// mainReconcilerStub is the end of the reconciler set up by kubebuilder in main.go.
+	mainReconcilerStub = `		Log:    ctrl.Log.WithName("controllers").WithName("%s"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr)`
+
+	mainReconcilerConcurrency = `		Log:    ctrl.Log.WithName("controllers").WithName("%s"),
+		Scheme: mgr.GetScheme(),
+		MaxConcurrentReconciles: managerConfig.Controller.MaxConcurrentReconciles,
+	}).SetupWithManager(mgr)`
+
+	// statusStub is the body of the status type scaffolded by kubebuilder.
+	statusStub = `	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+}`
+
+	statusFields = `	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// ObservedGeneration is the most recent generation reconciled by the controller.
+	// +optional
+	ObservedGeneration int64 ` + "`" + `json:"observedGeneration,omitempty"` + "`" + `
+	// Conditions are the latest observations of the state of this resource.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition ` + "`" + `json:"conditions,omitempty"` + "`" + `
+}`
+
+	// reconcileStub is the body of the Reconcile method scaffolded by kubebuilder.
+	reconcileStub = `	_ = context.Background()
+	_ = r.Log.WithValues("%[1]s", req.NamespacedName)
+
+	// your logic here
+`
+)
+
+var reconcileTemplate = template.Must(template.New("reconcile").Funcs(template.FuncMap{"lower": strings.ToLower}).
+	Parse(`	ctx := context.Background()
+	_ = r.Log.WithValues("{{ lower .Resource.Kind }}", req.NamespacedName)
+
+	{{ lower .Resource.Kind }} := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{}
+	if err := r.Get(ctx, req.NamespacedName, {{ lower .Resource.Kind }}); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+{{- if .Finalizer }}
+	if result, stop, err := r.handleFinalizer(ctx, {{ lower .Resource.Kind }}); stop {
+		return result, err
+	}
+{{- end }}
+
+	// your logic here
+{{- if .Conditions }}
+
+	// TODO(user): set the conditions of {{ lower .Resource.Kind }} from the outcome of your logic, ex. with
+	// r.setProgressing while changes are rolled out, and r.setDegraded if they failed.
+	r.setAvailable({{ lower .Resource.Kind }}, "Reconciled", "{{ .Resource.Kind }} is reconciled")
+	if err := r.updateStatus(ctx, {{ lower .Resource.Kind }}); err != nil {
+		return ctrl.Result{}, err
+	}
+{{- end }}
+`))
@@ -0,0 +1,222 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaffolds
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/model/resource"
+)
+
+var _ = Describe("RunCreateAPI", func() {
+	var (
+		cfg    *config.Config
+		dir    string
+		oldDir string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "api-scaffold-")
+		Expect(err).NotTo(HaveOccurred())
+		oldDir, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(dir)).To(Succeed())
+		Expect(os.Mkdir("hack", 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join("hack", "boilerplate.go.txt"), []byte("// Boilerplate"), 0644)).To(Succeed())
+		cfg = &config.Config{Version: config.Version3Alpha, Domain: "example.com", Repo: "github.com/example/memcached-operator"}
+	})
+
+	AfterEach(func() {
+		Expect(os.Chdir(oldDir)).To(Succeed())
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	opts := func(finalizer, conditions bool) APIOptions {
+		return APIOptions{
+			Resource:   resource.Options{Group: "cache", Version: "v1alpha1", Kind: "Memcached"},
+			Finalizer:  finalizer,
+			Conditions: conditions,
+		}
+	}
+
+	// writeController writes the controller scaffolded by kubebuilder's 'create api'.
+	writeController := func(path string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		controller := fmt.Sprintf(`package controllers
+
+func (r *MemcachedReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+%s
+	return ctrl.Result{}, nil
+}
+`, fmt.Sprintf(reconcileStub, "memcached"))
+		Expect(ioutil.WriteFile(path, []byte(controller), 0644)).To(Succeed())
+	}
+
+	// writeTypes writes the types scaffolded by kubebuilder's 'create api'.
+	writeTypes := func(path string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		types := fmt.Sprintf(`package v1alpha1
+
+type MemcachedSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+}
+
+type MemcachedStatus struct {
+%s
+}
+`, strings.TrimSuffix(statusStub, "\n}"))
+		Expect(ioutil.WriteFile(path, []byte(types), 0644)).To(Succeed())
+	}
+
+	parse := func(path string) string {
+		b, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = parser.ParseFile(token.NewFileSet(), path, b, 0)
+		Expect(err).NotTo(HaveOccurred(), string(b))
+		return string(b)
+	}
+
+	It("scaffolds a finalizer, its unit tests, and handles it in Reconcile", func() {
+		writeController(filepath.Join("controllers", "memcached_controller.go"))
+		Expect(RunCreateAPI(cfg, opts(true, false))).To(Succeed())
+
+		finalizer := parse(filepath.Join("controllers", "memcached_finalizer.go"))
+		Expect(finalizer).To(HavePrefix("// Boilerplate"))
+		Expect(finalizer).To(ContainSubstring(`const memcachedFinalizer = "cache.example.com/finalizer"`))
+		Expect(finalizer).To(ContainSubstring(
+			"func (r *MemcachedReconciler) finalizeMemcached(ctx context.Context, memcached *cachev1alpha1.Memcached) error"))
+		Expect(finalizer).To(ContainSubstring(`cachev1alpha1 "github.com/example/memcached-operator/api/v1alpha1"`))
+
+		test := parse(filepath.Join("controllers", "memcached_finalizer_test.go"))
+		Expect(test).To(ContainSubstring("func TestHandleMemcachedFinalizer(t *testing.T)"))
+
+		controller := parse(filepath.Join("controllers", "memcached_controller.go"))
+		Expect(controller).To(ContainSubstring("if err := r.Get(ctx, req.NamespacedName, memcached); err != nil {"))
+		Expect(controller).To(ContainSubstring("if result, stop, err := r.handleFinalizer(ctx, memcached); stop {"))
+		Expect(controller).To(ContainSubstring("// your logic here"))
+	})
+	It("scaffolds the finalizer of multi-group projects under the group's controllers", func() {
+		cfg.MultiGroup = true
+		writeController(filepath.Join("controllers", "cache", "memcached_controller.go"))
+		Expect(RunCreateAPI(cfg, opts(true, false))).To(Succeed())
+
+		finalizer := parse(filepath.Join("controllers", "cache", "memcached_finalizer.go"))
+		Expect(finalizer).To(ContainSubstring(`cachev1alpha1 "github.com/example/memcached-operator/apis/cache/v1alpha1"`))
+		Expect(filepath.Join("controllers", "cache", "memcached_finalizer_test.go")).To(BeAnExistingFile())
+		controller := parse(filepath.Join("controllers", "cache", "memcached_controller.go"))
+		Expect(controller).To(ContainSubstring("r.handleFinalizer(ctx, memcached)"))
+	})
+	It("scaffolds status conditions, their helpers and unit tests, and sets them in Reconcile", func() {
+		writeController(filepath.Join("controllers", "memcached_controller.go"))
+		writeTypes(filepath.Join("api", "v1alpha1", "memcached_types.go"))
+		Expect(RunCreateAPI(cfg, opts(false, true))).To(Succeed())
+
+		conditions := parse(filepath.Join("api", "v1alpha1", "conditions.go"))
+		Expect(conditions).To(ContainSubstring(`ConditionAvailable = "Available"`))
+		Expect(conditions).To(ContainSubstring("func SetStatusCondition(conditions *[]Condition, newCondition Condition)"))
+		types := parse(filepath.Join("api", "v1alpha1", "memcached_types.go"))
+		Expect(types).To(ContainSubstring("ObservedGeneration int64 `json:\"observedGeneration,omitempty\"`"))
+		Expect(types).To(ContainSubstring("Conditions []Condition `json:\"conditions,omitempty\"`"))
+
+		helpers := parse(filepath.Join("controllers", "memcached_conditions.go"))
+		Expect(helpers).To(ContainSubstring("func (r *MemcachedReconciler) updateStatus(ctx context.Context, memcached *cachev1alpha1.Memcached) error"))
+		test := parse(filepath.Join("controllers", "memcached_conditions_test.go"))
+		Expect(test).To(ContainSubstring("func TestSetMemcachedConditions(t *testing.T)"))
+		Expect(filepath.Join("controllers", "memcached_finalizer.go")).NotTo(BeAnExistingFile())
+
+		controller := parse(filepath.Join("controllers", "memcached_controller.go"))
+		Expect(controller).NotTo(ContainSubstring("handleFinalizer"))
+		Expect(controller).To(ContainSubstring(`r.setAvailable(memcached, "Reconciled", "Memcached is reconciled")`))
+		Expect(controller).To(ContainSubstring("if err := r.updateStatus(ctx, memcached); err != nil {"))
+	})
+	It("scaffolds conditions shared by the resources in a package once", func() {
+		writeController(filepath.Join("controllers", "memcached_controller.go"))
+		writeTypes(filepath.Join("api", "v1alpha1", "memcached_types.go"))
+		existing := []byte("package v1alpha1\n")
+		Expect(ioutil.WriteFile(filepath.Join("api", "v1alpha1", "conditions.go"), existing, 0644)).To(Succeed())
+		Expect(RunCreateAPI(cfg, opts(true, true))).To(Succeed())
+		Expect(ioutil.ReadFile(filepath.Join("api", "v1alpha1", "conditions.go"))).To(Equal(existing))
+
+		controller := parse(filepath.Join("controllers", "memcached_controller.go"))
+		Expect(controller).To(ContainSubstring("r.handleFinalizer(ctx, memcached)"))
+		Expect(controller).To(ContainSubstring("r.updateStatus(ctx, memcached)"))
+	})
+	It("fails if the controller's Reconcile was changed", func() {
+		Expect(os.Mkdir("controllers", 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join("controllers", "memcached_controller.go"), []byte("package controllers\n"), 0644)).
+			To(Succeed())
+		Expect(RunCreateAPI(cfg, opts(true, false))).NotTo(Succeed())
+	})
+	It("passes the concurrency of the manager's config to the controller", func() {
+		Expect(os.Mkdir("controllers", 0755)).To(Succeed())
+		controller := `package controllers
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type MemcachedReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func (r *MemcachedReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cachev1alpha1.Memcached{}).
+		Complete(r)
+}
+`
+		Expect(ioutil.WriteFile(filepath.Join("controllers", "memcached_controller.go"), []byte(controller), 0644)).
+			To(Succeed())
+		main := fmt.Sprintf(`package main
+
+func main() {
+	if err = (&controllers.MemcachedReconciler{
+		Client: mgr.GetClient(),
+%s; err != nil {
+		os.Exit(1)
+	}
+}
+`, fmt.Sprintf(mainReconcilerStub, "Memcached"))
+		Expect(ioutil.WriteFile("main.go", []byte(main), 0644)).To(Succeed())
+		o := opts(false, false)
+		o.ComponentConfig = true
+		Expect(RunCreateAPI(cfg, o)).To(Succeed())
+
+		Expect(parse(filepath.Join("controllers", "memcached_controller.go"))).To(And(
+			ContainSubstring(`"sigs.k8s.io/controller-runtime/pkg/controller"`),
+			ContainSubstring("\tMaxConcurrentReconciles int\n}"),
+			ContainSubstring("WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles})."),
+		))
+		Expect(parse("main.go")).To(ContainSubstring(
+			"\t\tMaxConcurrentReconciles: managerConfig.Controller.MaxConcurrentReconciles,\n\t}).SetupWithManager(mgr)"))
+	})
+	It("scaffolds nothing without a finalizer", func() {
+		Expect(RunCreateAPI(cfg, opts(false, false))).To(Succeed())
+		Expect("controllers").NotTo(BeAnExistingFile())
+	})
+})
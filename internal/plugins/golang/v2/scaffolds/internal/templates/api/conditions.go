@@ -0,0 +1,168 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/file"
+)
+
+var _ file.Template = &Conditions{}
+
+// Conditions scaffolds the conventional condition types of the resources in an API package, a Condition
+// type for their status, and helpers setting and finding conditions.
+type Conditions struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Conditions) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("apis", "%[group]", "%[version]", "conditions.go")
+		} else {
+			f.Path = filepath.Join("api", "%[version]", "conditions.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = conditionsTemplate
+
+	// Conditions are shared by the resources in the package.
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const conditionsTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Conventional condition types, which every resource in this package sets.
+const (
+	// ConditionAvailable is true when the resource is fully reconciled and what it manages is available.
+	ConditionAvailable = "Available"
+	// ConditionProgressing is true while changes to the resource are being rolled out.
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded is true when the resource failed to reach or stay in its desired state.
+	ConditionDegraded = "Degraded"
+)
+
+// TODO(user): once this project depends on k8s.io/apimachinery v0.19+, replace Condition with
+// metav1.Condition and the functions below with their counterparts in k8s.io/apimachinery/pkg/api/meta,
+// which they mirror.
+
+// Condition is an observation of the state of a resource.
+type Condition struct {
+	// Type of the condition, in CamelCase.
+	// +kubebuilder:validation:Pattern=` + "`" + `^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])$` + "`" + `
+	// +kubebuilder:validation:MaxLength=316
+	Type string ` + "`" + `json:"type"` + "`" + `
+	// Status of the condition, one of True, False, or Unknown.
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status metav1.ConditionStatus ` + "`" + `json:"status"` + "`" + `
+	// ObservedGeneration is the generation of the resource the condition was set from.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservedGeneration int64 ` + "`" + `json:"observedGeneration,omitempty"` + "`" + `
+	// LastTransitionTime is the last time the condition changed from one status to another.
+	LastTransitionTime metav1.Time ` + "`" + `json:"lastTransitionTime"` + "`" + `
+	// Reason is a programmatic, CamelCase identifier of the reason for the last transition.
+	// +kubebuilder:validation:Pattern=` + "`" + `^[A-Za-z]([A-Za-z0-9_,:]*[A-Za-z0-9_])?$` + "`" + `
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	Reason string ` + "`" + `json:"reason"` + "`" + `
+	// Message is a human readable message with details about the transition.
+	// +kubebuilder:validation:MaxLength=32768
+	Message string ` + "`" + `json:"message"` + "`" + `
+}
+
+// SetStatusCondition sets newCondition in conditions. LastTransitionTime is set to now if the condition
+// is new or its status changed, unless newCondition sets it.
+func SetStatusCondition(conditions *[]Condition, newCondition Condition) {
+	if conditions == nil {
+		return
+	}
+	existingCondition := FindStatusCondition(*conditions, newCondition.Type)
+	if existingCondition == nil {
+		if newCondition.LastTransitionTime.IsZero() {
+			newCondition.LastTransitionTime = metav1.Now()
+		}
+		*conditions = append(*conditions, newCondition)
+		return
+	}
+
+	if existingCondition.Status != newCondition.Status {
+		existingCondition.Status = newCondition.Status
+		if !newCondition.LastTransitionTime.IsZero() {
+			existingCondition.LastTransitionTime = newCondition.LastTransitionTime
+		} else {
+			existingCondition.LastTransitionTime = metav1.Now()
+		}
+	}
+	existingCondition.Reason = newCondition.Reason
+	existingCondition.Message = newCondition.Message
+	existingCondition.ObservedGeneration = newCondition.ObservedGeneration
+}
+
+// RemoveStatusCondition removes the condition of type conditionType from conditions.
+func RemoveStatusCondition(conditions *[]Condition, conditionType string) {
+	if conditions == nil {
+		return
+	}
+	newConditions := make([]Condition, 0, len(*conditions))
+	for _, condition := range *conditions {
+		if condition.Type != conditionType {
+			newConditions = append(newConditions, condition)
+		}
+	}
+	*conditions = newConditions
+}
+
+// FindStatusCondition returns the condition of type conditionType in conditions, or nil if there is none.
+func FindStatusCondition(conditions []Condition, conditionType string) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsStatusConditionTrue returns true if the condition of type conditionType in conditions is True.
+func IsStatusConditionTrue(conditions []Condition, conditionType string) bool {
+	return IsStatusConditionPresentAndEqual(conditions, conditionType, metav1.ConditionTrue)
+}
+
+// IsStatusConditionFalse returns true if the condition of type conditionType in conditions is False.
+func IsStatusConditionFalse(conditions []Condition, conditionType string) bool {
+	return IsStatusConditionPresentAndEqual(conditions, conditionType, metav1.ConditionFalse)
+}
+
+// IsStatusConditionPresentAndEqual returns true if the condition of type conditionType in conditions
+// has the given status.
+func IsStatusConditionPresentAndEqual(conditions []Condition, conditionType string, status metav1.ConditionStatus) bool {
+	condition := FindStatusCondition(conditions, conditionType)
+	return condition != nil && condition.Status == status
+}
+`
@@ -0,0 +1,107 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/file"
+)
+
+var _ file.Template = &ConversionTest{}
+
+// ConversionTest scaffolds a fuzz test converting random objects of a resource to its hub version and back.
+type ConversionTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ConversionTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("apis", "%[group]", "%[version]", "%[kind]_conversion_test.go")
+		} else {
+			f.Path = filepath.Join("api", "%[version]", "%[kind]_conversion_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = conversionTestTemplate
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+const conversionTestTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"math/rand"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// {{ lower .Resource.Kind }}FuzzIterations is the number of random {{ .Resource.Kind }} objects converted by the round-trip test.
+const {{ lower .Resource.Kind }}FuzzIterations = 100
+
+// Test{{ .Resource.Kind }}ConversionRoundTrip converts random {{ .Resource.Kind }} objects to the hub version
+// and back, and checks that no field is lost.
+func Test{{ .Resource.Kind }}ConversionRoundTrip(t *testing.T) {
+	// TODO(user): set hub to an object of the hub version of {{ .Resource.Kind }}, ex. &v1.{{ .Resource.Kind }}{}.
+	var hub conversion.Hub
+	if hub == nil {
+		t.Skip("no hub version of {{ .Resource.Kind }} is set")
+	}
+	if _, ok := interface{}(&{{ .Resource.Kind }}{}).(conversion.Convertible); !ok {
+		t.Skip("{{ .Resource.Kind }} does not implement conversion.Convertible")
+	}
+
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	seed := rand.Int63()
+	f := fuzzer.FuzzerFor(metafuzzer.Funcs, rand.NewSource(seed), serializer.NewCodecFactory(scheme))
+	for i := 0; i < {{ lower .Resource.Kind }}FuzzIterations; i++ {
+		original := &{{ .Resource.Kind }}{}
+		f.Fuzz(original)
+
+		converted := hub.DeepCopyObject().(conversion.Hub)
+		if err := interface{}(original.DeepCopy()).(conversion.Convertible).ConvertTo(converted); err != nil {
+			t.Fatalf("ConvertTo() error = %v (seed %d)", err, seed)
+		}
+		roundTripped := &{{ .Resource.Kind }}{}
+		if err := interface{}(roundTripped).(conversion.Convertible).ConvertFrom(converted); err != nil {
+			t.Fatalf("ConvertFrom() error = %v (seed %d)", err, seed)
+		}
+		if !equality.Semantic.DeepEqual(original, roundTripped) {
+			t.Fatalf("{{ .Resource.Kind }} changed in conversion round trip (seed %d):\n%s",
+				seed, diff.ObjectReflectDiff(original, roundTripped))
+		}
+	}
+}
+`
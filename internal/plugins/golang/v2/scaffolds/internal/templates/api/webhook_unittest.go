@@ -0,0 +1,186 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/file"
+)
+
+var _ file.Template = &WebhookTest{}
+
+// WebhookTest scaffolds table-driven unit tests of a resource's defaulting and validating webhooks,
+// and an integration test admitting the resource through the webhook server started by WebhookSuiteTest.
+type WebhookTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+
+	// Defaulting is true if the resource has a defaulting webhook.
+	Defaulting bool
+	// Validating is true if the resource has a validating webhook.
+	Validating bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *WebhookTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("apis", "%[group]", "%[version]", "%[kind]_webhook_test.go")
+		} else {
+			f.Path = filepath.Join("api", "%[version]", "%[kind]_webhook_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = webhookTestTemplate
+	if f.Defaulting {
+		f.TemplateBody += defaultingTestTemplate
+	}
+	if f.Validating {
+		f.TemplateBody += validatingTestTemplate
+	}
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+const webhookTestTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	{{- if .Defaulting }}
+	"k8s.io/apimachinery/pkg/api/equality"
+	{{- end }}
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	{{- if .Validating }}
+	"k8s.io/apimachinery/pkg/runtime"
+	{{- end }}
+)
+
+// The integration test below admits a {{ .Resource.Kind }} through the webhook server started in
+// webhook_suite_test.go, so run 'make manifests' before running it to generate the webhook configuration.
+var _ = Describe("{{ .Resource.Kind }} webhooks", func() {
+	It("admits a valid {{ .Resource.Kind }}", func() {
+		obj := &{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: "{{ lower .Resource.Kind }}-", Namespace: "default"},
+		}
+		// TODO(user): set the fields of a valid {{ .Resource.Kind }}.
+		Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, obj)).To(Succeed())
+	})
+	// TODO(user): add tests of objects your webhooks reject or default.
+})
+`
+
+const defaultingTestTemplate = `
+func Test{{ .Resource.Kind }}Default(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  *{{ .Resource.Kind }}
+		want *{{ .Resource.Kind }}
+	}{
+		{
+			name: "empty {{ .Resource.Kind }}",
+			obj:  &{{ .Resource.Kind }}{},
+			want: &{{ .Resource.Kind }}{},
+		},
+		// TODO(user): add cases for your defaulting logic.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.obj.Default()
+			if !equality.Semantic.DeepEqual(tt.obj, tt.want) {
+				t.Errorf("Default() = %+v, want %+v", tt.obj, tt.want)
+			}
+		})
+	}
+}
+`
+
+const validatingTestTemplate = `
+func Test{{ .Resource.Kind }}ValidateCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     *{{ .Resource.Kind }}
+		wantErr bool
+	}{
+		{
+			name: "empty {{ .Resource.Kind }}",
+			obj:  &{{ .Resource.Kind }}{},
+		},
+		// TODO(user): add cases for your validation logic upon object creation.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.obj.ValidateCreate(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test{{ .Resource.Kind }}ValidateUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     runtime.Object
+		obj     *{{ .Resource.Kind }}
+		wantErr bool
+	}{
+		{
+			name: "unchanged {{ .Resource.Kind }}",
+			old:  &{{ .Resource.Kind }}{},
+			obj:  &{{ .Resource.Kind }}{},
+		},
+		// TODO(user): add cases for your validation logic upon object update.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.obj.ValidateUpdate(tt.old); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test{{ .Resource.Kind }}ValidateDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     *{{ .Resource.Kind }}
+		wantErr bool
+	}{
+		{
+			name: "empty {{ .Resource.Kind }}",
+			obj:  &{{ .Resource.Kind }}{},
+		},
+		// TODO(user): add cases for your validation logic upon object deletion.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.obj.ValidateDelete(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDelete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+`
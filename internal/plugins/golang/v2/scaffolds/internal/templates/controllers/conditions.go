@@ -0,0 +1,114 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/file"
+)
+
+var _ file.Template = &Conditions{}
+
+// Conditions scaffolds reconciler helpers setting a resource's conventional status conditions,
+// and updating its status with the generation it was reconciled at.
+type Conditions struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Conditions) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("controllers", "%[group]", "%[kind]_conditions.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_conditions.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = conditionsTemplate
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+const conditionsTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+// setAvailable marks {{ lower .Resource.Kind }} as available, and neither progressing nor degraded.
+func (r *{{ .Resource.Kind }}Reconciler) setAvailable({{ lower .Resource.Kind }} *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}, reason, message string) {
+	r.setCondition({{ lower .Resource.Kind }}, {{ .Resource.ImportAlias }}.ConditionAvailable, metav1.ConditionTrue, reason, message)
+	r.setCondition({{ lower .Resource.Kind }}, {{ .Resource.ImportAlias }}.ConditionProgressing, metav1.ConditionFalse, reason, message)
+	r.setCondition({{ lower .Resource.Kind }}, {{ .Resource.ImportAlias }}.ConditionDegraded, metav1.ConditionFalse, reason, message)
+}
+
+// setProgressing marks {{ lower .Resource.Kind }} as progressing while changes to it are rolled out. Whether it is
+// available is left unchanged, as it may stay available during a rollout.
+func (r *{{ .Resource.Kind }}Reconciler) setProgressing({{ lower .Resource.Kind }} *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}, reason, message string) {
+	r.setCondition({{ lower .Resource.Kind }}, {{ .Resource.ImportAlias }}.ConditionProgressing, metav1.ConditionTrue, reason, message)
+	r.setCondition({{ lower .Resource.Kind }}, {{ .Resource.ImportAlias }}.ConditionDegraded, metav1.ConditionFalse, reason, message)
+	r.keepAvailable({{ lower .Resource.Kind }}, reason, message)
+}
+
+// setDegraded marks {{ lower .Resource.Kind }} as degraded by err, and no longer progressing. Whether it is
+// available is left unchanged, as it may still be available while degraded.
+func (r *{{ .Resource.Kind }}Reconciler) setDegraded({{ lower .Resource.Kind }} *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}, reason string, err error) {
+	r.setCondition({{ lower .Resource.Kind }}, {{ .Resource.ImportAlias }}.ConditionDegraded, metav1.ConditionTrue, reason, err.Error())
+	r.setCondition({{ lower .Resource.Kind }}, {{ .Resource.ImportAlias }}.ConditionProgressing, metav1.ConditionFalse, reason, err.Error())
+	r.keepAvailable({{ lower .Resource.Kind }}, reason, err.Error())
+}
+
+// keepAvailable observes whether {{ lower .Resource.Kind }} is available at its current generation without changing it,
+// or marks it as unknown if it was never set.
+func (r *{{ .Resource.Kind }}Reconciler) keepAvailable({{ lower .Resource.Kind }} *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}, reason, message string) {
+	available := {{ .Resource.ImportAlias }}.FindStatusCondition({{ lower .Resource.Kind }}.Status.Conditions, {{ .Resource.ImportAlias }}.ConditionAvailable)
+	if available == nil {
+		r.setCondition({{ lower .Resource.Kind }}, {{ .Resource.ImportAlias }}.ConditionAvailable, metav1.ConditionUnknown, reason, message)
+		return
+	}
+	available.ObservedGeneration = {{ lower .Resource.Kind }}.GetGeneration()
+}
+
+// setCondition sets a condition of {{ lower .Resource.Kind }}, observed at its current generation.
+func (r *{{ .Resource.Kind }}Reconciler) setCondition({{ lower .Resource.Kind }} *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	{{ .Resource.ImportAlias }}.SetStatusCondition(&{{ lower .Resource.Kind }}.Status.Conditions, {{ .Resource.ImportAlias }}.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: {{ lower .Resource.Kind }}.GetGeneration(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// updateStatus records that {{ lower .Resource.Kind }} was reconciled at its current generation, and updates its status.
+func (r *{{ .Resource.Kind }}Reconciler) updateStatus(ctx context.Context, {{ lower .Resource.Kind }} *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) error {
+	{{ lower .Resource.Kind }}.Status.ObservedGeneration = {{ lower .Resource.Kind }}.GetGeneration()
+	return r.Status().Update(ctx, {{ lower .Resource.Kind }})
+}
+`
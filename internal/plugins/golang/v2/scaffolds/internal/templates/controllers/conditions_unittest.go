@@ -0,0 +1,216 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/file"
+)
+
+var _ file.Template = &ConditionsTest{}
+
+// ConditionsTest scaffolds unit tests of the status condition helpers scaffolded by Conditions,
+// run against a fake client.
+type ConditionsTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ConditionsTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("controllers", "%[group]", "%[kind]_conditions_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_conditions_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = conditionsTestTemplate
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+const conditionsTestTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+func TestSet{{ .Resource.Kind }}Conditions(t *testing.T) {
+	r := &{{ .Resource.Kind }}Reconciler{}
+	type statuses map[string]metav1.ConditionStatus
+
+	tests := []struct {
+		name string
+		// set sets the conditions of a {{ .Resource.Kind }} that has the before conditions.
+		set    func(*{{ .Resource.ImportAlias }}.{{ .Resource.Kind }})
+		before statuses
+		want   statuses
+	}{
+		{
+			name: "available",
+			set: func(obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) {
+				r.setAvailable(obj, "Reconciled", "")
+			},
+			before: statuses{ {{- .Resource.ImportAlias }}.ConditionDegraded: metav1.ConditionTrue},
+			want: statuses{
+				{{ .Resource.ImportAlias }}.ConditionAvailable:   metav1.ConditionTrue,
+				{{ .Resource.ImportAlias }}.ConditionProgressing: metav1.ConditionFalse,
+				{{ .Resource.ImportAlias }}.ConditionDegraded:    metav1.ConditionFalse,
+			},
+		},
+		{
+			name: "progressing before the first rollout",
+			set: func(obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) {
+				r.setProgressing(obj, "RollingOut", "")
+			},
+			want: statuses{
+				{{ .Resource.ImportAlias }}.ConditionAvailable:   metav1.ConditionUnknown,
+				{{ .Resource.ImportAlias }}.ConditionProgressing: metav1.ConditionTrue,
+				{{ .Resource.ImportAlias }}.ConditionDegraded:    metav1.ConditionFalse,
+			},
+		},
+		{
+			name: "progressing while available",
+			set: func(obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) {
+				r.setProgressing(obj, "RollingOut", "")
+			},
+			before: statuses{ {{- .Resource.ImportAlias }}.ConditionAvailable: metav1.ConditionTrue},
+			want: statuses{
+				{{ .Resource.ImportAlias }}.ConditionAvailable:   metav1.ConditionTrue,
+				{{ .Resource.ImportAlias }}.ConditionProgressing: metav1.ConditionTrue,
+				{{ .Resource.ImportAlias }}.ConditionDegraded:    metav1.ConditionFalse,
+			},
+		},
+		{
+			name: "degraded",
+			set: func(obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) {
+				r.setDegraded(obj, "ReconcileFailed", errors.New("reconcile failed"))
+			},
+			before: statuses{
+				{{ .Resource.ImportAlias }}.ConditionAvailable:   metav1.ConditionTrue,
+				{{ .Resource.ImportAlias }}.ConditionProgressing: metav1.ConditionTrue,
+			},
+			want: statuses{
+				{{ .Resource.ImportAlias }}.ConditionAvailable:   metav1.ConditionTrue,
+				{{ .Resource.ImportAlias }}.ConditionProgressing: metav1.ConditionFalse,
+				{{ .Resource.ImportAlias }}.ConditionDegraded:    metav1.ConditionTrue,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+			for conditionType, status := range tt.before {
+				r.setCondition(obj, conditionType, status, "Before", "")
+			}
+			obj.Generation = 2
+
+			tt.set(obj)
+			if len(obj.Status.Conditions) != len(tt.want) {
+				t.Fatalf("conditions = %+v, want %v", obj.Status.Conditions, tt.want)
+			}
+			for conditionType, status := range tt.want {
+				c := {{ .Resource.ImportAlias }}.FindStatusCondition(obj.Status.Conditions, conditionType)
+				if c == nil || c.Status != status {
+					t.Errorf("%s condition = %+v, want status %s", conditionType, c, status)
+					continue
+				}
+				if c.ObservedGeneration != obj.Generation {
+					t.Errorf("%s condition observedGeneration = %d, want %d", conditionType, c.ObservedGeneration, obj.Generation)
+				}
+			}
+		})
+	}
+}
+
+func TestSet{{ .Resource.Kind }}ConditionLastTransitionTime(t *testing.T) {
+	r := &{{ .Resource.Kind }}Reconciler{}
+	obj := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{}
+	r.setAvailable(obj, "Reconciled", "")
+	transitioned := metav1.NewTime(time.Now().Add(-time.Hour))
+	for i := range obj.Status.Conditions {
+		obj.Status.Conditions[i].LastTransitionTime = transitioned
+	}
+
+	r.setDegraded(obj, "ReconcileFailed", errors.New("reconcile failed"))
+	available := {{ .Resource.ImportAlias }}.FindStatusCondition(obj.Status.Conditions, {{ .Resource.ImportAlias }}.ConditionAvailable)
+	if !available.LastTransitionTime.Equal(&transitioned) {
+		t.Errorf("unchanged condition %+v, want lastTransitionTime %v", available, transitioned)
+	}
+	degraded := {{ .Resource.ImportAlias }}.FindStatusCondition(obj.Status.Conditions, {{ .Resource.ImportAlias }}.ConditionDegraded)
+	if degraded.LastTransitionTime.Equal(&transitioned) {
+		t.Errorf("changed condition %+v, want a new lastTransitionTime", degraded)
+	}
+	if degraded.Reason != "ReconcileFailed" || degraded.Message != "reconcile failed" {
+		t.Errorf("changed condition %+v, want reason ReconcileFailed and message %q", degraded, "reconcile failed")
+	}
+}
+
+func TestUpdate{{ .Resource.Kind }}Status(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := {{ .Resource.ImportAlias }}.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	obj := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "{{ lower .Resource.Kind }}-sample", Generation: 3},
+	}
+	ctx := context.Background()
+	r := &{{ .Resource.Kind }}Reconciler{
+		Client: fake.NewFakeClientWithScheme(scheme, obj.DeepCopy()),
+		Log:    zap.New(zap.UseDevMode(true)),
+		Scheme: scheme,
+	}
+	key := types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}
+	if err := r.Get(ctx, key, obj); err != nil {
+		t.Fatal(err)
+	}
+
+	r.setAvailable(obj, "Reconciled", "")
+	if err := r.updateStatus(ctx, obj); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+	got := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{}
+	if err := r.Get(ctx, key, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.ObservedGeneration != 3 {
+		t.Errorf("observedGeneration = %d, want 3", got.Status.ObservedGeneration)
+	}
+	if !{{ .Resource.ImportAlias }}.IsStatusConditionTrue(got.Status.Conditions, {{ .Resource.ImportAlias }}.ConditionAvailable) {
+		t.Errorf("conditions = %+v, want Available", got.Status.Conditions)
+	}
+}
+`
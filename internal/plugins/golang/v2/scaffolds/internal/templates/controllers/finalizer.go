@@ -0,0 +1,123 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/file"
+)
+
+var _ file.Template = &Finalizer{}
+
+// Finalizer scaffolds a resource's finalizer, the reconciler helpers adding it to live objects and
+// finalizing deleted objects before removing it, and a stub for the user's finalization logic.
+type Finalizer struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Finalizer) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("controllers", "%[group]", "%[kind]_finalizer.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_finalizer.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = finalizerTemplate
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+const finalizerTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+// {{ lower .Resource.Kind }}Finalizer is added to every {{ .Resource.Kind }}, so that it is finalized by
+// finalize{{ .Resource.Kind }} before it is deleted.
+const {{ lower .Resource.Kind }}Finalizer = "{{ .Resource.Domain }}/finalizer"
+
+// finalize{{ .Resource.Kind }} cleans up what deleting {{ lower .Resource.Kind }} does not, such as resources outside
+// of the cluster or not owned by it. It must be idempotent: it is retried until it succeeds, and runs
+// again if removing the finalizer afterwards fails.
+func (r *{{ .Resource.Kind }}Reconciler) finalize{{ .Resource.Kind }}(ctx context.Context, {{ lower .Resource.Kind }} *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) error {
+	// TODO(user): clean up what deleting {{ lower .Resource.Kind }} does not.
+	return nil
+}
+
+// handleFinalizer adds {{ lower .Resource.Kind }}Finalizer to a {{ .Resource.Kind }} that is not being deleted. A {{ .Resource.Kind }}
+// that is being deleted is finalized, then its finalizer is removed so that the deletion completes.
+// If handleFinalizer returns true, Reconcile must stop and return the returned result and error.
+func (r *{{ .Resource.Kind }}Reconciler) handleFinalizer(ctx context.Context, {{ lower .Resource.Kind }} *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) (ctrl.Result, bool, error) {
+	if {{ lower .Resource.Kind }}.GetDeletionTimestamp().IsZero() {
+		if controllerutil.ContainsFinalizer({{ lower .Resource.Kind }}, {{ lower .Resource.Kind }}Finalizer) {
+			return ctrl.Result{}, false, nil
+		}
+		controllerutil.AddFinalizer({{ lower .Resource.Kind }}, {{ lower .Resource.Kind }}Finalizer)
+		if result, stop, err := r.updateFinalizers(ctx, {{ lower .Resource.Kind }}); stop {
+			return result, stop, err
+		}
+		return ctrl.Result{}, false, nil
+	}
+
+	// Another finalizer is holding up the deletion after this one was removed.
+	if !controllerutil.ContainsFinalizer({{ lower .Resource.Kind }}, {{ lower .Resource.Kind }}Finalizer) {
+		return ctrl.Result{}, true, nil
+	}
+	if err := r.finalize{{ .Resource.Kind }}(ctx, {{ lower .Resource.Kind }}); err != nil {
+		// Keep the finalizer, so that finalization is retried with backoff.
+		return ctrl.Result{}, true, err
+	}
+	controllerutil.RemoveFinalizer({{ lower .Resource.Kind }}, {{ lower .Resource.Kind }}Finalizer)
+	result, _, err := r.updateFinalizers(ctx, {{ lower .Resource.Kind }})
+	return result, true, err
+}
+
+// updateFinalizers updates the finalizers of {{ lower .Resource.Kind }}. It returns true if the update failed,
+// requeueing the reconcile if {{ lower .Resource.Kind }} changed since it was read.
+func (r *{{ .Resource.Kind }}Reconciler) updateFinalizers(ctx context.Context, {{ lower .Resource.Kind }} *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) (ctrl.Result, bool, error) {
+	err := r.Update(ctx, {{ lower .Resource.Kind }})
+	switch {
+	case err == nil:
+		return ctrl.Result{}, false, nil
+	case apierrors.IsConflict(err):
+		// Reconcile the latest version of {{ lower .Resource.Kind }} instead.
+		return ctrl.Result{Requeue: true}, true, nil
+	case apierrors.IsNotFound(err):
+		// {{ lower .Resource.Kind }} was deleted, and there is nothing left to reconcile.
+		return ctrl.Result{}, true, nil
+	default:
+		return ctrl.Result{}, true, err
+	}
+}
+`
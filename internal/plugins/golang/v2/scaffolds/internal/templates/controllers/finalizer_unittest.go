@@ -0,0 +1,174 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/file"
+)
+
+var _ file.Template = &FinalizerTest{}
+
+// FinalizerTest scaffolds table-driven unit tests of the finalizer helpers scaffolded by Finalizer,
+// run against a fake client.
+type FinalizerTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *FinalizerTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("controllers", "%[group]", "%[kind]_finalizer_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_finalizer_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = finalizerTestTemplate
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+const finalizerTestTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+func TestHandle{{ .Resource.Kind }}Finalizer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := {{ .Resource.ImportAlias }}.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	now := metav1.Now()
+
+	tests := []struct {
+		name string
+		// obj is the {{ .Resource.Kind }} in the cluster.
+		obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}
+		// stale is true if the reconciled {{ .Resource.Kind }} is older than the one in the cluster.
+		stale bool
+
+		wantResult     ctrl.Result
+		wantStop       bool
+		wantFinalizers []string
+	}{
+		{
+			name:           "adds the finalizer to a live object",
+			obj:            new{{ .Resource.Kind }}(nil),
+			wantFinalizers: []string{ {{- lower .Resource.Kind }}Finalizer},
+		},
+		{
+			name:           "keeps the finalizer of a live object",
+			obj:            new{{ .Resource.Kind }}(nil, {{ lower .Resource.Kind }}Finalizer),
+			wantFinalizers: []string{ {{- lower .Resource.Kind }}Finalizer},
+		},
+		{
+			name:           "finalizes a deleted object and removes its finalizer",
+			obj:            new{{ .Resource.Kind }}(&now, {{ lower .Resource.Kind }}Finalizer, "example.com/other"),
+			wantStop:       true,
+			wantFinalizers: []string{"example.com/other"},
+		},
+		{
+			name:           "stops reconciling a finalized object",
+			obj:            new{{ .Resource.Kind }}(&now, "example.com/other"),
+			wantStop:       true,
+			wantFinalizers: []string{"example.com/other"},
+		},
+		{
+			name:       "requeues a stale object",
+			obj:        new{{ .Resource.Kind }}(nil),
+			stale:      true,
+			wantResult: ctrl.Result{Requeue: true},
+			wantStop:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			r := &{{ .Resource.Kind }}Reconciler{
+				Client: fake.NewFakeClientWithScheme(scheme, tt.obj.DeepCopy()),
+				Log:    zap.New(zap.UseDevMode(true)),
+				Scheme: scheme,
+			}
+			key := types.NamespacedName{Namespace: tt.obj.Namespace, Name: tt.obj.Name}
+			{{ lower .Resource.Kind }} := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{}
+			if err := r.Get(ctx, key, {{ lower .Resource.Kind }}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.stale {
+				if err := r.Update(ctx, {{ lower .Resource.Kind }}.DeepCopy()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, stop, err := r.handleFinalizer(ctx, {{ lower .Resource.Kind }})
+			if err != nil {
+				t.Fatalf("handleFinalizer() error = %v", err)
+			}
+			if result != tt.wantResult || stop != tt.wantStop {
+				t.Errorf("handleFinalizer() = %+v, %v, want %+v, %v", result, stop, tt.wantResult, tt.wantStop)
+			}
+			if tt.stale {
+				return
+			}
+			got := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{}
+			if err := r.Get(ctx, key, got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got.GetFinalizers()) != len(tt.wantFinalizers) {
+				t.Fatalf("finalizers = %v, want %v", got.GetFinalizers(), tt.wantFinalizers)
+			}
+			for _, f := range tt.wantFinalizers {
+				if !controllerutil.ContainsFinalizer(got, f) {
+					t.Errorf("finalizers = %v, want %v", got.GetFinalizers(), tt.wantFinalizers)
+				}
+			}
+		})
+	}
+}
+
+func new{{ .Resource.Kind }}(deletionTimestamp *metav1.Time, finalizers ...string) *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }} {
+	return &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              "{{ lower .Resource.Kind }}-sample",
+			DeletionTimestamp: deletionTimestamp,
+			Finalizers:        finalizers,
+		},
+	}
+}
+`
@@ -0,0 +1,64 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scaffolds contains the SDK's additions to the kubebuilder Go scaffold.
+// TODO: rewrite this when plugins phase 2 is implemented.
+package scaffolds
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/pkg/model/resource"
+
+	"github.com/operator-framework/operator-sdk/internal/kubebuilder/machinery"
+	"github.com/operator-framework/operator-sdk/internal/plugins/golang/v2/scaffolds/internal/templates/api"
+)
+
+// WebhookOptions are the options of a 'create webhook' run.
+type WebhookOptions struct {
+	Resource                           resource.Options
+	Defaulting, Validation, Conversion bool
+}
+
+// RunCreateWebhook scaffolds tests of the webhooks of a resource: table-driven unit tests of its
+// defaulting and validating webhooks, an envtest suite serving its webhooks for integration tests,
+// and a conversion round-trip fuzz test if it has a conversion webhook.
+func RunCreateWebhook(cfg *config.Config, opts WebhookOptions) error {
+	bp, err := ioutil.ReadFile(filepath.Join("hack", "boilerplate.go.txt")) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("unable to load boilerplate: %v", err)
+	}
+	universe := model.NewUniverse(
+		model.WithConfig(cfg),
+		model.WithBoilerplate(string(bp)),
+		model.WithResource(opts.Resource.NewResource(cfg, false)),
+	)
+
+	builders := []file.Builder{&api.WebhookSuiteTest{}}
+	if opts.Defaulting || opts.Validation {
+		builders = append(builders, &api.WebhookTest{Defaulting: opts.Defaulting, Validating: opts.Validation})
+	}
+	if opts.Conversion {
+		builders = append(builders, &api.ConversionTest{})
+	}
+	if err := machinery.NewScaffold().Execute(universe, builders...); err != nil {
+		return fmt.Errorf("error scaffolding webhook tests: %v", err)
+	}
+	return nil
+}
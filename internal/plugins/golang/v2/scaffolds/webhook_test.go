@@ -0,0 +1,114 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaffolds
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/model/resource"
+)
+
+var _ = Describe("RunCreateWebhook", func() {
+	var (
+		cfg    *config.Config
+		dir    string
+		oldDir string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "webhook-scaffold-")
+		Expect(err).NotTo(HaveOccurred())
+		oldDir, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(dir)).To(Succeed())
+		Expect(os.Mkdir("hack", 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join("hack", "boilerplate.go.txt"), []byte("// Boilerplate"), 0644)).To(Succeed())
+		cfg = &config.Config{Version: config.Version3Alpha, Domain: "example.com", Repo: "github.com/example/memcached-operator"}
+	})
+
+	AfterEach(func() {
+		Expect(os.Chdir(oldDir)).To(Succeed())
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	opts := func(kind string, defaulting, validation, conversion bool) WebhookOptions {
+		return WebhookOptions{
+			Resource:   resource.Options{Group: "cache", Version: "v1alpha1", Kind: kind},
+			Defaulting: defaulting,
+			Validation: validation,
+			Conversion: conversion,
+		}
+	}
+
+	parse := func(path string) string {
+		b, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = parser.ParseFile(token.NewFileSet(), path, b, 0)
+		Expect(err).NotTo(HaveOccurred(), string(b))
+		return string(b)
+	}
+
+	It("scaffolds unit tests of defaulting and validating webhooks, and a webhook suite", func() {
+		Expect(RunCreateWebhook(cfg, opts("Memcached", true, true, false))).To(Succeed())
+
+		test := parse(filepath.Join("api", "v1alpha1", "memcached_webhook_test.go"))
+		Expect(test).To(HavePrefix("// Boilerplate"))
+		Expect(test).To(ContainSubstring("func TestMemcachedDefault(t *testing.T)"))
+		Expect(test).To(ContainSubstring("func TestMemcachedValidateCreate(t *testing.T)"))
+		Expect(test).To(ContainSubstring("func TestMemcachedValidateUpdate(t *testing.T)"))
+		Expect(test).To(ContainSubstring("func TestMemcachedValidateDelete(t *testing.T)"))
+		Expect(test).To(ContainSubstring(`Describe("Memcached webhooks"`))
+
+		suite := parse(filepath.Join("api", "v1alpha1", "webhook_suite_test.go"))
+		Expect(suite).To(ContainSubstring(`filepath.Join("..", "..", "config", "webhook")`))
+		Expect(suite).To(ContainSubstring("Expect((&Memcached{}).SetupWebhookWithManager(mgr)).To(Succeed())"))
+		Expect(filepath.Join("api", "v1alpha1", "memcached_conversion_test.go")).NotTo(BeAnExistingFile())
+	})
+	It("only scaffolds tests of the webhooks created", func() {
+		Expect(RunCreateWebhook(cfg, opts("Memcached", false, true, false))).To(Succeed())
+		test := parse(filepath.Join("api", "v1alpha1", "memcached_webhook_test.go"))
+		Expect(test).NotTo(ContainSubstring("TestMemcachedDefault"))
+		Expect(test).To(ContainSubstring("TestMemcachedValidateCreate"))
+	})
+	It("scaffolds a conversion round-trip test", func() {
+		Expect(RunCreateWebhook(cfg, opts("Memcached", false, false, true))).To(Succeed())
+		test := parse(filepath.Join("api", "v1alpha1", "memcached_conversion_test.go"))
+		Expect(test).To(ContainSubstring("func TestMemcachedConversionRoundTrip(t *testing.T)"))
+		Expect(filepath.Join("api", "v1alpha1", "memcached_webhook_test.go")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join("api", "v1alpha1", "webhook_suite_test.go")).To(BeAnExistingFile())
+	})
+	It("adds the webhooks of other resources to an existing suite", func() {
+		Expect(RunCreateWebhook(cfg, opts("Memcached", true, false, false))).To(Succeed())
+		Expect(RunCreateWebhook(cfg, opts("Memcache", false, true, false))).To(Succeed())
+		suite := parse(filepath.Join("api", "v1alpha1", "webhook_suite_test.go"))
+		Expect(suite).To(ContainSubstring("Expect((&Memcached{}).SetupWebhookWithManager(mgr)).To(Succeed())"))
+		Expect(suite).To(ContainSubstring("Expect((&Memcache{}).SetupWebhookWithManager(mgr)).To(Succeed())"))
+	})
+	It("scaffolds tests of multi-group projects under apis", func() {
+		cfg.MultiGroup = true
+		Expect(RunCreateWebhook(cfg, opts("Memcached", true, false, false))).To(Succeed())
+		suite := parse(filepath.Join("apis", "cache", "v1alpha1", "webhook_suite_test.go"))
+		Expect(suite).To(ContainSubstring(`filepath.Join("..", "..", "..", "config", "crd", "bases")`))
+		Expect(filepath.Join("apis", "cache", "v1alpha1", "memcached_webhook_test.go")).To(BeAnExistingFile())
+	})
+})
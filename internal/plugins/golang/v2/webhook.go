@@ -0,0 +1,84 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/plugin"
+
+	"github.com/operator-framework/operator-sdk/internal/plugins/golang/v2/scaffolds"
+)
+
+type createWebhookPlugin struct {
+	plugin.CreateWebhook
+
+	config *config.Config
+	// flags are the flags bound by the kubebuilder plugin, which stores their values in unexported fields.
+	flags *pflag.FlagSet
+}
+
+var _ plugin.CreateWebhook = &createWebhookPlugin{}
+
+func (p *createWebhookPlugin) UpdateContext(ctx *plugin.Context) { p.CreateWebhook.UpdateContext(ctx) }
+
+func (p *createWebhookPlugin) BindFlags(fs *pflag.FlagSet) {
+	p.CreateWebhook.BindFlags(fs)
+	p.flags = fs
+}
+
+func (p *createWebhookPlugin) InjectConfig(c *config.Config) {
+	p.CreateWebhook.InjectConfig(c)
+	p.config = c
+}
+
+func (p *createWebhookPlugin) Run() error {
+	if err := p.CreateWebhook.Run(); err != nil {
+		return err
+	}
+
+	// Emulate plugins phase 2 behavior by checking the config for this plugin's config object.
+	if !hasPluginConfig(p.config) {
+		return nil
+	}
+
+	// Run SDK phase 2 plugins.
+	return p.runPhase2()
+}
+
+// SDK phase 2 plugins.
+func (p *createWebhookPlugin) runPhase2() error {
+	opts := scaffolds.WebhookOptions{
+		Defaulting: p.boolFlag("defaulting"),
+		Validation: p.boolFlag("programmatic-validation"),
+		Conversion: p.boolFlag("conversion"),
+	}
+	opts.Resource.Group = p.stringFlag("group")
+	opts.Resource.Version = p.stringFlag("version")
+	opts.Resource.Kind = p.stringFlag("kind")
+	opts.Resource.Plural = p.stringFlag("resource")
+	return scaffolds.RunCreateWebhook(p.config, opts)
+}
+
+func (p *createWebhookPlugin) stringFlag(name string) string {
+	if f := p.flags.Lookup(name); f != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+func (p *createWebhookPlugin) boolFlag(name string) bool {
+	return p.stringFlag(name) == "true"
+}
@@ -27,6 +27,7 @@ import (
 	"sigs.k8s.io/kubebuilder/pkg/plugin/scaffold"
 
 	"github.com/operator-framework/operator-sdk/internal/kubebuilder/cmdutil"
+	"github.com/operator-framework/operator-sdk/internal/plugins/external"
 	"github.com/operator-framework/operator-sdk/internal/plugins/helm/v1/chartutil"
 	"github.com/operator-framework/operator-sdk/internal/plugins/helm/v1/scaffolds"
 	"github.com/operator-framework/operator-sdk/internal/plugins/manifests"
@@ -39,6 +40,8 @@ type initPlugin struct {
 
 	// If true, run the `create api` plugin.
 	doCreateAPI bool
+	// externalPlugins are declared as 'name=command [args]'.
+	externalPlugins []string
 
 	// For help text.
 	commandName string
@@ -129,6 +132,7 @@ func (p *initPlugin) BindFlags(fs *pflag.FlagSet) {
 	fs.SortFlags = false
 	fs.StringVar(&p.config.Domain, "domain", "my.domain", "domain for groups")
 	fs.StringVar(&p.config.ProjectName, "project-name", "", "name of this project, the default being directory name")
+	fs.StringArrayVar(&p.externalPlugins, "external-plugin", nil, external.FlagUsage)
 	p.apiPlugin.BindFlags(fs)
 }
 
@@ -162,6 +166,9 @@ func (p *initPlugin) runPhase2() error {
 	if err := scorecard.RunInit(p.config); err != nil {
 		return err
 	}
+	if err := external.RunInit(p.config, p.externalPlugins); err != nil {
+		return err
+	}
 
 	if p.doCreateAPI {
 		if err := p.apiPlugin.runPhase2(); err != nil {
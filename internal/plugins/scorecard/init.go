@@ -20,7 +20,6 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strings"
 	"text/template"
 
 	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
@@ -30,7 +29,6 @@ import (
 
 	"github.com/operator-framework/operator-sdk/internal/plugins/util/kustomize"
 	"github.com/operator-framework/operator-sdk/internal/scorecard"
-	"github.com/operator-framework/operator-sdk/internal/version"
 )
 
 const (
@@ -62,10 +60,6 @@ const (
 	defaultConfigName = "config"
 )
 
-// defaultTestImageTag points to the latest-released image.
-var defaultTestImageTag = fmt.Sprintf("quay.io/operator-framework/scorecard-test:%s",
-	strings.TrimSuffix(version.Version, "+git"))
-
 // defaultDir is the default directory in which to generate kustomize bases and the kustomization.yaml.
 var defaultDir = filepath.Join("config", "scorecard")
 
@@ -76,7 +70,7 @@ func RunInit(cfg *config.Config) error {
 		return nil
 	}
 
-	return generate(defaultTestImageTag, defaultDir)
+	return generate(scorecard.DefaultTestImage, defaultDir)
 }
 
 // scorecardKustomizationValues holds data required to generate a scorecard's kustomization.yaml.
@@ -0,0 +1,325 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/containerd/containerd/images"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// MaxUnpackedBundleSize is the maximum size of a bundle's manifests, which OLM unpacks into a ConfigMap.
+	MaxUnpackedBundleSize = 1024 * 1024
+
+	// largeCSVSize is the CSV size above which resolution and catalog serving slow noticeably.
+	largeCSVSize = MaxUnpackedBundleSize / 2
+	// largeCRDSize is the CRD size above which a CRD's schema descriptions should be trimmed.
+	largeCRDSize = MaxUnpackedBundleSize / 4
+	// largeCSVFieldSize is the size above which a CSV field is reported.
+	largeCSVFieldSize = 64 * 1024
+)
+
+// Levels of bundle analysis findings.
+const (
+	FindingWarning = "warning"
+	FindingError   = "error"
+)
+
+// BundleAnalysis describes the size and content of a bundle.
+type BundleAnalysis struct {
+	// ImageSize is the compressed size of the bundle image's layers and config, if the bundle is an image.
+	ImageSize int64 `json:"imageSize,omitempty"`
+	// ManifestsSize is the total size of the bundle's manifests.
+	ManifestsSize int64          `json:"manifestsSize"`
+	Manifests     []ManifestSize `json:"manifests"`
+	Findings      []Finding      `json:"findings,omitempty"`
+}
+
+// ManifestSize is the size of a manifest in a bundle's manifests directory.
+type ManifestSize struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	Size int64  `json:"size"`
+}
+
+// Finding is a problem found in a bundle and how to fix it.
+type Finding struct {
+	Level      string `json:"level"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// HasErrors returns true if a has any error-level findings.
+func (a BundleAnalysis) HasErrors() bool {
+	for _, f := range a.Findings {
+		if f.Level == FindingError {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *BundleAnalysis) addFinding(level, suggestion, format string, args ...interface{}) {
+	a.Findings = append(a.Findings, Finding{Level: level, Message: fmt.Sprintf(format, args...), Suggestion: suggestion})
+}
+
+// AnalyzeBundleDir analyzes the manifests of the bundle in bundleDir for content that makes
+// the bundle too large for OLM to unpack, or that slows resolution in catalogs containing it:
+// oversized CSVs and CRDs, large CSV fields like alm-examples, and duplicated RBAC rules.
+func AnalyzeBundleDir(bundleDir string) (*BundleAnalysis, error) {
+	manifestsDir := filepath.Join(bundleDir, registrybundle.ManifestsDir)
+	if labels, _, err := FindBundleMetadata(bundleDir); err == nil {
+		if dir, ok := labels.GetManifestsDir(); ok {
+			manifestsDir = filepath.Join(bundleDir, dir)
+		}
+	}
+	infos, err := ioutil.ReadDir(manifestsDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifests directory: %v", err)
+	}
+
+	a := &BundleAnalysis{}
+	var csv *v1alpha1.ClusterServiceVersion
+	var roles []rbacv1.PolicyRule
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		path := filepath.Join(manifestsDir, info.Name())
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		obj := struct {
+			Kind  string              `json:"kind"`
+			Rules []rbacv1.PolicyRule `json:"rules"`
+		}{}
+		if err := yaml.Unmarshal(b, &obj); err != nil {
+			return nil, fmt.Errorf("error decoding manifest %s: %v", path, err)
+		}
+		relPath, err := filepath.Rel(bundleDir, path)
+		if err != nil {
+			relPath = path
+		}
+		a.Manifests = append(a.Manifests, ManifestSize{Path: relPath, Kind: obj.Kind, Size: info.Size()})
+		a.ManifestsSize += info.Size()
+
+		switch obj.Kind {
+		case v1alpha1.ClusterServiceVersionKind:
+			csv = &v1alpha1.ClusterServiceVersion{}
+			if err := yaml.Unmarshal(b, csv); err != nil {
+				return nil, fmt.Errorf("error decoding CSV %s: %v", path, err)
+			}
+			if info.Size() > largeCSVSize {
+				a.addFinding(FindingWarning, "Reduce the size of the CSV's largest fields",
+					"CSV %s is %s, which slows resolution in catalogs containing it", relPath, FormatSize(info.Size()))
+			}
+		case "CustomResourceDefinition":
+			if info.Size() > largeCRDSize {
+				a.addFinding(FindingWarning, "Omit schema descriptions with the controller-gen option "+
+					"'crd:maxDescLen=0', or move detailed documentation out of the schema",
+					"CRD %s is %s", relPath, FormatSize(info.Size()))
+			}
+		case "Role", "ClusterRole":
+			roles = append(roles, obj.Rules...)
+		}
+	}
+	sort.Slice(a.Manifests, func(i, j int) bool { return a.Manifests[i].Size > a.Manifests[j].Size })
+
+	if a.ManifestsSize > MaxUnpackedBundleSize {
+		a.addFinding(FindingError, "Reduce the size of the largest manifests listed above",
+			"bundle manifests are %s, larger than the %s OLM can unpack",
+			FormatSize(a.ManifestsSize), FormatSize(MaxUnpackedBundleSize))
+	}
+	if csv != nil {
+		a.analyzeCSVFields(csv)
+		a.analyzeRBAC(csv, roles)
+	}
+	return a, nil
+}
+
+// analyzeCSVFields reports CSV fields larger than largeCSVFieldSize.
+func (a *BundleAnalysis) analyzeCSVFields(csv *v1alpha1.ClusterServiceVersion) {
+	fields := []struct {
+		name, suggestion string
+		value            interface{}
+	}{
+		{"metadata.annotations.alm-examples", "Include one minimal example per owned API",
+			csv.GetAnnotations()["alm-examples"]},
+		{"spec.description", "Link to detailed documentation instead of embedding it", csv.Spec.Description},
+		{"spec.icon", "Use a smaller, compressed icon", csv.Spec.Icon},
+		{"spec.customresourcedefinitions", "Describe only the fields users configure with spec and status descriptors",
+			csv.Spec.CustomResourceDefinitions},
+		{"spec.install", "Remove duplicated RBAC rules, and move large embedded configuration into separate manifests",
+			csv.Spec.InstallStrategy},
+	}
+	for _, f := range fields {
+		b, err := json.Marshal(f.value)
+		if err != nil {
+			continue
+		}
+		if size := int64(len(b)); size > largeCSVFieldSize {
+			a.addFinding(FindingWarning, f.suggestion, "CSV field %s is %s", f.name, FormatSize(size))
+		}
+	}
+}
+
+// analyzeRBAC reports rules duplicated within a CSV service account's permissions, and rules
+// in bundle Roles and ClusterRoles that duplicate a CSV rule.
+func (a *BundleAnalysis) analyzeRBAC(csv *v1alpha1.ClusterServiceVersion, roles []rbacv1.PolicyRule) {
+	spec := csv.Spec.InstallStrategy.StrategySpec
+	csvRules := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, perms := range [][]v1alpha1.StrategyDeploymentPermissions{spec.Permissions, spec.ClusterPermissions} {
+		for _, perm := range perms {
+			for _, rule := range perm.Rules {
+				key := ruleKey(rule)
+				csvRules[key] = true
+				if saKey := perm.ServiceAccountName + "/" + key; seen[saKey] {
+					a.addFinding(FindingWarning, "Remove the duplicate rule",
+						"CSV service account %q is granted rule %s more than once", perm.ServiceAccountName, key)
+				} else {
+					seen[saKey] = true
+				}
+			}
+		}
+	}
+	for _, rule := range roles {
+		if key := ruleKey(rule); csvRules[key] {
+			a.addFinding(FindingWarning, "Remove the rule from either the CSV or the role manifest",
+				"rule %s is in both the CSV and a role manifest", key)
+		}
+	}
+}
+
+// ruleKey returns a string identifying rule regardless of the order of its values.
+func ruleKey(rule rbacv1.PolicyRule) string {
+	parts := []string{}
+	for _, field := range []struct {
+		name   string
+		values []string
+	}{
+		{"apiGroups", rule.APIGroups},
+		{"resources", rule.Resources},
+		{"resourceNames", rule.ResourceNames},
+		{"nonResourceURLs", rule.NonResourceURLs},
+		{"verbs", rule.Verbs},
+	} {
+		if len(field.values) == 0 {
+			continue
+		}
+		values := append([]string{}, field.values...)
+		sort.Strings(values)
+		parts = append(parts, fmt.Sprintf("%s=%s", field.name, strings.Join(values, ",")))
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+// ImageSize returns the compressed size of image's layers and config in its registry.
+func ImageSize(ctx context.Context, image string) (int64, error) {
+	resolver, err := containerdregistry.NewResolver("", false, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error creating resolver: %v", err)
+	}
+	name, desc, err := resolver.Resolve(ctx, strings.TrimPrefix(image, ArtifactRefPrefix))
+	if err != nil {
+		return 0, fmt.Errorf("error resolving image %s: %v", image, err)
+	}
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, images.MediaTypeDockerSchema2Manifest:
+	default:
+		return 0, fmt.Errorf("image %s has unsupported manifest media type %q", image, desc.MediaType)
+	}
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("error creating fetcher for image %s: %v", image, err)
+	}
+	manifest := ocispec.Manifest{}
+	if err := fetchJSON(ctx, fetcher, desc, &manifest); err != nil {
+		return 0, fmt.Errorf("error fetching image %s manifest: %v", image, err)
+	}
+	size := manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+	return size, nil
+}
+
+// FormatSize returns size in bytes in human-readable binary units, ex. "1.5 KiB".
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// AnalyzeBundle analyzes the bundle at bundleRef, which is either a bundle directory on disk,
+// a bundle OCI artifact prefixed by ArtifactRefPrefix, or a bundle image. Artifacts and images
+// are pulled and unpacked into a temporary directory that is removed before AnalyzeBundle returns.
+func AnalyzeBundle(ctx context.Context, logger *log.Entry, bundleRef string) (*BundleAnalysis, error) {
+	if isDir(bundleRef) {
+		return AnalyzeBundleDir(bundleRef)
+	}
+
+	var bundleDir string
+	var err error
+	if IsArtifactRef(bundleRef) {
+		bundleDir, err = ExtractBundleArtifact(ctx, logger, bundleRef)
+	} else {
+		bundleDir, err = ExtractBundleImage(ctx, logger, bundleRef, false)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pull bundle: %v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(bundleDir)
+	}()
+
+	a, err := AnalyzeBundleDir(bundleDir)
+	if err != nil {
+		return nil, err
+	}
+	if a.ImageSize, err = ImageSize(ctx, bundleRef); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// isDir returns true if path is an existing directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const analyzeCSV = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.1
+  annotations:
+    alm-examples: '%s'
+spec:
+  install:
+    strategy: deployment
+    spec:
+      clusterPermissions:
+      - serviceAccountName: default
+        rules:
+        - apiGroups: [cache.example.com]
+          resources: [memcacheds]
+          verbs: [get, list, watch]
+      permissions:
+      - serviceAccountName: default
+        rules:
+        - apiGroups: [cache.example.com]
+          resources: [memcacheds]
+          verbs: [watch, list, get]
+`
+
+const analyzeClusterRole = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: memcached-operator-metrics-reader
+rules:
+- nonResourceURLs: [/metrics]
+  verbs: [get]
+- apiGroups: [cache.example.com]
+  resources: [memcacheds]
+  verbs: [get, list, watch]
+`
+
+var _ = Describe("Analyze", func() {
+	Describe("AnalyzeBundleDir", func() {
+		var bundleDir string
+
+		writeManifests := func(almExamples string) {
+			manifestsDir := filepath.Join(bundleDir, "manifests")
+			Expect(os.MkdirAll(manifestsDir, 0755)).To(Succeed())
+			csv := strings.Replace(analyzeCSV, "%s", almExamples, 1)
+			Expect(ioutil.WriteFile(filepath.Join(manifestsDir, "memcached-operator.clusterserviceversion.yaml"),
+				[]byte(csv), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(manifestsDir, "metrics-reader_rbac.yaml"),
+				[]byte(analyzeClusterRole), 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			var err error
+			bundleDir, err = ioutil.TempDir("", "bundle-")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(bundleDir)).To(Succeed())
+		})
+
+		It("reports manifest sizes and duplicated RBAC rules", func() {
+			writeManifests("[]")
+			a, err := AnalyzeBundleDir(bundleDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Manifests).To(HaveLen(2))
+			Expect(a.Manifests[0].Kind).To(Equal("ClusterServiceVersion"))
+			Expect(a.ManifestsSize).To(Equal(a.Manifests[0].Size + a.Manifests[1].Size))
+			Expect(a.HasErrors()).To(BeFalse())
+
+			var messages []string
+			for _, f := range a.Findings {
+				messages = append(messages, f.Message)
+			}
+			Expect(messages).To(ConsistOf(
+				`CSV service account "default" is granted rule {apiGroups=cache.example.com resources=memcacheds `+
+					`verbs=get,list,watch} more than once`,
+				"rule {apiGroups=cache.example.com resources=memcacheds verbs=get,list,watch} "+
+					"is in both the CSV and a role manifest",
+			))
+		})
+		It("reports a large alm-examples annotation and an oversized bundle", func() {
+			writeManifests(`[{"data": "` + strings.Repeat("x", MaxUnpackedBundleSize) + `"}]`)
+			a, err := AnalyzeBundleDir(bundleDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.HasErrors()).To(BeTrue())
+
+			var messages []string
+			for _, f := range a.Findings {
+				messages = append(messages, f.Message)
+			}
+			Expect(messages).To(ContainElement(HavePrefix("CSV field metadata.annotations.alm-examples is")))
+			Expect(messages).To(ContainElement(HavePrefix("bundle manifests are")))
+		})
+	})
+
+	Describe("FormatSize", func() {
+		It("formats sizes in binary units", func() {
+			Expect(FormatSize(512)).To(Equal("512 B"))
+			Expect(FormatSize(1536)).To(Equal("1.5 KiB"))
+			Expect(FormatSize(MaxUnpackedBundleSize)).To(Equal("1.0 MiB"))
+		})
+	})
+})
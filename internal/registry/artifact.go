@@ -0,0 +1,159 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/containerd/archive"
+	"github.com/containerd/containerd/archive/compression"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ArtifactRefPrefix prefixes references to bundles pushed as OCI artifacts, ex. with ORAS,
+	// rather than as runnable images.
+	ArtifactRefPrefix = "oci://"
+
+	// artifactUnpackAnnotation is set by ORAS on layers that are gzipped tarballs of a directory.
+	artifactUnpackAnnotation = "io.deis.oras.content.unpack"
+)
+
+// IsArtifactRef returns true if ref refers to a bundle OCI artifact.
+func IsArtifactRef(ref string) bool {
+	return strings.HasPrefix(ref, ArtifactRefPrefix)
+}
+
+// ExtractBundleArtifact returns a bundle directory containing the layers of the OCI artifact
+// referenced by ref, which may be prefixed by ArtifactRefPrefix. Layers are written to the path
+// in their title annotation; directory layers, which ORAS pushes as gzipped tarballs, are unpacked.
+// Unlike ExtractBundleImage, the artifact's config and layer media types are not interpreted.
+func ExtractBundleArtifact(ctx context.Context, logger *log.Entry, ref string) (_ string, err error) {
+	if logger == nil {
+		logger = DiscardLogger()
+	}
+	ref = strings.TrimPrefix(ref, ArtifactRefPrefix)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	bundleDir, err := ioutil.TempDir(wd, "bundle-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(bundleDir)
+		}
+	}()
+	logger = logger.WithFields(log.Fields{"dir": bundleDir, "artifact": ref})
+
+	resolver, err := containerdregistry.NewResolver("", false, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating resolver: %v", err)
+	}
+	name, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("error resolving artifact %s: %v", ref, err)
+	}
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("error creating fetcher for artifact %s: %v", ref, err)
+	}
+
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, images.MediaTypeDockerSchema2Manifest:
+	default:
+		return "", fmt.Errorf("artifact %s has unsupported manifest media type %q", ref, desc.MediaType)
+	}
+	manifest := ocispec.Manifest{}
+	if err := fetchJSON(ctx, fetcher, desc, &manifest); err != nil {
+		return "", fmt.Errorf("error fetching artifact %s manifest: %v", ref, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		title := layer.Annotations[ocispec.AnnotationTitle]
+		if title == "" {
+			logger.Debugf("Skipping layer %s without a title", layer.Digest)
+			continue
+		}
+		if err := extractArtifactLayer(ctx, fetcher, layer, bundleDir, title); err != nil {
+			return "", fmt.Errorf("error extracting artifact %s layer %s: %v", ref, title, err)
+		}
+		logger.Debugf("Extracted layer %s", title)
+	}
+
+	// This should always work, but if it doesn't bundleDir is still valid.
+	if dir, err := filepath.Rel(wd, bundleDir); err == nil {
+		bundleDir = dir
+	}
+	return bundleDir, nil
+}
+
+// extractArtifactLayer writes layer to title under dir, or unpacks it into dir if it is a directory layer.
+func extractArtifactLayer(ctx context.Context, fetcher remotes.Fetcher, layer ocispec.Descriptor, dir, title string) error {
+	rc, err := fetcher.Fetch(ctx, layer)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if layer.Annotations[artifactUnpackAnnotation] == "true" {
+		decompressed, err := compression.DecompressStream(rc)
+		if err != nil {
+			return err
+		}
+		defer decompressed.Close()
+		_, err = archive.Apply(ctx, dir, decompressed)
+		return err
+	}
+
+	relPath := filepath.Clean(filepath.FromSlash(title))
+	if filepath.IsAbs(relPath) || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("layer title %q is not a relative path within the bundle", title)
+	}
+	path := filepath.Join(dir, relPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, rc)
+	return err
+}
+
+func fetchJSON(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor, v interface{}) error {
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// layerFetcher fetches layers from a map of digest to content.
+type layerFetcher map[string]string
+
+func (f layerFetcher) Fetch(_ context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	content, ok := f[desc.Digest.String()]
+	if !ok {
+		return nil, fmt.Errorf("layer %s not found", desc.Digest)
+	}
+	return ioutil.NopCloser(strings.NewReader(content)), nil
+}
+
+var _ = Describe("Artifact", func() {
+	Describe("IsArtifactRef", func() {
+		It("returns true for oci:// references", func() {
+			Expect(IsArtifactRef("oci://quay.io/example/memcached-operator-bundle:v0.0.1")).To(BeTrue())
+			Expect(IsArtifactRef("quay.io/example/memcached-operator-bundle:v0.0.1")).To(BeFalse())
+		})
+	})
+
+	Describe("extractArtifactLayer", func() {
+		const digest = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+		var (
+			dir     string
+			fetcher layerFetcher
+		)
+
+		newLayer := func(title string) ocispec.Descriptor {
+			return ocispec.Descriptor{
+				Digest:      digest,
+				Annotations: map[string]string{ocispec.AnnotationTitle: title},
+			}
+		}
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "artifact-")
+			Expect(err).NotTo(HaveOccurred())
+			fetcher = layerFetcher{digest: "annotations: {}\n"}
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("writes a file layer to its title", func() {
+			title := "metadata/annotations.yaml"
+			Expect(extractArtifactLayer(context.TODO(), fetcher, newLayer(title), dir, title)).To(Succeed())
+			b, err := ioutil.ReadFile(filepath.Join(dir, "metadata", "annotations.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal("annotations: {}\n"))
+		})
+		It("rejects titles outside the bundle directory", func() {
+			for _, title := range []string{"../annotations.yaml", "/etc/annotations.yaml", "metadata/../../annotations.yaml"} {
+				err := extractArtifactLayer(context.TODO(), fetcher, newLayer(title), dir, title)
+				Expect(err).To(MatchError(ContainSubstring("is not a relative path within the bundle")))
+			}
+		})
+	})
+})
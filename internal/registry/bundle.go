@@ -0,0 +1,66 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	log "github.com/sirupsen/logrus"
+)
+
+// LoadBundle returns the metadata labels and manifests of the bundle at bundleRef,
+// which is either a bundle directory on disk, a bundle OCI artifact prefixed by
+// ArtifactRefPrefix, or a bundle image. If bundleRef is an artifact or image, it is
+// pulled and unpacked into a temporary directory that is removed before LoadBundle returns.
+func LoadBundle(ctx context.Context, logger *log.Entry, bundleRef string) (Labels, *apimanifests.Bundle, error) {
+	bundlePath := bundleRef
+	if IsArtifactRef(bundleRef) {
+		var err error
+		if bundlePath, err = ExtractBundleArtifact(ctx, logger, bundleRef); err != nil {
+			return nil, nil, fmt.Errorf("pull bundle artifact: %v", err)
+		}
+		defer func() {
+			_ = os.RemoveAll(bundlePath)
+		}()
+	} else if info, err := os.Stat(bundleRef); err != nil || !info.IsDir() {
+		if bundlePath, err = ExtractBundleImage(ctx, logger, bundleRef, false); err != nil {
+			return nil, nil, fmt.Errorf("pull bundle image: %v", err)
+		}
+		defer func() {
+			_ = os.RemoveAll(bundlePath)
+		}()
+	}
+
+	labels, _, err := FindBundleMetadata(bundlePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load bundle metadata: %v", err)
+	}
+
+	relManifestsDir, ok := labels.GetManifestsDir()
+	if !ok {
+		return nil, nil, fmt.Errorf("manifests directory not defined in bundle metadata")
+	}
+	manifestsDir := filepath.Join(bundlePath, relManifestsDir)
+	bundle, err := apimanifests.GetBundleFromDir(manifestsDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load bundle: %v", err)
+	}
+
+	return labels, bundle, nil
+}
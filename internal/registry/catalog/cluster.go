@@ -0,0 +1,164 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	registryclient "github.com/operator-framework/operator-registry/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// catalogSourceLabel is set by OLM on the registry pods it creates for a CatalogSource.
+	catalogSourceLabel = "olm.catalogSource"
+	defaultGRPCPort    = "50051"
+)
+
+// LoadCatalogSource reads the catalog served by the registry pod of the CatalogSource key,
+// by port-forwarding to the pod's gRPC port.
+func LoadCatalogSource(ctx context.Context, cfg *rest.Config, c client.Client, key types.NamespacedName) (*Catalog, error) {
+	cs := &v1alpha1.CatalogSource{}
+	if err := c.Get(ctx, key, cs); err != nil {
+		return nil, fmt.Errorf("error getting catalog source %s: %v", key, err)
+	}
+	if cs.Spec.SourceType != v1alpha1.SourceTypeGrpc && cs.Spec.SourceType != v1alpha1.SourceTypeInternal &&
+		cs.Spec.SourceType != v1alpha1.SourceTypeConfigmap {
+		return nil, fmt.Errorf("catalog source %s has unsupported source type %q", key, cs.Spec.SourceType)
+	}
+	pod, err := findRegistryPod(ctx, c, cs)
+	if err != nil {
+		return nil, err
+	}
+	port := defaultGRPCPort
+	if _, p, err := net.SplitHostPort(cs.Address()); err == nil && p != "" {
+		port = p
+	}
+
+	localPort, stop, err := forwardPort(ctx, cfg, pod, port)
+	if err != nil {
+		return nil, fmt.Errorf("error port-forwarding to registry pod %s: %v", pod.GetName(), err)
+	}
+	defer stop()
+
+	rc, err := registryclient.NewClient(fmt.Sprintf("localhost:%d", localPort))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to registry pod %s: %v", pod.GetName(), err)
+	}
+	defer rc.Close()
+
+	it, err := rc.ListBundles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing bundles: %v", err)
+	}
+	cat := &Catalog{DefaultChannels: map[string]string{}}
+	for b := it.Next(); b != nil; b = it.Next() {
+		cat.Bundles = append(cat.Bundles, b)
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("error listing bundles: %v", err)
+	}
+	for _, b := range cat.Bundles {
+		if _, ok := cat.DefaultChannels[b.PackageName]; ok {
+			continue
+		}
+		pkg, err := rc.GetPackage(ctx, b.PackageName)
+		if err != nil {
+			return nil, fmt.Errorf("error getting package %s: %v", b.PackageName, err)
+		}
+		cat.DefaultChannels[b.PackageName] = pkg.GetDefaultChannelName()
+	}
+	return cat, nil
+}
+
+// findRegistryPod returns the running pod serving cs, which is either labeled by OLM or,
+// for catalog sources with a spec address like those created by 'run bundle', has the address' IP.
+func findRegistryPod(ctx context.Context, c client.Client, cs *v1alpha1.CatalogSource) (*corev1.Pod, error) {
+	pods := corev1.PodList{}
+	if err := c.List(ctx, &pods, client.InNamespace(cs.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("error listing pods: %v", err)
+	}
+	host := cs.Spec.Address
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	selector := labels.SelectorFromSet(labels.Set{catalogSourceLabel: cs.GetName()})
+	for i, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.GetLabels())) || (host != "" && pod.Status.PodIP == host) {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no running registry pod found for catalog source %s/%s",
+		cs.GetNamespace(), cs.GetName())
+}
+
+// forwardPort forwards a free local port to port on pod, returning the local port
+// and a function stopping the forward.
+func forwardPort(ctx context.Context, cfg *rest.Config, pod *corev1.Pod, port string) (uint16, func(), error) {
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return 0, nil, err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(cfg)
+	if err != nil {
+		return 0, nil, err
+	}
+	req := cs.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(pod.GetNamespace()).Name(pod.GetName()).SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopCh, readyCh := make(chan struct{}), make(chan struct{})
+	errOut := &strings.Builder{}
+	fw, err := portforward.New(dialer, []string{"0:" + port}, stopCh, readyCh, ioutil.Discard, errOut)
+	if err != nil {
+		return 0, nil, err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fw.ForwardPorts()
+	}()
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return 0, nil, fmt.Errorf("%v %s", err, errOut.String())
+	case <-ctx.Done():
+		close(stopCh)
+		return 0, nil, ctx.Err()
+	}
+
+	stop := func() { close(stopCh) }
+	ports, err := fw.GetPorts()
+	if err != nil {
+		stop()
+		return 0, nil, err
+	}
+	return ports[0].Local, stop, nil
+}
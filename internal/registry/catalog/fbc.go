@@ -0,0 +1,568 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/pkg/api"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+// Schemas of file-based catalog (FBC) blobs.
+const (
+	SchemaPackage      = "olm.package"
+	SchemaChannel      = "olm.channel"
+	SchemaBundle       = "olm.bundle"
+	SchemaDeprecations = "olm.deprecations"
+)
+
+// DeprecationsFileName is the name of the file 'catalog deprecate' writes a package's deprecations to,
+// if the catalog does not contain them yet.
+const DeprecationsFileName = "deprecations.yaml"
+
+// Deprecations is an olm.deprecations blob, which deprecates a package, or channels or bundles in it.
+type Deprecations struct {
+	Schema  string             `json:"schema"`
+	Package string             `json:"package"`
+	Entries []DeprecationEntry `json:"entries"`
+}
+
+// DeprecationEntry deprecates the package, channel, or bundle it references.
+type DeprecationEntry struct {
+	Reference PackageScopedReference `json:"reference"`
+	Message   string                 `json:"message"`
+}
+
+// PackageScopedReference references a package by schema, or a channel or bundle in it by schema and name.
+type PackageScopedReference struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name,omitempty"`
+}
+
+func (r PackageScopedReference) String() string {
+	if r.Name == "" {
+		return r.Schema
+	}
+	return fmt.Sprintf("%s %q", r.Schema, r.Name)
+}
+
+// Channel is an olm.channel blob, the bundles in which are upgraded along the edges of its entries.
+type Channel struct {
+	Schema     string          `json:"schema"`
+	Package    string          `json:"package"`
+	Name       string          `json:"name"`
+	Entries    []ChannelEntry  `json:"entries"`
+	Properties json.RawMessage `json:"properties,omitempty"`
+}
+
+// ChannelEntry is a bundle in a channel, and the bundles it upgrades from.
+type ChannelEntry struct {
+	Name      string   `json:"name"`
+	Replaces  string   `json:"replaces,omitempty"`
+	Skips     []string `json:"skips,omitempty"`
+	SkipRange string   `json:"skipRange,omitempty"`
+}
+
+// FBC is the packages of a file-based catalog directory, with the channels, bundles, and deprecations
+// of each. Other blobs are not read.
+type FBC struct {
+	packages map[string]*fbcPackage
+	// files are the blobs in each file of the catalog.
+	files map[string][]json.RawMessage
+}
+
+// blobRef is the index of a blob in a file.
+type blobRef struct {
+	path  string
+	index int
+}
+
+type fbcPackage struct {
+	// file contains the package's olm.package blob, at index blob.
+	file     string
+	blob     int
+	channels map[string]blobRef
+	bundles  map[string]blobRef
+
+	deprecations *Deprecations
+	// deprecationsFile contains the package's olm.deprecations blob, and deprecationsFileBlobs blobs in total.
+	deprecationsFile      string
+	deprecationsFileBlobs int
+}
+
+// fbcBlob is the part of an FBC blob used to index it.
+type fbcBlob struct {
+	Schema  string `json:"schema"`
+	Name    string `json:"name"`
+	Package string `json:"package"`
+}
+
+// LoadFBC reads the file-based catalog in dir, in which blobs are in JSON or YAML files.
+func LoadFBC(dir string) (*FBC, error) {
+	blobsByFile := map[string][]json.RawMessage{}
+	f := &FBC{packages: map[string]*fbcPackage{}, files: blobsByFile}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".json", ".yaml", ".yml":
+		default:
+			return nil
+		}
+		blobs, err := readBlobs(path)
+		if err != nil {
+			return err
+		}
+		blobsByFile[path] = blobs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Packages are indexed before the blobs of their channels, bundles, and deprecations.
+	paths := make([]string, 0, len(blobsByFile))
+	for path := range blobsByFile {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for i, raw := range blobsByFile[path] {
+			b := fbcBlob{}
+			if err := json.Unmarshal(raw, &b); err != nil {
+				return nil, fmt.Errorf("error reading blob in %s: %v", path, err)
+			}
+			if b.Schema != SchemaPackage {
+				continue
+			}
+			if _, exists := f.packages[b.Name]; exists {
+				return nil, fmt.Errorf("package %q is declared more than once", b.Name)
+			}
+			f.packages[b.Name] = &fbcPackage{
+				file:     path,
+				blob:     i,
+				channels: map[string]blobRef{},
+				bundles:  map[string]blobRef{},
+			}
+		}
+	}
+	for _, path := range paths {
+		for i, raw := range blobsByFile[path] {
+			if err := f.index(blobRef{path: path, index: i}, raw, len(blobsByFile[path])); err != nil {
+				return nil, fmt.Errorf("error reading blob in %s: %v", path, err)
+			}
+		}
+	}
+	if len(f.packages) == 0 {
+		return nil, fmt.Errorf("no %s blobs found in %s", SchemaPackage, dir)
+	}
+	return f, nil
+}
+
+// index adds the blob raw at ref, in a file containing blobs blobs, to its package.
+func (f *FBC) index(ref blobRef, raw json.RawMessage, blobs int) error {
+	b := fbcBlob{}
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return err
+	}
+	if b.Schema != SchemaChannel && b.Schema != SchemaBundle && b.Schema != SchemaDeprecations {
+		return nil
+	}
+	pkg, hasPkg := f.packages[b.Package]
+	if !hasPkg {
+		return fmt.Errorf("%s blob references unknown package %q", b.Schema, b.Package)
+	}
+	switch b.Schema {
+	case SchemaChannel:
+		if _, exists := pkg.channels[b.Name]; exists {
+			return fmt.Errorf("channel %q of package %q is declared more than once", b.Name, b.Package)
+		}
+		pkg.channels[b.Name] = ref
+	case SchemaBundle:
+		if _, exists := pkg.bundles[b.Name]; exists {
+			return fmt.Errorf("bundle %q of package %q is declared more than once", b.Name, b.Package)
+		}
+		pkg.bundles[b.Name] = ref
+	case SchemaDeprecations:
+		if pkg.deprecations != nil {
+			return fmt.Errorf("package %q has more than one %s blob", b.Package, SchemaDeprecations)
+		}
+		d := &Deprecations{}
+		if err := json.Unmarshal(raw, d); err != nil {
+			return err
+		}
+		pkg.deprecations, pkg.deprecationsFile, pkg.deprecationsFileBlobs = d, ref.path, blobs
+	}
+	return nil
+}
+
+// readBlobs reads the JSON objects or YAML documents in the file at path.
+func readBlobs(path string) ([]json.RawMessage, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var blobs []json.RawMessage
+	dec := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(b), 4096)
+	for {
+		raw := json.RawMessage{}
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return blobs, nil
+			}
+			return nil, fmt.Errorf("error decoding %s: %v", path, err)
+		}
+		if len(raw) != 0 && string(raw) != "null" {
+			blobs = append(blobs, raw)
+		}
+	}
+}
+
+// HasBundle returns true if pkg has an olm.bundle blob named name.
+func (f *FBC) HasBundle(pkg, name string) bool {
+	p, hasPkg := f.packages[pkg]
+	if !hasPkg {
+		return false
+	}
+	_, hasBundle := p.bundles[name]
+	return hasBundle
+}
+
+// Channel returns the channel name of pkg, or nil if pkg has no such channel.
+func (f *FBC) Channel(pkg, name string) (*Channel, error) {
+	p, hasPkg := f.packages[pkg]
+	if !hasPkg {
+		return nil, fmt.Errorf("package %q not found in catalog", pkg)
+	}
+	ref, hasChannel := p.channels[name]
+	if !hasChannel {
+		return nil, nil
+	}
+	ch := &Channel{}
+	if err := json.Unmarshal(f.files[ref.path][ref.index], ch); err != nil {
+		return nil, fmt.Errorf("error reading channel %q of package %q: %v", name, pkg, err)
+	}
+	return ch, nil
+}
+
+// AddChannelEntry adds entry to the channel of pkg, which is created in the file containing the package's
+// olm.package blob if it does not exist. Since replaced bundles must be in the channel, entry's replaces is
+// dropped if the channel does not contain it. The file is rewritten, so the keys of its other YAML blobs
+// may be reordered.
+func (f *FBC) AddChannelEntry(pkg, channel string, entry ChannelEntry) error {
+	ch, err := f.Channel(pkg, channel)
+	if err != nil {
+		return err
+	}
+	p := f.packages[pkg]
+	ref, hasChannel := p.channels[channel]
+	if !hasChannel {
+		ch = &Channel{Schema: SchemaChannel, Package: pkg, Name: channel}
+		ref = blobRef{path: p.file, index: len(f.files[p.file])}
+		f.files[p.file] = append(f.files[p.file], nil)
+		p.channels[channel] = ref
+	}
+
+	replaced := false
+	for _, e := range ch.Entries {
+		if e.Name == entry.Name {
+			return fmt.Errorf("bundle %q is already in channel %q of package %q", entry.Name, channel, pkg)
+		}
+		replaced = replaced || e.Name == entry.Replaces
+	}
+	if !replaced {
+		entry.Replaces = ""
+	}
+	ch.Entries = append(ch.Entries, entry)
+
+	raw, err := json.Marshal(ch)
+	if err != nil {
+		return err
+	}
+	f.files[ref.path][ref.index] = raw
+	return f.writeFile(ref.path)
+}
+
+// writeFile writes the blobs of the catalog file at path in the file's format.
+func (f *FBC) writeFile(path string) error {
+	buf := &bytes.Buffer{}
+	isJSON := strings.EqualFold(filepath.Ext(path), ".json")
+	for _, raw := range f.files[path] {
+		if isJSON {
+			if err := json.Indent(buf, raw, "", "    "); err != nil {
+				return err
+			}
+			buf.WriteString("\n")
+			continue
+		}
+		b, err := sigsyaml.JSONToYAML(raw)
+		if err != nil {
+			return err
+		}
+		buf.WriteString("---\n")
+		buf.Write(b)
+	}
+	if err := ioutil.WriteFile(path, buf.Bytes(), projutil.FileMode); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return nil
+}
+
+// Deprecations returns the deprecations of pkg, or nil if it has none.
+func (f *FBC) Deprecations(pkg string) *Deprecations {
+	if p, hasPkg := f.packages[pkg]; hasPkg {
+		return p.deprecations
+	}
+	return nil
+}
+
+// Deprecate deprecates the package pkg, or the channel or bundle in it referenced by ref, with message.
+// An existing deprecation of ref is replaced. The package's deprecations are written to the catalog.
+func (f *FBC) Deprecate(pkg string, ref PackageScopedReference, message string) error {
+	p, err := f.reference(pkg, ref)
+	if err != nil {
+		return err
+	}
+	if p.deprecations == nil {
+		p.deprecations = &Deprecations{Schema: SchemaDeprecations, Package: pkg}
+	}
+	entry := DeprecationEntry{Reference: ref, Message: message}
+	replaced := false
+	for i, e := range p.deprecations.Entries {
+		if e.Reference == ref {
+			p.deprecations.Entries[i], replaced = entry, true
+		}
+	}
+	if !replaced {
+		p.deprecations.Entries = append(p.deprecations.Entries, entry)
+	}
+	return p.writeDeprecations()
+}
+
+// Undeprecate removes the deprecation of the package pkg, or the channel or bundle in it referenced by ref.
+func (f *FBC) Undeprecate(pkg string, ref PackageScopedReference) error {
+	p, err := f.reference(pkg, ref)
+	if err != nil {
+		return err
+	}
+	if p.deprecations == nil {
+		return fmt.Errorf("%s of package %q is not deprecated", ref, pkg)
+	}
+	entries := p.deprecations.Entries[:0]
+	for _, e := range p.deprecations.Entries {
+		if e.Reference != ref {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == len(p.deprecations.Entries) {
+		return fmt.Errorf("%s of package %q is not deprecated", ref, pkg)
+	}
+	p.deprecations.Entries = entries
+	return p.writeDeprecations()
+}
+
+// reference returns pkg if it contains ref.
+func (f *FBC) reference(pkg string, ref PackageScopedReference) (*fbcPackage, error) {
+	p, hasPkg := f.packages[pkg]
+	if !hasPkg {
+		return nil, fmt.Errorf("package %q not found in catalog", pkg)
+	}
+	var found bool
+	switch ref.Schema {
+	case SchemaPackage:
+		found = ref.Name == ""
+	case SchemaChannel:
+		_, found = p.channels[ref.Name]
+	case SchemaBundle:
+		_, found = p.bundles[ref.Name]
+	default:
+		return nil, fmt.Errorf("cannot deprecate %s, must reference one of: [%s, %s, %s]",
+			ref, SchemaPackage, SchemaChannel, SchemaBundle)
+	}
+	if !found {
+		return nil, fmt.Errorf("%s not found in package %q", ref, pkg)
+	}
+	return p, nil
+}
+
+// writeDeprecations writes the deprecations of p to the file containing them, or to DeprecationsFileName
+// next to its olm.package blob. Deprecations without entries are removed.
+func (p *fbcPackage) writeDeprecations() error {
+	if p.deprecationsFile == "" {
+		p.deprecationsFile, p.deprecationsFileBlobs = filepath.Join(filepath.Dir(p.file), DeprecationsFileName), 1
+		if _, err := os.Stat(p.deprecationsFile); err == nil {
+			return fmt.Errorf("cannot write deprecations of package %q: %s exists", p.deprecations.Package,
+				p.deprecationsFile)
+		}
+	}
+	// Rewriting a file would reformat the other blobs in it.
+	if p.deprecationsFileBlobs != 1 {
+		return fmt.Errorf("cannot edit deprecations of package %q in %s, which contains other blobs: "+
+			"move its %s blob to a file of its own", p.deprecations.Package, p.deprecationsFile, SchemaDeprecations)
+	}
+	if len(p.deprecations.Entries) == 0 {
+		return os.Remove(p.deprecationsFile)
+	}
+
+	var b []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(p.deprecationsFile), ".json") {
+		if b, err = json.MarshalIndent(p.deprecations, "", "    "); err == nil {
+			b = append(b, '\n')
+		}
+	} else {
+		b, err = sigsyaml.Marshal(p.deprecations)
+	}
+	if err != nil {
+		return fmt.Errorf("error encoding deprecations of package %q: %v", p.deprecations.Package, err)
+	}
+	if err := ioutil.WriteFile(p.deprecationsFile, b, projutil.FileMode); err != nil {
+		return fmt.Errorf("error writing %s: %v", p.deprecationsFile, err)
+	}
+	return nil
+}
+
+// Property types of olm.bundle blobs read by Catalog.
+const (
+	propertyPackage         = "olm.package"
+	propertyGVK             = "olm.gvk"
+	propertyPackageRequired = "olm.package.required"
+	propertyGVKRequired     = "olm.gvk.required"
+	propertyConstraint      = "olm.constraint"
+)
+
+// fbcPackageBlob is the part of an olm.package blob read by Catalog.
+type fbcPackageBlob struct {
+	DefaultChannel string `json:"defaultChannel"`
+}
+
+// fbcBundle is the part of an olm.bundle blob read by Catalog.
+type fbcBundle struct {
+	Name       string        `json:"name"`
+	Package    string        `json:"package"`
+	Image      string        `json:"image"`
+	Properties []fbcProperty `json:"properties"`
+}
+
+type fbcProperty struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Catalog returns the bundles in the channels of f, in the form of a catalog read from an index database:
+// olm.gvk properties are provided APIs, and olm.gvk.required, olm.package.required, and olm.constraint
+// properties are dependencies.
+func (f *FBC) Catalog() (*Catalog, error) {
+	c := &Catalog{DefaultChannels: map[string]string{}}
+	names := make([]string, 0, len(f.packages))
+	for name := range f.packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := f.packages[name]
+		pb := fbcPackageBlob{}
+		if err := json.Unmarshal(f.files[p.file][p.blob], &pb); err != nil {
+			return nil, fmt.Errorf("error reading package %q: %v", name, err)
+		}
+		c.DefaultChannels[name] = pb.DefaultChannel
+
+		channels := make([]string, 0, len(p.channels))
+		for channel := range p.channels {
+			channels = append(channels, channel)
+		}
+		sort.Strings(channels)
+		for _, channel := range channels {
+			ch, err := f.Channel(name, channel)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range ch.Entries {
+				ref, hasBundle := p.bundles[entry.Name]
+				if !hasBundle {
+					return nil, fmt.Errorf("channel %q of package %q has unknown bundle %q", channel, name, entry.Name)
+				}
+				b, err := newAPIBundle(f.files[ref.path][ref.index])
+				if err != nil {
+					return nil, fmt.Errorf("error reading bundle %q of package %q: %v", entry.Name, name, err)
+				}
+				b.ChannelName = channel
+				b.Replaces, b.Skips, b.SkipRange = entry.Replaces, entry.Skips, entry.SkipRange
+				c.Bundles = append(c.Bundles, b)
+			}
+		}
+	}
+	return c, nil
+}
+
+// newAPIBundle returns the registry API form of the olm.bundle blob raw.
+func newAPIBundle(raw json.RawMessage) (*api.Bundle, error) {
+	fb := fbcBundle{}
+	if err := json.Unmarshal(raw, &fb); err != nil {
+		return nil, err
+	}
+	b := &api.Bundle{CsvName: fb.Name, PackageName: fb.Package, BundlePath: fb.Image}
+	for _, prop := range fb.Properties {
+		b.Properties = append(b.Properties, &api.Property{Type: prop.Type, Value: string(prop.Value)})
+		switch prop.Type {
+		case propertyPackage:
+			var v struct {
+				Version string `json:"version"`
+			}
+			if err := json.Unmarshal(prop.Value, &v); err != nil {
+				return nil, fmt.Errorf("invalid %s property: %v", prop.Type, err)
+			}
+			b.Version = v.Version
+		case propertyGVK:
+			gvk := &api.GroupVersionKind{}
+			if err := json.Unmarshal(prop.Value, gvk); err != nil {
+				return nil, fmt.Errorf("invalid %s property: %v", prop.Type, err)
+			}
+			b.ProvidedApis = append(b.ProvidedApis, gvk)
+		case propertyGVKRequired:
+			b.Dependencies = append(b.Dependencies, &api.Dependency{Type: dependencyTypeGVK, Value: string(prop.Value)})
+		case propertyPackageRequired:
+			var v struct {
+				PackageName  string `json:"packageName"`
+				VersionRange string `json:"versionRange"`
+			}
+			if err := json.Unmarshal(prop.Value, &v); err != nil {
+				return nil, fmt.Errorf("invalid %s property: %v", prop.Type, err)
+			}
+			value, err := json.Marshal(map[string]string{"packageName": v.PackageName, "version": v.VersionRange})
+			if err != nil {
+				return nil, err
+			}
+			b.Dependencies = append(b.Dependencies, &api.Dependency{Type: dependencyTypePackage, Value: string(value)})
+		case propertyConstraint:
+			b.Dependencies = append(b.Dependencies, &api.Dependency{Type: prop.Type, Value: string(prop.Value)})
+		}
+	}
+	return b, nil
+}
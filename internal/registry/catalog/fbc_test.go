@@ -0,0 +1,184 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const memcachedFBC = `---
+schema: olm.package
+name: memcached
+defaultChannel: stable
+---
+schema: olm.channel
+package: memcached
+name: alpha
+entries:
+- name: memcached.v0.0.1
+---
+schema: olm.channel
+package: memcached
+name: stable
+entries:
+- name: memcached.v0.0.1
+---
+schema: olm.bundle
+package: memcached
+name: memcached.v0.0.1
+image: quay.io/example/memcached-bundle:v0.0.1
+`
+
+var _ = Describe("FBC", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "fbc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(dir, "memcached"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "memcached", "index.yaml"), []byte(memcachedFBC), 0644)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	load := func() *FBC {
+		f, err := LoadFBC(dir)
+		Expect(err).NotTo(HaveOccurred())
+		return f
+	}
+	channel := PackageScopedReference{Schema: SchemaChannel, Name: "alpha"}
+	bundle := PackageScopedReference{Schema: SchemaBundle, Name: "memcached.v0.0.1"}
+
+	It("writes deprecations next to the package and reads them back", func() {
+		Expect(load().Deprecate("memcached", channel, "use stable")).To(Succeed())
+		Expect(load().Deprecate("memcached", bundle, "upgrade")).To(Succeed())
+		Expect(filepath.Join(dir, "memcached", DeprecationsFileName)).To(BeAnExistingFile())
+		Expect(load().Deprecations("memcached")).To(Equal(&Deprecations{
+			Schema:  SchemaDeprecations,
+			Package: "memcached",
+			Entries: []DeprecationEntry{
+				{Reference: channel, Message: "use stable"},
+				{Reference: bundle, Message: "upgrade"},
+			},
+		}))
+	})
+	It("replaces an existing deprecation of a reference", func() {
+		Expect(load().Deprecate("memcached", channel, "use stable")).To(Succeed())
+		Expect(load().Deprecate("memcached", channel, "use stable instead")).To(Succeed())
+		Expect(load().Deprecations("memcached").Entries).To(Equal([]DeprecationEntry{
+			{Reference: channel, Message: "use stable instead"},
+		}))
+	})
+	It("removes deprecations, and the file once empty", func() {
+		pkg := PackageScopedReference{Schema: SchemaPackage}
+		Expect(load().Deprecate("memcached", pkg, "moved to memcached-operator")).To(Succeed())
+		Expect(load().Deprecate("memcached", channel, "use stable")).To(Succeed())
+		Expect(load().Undeprecate("memcached", pkg)).To(Succeed())
+		Expect(load().Deprecations("memcached").Entries).To(Equal([]DeprecationEntry{
+			{Reference: channel, Message: "use stable"},
+		}))
+		Expect(load().Undeprecate("memcached", channel)).To(Succeed())
+		Expect(filepath.Join(dir, "memcached", DeprecationsFileName)).NotTo(BeAnExistingFile())
+		Expect(load().Deprecations("memcached")).To(BeNil())
+		Expect(load().Undeprecate("memcached", channel)).To(MatchError(`olm.channel "alpha" of package "memcached" is not deprecated`))
+	})
+	It("edits deprecations in a JSON file", func() {
+		path := filepath.Join(dir, "memcached", "deprecations.json")
+		Expect(ioutil.WriteFile(path, []byte(`{"schema":"olm.deprecations","package":"memcached","entries":[]}`), 0644)).To(Succeed())
+		Expect(load().Deprecate("memcached", bundle, "upgrade")).To(Succeed())
+		b, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring(`"message": "upgrade"`))
+		Expect(filepath.Join(dir, "memcached", DeprecationsFileName)).NotTo(BeAnExistingFile())
+	})
+	It("rejects references not in the package", func() {
+		Expect(load().Deprecate("etcd", channel, "")).To(MatchError(`package "etcd" not found in catalog`))
+		Expect(load().Deprecate("memcached", PackageScopedReference{Schema: SchemaChannel, Name: "beta"}, "")).
+			To(MatchError(`olm.channel "beta" not found in package "memcached"`))
+		Expect(load().Deprecate("memcached", PackageScopedReference{Schema: "olm.gvk"}, "")).
+			To(MatchError(ContainSubstring("cannot deprecate olm.gvk")))
+	})
+	It("does not rewrite deprecations sharing a file with other blobs", func() {
+		path := filepath.Join(dir, "memcached", "index.yaml")
+		Expect(ioutil.WriteFile(path, []byte(memcachedFBC+"---\nschema: olm.deprecations\npackage: memcached\n"), 0644)).
+			To(Succeed())
+		Expect(load().Deprecate("memcached", bundle, "upgrade")).To(MatchError(ContainSubstring("contains other blobs")))
+	})
+	It("adds entries to channels, creating them in the package's file", func() {
+		f := load()
+		Expect(f.AddChannelEntry("memcached", "stable", ChannelEntry{Name: "memcached.v0.0.2", Replaces: "memcached.v0.0.1"})).
+			To(Succeed())
+		Expect(f.AddChannelEntry("memcached", "fast", ChannelEntry{Name: "memcached.v0.0.2", Replaces: "memcached.v0.0.1"})).
+			To(Succeed())
+		Expect(f.AddChannelEntry("memcached", "fast", ChannelEntry{Name: "memcached.v0.0.2"})).
+			To(MatchError(`bundle "memcached.v0.0.2" is already in channel "fast" of package "memcached"`))
+
+		f = load()
+		stable, err := f.Channel("memcached", "stable")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stable.Entries).To(Equal([]ChannelEntry{
+			{Name: "memcached.v0.0.1"},
+			{Name: "memcached.v0.0.2", Replaces: "memcached.v0.0.1"},
+		}))
+		fast, err := f.Channel("memcached", "fast")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fast).To(Equal(&Channel{Schema: SchemaChannel, Package: "memcached", Name: "fast",
+			Entries: []ChannelEntry{{Name: "memcached.v0.0.2"}}}))
+		Expect(f.Channel("memcached", "beta")).To(BeNil())
+		_, err = f.Channel("etcd", "alpha")
+		Expect(err).To(MatchError(`package "etcd" not found in catalog`))
+	})
+	It("rewrites JSON files as JSON", func() {
+		path := filepath.Join(dir, "etcd.json")
+		Expect(ioutil.WriteFile(path, []byte(`{"schema":"olm.package","name":"etcd"}
+{"schema":"olm.channel","package":"etcd","name":"alpha","entries":[{"name":"etcd.v0.9.2"}]}`), 0644)).To(Succeed())
+		Expect(load().AddChannelEntry("etcd", "alpha", ChannelEntry{Name: "etcd.v0.9.4", Replaces: "etcd.v0.9.2"})).
+			To(Succeed())
+		b, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(`{
+    "schema": "olm.package",
+    "name": "etcd"
+}
+{
+    "schema": "olm.channel",
+    "package": "etcd",
+    "name": "alpha",
+    "entries": [
+        {
+            "name": "etcd.v0.9.2"
+        },
+        {
+            "name": "etcd.v0.9.4",
+            "replaces": "etcd.v0.9.2"
+        }
+    ]
+}
+`))
+	})
+	It("fails on blobs of unknown packages", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "etcd.json"), []byte(`{"schema":"olm.bundle","package":"etcd","name":"etcd.v0.9.4"}`), 0644)).
+			To(Succeed())
+		_, err := LoadFBC(dir)
+		Expect(err).To(MatchError(ContainSubstring(`olm.bundle blob references unknown package "etcd"`)))
+	})
+})
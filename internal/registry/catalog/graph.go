@@ -0,0 +1,308 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package catalog reads the packages, channels, and upgrade graphs of operator catalogs,
+// and renders them as DOT or Mermaid graphs for review.
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+// Edge types between bundles, and from bundles to their dependencies.
+const (
+	EdgeReplaces  = "replaces"
+	EdgeSkips     = "skips"
+	EdgeDependsOn = "depends"
+)
+
+// Dependency types declared in bundle metadata.
+const (
+	dependencyTypePackage = "olm.package"
+	dependencyTypeGVK     = "olm.gvk"
+)
+
+// Catalog is the content of a catalog a Graph is built from.
+type Catalog struct {
+	// Bundles are the catalog's bundles, listed once per channel they are in.
+	Bundles []*api.Bundle
+	// DefaultChannels maps package names to their default channel.
+	DefaultChannels map[string]string
+}
+
+// Graph is the upgrade graph and dependencies of each package in a catalog.
+type Graph struct {
+	Packages []*Package
+}
+
+// Package is a package's channels and the bundles in them.
+type Package struct {
+	Name           string
+	DefaultChannel string
+	Channels       []string
+	// Bundles are sorted by version, then name. Bundles that are skipped or replaced
+	// but not in the catalog are included with Missing set.
+	Bundles []*Bundle
+	Edges   []Edge
+}
+
+// Bundle is a node in a package's upgrade graph.
+type Bundle struct {
+	Name      string
+	Version   string
+	SkipRange string
+	Channels  []string
+	// Missing is true if the bundle is referenced by another bundle but not in the catalog.
+	Missing bool
+}
+
+// Edge is a replaces or skips edge from a bundle to an older bundle, or a dependency edge
+// from a bundle to a description of the dependency.
+type Edge struct {
+	From, To, Type string
+}
+
+// NewGraph builds a Graph from c.
+func NewGraph(c Catalog) *Graph {
+	pkgs := map[string]*Package{}
+	bundles := map[string]map[string]*Bundle{}
+	edges := map[string]map[Edge]struct{}{}
+
+	getBundle := func(pkg *Package, name string) *Bundle {
+		b, ok := bundles[pkg.Name][name]
+		if !ok {
+			b = &Bundle{Name: name, Missing: true}
+			bundles[pkg.Name][name] = b
+		}
+		return b
+	}
+	for _, ab := range c.Bundles {
+		pkg, ok := pkgs[ab.PackageName]
+		if !ok {
+			pkg = &Package{Name: ab.PackageName, DefaultChannel: c.DefaultChannels[ab.PackageName]}
+			pkgs[ab.PackageName] = pkg
+			bundles[pkg.Name] = map[string]*Bundle{}
+			edges[pkg.Name] = map[Edge]struct{}{}
+		}
+		b := getBundle(pkg, ab.CsvName)
+		b.Missing = false
+		b.Version = ab.Version
+		b.SkipRange = ab.SkipRange
+		if ab.ChannelName != "" && !contains(b.Channels, ab.ChannelName) {
+			b.Channels = append(b.Channels, ab.ChannelName)
+		}
+		if ab.ChannelName != "" && !contains(pkg.Channels, ab.ChannelName) {
+			pkg.Channels = append(pkg.Channels, ab.ChannelName)
+		}
+
+		if ab.Replaces != "" {
+			getBundle(pkg, ab.Replaces)
+			edges[pkg.Name][Edge{From: ab.CsvName, To: ab.Replaces, Type: EdgeReplaces}] = struct{}{}
+		}
+		for _, skip := range ab.Skips {
+			getBundle(pkg, skip)
+			edges[pkg.Name][Edge{From: ab.CsvName, To: skip, Type: EdgeSkips}] = struct{}{}
+		}
+		for _, dep := range ab.Dependencies {
+			edges[pkg.Name][Edge{From: ab.CsvName, To: describeDependency(dep), Type: EdgeDependsOn}] = struct{}{}
+		}
+	}
+
+	g := &Graph{}
+	for _, pkg := range pkgs {
+		for _, b := range bundles[pkg.Name] {
+			sort.Strings(b.Channels)
+			pkg.Bundles = append(pkg.Bundles, b)
+		}
+		sort.Slice(pkg.Bundles, func(i, j int) bool {
+			return bundleLess(pkg.Bundles[i], pkg.Bundles[j])
+		})
+		for e := range edges[pkg.Name] {
+			pkg.Edges = append(pkg.Edges, e)
+		}
+		sort.Slice(pkg.Edges, func(i, j int) bool {
+			a, b := pkg.Edges[i], pkg.Edges[j]
+			if a.From != b.From {
+				return a.From < b.From
+			}
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+			return a.To < b.To
+		})
+		sort.Strings(pkg.Channels)
+		g.Packages = append(g.Packages, pkg)
+	}
+	sort.Slice(g.Packages, func(i, j int) bool {
+		return g.Packages[i].Name < g.Packages[j].Name
+	})
+	return g
+}
+
+// bundleLess orders bundles by semantic version, then name, with missing and unversioned bundles first.
+func bundleLess(a, b *Bundle) bool {
+	va, errA := semver.ParseTolerant(a.Version)
+	vb, errB := semver.ParseTolerant(b.Version)
+	switch {
+	case errA == nil && errB == nil && !va.EQ(vb):
+		return va.LT(vb)
+	case errA != nil && errB == nil:
+		return true
+	case errA == nil && errB != nil:
+		return false
+	}
+	return a.Name < b.Name
+}
+
+// describeDependency returns a short description of dep, ex. "package etcd (>=0.9.0)".
+func describeDependency(dep *api.Dependency) string {
+	switch dep.Type {
+	case dependencyTypePackage:
+		var v struct {
+			PackageName string `json:"packageName"`
+			Version     string `json:"version"`
+		}
+		if err := json.Unmarshal([]byte(dep.Value), &v); err == nil {
+			return fmt.Sprintf("package %s (%s)", v.PackageName, v.Version)
+		}
+	case dependencyTypeGVK:
+		var v struct {
+			Group   string `json:"group"`
+			Version string `json:"version"`
+			Kind    string `json:"kind"`
+		}
+		if err := json.Unmarshal([]byte(dep.Value), &v); err == nil {
+			return fmt.Sprintf("api %s/%s/%s", v.Group, v.Version, v.Kind)
+		}
+	}
+	return fmt.Sprintf("%s %s", dep.Type, dep.Value)
+}
+
+// WriteDOT writes g to w in the Graphviz DOT language, with one cluster per package.
+func WriteDOT(w io.Writer, g *Graph) error {
+	b := &strings.Builder{}
+	b.WriteString("digraph catalog {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, pkg := range g.Packages {
+		fmt.Fprintf(b, "  subgraph %q {\n    label=%q;\n", "cluster_"+pkg.Name, packageLabel(pkg))
+		for _, bundle := range pkg.Bundles {
+			style := ""
+			if bundle.Missing {
+				style = ", style=dashed"
+			}
+			fmt.Fprintf(b, "    %q [label=%q%s];\n", nodeID(pkg, bundle.Name), bundleLabel(bundle, "\n"), style)
+		}
+		b.WriteString("  }\n")
+		for _, e := range pkg.Edges {
+			switch e.Type {
+			case EdgeReplaces:
+				fmt.Fprintf(b, "  %q -> %q [label=%q];\n", nodeID(pkg, e.From), nodeID(pkg, e.To), e.Type)
+			case EdgeSkips:
+				fmt.Fprintf(b, "  %q -> %q [label=%q, style=dashed];\n", nodeID(pkg, e.From), nodeID(pkg, e.To), e.Type)
+			case EdgeDependsOn:
+				fmt.Fprintf(b, "  %q [label=%q, shape=ellipse];\n", dependencyID(e.To), e.To)
+				fmt.Fprintf(b, "  %q -> %q [label=%q, style=dotted];\n", nodeID(pkg, e.From), dependencyID(e.To), e.Type)
+			}
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid writes g to w as a Mermaid flowchart, with one subgraph per package.
+func WriteMermaid(w io.Writer, g *Graph) error {
+	ids := map[string]string{}
+	id := func(key string) string {
+		if _, ok := ids[key]; !ok {
+			ids[key] = fmt.Sprintf("n%d", len(ids))
+		}
+		return ids[key]
+	}
+
+	b := &strings.Builder{}
+	b.WriteString("graph LR\n")
+	for i, pkg := range g.Packages {
+		fmt.Fprintf(b, "  subgraph p%d[%q]\n", i, packageLabel(pkg))
+		for _, bundle := range pkg.Bundles {
+			fmt.Fprintf(b, "    %s[%q]\n", id(nodeID(pkg, bundle.Name)), bundleLabel(bundle, "<br/>"))
+		}
+		b.WriteString("  end\n")
+	}
+	for _, pkg := range g.Packages {
+		for _, e := range pkg.Edges {
+			switch e.Type {
+			case EdgeReplaces:
+				fmt.Fprintf(b, "  %s -->|%s| %s\n", id(nodeID(pkg, e.From)), e.Type, id(nodeID(pkg, e.To)))
+			case EdgeSkips:
+				fmt.Fprintf(b, "  %s -.->|%s| %s\n", id(nodeID(pkg, e.From)), e.Type, id(nodeID(pkg, e.To)))
+			case EdgeDependsOn:
+				depID := id(dependencyID(e.To))
+				fmt.Fprintf(b, "  %s([%q])\n", depID, e.To)
+				fmt.Fprintf(b, "  %s -.->|%s| %s\n", id(nodeID(pkg, e.From)), e.Type, depID)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func packageLabel(pkg *Package) string {
+	if pkg.DefaultChannel == "" {
+		return pkg.Name
+	}
+	return fmt.Sprintf("%s (default channel: %s)", pkg.Name, pkg.DefaultChannel)
+}
+
+// bundleLabel returns a label for b with lines separated by sep.
+func bundleLabel(b *Bundle, sep string) string {
+	lines := []string{b.Name}
+	if b.Missing {
+		lines = append(lines, "(not in catalog)")
+	}
+	if b.Version != "" {
+		lines = append(lines, "version: "+b.Version)
+	}
+	if len(b.Channels) != 0 {
+		lines = append(lines, "channels: "+strings.Join(b.Channels, ", "))
+	}
+	if b.SkipRange != "" {
+		lines = append(lines, "skipRange: "+b.SkipRange)
+	}
+	return strings.Join(lines, sep)
+}
+
+// nodeID is unique across packages, since bundle names need not be.
+func nodeID(pkg *Package, bundle string) string {
+	return pkg.Name + "/" + bundle
+}
+
+func dependencyID(description string) string {
+	return "dependency/" + description
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
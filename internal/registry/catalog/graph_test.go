@@ -0,0 +1,88 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+var _ = Describe("Graph", func() {
+	var g *Graph
+
+	BeforeEach(func() {
+		g = NewGraph(Catalog{
+			Bundles: []*api.Bundle{
+				{CsvName: "memcached.v0.0.2", PackageName: "memcached", ChannelName: "alpha", Version: "0.0.2",
+					Replaces: "memcached.v0.0.1", Skips: []string{"memcached.v0.0.0"}},
+				{CsvName: "memcached.v0.0.2", PackageName: "memcached", ChannelName: "stable", Version: "0.0.2",
+					Replaces: "memcached.v0.0.1", Skips: []string{"memcached.v0.0.0"}},
+				{CsvName: "memcached.v0.0.1", PackageName: "memcached", ChannelName: "stable", Version: "0.0.1",
+					Dependencies: []*api.Dependency{
+						{Type: "olm.package", Value: `{"packageName":"etcd","version":">=0.9.0"}`},
+						{Type: "olm.gvk", Value: `{"group":"etcd.database.coreos.com","version":"v1beta2","kind":"EtcdCluster"}`},
+					}},
+				{CsvName: "etcd.v0.9.4", PackageName: "etcd", ChannelName: "alpha", Version: "0.9.4"},
+			},
+			DefaultChannels: map[string]string{"memcached": "stable", "etcd": "alpha"},
+		})
+	})
+
+	It("groups bundles by package and merges their channels", func() {
+		Expect(g.Packages).To(HaveLen(2))
+		Expect(g.Packages[0].Name).To(Equal("etcd"))
+		pkg := g.Packages[1]
+		Expect(pkg.Name).To(Equal("memcached"))
+		Expect(pkg.DefaultChannel).To(Equal("stable"))
+		Expect(pkg.Channels).To(Equal([]string{"alpha", "stable"}))
+		Expect(pkg.Bundles).To(HaveLen(3))
+		Expect(pkg.Bundles[0]).To(Equal(&Bundle{Name: "memcached.v0.0.0", Missing: true}))
+		Expect(pkg.Bundles[1].Name).To(Equal("memcached.v0.0.1"))
+		Expect(pkg.Bundles[2].Channels).To(Equal([]string{"alpha", "stable"}))
+	})
+	It("deduplicates edges and describes dependencies", func() {
+		Expect(g.Packages[1].Edges).To(Equal([]Edge{
+			{From: "memcached.v0.0.1", To: "api etcd.database.coreos.com/v1beta2/EtcdCluster", Type: EdgeDependsOn},
+			{From: "memcached.v0.0.1", To: "package etcd (>=0.9.0)", Type: EdgeDependsOn},
+			{From: "memcached.v0.0.2", To: "memcached.v0.0.1", Type: EdgeReplaces},
+			{From: "memcached.v0.0.2", To: "memcached.v0.0.0", Type: EdgeSkips},
+		}))
+	})
+	It("writes DOT", func() {
+		b := &bytes.Buffer{}
+		Expect(WriteDOT(b, g)).To(Succeed())
+		Expect(b.String()).To(HavePrefix("digraph catalog {\n"))
+		Expect(b.String()).To(ContainSubstring(`subgraph "cluster_memcached" {` + "\n" +
+			`    label="memcached (default channel: stable)";`))
+		Expect(b.String()).To(ContainSubstring(
+			`"memcached/memcached.v0.0.0" [label="memcached.v0.0.0\n(not in catalog)", style=dashed];`))
+		Expect(b.String()).To(ContainSubstring(
+			`"memcached/memcached.v0.0.2" -> "memcached/memcached.v0.0.1" [label="replaces"];`))
+		Expect(b.String()).To(ContainSubstring(
+			`"memcached/memcached.v0.0.1" -> "dependency/package etcd (>=0.9.0)" [label="depends", style=dotted];`))
+	})
+	It("writes Mermaid", func() {
+		b := &bytes.Buffer{}
+		Expect(WriteMermaid(b, g)).To(Succeed())
+		Expect(b.String()).To(HavePrefix("graph LR\n  subgraph p0[\"etcd (default channel: alpha)\"]\n" +
+			"    n0[\"etcd.v0.9.4<br/>version: 0.9.4<br/>channels: alpha\"]\n  end\n"))
+		Expect(b.String()).To(ContainSubstring("  n3 -->|replaces| n2\n"))
+		Expect(b.String()).To(ContainSubstring("  n3 -.->|skips| n1\n"))
+		Expect(b.String()).To(ContainSubstring("  n5([\"package etcd (>=0.9.0)\"])\n"))
+	})
+})
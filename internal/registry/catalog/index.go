@@ -0,0 +1,82 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/operator-framework/operator-registry/pkg/containertools"
+	registrylib "github.com/operator-framework/operator-registry/pkg/lib/registry"
+	pregistry "github.com/operator-framework/operator-registry/pkg/registry"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+)
+
+// Index build context layout.
+const (
+	IndexDockerfileName = "index.Dockerfile"
+	indexDatabaseDir    = "database"
+	indexDatabaseFile   = "index.db"
+)
+
+// IndexOptions configure an index image's build context.
+type IndexOptions struct {
+	// Bundles are the bundle images added to the index.
+	Bundles []string
+	// BinaryImage is the image containing the opm binary serving the index.
+	// The operator-registry default is used if unset.
+	BinaryImage string
+	// Mode is the channel update mode, one of [replaces, semver, semver-skippatch].
+	Mode string
+	// SkipTLS pulls bundles from registries without verifying TLS certificates.
+	SkipTLS bool
+}
+
+// GenerateIndex writes the build context of an index image serving opts.Bundles to dir: a database
+// of the bundles, and a Dockerfile named IndexDockerfileName. Bundles are pulled without a container tool,
+// so the context can be built by any builder on any host.
+func GenerateIndex(logger *log.Entry, dir string, opts IndexOptions) error {
+	mode, err := pregistry.GetModeFromString(opts.Mode)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, indexDatabaseDir), projutil.DirMode); err != nil {
+		return err
+	}
+	err = registrylib.NewRegistryAdder(logger).AddToRegistry(registrylib.AddToRegistryRequest{
+		Bundles:       opts.Bundles,
+		InputDatabase: filepath.Join(dir, indexDatabaseDir, indexDatabaseFile),
+		Mode:          mode,
+		SkipTLS:       opts.SkipTLS,
+		ContainerTool: containertools.NoneTool,
+	})
+	if err != nil {
+		return fmt.Errorf("error adding bundles to index: %v", err)
+	}
+
+	// Dockerfile paths are always slash-separated, including on Windows.
+	dockerfile := containertools.NewDockerfileGenerator(logger).
+		GenerateIndexDockerfile(opts.BinaryImage, path.Join(indexDatabaseDir, indexDatabaseFile))
+	dockerfilePath := filepath.Join(dir, IndexDockerfileName)
+	if err := ioutil.WriteFile(dockerfilePath, []byte(dockerfile), projutil.FileMode); err != nil {
+		return fmt.Errorf("error writing %s: %v", dockerfilePath, err)
+	}
+	return nil
+}
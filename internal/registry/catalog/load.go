@@ -0,0 +1,102 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/operator-framework/operator-registry/pkg/sqlite"
+	log "github.com/sirupsen/logrus"
+
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+const (
+	// indexDBLabel is the label on an index image containing the path of its database.
+	indexDBLabel  = "operators.operatorframework.io.index.database.v1"
+	defaultDBPath = "/database/index.db"
+	// indexConfigsLabel is the label on a file-based catalog image containing the path of its catalog.
+	indexConfigsLabel = "operators.operatorframework.io.index.configs.v1"
+)
+
+// LoadIndexImage pulls the index image and reads the catalog from its database, or from its
+// file-based catalog if the image has one.
+func LoadIndexImage(ctx context.Context, logger *log.Entry, image string) (*Catalog, error) {
+	labels, err := registryutil.GetImageLabels(ctx, logger, image, false)
+	if err != nil {
+		return nil, err
+	}
+	dbPath, ok := labels[indexDBLabel]
+	if !ok {
+		dbPath = defaultDBPath
+	}
+	configsPath, isFBC := labels[indexConfigsLabel]
+
+	dir, err := registryutil.ExtractBundleImage(ctx, logger, image, true)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Errorf("Failed to remove index image directory %s: %v", dir, err)
+		}
+	}()
+	if isFBC {
+		return LoadFBCCatalog(filepath.Join(dir, configsPath))
+	}
+	return LoadDatabase(ctx, filepath.Join(dir, dbPath))
+}
+
+// LoadFBCCatalog reads the catalog from the file-based catalog in dir.
+func LoadFBCCatalog(dir string) (*Catalog, error) {
+	f, err := LoadFBC(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file-based catalog: %v", err)
+	}
+	return f.Catalog()
+}
+
+// LoadDatabase reads the catalog from the index database at path.
+func LoadDatabase(ctx context.Context, path string) (*Catalog, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("error reading index database: %v", err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?immutable=true")
+	if err != nil {
+		return nil, fmt.Errorf("error opening index database %s: %v", path, err)
+	}
+	defer db.Close()
+	querier := sqlite.NewSQLLiteQuerierFromDb(db)
+
+	bundles, err := querier.ListBundles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing bundles: %v", err)
+	}
+	pkgs, err := querier.ListPackages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing packages: %v", err)
+	}
+	c := &Catalog{Bundles: bundles, DefaultChannels: map[string]string{}}
+	for _, pkg := range pkgs {
+		if c.DefaultChannels[pkg], err = querier.GetDefaultChannelForPackage(ctx, pkg); err != nil {
+			return nil, fmt.Errorf("error getting default channel of package %s: %v", pkg, err)
+		}
+	}
+	return c, nil
+}
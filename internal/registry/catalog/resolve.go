@@ -0,0 +1,429 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+// Source is a catalog bundles are resolved from, named by the directory or image it was read from.
+type Source struct {
+	Name    string
+	Catalog *Catalog
+}
+
+// Subscription is the operator to resolve, as in the spec of a Subscription.
+type Subscription struct {
+	Package string
+	// Channel defaults to the package's default channel.
+	Channel string
+	// StartingCSV is installed instead of the channel's head if set.
+	StartingCSV string
+	// Source is the name of the Source to install Package from. Any source is used if unset.
+	Source string
+}
+
+// Resolution is the bundles installed by a subscription, in the order they were selected.
+type Resolution struct {
+	Bundles []ResolvedBundle `json:"bundles"`
+	// Warnings are dependencies that were not simulated, ex. CEL constraints on cluster properties.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ResolvedBundle is a bundle selected by a resolution.
+type ResolvedBundle struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	Channel string `json:"channel"`
+	Version string `json:"version"`
+	Source  string `json:"source"`
+	// Reason is why the bundle was selected.
+	Reason string `json:"reason"`
+}
+
+// ResolutionError explains why a subscription cannot be resolved.
+type ResolutionError struct {
+	Reasons []string
+}
+
+func (e *ResolutionError) Error() string {
+	return "resolution failed: " + strings.Join(e.Reasons, "; ")
+}
+
+func resolutionErrorf(format string, args ...interface{}) *ResolutionError {
+	return &ResolutionError{Reasons: []string{fmt.Sprintf(format, args...)}}
+}
+
+// Resolve simulates OLM's resolution of sub against sources, which are preferred in order. Like OLM, each
+// package and API is installed by at most one bundle, and candidates for a dependency are ordered by
+// preference: bundles in the dependent's source first, then in the default channel of their package, then
+// from the head of their channel down its upgrade graph. Unlike OLM's resolver, which solves all
+// constraints at once, the first candidate satisfying a dependency is selected without backtracking,
+// so a resolution failing here may succeed in a cluster by installing less-preferred bundles.
+func Resolve(sources []Source, sub Subscription) (*Resolution, error) {
+	r := &resolver{sources: sources, packages: map[string]*api.Bundle{}, apis: map[string]*api.Bundle{}}
+	src, channel, err := r.subscriptionChannel(sub)
+	if err != nil {
+		return nil, err
+	}
+	candidates := orderChannel(channelBundles(src.Catalog, sub.Package, channel))
+	target := candidates[0]
+	reason := fmt.Sprintf("is the head of channel %s of package %s, the subscription's channel", channel, sub.Package)
+	if sub.StartingCSV != "" {
+		target = nil
+		for _, b := range candidates {
+			if b.CsvName == sub.StartingCSV {
+				target = b
+			}
+		}
+		if target == nil {
+			return nil, resolutionErrorf("starting CSV %s is not in channel %s of package %s in catalog %s",
+				sub.StartingCSV, channel, sub.Package, src.Name)
+		}
+		reason = fmt.Sprintf("is the subscription's starting CSV in channel %s of package %s", channel, sub.Package)
+	}
+	if err := r.install(candidate{bundle: target, source: src.Name}, reason); err != nil {
+		return nil, err
+	}
+	return &r.resolution, nil
+}
+
+type resolver struct {
+	sources    []Source
+	resolution Resolution
+	// packages and apis are the selected bundles by package name and by provided API.
+	packages map[string]*api.Bundle
+	apis     map[string]*api.Bundle
+}
+
+type candidate struct {
+	bundle *api.Bundle
+	source string
+}
+
+// subscriptionChannel returns the source and channel sub installs from.
+func (r *resolver) subscriptionChannel(sub Subscription) (Source, string, error) {
+	for _, src := range r.sources {
+		if sub.Source != "" && src.Name != sub.Source {
+			continue
+		}
+		channels := packageChannels(src.Catalog, sub.Package)
+		if len(channels) == 0 {
+			continue
+		}
+		channel := sub.Channel
+		if channel == "" {
+			if channel = src.Catalog.DefaultChannels[sub.Package]; channel == "" {
+				return src, "", resolutionErrorf("package %s in catalog %s has no default channel, set a channel",
+					sub.Package, src.Name)
+			}
+		}
+		if !contains(channels, channel) {
+			return src, "", resolutionErrorf("package %s in catalog %s has no channel %s, channels are: [%s]",
+				sub.Package, src.Name, channel, strings.Join(channels, ", "))
+		}
+		return src, channel, nil
+	}
+	if sub.Source != "" {
+		for _, src := range r.sources {
+			if src.Name == sub.Source {
+				return Source{}, "", resolutionErrorf("package %s not found in catalog %s", sub.Package, sub.Source)
+			}
+		}
+		return Source{}, "", resolutionErrorf("catalog %s not found", sub.Source)
+	}
+	return Source{}, "", resolutionErrorf("package %s not found in any catalog", sub.Package)
+}
+
+// install selects c, then resolves its dependencies.
+func (r *resolver) install(c candidate, reason string) error {
+	b := c.bundle
+	if selected, ok := r.packages[b.PackageName]; ok && selected.CsvName == b.CsvName {
+		return nil
+	}
+	if conflict := r.conflict(b); conflict != "" {
+		return resolutionErrorf("cannot install %s, which %s: %s", b.CsvName, reason, conflict)
+	}
+
+	r.packages[b.PackageName] = b
+	for _, gvk := range b.ProvidedApis {
+		r.apis[gvkString(gvk)] = b
+	}
+	r.resolution.Bundles = append(r.resolution.Bundles, ResolvedBundle{
+		Name:    b.CsvName,
+		Package: b.PackageName,
+		Channel: b.ChannelName,
+		Version: b.Version,
+		Source:  c.source,
+		Reason:  reason,
+	})
+
+	for _, dep := range b.Dependencies {
+		if err := r.resolveDependency(c, dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependency is an API or package required by a bundle.
+type dependency struct {
+	gvk *api.GroupVersionKind
+	// pkg and versionRange are set for package dependencies.
+	pkg          string
+	versionRange string
+	// failureMessage is the message of an olm.constraint.
+	failureMessage string
+}
+
+func (d dependency) String() string {
+	if d.gvk != nil {
+		return "API " + gvkString(d.gvk)
+	}
+	return fmt.Sprintf("package %s (%s)", d.pkg, d.versionRange)
+}
+
+// resolveDependency selects a bundle satisfying dep of c's bundle, unless one is selected already.
+func (r *resolver) resolveDependency(c candidate, dep *api.Dependency) error {
+	d, ok, err := parseDependency(dep)
+	if err != nil {
+		return resolutionErrorf("bundle %s has an invalid %s dependency: %v", c.bundle.CsvName, dep.Type, err)
+	}
+	if !ok {
+		r.resolution.Warnings = append(r.resolution.Warnings, fmt.Sprintf(
+			"%s dependency %s of bundle %s was not simulated", dep.Type, dep.Value, c.bundle.CsvName))
+		return nil
+	}
+	var inRange semver.Range
+	if d.gvk == nil {
+		if inRange, err = semver.ParseRange(d.versionRange); err != nil {
+			return resolutionErrorf("bundle %s requires package %s with invalid version range %q: %v",
+				c.bundle.CsvName, d.pkg, d.versionRange, err)
+		}
+	}
+	satisfies := func(b *api.Bundle) bool {
+		if d.gvk != nil {
+			for _, gvk := range b.ProvidedApis {
+				if gvkString(gvk) == gvkString(d.gvk) {
+					return true
+				}
+			}
+			return false
+		}
+		v, err := semver.ParseTolerant(b.Version)
+		return b.PackageName == d.pkg && err == nil && inRange(v)
+	}
+
+	// A selected bundle may satisfy the dependency already.
+	if d.gvk != nil {
+		if provider, ok := r.apis[gvkString(d.gvk)]; ok && satisfies(provider) {
+			return nil
+		}
+	} else if selected, ok := r.packages[d.pkg]; ok {
+		if satisfies(selected) {
+			return nil
+		}
+		return r.dependencyError(c, d, fmt.Sprintf("package %s is already installed by %s at version %s",
+			d.pkg, selected.CsvName, selected.Version))
+	}
+
+	reason := fmt.Sprintf("provides %s required by %s", d, c.bundle.CsvName)
+	if d.gvk == nil {
+		reason = fmt.Sprintf("satisfies %s required by %s", d, c.bundle.CsvName)
+	}
+	var conflicts []string
+	for _, cand := range r.candidates(c.source, d.pkg) {
+		if !satisfies(cand.bundle) {
+			continue
+		}
+		if conflict := r.conflict(cand.bundle); conflict != "" {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%s)", cand.bundle.CsvName, conflict))
+			continue
+		}
+		return r.install(cand, reason)
+	}
+	if len(conflicts) != 0 {
+		return r.dependencyError(c, d, "it is only provided by bundles that conflict with selected bundles: "+
+			strings.Join(conflicts, ", "))
+	}
+	return r.dependencyError(c, d, "no bundle in the catalogs satisfies it")
+}
+
+// conflict describes why b cannot be installed alongside the selected bundles, or returns the empty string.
+func (r *resolver) conflict(b *api.Bundle) string {
+	if selected, ok := r.packages[b.PackageName]; ok && selected.CsvName != b.CsvName {
+		return fmt.Sprintf("package %s is already installed by %s", b.PackageName, selected.CsvName)
+	}
+	for _, gvk := range b.ProvidedApis {
+		if provider, ok := r.apis[gvkString(gvk)]; ok && provider.CsvName != b.CsvName {
+			return fmt.Sprintf("API %s is already provided by %s", gvkString(gvk), provider.CsvName)
+		}
+	}
+	return ""
+}
+
+func (r *resolver) dependencyError(c candidate, d dependency, why string) error {
+	err := resolutionErrorf("bundle %s requires %s, but %s", c.bundle.CsvName, d, why)
+	if d.failureMessage != "" {
+		err.Reasons = append(err.Reasons, d.failureMessage)
+	}
+	return err
+}
+
+// parseDependency parses dep, returning false if dep cannot be simulated.
+func parseDependency(dep *api.Dependency) (d dependency, ok bool, err error) {
+	switch dep.Type {
+	case dependencyTypeGVK:
+		d.gvk = &api.GroupVersionKind{}
+		err = json.Unmarshal([]byte(dep.Value), d.gvk)
+		return d, err == nil, err
+	case dependencyTypePackage:
+		var v struct {
+			PackageName string `json:"packageName"`
+			Version     string `json:"version"`
+		}
+		err = json.Unmarshal([]byte(dep.Value), &v)
+		d.pkg, d.versionRange = v.PackageName, v.Version
+		return d, err == nil, err
+	case propertyConstraint:
+		var v struct {
+			FailureMessage string                `json:"failureMessage"`
+			GVK            *api.GroupVersionKind `json:"gvk"`
+			Package        *struct {
+				PackageName  string `json:"packageName"`
+				VersionRange string `json:"versionRange"`
+			} `json:"package"`
+		}
+		if err = json.Unmarshal([]byte(dep.Value), &v); err != nil {
+			return d, false, err
+		}
+		d.failureMessage = v.FailureMessage
+		switch {
+		case v.GVK != nil:
+			d.gvk = v.GVK
+			return d, true, nil
+		case v.Package != nil:
+			d.pkg, d.versionRange = v.Package.PackageName, v.Package.VersionRange
+			return d, true, nil
+		}
+	}
+	return d, false, nil
+}
+
+// candidates returns the bundles of each source in order of preference: bundles of the preferred source
+// first, then by package, default channel first, then from the head of each channel. If pkg is set,
+// only bundles of pkg are returned.
+func (r *resolver) candidates(preferred, pkg string) (candidates []candidate) {
+	sources := make([]Source, 0, len(r.sources))
+	for _, src := range r.sources {
+		if src.Name == preferred {
+			sources = append([]Source{src}, sources...)
+		} else {
+			sources = append(sources, src)
+		}
+	}
+	for _, src := range sources {
+		var pkgs []string
+		for _, b := range src.Catalog.Bundles {
+			if (pkg == "" || b.PackageName == pkg) && !contains(pkgs, b.PackageName) {
+				pkgs = append(pkgs, b.PackageName)
+			}
+		}
+		sort.Strings(pkgs)
+		for _, p := range pkgs {
+			channels := packageChannels(src.Catalog, p)
+			defaultChannel := src.Catalog.DefaultChannels[p]
+			sort.SliceStable(channels, func(i, j int) bool {
+				return channels[i] == defaultChannel && channels[j] != defaultChannel
+			})
+			for _, channel := range channels {
+				for _, b := range orderChannel(channelBundles(src.Catalog, p, channel)) {
+					candidates = append(candidates, candidate{bundle: b, source: src.Name})
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// packageChannels returns the sorted channels of pkg in c.
+func packageChannels(c *Catalog, pkg string) (channels []string) {
+	for _, b := range c.Bundles {
+		if b.PackageName == pkg && b.ChannelName != "" && !contains(channels, b.ChannelName) {
+			channels = append(channels, b.ChannelName)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// channelBundles returns the bundles in channel of pkg in c.
+func channelBundles(c *Catalog, pkg, channel string) (bundles []*api.Bundle) {
+	for _, b := range c.Bundles {
+		if b.PackageName == pkg && b.ChannelName == channel {
+			bundles = append(bundles, b)
+		}
+	}
+	return bundles
+}
+
+// orderChannel orders the bundles of a channel from its head down its replaces edges. The head is the
+// bundle no other bundle replaces or skips, the newest if there are several. Bundles not reached from the
+// head follow, newest first.
+func orderChannel(bundles []*api.Bundle) []*api.Bundle {
+	byName := map[string]*api.Bundle{}
+	upgraded := map[string]bool{}
+	for _, b := range bundles {
+		byName[b.CsvName] = b
+		upgraded[b.Replaces] = true
+		for _, skip := range b.Skips {
+			upgraded[skip] = true
+		}
+	}
+	sorted := append([]*api.Bundle{}, bundles...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		return bundleLess(&Bundle{Name: b.CsvName, Version: b.Version}, &Bundle{Name: a.CsvName, Version: a.Version})
+	})
+
+	var ordered []*api.Bundle
+	visited := map[string]bool{}
+	for _, b := range sorted {
+		if upgraded[b.CsvName] || len(ordered) != 0 {
+			continue
+		}
+		for next := b; next != nil && !visited[next.CsvName]; next = byName[next.Replaces] {
+			visited[next.CsvName] = true
+			ordered = append(ordered, next)
+		}
+	}
+	for _, b := range sorted {
+		if !visited[b.CsvName] {
+			ordered = append(ordered, b)
+		}
+	}
+	return ordered
+}
+
+// gvkString returns gvk in the form group/version/kind.
+func gvkString(gvk *api.GroupVersionKind) string {
+	return fmt.Sprintf("%s/%s/%s", gvk.Group, gvk.Version, gvk.Kind)
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/operator-registry/pkg/api"
+)
+
+const resolveFBC = `---
+schema: olm.package
+name: memcached
+defaultChannel: stable
+---
+schema: olm.channel
+package: memcached
+name: stable
+entries:
+- name: memcached.v0.1.0
+- name: memcached.v0.2.0
+  replaces: memcached.v0.1.0
+---
+schema: olm.bundle
+package: memcached
+name: memcached.v0.1.0
+properties:
+- type: olm.package
+  value: {packageName: memcached, version: 0.1.0}
+---
+schema: olm.bundle
+package: memcached
+name: memcached.v0.2.0
+properties:
+- type: olm.package
+  value: {packageName: memcached, version: 0.2.0}
+- type: olm.gvk
+  value: {group: cache.example.com, version: v1, kind: Memcached}
+- type: olm.gvk.required
+  value: {group: etcd.database.coreos.com, version: v1beta2, kind: EtcdCluster}
+- type: olm.package.required
+  value: {packageName: etcd, versionRange: ">=0.9.0"}
+- type: olm.constraint
+  value: {failureMessage: requires OpenShift, cel: {rule: 'properties.exists(p, p.type == "olm.maxOpenShiftVersion")'}}
+---
+schema: olm.package
+name: etcd
+defaultChannel: singlenamespace-alpha
+---
+schema: olm.channel
+package: etcd
+name: singlenamespace-alpha
+entries:
+- name: etcdoperator.v0.9.2
+- name: etcdoperator.v0.9.4
+  replaces: etcdoperator.v0.9.2
+---
+schema: olm.bundle
+package: etcd
+name: etcdoperator.v0.9.2
+properties:
+- type: olm.package
+  value: {packageName: etcd, version: 0.9.2}
+- type: olm.gvk
+  value: {group: etcd.database.coreos.com, version: v1beta2, kind: EtcdCluster}
+---
+schema: olm.bundle
+package: etcd
+name: etcdoperator.v0.9.4
+properties:
+- type: olm.package
+  value: {packageName: etcd, version: 0.9.4}
+- type: olm.gvk
+  value: {group: etcd.database.coreos.com, version: v1beta2, kind: EtcdCluster}
+`
+
+var _ = Describe("Resolve", func() {
+	var (
+		dir     string
+		sources []Source
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "resolve")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "index.yaml"), []byte(resolveFBC), 0644)).To(Succeed())
+		c, err := LoadFBCCatalog(dir)
+		Expect(err).NotTo(HaveOccurred())
+		sources = []Source{{Name: "catalog", Catalog: c}}
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("selects the channel head and its dependencies, and warns of unsimulated constraints", func() {
+		res, err := Resolve(sources, Subscription{Package: "memcached"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Bundles).To(Equal([]ResolvedBundle{
+			{Name: "memcached.v0.2.0", Package: "memcached", Channel: "stable", Version: "0.2.0", Source: "catalog",
+				Reason: "is the head of channel stable of package memcached, the subscription's channel"},
+			{Name: "etcdoperator.v0.9.4", Package: "etcd", Channel: "singlenamespace-alpha", Version: "0.9.4",
+				Source: "catalog",
+				Reason: "provides API etcd.database.coreos.com/v1beta2/EtcdCluster required by memcached.v0.2.0"},
+		}))
+		Expect(res.Warnings).To(HaveLen(1))
+		Expect(res.Warnings[0]).To(ContainSubstring("olm.constraint dependency"))
+	})
+	It("installs the starting CSV", func() {
+		res, err := Resolve(sources, Subscription{Package: "memcached", Channel: "stable", StartingCSV: "memcached.v0.1.0"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Bundles).To(HaveLen(1))
+		Expect(res.Bundles[0].Name).To(Equal("memcached.v0.1.0"))
+	})
+	It("prefers the dependent's catalog, then catalogs in order", func() {
+		other, err := LoadFBCCatalog(dir)
+		Expect(err).NotTo(HaveOccurred())
+		sources = []Source{{Name: "other", Catalog: other}, sources[0]}
+		res, err := Resolve(sources, Subscription{Package: "memcached", Source: "catalog"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Bundles).To(HaveLen(2))
+		Expect(res.Bundles[1].Source).To(Equal("catalog"))
+	})
+	It("explains unknown packages and channels", func() {
+		_, err := Resolve(sources, Subscription{Package: "redis"})
+		Expect(err).To(MatchError("resolution failed: package redis not found in any catalog"))
+		_, err = Resolve(sources, Subscription{Package: "memcached", Channel: "alpha"})
+		Expect(err).To(MatchError("resolution failed: package memcached in catalog catalog has no channel alpha, " +
+			"channels are: [stable]"))
+		_, err = Resolve(sources, Subscription{Package: "memcached", Source: "missing"})
+		Expect(err).To(MatchError("resolution failed: catalog missing not found"))
+	})
+	It("explains unsatisfiable dependencies", func() {
+		c := sources[0].Catalog
+		for _, b := range c.Bundles {
+			if b.PackageName == "etcd" {
+				b.Version = "0.8.0"
+			}
+		}
+		_, err := Resolve(sources, Subscription{Package: "memcached"})
+		Expect(err).To(MatchError("resolution failed: bundle memcached.v0.2.0 requires package etcd (>=0.9.0), " +
+			"but package etcd is already installed by etcdoperator.v0.9.4 at version 0.8.0"))
+	})
+	It("explains API conflicts", func() {
+		c := sources[0].Catalog
+		for _, b := range c.Bundles {
+			if b.CsvName == "memcached.v0.2.0" {
+				b.ProvidedApis = append(b.ProvidedApis, &api.GroupVersionKind{
+					Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"})
+			}
+		}
+		_, err := Resolve(sources, Subscription{Package: "memcached"})
+		Expect(err).To(MatchError(ContainSubstring("bundle memcached.v0.2.0 requires package etcd (>=0.9.0), " +
+			"but it is only provided by bundles that conflict with selected bundles: " +
+			"etcdoperator.v0.9.4 (API etcd.database.coreos.com/v1beta2/EtcdCluster is already provided by memcached.v0.2.0)")))
+	})
+})
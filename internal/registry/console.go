@@ -0,0 +1,375 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register gif decoding for icon validation.
+	_ "image/jpeg" // Register jpeg decoding for icon validation.
+	_ "image/png"  // Register png decoding for icon validation.
+	"net/url"
+	"regexp"
+	"strings"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	crdvalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ConsoleValidator validates CSV fields that the OpenShift console renders, which
+// otherwise only fail visually once an operator is published.
+var ConsoleValidator interfaces.Validator = interfaces.ValidatorFunc(validateConsole)
+
+// maxIconSize is the largest decoded icon size the console renders without
+// noticeably slowing down catalog pages.
+const maxIconSize = 100 << 10
+
+// Media types the console can render for icons.
+var consoleIconMediaTypes = map[string]struct{}{
+	"image/gif":     {},
+	"image/jpeg":    {},
+	"image/png":     {},
+	"image/svg+xml": {},
+}
+
+const descriptorPrefix = "urn:alm:descriptor:"
+
+// consoleDescriptors are x-descriptors known to the console, without descriptorPrefix.
+// Descriptors ending in ':' take an argument.
+var consoleDescriptors = []string{
+	"com.tectonic.ui:podCount",
+	"com.tectonic.ui:podStatuses",
+	"com.tectonic.ui:resourceRequirements",
+	"com.tectonic.ui:selector:",
+	"com.tectonic.ui:namespaceSelector",
+	"com.tectonic.ui:booleanSwitch",
+	"com.tectonic.ui:checkbox",
+	"com.tectonic.ui:imagePullPolicy",
+	"com.tectonic.ui:updateStrategy",
+	"com.tectonic.ui:text",
+	"com.tectonic.ui:number",
+	"com.tectonic.ui:password",
+	"com.tectonic.ui:label",
+	"com.tectonic.ui:select:",
+	"com.tectonic.ui:fieldGroup:",
+	"com.tectonic.ui:arrayFieldGroup:",
+	"com.tectonic.ui:fieldDependency:",
+	"com.tectonic.ui:advanced",
+	"com.tectonic.ui:hidden",
+	"com.tectonic.ui:nodeAffinity",
+	"com.tectonic.ui:podAffinity",
+	"com.tectonic.ui:podAntiAffinity",
+	"io.kubernetes:",
+	"io.kubernetes.conditions",
+	"io.kubernetes.phase",
+	"io.kubernetes.phase:reason",
+	"org.w3:link",
+	"prometheusEndpoint",
+	"text",
+}
+
+func validateConsole(objs ...interface{}) (results []apierrors.ManifestResult) {
+	for _, obj := range objs {
+		switch v := obj.(type) {
+		case *apimanifests.Bundle:
+			results = append(results, validateBundleConsole(v))
+		}
+	}
+	return results
+}
+
+func validateBundleConsole(bundle *apimanifests.Bundle) apierrors.ManifestResult {
+	result := apierrors.ManifestResult{Name: bundle.Name}
+	if bundle.CSV == nil {
+		result.Add(apierrors.ErrInvalidBundle("no ClusterServiceVersion in bundle", bundle.Name))
+		return result
+	}
+
+	schemas, err := getCRDSchemas(bundle)
+	if err != nil {
+		result.Add(apierrors.ErrInvalidBundle(err.Error(), bundle.Name))
+		return result
+	}
+
+	csv := bundle.CSV
+	result.Add(validateConsoleDisplayNames(csv)...)
+	result.Add(validateConsoleIcon(csv)...)
+	result.Add(validateConsoleDescription(csv)...)
+	result.Add(validateConsoleDescriptors(csv, schemas)...)
+	result.Add(validateConsoleExamples(csv, schemas)...)
+	return result
+}
+
+// validateConsoleDisplayNames checks that the CSV and each of its owned APIs and descriptors have display names.
+func validateConsoleDisplayNames(csv *v1alpha1.ClusterServiceVersion) (errs []apierrors.Error) {
+	if csv.Spec.DisplayName == "" {
+		errs = append(errs, apierrors.ErrInvalidCSV("spec.displayName must be set", csv.GetName()))
+	}
+	for _, desc := range csv.Spec.CustomResourceDefinitions.Owned {
+		if desc.DisplayName == "" {
+			errs = append(errs, apierrors.WarnInvalidCSV(fmt.Sprintf("owned CRD %q has no displayName", desc.Name), csv.GetName()))
+		}
+		for _, d := range desc.SpecDescriptors {
+			if d.DisplayName == "" {
+				errs = append(errs, apierrors.WarnInvalidCSV(fmt.Sprintf("owned CRD %q specDescriptor %q has no displayName",
+					desc.Name, d.Path), csv.GetName()))
+			}
+		}
+		for _, d := range desc.StatusDescriptors {
+			if d.DisplayName == "" {
+				errs = append(errs, apierrors.WarnInvalidCSV(fmt.Sprintf("owned CRD %q statusDescriptor %q has no displayName",
+					desc.Name, d.Path), csv.GetName()))
+			}
+		}
+	}
+	return errs
+}
+
+// validateConsoleIcon checks that the CSV has an icon the console can render.
+func validateConsoleIcon(csv *v1alpha1.ClusterServiceVersion) (errs []apierrors.Error) {
+	if len(csv.Spec.Icon) == 0 {
+		return []apierrors.Error{apierrors.WarnInvalidCSV("spec.icon is not set, a default icon will be displayed", csv.GetName())}
+	}
+	for i, icon := range csv.Spec.Icon {
+		if _, ok := consoleIconMediaTypes[icon.MediaType]; !ok {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.icon[%d] has unsupported mediatype %q", i, icon.MediaType),
+				csv.GetName()))
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(icon.Data)
+		if err != nil {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.icon[%d] is not valid base64: %v", i, err), csv.GetName()))
+			continue
+		}
+		if len(data) > maxIconSize {
+			errs = append(errs, apierrors.WarnInvalidCSV(fmt.Sprintf("spec.icon[%d] is %d bytes, larger than the recommended %d bytes",
+				i, len(data), maxIconSize), csv.GetName()))
+		}
+		if icon.MediaType == "image/svg+xml" {
+			if !bytes.Contains(data, []byte("<svg")) {
+				errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.icon[%d] is not an svg image", i), csv.GetName()))
+			}
+			continue
+		}
+		if _, format, err := image.DecodeConfig(bytes.NewReader(data)); err != nil {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.icon[%d] cannot be decoded: %v", i, err), csv.GetName()))
+		} else if "image/"+format != icon.MediaType {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.icon[%d] is a %s image but has mediatype %q",
+				i, format, icon.MediaType), csv.GetName()))
+		}
+	}
+	return errs
+}
+
+var markdownLinkRe = regexp.MustCompile(`\[[^\]]*\]\(([^)\s]*)[^)]*\)`)
+
+// validateConsoleDescription checks that the CSV's description is markdown the console can render.
+func validateConsoleDescription(csv *v1alpha1.ClusterServiceVersion) (errs []apierrors.Error) {
+	desc := csv.Spec.Description
+	if strings.TrimSpace(desc) == "" {
+		return []apierrors.Error{apierrors.WarnInvalidCSV("spec.description is empty", csv.GetName())}
+	}
+	if strings.Count(desc, "```")%2 != 0 {
+		errs = append(errs, apierrors.ErrInvalidCSV("spec.description has an unterminated code block", csv.GetName()))
+	}
+	for _, match := range markdownLinkRe.FindAllStringSubmatch(desc, -1) {
+		link := match[1]
+		if link == "" {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.description link %q has no target", match[0]),
+				csv.GetName()))
+			continue
+		}
+		if _, err := url.Parse(link); err != nil {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.description link %q is invalid: %v", link, err),
+				csv.GetName()))
+		}
+	}
+	return errs
+}
+
+// validateConsoleDescriptors checks that owned CRD descriptors use known x-descriptors and
+// have paths that exist in their CRD's schema.
+func validateConsoleDescriptors(csv *v1alpha1.ClusterServiceVersion, schemas map[schema.GroupVersionKind]*apiextensions.JSONSchemaProps) (errs []apierrors.Error) {
+	for _, desc := range csv.Spec.CustomResourceDefinitions.Owned {
+		gvk := schema.GroupVersionKind{Group: crdGroup(desc.Name), Version: desc.Version, Kind: desc.Kind}
+		crdSchema := schemas[gvk]
+		for _, d := range desc.SpecDescriptors {
+			errs = append(errs, checkDescriptor(csv.GetName(), desc.Name, "spec", d.Path, d.XDescriptors, crdSchema)...)
+		}
+		for _, d := range desc.StatusDescriptors {
+			errs = append(errs, checkDescriptor(csv.GetName(), desc.Name, "status", d.Path, d.XDescriptors, crdSchema)...)
+		}
+	}
+	return errs
+}
+
+func checkDescriptor(csvName, crdName, field, path string, xDescriptors []string, crdSchema *apiextensions.JSONSchemaProps) (errs []apierrors.Error) {
+	for _, xd := range xDescriptors {
+		if !isConsoleDescriptor(xd) {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("owned CRD %q %sDescriptor %q has unknown x-descriptor %q",
+				crdName, field, path, xd), csvName))
+		}
+	}
+	if path == "" {
+		errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("owned CRD %q has a %sDescriptor with no path", crdName, field),
+			csvName))
+	} else if crdSchema != nil && !schemaHasPath(crdSchema, append([]string{field}, strings.Split(path, ".")...)) {
+		errs = append(errs, apierrors.WarnInvalidCSV(fmt.Sprintf("owned CRD %q %sDescriptor path %q not found in CRD schema",
+			crdName, field, path), csvName))
+	}
+	return errs
+}
+
+func isConsoleDescriptor(xd string) bool {
+	if !strings.HasPrefix(xd, descriptorPrefix) {
+		return false
+	}
+	xd = strings.TrimPrefix(xd, descriptorPrefix)
+	for _, known := range consoleDescriptors {
+		if xd == known || (strings.HasSuffix(known, ":") && strings.HasPrefix(xd, known) && len(xd) > len(known)) {
+			return true
+		}
+	}
+	return false
+}
+
+var arrayIndexRe = regexp.MustCompile(`\[\d*\]$`)
+
+// schemaHasPath returns true if path exists in s. Paths into objects that preserve
+// unknown fields or have additional properties are always considered to exist.
+func schemaHasPath(s *apiextensions.JSONSchemaProps, path []string) bool {
+	for _, seg := range path {
+		isIndexed := arrayIndexRe.MatchString(seg)
+		seg = arrayIndexRe.ReplaceAllString(seg, "")
+		if s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields || s.AdditionalProperties != nil {
+			return true
+		}
+		prop, ok := s.Properties[seg]
+		if !ok {
+			return false
+		}
+		s = &prop
+		if isIndexed {
+			if s.Items == nil || s.Items.Schema == nil {
+				return false
+			}
+			s = s.Items.Schema
+		}
+	}
+	return true
+}
+
+// validateConsoleExamples checks that alm-examples parses and that each example matches its CRD's schema.
+func validateConsoleExamples(csv *v1alpha1.ClusterServiceVersion, schemas map[schema.GroupVersionKind]*apiextensions.JSONSchemaProps) (errs []apierrors.Error) {
+	examplesStr, ok := csv.GetAnnotations()["alm-examples"]
+	if !ok || strings.TrimSpace(examplesStr) == "" {
+		if len(csv.Spec.CustomResourceDefinitions.Owned) != 0 {
+			errs = append(errs, apierrors.WarnInvalidCSV("alm-examples annotation is not set, the console will not "+
+				"prefill resources for owned APIs", csv.GetName()))
+		}
+		return errs
+	}
+
+	var examples []map[string]interface{}
+	if err := json.Unmarshal([]byte(examplesStr), &examples); err != nil {
+		return []apierrors.Error{apierrors.ErrInvalidCSV(fmt.Sprintf("alm-examples annotation is not a JSON list of objects: %v", err),
+			csv.GetName())}
+	}
+	for i, example := range examples {
+		u := unstructured.Unstructured{Object: example}
+		gvk := u.GroupVersionKind()
+		crdSchema, ok := schemas[gvk]
+		if !ok {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("alm-examples[%d] has kind %s not defined by a CRD in the bundle",
+				i, gvk), csv.GetName()))
+			continue
+		}
+		if crdSchema == nil {
+			continue
+		}
+		validator, _, err := crdvalidation.NewSchemaValidator(&apiextensions.CustomResourceValidation{OpenAPIV3Schema: crdSchema})
+		if err != nil {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("build schema validator for %s: %v", gvk, err), csv.GetName()))
+			continue
+		}
+		for _, ferr := range crdvalidation.ValidateCustomResource(nil, example, validator) {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("alm-examples[%d] (%s %q) does not match CRD schema: %v",
+				i, gvk.Kind, u.GetName(), ferr), csv.GetName()))
+		}
+	}
+	return errs
+}
+
+// getCRDSchemas returns the schema of each version of each CRD in bundle, keyed by GVK.
+// Versions without a schema have a nil value.
+func getCRDSchemas(bundle *apimanifests.Bundle) (map[schema.GroupVersionKind]*apiextensions.JSONSchemaProps, error) {
+	schemas := map[schema.GroupVersionKind]*apiextensions.JSONSchemaProps{}
+	for _, crd := range bundle.V1CRDs {
+		for _, ver := range crd.Spec.Versions {
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: ver.Name, Kind: crd.Spec.Names.Kind}
+			schemas[gvk] = nil
+			if ver.Schema == nil || ver.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			props := &apiextensions.JSONSchemaProps{}
+			if err := apiextv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(ver.Schema.OpenAPIV3Schema, props, nil); err != nil {
+				return nil, fmt.Errorf("convert CRD %q schema: %v", crd.GetName(), err)
+			}
+			schemas[gvk] = props
+		}
+	}
+	for _, crd := range bundle.V1beta1CRDs {
+		versions := crd.Spec.Versions
+		if len(versions) == 0 {
+			versions = []apiextv1beta1.CustomResourceDefinitionVersion{{Name: crd.Spec.Version}}
+		}
+		for _, ver := range versions {
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: ver.Name, Kind: crd.Spec.Names.Kind}
+			schemas[gvk] = nil
+			validation := crd.Spec.Validation
+			if ver.Schema != nil {
+				validation = ver.Schema
+			}
+			if validation == nil || validation.OpenAPIV3Schema == nil {
+				continue
+			}
+			props := &apiextensions.JSONSchemaProps{}
+			if err := apiextv1beta1.Convert_v1beta1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(validation.OpenAPIV3Schema, props, nil); err != nil {
+				return nil, fmt.Errorf("convert CRD %q schema: %v", crd.GetName(), err)
+			}
+			schemas[gvk] = props
+		}
+	}
+	return schemas, nil
+}
+
+// crdGroup returns the group of a CRD named "<plural>.<group>".
+func crdGroup(crdName string) string {
+	if split := strings.SplitN(crdName, ".", 2); len(split) == 2 {
+		return split[1]
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+var _ = Describe("ConsoleValidator", func() {
+	var (
+		bundle *apimanifests.Bundle
+		csv    *v1alpha1.ClusterServiceVersion
+	)
+
+	BeforeEach(func() {
+		csv = &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.SetAnnotations(map[string]string{
+			"alm-examples": `[{"apiVersion":"cache.example.com/v1alpha1","kind":"Memcached","metadata":{"name":"example"},"spec":{"size":3}}]`,
+		})
+		csv.Spec.DisplayName = "Memcached Operator"
+		csv.Spec.Description = "A [memcached](https://memcached.org) operator."
+		csv.Spec.Icon = []v1alpha1.Icon{{
+			MediaType: "image/svg+xml",
+			Data:      base64.StdEncoding.EncodeToString([]byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)),
+		}}
+		csv.Spec.CustomResourceDefinitions.Owned = []v1alpha1.CRDDescription{{
+			Name:        "memcacheds.cache.example.com",
+			Version:     "v1alpha1",
+			Kind:        "Memcached",
+			DisplayName: "Memcached",
+			SpecDescriptors: []v1alpha1.SpecDescriptor{{
+				Path:         "size",
+				DisplayName:  "Size",
+				XDescriptors: []string{"urn:alm:descriptor:com.tectonic.ui:podCount"},
+			}},
+		}}
+
+		crd := &apiextv1.CustomResourceDefinition{}
+		crd.SetName("memcacheds.cache.example.com")
+		crd.Spec.Group = "cache.example.com"
+		crd.Spec.Names.Kind = "Memcached"
+		crd.Spec.Versions = []apiextv1.CustomResourceDefinitionVersion{{
+			Name: "v1alpha1",
+			Schema: &apiextv1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextv1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]apiextv1.JSONSchemaProps{
+						"spec": {
+							Type: "object",
+							Properties: map[string]apiextv1.JSONSchemaProps{
+								"size": {Type: "integer"},
+							},
+						},
+					},
+				},
+			},
+		}}
+		bundle = &apimanifests.Bundle{Name: "memcached-operator", CSV: csv, V1CRDs: []*apiextv1.CustomResourceDefinition{crd}}
+	})
+
+	validate := func() apierrors.ManifestResult {
+		results := ConsoleValidator.Validate(bundle)
+		Expect(results).To(HaveLen(1))
+		return results[0]
+	}
+
+	It("passes a bundle with complete console metadata", func() {
+		result := validate()
+		Expect(result.Errors).To(BeEmpty())
+		Expect(result.Warnings).To(BeEmpty())
+	})
+	It("fails when displayName is not set", func() {
+		csv.Spec.DisplayName = ""
+		Expect(validate().HasError()).To(BeTrue())
+	})
+	It("fails when an x-descriptor is unknown", func() {
+		csv.Spec.CustomResourceDefinitions.Owned[0].SpecDescriptors[0].XDescriptors = []string{"urn:alm:descriptor:com.tectonic.ui:podCnt"}
+		Expect(validate().HasError()).To(BeTrue())
+	})
+	It("warns when a descriptor path is not in the CRD schema", func() {
+		csv.Spec.CustomResourceDefinitions.Owned[0].SpecDescriptors[0].Path = "replicas"
+		result := validate()
+		Expect(result.HasError()).To(BeFalse())
+		Expect(result.HasWarn()).To(BeTrue())
+	})
+	It("fails when the icon has an unsupported mediatype", func() {
+		csv.Spec.Icon[0].MediaType = "image/bmp"
+		Expect(validate().HasError()).To(BeTrue())
+	})
+	It("fails when the description has an unterminated code block", func() {
+		csv.Spec.Description = "```\nkubectl apply -f example.yaml\n"
+		Expect(validate().HasError()).To(BeTrue())
+	})
+	It("fails when alm-examples is not valid JSON", func() {
+		csv.GetAnnotations()["alm-examples"] = `[{"apiVersion":`
+		Expect(validate().HasError()).To(BeTrue())
+	})
+	It("fails when an example does not match its CRD schema", func() {
+		csv.GetAnnotations()["alm-examples"] = `[{"apiVersion":"cache.example.com/v1alpha1","kind":"Memcached","metadata":{"name":"example"},"spec":{"size":"three"}}]`
+		Expect(validate().HasError()).To(BeTrue())
+	})
+	It("fails when an example's kind is not defined by a bundled CRD", func() {
+		csv.GetAnnotations()["alm-examples"] = `[{"apiVersion":"cache.example.com/v1alpha1","kind":"Redis","metadata":{"name":"example"}}]`
+		Expect(validate().HasError()).To(BeTrue())
+	})
+})
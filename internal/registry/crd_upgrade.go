@@ -0,0 +1,280 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+)
+
+// CRDUpgradeCheck describes changes to the CRDs of a bundle that break upgrades from a previous bundle:
+// removed CRDs and versions, and schema changes that make existing custom resources invalid.
+type CRDUpgradeCheck struct {
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// HasErrors returns true if c has any error-level findings.
+func (c CRDUpgradeCheck) HasErrors() bool {
+	for _, f := range c.Findings {
+		if f.Level == FindingError {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CRDUpgradeCheck) addFinding(level, suggestion, format string, args ...interface{}) {
+	c.Findings = append(c.Findings, Finding{Level: level, Message: fmt.Sprintf(format, args...), Suggestion: suggestion})
+}
+
+// CheckCRDUpgrade compares the CRDs of bundle with those of previous, the bundle it upgrades from, for changes
+// that break custom resources created with previous' CRDs or clients of their APIs.
+func CheckCRDUpgrade(previous, bundle *apimanifests.Bundle) (*CRDUpgradeCheck, error) {
+	oldCRDs, err := bundleCRDs(previous)
+	if err != nil {
+		return nil, err
+	}
+	newCRDs, err := bundleCRDs(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CRDUpgradeCheck{}
+	names := make([]string, 0, len(oldCRDs))
+	for name := range oldCRDs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		newCRD, ok := newCRDs[name]
+		if !ok {
+			c.addFinding(FindingError, "keep the CRD in the bundle, or migrate its custom resources in a previous release",
+				"CRD %s was removed, so OLM deletes it and its custom resources on upgrade", name)
+			continue
+		}
+		c.checkCRD(oldCRDs[name], newCRD)
+	}
+	return c, nil
+}
+
+// bundleCRDs returns the CRDs of bundle by name, converted to v1.
+func bundleCRDs(bundle *apimanifests.Bundle) (map[string]*apiextv1.CustomResourceDefinition, error) {
+	crds := make(map[string]*apiextv1.CustomResourceDefinition, len(bundle.V1CRDs)+len(bundle.V1beta1CRDs))
+	for _, crd := range bundle.V1CRDs {
+		crds[crd.GetName()] = crd
+	}
+	for _, crd := range bundle.V1beta1CRDs {
+		converted, err := k8sutil.Convertv1beta1Tov1CustomResourceDefinition(crd)
+		if err != nil {
+			return nil, fmt.Errorf("error converting CRD %s to v1: %v", crd.GetName(), err)
+		}
+		crds[crd.GetName()] = converted
+	}
+	return crds, nil
+}
+
+func (c *CRDUpgradeCheck) checkCRD(oldCRD, newCRD *apiextv1.CustomResourceDefinition) {
+	name := oldCRD.GetName()
+	if oldCRD.Spec.Scope != newCRD.Spec.Scope {
+		c.addFinding(FindingError, "", "CRD %s scope changed from %s to %s, which the API server does not allow",
+			name, oldCRD.Spec.Scope, newCRD.Spec.Scope)
+	}
+
+	newVersions := make(map[string]apiextv1.CustomResourceDefinitionVersion, len(newCRD.Spec.Versions))
+	newStorage := ""
+	for _, v := range newCRD.Spec.Versions {
+		newVersions[v.Name] = v
+		if v.Storage {
+			newStorage = v.Name
+		}
+	}
+	// Objects may be stored in any version that was a storage version.
+	stored := make(map[string]bool, len(oldCRD.Status.StoredVersions)+1)
+	for _, v := range oldCRD.Status.StoredVersions {
+		stored[v] = true
+	}
+	for _, oldVersion := range oldCRD.Spec.Versions {
+		newVersion, ok := newVersions[oldVersion.Name]
+		switch {
+		case !ok && (oldVersion.Storage || stored[oldVersion.Name]):
+			c.addFinding(FindingError, fmt.Sprintf("keep serving %s, and migrate stored objects to %s before removing it "+
+				"in a later release", oldVersion.Name, newStorage),
+				"CRD %s version %s was removed, but custom resources are stored in it", name, oldVersion.Name)
+		case !ok && oldVersion.Served:
+			c.addFinding(FindingError, "deprecate the version and stop serving it in a release before removing it",
+				"CRD %s served version %s was removed, which breaks clients of it", name, oldVersion.Name)
+		case ok && oldVersion.Served && !newVersion.Served:
+			c.addFinding(FindingWarning, "", "CRD %s version %s is no longer served, which breaks clients of it",
+				name, oldVersion.Name)
+		}
+		if !ok {
+			continue
+		}
+		if oldVersion.Storage && !newVersion.Storage {
+			c.addFinding(FindingWarning, fmt.Sprintf("migrate stored objects to %s before removing %s in a later release",
+				newStorage, oldVersion.Name),
+				"CRD %s storage version changed from %s to %s", name, oldVersion.Name, newStorage)
+		}
+		if oldVersion.Schema != nil && oldVersion.Schema.OpenAPIV3Schema != nil {
+			var newSchema *apiextv1.JSONSchemaProps
+			if newVersion.Schema != nil {
+				newSchema = newVersion.Schema.OpenAPIV3Schema
+			}
+			prefix := fmt.Sprintf("CRD %s version %s", name, oldVersion.Name)
+			c.checkSchema(prefix, "", oldVersion.Schema.OpenAPIV3Schema, newSchema)
+		}
+	}
+}
+
+// checkSchema reports changes from oldSchema to newSchema, the schemas of the field at path, that make
+// objects valid under oldSchema invalid.
+func (c *CRDUpgradeCheck) checkSchema(prefix, path string, oldSchema, newSchema *apiextv1.JSONSchemaProps) {
+	field := strings.TrimPrefix(path, ".")
+	if field == "" {
+		field = "<root>"
+	}
+	tightened := func(format string, args ...interface{}) {
+		c.addFinding(FindingError, "relax the validation, or add a conversion that makes existing objects valid",
+			"%s field %s %s, so existing objects may become invalid", prefix, field, fmt.Sprintf(format, args...))
+	}
+	if newSchema == nil {
+		if len(oldSchema.Properties) != 0 || oldSchema.Type != "" {
+			tightened("has no schema")
+		}
+		return
+	}
+
+	if oldSchema.Type != newSchema.Type && oldSchema.Type != "" {
+		tightened("changed type from %q to %q", oldSchema.Type, newSchema.Type)
+		return
+	}
+	if oldSchema.Nullable && !newSchema.Nullable {
+		tightened("is no longer nullable")
+	}
+	if preservesUnknownFields(oldSchema) && !preservesUnknownFields(newSchema) {
+		tightened("no longer preserves unknown fields, so they are pruned")
+	}
+
+	checkLowerBound := func(desc string, oldMin, newMin *float64, oldExclusive, newExclusive bool) {
+		switch {
+		case newMin == nil:
+		case oldMin == nil:
+			tightened("has a new %s of %v", desc, *newMin)
+		case *newMin > *oldMin || (*newMin == *oldMin && newExclusive && !oldExclusive):
+			tightened("%s increased from %v to %v", desc, *oldMin, *newMin)
+		}
+	}
+	checkUpperBound := func(desc string, oldMax, newMax *float64, oldExclusive, newExclusive bool) {
+		switch {
+		case newMax == nil:
+		case oldMax == nil:
+			tightened("has a new %s of %v", desc, *newMax)
+		case *newMax < *oldMax || (*newMax == *oldMax && newExclusive && !oldExclusive):
+			tightened("%s decreased from %v to %v", desc, *oldMax, *newMax)
+		}
+	}
+	checkLowerBound("minimum", oldSchema.Minimum, newSchema.Minimum, oldSchema.ExclusiveMinimum, newSchema.ExclusiveMinimum)
+	checkUpperBound("maximum", oldSchema.Maximum, newSchema.Maximum, oldSchema.ExclusiveMaximum, newSchema.ExclusiveMaximum)
+	checkLowerBound("minLength", toFloat(oldSchema.MinLength), toFloat(newSchema.MinLength), false, false)
+	checkUpperBound("maxLength", toFloat(oldSchema.MaxLength), toFloat(newSchema.MaxLength), false, false)
+	checkLowerBound("minItems", toFloat(oldSchema.MinItems), toFloat(newSchema.MinItems), false, false)
+	checkUpperBound("maxItems", toFloat(oldSchema.MaxItems), toFloat(newSchema.MaxItems), false, false)
+	checkLowerBound("minProperties", toFloat(oldSchema.MinProperties), toFloat(newSchema.MinProperties), false, false)
+	checkUpperBound("maxProperties", toFloat(oldSchema.MaxProperties), toFloat(newSchema.MaxProperties), false, false)
+	if newSchema.Pattern != "" && newSchema.Pattern != oldSchema.Pattern {
+		tightened("pattern changed from %q to %q", oldSchema.Pattern, newSchema.Pattern)
+	}
+	if newSchema.UniqueItems && !oldSchema.UniqueItems {
+		tightened("requires unique items")
+	}
+	if len(newSchema.Enum) != 0 {
+		if len(oldSchema.Enum) == 0 {
+			tightened("has a new enum")
+		} else if removed := removedEnumValues(oldSchema.Enum, newSchema.Enum); len(removed) != 0 {
+			tightened("enum values %s were removed", strings.Join(removed, ", "))
+		}
+	}
+
+	oldRequired := make(map[string]bool, len(oldSchema.Required))
+	for _, r := range oldSchema.Required {
+		oldRequired[r] = true
+	}
+	for _, r := range newSchema.Required {
+		if !oldRequired[r] {
+			tightened("requires new field %s", r)
+		}
+	}
+
+	names := make([]string, 0, len(oldSchema.Properties))
+	for name := range oldSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldProp := oldSchema.Properties[name]
+		newProp, ok := newSchema.Properties[name]
+		if !ok {
+			if !preservesUnknownFields(newSchema) {
+				c.addFinding(FindingError, "keep the field, deprecating it in its description, until a new API version",
+					"%s field %s was removed, so it is pruned from existing objects", prefix, strings.TrimPrefix(path+"."+name, "."))
+			}
+			continue
+		}
+		c.checkSchema(prefix, path+"."+name, &oldProp, &newProp)
+	}
+	if oldSchema.Items != nil && oldSchema.Items.Schema != nil && newSchema.Items != nil {
+		c.checkSchema(prefix, path+"[*]", oldSchema.Items.Schema, newSchema.Items.Schema)
+	}
+	if oldAdditional, newAdditional := oldSchema.AdditionalProperties, newSchema.AdditionalProperties; oldAdditional != nil &&
+		oldAdditional.Schema != nil && newAdditional != nil {
+		if newAdditional.Schema != nil {
+			c.checkSchema(prefix, path+".*", oldAdditional.Schema, newAdditional.Schema)
+		} else if !newAdditional.Allows {
+			tightened("no longer allows additional properties")
+		}
+	}
+}
+
+func preservesUnknownFields(s *apiextv1.JSONSchemaProps) bool {
+	return s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields
+}
+
+func toFloat(i *int64) *float64 {
+	if i == nil {
+		return nil
+	}
+	f := float64(*i)
+	return &f
+}
+
+// removedEnumValues returns the JSON values of oldEnum not in newEnum.
+func removedEnumValues(oldEnum, newEnum []apiextv1.JSON) (removed []string) {
+	values := make(map[string]bool, len(newEnum))
+	for _, v := range newEnum {
+		values[string(v.Raw)] = true
+	}
+	for _, v := range oldEnum {
+		if !values[string(v.Raw)] {
+			removed = append(removed, string(v.Raw))
+		}
+	}
+	return removed
+}
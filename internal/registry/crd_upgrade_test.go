@@ -0,0 +1,170 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const previousCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Memcached
+    plural: memcacheds
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+                minimum: 1
+              image:
+                type: string
+              mode:
+                type: string
+                enum: ["fast", "safe"]
+              nodes:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    name:
+                      type: string
+`
+
+var _ = Describe("CheckCRDUpgrade", func() {
+	var previous, current *apiextv1.CustomResourceDefinition
+
+	BeforeEach(func() {
+		previous = &apiextv1.CustomResourceDefinition{}
+		Expect(yaml.Unmarshal([]byte(previousCRD), previous)).To(Succeed())
+		current = previous.DeepCopy()
+	})
+
+	check := func() []Finding {
+		c, err := CheckCRDUpgrade(
+			&apimanifests.Bundle{V1CRDs: []*apiextv1.CustomResourceDefinition{previous}},
+			&apimanifests.Bundle{V1CRDs: []*apiextv1.CustomResourceDefinition{current}},
+		)
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return c.Findings
+	}
+	messages := func(findings []Finding) (msgs []string) {
+		for _, f := range findings {
+			msgs = append(msgs, f.Level+": "+f.Message)
+		}
+		return msgs
+	}
+	spec := func(crd *apiextv1.CustomResourceDefinition) apiextv1.JSONSchemaProps {
+		return crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+	}
+	setSpec := func(s apiextv1.JSONSchemaProps) {
+		current.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = s
+	}
+
+	It("passes unchanged and loosened CRDs", func() {
+		Expect(check()).To(BeEmpty())
+
+		s := spec(current)
+		size := s.Properties["size"]
+		size.Minimum = nil
+		s.Properties["size"] = size
+		s.Properties["replicas"] = apiextv1.JSONSchemaProps{Type: "integer"}
+		setSpec(s)
+		current.Spec.Versions = append(current.Spec.Versions, apiextv1.CustomResourceDefinitionVersion{
+			Name: "v1beta1", Served: true,
+		})
+		Expect(check()).To(BeEmpty())
+	})
+	It("fails a removed CRD", func() {
+		current.SetName("backups.cache.example.com")
+		Expect(messages(check())).To(ConsistOf(
+			"error: CRD memcacheds.cache.example.com was removed, so OLM deletes it and its custom resources on upgrade"))
+	})
+	It("fails removed fields and tightened validation", func() {
+		s := spec(current)
+		delete(s.Properties, "image")
+		size := s.Properties["size"]
+		size.Type = "string"
+		s.Properties["size"] = size
+		s.Properties["mode"] = apiextv1.JSONSchemaProps{Type: "string", Enum: []apiextv1.JSON{{Raw: []byte(`"safe"`)}}}
+		nodes := s.Properties["nodes"]
+		nodes.Items.Schema.Required = []string{"name"}
+		s.Properties["nodes"] = nodes
+		setSpec(s)
+
+		prefix := "error: CRD memcacheds.cache.example.com version v1alpha1 field "
+		Expect(messages(check())).To(ConsistOf(
+			prefix+"spec.image was removed, so it is pruned from existing objects",
+			prefix+`spec.mode enum values "fast" were removed, so existing objects may become invalid`,
+			prefix+"spec.nodes[*] requires new field name, so existing objects may become invalid",
+			prefix+`spec.size changed type from "integer" to "string", so existing objects may become invalid`,
+		))
+	})
+	It("fails a raised minimum", func() {
+		s := spec(current)
+		size := s.Properties["size"]
+		minimum := 3.0
+		size.Minimum = &minimum
+		s.Properties["size"] = size
+		setSpec(s)
+		Expect(messages(check())).To(ConsistOf("error: CRD memcacheds.cache.example.com version v1alpha1 field " +
+			"spec.size minimum increased from 1 to 3, so existing objects may become invalid"))
+	})
+	It("fails a removed storage version and warns of a storage version change", func() {
+		v1 := current.Spec.Versions[0]
+		v1.Name = "v1"
+		current.Spec.Versions = []apiextv1.CustomResourceDefinitionVersion{v1}
+		findings := check()
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Level).To(Equal(FindingError))
+		Expect(findings[0].Message).To(Equal("CRD memcacheds.cache.example.com version v1alpha1 was removed, " +
+			"but custom resources are stored in it"))
+
+		v1alpha1 := previous.Spec.Versions[0]
+		v1alpha1.Storage = false
+		current.Spec.Versions = []apiextv1.CustomResourceDefinitionVersion{v1alpha1, v1}
+		Expect(messages(check())).To(ConsistOf(
+			"warning: CRD memcacheds.cache.example.com storage version changed from v1alpha1 to v1"))
+	})
+	It("fails a removed version that objects were stored in", func() {
+		previous.Spec.Versions[0].Storage = false
+		previous.Spec.Versions = append(previous.Spec.Versions, apiextv1.CustomResourceDefinitionVersion{
+			Name: "v1", Served: true, Storage: true,
+		})
+		previous.Status.StoredVersions = []string{"v1alpha1", "v1"}
+		current = previous.DeepCopy()
+		current.Spec.Versions = current.Spec.Versions[1:]
+		findings := check()
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Message).To(ContainSubstring("custom resources are stored in it"))
+	})
+})
@@ -0,0 +1,64 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/operator-framework/operator-sdk/internal/migrate"
+)
+
+// NewDeprecatedAPIsValidator returns a validator that checks the manifests of a bundle do not use API
+// versions removed in Kubernetes release k8sVersion, and warns of those deprecated in it.
+func NewDeprecatedAPIsValidator(k8sVersion string) interfaces.Validator {
+	return interfaces.ValidatorFunc(func(objs ...interface{}) (results []apierrors.ManifestResult) {
+		for _, obj := range objs {
+			switch v := obj.(type) {
+			case *apimanifests.Bundle:
+				results = append(results, validateBundleAPIs(v, k8sVersion))
+			}
+		}
+		return results
+	})
+}
+
+func validateBundleAPIs(bundle *apimanifests.Bundle, k8sVersion string) apierrors.ManifestResult {
+	result := apierrors.ManifestResult{Name: bundle.Name}
+	target, err := version.ParseGeneric(k8sVersion)
+	if err != nil {
+		result.Add(apierrors.ErrInvalidParse(fmt.Sprintf("invalid Kubernetes version %q: %v", k8sVersion, err), k8sVersion))
+		return result
+	}
+	for _, obj := range bundle.Objects {
+		api, ok := migrate.LookupDeprecatedAPI(obj.GroupVersionKind())
+		if !ok {
+			continue
+		}
+		msg := fmt.Sprintf("%s %q: %s", obj.GetKind(), obj.GetName(), api)
+		switch api.Status(target) {
+		case migrate.StatusRemoved:
+			result.Add(apierrors.ErrInvalidBundle(msg, obj.GetName()))
+		case migrate.StatusDeprecated:
+			// WarnInvalidBundle returns an error in this API version.
+			result.Add(apierrors.NewWarn(apierrors.ErrorInvalidBundle, msg, "", obj.GetName()))
+		}
+	}
+	return result
+}
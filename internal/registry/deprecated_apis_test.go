@@ -0,0 +1,60 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("DeprecatedAPIsValidator", func() {
+	var bundle *apimanifests.Bundle
+
+	BeforeEach(func() {
+		crd := &unstructured.Unstructured{}
+		crd.SetAPIVersion("apiextensions.k8s.io/v1beta1")
+		crd.SetKind("CustomResourceDefinition")
+		crd.SetName("memcacheds.cache.example.com")
+		pdb := &unstructured.Unstructured{}
+		pdb.SetAPIVersion("policy/v1beta1")
+		pdb.SetKind("PodDisruptionBudget")
+		pdb.SetName("memcached-operator")
+		svc := &unstructured.Unstructured{}
+		svc.SetAPIVersion("v1")
+		svc.SetKind("Service")
+		svc.SetName("memcached-operator-metrics")
+		bundle = &apimanifests.Bundle{Name: "memcached-operator", Objects: []*unstructured.Unstructured{crd, pdb, svc}}
+	})
+
+	It("fails on removed APIs and warns of deprecated APIs", func() {
+		results := NewDeprecatedAPIsValidator("v1.22").Validate(bundle)
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Errors).To(HaveLen(1))
+		Expect(results[0].Errors[0].Detail).To(ContainSubstring(`CustomResourceDefinition "memcacheds.cache.example.com"`))
+		Expect(results[0].Warnings).To(HaveLen(1))
+		Expect(results[0].Warnings[0].Detail).To(ContainSubstring(`PodDisruptionBudget "memcached-operator"`))
+	})
+	It("passes APIs supported by the release", func() {
+		results := NewDeprecatedAPIsValidator("v1.15").Validate(bundle)
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].HasError()).To(BeFalse())
+		Expect(results[0].HasWarn()).To(BeFalse())
+	})
+	It("fails on an invalid release", func() {
+		Expect(NewDeprecatedAPIsValidator("latest").Validate(bundle)[0].HasError()).To(BeTrue())
+	})
+})
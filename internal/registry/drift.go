@@ -0,0 +1,224 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Changes of drifted bundle files.
+const (
+	// DriftMissing files are generated from the project, but are not in the bundle.
+	DriftMissing = "missing"
+	// DriftExtra files are in the bundle, but are not generated from the project.
+	DriftExtra = "extra"
+	// DriftModified files differ between the bundle and the project.
+	DriftModified = "modified"
+)
+
+// BundleDrift describes the differences between the files of a bundle and those generated from its project,
+// ex. manifests edited by hand, or generated before the project's RBAC changed.
+type BundleDrift struct {
+	Drifted bool        `json:"drifted"`
+	Files   []FileDrift `json:"files,omitempty"`
+}
+
+// FileDrift is a bundle file that differs from the project.
+type FileDrift struct {
+	// Path is the file's path relative to the bundle directory.
+	Path   string       `json:"path"`
+	Change string       `json:"change"`
+	Fields []FieldDrift `json:"fields,omitempty"`
+}
+
+// FieldDrift is a field of a modified manifest, and its value in the bundle and generated from the project.
+// A value that is not set is omitted.
+type FieldDrift struct {
+	Path      string      `json:"path"`
+	Bundle    interface{} `json:"bundle,omitempty"`
+	Generated interface{} `json:"generated,omitempty"`
+}
+
+// driftIgnoredFields are not compared in manifests of a kind, since they are set outside of bundle generation:
+// replaces when a bundle is upgraded, and createdAt by release tooling.
+var driftIgnoredFields = map[string][][]string{
+	"ClusterServiceVersion": {
+		{"spec", "replaces"},
+		{"metadata", "annotations", "createdAt"},
+	},
+}
+
+// CompareBundleFiles compares the files at paths relative to bundleDir and generatedDir. Paths that are
+// directories are compared file by file. Manifests are compared field by field, ignoring formatting.
+func CompareBundleFiles(bundleDir, generatedDir string, paths ...string) (*BundleDrift, error) {
+	bundleFiles, err := listFiles(bundleDir, paths)
+	if err != nil {
+		return nil, err
+	}
+	generatedFiles, err := listFiles(generatedDir, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &BundleDrift{}
+	for _, path := range unionKeys(bundleFiles, generatedFiles) {
+		switch {
+		case !bundleFiles[path]:
+			d.Files = append(d.Files, FileDrift{Path: path, Change: DriftMissing})
+		case !generatedFiles[path]:
+			d.Files = append(d.Files, FileDrift{Path: path, Change: DriftExtra})
+		default:
+			fields, modified, err := compareFiles(filepath.Join(bundleDir, path), filepath.Join(generatedDir, path))
+			if err != nil {
+				return nil, err
+			}
+			if modified {
+				d.Files = append(d.Files, FileDrift{Path: path, Change: DriftModified, Fields: fields})
+			}
+		}
+	}
+	d.Drifted = len(d.Files) != 0
+	return d, nil
+}
+
+// listFiles returns the slash-separated paths of the files at paths in dir.
+func listFiles(dir string, paths []string) (map[string]bool, error) {
+	files := map[string]bool{}
+	for _, path := range paths {
+		err := filepath.Walk(filepath.Join(dir, path), func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			files[filepath.ToSlash(rel)] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// unionKeys returns the keys of a and b, sorted.
+func unionKeys(a, b map[string]bool) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if !a[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// compareFiles returns the fields that differ between the manifests in the files at bundlePath and
+// generatedPath, and whether the files differ. Files that are not single YAML or JSON objects are
+// compared byte by byte, without fields.
+func compareFiles(bundlePath, generatedPath string) ([]FieldDrift, bool, error) {
+	bundleData, err := ioutil.ReadFile(bundlePath)
+	if err != nil {
+		return nil, false, err
+	}
+	generatedData, err := ioutil.ReadFile(generatedPath)
+	if err != nil {
+		return nil, false, err
+	}
+	var bundleObj, generatedObj map[string]interface{}
+	if yaml.Unmarshal(bundleData, &bundleObj) != nil || yaml.Unmarshal(generatedData, &generatedObj) != nil {
+		return nil, !bytes.Equal(bundleData, generatedData), nil
+	}
+
+	for _, obj := range []map[string]interface{}{bundleObj, generatedObj} {
+		kind, _ := obj["kind"].(string)
+		for _, field := range driftIgnoredFields[kind] {
+			removeField(obj, field)
+		}
+	}
+	var fields []FieldDrift
+	compareValues("", bundleObj, generatedObj, &fields)
+	return fields, len(fields) != 0, nil
+}
+
+func removeField(obj map[string]interface{}, field []string) {
+	for _, name := range field[:len(field)-1] {
+		if obj, _ = obj[name].(map[string]interface{}); obj == nil {
+			return
+		}
+	}
+	delete(obj, field[len(field)-1])
+}
+
+// compareValues appends the fields at or under path that differ between a and b to fields.
+func compareValues(path string, a, b interface{}, fields *[]FieldDrift) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, isMap := b.(map[string]interface{}); isMap {
+			aKeys, bKeys := map[string]bool{}, map[string]bool{}
+			for k := range av {
+				aKeys[k] = true
+			}
+			for k := range bv {
+				bKeys[k] = true
+			}
+			for _, k := range unionKeys(aKeys, bKeys) {
+				compareValues(joinFieldPath(path, k), av[k], bv[k], fields)
+			}
+			return
+		}
+	case []interface{}:
+		if bv, isList := b.([]interface{}); isList && len(av) == len(bv) {
+			for i := range av {
+				compareValues(fmt.Sprintf("%s[%d]", path, i), av[i], bv[i], fields)
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		*fields = append(*fields, FieldDrift{Path: path, Bundle: a, Generated: b})
+	}
+}
+
+func joinFieldPath(path, field string) string {
+	if strings.ContainsAny(field, ".[]") {
+		field = fmt.Sprintf("[%q]", field)
+		return path + field
+	}
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CompareBundleFiles", func() {
+	var bundleDir, generatedDir string
+
+	BeforeEach(func() {
+		var err error
+		bundleDir, err = ioutil.TempDir("", "bundle")
+		Expect(err).NotTo(HaveOccurred())
+		generatedDir, err = ioutil.TempDir("", "generated")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(bundleDir)).To(Succeed())
+		Expect(os.RemoveAll(generatedDir)).To(Succeed())
+	})
+
+	writeFile := func(dir, path, content string) {
+		path = filepath.Join(dir, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	const csv = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.2
+spec:
+  install:
+    spec:
+      clusterPermissions:
+      - rules:
+        - apiGroups: [cache.example.com]
+          resources: [memcacheds]
+          verbs: [get, list]
+`
+
+	It("reports no drift for the same manifests, ignoring formatting and set-on-release fields", func() {
+		writeFile(bundleDir, "manifests/csv.yaml", csv+"  replaces: memcached-operator.v0.0.1\n")
+		writeFile(generatedDir, "manifests/csv.yaml", `{"apiVersion": "operators.coreos.com/v1alpha1",
+"kind": "ClusterServiceVersion", "metadata": {"name": "memcached-operator.v0.0.2"},
+"spec": {"install": {"spec": {"clusterPermissions": [{"rules": [{"apiGroups": ["cache.example.com"],
+"resources": ["memcacheds"], "verbs": ["get", "list"]}]}]}}}}`)
+		Expect(CompareBundleFiles(bundleDir, generatedDir, "manifests")).To(Equal(&BundleDrift{}))
+	})
+	It("reports missing, extra, and modified files", func() {
+		writeFile(bundleDir, "manifests/csv.yaml", csv)
+		writeFile(generatedDir, "manifests/csv.yaml", strings.Replace(csv, "[get, list]", "[get, list, watch]", 1))
+		writeFile(bundleDir, "manifests/old-service.yaml", "kind: Service\n")
+		writeFile(generatedDir, "manifests/metrics-service.yaml", "kind: Service\n")
+		writeFile(bundleDir, "tests/scorecard/config.yaml", "kind: Configuration\n")
+		writeFile(generatedDir, "tests/scorecard/config.yaml", "kind: Configuration\nstages: []\n")
+		writeFile(bundleDir, "metadata/annotations.yaml", "ignored: true\n")
+
+		Expect(CompareBundleFiles(bundleDir, generatedDir, "manifests", "tests/scorecard/config.yaml")).To(Equal(&BundleDrift{
+			Drifted: true,
+			Files: []FileDrift{
+				{Path: "manifests/csv.yaml", Change: DriftModified, Fields: []FieldDrift{{
+					Path:      "spec.install.spec.clusterPermissions[0].rules[0].verbs",
+					Bundle:    []interface{}{"get", "list"},
+					Generated: []interface{}{"get", "list", "watch"},
+				}}},
+				{Path: "manifests/metrics-service.yaml", Change: DriftMissing},
+				{Path: "manifests/old-service.yaml", Change: DriftExtra},
+				{Path: "tests/scorecard/config.yaml", Change: DriftModified, Fields: []FieldDrift{
+					{Path: "stages", Generated: []interface{}{}},
+				}},
+			},
+		}))
+	})
+	It("names fields with dots, and compares files that are not manifests byte by byte", func() {
+		writeFile(bundleDir, "manifests/cm.yaml", "kind: ConfigMap\ndata:\n  config.yaml: a\n")
+		writeFile(generatedDir, "manifests/cm.yaml", "kind: ConfigMap\ndata:\n  config.yaml: b\n")
+		writeFile(bundleDir, "manifests/list.yaml", "- a\n")
+		writeFile(generatedDir, "manifests/list.yaml", "- b\n")
+		Expect(CompareBundleFiles(bundleDir, generatedDir, "manifests")).To(Equal(&BundleDrift{
+			Drifted: true,
+			Files: []FileDrift{
+				{Path: "manifests/cm.yaml", Change: DriftModified, Fields: []FieldDrift{
+					{Path: `data["config.yaml"]`, Bundle: "a", Generated: "b"},
+				}},
+				{Path: "manifests/list.yaml", Change: DriftModified},
+			},
+		}))
+	})
+})
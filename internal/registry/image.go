@@ -24,6 +24,8 @@ import (
 	registryimage "github.com/operator-framework/operator-registry/pkg/image"
 	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
 )
 
 // ExtractBundleImage returns a bundle directory containing files extracted
@@ -63,7 +65,7 @@ func ExtractBundleImage(ctx context.Context, logger *log.Entry, image string, lo
 
 	// Pull the image if it isn't present locally.
 	if !local {
-		if err := reg.Pull(ctx, registryimage.SimpleReference(image)); err != nil {
+		if err := pullImage(ctx, reg, image); err != nil {
 			return "", fmt.Errorf("error pulling image %s: %v", image, err)
 		}
 	}
@@ -95,7 +97,7 @@ func GetImageLabels(ctx context.Context, logger *log.Entry, image string, local
 
 	// Pull the image if it isn't present locally.
 	if !local {
-		if err := reg.Pull(ctx, registryimage.SimpleReference(image)); err != nil {
+		if err := pullImage(ctx, reg, image); err != nil {
 			return nil, fmt.Errorf("error pulling image %s: %v", image, err)
 		}
 	}
@@ -108,3 +110,10 @@ func GetImageLabels(ctx context.Context, logger *log.Entry, image string, local
 
 	return labels, err
 }
+
+// pullImage pulls image into reg, recording a span of the pull.
+func pullImage(ctx context.Context, reg *containerdregistry.Registry, image string) (err error) {
+	ctx, span := tracing.Start(ctx, "pull image", tracing.String("image", image))
+	defer func() { span.End(err) }()
+	return reg.Pull(ctx, registryimage.SimpleReference(image))
+}
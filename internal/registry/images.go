@@ -0,0 +1,152 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/containerd/containerd/remotes"
+	"github.com/docker/distribution/reference"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// containerImageAnnotation is the CSV annotation naming the operator's image.
+const containerImageAnnotation = "containerImage"
+
+const defaultImageResolveTimeout = 30 * time.Second
+
+// ImageValidatorOptions configure the validator returned by NewImageValidator.
+type ImageValidatorOptions struct {
+	// RequireDigest makes images not pinned by digest errors.
+	RequireDigest bool
+	// RegistryConfigDir is the directory of the Docker config.json registry credentials are read from.
+	// If empty, $DOCKER_CONFIG, then ~/.docker, is used.
+	RegistryConfigDir string
+	// Timeout is how long resolving each image may take. Defaults to 30s.
+	Timeout time.Duration
+	// Resolver, if set, resolves images instead of a resolver using RegistryConfigDir's credentials.
+	Resolver remotes.Resolver
+}
+
+// NewImageValidator returns a validator that checks every image referenced by a bundle's CSV,
+// in its deployments, relatedImages, and containerImage annotation, exists in its registry,
+// by requesting the image's manifest with the registry credentials of opts.
+func NewImageValidator(opts ImageValidatorOptions) interfaces.Validator {
+	return interfaces.ValidatorFunc(func(objs ...interface{}) (results []apierrors.ManifestResult) {
+		for _, obj := range objs {
+			switch v := obj.(type) {
+			case *apimanifests.Bundle:
+				results = append(results, validateBundleImages(v, opts))
+			}
+		}
+		return results
+	})
+}
+
+func validateBundleImages(bundle *apimanifests.Bundle, opts ImageValidatorOptions) apierrors.ManifestResult {
+	result := apierrors.ManifestResult{Name: bundle.Name}
+	if bundle.CSV == nil {
+		result.Add(apierrors.ErrInvalidBundle("no ClusterServiceVersion in bundle", bundle.Name))
+		return result
+	}
+	csvName := bundle.CSV.GetName()
+
+	resolver := opts.Resolver
+	if resolver == nil {
+		var err error
+		if resolver, err = containerdregistry.NewResolver(opts.RegistryConfigDir, false, nil); err != nil {
+			result.Add(apierrors.ErrIOError(fmt.Sprintf("error creating image resolver: %v", err), csvName))
+			return result
+		}
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultImageResolveTimeout
+	}
+
+	refs := bundleImageRefs(bundle)
+	images := make([]string, 0, len(refs))
+	for image := range refs {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	for _, image := range images {
+		source := refs[image][0]
+		named, err := reference.ParseNormalizedNamed(image)
+		if err != nil {
+			result.Add(apierrors.ErrInvalidCSV(fmt.Sprintf("%s has invalid image %q: %v", source, image, err), csvName))
+			continue
+		}
+		if _, ok := named.(reference.Digested); !ok && opts.RequireDigest {
+			result.Add(apierrors.ErrInvalidCSV(fmt.Sprintf("%s image %q is not pinned by digest", source, image), csvName))
+		}
+		if err := resolveImage(resolver, reference.TagNameOnly(named).String(), timeout); err != nil {
+			result.Add(apierrors.ErrInvalidCSV(fmt.Sprintf("%s image %q cannot be pulled: %v", source, image, err), csvName))
+		}
+	}
+	return result
+}
+
+func resolveImage(resolver remotes.Resolver, ref string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_, _, err := resolver.Resolve(ctx, ref)
+	return err
+}
+
+// bundleImageRefs maps each image referenced by bundle's CSV to descriptions of where it is referenced.
+func bundleImageRefs(bundle *apimanifests.Bundle) map[string][]string {
+	refs := map[string][]string{}
+	add := func(image, source string) {
+		if image != "" {
+			refs[image] = append(refs[image], source)
+		}
+	}
+
+	csv := bundle.CSV
+	add(csv.GetAnnotations()[containerImageAnnotation], "containerImage annotation")
+	for _, dep := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		podSpec := dep.Spec.Template.Spec
+		for _, c := range podSpec.InitContainers {
+			add(c.Image, fmt.Sprintf("deployment %s init container %s", dep.Name, c.Name))
+		}
+		for _, c := range podSpec.Containers {
+			add(c.Image, fmt.Sprintf("deployment %s container %s", dep.Name, c.Name))
+		}
+	}
+	// relatedImages is read from the CSV object, since it is not a field of the CSV type.
+	for _, obj := range bundle.Objects {
+		if obj.GetKind() != v1alpha1.ClusterServiceVersionKind {
+			continue
+		}
+		related, _, _ := unstructured.NestedSlice(obj.Object, "spec", "relatedImages")
+		for _, r := range related {
+			if m, ok := r.(map[string]interface{}); ok {
+				name, _ := m["name"].(string)
+				image, _ := m["image"].(string)
+				add(image, fmt.Sprintf("relatedImages entry %s", name))
+			}
+		}
+	}
+	return refs
+}
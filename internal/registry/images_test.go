@@ -0,0 +1,125 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/containerd/containerd/remotes/docker"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const testDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+var _ = Describe("ImageValidator", func() {
+	var (
+		reg    *httptest.Server
+		host   string
+		bundle *apimanifests.Bundle
+		opts   ImageValidatorOptions
+	)
+
+	BeforeEach(func() {
+		// The registry serves the manifests of example/operator:v0.0.1 and any digest of example/operator.
+		reg = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v2/example/operator/manifests/v0.0.1" &&
+				!strings.HasPrefix(r.URL.Path, "/v2/example/operator/manifests/sha256:") {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Header().Set("Docker-Content-Digest", testDigest)
+			w.Header().Set("Content-Length", "2")
+		}))
+		host = strings.TrimPrefix(reg.URL, "http://")
+		opts = ImageValidatorOptions{Resolver: docker.NewResolver(docker.ResolverOptions{
+			Hosts: docker.ConfigureDefaultRegistries(docker.WithPlainHTTP(docker.MatchLocalhost)),
+		})}
+
+		csv := &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.SetAnnotations(map[string]string{containerImageAnnotation: host + "/example/operator:v0.0.1"})
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []v1alpha1.StrategyDeploymentSpec{{
+			Name: "memcached-operator",
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "manager", Image: host + "/example/operator:v0.0.1"}},
+			}}},
+		}}
+		bundle = &apimanifests.Bundle{Name: "memcached-operator", CSV: csv}
+	})
+	AfterEach(func() {
+		reg.Close()
+	})
+
+	validate := func() apierrors.ManifestResult {
+		results := NewImageValidator(opts).Validate(bundle)
+		Expect(results).To(HaveLen(1))
+		return results[0]
+	}
+	errorDetails := func(result apierrors.ManifestResult) (details []string) {
+		for _, err := range result.Errors {
+			details = append(details, err.Detail)
+		}
+		return details
+	}
+
+	It("passes a bundle whose images exist", func() {
+		result := validate()
+		Expect(result.Errors).To(BeEmpty())
+		Expect(result.Warnings).To(BeEmpty())
+	})
+	It("fails images that do not exist", func() {
+		container := &bundle.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec.Containers[0]
+		container.Image = host + "/example/operator:v0.0.2"
+		Expect(errorDetails(validate())).To(ConsistOf(
+			ContainSubstring("deployment memcached-operator container manager image %q cannot be pulled", container.Image),
+		))
+	})
+	It("checks relatedImages", func() {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"relatedImages": []interface{}{
+				map[string]interface{}{"name": "memcached", "image": host + "/example/memcached:1.6"},
+			}},
+		}}
+		u.SetKind(v1alpha1.ClusterServiceVersionKind)
+		bundle.Objects = append(bundle.Objects, u)
+		Expect(errorDetails(validate())).To(ConsistOf(
+			ContainSubstring("relatedImages entry memcached image %q cannot be pulled", host+"/example/memcached:1.6"),
+		))
+	})
+	It("fails invalid image references", func() {
+		bundle.CSV.GetAnnotations()[containerImageAnnotation] = "Example/Operator"
+		Expect(errorDetails(validate())).To(ConsistOf(
+			ContainSubstring("containerImage annotation has invalid image \"Example/Operator\""),
+		))
+	})
+	It("requires digests if configured", func() {
+		opts.RequireDigest = true
+		bundle.CSV.GetAnnotations()[containerImageAnnotation] = host + "/example/operator@" + testDigest
+		Expect(errorDetails(validate())).To(ConsistOf(
+			ContainSubstring("deployment memcached-operator container manager image %q is not pinned by digest",
+				host+"/example/operator:v0.0.1"),
+		))
+	})
+})
@@ -0,0 +1,158 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+
+	"github.com/operator-framework/operator-sdk/internal/util/operatorhub"
+)
+
+// NewOperatorHubValidator returns a validator of the CSV metadata OperatorHub.io requires,
+// which allows the CSV's categories annotation to contain only categories.
+func NewOperatorHubValidator(categories []string) interfaces.Validator {
+	return interfaces.ValidatorFunc(func(objs ...interface{}) (results []apierrors.ManifestResult) {
+		for _, obj := range objs {
+			switch v := obj.(type) {
+			case *apimanifests.Bundle:
+				results = append(results, validateBundleOperatorHub(v, categories))
+			}
+		}
+		return results
+	})
+}
+
+func validateBundleOperatorHub(bundle *apimanifests.Bundle, categories []string) apierrors.ManifestResult {
+	result := apierrors.ManifestResult{Name: bundle.Name}
+	if bundle.CSV == nil {
+		result.Add(apierrors.ErrInvalidBundle("no ClusterServiceVersion in bundle", bundle.Name))
+		return result
+	}
+
+	csv := bundle.CSV
+	result.Add(validateOperatorHubCategories(csv, categories)...)
+	result.Add(validateOperatorHubCapabilities(csv)...)
+	result.Add(validateOperatorHubSupport(csv)...)
+	result.Add(validateOperatorHubIcon(csv)...)
+	result.Add(validateOperatorHubContacts(csv)...)
+	return result
+}
+
+// validateOperatorHubCategories checks that the CSV is listed under at least one allowed category.
+func validateOperatorHubCategories(csv *v1alpha1.ClusterServiceVersion, allowed []string) (errs []apierrors.Error) {
+	annotation, ok := csv.GetAnnotations()[operatorhub.CategoriesAnnotation]
+	if !ok || strings.TrimSpace(annotation) == "" {
+		return []apierrors.Error{apierrors.WarnInvalidCSV("categories annotation is not set, the operator will "+
+			"not be listed under any category", csv.GetName())}
+	}
+	for _, category := range operatorhub.SplitCategories(annotation) {
+		if !operatorhub.Contains(allowed, category) {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("categories annotation has unknown category %q, "+
+				"allowed categories are: %s", category, strings.Join(allowed, ", ")), csv.GetName()))
+		}
+	}
+	return errs
+}
+
+// validateOperatorHubCapabilities checks that the CSV declares a known capability level.
+func validateOperatorHubCapabilities(csv *v1alpha1.ClusterServiceVersion) (errs []apierrors.Error) {
+	capability, ok := csv.GetAnnotations()[operatorhub.CapabilitiesAnnotation]
+	if !ok {
+		return []apierrors.Error{apierrors.ErrInvalidCSV("capabilities annotation is not set", csv.GetName())}
+	}
+	if !operatorhub.Contains(operatorhub.Capabilities, capability) {
+		errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("capabilities annotation %q is not one of: %s",
+			capability, strings.Join(operatorhub.Capabilities, ", ")), csv.GetName()))
+	}
+	return errs
+}
+
+// validateOperatorHubSupport checks that the CSV states who supports the operator.
+func validateOperatorHubSupport(csv *v1alpha1.ClusterServiceVersion) (errs []apierrors.Error) {
+	if strings.TrimSpace(csv.GetAnnotations()[operatorhub.SupportAnnotation]) == "" {
+		errs = append(errs, apierrors.WarnInvalidCSV("support annotation is not set, users will not know who "+
+			"supports the operator", csv.GetName()))
+	}
+	return errs
+}
+
+// validateOperatorHubIcon checks that the CSV has exactly one icon, which OperatorHub.io renders as a square.
+func validateOperatorHubIcon(csv *v1alpha1.ClusterServiceVersion) (errs []apierrors.Error) {
+	if len(csv.Spec.Icon) != 1 {
+		return []apierrors.Error{apierrors.ErrInvalidCSV(fmt.Sprintf("spec.icon must have exactly one icon, has %d",
+			len(csv.Spec.Icon)), csv.GetName())}
+	}
+	icon := csv.Spec.Icon[0]
+	if _, ok := consoleIconMediaTypes[icon.MediaType]; !ok {
+		return []apierrors.Error{apierrors.ErrInvalidCSV(fmt.Sprintf("spec.icon has unsupported mediatype %q",
+			icon.MediaType), csv.GetName())}
+	}
+	data, err := base64.StdEncoding.DecodeString(icon.Data)
+	if err != nil {
+		return []apierrors.Error{apierrors.ErrInvalidCSV(fmt.Sprintf("spec.icon is not valid base64: %v", err), csv.GetName())}
+	}
+	if icon.MediaType == "image/svg+xml" {
+		return nil
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return []apierrors.Error{apierrors.ErrInvalidCSV(fmt.Sprintf("spec.icon cannot be decoded: %v", err), csv.GetName())}
+	}
+	if cfg.Width != cfg.Height {
+		errs = append(errs, apierrors.WarnInvalidCSV(fmt.Sprintf("spec.icon is %dx%d, but is displayed with a 1:1 "+
+			"aspect ratio", cfg.Width, cfg.Height), csv.GetName()))
+	}
+	return errs
+}
+
+// validateOperatorHubContacts checks the CSV's provider, maintainers, and links.
+func validateOperatorHubContacts(csv *v1alpha1.ClusterServiceVersion) (errs []apierrors.Error) {
+	if csv.Spec.Provider.Name == "" {
+		errs = append(errs, apierrors.ErrInvalidCSV("spec.provider.name must be set", csv.GetName()))
+	}
+	if len(csv.Spec.Maintainers) == 0 {
+		errs = append(errs, apierrors.ErrInvalidCSV("spec.maintainers must be set", csv.GetName()))
+	}
+	for i, m := range csv.Spec.Maintainers {
+		if m.Name == "" || m.Email == "" {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.maintainers[%d] must have a name and email", i),
+				csv.GetName()))
+		} else if _, err := mail.ParseAddress(m.Email); err != nil {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.maintainers[%d] email %q is invalid: %v",
+				i, m.Email, err), csv.GetName()))
+		}
+	}
+	for i, link := range csv.Spec.Links {
+		if link.Name == "" || link.URL == "" {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.links[%d] must have a name and url", i),
+				csv.GetName()))
+		} else if _, err := url.ParseRequestURI(link.URL); err != nil {
+			errs = append(errs, apierrors.ErrInvalidCSV(fmt.Sprintf("spec.links[%d] url %q is invalid: %v",
+				i, link.URL, err), csv.GetName()))
+		}
+	}
+	return errs
+}
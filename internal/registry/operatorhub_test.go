@@ -0,0 +1,97 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/operator-framework/operator-sdk/internal/util/operatorhub"
+)
+
+var _ = Describe("OperatorHubValidator", func() {
+	var (
+		bundle *apimanifests.Bundle
+		csv    *v1alpha1.ClusterServiceVersion
+	)
+
+	pngIcon := func(width, height int) v1alpha1.Icon {
+		buf := &bytes.Buffer{}
+		Expect(png.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, height)))).To(Succeed())
+		return v1alpha1.Icon{MediaType: "image/png", Data: base64.StdEncoding.EncodeToString(buf.Bytes())}
+	}
+
+	BeforeEach(func() {
+		csv = &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.SetAnnotations(map[string]string{
+			"categories":   "Database, Monitoring",
+			"capabilities": "Basic Install",
+			"support":      "Example",
+		})
+		csv.Spec.Icon = []v1alpha1.Icon{pngIcon(40, 40)}
+		csv.Spec.Provider = v1alpha1.AppLink{Name: "Example", URL: "https://example.com"}
+		csv.Spec.Maintainers = []v1alpha1.Maintainer{{Name: "Some Corp", Email: "corp@example.com"}}
+		csv.Spec.Links = []v1alpha1.AppLink{{Name: "Source", URL: "https://github.com/example/memcached-operator"}}
+		bundle = &apimanifests.Bundle{Name: "memcached-operator", CSV: csv}
+	})
+
+	validate := func(categories []string) apierrors.ManifestResult {
+		results := NewOperatorHubValidator(categories).Validate(bundle)
+		Expect(results).To(HaveLen(1))
+		return results[0]
+	}
+
+	It("passes a bundle with complete OperatorHub.io metadata", func() {
+		result := validate(operatorhub.DefaultCategories)
+		Expect(result.Errors).To(BeEmpty())
+		Expect(result.Warnings).To(BeEmpty())
+	})
+	It("fails when a category is not allowed", func() {
+		Expect(validate([]string{"Database"}).HasError()).To(BeTrue())
+	})
+	It("warns when no categories are set", func() {
+		delete(csv.GetAnnotations(), "categories")
+		result := validate(operatorhub.DefaultCategories)
+		Expect(result.HasError()).To(BeFalse())
+		Expect(result.HasWarn()).To(BeTrue())
+	})
+	It("fails when the capability level is unknown", func() {
+		csv.GetAnnotations()["capabilities"] = "Autopilot"
+		Expect(validate(operatorhub.DefaultCategories).HasError()).To(BeTrue())
+	})
+	It("warns when the icon is not square", func() {
+		csv.Spec.Icon = []v1alpha1.Icon{pngIcon(80, 40)}
+		result := validate(operatorhub.DefaultCategories)
+		Expect(result.HasError()).To(BeFalse())
+		Expect(result.HasWarn()).To(BeTrue())
+	})
+	It("fails when there is more than one icon", func() {
+		csv.Spec.Icon = append(csv.Spec.Icon, pngIcon(40, 40))
+		Expect(validate(operatorhub.DefaultCategories).HasError()).To(BeTrue())
+	})
+	It("fails when a maintainer email is invalid", func() {
+		csv.Spec.Maintainers[0].Email = "corp"
+		Expect(validate(operatorhub.DefaultCategories).HasError()).To(BeTrue())
+	})
+})
@@ -0,0 +1,151 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	apierrors "github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/operator-framework/operator-sdk/internal/migrate"
+	"github.com/operator-framework/operator-sdk/internal/util/operatorhub"
+)
+
+// Label keys used to select optional validators.
+const (
+	nameKey  = "name"
+	suiteKey = "suite"
+)
+
+// OptionalValidator is a bundle validator that is not run by default,
+// and must be selected by its labels.
+type OptionalValidator struct {
+	Labels      labels.Set
+	Description string
+	interfaces.Validator
+}
+
+// OptionalValidators is a set of OptionalValidator's.
+type OptionalValidators []OptionalValidator
+
+// DefaultOptionalValidators are all optional validators known to the SDK.
+var DefaultOptionalValidators = OptionalValidators{
+	{
+		Labels:      labels.Set{nameKey: "console", suiteKey: "console"},
+		Description: "Checks CSV fields rendered by the OpenShift console: descriptors, icon, description, examples, and display names.",
+		Validator:   ConsoleValidator,
+	},
+	{
+		Labels:      labels.Set{nameKey: operatorHubValidatorName, suiteKey: operatorHubValidatorName},
+		Description: "Checks CSV metadata required by OperatorHub.io: categories, capability level, support, icon, provider, and maintainers.",
+		Validator:   NewOperatorHubValidator(operatorhub.DefaultCategories),
+	},
+	{
+		Labels:      labels.Set{nameKey: imageValidatorName, suiteKey: imageValidatorName},
+		Description: "Checks every image referenced by the CSV exists in its registry, and is optionally pinned by digest.",
+		Validator:   NewImageValidator(ImageValidatorOptions{}),
+	},
+	{
+		Labels:      labels.Set{nameKey: deprecatedAPIsValidatorName, suiteKey: deprecatedAPIsValidatorName},
+		Description: "Checks manifests do not use Kubernetes API versions removed in a Kubernetes release, and warns of deprecated ones.",
+		Validator:   NewDeprecatedAPIsValidator(migrate.LatestRemovalRelease),
+	},
+}
+
+const (
+	operatorHubValidatorName    = "operatorhub"
+	imageValidatorName          = "images"
+	deprecatedAPIsValidatorName = "deprecated-apis"
+)
+
+// WithOperatorHubCategories returns vals with the OperatorHub.io validator replaced by one
+// that allows categories instead of the default categories.
+func (vals OptionalValidators) WithOperatorHubCategories(categories []string) OptionalValidators {
+	replaced := make(OptionalValidators, len(vals))
+	for i, val := range vals {
+		if val.Labels[nameKey] == operatorHubValidatorName {
+			val.Validator = NewOperatorHubValidator(categories)
+		}
+		replaced[i] = val
+	}
+	return replaced
+}
+
+// WithImageValidatorOptions returns vals with the image validator replaced by one configured by opts.
+func (vals OptionalValidators) WithImageValidatorOptions(opts ImageValidatorOptions) OptionalValidators {
+	replaced := make(OptionalValidators, len(vals))
+	for i, val := range vals {
+		if val.Labels[nameKey] == imageValidatorName {
+			val.Validator = NewImageValidator(opts)
+		}
+		replaced[i] = val
+	}
+	return replaced
+}
+
+// WithDeprecatedAPIsK8sVersion returns vals with the deprecated API validator replaced by one checking
+// APIs deprecated and removed in Kubernetes release k8sVersion.
+func (vals OptionalValidators) WithDeprecatedAPIsK8sVersion(k8sVersion string) OptionalValidators {
+	replaced := make(OptionalValidators, len(vals))
+	for i, val := range vals {
+		if val.Labels[nameKey] == deprecatedAPIsValidatorName {
+			val.Validator = NewDeprecatedAPIsValidator(k8sVersion)
+		}
+		replaced[i] = val
+	}
+	return replaced
+}
+
+// Select returns all validators in vals with labels matching selector.
+func (vals OptionalValidators) Select(selector labels.Selector) (selected OptionalValidators) {
+	for _, val := range vals {
+		if selector.Matches(val.Labels) {
+			selected = append(selected, val)
+		}
+	}
+	return selected
+}
+
+// Validate runs all validators in vals on bundle and returns their combined results.
+func (vals OptionalValidators) Validate(bundle *apimanifests.Bundle) (results []apierrors.ManifestResult) {
+	for _, val := range vals {
+		results = append(results, val.Validate(bundle)...)
+	}
+	return results
+}
+
+func (vals OptionalValidators) String() string {
+	out := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(out, 8, 4, 4, ' ', 0)
+	fmt.Fprintf(tw, "LABELS\tDESCRIPTION\n")
+	for _, val := range vals {
+		var kvs []string
+		for k, v := range val.Labels {
+			kvs = append(kvs, k+"="+v)
+		}
+		sort.Strings(kvs)
+		fmt.Fprintf(tw, "%s\t%s\n", strings.Join(kvs, ","), val.Description)
+	}
+	tw.Flush()
+
+	return out.String()
+}
@@ -0,0 +1,397 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+)
+
+// Risk levels of upgrade risks.
+const (
+	RiskHigh   = "high"
+	RiskMedium = "medium"
+	RiskLow    = "low"
+)
+
+// Categories of upgrade risks.
+const (
+	RiskCategoryCRDs         = "crds"
+	RiskCategoryPermissions  = "permissions"
+	RiskCategoryWebhooks     = "webhooks"
+	RiskCategoryInstallModes = "install-modes"
+)
+
+// UpgradeRisk is a change between two bundles that reviewers should check before publishing the newer one.
+type UpgradeRisk struct {
+	Category   string `json:"category"`
+	Level      string `json:"level"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// UpgradeReport describes the risks of upgrading an operator from one bundle to another: CRD changes,
+// added permissions, webhook changes, and install mode changes.
+type UpgradeReport struct {
+	From  string        `json:"from"`
+	To    string        `json:"to"`
+	Risks []UpgradeRisk `json:"risks,omitempty"`
+}
+
+// HasRisks returns true if r has any risks of level.
+func (r UpgradeReport) HasRisks(level string) bool {
+	for _, risk := range r.Risks {
+		if risk.Level == level {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *UpgradeReport) addRisk(category, level, suggestion, format string, args ...interface{}) {
+	r.Risks = append(r.Risks, UpgradeRisk{
+		Category:   category,
+		Level:      level,
+		Message:    fmt.Sprintf(format, args...),
+		Suggestion: suggestion,
+	})
+}
+
+// CheckUpgrade statically compares bundle with from, the bundle it upgrades from, and reports the risks
+// of the upgrade ordered by category.
+func CheckUpgrade(from, bundle *apimanifests.Bundle) (*UpgradeReport, error) {
+	if from.CSV == nil || bundle.CSV == nil {
+		return nil, fmt.Errorf("both bundles must contain a ClusterServiceVersion")
+	}
+	r := &UpgradeReport{From: from.CSV.GetName(), To: bundle.CSV.GetName()}
+	if err := r.checkCRDs(from, bundle); err != nil {
+		return nil, err
+	}
+	r.checkPermissions(from.CSV, bundle.CSV)
+	r.checkWebhooks(from.CSV, bundle.CSV)
+	r.checkInstallModes(from.CSV, bundle.CSV)
+	return r, nil
+}
+
+func (r *UpgradeReport) checkCRDs(from, bundle *apimanifests.Bundle) error {
+	check, err := CheckCRDUpgrade(from, bundle)
+	if err != nil {
+		return err
+	}
+	for _, f := range check.Findings {
+		level := RiskMedium
+		if f.Level == FindingError {
+			level = RiskHigh
+		}
+		r.addRisk(RiskCategoryCRDs, level, f.Suggestion, "%s", f.Message)
+	}
+	oldCRDs, err := bundleCRDs(from)
+	if err != nil {
+		return err
+	}
+	newCRDs, err := bundleCRDs(bundle)
+	if err != nil {
+		return err
+	}
+	var added []string
+	for name := range newCRDs {
+		if _, ok := oldCRDs[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	for _, name := range added {
+		r.addRisk(RiskCategoryCRDs, RiskLow, "", "CRD %s was added", name)
+	}
+	return nil
+}
+
+// permission is a verb granted on a resource, or on a non-resource URL.
+type permission struct {
+	group, resource, resourceName, url, verb string
+}
+
+// covers returns true if p grants at least what o grants.
+func (p permission) covers(o permission) bool {
+	match := func(granted, wanted string) bool {
+		return granted == rbacv1.VerbAll || granted == wanted
+	}
+	if p.url != "" || o.url != "" {
+		return (p.url == o.url || (strings.HasSuffix(p.url, "*") && strings.HasPrefix(o.url, strings.TrimSuffix(p.url, "*")))) &&
+			match(p.verb, o.verb)
+	}
+	return match(p.group, o.group) && match(p.resource, o.resource) && match(p.verb, o.verb) &&
+		(p.resourceName == "" || p.resourceName == o.resourceName)
+}
+
+// target describes what p is granted on, ex. "secrets" or "deployments.apps/foo".
+func (p permission) target() string {
+	if p.url != "" {
+		return p.url
+	}
+	t := p.resource
+	if p.group != "" {
+		t += "." + p.group
+	}
+	if p.resourceName != "" {
+		t += "/" + p.resourceName
+	}
+	return t
+}
+
+// sensitive returns true if p grants access to secrets, can escalate privileges, or uses wildcards.
+func (p permission) sensitive() bool {
+	switch {
+	case p.verb == rbacv1.VerbAll, p.group == rbacv1.APIGroupAll, p.resource == rbacv1.ResourceAll:
+		return true
+	case p.verb == "escalate", p.verb == "bind", p.verb == "impersonate":
+		return true
+	case p.group == "" && p.resource == "secrets":
+		return true
+	}
+	return false
+}
+
+func permissions(rules []rbacv1.PolicyRule) []permission {
+	var perms []permission
+	for _, rule := range rules {
+		for _, verb := range rule.Verbs {
+			for _, url := range rule.NonResourceURLs {
+				perms = append(perms, permission{url: url, verb: verb})
+			}
+			names := rule.ResourceNames
+			if len(names) == 0 {
+				names = []string{""}
+			}
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					for _, name := range names {
+						perms = append(perms, permission{group: group, resource: resource, resourceName: name, verb: verb})
+					}
+				}
+			}
+		}
+	}
+	return perms
+}
+
+func (r *UpgradeReport) checkPermissions(from, csv *v1alpha1.ClusterServiceVersion) {
+	oldSpec, newSpec := from.Spec.InstallStrategy.StrategySpec, csv.Spec.InstallStrategy.StrategySpec
+	r.checkAddedPermissions("permissions", false, oldSpec.Permissions, newSpec.Permissions)
+	r.checkAddedPermissions("clusterPermissions", true, oldSpec.ClusterPermissions, newSpec.ClusterPermissions)
+}
+
+// checkAddedPermissions reports the verbs each service account is granted by newPerms but not by oldPerms.
+// Permissions granted to a service account by any rule cover the same permissions in other rules.
+func (r *UpgradeReport) checkAddedPermissions(field string, cluster bool, oldPerms, newPerms []v1alpha1.StrategyDeploymentPermissions) {
+	old := map[string][]permission{}
+	for _, p := range oldPerms {
+		old[p.ServiceAccountName] = append(old[p.ServiceAccountName], permissions(p.Rules)...)
+	}
+	for _, p := range newPerms {
+		// Verbs added to each target, in the order they are granted.
+		added := map[string][]string{}
+		var targets []string
+		sensitive := map[string]bool{}
+		for _, perm := range permissions(p.Rules) {
+			covered := false
+			for _, o := range old[p.ServiceAccountName] {
+				if o.covers(perm) {
+					covered = true
+					break
+				}
+			}
+			if covered {
+				continue
+			}
+			t := perm.target()
+			if _, ok := added[t]; !ok {
+				targets = append(targets, t)
+			}
+			if !containsString(added[t], perm.verb) {
+				added[t] = append(added[t], perm.verb)
+			}
+			sensitive[t] = sensitive[t] || perm.sensitive()
+		}
+		// Count granted permissions so a service account's permissions are only reported once.
+		old[p.ServiceAccountName] = append(old[p.ServiceAccountName], permissions(p.Rules)...)
+		for _, t := range targets {
+			level := RiskLow
+			switch {
+			case sensitive[t]:
+				level = RiskHigh
+			case cluster:
+				level = RiskMedium
+			}
+			r.addRisk(RiskCategoryPermissions, level, "", "%s of service account %s gain [%s] on %s",
+				field, p.ServiceAccountName, strings.Join(added[t], ", "), t)
+		}
+	}
+}
+
+func (r *UpgradeReport) checkWebhooks(from, csv *v1alpha1.ClusterServiceVersion) {
+	old := webhooksByName(from.Spec.WebhookDefinitions)
+	current := webhooksByName(csv.Spec.WebhookDefinitions)
+	for _, name := range sortedWebhookNames(old) {
+		o := old[name]
+		n, ok := current[name]
+		if !ok {
+			if o.Type == gencsv.ConversionWebhook {
+				r.addRisk(RiskCategoryWebhooks, RiskHigh, "keep the webhook until custom resources of every "+
+					"converted version are migrated", "conversion webhook %s of CRDs %s was removed",
+					name, strings.Join(o.ConversionCRDs, ", "))
+				continue
+			}
+			r.addRisk(RiskCategoryWebhooks, RiskMedium, "", "%s %s was removed, so requests are no longer %s",
+				o.Type, name, webhookAction(o.Type))
+			continue
+		}
+		if o.Type != n.Type {
+			r.addRisk(RiskCategoryWebhooks, RiskHigh, "", "webhook %s type changed from %s to %s", name, o.Type, n.Type)
+		}
+		if !reflect.DeepEqual(o.Rules, n.Rules) {
+			r.addRisk(RiskCategoryWebhooks, RiskMedium, "", "webhook %s rules changed from %s to %s",
+				name, formatWebhookRules(o.Rules), formatWebhookRules(n.Rules))
+		}
+		if !reflect.DeepEqual(o.ObjectSelector, n.ObjectSelector) {
+			r.addRisk(RiskCategoryWebhooks, RiskMedium, "", "webhook %s object selector changed", name)
+		}
+		if oldPolicy, newPolicy := failurePolicy(o), failurePolicy(n); oldPolicy != newPolicy {
+			level := RiskLow
+			if newPolicy == "Fail" {
+				level = RiskMedium
+			}
+			r.addRisk(RiskCategoryWebhooks, level, "", "webhook %s failure policy changed from %s to %s",
+				name, oldPolicy, newPolicy)
+		}
+		if o.DeploymentName != n.DeploymentName || o.ContainerPort != n.ContainerPort ||
+			!reflect.DeepEqual(o.WebhookPath, n.WebhookPath) {
+			r.addRisk(RiskCategoryWebhooks, RiskMedium, "", "webhook %s is served by a different deployment, port, or path", name)
+		}
+		for _, crd := range o.ConversionCRDs {
+			if !containsString(n.ConversionCRDs, crd) {
+				r.addRisk(RiskCategoryWebhooks, RiskHigh, "", "conversion webhook %s no longer converts CRD %s", name, crd)
+			}
+		}
+	}
+	for _, name := range sortedWebhookNames(current) {
+		if _, ok := old[name]; ok {
+			continue
+		}
+		n := current[name]
+		level := RiskMedium
+		if n.Type == gencsv.ConversionWebhook {
+			level = RiskLow
+		}
+		r.addRisk(RiskCategoryWebhooks, level, "", "%s %s was added, so requests matching %s are %s",
+			n.Type, name, formatWebhookRules(n.Rules), webhookAction(n.Type))
+	}
+}
+
+func (r *UpgradeReport) checkInstallModes(from, csv *v1alpha1.ClusterServiceVersion) {
+	supported := func(csv *v1alpha1.ClusterServiceVersion) map[v1alpha1.InstallModeType]bool {
+		modes := map[v1alpha1.InstallModeType]bool{}
+		for _, m := range csv.Spec.InstallModes {
+			modes[m.Type] = m.Supported
+		}
+		return modes
+	}
+	old, current := supported(from), supported(csv)
+	for _, mode := range []v1alpha1.InstallModeType{
+		v1alpha1.InstallModeTypeOwnNamespace,
+		v1alpha1.InstallModeTypeSingleNamespace,
+		v1alpha1.InstallModeTypeMultiNamespace,
+		v1alpha1.InstallModeTypeAllNamespaces,
+	} {
+		switch {
+		case old[mode] && !current[mode]:
+			r.addRisk(RiskCategoryInstallModes, RiskHigh, "keep supporting the install mode, or publish "+
+				"the bundle in a new channel", "install mode %s is no longer supported, so installs in "+
+				"OperatorGroups of that mode fail to upgrade", mode)
+		case !old[mode] && current[mode]:
+			r.addRisk(RiskCategoryInstallModes, RiskLow, "", "install mode %s is now supported", mode)
+		}
+	}
+}
+
+func webhooksByName(webhooks []v1alpha1.WebhookDescription) map[string]v1alpha1.WebhookDescription {
+	byName := make(map[string]v1alpha1.WebhookDescription, len(webhooks))
+	for _, w := range webhooks {
+		byName[w.GenerateName] = w
+	}
+	return byName
+}
+
+func sortedWebhookNames(webhooks map[string]v1alpha1.WebhookDescription) []string {
+	names := make([]string, 0, len(webhooks))
+	for name := range webhooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// webhookAction describes what webhooks of type t do to requests.
+func webhookAction(t v1alpha1.WebhookAdmissionType) string {
+	switch t {
+	case v1alpha1.MutatingAdmissionWebhook:
+		return "mutated"
+	case gencsv.ConversionWebhook:
+		return "converted"
+	}
+	return "validated"
+}
+
+// failurePolicy returns the failure policy of w, which defaults to Fail.
+func failurePolicy(w v1alpha1.WebhookDescription) string {
+	if w.FailurePolicy == nil {
+		return "Fail"
+	}
+	return string(*w.FailurePolicy)
+}
+
+func formatWebhookRules(rules []admissionregistrationv1.RuleWithOperations) string {
+	if len(rules) == 0 {
+		return "[]"
+	}
+	formatted := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ops := make([]string, 0, len(rule.Operations))
+		for _, op := range rule.Operations {
+			ops = append(ops, string(op))
+		}
+		formatted = append(formatted, fmt.Sprintf("%s %s/%s",
+			strings.Join(ops, ","), strings.Join(rule.APIGroups, ","), strings.Join(rule.Resources, ",")))
+	}
+	return "[" + strings.Join(formatted, "; ") + "]"
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
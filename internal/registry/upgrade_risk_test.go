@@ -0,0 +1,224 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+
+	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+)
+
+var _ = Describe("CheckUpgrade", func() {
+	var from, to *v1alpha1.ClusterServiceVersion
+
+	newCSV := func(name string) *v1alpha1.ClusterServiceVersion {
+		csv := &v1alpha1.ClusterServiceVersion{}
+		csv.SetName(name)
+		csv.Spec.InstallModes = []v1alpha1.InstallMode{
+			{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+			{Type: v1alpha1.InstallModeTypeSingleNamespace, Supported: true},
+			{Type: v1alpha1.InstallModeTypeMultiNamespace, Supported: false},
+			{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+		}
+		csv.Spec.InstallStrategy.StrategySpec.ClusterPermissions = []v1alpha1.StrategyDeploymentPermissions{{
+			ServiceAccountName: "memcached-operator",
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{"cache.example.com"},
+				Resources: []string{"memcacheds", "memcacheds/status"},
+				Verbs:     []string{"get", "list", "watch", "update"},
+			}},
+		}}
+		csv.Spec.WebhookDefinitions = []v1alpha1.WebhookDescription{{
+			GenerateName:   "vmemcached.kb.io",
+			Type:           v1alpha1.ValidatingAdmissionWebhook,
+			DeploymentName: "memcached-operator",
+			ContainerPort:  443,
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups: []string{"cache.example.com"},
+					Resources: []string{"memcacheds"},
+				},
+			}},
+		}}
+		return csv
+	}
+
+	check := func() *UpgradeReport {
+		report, err := CheckUpgrade(&apimanifests.Bundle{CSV: from}, &apimanifests.Bundle{CSV: to})
+		Expect(err).NotTo(HaveOccurred())
+		return report
+	}
+
+	BeforeEach(func() {
+		from, to = newCSV("memcached-operator.v0.0.1"), newCSV("memcached-operator.v0.0.2")
+	})
+
+	It("reports no risks for unchanged bundles", func() {
+		report := check()
+		Expect(report.From).To(Equal("memcached-operator.v0.0.1"))
+		Expect(report.To).To(Equal("memcached-operator.v0.0.2"))
+		Expect(report.Risks).To(BeEmpty())
+	})
+	It("fails without a CSV", func() {
+		_, err := CheckUpgrade(&apimanifests.Bundle{}, &apimanifests.Bundle{CSV: to})
+		Expect(err).To(HaveOccurred())
+	})
+	It("reports CRD changes", func() {
+		previous, current := &apiextv1.CustomResourceDefinition{}, &apiextv1.CustomResourceDefinition{}
+		Expect(yaml.Unmarshal([]byte(previousCRD), previous)).To(Succeed())
+		Expect(yaml.Unmarshal([]byte(previousCRD), current)).To(Succeed())
+		current.Spec.Scope = apiextv1.ClusterScoped
+		added := &apiextv1.CustomResourceDefinition{}
+		added.SetName("memcachedbackups.cache.example.com")
+
+		report, err := CheckUpgrade(
+			&apimanifests.Bundle{CSV: from, V1CRDs: []*apiextv1.CustomResourceDefinition{previous}},
+			&apimanifests.Bundle{CSV: to, V1CRDs: []*apiextv1.CustomResourceDefinition{current, added}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Risks).To(HaveLen(2))
+		Expect(report.Risks[0].Category).To(Equal(RiskCategoryCRDs))
+		Expect(report.Risks[0].Level).To(Equal(RiskHigh))
+		Expect(report.Risks[0].Message).To(ContainSubstring("scope changed"))
+		Expect(report.Risks[1]).To(Equal(UpgradeRisk{
+			Category: RiskCategoryCRDs,
+			Level:    RiskLow,
+			Message:  "CRD memcachedbackups.cache.example.com was added",
+		}))
+	})
+
+	Describe("permissions", func() {
+		It("reports added verbs and resources", func() {
+			perms := &to.Spec.InstallStrategy.StrategySpec.ClusterPermissions[0]
+			perms.Rules[0].Verbs = append(perms.Rules[0].Verbs, "delete")
+			perms.Rules = append(perms.Rules, rbacv1.PolicyRule{
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"get"},
+			})
+			to.Spec.InstallStrategy.StrategySpec.Permissions = []v1alpha1.StrategyDeploymentPermissions{{
+				ServiceAccountName: "memcached-operator",
+				Rules: []rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"configmaps"},
+					Verbs:     []string{"get", "create"},
+				}},
+			}}
+			Expect(check().Risks).To(Equal([]UpgradeRisk{
+				{
+					Category: RiskCategoryPermissions,
+					Level:    RiskLow,
+					Message:  "permissions of service account memcached-operator gain [get, create] on configmaps",
+				},
+				{
+					Category: RiskCategoryPermissions,
+					Level:    RiskMedium,
+					Message: "clusterPermissions of service account memcached-operator gain [delete] on " +
+						"memcacheds.cache.example.com",
+				},
+				{
+					Category: RiskCategoryPermissions,
+					Level:    RiskMedium,
+					Message: "clusterPermissions of service account memcached-operator gain [delete] on " +
+						"memcacheds/status.cache.example.com",
+				},
+				{
+					Category: RiskCategoryPermissions,
+					Level:    RiskHigh,
+					Message:  "clusterPermissions of service account memcached-operator gain [get] on secrets",
+				},
+			}))
+		})
+		It("does not report permissions covered by wildcards or removed permissions", func() {
+			from.Spec.InstallStrategy.StrategySpec.ClusterPermissions[0].Rules = []rbacv1.PolicyRule{{
+				APIGroups: []string{"cache.example.com"},
+				Resources: []string{"*"},
+				Verbs:     []string{"*"},
+			}}
+			to.Spec.InstallStrategy.StrategySpec.ClusterPermissions[0].Rules[0].Verbs = []string{"get"}
+			Expect(check().Risks).To(BeEmpty())
+		})
+		It("reports permissions restricted to resource names as covered", func() {
+			from.Spec.InstallStrategy.StrategySpec.ClusterPermissions[0].Rules[0].ResourceNames = []string{"foo"}
+			Expect(check().Risks).To(HaveLen(2))
+			to.Spec.InstallStrategy.StrategySpec.ClusterPermissions[0].Rules[0].ResourceNames = []string{"foo"}
+			Expect(check().Risks).To(BeEmpty())
+		})
+	})
+
+	Describe("webhooks", func() {
+		It("reports added and removed webhooks", func() {
+			to.Spec.WebhookDefinitions[0].GenerateName = "mmemcached.kb.io"
+			to.Spec.WebhookDefinitions[0].Type = v1alpha1.MutatingAdmissionWebhook
+			report := check()
+			Expect(report.Risks).To(HaveLen(2))
+			Expect(report.Risks[0].Message).To(Equal("ValidatingAdmissionWebhook vmemcached.kb.io was removed, " +
+				"so requests are no longer validated"))
+			Expect(report.Risks[1].Message).To(Equal("MutatingAdmissionWebhook mmemcached.kb.io was added, " +
+				"so requests matching [CREATE cache.example.com/memcacheds] are mutated"))
+			Expect(report.Risks[1].Level).To(Equal(RiskMedium))
+		})
+		It("reports changed webhooks", func() {
+			ignore := admissionregistrationv1.Ignore
+			from.Spec.WebhookDefinitions[0].FailurePolicy = &ignore
+			to.Spec.WebhookDefinitions[0].Rules[0].Operations = append(to.Spec.WebhookDefinitions[0].Rules[0].Operations,
+				admissionregistrationv1.Update)
+			to.Spec.WebhookDefinitions[0].ContainerPort = 9443
+			report := check()
+			Expect(report.Risks).To(HaveLen(3))
+			Expect(report.Risks[0].Message).To(Equal("webhook vmemcached.kb.io rules changed from " +
+				"[CREATE cache.example.com/memcacheds] to [CREATE,UPDATE cache.example.com/memcacheds]"))
+			Expect(report.Risks[1].Message).To(Equal("webhook vmemcached.kb.io failure policy changed from Ignore to Fail"))
+			Expect(report.Risks[1].Level).To(Equal(RiskMedium))
+			Expect(report.Risks[2].Message).To(ContainSubstring("different deployment, port, or path"))
+		})
+		It("reports removed conversion webhooks as high risks", func() {
+			from.Spec.WebhookDefinitions[0].Type = gencsv.ConversionWebhook
+			from.Spec.WebhookDefinitions[0].ConversionCRDs = []string{"memcacheds.cache.example.com"}
+			to.Spec.WebhookDefinitions = nil
+			report := check()
+			Expect(report.Risks).To(HaveLen(1))
+			Expect(report.Risks[0].Level).To(Equal(RiskHigh))
+			Expect(report.HasRisks(RiskHigh)).To(BeTrue())
+		})
+	})
+
+	Describe("install modes", func() {
+		It("reports unsupported and newly supported install modes", func() {
+			to.Spec.InstallModes[2].Supported = true
+			to.Spec.InstallModes[3].Supported = false
+			Expect(check().Risks).To(Equal([]UpgradeRisk{
+				{
+					Category: RiskCategoryInstallModes,
+					Level:    RiskLow,
+					Message:  "install mode MultiNamespace is now supported",
+				},
+				{
+					Category:   RiskCategoryInstallModes,
+					Level:      RiskHigh,
+					Message:    "install mode AllNamespaces is no longer supported, so installs in OperatorGroups of that mode fail to upgrade",
+					Suggestion: "keep supporting the install mode, or publish the bundle in a new channel",
+				},
+			}))
+		})
+	})
+})
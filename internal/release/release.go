@@ -0,0 +1,190 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package release prepares the files of an operator project for the release of a new version: the VERSION
+// in its Makefile, and the upgrade edges of its bundle.
+package release
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+// Parts of a version bumped by Bump.
+const (
+	Major = "major"
+	Minor = "minor"
+	Patch = "patch"
+)
+
+// Parts are the parts of a version Bump can bump.
+var Parts = []string{Major, Minor, Patch}
+
+// Bump returns version with part incremented, and the parts after it reset.
+func Bump(version, part string) (string, error) {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return "", fmt.Errorf("invalid version %q: %v", version, err)
+	}
+	switch part {
+	case Major:
+		v = semver.Version{Major: v.Major + 1}
+	case Minor:
+		v = semver.Version{Major: v.Major, Minor: v.Minor + 1}
+	case Patch:
+		// A prerelease is released without incrementing its patch version.
+		if len(v.Pre) == 0 {
+			v.Patch++
+		}
+		v = semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	default:
+		return "", fmt.Errorf("invalid version part %q, must be one of [%s]", part, strings.Join(Parts, ", "))
+	}
+	return v.String(), nil
+}
+
+// makefileVersionRe matches the VERSION variable scaffolded into project Makefiles.
+var makefileVersionRe = regexp.MustCompile(`(?m)^(VERSION\s*\?=[ \t]*)(\S*)[ \t]*$`)
+
+// ReadMakefileVersion returns the VERSION set in the Makefile at path.
+func ReadMakefileVersion(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	m := makefileVersionRe.FindSubmatch(b)
+	if m == nil {
+		return "", fmt.Errorf("%s does not set VERSION, ex. 'VERSION ?= 0.0.1'", path)
+	}
+	return string(m[2]), nil
+}
+
+// WriteMakefileVersion sets VERSION to version in the Makefile at path.
+func WriteMakefileVersion(path, version string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !makefileVersionRe.Match(b) {
+		return fmt.Errorf("%s does not set VERSION, ex. 'VERSION ?= 0.0.1'", path)
+	}
+	b = makefileVersionRe.ReplaceAll(b, []byte("${1}"+version))
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, info.Mode())
+}
+
+// CSV is the ClusterServiceVersion of a bundle directory.
+type CSV struct {
+	Name    string
+	Version string
+	// Replaces is the name of the CSV this CSV replaces.
+	Replaces string
+	path     string
+	obj      map[string]interface{}
+}
+
+// ReadBundleCSV returns the CSV of the bundle in bundleRoot, or nil if bundleRoot has no CSV.
+func ReadBundleCSV(bundleRoot string) (*CSV, error) {
+	paths, err := filepath.Glob(filepath.Join(bundleRoot, bundle.ManifestsDir, "*.clusterserviceversion.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	switch len(paths) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("bundle %s has more than one ClusterServiceVersion", bundleRoot)
+	}
+
+	b, err := ioutil.ReadFile(paths[0])
+	if err != nil {
+		return nil, err
+	}
+	csv := &CSV{path: paths[0]}
+	if err := yaml.Unmarshal(b, &csv.obj); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", paths[0], err)
+	}
+	csv.Name = stringField(csv.obj, "metadata", "name")
+	csv.Version = stringField(csv.obj, "spec", "version")
+	csv.Replaces = stringField(csv.obj, "spec", "replaces")
+	if csv.Name == "" {
+		return nil, fmt.Errorf("%s has no metadata.name", paths[0])
+	}
+	return csv, nil
+}
+
+// SetReplaces sets the CSV's replaces to name, and writes it.
+func (csv *CSV) SetReplaces(name string) error {
+	spec, isMap := csv.obj["spec"].(map[string]interface{})
+	if !isMap {
+		return fmt.Errorf("%s has no spec", csv.path)
+	}
+	if name == "" {
+		delete(spec, "replaces")
+	} else {
+		spec["replaces"] = name
+	}
+	b, err := yaml.Marshal(csv.obj)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(csv.path, b, 0666); err != nil {
+		return err
+	}
+	csv.Replaces = name
+	return nil
+}
+
+func stringField(obj map[string]interface{}, fields ...string) string {
+	for _, field := range fields[:len(fields)-1] {
+		obj, _ = obj[field].(map[string]interface{})
+	}
+	s, _ := obj[fields[len(fields)-1]].(string)
+	return s
+}
+
+// ReadBundlePackage returns the package and channels in the metadata of the bundle in bundleRoot.
+func ReadBundlePackage(bundleRoot string) (pkg string, channels []string, err error) {
+	labels, _, err := registry.FindBundleMetadata(bundleRoot)
+	if err != nil {
+		return "", nil, err
+	}
+	if pkg = labels[bundle.PackageLabel]; pkg == "" {
+		return "", nil, errors.New("bundle metadata declares no package")
+	}
+	for _, channel := range strings.Split(labels[bundle.ChannelsLabel], ",") {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			channels = append(channels, channel)
+		}
+	}
+	if len(channels) == 0 {
+		return "", nil, errors.New("bundle metadata declares no channels")
+	}
+	return pkg, channels, nil
+}
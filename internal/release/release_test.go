@@ -0,0 +1,123 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Release", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "release")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	writeFile := func(path, content string) {
+		path = filepath.Join(dir, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+	readFile := func(path string) string {
+		b, err := ioutil.ReadFile(filepath.Join(dir, path))
+		Expect(err).NotTo(HaveOccurred())
+		return string(b)
+	}
+
+	Describe("Bump", func() {
+		It("bumps each part of a version", func() {
+			for _, c := range []struct{ version, part, bumped string }{
+				{"0.1.2", Major, "1.0.0"},
+				{"0.1.2", Minor, "0.2.0"},
+				{"0.1.2", Patch, "0.1.3"},
+				{"0.1.2-alpha.1+build", Patch, "0.1.2"},
+				{"0.1.2-alpha.1", Minor, "0.2.0"},
+			} {
+				Expect(Bump(c.version, c.part)).To(Equal(c.bumped), "%s %s", c.version, c.part)
+			}
+		})
+		It("fails on invalid versions and parts", func() {
+			_, err := Bump("v0.1", Patch)
+			Expect(err).To(MatchError(ContainSubstring(`invalid version "v0.1"`)))
+			_, err = Bump("0.1.2", "build")
+			Expect(err).To(MatchError(`invalid version part "build", must be one of [major, minor, patch]`))
+		})
+	})
+
+	Describe("Makefile VERSION", func() {
+		It("reads and writes VERSION", func() {
+			writeFile("Makefile", "# Current Operator version\nVERSION ?= 0.0.1\nBUNDLE_IMG ?= controller-bundle:$(VERSION)\n")
+			path := filepath.Join(dir, "Makefile")
+			Expect(ReadMakefileVersion(path)).To(Equal("0.0.1"))
+			Expect(WriteMakefileVersion(path, "0.0.2")).To(Succeed())
+			Expect(readFile("Makefile")).To(Equal("# Current Operator version\nVERSION ?= 0.0.2\nBUNDLE_IMG ?= controller-bundle:$(VERSION)\n"))
+		})
+		It("fails on Makefiles without VERSION", func() {
+			writeFile("Makefile", "IMG ?= controller:latest\n")
+			path := filepath.Join(dir, "Makefile")
+			_, err := ReadMakefileVersion(path)
+			Expect(err).To(MatchError(ContainSubstring("does not set VERSION")))
+			Expect(WriteMakefileVersion(path, "0.0.2")).To(MatchError(ContainSubstring("does not set VERSION")))
+		})
+	})
+
+	Describe("bundles", func() {
+		It("reads bundle CSVs and sets their replaces", func() {
+			writeFile("bundle/manifests/memcached-operator.clusterserviceversion.yaml", `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.2
+spec:
+  displayName: Memcached Operator
+  version: 0.0.2
+`)
+			csv, err := ReadBundleCSV(filepath.Join(dir, "bundle"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(csv.Name).To(Equal("memcached-operator.v0.0.2"))
+			Expect(csv.Version).To(Equal("0.0.2"))
+			Expect(csv.Replaces).To(BeEmpty())
+
+			Expect(csv.SetReplaces("memcached-operator.v0.0.1")).To(Succeed())
+			csv, err = ReadBundleCSV(filepath.Join(dir, "bundle"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(csv.Replaces).To(Equal("memcached-operator.v0.0.1"))
+			Expect(readFile("bundle/manifests/memcached-operator.clusterserviceversion.yaml")).
+				To(ContainSubstring("  displayName: Memcached Operator\n  replaces: memcached-operator.v0.0.1\n"))
+		})
+		It("reads no CSV from a missing bundle", func() {
+			Expect(ReadBundleCSV(filepath.Join(dir, "bundle"))).To(BeNil())
+		})
+		It("reads the package and channels of a bundle", func() {
+			writeFile("bundle/metadata/annotations.yaml", `annotations:
+  operators.operatorframework.io.bundle.channels.v1: alpha, stable
+  operators.operatorframework.io.bundle.package.v1: memcached-operator
+`)
+			pkg, channels, err := ReadBundlePackage(filepath.Join(dir, "bundle"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pkg).To(Equal("memcached-operator"))
+			Expect(channels).To(Equal([]string{"alpha", "stable"}))
+		})
+	})
+})
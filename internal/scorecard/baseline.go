@@ -0,0 +1,181 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+// ReadBaseline reads the results of a previous run, as written by WriteBaseline or '--output json'.
+func ReadBaseline(path string) (v1alpha3.TestList, error) {
+	var list v1alpha3.TestList
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return list, fmt.Errorf("error reading baseline: %w", err)
+	}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return list, fmt.Errorf("error parsing baseline %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// WriteBaseline writes the results of a run to path, for later runs to be compared against.
+func WriteBaseline(path string, list v1alpha3.TestList) error {
+	b, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling baseline: %w", err)
+	}
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing baseline: %w", err)
+	}
+	return nil
+}
+
+// ResultChange is the state of a test result in a baseline and in the current run.
+// A state is empty if the result is not in that run.
+type ResultChange struct {
+	// Test is the entrypoint of the test the result is from, ex. 'scorecard-test basic-check-spec'.
+	Test     string
+	Result   string
+	Baseline v1alpha3.State
+	Current  v1alpha3.State
+}
+
+func (c ResultChange) String() string {
+	from, to := c.Baseline, c.Current
+	if from == "" {
+		from = "new"
+	}
+	if to == "" {
+		to = "not run"
+	}
+	if c.Test == c.Result {
+		return fmt.Sprintf("%s: %s -> %s", c.Test, from, to)
+	}
+	return fmt.Sprintf("%s (%s): %s -> %s", c.Test, c.Result, from, to)
+}
+
+// Comparison is the difference between the results of a run and a baseline.
+type Comparison struct {
+	// Regressions are results that passed in the baseline, or new results, that do not pass.
+	Regressions []ResultChange
+	// Improvements are results that did not pass in the baseline and pass.
+	Improvements []ResultChange
+	// KnownFailures are results that did not pass in either run. They are not regressions.
+	KnownFailures []ResultChange
+	// Missing are results of the baseline that were not in the run, ex. because of a selector.
+	Missing []ResultChange
+}
+
+// HasRegressions returns true if a result regressed since the baseline.
+func (c Comparison) HasRegressions() bool {
+	return len(c.Regressions) != 0
+}
+
+// MarshalText summarizes the comparison.
+func (c Comparison) MarshalText() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Compared to baseline: regressions: %d, improvements: %d, known failures: %d",
+		len(c.Regressions), len(c.Improvements), len(c.KnownFailures)))
+	if len(c.Missing) != 0 {
+		sb.WriteString(fmt.Sprintf(", not run: %d", len(c.Missing)))
+	}
+	sb.WriteString("\n")
+	for _, group := range []struct {
+		title   string
+		changes []ResultChange
+	}{
+		{"Regressions", c.Regressions},
+		{"Improvements", c.Improvements},
+		{"Known failures", c.KnownFailures},
+		{"Not run", c.Missing},
+	} {
+		if len(group.changes) == 0 {
+			continue
+		}
+		sb.WriteString(group.title + ":\n")
+		for _, change := range group.changes {
+			sb.WriteString("\t" + change.String() + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// CompareBaseline compares the results of current with those of baseline. Results are matched by the
+// entrypoint of their test and their name.
+func CompareBaseline(baseline, current v1alpha3.TestList) Comparison {
+	before, after := resultStates(baseline), resultStates(current)
+	keys := make([]resultKey, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].test != keys[j].test {
+			return keys[i].test < keys[j].test
+		}
+		return keys[i].result < keys[j].result
+	})
+
+	var c Comparison
+	for _, key := range keys {
+		change := ResultChange{Test: key.test, Result: key.result, Baseline: before[key], Current: after[key]}
+		passed, passes := change.Baseline == v1alpha3.PassState, change.Current == v1alpha3.PassState
+		switch {
+		case change.Current == "":
+			c.Missing = append(c.Missing, change)
+		case passes && !passed:
+			c.Improvements = append(c.Improvements, change)
+		case !passes && (passed || change.Baseline == ""):
+			c.Regressions = append(c.Regressions, change)
+		case !passes:
+			c.KnownFailures = append(c.KnownFailures, change)
+		}
+	}
+	return c
+}
+
+type resultKey struct {
+	test, result string
+}
+
+// resultStates returns the state of each result in list. If a test has several results of the
+// same name, the state is the first that does not pass.
+func resultStates(list v1alpha3.TestList) map[resultKey]v1alpha3.State {
+	states := map[resultKey]v1alpha3.State{}
+	for _, test := range list.Items {
+		name := strings.Join(test.Spec.Entrypoint, " ")
+		if name == "" {
+			name = test.Spec.Image
+		}
+		for _, r := range test.Status.Results {
+			key := resultKey{test: name, result: r.Name}
+			if state, ok := states[key]; !ok || state == v1alpha3.PassState {
+				states[key] = r.State
+			}
+		}
+	}
+	return states
+}
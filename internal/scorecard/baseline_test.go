@@ -0,0 +1,103 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+var _ = Describe("Baselines", func() {
+	newList := func(states map[string]v1alpha3.State) v1alpha3.TestList {
+		list := v1alpha3.NewTestList()
+		for _, name := range []string{"basic-check-spec", "olm-bundle-validation", "olm-crds-have-validation"} {
+			state, ok := states[name]
+			if !ok {
+				continue
+			}
+			test := v1alpha3.NewTest()
+			test.Spec.Entrypoint = []string{"scorecard-test", name}
+			test.Status.Results = []v1alpha3.TestResult{{Name: name, State: state}}
+			list.Items = append(list.Items, test)
+		}
+		return list
+	}
+
+	Describe("CompareBaseline", func() {
+		It("only reports results that newly fail as regressions", func() {
+			baseline := newList(map[string]v1alpha3.State{
+				"basic-check-spec":         v1alpha3.PassState,
+				"olm-bundle-validation":    v1alpha3.FailState,
+				"olm-crds-have-validation": v1alpha3.FailState,
+			})
+			current := newList(map[string]v1alpha3.State{
+				"basic-check-spec":         v1alpha3.ErrorState,
+				"olm-bundle-validation":    v1alpha3.PassState,
+				"olm-crds-have-validation": v1alpha3.FailState,
+			})
+			c := CompareBaseline(baseline, current)
+			Expect(c.HasRegressions()).To(BeTrue())
+			Expect(c.Regressions).To(Equal([]ResultChange{{Test: "scorecard-test basic-check-spec",
+				Result: "basic-check-spec", Baseline: v1alpha3.PassState, Current: v1alpha3.ErrorState}}))
+			Expect(c.Improvements).To(HaveLen(1))
+			Expect(c.Improvements[0].Result).To(Equal("olm-bundle-validation"))
+			Expect(c.KnownFailures).To(HaveLen(1))
+			Expect(c.KnownFailures[0].Result).To(Equal("olm-crds-have-validation"))
+			Expect(c.MarshalText()).To(Equal(`Compared to baseline: regressions: 1, improvements: 1, known failures: 1
+Regressions:
+	scorecard-test basic-check-spec (basic-check-spec): pass -> error
+Improvements:
+	scorecard-test olm-bundle-validation (olm-bundle-validation): fail -> pass
+Known failures:
+	scorecard-test olm-crds-have-validation (olm-crds-have-validation): fail -> fail
+`))
+		})
+		It("reports new failing results as regressions and results not run as missing", func() {
+			baseline := newList(map[string]v1alpha3.State{"basic-check-spec": v1alpha3.PassState})
+			current := newList(map[string]v1alpha3.State{"olm-bundle-validation": v1alpha3.FailState})
+			c := CompareBaseline(baseline, current)
+			Expect(c.Regressions).To(HaveLen(1))
+			Expect(c.Regressions[0].String()).To(Equal(
+				"scorecard-test olm-bundle-validation (olm-bundle-validation): new -> fail"))
+			Expect(c.Missing).To(HaveLen(1))
+			Expect(c.Missing[0].String()).To(Equal("scorecard-test basic-check-spec (basic-check-spec): pass -> not run"))
+		})
+		It("has no regressions if nothing changed", func() {
+			list := newList(map[string]v1alpha3.State{"olm-bundle-validation": v1alpha3.FailState})
+			Expect(CompareBaseline(list, list).HasRegressions()).To(BeFalse())
+		})
+	})
+
+	Describe("WriteBaseline and ReadBaseline", func() {
+		It("round-trip results", func() {
+			dir, err := ioutil.TempDir("", "baseline")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "results.json")
+			list := newList(map[string]v1alpha3.State{"basic-check-spec": v1alpha3.PassState})
+			Expect(WriteBaseline(path, list)).To(Succeed())
+			Expect(ReadBaseline(path)).To(Equal(list))
+
+			_, err = ReadBaseline(filepath.Join(dir, "missing.json"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -15,10 +15,15 @@
 package scorecard
 
 import (
+	"fmt"
 	"io/ioutil"
+	"strings"
 
 	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/scorecard/tests"
 )
 
 const (
@@ -45,3 +50,96 @@ func LoadConfig(configFilePath string) (v1alpha3.Configuration, error) {
 	err = yaml.Unmarshal(yamlFile, &c)
 	return c, err
 }
+
+// builtinTests are the tests run by the built-in test image.
+var builtinTests = []string{
+	tests.BasicCheckSpecTest,
+	tests.BasicCheckMetricsTest,
+	tests.BasicCheckResourcesTest,
+	tests.BasicCheckResilienceTest,
+	tests.ClusterAssertionsTest,
+	tests.OLMBundleValidationTest,
+	tests.OLMCRDsHaveValidationTest,
+	tests.OLMCRDsHaveResourcesTest,
+	tests.OLMSpecDescriptorsTest,
+	tests.OLMStatusDescriptorsTest,
+}
+
+// ValidateConfig validates the scorecard config in b against the Configuration schema,
+// returning an error listing every problem found with the path of the field it is in.
+func ValidateConfig(b []byte) error {
+	c := v1alpha3.Configuration{}
+	if err := yaml.UnmarshalStrict(b, &c); err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+
+	var problems []string
+	addProblem := func(path, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+	if c.Kind != v1alpha3.ConfigurationKind {
+		addProblem("kind", "must be %q, got %q", v1alpha3.ConfigurationKind, c.Kind)
+	}
+	if c.APIVersion != v1alpha3.GroupVersion.String() {
+		addProblem("apiVersion", "must be %q, got %q", v1alpha3.GroupVersion.String(), c.APIVersion)
+	}
+	if len(c.Stages) == 0 {
+		addProblem("stages", "at least one stage is required")
+	}
+	testNames := map[string]string{}
+	for i, stage := range c.Stages {
+		stagePath := fmt.Sprintf("stages[%d]", i)
+		if len(stage.Tests) == 0 {
+			addProblem(stagePath+".tests", "at least one test is required")
+		}
+		for j, test := range stage.Tests {
+			testPath := fmt.Sprintf("%s.tests[%d]", stagePath, j)
+			if test.Image == "" {
+				addProblem(testPath+".image", "an image is required")
+			} else if isBuiltinTestImage(test.Image) {
+				switch {
+				case len(test.Entrypoint) < 2 || test.Entrypoint[0] != testImageEntrypoint:
+					addProblem(testPath+".entrypoint", "the built-in test image's entrypoint must be [%s, <test name>]",
+						testImageEntrypoint)
+				case !containsString(builtinTests, test.Entrypoint[1]):
+					addProblem(testPath+".entrypoint", "unknown built-in test %q, valid tests are: %s",
+						test.Entrypoint[1], strings.Join(builtinTests, ", "))
+				}
+			}
+			for key, value := range test.Labels {
+				if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+					addProblem(testPath+".labels", "invalid label key %q: %s", key, strings.Join(errs, ", "))
+				}
+				if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+					addProblem(testPath+".labels", "invalid value of label %q: %s", key, strings.Join(errs, ", "))
+				}
+			}
+			if name, ok := test.Labels["test"]; ok {
+				if other, ok := testNames[name]; ok {
+					addProblem(testPath+".labels", "test label %q is also set on %s, so the tests cannot be "+
+						"selected separately", name, other)
+				}
+				testNames[name] = testPath
+			}
+		}
+	}
+	if len(problems) != 0 {
+		return fmt.Errorf("invalid config:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// isBuiltinTestImage returns true if image is a tag or digest of the built-in test image.
+func isBuiltinTestImage(image string) bool {
+	return image == testImageRepository || strings.HasPrefix(image, testImageRepository+":") ||
+		strings.HasPrefix(image, testImageRepository+"@")
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
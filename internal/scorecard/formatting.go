@@ -22,10 +22,16 @@ import (
 	v1 "k8s.io/api/core/v1"
 )
 
-// getTestResult fetches the test pod log and converts it into
+// getTestResult fetches the test pod log, or result ConfigMap, and converts it into
 // Test format
 func (r PodTestRunner) getTestStatus(ctx context.Context, p *v1.Pod) (output *v1alpha3.TestStatus) {
-	logBytes, err := getPodLog(ctx, r.Client, p)
+	var logBytes []byte
+	var err error
+	if r.ResultSource == ResultSourceConfigMap {
+		logBytes, err = r.getResultConfigMap(ctx, p)
+	} else {
+		logBytes, err = getPodLog(ctx, r.Client, p)
+	}
 	if err != nil {
 		return convertErrorToStatus(err, string(logBytes))
 	}
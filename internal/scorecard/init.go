@@ -0,0 +1,193 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+
+	"github.com/operator-framework/operator-sdk/internal/scorecard/tests"
+	"github.com/operator-framework/operator-sdk/internal/version"
+)
+
+// DefaultTestImage is the latest-released image of the built-in tests.
+var DefaultTestImage = fmt.Sprintf("%s:%s", testImageRepository, strings.TrimSuffix(version.Version, "+git"))
+
+const (
+	testImageRepository = "quay.io/operator-framework/scorecard-test"
+	testImageEntrypoint = "scorecard-test"
+	// kubeRBACProxyContainer serves the metrics of operators scaffolded by operator-sdk.
+	kubeRBACProxyContainer = "kube-rbac-proxy"
+)
+
+// ConfigOptions configure GenerateConfig.
+type ConfigOptions struct {
+	// TestImage is the image of the built-in tests.
+	TestImage string
+	// ServiceAccount is the service account the tests are meant to be run with, which is documented in the config.
+	ServiceAccount string
+	// ClusterTests adds a stage of built-in tests that create CRs and restart pods in the test namespace.
+	ClusterTests bool
+}
+
+// generatedStage is a stage of a generated config, with a comment for each included or skipped test.
+type generatedStage struct {
+	Comment  string
+	Parallel bool
+	Tests    []generatedTest
+	Skipped  []string
+}
+
+type generatedTest struct {
+	Comment, Image, Name, Suite string
+}
+
+const configTemplate = `# Scorecard configuration for {{ .CSVName }}, generated by 'operator-sdk scorecard init'.
+#
+# Run every test with:
+#   operator-sdk scorecard <bundle> --service-account={{ .ServiceAccount }}
+# Tests are labeled by suite and name, so a subset can be run with a selector, ex:
+#   operator-sdk scorecard <bundle> --selector=suite=basic
+#   operator-sdk scorecard <bundle> --selector='test in (olm-bundle-validation-test)'
+{{- if .ClusterTests }}
+#
+# The cluster stage creates the bundle's example CRs and deletes pods in the test namespace.
+# Its tests need the {{ .ServiceAccount }} service account to be able to create the example CRs,
+# list, watch, and delete pods, list workloads, services, configmaps, secrets, and pod metrics
+# in the namespace, and get the operator's metrics, ex. by binding the metrics-reader ClusterRole.
+{{- end }}
+kind: Configuration
+apiVersion: scorecard.operatorframework.io/v1alpha3
+metadata:
+  name: config
+stages:
+{{- range .Stages }}
+# {{ .Comment }}
+- parallel: {{ .Parallel }}
+  tests:
+{{- range .Tests }}
+  # {{ .Comment }}
+  - image: {{ .Image }}
+    entrypoint:
+    - scorecard-test
+    - {{ .Name }}
+    labels:
+      suite: {{ .Suite }}
+      test: {{ .Name }}-test
+{{- end }}
+{{- range .Skipped }}
+  # {{ . }}
+{{- end }}
+{{- end }}
+`
+
+// GenerateConfig returns a commented scorecard config with the built-in tests that apply to bundle.
+// Tests that do not apply, ex. those checking example CRs of a bundle without any, are listed in comments.
+func GenerateConfig(bundle *apimanifests.Bundle, opts ConfigOptions) ([]byte, error) {
+	if bundle.CSV == nil {
+		return nil, fmt.Errorf("bundle %s has no ClusterServiceVersion", bundle.Name)
+	}
+	if opts.TestImage == "" {
+		opts.TestImage = DefaultTestImage
+	}
+	if opts.ServiceAccount == "" {
+		opts.ServiceAccount = "default"
+	}
+	crs, err := tests.GetCRs(bundle)
+	if err != nil {
+		return nil, err
+	}
+	hasCRs := len(crs) != 0
+	ownsCRDs := len(bundle.CSV.Spec.CustomResourceDefinitions.Owned) != 0
+
+	static := generatedStage{
+		Comment:  "Static tests inspect the bundle's manifests, and run in parallel.",
+		Parallel: true,
+	}
+	add := func(s *generatedStage, include bool, name, suite, comment, skipReason string) {
+		if include {
+			s.Tests = append(s.Tests, generatedTest{Comment: comment, Image: opts.TestImage, Name: name, Suite: suite})
+		} else {
+			s.Skipped = append(s.Skipped, fmt.Sprintf("%s is not included, since %s.", name, skipReason))
+		}
+	}
+	const noCRs = "the CSV has no example CRs in its alm-examples annotation"
+	const noCRDs = "the CSV owns no CRDs"
+	add(&static, hasCRs, tests.BasicCheckSpecTest, "basic",
+		"Checks that every example CR has a spec.", noCRs)
+	add(&static, true, tests.OLMBundleValidationTest, "olm",
+		"Validates the bundle's manifests and metadata.", "")
+	add(&static, hasCRs && ownsCRDs, tests.OLMCRDsHaveValidationTest, "olm",
+		"Checks that the CRDs of the example CRs have a validation schema for each of their fields.",
+		"the CSV has no example CRs or owns no CRDs")
+	add(&static, ownsCRDs, tests.OLMCRDsHaveResourcesTest, "olm",
+		"Checks that each owned CRD in the CSV lists the resources created for it.", noCRDs)
+	add(&static, hasCRs && ownsCRDs, tests.OLMSpecDescriptorsTest, "olm",
+		"Checks that each spec field of the example CRs has a descriptor in the CSV.",
+		"the CSV has no example CRs or owns no CRDs")
+	add(&static, hasCRs && ownsCRDs, tests.OLMStatusDescriptorsTest, "olm",
+		"Checks that each status field of the example CRs has a descriptor in the CSV.",
+		"the CSV has no example CRs or owns no CRDs")
+	stages := []generatedStage{static}
+
+	if opts.ClusterTests {
+		cluster := generatedStage{
+			Comment: "Cluster tests create the example CRs in the test namespace, so they run one at a time.",
+		}
+		add(&cluster, hasCRs && hasContainer(bundle, kubeRBACProxyContainer), tests.BasicCheckMetricsTest, "basic",
+			"Checks that the operator exposes controller-runtime metrics after reconciling the example CRs.",
+			"the CSV has no example CRs or its deployments have no "+kubeRBACProxyContainer+" container serving metrics")
+		add(&cluster, hasCRs, tests.BasicCheckResourcesTest, "basic",
+			"Checks the operator's resource limits, and its memory usage and leaked objects across reconcile cycles.",
+			noCRs)
+		add(&cluster, hasCRs, tests.BasicCheckResilienceTest, "basic",
+			"Checks that the operator recovers from restarts and restores deleted operand pods.", noCRs)
+		if len(cluster.Tests) != 0 {
+			stages = append(stages, cluster)
+		} else {
+			stages[0].Skipped = append(stages[0].Skipped, cluster.Skipped...)
+		}
+	}
+
+	t, err := template.New("config").Parse(configTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config template: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, struct {
+		CSVName, ServiceAccount string
+		ClusterTests            bool
+		Stages                  []generatedStage
+	}{bundle.CSV.GetName(), opts.ServiceAccount, opts.ClusterTests, stages}); err != nil {
+		return nil, fmt.Errorf("error executing config template: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// hasContainer returns true if a deployment of bundle has a container named name.
+func hasContainer(bundle *apimanifests.Bundle, name string) bool {
+	for _, dep := range bundle.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		for _, c := range dep.Spec.Template.Spec.Containers {
+			if c.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Config generation and validation", func() {
+	var bundle *apimanifests.Bundle
+
+	BeforeEach(func() {
+		var err error
+		bundle, err = apimanifests.GetBundleFromDir(filepath.Join("testdata", "bundle"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("GenerateConfig", func() {
+		testNames := func(b []byte) (names []string) {
+			c := v1alpha3.Configuration{}
+			Expect(yaml.Unmarshal(b, &c)).To(Succeed())
+			for _, stage := range c.Stages {
+				for _, t := range stage.Tests {
+					names = append(names, t.Entrypoint[1])
+				}
+			}
+			return names
+		}
+
+		It("generates a valid config with the static tests that apply to the bundle", func() {
+			b, err := GenerateConfig(bundle, ConfigOptions{TestImage: "quay.io/operator-framework/scorecard-test:dev"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ValidateConfig(b)).To(Succeed())
+			Expect(testNames(b)).To(Equal([]string{
+				"basic-check-spec",
+				"olm-bundle-validation",
+				"olm-crds-have-validation",
+				"olm-crds-have-resources",
+				"olm-spec-descriptors",
+				"olm-status-descriptors",
+			}))
+			Expect(string(b)).To(ContainSubstring("--service-account=default"))
+		})
+		It("lists tests of example CRs as skipped if the CSV has none", func() {
+			bundle.CSV.SetAnnotations(nil)
+			b, err := GenerateConfig(bundle, ConfigOptions{ClusterTests: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ValidateConfig(b)).To(Succeed())
+			Expect(testNames(b)).To(Equal([]string{"olm-bundle-validation", "olm-crds-have-resources"}))
+			Expect(string(b)).To(ContainSubstring("# basic-check-resilience is not included, since the CSV has " +
+				"no example CRs in its alm-examples annotation."))
+		})
+		It("adds a serial stage of cluster tests", func() {
+			b, err := GenerateConfig(bundle, ConfigOptions{ClusterTests: true, ServiceAccount: "scorecard"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ValidateConfig(b)).To(Succeed())
+			c := v1alpha3.Configuration{}
+			Expect(yaml.Unmarshal(b, &c)).To(Succeed())
+			Expect(c.Stages).To(HaveLen(2))
+			Expect(c.Stages[1].Parallel).To(BeFalse())
+			Expect(testNames(b)).To(ContainElements("basic-check-resources", "basic-check-resilience"))
+			Expect(testNames(b)).NotTo(ContainElement("basic-check-metrics"))
+			Expect(string(b)).To(ContainSubstring("--service-account=scorecard"))
+		})
+	})
+
+	Describe("ValidateConfig", func() {
+		It("accepts the test bundle's config", func() {
+			b, err := ioutil.ReadFile(filepath.Join("testdata", "bundle", "tests", "scorecard", "config.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ValidateConfig(b)).To(Succeed())
+		})
+		It("reports unknown fields", func() {
+			err := ValidateConfig([]byte("kind: Configuration\nstage: []\n"))
+			Expect(err).To(MatchError(ContainSubstring(`unknown field "stage"`)))
+		})
+		It("reports every problem with its path", func() {
+			err := ValidateConfig([]byte(`kind: Config
+apiVersion: scorecard.operatorframework.io/v1alpha3
+stages:
+- tests:
+  - image: quay.io/operator-framework/scorecard-test:dev
+    entrypoint: [scorecard-test, basic-check-specs]
+    labels:
+      test: basic
+  - image: quay.io/example/custom-test:v0.0.1
+    labels:
+      test: basic
+      "bad key!": x
+- tests: []
+`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`kind: must be "Configuration", got "Config"`))
+			Expect(err.Error()).To(ContainSubstring(`stages[0].tests[0].entrypoint: unknown built-in test "basic-check-specs"`))
+			Expect(err.Error()).To(ContainSubstring(`stages[0].tests[1].labels: invalid label key "bad key!"`))
+			Expect(err.Error()).To(ContainSubstring(`stages[0].tests[1].labels: test label "basic" is also set on stages[0].tests[0]`))
+			Expect(err.Error()).To(ContainSubstring("stages[1].tests: at least one test is required"))
+		})
+	})
+})
@@ -18,6 +18,7 @@ import (
 	"os"
 
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	cruntime "sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -40,6 +41,7 @@ func GetKubeClient(kubeconfig string) (client kubernetes.Interface, err error) {
 	if err != nil {
 		return client, err
 	}
+	tracing.WrapConfig(config)
 
 	// create the clientset
 	clientset, err := kubernetes.NewForConfig(config)
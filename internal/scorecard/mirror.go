@@ -0,0 +1,77 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseImageMirrors parses mirrors of the form "<repository>=<mirror>", ex.
+// "quay.io/operator-framework=registry.example.com/operator-framework", into a map of repositories
+// to mirrors.
+func ParseImageMirrors(mirrors []string) (map[string]string, error) {
+	m := make(map[string]string, len(mirrors))
+	for _, mirror := range mirrors {
+		split := strings.SplitN(mirror, "=", 2)
+		if len(split) != 2 || split[0] == "" || split[1] == "" {
+			return nil, fmt.Errorf("invalid image mirror %q, must be of the form <repository>=<mirror>", mirror)
+		}
+		m[split[0]] = split[1]
+	}
+	return m, nil
+}
+
+// mirrorImage returns image pulled from the mirror of the longest repository in mirrors it is in, if any.
+// A repository contains image if it is image's repository, or a path prefix of it, ex. "quay.io" and
+// "quay.io/operator-framework" both contain "quay.io/operator-framework/scorecard-test:v1.0.0".
+func mirrorImage(mirrors map[string]string, image string) string {
+	longest := ""
+	for repo := range mirrors {
+		if len(repo) > len(longest) && inRepository(image, repo) {
+			longest = repo
+		}
+	}
+	if longest == "" {
+		return image
+	}
+	return mirrors[longest] + strings.TrimPrefix(image, longest)
+}
+
+func inRepository(image, repo string) bool {
+	if !strings.HasPrefix(image, repo) {
+		return false
+	}
+	rest := image[len(repo):]
+	return rest == "" || strings.ContainsAny(rest[:1], "/:@")
+}
+
+// Images returns the images the selected tests are run with, which must be mirrored for scorecard to run
+// on clusters that cannot pull them.
+func (o Scorecard) Images() []string {
+	images := map[string]struct{}{untarImage: {}}
+	for _, stage := range o.Config.Stages {
+		for _, test := range o.selectTests(stage) {
+			images[test.Image] = struct{}{}
+		}
+	}
+	list := make([]string, 0, len(images))
+	for image := range images {
+		list = append(list, image)
+	}
+	sort.Strings(list)
+	return list
+}
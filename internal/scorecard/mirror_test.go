@@ -0,0 +1,72 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var _ = Describe("Image mirrors", func() {
+	Describe("ParseImageMirrors", func() {
+		It("parses repositories and their mirrors", func() {
+			m, err := ParseImageMirrors([]string{"quay.io/operator-framework=mirror.example.com/osdk", "busybox=mirror.example.com/busybox"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m).To(Equal(map[string]string{
+				"quay.io/operator-framework": "mirror.example.com/osdk",
+				"busybox":                    "mirror.example.com/busybox",
+			}))
+		})
+		It("rejects malformed mirrors", func() {
+			for _, mirror := range []string{"quay.io", "=mirror.example.com", "quay.io="} {
+				_, err := ParseImageMirrors([]string{mirror})
+				Expect(err).To(MatchError(`invalid image mirror "` + mirror + `", must be of the form <repository>=<mirror>`))
+			}
+		})
+	})
+
+	Describe("mirrorImage", func() {
+		mirrors := map[string]string{
+			"quay.io":                    "mirror.example.com/quay",
+			"quay.io/operator-framework": "mirror.example.com/osdk",
+			"busybox":                    "mirror.example.com/busybox",
+		}
+		It("pulls images from the mirror of their longest repository", func() {
+			Expect(mirrorImage(mirrors, "quay.io/operator-framework/scorecard-test:dev")).
+				To(Equal("mirror.example.com/osdk/scorecard-test:dev"))
+			Expect(mirrorImage(mirrors, "quay.io/example/custom-tests@sha256:abcd")).
+				To(Equal("mirror.example.com/quay/example/custom-tests@sha256:abcd"))
+			Expect(mirrorImage(mirrors, "busybox")).To(Equal("mirror.example.com/busybox"))
+			Expect(mirrorImage(mirrors, "busybox:1.32")).To(Equal("mirror.example.com/busybox:1.32"))
+		})
+		It("does not change images in no mirrored repository", func() {
+			Expect(mirrorImage(mirrors, "quay.io.example.com/tests:v1")).To(Equal("quay.io.example.com/tests:v1"))
+			Expect(mirrorImage(mirrors, "busyboxes")).To(Equal("busyboxes"))
+			Expect(mirrorImage(nil, "busybox")).To(Equal("busybox"))
+		})
+	})
+
+	Describe("Images", func() {
+		It("lists the images of the selected tests and of the test pods' init container", func() {
+			c, err := LoadConfig(filepath.Join("testdata", "bundle", "tests", "scorecard", "config.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			o := Scorecard{Config: c, Selector: labels.Everything()}
+			Expect(o.Images()).To(Equal([]string{"busybox", "quay.io/operator-framework/scorecard-test:dev"}))
+		})
+	})
+})
@@ -0,0 +1,358 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/operator-framework/operator-sdk/internal/util/projutil"
+	"github.com/operator-framework/operator-sdk/internal/version"
+)
+
+// CustomTestBinary is the name of the binary of scaffolded Go test images, the first element of
+// their tests' entrypoints.
+const CustomTestBinary = "custom-scorecard-tests"
+
+// Languages of scaffolded tests.
+const (
+	LanguageGo     = "go"
+	LanguagePython = "python"
+	LanguageShell  = "shell"
+)
+
+// Languages are the languages tests can be scaffolded in.
+var Languages = []string{LanguageGo, LanguagePython, LanguageShell}
+
+const (
+	sdkModule = "github.com/operator-framework/operator-sdk"
+	// scriptAdapter runs script tests in the scorecard-test-python and scorecard-test-shell images.
+	scriptAdapter = "scorecard-test-adapter"
+	// scriptDir is the directory of script tests in test images.
+	scriptDir = "tests"
+)
+
+var testNameRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// TestScaffold configures ScaffoldTest.
+type TestScaffold struct {
+	// Dir is the directory of the test image, a Go module for Go tests.
+	Dir string
+	// Language is the language of the test, one of Languages. Defaults to go.
+	Language string
+	// Module is the path of the test image's Go module, used if Dir has no go.mod.
+	Module string
+	// Name is the name of the test, ex. has-examples.
+	Name string
+	// Image is the test image, written in the test's config entry.
+	Image string
+	// Force overwrites the test's file if it exists.
+	Force bool
+}
+
+type scaffoldFile struct {
+	name, tmpl string
+	mode       os.FileMode
+	// test is true for the test's own file, which is only overwritten if forced.
+	test bool
+}
+
+// ScaffoldTest scaffolds a test image in s.Dir if it does not exist, then a test named s.Name in it.
+// Go tests are written with the pkg/scorecard library, and Python and shell tests are scripts run by the
+// adapter of the scorecard-test-python and scorecard-test-shell base images. Each test is in its own file,
+// so tests are added by scaffolding them one at a time. It returns the test's scorecard config entry.
+func ScaffoldTest(s TestScaffold) (string, error) {
+	if !testNameRe.MatchString(s.Name) {
+		return "", fmt.Errorf("test name %q must consist of lower case alphanumeric characters or '-', "+
+			"and start and end with an alphanumeric character", s.Name)
+	}
+	if s.Language == "" {
+		s.Language = LanguageGo
+	}
+	fileName := strings.ReplaceAll(s.Name, "-", "_")
+	data := struct {
+		TestScaffold
+		FuncName, Binary, SDKModule, SDKVersion, BaseImage string
+		Entrypoint                                         []string
+	}{
+		TestScaffold: s,
+		FuncName:     testFuncName(s.Name),
+		Binary:       CustomTestBinary,
+		SDKModule:    sdkModule,
+	}
+	if strings.HasPrefix(version.Version, "v") {
+		data.SDKVersion = strings.TrimSuffix(version.Version, "+git")
+	}
+
+	var files []scaffoldFile
+	switch s.Language {
+	case LanguageGo:
+		data.Entrypoint = []string{CustomTestBinary, s.Name}
+		files = []scaffoldFile{
+			{name: "go.mod", tmpl: goModTemplate},
+			{name: "main.go", tmpl: testMainTemplate},
+			{name: "Dockerfile", tmpl: testDockerfileTemplate},
+			{name: fileName + ".go", tmpl: testTemplate, test: true},
+		}
+	case LanguagePython, LanguageShell:
+		ext, tmpl := ".py", pythonTestTemplate
+		if s.Language == LanguageShell {
+			ext, tmpl = ".sh", shellTestTemplate
+		}
+		data.BaseImage = fmt.Sprintf("quay.io/operator-framework/scorecard-test-%s:%s", s.Language,
+			strings.TrimSuffix(version.Version, "+git"))
+		data.Entrypoint = []string{scriptAdapter, path.Join("/", scriptDir, fileName+ext)}
+		files = []scaffoldFile{
+			{name: "Dockerfile", tmpl: scriptDockerfileTemplate},
+			{name: filepath.Join(scriptDir, fileName+ext), tmpl: tmpl, mode: 0755, test: true},
+		}
+	default:
+		return "", fmt.Errorf("unknown test language %q, must be one of %v", s.Language, Languages)
+	}
+
+	for _, f := range files {
+		filePath := filepath.Join(s.Dir, f.name)
+		if _, err := os.Stat(filePath); err == nil {
+			if !f.test {
+				continue
+			}
+			if !s.Force {
+				return "", fmt.Errorf("test file %s exists, set --force to overwrite it", filePath)
+			}
+		}
+		b, err := executeTemplate(f.tmpl, data)
+		if err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(filePath), projutil.DirMode); err != nil {
+			return "", err
+		}
+		mode := f.mode
+		if mode == 0 {
+			mode = projutil.FileMode
+		}
+		if err := ioutil.WriteFile(filePath, b, mode); err != nil {
+			return "", fmt.Errorf("error writing %s: %v", filePath, err)
+		}
+	}
+
+	entry, err := executeTemplate(testEntryTemplate, data)
+	return string(entry), err
+}
+
+// testFuncName returns the name of the function of a test, ex. hasExamplesTest for has-examples.
+func testFuncName(name string) string {
+	parts := strings.Split(name, "-")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.Title(parts[i])
+	}
+	funcName := strings.Join(parts, "") + "Test"
+	if funcName[0] >= '0' && funcName[0] <= '9' {
+		funcName = "test" + strings.Title(funcName)
+	}
+	return funcName
+}
+
+func executeTemplate(text string, data interface{}) ([]byte, error) {
+	t, err := template.New("").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const goModTemplate = `module {{ .Module }}
+
+go 1.13
+{{- if .SDKVersion }}
+
+require {{ .SDKModule }} {{ .SDKVersion }}
+{{- end }}
+`
+
+const testMainTemplate = `// Command {{ .Binary }} runs the custom scorecard test named by its first argument.
+// Add a test with 'operator-sdk scorecard create test <name>'.
+package main
+
+import (
+	"{{ .SDKModule }}/pkg/scorecard"
+)
+
+// tests are the tests of this image by name, added by the init function of each test's file.
+var tests = map[string]scorecard.TestFunc{}
+
+func main() {
+	scorecard.Main(tests)
+}
+`
+
+const testTemplate = `package main
+
+import (
+	"context"
+
+	"{{ .SDKModule }}/pkg/scorecard"
+)
+
+func init() {
+	tests["{{ .Name }}"] = {{ .FuncName }}
+}
+
+// {{ .FuncName }} checks that the bundle's CSV has example CRs. Replace its body with the checks of
+// the {{ .Name }} test.
+func {{ .FuncName }}(ctx context.Context, t *scorecard.T) {
+	crs, err := t.ExampleCRs()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Logf("found %d example CRs", len(crs))
+	if len(crs) == 0 {
+		t.Errorf("the CSV has no example CRs")
+		t.Suggestf("add example CRs to the CSV's alm-examples annotation")
+	}
+
+	// Tests run in the namespace of the operator under test, t.Namespace, and can get and create
+	// objects with t.Client(). For example, to wait for an example CR to become ready:
+	//
+	//	c, err := t.Client()
+	//	if err != nil {
+	//		t.Error(err)
+	//		return
+	//	}
+	//	cr := crs[0].DeepCopy()
+	//	cr.SetNamespace(t.Namespace)
+	//	if err := c.Create(ctx, cr); err != nil {
+	//		t.Error(err)
+	//		return
+	//	}
+	//	if err := scorecard.WaitForCondition(ctx, c, cr, "Ready", "True"); err != nil {
+	//		t.Errorf("%v", err)
+	//		_ = t.WriteJSONArtifact(cr.GetName()+".json", cr)
+	//	}
+}
+`
+
+const testDockerfileTemplate = `# Build the custom scorecard test binary
+FROM golang:1.13 as builder
+
+WORKDIR /workspace
+COPY go.mod go.sum ./
+RUN go mod download
+COPY *.go ./
+RUN CGO_ENABLED=0 GOOS=linux GOARCH=amd64 GO111MODULE=on go build -a -o {{ .Binary }} .
+
+FROM registry.access.redhat.com/ubi8/ubi-minimal:latest
+COPY --from=builder /workspace/{{ .Binary }} /usr/local/bin/{{ .Binary }}
+USER 1001
+`
+
+const testEntryTemplate = `  - image: {{ .Image }}
+    entrypoint:
+{{- range .Entrypoint }}
+    - {{ . }}
+{{- end }}
+    labels:
+      suite: custom
+      test: {{ .Name }}-test
+`
+
+const scriptDockerfileTemplate = `# Scorecard tests written as scripts, run by the base image's scorecard-test-adapter.
+FROM {{ .BaseImage }}
+
+COPY tests/ /tests/
+`
+
+const pythonTestTemplate = `#!/usr/bin/env python3
+"""The {{ .Name }} scorecard test checks that the bundle's CSV has example CRs.
+Replace its checks with those of your test.
+
+The test passes if it exits with status 0 and fails if it exits with status 1. Its output is its log,
+and errors and suggestions written as JSON to the file in $SCORECARD_RESULT are shown in its result.
+"""
+import glob
+import json
+import os
+import sys
+
+import yaml
+
+
+def main():
+    errors, suggestions = [], []
+
+    csv = None
+    for path in glob.glob(os.path.join(os.environ["SCORECARD_BUNDLE_ROOT"], "manifests", "*.yaml")):
+        with open(path) as f:
+            for doc in yaml.safe_load_all(f):
+                if doc and doc.get("kind") == "ClusterServiceVersion":
+                    csv = doc
+    if csv is None:
+        errors.append("the bundle has no ClusterServiceVersion")
+    elif not json.loads((csv["metadata"].get("annotations") or {}).get("alm-examples", "[]")):
+        errors.append("the CSV has no example CRs")
+        suggestions.append("add example CRs to the alm-examples annotation of the CSV")
+
+    # Tests run in the namespace of the operator under test, $SCORECARD_NAMESPACE, and can inspect
+    # the cluster with the Kubernetes client, ex:
+    #
+    #   from kubernetes import client, config
+    #   config.load_incluster_config()
+    #   pods = client.CoreV1Api().list_namespaced_pod(os.environ["SCORECARD_NAMESPACE"])
+
+    with open(os.environ["SCORECARD_RESULT"], "w") as f:
+        json.dump({"errors": errors, "suggestions": suggestions}, f)
+    return 1 if errors else 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
+`
+
+const shellTestTemplate = `#!/usr/bin/env bash
+# The {{ .Name }} scorecard test checks that the bundle's CSV has example CRs.
+# Replace its checks with those of your test.
+#
+# The test passes if it exits with status 0 and fails if it exits with status 1. Its output is its log,
+# and errors and suggestions written as JSON to the file in $SCORECARD_RESULT are shown in its result.
+# Tests run in the namespace of the operator under test, $SCORECARD_NAMESPACE, and can inspect the
+# cluster with kubectl, ex. 'kubectl get pods -n "$SCORECARD_NAMESPACE" -o json | jq ...'.
+set -euo pipefail
+
+fail() {
+  jq -n --arg error "$1" --arg suggestion "${2:-}" \
+    '{errors: [$error], suggestions: ([$suggestion] | map(select(. != "")))}' > "$SCORECARD_RESULT"
+  exit 1
+}
+
+csv=$(grep -l "^kind: ClusterServiceVersion" "$SCORECARD_BUNDLE_ROOT"/manifests/*.yaml | head -n 1 || true)
+if [[ -z "$csv" ]]; then
+  fail "the bundle has no ClusterServiceVersion"
+fi
+if ! grep -q "alm-examples" "$csv"; then
+  fail "the CSV has no example CRs" "add example CRs to the alm-examples annotation of the CSV"
+fi
+`
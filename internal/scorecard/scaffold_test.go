@@ -0,0 +1,106 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScaffoldTest", func() {
+	var s TestScaffold
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "scorecard-tests")
+		Expect(err).NotTo(HaveOccurred())
+		s = TestScaffold{Dir: dir, Module: "example.com/memcached-operator/scorecard-tests",
+			Name: "has-examples", Image: "quay.io/example/custom-scorecard-tests:v0.0.1"}
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(s.Dir)).To(Succeed())
+	})
+
+	read := func(name string) string {
+		b, err := ioutil.ReadFile(filepath.Join(s.Dir, name))
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return string(b)
+	}
+
+	It("scaffolds a test image module and a test", func() {
+		entry, err := ScaffoldTest(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entry).To(Equal(`  - image: quay.io/example/custom-scorecard-tests:v0.0.1
+    entrypoint:
+    - custom-scorecard-tests
+    - has-examples
+    labels:
+      suite: custom
+      test: has-examples-test
+`))
+		Expect(read("go.mod")).To(HavePrefix("module example.com/memcached-operator/scorecard-tests\n"))
+		Expect(read("main.go")).To(ContainSubstring("scorecard.Main(tests)"))
+		Expect(read("Dockerfile")).To(ContainSubstring("/usr/local/bin/custom-scorecard-tests"))
+		Expect(read("has_examples.go")).To(ContainSubstring(`tests["has-examples"] = hasExamplesTest`))
+	})
+	It("adds tests to an existing module, without overwriting tests unless forced", func() {
+		_, err := ScaffoldTest(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(s.Dir, "main.go"), []byte("package main\n"), 0644)).To(Succeed())
+
+		second := s
+		second.Name = "2nd-check"
+		_, err = ScaffoldTest(second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read("2nd_check.go")).To(ContainSubstring(`tests["2nd-check"] = test2ndCheckTest`))
+		Expect(read("main.go")).To(Equal("package main\n"))
+
+		_, err = ScaffoldTest(s)
+		Expect(err).To(MatchError(ContainSubstring("has_examples.go exists, set --force to overwrite it")))
+		s.Force = true
+		_, err = ScaffoldTest(s)
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("scaffolds script tests run by the adapter of a base image", func() {
+		for _, lang := range []struct{ language, ext, shebang string }{
+			{LanguagePython, ".py", "#!/usr/bin/env python3\n"},
+			{LanguageShell, ".sh", "#!/usr/bin/env bash\n"},
+		} {
+			s.Language = lang.language
+			entry, err := ScaffoldTest(s)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entry).To(ContainSubstring("    - scorecard-test-adapter\n    - /tests/has_examples" + lang.ext + "\n"))
+			Expect(read("Dockerfile")).To(ContainSubstring("FROM quay.io/operator-framework/scorecard-test-" + lang.language))
+			Expect(read(filepath.Join("tests", "has_examples"+lang.ext))).To(HavePrefix(lang.shebang))
+			info, err := os.Stat(filepath.Join(s.Dir, "tests", "has_examples"+lang.ext))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode() & 0111).NotTo(BeZero())
+			Expect(os.RemoveAll(filepath.Join(s.Dir, "Dockerfile"))).To(Succeed())
+		}
+	})
+	It("rejects unknown languages", func() {
+		s.Language = "rust"
+		_, err := ScaffoldTest(s)
+		Expect(err).To(MatchError("unknown test language \"rust\", must be one of [go python shell]"))
+	})
+	It("rejects invalid test names", func() {
+		s.Name = "Has_Examples"
+		_, err := ScaffoldTest(s)
+		Expect(err).To(MatchError(ContainSubstring(`test name "Has_Examples" must consist of lower case`)))
+	})
+})
@@ -17,6 +17,7 @@ package scorecard
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+	"github.com/operator-framework/operator-sdk/internal/util/tracing"
 )
 
 type TestRunner interface {
@@ -49,6 +51,13 @@ type PodTestRunner struct {
 	BundlePath     string
 	BundleMetadata registryutil.Labels
 	Client         kubernetes.Interface
+	// ResultSource is where test results are read from, ResultSourceLog by default.
+	ResultSource string
+	// ImageMirrors maps image repositories to the mirrors test pods pull them from.
+	ImageMirrors map[string]string
+	// DNSPolicy and DNSConfig, if set, configure the DNS resolution of test pods.
+	DNSPolicy v1.DNSPolicy
+	DNSConfig *v1.PodDNSConfig
 
 	configMapName string
 }
@@ -126,7 +135,10 @@ func (o Scorecard) runStageSequential(ctx context.Context, tests []v1alpha3.Test
 }
 
 func (o Scorecard) runTest(ctx context.Context, test v1alpha3.TestConfiguration) v1alpha3.Test {
+	ctx, span := tracing.Start(ctx, "run test", tracing.String("image", test.Image),
+		tracing.String("entrypoint", strings.Join(test.Entrypoint, " ")))
 	result, err := o.TestRunner.RunTest(ctx, test)
+	span.End(err)
 	if err != nil {
 		result = convertErrorToStatus(err, "")
 	}
@@ -189,6 +201,11 @@ func (r PodTestRunner) Cleanup(ctx context.Context) (err error) {
 	if err != nil {
 		return err
 	}
+	if r.ResultSource == ResultSourceConfigMap {
+		if err := r.deleteResultConfigMaps(ctx, r.configMapName); err != nil {
+			return err
+		}
+	}
 	err = r.deleteConfigMap(ctx, r.configMapName)
 	if err != nil {
 		return err
@@ -200,6 +217,11 @@ func (r PodTestRunner) Cleanup(ctx context.Context) (err error) {
 func (r PodTestRunner) RunTest(ctx context.Context, test v1alpha3.TestConfiguration) (*v1alpha3.TestStatus, error) {
 	// Create a Pod to run the test
 	podDef := getPodDefinition(r.configMapName, test, r)
+	if r.ResultSource == ResultSourceConfigMap {
+		if err := r.createResultConfigMap(ctx, podDef); err != nil {
+			return nil, err
+		}
+	}
 	pod, err := r.Client.CoreV1().Pods(r.Namespace).Create(ctx, podDef, metav1.CreateOptions{})
 	if err != nil {
 		return nil, err
@@ -30,12 +30,15 @@ import (
 const (
 	// PodBundleRoot is the directory containing all bundle data within a test pod.
 	PodBundleRoot = "/bundle"
+
+	// untarImage is the image of the init container extracting the bundle in test pods.
+	untarImage = "busybox"
 )
 
 // getPodDefinition fills out a Pod definition based on
 // information from the test
 func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r PodTestRunner) *v1.Pod {
-	return &v1.Pod{
+	pod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("scorecard-test-%s", rand.String(4)),
 			Namespace: r.Namespace,
@@ -47,10 +50,12 @@ func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r P
 		Spec: v1.PodSpec{
 			ServiceAccountName: r.ServiceAccount,
 			RestartPolicy:      v1.RestartPolicyNever,
+			DNSPolicy:          r.DNSPolicy,
+			DNSConfig:          r.DNSConfig,
 			Containers: []v1.Container{
 				{
 					Name:            "scorecard-test",
-					Image:           test.Image,
+					Image:           mirrorImage(r.ImageMirrors, test.Image),
 					ImagePullPolicy: v1.PullIfNotPresent,
 					Command:         test.Entrypoint,
 					VolumeMounts: []v1.VolumeMount{
@@ -75,7 +80,7 @@ func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r P
 			InitContainers: []v1.Container{
 				{
 					Name:            "scorecard-untar",
-					Image:           "busybox",
+					Image:           mirrorImage(r.ImageMirrors, untarImage),
 					ImagePullPolicy: v1.PullIfNotPresent,
 					Args: []string{
 						"tar",
@@ -118,6 +123,14 @@ func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r P
 			},
 		},
 	}
+	if r.ResultSource == ResultSourceConfigMap {
+		// The result ConfigMap is named after the pod.
+		pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, v1.EnvVar{
+			Name:  "SCORECARD_RESULT_CONFIGMAP",
+			Value: pod.Name,
+		})
+	}
+	return pod
 }
 
 // getPodLog fetches the test results which are found in the pod log
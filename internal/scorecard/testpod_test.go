@@ -0,0 +1,109 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Test pods", func() {
+	test := v1alpha3.TestConfiguration{
+		Image:      "quay.io/operator-framework/scorecard-test:dev",
+		Entrypoint: []string{"scorecard-test", "basic-check-spec"},
+	}
+
+	Describe("getPodDefinition", func() {
+		It("runs tests with their images and the default DNS policy", func() {
+			pod := getPodDefinition("scorecard-test-abcd", test, PodTestRunner{Namespace: "default"})
+			Expect(pod.Spec.Containers[0].Image).To(Equal(test.Image))
+			Expect(pod.Spec.InitContainers[0].Image).To(Equal("busybox"))
+			Expect(pod.Spec.DNSPolicy).To(BeEmpty())
+			Expect(pod.Spec.DNSConfig).To(BeNil())
+			for _, env := range pod.Spec.Containers[0].Env {
+				Expect(env.Name).NotTo(Equal("SCORECARD_RESULT_CONFIGMAP"))
+			}
+		})
+		It("pulls images from mirrors and sets the DNS policy", func() {
+			r := PodTestRunner{
+				Namespace:    "default",
+				ImageMirrors: map[string]string{"quay.io/operator-framework": "mirror.example.com/osdk", "busybox": "mirror.example.com/busybox"},
+				DNSPolicy:    v1.DNSNone,
+				DNSConfig:    &v1.PodDNSConfig{Nameservers: []string{"10.0.0.10"}},
+			}
+			pod := getPodDefinition("scorecard-test-abcd", test, r)
+			Expect(pod.Spec.Containers[0].Image).To(Equal("mirror.example.com/osdk/scorecard-test:dev"))
+			Expect(pod.Spec.InitContainers[0].Image).To(Equal("mirror.example.com/busybox"))
+			Expect(pod.Spec.DNSPolicy).To(Equal(v1.DNSNone))
+			Expect(pod.Spec.DNSConfig.Nameservers).To(Equal([]string{"10.0.0.10"}))
+		})
+		It("names the result ConfigMap of the test if results are read from ConfigMaps", func() {
+			r := PodTestRunner{Namespace: "default", ResultSource: ResultSourceConfigMap}
+			pod := getPodDefinition("scorecard-test-abcd", test, r)
+			Expect(pod.Spec.Containers[0].Env).To(ContainElement(v1.EnvVar{
+				Name:  "SCORECARD_RESULT_CONFIGMAP",
+				Value: pod.Name,
+			}))
+		})
+	})
+
+	Describe("result ConfigMaps", func() {
+		var (
+			r   PodTestRunner
+			pod *v1.Pod
+			ctx = context.TODO()
+		)
+		BeforeEach(func() {
+			r = PodTestRunner{Namespace: "default", ResultSource: ResultSourceConfigMap, Client: fake.NewSimpleClientset()}
+			pod = getPodDefinition("scorecard-test-abcd", test, r)
+			Expect(r.createResultConfigMap(ctx, pod)).To(Succeed())
+		})
+		It("reads the result a test wrote to its ConfigMap", func() {
+			cm, err := r.Client.CoreV1().ConfigMaps("default").Get(ctx, pod.Name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cm.Labels).To(HaveKeyWithValue("testrun", "scorecard-test-abcd"))
+			cm.Data = map[string]string{"result.json": `{"results":[{"name":"basic-check-spec","state":"pass"}]}`}
+			_, err = r.Client.CoreV1().ConfigMaps("default").Update(ctx, cm, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			status := r.getTestStatus(ctx, pod)
+			Expect(status.Results).To(HaveLen(1))
+			Expect(status.Results[0].Name).To(Equal("basic-check-spec"))
+			Expect(status.Results[0].State).To(Equal(v1alpha3.PassState))
+		})
+		It("fails the test if it wrote no result", func() {
+			status := r.getTestStatus(ctx, pod)
+			Expect(status.Results).To(HaveLen(1))
+			Expect(status.Results[0].State).To(Equal(v1alpha3.FailState))
+			Expect(status.Results[0].Errors).To(Equal([]string{"test did not write a result to ConfigMap " + pod.Name +
+				", its image may not support --result-source=configmap"}))
+		})
+		It("deletes the result ConfigMaps of the test run", func() {
+			Expect(r.deleteResultConfigMaps(ctx, "scorecard-test-abcd")).To(Succeed())
+			actions := r.Client.(*fake.Clientset).Actions()
+			action, ok := actions[len(actions)-1].(k8stesting.DeleteCollectionAction)
+			Expect(ok).To(BeTrue())
+			Expect(action.GetResource().Resource).To(Equal("configmaps"))
+			Expect(action.GetListRestrictions().Labels.String()).To(Equal("testrun=scorecard-test-abcd"))
+		})
+	})
+})
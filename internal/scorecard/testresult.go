@@ -0,0 +1,79 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ResultSourceLog reads test results from test pod logs, which requires permission to get pods/log.
+	ResultSourceLog = "log"
+	// ResultSourceConfigMap reads test results from a ConfigMap per test pod, which tests write to.
+	// The test images must support it, as images built with the scorecard test library do, and their
+	// service account must be allowed to patch configmaps.
+	ResultSourceConfigMap = "configmap"
+
+	// resultConfigMapKey is the key of a test's result in its result ConfigMap.
+	resultConfigMapKey = "result.json"
+)
+
+// ResultSources are the valid sources of test results.
+var ResultSources = []string{ResultSourceLog, ResultSourceConfigMap}
+
+// createResultConfigMap creates the empty ConfigMap the test run by pod writes its result to, so the
+// test only needs permission to patch it.
+func (r PodTestRunner) createResultConfigMap(ctx context.Context, pod *v1.Pod) error {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Labels:    pod.Labels,
+		},
+	}
+	if _, err := r.Client.CoreV1().ConfigMaps(cm.Namespace).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error creating result ConfigMap %s: %w", cm.Name, err)
+	}
+	return nil
+}
+
+// getResultConfigMap fetches the result the test run by pod wrote to its result ConfigMap.
+func (r PodTestRunner) getResultConfigMap(ctx context.Context, pod *v1.Pod) ([]byte, error) {
+	cm, err := r.Client.CoreV1().ConfigMaps(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting result ConfigMap %s: %w", pod.Name, err)
+	}
+	result, ok := cm.Data[resultConfigMapKey]
+	if !ok {
+		return nil, fmt.Errorf("test did not write a result to ConfigMap %s, its image may not support "+
+			"--result-source=%s", pod.Name, ResultSourceConfigMap)
+	}
+	return []byte(result), nil
+}
+
+// deleteResultConfigMaps deletes the result ConfigMaps of a test run, which have the same labels as its pods.
+func (r PodTestRunner) deleteResultConfigMaps(ctx context.Context, configMapName string) error {
+	selector := fmt.Sprintf("testrun=%s", configMapName)
+	lo := metav1.ListOptions{LabelSelector: selector}
+	err := r.Client.CoreV1().ConfigMaps(r.Namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, lo)
+	if err != nil {
+		return fmt.Errorf("error deleting result ConfigMaps (label selector %q): %w", selector, err)
+	}
+	return nil
+}
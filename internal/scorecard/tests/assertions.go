@@ -0,0 +1,253 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	ClusterAssertionsTest = "cluster-assertions"
+
+	// DefaultAssertionsFile is the path of the assertions file in a bundle, if none is passed to the test.
+	DefaultAssertionsFile = "tests/scorecard/assertions.yaml"
+
+	assertionsPollInterval = 2 * time.Second
+)
+
+// AssertionsFile declares cluster objects to retrieve, and CEL expressions asserting their state.
+//
+//	objects:
+//	  deployment:
+//	    apiVersion: apps/v1
+//	    kind: Deployment
+//	    name: memcached-operator-controller-manager
+//	  pods:
+//	    apiVersion: v1
+//	    kind: Pod
+//	    selector: control-plane=controller-manager
+//	assertions:
+//	- expression: deployment.status.availableReplicas >= 1
+//	  description: The operator is available
+//	- expression: pods.all(p, p.status.phase == 'Running')
+//	timeout: 2m
+type AssertionsFile struct {
+	// Objects are bound to CEL variables by name.
+	Objects map[string]ObjectQuery `json:"objects"`
+	// Assertions must all evaluate to true for the test to pass.
+	Assertions []ClusterAssertion `json:"assertions"`
+	// Timeout is how long to retry failing assertions, ex. while a Deployment becomes available.
+	// Assertions are evaluated once if unset.
+	Timeout string `json:"timeout,omitempty"`
+
+	timeout  time.Duration
+	programs []cel.Program
+}
+
+// ObjectQuery retrieves a single object by name, or a list of objects by label selector.
+type ObjectQuery struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Namespace defaults to the test's namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// Name retrieves the named object, which is bound as a map.
+	Name string `json:"name,omitempty"`
+	// Selector retrieves the objects matching a label selector, which are bound as a list of maps.
+	// Every object of the kind in the namespace is retrieved if Name and Selector are unset.
+	Selector string `json:"selector,omitempty"`
+}
+
+// ClusterAssertion is a CEL expression evaluating to a bool.
+type ClusterAssertion struct {
+	Expression  string `json:"expression"`
+	Description string `json:"description,omitempty"`
+}
+
+func (a ClusterAssertion) String() string {
+	if a.Description == "" {
+		return a.Expression
+	}
+	return fmt.Sprintf("%s (%s)", a.Description, a.Expression)
+}
+
+// ReadAssertionsFile reads the assertions file at path, and compiles its assertions.
+func ReadAssertionsFile(path string) (*AssertionsFile, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &AssertionsFile{}
+	if err := yaml.UnmarshalStrict(b, f); err != nil {
+		return nil, fmt.Errorf("error unmarshalling assertions file %s: %v", path, err)
+	}
+	if err := f.compile(); err != nil {
+		return nil, fmt.Errorf("invalid assertions file %s: %v", path, err)
+	}
+	return f, nil
+}
+
+// compile validates f and compiles its assertions against variables of its objects' names.
+func (f *AssertionsFile) compile() (err error) {
+	if len(f.Assertions) == 0 {
+		return fmt.Errorf("at least one assertion is required")
+	}
+	if f.Timeout != "" {
+		if f.timeout, err = time.ParseDuration(f.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout %q: %v", f.Timeout, err)
+		}
+	}
+	vars := make([]*exprpb.Decl, 0, len(f.Objects))
+	for name, q := range f.Objects {
+		if q.APIVersion == "" || q.Kind == "" {
+			return fmt.Errorf("objects.%s: apiVersion and kind are required", name)
+		}
+		if q.Name != "" && q.Selector != "" {
+			return fmt.Errorf("objects.%s: only one of name and selector may be set", name)
+		}
+		if _, err := labels.Parse(q.Selector); err != nil {
+			return fmt.Errorf("objects.%s: invalid selector: %v", name, err)
+		}
+		vars = append(vars, decls.NewVar(name, decls.Dyn))
+	}
+	env, err := cel.NewEnv(cel.Declarations(vars...))
+	if err != nil {
+		return err
+	}
+	f.programs = make([]cel.Program, len(f.Assertions))
+	for i, a := range f.Assertions {
+		ast, issues := env.Compile(a.Expression)
+		if issues != nil && issues.Err() != nil {
+			return fmt.Errorf("assertions[%d]: %v", i, issues.Err())
+		}
+		if t := ast.ResultType(); !proto.Equal(t, decls.Bool) && !proto.Equal(t, decls.Dyn) {
+			return fmt.Errorf("assertions[%d]: expression must evaluate to a bool", i)
+		}
+		if f.programs[i], err = env.Program(ast); err != nil {
+			return fmt.Errorf("assertions[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// AssertionsTest retrieves f's objects from namespace and evaluates f's assertions against them, until all
+// assertions are true or f's timeout expires. Each assertion that is not true is reported as an error.
+func AssertionsTest(ctx context.Context, c client.Client, namespace string,
+	f *AssertionsFile) scapiv1alpha3.TestStatus {
+
+	r := scapiv1alpha3.TestResult{
+		Name:        ClusterAssertionsTest,
+		State:       scapiv1alpha3.PassState,
+		Errors:      make([]string, 0),
+		Suggestions: make([]string, 0),
+	}
+
+	var failed []string
+	evaluate := func() (bool, error) {
+		vars, err := getObjects(ctx, c, namespace, f.Objects)
+		if err != nil {
+			failed = []string{err.Error()}
+			return false, nil
+		}
+		failed = nil
+		for i, prg := range f.programs {
+			out, _, err := prg.Eval(vars)
+			switch {
+			case err != nil:
+				failed = append(failed, fmt.Sprintf("%s: %v", f.Assertions[i], err))
+			case out.Value() != true:
+				failed = append(failed, fmt.Sprintf("%s: evaluated to %v", f.Assertions[i], out.Value()))
+			}
+		}
+		return len(failed) == 0, nil
+	}
+
+	ok, err := evaluate()
+	if !ok && err == nil && f.timeout > 0 {
+		err = wait.PollImmediate(assertionsPollInterval, f.timeout, evaluate)
+	}
+	if err != nil && err != wait.ErrWaitTimeout {
+		failed = append(failed, err.Error())
+	}
+	if len(failed) != 0 {
+		r.Errors = append(r.Errors, failed...)
+		r.State = scapiv1alpha3.FailState
+	} else {
+		r.Log = fmt.Sprintf("%d assertions are true\n", len(f.Assertions))
+	}
+	return wrapResult(r)
+}
+
+// getObjects retrieves the objects of queries in namespace, keyed by the name they are bound to.
+func getObjects(ctx context.Context, c client.Client, namespace string,
+	queries map[string]ObjectQuery) (map[string]interface{}, error) {
+
+	vars := make(map[string]interface{}, len(queries))
+	for name, q := range queries {
+		gv, err := schema.ParseGroupVersion(q.APIVersion)
+		if err != nil {
+			return nil, fmt.Errorf("objects.%s: invalid apiVersion: %v", name, err)
+		}
+		ns := q.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		if q.Name != "" {
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(gv.WithKind(q.Kind))
+			if err := c.Get(ctx, types.NamespacedName{Namespace: ns, Name: q.Name}, obj); err != nil {
+				if apierrors.IsNotFound(err) {
+					return nil, fmt.Errorf("objects.%s: %s %s/%s not found", name, q.Kind, ns, q.Name)
+				}
+				return nil, fmt.Errorf("objects.%s: error getting %s %s/%s: %v", name, q.Kind, ns, q.Name, err)
+			}
+			vars[name] = obj.Object
+			continue
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gv.WithKind(q.Kind + "List"))
+		sel, err := labels.Parse(q.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("objects.%s: invalid selector: %v", name, err)
+		}
+		opts := []client.ListOption{client.InNamespace(ns), client.MatchingLabelsSelector{Selector: sel}}
+		if err := c.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("objects.%s: error listing %s: %v", name, strings.ToLower(q.Kind), err)
+		}
+		items := make([]interface{}, len(list.Items))
+		for i := range list.Items {
+			items[i] = list.Items[i].Object
+		}
+		vars[name] = items
+	}
+	return vars, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Cluster assertions test", func() {
+	var (
+		dir string
+		c   client.Client
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "assertions")
+		Expect(err).NotTo(HaveOccurred())
+
+		dep := &appsv1.Deployment{}
+		dep.SetNamespace("default")
+		dep.SetName("memcached-operator-controller-manager")
+		dep.Status.AvailableReplicas = 1
+		pod := &corev1.Pod{}
+		pod.SetNamespace("default")
+		pod.SetName("memcached-operator-controller-manager-6f4b")
+		pod.SetLabels(map[string]string{"control-plane": "controller-manager"})
+		pod.Status.Phase = corev1.PodRunning
+		c = fake.NewFakeClient(dep, pod)
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	read := func(content string) (*AssertionsFile, error) {
+		path := filepath.Join(dir, "assertions.yaml")
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return ReadAssertionsFile(path)
+	}
+	const objects = `objects:
+  deployment:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: memcached-operator-controller-manager
+  pods:
+    apiVersion: v1
+    kind: Pod
+    selector: control-plane=controller-manager
+`
+
+	It("passes when every assertion is true", func() {
+		f, err := read(objects + `assertions:
+- expression: deployment.status.availableReplicas >= 1
+  description: The operator is available
+- expression: size(pods) == 1 && pods.all(p, p.status.phase == 'Running')
+`)
+		Expect(err).NotTo(HaveOccurred())
+		status := AssertionsTest(context.TODO(), c, "default", f)
+		Expect(status.Results[0].State).To(Equal(scapiv1alpha3.PassState))
+		Expect(status.Results[0].Errors).To(BeEmpty())
+	})
+	It("reports assertions that are false", func() {
+		f, err := read(objects + `assertions:
+- expression: deployment.status.availableReplicas >= 2
+  description: The operator is highly available
+`)
+		Expect(err).NotTo(HaveOccurred())
+		status := AssertionsTest(context.TODO(), c, "default", f)
+		Expect(status.Results[0].State).To(Equal(scapiv1alpha3.FailState))
+		Expect(status.Results[0].Errors).To(Equal([]string{"The operator is highly available " +
+			"(deployment.status.availableReplicas >= 2): evaluated to false"}))
+	})
+	It("reports objects that are not found", func() {
+		f, err := read(`objects:
+  deployment:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: missing
+assertions:
+- expression: deployment.status.availableReplicas >= 1
+`)
+		Expect(err).NotTo(HaveOccurred())
+		status := AssertionsTest(context.TODO(), c, "default", f)
+		Expect(status.Results[0].State).To(Equal(scapiv1alpha3.FailState))
+		Expect(status.Results[0].Errors).To(Equal([]string{"objects.deployment: Deployment default/missing not found"}))
+	})
+	It("fails to read assertions that do not compile", func() {
+		_, err := read(objects + `assertions:
+- expression: service.spec.clusterIP != ''
+`)
+		Expect(err).To(MatchError(ContainSubstring("assertions[0]")))
+		Expect(err).To(MatchError(ContainSubstring("undeclared reference to 'service'")))
+	})
+	It("fails to read assertions that do not evaluate to a bool", func() {
+		_, err := read(objects + `assertions:
+- expression: size(pods) + 1
+`)
+		Expect(err).To(MatchError(ContainSubstring("expression must evaluate to a bool")))
+	})
+})
@@ -0,0 +1,196 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/prometheus/common/expfmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	BasicCheckMetricsTest = "basic-check-metrics"
+
+	// metricsServicePort is the port kube-rbac-proxy serves the manager's metrics on in scaffolded projects.
+	metricsServicePort = 8443
+	// reconcileErrorsMetric is only exported by controller-runtime after a reconcile fails.
+	reconcileErrorsMetric = "controller_runtime_reconcile_errors_total"
+
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	metricsPollInterval     = 5 * time.Second
+	metricsPollTimeout      = 2 * time.Minute
+)
+
+// RequiredMetrics are exposed by every controller-runtime manager once it has reconciled an object.
+var RequiredMetrics = []string{
+	"controller_runtime_reconcile_total",
+	"workqueue_depth",
+}
+
+// MetricsScraper returns the text-format metrics exposed by an operator.
+type MetricsScraper func(ctx context.Context) (string, error)
+
+// DefaultMetricsURL returns the URL of the metrics Service scaffolded for the bundle's
+// first Deployment in namespace.
+func DefaultMetricsURL(bundle *apimanifests.Bundle, namespace string) (string, error) {
+	deps := bundle.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+	if len(deps) == 0 {
+		return "", fmt.Errorf("ClusterServiceVersion %s has no deployments", bundle.CSV.GetName())
+	}
+	return fmt.Sprintf("https://%s-metrics-service.%s.svc:%d/metrics", deps[0].Name, namespace, metricsServicePort), nil
+}
+
+// NewMetricsScraper returns a MetricsScraper that scrapes url with the test pod's service account token.
+// The metrics proxy's serving certificate is self-signed, so it is not verified.
+func NewMetricsScraper(url string) MetricsScraper {
+	httpClient := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}, //nolint:gosec
+		Timeout:   metricsPollInterval,
+	}
+	return func(ctx context.Context) (string, error) {
+		token, err := ioutil.ReadFile(serviceAccountTokenPath)
+		if err != nil {
+			return "", fmt.Errorf("error reading service account token: %v", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("scraping %s returned status %s", url, resp.Status)
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		return string(b), err
+	}
+}
+
+// MetricsTest creates the bundle's example CRs in namespace so its operator reconciles them, then scrapes
+// the operator's metrics until all RequiredMetrics are exposed. CRs created by the test are deleted when it completes.
+func MetricsTest(ctx context.Context, bundle *apimanifests.Bundle, c client.Client, namespace string,
+	scrape MetricsScraper) scapiv1alpha3.TestStatus {
+
+	r := scapiv1alpha3.TestResult{
+		Name:        BasicCheckMetricsTest,
+		State:       scapiv1alpha3.PassState,
+		Errors:      make([]string, 0),
+		Suggestions: make([]string, 0),
+	}
+
+	crs, err := GetCRs(bundle)
+	if err != nil {
+		r.Errors = append(r.Errors, err.Error())
+		r.State = scapiv1alpha3.FailState
+		return wrapResult(r)
+	}
+	if len(crs) == 0 {
+		r.Suggestions = append(r.Suggestions, "Add example CRs to the alm-examples annotation so they can be reconciled")
+	}
+	var created []*unstructured.Unstructured
+	defer func() {
+		for _, cr := range created {
+			if err := c.Delete(ctx, cr); err != nil && !apierrors.IsNotFound(err) {
+				log.Printf("Failed to delete %s %s: %v", cr.GetKind(), cr.GetName(), err)
+			}
+		}
+	}()
+	for i := range crs {
+		cr := &crs[i]
+		if cr.GetNamespace() == "" {
+			cr.SetNamespace(namespace)
+		}
+		if err := c.Create(ctx, cr); err != nil {
+			if !apierrors.IsAlreadyExists(err) {
+				r.Errors = append(r.Errors, fmt.Sprintf("error creating %s %s: %v", cr.GetKind(), cr.GetName(), err))
+				r.State = scapiv1alpha3.FailState
+				return wrapResult(r)
+			}
+			continue
+		}
+		created = append(created, cr)
+		r.Log += fmt.Sprintf("Created %s %s\n", cr.GetKind(), cr.GetName())
+	}
+
+	var missing []string
+	err = wait.PollImmediate(metricsPollInterval, metricsPollTimeout, func() (bool, error) {
+		body, err := scrape(ctx)
+		if err != nil {
+			r.Log += fmt.Sprintf("Failed to scrape metrics: %v\n", err)
+			return false, nil
+		}
+		var exposed map[string]struct{}
+		if exposed, err = exposedMetrics(body); err != nil {
+			return false, err
+		}
+		missing = missingMetrics(exposed, RequiredMetrics)
+		if _, hasErrors := exposed[reconcileErrorsMetric]; !hasErrors && len(missing) == 0 {
+			r.Log += fmt.Sprintf("%s is not exposed, since no reconcile has failed\n", reconcileErrorsMetric)
+		}
+		return len(missing) == 0, nil
+	})
+	if err != nil {
+		r.State = scapiv1alpha3.FailState
+		if len(missing) != 0 {
+			r.Errors = append(r.Errors, fmt.Sprintf("required metrics not exposed: %s", strings.Join(missing, ", ")))
+		} else {
+			r.Errors = append(r.Errors, fmt.Sprintf("error scraping metrics: %v", err))
+		}
+		r.Suggestions = append(r.Suggestions, "Ensure the operator is running, and the scorecard service account "+
+			"is bound to the metrics-reader ClusterRole and can create the operator's example CRs")
+	}
+	return wrapResult(r)
+}
+
+// exposedMetrics returns the names of metrics with at least one sample in text-format body.
+func exposedMetrics(body string) (map[string]struct{}, error) {
+	families, err := (&expfmt.TextParser{}).TextToMetricFamilies(strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %v", err)
+	}
+	exposed := make(map[string]struct{}, len(families))
+	for name, family := range families {
+		if len(family.GetMetric()) != 0 {
+			exposed[name] = struct{}{}
+		}
+	}
+	return exposed, nil
+}
+
+func missingMetrics(exposed map[string]struct{}, required []string) (missing []string) {
+	for _, name := range required {
+		if _, ok := exposed[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Metrics test", func() {
+	const metrics = `# HELP controller_runtime_reconcile_total Total number of reconciliations per controller
+# TYPE controller_runtime_reconcile_total counter
+controller_runtime_reconcile_total{controller="memcached",result="success"} 3
+# HELP workqueue_depth Current depth of workqueue
+# TYPE workqueue_depth gauge
+workqueue_depth{name="memcached"} 0
+`
+
+	var bundle *apimanifests.Bundle
+
+	BeforeEach(func() {
+		csv := &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []operatorsv1alpha1.StrategyDeploymentSpec{
+			{Name: "memcached-operator-controller-manager"},
+		}
+		bundle = &apimanifests.Bundle{CSV: csv}
+	})
+
+	It("returns a pass state when required metrics are exposed", func() {
+		scrape := func(context.Context) (string, error) { return metrics, nil }
+		status := MetricsTest(context.TODO(), bundle, fake.NewFakeClient(), "default", scrape)
+		Expect(status.Results[0].State).To(Equal(scapiv1alpha3.PassState))
+	})
+	It("returns the default metrics service URL", func() {
+		url, err := DefaultMetricsURL(bundle, "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(url).To(Equal("https://memcached-operator-controller-manager-metrics-service.default.svc:8443/metrics"))
+	})
+	It("lists metrics that are not exposed", func() {
+		exposed, err := exposedMetrics(metrics)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missingMetrics(exposed, []string{"workqueue_depth", "workqueue_adds_total"})).
+			To(Equal([]string{"workqueue_adds_total"}))
+	})
+	It("does not consider scrape errors fatal", func() {
+		calls := 0
+		scrape := func(context.Context) (string, error) {
+			if calls++; calls == 1 {
+				return "", errors.New("connection refused")
+			}
+			return metrics, nil
+		}
+		status := MetricsTest(context.TODO(), bundle, fake.NewFakeClient(), "default", scrape)
+		Expect(status.Results[0].State).To(Equal(scapiv1alpha3.PassState))
+		Expect(status.Results[0].Log).To(ContainSubstring("connection refused"))
+	})
+})
@@ -0,0 +1,341 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	BasicCheckResilienceTest = "basic-check-resilience"
+
+	resiliencePollInterval = 2 * time.Second
+	resiliencePollTimeout  = 3 * time.Minute
+)
+
+// readyConditionTypes are status condition types that, if a CR has them, must be "True" for the CR to converge.
+var readyConditionTypes = []string{"Ready", "Available", "Successful"}
+
+// ResilienceTestOptions configure ResilienceTest.
+type ResilienceTestOptions struct {
+	// Timeout bounds waiting for the operator to restart, and for CRs and operand pods to converge.
+	Timeout time.Duration
+}
+
+// ResilienceTest creates the bundle's example CRs in namespace and immediately deletes the operator's pods,
+// then checks that the operator is restarted and the CRs converge. It then deletes the pods the operator
+// created for the CRs, and checks that they are replaced and the CRs converge again. A CR has converged when
+// it has a status, its status.observedGeneration, if set, is its generation, and its Ready, Available,
+// or Successful conditions, if any, are "True". CRs created by the test are deleted when it completes.
+func ResilienceTest(ctx context.Context, bundle *apimanifests.Bundle, c client.Client, namespace string,
+	opts ResilienceTestOptions) scapiv1alpha3.TestStatus {
+
+	r := scapiv1alpha3.TestResult{
+		Name:        BasicCheckResilienceTest,
+		State:       scapiv1alpha3.PassState,
+		Errors:      make([]string, 0),
+		Suggestions: make([]string, 0),
+	}
+	fail := func(format string, args ...interface{}) scapiv1alpha3.TestStatus {
+		r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+		r.State = scapiv1alpha3.FailState
+		return wrapResult(r)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = resiliencePollTimeout
+	}
+
+	selectors, err := operatorSelectors(bundle)
+	if err != nil {
+		return fail("%v", err)
+	}
+	crs, err := GetCRs(bundle)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if len(crs) == 0 {
+		r.Suggestions = append(r.Suggestions, "Add example CRs to the alm-examples annotation so they can be reconciled")
+		return wrapResult(r)
+	}
+
+	var created []*unstructured.Unstructured
+	defer func() {
+		for _, cr := range created {
+			if err := c.Delete(ctx, cr); err != nil && !apierrors.IsNotFound(err) {
+				log.Printf("Failed to delete %s %s: %v", cr.GetKind(), cr.GetName(), err)
+			}
+		}
+	}()
+	for i := range crs {
+		cr := &crs[i]
+		if cr.GetNamespace() == "" {
+			cr.SetNamespace(namespace)
+		}
+		if err := c.Create(ctx, cr); err != nil {
+			return fail("error creating %s %s: %v", cr.GetKind(), cr.GetName(), err)
+		}
+		created = append(created, cr)
+	}
+
+	// Restart the operator while it reconciles the new CRs.
+	deleted, err := deletePods(ctx, c, namespace, func(pod corev1.Pod) bool {
+		return podMatches(pod, selectors)
+	})
+	if err != nil {
+		return fail("error deleting operator pods: %v", err)
+	}
+	if len(deleted) == 0 {
+		return fail("no operator pods found in namespace %s", namespace)
+	}
+	r.Log += fmt.Sprintf("Deleted operator pods %s\n", strings.Join(deleted, ", "))
+	if err := waitForReadyPods(ctx, c, namespace, deleted, opts.Timeout, func(pod corev1.Pod) bool {
+		return podMatches(pod, selectors)
+	}); err != nil {
+		return fail("operator was not restarted: %v", err)
+	}
+	if err := waitForConvergence(ctx, c, created, opts.Timeout); err != nil {
+		r.Suggestions = append(r.Suggestions, "Ensure the operator's reconciliation is idempotent and does not "+
+			"depend on state lost when the operator restarts")
+		return fail("CRs did not converge after the operator restarted: %v", err)
+	}
+	r.Log += "CRs converged after the operator restarted\n"
+
+	// Kill the operands, which the operator or their owners must restore.
+	owned := map[types.UID]struct{}{}
+	for _, cr := range created {
+		owned[cr.GetUID()] = struct{}{}
+	}
+	if err := addOwned(ctx, c, namespace, owned); err != nil {
+		return fail("%v", err)
+	}
+	isOperand := func(pod corev1.Pod) bool {
+		return !podMatches(pod, selectors) && isOwned(pod.GetOwnerReferences(), owned)
+	}
+	if deleted, err = deletePods(ctx, c, namespace, isOperand); err != nil {
+		return fail("error deleting operand pods: %v", err)
+	}
+	if len(deleted) == 0 {
+		r.Log += "CRs have no operand pods\n"
+		return wrapResult(r)
+	}
+	r.Log += fmt.Sprintf("Deleted operand pods %s\n", strings.Join(deleted, ", "))
+	if err := waitForReadyPods(ctx, c, namespace, deleted, opts.Timeout, isOperand); err != nil {
+		r.Suggestions = append(r.Suggestions, "Ensure the operator watches the resources it creates and recreates them")
+		return fail("operand pods were not restored: %v", err)
+	}
+	if err := waitForConvergence(ctx, c, created, opts.Timeout); err != nil {
+		return fail("CRs did not converge after operand pods were deleted: %v", err)
+	}
+	r.Log += "Operand pods were restored and CRs converged\n"
+	return wrapResult(r)
+}
+
+// podMatches returns true if pod is selected by any of selectors.
+func podMatches(pod corev1.Pod, selectors []labels.Selector) bool {
+	for _, sel := range selectors {
+		if sel.Matches(labels.Set(pod.GetLabels())) {
+			return true
+		}
+	}
+	return false
+}
+
+// deletePods deletes the pods in namespace matching f, returning their names.
+func deletePods(ctx context.Context, c client.Client, namespace string, f func(corev1.Pod) bool) ([]string, error) {
+	pods := corev1.PodList{}
+	if err := c.List(ctx, &pods, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var deleted []string
+	for i, pod := range pods.Items {
+		if pod.GetDeletionTimestamp() != nil || !f(pod) {
+			continue
+		}
+		if err := c.Delete(ctx, &pods.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		deleted = append(deleted, pod.GetName())
+	}
+	sort.Strings(deleted)
+	return deleted, nil
+}
+
+// waitForReadyPods waits for as many ready pods in namespace matching f as were deleted,
+// none of which are one of deleted.
+func waitForReadyPods(ctx context.Context, c client.Client, namespace string, deleted []string,
+	timeout time.Duration, f func(corev1.Pod) bool) error {
+
+	ready, count := 0, len(deleted)
+	err := wait.PollImmediate(resiliencePollInterval, timeout, func() (bool, error) {
+		pods := corev1.PodList{}
+		if err := c.List(ctx, &pods, client.InNamespace(namespace)); err != nil {
+			return false, err
+		}
+		ready = 0
+		for _, pod := range pods.Items {
+			if f(pod) && !containsString(deleted, pod.GetName()) && pod.GetDeletionTimestamp() == nil && isPodReady(pod) {
+				ready++
+			}
+		}
+		return ready >= count, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("%d of %d pods ready", ready, count)
+	}
+	return err
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// waitForConvergence waits for every CR in crs to converge.
+func waitForConvergence(ctx context.Context, c client.Client, crs []*unstructured.Unstructured,
+	timeout time.Duration) error {
+
+	var pending []string
+	err := wait.PollImmediate(resiliencePollInterval, timeout, func() (bool, error) {
+		pending = nil
+		for _, cr := range crs {
+			cur := cr.DeepCopy()
+			key := types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}
+			if err := c.Get(ctx, key, cur); err != nil {
+				return false, err
+			}
+			if reason := notConverged(cur); reason != "" {
+				pending = append(pending, fmt.Sprintf("%s %s (%s)", cur.GetKind(), cur.GetName(), reason))
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("not converged: %s", strings.Join(pending, ", "))
+	}
+	return err
+}
+
+// notConverged returns why cr has not converged, or an empty string if it has.
+func notConverged(cr *unstructured.Unstructured) string {
+	status, ok, _ := unstructured.NestedMap(cr.Object, "status")
+	if !ok || len(status) == 0 {
+		return "no status"
+	}
+	if observed, ok, _ := unstructured.NestedInt64(status, "observedGeneration"); ok && observed != cr.GetGeneration() {
+		return fmt.Sprintf("observed generation %d, expected %d", observed, cr.GetGeneration())
+	}
+	conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+	for _, cond := range conditions {
+		m, ok := cond.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _ := m["type"].(string)
+		condStatus, _ := m["status"].(string)
+		if containsString(readyConditionTypes, condType) && condStatus != string(corev1.ConditionTrue) {
+			return fmt.Sprintf("condition %s is %s", condType, condStatus)
+		}
+	}
+	return ""
+}
+
+// addOwned adds the UIDs of the workloads in namespace owned by owners, directly or through
+// other workloads, to owners.
+func addOwned(ctx context.Context, c client.Client, namespace string, owners map[types.UID]struct{}) error {
+	workloads, err := listWorkloads(ctx, c, namespace)
+	if err != nil {
+		return err
+	}
+	// Owners may be listed after the workloads they own, so ownership is propagated until no workload is added.
+	for added := true; added; {
+		added = false
+		for _, w := range workloads {
+			if _, ok := owners[w.GetUID()]; !ok && isOwned(w.GetOwnerReferences(), owners) {
+				owners[w.GetUID()] = struct{}{}
+				added = true
+			}
+		}
+	}
+	return nil
+}
+
+// listWorkloads returns the Deployments, ReplicaSets, StatefulSets, DaemonSets, and Jobs in namespace.
+func listWorkloads(ctx context.Context, c client.Client, namespace string) ([]metav1.Object, error) {
+	var workloads []metav1.Object
+	deps, rss, sss, dss, jobs := appsv1.DeploymentList{}, appsv1.ReplicaSetList{}, appsv1.StatefulSetList{},
+		appsv1.DaemonSetList{}, batchv1.JobList{}
+	for _, list := range []runtime.Object{&deps, &rss, &sss, &dss, &jobs} {
+		if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("error listing workloads: %v", err)
+		}
+	}
+	for i := range deps.Items {
+		workloads = append(workloads, &deps.Items[i])
+	}
+	for i := range rss.Items {
+		workloads = append(workloads, &rss.Items[i])
+	}
+	for i := range sss.Items {
+		workloads = append(workloads, &sss.Items[i])
+	}
+	for i := range dss.Items {
+		workloads = append(workloads, &dss.Items[i])
+	}
+	for i := range jobs.Items {
+		workloads = append(workloads, &jobs.Items[i])
+	}
+	return workloads, nil
+}
+
+func isOwned(refs []metav1.OwnerReference, owners map[types.UID]struct{}) bool {
+	for _, ref := range refs {
+		if _, ok := owners[ref.UID]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Resilience test", func() {
+	Describe("notConverged", func() {
+		var cr *unstructured.Unstructured
+
+		BeforeEach(func() {
+			cr = &unstructured.Unstructured{Object: map[string]interface{}{}}
+			cr.SetGeneration(2)
+		})
+
+		It("requires a status", func() {
+			Expect(notConverged(cr)).To(Equal("no status"))
+		})
+		It("requires the current generation to be observed", func() {
+			Expect(unstructured.SetNestedField(cr.Object, int64(1), "status", "observedGeneration")).To(Succeed())
+			Expect(notConverged(cr)).To(Equal("observed generation 1, expected 2"))
+		})
+		It("requires ready conditions to be true", func() {
+			Expect(unstructured.SetNestedSlice(cr.Object, []interface{}{
+				map[string]interface{}{"type": "Progressing", "status": "True"},
+				map[string]interface{}{"type": "Available", "status": "False"},
+			}, "status", "conditions")).To(Succeed())
+			Expect(notConverged(cr)).To(Equal("condition Available is False"))
+		})
+		It("returns an empty string for a converged CR", func() {
+			Expect(unstructured.SetNestedField(cr.Object, int64(2), "status", "observedGeneration")).To(Succeed())
+			Expect(unstructured.SetNestedSlice(cr.Object, []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			}, "status", "conditions")).To(Succeed())
+			Expect(notConverged(cr)).To(BeEmpty())
+		})
+	})
+
+	It("adds workloads owned through other workloads", func() {
+		owner := func(uid types.UID) []metav1.OwnerReference {
+			return []metav1.OwnerReference{{UID: uid}}
+		}
+		rs := &appsv1.ReplicaSet{}
+		rs.SetNamespace("default")
+		rs.SetName("memcached-sample-5d8f")
+		rs.SetUID("rs")
+		rs.SetOwnerReferences(owner("dep"))
+		dep := &appsv1.Deployment{}
+		dep.SetNamespace("default")
+		dep.SetName("memcached-sample")
+		dep.SetUID("dep")
+		dep.SetOwnerReferences(owner("cr"))
+		other := &appsv1.Deployment{}
+		other.SetNamespace("default")
+		other.SetName("memcached-operator-controller-manager")
+		other.SetUID("other")
+
+		owned := map[types.UID]struct{}{"cr": {}}
+		Expect(addOwned(context.TODO(), fake.NewFakeClient(rs, dep, other), "default", owned)).To(Succeed())
+		Expect(owned).To(HaveLen(3))
+		Expect(owned).To(HaveKey(types.UID("rs")))
+		Expect(owned).NotTo(HaveKey(types.UID("other")))
+	})
+
+	It("deletes the matching pods", func() {
+		running := func(name string) *corev1.Pod {
+			pod := &corev1.Pod{}
+			pod.SetNamespace("default")
+			pod.SetName(name)
+			pod.SetLabels(map[string]string{"control-plane": name})
+			return pod
+		}
+		c := fake.NewFakeClient(running("controller-manager"), running("memcached"))
+		deleted, err := deletePods(context.TODO(), c, "default", func(pod corev1.Pod) bool {
+			return pod.GetLabels()["control-plane"] == "controller-manager"
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(Equal([]string{"controller-manager"}))
+		pods := corev1.PodList{}
+		Expect(c.List(context.TODO(), &pods)).To(Succeed())
+		Expect(pods.Items).To(HaveLen(1))
+		Expect(pods.Items[0].GetName()).To(Equal("memcached"))
+	})
+})
@@ -0,0 +1,376 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	BasicCheckResourcesTest = "basic-check-resources"
+
+	// DefaultReconcileCycles is the number of times example CRs are created and deleted by ResourcesTest.
+	DefaultReconcileCycles = 3
+	// maxMemoryGrowth is the fraction operator memory may grow by across all reconcile cycles
+	// before steady growth is considered unbounded.
+	maxMemoryGrowth = 0.5
+
+	resourcesPollInterval = 2 * time.Second
+	resourcesPollTimeout  = 2 * time.Minute
+	// resourcesSettleTime is how long the operator is given to reconcile created CRs before usage is sampled.
+	resourcesSettleTime = 15 * time.Second
+)
+
+// podMetricsListGVK is served by the metrics API, ex. by metrics-server.
+var podMetricsListGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetricsList"}
+
+// leakCheckedKinds are the kinds of namespaced objects operators commonly create for a CR. Pods and
+// ReplicaSets are not checked, since they are owned by other checked kinds and scorecard creates test pods.
+var leakCheckedKinds = []schema.GroupVersionKind{
+	{Group: "apps", Version: "v1", Kind: "DeploymentList"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSetList"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSetList"},
+	{Group: "batch", Version: "v1", Kind: "JobList"},
+	{Version: "v1", Kind: "ServiceList"},
+	{Version: "v1", Kind: "ConfigMapList"},
+	{Version: "v1", Kind: "SecretList"},
+	{Version: "v1", Kind: "PersistentVolumeClaimList"},
+	{Version: "v1", Kind: "ServiceAccountList"},
+}
+
+// Usage is the CPU and memory used by an operator's pods.
+type Usage struct {
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+func (u Usage) String() string {
+	return fmt.Sprintf("cpu %s, memory %s", u.CPU.String(), u.Memory.String())
+}
+
+// UsageSampler returns the current CPU and memory usage of an operator.
+type UsageSampler func(ctx context.Context) (Usage, error)
+
+// ResourcesTestOptions configure ResourcesTest.
+type ResourcesTestOptions struct {
+	// Cycles is the number of times the example CRs are created and deleted.
+	Cycles int
+	// SettleTime is how long the operator is given to reconcile created or deleted CRs before usage is sampled.
+	SettleTime time.Duration
+	// Timeout bounds waiting for deleted CRs and the objects created for them to be removed.
+	Timeout time.Duration
+}
+
+// NewPodMetricsSampler returns a UsageSampler summing the metrics API usage of the pods
+// of the bundle's Deployments in namespace.
+func NewPodMetricsSampler(bundle *apimanifests.Bundle, c client.Client, namespace string) (UsageSampler, error) {
+	selectors, err := operatorSelectors(bundle)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) (Usage, error) {
+		total := Usage{}
+		for _, sel := range selectors {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(podMetricsListGVK)
+			opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: sel}}
+			if err := c.List(ctx, list, opts...); err != nil {
+				return Usage{}, fmt.Errorf("error listing pod metrics: %v", err)
+			}
+			u, err := sumPodMetrics(list)
+			if err != nil {
+				return Usage{}, err
+			}
+			total.CPU.Add(u.CPU)
+			total.Memory.Add(u.Memory)
+		}
+		return total, nil
+	}, nil
+}
+
+// operatorSelectors returns the pod selectors of the bundle's Deployments.
+func operatorSelectors(bundle *apimanifests.Bundle) ([]labels.Selector, error) {
+	deps := bundle.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+	if len(deps) == 0 {
+		return nil, fmt.Errorf("ClusterServiceVersion %s has no deployments", bundle.CSV.GetName())
+	}
+	selectors := make([]labels.Selector, 0, len(deps))
+	for _, dep := range deps {
+		sel, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of deployment %s: %v", dep.Name, err)
+		}
+		selectors = append(selectors, sel)
+	}
+	return selectors, nil
+}
+
+// sumPodMetrics sums the container usage of every PodMetrics in list.
+func sumPodMetrics(list *unstructured.UnstructuredList) (Usage, error) {
+	total := Usage{}
+	for _, item := range list.Items {
+		containers, _, err := unstructured.NestedSlice(item.Object, "containers")
+		if err != nil {
+			return Usage{}, fmt.Errorf("invalid metrics of pod %s: %v", item.GetName(), err)
+		}
+		for _, container := range containers {
+			m, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			usage, _, err := unstructured.NestedStringMap(m, "usage")
+			if err != nil {
+				return Usage{}, fmt.Errorf("invalid metrics of pod %s: %v", item.GetName(), err)
+			}
+			for name, dst := range map[string]*resource.Quantity{"cpu": &total.CPU, "memory": &total.Memory} {
+				if v, ok := usage[name]; ok {
+					q, err := resource.ParseQuantity(v)
+					if err != nil {
+						return Usage{}, fmt.Errorf("invalid %s usage of pod %s: %v", name, item.GetName(), err)
+					}
+					dst.Add(q)
+				}
+			}
+		}
+	}
+	return total, nil
+}
+
+// ResourcesTest checks that the bundle's operator containers set CPU and memory limits, then creates and
+// deletes the bundle's example CRs in namespace opts.Cycles times while sampling the operator's usage.
+// The test fails if operator memory grows after every cycle by more than maxMemoryGrowth in total,
+// if a deleted CR is not removed, or if objects created while the CRs existed remain after they are deleted.
+func ResourcesTest(ctx context.Context, bundle *apimanifests.Bundle, c client.Client, namespace string,
+	sample UsageSampler, opts ResourcesTestOptions) scapiv1alpha3.TestStatus {
+
+	r := scapiv1alpha3.TestResult{
+		Name:        BasicCheckResourcesTest,
+		State:       scapiv1alpha3.PassState,
+		Errors:      make([]string, 0),
+		Suggestions: make([]string, 0),
+	}
+	fail := func(format string, args ...interface{}) scapiv1alpha3.TestStatus {
+		r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+		r.State = scapiv1alpha3.FailState
+		return wrapResult(r)
+	}
+	if opts.Cycles <= 0 {
+		opts.Cycles = DefaultReconcileCycles
+	}
+	if opts.SettleTime <= 0 {
+		opts.SettleTime = resourcesSettleTime
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = resourcesPollTimeout
+	}
+
+	if missing := missingLimits(bundle); len(missing) != 0 {
+		r.Errors = append(r.Errors, fmt.Sprintf("containers without cpu and memory limits: %s", strings.Join(missing, ", ")))
+		r.Suggestions = append(r.Suggestions, "Set resources.limits.cpu and resources.limits.memory on every "+
+			"container of the operator's deployments")
+		r.State = scapiv1alpha3.FailState
+	}
+
+	crs, err := GetCRs(bundle)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if len(crs) == 0 {
+		r.Suggestions = append(r.Suggestions, "Add example CRs to the alm-examples annotation so they can be reconciled")
+		return wrapResult(r)
+	}
+	for i := range crs {
+		if crs[i].GetNamespace() == "" {
+			crs[i].SetNamespace(namespace)
+		}
+	}
+
+	before, err := listNamespacedObjects(ctx, c, namespace)
+	if err != nil {
+		return fail("%v", err)
+	}
+	usages := make([]Usage, 0, opts.Cycles+1)
+	if usages, err = appendUsage(ctx, sample, usages); err != nil {
+		return fail("%v", err)
+	}
+	r.Log += fmt.Sprintf("Initial usage: %s\n", usages[0])
+
+	for cycle := 1; cycle <= opts.Cycles; cycle++ {
+		for i := range crs {
+			cr := crs[i].DeepCopy()
+			if err := c.Create(ctx, cr); err != nil {
+				return fail("error creating %s %s: %v", cr.GetKind(), cr.GetName(), err)
+			}
+		}
+		time.Sleep(opts.SettleTime)
+		if usages, err = appendUsage(ctx, sample, usages); err != nil {
+			return fail("%v", err)
+		}
+		r.Log += fmt.Sprintf("Cycle %d usage with CRs: %s\n", cycle, usages[len(usages)-1])
+
+		if err := deleteCRs(ctx, c, crs, opts.Timeout); err != nil {
+			r.Suggestions = append(r.Suggestions, "Ensure the operator removes its finalizers from deleted CRs")
+			return fail("%v", err)
+		}
+		leaked, err := waitForLeaks(ctx, c, namespace, before, opts.Timeout)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if len(leaked) != 0 {
+			r.Suggestions = append(r.Suggestions, "Set an owner reference to the CR on every object created for it, "+
+				"or delete the objects when finalizing the CR")
+			return fail("objects not removed after deleting CRs: %s", strings.Join(leaked, ", "))
+		}
+		time.Sleep(opts.SettleTime)
+	}
+
+	if last := usages[len(usages)-1]; unboundedGrowth(usages) {
+		r.Errors = append(r.Errors, fmt.Sprintf("operator memory grew in every reconcile cycle, from %s to %s",
+			usages[0].Memory.String(), last.Memory.String()))
+		r.Suggestions = append(r.Suggestions, "Check the operator for memory leaks, ex. caches or goroutines "+
+			"that are not released when CRs are deleted")
+		r.State = scapiv1alpha3.FailState
+	}
+	return wrapResult(r)
+}
+
+// missingLimits returns the containers of the bundle's deployments without CPU or memory limits,
+// formatted as "deployment/container".
+func missingLimits(bundle *apimanifests.Bundle) (missing []string) {
+	for _, dep := range bundle.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		for _, container := range dep.Spec.Template.Spec.Containers {
+			limits := container.Resources.Limits
+			if _, ok := limits[corev1.ResourceCPU]; !ok {
+				missing = append(missing, dep.Name+"/"+container.Name)
+			} else if _, ok := limits[corev1.ResourceMemory]; !ok {
+				missing = append(missing, dep.Name+"/"+container.Name)
+			}
+		}
+	}
+	return missing
+}
+
+// unboundedGrowth returns true if the memory sampled in each reconcile cycle grew after every cycle,
+// and by more than maxMemoryGrowth since the first cycle. The initial sample, taken before any CR
+// was created, is not compared, since caches are expected to grow when the operator first reconciles a kind.
+func unboundedGrowth(usages []Usage) bool {
+	if len(usages) < 3 {
+		return false
+	}
+	first := float64(usages[1].Memory.Value())
+	for i := 2; i < len(usages); i++ {
+		if usages[i].Memory.Cmp(usages[i-1].Memory) <= 0 {
+			return false
+		}
+	}
+	return float64(usages[len(usages)-1].Memory.Value()) > first*(1+maxMemoryGrowth)
+}
+
+func appendUsage(ctx context.Context, sample UsageSampler, usages []Usage) ([]Usage, error) {
+	u, err := sample(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error sampling operator usage: %v", err)
+	}
+	return append(usages, u), nil
+}
+
+// deleteCRs deletes crs and waits until they are removed.
+func deleteCRs(ctx context.Context, c client.Client, crs []unstructured.Unstructured, timeout time.Duration) error {
+	for i := range crs {
+		cr := crs[i].DeepCopy()
+		if err := c.Delete(ctx, cr); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting %s %s: %v", cr.GetKind(), cr.GetName(), err)
+		}
+	}
+	var remaining []string
+	err := wait.PollImmediate(resourcesPollInterval, timeout, func() (bool, error) {
+		remaining = nil
+		for i := range crs {
+			cr := crs[i].DeepCopy()
+			key := types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}
+			if err := c.Get(ctx, key, cr); err == nil {
+				remaining = append(remaining, cr.GetKind()+" "+cr.GetName())
+			} else if !apierrors.IsNotFound(err) {
+				return false, err
+			}
+		}
+		return len(remaining) == 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("deleted CRs were not removed: %s", strings.Join(remaining, ", "))
+	}
+	return err
+}
+
+// waitForLeaks waits for the objects in namespace to be those in before, and returns those that remain
+// after timeout. Objects in before that were deleted are not leaks.
+func waitForLeaks(ctx context.Context, c client.Client, namespace string, before map[string]struct{},
+	timeout time.Duration) (leaked []string, err error) {
+
+	err = wait.PollImmediate(resourcesPollInterval, timeout, func() (bool, error) {
+		after, err := listNamespacedObjects(ctx, c, namespace)
+		if err != nil {
+			return false, err
+		}
+		leaked = leaked[:0]
+		for obj := range after {
+			if _, ok := before[obj]; !ok {
+				leaked = append(leaked, obj)
+			}
+		}
+		return len(leaked) == 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		sort.Strings(leaked)
+		return leaked, nil
+	}
+	return nil, err
+}
+
+// listNamespacedObjects returns the objects of leakCheckedKinds in namespace, formatted as "Kind/name".
+// Objects being deleted are not included.
+func listNamespacedObjects(ctx context.Context, c client.Client, namespace string) (map[string]struct{}, error) {
+	objs := map[string]struct{}{}
+	for _, gvk := range leakCheckedKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk)
+		if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("error listing %s: %v", gvk.Kind, err)
+		}
+		for _, item := range list.Items {
+			if item.GetDeletionTimestamp() == nil {
+				objs[strings.TrimSuffix(gvk.Kind, "List")+"/"+item.GetName()] = struct{}{}
+			}
+		}
+	}
+	return objs, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Resources test", func() {
+	var bundle *apimanifests.Bundle
+
+	BeforeEach(func() {
+		csv := &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		dep := operatorsv1alpha1.StrategyDeploymentSpec{Name: "memcached-operator-controller-manager"}
+		dep.Spec.Template.Spec.Containers = []corev1.Container{
+			{
+				Name: "manager",
+				Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("30Mi"),
+				}},
+			},
+			{
+				Name: "kube-rbac-proxy",
+				Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("100m"),
+				}},
+			},
+		}
+		csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []operatorsv1alpha1.StrategyDeploymentSpec{dep}
+		bundle = &apimanifests.Bundle{CSV: csv}
+	})
+
+	It("lists containers without cpu or memory limits", func() {
+		Expect(missingLimits(bundle)).To(Equal([]string{"memcached-operator-controller-manager/kube-rbac-proxy"}))
+	})
+	It("fails on missing limits without sampling usage when there are no example CRs", func() {
+		sample := func(context.Context) (Usage, error) {
+			Fail("usage should not be sampled")
+			return Usage{}, nil
+		}
+		status := ResourcesTest(context.TODO(), bundle, fake.NewFakeClient(), "default", sample, ResourcesTestOptions{})
+		Expect(status.Results[0].State).To(Equal(scapiv1alpha3.FailState))
+		Expect(status.Results[0].Errors).To(ConsistOf(ContainSubstring("kube-rbac-proxy")))
+	})
+
+	Describe("unboundedGrowth", func() {
+		usages := func(memory ...string) (us []Usage) {
+			for _, m := range memory {
+				us = append(us, Usage{Memory: resource.MustParse(m)})
+			}
+			return us
+		}
+		It("ignores growth in the first cycle", func() {
+			Expect(unboundedGrowth(usages("10Mi", "40Mi", "40Mi", "41Mi"))).To(BeFalse())
+		})
+		It("ignores bounded growth", func() {
+			Expect(unboundedGrowth(usages("10Mi", "20Mi", "21Mi", "22Mi"))).To(BeFalse())
+		})
+		It("detects memory growing in every cycle", func() {
+			Expect(unboundedGrowth(usages("10Mi", "20Mi", "30Mi", "40Mi"))).To(BeTrue())
+		})
+	})
+
+	It("sums the usage of every container", func() {
+		list := &unstructured.UnstructuredList{}
+		for _, name := range []string{"a", "b"} {
+			item := unstructured.Unstructured{Object: map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "manager", "usage": map[string]interface{}{"cpu": "5m", "memory": "20Mi"}},
+					map[string]interface{}{"name": "kube-rbac-proxy", "usage": map[string]interface{}{"cpu": "1m", "memory": "10Mi"}},
+				},
+			}}
+			item.SetName(name)
+			list.Items = append(list.Items, item)
+		}
+		u, err := sumPodMetrics(list)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.CPU.Cmp(resource.MustParse("12m"))).To(Equal(0))
+		Expect(u.Memory.Cmp(resource.MustParse("60Mi"))).To(Equal(0))
+	})
+
+	It("returns objects created after the snapshot that are not removed", func() {
+		existing := &corev1.ConfigMap{}
+		existing.SetNamespace("default")
+		existing.SetName("existing")
+		c := fake.NewFakeClient(existing)
+		before, err := listNamespacedObjects(context.TODO(), c, "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(before).To(HaveKey("ConfigMap/existing"))
+
+		leftover := &corev1.Service{}
+		leftover.SetNamespace("default")
+		leftover.SetName("memcached-sample")
+		Expect(c.Create(context.TODO(), leftover)).To(Succeed())
+		leaked, err := waitForLeaks(context.TODO(), c, "default", before, time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(leaked).To(Equal([]string{"Service/memcached-sample"}))
+	})
+})
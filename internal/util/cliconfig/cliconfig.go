@@ -0,0 +1,219 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cliconfig loads the defaults of operator-sdk flags from config files, so Makefiles and docs
+// need not repeat long flag strings.
+package cliconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/imagebuild"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+// ProjectFile is the name of the project config file, read from the working directory.
+const ProjectFile = ".operator-sdk.yaml"
+
+// Help documents the config files, for the long help of the root command.
+const Help = `Defaults of the --namespace, --timeout, --image-builder, and --security-context-config flags,
+and the registry of unqualified --tag images, are read from ` + ProjectFile + ` in the working directory,
+then from $XDG_CONFIG_HOME/operator-sdk/config.yaml (default ~/.config/operator-sdk/config.yaml):
+
+  namespace: memcached-system
+  timeout: 5m
+  imageBuilder: podman
+  securityContextConfig: restricted
+  registry: quay.io/example
+
+Flags, and the $` + operator.NamespaceEnv + ` and $` + imagebuild.ImageBuilderEnv + ` environment variables,
+take precedence.`
+
+// Config is the contents of a config file. Empty fields have no default.
+type Config struct {
+	// Namespace is the default of --namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// Timeout is the default of --timeout, ex. 5m.
+	Timeout string `json:"timeout,omitempty"`
+	// ImageBuilder is the default of --image-builder.
+	ImageBuilder string `json:"imageBuilder,omitempty"`
+	// SecurityContextConfig is the default of --security-context-config.
+	SecurityContextConfig string `json:"securityContextConfig,omitempty"`
+	// Registry prefixes --tag images without a registry or repository, ex. quay.io/example.
+	Registry string `json:"registry,omitempty"`
+}
+
+// Load reads the config file at path. A missing file is an empty config. Unknown keys are warned about
+// rather than rejected, so config files written for newer operator-sdk versions can still be read.
+func Load(path string) (Config, error) {
+	c := Config{}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, err
+	}
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("error parsing config file %s: %v", path, err)
+	}
+	keys, err := unknownKeys(b)
+	if err != nil {
+		return c, fmt.Errorf("error parsing config file %s: %v", path, err)
+	}
+	if len(keys) != 0 {
+		log.Warnf("Ignoring unknown keys in config file %s: %s", path, strings.Join(keys, ", "))
+	}
+	return c, nil
+}
+
+// unknownKeys returns the sorted top-level keys of the config file b that are not fields of Config.
+func unknownKeys(b []byte) ([]string, error) {
+	fields := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		known[strings.Split(t.Field(i).Tag.Get("json"), ",")[0]] = true
+	}
+	var keys []string
+	for k := range fields {
+		if !known[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// UserFile returns the path of the user config file.
+func UserFile() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "operator-sdk", "config.yaml"), nil
+}
+
+// LoadDefault reads the project config file, merged over the user config file.
+func LoadDefault() (Config, error) {
+	c, err := Load(ProjectFile)
+	if err != nil {
+		return c, err
+	}
+	path, err := UserFile()
+	if err != nil {
+		// Without a home directory there is no user config.
+		return c, nil
+	}
+	user, err := Load(path)
+	if err != nil {
+		return c, err
+	}
+	return c.Merge(user), nil
+}
+
+// Merge returns c with its empty fields set from base.
+func (c Config) Merge(base Config) Config {
+	merge := func(v *string, b string) {
+		if *v == "" {
+			*v = b
+		}
+	}
+	merge(&c.Namespace, base.Namespace)
+	merge(&c.Timeout, base.Timeout)
+	merge(&c.ImageBuilder, base.ImageBuilder)
+	merge(&c.SecurityContextConfig, base.SecurityContextConfig)
+	merge(&c.Registry, base.Registry)
+	return c
+}
+
+// flagNames are the flags whose defaults or values config files set.
+var flagNames = []string{"namespace", "timeout", "image-builder", "security-context-config", "tag"}
+
+// Uses returns true if cmd has a flag set from config files, so config files are not read for other commands.
+func Uses(cmd *cobra.Command) bool {
+	for _, name := range flagNames {
+		if cmd.Flags().Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply sets the flags of cmd not set on the command line from c.
+func (c Config) Apply(cmd *cobra.Command) error {
+	fs := cmd.Flags()
+	defaults := []struct {
+		flag, value, env string
+	}{
+		{"namespace", c.Namespace, operator.NamespaceEnv},
+		{"timeout", c.Timeout, ""},
+		{"image-builder", c.ImageBuilder, imagebuild.ImageBuilderEnv},
+		{"security-context-config", c.SecurityContextConfig, ""},
+	}
+	for _, d := range defaults {
+		if d.value == "" || (d.env != "" && os.Getenv(d.env) != "") {
+			continue
+		}
+		if err := setDefault(fs, d.flag, d.value); err != nil {
+			return err
+		}
+	}
+	if c.Registry != "" {
+		if f := fs.Lookup("tag"); f != nil && f.Changed && isUnqualified(f.Value.String()) {
+			image := strings.TrimSuffix(c.Registry, "/") + "/" + f.Value.String()
+			if err := f.Value.Set(image); err != nil {
+				return fmt.Errorf("invalid registry %q: %v", c.Registry, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setDefault sets the default of the flag name of fs to value, if fs has it and it is unset. The flag is
+// not marked as changed, so commands can tell config defaults from flags set on the command line.
+func setDefault(fs *pflag.FlagSet, name, value string) error {
+	f := fs.Lookup(name)
+	if f == nil || f.Changed {
+		return nil
+	}
+	if err := f.Value.Set(value); err != nil {
+		return fmt.Errorf("invalid default of --%s: %v", name, err)
+	}
+	f.DefValue = f.Value.String()
+	return nil
+}
+
+// isUnqualified returns true if image has neither a registry nor a repository, ex. memcached-bundle:v0.0.1.
+func isUnqualified(image string) bool {
+	return image != "" && !strings.Contains(image, "/")
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cliconfig
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/imagebuild"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("Config", func() {
+	Describe("Load", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "cliconfig")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("returns an empty config if the file is missing", func() {
+			c, err := Load(filepath.Join(dir, "missing.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).To(Equal(Config{}))
+		})
+		It("reads the file", func() {
+			path := filepath.Join(dir, ProjectFile)
+			Expect(ioutil.WriteFile(path, []byte("namespace: foo\ntimeout: 5m\nregistry: quay.io/example\n"),
+				0644)).To(Succeed())
+			c, err := Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).To(Equal(Config{Namespace: "foo", Timeout: "5m", Registry: "quay.io/example"}))
+		})
+		It("ignores unknown keys", func() {
+			path := filepath.Join(dir, ProjectFile)
+			Expect(ioutil.WriteFile(path, []byte("namespaces: foo\ntimeout: 5m\n"), 0644)).To(Succeed())
+			c, err := Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).To(Equal(Config{Timeout: "5m"}))
+		})
+		It("fails on invalid values", func() {
+			path := filepath.Join(dir, ProjectFile)
+			Expect(ioutil.WriteFile(path, []byte("namespace: [foo]\n"), 0644)).To(Succeed())
+			_, err := Load(path)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("unknownKeys", func() {
+		It("returns the keys that are not fields of Config", func() {
+			keys, err := unknownKeys([]byte("registry: quay.io/example\nnamespaces: foo\nbuilder: podman\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(Equal([]string{"builder", "namespaces"}))
+		})
+	})
+
+	Describe("Uses", func() {
+		It("returns true for commands with a flag set from config files", func() {
+			cmd := &cobra.Command{Use: "run"}
+			cmd.Flags().String("namespace", "", "")
+			Expect(Uses(cmd)).To(BeTrue())
+		})
+		It("returns false for other commands", func() {
+			cmd := &cobra.Command{Use: "version"}
+			cmd.Flags().Bool("verbose", false, "")
+			Expect(Uses(cmd)).To(BeFalse())
+		})
+	})
+
+	Describe("Merge", func() {
+		It("keeps the set fields of c", func() {
+			c := Config{Namespace: "project", Timeout: "1m"}
+			base := Config{Namespace: "user", ImageBuilder: "podman"}
+			Expect(c.Merge(base)).To(Equal(Config{Namespace: "project", Timeout: "1m", ImageBuilder: "podman"}))
+		})
+	})
+
+	Describe("Apply", func() {
+		var (
+			cmd     *cobra.Command
+			ns, tag string
+			timeout time.Duration
+			flags   imagebuild.Flags
+		)
+
+		BeforeEach(func() {
+			ns, tag, timeout, flags = "", "", 0, imagebuild.Flags{}
+			cmd = &cobra.Command{Use: "test"}
+			cmd.Flags().StringVarP(&ns, "namespace", "n", "", "")
+			cmd.Flags().StringVarP(&tag, "tag", "t", "", "")
+			cmd.Flags().DurationVar(&timeout, "timeout", time.Minute, "")
+			flags.BindFlags(cmd.Flags())
+		})
+
+		It("sets unset flags", func() {
+			Expect(cmd.ParseFlags([]string{"--namespace", "flag"})).To(Succeed())
+			Expect(Config{Namespace: "config", Timeout: "5m"}.Apply(cmd)).To(Succeed())
+			Expect(ns).To(Equal("flag"))
+			Expect(timeout).To(Equal(5 * time.Minute))
+		})
+		It("does not mark defaulted flags as set", func() {
+			Expect(Config{Timeout: "5m"}.Apply(cmd)).To(Succeed())
+			f := cmd.Flags().Lookup("timeout")
+			Expect(f.Changed).To(BeFalse())
+			Expect(f.DefValue).To(Equal("5m0s"))
+		})
+		It("allows a namespace default with --ephemeral-namespace", func() {
+			cfg := &operator.Configuration{}
+			e := operator.EphemeralNamespace{}
+			cmd = &cobra.Command{Use: "test"}
+			cfg.BindFlags(cmd.Flags())
+			e.BindFlags(cmd.Flags())
+			Expect(cmd.ParseFlags([]string{"--ephemeral-namespace"})).To(Succeed())
+			Expect(Config{Namespace: "config"}.Apply(cmd)).To(Succeed())
+			Expect(cfg.NamespaceFlag()).To(Equal("config"))
+			Expect(cfg.NamespaceFlagChanged()).To(BeFalse())
+			Expect(e.Enabled()).To(BeTrue())
+
+			// Reloading the configuration fails without a kubeconfig, after the namespace is created.
+			cfg.Client = fake.NewFakeClient()
+			err := e.Create(context.TODO(), cfg)
+			Expect(err).NotTo(MatchError(ContainSubstring("cannot be set with --namespace")))
+			namespaces := corev1.NamespaceList{}
+			Expect(cfg.Client.List(context.TODO(), &namespaces,
+				client.HasLabels{operator.EphemeralNamespaceLabel})).To(Succeed())
+			Expect(namespaces.Items).To(HaveLen(1))
+		})
+		It("ignores flags the command does not have", func() {
+			Expect(Config{SecurityContextConfig: "restricted"}.Apply(cmd)).To(Succeed())
+		})
+		It("fails on invalid values", func() {
+			Expect(Config{Timeout: "soon"}.Apply(cmd)).To(MatchError(ContainSubstring("invalid default of --timeout")))
+		})
+		It("prefers the image builder environment variable", func() {
+			Expect(os.Setenv(imagebuild.ImageBuilderEnv, "docker")).To(Succeed())
+			defer os.Unsetenv(imagebuild.ImageBuilderEnv)
+			Expect(Config{ImageBuilder: "podman"}.Apply(cmd)).To(Succeed())
+			Expect(flags.Tool).To(BeEmpty())
+		})
+		It("prefixes unqualified tags with the registry", func() {
+			Expect(cmd.ParseFlags([]string{"-t", "memcached-bundle:v0.0.1"})).To(Succeed())
+			Expect(Config{Registry: "quay.io/example/"}.Apply(cmd)).To(Succeed())
+			Expect(tag).To(Equal("quay.io/example/memcached-bundle:v0.0.1"))
+		})
+		It("does not prefix qualified tags", func() {
+			Expect(cmd.ParseFlags([]string{"-t", "example/memcached-bundle:v0.0.1"})).To(Succeed())
+			Expect(Config{Registry: "quay.io/example"}.Apply(cmd)).To(Succeed())
+			Expect(tag).To(Equal("example/memcached-bundle:v0.0.1"))
+		})
+	})
+})
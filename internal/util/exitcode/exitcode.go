@@ -0,0 +1,147 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exitcode maps the errors of operator-sdk commands to stable exit codes, so scripts can react
+// to a failure's category without parsing stderr.
+package exitcode
+
+import (
+	"context"
+	"errors"
+
+	"github.com/operator-framework/api/pkg/operators"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+)
+
+// Exit codes of failed commands. These are stable: new categories get new codes.
+const (
+	// Failure is the exit code of failures of no other category, and of scorecard runs with failing tests.
+	Failure = 1
+	// Validation is the exit code of invalid flags, arguments, or inputs.
+	Validation = 2
+	// OLMNotInstalled is the exit code of commands that need OLM run against a cluster without it.
+	OLMNotInstalled = 3
+	// Timeout is the exit code of commands that timed out.
+	Timeout = 4
+	// Forbidden is the exit code of commands denied access to a resource by RBAC.
+	Forbidden = 5
+	// CatalogFailed is the exit code of installs whose catalog could not be built or served.
+	CatalogFailed = 6
+	// CSVFailed is the exit code of installs whose CSV reached the Failed phase.
+	CSVFailed = 7
+	// UpgradeBlocked is the exit code of upgrades blocked by the installed operator's OperatorCondition.
+	UpgradeBlocked = 8
+)
+
+// Help documents the exit codes, for the long help of commands that use them.
+const Help = `Exit codes:
+  1  failure of no other category
+  2  invalid flags, arguments, or inputs
+  3  OLM is not installed
+  4  timed out
+  5  access denied by RBAC
+  6  the catalog could not be built or served
+  7  the CSV failed to install
+  8  the upgrade was blocked by the installed Operator`
+
+// invalidError marks an error as a validation error.
+type invalidError struct {
+	err error
+}
+
+func (e invalidError) Error() string {
+	return e.err.Error()
+}
+
+func (e invalidError) Unwrap() error {
+	return e.err
+}
+
+// Invalid marks err as a validation error, returning nil if err is nil.
+func Invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return invalidError{err}
+}
+
+// Args marks the errors of args as validation errors.
+func Args(args cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, a []string) error {
+		return Invalid(args(cmd, a))
+	}
+}
+
+// For returns the exit code of err's category, 0 if err is nil.
+func For(err error) int {
+	if err == nil {
+		return 0
+	}
+	var (
+		invalid   invalidError
+		csvFailed olmclient.ErrCSVFailed
+		status    apierrors.APIStatus
+	)
+	switch {
+	case errors.As(err, &invalid):
+		return Validation
+	case errors.As(err, &csvFailed):
+		return CSVFailed
+	case isOLMNotInstalled(err):
+		return OLMNotInstalled
+	case errors.As(err, &status) && status.Status().Reason == metav1.StatusReasonForbidden:
+		return Forbidden
+	case errors.Is(err, registry.ErrCatalogFailed):
+		return CatalogFailed
+	case errors.Is(err, registry.ErrUpgradeBlocked):
+		return UpgradeBlocked
+	case errors.Is(err, registry.ErrInstallPlanTimeout), errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, wait.ErrWaitTimeout):
+		return Timeout
+	default:
+		return Failure
+	}
+}
+
+// isOLMNotInstalled returns whether err is caused by OLM's APIs being missing from the cluster.
+func isOLMNotInstalled(err error) bool {
+	if errors.Is(err, olmclient.ErrOLMNotInstalled) {
+		return true
+	}
+	var (
+		noKind     *meta.NoKindMatchError
+		noResource *meta.NoResourceMatchError
+	)
+	if errors.As(err, &noKind) {
+		return noKind.GroupKind.Group == operators.GroupName
+	}
+	if errors.As(err, &noResource) {
+		return noResource.PartialResource.Group == operators.GroupName
+	}
+	return false
+}
+
+// Fatal logs msg and err, then exits with err's exit code once logrus' exit handlers have run.
+func Fatal(msg string, err error) {
+	log.Errorf("%s: %v", msg, err)
+	log.Exit(For(err))
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+)
+
+var _ = Describe("For", func() {
+	wrap := func(err error) error {
+		return fmt.Errorf("failed to run bundle: %w", err)
+	}
+
+	It("should return 0 for no error", func() {
+		Expect(For(nil)).To(Equal(0))
+	})
+	It("should return Failure for uncategorized errors", func() {
+		Expect(For(errors.New("something broke"))).To(Equal(Failure))
+	})
+	It("should return Validation for invalid inputs", func() {
+		Expect(For(wrap(Invalid(errors.New("--interactive requires a terminal"))))).To(Equal(Validation))
+		Expect(Invalid(nil)).To(BeNil())
+	})
+	It("should return Validation for invalid arguments", func() {
+		err := Args(cobra.ExactArgs(1))(&cobra.Command{}, nil)
+		Expect(For(err)).To(Equal(Validation))
+		Expect(Args(cobra.ExactArgs(1))(&cobra.Command{}, []string{"a"})).To(Succeed())
+	})
+	It("should return OLMNotInstalled if OLM is missing", func() {
+		Expect(For(wrap(olmclient.ErrOLMNotInstalled))).To(Equal(OLMNotInstalled))
+		noKind := &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "operators.coreos.com", Kind: "CatalogSource"}}
+		Expect(For(wrap(noKind))).To(Equal(OLMNotInstalled))
+		noKind.GroupKind.Group = "example.com"
+		Expect(For(wrap(noKind))).To(Equal(Failure))
+	})
+	It("should return Forbidden for RBAC denials", func() {
+		err := apierrors.NewForbidden(schema.GroupResource{Resource: "subscriptions"}, "sub", errors.New("denied"))
+		Expect(For(wrap(err))).To(Equal(Forbidden))
+	})
+	It("should return Timeout for timeouts", func() {
+		Expect(For(wrap(context.DeadlineExceeded))).To(Equal(Timeout))
+		Expect(For(wrap(wait.ErrWaitTimeout))).To(Equal(Timeout))
+		Expect(For(wrap(registry.ErrInstallPlanTimeout))).To(Equal(Timeout))
+	})
+	It("should return CatalogFailed for catalog failures", func() {
+		Expect(For(wrap(registry.ErrCatalogFailed))).To(Equal(CatalogFailed))
+	})
+	It("should return UpgradeBlocked for blocked upgrades", func() {
+		Expect(For(wrap(registry.ErrUpgradeBlocked))).To(Equal(UpgradeBlocked))
+	})
+	It("should return CSVFailed for failed CSVs", func() {
+		err := olmclient.ErrCSVFailed{Reason: v1alpha1.CSVReasonComponentFailed}
+		Expect(For(wrap(err))).To(Equal(CSVFailed))
+	})
+})
@@ -0,0 +1,133 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health provides health and readiness checks for the Ansible and Helm operators
+// that reflect more than process liveness.
+package health
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Tracker tracks the in-flight reconciles of a controller to detect when it is wedged:
+// all of its workers are busy and none has finished for a while.
+type Tracker struct {
+	name     string
+	capacity int
+
+	mu       sync.Mutex
+	inFlight int
+	// progress is when a reconcile last finished or the workers last became saturated,
+	// whichever is later.
+	progress time.Time
+	now      func() time.Time
+}
+
+// NewTracker returns a Tracker named name for a controller running up to capacity
+// concurrent reconciles.
+func NewTracker(name string, capacity int) *Tracker {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Tracker{name: name, capacity: capacity, now: time.Now}
+}
+
+// Reconciler returns r, tracking each of its reconciles.
+func (t *Tracker) Reconciler(r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(req reconcile.Request) (reconcile.Result, error) {
+		t.begin()
+		defer t.done()
+		return r.Reconcile(req)
+	})
+}
+
+func (t *Tracker) begin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight++
+	if t.inFlight == t.capacity {
+		t.progress = t.now()
+	}
+}
+
+func (t *Tracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight--
+	t.progress = t.now()
+}
+
+// stalledFor returns how long all workers have been busy without any reconcile finishing,
+// or zero if a worker is free.
+func (t *Tracker) stalledFor() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.inFlight < t.capacity {
+		return 0
+	}
+	return t.now().Sub(t.progress)
+}
+
+// StallChecker returns a checker that fails when any tracker has had all of its workers busy
+// without any reconcile finishing for longer than timeout.
+func StallChecker(timeout time.Duration, trackers ...*Tracker) healthz.Checker {
+	return func(_ *http.Request) error {
+		var stalled []string
+		for _, t := range trackers {
+			if d := t.stalledFor(); d > timeout {
+				stalled = append(stalled, fmt.Sprintf("%s (%s)", t.name, d.Round(time.Second)))
+			}
+		}
+		if len(stalled) != 0 {
+			sort.Strings(stalled)
+			return fmt.Errorf("reconciles of %s have not progressed with all workers busy",
+				strings.Join(stalled, ", "))
+		}
+		return nil
+	}
+}
+
+// DialChecker returns a checker that fails when address does not accept TCP connections.
+func DialChecker(address string) healthz.Checker {
+	return func(_ *http.Request) error {
+		conn, err := net.DialTimeout("tcp", address, time.Second)
+		if err != nil {
+			return fmt.Errorf("error connecting to %s: %w", address, err)
+		}
+		return conn.Close()
+	}
+}
+
+// PathsChecker returns a checker that fails when any of paths does not exist, such as
+// the playbooks, roles, or charts an operator was loaded with.
+func PathsChecker(paths ...string) healthz.Checker {
+	return func(_ *http.Request) error {
+		for _, path := range paths {
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("error loading %s: %w", path, err)
+			}
+		}
+		return nil
+	}
+}
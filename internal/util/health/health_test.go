@@ -0,0 +1,103 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Tracker", func() {
+	var (
+		t   *Tracker
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		t = NewTracker("memcached", 2)
+		t.now = func() time.Time { return now }
+	})
+
+	It("is not stalled while a worker is free", func() {
+		t.begin()
+		now = now.Add(time.Hour)
+		Expect(t.stalledFor()).To(BeZero())
+		Expect(StallChecker(time.Minute, t)(nil)).To(Succeed())
+	})
+
+	It("is stalled while all workers are busy without progress", func() {
+		t.begin()
+		now = now.Add(time.Hour)
+		t.begin()
+		now = now.Add(2 * time.Minute)
+		Expect(t.stalledFor()).To(Equal(2 * time.Minute))
+		Expect(StallChecker(time.Minute, t)(nil)).To(MatchError(ContainSubstring("memcached (2m0s)")))
+		Expect(StallChecker(5*time.Minute, t)(nil)).To(Succeed())
+	})
+
+	It("progresses when a reconcile finishes", func() {
+		t.begin()
+		t.begin()
+		now = now.Add(time.Hour)
+		t.done()
+		t.begin()
+		now = now.Add(time.Second)
+		Expect(t.stalledFor()).To(Equal(time.Second))
+	})
+
+	It("tracks the reconciles of a reconciler", func() {
+		r := t.Reconciler(reconcile.Func(func(reconcile.Request) (reconcile.Result, error) {
+			Expect(t.inFlight).To(Equal(1))
+			return reconcile.Result{}, nil
+		}))
+		Expect(r.Reconcile(reconcile.Request{})).To(Equal(reconcile.Result{}))
+		Expect(t.inFlight).To(BeZero())
+	})
+})
+
+var _ = Describe("DialChecker", func() {
+	It("succeeds when the address accepts connections", func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer l.Close()
+		Expect(DialChecker(l.Addr().String())(nil)).To(Succeed())
+	})
+
+	It("fails when the address does not accept connections", func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := l.Addr().String()
+		Expect(l.Close()).To(Succeed())
+		Expect(DialChecker(addr)(nil)).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("PathsChecker", func() {
+	It("fails when a path does not exist", func() {
+		dir, err := ioutil.TempDir("", "health-")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Expect(PathsChecker(dir)(nil)).To(Succeed())
+		Expect(PathsChecker(dir, filepath.Join(dir, "missing"))(nil)).To(MatchError(ContainSubstring("missing")))
+	})
+})
@@ -0,0 +1,94 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logutil configures the format and level of operator-sdk logs, and provides structured
+// fields identifying the resources logs are about, so automation can parse them.
+package logutil
+
+import (
+	"fmt"
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Structured field keys.
+const (
+	KindKey      = "kind"
+	NamespaceKey = "namespace"
+	NameKey      = "name"
+	PhaseKey     = "phase"
+)
+
+// Configure sets the standard logger's format, one of FormatText or FormatJSON, and its level from
+// verbosity: 0 logs info and above, 1 adds debug logs, and 2 or more adds trace logs.
+func Configure(format string, verbosity int) error {
+	switch format {
+	case FormatText:
+		log.SetFormatter(&log.TextFormatter{})
+	case FormatJSON:
+		log.SetFormatter(&log.JSONFormatter{})
+	default:
+		return fmt.Errorf("log format must be one of [%q, %q]", FormatText, FormatJSON)
+	}
+	if verbosity < 0 {
+		return fmt.Errorf("log verbosity must not be negative")
+	}
+	switch verbosity {
+	case 0:
+		log.SetLevel(log.InfoLevel)
+	case 1:
+		log.SetLevel(log.DebugLevel)
+	default:
+		log.SetLevel(log.TraceLevel)
+	}
+	return nil
+}
+
+// ResourceFields returns fields identifying a resource.
+func ResourceFields(kind string, key types.NamespacedName) log.Fields {
+	fields := log.Fields{KindKey: kind, NameKey: key.Name}
+	if key.Namespace != "" {
+		fields[NamespaceKey] = key.Namespace
+	}
+	return fields
+}
+
+// WithResource returns a standard logger entry with fields identifying a resource.
+func WithResource(kind string, key types.NamespacedName) *log.Entry {
+	return log.WithFields(ResourceFields(kind, key))
+}
+
+// WithObject returns a standard logger entry with fields identifying obj. obj's kind is its
+// GroupVersionKind's kind if set, otherwise its Go type name.
+func WithObject(obj runtime.Object) *log.Entry {
+	key := types.NamespacedName{}
+	if accessor, err := meta.Accessor(obj); err == nil {
+		key = types.NamespacedName{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		kind = reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+	}
+	return WithResource(kind, key)
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Logutil", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		log.SetOutput(out)
+	})
+	AfterEach(func() {
+		log.SetOutput(os.Stderr)
+		Expect(Configure(FormatText, 0)).To(Succeed())
+	})
+
+	Describe("Configure", func() {
+		It("sets the level from verbosity", func() {
+			Expect(Configure(FormatText, 0)).To(Succeed())
+			Expect(log.GetLevel()).To(Equal(log.InfoLevel))
+			Expect(Configure(FormatText, 1)).To(Succeed())
+			Expect(log.GetLevel()).To(Equal(log.DebugLevel))
+			Expect(Configure(FormatText, 3)).To(Succeed())
+			Expect(log.GetLevel()).To(Equal(log.TraceLevel))
+		})
+		It("rejects unknown formats and negative verbosity", func() {
+			Expect(Configure("yaml", 0)).NotTo(Succeed())
+			Expect(Configure(FormatJSON, -1)).NotTo(Succeed())
+		})
+	})
+
+	Describe("WithObject", func() {
+		It("logs fields identifying an object as JSON", func() {
+			Expect(Configure(FormatJSON, 0)).To(Succeed())
+			sub := &v1alpha1.Subscription{}
+			sub.SetName("memcached-operator")
+			sub.SetNamespace("default")
+			WithObject(sub).WithField(PhaseKey, "AtLatestKnown").Info("Created Subscription")
+
+			entry := map[string]interface{}{}
+			Expect(json.Unmarshal(out.Bytes(), &entry)).To(Succeed())
+			Expect(entry).To(HaveKeyWithValue("msg", "Created Subscription"))
+			Expect(entry).To(HaveKeyWithValue(KindKey, "Subscription"))
+			Expect(entry).To(HaveKeyWithValue(NamespaceKey, "default"))
+			Expect(entry).To(HaveKeyWithValue(NameKey, "memcached-operator"))
+			Expect(entry).To(HaveKeyWithValue(PhaseKey, "AtLatestKnown"))
+		})
+	})
+})
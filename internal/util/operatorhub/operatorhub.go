@@ -0,0 +1,105 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operatorhub holds the CSV metadata values allowed by OperatorHub.io.
+package operatorhub
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// CSV annotations read by OperatorHub.io.
+const (
+	CategoriesAnnotation   = "categories"
+	CapabilitiesAnnotation = "capabilities"
+	SupportAnnotation      = "support"
+)
+
+// DefaultCategories are the categories OperatorHub.io lists operators under.
+var DefaultCategories = []string{
+	"AI/Machine Learning",
+	"Application Runtime",
+	"Big Data",
+	"Cloud Provider",
+	"Database",
+	"Developer Tools",
+	"Integration & Delivery",
+	"Logging & Tracing",
+	"Monitoring",
+	"Networking",
+	"OpenShift Optional",
+	"Security",
+	"Storage",
+	"Streaming & Messaging",
+}
+
+// Capabilities are the Operator Capability Levels, from lowest to highest.
+var Capabilities = []string{
+	"Basic Install",
+	"Seamless Upgrades",
+	"Full Lifecycle",
+	"Deep Insights",
+	"Auto Pilot",
+}
+
+// categoriesFile is the format of a file overriding DefaultCategories.
+type categoriesFile struct {
+	Categories []string `json:"categories"`
+}
+
+// ReadCategoriesFile returns the categories in the YAML or JSON file at path, which has the form
+// "categories: [<category>, ...]", for catalogs that allow other categories than OperatorHub.io.
+func ReadCategoriesFile(path string) ([]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := categoriesFile{}
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("error unmarshaling categories file %s: %v", path, err)
+	}
+	if len(f.Categories) == 0 {
+		return nil, fmt.Errorf("categories file %s has no categories", path)
+	}
+	return f.Categories, nil
+}
+
+// SplitCategories splits the value of a categories annotation.
+func SplitCategories(annotation string) (categories []string) {
+	for _, c := range strings.Split(annotation, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			categories = append(categories, c)
+		}
+	}
+	return categories
+}
+
+// JoinCategories returns the value of a categories annotation for categories.
+func JoinCategories(categories []string) string {
+	return strings.Join(categories, ",")
+}
+
+// Contains returns true if values contains v.
+func Contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
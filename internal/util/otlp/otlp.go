@@ -0,0 +1,105 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp sends metrics and traces to OpenTelemetry collectors with OTLP/HTTP, in its JSON encoding.
+// Only the subset of OTLP the SDK exports is implemented. See
+// https://github.com/open-telemetry/opentelemetry-proto for the protocol's definition.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Paths signals are posted to if an endpoint has no path.
+const (
+	MetricsPath = "/v1/metrics"
+	TracesPath  = "/v1/traces"
+)
+
+// Post posts req as JSON to endpoint, or to defaultPath of endpoint if it has no path.
+// If c is nil, http.DefaultClient is used.
+func Post(ctx context.Context, c *http.Client, endpoint, defaultPath string, req interface{}) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = defaultPath
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, b)
+	}
+	return nil
+}
+
+// UnixNano returns t as OTLP/JSON encodes timestamps: a string, since it encodes 64-bit integers as strings.
+func UnixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// Resource describes the entity producing signals.
+type Resource struct {
+	Attributes []Attribute `json:"attributes"`
+}
+
+// NewResource returns a Resource of the service serviceName.
+func NewResource(serviceName string) Resource {
+	return Resource{Attributes: []Attribute{NewAttribute("service.name", serviceName)}}
+}
+
+// Scope is the instrumentation scope signals are produced by.
+type Scope struct {
+	Name string `json:"name"`
+}
+
+// Attribute is a string-valued key-value pair.
+type Attribute struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+type AnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func NewAttribute(key, value string) Attribute {
+	return Attribute{Key: key, Value: AnyValue{StringValue: value}}
+}
@@ -0,0 +1,220 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing records spans of CLI operations and exports them to an OpenTelemetry collector with
+// OTLP/HTTP, so slow operations can be correlated with API server and registry latency.
+//
+// Spans are only recorded once Configure is called with an endpoint; otherwise Start returns spans
+// that do nothing. Spans are buffered and exported by Flush, which exports spans not yet ended
+// as ending at the time of the flush, so spans of commands exiting on errors are still exported.
+// At most maxSpans spans are buffered between flushes; spans started once the buffer is full do nothing.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/operator-framework/operator-sdk/internal/util/otlp"
+)
+
+const (
+	// ServiceName is the service name spans are exported with.
+	ServiceName = "operator-sdk"
+
+	// EndpointEnv and TracesEndpointEnv are the standard OpenTelemetry environment variables
+	// the collector endpoint is read from, if not configured otherwise.
+	EndpointEnv       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	TracesEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
+// maxSpans is the number of spans buffered between flushes, which bounds the memory of long runs
+// making many API requests.
+const maxSpans = 10000
+
+var (
+	mu       sync.Mutex
+	endpoint string
+	spans    []*Span
+	// dropped is the number of spans not buffered since the last flush, since the buffer was full.
+	dropped int
+	// client exports spans. It is not traced, so exports do not record spans.
+	client = &http.Client{Timeout: 10 * time.Second}
+)
+
+// Configure enables tracing, exporting spans to the OTLP/HTTP collector at ep. If ep is empty,
+// the endpoint is read from TracesEndpointEnv, then EndpointEnv, and tracing stays disabled if neither is set.
+func Configure(ep string) {
+	if ep == "" {
+		ep = os.Getenv(TracesEndpointEnv)
+	}
+	if ep == "" {
+		ep = os.Getenv(EndpointEnv)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	endpoint = ep
+}
+
+// Enabled returns true if spans are recorded.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return endpoint != ""
+}
+
+// Attribute is an attribute of a span.
+type Attribute = otlp.Attribute
+
+// String returns a string-valued Attribute.
+func String(key, value string) Attribute {
+	return otlp.NewAttribute(key, value)
+}
+
+// Span is a timed operation.
+type Span struct {
+	mu       sync.Mutex
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+	attrs    []Attribute
+	err      error
+}
+
+type spanKey struct{}
+
+// Start starts a span named name, a child of the span in ctx if any, returning a context containing it.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return start(ctx, name, otlp.SpanKindInternal, attrs)
+}
+
+func start(ctx context.Context, name string, kind int, attrs []Attribute) (context.Context, *Span) {
+	if !Enabled() {
+		return ctx, nil
+	}
+	s := &Span{name: name, kind: kind, spanID: newID(8), start: time.Now(), attrs: attrs}
+	if parent := FromContext(ctx); parent != nil {
+		s.traceID, s.parentID = parent.traceID, parent.spanID
+	} else {
+		s.traceID = newID(16)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(spans) >= maxSpans {
+		dropped++
+		return ctx, nil
+	}
+	spans = append(spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// FromContext returns the span in ctx, or nil if ctx has none.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// SetAttributes adds attrs to s. Methods of a nil Span, returned if tracing is disabled, do nothing.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// End ends s, with an error status if err is not nil. Only the first call has an effect.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.end.IsZero() {
+		s.end, s.err = time.Now(), err
+	}
+}
+
+// toOTLP returns s as an OTLP span, ending at now if it has not ended.
+func (s *Span) toOTLP(now time.Time) otlp.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	end := s.end
+	if end.IsZero() {
+		end = now
+	}
+	span := otlp.Span{
+		TraceID:           s.traceID,
+		SpanID:            s.spanID,
+		ParentSpanID:      s.parentID,
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: otlp.UnixNano(s.start),
+		EndTimeUnixNano:   otlp.UnixNano(end),
+		Attributes:        append([]Attribute(nil), s.attrs...),
+	}
+	if s.err != nil {
+		span.Status = otlp.Status{Code: otlp.StatusCodeError, Message: s.err.Error()}
+	} else if !s.end.IsZero() {
+		span.Status = otlp.Status{Code: otlp.StatusCodeOK}
+	}
+	return span
+}
+
+// Flush exports every recorded span, then forgets them. It returns an error if spans were dropped
+// since the last flush, once the buffered spans are exported.
+func Flush(ctx context.Context) error {
+	mu.Lock()
+	ep, flushed, n := endpoint, spans, dropped
+	spans, dropped = nil, 0
+	mu.Unlock()
+	if ep == "" || len(flushed) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	req := otlp.TracesRequest{ResourceSpans: []otlp.ResourceSpans{{
+		Resource:   otlp.NewResource(ServiceName),
+		ScopeSpans: []otlp.ScopeSpans{{Scope: otlp.Scope{Name: ServiceName}}},
+	}}}
+	scope := &req.ResourceSpans[0].ScopeSpans[0]
+	for _, s := range flushed {
+		scope.Spans = append(scope.Spans, s.toOTLP(now))
+	}
+	if err := otlp.Post(ctx, client, ep, otlp.TracesPath, req); err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("%d spans were dropped, since more than %d were recorded", n, maxSpans)
+	}
+	return nil
+}
+
+// newID returns a random hex-encoded ID of n bytes.
+func newID(n int) string {
+	b := make([]byte, n)
+	// crypto/rand does not fail on supported platforms.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+
+	"github.com/operator-framework/operator-sdk/internal/util/otlp"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		collector *httptest.Server
+		exported  []otlp.TracesRequest
+	)
+
+	BeforeEach(func() {
+		exported = nil
+		collector = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.URL.Path).To(Equal(otlp.TracesPath))
+			b, err := ioutil.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			req := otlp.TracesRequest{}
+			Expect(json.Unmarshal(b, &req)).To(Succeed())
+			exported = append(exported, req)
+		}))
+		Configure(collector.URL)
+	})
+	AfterEach(func() {
+		Configure("")
+		collector.Close()
+	})
+
+	exportedSpans := func() []otlp.Span {
+		Expect(exported).To(HaveLen(1))
+		return exported[0].ResourceSpans[0].ScopeSpans[0].Spans
+	}
+
+	It("does not record spans if disabled", func() {
+		Configure("")
+		ctx, span := Start(context.TODO(), "install operator")
+		Expect(span).To(BeNil())
+		Expect(FromContext(ctx)).To(BeNil())
+		span.SetAttributes(String("package", "memcached-operator"))
+		span.End(nil)
+		Expect(Flush(context.TODO())).To(Succeed())
+		Expect(exported).To(BeEmpty())
+	})
+
+	It("exports child spans in the trace of their parent", func() {
+		ctx, parent := Start(context.TODO(), "run bundle", String("bundle", "example.com/bundle:v0.0.1"))
+		_, child := Start(ctx, "install operator")
+		child.End(errors.New("timed out"))
+		parent.End(nil)
+		Expect(Flush(context.TODO())).To(Succeed())
+
+		spans := exportedSpans()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Name).To(Equal("run bundle"))
+		Expect(spans[0].ParentSpanID).To(BeEmpty())
+		Expect(spans[0].Attributes).To(ConsistOf(String("bundle", "example.com/bundle:v0.0.1")))
+		Expect(spans[0].Status.Code).To(Equal(otlp.StatusCodeOK))
+		Expect(spans[0].TraceID).To(HaveLen(32))
+		Expect(spans[1].TraceID).To(Equal(spans[0].TraceID))
+		Expect(spans[1].ParentSpanID).To(Equal(spans[0].SpanID))
+		Expect(spans[1].Status).To(Equal(otlp.Status{Code: otlp.StatusCodeError, Message: "timed out"}))
+		Expect(exported[0].ResourceSpans[0].Resource).To(Equal(otlp.NewResource(ServiceName)))
+	})
+
+	It("exports spans not yet ended with an unset status, once", func() {
+		_, span := Start(context.TODO(), "cleanup")
+		Expect(Flush(context.TODO())).To(Succeed())
+		spans := exportedSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status.Code).To(Equal(otlp.StatusCodeUnset))
+		Expect(spans[0].EndTimeUnixNano).NotTo(BeEmpty())
+
+		span.End(nil)
+		Expect(Flush(context.TODO())).To(Succeed())
+		Expect(exported).To(HaveLen(1))
+	})
+
+	It("drops spans once the buffer is full, and reports them", func() {
+		for i := 0; i < maxSpans; i++ {
+			Start(context.TODO(), "GET pods")
+		}
+		ctx, span := Start(context.TODO(), "GET pods")
+		Expect(span).To(BeNil())
+		Expect(FromContext(ctx)).To(BeNil())
+		Expect(Flush(context.TODO())).To(MatchError("1 spans were dropped, since more than 10000 were recorded"))
+		Expect(exportedSpans()).To(HaveLen(maxSpans))
+
+		_, span = Start(context.TODO(), "GET pods")
+		Expect(span).NotTo(BeNil())
+		span.End(nil)
+		Expect(Flush(context.TODO())).To(Succeed())
+	})
+
+	It("records a client span per API request", func() {
+		apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/v1/namespaces/default/pods/missing" {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer apiServer.Close()
+
+		cfg := &rest.Config{Host: apiServer.URL}
+		WrapConfig(cfg)
+		rt, err := rest.TransportFor(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		c := &http.Client{Transport: rt}
+		for _, p := range []string{"/api/v1/namespaces/default/pods/missing", "/apis/operators.coreos.com/v1alpha1/subscriptions"} {
+			resp, err := c.Get(apiServer.URL + p)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+		}
+		Expect(Flush(context.TODO())).To(Succeed())
+
+		spans := exportedSpans()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Name).To(Equal("GET pods"))
+		Expect(spans[0].Kind).To(Equal(otlp.SpanKindClient))
+		Expect(spans[0].Attributes).To(ContainElement(String("k8s.namespace", "default")))
+		Expect(spans[0].Attributes).To(ContainElement(String("http.status_code", "404")))
+		Expect(spans[0].Status.Code).To(Equal(otlp.StatusCodeOK))
+		Expect(spans[1].Name).To(Equal("GET operators.coreos.com/subscriptions"))
+		Expect(spans[1].Status.Code).To(Equal(otlp.StatusCodeError))
+	})
+})
+
+var _ = Describe("parseAPIPath", func() {
+	DescribeTable("returns the resource and namespace of a path",
+		func(p, resource, namespace string) {
+			r, ns := parseAPIPath(p)
+			Expect(r).To(Equal(resource))
+			Expect(ns).To(Equal(namespace))
+		},
+		Entry("core, cluster-scoped", "/api/v1/namespaces", "namespaces", ""),
+		Entry("core, named namespace", "/api/v1/namespaces/default", "namespaces", ""),
+		Entry("core, namespaced", "/api/v1/namespaces/default/pods/foo", "pods", "default"),
+		Entry("group, subresource", "/apis/apps/v1/namespaces/default/deployments/foo/status",
+			"apps/deployments/status", "default"),
+		Entry("group, list", "/apis/operators.coreos.com/v1alpha1/subscriptions",
+			"operators.coreos.com/subscriptions", ""),
+		Entry("non-resource", "/version", "/version", ""),
+	)
+})
@@ -0,0 +1,95 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+
+	"github.com/operator-framework/operator-sdk/internal/util/otlp"
+)
+
+// WrapConfig records a span for each request made by clients of cfg, if tracing is enabled.
+// Spans are named by the request's method and API resource, ex. "GET operators.coreos.com/subscriptions",
+// so requests to the same resource can be grouped.
+func WrapConfig(cfg *rest.Config) {
+	if !Enabled() {
+		return
+	}
+	cfg.WrapTransport = transport.Wrappers(cfg.WrapTransport, func(rt http.RoundTripper) http.RoundTripper {
+		return &roundTripper{rt: rt}
+	})
+}
+
+type roundTripper struct {
+	rt http.RoundTripper
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource, namespace := parseAPIPath(req.URL.Path)
+	attrs := []Attribute{
+		String("http.method", req.Method),
+		String("http.target", req.URL.Path),
+		String("k8s.resource", resource),
+	}
+	if namespace != "" {
+		attrs = append(attrs, String("k8s.namespace", namespace))
+	}
+	_, span := start(req.Context(), req.Method+" "+resource, otlp.SpanKindClient, attrs)
+	resp, err := t.rt.RoundTrip(req)
+	spanErr := err
+	if err == nil {
+		span.SetAttributes(String("http.status_code", strconv.Itoa(resp.StatusCode)))
+		// Only server errors are span errors, since clients expect some client errors, ex. NotFound.
+		if resp.StatusCode >= http.StatusInternalServerError {
+			spanErr = fmt.Errorf("server responded with %s", resp.Status)
+		}
+	}
+	span.End(spanErr)
+	return resp, err
+}
+
+// parseAPIPath returns the resource, qualified by its group if any, and the namespace of the API path p,
+// ex. "/apis/apps/v1/namespaces/default/deployments/foo/status" is the resource "apps/deployments/status"
+// in namespace "default". Paths that are not resource paths are returned as is.
+func parseAPIPath(p string) (resource, namespace string) {
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	var group string
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		group, parts = parts[1], parts[3:]
+	default:
+		return p, ""
+	}
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		namespace, parts = parts[1], parts[2:]
+	}
+	// parts are now <resource>[/<name>[/<subresource>]].
+	resource = parts[0]
+	if len(parts) >= 3 {
+		resource += "/" + parts[2]
+	}
+	if group != "" {
+		resource = group + "/" + resource
+	}
+	return resource, namespace
+}
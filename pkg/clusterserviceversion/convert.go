@@ -0,0 +1,384 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+const (
+	// defaultServiceAccountName exists in every namespace, so is never created.
+	defaultServiceAccountName = "default"
+	// defaultWebhookPort is the port OLM uses for webhooks that do not set containerPort.
+	defaultWebhookPort = 443
+	// webhookServiceSuffix is appended to a deployment name to make its webhook Service name.
+	// The CSV generator trims this suffix when matching Services to deployments.
+	webhookServiceSuffix = "-service"
+)
+
+// BundleToManifests converts bundle's CSV to plain manifests installable in namespace,
+// and adds all CustomResourceDefinitions in bundle.
+func BundleToManifests(bundle *apimanifests.Bundle, namespace string) (*Manifests, error) {
+	if bundle == nil || bundle.CSV == nil {
+		return nil, errors.New("bundle has no ClusterServiceVersion")
+	}
+	m, err := ToManifests(bundle.CSV, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, crd := range bundle.V1CRDs {
+		crd = crd.DeepCopy()
+		crd.SetGroupVersionKind(apiextv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+		m.V1CustomResourceDefinitions = append(m.V1CustomResourceDefinitions, *crd)
+	}
+	for _, crd := range bundle.V1beta1CRDs {
+		crd = crd.DeepCopy()
+		crd.SetGroupVersionKind(apiextv1beta1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+		m.V1beta1CustomResourceDefinitions = append(m.V1beta1CustomResourceDefinitions, *crd)
+	}
+	setConversionWebhooks(m, bundle.CSV, namespace)
+	return m, nil
+}
+
+// ToManifests converts csv to plain Deployments, ServiceAccounts, RBAC, and webhook
+// manifests installable in namespace without OLM. Each permission in csv becomes a
+// Role (or ClusterRole) and binding named after its service account. Webhook
+// certificates are not managed. Conversion webhooks get a Service, but are only
+// set on CRDs by BundleToManifests.
+func ToManifests(csv *operatorsv1alpha1.ClusterServiceVersion, namespace string) (*Manifests, error) {
+	if csv == nil {
+		return nil, errors.New("empty ClusterServiceVersion")
+	}
+	if csv.Spec.InstallStrategy.StrategyName != operatorsv1alpha1.InstallStrategyNameDeployment {
+		return nil, fmt.Errorf("unsupported install strategy %q", csv.Spec.InstallStrategy.StrategyName)
+	}
+	strategy := csv.Spec.InstallStrategy.StrategySpec
+
+	m := &Manifests{}
+	saNames := make(map[string]struct{})
+	addServiceAccount := func(name string) {
+		if name == "" || name == defaultServiceAccountName {
+			return
+		}
+		if _, seen := saNames[name]; seen {
+			return
+		}
+		saNames[name] = struct{}{}
+		sa := corev1.ServiceAccount{}
+		sa.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ServiceAccount"))
+		sa.SetName(name)
+		sa.SetNamespace(namespace)
+		m.ServiceAccounts = append(m.ServiceAccounts, sa)
+	}
+
+	for _, depSpec := range strategy.DeploymentSpecs {
+		dep := appsv1.Deployment{}
+		dep.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+		dep.SetName(depSpec.Name)
+		dep.SetNamespace(namespace)
+		dep.Spec = *depSpec.Spec.DeepCopy()
+		unsetTargetNamespacesRef(&dep.Spec)
+		m.Deployments = append(m.Deployments, dep)
+		addServiceAccount(dep.Spec.Template.Spec.ServiceAccountName)
+	}
+
+	for _, perm := range mergePermissions(strategy.Permissions) {
+		addServiceAccount(perm.ServiceAccountName)
+		role := rbacv1.Role{}
+		role.SetGroupVersionKind(rbacv1.SchemeGroupVersion.WithKind("Role"))
+		role.SetName(perm.ServiceAccountName)
+		role.SetNamespace(namespace)
+		role.Rules = perm.Rules
+		m.Roles = append(m.Roles, role)
+
+		binding := rbacv1.RoleBinding{}
+		binding.SetGroupVersionKind(rbacv1.SchemeGroupVersion.WithKind("RoleBinding"))
+		binding.SetName(perm.ServiceAccountName)
+		binding.SetNamespace(namespace)
+		binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: role.GetName()}
+		binding.Subjects = []rbacv1.Subject{newServiceAccountSubject(perm.ServiceAccountName, namespace)}
+		m.RoleBindings = append(m.RoleBindings, binding)
+	}
+
+	for _, perm := range mergePermissions(strategy.ClusterPermissions) {
+		addServiceAccount(perm.ServiceAccountName)
+		role := rbacv1.ClusterRole{}
+		role.SetGroupVersionKind(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"))
+		role.SetName(perm.ServiceAccountName)
+		role.Rules = perm.Rules
+		m.ClusterRoles = append(m.ClusterRoles, role)
+
+		binding := rbacv1.ClusterRoleBinding{}
+		binding.SetGroupVersionKind(rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding"))
+		binding.SetName(perm.ServiceAccountName)
+		binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role.GetName()}
+		binding.Subjects = []rbacv1.Subject{newServiceAccountSubject(perm.ServiceAccountName, namespace)}
+		m.ClusterRoleBindings = append(m.ClusterRoleBindings, binding)
+	}
+
+	if err := addWebhooks(m, csv, namespace); err != nil {
+		return nil, err
+	}
+
+	if examples, hasExamples := csv.GetAnnotations()["alm-examples"]; hasExamples && examples != "" {
+		var crs []unstructured.Unstructured
+		if err := json.Unmarshal([]byte(examples), &crs); err != nil {
+			return nil, fmt.Errorf("error parsing alm-examples: %v", err)
+		}
+		m.CustomResources = crs
+	}
+
+	return m, nil
+}
+
+// FromManifests returns a copy of base with its install strategy, owned CRDs, webhook definitions,
+// and "alm-examples" annotation replaced by the contents of m. Other fields in base, ex. descriptions
+// and descriptors, are preserved. The result is validated before being returned.
+func FromManifests(base *operatorsv1alpha1.ClusterServiceVersion, m *Manifests) (*operatorsv1alpha1.ClusterServiceVersion, error) {
+	if base == nil {
+		return nil, errors.New("empty base ClusterServiceVersion")
+	}
+	csv := base.DeepCopy()
+
+	c := &collector.Manifests{
+		ServiceAccounts:                  m.ServiceAccounts,
+		Roles:                            m.Roles,
+		RoleBindings:                     m.RoleBindings,
+		ClusterRoles:                     m.ClusterRoles,
+		ClusterRoleBindings:              m.ClusterRoleBindings,
+		Services:                         m.Services,
+		V1CustomResourceDefinitions:      m.V1CustomResourceDefinitions,
+		V1beta1CustomResourceDefinitions: m.V1beta1CustomResourceDefinitions,
+		CustomResources:                  m.CustomResources,
+	}
+	// ApplyTo modifies deployment specs in place, so do not share them with m.
+	for _, dep := range m.Deployments {
+		c.Deployments = append(c.Deployments, *dep.DeepCopy())
+	}
+	for _, cfg := range m.ValidatingWebhookConfigurations {
+		c.ValidatingWebhooks = append(c.ValidatingWebhooks, cfg.Webhooks...)
+	}
+	for _, cfg := range m.MutatingWebhookConfigurations {
+		c.MutatingWebhooks = append(c.MutatingWebhooks, cfg.Webhooks...)
+	}
+
+	if err := gencsv.ApplyTo(c, csv); err != nil {
+		return nil, err
+	}
+	return csv, nil
+}
+
+// mergePermissions combines all permissions for the same service account,
+// preserving the order in which service accounts first appear.
+func mergePermissions(perms []operatorsv1alpha1.StrategyDeploymentPermissions) (merged []operatorsv1alpha1.StrategyDeploymentPermissions) {
+	saIndex := make(map[string]int)
+	for _, perm := range perms {
+		if i, seen := saIndex[perm.ServiceAccountName]; seen {
+			merged[i].Rules = append(merged[i].Rules, perm.Rules...)
+			continue
+		}
+		saIndex[perm.ServiceAccountName] = len(merged)
+		merged = append(merged, operatorsv1alpha1.StrategyDeploymentPermissions{
+			ServiceAccountName: perm.ServiceAccountName,
+			Rules:              append([]rbacv1.PolicyRule{}, perm.Rules...),
+		})
+	}
+	return merged
+}
+
+// unsetTargetNamespacesRef points WATCH_NAMESPACE references to the OLM target namespaces
+// annotation, which only exists on OLM-managed pods, at the pod's own namespace.
+// The CSV generator reverses this when a CSV is regenerated.
+func unsetTargetNamespacesRef(spec *appsv1.DeploymentSpec) {
+	for _, c := range spec.Template.Spec.Containers {
+		for i := range c.Env {
+			ev := &c.Env[i]
+			if ev.Name == gencsv.WatchNamespaceEnv && ev.ValueFrom != nil && ev.ValueFrom.FieldRef != nil &&
+				ev.ValueFrom.FieldRef.FieldPath == gencsv.TargetNamespacesRef {
+				ev.ValueFrom.FieldRef.FieldPath = "metadata.namespace"
+			}
+		}
+	}
+}
+
+// addWebhooks adds a Service per webhook-serving deployment and one webhook configuration
+// per admission type described by csv to m.
+func addWebhooks(m *Manifests, csv *operatorsv1alpha1.ClusterServiceVersion, namespace string) error {
+	var validating []admissionregv1.ValidatingWebhook
+	var mutating []admissionregv1.MutatingWebhook
+	serviceNames := make(map[string]struct{})
+	for _, desc := range csv.Spec.WebhookDefinitions {
+		port := desc.ContainerPort
+		if port == 0 {
+			port = defaultWebhookPort
+		}
+		serviceName := desc.DeploymentName + webhookServiceSuffix
+		if _, seen := serviceNames[serviceName]; !seen {
+			service, err := newWebhookService(m.Deployments, desc.DeploymentName, serviceName, namespace, port)
+			if err != nil {
+				return err
+			}
+			m.Services = append(m.Services, service)
+			serviceNames[serviceName] = struct{}{}
+		}
+		clientConfig := admissionregv1.WebhookClientConfig{
+			Service: &admissionregv1.ServiceReference{
+				Name:      serviceName,
+				Namespace: namespace,
+				Path:      desc.WebhookPath,
+				Port:      &port,
+			},
+		}
+
+		switch desc.Type {
+		case operatorsv1alpha1.ValidatingAdmissionWebhook:
+			webhook := desc.GetValidatingWebhook(namespace, nil, nil)
+			webhook.ClientConfig = clientConfig
+			validating = append(validating, webhook)
+		case operatorsv1alpha1.MutatingAdmissionWebhook:
+			webhook := desc.GetMutatingWebhook(namespace, nil, nil)
+			webhook.ClientConfig = clientConfig
+			mutating = append(mutating, webhook)
+		}
+	}
+
+	if len(validating) != 0 {
+		cfg := admissionregv1.ValidatingWebhookConfiguration{Webhooks: validating}
+		cfg.SetGroupVersionKind(admissionregv1.SchemeGroupVersion.WithKind("ValidatingWebhookConfiguration"))
+		cfg.SetName(csv.GetName() + "-validating-webhook-configuration")
+		m.ValidatingWebhookConfigurations = append(m.ValidatingWebhookConfigurations, cfg)
+	}
+	if len(mutating) != 0 {
+		cfg := admissionregv1.MutatingWebhookConfiguration{Webhooks: mutating}
+		cfg.SetGroupVersionKind(admissionregv1.SchemeGroupVersion.WithKind("MutatingWebhookConfiguration"))
+		cfg.SetName(csv.GetName() + "-mutating-webhook-configuration")
+		m.MutatingWebhookConfigurations = append(m.MutatingWebhookConfigurations, cfg)
+	}
+	return nil
+}
+
+// setConversionWebhooks points the conversion webhook of each CRD in m converted by a ConversionWebhook
+// description in csv at the description's Service in namespace. Conversion review versions already set
+// on a CRD are kept.
+func setConversionWebhooks(m *Manifests, csv *operatorsv1alpha1.ClusterServiceVersion, namespace string) {
+	for _, desc := range csv.Spec.WebhookDefinitions {
+		if desc.Type != gencsv.ConversionWebhook {
+			continue
+		}
+		port := desc.ContainerPort
+		if port == 0 {
+			port = defaultWebhookPort
+		}
+		serviceName := desc.DeploymentName + webhookServiceSuffix
+		reviewVersions := desc.AdmissionReviewVersions
+		if len(reviewVersions) == 0 {
+			reviewVersions = []string{"v1beta1"}
+		}
+
+		for _, crdName := range desc.ConversionCRDs {
+			for i := range m.V1CustomResourceDefinitions {
+				crd := &m.V1CustomResourceDefinitions[i]
+				if crd.GetName() != crdName {
+					continue
+				}
+				conversion := &apiextv1.CustomResourceConversion{
+					Strategy: apiextv1.WebhookConverter,
+					Webhook: &apiextv1.WebhookConversion{
+						ClientConfig: &apiextv1.WebhookClientConfig{
+							Service: &apiextv1.ServiceReference{
+								Name:      serviceName,
+								Namespace: namespace,
+								Path:      desc.WebhookPath,
+								Port:      &port,
+							},
+						},
+						ConversionReviewVersions: reviewVersions,
+					},
+				}
+				if old := crd.Spec.Conversion; old != nil && old.Webhook != nil && len(old.Webhook.ConversionReviewVersions) != 0 {
+					conversion.Webhook.ConversionReviewVersions = old.Webhook.ConversionReviewVersions
+				}
+				crd.Spec.Conversion = conversion
+			}
+			for i := range m.V1beta1CustomResourceDefinitions {
+				crd := &m.V1beta1CustomResourceDefinitions[i]
+				if crd.GetName() != crdName {
+					continue
+				}
+				conversion := &apiextv1beta1.CustomResourceConversion{
+					Strategy: apiextv1beta1.WebhookConverter,
+					WebhookClientConfig: &apiextv1beta1.WebhookClientConfig{
+						Service: &apiextv1beta1.ServiceReference{
+							Name:      serviceName,
+							Namespace: namespace,
+							Path:      desc.WebhookPath,
+							Port:      &port,
+						},
+					},
+					ConversionReviewVersions: reviewVersions,
+				}
+				if old := crd.Spec.Conversion; old != nil && len(old.ConversionReviewVersions) != 0 {
+					conversion.ConversionReviewVersions = old.ConversionReviewVersions
+				}
+				crd.Spec.Conversion = conversion
+			}
+		}
+	}
+}
+
+// newWebhookService returns a Service selecting the pods of the deployment named depName in deps.
+func newWebhookService(deps []appsv1.Deployment, depName, name, namespace string, port int32) (s corev1.Service, err error) {
+	var selector map[string]string
+	for _, dep := range deps {
+		if dep.GetName() == depName {
+			selector = dep.Spec.Template.GetLabels()
+			break
+		}
+	}
+	if len(selector) == 0 {
+		return s, fmt.Errorf("webhook deployment %q not found or has no pod template labels", depName)
+	}
+
+	s.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Service"))
+	s.SetName(name)
+	s.SetNamespace(namespace)
+	s.Spec.Selector = selector
+	s.Spec.Ports = []corev1.ServicePort{{
+		Port:       port,
+		TargetPort: intstr.FromInt(int(port)),
+		Protocol:   corev1.ProtocolTCP,
+	}}
+	return s, nil
+}
+
+func newServiceAccountSubject(name, namespace string) rbacv1.Subject {
+	return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace}
+}
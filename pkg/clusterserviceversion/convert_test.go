@@ -0,0 +1,235 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterserviceversion
+
+import (
+	"github.com/blang/semver"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/lib/version"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gencsv "github.com/operator-framework/operator-sdk/internal/generate/clusterserviceversion"
+)
+
+var _ = Describe("Converting a ClusterServiceVersion", func() {
+	const namespace = "memcached-system"
+
+	var (
+		csv  *v1alpha1.ClusterServiceVersion
+		rule rbacv1.PolicyRule
+	)
+
+	BeforeEach(func() {
+		rule = rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}
+		path := "/validate-cache-example-com-v1alpha1-memcached"
+		sideEffects := admissionregv1.SideEffectClassNone
+
+		csv = &v1alpha1.ClusterServiceVersion{}
+		csv.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.ClusterServiceVersionKind))
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.SetAnnotations(map[string]string{
+			"alm-examples": `[{"apiVersion":"cache.example.com/v1alpha1","kind":"Memcached","metadata":{"name":"example"},"spec":{"size":3}}]`,
+		})
+		csv.Spec.DisplayName = "Memcached Operator"
+		csv.Spec.Version = version.OperatorVersion{Version: semver.MustParse("0.0.1")}
+		csv.Spec.InstallModes = []v1alpha1.InstallMode{{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true}}
+		csv.Spec.InstallStrategy = v1alpha1.NamedInstallStrategy{
+			StrategyName: v1alpha1.InstallStrategyNameDeployment,
+			StrategySpec: v1alpha1.StrategyDetailsDeployment{
+				DeploymentSpecs: []v1alpha1.StrategyDeploymentSpec{{
+					Name: "memcached-operator-controller-manager",
+					Spec: appsv1.DeploymentSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"control-plane": "controller-manager"}},
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"control-plane": "controller-manager"}},
+							Spec: corev1.PodSpec{
+								ServiceAccountName: "memcached-operator-manager",
+								Containers: []corev1.Container{{
+									Name:  "manager",
+									Image: "quay.io/example/memcached-operator:v0.0.1",
+									Env: []corev1.EnvVar{{
+										Name: "WATCH_NAMESPACE",
+										ValueFrom: &corev1.EnvVarSource{
+											FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations['olm.targetNamespaces']"},
+										},
+									}},
+								}},
+							},
+						},
+					},
+				}},
+				Permissions: []v1alpha1.StrategyDeploymentPermissions{
+					{ServiceAccountName: "memcached-operator-manager", Rules: []rbacv1.PolicyRule{rule}},
+				},
+				ClusterPermissions: []v1alpha1.StrategyDeploymentPermissions{
+					{ServiceAccountName: "memcached-operator-manager", Rules: []rbacv1.PolicyRule{rule}},
+				},
+			},
+		}
+		csv.Spec.WebhookDefinitions = []v1alpha1.WebhookDescription{{
+			GenerateName:            "vmemcached.kb.io",
+			Type:                    v1alpha1.ValidatingAdmissionWebhook,
+			DeploymentName:          "memcached-operator-controller-manager",
+			ContainerPort:           9443,
+			WebhookPath:             &path,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1beta1"},
+		}}
+	})
+
+	Describe("ToManifests", func() {
+		It("creates one ServiceAccount and namespaced RBAC per service account", func() {
+			m, err := ToManifests(csv, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.ServiceAccounts).To(HaveLen(1))
+			Expect(m.ServiceAccounts[0].GetNamespace()).To(Equal(namespace))
+			Expect(m.Roles).To(HaveLen(1))
+			Expect(m.Roles[0].Rules).To(Equal([]rbacv1.PolicyRule{rule}))
+			Expect(m.RoleBindings).To(HaveLen(1))
+			Expect(m.RoleBindings[0].Subjects[0].Namespace).To(Equal(namespace))
+			Expect(m.ClusterRoles).To(HaveLen(1))
+			Expect(m.ClusterRoleBindings).To(HaveLen(1))
+			Expect(m.CustomResources).To(HaveLen(1))
+		})
+		It("replaces OLM target namespace references", func() {
+			m, err := ToManifests(csv, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Deployments).To(HaveLen(1))
+			env := m.Deployments[0].Spec.Template.Spec.Containers[0].Env
+			Expect(env[0].ValueFrom.FieldRef.FieldPath).To(Equal("metadata.namespace"))
+		})
+		It("creates a Service and webhook configuration for webhooks", func() {
+			m, err := ToManifests(csv, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Services).To(HaveLen(1))
+			Expect(m.Services[0].GetName()).To(Equal("memcached-operator-controller-manager-service"))
+			Expect(m.Services[0].Spec.Selector).To(Equal(map[string]string{"control-plane": "controller-manager"}))
+			Expect(m.ValidatingWebhookConfigurations).To(HaveLen(1))
+			Expect(m.ValidatingWebhookConfigurations[0].Webhooks[0].ClientConfig.Service.Name).To(Equal(m.Services[0].GetName()))
+		})
+		It("fails for a webhook whose deployment does not exist", func() {
+			csv.Spec.WebhookDefinitions[0].DeploymentName = "foo"
+			_, err := ToManifests(csv, namespace)
+			Expect(err).To(HaveOccurred())
+		})
+		It("adds CRDs from a bundle", func() {
+			crd := &apiextv1.CustomResourceDefinition{}
+			crd.SetName("memcacheds.cache.example.com")
+			m, err := BundleToManifests(&apimanifests.Bundle{CSV: csv, V1CRDs: []*apiextv1.CustomResourceDefinition{crd}}, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.V1CustomResourceDefinitions).To(HaveLen(1))
+			Expect(m.Objects()[0].GetName()).To(Equal("memcacheds.cache.example.com"))
+		})
+		It("points CRD conversion webhooks at the webhook Service", func() {
+			sideEffects := admissionregv1.SideEffectClassNone
+			convertPath := "/convert"
+			csv.Spec.WebhookDefinitions = append(csv.Spec.WebhookDefinitions, v1alpha1.WebhookDescription{
+				GenerateName:            "cmemcached.cache.example.com",
+				Type:                    gencsv.ConversionWebhook,
+				DeploymentName:          "memcached-operator-controller-manager",
+				ContainerPort:           9443,
+				WebhookPath:             &convertPath,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1", "v1beta1"},
+				ConversionCRDs:          []string{"memcacheds.cache.example.com"},
+			})
+			crd := &apiextv1.CustomResourceDefinition{}
+			crd.SetName("memcacheds.cache.example.com")
+			m, err := BundleToManifests(&apimanifests.Bundle{CSV: csv, V1CRDs: []*apiextv1.CustomResourceDefinition{crd}}, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Services).To(HaveLen(1))
+
+			conversion := m.V1CustomResourceDefinitions[0].Spec.Conversion
+			Expect(conversion.Strategy).To(Equal(apiextv1.WebhookConverter))
+			Expect(conversion.Webhook.ConversionReviewVersions).To(Equal([]string{"v1", "v1beta1"}))
+			service := conversion.Webhook.ClientConfig.Service
+			Expect(service.Name).To(Equal(m.Services[0].GetName()))
+			Expect(service.Namespace).To(Equal(namespace))
+			Expect(*service.Path).To(Equal(convertPath))
+			Expect(*service.Port).To(BeEquivalentTo(9443))
+			Expect(crd.Spec.Conversion).To(BeNil())
+		})
+	})
+
+	Describe("FromManifests", func() {
+		// toManifests adds the CRD of the CSV's example, which a bundle would contain.
+		toManifests := func() *Manifests {
+			m, err := ToManifests(csv, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			crd := &apiextv1.CustomResourceDefinition{}
+			crd.SetName("memcacheds.cache.example.com")
+			crd.Spec.Group = "cache.example.com"
+			crd.Spec.Names = apiextv1.CustomResourceDefinitionNames{Plural: "memcacheds", Kind: "Memcached"}
+			crd.Spec.Versions = []apiextv1.CustomResourceDefinitionVersion{{Name: "v1alpha1", Served: true, Storage: true}}
+			m.V1CustomResourceDefinitions = append(m.V1CustomResourceDefinitions, *crd)
+			return m
+		}
+
+		It("round trips a ClusterServiceVersion", func() {
+			m := toManifests()
+
+			base := csv.DeepCopy()
+			base.Spec.InstallStrategy = v1alpha1.NamedInstallStrategy{}
+			base.Spec.WebhookDefinitions = nil
+			out, err := FromManifests(base, m)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Spec.InstallStrategy).To(Equal(csv.Spec.InstallStrategy))
+			Expect(out.Spec.WebhookDefinitions).To(Equal(csv.Spec.WebhookDefinitions))
+			Expect(out.Spec.DisplayName).To(Equal(csv.Spec.DisplayName))
+		})
+		It("round trips a conversion webhook", func() {
+			sideEffects := admissionregv1.SideEffectClassNone
+			convertPath := "/convert"
+			csv.Spec.WebhookDefinitions = append(csv.Spec.WebhookDefinitions, v1alpha1.WebhookDescription{
+				GenerateName:            "cmemcached.cache.example.com",
+				Type:                    gencsv.ConversionWebhook,
+				DeploymentName:          "memcached-operator-controller-manager",
+				ContainerPort:           9443,
+				WebhookPath:             &convertPath,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1beta1"},
+				ConversionCRDs:          []string{"memcacheds.cache.example.com"},
+			})
+			crd := &apiextv1.CustomResourceDefinition{}
+			crd.SetName("memcacheds.cache.example.com")
+			crd.Spec.Group = "cache.example.com"
+			crd.Spec.Names = apiextv1.CustomResourceDefinitionNames{Plural: "memcacheds", Singular: "memcached", Kind: "Memcached"}
+			crd.Spec.Versions = []apiextv1.CustomResourceDefinitionVersion{{Name: "v1alpha1", Served: true, Storage: true}}
+			m, err := BundleToManifests(&apimanifests.Bundle{CSV: csv, V1CRDs: []*apiextv1.CustomResourceDefinition{crd}}, namespace)
+			Expect(err).NotTo(HaveOccurred())
+
+			base := csv.DeepCopy()
+			base.Spec.WebhookDefinitions = nil
+			out, err := FromManifests(base, m)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Spec.WebhookDefinitions).To(Equal(csv.Spec.WebhookDefinitions))
+		})
+		It("does not modify manifests", func() {
+			m := toManifests()
+			_, err := FromManifests(csv, m)
+			Expect(err).NotTo(HaveOccurred())
+			env := m.Deployments[0].Spec.Template.Spec.Containers[0].Env
+			Expect(env[0].ValueFrom.FieldRef.FieldPath).To(Equal("metadata.namespace"))
+		})
+	})
+})
@@ -0,0 +1,86 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clusterserviceversion converts between a ClusterServiceVersion and the
+// plain Kubernetes manifests it describes, so one set of manifests can be used to
+// install an operator both with and without OLM.
+package clusterserviceversion
+
+import (
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Manifests holds all plain manifests a ClusterServiceVersion can be converted to or from.
+type Manifests struct {
+	ServiceAccounts                  []corev1.ServiceAccount
+	Roles                            []rbacv1.Role
+	RoleBindings                     []rbacv1.RoleBinding
+	ClusterRoles                     []rbacv1.ClusterRole
+	ClusterRoleBindings              []rbacv1.ClusterRoleBinding
+	Deployments                      []appsv1.Deployment
+	Services                         []corev1.Service
+	V1CustomResourceDefinitions      []apiextv1.CustomResourceDefinition
+	V1beta1CustomResourceDefinitions []apiextv1beta1.CustomResourceDefinition
+	ValidatingWebhookConfigurations  []admissionregv1.ValidatingWebhookConfiguration
+	MutatingWebhookConfigurations    []admissionregv1.MutatingWebhookConfiguration
+	// CustomResources are examples parsed from or written to a CSV's "alm-examples" annotation.
+	// They are not returned by Objects().
+	CustomResources []unstructured.Unstructured
+}
+
+// Objects returns all objects in m that should be applied to a cluster, in an order
+// that can be applied as-is: CRDs first, then RBAC, then workloads and webhooks.
+func (m *Manifests) Objects() (objs []controllerutil.Object) {
+	for i := range m.V1CustomResourceDefinitions {
+		objs = append(objs, &m.V1CustomResourceDefinitions[i])
+	}
+	for i := range m.V1beta1CustomResourceDefinitions {
+		objs = append(objs, &m.V1beta1CustomResourceDefinitions[i])
+	}
+	for i := range m.ServiceAccounts {
+		objs = append(objs, &m.ServiceAccounts[i])
+	}
+	for i := range m.Roles {
+		objs = append(objs, &m.Roles[i])
+	}
+	for i := range m.RoleBindings {
+		objs = append(objs, &m.RoleBindings[i])
+	}
+	for i := range m.ClusterRoles {
+		objs = append(objs, &m.ClusterRoles[i])
+	}
+	for i := range m.ClusterRoleBindings {
+		objs = append(objs, &m.ClusterRoleBindings[i])
+	}
+	for i := range m.Services {
+		objs = append(objs, &m.Services[i])
+	}
+	for i := range m.Deployments {
+		objs = append(objs, &m.Deployments[i])
+	}
+	for i := range m.ValidatingWebhookConfigurations {
+		objs = append(objs, &m.ValidatingWebhookConfigurations[i])
+	}
+	for i := range m.MutatingWebhookConfigurations {
+		objs = append(objs, &m.MutatingWebhookConfigurations[i])
+	}
+	return objs
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics registers operator-specific Prometheus metrics with the controller-runtime
+// metrics registry, which is served on the manager's metrics endpoint.
+//
+// Metrics are named "<operator name>_<name>", with the operator name in snake case, so they do not
+// collide with metrics exported by controller-runtime or other operators. Names must be snake case,
+// and counter names must end in "_total", following Prometheus naming conventions.
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var nameRe = regexp.MustCompile(`^[a-z][a-z0-9_]*[a-z0-9]$`)
+
+// Registry creates and registers metrics prefixed by an operator's name.
+type Registry struct {
+	namespace  string
+	registerer prometheus.Registerer
+}
+
+// NewRegistry returns a Registry that registers metrics for operatorName,
+// ex. "memcached-operator", with the controller-runtime metrics registry.
+func NewRegistry(operatorName string) *Registry {
+	return NewRegistryFor(operatorName, ctrlmetrics.Registry)
+}
+
+// NewRegistryFor returns a Registry that registers metrics for operatorName with registerer.
+func NewRegistryFor(operatorName string, registerer prometheus.Registerer) *Registry {
+	namespace := strings.ToLower(strings.NewReplacer("-", "_", ".", "_").Replace(operatorName))
+	return &Registry{namespace: namespace, registerer: registerer}
+}
+
+// NewCounterVec registers and returns a counter named "<operator name>_<name>". name must end in "_total".
+func (r *Registry) NewCounterVec(name, help string, labels ...string) (*prometheus.CounterVec, error) {
+	if !strings.HasSuffix(name, "_total") {
+		return nil, fmt.Errorf("counter name %q must end in \"_total\"", name)
+	}
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: r.namespace, Name: name, Help: help}, labels)
+	return c, r.register(c)
+}
+
+// NewGaugeVec registers and returns a gauge named "<operator name>_<name>".
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) (*prometheus.GaugeVec, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: r.namespace, Name: name, Help: help}, labels)
+	return g, r.register(g)
+}
+
+// NewHistogramVec registers and returns a histogram named "<operator name>_<name>".
+// If buckets is nil, prometheus.DefBuckets are used.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) (*prometheus.HistogramVec, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	opts := prometheus.HistogramOpts{Namespace: r.namespace, Name: name, Help: help, Buckets: buckets}
+	h := prometheus.NewHistogramVec(opts, labels)
+	return h, r.register(h)
+}
+
+func (r *Registry) register(c prometheus.Collector) error {
+	if err := r.registerer.Register(c); err != nil {
+		return fmt.Errorf("error registering metric: %v", err)
+	}
+	return nil
+}
+
+func validateName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("metric name %q must be snake case", name)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ = Describe("Registry", func() {
+	var (
+		reg *prometheus.Registry
+		r   *Registry
+	)
+
+	BeforeEach(func() {
+		reg = prometheus.NewRegistry()
+		r = NewRegistryFor("memcached-operator", reg)
+	})
+
+	It("prefixes metric names with the operator name", func() {
+		c, err := r.NewCounterVec("cache_misses_total", "Number of cache misses.", "kind")
+		Expect(err).NotTo(HaveOccurred())
+		c.WithLabelValues("Memcached").Inc()
+
+		families, err := reg.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(families).To(HaveLen(1))
+		Expect(families[0].GetName()).To(Equal("memcached_operator_cache_misses_total"))
+	})
+	It("rejects counters not ending in _total", func() {
+		_, err := r.NewCounterVec("cache_misses", "Number of cache misses.")
+		Expect(err).To(HaveOccurred())
+	})
+	It("rejects names that are not snake case", func() {
+		_, err := r.NewGaugeVec("cacheSize", "Size of the cache.")
+		Expect(err).To(HaveOccurred())
+	})
+	It("rejects metrics registered twice", func() {
+		_, err := r.NewHistogramVec("sync_duration_seconds", "Sync duration.", nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.NewHistogramVec("sync_duration_seconds", "Sync duration.", nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
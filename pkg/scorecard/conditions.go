@@ -0,0 +1,116 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// conditionPollInterval is how often WaitForCondition gets the object.
+const conditionPollInterval = 2 * time.Second
+
+// Condition is an entry of an object's status.conditions.
+type Condition struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+// GetCondition returns the condition of type condType in obj's status.conditions, or nil if it has none.
+func GetCondition(obj *unstructured.Unstructured, condType string) (*Condition, error) {
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		return nil, fmt.Errorf("%s %s has invalid status.conditions: %v", obj.GetKind(), obj.GetName(), err)
+	}
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t, _, _ := unstructured.NestedString(m, "type"); t != condType {
+			continue
+		}
+		cond := &Condition{Type: condType}
+		cond.Status, _, _ = unstructured.NestedString(m, "status")
+		cond.Reason, _, _ = unstructured.NestedString(m, "reason")
+		cond.Message, _, _ = unstructured.NestedString(m, "message")
+		return cond, nil
+	}
+	return nil, nil
+}
+
+// checkCondition returns why obj does not have a condType condition with status, or the empty string.
+func checkCondition(obj *unstructured.Unstructured, condType, status string) (string, error) {
+	cond, err := GetCondition(obj, condType)
+	if err != nil {
+		return "", err
+	}
+	if cond == nil {
+		return fmt.Sprintf("%s %s has no %s condition", obj.GetKind(), obj.GetName(), condType), nil
+	}
+	if cond.Status != status {
+		return fmt.Sprintf("%s %s has condition %s=%s, want %s (reason: %q, message: %q)", obj.GetKind(),
+			obj.GetName(), condType, cond.Status, status, cond.Reason, cond.Message), nil
+	}
+	return "", nil
+}
+
+// AssertCondition records a failure if obj does not have a condType condition with status, ex. "True".
+// It returns true if the assertion holds.
+func (t *T) AssertCondition(obj *unstructured.Unstructured, condType, status string) bool {
+	why, err := checkCondition(obj, condType, status)
+	if err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	if why != "" {
+		t.Errorf("%s", why)
+		return false
+	}
+	return true
+}
+
+// WaitForCondition gets obj, which must have its kind, name, and namespace set, until it has a condType
+// condition with status, or ctx is done. obj may not exist yet. obj is updated with the last state retrieved.
+func WaitForCondition(ctx context.Context, c client.Client, obj *unstructured.Unstructured, condType,
+	status string) error {
+
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	var why string
+	err := wait.PollImmediateUntil(conditionPollInterval, func() (bool, error) {
+		if err := c.Get(ctx, key, obj); apierrors.IsNotFound(err) {
+			why = fmt.Sprintf("%s %s does not exist", obj.GetKind(), obj.GetName())
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		var err error
+		why, err = checkCondition(obj, condType, status)
+		return why == "", err
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out waiting for condition: %s", why)
+	}
+	return err
+}
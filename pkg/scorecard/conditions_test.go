@@ -0,0 +1,82 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Conditions", func() {
+	pod := func(status corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "memcached-0", Namespace: "default"},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: status, Reason: "ContainersNotReady", Message: "not ready"},
+			}},
+		}
+	}
+	toUnstructured := func(p *corev1.Pod) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		Expect(scheme.Scheme.Convert(p, u, nil)).To(Succeed())
+		return u
+	}
+
+	It("gets conditions by type", func() {
+		u := toUnstructured(pod(corev1.ConditionFalse))
+		Expect(GetCondition(u, "Ready")).To(Equal(&Condition{
+			Type: "Ready", Status: "False", Reason: "ContainersNotReady", Message: "not ready"}))
+		Expect(GetCondition(u, "Initialized")).To(BeNil())
+	})
+	It("asserts conditions", func() {
+		t := newT("ready", "", nil)
+		u := toUnstructured(pod(corev1.ConditionFalse))
+		Expect(t.AssertCondition(u, "Ready", "False")).To(BeTrue())
+		Expect(t.Failed()).To(BeFalse())
+		Expect(t.AssertCondition(u, "Ready", "True")).To(BeFalse())
+		Expect(t.AssertCondition(u, "Initialized", "True")).To(BeFalse())
+		Expect(t.Result().State).To(Equal(v1alpha3.FailState))
+		Expect(t.Result().Errors).To(Equal([]string{
+			`Pod memcached-0 has condition Ready=False, want True (reason: "ContainersNotReady", message: "not ready")`,
+			"Pod memcached-0 has no Initialized condition",
+		}))
+	})
+	It("waits for conditions", func() {
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, pod(corev1.ConditionTrue))
+		u := toUnstructured(pod(corev1.ConditionFalse))
+		Expect(WaitForCondition(context.TODO(), c, u, "Ready", "True")).To(Succeed())
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer cancel()
+		err := WaitForCondition(ctx, c, u, "Ready", "False")
+		Expect(err).To(MatchError(ContainSubstring("timed out waiting for condition: Pod memcached-0 has condition Ready=True")))
+
+		u.SetName("memcached-1")
+		ctx, cancel = context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer cancel()
+		Expect(WaitForCondition(ctx, c, u, "Ready", "True")).To(MatchError(
+			"timed out waiting for condition: Pod memcached-1 does not exist"))
+	})
+})
@@ -0,0 +1,83 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	// ResultConfigMapEnv is the env var scorecard sets to the name of the ConfigMap a test writes its
+	// status to when run with --result-source=configmap, for clusters where scorecard cannot read pod logs.
+	ResultConfigMapEnv = "SCORECARD_RESULT_CONFIGMAP"
+	// ResultConfigMapKey is the key of a test's status in its result ConfigMap.
+	ResultConfigMapKey = "result.json"
+)
+
+// WriteStatus writes status to stdout, and to the ConfigMap named by ResultConfigMapEnv if set,
+// where scorecard reads it from. It exits if status cannot be written.
+func WriteStatus(status v1alpha3.TestStatus) {
+	b, err := json.MarshalIndent(status, "", "    ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate json: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s\n", b)
+
+	name := os.Getenv(ResultConfigMapEnv)
+	if name == "" {
+		return
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error getting kubeconfig: %v\n", err)
+		os.Exit(1)
+	}
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error creating client: %v\n", err)
+		os.Exit(1)
+	}
+	if err := writeResultConfigMap(context.Background(), c, namespace(), name, b); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// writeResultConfigMap patches result into the ConfigMap name, which scorecard creates before the test
+// pod, so tests only need permission to patch it.
+func writeResultConfigMap(ctx context.Context, c client.Client, namespace, name string, result []byte) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"data": map[string]string{ResultConfigMapKey: string(result)},
+	})
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	if err := c.Patch(ctx, cm, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("error writing result to ConfigMap %s/%s: %v", namespace, name, err)
+	}
+	return nil
+}
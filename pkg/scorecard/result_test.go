@@ -0,0 +1,48 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("writeResultConfigMap", func() {
+	It("writes the result to the existing ConfigMap", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "scorecard-test-abcd", Namespace: "default",
+				Labels: map[string]string{"testrun": "scorecard-test-wxyz"}},
+		}
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, cm)
+		Expect(writeResultConfigMap(context.TODO(), c, "default", cm.Name, []byte(`{"results":[]}`))).To(Succeed())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: cm.Name}, cm)).To(Succeed())
+		Expect(cm.Data).To(Equal(map[string]string{ResultConfigMapKey: `{"results":[]}`}))
+		Expect(cm.Labels).To(HaveKeyWithValue("testrun", "scorecard-test-wxyz"))
+	})
+	It("fails if the ConfigMap does not exist", func() {
+		c := fake.NewFakeClientWithScheme(scheme.Scheme)
+		err := writeResultConfigMap(context.TODO(), c, "default", "scorecard-test-abcd", []byte(`{}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("error writing result to ConfigMap default/scorecard-test-abcd: "))
+	})
+})
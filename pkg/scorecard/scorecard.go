@@ -0,0 +1,119 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scorecard is a library for writing custom scorecard tests in Go. A test image runs a binary whose
+// first argument names the test to run, ex. with the entrypoint [custom-scorecard-tests, has-examples] in the
+// scorecard config. Main runs the named test against the bundle under test, and writes its result to stdout
+// for scorecard to read:
+//
+//	func main() {
+//		scorecard.Main(map[string]scorecard.TestFunc{
+//			"has-examples": func(ctx context.Context, t *scorecard.T) {
+//				if _, ok := t.Bundle.CSV.GetAnnotations()["alm-examples"]; !ok {
+//					t.Errorf("the CSV has no alm-examples annotation")
+//					t.Suggestf("add example CRs to the CSV's alm-examples annotation")
+//				}
+//			},
+//		})
+//	}
+//
+// Scorecard reads a test's result from the logs of its pod, or from a ConfigMap on clusters where it cannot read
+// pod logs, so tests must not write to stdout or stderr.
+// Write to the test's log with T.Logf, or to an artifact with T.Artifact, instead.
+//
+// Tests may also be scripts in any language, run by ScriptTest.
+package scorecard
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+)
+
+const (
+	// BundleRoot is the directory the bundle under test is mounted at in test pods.
+	BundleRoot = "/bundle"
+	// NamespaceEnv is the env var scorecard sets to the namespace tests are run in.
+	NamespaceEnv = "SCORECARD_NAMESPACE"
+
+	serviceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// TestFunc is a test. It passes unless it records a failure or error with T.
+type TestFunc func(ctx context.Context, t *T)
+
+// Main runs the test named by the program's first argument against the bundle at BundleRoot, and
+// writes its status with WriteStatus.
+func Main(tests map[string]TestFunc) {
+	WriteStatus(Run(context.Background(), tests, os.Args[1:], BundleRoot))
+}
+
+// Run runs the test named by args[0] against the bundle in bundleRoot, passing it the remaining args,
+// and returns its status. Tests can be run locally by calling Run with the path of a bundle directory.
+func Run(ctx context.Context, tests map[string]TestFunc, args []string, bundleRoot string) v1alpha3.TestStatus {
+	if len(args) == 0 || tests[args[0]] == nil {
+		names := make([]string, 0, len(tests))
+		for name := range tests {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		t := newT("", bundleRoot, nil)
+		why := "a test name argument is required"
+		if len(args) != 0 {
+			why = fmt.Sprintf("unknown test %q", args[0])
+		}
+		if len(names) != 0 {
+			why += ". Valid tests for this image include: " + strings.Join(names, " ")
+		}
+		t.Errorf("%s", why)
+		return t.Status()
+	}
+
+	t := newT(args[0], bundleRoot, args[1:])
+	bundle, err := apimanifests.GetBundleFromDir(bundleRoot)
+	if err != nil {
+		t.Error(fmt.Errorf("error reading bundle %s: %v", bundleRoot, err))
+		return t.Status()
+	}
+	t.Bundle = bundle
+	runTest(ctx, tests[args[0]], t)
+	return t.Status()
+}
+
+// runTest runs fn, recording a panic as an error.
+func runTest(ctx context.Context, fn TestFunc, t *T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Error(fmt.Errorf("test panicked: %v", r))
+		}
+	}()
+	fn(ctx, t)
+}
+
+// namespace returns the namespace tests are run in, or the empty string if unknown.
+func namespace() string {
+	if ns := os.Getenv(NamespaceEnv); ns != "" {
+		return ns
+	}
+	if b, err := ioutil.ReadFile(serviceAccountNamespacePath); err == nil {
+		return strings.TrimSpace(string(b))
+	}
+	return ""
+}
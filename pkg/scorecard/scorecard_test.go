@@ -0,0 +1,84 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+var _ = Describe("Running a test", func() {
+	bundleRoot := filepath.Join("..", "..", "internal", "scorecard", "testdata", "bundle")
+	tests := map[string]TestFunc{
+		"has-examples": func(ctx context.Context, t *T) {
+			crs, err := t.ExampleCRs()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			t.Logf("found %d example CRs", len(crs))
+			if len(crs) == 0 {
+				t.Errorf("the CSV has no example CRs")
+			}
+		},
+		"args": func(ctx context.Context, t *T) {
+			t.Errorf("args: %v", t.Args)
+			t.Suggestf("pass fewer args")
+			Expect(t.WriteJSONArtifact("args.json", t.Args)).To(Succeed())
+		},
+		"panics": func(ctx context.Context, t *T) {
+			panic("oops")
+		},
+	}
+
+	It("runs the named test against the bundle", func() {
+		status := Run(context.TODO(), tests, []string{"has-examples"}, bundleRoot)
+		Expect(status.Results).To(HaveLen(1))
+		r := status.Results[0]
+		Expect(r.Name).To(Equal("has-examples"))
+		Expect(r.State).To(Equal(v1alpha3.PassState))
+		Expect(r.Log).To(MatchRegexp(`^found [1-9]\d* example CRs\n$`))
+	})
+	It("passes the remaining args and records failures, suggestions, and artifacts", func() {
+		r := Run(context.TODO(), tests, []string{"args", "a", "b"}, bundleRoot).Results[0]
+		Expect(r.State).To(Equal(v1alpha3.FailState))
+		Expect(r.Errors).To(Equal([]string{"args: [a b]"}))
+		Expect(r.Suggestions).To(Equal([]string{"pass fewer args"}))
+		Expect(r.Log).To(Equal("--- artifact: args.json ---\n[\n  \"a\",\n  \"b\"\n]\n"))
+	})
+	It("records panics as errors", func() {
+		r := Run(context.TODO(), tests, []string{"panics"}, bundleRoot).Results[0]
+		Expect(r.State).To(Equal(v1alpha3.ErrorState))
+		Expect(r.Errors).To(Equal([]string{"test panicked: oops"}))
+	})
+	It("lists the valid tests if the test is unknown", func() {
+		r := Run(context.TODO(), tests, []string{"missing"}, bundleRoot).Results[0]
+		Expect(r.State).To(Equal(v1alpha3.FailState))
+		Expect(r.Errors).To(Equal([]string{
+			`unknown test "missing". Valid tests for this image include: args has-examples panics`}))
+		r = Run(context.TODO(), tests, nil, bundleRoot).Results[0]
+		Expect(r.Errors).To(Equal([]string{
+			"a test name argument is required. Valid tests for this image include: args has-examples panics"}))
+	})
+	It("errors if the bundle cannot be read", func() {
+		r := Run(context.TODO(), tests, []string{"has-examples"}, "missing").Results[0]
+		Expect(r.State).To(Equal(v1alpha3.ErrorState))
+		Expect(r.Errors[0]).To(HavePrefix("error reading bundle missing"))
+	})
+})
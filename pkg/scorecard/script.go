@@ -0,0 +1,126 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+// Env vars set for script tests, in addition to NamespaceEnv.
+const (
+	// BundleRootEnv is the directory of the bundle under test.
+	BundleRootEnv = "SCORECARD_BUNDLE_ROOT"
+	// TestNameEnv is the name of the test, the script's file name without its extension.
+	TestNameEnv = "SCORECARD_TEST_NAME"
+	// ResultFileEnv is the path of a file a script may write its result to as JSON.
+	ResultFileEnv = "SCORECARD_RESULT"
+)
+
+// ScriptResult is the JSON a script test may write to the file in ResultFileEnv. All fields are optional.
+type ScriptResult struct {
+	// State overrides the state set by the script's exit code: "pass", "fail", or "error".
+	State       v1alpha3.State `json:"state,omitempty"`
+	Errors      []string       `json:"errors,omitempty"`
+	Suggestions []string       `json:"suggestions,omitempty"`
+}
+
+// ScriptTest returns a test that runs the executable script at path, ex. a Python or shell script, with the
+// test's args. The script passes if it exits with status 0, fails if it exits with status 1, and errors
+// otherwise. Its output is the test's log, and it may write a ScriptResult to the file in ResultFileEnv
+// to report errors and suggestions.
+func ScriptTest(path string) TestFunc {
+	return func(ctx context.Context, t *T) {
+		resultDir, err := ioutil.TempDir("", "scorecard-result")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer os.RemoveAll(resultDir)
+		resultFile := filepath.Join(resultDir, "result.json")
+
+		cmd := exec.CommandContext(ctx, path, t.Args...)
+		cmd.Env = append(os.Environ(),
+			BundleRootEnv+"="+t.BundleRoot,
+			NamespaceEnv+"="+t.Namespace,
+			TestNameEnv+"="+t.Name(),
+			ResultFileEnv+"="+resultFile,
+		)
+		out := &bytes.Buffer{}
+		cmd.Stdout, cmd.Stderr = out, out
+		runErr := cmd.Run()
+		if out.Len() != 0 {
+			t.Logf("%s", out.String())
+		}
+
+		var exitErr *exec.ExitError
+		switch {
+		case runErr == nil:
+		case errors.As(runErr, &exitErr) && exitErr.ExitCode() == 1:
+			t.result.State = v1alpha3.FailState
+		default:
+			t.Error(fmt.Errorf("script %s: %v", path, runErr))
+		}
+
+		b, err := ioutil.ReadFile(resultFile)
+		if os.IsNotExist(err) {
+			if t.result.State == v1alpha3.FailState && len(t.result.Errors) == 0 {
+				t.Errorf("script %s exited with status 1", path)
+			}
+			return
+		} else if err != nil {
+			t.Error(err)
+			return
+		}
+		var r ScriptResult
+		if err := json.Unmarshal(b, &r); err != nil {
+			t.Error(fmt.Errorf("error parsing the result of script %s: %v", path, err))
+			return
+		}
+		switch r.State {
+		case "":
+		case v1alpha3.PassState, v1alpha3.FailState, v1alpha3.ErrorState:
+			t.result.State = r.State
+		default:
+			t.Error(fmt.Errorf("script %s wrote an invalid state %q", path, r.State))
+		}
+		t.result.Errors = append(t.result.Errors, r.Errors...)
+		t.result.Suggestions = append(t.result.Suggestions, r.Suggestions...)
+	}
+}
+
+// ScriptMain runs the script named by the program's first argument as a test, with the remaining arguments,
+// against the bundle at BundleRoot, and writes its status to stdout. It is the entrypoint of the adapter
+// in the scorecard-test-python and scorecard-test-shell images.
+func ScriptMain() {
+	args := os.Args[1:]
+	tests := map[string]TestFunc{}
+	if len(args) != 0 {
+		name := strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		tests[name] = ScriptTest(args[0])
+		args[0] = name
+	}
+	WriteStatus(Run(context.Background(), tests, args, BundleRoot))
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+var _ = Describe("Running a script test", func() {
+	var dir string
+	bundleRoot := filepath.Join("..", "..", "internal", "scorecard", "testdata", "bundle")
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "script-test")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	run := func(script string, args ...string) v1alpha3.TestResult {
+		path := filepath.Join(dir, "check.sh")
+		ExpectWithOffset(1, ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755)).To(Succeed())
+		tests := map[string]TestFunc{"check": ScriptTest(path)}
+		status := Run(context.TODO(), tests, append([]string{"check"}, args...), bundleRoot)
+		ExpectWithOffset(1, status.Results).To(HaveLen(1))
+		return status.Results[0]
+	}
+
+	It("passes if the script exits with status 0, and logs its output", func() {
+		r := run(`echo "$SCORECARD_TEST_NAME $1 $(basename $SCORECARD_BUNDLE_ROOT)"`, "arg")
+		Expect(r.State).To(Equal(v1alpha3.PassState))
+		Expect(r.Log).To(Equal("check arg bundle\n"))
+	})
+	It("fails if the script exits with status 1", func() {
+		r := run("exit 1")
+		Expect(r.State).To(Equal(v1alpha3.FailState))
+		Expect(r.Errors).To(Equal([]string{"script " + filepath.Join(dir, "check.sh") + " exited with status 1"}))
+	})
+	It("errors if the script exits with another status", func() {
+		r := run("exit 2")
+		Expect(r.State).To(Equal(v1alpha3.ErrorState))
+		Expect(r.Errors[0]).To(HaveSuffix("exit status 2"))
+	})
+	It("reads errors and suggestions from the result file", func() {
+		r := run(`echo '{"errors": ["no example CRs"], "suggestions": ["add alm-examples"]}' > "$SCORECARD_RESULT"
+exit 1`)
+		Expect(r.State).To(Equal(v1alpha3.FailState))
+		Expect(r.Errors).To(Equal([]string{"no example CRs"}))
+		Expect(r.Suggestions).To(Equal([]string{"add alm-examples"}))
+	})
+	It("lets the result file override the state", func() {
+		r := run(`echo '{"state": "fail"}' > "$SCORECARD_RESULT"`)
+		Expect(r.State).To(Equal(v1alpha3.FailState))
+
+		r = run(`echo '{"state": "skipped"}' > "$SCORECARD_RESULT"`)
+		Expect(r.State).To(Equal(v1alpha3.ErrorState))
+		Expect(r.Errors[0]).To(HaveSuffix(`wrote an invalid state "skipped"`))
+	})
+})
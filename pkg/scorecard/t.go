@@ -0,0 +1,168 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// T is passed to a test to inspect the bundle under test and record the test's result.
+type T struct {
+	// Bundle is the bundle under test.
+	Bundle *apimanifests.Bundle
+	// BundleRoot is the directory of the bundle under test, for tests of files other than its manifests.
+	BundleRoot string
+	// Args are the arguments following the test's name in its entrypoint.
+	Args []string
+	// Namespace is the namespace the test is run in, which the operator is installed in.
+	Namespace string
+
+	result    v1alpha3.TestResult
+	log       strings.Builder
+	artifacts []artifact
+	client    client.Client
+}
+
+type artifact struct {
+	name string
+	buf  *bytes.Buffer
+}
+
+func newT(name, bundleRoot string, args []string) *T {
+	return &T{
+		BundleRoot: bundleRoot,
+		Args:       args,
+		Namespace:  namespace(),
+		result:     v1alpha3.TestResult{Name: name, State: v1alpha3.PassState},
+	}
+}
+
+// Name returns the name of the test.
+func (t *T) Name() string {
+	return t.result.Name
+}
+
+// Logf writes a line to the test's log.
+func (t *T) Logf(format string, args ...interface{}) {
+	t.log.WriteString(strings.TrimSuffix(fmt.Sprintf(format, args...), "\n") + "\n")
+}
+
+// Errorf records why the test fails. The test continues.
+func (t *T) Errorf(format string, args ...interface{}) {
+	if t.result.State != v1alpha3.ErrorState {
+		t.result.State = v1alpha3.FailState
+	}
+	t.result.Errors = append(t.result.Errors, fmt.Sprintf(format, args...))
+}
+
+// Error records that the test could not be run to completion, ex. because the cluster is unreachable,
+// rather than that the operator failed it.
+func (t *T) Error(err error) {
+	t.result.State = v1alpha3.ErrorState
+	t.result.Errors = append(t.result.Errors, err.Error())
+}
+
+// Suggestf records a suggestion for improving the operator, shown with the test's result.
+func (t *T) Suggestf(format string, args ...interface{}) {
+	t.result.Suggestions = append(t.result.Suggestions, fmt.Sprintf(format, args...))
+}
+
+// Failed returns true if the test has failed or errored.
+func (t *T) Failed() bool {
+	return t.result.State != v1alpha3.PassState
+}
+
+// Artifact returns a writer for an artifact of the test, ex. the objects a failing test inspected.
+// Artifacts are appended to the test's log under their name, as scorecard only reads a test's output.
+func (t *T) Artifact(name string) io.Writer {
+	for _, a := range t.artifacts {
+		if a.name == name {
+			return a.buf
+		}
+	}
+	a := artifact{name: name, buf: &bytes.Buffer{}}
+	t.artifacts = append(t.artifacts, a)
+	return a.buf
+}
+
+// WriteJSONArtifact writes v as an indented JSON artifact named name.
+func (t *T) WriteJSONArtifact(name string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling artifact %s: %v", name, err)
+	}
+	_, err = t.Artifact(name).Write(append(b, '\n'))
+	return err
+}
+
+// Client returns a client of the cluster the test is run in, with the test pod's service account.
+func (t *T) Client() (client.Client, error) {
+	if t.client != nil {
+		return t.client, nil
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error getting kubeconfig: %v", err)
+	}
+	if t.client, err = client.New(cfg, client.Options{}); err != nil {
+		return nil, fmt.Errorf("error creating client: %v", err)
+	}
+	return t.client, nil
+}
+
+// ExampleCRs returns the example CRs in the alm-examples annotation of the bundle's CSV.
+func (t *T) ExampleCRs() ([]unstructured.Unstructured, error) {
+	if t.Bundle == nil || t.Bundle.CSV == nil {
+		return nil, nil
+	}
+	almExamples := t.Bundle.CSV.GetAnnotations()["alm-examples"]
+	if almExamples == "" {
+		return nil, nil
+	}
+	var crs []unstructured.Unstructured
+	if err := json.Unmarshal([]byte(almExamples), &crs); err != nil {
+		return nil, fmt.Errorf("failed to parse alm-examples annotation: %v", err)
+	}
+	return crs, nil
+}
+
+// Result returns the test's result, with its artifacts appended to its log.
+func (t *T) Result() v1alpha3.TestResult {
+	r := t.result
+	log := t.log.String()
+	for _, a := range t.artifacts {
+		log += fmt.Sprintf("--- artifact: %s ---\n%s", a.name, a.buf.String())
+		if !strings.HasSuffix(log, "\n") {
+			log += "\n"
+		}
+	}
+	r.Log = log
+	return r
+}
+
+// Status returns the status scorecard reads from the test's output.
+func (t *T) Status() v1alpha3.TestStatus {
+	return v1alpha3.TestStatus{Results: []v1alpha3.TestResult{t.Result()}}
+}